@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"math"
 	"runtime"
+	"time"
 
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/persist/fs"
@@ -58,6 +59,10 @@ type BootstrapConfiguration struct {
 	// CacheSeriesMetadata determines whether individual bootstrappers cache
 	// series metadata across all calls (namespaces / shards / blocks).
 	CacheSeriesMetadata *bool `yaml:"cacheSeriesMetadata"`
+
+	// ProgressLogInterval is how often bootstrap progress is logged while
+	// bootstrapping, zero disables logging progress.
+	ProgressLogInterval *time.Duration `yaml:"progressLogInterval"`
 }
 
 func (bsc BootstrapConfiguration) fsNumProcessors() int {
@@ -68,6 +73,14 @@ func (bsc BootstrapConfiguration) fsNumProcessors() int {
 	return int(math.Ceil(float64(runtime.NumCPU()) * np))
 }
 
+func (bsc BootstrapConfiguration) fsNumReaderWorkers() int {
+	if fsCfg := bsc.Filesystem; fsCfg != nil && fsCfg.NumReaderWorkers != nil &&
+		*fsCfg.NumReaderWorkers > 0 {
+		return *fsCfg.NumReaderWorkers
+	}
+	return runtime.NumCPU()
+}
+
 // TODO: Remove once v1 endpoint no longer required.
 func (bsc BootstrapConfiguration) peersFetchBlocksMetadataEndpointVersion() client.FetchBlocksMetadataEndpointVersion {
 	version := client.FetchBlocksMetadataEndpointDefault
@@ -81,6 +94,10 @@ func (bsc BootstrapConfiguration) peersFetchBlocksMetadataEndpointVersion() clie
 type BootstrapFilesystemConfiguration struct {
 	// NumProcessorsPerCPU is the number of processors per CPU.
 	NumProcessorsPerCPU float64 `yaml:"numProcessorsPerCPU" validate:"min=0.0"`
+
+	// NumReaderWorkers is the number of workers reading shard file sets
+	// concurrently, defaults to the number of CPUs and one reads serially.
+	NumReaderWorkers *int `yaml:"numReaderWorkers"`
 }
 
 // BootstrapPeersConfiguration specifies config for the peers bootstrapper.
@@ -127,6 +144,7 @@ func (bsc BootstrapConfiguration) New(
 				SetFilesystemOptions(fsOpts).
 				SetPersistManager(opts.PersistManager()).
 				SetBoostrapDataNumProcessors(bsc.fsNumProcessors()).
+				SetBootstrapReaderNumWorkers(bsc.fsNumReaderWorkers()).
 				SetDatabaseBlockRetrieverManager(opts.DatabaseBlockRetrieverManager()).
 				SetRuntimeOptionsManager(opts.RuntimeOptionsManager()).
 				SetIdentifierPool(opts.IdentifierPool())
@@ -173,6 +191,9 @@ func (bsc BootstrapConfiguration) New(
 	if bsc.CacheSeriesMetadata != nil {
 		providerOpts = providerOpts.SetCacheSeriesMetadata(*bsc.CacheSeriesMetadata)
 	}
+	if bsc.ProgressLogInterval != nil {
+		providerOpts = providerOpts.SetProgressLogInterval(*bsc.ProgressLogInterval)
+	}
 	providerOpts = providerOpts.SetAdminClient(adminClient)
 	return bootstrap.NewProcessProvider(bs, providerOpts, rsOpts)
 }
@@ -32,7 +32,9 @@ import (
 	coordinatorcfg "github.com/m3db/m3/src/cmd/services/m3query/config"
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/environment"
-	"github.com/m3db/m3x/config/hostid"
+	"github.com/m3db/m3/src/dbnode/network/server/carbon"
+	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
+	"github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3x/instrument"
 	xlog "github.com/m3db/m3x/log"
 
@@ -72,6 +74,10 @@ type DBConfiguration struct {
 	// The host and port on which to listen for the node service.
 	ListenAddress string `yaml:"listenAddress" validate:"nonzero"`
 
+	// Additional endpoints on which to listen for the node service, each
+	// optionally serving TLS.
+	ListenEndpoints []ListenEndpointConfiguration `yaml:"listenEndpoints"`
+
 	// The host and port on which to listen for the cluster service.
 	ClusterListenAddress string `yaml:"clusterListenAddress" validate:"nonzero"`
 
@@ -85,7 +91,7 @@ type DBConfiguration struct {
 	DebugListenAddress string `yaml:"debugListenAddress"`
 
 	// HostID is the local host ID configuration.
-	HostID hostid.Configuration `yaml:"hostID"`
+	HostID HostIDConfiguration `yaml:"hostID"`
 
 	// Client configuration, used for inter-node communication and when used as a coordinator.
 	Client client.Configuration `yaml:"client"`
@@ -131,6 +137,46 @@ type DBConfiguration struct {
 
 	// Write new series asynchronously for fast ingestion of new ID bursts.
 	WriteNewSeriesAsync bool `yaml:"writeNewSeriesAsync"`
+
+	// ShutdownFlushEnabled snapshots the in-memory buffers of every shard before
+	// the server closes so that restarts bootstrap from snapshots rather than
+	// replaying the entire commit log.
+	ShutdownFlushEnabled bool `yaml:"shutdownFlushEnabled"`
+
+	// ShutdownFlushTimeout bounds how long the server waits for the shutdown
+	// snapshot before closing, defaults to a minute if not set.
+	ShutdownFlushTimeout time.Duration `yaml:"shutdownFlushTimeout"`
+
+	// NamespaceRemovalFlushEnabled flushes the data of a namespace removed from
+	// the registry before it is released. Note that the files of namespaces no
+	// longer owned are deleted by the next cleanup unless the namespace is added
+	// back beforehand.
+	NamespaceRemovalFlushEnabled bool `yaml:"namespaceRemovalFlushEnabled"`
+
+	// ReadOnly starts the node as a standby which joins the topology, bootstraps
+	// and serves reads but refuses writes, the node is switched to accept writes
+	// by setting the KV key kvconfig.NodeReadOnlyPrefix plus its host ID.
+	ReadOnly bool `yaml:"readOnly"`
+
+	// ShardCircuitBreaker fast fails the reads or writes of a shard while
+	// their recent error rate is high, unset fields use the default values.
+	ShardCircuitBreaker *ShardCircuitBreakerConfiguration `yaml:"shardCircuitBreaker"`
+
+	// Preflight configures the checks of the configuration and environment run
+	// before the server starts.
+	Preflight *PreflightConfiguration `yaml:"preflight"`
+
+	// PrometheusRemote configures the Prometheus remote write and read
+	// endpoints served by the node, they are not served if not set.
+	PrometheusRemote *PrometheusRemoteConfiguration `yaml:"prometheusRemote"`
+
+	// InfluxDB configures the InfluxDB line protocol write endpoint served by
+	// the node, it is not served if not set.
+	InfluxDB *InfluxDBConfiguration `yaml:"influxdb"`
+
+	// Carbon configures the carbon plaintext listener served by the node,
+	// it is not served if not set.
+	Carbon *CarbonConfiguration `yaml:"carbon"`
 }
 
 // IndexConfiguration contains index-specific configuration.
@@ -140,6 +186,102 @@ type IndexConfiguration struct {
 	// important to prevent index queries from overloading the database entirely
 	// as they are very CPU-intensive (regex and FST matching.)
 	MaxQueryIDsConcurrency int `yaml:"maxQueryIDsConcurrency" validate:"min=0"`
+
+	// MaxQueryTermsMatched limits the number of terms a regexp or wildcard query may
+	// match in a single index segment, queries matching more terms are rejected rather
+	// than allocating postings lists for every matched term. Zero means no limit.
+	MaxQueryTermsMatched int `yaml:"maxQueryTermsMatched" validate:"min=0"`
+
+	// PostingsCacheMaxBytes is the maximum estimated size of the postings lists matched
+	// by queries against immutable index segments which are cached, so that repeated
+	// queries aren't searched again. Zero disables the cache.
+	PostingsCacheMaxBytes int `yaml:"postingsCacheMaxBytes" validate:"min=0"`
+
+	// BlockRetentionGracePeriod is how long past the retention period index blocks
+	// are held in memory before they are sealed and released.
+	BlockRetentionGracePeriod time.Duration `yaml:"blockRetentionGracePeriod" validate:"min=0"`
+
+	// QueryLimits are the default limits of index queries which don't set their own,
+	// queries exceeding them return partial results flagged as non-exhaustive.
+	QueryLimits IndexQueryLimitsConfiguration `yaml:"queryLimits"`
+
+	// NamespaceQueryLimits are the per namespace index query limits, which take
+	// precedence over the default query limits.
+	NamespaceQueryLimits map[string]IndexQueryLimitsConfiguration `yaml:"namespaceQueryLimits"`
+
+	// CompactionScheduling defers non-essential index compactions while the
+	// index insert rate is high.
+	CompactionScheduling *IndexCompactionSchedulingConfiguration `yaml:"compactionScheduling"`
+}
+
+// IndexCompactionSchedulingConfiguration is the index compaction scheduling configuration.
+type IndexCompactionSchedulingConfiguration struct {
+	// InsertRateThreshold is the number of index inserts per second above which
+	// non-essential compactions are deferred. Zero disables deferral.
+	InsertRateThreshold float64 `yaml:"insertRateThreshold" validate:"min=0"`
+
+	// InsertRateWindow is the window over which the index insert rate is measured.
+	InsertRateWindow time.Duration `yaml:"insertRateWindow" validate:"min=0"`
+
+	// MaxDeferral is the longest a compaction can be deferred for.
+	MaxDeferral time.Duration `yaml:"maxDeferral" validate:"min=0"`
+}
+
+// IndexQueryLimitsConfiguration is the configuration of the limits of index queries.
+type IndexQueryLimitsConfiguration struct {
+	// SeriesLimit is the maximum number of distinct series returned by a query.
+	// Zero means no limit.
+	SeriesLimit int `yaml:"seriesLimit" validate:"min=0"`
+
+	// DocsLimit is the maximum number of index documents visited by a query.
+	// Zero means no limit.
+	DocsLimit int `yaml:"docsLimit" validate:"min=0"`
+}
+
+// PrometheusRemoteConfiguration is the configuration of the Prometheus remote
+// write and read endpoints served by the node.
+type PrometheusRemoteConfiguration struct {
+	// ListenAddress is the HTTP host and port on which to listen.
+	ListenAddress string `yaml:"listenAddress" validate:"nonzero"`
+
+	// Namespace is the namespace that remote writes and reads are served from.
+	Namespace string `yaml:"namespace" validate:"nonzero"`
+
+	// FetchSeriesMax is the maximum number of series a single remote read
+	// query returns, queries matching more series are rejected.
+	FetchSeriesMax int `yaml:"fetchSeriesMax" validate:"min=0"`
+}
+
+// InfluxDBConfiguration is the configuration of the InfluxDB line protocol
+// write endpoint served by the node.
+type InfluxDBConfiguration struct {
+	// ListenAddress is the HTTP host and port on which to listen.
+	ListenAddress string `yaml:"listenAddress" validate:"nonzero"`
+
+	// Namespace is the namespace that writes are written to.
+	Namespace string `yaml:"namespace" validate:"nonzero"`
+
+	// MaxRequestBytes is the maximum size of a single write request body
+	// after it is decompressed, larger requests are rejected.
+	MaxRequestBytes int `yaml:"maxRequestBytes" validate:"min=0"`
+}
+
+// CarbonConfiguration is the configuration of the carbon plaintext listener
+// served by the node.
+type CarbonConfiguration struct {
+	// ListenAddress is the TCP host and port on which to listen.
+	ListenAddress string `yaml:"listenAddress" validate:"nonzero"`
+
+	// Namespace is the namespace that metrics are written to.
+	Namespace string `yaml:"namespace" validate:"nonzero"`
+
+	// MaxConnections is the maximum number of concurrent connections.
+	MaxConnections int `yaml:"maxConnections" validate:"min=0"`
+
+	// Rules are the rules that extract tags from metric paths, the first
+	// rule that matches a path is applied. Paths that no rule matches are
+	// tagged by the position of their components.
+	Rules []carbon.RuleConfiguration `yaml:"rules"`
 }
 
 // TickConfiguration is the tick configuration for background processing of
@@ -158,6 +300,52 @@ type TickConfiguration struct {
 
 	// Tick minimum interval controls the minimum tick interval for the node.
 	MinimumInterval time.Duration `yaml:"minimumInterval"`
+
+	// AdaptivePacing adjusts the series batch size and per series sleep by
+	// the observed write latency, unset fields use the default values.
+	AdaptivePacing *TickAdaptivePacingConfiguration `yaml:"adaptivePacing"`
+}
+
+// TickAdaptivePacingConfiguration is the adaptive tick pacing configuration.
+type TickAdaptivePacingConfiguration struct {
+	// Enabled enables the adaptive tick pacing.
+	Enabled bool `yaml:"enabled"`
+
+	// TargetWriteLatency is the p99 write latency above which ticks back off.
+	TargetWriteLatency time.Duration `yaml:"targetWriteLatency" validate:"min=0"`
+
+	// MinSeriesBatchSize is the lower bound of the series batch size.
+	MinSeriesBatchSize int `yaml:"minSeriesBatchSize" validate:"min=0"`
+
+	// MaxSeriesBatchSize is the upper bound of the series batch size.
+	MaxSeriesBatchSize int `yaml:"maxSeriesBatchSize" validate:"min=0"`
+
+	// MinPerSeriesSleepDuration is the lower bound of the per series sleep.
+	MinPerSeriesSleepDuration time.Duration `yaml:"minPerSeriesSleepDuration" validate:"min=0"`
+
+	// MaxPerSeriesSleepDuration is the upper bound of the per series sleep.
+	MaxPerSeriesSleepDuration time.Duration `yaml:"maxPerSeriesSleepDuration" validate:"min=0"`
+}
+
+// ShardCircuitBreakerConfiguration is the shard circuit breaker configuration.
+type ShardCircuitBreakerConfiguration struct {
+	// Enabled enables the shard circuit breakers.
+	Enabled bool `yaml:"enabled"`
+
+	// ErrorRateThreshold is the fraction of failed requests within a window
+	// at or above which a breaker opens.
+	ErrorRateThreshold float64 `yaml:"errorRateThreshold" validate:"min=0.0,max=1.0"`
+
+	// MinimumRequests is the number of requests a window needs before its
+	// error rate can open a breaker.
+	MinimumRequests int `yaml:"minimumRequests" validate:"min=0"`
+
+	// Window is the duration over which the error rate is tracked.
+	Window time.Duration `yaml:"window" validate:"min=0"`
+
+	// ProbeInterval is how long an open breaker fast fails requests before
+	// probing whether the shard recovered.
+	ProbeInterval time.Duration `yaml:"probeInterval" validate:"min=0"`
 }
 
 // BlockRetrievePolicy is the block retrieve policy.
@@ -165,6 +353,14 @@ type BlockRetrievePolicy struct {
 	// FetchConcurrency is the concurrency to fetch blocks from disk. For
 	// spinning disks it is highly recommended to set this value to 1.
 	FetchConcurrency int `yaml:"fetchConcurrency" validate:"min=0"`
+
+	// ReadAhead enables prefetching the subsequent blocks of a series when
+	// a range query reads consecutive blocks of it from disk.
+	ReadAhead bool `yaml:"readAhead"`
+
+	// ReadAheadBlocks is the number of subsequent blocks to prefetch when
+	// read ahead is enabled.
+	ReadAheadBlocks int `yaml:"readAheadBlocks" validate:"min=0"`
 }
 
 // CommitLogPolicy is the commit log policy.
@@ -180,6 +376,10 @@ type CommitLogPolicy struct {
 
 	// The commit log block size.
 	BlockSize time.Duration `yaml:"blockSize" validate:"nonzero"`
+
+	// The compression applied to each commit log chunk as it is flushed,
+	// commit logs written with or without compression are always readable.
+	Compression *commitlog.CompressionType `yaml:"compression"`
 }
 
 // CalculationType is a type of configuration parameter.
@@ -199,6 +399,14 @@ type CommitLogQueuePolicy struct {
 
 	// The size of the commit log, calculated according to the calculation type.
 	Size int `yaml:"size" validate:"nonzero"`
+
+	// The policy applied to writes when the queue is full, one of
+	// drop-current, drop-oldest or block, defaults to drop-current.
+	Policy *runtime.CommitLogQueuePolicy `yaml:"policy"`
+
+	// The number of writes that can be queued before the policy applies,
+	// zero or a value larger than the size uses the size.
+	Limit int `yaml:"limit" validate:"min=0"`
 }
 
 // RepairPolicy is the repair policy.
@@ -220,6 +428,14 @@ type RepairPolicy struct {
 
 	// The repair check interval.
 	CheckInterval time.Duration `yaml:"checkInterval" validate:"nonzero"`
+
+	// SkipBufferChecksums skips checksumming blocks still in the buffer, these
+	// blocks are then only compared by size with the blocks of other replicas.
+	SkipBufferChecksums bool `yaml:"skipBufferChecksums"`
+
+	// BufferChecksumsPerSecond limits the number of series per second that
+	// have their buffered blocks checksummed, zero means unlimited.
+	BufferChecksumsPerSecond *float64 `yaml:"bufferChecksumsPerSecond"`
 }
 
 // HashingConfiguration is the configuration for hashing.
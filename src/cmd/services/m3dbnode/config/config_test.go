@@ -301,6 +301,14 @@ db:
 	expected := `db:
   index:
     maxQueryIDsConcurrency: 0
+    maxQueryTermsMatched: 0
+    postingsCacheMaxBytes: 0
+    blockRetentionGracePeriod: 0s
+    queryLimits:
+      seriesLimit: 0
+      docsLimit: 0
+    namespaceQueryLimits: {}
+    compactionScheduling: null
   logging:
     file: /var/log/m3dbnode.log
     level: info
@@ -319,6 +327,7 @@ db:
     extended: 3
     sanitization: 2
   listenAddress: 0.0.0.0:9000
+  listenEndpoints: []
   clusterListenAddress: 0.0.0.0:9001
   httpNodeListenAddress: 0.0.0.0:9002
   httpClusterListenAddress: 0.0.0.0:9003
@@ -327,11 +336,14 @@ db:
     resolver: config
     value: host1
     envVarName: null
+    http: null
+    file: null
   client:
     config:
       service: null
       static: null
       seedNodes: null
+      etcdClient: null
     writeConsistencyLevel: 2
     readConsistencyLevel: 2
     connectConsistencyLevel: 0
@@ -356,6 +368,8 @@ db:
     backgroundHealthCheckFailThrottleFactor: 0.5
     hashing:
       seed: 42
+    peerStreaming: null
+    readSpeculativeRetry: null
   gcPercentage: 100
   writeNewSeriesLimitPerSecond: 1048576
   writeNewSeriesBackoffDuration: 2ms
@@ -367,8 +381,10 @@ db:
     - noop-all
     fs:
       numProcessorsPerCPU: 0.125
+      numReaderWorkers: null
     peers: null
     cacheSeriesMetadata: null
+    progressLogInterval: null
   blockRetrieve: null
   cache:
     series: null
@@ -383,13 +399,18 @@ db:
     newFileMode: null
     newDirectoryMode: null
     mmap: null
+    bloomFilterFalsePositivePercent: null
+    dataPageSize: null
   commitlog:
     flushMaxBytes: 524288
     flushEvery: 1s
     queue:
       calculationType: fixed
       size: 2097152
+      policy: null
+      limit: 0
     blockSize: 10m0s
+    compression: null
   repair:
     enabled: false
     interval: 2h0m0s
@@ -397,6 +418,8 @@ db:
     jitter: 1h0m0s
     throttle: 2m0s
     checkInterval: 1m0s
+    skipBufferChecksums: false
+    bufferChecksumsPerSecond: null
   pooling:
     blockAllocSize: 16
     type: simple
@@ -575,9 +598,23 @@ db:
         trustedCaFile: ""
         clientCertAuth: false
         autoTls: false
+      autoCompactionRetention: 0s
+      defragInterval: 0s
+      maintainOnlyAsLeader: false
+      readyTimeout: 0s
+    etcdClient: null
   hashing:
     seed: 42
   writeNewSeriesAsync: true
+  shutdownFlushEnabled: false
+  shutdownFlushTimeout: 0s
+  namespaceRemovalFlushEnabled: false
+  readOnly: false
+  shardCircuitBreaker: null
+  preflight: null
+  prometheusRemote: null
+  influxdb: null
+  carbon: null
 coordinator: null
 `
 
@@ -75,6 +75,18 @@ type FilesystemConfiguration struct {
 
 	// Mmap is the mmap options which features are primarily platform dependent
 	Mmap *MmapConfiguration `yaml:"mmap"`
+
+	// BloomFilterFalsePositivePercent is the target false positive rate of the
+	// bloom filter of series IDs written alongside each fileset, as a fraction
+	// between zero and one.
+	BloomFilterFalsePositivePercent *float64 `yaml:"bloomFilterFalsePositivePercent"`
+
+	// DataPageSize is the size in bytes of the pages that series data is
+	// buffered into before being written to the data file of a fileset, zero
+	// writes each series directly using the legacy data layout. Defaults to
+	// zero as filesets written with the paged layout can't be read by earlier
+	// versions, so a rollback would fail to read them.
+	DataPageSize *int `yaml:"dataPageSize" validate:"min=0"`
 }
 
 // MmapConfiguration is the mmap configuration.
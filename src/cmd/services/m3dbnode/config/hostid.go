@@ -0,0 +1,245 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3x/config/hostid"
+)
+
+const (
+	// HTTPHostIDResolver resolves the host ID by requesting it from an HTTP
+	// endpoint, such as a cloud provider instance metadata service.
+	HTTPHostIDResolver hostid.Resolver = "http"
+	// FileHostIDResolver resolves the host ID by reading it from a file,
+	// optionally waiting for the file to be written.
+	FileHostIDResolver hostid.Resolver = "file"
+
+	defaultHostIDHTTPTimeout      = 5 * time.Second
+	defaultHostIDFilePollInterval = time.Second
+)
+
+var (
+	errHostIDHTTPConfigMissing = errors.New("missing host ID http config")
+	errHostIDHTTPURLMissing    = errors.New("missing host ID http url")
+	errHostIDFileConfigMissing = errors.New("missing host ID file config")
+	errHostIDFilePathMissing   = errors.New("missing host ID file path")
+	errHostIDEmpty             = errors.New("resolved host ID is empty")
+)
+
+// HostIDConfiguration is the configuration for resolving the local host ID,
+// it supports the hostname, config and environment resolvers as well as
+// resolving the host ID from an HTTP endpoint or from a file.
+type HostIDConfiguration struct {
+	// Resolver is the resolver for the host ID.
+	Resolver hostid.Resolver `yaml:"resolver"`
+
+	// Value is the config specified host ID if using config host ID resolver.
+	Value *string `yaml:"value"`
+
+	// EnvVarName is the environment specified host ID if using environment host ID resolver.
+	EnvVarName *string `yaml:"envVarName"`
+
+	// HTTP is the configuration used if using http host ID resolver.
+	HTTP *HostIDHTTPConfiguration `yaml:"http"`
+
+	// File is the configuration used if using file host ID resolver.
+	File *HostIDFileConfiguration `yaml:"file"`
+}
+
+// HostIDHTTPConfiguration is the configuration for resolving the host ID
+// from an HTTP endpoint.
+type HostIDHTTPConfiguration struct {
+	// URL is the URL to request the host ID from.
+	URL string `yaml:"url"`
+
+	// Timeout is the timeout for the request, defaults to 5s.
+	Timeout time.Duration `yaml:"timeout"`
+
+	// Headers are additional headers to set on the request.
+	Headers map[string]string `yaml:"headers"`
+
+	// JSONPath is an optional dot separated path to the host ID in a JSON
+	// response body, if not set the whole response body is the host ID.
+	JSONPath string `yaml:"jsonPath"`
+}
+
+// HostIDFileConfiguration is the configuration for resolving the host ID
+// from a file.
+type HostIDFileConfiguration struct {
+	// Path is the path of the file containing the host ID.
+	Path string `yaml:"path"`
+
+	// PollInterval is the interval between attempts to read the file while
+	// waiting for it to be written, defaults to 1s.
+	PollInterval time.Duration `yaml:"pollInterval"`
+
+	// MaxWait is the maximum time to wait for the file to exist and be
+	// non-empty, if zero the file is only read once.
+	MaxWait time.Duration `yaml:"maxWait"`
+}
+
+// Resolve returns the resolved host ID given the configuration.
+func (c HostIDConfiguration) Resolve() (string, error) {
+	switch c.Resolver {
+	case HTTPHostIDResolver:
+		if c.HTTP == nil {
+			return "", errHostIDHTTPConfigMissing
+		}
+		return c.HTTP.resolve()
+	case FileHostIDResolver:
+		if c.File == nil {
+			return "", errHostIDFileConfigMissing
+		}
+		return c.File.resolve()
+	}
+
+	cfg := hostid.Configuration{
+		Resolver:   c.Resolver,
+		Value:      c.Value,
+		EnvVarName: c.EnvVarName,
+	}
+	return cfg.Resolve()
+}
+
+func (c HostIDHTTPConfiguration) resolve() (string, error) {
+	if c.URL == "" {
+		return "", errHostIDHTTPURLMissing
+	}
+
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = defaultHostIDHTTPTimeout
+	}
+
+	req, err := http.NewRequest(http.MethodGet, c.URL, nil)
+	if err != nil {
+		return "", err
+	}
+	for k, v := range c.Headers {
+		req.Header.Set(k, v)
+	}
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("could not request host ID: url=%s, err=%v", c.URL, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("could not read host ID response: url=%s, err=%v", c.URL, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected host ID response: url=%s, status=%d",
+			c.URL, resp.StatusCode)
+	}
+
+	if c.JSONPath == "" {
+		return validHostID(string(body))
+	}
+	return hostIDFromJSON(body, c.JSONPath)
+}
+
+// hostIDFromJSON extracts the host ID at the dot separated path in a JSON
+// document, the value at the path must be a string or a number.
+func hostIDFromJSON(body []byte, path string) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return "", fmt.Errorf("could not decode host ID response: %v", err)
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("host ID json path not an object: path=%s, key=%s", path, key)
+		}
+		value, ok = obj[key]
+		if !ok {
+			return "", fmt.Errorf("host ID json path not found: path=%s, key=%s", path, key)
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		return validHostID(v)
+	case json.Number:
+		return v.String(), nil
+	}
+	return "", fmt.Errorf("host ID json value not a string or number: path=%s", path)
+}
+
+func (c HostIDFileConfiguration) resolve() (string, error) {
+	if c.Path == "" {
+		return "", errHostIDFilePathMissing
+	}
+
+	pollInterval := c.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultHostIDFilePollInterval
+	}
+
+	deadline := time.Now().Add(c.MaxWait)
+	for {
+		id, err := c.read()
+		if err == nil {
+			return id, nil
+		}
+		remaining := deadline.Sub(time.Now())
+		if remaining <= 0 {
+			return "", fmt.Errorf("could not read host ID file: path=%s, maxWait=%s, err=%v",
+				c.Path, c.MaxWait.String(), err)
+		}
+		if remaining < pollInterval {
+			time.Sleep(remaining)
+		} else {
+			time.Sleep(pollInterval)
+		}
+	}
+}
+
+func (c HostIDFileConfiguration) read() (string, error) {
+	data, err := ioutil.ReadFile(c.Path)
+	if err != nil {
+		return "", err
+	}
+	return validHostID(string(data))
+}
+
+func validHostID(value string) (string, error) {
+	id := strings.TrimSpace(value)
+	if id == "" {
+		return "", errHostIDEmpty
+	}
+	return id, nil
+}
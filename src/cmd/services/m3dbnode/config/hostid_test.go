@@ -0,0 +1,202 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3x/config/hostid"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostIDConfigurationConfigResolver(t *testing.T) {
+	value := "host1"
+	cfg := HostIDConfiguration{
+		Resolver: hostid.ConfigResolver,
+		Value:    &value,
+	}
+
+	id, err := cfg.Resolve()
+	require.NoError(t, err)
+	assert.Equal(t, "host1", id)
+}
+
+func TestHostIDConfigurationHTTPResolver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Google", r.Header.Get("Metadata-Flavor"))
+		fmt.Fprint(w, "i-0123456789\n")
+	}))
+	defer server.Close()
+
+	cfg := HostIDConfiguration{
+		Resolver: HTTPHostIDResolver,
+		HTTP: &HostIDHTTPConfiguration{
+			URL:     server.URL,
+			Headers: map[string]string{"Metadata-Flavor": "Google"},
+		},
+	}
+
+	id, err := cfg.Resolve()
+	require.NoError(t, err)
+	assert.Equal(t, "i-0123456789", id)
+}
+
+func TestHostIDConfigurationHTTPResolverJSONPath(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"instance":{"id":"i-0123456789","index":3}}`)
+	}))
+	defer server.Close()
+
+	cfg := HostIDConfiguration{
+		Resolver: HTTPHostIDResolver,
+		HTTP: &HostIDHTTPConfiguration{
+			URL:      server.URL,
+			JSONPath: "instance.id",
+		},
+	}
+
+	id, err := cfg.Resolve()
+	require.NoError(t, err)
+	assert.Equal(t, "i-0123456789", id)
+
+	cfg.HTTP.JSONPath = "instance.index"
+	id, err = cfg.Resolve()
+	require.NoError(t, err)
+	assert.Equal(t, "3", id)
+
+	cfg.HTTP.JSONPath = "instance.name"
+	_, err = cfg.Resolve()
+	require.Error(t, err)
+
+	cfg.HTTP.JSONPath = "instance.id.value"
+	_, err = cfg.Resolve()
+	require.Error(t, err)
+}
+
+func TestHostIDConfigurationHTTPResolverErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Second)
+		fmt.Fprint(w, "i-0123456789")
+	}))
+	defer server.Close()
+
+	cfg := HostIDConfiguration{Resolver: HTTPHostIDResolver}
+	_, err := cfg.Resolve()
+	require.Equal(t, errHostIDHTTPConfigMissing, err)
+
+	cfg.HTTP = &HostIDHTTPConfiguration{}
+	_, err = cfg.Resolve()
+	require.Equal(t, errHostIDHTTPURLMissing, err)
+
+	cfg.HTTP = &HostIDHTTPConfiguration{
+		URL:     server.URL,
+		Timeout: 50 * time.Millisecond,
+	}
+	_, err = cfg.Resolve()
+	require.Error(t, err)
+
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	defer notFound.Close()
+
+	cfg.HTTP = &HostIDHTTPConfiguration{URL: notFound.URL}
+	_, err = cfg.Resolve()
+	require.Error(t, err)
+}
+
+func TestHostIDConfigurationFileResolver(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hostid")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filePath := path.Join(dir, "hostid")
+	require.NoError(t, ioutil.WriteFile(filePath, []byte("host1\n"), 0666))
+
+	cfg := HostIDConfiguration{
+		Resolver: FileHostIDResolver,
+		File:     &HostIDFileConfiguration{Path: filePath},
+	}
+
+	id, err := cfg.Resolve()
+	require.NoError(t, err)
+	assert.Equal(t, "host1", id)
+}
+
+func TestHostIDConfigurationFileResolverWaitsForFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hostid")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filePath := path.Join(dir, "hostid")
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		// Write an empty file first to ensure empty files are retried.
+		assert.NoError(t, ioutil.WriteFile(filePath, nil, 0666))
+		time.Sleep(100 * time.Millisecond)
+		assert.NoError(t, ioutil.WriteFile(filePath, []byte("host1"), 0666))
+	}()
+
+	cfg := HostIDConfiguration{
+		Resolver: FileHostIDResolver,
+		File: &HostIDFileConfiguration{
+			Path:         filePath,
+			PollInterval: 10 * time.Millisecond,
+			MaxWait:      10 * time.Second,
+		},
+	}
+
+	id, err := cfg.Resolve()
+	require.NoError(t, err)
+	assert.Equal(t, "host1", id)
+}
+
+func TestHostIDConfigurationFileResolverMaxWait(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hostid")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := HostIDConfiguration{Resolver: FileHostIDResolver}
+	_, err = cfg.Resolve()
+	require.Equal(t, errHostIDFileConfigMissing, err)
+
+	cfg.File = &HostIDFileConfiguration{}
+	_, err = cfg.Resolve()
+	require.Equal(t, errHostIDFilePathMissing, err)
+
+	cfg.File = &HostIDFileConfiguration{
+		Path:         path.Join(dir, "hostid"),
+		PollInterval: 10 * time.Millisecond,
+		MaxWait:      100 * time.Millisecond,
+	}
+
+	start := time.Now()
+	_, err = cfg.Resolve()
+	require.Error(t, err)
+	assert.True(t, time.Since(start) >= 100*time.Millisecond)
+}
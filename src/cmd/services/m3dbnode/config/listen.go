@@ -0,0 +1,161 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+)
+
+var (
+	errTLSClientAuthWithoutCA = errors.New("tls client certificate verification requires a CA file")
+)
+
+// TLSClientAuthType is the client certificate policy of a TLS endpoint.
+type TLSClientAuthType string
+
+const (
+	// TLSNoClientCert does not request a client certificate.
+	TLSNoClientCert TLSClientAuthType = "none"
+	// TLSRequestClientCert requests but does not require a client certificate.
+	TLSRequestClientCert TLSClientAuthType = "request"
+	// TLSRequireAnyClientCert requires a client certificate but does not verify it.
+	TLSRequireAnyClientCert TLSClientAuthType = "requireAny"
+	// TLSVerifyClientCertIfGiven verifies the client certificate if one is given.
+	TLSVerifyClientCertIfGiven TLSClientAuthType = "verifyIfGiven"
+	// TLSRequireAndVerifyClientCert requires and verifies a client certificate.
+	TLSRequireAndVerifyClientCert TLSClientAuthType = "requireAndVerify"
+)
+
+func (t TLSClientAuthType) clientAuth() (tls.ClientAuthType, error) {
+	switch t {
+	case "", TLSNoClientCert:
+		return tls.NoClientCert, nil
+	case TLSRequestClientCert:
+		return tls.RequestClientCert, nil
+	case TLSRequireAnyClientCert:
+		return tls.RequireAnyClientCert, nil
+	case TLSVerifyClientCertIfGiven:
+		return tls.VerifyClientCertIfGiven, nil
+	case TLSRequireAndVerifyClientCert:
+		return tls.RequireAndVerifyClientCert, nil
+	}
+	return tls.NoClientCert, fmt.Errorf("unknown tls client auth type: %s", string(t))
+}
+
+// ListenEndpointConfiguration is the configuration of an endpoint the node
+// service listens on in addition to the listen address.
+type ListenEndpointConfiguration struct {
+	// Name identifies the endpoint in metrics, defaults to the address.
+	Name string `yaml:"name"`
+
+	// Address is the host and port to listen on.
+	Address string `yaml:"address" validate:"nonzero"`
+
+	// TLS configures TLS for the endpoint, if not set connections are plaintext.
+	TLS *TLSConfiguration `yaml:"tls"`
+}
+
+// NameOrDefault returns the name of the endpoint or its address if none is set.
+func (c ListenEndpointConfiguration) NameOrDefault() string {
+	if c.Name == "" {
+		return c.Address
+	}
+	return c.Name
+}
+
+// Listen listens on the endpoint, returning a TLS listener if TLS is configured.
+func (c ListenEndpointConfiguration) Listen() (net.Listener, error) {
+	var tlsCfg *tls.Config
+	if c.TLS != nil {
+		var err error
+		tlsCfg, err = c.TLS.ServerConfig()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	l, err := net.Listen("tcp", c.Address)
+	if err != nil {
+		return nil, err
+	}
+	if tlsCfg == nil {
+		return l, nil
+	}
+	return tls.NewListener(l, tlsCfg), nil
+}
+
+// TLSConfiguration is the TLS configuration of an endpoint.
+type TLSConfiguration struct {
+	// CertFile is the path of the PEM encoded certificate.
+	CertFile string `yaml:"certFile" validate:"nonzero"`
+
+	// KeyFile is the path of the PEM encoded private key of the certificate.
+	KeyFile string `yaml:"keyFile" validate:"nonzero"`
+
+	// CAFile is the path of the PEM encoded certificate authorities used to
+	// verify client certificates.
+	CAFile string `yaml:"caFile"`
+
+	// ClientAuth is the client certificate policy, defaults to none.
+	ClientAuth TLSClientAuthType `yaml:"clientAuth"`
+}
+
+// ServerConfig returns the TLS configuration for serving the endpoint.
+func (c TLSConfiguration) ServerConfig() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not load tls certificate: %v", err)
+	}
+
+	clientAuth, err := c.ClientAuth.clientAuth()
+	if err != nil {
+		return nil, err
+	}
+
+	tlsCfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   clientAuth,
+	}
+
+	if c.CAFile == "" {
+		if clientAuth == tls.VerifyClientCertIfGiven ||
+			clientAuth == tls.RequireAndVerifyClientCert {
+			return nil, errTLSClientAuthWithoutCA
+		}
+		return tlsCfg, nil
+	}
+
+	caPEM, err := ioutil.ReadFile(c.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read tls CA file: %v", err)
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificates in tls CA file: %s", c.CAFile)
+	}
+	tlsCfg.ClientCAs = clientCAs
+	return tlsCfg, nil
+}
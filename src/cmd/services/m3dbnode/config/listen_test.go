@@ -0,0 +1,145 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestTLSCertificate writes a self signed certificate for 127.0.0.1 and
+// its key to the directory, returning the paths of the certificate and key.
+func writeTestTLSCertificate(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := path.Join(dir, "cert.pem")
+	keyFile := path.Join(dir, "key.pem")
+	require.NoError(t, ioutil.WriteFile(certFile,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, ioutil.WriteFile(keyFile,
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
+
+func TestListenEndpointConfigurationTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "listen")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile := writeTestTLSCertificate(t, dir)
+	cfg := ListenEndpointConfiguration{
+		Address: "127.0.0.1:0",
+		TLS: &TLSConfiguration{
+			CertFile: certFile,
+			KeyFile:  keyFile,
+		},
+	}
+	require.Equal(t, "127.0.0.1:0", cfg.NameOrDefault())
+
+	l, err := cfg.Listen()
+	require.NoError(t, err)
+	defer l.Close()
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte("ok"))
+	}()
+
+	caPEM, err := ioutil.ReadFile(certFile)
+	require.NoError(t, err)
+	rootCAs := x509.NewCertPool()
+	require.True(t, rootCAs.AppendCertsFromPEM(caPEM))
+
+	conn, err := tls.Dial("tcp", l.Addr().String(), &tls.Config{RootCAs: rootCAs})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	buf := make([]byte, 2)
+	_, err = conn.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "ok", string(buf))
+}
+
+func TestTLSConfigurationClientAuth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "listen")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile := writeTestTLSCertificate(t, dir)
+	cfg := TLSConfiguration{
+		CertFile:   certFile,
+		KeyFile:    keyFile,
+		ClientAuth: TLSRequireAndVerifyClientCert,
+	}
+
+	_, err = cfg.ServerConfig()
+	require.Equal(t, errTLSClientAuthWithoutCA, err)
+
+	cfg.CAFile = certFile
+	tlsCfg, err := cfg.ServerConfig()
+	require.NoError(t, err)
+	require.Equal(t, tls.RequireAndVerifyClientCert, tlsCfg.ClientAuth)
+	require.NotNil(t, tlsCfg.ClientCAs)
+
+	cfg.ClientAuth = "unknown"
+	_, err = cfg.ServerConfig()
+	require.Error(t, err)
+
+	cfg.ClientAuth = TLSNoClientCert
+	cfg.KeyFile = path.Join(dir, "missing.pem")
+	_, err = cfg.ServerConfig()
+	require.Error(t, err)
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import "time"
+
+const (
+	defaultPreflightMinimumFreeSpaceBytes = 1 << 30 // 1GiB
+	defaultPreflightEtcdDialTimeout       = 5 * time.Second
+)
+
+// PreflightConfiguration is the configuration for the checks of the
+// configuration and environment run before the server starts.
+type PreflightConfiguration struct {
+	// MinimumFreeSpaceBytes is the minimum free space required on the filesystem
+	// holding the data directory, defaults to 1GiB.
+	MinimumFreeSpaceBytes uint64 `yaml:"minimumFreeSpaceBytes"`
+
+	// EtcdDialTimeout is the timeout for connecting to each etcd endpoint when
+	// checking the endpoints are reachable, defaults to 5s.
+	EtcdDialTimeout time.Duration `yaml:"etcdDialTimeout"`
+}
+
+// MinimumFreeSpaceBytesOrDefault returns the minimum free space or the default
+// if none is specified.
+func (c *PreflightConfiguration) MinimumFreeSpaceBytesOrDefault() uint64 {
+	if c == nil || c.MinimumFreeSpaceBytes == 0 {
+		return defaultPreflightMinimumFreeSpaceBytes
+	}
+	return c.MinimumFreeSpaceBytes
+}
+
+// EtcdDialTimeoutOrDefault returns the etcd dial timeout or the default if
+// none is specified.
+func (c *PreflightConfiguration) EtcdDialTimeoutOrDefault() time.Duration {
+	if c == nil || c.EtcdDialTimeout <= 0 {
+		return defaultPreflightEtcdDialTimeout
+	}
+	return c.EtcdDialTimeout
+}
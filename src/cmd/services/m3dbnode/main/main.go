@@ -35,7 +35,9 @@ import (
 )
 
 var (
-	configFile = flag.String("f", "", "configuration file")
+	configFile   = flag.String("f", "", "configuration file")
+	validateOnly = flag.Bool("validate-only", false,
+		"run the preflight checks of the configuration and environment then exit")
 )
 
 func main() {
@@ -52,6 +54,17 @@ func main() {
 		os.Exit(1)
 	}
 
+	if *validateOnly {
+		if cfg.DB != nil {
+			if err := dbserver.Preflight(*cfg.DB); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+		}
+		fmt.Fprintf(os.Stdout, "configuration %s is valid\n", *configFile)
+		os.Exit(0)
+	}
+
 	var (
 		dbClientCh        chan client.Client
 		clusterClientCh   chan clusterclient.Client
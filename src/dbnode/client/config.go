@@ -79,6 +79,74 @@ type Configuration struct {
 
 	// HashingConfiguration is the configuration for hashing of IDs to shards.
 	HashingConfiguration HashingConfiguration `yaml:"hashing"`
+
+	// PeerStreaming is the configuration for streaming blocks from peers.
+	PeerStreaming *PeerStreamingConfiguration `yaml:"peerStreaming"`
+
+	// ReadSpeculativeRetry is the configuration for fetching from a single
+	// replica first and only fanning out to the remaining replicas on delay.
+	ReadSpeculativeRetry *ReadSpeculativeRetryConfiguration `yaml:"readSpeculativeRetry"`
+}
+
+// PeerStreamingConfiguration is the configuration for streaming blocks from
+// peers, unset values fall back to the defaults
+type PeerStreamingConfiguration struct {
+	// BlocksBatchSize is the number of blocks to fetch in a single request.
+	BlocksBatchSize int `yaml:"blocksBatchSize" validate:"min=0"`
+
+	// MetadataBatchSize is the page size when fetching blocks metadata.
+	MetadataBatchSize int `yaml:"metadataBatchSize" validate:"min=0"`
+
+	// MaxBlocksInFlightPerPeer is the max number of blocks assigned to a
+	// peer that are yet to be fetched.
+	MaxBlocksInFlightPerPeer int `yaml:"maxBlocksInFlightPerPeer" validate:"min=0"`
+
+	// MaxConcurrentStreamsPerPeer is the max number of requests for blocks
+	// outstanding to a single peer.
+	MaxConcurrentStreamsPerPeer int `yaml:"maxConcurrentStreamsPerPeer" validate:"min=0"`
+}
+
+func (c PeerStreamingConfiguration) apply(opts AdminOptions) AdminOptions {
+	if c.BlocksBatchSize > 0 {
+		opts = opts.SetFetchSeriesBlocksBatchSize(c.BlocksBatchSize)
+	}
+	if c.MetadataBatchSize > 0 {
+		opts = opts.SetFetchSeriesBlocksMetadataBatchSize(c.MetadataBatchSize)
+	}
+	if c.MaxBlocksInFlightPerPeer > 0 {
+		opts = opts.SetFetchSeriesBlocksMaxBlocksInFlightPerPeer(c.MaxBlocksInFlightPerPeer)
+	}
+	if c.MaxConcurrentStreamsPerPeer > 0 {
+		opts = opts.SetFetchSeriesBlocksMaxConcurrentStreamsPerPeer(c.MaxConcurrentStreamsPerPeer)
+	}
+	return opts
+}
+
+// ReadSpeculativeRetryConfiguration is the configuration for speculatively
+// retrying fetches against the remaining replicas, unset values fall back
+// to the defaults
+type ReadSpeculativeRetryConfiguration struct {
+	// Enabled sets whether fetches at the unstrict majority read consistency
+	// level are sent to a single replica first.
+	Enabled bool `yaml:"enabled"`
+
+	// Percentile is the percentile of recent latencies to the first replica
+	// after which the remaining replicas are fetched from.
+	Percentile float64 `yaml:"percentile" validate:"min=0,max=1"`
+
+	// MaxDelay is the max delay before the remaining replicas are fetched from.
+	MaxDelay time.Duration `yaml:"maxDelay" validate:"min=0"`
+}
+
+func (c ReadSpeculativeRetryConfiguration) apply(opts AdminOptions) AdminOptions {
+	opts = opts.SetReadSpeculativeRetryEnabled(c.Enabled).(AdminOptions)
+	if c.Percentile > 0 {
+		opts = opts.SetReadSpeculativeRetryPercentile(c.Percentile).(AdminOptions)
+	}
+	if c.MaxDelay > 0 {
+		opts = opts.SetReadSpeculativeRetryMaxDelay(c.MaxDelay).(AdminOptions)
+	}
+	return opts
 }
 
 // HashingConfiguration is the configuration for hashing
@@ -200,8 +268,15 @@ func (c Configuration) NewAdminClient(
 		return m3tsz.NewReaderIterator(r, intOptimized, encodingOpts)
 	})
 
-	// Apply programtic custom options last
 	opts := v.(AdminOptions)
+	if c.PeerStreaming != nil {
+		opts = c.PeerStreaming.apply(opts)
+	}
+	if c.ReadSpeculativeRetry != nil {
+		opts = c.ReadSpeculativeRetry.apply(opts)
+	}
+
+	// Apply programtic custom options last
 	for _, opt := range custom {
 		opts = opt(opts)
 	}
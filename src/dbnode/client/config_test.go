@@ -56,6 +56,14 @@ backgroundHealthCheckFailLimit: 4
 backgroundHealthCheckFailThrottleFactor: 0.5
 hashing:
   seed: 42
+peerStreaming:
+  metadataBatchSize: 1024
+  maxBlocksInFlightPerPeer: 8192
+  maxConcurrentStreamsPerPeer: 2
+readSpeculativeRetry:
+  enabled: true
+  percentile: 0.99
+  maxDelay: 50ms
 `
 
 	fd, err := ioutil.TempFile("", "config.yaml")
@@ -97,6 +105,16 @@ hashing:
 		HashingConfiguration: HashingConfiguration{
 			Seed: 42,
 		},
+		PeerStreaming: &PeerStreamingConfiguration{
+			MetadataBatchSize:           1024,
+			MaxBlocksInFlightPerPeer:    8192,
+			MaxConcurrentStreamsPerPeer: 2,
+		},
+		ReadSpeculativeRetry: &ReadSpeculativeRetryConfiguration{
+			Enabled:    true,
+			Percentile: 0.99,
+			MaxDelay:   50 * time.Millisecond,
+		},
 	}
 
 	assert.Equal(t, expected, cfg)
@@ -35,6 +35,7 @@ import (
 	xclose "github.com/m3db/m3x/close"
 
 	"github.com/spaolacci/murmur3"
+	"github.com/uber-go/tally"
 	"github.com/uber/tchannel-go"
 	"github.com/uber/tchannel-go/thrift"
 )
@@ -56,6 +57,8 @@ type connPool struct {
 	pool               []conn
 	poolLen            int64
 	used               int64
+	failing            int64
+	metrics            connPoolMetrics
 	connectRand        rand.Source
 	healthCheckRand    rand.Source
 	newConn            newConnFn
@@ -67,6 +70,25 @@ type connPool struct {
 	status             status
 }
 
+type connPoolMetrics struct {
+	healthy            tally.Gauge
+	connections        tally.Gauge
+	failingConnections tally.Gauge
+	replacedConns      tally.Counter
+}
+
+func newConnPoolMetrics(host topology.Host, scope tally.Scope) connPoolMetrics {
+	hostScope := scope.SubScope("connection-pool").Tagged(map[string]string{
+		"host": host.ID(),
+	})
+	return connPoolMetrics{
+		healthy:            hostScope.Gauge("healthy"),
+		connections:        hostScope.Gauge("connections"),
+		failingConnections: hostScope.Gauge("failing-connections"),
+		replacedConns:      hostScope.Counter("replaced-connections"),
+	}
+}
+
 type conn struct {
 	channel xclose.SimpleCloser
 	client  rpc.TChanNode
@@ -89,6 +111,7 @@ func newConnectionPool(host topology.Host, opts Options) connectionPool {
 		host:               host,
 		pool:               make([]conn, 0, opts.MaxConnectionCount()),
 		poolLen:            0,
+		metrics:            newConnPoolMetrics(host, opts.InstrumentOptions().MetricsScope()),
 		connectRand:        rand.NewSource(seed),
 		healthCheckRand:    rand.NewSource(seed + 1),
 		newConn:            globalNewConn,
@@ -116,6 +139,10 @@ func (p *connPool) Open() {
 	connectStutter := p.opts.BackgroundConnectStutter()
 	go p.connectEvery(connectEvery, connectStutter)
 
+	if !p.opts.BackgroundHealthCheckEnabled() {
+		return
+	}
+
 	healthCheckEvery := p.opts.BackgroundHealthCheckInterval()
 	healthCheckStutter := p.opts.BackgroundHealthCheckStutter()
 	go p.healthCheckEvery(healthCheckEvery, healthCheckStutter)
@@ -128,6 +155,20 @@ func (p *connPool) ConnectionCount() int {
 	return int(poolLen)
 }
 
+func (p *connPool) Healthy() bool {
+	if !p.opts.BackgroundHealthCheckEnabled() {
+		return true
+	}
+	p.RLock()
+	poolLen := p.poolLen
+	p.RUnlock()
+	// NB: Connections are counted as failing from their first failed health
+	// check until they either pass one or are replaced, so that a host whose
+	// connections have all gone bad stops taking writes before the
+	// connections reach the fail limit.
+	return poolLen > atomic.LoadInt64(&p.failing)
+}
+
 func (p *connPool) NextClient() (rpc.TChanNode, error) {
 	p.RLock()
 	if p.status != statusOpen {
@@ -159,7 +200,6 @@ func (p *connPool) Close() {
 }
 
 func (p *connPool) connectEvery(interval time.Duration, stutter time.Duration) {
-	log := p.opts.InstrumentOptions().Logger()
 	target := p.opts.MaxConnectionCount()
 
 	for {
@@ -171,34 +211,12 @@ func (p *connPool) connectEvery(interval time.Duration, stutter time.Duration) {
 			return
 		}
 
-		address := p.host.Address()
-
 		var wg sync.WaitGroup
 		for i := 0; i < target-poolLen; i++ {
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
-
-				// Create connection
-				channel, client, err := p.newConn(channelName, address, p.opts)
-				if err != nil {
-					log.Debugf("could not connect to %s: %v", address, err)
-					return
-				}
-
-				// Health check the connection
-				if err := p.healthCheckNewConn(client, p.opts); err != nil {
-					log.Debugf("could not connect to %s: failed health check: %v", address, err)
-					channel.Close()
-					return
-				}
-
-				p.Lock()
-				if p.status == statusOpen {
-					p.pool = append(p.pool, conn{channel, client})
-					p.poolLen = int64(len(p.pool))
-				}
-				p.Unlock()
+				p.connect()
 			}()
 		}
 
@@ -208,6 +226,41 @@ func (p *connPool) connectEvery(interval time.Duration, stutter time.Duration) {
 	}
 }
 
+// connect dials a new connection to the host and adds it to the pool if it
+// passes a health check and the pool is not already full, returning whether
+// the connection was added.
+func (p *connPool) connect() bool {
+	var (
+		log     = p.opts.InstrumentOptions().Logger()
+		address = p.host.Address()
+	)
+
+	// Create connection
+	channel, client, err := p.newConn(channelName, address, p.opts)
+	if err != nil {
+		log.Debugf("could not connect to %s: %v", address, err)
+		return false
+	}
+
+	// Health check the connection
+	if err := p.healthCheckNewConn(client, p.opts); err != nil {
+		log.Debugf("could not connect to %s: failed health check: %v", address, err)
+		channel.Close()
+		return false
+	}
+
+	p.Lock()
+	if p.status != statusOpen || len(p.pool) >= p.opts.MaxConnectionCount() {
+		p.Unlock()
+		channel.Close()
+		return false
+	}
+	p.pool = append(p.pool, conn{channel, client})
+	p.poolLen = int64(len(p.pool))
+	p.Unlock()
+	return true
+}
+
 func (p *connPool) healthCheckEvery(interval time.Duration, stutter time.Duration) {
 	log := p.opts.InstrumentOptions().Logger()
 	nowFn := p.opts.ClockOptions().NowFn()
@@ -241,6 +294,9 @@ func (p *connPool) healthCheckEvery(interval time.Duration, stutter time.Duratio
 					if err := p.healthCheck(client, p.opts); err != nil {
 						checkErr = err
 						failed++
+						if failed == 1 {
+							atomic.AddInt64(&p.failing, 1)
+						}
 						throttleDuration := time.Duration(math.Max(
 							float64(time.Second),
 							p.opts.BackgroundHealthCheckFailThrottleFactor()*
@@ -261,6 +317,7 @@ func (p *connPool) healthCheckEvery(interval time.Duration, stutter time.Duratio
 					p.Lock()
 					if p.status != statusOpen {
 						p.Unlock()
+						atomic.AddInt64(&p.failing, -1)
 						return
 					}
 					var c conn
@@ -274,9 +331,19 @@ func (p *connPool) healthCheckEvery(interval time.Duration, stutter time.Duratio
 						}
 					}
 					p.Unlock()
+					atomic.AddInt64(&p.failing, -1)
 
-					// Close the client's channel
+					// Close the client's channel and proactively re-dial
+					// rather than wait for the next background connect
 					c.channel.Close()
+					if p.connect() {
+						p.metrics.replacedConns.Inc(1)
+					}
+					return
+				}
+
+				if failed > 0 {
+					atomic.AddInt64(&p.failing, -1)
 				}
 			}(p.pool[i].client)
 		}
@@ -284,6 +351,8 @@ func (p *connPool) healthCheckEvery(interval time.Duration, stutter time.Duratio
 
 		wg.Wait()
 
+		p.reportHealth()
+
 		now := nowFn()
 		if !now.Before(deadline) {
 			// Exceeded deadline, start next health check loop
@@ -295,6 +364,16 @@ func (p *connPool) healthCheckEvery(interval time.Duration, stutter time.Duratio
 	}
 }
 
+func (p *connPool) reportHealth() {
+	healthy := 0.0
+	if p.Healthy() {
+		healthy = 1.0
+	}
+	p.metrics.healthy.Update(healthy)
+	p.metrics.connections.Update(float64(p.ConnectionCount()))
+	p.metrics.failingConnections.Update(float64(atomic.LoadInt64(&p.failing)))
+}
+
 func newConn(channelName string, address string, opts Options) (xclose.SimpleCloser, rpc.TChanNode, error) {
 	channel, err := tchannel.NewChannel(channelName, opts.ChannelOptions())
 	if err != nil {
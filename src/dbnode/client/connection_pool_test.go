@@ -33,6 +33,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/uber-go/tally"
 )
 
 const (
@@ -292,6 +293,7 @@ func TestConnectionPoolHealthChecks(t *testing.T) {
 
 	// Verify only 1 connection and its client2
 	assert.Equal(t, 1, conns.ConnectionCount())
+	assert.True(t, conns.Healthy())
 	for i := 0; i < 2; i++ {
 		nextClient, err := conns.NextClient()
 		assert.NoError(t, err)
@@ -306,6 +308,7 @@ func TestConnectionPoolHealthChecks(t *testing.T) {
 	// Wait for health check round to take action
 	failsDoneWg[1].Wait()
 	assert.Equal(t, 0, conns.ConnectionCount())
+	assert.False(t, conns.Healthy())
 	nextClient, err := conns.NextClient()
 	assert.Nil(t, nextClient)
 	assert.Equal(t, errConnectionPoolHasNoConnections, err)
@@ -317,6 +320,132 @@ func TestConnectionPoolHealthChecks(t *testing.T) {
 	assert.Equal(t, errConnectionPoolClosed, err)
 }
 
+func TestConnectionPoolHealthCheckReplacesFailedConnection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	scope := tally.NewTestScope("", nil)
+	opts := newConnectionPoolTestOptions().SetMaxConnectionCount(1)
+	opts = opts.SetInstrumentOptions(opts.InstrumentOptions().SetMetricsScope(scope))
+
+	var (
+		newConnAttempt      int32
+		connectRounds       int32
+		healthyWhileFailing = int32(-1)
+		release             = make(chan struct{})
+		client1             = rpc.TChanNode(rpc.NewMockTChanNode(ctrl))
+		client2             = rpc.TChanNode(rpc.NewMockTChanNode(ctrl))
+	)
+
+	conns := newConnectionPool(h, opts).(*connPool)
+	conns.newConn = func(ch string, addr string, opts Options) (xclose.SimpleCloser, rpc.TChanNode, error) {
+		attempt := atomic.AddInt32(&newConnAttempt, 1)
+		if attempt == 1 {
+			return channelNone, client1, nil
+		} else if attempt == 2 {
+			return channelNone, client2, nil
+		}
+		return nil, nil, fmt.Errorf("spawning only 2 connections")
+	}
+	conns.healthCheckNewConn = func(client rpc.TChanNode, opts Options) error {
+		return nil
+	}
+	conns.healthCheck = func(client rpc.TChanNode, opts Options) error {
+		if client == client1 {
+			return fmt.Errorf("fail client")
+		}
+		return nil
+	}
+	conns.sleepConnect = func(d time.Duration) {
+		// Only allow the first background connect round so that any new
+		// connection must come from the health check replacing client1
+		atomic.AddInt32(&connectRounds, 1)
+		<-release
+	}
+	conns.sleepHealth = func(d time.Duration) {
+		time.Sleep(time.Millisecond)
+	}
+	conns.sleepHealthRetry = func(d time.Duration) {
+		var healthy int32
+		if conns.Healthy() {
+			healthy = 1
+		}
+		atomic.CompareAndSwapInt32(&healthyWhileFailing, -1, healthy)
+	}
+
+	conns.Open()
+
+	for atomic.LoadInt32(&connectRounds) < 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Wait for client1 to be replaced
+	replacedKey := "connection-pool.replaced-connections+host=" + testHostStr
+	for {
+		counter, ok := scope.Snapshot().Counters()[replacedKey]
+		if ok && counter.Value() == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// The only connection was failing so the pool reported itself as
+	// unhealthy before the connection reached the fail limit
+	assert.Equal(t, int32(0), atomic.LoadInt32(&healthyWhileFailing))
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&newConnAttempt))
+	assert.Equal(t, 1, conns.ConnectionCount())
+	assert.True(t, conns.Healthy())
+	nextClient, err := conns.NextClient()
+	assert.NoError(t, err)
+	assert.Equal(t, client2, nextClient)
+
+	conns.Close()
+	close(release)
+}
+
+func TestConnectionPoolHealthChecksDisabled(t *testing.T) {
+	opts := newConnectionPoolTestOptions().
+		SetMaxConnectionCount(1).
+		SetBackgroundHealthCheckEnabled(false)
+
+	var (
+		connectRounds int32
+		healthChecks  int32
+	)
+
+	conns := newConnectionPool(h, opts).(*connPool)
+	conns.newConn = func(ch string, addr string, opts Options) (xclose.SimpleCloser, rpc.TChanNode, error) {
+		return channelNone, nil, nil
+	}
+	conns.healthCheckNewConn = func(client rpc.TChanNode, opts Options) error {
+		return nil
+	}
+	conns.healthCheck = func(client rpc.TChanNode, opts Options) error {
+		atomic.AddInt32(&healthChecks, 1)
+		return fmt.Errorf("fail client")
+	}
+	conns.sleepConnect = func(d time.Duration) {
+		atomic.AddInt32(&connectRounds, 1)
+		time.Sleep(opts.BackgroundHealthCheckInterval())
+	}
+
+	// Without health checking a pool is always considered healthy
+	assert.True(t, conns.Healthy())
+
+	conns.Open()
+
+	for atomic.LoadInt32(&connectRounds) < 3 {
+		time.Sleep(time.Millisecond)
+	}
+
+	assert.Equal(t, int32(0), atomic.LoadInt32(&healthChecks))
+	assert.Equal(t, 1, conns.ConnectionCount())
+	assert.True(t, conns.Healthy())
+
+	conns.Close()
+}
+
 type nullChannel struct{}
 
 func (*nullChannel) Close() {}
@@ -54,6 +54,44 @@ func IsBadRequestError(err error) bool {
 	return false
 }
 
+// IsReadOnlyError determines if the error is a node refusing a write as it is
+// read only, which is retryable as other replicas of the node accept the write
+func IsReadOnlyError(err error) bool {
+	for err != nil {
+		if e, ok := err.(*rpc.Error); ok && tterrors.IsReadOnlyError(e) {
+			return true
+		}
+		err = xerrors.InnerError(err)
+	}
+	return false
+}
+
+// IsResourceExhaustedError determines if the error is a node refusing a
+// write as a resource is exhausted, which is not retried so that retries do
+// not add to the load of an already overloaded node
+func IsResourceExhaustedError(err error) bool {
+	for err != nil {
+		if e, ok := err.(*rpc.Error); ok && tterrors.IsResourceExhaustedError(e) {
+			return true
+		}
+		err = xerrors.InnerError(err)
+	}
+	return false
+}
+
+// IsShardUnavailableError determines if the error is a node refusing a
+// request as the shard it targets is unavailable on the node, which is
+// retryable as other replicas of the shard serve the request
+func IsShardUnavailableError(err error) bool {
+	for err != nil {
+		if e, ok := err.(*rpc.Error); ok && tterrors.IsShardUnavailableError(e) {
+			return true
+		}
+		err = xerrors.InnerError(err)
+	}
+	return false
+}
+
 // NumResponded returns how many nodes responded for a given error
 func NumResponded(err error) int {
 	for err != nil {
@@ -152,6 +190,47 @@ func (e consistencyResultErr) numSuccess() int {
 	return e.success
 }
 
+// BatchError is returned by batch writes when one or more elements of the
+// batch failed to be written, the remaining elements were written.
+type BatchError interface {
+	error
+
+	// Errors returns the errors of the elements that failed ordered by
+	// their index in the batch.
+	Errors() []BatchElementError
+}
+
+// BatchElementError is the error of a single element of a batch write.
+type BatchElementError struct {
+	// Index is the index of the element in the batch.
+	Index int
+	// Err is the error the element failed with.
+	Err error
+	// Retryable is whether writing the element again may succeed, elements
+	// rejected as bad requests or by nodes out of resources are not.
+	Retryable bool
+}
+
+type batchError struct {
+	size int
+	errs []BatchElementError
+}
+
+func newBatchError(size int, errs []BatchElementError) BatchError {
+	return batchError{size: size, errs: errs}
+}
+
+func (e batchError) Error() string {
+	first := e.errs[0]
+	return fmt.Sprintf(
+		"failed to write %d of %d batch elements, first error at index %d: %v",
+		len(e.errs), e.size, first.Index, first.Err)
+}
+
+func (e batchError) Errors() []BatchElementError {
+	return e.errs
+}
+
 type syncAbortableErrorsMap struct {
 	sync.RWMutex
 	errors     map[int]error
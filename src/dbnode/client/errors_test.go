@@ -54,3 +54,64 @@ func TestConsistencyResultError(t *testing.T) {
 	assert.Equal(t, 1, NumSuccess(err))
 	assert.Equal(t, 2, NumError(err))
 }
+
+func TestReadOnlyError(t *testing.T) {
+	topErr := &rpc.Error{
+		Type: rpc.ErrorType_READ_ONLY,
+	}
+
+	err := consistencyResultErr{
+		level:       topology.ReadConsistencyLevelMajority,
+		success:     2,
+		enqueued:    3,
+		responded:   3,
+		topLevelErr: topErr,
+		errs:        []error{topErr},
+	}
+
+	assert.True(t, IsReadOnlyError(err))
+	assert.False(t, IsBadRequestError(err))
+	assert.False(t, IsInternalServerError(err))
+	assert.False(t, IsReadOnlyError(fmt.Errorf("another error")))
+}
+
+func TestResourceExhaustedError(t *testing.T) {
+	topErr := &rpc.Error{
+		Type: rpc.ErrorType_RESOURCE_EXHAUSTED,
+	}
+
+	err := consistencyResultErr{
+		level:       topology.ReadConsistencyLevelMajority,
+		success:     1,
+		enqueued:    3,
+		responded:   3,
+		topLevelErr: topErr,
+		errs:        []error{topErr, fmt.Errorf("another error")},
+	}
+
+	assert.True(t, IsResourceExhaustedError(err))
+	assert.False(t, IsBadRequestError(err))
+	assert.False(t, IsInternalServerError(err))
+	assert.False(t, IsResourceExhaustedError(fmt.Errorf("another error")))
+}
+
+func TestShardUnavailableError(t *testing.T) {
+	topErr := &rpc.Error{
+		Type: rpc.ErrorType_SHARD_UNAVAILABLE,
+	}
+
+	err := consistencyResultErr{
+		level:       topology.ReadConsistencyLevelMajority,
+		success:     2,
+		enqueued:    3,
+		responded:   3,
+		topLevelErr: topErr,
+		errs:        []error{topErr},
+	}
+
+	assert.True(t, IsShardUnavailableError(err))
+	assert.False(t, IsBadRequestError(err))
+	assert.False(t, IsResourceExhaustedError(err))
+	assert.False(t, IsInternalServerError(err))
+	assert.False(t, IsShardUnavailableError(fmt.Errorf("another error")))
+}
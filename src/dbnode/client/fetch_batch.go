@@ -21,6 +21,8 @@
 package client
 
 import (
+	"time"
+
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	"github.com/m3db/m3x/checked"
 	"github.com/m3db/m3x/pool"
@@ -31,6 +33,15 @@ type fetchBatchOp struct {
 	request       rpc.FetchBatchRawRequest
 	completionFns []completionFn
 	finalizer     fetchBatchOpFinalizer
+
+	// deadline is the deadline of the fetch the op is part of, the time
+	// remaining until it is propagated to the node when the op is sent.
+	deadline     time.Time
+	timeoutNanos int64
+
+	// cancellation is set for the ops of speculative fetches so they can be
+	// cancelled once no longer needed.
+	cancellation *fetchCancellation
 }
 
 func (f *fetchBatchOp) reset() {
@@ -38,6 +49,7 @@ func (f *fetchBatchOp) reset() {
 	f.request.RangeStart = 0
 	f.request.RangeEnd = 0
 	f.request.NameSpace = nil
+	f.request.TimeoutNanos = nil
 	for i := range f.request.Ids {
 		f.request.Ids[i] = nil
 	}
@@ -46,6 +58,9 @@ func (f *fetchBatchOp) reset() {
 		f.completionFns[i] = nil
 	}
 	f.completionFns = f.completionFns[:0]
+	f.deadline = time.Time{}
+	f.timeoutNanos = 0
+	f.cancellation = nil
 	f.DecWrites()
 }
 
@@ -0,0 +1,366 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+	"github.com/m3db/m3/src/dbnode/topology"
+)
+
+var errFetchSpeculationCancelled = errors.New(
+	"speculative fetch cancelled as the read consistency level was satisfied")
+
+type fetchSpeculationState int
+
+const (
+	fetchSpeculationPending fetchSpeculationState = iota
+	fetchSpeculationSent
+	fetchSpeculationCancelled
+)
+
+// fetchRoute is a replica that a series can be fetched from.
+type fetchRoute struct {
+	hostIdx int
+	host    topology.Host
+}
+
+// fetchSpeculation is a series that was only fetched from a single replica
+// and that may be fetched from the remaining replicas if the first replica
+// errors or does not respond within the speculative retry delay.
+type fetchSpeculation struct {
+	sync.Mutex
+
+	state         fetchSpeculationState
+	done          bool
+	cancellations []*fetchCancellation
+	id            []byte
+	routes        []fetchRoute
+	completionFn  completionFn
+	onSend        func(n int32)
+	onCancel      func()
+}
+
+// send marks the remaining replicas as to be fetched from, it returns false
+// if they were already sent to or the fetch has already completed.
+func (f *fetchSpeculation) send() bool {
+	f.Lock()
+	defer f.Unlock()
+	if f.state != fetchSpeculationPending {
+		return false
+	}
+	f.state = fetchSpeculationSent
+	f.onSend(int32(len(f.routes)))
+	return true
+}
+
+// cancel prevents the remaining replicas from being fetched from, it returns
+// whether they were already sent to in which case the fetches from them are
+// cancelled once no other series still needs them.
+func (f *fetchSpeculation) cancel() bool {
+	f.Lock()
+	switch f.state {
+	case fetchSpeculationPending:
+		f.state = fetchSpeculationCancelled
+		f.onCancel()
+		f.Unlock()
+		return false
+	case fetchSpeculationSent:
+		f.done = true
+		cancellations := f.cancellations
+		f.cancellations = nil
+		f.Unlock()
+		for _, c := range cancellations {
+			c.release()
+		}
+		return true
+	}
+	f.Unlock()
+	return false
+}
+
+// register adds the cancellation of an op fetching from one of the remaining
+// replicas, unless the fetch has already completed.
+func (f *fetchSpeculation) register(c *fetchCancellation) {
+	f.Lock()
+	defer f.Unlock()
+	if f.done {
+		return
+	}
+	c.retain()
+	f.cancellations = append(f.cancellations, c)
+}
+
+// fetchCancellation cancels a speculative fetch op once every series it
+// fetches has completed, whether the op is still enqueued or in flight.
+type fetchCancellation struct {
+	sync.Mutex
+
+	refs      int
+	cancelled bool
+	cancelFn  func()
+}
+
+// newFetchCancellation returns a cancellation holding a ref for the sender
+// of the op, which must be released once every series has been added.
+func newFetchCancellation() *fetchCancellation {
+	return &fetchCancellation{refs: 1}
+}
+
+func (c *fetchCancellation) retain() {
+	c.Lock()
+	c.refs++
+	c.Unlock()
+}
+
+func (c *fetchCancellation) release() {
+	c.Lock()
+	c.refs--
+	if c.refs > 0 || c.cancelled {
+		c.Unlock()
+		return
+	}
+	c.cancelled = true
+	cancelFn := c.cancelFn
+	c.Unlock()
+
+	if cancelFn != nil {
+		cancelFn()
+	}
+}
+
+func (c *fetchCancellation) isCancelled() bool {
+	c.Lock()
+	defer c.Unlock()
+	return c.cancelled
+}
+
+// start sets the fn that cancels the in flight request of the op, it returns
+// false if the op was already cancelled and should not be sent.
+func (c *fetchCancellation) start(cancelFn func()) bool {
+	c.Lock()
+	defer c.Unlock()
+	if c.cancelled {
+		return false
+	}
+	c.cancelFn = cancelFn
+	return true
+}
+
+type fetchHostLatency struct {
+	resolved bool
+	ok       bool
+	latency  time.Duration
+}
+
+// fetchSpeculations tracks the speculations of a single fetch attempt
+// grouped by the host of the replica first fetched from.
+type fetchSpeculations struct {
+	session    *session
+	queues     []hostQueue
+	namespace  []byte
+	rangeStart int64
+	rangeEnd   int64
+	start      time.Time
+	deadline   time.Time
+	latencies  []fetchHostLatency
+	byHostIdx  [][]*fetchSpeculation
+	timers     []*time.Timer
+}
+
+func newFetchSpeculations(
+	s *session,
+	queues []hostQueue,
+	namespace []byte,
+	rangeStart, rangeEnd int64,
+	deadline time.Time,
+) *fetchSpeculations {
+	return &fetchSpeculations{
+		session:    s,
+		queues:     queues,
+		namespace:  namespace,
+		rangeStart: rangeStart,
+		rangeEnd:   rangeEnd,
+		start:      s.nowFn(),
+		deadline:   deadline,
+		latencies:  make([]fetchHostLatency, len(queues)),
+		byHostIdx:  make([][]*fetchSpeculation, len(queues)),
+	}
+}
+
+func (f *fetchSpeculations) latency(route fetchRoute) (time.Duration, bool) {
+	l := &f.latencies[route.hostIdx]
+	if !l.resolved {
+		l.latency, l.ok = f.session.hostLatencies.quantile(route.host,
+			f.session.readSpeculativeRetryPercentile)
+		l.resolved = true
+	}
+	return l.latency, l.ok
+}
+
+// primary returns the index of the route with the best recent latency.
+func (f *fetchSpeculations) primary(routes []fetchRoute) int {
+	var (
+		best        = 0
+		bestLatency time.Duration
+	)
+	for i, route := range routes {
+		latency, ok := f.latency(route)
+		if !ok {
+			// NB: prefer hosts without recent samples so that every
+			// replica is eventually measured.
+			return i
+		}
+		if i == 0 || latency < bestLatency {
+			best, bestLatency = i, latency
+		}
+	}
+	return best
+}
+
+func (f *fetchSpeculations) add(primary fetchRoute, speculation *fetchSpeculation) {
+	f.byHostIdx[primary.hostIdx] = append(f.byHostIdx[primary.hostIdx], speculation)
+}
+
+// timedCompletionFn wraps a completion fn to record the latency of the
+// replica responding.
+func (f *fetchSpeculations) timedCompletionFn(
+	host topology.Host,
+	start time.Time,
+	fn completionFn,
+) completionFn {
+	return func(result interface{}, err error) {
+		if err == errFetchSpeculationCancelled {
+			// NB: the host did not get to respond so its latency is unknown.
+			fn(result, err)
+			return
+		}
+		latency := f.session.nowFn().Sub(start)
+		if err != nil {
+			// NB: record errors as taking the full request timeout so that
+			// hosts failing fast are not preferred.
+			latency = time.Duration(f.session.fetchTimeoutNanos)
+		}
+		f.session.hostLatencies.record(host, latency)
+		fn(result, err)
+	}
+}
+
+// startTimers starts a timer for each host that was first fetched from,
+// fetching from the remaining replicas if it has not responded within the
+// speculative retry delay.
+func (f *fetchSpeculations) startTimers() {
+	for hostIdx, speculations := range f.byHostIdx {
+		if len(speculations) == 0 {
+			continue
+		}
+
+		delay := f.session.readSpeculativeRetryMaxDelay
+		if l := f.latencies[hostIdx]; l.ok && l.latency < delay {
+			delay = l.latency
+		}
+
+		speculations := speculations
+		f.timers = append(f.timers, time.AfterFunc(delay, func() {
+			sends := make([]*fetchSpeculation, 0, len(speculations))
+			for _, speculation := range speculations {
+				if speculation.send() {
+					sends = append(sends, speculation)
+				}
+			}
+			if len(sends) > 0 {
+				f.send(sends)
+			}
+		}))
+	}
+}
+
+func (f *fetchSpeculations) stopTimers() {
+	for _, timer := range f.timers {
+		timer.Stop()
+	}
+}
+
+// send fetches the series of the speculations from their remaining
+// replicas, the speculations must have been marked as sent. The ops are
+// cancelled, whether enqueued or in flight, once every series they fetch
+// has satisfied its read consistency level.
+func (f *fetchSpeculations) send(speculations []*fetchSpeculation) {
+	s := f.session
+	s.metrics.fetchSpeculativeSent.Inc(int64(len(speculations)))
+
+	s.state.RLock()
+	defer s.state.RUnlock()
+
+	if s.state.status != statusOpen {
+		for _, speculation := range speculations {
+			for range speculation.routes {
+				speculation.completionFn(nil, errSessionStatusNotOpen)
+			}
+		}
+		return
+	}
+
+	var (
+		start     = s.nowFn()
+		opsByHost = make(map[int][]*fetchBatchOp)
+	)
+	for _, speculation := range speculations {
+		for _, route := range speculation.routes {
+			ops := opsByHost[route.hostIdx]
+
+			var op *fetchBatchOp
+			if len(ops) > 0 {
+				op = ops[len(ops)-1]
+			}
+			if op == nil || op.Size() >= s.fetchBatchSize {
+				op = s.pools.fetchBatchOp.Get()
+				op.IncRef()
+				opsByHost[route.hostIdx] = append(ops, op)
+				op.request.RangeStart = f.rangeStart
+				op.request.RangeEnd = f.rangeEnd
+				op.request.RangeTimeType = rpc.TimeType_UNIX_NANOSECONDS
+				op.deadline = f.deadline
+				op.cancellation = newFetchCancellation()
+			}
+
+			speculation.register(op.cancellation)
+			op.append(f.namespace, speculation.id,
+				f.timedCompletionFn(route.host, start, speculation.completionFn))
+		}
+	}
+
+	for hostIdx, ops := range opsByHost {
+		for _, op := range ops {
+			// NB: release the ref held while adding series, the op is cancelled
+			// straight away if every series it fetches has already completed.
+			op.cancellation.release()
+			// Passing ownership of the op itself to the host queue
+			op.DecRef()
+			if err := f.queues[hostIdx].Enqueue(op); err != nil {
+				op.completeAll(nil, err)
+			}
+		}
+	}
+}
@@ -165,6 +165,19 @@ func (f *fetchState) asEncodingSeriesIterators(pools fetchTaggedPools) (encoding
 	return f.tagResultAccumulator.AsEncodingSeriesIterators(limit, pools)
 }
 
+// nextPageToken returns a copy of the token of the page following the fetched
+// page, it is nil once the last page has been fetched.
+func (f *fetchState) nextPageToken() []byte {
+	f.Lock()
+	defer f.Unlock()
+
+	token := f.tagResultAccumulator.PageToken()
+	if token == nil {
+		return nil
+	}
+	return append([]byte(nil), token...)
+}
+
 // NB(prateek): this is backed by the sessionPools struct, but we're restricting it to a narrow
 // interface to force the fetchTagged code-paths to be explicit about the pools they need access
 // to. The alternative is to either expose the sessionPools struct (which is a worse abstraction),
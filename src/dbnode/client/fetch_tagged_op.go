@@ -21,6 +21,8 @@
 package client
 
 import (
+	"time"
+
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	"github.com/m3db/m3x/pool"
 )
@@ -34,6 +36,15 @@ type fetchTaggedOp struct {
 	request      rpc.FetchTaggedRequest
 	completionFn completionFn
 
+	// NB: a positive page size fetches a single page of the results
+	// following the page token rather than every result.
+	pageSize  int
+	pageToken []byte
+
+	// deadline is the deadline of the fetch, the time remaining until it is
+	// propagated to each node when the op is sent.
+	deadline time.Time
+
 	pool fetchTaggedOpPool
 }
 
@@ -45,6 +56,19 @@ func (f *fetchTaggedOp) update(req rpc.FetchTaggedRequest, fn completionFn) {
 	f.completionFn = fn
 }
 
+func (f *fetchTaggedOp) updatePage(size int, token []byte) {
+	f.pageSize = size
+	f.pageToken = token
+}
+
+func (f *fetchTaggedOp) pageRequest(req *rpc.FetchTaggedRequest) *rpc.FetchTaggedPageRequest {
+	return &rpc.FetchTaggedPageRequest{
+		Request:   req,
+		PageSize:  int64(f.pageSize),
+		PageToken: f.pageToken,
+	}
+}
+
 func (f *fetchTaggedOp) requestLimit(defaultValue int) int {
 	if f.request.Limit == nil {
 		return defaultValue
@@ -55,6 +79,9 @@ func (f *fetchTaggedOp) requestLimit(defaultValue int) int {
 func (f *fetchTaggedOp) close() {
 	f.completionFn = nil
 	f.request = fetchTaggedOpRequestZeroed
+	f.pageSize = 0
+	f.pageToken = nil
+	f.deadline = time.Time{}
 	// return to pool
 	if f.pool == nil {
 		return
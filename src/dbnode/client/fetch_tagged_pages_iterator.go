@@ -0,0 +1,112 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/storage/index"
+	"github.com/m3db/m3x/ident"
+)
+
+type fetchTaggedPagesIterator struct {
+	session *session
+	ns      ident.ID
+	query   index.Query
+	opts    index.QueryOptions
+
+	current    encoding.SeriesIterators
+	exhaustive bool
+	done       bool
+	err        error
+}
+
+func newFetchTaggedPagesIterator(
+	session *session,
+	ns ident.ID,
+	query index.Query,
+	opts index.QueryOptions,
+) *fetchTaggedPagesIterator {
+	return &fetchTaggedPagesIterator{
+		session: session,
+		ns:      ns,
+		query:   query,
+		opts:    opts,
+	}
+}
+
+func (it *fetchTaggedPagesIterator) Next() bool {
+	if it.done || it.err != nil {
+		return false
+	}
+	it.closeCurrent()
+
+	var (
+		iters      encoding.SeriesIterators
+		exhaustive bool
+		token      []byte
+	)
+	it.err = it.session.fetchRetrier.Attempt(func() error {
+		var err error
+		iters, exhaustive, token, err = it.session.fetchTaggedPageAttempt(
+			it.ns, it.query, it.opts)
+		return err
+	})
+	if it.err != nil {
+		return false
+	}
+
+	// NB: only the page being iterated is held, the page following it is not
+	// requested until Next is called again.
+	it.opts.PageToken = token
+	it.done = token == nil
+	if it.done && iters.Len() == 0 {
+		iters.Close()
+		return false
+	}
+	it.current, it.exhaustive = iters, exhaustive
+	return true
+}
+
+func (it *fetchTaggedPagesIterator) Current() (encoding.SeriesIterators, bool) {
+	return it.current, it.exhaustive
+}
+
+func (it *fetchTaggedPagesIterator) Err() error {
+	return it.err
+}
+
+func (it *fetchTaggedPagesIterator) Close() {
+	it.closeCurrent()
+	it.done = true
+	if it.ns != nil {
+		it.ns.Finalize()
+		it.ns = nil
+	}
+}
+
+func (it *fetchTaggedPagesIterator) closeCurrent() {
+	if it.current == nil {
+		return
+	}
+	it.current.Close()
+	it.current = nil
+	it.exhaustive = false
+}
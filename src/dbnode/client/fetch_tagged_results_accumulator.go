@@ -34,8 +34,9 @@ import (
 )
 
 type fetchTaggedResultAccumulatorOpts struct {
-	host     topology.Host
-	response *rpc.FetchTaggedResult_
+	host      topology.Host
+	response  *rpc.FetchTaggedResult_
+	pageToken []byte
 }
 
 func newFetchTaggedResultAccumulator() fetchTaggedResultAccumulator {
@@ -56,6 +57,11 @@ type fetchTaggedResultAccumulator struct {
 	responses  fetchTaggedIDResults
 	exhaustive bool
 
+	// NB(paging): each host returns the page of its own series following the
+	// requested token, the lowest token returned bounds the IDs every host has
+	// included in its page so the IDs past it are left to the next page.
+	pageToken []byte
+
 	startTime        time.Time
 	endTime          time.Time
 	majority         int
@@ -107,6 +113,10 @@ func (accum *fetchTaggedResultAccumulator) Add(
 		for _, elem := range response.Elements {
 			accum.responses = append(accum.responses, elem)
 		}
+		if token := opts.pageToken; token != nil &&
+			(accum.pageToken == nil || bytes.Compare(token, accum.pageToken) < 0) {
+			accum.pageToken = token
+		}
 	}
 
 	// FOLLOWUP(prateek): once we transmit the shards successfully satisfied by a response, the
@@ -181,6 +191,7 @@ func (accum *fetchTaggedResultAccumulator) Clear() {
 	accum.startTime, accum.endTime = time.Time{}, time.Time{}
 	accum.topoMap = nil
 	accum.exhaustive = true
+	accum.pageToken = nil
 }
 
 func (accum *fetchTaggedResultAccumulator) Reset(
@@ -212,6 +223,31 @@ func (accum *fetchTaggedResultAccumulator) Reset(
 	}
 }
 
+// PageToken returns the token of the page following the accumulated page,
+// it is nil once every host has returned its last page.
+func (accum *fetchTaggedResultAccumulator) PageToken() []byte {
+	return accum.pageToken
+}
+
+// sortResponses sorts the responses by ID, dropping those following the
+// page token as they're returned with the next page.
+func (accum *fetchTaggedResultAccumulator) sortResponses() {
+	results := fetchTaggedIDResultsSortedByID(accum.responses)
+	sort.Sort(results)
+	accum.responses = fetchTaggedIDResults(results)
+	if accum.pageToken == nil {
+		return
+	}
+
+	n := sort.Search(len(accum.responses), func(i int) bool {
+		return bytes.Compare(accum.responses[i].ID, accum.pageToken) > 0
+	})
+	for i := n; i < len(accum.responses); i++ {
+		accum.responses[i] = nil
+	}
+	accum.responses = accum.responses[:n]
+}
+
 func (accum *fetchTaggedResultAccumulator) sliceResponsesAsSeriesIter(
 	pools fetchTaggedPools,
 	elems fetchTaggedIDResults,
@@ -253,9 +289,7 @@ func (accum *fetchTaggedResultAccumulator) sliceResponsesAsSeriesIter(
 func (accum *fetchTaggedResultAccumulator) AsEncodingSeriesIterators(
 	limit int, pools fetchTaggedPools,
 ) (encoding.SeriesIterators, bool, error) {
-	results := fetchTaggedIDResultsSortedByID(accum.responses)
-	sort.Sort(results)
-	accum.responses = fetchTaggedIDResults(results)
+	accum.sortResponses()
 
 	numElements := 0
 	accum.responses.forEachID(func(_ fetchTaggedIDResults, _ bool) bool {
@@ -288,9 +322,7 @@ func (accum *fetchTaggedResultAccumulator) AsTaggedIDsIterator(
 		count     = 0
 		moreElems = false
 	)
-	results := fetchTaggedIDResultsSortedByID(accum.responses)
-	sort.Sort(results)
-	accum.responses = fetchTaggedIDResults(results)
+	accum.sortResponses()
 	accum.responses.forEachID(func(elems fetchTaggedIDResults, hasMore bool) bool {
 		iter.addBacking(elems[0].NameSpace, elems[0].ID, elems[0].EncodedTags)
 		count++
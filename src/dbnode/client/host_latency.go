@@ -0,0 +1,163 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/clock"
+	"github.com/m3db/m3/src/dbnode/topology"
+)
+
+const (
+	// hostLatencyBuckets is the number of exponential latency buckets, the
+	// last bucket is unbounded.
+	hostLatencyBuckets = 20
+
+	// hostLatencyMinBucket is the upper bound of the first latency bucket,
+	// each subsequent bucket doubles the upper bound of the last.
+	hostLatencyMinBucket = 100 * time.Microsecond
+
+	// hostLatencyDecayInterval is the interval at which recorded latency
+	// samples lose half of their weight.
+	hostLatencyDecayInterval = 10 * time.Second
+
+	// hostLatencyMinWeight is the minimum total weight of samples required
+	// before a quantile is reported for a host.
+	hostLatencyMinWeight = 1.0
+)
+
+// hostLatencyHistogram is a small exponentially bucketed histogram of
+// request latencies whose samples decay so it reflects recent latencies.
+type hostLatencyHistogram struct {
+	sync.Mutex
+
+	buckets   [hostLatencyBuckets]float64
+	total     float64
+	lastDecay time.Time
+}
+
+func (h *hostLatencyHistogram) record(latency time.Duration, now time.Time) {
+	idx := 0
+	for bound := hostLatencyMinBucket; latency > bound && idx < hostLatencyBuckets-1; bound *= 2 {
+		idx++
+	}
+
+	h.Lock()
+	h.decayWithLock(now)
+	h.buckets[idx]++
+	h.total++
+	h.Unlock()
+}
+
+// quantile returns the upper bound of the bucket containing the quantile q,
+// it returns false if there are not enough recent samples.
+func (h *hostLatencyHistogram) quantile(q float64, now time.Time) (time.Duration, bool) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.decayWithLock(now)
+	if h.total < hostLatencyMinWeight {
+		return 0, false
+	}
+
+	var (
+		target     = q * h.total
+		cumulative float64
+		bound      = hostLatencyMinBucket
+	)
+	for idx := 0; idx < hostLatencyBuckets-1; idx++ {
+		cumulative += h.buckets[idx]
+		if cumulative >= target {
+			return bound, true
+		}
+		bound *= 2
+	}
+	return bound, true
+}
+
+func (h *hostLatencyHistogram) decayWithLock(now time.Time) {
+	if h.lastDecay.IsZero() {
+		h.lastDecay = now
+		return
+	}
+
+	intervals := int64(now.Sub(h.lastDecay) / hostLatencyDecayInterval)
+	if intervals <= 0 {
+		return
+	}
+
+	factor := math.Pow(0.5, float64(intervals))
+	h.total = 0
+	for idx := range h.buckets {
+		h.buckets[idx] *= factor
+		h.total += h.buckets[idx]
+	}
+	h.lastDecay = h.lastDecay.Add(time.Duration(intervals) * hostLatencyDecayInterval)
+}
+
+// hostLatencies tracks recent request latencies for each host.
+type hostLatencies struct {
+	sync.RWMutex
+
+	nowFn  clock.NowFn
+	byHost map[string]*hostLatencyHistogram
+}
+
+func newHostLatencies(nowFn clock.NowFn) *hostLatencies {
+	return &hostLatencies{
+		nowFn:  nowFn,
+		byHost: make(map[string]*hostLatencyHistogram),
+	}
+}
+
+func (l *hostLatencies) record(host topology.Host, latency time.Duration) {
+	l.RLock()
+	h, ok := l.byHost[host.ID()]
+	l.RUnlock()
+
+	if !ok {
+		l.Lock()
+		h, ok = l.byHost[host.ID()]
+		if !ok {
+			h = &hostLatencyHistogram{}
+			l.byHost[host.ID()] = h
+		}
+		l.Unlock()
+	}
+
+	h.record(latency, l.nowFn())
+}
+
+// quantile returns the recent latency quantile q for a host, it returns
+// false if the host has no recent latency samples.
+func (l *hostLatencies) quantile(host topology.Host, q float64) (time.Duration, bool) {
+	l.RLock()
+	h, ok := l.byHost[host.ID()]
+	l.RUnlock()
+
+	if !ok {
+		return 0, false
+	}
+	return h.quantile(q, l.nowFn())
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/topology"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostLatencyHistogramQuantile(t *testing.T) {
+	var (
+		h   hostLatencyHistogram
+		now = time.Now()
+	)
+
+	_, ok := h.quantile(0.5, now)
+	assert.False(t, ok)
+
+	for i := 0; i < 90; i++ {
+		h.record(time.Millisecond, now)
+	}
+	for i := 0; i < 10; i++ {
+		h.record(time.Second, now)
+	}
+
+	median, ok := h.quantile(0.5, now)
+	require.True(t, ok)
+	assert.Equal(t, 1600*time.Microsecond, median)
+
+	p99, ok := h.quantile(0.99, now)
+	require.True(t, ok)
+	assert.True(t, p99 >= time.Second)
+	assert.True(t, p99 < 2*time.Second)
+}
+
+func TestHostLatencyHistogramDecay(t *testing.T) {
+	var (
+		h   hostLatencyHistogram
+		now = time.Now()
+	)
+
+	for i := 0; i < 100; i++ {
+		h.record(time.Second, now)
+	}
+
+	// After the old samples have mostly decayed recent samples dominate.
+	now = now.Add(10 * hostLatencyDecayInterval)
+	for i := 0; i < 10; i++ {
+		h.record(time.Millisecond, now)
+	}
+
+	median, ok := h.quantile(0.5, now)
+	require.True(t, ok)
+	assert.Equal(t, 1600*time.Microsecond, median)
+
+	// Without new samples the histogram eventually reports no latency.
+	now = now.Add(10 * hostLatencyDecayInterval)
+	_, ok = h.quantile(0.5, now)
+	assert.False(t, ok)
+}
+
+func TestFetchSpeculationsPrimaryPrefersUnmeasuredHosts(t *testing.T) {
+	opts := newSessionTestOptions()
+	s, err := newSession(opts)
+	require.NoError(t, err)
+	session := s.(*session)
+
+	routes := make([]fetchRoute, 3)
+	for i := range routes {
+		routes[i] = fetchRoute{
+			hostIdx: i,
+			host:    topology.NewHost(testHostName(i), testHostName(i)),
+		}
+	}
+	session.hostLatencies.record(routes[0].host, time.Second)
+	session.hostLatencies.record(routes[1].host, time.Millisecond)
+
+	speculations := newFetchSpeculations(session, make([]hostQueue, 3), nil, 0, 0, time.Time{})
+	assert.Equal(t, 2, speculations.primary(routes))
+
+	session.hostLatencies.record(routes[2].host, 10*time.Millisecond)
+	speculations = newFetchSpeculations(session, make([]hostQueue, 3), nil, 0, 0, time.Time{})
+	assert.Equal(t, 1, speculations.primary(routes))
+}
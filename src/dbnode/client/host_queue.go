@@ -237,8 +237,8 @@ func (q *queue) drain() {
 				idx := currTaggedWriteOpsByNamespace.indexOf(namespace)
 				if idx == -1 {
 					value := namespaceWriteTaggedBatchOps{
-						namespace:                                  namespace,
-						opsArrayPool:                               q.opsArrayPool,
+						namespace:    namespace,
+						opsArrayPool: q.opsArrayPool,
 						writeTaggedBatchRawRequestElementArrayPool: q.writeTaggedBatchRawRequestElementArrayPool,
 					}
 					idx = len(currTaggedWriteOpsByNamespace)
@@ -259,6 +259,8 @@ func (q *queue) drain() {
 				q.asyncFetchTagged(v)
 			case *truncateOp:
 				q.asyncTruncate(v)
+			case *aggregateTagValuesOp:
+				q.asyncAggregateTagValues(v)
 			default:
 				completionFn := ops[i].CompletionFn()
 				completionFn(nil, errQueueUnknownOperation(q.host.ID()))
@@ -446,9 +448,30 @@ func (q *queue) asyncFetch(op *fetchBatchOp) {
 			return
 		}
 
-		ctx, _ := thrift.NewContext(q.opts.FetchRequestTimeout())
+		timeout, ok := q.fetchTimeout(op.deadline)
+		if !ok {
+			op.completeAll(nil, errQueueFetchDeadlineExceeded(q.host.ID()))
+			cleanup()
+			return
+		}
+		if !op.deadline.IsZero() {
+			// Propagate the remaining time so the node can abandon the fetch
+			op.timeoutNanos = int64(timeout)
+			op.request.TimeoutNanos = &op.timeoutNanos
+		}
+
+		ctx, cancel := thrift.NewContext(timeout)
+		if op.cancellation != nil && !op.cancellation.start(cancel) {
+			// Speculative fetch no longer needed
+			op.completeAll(nil, errFetchSpeculationCancelled)
+			cleanup()
+			return
+		}
 		result, err := client.FetchBatchRaw(ctx, &op.request)
 		if err != nil {
+			if op.cancellation != nil && op.cancellation.isCancelled() {
+				err = errFetchSpeculationCancelled
+			}
 			op.completeAll(nil, err)
 			cleanup()
 			return
@@ -489,8 +512,41 @@ func (q *queue) asyncFetchTagged(op *fetchTaggedOp) {
 			return
 		}
 
-		ctx, _ := thrift.NewContext(q.opts.FetchRequestTimeout())
-		result, err := client.FetchTagged(ctx, &op.request)
+		timeout, ok := q.fetchTimeout(op.deadline)
+		if !ok {
+			op.CompletionFn()(fetchTaggedResultAccumulatorOpts{host: q.host},
+				errQueueFetchDeadlineExceeded(q.host.ID()))
+			cleanup()
+			return
+		}
+
+		// NB: the op is shared by every host queue so the remaining time is
+		// propagated to the node on a copy of the request.
+		req := op.request
+		if !op.deadline.IsZero() {
+			timeoutNanos := int64(timeout)
+			req.TimeoutNanos = &timeoutNanos
+		}
+
+		ctx, _ := thrift.NewContext(timeout)
+		if op.pageSize > 0 {
+			result, err := client.FetchTaggedPage(ctx, op.pageRequest(&req))
+			if err != nil {
+				op.CompletionFn()(fetchTaggedResultAccumulatorOpts{host: q.host}, err)
+				cleanup()
+				return
+			}
+
+			op.CompletionFn()(fetchTaggedResultAccumulatorOpts{
+				host:      q.host,
+				response:  result.Result_,
+				pageToken: result.PageToken,
+			}, err)
+			cleanup()
+			return
+		}
+
+		result, err := client.FetchTagged(ctx, &req)
 		if err != nil {
 			op.CompletionFn()(fetchTaggedResultAccumulatorOpts{host: q.host}, err)
 			cleanup()
@@ -505,6 +561,17 @@ func (q *queue) asyncFetchTagged(op *fetchTaggedOp) {
 	})
 }
 
+// fetchTimeout returns the time remaining until the deadline of a fetch, or
+// the fetch request timeout if the fetch has no deadline, and false once the
+// deadline has passed.
+func (q *queue) fetchTimeout(deadline time.Time) (time.Duration, bool) {
+	if deadline.IsZero() {
+		return q.opts.FetchRequestTimeout(), true
+	}
+	timeout := deadline.Sub(q.nowFn())
+	return timeout, timeout > 0
+}
+
 func (q *queue) asyncTruncate(op *truncateOp) {
 	q.Add(1)
 
@@ -530,6 +597,31 @@ func (q *queue) asyncTruncate(op *truncateOp) {
 	})
 }
 
+func (q *queue) asyncAggregateTagValues(op *aggregateTagValuesOp) {
+	q.Add(1)
+
+	q.workerPool.Go(func() {
+		cleanup := q.Done
+
+		client, err := q.connPool.NextClient()
+		if err != nil {
+			// No client available
+			op.completionFn(nil, err)
+			cleanup()
+			return
+		}
+
+		ctx, _ := thrift.NewContext(q.opts.FetchRequestTimeout())
+		if res, err := client.AggregateTagValues(ctx, &op.request); err != nil {
+			op.completionFn(nil, err)
+		} else {
+			op.completionFn(res, nil)
+		}
+
+		cleanup()
+	})
+}
+
 func (q *queue) Len() int {
 	q.RLock()
 	v := q.opsSumSize
@@ -576,6 +668,10 @@ func (q *queue) ConnectionCount() int {
 	return q.connPool.ConnectionCount()
 }
 
+func (q *queue) Healthy() bool {
+	return q.connPool.Healthy()
+}
+
 func (q *queue) ConnectionPool() connectionPool {
 	return q.connPool
 }
@@ -635,6 +731,10 @@ func errQueueFetchNoResponse(hostID string) error {
 	return fmt.Errorf("host operation queue did not receive response for given fetch for host: %s", hostID)
 }
 
+func errQueueFetchDeadlineExceeded(hostID string) error {
+	return fmt.Errorf("host operation queue fetch deadline exceeded before sending to host: %s", hostID)
+}
+
 // ops container types
 
 type namespaceWriteBatchOps struct {
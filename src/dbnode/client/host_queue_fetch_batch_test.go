@@ -24,11 +24,13 @@ import (
 	"fmt"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/uber/tchannel-go/thrift"
 )
 
@@ -64,6 +66,54 @@ func TestHostQueueFetchBatchesErrorOnNextClientUnavailable(t *testing.T) {
 	})
 }
 
+func TestHostQueueFetchBatchesCancelledSpeculation(t *testing.T) {
+	namespace := "testNs"
+	ids := []string{"foo", "bar", "baz", "qux"}
+	var expected []hostQueueResult
+	for range ids {
+		expected = append(expected, hostQueueResult{nil, errFetchSpeculationCancelled})
+	}
+	opts := &testHostQueueFetchBatchesOptions{
+		cancelled: true,
+	}
+	testHostQueueFetchBatches(t, namespace, ids, nil, expected, opts, func(results []hostQueueResult) {
+		assert.Equal(t, expected, results)
+	})
+}
+
+func TestHostQueueFetchBatchesDeadline(t *testing.T) {
+	namespace := "testNs"
+	ids := []string{"foo", "bar", "baz", "qux"}
+	result := &rpc.FetchBatchRawResult_{}
+	for range ids {
+		result.Elements = append(result.Elements, &rpc.FetchRawResult_{Segments: []*rpc.Segments{}})
+	}
+	var expected []hostQueueResult
+	for i := range ids {
+		expected = append(expected, hostQueueResult{result.Elements[i].Segments, nil})
+	}
+	opts := &testHostQueueFetchBatchesOptions{
+		deadline: time.Now().Add(time.Minute),
+	}
+	testHostQueueFetchBatches(t, namespace, ids, result, expected, opts, func(results []hostQueueResult) {
+		assert.Equal(t, expected, results)
+	})
+}
+
+func TestHostQueueFetchBatchesErrorOnDeadlineExceeded(t *testing.T) {
+	namespace := "testNs"
+	ids := []string{"foo", "bar", "baz", "qux"}
+	opts := &testHostQueueFetchBatchesOptions{
+		deadline: time.Now().Add(-time.Second),
+	}
+	testHostQueueFetchBatches(t, namespace, ids, nil, nil, opts, func(results []hostQueueResult) {
+		require.Len(t, results, len(ids))
+		for _, r := range results {
+			assert.Error(t, r.err)
+		}
+	})
+}
+
 func TestHostQueueFetchBatchesErrorOnFetchRawBatchError(t *testing.T) {
 	namespace := "testNs"
 	ids := []string{"foo", "bar", "baz", "qux"}
@@ -125,6 +175,8 @@ func TestHostQueueFetchBatchesErrorOnResultError(t *testing.T) {
 type testHostQueueFetchBatchesOptions struct {
 	nextClientErr    error
 	fetchRawBatchErr error
+	cancelled        bool
+	deadline         time.Time
 }
 
 func testHostQueueFetchBatches(
@@ -178,11 +230,20 @@ func testHostQueueFetchBatches(
 	for range fetchBatch.request.Ids {
 		fetchBatch.completionFns = append(fetchBatch.completionFns, callback)
 	}
+	if testOpts != nil {
+		fetchBatch.deadline = testOpts.deadline
+	}
 	wg.Add(len(fetchBatch.request.Ids))
 
 	// Prepare mocks for flush
 	mockClient := rpc.NewMockTChanNode(ctrl)
-	if testOpts != nil && testOpts.nextClientErr != nil {
+	if testOpts != nil && !testOpts.deadline.IsZero() && !testOpts.deadline.After(time.Now()) {
+		mockConnPool.EXPECT().NextClient().Return(mockClient, nil)
+	} else if testOpts != nil && testOpts.cancelled {
+		fetchBatch.cancellation = newFetchCancellation()
+		fetchBatch.cancellation.release()
+		mockConnPool.EXPECT().NextClient().Return(mockClient, nil)
+	} else if testOpts != nil && testOpts.nextClientErr != nil {
 		mockConnPool.EXPECT().NextClient().Return(nil, testOpts.nextClientErr)
 	} else if testOpts != nil && testOpts.fetchRawBatchErr != nil {
 		fetchBatchRaw := func(ctx thrift.Context, req *rpc.FetchBatchRawRequest) {
@@ -197,6 +258,13 @@ func testHostQueueFetchBatches(
 	} else {
 		fetchBatchRaw := func(ctx thrift.Context, req *rpc.FetchBatchRawRequest) {
 			assert.Equal(t, &fetchBatch.request, req)
+			if testOpts != nil && !testOpts.deadline.IsZero() {
+				// The time remaining until the deadline is propagated
+				if assert.NotNil(t, req.TimeoutNanos) {
+					remaining := time.Duration(*req.TimeoutNanos)
+					assert.True(t, remaining > 0 && remaining <= time.Minute)
+				}
+			}
 		}
 		mockClient.EXPECT().
 			FetchBatchRaw(gomock.Any(), gomock.Any()).
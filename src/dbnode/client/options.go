@@ -94,6 +94,15 @@ const (
 	// defaultFetchBatchSize is the default fetch batch size
 	defaultFetchBatchSize = 128
 
+	// defaultReadSpeculativeRetryEnabled is the default read speculative retry enabled
+	defaultReadSpeculativeRetryEnabled = false
+
+	// defaultReadSpeculativeRetryPercentile is the default read speculative retry percentile
+	defaultReadSpeculativeRetryPercentile = 0.95
+
+	// defaultReadSpeculativeRetryMaxDelay is the default read speculative retry max delay
+	defaultReadSpeculativeRetryMaxDelay = 100 * time.Millisecond
+
 	// defaultCheckedBytesWrapperPoolSize is the default checkedBytesWrapperPoolSize
 	defaultCheckedBytesWrapperPoolSize = 65536
 
@@ -112,6 +121,10 @@ const (
 	// defaultBackgroundConnectStutter is the default background connect stutter
 	defaultBackgroundConnectStutter = 2 * time.Second
 
+	// defaultBackgroundHealthCheckEnabled is the default for whether background
+	// health checks are enabled
+	defaultBackgroundHealthCheckEnabled = true
+
 	// defaultBackgroundHealthCheckInterval is the default background health check interval
 	defaultBackgroundHealthCheckInterval = 4 * time.Second
 
@@ -144,6 +157,18 @@ const (
 	// defaultFetchSeriesBlocksBatchSize is the default fetch series blocks batch size
 	defaultFetchSeriesBlocksBatchSize = 4096
 
+	// defaultFetchSeriesBlocksMetadataBatchSize is the default fetch series blocks
+	// metadata batch size
+	defaultFetchSeriesBlocksMetadataBatchSize = 4096
+
+	// defaultFetchSeriesBlocksMaxBlocksInFlightPerPeer is the default max number
+	// of blocks being fetched from a single peer at once
+	defaultFetchSeriesBlocksMaxBlocksInFlightPerPeer = 4 * defaultFetchSeriesBlocksBatchSize
+
+	// defaultFetchSeriesBlocksMaxConcurrentStreamsPerPeer is the default max
+	// number of concurrent batches being fetched from a single peer
+	defaultFetchSeriesBlocksMaxConcurrentStreamsPerPeer = 4
+
 	// defaultFetchSeriesBlocksMetadataBatchTimeout is the default series blocks metadata fetch timeout
 	defaultFetchSeriesBlocksMetadataBatchTimeout = 60 * time.Second
 
@@ -180,58 +205,70 @@ var (
 
 	errNoTopologyInitializerSet    = errors.New("no topology initializer set")
 	errNoReaderIteratorAllocateSet = errors.New("no reader iterator allocator set, encoding not set")
+
+	errReadSpeculativeRetryPercentileInvalid = errors.New(
+		"read speculative retry percentile must be greater than 0 and at most 1")
+	errReadSpeculativeRetryMaxDelayNotPositive = errors.New(
+		"read speculative retry max delay must be positive")
 )
 
 type options struct {
-	runtimeOptsMgr                          m3dbruntime.OptionsManager
-	clockOpts                               clock.Options
-	instrumentOpts                          instrument.Options
-	topologyInitializer                     topology.Initializer
-	readConsistencyLevel                    topology.ReadConsistencyLevel
-	writeConsistencyLevel                   topology.ConsistencyLevel
-	bootstrapConsistencyLevel               topology.ReadConsistencyLevel
-	channelOptions                          *tchannel.ChannelOptions
-	maxConnectionCount                      int
-	minConnectionCount                      int
-	hostConnectTimeout                      time.Duration
-	clusterConnectTimeout                   time.Duration
-	clusterConnectConsistencyLevel          topology.ConnectConsistencyLevel
-	writeRequestTimeout                     time.Duration
-	fetchRequestTimeout                     time.Duration
-	truncateRequestTimeout                  time.Duration
-	backgroundConnectInterval               time.Duration
-	backgroundConnectStutter                time.Duration
-	backgroundHealthCheckInterval           time.Duration
-	backgroundHealthCheckStutter            time.Duration
-	backgroundHealthCheckFailLimit          int
-	backgroundHealthCheckFailThrottleFactor float64
-	tagEncoderOpts                          serialize.TagEncoderOptions
-	tagEncoderPoolSize                      int
-	tagDecoderOpts                          serialize.TagDecoderOptions
-	tagDecoderPoolSize                      int
-	writeRetrier                            xretry.Retrier
-	fetchRetrier                            xretry.Retrier
-	streamBlocksRetrier                     xretry.Retrier
-	readerIteratorAllocate                  encoding.ReaderIteratorAllocate
-	writeOperationPoolSize                  int
-	writeTaggedOperationPoolSize            int
-	fetchBatchOpPoolSize                    int
-	writeBatchSize                          int
-	fetchBatchSize                          int
-	identifierPool                          ident.Pool
-	hostQueueOpsFlushSize                   int
-	hostQueueOpsFlushInterval               time.Duration
-	hostQueueOpsArrayPoolSize               int
-	seriesIteratorPoolSize                  int
-	seriesIteratorArrayPoolBuckets          []pool.Bucket
-	checkedBytesWrapperPoolSize             int
-	contextPool                             context.Pool
-	origin                                  topology.Host
-	fetchSeriesBlocksMaxBlockRetries        int
-	fetchSeriesBlocksBatchSize              int
-	fetchSeriesBlocksMetadataBatchTimeout   time.Duration
-	fetchSeriesBlocksBatchTimeout           time.Duration
-	fetchSeriesBlocksBatchConcurrency       int
+	runtimeOptsMgr                               m3dbruntime.OptionsManager
+	clockOpts                                    clock.Options
+	instrumentOpts                               instrument.Options
+	topologyInitializer                          topology.Initializer
+	readConsistencyLevel                         topology.ReadConsistencyLevel
+	writeConsistencyLevel                        topology.ConsistencyLevel
+	bootstrapConsistencyLevel                    topology.ReadConsistencyLevel
+	channelOptions                               *tchannel.ChannelOptions
+	maxConnectionCount                           int
+	minConnectionCount                           int
+	hostConnectTimeout                           time.Duration
+	clusterConnectTimeout                        time.Duration
+	clusterConnectConsistencyLevel               topology.ConnectConsistencyLevel
+	writeRequestTimeout                          time.Duration
+	fetchRequestTimeout                          time.Duration
+	truncateRequestTimeout                       time.Duration
+	backgroundConnectInterval                    time.Duration
+	backgroundConnectStutter                     time.Duration
+	backgroundHealthCheckEnabled                 bool
+	backgroundHealthCheckInterval                time.Duration
+	backgroundHealthCheckStutter                 time.Duration
+	backgroundHealthCheckFailLimit               int
+	backgroundHealthCheckFailThrottleFactor      float64
+	tagEncoderOpts                               serialize.TagEncoderOptions
+	tagEncoderPoolSize                           int
+	tagDecoderOpts                               serialize.TagDecoderOptions
+	tagDecoderPoolSize                           int
+	writeRetrier                                 xretry.Retrier
+	fetchRetrier                                 xretry.Retrier
+	streamBlocksRetrier                          xretry.Retrier
+	readerIteratorAllocate                       encoding.ReaderIteratorAllocate
+	writeOperationPoolSize                       int
+	writeTaggedOperationPoolSize                 int
+	fetchBatchOpPoolSize                         int
+	writeBatchSize                               int
+	fetchBatchSize                               int
+	readSpeculativeRetryEnabled                  bool
+	readSpeculativeRetryPercentile               float64
+	readSpeculativeRetryMaxDelay                 time.Duration
+	identifierPool                               ident.Pool
+	hostQueueOpsFlushSize                        int
+	hostQueueOpsFlushInterval                    time.Duration
+	hostQueueOpsArrayPoolSize                    int
+	seriesIteratorPoolSize                       int
+	seriesIteratorArrayPoolBuckets               []pool.Bucket
+	checkedBytesWrapperPoolSize                  int
+	contextPool                                  context.Pool
+	origin                                       topology.Host
+	fetchSeriesBlocksMaxBlockRetries             int
+	fetchSeriesBlocksBatchSize                   int
+	fetchSeriesBlocksMetadataBatchSize           int
+	fetchSeriesBlocksMetadataBatchTimeout        time.Duration
+	fetchSeriesBlocksBatchTimeout                time.Duration
+	fetchSeriesBlocksBatchConcurrency            int
+	fetchSeriesBlocksMaxBlocksInFlightPerPeer    int
+	fetchSeriesBlocksMaxConcurrentStreamsPerPeer int
 }
 
 // NewOptions creates a new set of client options with defaults
@@ -266,50 +303,57 @@ func newOptions() *options {
 		SetFinalizerPoolOptions(poolOpts))
 
 	opts := &options{
-		clockOpts:                               clock.NewOptions(),
-		instrumentOpts:                          instrument.NewOptions(),
-		writeConsistencyLevel:                   defaultWriteConsistencyLevel,
-		readConsistencyLevel:                    defaultReadConsistencyLevel,
-		bootstrapConsistencyLevel:               defaultBootstrapConsistencyLevel,
-		maxConnectionCount:                      defaultMaxConnectionCount,
-		minConnectionCount:                      defaultMinConnectionCount,
-		hostConnectTimeout:                      defaultHostConnectTimeout,
-		clusterConnectTimeout:                   defaultClusterConnectTimeout,
-		clusterConnectConsistencyLevel:          defaultClusterConnectConsistencyLevel,
-		writeRequestTimeout:                     defaultWriteRequestTimeout,
-		fetchRequestTimeout:                     defaultFetchRequestTimeout,
-		truncateRequestTimeout:                  defaultTruncateRequestTimeout,
-		backgroundConnectInterval:               defaultBackgroundConnectInterval,
-		backgroundConnectStutter:                defaultBackgroundConnectStutter,
-		backgroundHealthCheckInterval:           defaultBackgroundHealthCheckInterval,
-		backgroundHealthCheckStutter:            defaultBackgroundHealthCheckStutter,
-		backgroundHealthCheckFailLimit:          defaultBackgroundHealthCheckFailLimit,
-		backgroundHealthCheckFailThrottleFactor: defaultBackgroundHealthCheckFailThrottleFactor,
-		writeRetrier:                            defaultWriteRetrier,
-		fetchRetrier:                            defaultFetchRetrier,
-		tagEncoderPoolSize:                      defaultTagEncoderPoolSize,
-		tagEncoderOpts:                          serialize.NewTagEncoderOptions(),
-		tagDecoderPoolSize:                      defaultTagDecoderPoolSize,
-		tagDecoderOpts:                          serialize.NewTagDecoderOptions(),
-		streamBlocksRetrier:                     defaultStreamBlocksRetrier,
-		writeOperationPoolSize:                  defaultWriteOpPoolSize,
-		writeTaggedOperationPoolSize:            defaultWriteTaggedOpPoolSize,
-		fetchBatchOpPoolSize:                    defaultFetchBatchOpPoolSize,
-		writeBatchSize:                          DefaultWriteBatchSize,
-		fetchBatchSize:                          defaultFetchBatchSize,
-		identifierPool:                          idPool,
-		hostQueueOpsFlushSize:                   defaultHostQueueOpsFlushSize,
-		hostQueueOpsFlushInterval:               defaultHostQueueOpsFlushInterval,
-		hostQueueOpsArrayPoolSize:               defaultHostQueueOpsArrayPoolSize,
-		seriesIteratorPoolSize:                  defaultSeriesIteratorPoolSize,
-		seriesIteratorArrayPoolBuckets:          defaultSeriesIteratorArrayPoolBuckets,
-		checkedBytesWrapperPoolSize:             defaultCheckedBytesWrapperPoolSize,
-		contextPool:                             contextPool,
-		fetchSeriesBlocksMaxBlockRetries:        defaultFetchSeriesBlocksMaxBlockRetries,
-		fetchSeriesBlocksBatchSize:              defaultFetchSeriesBlocksBatchSize,
-		fetchSeriesBlocksMetadataBatchTimeout:   defaultFetchSeriesBlocksMetadataBatchTimeout,
-		fetchSeriesBlocksBatchTimeout:           defaultFetchSeriesBlocksBatchTimeout,
-		fetchSeriesBlocksBatchConcurrency:       defaultFetchSeriesBlocksBatchConcurrency,
+		clockOpts:                                    clock.NewOptions(),
+		instrumentOpts:                               instrument.NewOptions(),
+		writeConsistencyLevel:                        defaultWriteConsistencyLevel,
+		readConsistencyLevel:                         defaultReadConsistencyLevel,
+		bootstrapConsistencyLevel:                    defaultBootstrapConsistencyLevel,
+		maxConnectionCount:                           defaultMaxConnectionCount,
+		minConnectionCount:                           defaultMinConnectionCount,
+		hostConnectTimeout:                           defaultHostConnectTimeout,
+		clusterConnectTimeout:                        defaultClusterConnectTimeout,
+		clusterConnectConsistencyLevel:               defaultClusterConnectConsistencyLevel,
+		writeRequestTimeout:                          defaultWriteRequestTimeout,
+		fetchRequestTimeout:                          defaultFetchRequestTimeout,
+		truncateRequestTimeout:                       defaultTruncateRequestTimeout,
+		backgroundConnectInterval:                    defaultBackgroundConnectInterval,
+		backgroundConnectStutter:                     defaultBackgroundConnectStutter,
+		backgroundHealthCheckEnabled:                 defaultBackgroundHealthCheckEnabled,
+		backgroundHealthCheckInterval:                defaultBackgroundHealthCheckInterval,
+		backgroundHealthCheckStutter:                 defaultBackgroundHealthCheckStutter,
+		backgroundHealthCheckFailLimit:               defaultBackgroundHealthCheckFailLimit,
+		backgroundHealthCheckFailThrottleFactor:      defaultBackgroundHealthCheckFailThrottleFactor,
+		writeRetrier:                                 defaultWriteRetrier,
+		fetchRetrier:                                 defaultFetchRetrier,
+		tagEncoderPoolSize:                           defaultTagEncoderPoolSize,
+		tagEncoderOpts:                               serialize.NewTagEncoderOptions(),
+		tagDecoderPoolSize:                           defaultTagDecoderPoolSize,
+		tagDecoderOpts:                               serialize.NewTagDecoderOptions(),
+		streamBlocksRetrier:                          defaultStreamBlocksRetrier,
+		writeOperationPoolSize:                       defaultWriteOpPoolSize,
+		writeTaggedOperationPoolSize:                 defaultWriteTaggedOpPoolSize,
+		fetchBatchOpPoolSize:                         defaultFetchBatchOpPoolSize,
+		writeBatchSize:                               DefaultWriteBatchSize,
+		fetchBatchSize:                               defaultFetchBatchSize,
+		readSpeculativeRetryEnabled:                  defaultReadSpeculativeRetryEnabled,
+		readSpeculativeRetryPercentile:               defaultReadSpeculativeRetryPercentile,
+		readSpeculativeRetryMaxDelay:                 defaultReadSpeculativeRetryMaxDelay,
+		identifierPool:                               idPool,
+		hostQueueOpsFlushSize:                        defaultHostQueueOpsFlushSize,
+		hostQueueOpsFlushInterval:                    defaultHostQueueOpsFlushInterval,
+		hostQueueOpsArrayPoolSize:                    defaultHostQueueOpsArrayPoolSize,
+		seriesIteratorPoolSize:                       defaultSeriesIteratorPoolSize,
+		seriesIteratorArrayPoolBuckets:               defaultSeriesIteratorArrayPoolBuckets,
+		checkedBytesWrapperPoolSize:                  defaultCheckedBytesWrapperPoolSize,
+		contextPool:                                  contextPool,
+		fetchSeriesBlocksMaxBlockRetries:             defaultFetchSeriesBlocksMaxBlockRetries,
+		fetchSeriesBlocksBatchSize:                   defaultFetchSeriesBlocksBatchSize,
+		fetchSeriesBlocksMetadataBatchSize:           defaultFetchSeriesBlocksMetadataBatchSize,
+		fetchSeriesBlocksMetadataBatchTimeout:        defaultFetchSeriesBlocksMetadataBatchTimeout,
+		fetchSeriesBlocksBatchTimeout:                defaultFetchSeriesBlocksBatchTimeout,
+		fetchSeriesBlocksBatchConcurrency:            defaultFetchSeriesBlocksBatchConcurrency,
+		fetchSeriesBlocksMaxBlocksInFlightPerPeer:    defaultFetchSeriesBlocksMaxBlocksInFlightPerPeer,
+		fetchSeriesBlocksMaxConcurrentStreamsPerPeer: defaultFetchSeriesBlocksMaxConcurrentStreamsPerPeer,
 	}
 	return opts.SetEncodingM3TSZ().(*options)
 }
@@ -336,6 +380,13 @@ func (o *options) Validate() error {
 	); err != nil {
 		return err
 	}
+	if o.readSpeculativeRetryPercentile <= 0 ||
+		o.readSpeculativeRetryPercentile > 1 {
+		return errReadSpeculativeRetryPercentileInvalid
+	}
+	if o.readSpeculativeRetryMaxDelay <= 0 {
+		return errReadSpeculativeRetryMaxDelayNotPositive
+	}
 	return topology.ValidateConnectConsistencyLevel(
 		o.clusterConnectConsistencyLevel,
 	)
@@ -529,6 +580,16 @@ func (o *options) BackgroundConnectStutter() time.Duration {
 	return o.backgroundConnectStutter
 }
 
+func (o *options) SetBackgroundHealthCheckEnabled(value bool) Options {
+	opts := *o
+	opts.backgroundHealthCheckEnabled = value
+	return &opts
+}
+
+func (o *options) BackgroundHealthCheckEnabled() bool {
+	return o.backgroundHealthCheckEnabled
+}
+
 func (o *options) SetBackgroundHealthCheckInterval(value time.Duration) Options {
 	opts := *o
 	opts.backgroundHealthCheckInterval = value
@@ -699,6 +760,36 @@ func (o *options) FetchBatchSize() int {
 	return o.fetchBatchSize
 }
 
+func (o *options) SetReadSpeculativeRetryEnabled(value bool) Options {
+	opts := *o
+	opts.readSpeculativeRetryEnabled = value
+	return &opts
+}
+
+func (o *options) ReadSpeculativeRetryEnabled() bool {
+	return o.readSpeculativeRetryEnabled
+}
+
+func (o *options) SetReadSpeculativeRetryPercentile(value float64) Options {
+	opts := *o
+	opts.readSpeculativeRetryPercentile = value
+	return &opts
+}
+
+func (o *options) ReadSpeculativeRetryPercentile() float64 {
+	return o.readSpeculativeRetryPercentile
+}
+
+func (o *options) SetReadSpeculativeRetryMaxDelay(value time.Duration) Options {
+	opts := *o
+	opts.readSpeculativeRetryMaxDelay = value
+	return &opts
+}
+
+func (o *options) ReadSpeculativeRetryMaxDelay() time.Duration {
+	return o.readSpeculativeRetryMaxDelay
+}
+
 func (o *options) SetIdentifierPool(value ident.Pool) Options {
 	opts := *o
 	opts.identifierPool = value
@@ -809,6 +900,16 @@ func (o *options) FetchSeriesBlocksBatchSize() int {
 	return o.fetchSeriesBlocksBatchSize
 }
 
+func (o *options) SetFetchSeriesBlocksMetadataBatchSize(value int) AdminOptions {
+	opts := *o
+	opts.fetchSeriesBlocksMetadataBatchSize = value
+	return &opts
+}
+
+func (o *options) FetchSeriesBlocksMetadataBatchSize() int {
+	return o.fetchSeriesBlocksMetadataBatchSize
+}
+
 func (o *options) SetFetchSeriesBlocksMetadataBatchTimeout(value time.Duration) AdminOptions {
 	opts := *o
 	opts.fetchSeriesBlocksMetadataBatchTimeout = value
@@ -838,3 +939,23 @@ func (o *options) SetFetchSeriesBlocksBatchConcurrency(value int) AdminOptions {
 func (o *options) FetchSeriesBlocksBatchConcurrency() int {
 	return o.fetchSeriesBlocksBatchConcurrency
 }
+
+func (o *options) SetFetchSeriesBlocksMaxBlocksInFlightPerPeer(value int) AdminOptions {
+	opts := *o
+	opts.fetchSeriesBlocksMaxBlocksInFlightPerPeer = value
+	return &opts
+}
+
+func (o *options) FetchSeriesBlocksMaxBlocksInFlightPerPeer() int {
+	return o.fetchSeriesBlocksMaxBlocksInFlightPerPeer
+}
+
+func (o *options) SetFetchSeriesBlocksMaxConcurrentStreamsPerPeer(value int) AdminOptions {
+	opts := *o
+	opts.fetchSeriesBlocksMaxConcurrentStreamsPerPeer = value
+	return &opts
+}
+
+func (o *options) FetchSeriesBlocksMaxConcurrentStreamsPerPeer() int {
+	return o.fetchSeriesBlocksMaxConcurrentStreamsPerPeer
+}
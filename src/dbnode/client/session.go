@@ -48,6 +48,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/xio"
 	"github.com/m3db/m3/src/dbnode/x/xpool"
+	m3ninxindex "github.com/m3db/m3/src/m3ninx/index"
 	"github.com/m3db/m3cluster/shard"
 	"github.com/m3db/m3x/checked"
 	xclose "github.com/m3db/m3x/close"
@@ -125,6 +126,9 @@ var (
 	// errInvalidFetchBlocksMetadataVersion is raised when an invalid fetch blocks
 	// metadata endpoint version is provided
 	errInvalidFetchBlocksMetadataVersion = errors.New("invalid fetch blocks metadata endpoint version")
+	// errFetchTaggedPageSizeNotPositive is raised when a paged fetch tagged query is
+	// requested without a positive page size.
+	errFetchTaggedPageSizeNotPositive = errors.New("fetch tagged page size must be positive")
 	// errUnableToEncodeTags is raised when the server is unable to encode provided tags
 	// to be sent over the wire.
 	errUnableToEncodeTags = errors.New("unable to include tags")
@@ -144,6 +148,10 @@ type sessionState struct {
 	readLevel      topology.ReadConsistencyLevel
 	bootstrapLevel topology.ReadConsistencyLevel
 
+	// namespaceWriteLevels overrides writeLevel for writes to specific
+	// namespaces, keyed by namespace ID.
+	namespaceWriteLevels map[string]topology.ConsistencyLevel
+
 	queues         []hostQueue
 	queuesByHostID map[string]hostQueue
 	topo           topology.Topology
@@ -166,6 +174,11 @@ type session struct {
 	streamBlocksRetrier              xretry.Retrier
 	pools                            sessionPools
 	fetchBatchSize                   int
+	fetchTimeoutNanos                int64
+	readSpeculativeRetryEnabled      bool
+	readSpeculativeRetryPercentile   float64
+	readSpeculativeRetryMaxDelay     time.Duration
+	hostLatencies                    *hostLatencies
 	newPeerBlocksQueueFn             newPeerBlocksQueueFn
 	reattemptStreamBlocksFromPeersFn reattemptStreamBlocksFromPeersFn
 	pickBestPeerFn                   pickBestPeerFn
@@ -173,6 +186,9 @@ type session struct {
 	streamBlocksMaxBlockRetries      int
 	streamBlocksWorkers              xsync.WorkerPool
 	streamBlocksBatchSize            int
+	streamBlocksMetadataBatchSize    int
+	streamBlocksMaxBlocksInFlight    int
+	streamBlocksMaxStreams           int
 	streamBlocksMetadataBatchTimeout time.Duration
 	streamBlocksBatchTimeout         time.Duration
 	metrics                          sessionMetrics
@@ -191,6 +207,8 @@ type sessionMetrics struct {
 	fetchSuccess               tally.Counter
 	fetchErrors                tally.Counter
 	fetchNodesRespondingErrors []tally.Counter
+	fetchSpeculativeSent       tally.Counter
+	fetchPrimaryOnlySuccess    tally.Counter
 	topologyUpdatedSuccess     tally.Counter
 	topologyUpdatedError       tally.Counter
 	streamFromPeersMetrics     map[shardMetricsKey]streamFromPeersMetrics
@@ -198,13 +216,15 @@ type sessionMetrics struct {
 
 func newSessionMetrics(scope tally.Scope) sessionMetrics {
 	return sessionMetrics{
-		writeSuccess:           scope.Counter("write.success"),
-		writeErrors:            scope.Counter("write.errors"),
-		fetchSuccess:           scope.Counter("fetch.success"),
-		fetchErrors:            scope.Counter("fetch.errors"),
-		topologyUpdatedSuccess: scope.Counter("topology.updated-success"),
-		topologyUpdatedError:   scope.Counter("topology.updated-error"),
-		streamFromPeersMetrics: make(map[shardMetricsKey]streamFromPeersMetrics),
+		writeSuccess:            scope.Counter("write.success"),
+		writeErrors:             scope.Counter("write.errors"),
+		fetchSuccess:            scope.Counter("fetch.success"),
+		fetchErrors:             scope.Counter("fetch.errors"),
+		fetchSpeculativeSent:    scope.Counter("fetch.speculative-sent"),
+		fetchPrimaryOnlySuccess: scope.Counter("fetch.primary-only-success"),
+		topologyUpdatedSuccess:  scope.Counter("topology.updated-success"),
+		topologyUpdatedError:    scope.Counter("topology.updated-error"),
+		streamFromPeersMetrics:  make(map[shardMetricsKey]streamFromPeersMetrics),
 	}
 }
 
@@ -218,6 +238,7 @@ type streamFromPeersMetrics struct {
 	metadataReceived                                  tally.Counter
 	metadataPeerRetry                                 tally.Counter
 	fetchBlockSuccess                                 tally.Counter
+	fetchBlockBytes                                   tally.Counter
 	fetchBlockError                                   tally.Counter
 	fetchBlockFullRetry                               tally.Counter
 	fetchBlockFinalError                              tally.Counter
@@ -225,6 +246,7 @@ type streamFromPeersMetrics struct {
 	fetchBlockRetriesRespError                        tally.Counter
 	fetchBlockRetriesConsistencyLevelNotAchievedError tally.Counter
 	blocksEnqueueChannel                              tally.Gauge
+	blocksInFlight                                    tally.Gauge
 }
 
 type hostQueueOpts struct {
@@ -255,15 +277,20 @@ func newSession(opts Options) (clientSession, error) {
 			queuesByHostID: make(map[string]hostQueue),
 			topo:           topo,
 		},
-		opts:                 opts,
-		scope:                scope,
-		nowFn:                opts.ClockOptions().NowFn(),
-		log:                  opts.InstrumentOptions().Logger(),
-		newHostQueueFn:       newHostQueue,
-		fetchBatchSize:       opts.FetchBatchSize(),
-		newPeerBlocksQueueFn: newPeerBlocksQueue,
-		writeRetrier:         opts.WriteRetrier(),
-		fetchRetrier:         opts.FetchRetrier(),
+		opts:                           opts,
+		scope:                          scope,
+		nowFn:                          opts.ClockOptions().NowFn(),
+		log:                            opts.InstrumentOptions().Logger(),
+		newHostQueueFn:                 newHostQueue,
+		fetchBatchSize:                 opts.FetchBatchSize(),
+		fetchTimeoutNanos:              int64(opts.FetchRequestTimeout()),
+		readSpeculativeRetryEnabled:    opts.ReadSpeculativeRetryEnabled(),
+		readSpeculativeRetryPercentile: opts.ReadSpeculativeRetryPercentile(),
+		readSpeculativeRetryMaxDelay:   opts.ReadSpeculativeRetryMaxDelay(),
+		hostLatencies:                  newHostLatencies(opts.ClockOptions().NowFn()),
+		newPeerBlocksQueueFn:           newPeerBlocksQueue,
+		writeRetrier:                   opts.WriteRetrier(),
+		fetchRetrier:                   opts.FetchRetrier(),
 		pools: sessionPools{
 			context: opts.ContextPool(),
 			id:      opts.IdentifierPool(),
@@ -326,6 +353,9 @@ func newSession(opts Options) (clientSession, error) {
 		s.streamBlocksWorkers = xsync.NewWorkerPool(opts.FetchSeriesBlocksBatchConcurrency())
 		s.streamBlocksWorkers.Init()
 		s.streamBlocksBatchSize = opts.FetchSeriesBlocksBatchSize()
+		s.streamBlocksMetadataBatchSize = opts.FetchSeriesBlocksMetadataBatchSize()
+		s.streamBlocksMaxBlocksInFlight = opts.FetchSeriesBlocksMaxBlocksInFlightPerPeer()
+		s.streamBlocksMaxStreams = opts.FetchSeriesBlocksMaxConcurrentStreamsPerPeer()
 		s.streamBlocksMetadataBatchTimeout = opts.FetchSeriesBlocksMetadataBatchTimeout()
 		s.streamBlocksBatchTimeout = opts.FetchSeriesBlocksBatchTimeout()
 		s.streamBlocksRetrier = opts.StreamBlocksRetrier()
@@ -343,9 +373,24 @@ func (s *session) SetRuntimeOptions(value runtime.Options) {
 	s.state.bootstrapLevel = value.ClientBootstrapConsistencyLevel()
 	s.state.readLevel = value.ClientReadConsistencyLevel()
 	s.state.writeLevel = value.ClientWriteConsistencyLevel()
+	s.state.namespaceWriteLevels = value.ClientNamespaceWriteConsistencyLevels()
 	s.state.Unlock()
 }
 
+// writeLevelWithRLock returns the write consistency level for writes to a
+// namespace, falling back to the session write consistency level.
+func (s *session) writeLevelWithRLock(namespace ident.ID) topology.ConsistencyLevel {
+	if len(s.state.namespaceWriteLevels) == 0 {
+		return s.state.writeLevel
+	}
+	// NB: the map lookup with a string conversion of the bytes does not
+	// allocate, unlike namespace.String().
+	if level, ok := s.state.namespaceWriteLevels[string(namespace.Bytes())]; ok {
+		return level
+	}
+	return s.state.writeLevel
+}
+
 func (s *session) ShardID(id ident.ID) (uint32, error) {
 	s.state.RLock()
 	if s.state.status != statusOpen {
@@ -395,6 +440,7 @@ func (s *session) newPeerMetadataStreamingProgressMetrics(
 		metadataReceived:           scope.Counter("fetch-metadata-peers-received"),
 		metadataPeerRetry:          scope.Counter("fetch-metadata-peers-peer-retry"),
 		fetchBlockSuccess:          scope.Counter("fetch-block-success"),
+		fetchBlockBytes:            scope.Counter("fetch-block-bytes"),
 		fetchBlockError:            scope.Counter("fetch-block-error"),
 		fetchBlockFinalError:       scope.Counter("fetch-block-final-error"),
 		fetchBlockFullRetry:        scope.Counter("fetch-block-full-retry"),
@@ -408,6 +454,7 @@ func (s *session) newPeerMetadataStreamingProgressMetrics(
 			"reason": "consistency-level-not-achieved-error",
 		}).Counter("fetch-block-retries"),
 		blocksEnqueueChannel: scope.Gauge("fetch-blocks-enqueue-channel-length"),
+		blocksInFlight:       scope.Gauge("fetch-blocks-in-flight"),
 	}
 	s.metrics.streamFromPeersMetrics[mKey] = m
 	s.metrics.Unlock()
@@ -900,6 +947,136 @@ func (s *session) WriteTagged(
 	return err
 }
 
+func (s *session) WriteTaggedBatch(
+	namespace ident.ID,
+	writes []TaggedWrite,
+) error {
+	var (
+		errs    = make([]error, len(writes))
+		pending = make([]int, 0, len(writes))
+	)
+	for i := range writes {
+		pending = append(pending, i)
+	}
+
+	// Only the elements that failed with retryable errors are attempted
+	// again, the errors of the elements are inspected once done rather than
+	// the error of the last attempt.
+	_ = s.writeRetrier.Attempt(func() error {
+		pending = s.writeTaggedBatchAttempt(namespace, writes, pending, errs)
+		if len(pending) > 0 {
+			return errs[pending[0]]
+		}
+		return nil
+	})
+
+	var elemErrs []BatchElementError
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		elemErrs = append(elemErrs, BatchElementError{
+			Index:     i,
+			Err:       err,
+			Retryable: isRetryableWriteError(err),
+		})
+	}
+	if len(elemErrs) == 0 {
+		return nil
+	}
+	return newBatchError(len(writes), elemErrs)
+}
+
+// writeTaggedBatchAttempt writes the pending elements of a batch, each is
+// enqueued to the host queues which batch the writes per host, and then
+// waited on in turn to evaluate the consistency level of each element. The
+// errors of the elements are set and those that failed with retryable
+// errors are returned.
+func (s *session) writeTaggedBatchAttempt(
+	namespace ident.ID,
+	writes []TaggedWrite,
+	pending []int,
+	errs []error,
+) []int {
+	type enqueuedWrite struct {
+		idx      int
+		state    *writeState
+		majority int32
+		enqueued int32
+	}
+
+	s.state.RLock()
+	if s.state.status != statusOpen {
+		s.state.RUnlock()
+		for _, idx := range pending {
+			errs[idx] = errSessionStatusNotOpen
+		}
+		return pending
+	}
+
+	enqueuedWrites := make([]enqueuedWrite, 0, len(pending))
+	for _, idx := range pending {
+		w := writes[idx]
+		timeType, err := convert.ToTimeType(w.Unit)
+		if err != nil {
+			errs[idx] = xerrors.NewInvalidParamsError(err)
+			continue
+		}
+		timestamp, err := convert.ToValue(w.Timestamp, timeType)
+		if err != nil {
+			errs[idx] = xerrors.NewInvalidParamsError(err)
+			continue
+		}
+
+		state, majority, enqueued, err := s.writeAttemptWithRLock(
+			taggedWriteAttemptType, namespace, w.ID, w.Tags, timestamp,
+			w.Value, timeType, w.Annotation)
+		if err != nil {
+			errs[idx] = err
+			continue
+		}
+
+		// NB: Release the lock on the state so that completions are not
+		// blocked while the rest of the batch is enqueued, the state is
+		// waited on below until it has completed.
+		state.Unlock()
+		enqueuedWrites = append(enqueuedWrites, enqueuedWrite{
+			idx:      idx,
+			state:    state,
+			majority: majority,
+			enqueued: enqueued,
+		})
+	}
+	s.state.RUnlock()
+
+	for _, w := range enqueuedWrites {
+		state := w.state
+		state.Lock()
+		for !state.completedWithLock() {
+			state.Wait()
+		}
+
+		err := s.writeConsistencyResult(state.consistencyLevel, w.majority,
+			w.enqueued, w.enqueued-state.pending, int32(len(state.errors)),
+			state.errors)
+		s.incWriteMetrics(err, int32(len(state.errors)))
+		errs[w.idx] = err
+
+		// must Unlock before decRef'ing, as the latter releases the
+		// writeState back into a pool if ref count == 0.
+		state.Unlock()
+		state.decRef()
+	}
+
+	retry := pending[:0]
+	for _, idx := range pending {
+		if errs[idx] != nil && isRetryableWriteError(errs[idx]) {
+			retry = append(retry, idx)
+		}
+	}
+	return retry
+}
+
 func (s *session) writeAttempt(
 	wType writeAttemptType,
 	namespace, id ident.ID,
@@ -935,7 +1112,9 @@ func (s *session) writeAttempt(
 
 	// it's safe to Wait() here, as we still hold the lock on state, after it's
 	// returned from writeAttemptWithRLock.
-	state.Wait()
+	for !state.completedWithLock() {
+		state.Wait()
+	}
 
 	err = s.writeConsistencyResult(state.consistencyLevel, majority, enqueued,
 		enqueued-state.pending, int32(len(state.errors)), state.errors)
@@ -1016,7 +1195,7 @@ func (s *session) writeAttemptWithRLock(
 	}
 
 	state := s.pools.writeState.Get()
-	state.consistencyLevel = s.state.writeLevel
+	state.consistencyLevel = s.writeLevelWithRLock(namespace)
 	state.topoMap = s.state.topoMap
 	state.incRef()
 
@@ -1037,6 +1216,16 @@ func (s *session) writeAttemptWithRLock(
 
 	state.Lock()
 	for i := range state.queues {
+		if !state.queues[i].Healthy() {
+			// NB: Writes to hosts whose connections are all failing health
+			// checks are failed immediately rather than left to time out.
+			errStr := "host %s is unhealthy, its connections are failing health checks"
+			state.errors = append(state.errors, xerrors.NewRetryableError(
+				fmt.Errorf(errStr, state.queues[i].Host().ID())))
+			state.pending--
+			enqueued++
+			continue
+		}
 		state.incRef()
 		if err := state.queues[i].Enqueue(state.op); err != nil {
 			state.Unlock()
@@ -1180,6 +1369,144 @@ func (s *session) fetchTaggedIDsAttempt(
 	return iter, exhaustive, err
 }
 
+func (s *session) FetchTaggedPages(
+	ns ident.ID, q index.Query, opts index.QueryOptions,
+) (FetchTaggedPagesIterator, error) {
+	if opts.PageSize <= 0 {
+		return nil, errFetchTaggedPageSizeNotPositive
+	}
+	// NB: each page is bounded by the page size rather than the limit.
+	opts.Limit = 0
+	opts.PageToken = nil
+	return newFetchTaggedPagesIterator(s, s.pools.id.Clone(ns), q, opts), nil
+}
+
+func (s *session) fetchTaggedPageAttempt(
+	ns ident.ID, q index.Query, opts index.QueryOptions,
+) (encoding.SeriesIterators, bool, []byte, error) {
+	s.state.RLock()
+	if s.state.status != statusOpen {
+		s.state.RUnlock()
+		return nil, false, nil, errSessionStatusNotOpen
+	}
+
+	const fetchData = true
+	fetchState, err := s.fetchTaggedAttemptWithRLock(ns, q, opts, fetchData)
+	s.state.RUnlock()
+
+	if err != nil {
+		return nil, false, nil, err
+	}
+
+	// it's safe to Wait() here, as we still hold the lock on fetchState, after it's
+	// returned from fetchTaggedAttemptWithRLock.
+	fetchState.Wait()
+
+	// must Unlock before calling `asEncodingSeriesIterators` as the latter needs to acquire
+	// the fetchState Lock
+	fetchState.Unlock()
+	iters, exhaustive, err := fetchState.asEncodingSeriesIterators(s.pools)
+	token := fetchState.nextPageToken()
+
+	// must Unlock() before decRef'ing, as the latter releases the fetchState back into a
+	// pool if ref count == 0.
+	fetchState.decRef()
+
+	return iters, exhaustive, token, err
+}
+
+func (s *session) AggregateTagValues(
+	ns ident.ID, opts index.AggregateTagValuesOptions,
+) (index.AggregateTagValuesResults, error) {
+	request, err := convert.ToRPCAggregateTagValuesRequest(ns, opts)
+	if err != nil {
+		return index.AggregateTagValuesResults{}, err
+	}
+
+	results, err := index.NewTagValuesResults(opts)
+	if err != nil {
+		return index.AggregateTagValuesResults{}, err
+	}
+
+	var (
+		wg              sync.WaitGroup
+		enqueueErr      xerrors.MultiError
+		resultLock      sync.Mutex
+		resultErr       xerrors.MultiError
+		hostsSucceeded  int
+		hostsExhaustive = true
+	)
+
+	op := &aggregateTagValuesOp{request: request}
+	op.completionFn = func(result interface{}, err error) {
+		resultLock.Lock()
+		if err != nil {
+			resultErr = resultErr.Add(err)
+		} else {
+			res := result.(*rpc.AggregateTagValuesResult_)
+			values := make([]m3ninxindex.TermFrequency, 0, len(res.Values))
+			for _, v := range res.Values {
+				values = append(values, m3ninxindex.TermFrequency{
+					Term:  v.Term,
+					Count: int(v.Count),
+				})
+			}
+			results.Add(values)
+			hostsSucceeded++
+			hostsExhaustive = hostsExhaustive && res.Exhaustive
+		}
+		resultLock.Unlock()
+		wg.Done()
+	}
+
+	s.state.RLock()
+	if s.state.status != statusOpen {
+		s.state.RUnlock()
+		return index.AggregateTagValuesResults{}, errSessionStatusNotOpen
+	}
+	replicas := s.state.replicas
+	for idx := range s.state.queues {
+		wg.Add(1)
+		if err := s.state.queues[idx].Enqueue(op); err != nil {
+			wg.Done()
+			enqueueErr = enqueueErr.Add(err)
+		}
+	}
+	s.state.RUnlock()
+
+	if err := enqueueErr.FinalError(); err != nil {
+		s.log.Errorf("failed to enqueue request: %v", err)
+		return index.AggregateTagValuesResults{}, err
+	}
+
+	// Wait for the values of all hosts
+	wg.Wait()
+
+	if hostsSucceeded == 0 {
+		return index.AggregateTagValuesResults{}, resultErr.FinalError()
+	}
+
+	page := results.Page()
+	if page.Exhaustive && (!hostsExhaustive || resultErr.FinalError() != nil) {
+		// NB: the values of hosts that failed or were not exhaustive may be
+		// missing so the page cannot be considered exhaustive.
+		page.Exhaustive = false
+		if n := len(page.Values); n > 0 {
+			page.Cursor = index.NewTagValuesCursor(page.Values[n-1].Term)
+		}
+	}
+
+	// NB: every replica of a shard returns the frequencies of the values it
+	// holds, so the frequencies are approximated by their mean across replicas.
+	if replicas > 1 {
+		for i := range page.Values {
+			page.Values[i].Count = (page.Values[i].Count + replicas - 1) / replicas
+		}
+	}
+
+	return page, nil
+}
+
 // NB(prateek): the returned fetchState, if valid, still holds the lock. Its ownership
 // is transferred to the calling function, and is expected to manage the lifecycle of
 // of the object (including releasing the lock/decRef'ing it).
@@ -1213,6 +1540,10 @@ func (s *session) fetchTaggedAttemptWithRLock(
 	fetchState.incRef()       // indicate current go-routine has a reference to the fetchState
 	op.incRef()               // indicate current go-routine has a reference to the op
 	op.update(req, fetchState.completionFn)
+	op.updatePage(opts.PageSize, opts.PageToken)
+	// The host queues propagate the time remaining until the deadline so
+	// nodes can abandon the fetch once it expires
+	op.deadline = s.nowFn().Add(time.Duration(s.fetchTimeoutNanos))
 
 	fetchState.Reset(opts.StartInclusive, opts.EndExclusive, op, topoMap, s.state.majority, s.state.readLevel)
 	fetchState.Lock()
@@ -1256,6 +1587,7 @@ func (s *session) fetchIDsAttempt(
 		majority               int32
 		consistencyLevel       topology.ReadConsistencyLevel
 		fetchBatchOpsByHostIdx [][]*fetchBatchOp
+		speculations           *fetchSpeculations
 		success                = false
 	)
 
@@ -1277,6 +1609,10 @@ func (s *session) fetchIDsAttempt(
 		return nil, tsErr
 	}
 
+	// The host queues propagate the time remaining until the deadline so
+	// nodes can abandon the fetch once it expires
+	deadline := s.nowFn().Add(time.Duration(s.fetchTimeoutNanos))
+
 	s.state.RLock()
 	if s.state.status != statusOpen {
 		s.state.RUnlock()
@@ -1305,6 +1641,41 @@ func (s *session) fetchIDsAttempt(
 	consistencyLevel = s.state.readLevel
 	majority = int32(s.state.majority)
 
+	// NB: when speculatively retrying reads each ID is only fetched from the
+	// replica with the best recent latency, the remaining replicas are only
+	// fetched from if it errors or does not respond in time.
+	if s.readSpeculativeRetryEnabled &&
+		consistencyLevel == topology.ReadConsistencyLevelUnstrictMajority {
+		speculations = newFetchSpeculations(s, s.state.queues,
+			namespace.Bytes(), rangeStart, rangeEnd, deadline)
+	}
+
+	appendFetchBatchOp := func(hostIdx int, id []byte, completionFn completionFn) {
+		ops := fetchBatchOpsByHostIdx[hostIdx]
+
+		var f *fetchBatchOp
+		if len(ops) > 0 {
+			// Find the last and potentially current fetch op for this host
+			f = ops[len(ops)-1]
+		}
+		if f == nil || f.Size() >= s.fetchBatchSize {
+			// If no current fetch op or existing one is at batch capacity add one
+			// NB(r): Note that we defer to the host queue to take ownership
+			// of these ops and for returning the ops to the pool when done as
+			// they know when their use is complete.
+			f = s.pools.fetchBatchOp.Get()
+			f.IncRef()
+			fetchBatchOpsByHostIdx[hostIdx] = append(fetchBatchOpsByHostIdx[hostIdx], f)
+			f.request.RangeStart = rangeStart
+			f.request.RangeEnd = rangeEnd
+			f.request.RangeTimeType = rpc.TimeType_UNIX_NANOSECONDS
+			f.deadline = deadline
+		}
+
+		// Append IDWithNamespace to this request
+		f.append(namespace.Bytes(), id, completionFn)
+	}
+
 	// NB(prateek): namespaceAccessors tracks the number of pending accessors for nsID.
 	// It is set to incremented by `replica` for each requested ID during fetch enqueuing,
 	// and once by initial request, and is decremented for each replica retrieved, inside
@@ -1327,19 +1698,41 @@ func (s *session) fetchIDsAttempt(
 			idAccessors      int32 = 1
 			resultsLock      sync.RWMutex
 			results          []encoding.MultiReaderIterator
+			routes           []fetchRoute
+			numRoutes        int32
 			enqueued         int32
 			pending          int32
 			success          int32
 			errors           []error
 			errs             int32
+			speculation      *fetchSpeculation
 		)
 
 		// increment namespaceAccesors by 1 to indicate it still needs to be handled by the
 		// allCompletionFn for tsID.
 		atomic.AddInt32(&namespaceAccessors, 1)
 
+		releaseAccessors := func(n int32) {
+			if atomic.AddInt32(&resultsAccessors, -n) == 0 {
+				s.pools.multiReaderIteratorArray.Put(results)
+			}
+			if atomic.AddInt32(&idAccessors, -n) == 0 {
+				tsID.Finalize()
+			}
+			if atomic.AddInt32(&namespaceAccessors, -n) == 0 {
+				namespace.Finalize()
+			}
+		}
+
 		wg.Add(1)
 		allCompletionFn := func() {
+			// NB: cancelling the speculation must come before reading enqueued
+			// as sending the speculation increments enqueued.
+			speculativeSent := false
+			if speculation != nil {
+				speculativeSent = speculation.cancel()
+			}
+
 			var reportErrors []error
 			errsLen := atomic.LoadInt32(&errs)
 			if errsLen > 0 {
@@ -1351,6 +1744,9 @@ func (s *session) fetchIDsAttempt(
 			err := s.readConsistencyResult(consistencyLevel, majority, enqueued,
 				responded, errsLen, reportErrors)
 			s.incFetchMetrics(err, errsLen)
+			if err == nil && speculation != nil && !speculativeSent {
+				s.metrics.fetchPrimaryOnlySuccess.Inc(1)
+			}
 			if err != nil {
 				resultErrLock.Lock()
 				if resultErr == nil {
@@ -1378,15 +1774,7 @@ func (s *session) fetchIDsAttempt(
 				})
 				iters.SetAt(idx, iter)
 			}
-			if atomic.AddInt32(&resultsAccessors, -1) == 0 {
-				s.pools.multiReaderIteratorArray.Put(results)
-			}
-			if atomic.AddInt32(&idAccessors, -1) == 0 {
-				tsID.Finalize()
-			}
-			if atomic.AddInt32(&namespaceAccessors, -1) == 0 {
-				namespace.Finalize()
-			}
+			releaseAccessors(1)
 			wg.Done()
 		}
 		completionFn := func(result interface{}, err error) {
@@ -1401,6 +1789,13 @@ func (s *session) fetchIDsAttempt(
 				resultErrLock.Lock()
 				errors = append(errors, err)
 				resultErrLock.Unlock()
+
+				// NB: fetch from the remaining replicas as soon as the first
+				// replica errors, this must come before decrementing pending
+				// so the fetch does not terminate with just the error.
+				if speculation != nil && speculation.send() {
+					go speculations.send([]*fetchSpeculation{speculation})
+				}
 			} else {
 				slicesIter := s.pools.readerSliceOfSlicesIterator.Get()
 				slicesIter.Reset(result.([]*rpc.Segments))
@@ -1423,19 +1818,17 @@ func (s *session) fetchIDsAttempt(
 				allCompletionFn()
 			}
 
-			if atomic.AddInt32(&resultsAccessors, -1) == 0 {
-				s.pools.multiReaderIteratorArray.Put(results)
-			}
-			if atomic.AddInt32(&idAccessors, -1) == 0 {
-				tsID.Finalize()
-			}
-			if atomic.AddInt32(&namespaceAccessors, -1) == 0 {
-				namespace.Finalize()
-			}
+			releaseAccessors(1)
 		}
 
 		if err := s.state.topoMap.RouteForEach(tsID, func(hostIdx int, host topology.Host) {
 			// Inc safely as this for each is sequential
+			numRoutes++
+			if speculations != nil {
+				routes = append(routes, fetchRoute{hostIdx: hostIdx, host: host})
+				return
+			}
+
 			enqueued++
 			pending++
 			allPending++
@@ -1443,36 +1836,60 @@ func (s *session) fetchIDsAttempt(
 			namespaceAccessors++
 			idAccessors++
 
-			ops := fetchBatchOpsByHostIdx[hostIdx]
-
-			var f *fetchBatchOp
-			if len(ops) > 0 {
-				// Find the last and potentially current fetch op for this host
-				f = ops[len(ops)-1]
-			}
-			if f == nil || f.Size() >= s.fetchBatchSize {
-				// If no current fetch op or existing one is at batch capacity add one
-				// NB(r): Note that we defer to the host queue to take ownership
-				// of these ops and for returning the ops to the pool when done as
-				// they know when their use is complete.
-				f = s.pools.fetchBatchOp.Get()
-				f.IncRef()
-				fetchBatchOpsByHostIdx[hostIdx] = append(fetchBatchOpsByHostIdx[hostIdx], f)
-				f.request.RangeStart = rangeStart
-				f.request.RangeEnd = rangeEnd
-				f.request.RangeTimeType = rpc.TimeType_UNIX_NANOSECONDS
-			}
-
-			// Append IDWithNamespace to this request
-			f.append(namespace.Bytes(), tsID.Bytes(), completionFn)
+			appendFetchBatchOp(hostIdx, tsID.Bytes(), completionFn)
 		}); err != nil {
 			routeErr = err
 			break
 		}
 
-		// Once we've enqueued we know how many to expect so retrieve and set length
-		results = s.pools.multiReaderIteratorArray.Get(int(enqueued))
-		results = results[:enqueued]
+		if len(routes) > 0 {
+			primaryIdx := speculations.primary(routes)
+			primary := routes[primaryIdx]
+
+			enqueued++
+			pending++
+			allPending++
+			resultsAccessors++
+			namespaceAccessors++
+			idAccessors++
+
+			appendFetchBatchOp(primary.hostIdx, tsID.Bytes(),
+				speculations.timedCompletionFn(primary.host, speculations.start, completionFn))
+
+			if len(routes) > 1 {
+				remaining := make([]fetchRoute, 0, len(routes)-1)
+				remaining = append(remaining, routes[:primaryIdx]...)
+				remaining = append(remaining, routes[primaryIdx+1:]...)
+
+				// NB: the speculation holds a ref on the accessors until it
+				// is either sent or cancelled.
+				resultsAccessors++
+				namespaceAccessors++
+				idAccessors++
+
+				speculation = &fetchSpeculation{
+					id:           tsID.Bytes(),
+					routes:       remaining,
+					completionFn: completionFn,
+					onSend: func(n int32) {
+						enqueued += n
+						atomic.AddInt32(&pending, n)
+						atomic.AddInt32(&resultsAccessors, n)
+						atomic.AddInt32(&namespaceAccessors, n)
+						atomic.AddInt32(&idAccessors, n)
+						releaseAccessors(1)
+					},
+					onCancel: func() {
+						releaseAccessors(1)
+					},
+				}
+				speculations.add(primary, speculation)
+			}
+		}
+
+		// Once we've routed we know how many to expect so retrieve and set length
+		results = s.pools.multiReaderIteratorArray.Get(int(numRoutes))
+		results = results[:numRoutes]
 	}
 
 	if routeErr != nil {
@@ -1502,8 +1919,16 @@ func (s *session) fetchIDsAttempt(
 		return nil, enqueueErr
 	}
 
+	if speculations != nil {
+		speculations.startTimers()
+	}
+
 	wg.Wait()
 
+	if speculations != nil {
+		speculations.stopTimers()
+	}
+
 	resultErrLock.RLock()
 	retErr := resultErr
 	resultErrLock.RUnlock()
@@ -1711,7 +2136,7 @@ func (s *session) FetchBootstrapBlocksMetadataFromPeers(
 ) (PeerBlockMetadataIter, error) {
 	level := newSessionBootstrapRuntimeReadConsistencyLevel(s)
 	return s.fetchBlocksMetadataFromPeers(namespace,
-		shard, start, end, level, resultOpts, version)
+		shard, start, end, level, resultOpts, version, false)
 }
 
 func (s *session) FetchBlocksMetadataFromPeers(
@@ -1721,10 +2146,11 @@ func (s *session) FetchBlocksMetadataFromPeers(
 	consistencyLevel topology.ReadConsistencyLevel,
 	resultOpts result.Options,
 	version FetchBlocksMetadataEndpointVersion,
+	includeBufferChecksums bool,
 ) (PeerBlockMetadataIter, error) {
 	level := newStaticRuntimeReadConsistencyLevel(consistencyLevel)
-	return s.fetchBlocksMetadataFromPeers(namespace,
-		shard, start, end, level, resultOpts, version)
+	return s.fetchBlocksMetadataFromPeers(namespace, shard, start, end,
+		level, resultOpts, version, includeBufferChecksums)
 }
 
 func (s *session) fetchBlocksMetadataFromPeers(
@@ -1734,6 +2160,7 @@ func (s *session) fetchBlocksMetadataFromPeers(
 	level runtimeReadConsistencyLevel,
 	resultOpts result.Options,
 	version FetchBlocksMetadataEndpointVersion,
+	includeBufferChecksums bool,
 ) (PeerBlockMetadataIter, error) {
 	peers, err := s.peersForShard(shard)
 	if err != nil {
@@ -1748,8 +2175,9 @@ func (s *session) fetchBlocksMetadataFromPeers(
 		m     = s.newPeerMetadataStreamingProgressMetrics(shard, meta)
 	)
 	go func() {
-		errCh <- s.streamBlocksMetadataFromPeers(namespace, shard,
-			peers, start, end, level, metadataCh, resultOpts, m, version)
+		errCh <- s.streamBlocksMetadataFromPeers(namespace, shard, peers,
+			start, end, level, metadataCh, resultOpts, m, version,
+			includeBufferChecksums)
 		close(metadataCh)
 		close(errCh)
 	}()
@@ -1810,7 +2238,7 @@ func (s *session) FetchBootstrapBlocksFromPeers(
 	errCh := make(chan error, 1)
 	go func() {
 		errCh <- s.streamBlocksMetadataFromPeers(nsMetadata.ID(), shard,
-			peers, start, end, level, metadataCh, opts, progress, version)
+			peers, start, end, level, metadataCh, opts, progress, version, false)
 		close(metadataCh)
 	}()
 
@@ -1921,6 +2349,7 @@ func (s *session) streamBlocksMetadataFromPeers(
 	resultOpts result.Options,
 	progress *streamFromPeersMetrics,
 	version FetchBlocksMetadataEndpointVersion,
+	includeBufferChecksums bool,
 ) error {
 	var (
 		wg        sync.WaitGroup
@@ -1994,7 +2423,8 @@ func (s *session) streamBlocksMetadataFromPeers(
 						peer, start, end, currPageToken, metadataCh, progress)
 				case FetchBlocksMetadataEndpointV2:
 					currPageToken, err = s.streamBlocksMetadataFromPeerV2(namespace, shardID,
-						peer, start, end, currPageToken, metadataCh, resultOpts, progress)
+						peer, start, end, currPageToken, metadataCh, resultOpts, progress,
+						includeBufferChecksums)
 				default:
 					// Should never happen - we validate the version before this function is
 					// ever called
@@ -2086,7 +2516,7 @@ func (s *session) streamBlocksMetadataFromPeer(
 		req.Shard = int32(shard)
 		req.RangeStart = start.UnixNano()
 		req.RangeEnd = end.UnixNano()
-		req.Limit = int64(s.streamBlocksBatchSize)
+		req.Limit = int64(s.streamBlocksMetadataBatchSize)
 		req.PageToken = pageToken
 		req.IncludeSizes = &optionIncludeSizes
 		req.IncludeChecksums = &optionIncludeChecksums
@@ -2208,6 +2638,7 @@ func (s *session) streamBlocksMetadataFromPeerV2(
 	metadataCh chan<- receivedBlockMetadata,
 	resultOpts result.Options,
 	progress *streamFromPeersMetrics,
+	includeBufferChecksums bool,
 ) (pageToken, error) {
 	var pageToken []byte
 	if startPageToken != nil {
@@ -2251,11 +2682,14 @@ func (s *session) streamBlocksMetadataFromPeerV2(
 		req.Shard = int32(shard)
 		req.RangeStart = start.UnixNano()
 		req.RangeEnd = end.UnixNano()
-		req.Limit = int64(s.streamBlocksBatchSize)
+		req.Limit = int64(s.streamBlocksMetadataBatchSize)
 		req.PageToken = pageToken
 		req.IncludeSizes = &optionIncludeSizes
 		req.IncludeChecksums = &optionIncludeChecksums
 		req.IncludeLastRead = &optionIncludeLastRead
+		if includeBufferChecksums {
+			req.IncludeBufferChecksums = &includeBufferChecksums
+		}
 
 		progress.metadataFetchBatchCall.Inc(1)
 		result, err := client.FetchBlocksMetadataRawV2(tctx, req)
@@ -2334,6 +2768,11 @@ func (s *session) streamBlocksMetadataFromPeerV2(
 				}
 			}
 
+			var buffered bool
+			if elem.Buffered != nil {
+				buffered = *elem.Buffered
+			}
+
 			metadataCh <- receivedBlockMetadata{
 				peer:        peer,
 				id:          clonedID,
@@ -2343,6 +2782,7 @@ func (s *session) streamBlocksMetadataFromPeerV2(
 					size:     size,
 					checksum: pChecksum,
 					lastRead: lastRead,
+					buffered: buffered,
 				},
 			}
 			// Only used for logs
@@ -2403,14 +2843,29 @@ func (s *session) streamBlocksFromPeers(
 		size := peerBlocksBatchSize
 		workers := s.streamBlocksWorkers
 		drainEvery := 100 * time.Millisecond
-		queue := s.newPeerBlocksQueueFn(peer, size, drainEvery, workers,
-			func(batch []receivedBlockMetadata) {
+		queue := s.newPeerBlocksQueueFn(peer, size, s.streamBlocksMaxBlocksInFlight,
+			s.streamBlocksMaxStreams, drainEvery, workers, func(batch []receivedBlockMetadata) {
 				s.streamBlocksBatchFromPeer(nsMetadata, shard, peer, batch, opts,
 					result, enqueueCh, s.streamBlocksRetrier, progress)
 			})
 		peerQueues = append(peerQueues, queue)
 	}
 
+	// Report the blocks assigned to peers that are yet to be fetched
+	reportDone := make(chan struct{})
+	defer close(reportDone)
+	go func() {
+		for {
+			select {
+			case <-reportDone:
+				return
+			default:
+			}
+			progress.blocksInFlight.Update(float64(peerQueues.numInFlight()))
+			time.Sleep(gaugeReportInterval)
+		}
+	}()
+
 	var (
 		selected             []receivedBlockMetadata
 		pooled               selectPeersFromPerPeerBlockMetadatasPooledResources
@@ -2989,10 +3444,25 @@ func (s *session) streamBlocksBatchFromPeer(
 			}
 
 			m.fetchBlockSuccess.Inc(1)
+			m.fetchBlockBytes.Inc(blockSegmentsLen(block.Segments))
 		}
 	}
 }
 
+func blockSegmentsLen(segments *rpc.Segments) int64 {
+	if segments == nil {
+		return 0
+	}
+	var size int64
+	if merged := segments.Merged; merged != nil {
+		size += int64(len(merged.Head) + len(merged.Tail))
+	}
+	for _, unmerged := range segments.Unmerged {
+		size += int64(len(unmerged.Head) + len(unmerged.Tail))
+	}
+	return size
+}
+
 func (s *session) verifyFetchedBlock(block *rpc.Block) error {
 	if block.Err != nil {
 		return fmt.Errorf("block error from peer: %s %s", block.Err.Type.String(), block.Err.Message)
@@ -3548,7 +4018,10 @@ type receivedBlocks struct {
 
 type processFn func(batch []receivedBlockMetadata)
 
-// peerBlocksQueue is a per peer queue of blocks to be retrieved from a peer
+// peerBlocksQueue is a per peer queue of blocks to be retrieved from a peer,
+// the number of blocks assigned but not yet fetched and the number of batches
+// being fetched at once are optionally bounded to apply flow control when
+// streaming blocks from a peer
 type peerBlocksQueue struct {
 	sync.RWMutex
 	closed       bool
@@ -3558,6 +4031,8 @@ type peerBlocksQueue struct {
 	assigned     uint64
 	completed    uint64
 	maxQueueSize int
+	inFlight     chan struct{}
+	streams      chan struct{}
 	workers      xsync.WorkerPool
 	processFn    processFn
 }
@@ -3565,6 +4040,8 @@ type peerBlocksQueue struct {
 type newPeerBlocksQueueFn func(
 	peer peer,
 	maxQueueSize int,
+	maxInFlight int,
+	maxStreams int,
 	interval time.Duration,
 	workers xsync.WorkerPool,
 	processFn processFn,
@@ -3573,6 +4050,8 @@ type newPeerBlocksQueueFn func(
 func newPeerBlocksQueue(
 	peer peer,
 	maxQueueSize int,
+	maxInFlight int,
+	maxStreams int,
 	interval time.Duration,
 	workers xsync.WorkerPool,
 	processFn processFn,
@@ -3583,6 +4062,12 @@ func newPeerBlocksQueue(
 		workers:      workers,
 		processFn:    processFn,
 	}
+	if maxInFlight > 0 {
+		q.inFlight = make(chan struct{}, maxInFlight)
+	}
+	if maxStreams > 0 {
+		q.streams = make(chan struct{}, maxStreams)
+	}
 	if interval > 0 {
 		go q.drainEvery(interval)
 	}
@@ -3616,7 +4101,38 @@ func (q *peerBlocksQueue) trackCompleted(amount int) {
 	atomic.AddUint64(&q.completed, uint64(amount))
 }
 
+func (q *peerBlocksQueue) numInFlight() uint64 {
+	return atomic.LoadUint64(&q.assigned) - atomic.LoadUint64(&q.completed)
+}
+
+func (q *peerBlocksQueue) acquireInFlight() {
+	if q.inFlight == nil {
+		return
+	}
+	select {
+	case q.inFlight <- struct{}{}:
+		return
+	default:
+	}
+	// NB: The in flight window is full, drain what is queued so that the
+	// window is released as soon as the pending fetches complete rather than
+	// waiting on the next periodic drain
+	q.drain()
+	q.inFlight <- struct{}{}
+}
+
+func (q *peerBlocksQueue) releaseInFlight(amount int) {
+	if q.inFlight == nil {
+		return
+	}
+	for i := 0; i < amount; i++ {
+		<-q.inFlight
+	}
+}
+
 func (q *peerBlocksQueue) enqueue(bl receivedBlockMetadata, doneFn func()) {
+	q.acquireInFlight()
+
 	q.Lock()
 
 	if len(q.queue) == 0 && cap(q.queue) < q.maxQueueSize {
@@ -3659,6 +4175,9 @@ func (q *peerBlocksQueue) drainWithLock() {
 	doneFns := q.doneFns
 	q.queue = nil
 	q.doneFns = nil
+	if q.streams != nil {
+		q.streams <- struct{}{}
+	}
 	q.workers.Go(func() {
 		q.processFn(enqueued)
 		// Call done callbacks
@@ -3667,6 +4186,11 @@ func (q *peerBlocksQueue) drainWithLock() {
 		}
 		// Track completed blocks
 		q.trackCompleted(len(enqueued))
+		// Release the stream and the blocks from the in flight window
+		if q.streams != nil {
+			<-q.streams
+		}
+		q.releaseInFlight(len(enqueued))
 	})
 }
 
@@ -3681,6 +4205,14 @@ func (qs peerBlocksQueues) findQueue(peer peer) *peerBlocksQueue {
 	return nil
 }
 
+func (qs peerBlocksQueues) numInFlight() uint64 {
+	var n uint64
+	for _, q := range qs {
+		n += q.numInFlight()
+	}
+	return n
+}
+
 func (qs peerBlocksQueues) closeAll() {
 	for _, q := range qs {
 		q.close()
@@ -3742,6 +4274,7 @@ type blockMetadata struct {
 	size      int64
 	checksum  *uint32
 	lastRead  time.Time
+	buffered  bool
 	reattempt blockMetadataReattempt
 }
 
@@ -3849,6 +4382,7 @@ func (it *metadataIter) Next() bool {
 	it.host = m.peer.Host()
 	it.metadata = block.NewMetadata(m.id, tags, m.block.start,
 		m.block.size, m.block.checksum, m.block.lastRead)
+	it.metadata.Buffered = m.block.buffered
 	return true
 }
 
@@ -55,6 +55,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/uber/tchannel-go/thrift"
 )
 
 var (
@@ -112,6 +113,7 @@ func newSessionTestAdminOptions() AdminOptions {
 	return opts.
 		SetOrigin(host).
 		SetFetchSeriesBlocksBatchSize(2).
+		SetFetchSeriesBlocksMetadataBatchSize(2).
 		SetFetchSeriesBlocksMetadataBatchTimeout(time.Second).
 		SetFetchSeriesBlocksBatchTimeout(time.Second).
 		SetFetchSeriesBlocksBatchConcurrency(4)
@@ -153,13 +155,16 @@ func TestFetchBootstrapBlocksAllPeersSucceed(t *testing.T) {
 	session.newPeerBlocksQueueFn = func(
 		peer peer,
 		maxQueueSize int,
+		maxInFlight int,
+		maxStreams int,
 		_ time.Duration,
 		workers xsync.WorkerPool,
 		processFn processFn,
 	) *peerBlocksQueue {
 		qsMutex.Lock()
 		defer qsMutex.Unlock()
-		q := newPeerBlocksQueue(peer, maxQueueSize, 0, workers, processFn)
+		q := newPeerBlocksQueue(peer, maxQueueSize, maxInFlight, maxStreams,
+			0, workers, processFn)
 		qs = append(qs, q)
 		return q
 	}
@@ -301,13 +306,16 @@ func TestFetchBootstrapBlocksAllPeersSucceedV2(t *testing.T) {
 	session.newPeerBlocksQueueFn = func(
 		peer peer,
 		maxQueueSize int,
+		maxInFlight int,
+		maxStreams int,
 		_ time.Duration,
 		workers xsync.WorkerPool,
 		processFn processFn,
 	) *peerBlocksQueue {
 		qsMutex.Lock()
 		defer qsMutex.Unlock()
-		q := newPeerBlocksQueue(peer, maxQueueSize, 0, workers, processFn)
+		q := newPeerBlocksQueue(peer, maxQueueSize, maxInFlight, maxStreams,
+			0, workers, processFn)
 		qs = append(qs, q)
 		return q
 	}
@@ -444,13 +452,16 @@ func fetchBlocksFromPeersTestsHelper(
 	session.newPeerBlocksQueueFn = func(
 		peer peer,
 		maxQueueSize int,
+		maxInFlight int,
+		maxStreams int,
 		_ time.Duration,
 		workers xsync.WorkerPool,
 		processFn processFn,
 	) *peerBlocksQueue {
 		qsMutex.Lock()
 		defer qsMutex.Unlock()
-		q := newPeerBlocksQueue(peer, maxQueueSize, 0, workers, processFn)
+		q := newPeerBlocksQueue(peer, maxQueueSize, maxInFlight, maxStreams,
+			0, workers, processFn)
 		qs = append(qs, q)
 		return q
 	}
@@ -1669,6 +1680,142 @@ func TestStreamBlocksBatchFromPeerVerifiesBlockChecksum(t *testing.T) {
 	assert.NoError(t, session.Close())
 }
 
+func TestStreamBlocksFromPeersBoundsBlocksInFlight(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		numBlocks   = 100000
+		maxInFlight = 512
+		maxStreams  = 2
+	)
+	opts := newSessionTestAdminOptions().
+		SetFetchSeriesBlocksBatchSize(64).
+		SetFetchSeriesBlocksBatchConcurrency(8).
+		SetFetchSeriesBlocksMaxBlocksInFlightPerPeer(maxInFlight).
+		SetFetchSeriesBlocksMaxConcurrentStreamsPerPeer(maxStreams)
+	s, err := newSession(opts)
+	require.NoError(t, err)
+	session := s.(*session)
+
+	var queue *peerBlocksQueue
+	session.newPeerBlocksQueueFn = func(
+		peer peer,
+		maxQueueSize int,
+		maxInFlight int,
+		maxStreams int,
+		interval time.Duration,
+		workers xsync.WorkerPool,
+		processFn processFn,
+	) *peerBlocksQueue {
+		queue = newPeerBlocksQueue(peer, maxQueueSize, maxInFlight, maxStreams,
+			interval, workers, processFn)
+		return queue
+	}
+
+	var (
+		start     = time.Now().Truncate(blockSize).Add(-blockSize)
+		blockData = bytes.Repeat([]byte{0x1}, 1024)
+		checksum  = digest.Checksum(blockData)
+		rpcSum    = int64(checksum)
+		client    = rpc.NewMockTChanNode(ctrl)
+		mockPeer  = NewMockpeer(ctrl)
+		peers     = preparedMockPeers(mockPeer)
+		res       = &boundedBlocksResult{}
+
+		streams, maxStreamsSeen, maxQueueInFlightSeen int64
+	)
+	mockPeer.EXPECT().BorrowConnection(gomock.Any()).Do(func(fn withConnectionFn) {
+		fn(client)
+	}).Return(nil).AnyTimes()
+	client.EXPECT().FetchBlocksRaw(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ thrift.Context, req *rpc.FetchBlocksRawRequest) (*rpc.FetchBlocksRawResult_, error) {
+			defer atomic.AddInt64(&streams, -1)
+			storeMax(&maxStreamsSeen, atomic.AddInt64(&streams, 1))
+			storeMax(&maxQueueInFlightSeen, int64(queue.numInFlight()))
+
+			result := &rpc.FetchBlocksRawResult_{}
+			for _, elem := range req.Elements {
+				data := append([]byte(nil), blockData...)
+				result.Elements = append(result.Elements, &rpc.Blocks{
+					ID: elem.ID,
+					Blocks: []*rpc.Block{
+						&rpc.Block{
+							Start:    elem.Starts[0],
+							Segments: &rpc.Segments{Merged: &rpc.Segment{Head: data}},
+							Checksum: &rpcSum,
+						},
+					},
+				})
+				res.trackFetched(int64(len(data)))
+			}
+			return result, nil
+		}).AnyTimes()
+
+	metadataCh := make(chan receivedBlockMetadata, 4096)
+	go func() {
+		for i := 0; i < numBlocks; i++ {
+			metadataCh <- receivedBlockMetadata{
+				peer: peers[0],
+				id:   ident.StringID(fmt.Sprintf("foo.%d", i)),
+				block: blockMetadata{
+					start:    start,
+					size:     int64(len(blockData)),
+					checksum: &checksum,
+				},
+			}
+		}
+		close(metadataCh)
+	}()
+
+	m := session.newPeerMetadataStreamingProgressMetrics(0, resultTypeRaw)
+	session.streamBlocksFromPeers(testsNsMetadata(t), 0, testPeers(peers),
+		metadataCh, result.NewOptions(),
+		newStaticRuntimeReadConsistencyLevel(opts.BootstrapConsistencyLevel()),
+		res, m, session.passThroughBlocksMetadata)
+
+	// Assert all blocks were streamed without exceeding the memory budget
+	budget := int64(maxInFlight * len(blockData))
+	assert.Equal(t, int64(numBlocks), atomic.LoadInt64(&res.added))
+	assert.Equal(t, int64(0), atomic.LoadInt64(&res.held))
+	assert.True(t, atomic.LoadInt64(&res.maxHeld) <= budget,
+		fmt.Sprintf("max held %d exceeds budget %d", res.maxHeld, budget))
+	assert.True(t, maxQueueInFlightSeen <= int64(maxInFlight))
+	assert.True(t, maxStreamsSeen <= int64(maxStreams))
+}
+
+// boundedBlocksResult tracks the bytes fetched from peers that have not yet
+// been handed to the result
+type boundedBlocksResult struct {
+	added   int64
+	held    int64
+	maxHeld int64
+}
+
+func (r *boundedBlocksResult) trackFetched(size int64) {
+	storeMax(&r.maxHeld, atomic.AddInt64(&r.held, size))
+}
+
+func (r *boundedBlocksResult) addBlockFromPeer(
+	id ident.ID,
+	encodedTags checked.Bytes,
+	peer topology.Host,
+	block *rpc.Block,
+) error {
+	atomic.AddInt64(&r.held, -int64(len(block.Segments.Merged.Head)))
+	atomic.AddInt64(&r.added, 1)
+	return nil
+}
+
+func storeMax(addr *int64, value int64) {
+	for {
+		curr := atomic.LoadInt64(addr)
+		if value <= curr || atomic.CompareAndSwapInt64(addr, curr, value) {
+			return
+		}
+	}
+}
+
 func TestBlocksResultAddBlockFromPeerReadMerged(t *testing.T) {
 	opts := newSessionTestAdminOptions()
 	bopts := newResultTestOptions()
@@ -1878,9 +2025,10 @@ func mockPeerBlocksQueues(peers []peer, opts AdminOptions) peerBlocksQueues {
 	for _, peer := range peers {
 		size := opts.FetchSeriesBlocksBatchSize()
 		drainEvery := 100 * time.Millisecond
-		queue := newPeerBlocksQueue(peer, size, drainEvery, workers, func(batch []receivedBlockMetadata) {
-			// No-op
-		})
+		queue := newPeerBlocksQueue(peer, size, 0, 0, drainEvery, workers,
+			func(batch []receivedBlockMetadata) {
+				// No-op
+			})
 		peerQueues = append(peerQueues, queue)
 	}
 	return peerQueues
@@ -2095,7 +2243,7 @@ func expectFetchMetadataAndReturn(
 	result []testBlocksMetadata,
 	opts AdminOptions,
 ) {
-	batchSize := opts.FetchSeriesBlocksBatchSize()
+	batchSize := opts.FetchSeriesBlocksMetadataBatchSize()
 	totalCalls := int(math.Ceil(float64(len(result)) / float64(batchSize)))
 	includeSizes := true
 
@@ -2149,7 +2297,7 @@ func expectFetchMetadataAndReturnV2(
 	result []testBlocksMetadata,
 	opts AdminOptions,
 ) {
-	batchSize := opts.FetchSeriesBlocksBatchSize()
+	batchSize := opts.FetchSeriesBlocksMetadataBatchSize()
 	totalCalls := int(math.Ceil(float64(len(result)) / float64(batchSize)))
 	includeSizes := true
 
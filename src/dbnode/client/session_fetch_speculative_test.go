@@ -0,0 +1,290 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/topology"
+	"github.com/m3db/m3x/ident"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+type testSpeculativeEnqueueFn func(host topology.Host, op *fetchBatchOp, first bool)
+
+func newSpeculativeSessionTestOptions(
+	scope tally.Scope,
+	maxDelay time.Duration,
+) Options {
+	opts := newSessionTestOptions().
+		SetReadConsistencyLevel(topology.ReadConsistencyLevelUnstrictMajority).
+		SetReadSpeculativeRetryEnabled(true).
+		SetReadSpeculativeRetryMaxDelay(maxDelay)
+	return opts.SetInstrumentOptions(opts.InstrumentOptions().
+		SetMetricsScope(scope))
+}
+
+func mockSpeculativeHostQueues(
+	ctrl *gomock.Controller,
+	s *session,
+	enqueueFn testSpeculativeEnqueueFn,
+) *testSpeculativeEnqueues {
+	enqueues := &testSpeculativeEnqueues{}
+	s.newHostQueueFn = func(
+		host topology.Host,
+		opts hostQueueOpts,
+	) (hostQueue, error) {
+		hostQueue := NewMockhostQueue(ctrl)
+		hostQueue.EXPECT().Open()
+		hostQueue.EXPECT().Host().Return(host).AnyTimes()
+		hostQueue.EXPECT().Healthy().Return(true).AnyTimes()
+		hostQueue.EXPECT().ConnectionCount().
+			Return(opts.opts.MinConnectionCount()).AnyTimes()
+		hostQueue.EXPECT().Enqueue(gomock.Any()).Do(func(op op) error {
+			fetch := op.(*fetchBatchOp)
+			enqueueFn(host, fetch, enqueues.add(host))
+			return nil
+		}).Return(nil).AnyTimes()
+		hostQueue.EXPECT().Close()
+		return hostQueue, nil
+	}
+	return enqueues
+}
+
+type testSpeculativeEnqueues struct {
+	sync.Mutex
+	hosts []string
+}
+
+func (e *testSpeculativeEnqueues) add(host topology.Host) bool {
+	e.Lock()
+	defer e.Unlock()
+	e.hosts = append(e.hosts, host.ID())
+	return len(e.hosts) == 1
+}
+
+func (e *testSpeculativeEnqueues) hostIDs() []string {
+	e.Lock()
+	defer e.Unlock()
+	return append([]string(nil), e.hosts...)
+}
+
+func testSpeculativeFetches(start time.Time) testFetches {
+	return testFetches([]testFetch{
+		{"foo", []testValue{
+			{1.0, start.Add(1 * time.Second), xtime.Second, []byte{1, 2, 3}},
+			{2.0, start.Add(2 * time.Second), xtime.Second, nil},
+			{3.0, start.Add(3 * time.Second), xtime.Second, nil},
+		}},
+	})
+}
+
+func TestSessionFetchIDsSpeculativeRetryPrimaryOnly(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	scope := tally.NewTestScope("", nil)
+	opts := newSpeculativeSessionTestOptions(scope, time.Minute)
+	s, err := newSession(opts)
+	require.NoError(t, err)
+	session := s.(*session)
+
+	// Prefer the second host as it has the best recent latency.
+	for i, latency := range []time.Duration{20 * time.Second, 10 * time.Second, 20 * time.Second} {
+		host := topology.NewHost(testHostName(i), fmt.Sprintf("%s:9000", testHostName(i)))
+		session.hostLatencies.record(host, latency)
+	}
+
+	start := time.Now().Truncate(time.Hour)
+	end := start.Add(2 * time.Hour)
+	fetches := testSpeculativeFetches(start)
+
+	enqueues := mockSpeculativeHostQueues(ctrl, session,
+		func(host topology.Host, op *fetchBatchOp, first bool) {
+			go fulfillTszFetchBatchOps(t, fetches, []*fetchBatchOp{op}, 0)
+		})
+
+	require.NoError(t, session.Open())
+
+	results, err := session.FetchIDs(ident.StringID(testNamespaceName),
+		fetches.IDsIter(), start, end)
+	require.NoError(t, err)
+	assertFetchResults(t, start, end, fetches, results)
+
+	assert.Equal(t, []string{testHostName(1)}, enqueues.hostIDs())
+
+	counters := scope.Snapshot().Counters()
+	assert.Equal(t, int64(1), counters["fetch.primary-only-success+"].Value())
+	_, ok := counters["fetch.speculative-sent+"]
+	assert.False(t, ok)
+
+	assert.NoError(t, session.Close())
+}
+
+func TestSessionFetchIDsSpeculativeRetryPrimaryErrorsImmediately(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// NB: use a max delay well beyond the test timeout so that the fan out
+	// can only be triggered by the first replica erroring.
+	scope := tally.NewTestScope("", nil)
+	opts := newSpeculativeSessionTestOptions(scope, time.Hour)
+	s, err := newSession(opts)
+	require.NoError(t, err)
+	session := s.(*session)
+
+	start := time.Now().Truncate(time.Hour)
+	end := start.Add(2 * time.Hour)
+	fetches := testSpeculativeFetches(start)
+
+	enqueues := mockSpeculativeHostQueues(ctrl, session,
+		func(host topology.Host, op *fetchBatchOp, first bool) {
+			failures := 0
+			if first {
+				failures = 1
+			}
+			go fulfillTszFetchBatchOps(t, fetches, []*fetchBatchOp{op}, failures)
+		})
+
+	require.NoError(t, session.Open())
+
+	results, err := session.FetchIDs(ident.StringID(testNamespaceName),
+		fetches.IDsIter(), start, end)
+	require.NoError(t, err)
+	assertFetchResults(t, start, end, fetches, results)
+
+	hostIDs := enqueues.hostIDs()
+	require.Equal(t, sessionTestReplicas, len(hostIDs))
+	assert.NotContains(t, hostIDs[1:], hostIDs[0])
+
+	counters := scope.Snapshot().Counters()
+	assert.Equal(t, int64(1), counters["fetch.speculative-sent+"].Value())
+	assert.Equal(t, int64(1), counters["fetch.success+"].Value())
+	_, ok := counters["fetch.primary-only-success+"]
+	assert.False(t, ok)
+
+	assert.NoError(t, session.Close())
+}
+
+func TestSessionFetchIDsSpeculativeRetryPrimarySlow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	scope := tally.NewTestScope("", nil)
+	opts := newSpeculativeSessionTestOptions(scope, 10*time.Millisecond)
+	s, err := newSession(opts)
+	require.NoError(t, err)
+	session := s.(*session)
+
+	start := time.Now().Truncate(time.Hour)
+	end := start.Add(2 * time.Hour)
+	fetches := testSpeculativeFetches(start)
+
+	var (
+		primaryLock sync.Mutex
+		primary     *fetchBatchOp
+	)
+	enqueues := mockSpeculativeHostQueues(ctrl, session,
+		func(host topology.Host, op *fetchBatchOp, first bool) {
+			if first {
+				// Hold the first replica's response until after the fetch
+				// completes from the remaining replicas.
+				primaryLock.Lock()
+				primary = op
+				primaryLock.Unlock()
+				return
+			}
+			go fulfillTszFetchBatchOps(t, fetches, []*fetchBatchOp{op}, 0)
+		})
+
+	require.NoError(t, session.Open())
+
+	results, err := session.FetchIDs(ident.StringID(testNamespaceName),
+		fetches.IDsIter(), start, end)
+	require.NoError(t, err)
+	assertFetchResults(t, start, end, fetches, results)
+
+	assert.Equal(t, sessionTestReplicas, len(enqueues.hostIDs()))
+
+	counters := scope.Snapshot().Counters()
+	assert.Equal(t, int64(1), counters["fetch.speculative-sent+"].Value())
+	_, ok := counters["fetch.primary-only-success+"]
+	assert.False(t, ok)
+
+	primaryLock.Lock()
+	require.NotNil(t, primary)
+	fulfillTszFetchBatchOps(t, fetches, []*fetchBatchOp{primary}, 0)
+	primaryLock.Unlock()
+
+	assert.NoError(t, session.Close())
+}
+
+func TestFetchSpeculationCancelsSentFetches(t *testing.T) {
+	newSentSpeculation := func() *fetchSpeculation {
+		speculation := &fetchSpeculation{
+			onSend:   func(int32) {},
+			onCancel: func() {},
+		}
+		require.True(t, speculation.send())
+		return speculation
+	}
+
+	var (
+		first   = newSentSpeculation()
+		second  = newSentSpeculation()
+		shared  = newFetchCancellation()
+		single  = newFetchCancellation()
+		cancels = make(map[*fetchCancellation]int)
+	)
+	first.register(shared)
+	second.register(shared)
+	first.register(single)
+	shared.release()
+	single.release()
+
+	// Both fetches are in flight once sent.
+	require.True(t, shared.start(func() { cancels[shared]++ }))
+	require.True(t, single.start(func() { cancels[single]++ }))
+
+	// The fetch of a single series is cancelled once it completes, whereas
+	// a fetch shared with another series waits for both to complete.
+	require.True(t, first.cancel())
+	assert.Equal(t, 1, cancels[single])
+	assert.Equal(t, 0, cancels[shared])
+
+	require.True(t, second.cancel())
+	assert.Equal(t, 1, cancels[shared])
+
+	// Fetches added once their series have completed are never sent.
+	late := newFetchCancellation()
+	first.register(late)
+	late.release()
+	assert.False(t, late.start(func() { cancels[late]++ }))
+	assert.Equal(t, 0, cancels[late])
+}
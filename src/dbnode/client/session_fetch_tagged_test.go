@@ -21,6 +21,7 @@
 package client
 
 import (
+	"bytes"
 	"fmt"
 	"strings"
 	"sync"
@@ -42,7 +43,7 @@ import (
 )
 
 var (
-	testSessionFetchTaggedQuery     = index.Query{idx.NewTermQuery([]byte("a"), []byte("b"))}
+	testSessionFetchTaggedQuery     = index.Query{idx.MustCreateTermQuery([]byte("a"), []byte("b"))}
 	testSessionFetchTaggedQueryOpts = func(t0, t1 time.Time) index.QueryOptions {
 		return index.QueryOptions{StartInclusive: t0, EndExclusive: t1}
 	}
@@ -538,6 +539,113 @@ func TestSessionFetchTaggedMergeWithRetriesTest(t *testing.T) {
 	assert.NoError(t, session.Close())
 }
 
+func TestSessionFetchTaggedPagesSizeNotPositive(t *testing.T) {
+	opts := newSessionTestOptions()
+	s, err := newSession(opts)
+	require.NoError(t, err)
+
+	_, err = s.FetchTaggedPages(ident.StringID("namespace"),
+		testSessionFetchTaggedQuery, testSessionFetchTaggedQueryOpts(time.Time{}, time.Time{}))
+	require.Equal(t, errFetchTaggedPageSizeNotPositive, err)
+}
+
+func TestSessionFetchTaggedPages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newSessionTestOptions()
+	opts = opts.SetReadConsistencyLevel(topology.ReadConsistencyLevelAll)
+	s, err := newSession(opts)
+	assert.NoError(t, err)
+	session := s.(*session)
+
+	start := time.Now().Truncate(time.Hour)
+	end := start.Add(2 * time.Hour)
+
+	const pageSize = 3
+	var (
+		all = newTestSerieses(1, 9)
+		odd testSerieses
+		th  = newTestFetchTaggedHelper(t)
+	)
+	all.addDatapoints(100, start, end)
+	for i := 0; i < len(all); i += 2 {
+		odd = append(odd, all[i])
+	}
+
+	topoInit := opts.TopologyInitializer()
+	topoWatch, err := topoInit.Init()
+	require.NoError(t, err)
+	topoMap := topoWatch.Get()
+	require.Equal(t, 3, topoMap.HostsLen()) // the code below assumes this
+
+	// NB: each host serves the page of its own series following the requested
+	// token, the same way the server pages the results of its index.
+	pageEnqueue := func(series testSerieses) testEnqueue {
+		return testEnqueue{
+			enqueueFn: func(idx int, op op) {
+				fetchOp := op.(*fetchTaggedOp)
+				require.Equal(t, pageSize, fetchOp.pageSize)
+				var (
+					page  testSerieses
+					token []byte
+				)
+				for _, s := range series {
+					if fetchOp.pageToken != nil && bytes.Compare(s.id.Bytes(), fetchOp.pageToken) <= 0 {
+						continue
+					}
+					if len(page) == fetchOp.pageSize {
+						token = page[len(page)-1].id.Bytes()
+						break
+					}
+					page = append(page, s)
+				}
+				go func() {
+					op.CompletionFn()(fetchTaggedResultAccumulatorOpts{
+						host:      topoMap.Hosts()[idx],
+						response:  page.toRPCResult(th, start, true),
+						pageToken: token,
+					}, nil)
+				}()
+			},
+		}
+	}
+	pageEnqueues := func(series testSerieses) *testHostQueueOps {
+		return &testHostQueueOps{
+			enqueues: []testEnqueue{pageEnqueue(series), pageEnqueue(series), pageEnqueue(series)},
+		}
+	}
+	mockExtendedHostQueues(
+		t, ctrl, session, sessionTestReplicas,
+		testHostQueueOpsByHost{
+			testHostName(0): pageEnqueues(all),
+			testHostName(1): pageEnqueues(odd),
+			testHostName(2): pageEnqueues(nil),
+		})
+
+	assert.NoError(t, session.Open())
+
+	qOpts := testSessionFetchTaggedQueryOpts(start, end)
+	qOpts.PageSize = pageSize
+	iter, err := session.FetchTaggedPages(ident.StringID("namespace"),
+		testSessionFetchTaggedQuery, qOpts)
+	require.NoError(t, err)
+
+	numPages := 0
+	for iter.Next() {
+		iters, exhaustive := iter.Current()
+		require.True(t, exhaustive)
+		require.True(t, iters.Len() <= pageSize)
+		all[numPages*pageSize:(numPages+1)*pageSize].assertMatchesEncodingIters(t, iters)
+		numPages++
+	}
+	require.NoError(t, iter.Err())
+	require.Equal(t, 3, numPages)
+	iter.Close()
+
+	assert.NoError(t, session.Close())
+}
+
 func injectLeakcheckFetchTaggedAttempPool(session *session) *leakcheckFetchTaggedAttemptPool {
 	leakPool := newLeakcheckFetchTaggedAttemptPool(leakcheckFetchTaggedAttemptPoolOpts{}, session.pools.fetchTaggedAttempt)
 	session.pools.fetchTaggedAttempt = leakPool
@@ -437,6 +437,9 @@ func prepareTestFetchEnqueues(
 	enqueueFn := func(idx int, op op) {
 		fetch, ok := op.(*fetchBatchOp)
 		assert.True(t, ok)
+		// The deadline is propagated for nodes to abandon the fetch
+		assert.False(t, fetch.deadline.IsZero())
+		assert.False(t, fetch.deadline.After(time.Now().Add(session.opts.FetchRequestTimeout())))
 		fetchBatchOps = append(fetchBatchOps, fetch)
 	}
 
@@ -320,6 +320,7 @@ func mockHostQueues(
 		hostQueue := NewMockhostQueue(ctrl)
 		hostQueue.EXPECT().Open()
 		hostQueue.EXPECT().Host().Return(host).AnyTimes()
+		hostQueue.EXPECT().Healthy().Return(true).AnyTimes()
 		// Take two attempts to establish min connection count
 		hostQueue.EXPECT().ConnectionCount().Return(0).Times(sessionTestShards)
 		hostQueue.EXPECT().ConnectionCount().Return(opts.opts.MinConnectionCount()).Times(sessionTestShards)
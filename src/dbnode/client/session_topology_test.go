@@ -119,6 +119,7 @@ func TestSessionTopologyChangeCreatesNewClosesOldHostQueues(t *testing.T) {
 
 		queue.EXPECT().Open()
 		queue.EXPECT().Host().Return(host).AnyTimes()
+		queue.EXPECT().Healthy().Return(true).AnyTimes()
 		queue.EXPECT().ConnectionCount().Return(opts.opts.MinConnectionCount()).AnyTimes()
 		queue.EXPECT().Close().Do(func() {
 			closedQueues.add(host.ID(), queue)
@@ -496,6 +496,91 @@ func testWriteTaggedConsistencyLevel(
 	}
 }
 
+func TestSessionWriteTaggedBatchReplicaRejectsHalf(t *testing.T) {
+	// The consistency level is evaluated per element, a single replica
+	// rejecting an element only fails it if every replica must succeed.
+	testWriteTaggedBatchReplicaRejectsHalf(t, topology.ConsistencyLevelAll, []int{0, 2})
+	testWriteTaggedBatchReplicaRejectsHalf(t, topology.ConsistencyLevelMajority, nil)
+}
+
+func testWriteTaggedBatchReplicaRejectsHalf(
+	t *testing.T,
+	level topology.ConsistencyLevel,
+	expectedFailed []int,
+) {
+	ctrl := gomock.NewController(xtest.Reporter{t})
+	defer ctrl.Finish()
+
+	opts := newSessionTestOptions().SetWriteConsistencyLevel(level)
+	session := newTestSession(t, opts).(*session)
+
+	var (
+		start  = time.Now().Truncate(time.Second)
+		writes []TaggedWrite
+		hosts  []topology.Host
+	)
+	for i := 0; i < 4; i++ {
+		writes = append(writes, TaggedWrite{
+			ID:        ident.StringID(fmt.Sprintf("foo%d", i)),
+			Tags:      ident.NewTagsIterator(ident.NewTags(ident.StringTag("abc", "def"))),
+			Timestamp: start.Add(time.Duration(i) * time.Second),
+			Value:     float64(i),
+			Unit:      xtime.Second,
+		})
+	}
+
+	// The first replica rejects every other element of the batch, one as a
+	// bad request and the other with a retryable error.
+	rejectErrs := map[string]error{
+		"foo0": &rpc.Error{
+			Type:    rpc.ErrorType_BAD_REQUEST,
+			Message: "expected bad request error",
+		},
+		"foo2": errors.New("expected retryable error"),
+	}
+	enqueueFn := func(idx int, op op) {
+		write, ok := op.(*writeTaggedOperation)
+		require.True(t, ok)
+		var err error
+		if idx == 0 {
+			err = rejectErrs[string(write.request.ID)]
+		}
+		go func() {
+			op.CompletionFn()(hosts[idx], err)
+		}()
+	}
+	mockHostQueues(ctrl, session, sessionTestReplicas, []testEnqueueFn{
+		enqueueFn, enqueueFn, enqueueFn, enqueueFn,
+	})
+
+	require.NoError(t, session.Open())
+
+	session.state.RLock()
+	hosts = session.state.topoMap.Hosts()
+	session.state.RUnlock()
+
+	err := session.WriteTaggedBatch(ident.StringID("testNs"), writes)
+	if len(expectedFailed) == 0 {
+		require.NoError(t, err)
+		require.NoError(t, session.Close())
+		return
+	}
+
+	batchErr, ok := err.(BatchError)
+	require.True(t, ok)
+	elemErrs := batchErr.Errors()
+	require.Equal(t, len(expectedFailed), len(elemErrs))
+	for i, idx := range expectedFailed {
+		assert.Equal(t, idx, elemErrs[i].Index)
+	}
+	assert.True(t, IsBadRequestError(elemErrs[0].Err))
+	assert.False(t, elemErrs[0].Retryable)
+	assert.False(t, IsBadRequestError(elemErrs[1].Err))
+	assert.True(t, elemErrs[1].Retryable)
+
+	require.NoError(t, session.Close())
+}
+
 type writeTaggedStub struct {
 	ns         ident.ID
 	id         ident.ID
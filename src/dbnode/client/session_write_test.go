@@ -30,6 +30,7 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+	"github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/topology"
 	xmetrics "github.com/m3db/m3/src/dbnode/x/metrics"
 	xerrors "github.com/m3db/m3x/errors"
@@ -290,6 +291,110 @@ func TestSessionWriteConsistencyLevelOne(t *testing.T) {
 	testWriteConsistencyLevel(t, ctrl, level, 0, 3, outcomeFail)
 }
 
+func TestSessionWriteNamespaceConsistencyLevelOverride(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newSessionTestOptions().
+		SetWriteConsistencyLevel(topology.ConsistencyLevelAll)
+	session := newTestSession(t, opts).(*session)
+	session.SetRuntimeOptions(runtime.NewOptions().
+		SetClientWriteConsistencyLevel(topology.ConsistencyLevelAll).
+		SetClientNamespaceWriteConsistencyLevels(map[string]topology.ConsistencyLevel{
+			"testNs": topology.ConsistencyLevelOne,
+		}))
+
+	session.state.RLock()
+	assert.Equal(t, topology.ConsistencyLevelOne,
+		session.writeLevelWithRLock(ident.StringID("testNs")))
+	assert.Equal(t, topology.ConsistencyLevelAll,
+		session.writeLevelWithRLock(ident.StringID("otherNs")))
+	session.state.RUnlock()
+
+	var completionFn completionFn
+	enqueueWg := mockHostQueues(ctrl, session, sessionTestReplicas, []testEnqueueFn{func(idx int, op op) {
+		completionFn = op.CompletionFn()
+	}})
+
+	require.NoError(t, session.Open())
+
+	// A single successful replica meets the overridden consistency level of one.
+	var (
+		resultErr error
+		writeWg   sync.WaitGroup
+	)
+	writeWg.Add(1)
+	go func() {
+		resultErr = session.Write(ident.StringID("testNs"), ident.StringID("foo"),
+			time.Now(), 1.0, xtime.Second, nil)
+		writeWg.Done()
+	}()
+
+	enqueueWg.Wait()
+	host := session.state.topoMap.Hosts()[0]
+	completionFn(host, nil)
+	for i := 1; i < sessionTestReplicas; i++ {
+		completionFn(host, errors.New("a specific write error"))
+	}
+	writeWg.Wait()
+	assert.NoError(t, resultErr)
+
+	assert.NoError(t, session.Close())
+}
+
+func TestSessionWriteSkipsUnhealthyHosts(t *testing.T) {
+	testSessionWriteSkipsUnhealthyHosts(t, topology.ConsistencyLevelAll, true)
+	testSessionWriteSkipsUnhealthyHosts(t, topology.ConsistencyLevelMajority, false)
+}
+
+func testSessionWriteSkipsUnhealthyHosts(
+	t *testing.T,
+	level topology.ConsistencyLevel,
+	expectErr bool,
+) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newSessionTestOptions().SetWriteConsistencyLevel(level)
+	session := newTestSession(t, opts).(*session)
+
+	// The first host is unhealthy and should never have the write enqueued
+	idx := 0
+	session.newHostQueueFn = func(
+		host topology.Host,
+		opts hostQueueOpts,
+	) (hostQueue, error) {
+		healthy := idx != 0
+		hostQueue := NewMockhostQueue(ctrl)
+		hostQueue.EXPECT().Open()
+		hostQueue.EXPECT().Host().Return(host).AnyTimes()
+		hostQueue.EXPECT().Healthy().Return(healthy).AnyTimes()
+		hostQueue.EXPECT().ConnectionCount().Return(opts.opts.MinConnectionCount()).AnyTimes()
+		if healthy {
+			hostQueue.EXPECT().Enqueue(gomock.Any()).Do(func(op op) error {
+				go op.CompletionFn()(host, nil)
+				return nil
+			}).Return(nil)
+		}
+		hostQueue.EXPECT().Close()
+		idx++
+		return hostQueue, nil
+	}
+
+	require.NoError(t, session.Open())
+
+	w := newWriteStub()
+	err := session.Write(w.ns, w.id, w.t, w.value, w.unit, w.annotation)
+	if expectErr {
+		require.Error(t, err)
+		assert.True(t, strings.Contains(err.Error(), "unhealthy"))
+	} else {
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, session.Close())
+}
+
 func testWriteConsistencyLevel(
 	t *testing.T,
 	ctrl *gomock.Controller,
@@ -66,6 +66,11 @@ type Session interface {
 	// WriteTagged value to the database for an ID and given tags.
 	WriteTagged(namespace, id ident.ID, tags ident.TagIterator, t time.Time, value float64, unit xtime.Unit, annotation []byte) error
 
+	// WriteTaggedBatch writes a batch of tagged values to the database, the
+	// write consistency level is evaluated for each element of the batch and
+	// if any elements fail a BatchError describing them is returned.
+	WriteTaggedBatch(namespace ident.ID, writes []TaggedWrite) error
+
 	// Fetch values from the database for an ID
 	Fetch(namespace, id ident.ID, startInclusive, endExclusive time.Time) (encoding.SeriesIterator, error)
 
@@ -78,6 +83,14 @@ type Session interface {
 	// FetchTaggedIDs resolves the provided query to known IDs.
 	FetchTaggedIDs(namespace ident.ID, q index.Query, opts index.QueryOptions) (iter TaggedIDsIterator, exhaustive bool, err error)
 
+	// FetchTaggedPages resolves the provided query to known IDs, and returns an iterator which
+	// lazily fetches the data for them a page of opts.PageSize IDs at a time.
+	FetchTaggedPages(namespace ident.ID, q index.Query, opts index.QueryOptions) (FetchTaggedPagesIterator, error)
+
+	// AggregateTagValues returns a page of the values of a tag across the cluster in ascending
+	// order, along with approximate counts of the series with each value.
+	AggregateTagValues(namespace ident.ID, opts index.AggregateTagValuesOptions) (index.AggregateTagValuesResults, error)
+
 	// ShardID returns the given shard for an ID for callers
 	// to easily discern what shard is failing when operations
 	// for given IDs begin failing
@@ -90,6 +103,16 @@ type Session interface {
 	Close() error
 }
 
+// TaggedWrite is a single tagged write of a batch of writes.
+type TaggedWrite struct {
+	ID         ident.ID
+	Tags       ident.TagIterator
+	Timestamp  time.Time
+	Value      float64
+	Unit       xtime.Unit
+	Annotation []byte
+}
+
 // TaggedIDsIterator iterates over a collection of IDs with associated tags and namespace.
 type TaggedIDsIterator interface {
 	// Next returns whether there are more items in the collection.
@@ -106,6 +129,25 @@ type TaggedIDsIterator interface {
 	Finalize()
 }
 
+// FetchTaggedPagesIterator iterates over the pages of a fetch tagged query, fetching each
+// page from the cluster as it's requested.
+type FetchTaggedPagesIterator interface {
+	// Next fetches the next page, returning false once every page has been fetched
+	// or an error was encountered.
+	Next() bool
+
+	// Current returns the series of the current page in ascending order of ID and
+	// whether the page is exhaustive. The series remain valid until Next() or
+	// Close() is called.
+	Current() (results encoding.SeriesIterators, exhaustive bool)
+
+	// Err returns any error encountered.
+	Err() error
+
+	// Close releases any held resources.
+	Close()
+}
+
 // AdminClient can create administration sessions
 type AdminClient interface {
 	Client
@@ -181,7 +223,8 @@ type AdminSession interface {
 	) (PeerBlockMetadataIter, error)
 
 	// FetchBlocksMetadataFromPeers will fetch the blocks metadata from
-	// available peers
+	// available peers, optionally asking peers to also checksum blocks
+	// still in their buffer
 	FetchBlocksMetadataFromPeers(
 		namespace ident.ID,
 		shard uint32,
@@ -189,6 +232,7 @@ type AdminSession interface {
 		consistencyLevel topology.ReadConsistencyLevel,
 		result result.Options,
 		version FetchBlocksMetadataEndpointVersion,
+		includeBufferChecksums bool,
 	) (PeerBlockMetadataIter, error)
 
 	// FetchBlocksFromPeers will fetch the required blocks from the
@@ -312,6 +356,16 @@ type Options interface {
 	// BackgroundConnectStutter returns the backgroundConnectStutter
 	BackgroundConnectStutter() time.Duration
 
+	// SetBackgroundHealthCheckEnabled sets whether connections are health
+	// checked in the background, when disabled hosts whose connections are
+	// failing health checks are also no longer excluded from writes
+	SetBackgroundHealthCheckEnabled(value bool) Options
+
+	// BackgroundHealthCheckEnabled returns whether connections are health
+	// checked in the background, when disabled hosts whose connections are
+	// failing health checks are also no longer excluded from writes
+	BackgroundHealthCheckEnabled() bool
+
 	// SetBackgroundHealthCheckInterval sets the background health check interval
 	SetBackgroundHealthCheckInterval(value time.Duration) Options
 
@@ -402,6 +456,33 @@ type Options interface {
 	// FetchBatchSize returns the fetchBatchSize
 	FetchBatchSize() int
 
+	// SetReadSpeculativeRetryEnabled sets whether fetches at the unstrict
+	// majority read consistency level are first sent to a single replica,
+	// only fanning out to the remaining replicas if it errors or does not
+	// respond within the speculative retry delay.
+	SetReadSpeculativeRetryEnabled(value bool) Options
+
+	// ReadSpeculativeRetryEnabled returns whether fetches at the unstrict
+	// majority read consistency level are speculatively retried.
+	ReadSpeculativeRetryEnabled() bool
+
+	// SetReadSpeculativeRetryPercentile sets the percentile of recent latencies
+	// to the first replica after which the remaining replicas are fetched from.
+	SetReadSpeculativeRetryPercentile(value float64) Options
+
+	// ReadSpeculativeRetryPercentile returns the percentile of recent latencies
+	// to the first replica after which the remaining replicas are fetched from.
+	ReadSpeculativeRetryPercentile() float64
+
+	// SetReadSpeculativeRetryMaxDelay sets the maximum delay before fetching
+	// from the remaining replicas, this is also used as the delay for hosts
+	// with no recent latency samples.
+	SetReadSpeculativeRetryMaxDelay(value time.Duration) Options
+
+	// ReadSpeculativeRetryMaxDelay returns the maximum delay before fetching
+	// from the remaining replicas.
+	ReadSpeculativeRetryMaxDelay() time.Duration
+
 	// SetWriteOpPoolSize sets the writeOperationPoolSize
 	SetWriteOpPoolSize(value int) Options
 
@@ -503,6 +584,12 @@ type AdminOptions interface {
 	// FetchSeriesBlocksBatchSize gets the batch size for fetching series blocks in batch
 	FetchSeriesBlocksBatchSize() int
 
+	// SetFetchSeriesBlocksMetadataBatchSize sets the page size for fetching series blocks metadata
+	SetFetchSeriesBlocksMetadataBatchSize(value int) AdminOptions
+
+	// FetchSeriesBlocksMetadataBatchSize gets the page size for fetching series blocks metadata
+	FetchSeriesBlocksMetadataBatchSize() int
+
 	// SetFetchSeriesBlocksMetadataBatchTimeout sets the timeout for fetching series blocks metadata in batch
 	SetFetchSeriesBlocksMetadataBatchTimeout(value time.Duration) AdminOptions
 
@@ -521,6 +608,24 @@ type AdminOptions interface {
 	// FetchSeriesBlocksBatchConcurrency gets the concurrency for fetching series blocks in batch
 	FetchSeriesBlocksBatchConcurrency() int
 
+	// SetFetchSeriesBlocksMaxBlocksInFlightPerPeer sets the max number of blocks
+	// assigned to be fetched from a single peer that have not yet been fetched,
+	// once reached no more metadata is consumed until blocks are fetched which
+	// bounds the memory used when streaming blocks from peers
+	SetFetchSeriesBlocksMaxBlocksInFlightPerPeer(value int) AdminOptions
+
+	// FetchSeriesBlocksMaxBlocksInFlightPerPeer gets the max number of blocks
+	// assigned to be fetched from a single peer that have not yet been fetched
+	FetchSeriesBlocksMaxBlocksInFlightPerPeer() int
+
+	// SetFetchSeriesBlocksMaxConcurrentStreamsPerPeer sets the max number of
+	// batches of blocks being fetched concurrently from a single peer
+	SetFetchSeriesBlocksMaxConcurrentStreamsPerPeer(value int) AdminOptions
+
+	// FetchSeriesBlocksMaxConcurrentStreamsPerPeer gets the max number of
+	// batches of blocks being fetched concurrently from a single peer
+	FetchSeriesBlocksMaxConcurrentStreamsPerPeer() int
+
 	// SetStreamBlocksRetrier sets the retrier for streaming blocks
 	SetStreamBlocksRetrier(value xretry.Retrier) AdminOptions
 
@@ -556,6 +661,10 @@ type hostQueue interface {
 	// ConnectionCount gets the current open connection count
 	ConnectionCount() int
 
+	// Healthy returns whether the host has a connection that is passing
+	// health checks, writes are not sent to unhealthy hosts
+	Healthy() bool
+
 	// ConnectionPool gets the connection pool
 	ConnectionPool() connectionPool
 
@@ -575,6 +684,10 @@ type connectionPool interface {
 	// ConnectionCount gets the current open connection count
 	ConnectionCount() int
 
+	// Healthy returns whether the pool has a connection that is passing
+	// health checks, always true if background health checks are disabled
+	Healthy() bool
+
 	// NextClient gets the next client for use by the connection pool
 	NextClient() (rpc.TChanNode, error)
 
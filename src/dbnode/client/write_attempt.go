@@ -69,14 +69,21 @@ func (w *writeAttempt) perform() error {
 		w.args.namespace, w.args.id, w.args.tags, w.args.t,
 		w.args.value, w.args.unit, w.args.annotation)
 
-	if IsBadRequestError(err) {
-		// Do not retry bad request errors
+	if !isRetryableWriteError(err) {
 		err = xerrors.NewNonRetryableError(err)
 	}
 
 	return err
 }
 
+// isRetryableWriteError returns whether a write that failed with the error
+// may succeed if attempted again.
+func isRetryableWriteError(err error) bool {
+	// Do not retry bad request errors or errors from nodes that are
+	// refusing writes as they are out of resources
+	return err == nil || !(IsBadRequestError(err) || IsResourceExhaustedError(err))
+}
+
 type writeAttemptPool struct {
 	pool    pool.ObjectPool
 	session *session
@@ -142,25 +142,27 @@ func (w *writeState) completionFn(result interface{}, err error) {
 		w.errors = append(w.errors, wErr)
 	}
 
-	switch w.consistencyLevel {
-	case topology.ConsistencyLevelOne:
-		if w.success > 0 || w.pending == 0 {
-			w.Signal()
-		}
-	case topology.ConsistencyLevelMajority:
-		if w.success >= w.majority || w.pending == 0 {
-			w.Signal()
-		}
-	case topology.ConsistencyLevelAll:
-		if w.pending == 0 {
-			w.Signal()
-		}
+	if w.completedWithLock() {
+		w.Signal()
 	}
 
 	w.Unlock()
 	w.decRef()
 }
 
+// completedWithLock returns whether enough of the enqueued writes have
+// completed to evaluate the consistency level of the write, must be called
+// with the lock held.
+func (w *writeState) completedWithLock() bool {
+	switch w.consistencyLevel {
+	case topology.ConsistencyLevelOne:
+		return w.success > 0 || w.pending == 0
+	case topology.ConsistencyLevelMajority:
+		return w.success >= w.majority || w.pending == 0
+	}
+	return w.pending == 0
+}
+
 type writeStatePool struct {
 	pool           pool.ObjectPool
 	tagEncoderPool serialize.TagEncoderPool
@@ -0,0 +1,53 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package encoding
+
+import (
+	"testing"
+
+	"github.com/m3db/m3/src/dbnode/ts"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrentMetricType(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	annotation, err := ts.EncodeMetricTypeAnnotation(ts.MetricTypeTimer, []byte("rest"))
+	require.NoError(t, err)
+
+	iter := NewMockIterator(ctrl)
+	iter.EXPECT().Current().Return(ts.Datapoint{}, xtime.Second, annotation)
+	metricType, rest, ok := CurrentMetricType(iter)
+	require.True(t, ok)
+	assert.Equal(t, ts.MetricTypeTimer, metricType)
+	assert.Equal(t, ts.Annotation("rest"), rest)
+
+	iter.EXPECT().Current().Return(ts.Datapoint{}, xtime.Second, ts.Annotation("rest"))
+	metricType, rest, ok = CurrentMetricType(iter)
+	require.False(t, ok)
+	assert.Equal(t, ts.MetricTypeUnknown, metricType)
+	assert.Equal(t, ts.Annotation("rest"), rest)
+}
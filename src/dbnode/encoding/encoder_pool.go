@@ -21,16 +21,29 @@
 package encoding
 
 import (
+	"sync/atomic"
+
+	"github.com/m3db/m3x/checked"
 	"github.com/m3db/m3x/pool"
+
+	"github.com/uber-go/tally"
 )
 
 type encoderPool struct {
-	pool pool.ObjectPool
+	pool       pool.ObjectPool
+	accounting *poolAccounting
 }
 
 // NewEncoderPool creates a new pool
 func NewEncoderPool(opts pool.ObjectPoolOptions) EncoderPool {
-	return &encoderPool{pool: pool.NewObjectPool(opts)}
+	if opts == nil {
+		opts = pool.NewObjectPoolOptions()
+	}
+	scope := opts.InstrumentOptions().MetricsScope()
+	return &encoderPool{
+		pool:       pool.NewObjectPool(opts),
+		accounting: newPoolAccounting(scope),
+	}
 }
 
 func (p *encoderPool) Init(alloc EncoderAllocate) {
@@ -40,9 +53,127 @@ func (p *encoderPool) Init(alloc EncoderAllocate) {
 }
 
 func (p *encoderPool) Get() Encoder {
+	atomic.AddInt64(&p.accounting.gets, 1)
 	return p.pool.Get().(Encoder)
 }
 
 func (p *encoderPool) Put(encoder Encoder) {
+	atomic.AddInt64(&p.accounting.puts, 1)
 	p.pool.Put(encoder)
 }
+
+func (p *encoderPool) AccountedBytesPool(
+	bytesPool pool.CheckedBytesPool,
+) pool.CheckedBytesPool {
+	return &accountedBytesPool{
+		CheckedBytesPool: bytesPool,
+		accounting:       p.accounting,
+	}
+}
+
+func (p *encoderPool) Accounting() PoolAccounting {
+	return p.accounting.snapshot()
+}
+
+func (p *encoderPool) Report() {
+	p.accounting.report()
+}
+
+// accountedBytesPool is a checked bytes pool that accounts the capacity of
+// the bytes checked out of it until they are finalized.
+type accountedBytesPool struct {
+	pool.CheckedBytesPool
+
+	accounting *poolAccounting
+}
+
+func (p *accountedBytesPool) Get(capacity int) checked.Bytes {
+	bytes := p.CheckedBytesPool.Get(capacity)
+	accounted := bytes.Cap()
+	p.accounting.checkOut(accounted)
+	// NB: the bytes are wrapped as they are returned to the underlying pool
+	// by its own finalizer, which cannot be intercepted otherwise.
+	return &accountedBytes{
+		Bytes:      bytes,
+		accounting: p.accounting,
+		accounted:  accounted,
+	}
+}
+
+// accountedBytes releases its capacity from the accounting when finalized.
+type accountedBytes struct {
+	checked.Bytes
+
+	accounting *poolAccounting
+	accounted  int
+	released   int32
+}
+
+func (b *accountedBytes) Finalize() {
+	if atomic.CompareAndSwapInt32(&b.released, 0, 1) {
+		b.accounting.release(b.accounted)
+	}
+	b.Bytes.Finalize()
+}
+
+type poolAccounting struct {
+	checkedOut    int64
+	highWatermark int64
+	gets          int64
+	puts          int64
+	metrics       poolAccountingMetrics
+}
+
+type poolAccountingMetrics struct {
+	checkedOut    tally.Gauge
+	highWatermark tally.Gauge
+	gets          tally.Gauge
+	puts          tally.Gauge
+}
+
+func newPoolAccounting(scope tally.Scope) *poolAccounting {
+	return &poolAccounting{
+		metrics: poolAccountingMetrics{
+			checkedOut:    scope.Gauge("checked-out-bytes"),
+			highWatermark: scope.Gauge("checked-out-bytes-high-watermark"),
+			gets:          scope.Gauge("gets"),
+			puts:          scope.Gauge("puts"),
+		},
+	}
+}
+
+func (a *poolAccounting) checkOut(bytes int) {
+	checkedOut := atomic.AddInt64(&a.checkedOut, int64(bytes))
+	for {
+		highWatermark := atomic.LoadInt64(&a.highWatermark)
+		if checkedOut <= highWatermark ||
+			atomic.CompareAndSwapInt64(&a.highWatermark, highWatermark, checkedOut) {
+			return
+		}
+	}
+}
+
+func (a *poolAccounting) release(bytes int) {
+	atomic.AddInt64(&a.checkedOut, -int64(bytes))
+}
+
+func (a *poolAccounting) snapshot() PoolAccounting {
+	return PoolAccounting{
+		CheckedOutBytes:    atomic.LoadInt64(&a.checkedOut),
+		HighWatermarkBytes: atomic.LoadInt64(&a.highWatermark),
+		Gets:               atomic.LoadInt64(&a.gets),
+		Puts:               atomic.LoadInt64(&a.puts),
+	}
+}
+
+// report updates the gauges and resets the high watermark to the bytes
+// currently checked out so that each report covers the interval since the
+// last.
+func (a *poolAccounting) report() {
+	s := a.snapshot()
+	a.metrics.checkedOut.Update(float64(s.CheckedOutBytes))
+	a.metrics.highWatermark.Update(float64(s.HighWatermarkBytes))
+	a.metrics.gets.Update(float64(s.Gets))
+	a.metrics.puts.Update(float64(s.Puts))
+	atomic.StoreInt64(&a.highWatermark, atomic.LoadInt64(&a.checkedOut))
+}
@@ -152,6 +152,14 @@ func (it *testMultiIterator) ResetSliceOfSlices(readers xio.ReaderSliceOfSlicesI
 	}
 }
 
+func (it *testMultiIterator) ResetTolerant(r []xio.SegmentReader, start time.Time, blockSize time.Duration) {
+	it.Reset(r, start, blockSize)
+}
+
+func (it *testMultiIterator) ReadErrors() []ReadError {
+	return nil
+}
+
 func (it *testMultiIterator) Readers() xio.ReaderSliceOfSlicesIterator {
 	return nil
 }
@@ -47,6 +47,11 @@ type iterators struct {
 	filtering          bool
 	equalTimesStrategy IterateEqualTimestampStrategy
 
+	// onIterErr if set is called with an iterator that failed rather than
+	// failing all iterators, the iterator is then dropped like an exhausted
+	// iterator.
+	onIterErr func(iter Iterator, err error)
+
 	// Used for caching reuse of value frequency lookup
 	valueFrequencies map[float64]int
 }
@@ -165,15 +170,21 @@ func (i *iterators) moveToValidNext() (bool, error) {
 
 		err := iter.Err()
 		if err != nil {
-			i.reset()
-			return false, err
+			if i.onIterErr == nil {
+				i.reset()
+				return false, err
+			}
+			i.onIterErr(iter, err)
+			next = false
 		}
 
 		if next {
 			continue
 		}
 
-		// No next so swap tail in and shrink by one
+		// No next so remove and shrink by one, preserving the order of the
+		// remaining iterators since equal timestamps are resolved by the
+		// order the iterators were pushed
 		iter.Close()
 		idx := -1
 		for i, curr := range i.values {
@@ -182,7 +193,7 @@ func (i *iterators) moveToValidNext() (bool, error) {
 				break
 			}
 		}
-		i.values[idx] = i.values[n-1]
+		copy(i.values[idx:], i.values[idx+1:])
 		i.values[n-1] = nil
 		i.values = i.values[:n-1]
 		n = n - 1
@@ -37,6 +37,10 @@ import (
 const (
 	sigDiffThreshold   = uint8(3)
 	sigRepeatThreshold = uint8(5)
+
+	// uncontainedXORHeaderBits is the number of bits used to write the
+	// leading zeros and number of meaningful bits of an uncontained XOR
+	uncontainedXORHeaderBits = 12
 )
 
 var (
@@ -377,14 +381,21 @@ func (enc *encoder) writeXOR(prevXOR, curXOR uint64) {
 	// NB(xichen): can be further optimized by keeping track of leading and trailing zeros in enc.
 	prevLeading, prevTrailing := encoding.LeadingAndTrailingZeros(prevXOR)
 	curLeading, curTrailing := encoding.LeadingAndTrailingZeros(curXOR)
+	numMeaningfulBits := 64 - curLeading - curTrailing
 	if curLeading >= prevLeading && curTrailing >= prevTrailing {
-		enc.os.WriteBits(opcodeContainedValueXOR, 2)
-		enc.os.WriteBits(curXOR>>uint(prevTrailing), 64-prevLeading-prevTrailing)
-		return
+		// NB: The window of the next XOR is always that of the current XOR
+		// regardless of how it is written, so writing the current XOR with a
+		// new window when that is smaller never costs bits later on.
+		numWindowBits := 64 - prevLeading - prevTrailing
+		if !enc.opts.OptimizeXORWindows() ||
+			numWindowBits <= numMeaningfulBits+uncontainedXORHeaderBits {
+			enc.os.WriteBits(opcodeContainedValueXOR, 2)
+			enc.os.WriteBits(curXOR>>uint(prevTrailing), numWindowBits)
+			return
+		}
 	}
 	enc.os.WriteBits(opcodeUncontainedValueXOR, 2)
 	enc.os.WriteBits(uint64(curLeading), 6)
-	numMeaningfulBits := 64 - curLeading - curTrailing
 	// numMeaningfulBits is at least 1, so we can subtract 1 from it and encode it in 6 bits
 	enc.os.WriteBits(uint64(numMeaningfulBits-1), 6)
 	enc.os.WriteBits(curXOR>>uint(curTrailing), numMeaningfulBits)
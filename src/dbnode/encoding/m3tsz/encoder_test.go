@@ -27,6 +27,7 @@ import (
 
 	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3x/pool"
 	xtime "github.com/m3db/m3x/time"
 
 	"github.com/stretchr/testify/assert"
@@ -458,3 +459,116 @@ func testMultiplePasses(t *testing.T, test multiplePassesTest) {
 		}
 	}
 }
+
+func newTestAccountedEncoderPool() encoding.EncoderPool {
+	// NB: small buckets so that encoders outgrow their bytes while encoding.
+	bytesPool := pool.NewCheckedBytesPool([]pool.Bucket{
+		{Capacity: 16, Count: 8},
+		{Capacity: 1024, Count: 8},
+	}, nil, func(s []pool.Bucket) pool.BytesPool {
+		return pool.NewBytesPool(s, nil)
+	})
+	bytesPool.Init()
+
+	encoderPool := encoding.NewEncoderPool(nil)
+	encodingOpts := encoding.NewOptions().
+		SetEncoderPool(encoderPool).
+		SetBytesPool(encoderPool.AccountedBytesPool(bytesPool))
+	encoderPool.Init(func() encoding.Encoder {
+		return NewEncoder(time.Time{}, nil, DefaultIntOptimizationEnabled, encodingOpts)
+	})
+	return encoderPool
+}
+
+func encodeTestDatapoints(t *testing.T, enc encoding.Encoder, n int) {
+	for i := 0; i < n; i++ {
+		dp := ts.Datapoint{
+			Timestamp: testStartTime.Add(time.Duration(i) * time.Second),
+			Value:     float64(i) * 1.5,
+		}
+		require.NoError(t, enc.Encode(dp, xtime.Second, nil))
+	}
+}
+
+func TestEncoderPoolAccountingBalanced(t *testing.T) {
+	tests := []struct {
+		name    string
+		release func(t *testing.T, enc encoding.Encoder)
+	}{
+		{
+			name: "close",
+			release: func(t *testing.T, enc encoding.Encoder) {
+				enc.Close()
+			},
+		},
+		{
+			name: "reset",
+			release: func(t *testing.T, enc encoding.Encoder) {
+				enc.Reset(testStartTime, 16)
+				encodeTestDatapoints(t, enc, 10)
+				enc.Close()
+			},
+		},
+		{
+			name: "stream",
+			release: func(t *testing.T, enc encoding.Encoder) {
+				stream := enc.Stream()
+				require.NotNil(t, stream)
+				enc.Close()
+				stream.Finalize()
+			},
+		},
+		{
+			name: "discard",
+			release: func(t *testing.T, enc encoding.Encoder) {
+				segment := enc.Discard()
+				require.NotNil(t, segment.Head)
+				segment.Finalize()
+			},
+		},
+		{
+			name: "discard reset",
+			release: func(t *testing.T, enc encoding.Encoder) {
+				segment := enc.DiscardReset(testStartTime, 16)
+				segment.Finalize()
+				enc.Close()
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			encoderPool := newTestAccountedEncoderPool()
+
+			enc := encoderPool.Get()
+			enc.Reset(testStartTime, 16)
+			encodeTestDatapoints(t, enc, 100)
+
+			accounting := encoderPool.Accounting()
+			assert.True(t, accounting.CheckedOutBytes > 16)
+			assert.True(t, accounting.HighWatermarkBytes >= accounting.CheckedOutBytes)
+
+			test.release(t, enc)
+
+			accounting = encoderPool.Accounting()
+			assert.Equal(t, int64(0), accounting.CheckedOutBytes)
+			assert.Equal(t, int64(1), accounting.Gets)
+			assert.Equal(t, int64(1), accounting.Puts)
+		})
+	}
+}
+
+func TestEncoderPoolAccountingReportResetsHighWatermark(t *testing.T) {
+	encoderPool := newTestAccountedEncoderPool()
+
+	enc := encoderPool.Get()
+	enc.Reset(testStartTime, 16)
+	encodeTestDatapoints(t, enc, 100)
+	enc.Close()
+
+	accounting := encoderPool.Accounting()
+	require.Equal(t, int64(0), accounting.CheckedOutBytes)
+	require.True(t, accounting.HighWatermarkBytes > 0)
+
+	encoderPool.Report()
+	assert.Equal(t, int64(0), encoderPool.Accounting().HighWatermarkBytes)
+}
@@ -26,6 +26,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/dbnode/encoding/testgen"
 	"github.com/m3db/m3/src/dbnode/ts"
 	xtime "github.com/m3db/m3x/time"
@@ -101,6 +102,40 @@ func TestIntOverflow(t *testing.T) {
 	testRoundTrip(t, generateOverflowDatapoints())
 }
 
+func TestOptimizeXORWindowsRoundTrip(t *testing.T) {
+	timeUnit := time.Second
+	numPoints := 1000
+	numIterations := 100
+	optimizedOpts := encoding.NewOptions().SetOptimizeXORWindows(true)
+	for i := 0; i < numIterations; i++ {
+		input := generatePreciseFloatDatapoints(numPoints, timeUnit)
+		defaultEncoder := NewEncoder(testStartTime, nil, false, nil)
+		optimizedEncoder := NewEncoder(testStartTime, nil, false, optimizedOpts)
+		for _, v := range input {
+			require.NoError(t, defaultEncoder.Encode(v, xtime.Second, nil))
+			require.NoError(t, optimizedEncoder.Encode(v, xtime.Second, nil))
+		}
+
+		defaultLen := defaultEncoder.Discard().Len()
+		stream := optimizedEncoder.Stream()
+		segment, err := stream.Segment()
+		require.NoError(t, err)
+		require.True(t, segment.Len() <= defaultLen)
+
+		it := NewDecoder(false, nil).Decode(stream)
+		j := 0
+		for it.Next() {
+			v, _, _ := it.Current()
+			require.Equal(t, input[j].Timestamp, v.Timestamp)
+			require.Equal(t, input[j].Value, v.Value)
+			j++
+		}
+		require.NoError(t, it.Err())
+		require.Equal(t, len(input), j)
+		it.Close()
+	}
+}
+
 func testRoundTrip(t *testing.T, input []ts.Datapoint) {
 	validateRoundTrip(t, input, true)
 	validateRoundTrip(t, input, false)
@@ -44,6 +44,14 @@ type multiReaderIterator struct {
 	err              error
 	firstNext        bool
 	closed           bool
+
+	// tolerant is set when readers that fail to decode are skipped, the
+	// iterators of the current readers are then tracked by reader index so
+	// that the skipped readers can be reported.
+	tolerant    bool
+	readerIters []Iterator
+	readErrs    []ReadError
+	skipIterFn  func(iter Iterator, err error)
 }
 
 // NewMultiReaderIterator creates a new multi-reader iterator.
@@ -52,6 +60,7 @@ func NewMultiReaderIterator(
 	pool MultiReaderIteratorPool,
 ) MultiReaderIterator {
 	it := &multiReaderIterator{pool: pool, iteratorAlloc: iteratorAlloc}
+	it.skipIterFn = it.skipIter
 	it.Reset(nil, time.Time{}, 0)
 	return it
 }
@@ -106,7 +115,8 @@ func (it *multiReaderIterator) moveToNext() {
 	}
 
 	// Add all readers to current iterators heap
-	currentLen, _, _ := it.slicesIter.CurrentReaders()
+	currentLen, start, _ := it.slicesIter.CurrentReaders()
+	it.resetReaderIters()
 	for i := 0; i < currentLen; i++ {
 		var (
 			reader = it.slicesIter.CurrentReaderAt(i)
@@ -115,12 +125,23 @@ func (it *multiReaderIterator) moveToNext() {
 		if iter.Next() {
 			// Only insert it if it has values
 			it.iters.push(iter)
-		} else {
-			err := iter.Err()
-			iter.Close()
-			if it.err == nil && err != nil {
-				it.err = err
+			if it.tolerant {
+				it.readerIters = append(it.readerIters, iter)
+			}
+			continue
+		}
+
+		err := iter.Err()
+		iter.Close()
+		if it.tolerant {
+			it.readerIters = append(it.readerIters, nil)
+			if err != nil {
+				it.readErrs = append(it.readErrs, ReadError{Start: start, Index: i, Err: err})
 			}
+			continue
+		}
+		if it.err == nil && err != nil {
+			it.err = err
 		}
 	}
 
@@ -151,25 +172,81 @@ func (it *multiReaderIterator) moveIteratorsToNext() {
 	}
 }
 
+// skipIter records the error of an iterator that failed while reading in
+// tolerant mode, the iterator is then closed and dropped by the iterators.
+func (it *multiReaderIterator) skipIter(iter Iterator, err error) {
+	readErr := ReadError{Index: -1, Err: err}
+	if it.slicesIter != nil {
+		_, readErr.Start, _ = it.slicesIter.CurrentReaders()
+	}
+	for i, curr := range it.readerIters {
+		if curr == iter {
+			readErr.Index = i
+			it.readerIters[i] = nil
+			break
+		}
+	}
+	it.readErrs = append(it.readErrs, readErr)
+}
+
+func (it *multiReaderIterator) resetReaderIters() {
+	for i := range it.readerIters {
+		it.readerIters[i] = nil
+	}
+	it.readerIters = it.readerIters[:0]
+}
+
 func (it *multiReaderIterator) Err() error {
 	return it.err
 }
 
+func (it *multiReaderIterator) ReadErrors() []ReadError {
+	return it.readErrs
+}
+
 func (it *multiReaderIterator) Readers() xio.ReaderSliceOfSlicesIterator {
 	return it.slicesIter
 }
 
 func (it *multiReaderIterator) Reset(blocks []xio.SegmentReader, start time.Time, blockSize time.Duration) {
+	it.setTolerant(false)
+	it.resetSingleSlices(blocks, start, blockSize)
+}
+
+func (it *multiReaderIterator) ResetTolerant(blocks []xio.SegmentReader, start time.Time, blockSize time.Duration) {
+	it.setTolerant(true)
+	it.resetSingleSlices(blocks, start, blockSize)
+}
+
+func (it *multiReaderIterator) setTolerant(value bool) {
+	it.tolerant = value
+	it.iters.onIterErr = nil
+	if value {
+		it.iters.onIterErr = it.skipIterFn
+	}
+}
+
+func (it *multiReaderIterator) resetSingleSlices(blocks []xio.SegmentReader, start time.Time, blockSize time.Duration) {
 	it.singleSlicesIter.readers = blocks
 	it.singleSlicesIter.firstNext = true
 	it.singleSlicesIter.closed = false
 	it.singleSlicesIter.start = start
 	it.singleSlicesIter.blockSize = blockSize
-	it.ResetSliceOfSlices(&it.singleSlicesIter)
+	it.resetSliceOfSlices(&it.singleSlicesIter)
 }
 
 func (it *multiReaderIterator) ResetSliceOfSlices(slicesIter xio.ReaderSliceOfSlicesIterator) {
+	it.setTolerant(false)
+	it.resetSliceOfSlices(slicesIter)
+}
+
+func (it *multiReaderIterator) resetSliceOfSlices(slicesIter xio.ReaderSliceOfSlicesIterator) {
 	it.iters.reset()
+	it.resetReaderIters()
+	for i := range it.readErrs {
+		it.readErrs[i] = ReadError{}
+	}
+	it.readErrs = it.readErrs[:0]
 	it.slicesIter = slicesIter
 	it.err = nil
 	it.firstNext = true
@@ -227,6 +227,36 @@ func TestMultiReaderIteratorDeduplicatesMulti(t *testing.T) {
 	assertTestMultiReaderIterator(t, test)
 }
 
+func TestMultiReaderIteratorDeduplicatesLastPushedAfterExhausted(t *testing.T) {
+	start := time.Now().Truncate(time.Minute)
+
+	first := []testValue{
+		{1.0, start.Add(1 * time.Second), xtime.Second, nil},
+	}
+	older := []testValue{
+		{2.0, start.Add(2 * time.Second), xtime.Second, nil},
+	}
+	newer := []testValue{
+		{3.0, start.Add(2 * time.Second), xtime.Second, nil},
+	}
+
+	// Readers exhausted before an equal timestamp must not change the order
+	// in which the remaining readers were pushed
+	test := testMultiReader{
+		input: [][]testMultiReaderEntries{
+			[]testMultiReaderEntries{
+				{values: first},
+				{values: older},
+				{values: newer},
+				{values: first},
+			},
+		},
+		expected: []testValue{first[0], newer[0]},
+	}
+
+	assertTestMultiReaderIterator(t, test)
+}
+
 func TestMultiReaderIteratorErrorOnOutOfOrder(t *testing.T) {
 	start := time.Now().Truncate(time.Minute)
 
@@ -281,6 +311,86 @@ func TestMultiReaderIteratorErrorOnInnerIteratorError(t *testing.T) {
 	assertTestMultiReaderIterator(t, test)
 }
 
+func TestMultiReaderIteratorTolerantSkipsFailedReaders(t *testing.T) {
+	start := time.Now().Truncate(time.Minute)
+
+	entries := []testMultiReaderEntries{
+		{
+			values: []testValue{
+				{1.0, start.Add(1 * time.Second), xtime.Second, nil},
+				{3.0, start.Add(3 * time.Second), xtime.Second, nil},
+				{5.0, start.Add(5 * time.Second), xtime.Second, nil},
+			},
+		},
+		{
+			values: []testValue{
+				{2.0, start.Add(2 * time.Second), xtime.Second, nil},
+				{4.0, start.Add(4 * time.Second), xtime.Second, nil},
+			},
+			err: &testMultiReaderError{err: fmt.Errorf("truncated"), atIdx: 1},
+		},
+		{
+			values: []testValue{
+				{6.0, start.Add(6 * time.Second), xtime.Second, nil},
+			},
+			err: &testMultiReaderError{err: fmt.Errorf("bad checksum"), atIdx: 0},
+		},
+	}
+
+	var (
+		readers       []xio.SegmentReader
+		testIterators []*testIterator
+	)
+	for range entries {
+		readers = append(readers, &testNoopReader{})
+	}
+	iteratorAlloc := func(reader io.Reader) ReaderIterator {
+		for i := range readers {
+			if reader.(xio.BlockReader).SegmentReader != readers[i] {
+				continue
+			}
+			entries := entries[i]
+			it := newTestIterator(entries.values).(*testIterator)
+			if entries.err != nil {
+				it.onNext = func(oldIdx, newIdx int) {
+					if newIdx == entries.err.atIdx {
+						it.err = entries.err.err
+					}
+				}
+			}
+			testIterators = append(testIterators, it)
+			return it
+		}
+		assert.Fail(t, "iterator allocate called for unknown reader")
+		return nil
+	}
+
+	iter := NewMultiReaderIterator(iteratorAlloc, nil)
+	iter.ResetTolerant(readers, start, time.Minute)
+
+	var values []float64
+	for iter.Next() {
+		dp, _, _ := iter.Current()
+		values = append(values, dp.Value)
+	}
+	require.NoError(t, iter.Err())
+	require.Equal(t, []float64{1.0, 2.0, 3.0, 5.0}, values)
+	require.Equal(t, []ReadError{
+		{Start: start, Index: 2, Err: entries[2].err.err},
+		{Start: start, Index: 1, Err: entries[1].err.err},
+	}, iter.ReadErrors())
+
+	// A strict reset fails on the first reader that fails to decode
+	iter.Reset(readers, start, time.Minute)
+	require.Equal(t, entries[2].err.err, iter.Err())
+	require.Equal(t, 0, len(iter.ReadErrors()))
+
+	iter.Close()
+	for _, iter := range testIterators {
+		assert.Equal(t, true, iter.closed)
+	}
+}
+
 func assertTestMultiReaderIterator(
 	t *testing.T,
 	test testMultiReader,
@@ -43,6 +43,7 @@ type options struct {
 	readerIteratorPool   ReaderIteratorPool
 	bytesPool            pool.CheckedBytesPool
 	segmentReaderPool    xio.SegmentReaderPool
+	optimizeXORWindows   bool
 }
 
 func newOptions() Options {
@@ -127,3 +128,13 @@ func (o *options) SetSegmentReaderPool(value xio.SegmentReaderPool) Options {
 func (o *options) SegmentReaderPool() xio.SegmentReaderPool {
 	return o.segmentReaderPool
 }
+
+func (o *options) SetOptimizeXORWindows(value bool) Options {
+	opts := *o
+	opts.optimizeXORWindows = value
+	return &opts
+}
+
+func (o *options) OptimizeXORWindows() bool {
+	return o.optimizeXORWindows
+}
@@ -112,6 +112,16 @@ type Options interface {
 
 	// SegmentReaderPool returns the segment reader pool.
 	SegmentReaderPool() xio.SegmentReaderPool
+
+	// SetOptimizeXORWindows sets whether a float XOR is only written within
+	// the leading and trailing zero window of the previous XOR when that is
+	// no larger than writing it with a new window.
+	SetOptimizeXORWindows(value bool) Options
+
+	// OptimizeXORWindows returns whether a float XOR is only written within
+	// the leading and trailing zero window of the previous XOR when that is
+	// no larger than writing it with a new window.
+	OptimizeXORWindows() bool
 }
 
 // Iterator is the generic interface for iterating over encoded data.
@@ -150,10 +160,29 @@ type MultiReaderIterator interface {
 	// Reset resets the iterator to read from a slice of slice readers.
 	ResetSliceOfSlices(readers xio.ReaderSliceOfSlicesIterator)
 
+	// ResetTolerant resets the iterator to read from a slice of readers, a
+	// reader that fails to decode is closed and skipped rather than failing
+	// the iteration and its error is reported by ReadErrors.
+	ResetTolerant(readers []xio.SegmentReader, start time.Time, blockSize time.Duration)
+
+	// ReadErrors returns the errors of the readers skipped since the iterator
+	// was last reset, only readers skipped in tolerant mode are reported.
+	ReadErrors() []ReadError
+
 	// Readers exposes the underlying ReaderSliceOfSlicesIterator for this MultiReaderIterator
 	Readers() xio.ReaderSliceOfSlicesIterator
 }
 
+// ReadError is the error of a reader skipped by a MultiReaderIterator.
+type ReadError struct {
+	// Start is the block start of the reader.
+	Start time.Time
+	// Index is the index of the reader in the readers the iterator was reset with.
+	Index int
+	// Err is the error encountered decoding the reader.
+	Err error
+}
+
 // SeriesIterator is an iterator that iterates over a set of iterators from different replicas
 // and de-dupes & merges results from the replicas for a given series while also applying a time
 // filter on top of the values in case replicas returned values out of range on either end
@@ -276,6 +305,35 @@ type EncoderPool interface {
 
 	// Put returns an encoder to the pool
 	Put(e Encoder)
+
+	// AccountedBytesPool returns the bytes pool wrapped so that the bytes
+	// checked out of it are accounted to the pool until finalized, the
+	// encoders of the pool should be allocated to use it.
+	AccountedBytesPool(bytesPool pool.CheckedBytesPool) pool.CheckedBytesPool
+
+	// Accounting returns the live accounting of the pool.
+	Accounting() PoolAccounting
+
+	// Report reports the accounting of the pool as gauges and resets the
+	// high watermark of the bytes checked out.
+	Report()
+}
+
+// PoolAccounting is the live accounting of an encoder pool.
+type PoolAccounting struct {
+	// CheckedOutBytes is the capacity of the bytes currently checked out by
+	// the encoders of the pool.
+	CheckedOutBytes int64
+
+	// HighWatermarkBytes is the most bytes checked out at once by the
+	// encoders of the pool since the accounting was last reported.
+	HighWatermarkBytes int64
+
+	// Gets is the number of encoders taken from the pool.
+	Gets int64
+
+	// Puts is the number of encoders returned to the pool.
+	Puts int64
 }
 
 // ReaderIteratorPool provides a pool for ReaderIterators
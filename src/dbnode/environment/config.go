@@ -23,6 +23,7 @@ package environment
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/m3db/m3/src/dbnode/kvconfig"
 	"github.com/m3db/m3/src/dbnode/sharding"
@@ -39,6 +40,8 @@ import (
 
 var (
 	errInvalidConfig = errors.New("must supply either service or static config")
+
+	newConfigServiceClient = etcdclient.NewConfigServiceClient
 )
 
 // Configuration is a configuration that can be used to create namespaces, a topology, and kv store
@@ -51,6 +54,47 @@ type Configuration struct {
 
 	// Presence of a (etcd) server in this config denotes an embedded cluster
 	SeedNodes *SeedNodesConfig `yaml:"seedNodes"`
+
+	// ETCDClient tunes the etcd clients of the config service, it applies to every
+	// etcd cluster that does not enable keep alives itself, including the cluster
+	// derived from the seed nodes.
+	ETCDClient *ETCDClientConfiguration `yaml:"etcdClient"`
+}
+
+// ETCDClientConfiguration configures the etcd clients of the config service
+type ETCDClientConfiguration struct {
+	// KeepAliveTime, if positive, is the period of inactivity after which the
+	// client pings the server to check the connection is still alive.
+	KeepAliveTime time.Duration `yaml:"keepAliveTime"`
+
+	// KeepAliveTimeout is how long the client waits for a keep alive response
+	// before closing the connection, defaults to the etcd client default if not set.
+	KeepAliveTimeout time.Duration `yaml:"keepAliveTimeout"`
+}
+
+// applyToOptions enables keep alives on the clusters of the config service client
+// options that do not already configure them.
+func (c *ETCDClientConfiguration) applyToOptions(opts etcdclient.Options) etcdclient.Options {
+	if c == nil || c.KeepAliveTime <= 0 {
+		return opts
+	}
+
+	clusters := opts.Clusters()
+	updated := make([]etcdclient.Cluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		keepAliveOpts := cluster.KeepAliveOptions()
+		if !keepAliveOpts.KeepAliveEnabled() {
+			keepAliveOpts = keepAliveOpts.
+				SetKeepAliveEnabled(true).
+				SetKeepAlivePeriod(c.KeepAliveTime)
+			if c.KeepAliveTimeout > 0 {
+				keepAliveOpts = keepAliveOpts.SetKeepAliveTimeout(c.KeepAliveTimeout)
+			}
+			cluster = cluster.SetKeepAliveOptions(keepAliveOpts)
+		}
+		updated = append(updated, cluster)
+	}
+	return opts.SetClusters(updated)
 }
 
 // SeedNodesConfig defines fields for seed node
@@ -63,6 +107,22 @@ type SeedNodesConfig struct {
 	InitialCluster           []SeedNode             `yaml:"initialCluster"`
 	ClientTransportSecurity  SeedNodeSecurityConfig `yaml:"clientTransportSecurity"`
 	PeerTransportSecurity    SeedNodeSecurityConfig `yaml:"peerTransportSecurity"`
+
+	// AutoCompactionRetention, if positive, is the period of key revision history
+	// retained when the embedded etcd keyspace is periodically compacted.
+	AutoCompactionRetention time.Duration `yaml:"autoCompactionRetention"`
+
+	// DefragInterval, if positive, is the interval at which the embedded etcd backend
+	// is defragmented to release the space freed by compaction.
+	DefragInterval time.Duration `yaml:"defragInterval"`
+
+	// MaintainOnlyAsLeader skips compaction and defragmentation of the embedded etcd
+	// while it is not the leader of the cluster.
+	MaintainOnlyAsLeader bool `yaml:"maintainOnlyAsLeader"`
+
+	// ReadyTimeout is how long to wait for the embedded etcd to become ready before
+	// warning that it is not, defaults to a minute if not set.
+	ReadyTimeout time.Duration `yaml:"readyTimeout"`
 }
 
 // SeedNode represents a seed node for the cluster
@@ -128,7 +188,8 @@ func (c Configuration) configureDynamic(cfgParams ConfigurationParameters) (Conf
 		// Set timeout to zero so it will wait indefinitely for the
 		// initial value.
 		SetServicesOptions(services.NewOptions().SetInitTimeout(0))
-	configSvcClient, err := etcdclient.NewConfigServiceClient(configSvcClientOpts)
+	configSvcClientOpts = c.ETCDClient.applyToOptions(configSvcClientOpts)
+	configSvcClient, err := newConfigServiceClient(configSvcClientOpts)
 	if err != nil {
 		err = fmt.Errorf("could not create m3cluster client: %v", err)
 		return ConfigureResults{}, err
@@ -27,11 +27,14 @@ import (
 	"github.com/m3db/m3/src/dbnode/retention"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3/src/dbnode/topology"
+	clusterclient "github.com/m3db/m3cluster/client"
 	etcdclient "github.com/m3db/m3cluster/client/etcd"
 	"github.com/m3db/m3cluster/services"
 	"github.com/m3db/m3x/instrument"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
 )
 
 var initTimeout = time.Minute
@@ -100,3 +103,81 @@ func TestConfigureDynamic(t *testing.T) {
 	assert.NotNil(t, configRes)
 	assert.NoError(t, err)
 }
+
+func TestETCDClientConfigurationUnmarshal(t *testing.T) {
+	yamlBytes := []byte(`
+service:
+  zone: local
+  env: test
+  service: m3dbnode_test
+  etcdClusters:
+    - zone: local
+      endpoints:
+        - localhost:1111
+etcdClient:
+  keepAliveTime: 30s
+  keepAliveTimeout: 5s
+`)
+
+	var config Configuration
+	require.NoError(t, yaml.Unmarshal(yamlBytes, &config))
+	require.NotNil(t, config.ETCDClient)
+	assert.Equal(t, 30*time.Second, config.ETCDClient.KeepAliveTime)
+	assert.Equal(t, 5*time.Second, config.ETCDClient.KeepAliveTimeout)
+}
+
+func TestConfigureDynamicETCDClientKeepAlive(t *testing.T) {
+	var capturedOpts etcdclient.Options
+	defer func(fn func(etcdclient.Options) (clusterclient.Client, error)) {
+		newConfigServiceClient = fn
+	}(newConfigServiceClient)
+	newConfigServiceClient = func(opts etcdclient.Options) (clusterclient.Client, error) {
+		capturedOpts = opts
+		return etcdclient.NewConfigServiceClient(opts)
+	}
+
+	yamlBytes := []byte(`
+service:
+  zone: local
+  env: test
+  service: m3dbnode_test
+  cacheDir: /
+  etcdClusters:
+    - zone: local
+      endpoints:
+        - localhost:1111
+    - zone: remote
+      endpoints:
+        - localhost:2222
+      keepAlive:
+        enabled: true
+        period: 1m
+        timeout: 1s
+etcdClient:
+  keepAliveTime: 30s
+  keepAliveTimeout: 5s
+`)
+
+	var config Configuration
+	require.NoError(t, yaml.Unmarshal(yamlBytes, &config))
+
+	_, err := config.Configure(ConfigurationParameters{
+		InstrumentOpts: instrument.NewOptions(),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, capturedOpts)
+
+	clusters := capturedOpts.Clusters()
+	require.Equal(t, 2, len(clusters))
+
+	local := clusters[0].KeepAliveOptions()
+	assert.True(t, local.KeepAliveEnabled())
+	assert.Equal(t, 30*time.Second, local.KeepAlivePeriod())
+	assert.Equal(t, 5*time.Second, local.KeepAliveTimeout())
+
+	// Clusters configuring their own keep alive are left untouched.
+	remote := clusters[1].KeepAliveOptions()
+	assert.True(t, remote.KeepAliveEnabled())
+	assert.Equal(t, time.Minute, remote.KeepAlivePeriod())
+	assert.Equal(t, time.Second, remote.KeepAliveTimeout())
+}
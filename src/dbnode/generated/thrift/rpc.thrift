@@ -29,7 +29,11 @@ enum TimeType {
 
 enum ErrorType {
 	INTERNAL_ERROR,
-	BAD_REQUEST
+	BAD_REQUEST,
+	READ_ONLY,
+	RESOURCE_EXHAUSTED,
+	DEADLINE_EXCEEDED,
+	SHARD_UNAVAILABLE
 }
 
 exception Error {
@@ -46,6 +50,9 @@ service Node {
 	QueryResult query(1: QueryRequest req) throws (1: Error err)
 	FetchResult fetch(1: FetchRequest req) throws (1: Error err)
 	FetchTaggedResult fetchTagged(1: FetchTaggedRequest req) throws (1: Error err)
+	FetchTaggedPageResult fetchTaggedPage(1: FetchTaggedPageRequest req) throws (1: Error err)
+	AggregateQueryResult aggregate(1: AggregateQueryRequest req) throws (1: Error err)
+	AggregateTagValuesResult aggregateTagValues(1: AggregateTagValuesRequest req) throws (1: Error err)
 	void write(1: WriteRequest req) throws (1: Error err)
 	void writeTagged(1: WriteTaggedRequest req) throws (1: Error err)
 
@@ -60,6 +67,7 @@ service Node {
 	void writeTaggedBatchRaw(1: WriteTaggedBatchRawRequest req) throws (1: WriteBatchRawErrors err)
 	void repair() throws (1: Error err)
 	TruncateResult truncate(1: TruncateRequest req) throws (1: Error err)
+	ForceFlushResult forceFlush(1: ForceFlushRequest req) throws (1: Error err)
 
 	// Management endpoints
 	NodeHealthResult health() throws (1: Error err)
@@ -81,6 +89,7 @@ struct FetchRequest {
 	4: required string id
 	5: optional TimeType rangeType = TimeType.UNIX_SECONDS
 	6: optional TimeType resultTimeType = TimeType.UNIX_SECONDS
+	7: optional i64 timeoutNanos
 }
 
 struct FetchResult {
@@ -113,6 +122,7 @@ struct FetchBatchRawRequest {
 	3: required binary nameSpace
 	4: required list<binary> ids
 	5: optional TimeType rangeTimeType = TimeType.UNIX_SECONDS
+	6: optional i64 timeoutNanos
 }
 
 struct FetchBatchRawResult {
@@ -144,11 +154,27 @@ struct FetchTaggedRequest {
 	5: required bool fetchData
 	6: optional i64 limit
 	7: optional TimeType rangeTimeType = TimeType.UNIX_SECONDS
+	8: optional bool trace
+	9: optional i64 timeoutNanos
+	10: optional i64 seriesLimit
+	11: optional i64 docsLimit
 }
 
 struct FetchTaggedResult {
 	1: required list<FetchTaggedIDResult> elements
 	2: required bool exhaustive
+	3: optional QueryTrace trace
+}
+
+struct FetchTaggedPageRequest {
+	1: required FetchTaggedRequest request
+	2: required i64 pageSize
+	3: optional binary pageToken
+}
+
+struct FetchTaggedPageResult {
+	1: required FetchTaggedResult result
+	2: optional binary pageToken
 }
 
 struct FetchTaggedIDResult {
@@ -159,6 +185,51 @@ struct FetchTaggedIDResult {
 	5: optional Error err
 }
 
+struct QueryTrace {
+	1: required string name
+	2: required i64 termsScanned
+	3: required i64 postingsDecoded
+	4: required i64 docsEmitted
+	5: required i64 durationNanos
+	6: required list<QueryTrace> children
+}
+
+struct AggregateQueryRequest {
+	1: required binary nameSpace
+	2: required binary query
+	3: required i64 rangeStart
+	4: required i64 rangeEnd
+	5: optional i64 limit
+	6: optional TimeType rangeTimeType = TimeType.UNIX_SECONDS
+}
+
+struct AggregateQueryResult {
+	1: required list<AggregateQueryResultTerm> terms
+	2: required bool exhaustive
+}
+
+struct AggregateQueryResultTerm {
+	1: required binary term
+	2: required i64 count
+}
+
+struct AggregateTagValuesRequest {
+	1: required binary nameSpace
+	2: required binary tagName
+	3: required i64 rangeStart
+	4: required i64 rangeEnd
+	5: optional i64 limit
+	6: optional TimeType rangeTimeType = TimeType.UNIX_SECONDS
+	7: optional binary tagValuePrefix
+	8: optional binary cursor
+}
+
+struct AggregateTagValuesResult {
+	1: required list<AggregateQueryResultTerm> values
+	2: required bool exhaustive
+	3: optional binary cursor
+}
+
 struct FetchBlocksRawRequest {
 	1: required binary nameSpace
 	2: required i32 shard
@@ -236,6 +307,7 @@ struct FetchBlocksMetadataRawV2Request {
 	7: optional bool includeSizes
 	8: optional bool includeChecksums
 	9: optional bool includeLastRead
+	10: optional bool includeBufferChecksums
 }
 
 struct FetchBlocksMetadataRawV2Result {
@@ -252,6 +324,7 @@ struct BlockMetadataV2 {
 	6: optional i64 lastRead
 	7: optional TimeType lastReadTimeType = TimeType.UNIX_SECONDS
 	8: optional binary encodedTags
+	9: optional bool buffered
 }
 
 struct WriteBatchRawRequest {
@@ -288,6 +361,20 @@ struct TruncateResult {
 	1: required i64 numSeries
 }
 
+struct ForceFlushRequest {
+	1: required binary nameSpace
+	2: required i64 rangeStart
+	3: required i64 rangeEnd
+	4: optional TimeType rangeType = TimeType.UNIX_SECONDS
+	5: optional i32 shard
+	6: optional bool force
+}
+
+struct ForceFlushResult {
+	1: required i64 numSeriesFlushed
+	2: required i64 numBytesWritten
+}
+
 struct NodeHealthResult {
 	1: required bool ok
 	2: required string status
@@ -107,8 +107,12 @@ func (p *TimeType) Value() (driver.Value, error) {
 type ErrorType int64
 
 const (
-	ErrorType_INTERNAL_ERROR ErrorType = 0
-	ErrorType_BAD_REQUEST    ErrorType = 1
+	ErrorType_INTERNAL_ERROR     ErrorType = 0
+	ErrorType_BAD_REQUEST        ErrorType = 1
+	ErrorType_READ_ONLY          ErrorType = 2
+	ErrorType_RESOURCE_EXHAUSTED ErrorType = 3
+	ErrorType_DEADLINE_EXCEEDED  ErrorType = 4
+	ErrorType_SHARD_UNAVAILABLE  ErrorType = 5
 )
 
 func (p ErrorType) String() string {
@@ -117,6 +121,14 @@ func (p ErrorType) String() string {
 		return "INTERNAL_ERROR"
 	case ErrorType_BAD_REQUEST:
 		return "BAD_REQUEST"
+	case ErrorType_READ_ONLY:
+		return "READ_ONLY"
+	case ErrorType_RESOURCE_EXHAUSTED:
+		return "RESOURCE_EXHAUSTED"
+	case ErrorType_DEADLINE_EXCEEDED:
+		return "DEADLINE_EXCEEDED"
+	case ErrorType_SHARD_UNAVAILABLE:
+		return "SHARD_UNAVAILABLE"
 	}
 	return "<UNSET>"
 }
@@ -127,6 +139,14 @@ func ErrorTypeFromString(s string) (ErrorType, error) {
 		return ErrorType_INTERNAL_ERROR, nil
 	case "BAD_REQUEST":
 		return ErrorType_BAD_REQUEST, nil
+	case "READ_ONLY":
+		return ErrorType_READ_ONLY, nil
+	case "RESOURCE_EXHAUSTED":
+		return ErrorType_RESOURCE_EXHAUSTED, nil
+	case "DEADLINE_EXCEEDED":
+		return ErrorType_DEADLINE_EXCEEDED, nil
+	case "SHARD_UNAVAILABLE":
+		return ErrorType_SHARD_UNAVAILABLE, nil
 	}
 	return ErrorType(0), fmt.Errorf("not a valid ErrorType string")
 }
@@ -437,6 +457,7 @@ func (p *WriteBatchRawErrors) Error() string {
 //  - ID
 //  - RangeType
 //  - ResultTimeType
+//  - TimeoutNanos
 type FetchRequest struct {
 	RangeStart     int64    `thrift:"rangeStart,1,required" db:"rangeStart" json:"rangeStart"`
 	RangeEnd       int64    `thrift:"rangeEnd,2,required" db:"rangeEnd" json:"rangeEnd"`
@@ -444,6 +465,7 @@ type FetchRequest struct {
 	ID             string   `thrift:"id,4,required" db:"id" json:"id"`
 	RangeType      TimeType `thrift:"rangeType,5" db:"rangeType" json:"rangeType,omitempty"`
 	ResultTimeType TimeType `thrift:"resultTimeType,6" db:"resultTimeType" json:"resultTimeType,omitempty"`
+	TimeoutNanos   *int64   `thrift:"timeoutNanos,7" db:"timeoutNanos" json:"timeoutNanos,omitempty"`
 }
 
 func NewFetchRequest() *FetchRequest {
@@ -481,6 +503,15 @@ var FetchRequest_ResultTimeType_DEFAULT TimeType = 0
 func (p *FetchRequest) GetResultTimeType() TimeType {
 	return p.ResultTimeType
 }
+
+var FetchRequest_TimeoutNanos_DEFAULT int64
+
+func (p *FetchRequest) GetTimeoutNanos() int64 {
+	if !p.IsSetTimeoutNanos() {
+		return FetchRequest_TimeoutNanos_DEFAULT
+	}
+	return *p.TimeoutNanos
+}
 func (p *FetchRequest) IsSetRangeType() bool {
 	return p.RangeType != FetchRequest_RangeType_DEFAULT
 }
@@ -489,6 +520,10 @@ func (p *FetchRequest) IsSetResultTimeType() bool {
 	return p.ResultTimeType != FetchRequest_ResultTimeType_DEFAULT
 }
 
+func (p *FetchRequest) IsSetTimeoutNanos() bool {
+	return p.TimeoutNanos != nil
+}
+
 func (p *FetchRequest) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
@@ -536,6 +571,10 @@ func (p *FetchRequest) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField6(iprot); err != nil {
 				return err
 			}
+		case 7:
+			if err := p.ReadField7(iprot); err != nil {
+				return err
+			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -619,6 +658,15 @@ func (p *FetchRequest) ReadField6(iprot thrift.TProtocol) error {
 	return nil
 }
 
+func (p *FetchRequest) ReadField7(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 7: ", err)
+	} else {
+		p.TimeoutNanos = &v
+	}
+	return nil
+}
+
 func (p *FetchRequest) Write(oprot thrift.TProtocol) error {
 	if err := oprot.WriteStructBegin("FetchRequest"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
@@ -642,6 +690,9 @@ func (p *FetchRequest) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField6(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField7(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -734,6 +785,21 @@ func (p *FetchRequest) writeField6(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
+func (p *FetchRequest) writeField7(oprot thrift.TProtocol) (err error) {
+	if p.IsSetTimeoutNanos() {
+		if err := oprot.WriteFieldBegin("timeoutNanos", thrift.I64, 7); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 7:timeoutNanos: ", p), err)
+		}
+		if err := oprot.WriteI64(int64(*p.TimeoutNanos)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.timeoutNanos (7) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 7:timeoutNanos: ", p), err)
+		}
+	}
+	return err
+}
+
 func (p *FetchRequest) String() string {
 	if p == nil {
 		return "<nil>"
@@ -1532,12 +1598,14 @@ func (p *WriteTaggedRequest) String() string {
 //  - NameSpace
 //  - Ids
 //  - RangeTimeType
+//  - TimeoutNanos
 type FetchBatchRawRequest struct {
 	RangeStart    int64    `thrift:"rangeStart,1,required" db:"rangeStart" json:"rangeStart"`
 	RangeEnd      int64    `thrift:"rangeEnd,2,required" db:"rangeEnd" json:"rangeEnd"`
 	NameSpace     []byte   `thrift:"nameSpace,3,required" db:"nameSpace" json:"nameSpace"`
 	Ids           [][]byte `thrift:"ids,4,required" db:"ids" json:"ids"`
 	RangeTimeType TimeType `thrift:"rangeTimeType,5" db:"rangeTimeType" json:"rangeTimeType,omitempty"`
+	TimeoutNanos  *int64   `thrift:"timeoutNanos,6" db:"timeoutNanos" json:"timeoutNanos,omitempty"`
 }
 
 func NewFetchBatchRawRequest() *FetchBatchRawRequest {
@@ -1567,10 +1635,23 @@ var FetchBatchRawRequest_RangeTimeType_DEFAULT TimeType = 0
 func (p *FetchBatchRawRequest) GetRangeTimeType() TimeType {
 	return p.RangeTimeType
 }
+
+var FetchBatchRawRequest_TimeoutNanos_DEFAULT int64
+
+func (p *FetchBatchRawRequest) GetTimeoutNanos() int64 {
+	if !p.IsSetTimeoutNanos() {
+		return FetchBatchRawRequest_TimeoutNanos_DEFAULT
+	}
+	return *p.TimeoutNanos
+}
 func (p *FetchBatchRawRequest) IsSetRangeTimeType() bool {
 	return p.RangeTimeType != FetchBatchRawRequest_RangeTimeType_DEFAULT
 }
 
+func (p *FetchBatchRawRequest) IsSetTimeoutNanos() bool {
+	return p.TimeoutNanos != nil
+}
+
 func (p *FetchBatchRawRequest) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
@@ -1614,6 +1695,10 @@ func (p *FetchBatchRawRequest) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField5(iprot); err != nil {
 				return err
 			}
+		case 6:
+			if err := p.ReadField6(iprot); err != nil {
+				return err
+			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -1700,6 +1785,15 @@ func (p *FetchBatchRawRequest) ReadField5(iprot thrift.TProtocol) error {
 	return nil
 }
 
+func (p *FetchBatchRawRequest) ReadField6(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 6: ", err)
+	} else {
+		p.TimeoutNanos = &v
+	}
+	return nil
+}
+
 func (p *FetchBatchRawRequest) Write(oprot thrift.TProtocol) error {
 	if err := oprot.WriteStructBegin("FetchBatchRawRequest"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
@@ -1720,6 +1814,9 @@ func (p *FetchBatchRawRequest) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField5(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField6(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -1805,6 +1902,21 @@ func (p *FetchBatchRawRequest) writeField5(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
+func (p *FetchBatchRawRequest) writeField6(oprot thrift.TProtocol) (err error) {
+	if p.IsSetTimeoutNanos() {
+		if err := oprot.WriteFieldBegin("timeoutNanos", thrift.I64, 6); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 6:timeoutNanos: ", p), err)
+		}
+		if err := oprot.WriteI64(int64(*p.TimeoutNanos)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.timeoutNanos (6) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 6:timeoutNanos: ", p), err)
+		}
+	}
+	return err
+}
+
 func (p *FetchBatchRawRequest) String() string {
 	if p == nil {
 		return "<nil>"
@@ -2499,6 +2611,10 @@ func (p *Segment) String() string {
 //  - FetchData
 //  - Limit
 //  - RangeTimeType
+//  - Trace
+//  - TimeoutNanos
+//  - SeriesLimit
+//  - DocsLimit
 type FetchTaggedRequest struct {
 	NameSpace     []byte   `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
 	Query         []byte   `thrift:"query,2,required" db:"query" json:"query"`
@@ -2507,6 +2623,10 @@ type FetchTaggedRequest struct {
 	FetchData     bool     `thrift:"fetchData,5,required" db:"fetchData" json:"fetchData"`
 	Limit         *int64   `thrift:"limit,6" db:"limit" json:"limit,omitempty"`
 	RangeTimeType TimeType `thrift:"rangeTimeType,7" db:"rangeTimeType" json:"rangeTimeType,omitempty"`
+	Trace         *bool    `thrift:"trace,8" db:"trace" json:"trace,omitempty"`
+	TimeoutNanos  *int64   `thrift:"timeoutNanos,9" db:"timeoutNanos" json:"timeoutNanos,omitempty"`
+	SeriesLimit   *int64   `thrift:"seriesLimit,10" db:"seriesLimit" json:"seriesLimit,omitempty"`
+	DocsLimit     *int64   `thrift:"docsLimit,11" db:"docsLimit" json:"docsLimit,omitempty"`
 }
 
 func NewFetchTaggedRequest() *FetchTaggedRequest {
@@ -2549,6 +2669,42 @@ var FetchTaggedRequest_RangeTimeType_DEFAULT TimeType = 0
 func (p *FetchTaggedRequest) GetRangeTimeType() TimeType {
 	return p.RangeTimeType
 }
+
+var FetchTaggedRequest_Trace_DEFAULT bool
+
+func (p *FetchTaggedRequest) GetTrace() bool {
+	if !p.IsSetTrace() {
+		return FetchTaggedRequest_Trace_DEFAULT
+	}
+	return *p.Trace
+}
+
+var FetchTaggedRequest_TimeoutNanos_DEFAULT int64
+
+func (p *FetchTaggedRequest) GetTimeoutNanos() int64 {
+	if !p.IsSetTimeoutNanos() {
+		return FetchTaggedRequest_TimeoutNanos_DEFAULT
+	}
+	return *p.TimeoutNanos
+}
+
+var FetchTaggedRequest_SeriesLimit_DEFAULT int64
+
+func (p *FetchTaggedRequest) GetSeriesLimit() int64 {
+	if !p.IsSetSeriesLimit() {
+		return FetchTaggedRequest_SeriesLimit_DEFAULT
+	}
+	return *p.SeriesLimit
+}
+
+var FetchTaggedRequest_DocsLimit_DEFAULT int64
+
+func (p *FetchTaggedRequest) GetDocsLimit() int64 {
+	if !p.IsSetDocsLimit() {
+		return FetchTaggedRequest_DocsLimit_DEFAULT
+	}
+	return *p.DocsLimit
+}
 func (p *FetchTaggedRequest) IsSetLimit() bool {
 	return p.Limit != nil
 }
@@ -2557,6 +2713,22 @@ func (p *FetchTaggedRequest) IsSetRangeTimeType() bool {
 	return p.RangeTimeType != FetchTaggedRequest_RangeTimeType_DEFAULT
 }
 
+func (p *FetchTaggedRequest) IsSetTrace() bool {
+	return p.Trace != nil
+}
+
+func (p *FetchTaggedRequest) IsSetTimeoutNanos() bool {
+	return p.TimeoutNanos != nil
+}
+
+func (p *FetchTaggedRequest) IsSetSeriesLimit() bool {
+	return p.SeriesLimit != nil
+}
+
+func (p *FetchTaggedRequest) IsSetDocsLimit() bool {
+	return p.DocsLimit != nil
+}
+
 func (p *FetchTaggedRequest) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
@@ -2610,6 +2782,22 @@ func (p *FetchTaggedRequest) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField7(iprot); err != nil {
 				return err
 			}
+		case 8:
+			if err := p.ReadField8(iprot); err != nil {
+				return err
+			}
+		case 9:
+			if err := p.ReadField9(iprot); err != nil {
+				return err
+			}
+		case 10:
+			if err := p.ReadField10(iprot); err != nil {
+				return err
+			}
+		case 11:
+			if err := p.ReadField11(iprot); err != nil {
+				return err
+			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -2704,6 +2892,42 @@ func (p *FetchTaggedRequest) ReadField7(iprot thrift.TProtocol) error {
 	return nil
 }
 
+func (p *FetchTaggedRequest) ReadField8(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 8: ", err)
+	} else {
+		p.Trace = &v
+	}
+	return nil
+}
+
+func (p *FetchTaggedRequest) ReadField9(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 9: ", err)
+	} else {
+		p.TimeoutNanos = &v
+	}
+	return nil
+}
+
+func (p *FetchTaggedRequest) ReadField10(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 10: ", err)
+	} else {
+		p.SeriesLimit = &v
+	}
+	return nil
+}
+
+func (p *FetchTaggedRequest) ReadField11(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 11: ", err)
+	} else {
+		p.DocsLimit = &v
+	}
+	return nil
+}
+
 func (p *FetchTaggedRequest) Write(oprot thrift.TProtocol) error {
 	if err := oprot.WriteStructBegin("FetchTaggedRequest"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
@@ -2730,6 +2954,18 @@ func (p *FetchTaggedRequest) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField7(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField8(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField9(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField10(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField11(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -2835,6 +3071,66 @@ func (p *FetchTaggedRequest) writeField7(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
+func (p *FetchTaggedRequest) writeField8(oprot thrift.TProtocol) (err error) {
+	if p.IsSetTrace() {
+		if err := oprot.WriteFieldBegin("trace", thrift.BOOL, 8); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 8:trace: ", p), err)
+		}
+		if err := oprot.WriteBool(bool(*p.Trace)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.trace (8) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 8:trace: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *FetchTaggedRequest) writeField9(oprot thrift.TProtocol) (err error) {
+	if p.IsSetTimeoutNanos() {
+		if err := oprot.WriteFieldBegin("timeoutNanos", thrift.I64, 9); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 9:timeoutNanos: ", p), err)
+		}
+		if err := oprot.WriteI64(int64(*p.TimeoutNanos)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.timeoutNanos (9) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 9:timeoutNanos: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *FetchTaggedRequest) writeField10(oprot thrift.TProtocol) (err error) {
+	if p.IsSetSeriesLimit() {
+		if err := oprot.WriteFieldBegin("seriesLimit", thrift.I64, 10); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 10:seriesLimit: ", p), err)
+		}
+		if err := oprot.WriteI64(int64(*p.SeriesLimit)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.seriesLimit (10) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 10:seriesLimit: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *FetchTaggedRequest) writeField11(oprot thrift.TProtocol) (err error) {
+	if p.IsSetDocsLimit() {
+		if err := oprot.WriteFieldBegin("docsLimit", thrift.I64, 11); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 11:docsLimit: ", p), err)
+		}
+		if err := oprot.WriteI64(int64(*p.DocsLimit)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.docsLimit (11) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 11:docsLimit: ", p), err)
+		}
+	}
+	return err
+}
+
 func (p *FetchTaggedRequest) String() string {
 	if p == nil {
 		return "<nil>"
@@ -2845,9 +3141,11 @@ func (p *FetchTaggedRequest) String() string {
 // Attributes:
 //  - Elements
 //  - Exhaustive
+//  - Trace
 type FetchTaggedResult_ struct {
 	Elements   []*FetchTaggedIDResult_ `thrift:"elements,1,required" db:"elements" json:"elements"`
 	Exhaustive bool                    `thrift:"exhaustive,2,required" db:"exhaustive" json:"exhaustive"`
+	Trace      *QueryTrace             `thrift:"trace,3" db:"trace" json:"trace,omitempty"`
 }
 
 func NewFetchTaggedResult_() *FetchTaggedResult_ {
@@ -2861,6 +3159,18 @@ func (p *FetchTaggedResult_) GetElements() []*FetchTaggedIDResult_ {
 func (p *FetchTaggedResult_) GetExhaustive() bool {
 	return p.Exhaustive
 }
+
+var FetchTaggedResult__Trace_DEFAULT *QueryTrace
+
+func (p *FetchTaggedResult_) GetTrace() *QueryTrace {
+	if !p.IsSetTrace() {
+		return FetchTaggedResult__Trace_DEFAULT
+	}
+	return p.Trace
+}
+func (p *FetchTaggedResult_) IsSetTrace() bool {
+	return p.Trace != nil
+}
 func (p *FetchTaggedResult_) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
@@ -2888,6 +3198,10 @@ func (p *FetchTaggedResult_) Read(iprot thrift.TProtocol) error {
 				return err
 			}
 			issetExhaustive = true
+		case 3:
+			if err := p.ReadField3(iprot); err != nil {
+				return err
+			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -2938,6 +3252,14 @@ func (p *FetchTaggedResult_) ReadField2(iprot thrift.TProtocol) error {
 	return nil
 }
 
+func (p *FetchTaggedResult_) ReadField3(iprot thrift.TProtocol) error {
+	p.Trace = &QueryTrace{}
+	if err := p.Trace.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Trace), err)
+	}
+	return nil
+}
+
 func (p *FetchTaggedResult_) Write(oprot thrift.TProtocol) error {
 	if err := oprot.WriteStructBegin("FetchTaggedResult"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
@@ -2949,6 +3271,9 @@ func (p *FetchTaggedResult_) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField2(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField3(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -2993,6 +3318,21 @@ func (p *FetchTaggedResult_) writeField2(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
+func (p *FetchTaggedResult_) writeField3(oprot thrift.TProtocol) (err error) {
+	if p.IsSetTrace() {
+		if err := oprot.WriteFieldBegin("trace", thrift.STRUCT, 3); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:trace: ", p), err)
+		}
+		if err := p.Trace.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Trace), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 3:trace: ", p), err)
+		}
+	}
+	return err
+}
+
 func (p *FetchTaggedResult_) String() string {
 	if p == nil {
 		return "<nil>"
@@ -3001,65 +3341,52 @@ func (p *FetchTaggedResult_) String() string {
 }
 
 // Attributes:
-//  - ID
-//  - NameSpace
-//  - EncodedTags
-//  - Segments
-//  - Err
-type FetchTaggedIDResult_ struct {
-	ID          []byte      `thrift:"id,1,required" db:"id" json:"id"`
-	NameSpace   []byte      `thrift:"nameSpace,2,required" db:"nameSpace" json:"nameSpace"`
-	EncodedTags []byte      `thrift:"encodedTags,3,required" db:"encodedTags" json:"encodedTags"`
-	Segments    []*Segments `thrift:"segments,4" db:"segments" json:"segments,omitempty"`
-	Err         *Error      `thrift:"err,5" db:"err" json:"err,omitempty"`
-}
-
-func NewFetchTaggedIDResult_() *FetchTaggedIDResult_ {
-	return &FetchTaggedIDResult_{}
+//  - Request
+//  - PageSize
+//  - PageToken
+type FetchTaggedPageRequest struct {
+	Request   *FetchTaggedRequest `thrift:"request,1,required" db:"request" json:"request"`
+	PageSize  int64               `thrift:"pageSize,2,required" db:"pageSize" json:"pageSize"`
+	PageToken []byte              `thrift:"pageToken,3" db:"pageToken" json:"pageToken,omitempty"`
 }
 
-func (p *FetchTaggedIDResult_) GetID() []byte {
-	return p.ID
+func NewFetchTaggedPageRequest() *FetchTaggedPageRequest {
+	return &FetchTaggedPageRequest{}
 }
 
-func (p *FetchTaggedIDResult_) GetNameSpace() []byte {
-	return p.NameSpace
-}
+var FetchTaggedPageRequest_Request_DEFAULT *FetchTaggedRequest
 
-func (p *FetchTaggedIDResult_) GetEncodedTags() []byte {
-	return p.EncodedTags
+func (p *FetchTaggedPageRequest) GetRequest() *FetchTaggedRequest {
+	if !p.IsSetRequest() {
+		return FetchTaggedPageRequest_Request_DEFAULT
+	}
+	return p.Request
 }
 
-var FetchTaggedIDResult__Segments_DEFAULT []*Segments
-
-func (p *FetchTaggedIDResult_) GetSegments() []*Segments {
-	return p.Segments
+func (p *FetchTaggedPageRequest) GetPageSize() int64 {
+	return p.PageSize
 }
 
-var FetchTaggedIDResult__Err_DEFAULT *Error
+var FetchTaggedPageRequest_PageToken_DEFAULT []byte
 
-func (p *FetchTaggedIDResult_) GetErr() *Error {
-	if !p.IsSetErr() {
-		return FetchTaggedIDResult__Err_DEFAULT
-	}
-	return p.Err
+func (p *FetchTaggedPageRequest) GetPageToken() []byte {
+	return p.PageToken
 }
-func (p *FetchTaggedIDResult_) IsSetSegments() bool {
-	return p.Segments != nil
+func (p *FetchTaggedPageRequest) IsSetRequest() bool {
+	return p.Request != nil
 }
 
-func (p *FetchTaggedIDResult_) IsSetErr() bool {
-	return p.Err != nil
+func (p *FetchTaggedPageRequest) IsSetPageToken() bool {
+	return p.PageToken != nil
 }
 
-func (p *FetchTaggedIDResult_) Read(iprot thrift.TProtocol) error {
+func (p *FetchTaggedPageRequest) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetID bool = false
-	var issetNameSpace bool = false
-	var issetEncodedTags bool = false
+	var issetRequest bool = false
+	var issetPageSize bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -3074,25 +3401,16 @@ func (p *FetchTaggedIDResult_) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetID = true
+			issetRequest = true
 		case 2:
 			if err := p.ReadField2(iprot); err != nil {
 				return err
 			}
-			issetNameSpace = true
+			issetPageSize = true
 		case 3:
 			if err := p.ReadField3(iprot); err != nil {
 				return err
 			}
-			issetEncodedTags = true
-		case 4:
-			if err := p.ReadField4(iprot); err != nil {
-				return err
-			}
-		case 5:
-			if err := p.ReadField5(iprot); err != nil {
-				return err
-			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -3105,77 +3423,45 @@ func (p *FetchTaggedIDResult_) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetID {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field ID is not set"))
-	}
-	if !issetNameSpace {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field NameSpace is not set"))
+	if !issetRequest {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Request is not set"))
 	}
-	if !issetEncodedTags {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field EncodedTags is not set"))
+	if !issetPageSize {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field PageSize is not set"))
 	}
 	return nil
 }
 
-func (p *FetchTaggedIDResult_) ReadField1(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBinary(); err != nil {
-		return thrift.PrependError("error reading field 1: ", err)
-	} else {
-		p.ID = v
+func (p *FetchTaggedPageRequest) ReadField1(iprot thrift.TProtocol) error {
+	p.Request = &FetchTaggedRequest{
+		RangeTimeType: 0,
+	}
+	if err := p.Request.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Request), err)
 	}
 	return nil
 }
 
-func (p *FetchTaggedIDResult_) ReadField2(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBinary(); err != nil {
+func (p *FetchTaggedPageRequest) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
 		return thrift.PrependError("error reading field 2: ", err)
 	} else {
-		p.NameSpace = v
+		p.PageSize = v
 	}
 	return nil
 }
 
-func (p *FetchTaggedIDResult_) ReadField3(iprot thrift.TProtocol) error {
+func (p *FetchTaggedPageRequest) ReadField3(iprot thrift.TProtocol) error {
 	if v, err := iprot.ReadBinary(); err != nil {
 		return thrift.PrependError("error reading field 3: ", err)
 	} else {
-		p.EncodedTags = v
-	}
-	return nil
-}
-
-func (p *FetchTaggedIDResult_) ReadField4(iprot thrift.TProtocol) error {
-	_, size, err := iprot.ReadListBegin()
-	if err != nil {
-		return thrift.PrependError("error reading list begin: ", err)
-	}
-	tSlice := make([]*Segments, 0, size)
-	p.Segments = tSlice
-	for i := 0; i < size; i++ {
-		_elem8 := &Segments{}
-		if err := _elem8.Read(iprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem8), err)
-		}
-		p.Segments = append(p.Segments, _elem8)
-	}
-	if err := iprot.ReadListEnd(); err != nil {
-		return thrift.PrependError("error reading list end: ", err)
-	}
-	return nil
-}
-
-func (p *FetchTaggedIDResult_) ReadField5(iprot thrift.TProtocol) error {
-	p.Err = &Error{
-		Type: 0,
-	}
-	if err := p.Err.Read(iprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Err), err)
+		p.PageToken = v
 	}
 	return nil
 }
 
-func (p *FetchTaggedIDResult_) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("FetchTaggedIDResult"); err != nil {
+func (p *FetchTaggedPageRequest) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("FetchTaggedPageRequest"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -3188,12 +3474,6 @@ func (p *FetchTaggedIDResult_) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField3(oprot); err != nil {
 			return err
 		}
-		if err := p.writeField4(oprot); err != nil {
-			return err
-		}
-		if err := p.writeField5(oprot); err != nil {
-			return err
-		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -3204,123 +3484,94 @@ func (p *FetchTaggedIDResult_) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *FetchTaggedIDResult_) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("id", thrift.STRING, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:id: ", p), err)
-	}
-	if err := oprot.WriteBinary(p.ID); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.id (1) field write error: ", p), err)
-	}
-	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:id: ", p), err)
-	}
-	return err
-}
-
-func (p *FetchTaggedIDResult_) writeField2(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("nameSpace", thrift.STRING, 2); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:nameSpace: ", p), err)
+func (p *FetchTaggedPageRequest) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("request", thrift.STRUCT, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:request: ", p), err)
 	}
-	if err := oprot.WriteBinary(p.NameSpace); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.nameSpace (2) field write error: ", p), err)
+	if err := p.Request.Write(oprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Request), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:nameSpace: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:request: ", p), err)
 	}
 	return err
 }
 
-func (p *FetchTaggedIDResult_) writeField3(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("encodedTags", thrift.STRING, 3); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:encodedTags: ", p), err)
+func (p *FetchTaggedPageRequest) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("pageSize", thrift.I64, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:pageSize: ", p), err)
 	}
-	if err := oprot.WriteBinary(p.EncodedTags); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.encodedTags (3) field write error: ", p), err)
+	if err := oprot.WriteI64(int64(p.PageSize)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.pageSize (2) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:encodedTags: ", p), err)
-	}
-	return err
-}
-
-func (p *FetchTaggedIDResult_) writeField4(oprot thrift.TProtocol) (err error) {
-	if p.IsSetSegments() {
-		if err := oprot.WriteFieldBegin("segments", thrift.LIST, 4); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:segments: ", p), err)
-		}
-		if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Segments)); err != nil {
-			return thrift.PrependError("error writing list begin: ", err)
-		}
-		for _, v := range p.Segments {
-			if err := v.Write(oprot); err != nil {
-				return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
-			}
-		}
-		if err := oprot.WriteListEnd(); err != nil {
-			return thrift.PrependError("error writing list end: ", err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 4:segments: ", p), err)
-		}
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:pageSize: ", p), err)
 	}
 	return err
 }
 
-func (p *FetchTaggedIDResult_) writeField5(oprot thrift.TProtocol) (err error) {
-	if p.IsSetErr() {
-		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 5); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 5:err: ", p), err)
+func (p *FetchTaggedPageRequest) writeField3(oprot thrift.TProtocol) (err error) {
+	if p.IsSetPageToken() {
+		if err := oprot.WriteFieldBegin("pageToken", thrift.STRING, 3); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:pageToken: ", p), err)
 		}
-		if err := p.Err.Write(oprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Err), err)
+		if err := oprot.WriteBinary(p.PageToken); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.pageToken (3) field write error: ", p), err)
 		}
 		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 5:err: ", p), err)
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 3:pageToken: ", p), err)
 		}
 	}
 	return err
 }
 
-func (p *FetchTaggedIDResult_) String() string {
+func (p *FetchTaggedPageRequest) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("FetchTaggedIDResult_(%+v)", *p)
+	return fmt.Sprintf("FetchTaggedPageRequest(%+v)", *p)
 }
 
 // Attributes:
-//  - NameSpace
-//  - Shard
-//  - Elements
-type FetchBlocksRawRequest struct {
-	NameSpace []byte                          `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
-	Shard     int32                           `thrift:"shard,2,required" db:"shard" json:"shard"`
-	Elements  []*FetchBlocksRawRequestElement `thrift:"elements,3,required" db:"elements" json:"elements"`
+//  - Result_
+//  - PageToken
+type FetchTaggedPageResult_ struct {
+	Result_   *FetchTaggedResult_ `thrift:"result,1,required" db:"result" json:"result"`
+	PageToken []byte              `thrift:"pageToken,2" db:"pageToken" json:"pageToken,omitempty"`
 }
 
-func NewFetchBlocksRawRequest() *FetchBlocksRawRequest {
-	return &FetchBlocksRawRequest{}
+func NewFetchTaggedPageResult_() *FetchTaggedPageResult_ {
+	return &FetchTaggedPageResult_{}
 }
 
-func (p *FetchBlocksRawRequest) GetNameSpace() []byte {
-	return p.NameSpace
+var FetchTaggedPageResult__Result__DEFAULT *FetchTaggedResult_
+
+func (p *FetchTaggedPageResult_) GetResult_() *FetchTaggedResult_ {
+	if !p.IsSetResult_() {
+		return FetchTaggedPageResult__Result__DEFAULT
+	}
+	return p.Result_
 }
 
-func (p *FetchBlocksRawRequest) GetShard() int32 {
-	return p.Shard
+var FetchTaggedPageResult__PageToken_DEFAULT []byte
+
+func (p *FetchTaggedPageResult_) GetPageToken() []byte {
+	return p.PageToken
+}
+func (p *FetchTaggedPageResult_) IsSetResult_() bool {
+	return p.Result_ != nil
 }
 
-func (p *FetchBlocksRawRequest) GetElements() []*FetchBlocksRawRequestElement {
-	return p.Elements
+func (p *FetchTaggedPageResult_) IsSetPageToken() bool {
+	return p.PageToken != nil
 }
-func (p *FetchBlocksRawRequest) Read(iprot thrift.TProtocol) error {
+
+func (p *FetchTaggedPageResult_) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetNameSpace bool = false
-	var issetShard bool = false
-	var issetElements bool = false
+	var issetResult_ bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -3335,17 +3586,11 @@ func (p *FetchBlocksRawRequest) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetNameSpace = true
+			issetResult_ = true
 		case 2:
 			if err := p.ReadField2(iprot); err != nil {
 				return err
 			}
-			issetShard = true
-		case 3:
-			if err := p.ReadField3(iprot); err != nil {
-				return err
-			}
-			issetElements = true
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -3358,58 +3603,31 @@ func (p *FetchBlocksRawRequest) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetNameSpace {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field NameSpace is not set"))
-	}
-	if !issetShard {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Shard is not set"))
-	}
-	if !issetElements {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Elements is not set"))
+	if !issetResult_ {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Result_ is not set"))
 	}
 	return nil
 }
 
-func (p *FetchBlocksRawRequest) ReadField1(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBinary(); err != nil {
-		return thrift.PrependError("error reading field 1: ", err)
-	} else {
-		p.NameSpace = v
+func (p *FetchTaggedPageResult_) ReadField1(iprot thrift.TProtocol) error {
+	p.Result_ = &FetchTaggedResult_{}
+	if err := p.Result_.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Result_), err)
 	}
 	return nil
 }
 
-func (p *FetchBlocksRawRequest) ReadField2(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI32(); err != nil {
+func (p *FetchTaggedPageResult_) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
 		return thrift.PrependError("error reading field 2: ", err)
 	} else {
-		p.Shard = v
-	}
-	return nil
-}
-
-func (p *FetchBlocksRawRequest) ReadField3(iprot thrift.TProtocol) error {
-	_, size, err := iprot.ReadListBegin()
-	if err != nil {
-		return thrift.PrependError("error reading list begin: ", err)
-	}
-	tSlice := make([]*FetchBlocksRawRequestElement, 0, size)
-	p.Elements = tSlice
-	for i := 0; i < size; i++ {
-		_elem9 := &FetchBlocksRawRequestElement{}
-		if err := _elem9.Read(iprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem9), err)
-		}
-		p.Elements = append(p.Elements, _elem9)
-	}
-	if err := iprot.ReadListEnd(); err != nil {
-		return thrift.PrependError("error reading list end: ", err)
+		p.PageToken = v
 	}
 	return nil
 }
 
-func (p *FetchBlocksRawRequest) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("FetchBlocksRawRequest"); err != nil {
+func (p *FetchTaggedPageResult_) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("FetchTaggedPageResult"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -3419,9 +3637,6 @@ func (p *FetchBlocksRawRequest) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField2(oprot); err != nil {
 			return err
 		}
-		if err := p.writeField3(oprot); err != nil {
-			return err
-		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -3432,90 +3647,105 @@ func (p *FetchBlocksRawRequest) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *FetchBlocksRawRequest) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("nameSpace", thrift.STRING, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:nameSpace: ", p), err)
-	}
-	if err := oprot.WriteBinary(p.NameSpace); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.nameSpace (1) field write error: ", p), err)
-	}
-	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:nameSpace: ", p), err)
-	}
-	return err
-}
-
-func (p *FetchBlocksRawRequest) writeField2(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("shard", thrift.I32, 2); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:shard: ", p), err)
+func (p *FetchTaggedPageResult_) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("result", thrift.STRUCT, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:result: ", p), err)
 	}
-	if err := oprot.WriteI32(int32(p.Shard)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.shard (2) field write error: ", p), err)
+	if err := p.Result_.Write(oprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Result_), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:shard: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:result: ", p), err)
 	}
 	return err
 }
 
-func (p *FetchBlocksRawRequest) writeField3(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("elements", thrift.LIST, 3); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:elements: ", p), err)
-	}
-	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Elements)); err != nil {
-		return thrift.PrependError("error writing list begin: ", err)
-	}
-	for _, v := range p.Elements {
-		if err := v.Write(oprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
+func (p *FetchTaggedPageResult_) writeField2(oprot thrift.TProtocol) (err error) {
+	if p.IsSetPageToken() {
+		if err := oprot.WriteFieldBegin("pageToken", thrift.STRING, 2); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:pageToken: ", p), err)
+		}
+		if err := oprot.WriteBinary(p.PageToken); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.pageToken (2) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 2:pageToken: ", p), err)
 		}
-	}
-	if err := oprot.WriteListEnd(); err != nil {
-		return thrift.PrependError("error writing list end: ", err)
-	}
-	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:elements: ", p), err)
 	}
 	return err
 }
 
-func (p *FetchBlocksRawRequest) String() string {
+func (p *FetchTaggedPageResult_) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("FetchBlocksRawRequest(%+v)", *p)
+	return fmt.Sprintf("FetchTaggedPageResult_(%+v)", *p)
 }
 
 // Attributes:
 //  - ID
-//  - Starts
-type FetchBlocksRawRequestElement struct {
-	ID     []byte  `thrift:"id,1,required" db:"id" json:"id"`
-	Starts []int64 `thrift:"starts,2,required" db:"starts" json:"starts"`
+//  - NameSpace
+//  - EncodedTags
+//  - Segments
+//  - Err
+type FetchTaggedIDResult_ struct {
+	ID          []byte      `thrift:"id,1,required" db:"id" json:"id"`
+	NameSpace   []byte      `thrift:"nameSpace,2,required" db:"nameSpace" json:"nameSpace"`
+	EncodedTags []byte      `thrift:"encodedTags,3,required" db:"encodedTags" json:"encodedTags"`
+	Segments    []*Segments `thrift:"segments,4" db:"segments" json:"segments,omitempty"`
+	Err         *Error      `thrift:"err,5" db:"err" json:"err,omitempty"`
 }
 
-func NewFetchBlocksRawRequestElement() *FetchBlocksRawRequestElement {
-	return &FetchBlocksRawRequestElement{}
+func NewFetchTaggedIDResult_() *FetchTaggedIDResult_ {
+	return &FetchTaggedIDResult_{}
 }
 
-func (p *FetchBlocksRawRequestElement) GetID() []byte {
+func (p *FetchTaggedIDResult_) GetID() []byte {
 	return p.ID
 }
 
-func (p *FetchBlocksRawRequestElement) GetStarts() []int64 {
-	return p.Starts
+func (p *FetchTaggedIDResult_) GetNameSpace() []byte {
+	return p.NameSpace
 }
-func (p *FetchBlocksRawRequestElement) Read(iprot thrift.TProtocol) error {
-	if _, err := iprot.ReadStructBegin(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
-	}
 
-	var issetID bool = false
-	var issetStarts bool = false
+func (p *FetchTaggedIDResult_) GetEncodedTags() []byte {
+	return p.EncodedTags
+}
 
-	for {
-		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
-		if err != nil {
+var FetchTaggedIDResult__Segments_DEFAULT []*Segments
+
+func (p *FetchTaggedIDResult_) GetSegments() []*Segments {
+	return p.Segments
+}
+
+var FetchTaggedIDResult__Err_DEFAULT *Error
+
+func (p *FetchTaggedIDResult_) GetErr() *Error {
+	if !p.IsSetErr() {
+		return FetchTaggedIDResult__Err_DEFAULT
+	}
+	return p.Err
+}
+func (p *FetchTaggedIDResult_) IsSetSegments() bool {
+	return p.Segments != nil
+}
+
+func (p *FetchTaggedIDResult_) IsSetErr() bool {
+	return p.Err != nil
+}
+
+func (p *FetchTaggedIDResult_) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	var issetID bool = false
+	var issetNameSpace bool = false
+	var issetEncodedTags bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
 			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
 		}
 		if fieldTypeId == thrift.STOP {
@@ -3531,7 +3761,20 @@ func (p *FetchBlocksRawRequestElement) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField2(iprot); err != nil {
 				return err
 			}
-			issetStarts = true
+			issetNameSpace = true
+		case 3:
+			if err := p.ReadField3(iprot); err != nil {
+				return err
+			}
+			issetEncodedTags = true
+		case 4:
+			if err := p.ReadField4(iprot); err != nil {
+				return err
+			}
+		case 5:
+			if err := p.ReadField5(iprot); err != nil {
+				return err
+			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -3547,13 +3790,16 @@ func (p *FetchBlocksRawRequestElement) Read(iprot thrift.TProtocol) error {
 	if !issetID {
 		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field ID is not set"))
 	}
-	if !issetStarts {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Starts is not set"))
+	if !issetNameSpace {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field NameSpace is not set"))
+	}
+	if !issetEncodedTags {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field EncodedTags is not set"))
 	}
 	return nil
 }
 
-func (p *FetchBlocksRawRequestElement) ReadField1(iprot thrift.TProtocol) error {
+func (p *FetchTaggedIDResult_) ReadField1(iprot thrift.TProtocol) error {
 	if v, err := iprot.ReadBinary(); err != nil {
 		return thrift.PrependError("error reading field 1: ", err)
 	} else {
@@ -3562,21 +3808,37 @@ func (p *FetchBlocksRawRequestElement) ReadField1(iprot thrift.TProtocol) error
 	return nil
 }
 
-func (p *FetchBlocksRawRequestElement) ReadField2(iprot thrift.TProtocol) error {
+func (p *FetchTaggedIDResult_) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		p.NameSpace = v
+	}
+	return nil
+}
+
+func (p *FetchTaggedIDResult_) ReadField3(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
+		return thrift.PrependError("error reading field 3: ", err)
+	} else {
+		p.EncodedTags = v
+	}
+	return nil
+}
+
+func (p *FetchTaggedIDResult_) ReadField4(iprot thrift.TProtocol) error {
 	_, size, err := iprot.ReadListBegin()
 	if err != nil {
 		return thrift.PrependError("error reading list begin: ", err)
 	}
-	tSlice := make([]int64, 0, size)
-	p.Starts = tSlice
+	tSlice := make([]*Segments, 0, size)
+	p.Segments = tSlice
 	for i := 0; i < size; i++ {
-		var _elem10 int64
-		if v, err := iprot.ReadI64(); err != nil {
-			return thrift.PrependError("error reading field 0: ", err)
-		} else {
-			_elem10 = v
+		_elem8 := &Segments{}
+		if err := _elem8.Read(iprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem8), err)
 		}
-		p.Starts = append(p.Starts, _elem10)
+		p.Segments = append(p.Segments, _elem8)
 	}
 	if err := iprot.ReadListEnd(); err != nil {
 		return thrift.PrependError("error reading list end: ", err)
@@ -3584,8 +3846,18 @@ func (p *FetchBlocksRawRequestElement) ReadField2(iprot thrift.TProtocol) error
 	return nil
 }
 
-func (p *FetchBlocksRawRequestElement) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("FetchBlocksRawRequestElement"); err != nil {
+func (p *FetchTaggedIDResult_) ReadField5(iprot thrift.TProtocol) error {
+	p.Err = &Error{
+		Type: 0,
+	}
+	if err := p.Err.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Err), err)
+	}
+	return nil
+}
+
+func (p *FetchTaggedIDResult_) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("FetchTaggedIDResult"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -3595,6 +3867,15 @@ func (p *FetchBlocksRawRequestElement) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField2(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField3(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField4(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField5(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -3605,7 +3886,7 @@ func (p *FetchBlocksRawRequestElement) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *FetchBlocksRawRequestElement) writeField1(oprot thrift.TProtocol) (err error) {
+func (p *FetchTaggedIDResult_) writeField1(oprot thrift.TProtocol) (err error) {
 	if err := oprot.WriteFieldBegin("id", thrift.STRING, 1); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:id: ", p), err)
 	}
@@ -3618,53 +3899,131 @@ func (p *FetchBlocksRawRequestElement) writeField1(oprot thrift.TProtocol) (err
 	return err
 }
 
-func (p *FetchBlocksRawRequestElement) writeField2(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("starts", thrift.LIST, 2); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:starts: ", p), err)
+func (p *FetchTaggedIDResult_) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("nameSpace", thrift.STRING, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:nameSpace: ", p), err)
 	}
-	if err := oprot.WriteListBegin(thrift.I64, len(p.Starts)); err != nil {
-		return thrift.PrependError("error writing list begin: ", err)
+	if err := oprot.WriteBinary(p.NameSpace); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.nameSpace (2) field write error: ", p), err)
 	}
-	for _, v := range p.Starts {
-		if err := oprot.WriteI64(int64(v)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T. (0) field write error: ", p), err)
-		}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:nameSpace: ", p), err)
 	}
-	if err := oprot.WriteListEnd(); err != nil {
-		return thrift.PrependError("error writing list end: ", err)
+	return err
+}
+
+func (p *FetchTaggedIDResult_) writeField3(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("encodedTags", thrift.STRING, 3); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:encodedTags: ", p), err)
+	}
+	if err := oprot.WriteBinary(p.EncodedTags); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.encodedTags (3) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:starts: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:encodedTags: ", p), err)
 	}
 	return err
 }
 
-func (p *FetchBlocksRawRequestElement) String() string {
+func (p *FetchTaggedIDResult_) writeField4(oprot thrift.TProtocol) (err error) {
+	if p.IsSetSegments() {
+		if err := oprot.WriteFieldBegin("segments", thrift.LIST, 4); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:segments: ", p), err)
+		}
+		if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Segments)); err != nil {
+			return thrift.PrependError("error writing list begin: ", err)
+		}
+		for _, v := range p.Segments {
+			if err := v.Write(oprot); err != nil {
+				return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
+			}
+		}
+		if err := oprot.WriteListEnd(); err != nil {
+			return thrift.PrependError("error writing list end: ", err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 4:segments: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *FetchTaggedIDResult_) writeField5(oprot thrift.TProtocol) (err error) {
+	if p.IsSetErr() {
+		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 5); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 5:err: ", p), err)
+		}
+		if err := p.Err.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Err), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 5:err: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *FetchTaggedIDResult_) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("FetchBlocksRawRequestElement(%+v)", *p)
+	return fmt.Sprintf("FetchTaggedIDResult_(%+v)", *p)
 }
 
 // Attributes:
-//  - Elements
-type FetchBlocksRawResult_ struct {
-	Elements []*Blocks `thrift:"elements,1,required" db:"elements" json:"elements"`
+//  - Name
+//  - TermsScanned
+//  - PostingsDecoded
+//  - DocsEmitted
+//  - DurationNanos
+//  - Children
+type QueryTrace struct {
+	Name            string        `thrift:"name,1,required" db:"name" json:"name"`
+	TermsScanned    int64         `thrift:"termsScanned,2,required" db:"termsScanned" json:"termsScanned"`
+	PostingsDecoded int64         `thrift:"postingsDecoded,3,required" db:"postingsDecoded" json:"postingsDecoded"`
+	DocsEmitted     int64         `thrift:"docsEmitted,4,required" db:"docsEmitted" json:"docsEmitted"`
+	DurationNanos   int64         `thrift:"durationNanos,5,required" db:"durationNanos" json:"durationNanos"`
+	Children        []*QueryTrace `thrift:"children,6,required" db:"children" json:"children"`
 }
 
-func NewFetchBlocksRawResult_() *FetchBlocksRawResult_ {
-	return &FetchBlocksRawResult_{}
+func NewQueryTrace() *QueryTrace {
+	return &QueryTrace{}
 }
 
-func (p *FetchBlocksRawResult_) GetElements() []*Blocks {
-	return p.Elements
+func (p *QueryTrace) GetName() string {
+	return p.Name
 }
-func (p *FetchBlocksRawResult_) Read(iprot thrift.TProtocol) error {
+
+func (p *QueryTrace) GetTermsScanned() int64 {
+	return p.TermsScanned
+}
+
+func (p *QueryTrace) GetPostingsDecoded() int64 {
+	return p.PostingsDecoded
+}
+
+func (p *QueryTrace) GetDocsEmitted() int64 {
+	return p.DocsEmitted
+}
+
+func (p *QueryTrace) GetDurationNanos() int64 {
+	return p.DurationNanos
+}
+
+func (p *QueryTrace) GetChildren() []*QueryTrace {
+	return p.Children
+}
+func (p *QueryTrace) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetElements bool = false
+	var issetName bool = false
+	var issetTermsScanned bool = false
+	var issetPostingsDecoded bool = false
+	var issetDocsEmitted bool = false
+	var issetDurationNanos bool = false
+	var issetChildren bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -3679,7 +4038,32 @@ func (p *FetchBlocksRawResult_) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetElements = true
+			issetName = true
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
+				return err
+			}
+			issetTermsScanned = true
+		case 3:
+			if err := p.ReadField3(iprot); err != nil {
+				return err
+			}
+			issetPostingsDecoded = true
+		case 4:
+			if err := p.ReadField4(iprot); err != nil {
+				return err
+			}
+			issetDocsEmitted = true
+		case 5:
+			if err := p.ReadField5(iprot); err != nil {
+				return err
+			}
+			issetDurationNanos = true
+		case 6:
+			if err := p.ReadField6(iprot); err != nil {
+				return err
+			}
+			issetChildren = true
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -3692,167 +4076,85 @@ func (p *FetchBlocksRawResult_) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetElements {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Elements is not set"))
+	if !issetName {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Name is not set"))
 	}
-	return nil
-}
-
-func (p *FetchBlocksRawResult_) ReadField1(iprot thrift.TProtocol) error {
-	_, size, err := iprot.ReadListBegin()
-	if err != nil {
-		return thrift.PrependError("error reading list begin: ", err)
+	if !issetTermsScanned {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field TermsScanned is not set"))
 	}
-	tSlice := make([]*Blocks, 0, size)
-	p.Elements = tSlice
-	for i := 0; i < size; i++ {
-		_elem11 := &Blocks{}
-		if err := _elem11.Read(iprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem11), err)
-		}
-		p.Elements = append(p.Elements, _elem11)
+	if !issetPostingsDecoded {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field PostingsDecoded is not set"))
 	}
-	if err := iprot.ReadListEnd(); err != nil {
-		return thrift.PrependError("error reading list end: ", err)
+	if !issetDocsEmitted {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field DocsEmitted is not set"))
+	}
+	if !issetDurationNanos {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field DurationNanos is not set"))
+	}
+	if !issetChildren {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Children is not set"))
 	}
 	return nil
 }
 
-func (p *FetchBlocksRawResult_) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("FetchBlocksRawResult"); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
-	}
-	if p != nil {
-		if err := p.writeField1(oprot); err != nil {
-			return err
-		}
-	}
-	if err := oprot.WriteFieldStop(); err != nil {
-		return thrift.PrependError("write field stop error: ", err)
-	}
-	if err := oprot.WriteStructEnd(); err != nil {
-		return thrift.PrependError("write struct stop error: ", err)
+func (p *QueryTrace) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
+	} else {
+		p.Name = v
 	}
 	return nil
 }
 
-func (p *FetchBlocksRawResult_) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("elements", thrift.LIST, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:elements: ", p), err)
-	}
-	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Elements)); err != nil {
-		return thrift.PrependError("error writing list begin: ", err)
-	}
-	for _, v := range p.Elements {
-		if err := v.Write(oprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
-		}
-	}
-	if err := oprot.WriteListEnd(); err != nil {
-		return thrift.PrependError("error writing list end: ", err)
-	}
-	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:elements: ", p), err)
+func (p *QueryTrace) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		p.TermsScanned = v
 	}
-	return err
+	return nil
 }
 
-func (p *FetchBlocksRawResult_) String() string {
-	if p == nil {
-		return "<nil>"
+func (p *QueryTrace) ReadField3(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 3: ", err)
+	} else {
+		p.PostingsDecoded = v
 	}
-	return fmt.Sprintf("FetchBlocksRawResult_(%+v)", *p)
-}
-
-// Attributes:
-//  - ID
-//  - Blocks
-type Blocks struct {
-	ID     []byte   `thrift:"id,1,required" db:"id" json:"id"`
-	Blocks []*Block `thrift:"blocks,2,required" db:"blocks" json:"blocks"`
-}
-
-func NewBlocks() *Blocks {
-	return &Blocks{}
-}
-
-func (p *Blocks) GetID() []byte {
-	return p.ID
-}
-
-func (p *Blocks) GetBlocks() []*Block {
-	return p.Blocks
+	return nil
 }
-func (p *Blocks) Read(iprot thrift.TProtocol) error {
-	if _, err := iprot.ReadStructBegin(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
-	}
-
-	var issetID bool = false
-	var issetBlocks bool = false
 
-	for {
-		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
-		if err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
-		}
-		if fieldTypeId == thrift.STOP {
-			break
-		}
-		switch fieldId {
-		case 1:
-			if err := p.ReadField1(iprot); err != nil {
-				return err
-			}
-			issetID = true
-		case 2:
-			if err := p.ReadField2(iprot); err != nil {
-				return err
-			}
-			issetBlocks = true
-		default:
-			if err := iprot.Skip(fieldTypeId); err != nil {
-				return err
-			}
-		}
-		if err := iprot.ReadFieldEnd(); err != nil {
-			return err
-		}
-	}
-	if err := iprot.ReadStructEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
-	}
-	if !issetID {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field ID is not set"))
-	}
-	if !issetBlocks {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Blocks is not set"))
+func (p *QueryTrace) ReadField4(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 4: ", err)
+	} else {
+		p.DocsEmitted = v
 	}
 	return nil
 }
 
-func (p *Blocks) ReadField1(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBinary(); err != nil {
-		return thrift.PrependError("error reading field 1: ", err)
+func (p *QueryTrace) ReadField5(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 5: ", err)
 	} else {
-		p.ID = v
+		p.DurationNanos = v
 	}
 	return nil
 }
 
-func (p *Blocks) ReadField2(iprot thrift.TProtocol) error {
+func (p *QueryTrace) ReadField6(iprot thrift.TProtocol) error {
 	_, size, err := iprot.ReadListBegin()
 	if err != nil {
 		return thrift.PrependError("error reading list begin: ", err)
 	}
-	tSlice := make([]*Block, 0, size)
-	p.Blocks = tSlice
+	tSlice := make([]*QueryTrace, 0, size)
+	p.Children = tSlice
 	for i := 0; i < size; i++ {
-		_elem12 := &Block{}
-		if err := _elem12.Read(iprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem12), err)
+		_elem9 := &QueryTrace{}
+		if err := _elem9.Read(iprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem9), err)
 		}
-		p.Blocks = append(p.Blocks, _elem12)
+		p.Children = append(p.Children, _elem9)
 	}
 	if err := iprot.ReadListEnd(); err != nil {
 		return thrift.PrependError("error reading list end: ", err)
@@ -3860,8 +4162,8 @@ func (p *Blocks) ReadField2(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *Blocks) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("Blocks"); err != nil {
+func (p *QueryTrace) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("QueryTrace"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -3871,6 +4173,18 @@ func (p *Blocks) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField2(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField3(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField4(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField5(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField6(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -3881,27 +4195,79 @@ func (p *Blocks) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *Blocks) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("id", thrift.STRING, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:id: ", p), err)
+func (p *QueryTrace) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("name", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:name: ", p), err)
 	}
-	if err := oprot.WriteBinary(p.ID); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.id (1) field write error: ", p), err)
+	if err := oprot.WriteString(string(p.Name)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.name (1) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:id: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:name: ", p), err)
 	}
 	return err
 }
 
-func (p *Blocks) writeField2(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("blocks", thrift.LIST, 2); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:blocks: ", p), err)
+func (p *QueryTrace) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("termsScanned", thrift.I64, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:termsScanned: ", p), err)
 	}
-	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Blocks)); err != nil {
+	if err := oprot.WriteI64(int64(p.TermsScanned)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.termsScanned (2) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:termsScanned: ", p), err)
+	}
+	return err
+}
+
+func (p *QueryTrace) writeField3(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("postingsDecoded", thrift.I64, 3); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:postingsDecoded: ", p), err)
+	}
+	if err := oprot.WriteI64(int64(p.PostingsDecoded)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.postingsDecoded (3) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:postingsDecoded: ", p), err)
+	}
+	return err
+}
+
+func (p *QueryTrace) writeField4(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("docsEmitted", thrift.I64, 4); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:docsEmitted: ", p), err)
+	}
+	if err := oprot.WriteI64(int64(p.DocsEmitted)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.docsEmitted (4) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 4:docsEmitted: ", p), err)
+	}
+	return err
+}
+
+func (p *QueryTrace) writeField5(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("durationNanos", thrift.I64, 5); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 5:durationNanos: ", p), err)
+	}
+	if err := oprot.WriteI64(int64(p.DurationNanos)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.durationNanos (5) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 5:durationNanos: ", p), err)
+	}
+	return err
+}
+
+func (p *QueryTrace) writeField6(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("children", thrift.LIST, 6); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 6:children: ", p), err)
+	}
+	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Children)); err != nil {
 		return thrift.PrependError("error writing list begin: ", err)
 	}
-	for _, v := range p.Blocks {
+	for _, v := range p.Children {
 		if err := v.Write(oprot); err != nil {
 			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
 		}
@@ -3910,82 +4276,87 @@ func (p *Blocks) writeField2(oprot thrift.TProtocol) (err error) {
 		return thrift.PrependError("error writing list end: ", err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:blocks: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 6:children: ", p), err)
 	}
 	return err
 }
 
-func (p *Blocks) String() string {
+func (p *QueryTrace) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("Blocks(%+v)", *p)
+	return fmt.Sprintf("QueryTrace(%+v)", *p)
 }
 
 // Attributes:
-//  - Start
-//  - Segments
-//  - Err
-//  - Checksum
-type Block struct {
-	Start    int64     `thrift:"start,1,required" db:"start" json:"start"`
-	Segments *Segments `thrift:"segments,2" db:"segments" json:"segments,omitempty"`
-	Err      *Error    `thrift:"err,3" db:"err" json:"err,omitempty"`
-	Checksum *int64    `thrift:"checksum,4" db:"checksum" json:"checksum,omitempty"`
+//  - NameSpace
+//  - Query
+//  - RangeStart
+//  - RangeEnd
+//  - Limit
+//  - RangeTimeType
+type AggregateQueryRequest struct {
+	NameSpace     []byte   `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
+	Query         []byte   `thrift:"query,2,required" db:"query" json:"query"`
+	RangeStart    int64    `thrift:"rangeStart,3,required" db:"rangeStart" json:"rangeStart"`
+	RangeEnd      int64    `thrift:"rangeEnd,4,required" db:"rangeEnd" json:"rangeEnd"`
+	Limit         *int64   `thrift:"limit,5" db:"limit" json:"limit,omitempty"`
+	RangeTimeType TimeType `thrift:"rangeTimeType,6" db:"rangeTimeType" json:"rangeTimeType,omitempty"`
 }
 
-func NewBlock() *Block {
-	return &Block{}
+func NewAggregateQueryRequest() *AggregateQueryRequest {
+	return &AggregateQueryRequest{
+		RangeTimeType: 0,
+	}
 }
 
-func (p *Block) GetStart() int64 {
-	return p.Start
+func (p *AggregateQueryRequest) GetNameSpace() []byte {
+	return p.NameSpace
 }
 
-var Block_Segments_DEFAULT *Segments
-
-func (p *Block) GetSegments() *Segments {
-	if !p.IsSetSegments() {
-		return Block_Segments_DEFAULT
-	}
-	return p.Segments
+func (p *AggregateQueryRequest) GetQuery() []byte {
+	return p.Query
 }
 
-var Block_Err_DEFAULT *Error
+func (p *AggregateQueryRequest) GetRangeStart() int64 {
+	return p.RangeStart
+}
 
-func (p *Block) GetErr() *Error {
-	if !p.IsSetErr() {
-		return Block_Err_DEFAULT
-	}
-	return p.Err
+func (p *AggregateQueryRequest) GetRangeEnd() int64 {
+	return p.RangeEnd
 }
 
-var Block_Checksum_DEFAULT int64
+var AggregateQueryRequest_Limit_DEFAULT int64
 
-func (p *Block) GetChecksum() int64 {
-	if !p.IsSetChecksum() {
-		return Block_Checksum_DEFAULT
+func (p *AggregateQueryRequest) GetLimit() int64 {
+	if !p.IsSetLimit() {
+		return AggregateQueryRequest_Limit_DEFAULT
 	}
-	return *p.Checksum
-}
-func (p *Block) IsSetSegments() bool {
-	return p.Segments != nil
+	return *p.Limit
 }
 
-func (p *Block) IsSetErr() bool {
-	return p.Err != nil
+var AggregateQueryRequest_RangeTimeType_DEFAULT TimeType = 0
+
+func (p *AggregateQueryRequest) GetRangeTimeType() TimeType {
+	return p.RangeTimeType
+}
+func (p *AggregateQueryRequest) IsSetLimit() bool {
+	return p.Limit != nil
 }
 
-func (p *Block) IsSetChecksum() bool {
-	return p.Checksum != nil
+func (p *AggregateQueryRequest) IsSetRangeTimeType() bool {
+	return p.RangeTimeType != AggregateQueryRequest_RangeTimeType_DEFAULT
 }
 
-func (p *Block) Read(iprot thrift.TProtocol) error {
+func (p *AggregateQueryRequest) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetStart bool = false
+	var issetNameSpace bool = false
+	var issetQuery bool = false
+	var issetRangeStart bool = false
+	var issetRangeEnd bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -4000,19 +4371,30 @@ func (p *Block) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetStart = true
+			issetNameSpace = true
 		case 2:
 			if err := p.ReadField2(iprot); err != nil {
 				return err
 			}
+			issetQuery = true
 		case 3:
 			if err := p.ReadField3(iprot); err != nil {
 				return err
 			}
+			issetRangeStart = true
 		case 4:
 			if err := p.ReadField4(iprot); err != nil {
 				return err
 			}
+			issetRangeEnd = true
+		case 5:
+			if err := p.ReadField5(iprot); err != nil {
+				return err
+			}
+		case 6:
+			if err := p.ReadField6(iprot); err != nil {
+				return err
+			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -4025,50 +4407,78 @@ func (p *Block) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetStart {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Start is not set"))
+	if !issetNameSpace {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field NameSpace is not set"))
+	}
+	if !issetQuery {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Query is not set"))
+	}
+	if !issetRangeStart {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field RangeStart is not set"))
+	}
+	if !issetRangeEnd {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field RangeEnd is not set"))
 	}
 	return nil
 }
 
-func (p *Block) ReadField1(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI64(); err != nil {
+func (p *AggregateQueryRequest) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
 		return thrift.PrependError("error reading field 1: ", err)
 	} else {
-		p.Start = v
+		p.NameSpace = v
 	}
 	return nil
 }
 
-func (p *Block) ReadField2(iprot thrift.TProtocol) error {
-	p.Segments = &Segments{}
-	if err := p.Segments.Read(iprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Segments), err)
+func (p *AggregateQueryRequest) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		p.Query = v
 	}
 	return nil
 }
 
-func (p *Block) ReadField3(iprot thrift.TProtocol) error {
-	p.Err = &Error{
-		Type: 0,
-	}
-	if err := p.Err.Read(iprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Err), err)
+func (p *AggregateQueryRequest) ReadField3(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 3: ", err)
+	} else {
+		p.RangeStart = v
 	}
 	return nil
 }
 
-func (p *Block) ReadField4(iprot thrift.TProtocol) error {
+func (p *AggregateQueryRequest) ReadField4(iprot thrift.TProtocol) error {
 	if v, err := iprot.ReadI64(); err != nil {
 		return thrift.PrependError("error reading field 4: ", err)
 	} else {
-		p.Checksum = &v
+		p.RangeEnd = v
 	}
 	return nil
 }
 
-func (p *Block) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("Block"); err != nil {
+func (p *AggregateQueryRequest) ReadField5(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 5: ", err)
+	} else {
+		p.Limit = &v
+	}
+	return nil
+}
+
+func (p *AggregateQueryRequest) ReadField6(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI32(); err != nil {
+		return thrift.PrependError("error reading field 6: ", err)
+	} else {
+		temp := TimeType(v)
+		p.RangeTimeType = temp
+	}
+	return nil
+}
+
+func (p *AggregateQueryRequest) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("AggregateQueryRequest"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -4084,6 +4494,12 @@ func (p *Block) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField4(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField5(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField6(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -4094,97 +4510,122 @@ func (p *Block) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *Block) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("start", thrift.I64, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:start: ", p), err)
+func (p *AggregateQueryRequest) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("nameSpace", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:nameSpace: ", p), err)
 	}
-	if err := oprot.WriteI64(int64(p.Start)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.start (1) field write error: ", p), err)
+	if err := oprot.WriteBinary(p.NameSpace); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.nameSpace (1) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:start: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:nameSpace: ", p), err)
 	}
 	return err
 }
 
-func (p *Block) writeField2(oprot thrift.TProtocol) (err error) {
-	if p.IsSetSegments() {
-		if err := oprot.WriteFieldBegin("segments", thrift.STRUCT, 2); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:segments: ", p), err)
-		}
-		if err := p.Segments.Write(oprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Segments), err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 2:segments: ", p), err)
-		}
+func (p *AggregateQueryRequest) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("query", thrift.STRING, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:query: ", p), err)
+	}
+	if err := oprot.WriteBinary(p.Query); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.query (2) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:query: ", p), err)
 	}
 	return err
 }
 
-func (p *Block) writeField3(oprot thrift.TProtocol) (err error) {
-	if p.IsSetErr() {
-		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 3); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:err: ", p), err)
+func (p *AggregateQueryRequest) writeField3(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("rangeStart", thrift.I64, 3); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:rangeStart: ", p), err)
+	}
+	if err := oprot.WriteI64(int64(p.RangeStart)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.rangeStart (3) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:rangeStart: ", p), err)
+	}
+	return err
+}
+
+func (p *AggregateQueryRequest) writeField4(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("rangeEnd", thrift.I64, 4); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:rangeEnd: ", p), err)
+	}
+	if err := oprot.WriteI64(int64(p.RangeEnd)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.rangeEnd (4) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 4:rangeEnd: ", p), err)
+	}
+	return err
+}
+
+func (p *AggregateQueryRequest) writeField5(oprot thrift.TProtocol) (err error) {
+	if p.IsSetLimit() {
+		if err := oprot.WriteFieldBegin("limit", thrift.I64, 5); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 5:limit: ", p), err)
 		}
-		if err := p.Err.Write(oprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Err), err)
+		if err := oprot.WriteI64(int64(*p.Limit)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.limit (5) field write error: ", p), err)
 		}
 		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 3:err: ", p), err)
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 5:limit: ", p), err)
 		}
 	}
 	return err
 }
 
-func (p *Block) writeField4(oprot thrift.TProtocol) (err error) {
-	if p.IsSetChecksum() {
-		if err := oprot.WriteFieldBegin("checksum", thrift.I64, 4); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:checksum: ", p), err)
+func (p *AggregateQueryRequest) writeField6(oprot thrift.TProtocol) (err error) {
+	if p.IsSetRangeTimeType() {
+		if err := oprot.WriteFieldBegin("rangeTimeType", thrift.I32, 6); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 6:rangeTimeType: ", p), err)
 		}
-		if err := oprot.WriteI64(int64(*p.Checksum)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.checksum (4) field write error: ", p), err)
+		if err := oprot.WriteI32(int32(p.RangeTimeType)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.rangeTimeType (6) field write error: ", p), err)
 		}
 		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 4:checksum: ", p), err)
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 6:rangeTimeType: ", p), err)
 		}
 	}
 	return err
 }
 
-func (p *Block) String() string {
+func (p *AggregateQueryRequest) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("Block(%+v)", *p)
+	return fmt.Sprintf("AggregateQueryRequest(%+v)", *p)
 }
 
 // Attributes:
-//  - Name
-//  - Value
-type Tag struct {
-	Name  string `thrift:"name,1,required" db:"name" json:"name"`
-	Value string `thrift:"value,2,required" db:"value" json:"value"`
+//  - Terms
+//  - Exhaustive
+type AggregateQueryResult_ struct {
+	Terms      []*AggregateQueryResultTerm `thrift:"terms,1,required" db:"terms" json:"terms"`
+	Exhaustive bool                        `thrift:"exhaustive,2,required" db:"exhaustive" json:"exhaustive"`
 }
 
-func NewTag() *Tag {
-	return &Tag{}
+func NewAggregateQueryResult_() *AggregateQueryResult_ {
+	return &AggregateQueryResult_{}
 }
 
-func (p *Tag) GetName() string {
-	return p.Name
+func (p *AggregateQueryResult_) GetTerms() []*AggregateQueryResultTerm {
+	return p.Terms
 }
 
-func (p *Tag) GetValue() string {
-	return p.Value
+func (p *AggregateQueryResult_) GetExhaustive() bool {
+	return p.Exhaustive
 }
-func (p *Tag) Read(iprot thrift.TProtocol) error {
+
+func (p *AggregateQueryResult_) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetName bool = false
-	var issetValue bool = false
+	var issetTerms bool = false
+	var issetExhaustive bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -4199,12 +4640,12 @@ func (p *Tag) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetName = true
+			issetTerms = true
 		case 2:
 			if err := p.ReadField2(iprot); err != nil {
 				return err
 			}
-			issetValue = true
+			issetExhaustive = true
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -4217,35 +4658,46 @@ func (p *Tag) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetName {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Name is not set"))
+	if !issetTerms {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Terms is not set"))
 	}
-	if !issetValue {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Value is not set"))
+	if !issetExhaustive {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Exhaustive is not set"))
 	}
 	return nil
 }
 
-func (p *Tag) ReadField1(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadString(); err != nil {
-		return thrift.PrependError("error reading field 1: ", err)
-	} else {
-		p.Name = v
+func (p *AggregateQueryResult_) ReadField1(iprot thrift.TProtocol) error {
+	_, size, err := iprot.ReadListBegin()
+	if err != nil {
+		return thrift.PrependError("error reading list begin: ", err)
+	}
+	tSlice := make([]*AggregateQueryResultTerm, 0, size)
+	p.Terms = tSlice
+	for i := 0; i < size; i++ {
+		_elem182 := &AggregateQueryResultTerm{}
+		if err := _elem182.Read(iprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem182), err)
+		}
+		p.Terms = append(p.Terms, _elem182)
+	}
+	if err := iprot.ReadListEnd(); err != nil {
+		return thrift.PrependError("error reading list end: ", err)
 	}
 	return nil
 }
 
-func (p *Tag) ReadField2(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadString(); err != nil {
+func (p *AggregateQueryResult_) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
 		return thrift.PrependError("error reading field 2: ", err)
 	} else {
-		p.Value = v
+		p.Exhaustive = v
 	}
 	return nil
 }
 
-func (p *Tag) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("Tag"); err != nil {
+func (p *AggregateQueryResult_) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("AggregateQueryResult"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -4265,146 +4717,280 @@ func (p *Tag) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *Tag) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("name", thrift.STRING, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:name: ", p), err)
+func (p *AggregateQueryResult_) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("terms", thrift.LIST, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:terms: ", p), err)
 	}
-	if err := oprot.WriteString(string(p.Name)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.name (1) field write error: ", p), err)
+	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Terms)); err != nil {
+		return thrift.PrependError("error writing list begin: ", err)
+	}
+	for _, v := range p.Terms {
+		if err := v.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
+		}
+	}
+	if err := oprot.WriteListEnd(); err != nil {
+		return thrift.PrependError("error writing list end: ", err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:name: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:terms: ", p), err)
 	}
 	return err
 }
 
-func (p *Tag) writeField2(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("value", thrift.STRING, 2); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:value: ", p), err)
+func (p *AggregateQueryResult_) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("exhaustive", thrift.BOOL, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:exhaustive: ", p), err)
 	}
-	if err := oprot.WriteString(string(p.Value)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.value (2) field write error: ", p), err)
+	if err := oprot.WriteBool(bool(p.Exhaustive)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.exhaustive (2) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:value: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:exhaustive: ", p), err)
 	}
 	return err
 }
 
-func (p *Tag) String() string {
+func (p *AggregateQueryResult_) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("Tag(%+v)", *p)
+	return fmt.Sprintf("AggregateQueryResult_(%+v)", *p)
 }
 
 // Attributes:
-//  - NameSpace
-//  - Shard
-//  - RangeStart
-//  - RangeEnd
-//  - Limit
-//  - PageToken
-//  - IncludeSizes
-//  - IncludeChecksums
-//  - IncludeLastRead
-type FetchBlocksMetadataRawRequest struct {
-	NameSpace        []byte `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
-	Shard            int32  `thrift:"shard,2,required" db:"shard" json:"shard"`
-	RangeStart       int64  `thrift:"rangeStart,3,required" db:"rangeStart" json:"rangeStart"`
-	RangeEnd         int64  `thrift:"rangeEnd,4,required" db:"rangeEnd" json:"rangeEnd"`
-	Limit            int64  `thrift:"limit,5,required" db:"limit" json:"limit"`
-	PageToken        *int64 `thrift:"pageToken,6" db:"pageToken" json:"pageToken,omitempty"`
-	IncludeSizes     *bool  `thrift:"includeSizes,7" db:"includeSizes" json:"includeSizes,omitempty"`
-	IncludeChecksums *bool  `thrift:"includeChecksums,8" db:"includeChecksums" json:"includeChecksums,omitempty"`
-	IncludeLastRead  *bool  `thrift:"includeLastRead,9" db:"includeLastRead" json:"includeLastRead,omitempty"`
+//  - Term
+//  - Count
+type AggregateQueryResultTerm struct {
+	Term  []byte `thrift:"term,1,required" db:"term" json:"term"`
+	Count int64  `thrift:"count,2,required" db:"count" json:"count"`
 }
 
-func NewFetchBlocksMetadataRawRequest() *FetchBlocksMetadataRawRequest {
-	return &FetchBlocksMetadataRawRequest{}
+func NewAggregateQueryResultTerm() *AggregateQueryResultTerm {
+	return &AggregateQueryResultTerm{}
 }
 
-func (p *FetchBlocksMetadataRawRequest) GetNameSpace() []byte {
-	return p.NameSpace
+func (p *AggregateQueryResultTerm) GetTerm() []byte {
+	return p.Term
 }
 
-func (p *FetchBlocksMetadataRawRequest) GetShard() int32 {
-	return p.Shard
+func (p *AggregateQueryResultTerm) GetCount() int64 {
+	return p.Count
 }
 
-func (p *FetchBlocksMetadataRawRequest) GetRangeStart() int64 {
-	return p.RangeStart
+func (p *AggregateQueryResultTerm) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	var issetTerm bool = false
+	var issetCount bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetTerm = true
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
+				return err
+			}
+			issetCount = true
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	if !issetTerm {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Term is not set"))
+	}
+	if !issetCount {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Count is not set"))
+	}
+	return nil
 }
 
-func (p *FetchBlocksMetadataRawRequest) GetRangeEnd() int64 {
-	return p.RangeEnd
+func (p *AggregateQueryResultTerm) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
+	} else {
+		p.Term = v
+	}
+	return nil
 }
 
-func (p *FetchBlocksMetadataRawRequest) GetLimit() int64 {
-	return p.Limit
+func (p *AggregateQueryResultTerm) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		p.Count = v
+	}
+	return nil
 }
 
-var FetchBlocksMetadataRawRequest_PageToken_DEFAULT int64
+func (p *AggregateQueryResultTerm) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("AggregateQueryResultTerm"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField2(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
 
-func (p *FetchBlocksMetadataRawRequest) GetPageToken() int64 {
-	if !p.IsSetPageToken() {
-		return FetchBlocksMetadataRawRequest_PageToken_DEFAULT
+func (p *AggregateQueryResultTerm) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("term", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:term: ", p), err)
 	}
-	return *p.PageToken
+	if err := oprot.WriteBinary(p.Term); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.term (1) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:term: ", p), err)
+	}
+	return err
 }
 
-var FetchBlocksMetadataRawRequest_IncludeSizes_DEFAULT bool
+func (p *AggregateQueryResultTerm) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("count", thrift.I64, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:count: ", p), err)
+	}
+	if err := oprot.WriteI64(int64(p.Count)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.count (2) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:count: ", p), err)
+	}
+	return err
+}
 
-func (p *FetchBlocksMetadataRawRequest) GetIncludeSizes() bool {
-	if !p.IsSetIncludeSizes() {
-		return FetchBlocksMetadataRawRequest_IncludeSizes_DEFAULT
+func (p *AggregateQueryResultTerm) String() string {
+	if p == nil {
+		return "<nil>"
 	}
-	return *p.IncludeSizes
+	return fmt.Sprintf("AggregateQueryResultTerm(%+v)", *p)
 }
 
-var FetchBlocksMetadataRawRequest_IncludeChecksums_DEFAULT bool
+// Attributes:
+//  - NameSpace
+//  - TagName
+//  - RangeStart
+//  - RangeEnd
+//  - Limit
+//  - RangeTimeType
+//  - TagValuePrefix
+//  - Cursor
+type AggregateTagValuesRequest struct {
+	NameSpace      []byte   `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
+	TagName        []byte   `thrift:"tagName,2,required" db:"tagName" json:"tagName"`
+	RangeStart     int64    `thrift:"rangeStart,3,required" db:"rangeStart" json:"rangeStart"`
+	RangeEnd       int64    `thrift:"rangeEnd,4,required" db:"rangeEnd" json:"rangeEnd"`
+	Limit          *int64   `thrift:"limit,5" db:"limit" json:"limit,omitempty"`
+	RangeTimeType  TimeType `thrift:"rangeTimeType,6" db:"rangeTimeType" json:"rangeTimeType,omitempty"`
+	TagValuePrefix []byte   `thrift:"tagValuePrefix,7" db:"tagValuePrefix" json:"tagValuePrefix,omitempty"`
+	Cursor         []byte   `thrift:"cursor,8" db:"cursor" json:"cursor,omitempty"`
+}
 
-func (p *FetchBlocksMetadataRawRequest) GetIncludeChecksums() bool {
-	if !p.IsSetIncludeChecksums() {
-		return FetchBlocksMetadataRawRequest_IncludeChecksums_DEFAULT
+func NewAggregateTagValuesRequest() *AggregateTagValuesRequest {
+	return &AggregateTagValuesRequest{
+		RangeTimeType: 0,
 	}
-	return *p.IncludeChecksums
 }
 
-var FetchBlocksMetadataRawRequest_IncludeLastRead_DEFAULT bool
+func (p *AggregateTagValuesRequest) GetNameSpace() []byte {
+	return p.NameSpace
+}
 
-func (p *FetchBlocksMetadataRawRequest) GetIncludeLastRead() bool {
-	if !p.IsSetIncludeLastRead() {
-		return FetchBlocksMetadataRawRequest_IncludeLastRead_DEFAULT
+func (p *AggregateTagValuesRequest) GetTagName() []byte {
+	return p.TagName
+}
+
+func (p *AggregateTagValuesRequest) GetRangeStart() int64 {
+	return p.RangeStart
+}
+
+func (p *AggregateTagValuesRequest) GetRangeEnd() int64 {
+	return p.RangeEnd
+}
+
+var AggregateTagValuesRequest_Limit_DEFAULT int64
+
+func (p *AggregateTagValuesRequest) GetLimit() int64 {
+	if !p.IsSetLimit() {
+		return AggregateTagValuesRequest_Limit_DEFAULT
 	}
-	return *p.IncludeLastRead
+	return *p.Limit
 }
-func (p *FetchBlocksMetadataRawRequest) IsSetPageToken() bool {
-	return p.PageToken != nil
+
+var AggregateTagValuesRequest_RangeTimeType_DEFAULT TimeType = 0
+
+func (p *AggregateTagValuesRequest) GetRangeTimeType() TimeType {
+	return p.RangeTimeType
 }
 
-func (p *FetchBlocksMetadataRawRequest) IsSetIncludeSizes() bool {
-	return p.IncludeSizes != nil
+var AggregateTagValuesRequest_TagValuePrefix_DEFAULT []byte
+
+func (p *AggregateTagValuesRequest) GetTagValuePrefix() []byte {
+	return p.TagValuePrefix
 }
 
-func (p *FetchBlocksMetadataRawRequest) IsSetIncludeChecksums() bool {
-	return p.IncludeChecksums != nil
+var AggregateTagValuesRequest_Cursor_DEFAULT []byte
+
+func (p *AggregateTagValuesRequest) GetCursor() []byte {
+	return p.Cursor
+}
+func (p *AggregateTagValuesRequest) IsSetLimit() bool {
+	return p.Limit != nil
 }
 
-func (p *FetchBlocksMetadataRawRequest) IsSetIncludeLastRead() bool {
-	return p.IncludeLastRead != nil
+func (p *AggregateTagValuesRequest) IsSetRangeTimeType() bool {
+	return p.RangeTimeType != AggregateTagValuesRequest_RangeTimeType_DEFAULT
 }
 
-func (p *FetchBlocksMetadataRawRequest) Read(iprot thrift.TProtocol) error {
+func (p *AggregateTagValuesRequest) IsSetTagValuePrefix() bool {
+	return p.TagValuePrefix != nil
+}
+
+func (p *AggregateTagValuesRequest) IsSetCursor() bool {
+	return p.Cursor != nil
+}
+
+func (p *AggregateTagValuesRequest) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
 	var issetNameSpace bool = false
-	var issetShard bool = false
+	var issetTagName bool = false
 	var issetRangeStart bool = false
 	var issetRangeEnd bool = false
-	var issetLimit bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -4424,7 +5010,7 @@ func (p *FetchBlocksMetadataRawRequest) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField2(iprot); err != nil {
 				return err
 			}
-			issetShard = true
+			issetTagName = true
 		case 3:
 			if err := p.ReadField3(iprot); err != nil {
 				return err
@@ -4439,7 +5025,6 @@ func (p *FetchBlocksMetadataRawRequest) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField5(iprot); err != nil {
 				return err
 			}
-			issetLimit = true
 		case 6:
 			if err := p.ReadField6(iprot); err != nil {
 				return err
@@ -4452,10 +5037,6 @@ func (p *FetchBlocksMetadataRawRequest) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField8(iprot); err != nil {
 				return err
 			}
-		case 9:
-			if err := p.ReadField9(iprot); err != nil {
-				return err
-			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -4471,8 +5052,8 @@ func (p *FetchBlocksMetadataRawRequest) Read(iprot thrift.TProtocol) error {
 	if !issetNameSpace {
 		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field NameSpace is not set"))
 	}
-	if !issetShard {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Shard is not set"))
+	if !issetTagName {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field TagName is not set"))
 	}
 	if !issetRangeStart {
 		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field RangeStart is not set"))
@@ -4480,13 +5061,10 @@ func (p *FetchBlocksMetadataRawRequest) Read(iprot thrift.TProtocol) error {
 	if !issetRangeEnd {
 		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field RangeEnd is not set"))
 	}
-	if !issetLimit {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Limit is not set"))
-	}
 	return nil
 }
 
-func (p *FetchBlocksMetadataRawRequest) ReadField1(iprot thrift.TProtocol) error {
+func (p *AggregateTagValuesRequest) ReadField1(iprot thrift.TProtocol) error {
 	if v, err := iprot.ReadBinary(); err != nil {
 		return thrift.PrependError("error reading field 1: ", err)
 	} else {
@@ -4495,16 +5073,16 @@ func (p *FetchBlocksMetadataRawRequest) ReadField1(iprot thrift.TProtocol) error
 	return nil
 }
 
-func (p *FetchBlocksMetadataRawRequest) ReadField2(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI32(); err != nil {
+func (p *AggregateTagValuesRequest) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
 		return thrift.PrependError("error reading field 2: ", err)
 	} else {
-		p.Shard = v
+		p.TagName = v
 	}
 	return nil
 }
 
-func (p *FetchBlocksMetadataRawRequest) ReadField3(iprot thrift.TProtocol) error {
+func (p *AggregateTagValuesRequest) ReadField3(iprot thrift.TProtocol) error {
 	if v, err := iprot.ReadI64(); err != nil {
 		return thrift.PrependError("error reading field 3: ", err)
 	} else {
@@ -4513,7 +5091,7 @@ func (p *FetchBlocksMetadataRawRequest) ReadField3(iprot thrift.TProtocol) error
 	return nil
 }
 
-func (p *FetchBlocksMetadataRawRequest) ReadField4(iprot thrift.TProtocol) error {
+func (p *AggregateTagValuesRequest) ReadField4(iprot thrift.TProtocol) error {
 	if v, err := iprot.ReadI64(); err != nil {
 		return thrift.PrependError("error reading field 4: ", err)
 	} else {
@@ -4522,53 +5100,45 @@ func (p *FetchBlocksMetadataRawRequest) ReadField4(iprot thrift.TProtocol) error
 	return nil
 }
 
-func (p *FetchBlocksMetadataRawRequest) ReadField5(iprot thrift.TProtocol) error {
+func (p *AggregateTagValuesRequest) ReadField5(iprot thrift.TProtocol) error {
 	if v, err := iprot.ReadI64(); err != nil {
 		return thrift.PrependError("error reading field 5: ", err)
 	} else {
-		p.Limit = v
+		p.Limit = &v
 	}
 	return nil
 }
 
-func (p *FetchBlocksMetadataRawRequest) ReadField6(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI64(); err != nil {
+func (p *AggregateTagValuesRequest) ReadField6(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI32(); err != nil {
 		return thrift.PrependError("error reading field 6: ", err)
 	} else {
-		p.PageToken = &v
+		temp := TimeType(v)
+		p.RangeTimeType = temp
 	}
 	return nil
 }
 
-func (p *FetchBlocksMetadataRawRequest) ReadField7(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBool(); err != nil {
+func (p *AggregateTagValuesRequest) ReadField7(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
 		return thrift.PrependError("error reading field 7: ", err)
 	} else {
-		p.IncludeSizes = &v
+		p.TagValuePrefix = v
 	}
 	return nil
 }
 
-func (p *FetchBlocksMetadataRawRequest) ReadField8(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBool(); err != nil {
+func (p *AggregateTagValuesRequest) ReadField8(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
 		return thrift.PrependError("error reading field 8: ", err)
 	} else {
-		p.IncludeChecksums = &v
-	}
-	return nil
-}
-
-func (p *FetchBlocksMetadataRawRequest) ReadField9(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBool(); err != nil {
-		return thrift.PrependError("error reading field 9: ", err)
-	} else {
-		p.IncludeLastRead = &v
+		p.Cursor = v
 	}
 	return nil
 }
 
-func (p *FetchBlocksMetadataRawRequest) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("FetchBlocksMetadataRawRequest"); err != nil {
+func (p *AggregateTagValuesRequest) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("AggregateTagValuesRequest"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -4596,9 +5166,6 @@ func (p *FetchBlocksMetadataRawRequest) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField8(oprot); err != nil {
 			return err
 		}
-		if err := p.writeField9(oprot); err != nil {
-			return err
-		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -4609,7 +5176,7 @@ func (p *FetchBlocksMetadataRawRequest) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *FetchBlocksMetadataRawRequest) writeField1(oprot thrift.TProtocol) (err error) {
+func (p *AggregateTagValuesRequest) writeField1(oprot thrift.TProtocol) (err error) {
 	if err := oprot.WriteFieldBegin("nameSpace", thrift.STRING, 1); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:nameSpace: ", p), err)
 	}
@@ -4622,20 +5189,20 @@ func (p *FetchBlocksMetadataRawRequest) writeField1(oprot thrift.TProtocol) (err
 	return err
 }
 
-func (p *FetchBlocksMetadataRawRequest) writeField2(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("shard", thrift.I32, 2); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:shard: ", p), err)
+func (p *AggregateTagValuesRequest) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("tagName", thrift.STRING, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:tagName: ", p), err)
 	}
-	if err := oprot.WriteI32(int32(p.Shard)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.shard (2) field write error: ", p), err)
+	if err := oprot.WriteBinary(p.TagName); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.tagName (2) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:shard: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:tagName: ", p), err)
 	}
 	return err
 }
 
-func (p *FetchBlocksMetadataRawRequest) writeField3(oprot thrift.TProtocol) (err error) {
+func (p *AggregateTagValuesRequest) writeField3(oprot thrift.TProtocol) (err error) {
 	if err := oprot.WriteFieldBegin("rangeStart", thrift.I64, 3); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:rangeStart: ", p), err)
 	}
@@ -4648,7 +5215,7 @@ func (p *FetchBlocksMetadataRawRequest) writeField3(oprot thrift.TProtocol) (err
 	return err
 }
 
-func (p *FetchBlocksMetadataRawRequest) writeField4(oprot thrift.TProtocol) (err error) {
+func (p *AggregateTagValuesRequest) writeField4(oprot thrift.TProtocol) (err error) {
 	if err := oprot.WriteFieldBegin("rangeEnd", thrift.I64, 4); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:rangeEnd: ", p), err)
 	}
@@ -4661,120 +5228,111 @@ func (p *FetchBlocksMetadataRawRequest) writeField4(oprot thrift.TProtocol) (err
 	return err
 }
 
-func (p *FetchBlocksMetadataRawRequest) writeField5(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("limit", thrift.I64, 5); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 5:limit: ", p), err)
-	}
-	if err := oprot.WriteI64(int64(p.Limit)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.limit (5) field write error: ", p), err)
-	}
-	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 5:limit: ", p), err)
+func (p *AggregateTagValuesRequest) writeField5(oprot thrift.TProtocol) (err error) {
+	if p.IsSetLimit() {
+		if err := oprot.WriteFieldBegin("limit", thrift.I64, 5); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 5:limit: ", p), err)
+		}
+		if err := oprot.WriteI64(int64(*p.Limit)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.limit (5) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 5:limit: ", p), err)
+		}
 	}
 	return err
 }
 
-func (p *FetchBlocksMetadataRawRequest) writeField6(oprot thrift.TProtocol) (err error) {
-	if p.IsSetPageToken() {
-		if err := oprot.WriteFieldBegin("pageToken", thrift.I64, 6); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 6:pageToken: ", p), err)
+func (p *AggregateTagValuesRequest) writeField6(oprot thrift.TProtocol) (err error) {
+	if p.IsSetRangeTimeType() {
+		if err := oprot.WriteFieldBegin("rangeTimeType", thrift.I32, 6); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 6:rangeTimeType: ", p), err)
 		}
-		if err := oprot.WriteI64(int64(*p.PageToken)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.pageToken (6) field write error: ", p), err)
+		if err := oprot.WriteI32(int32(p.RangeTimeType)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.rangeTimeType (6) field write error: ", p), err)
 		}
 		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 6:pageToken: ", p), err)
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 6:rangeTimeType: ", p), err)
 		}
 	}
 	return err
 }
 
-func (p *FetchBlocksMetadataRawRequest) writeField7(oprot thrift.TProtocol) (err error) {
-	if p.IsSetIncludeSizes() {
-		if err := oprot.WriteFieldBegin("includeSizes", thrift.BOOL, 7); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 7:includeSizes: ", p), err)
+func (p *AggregateTagValuesRequest) writeField7(oprot thrift.TProtocol) (err error) {
+	if p.IsSetTagValuePrefix() {
+		if err := oprot.WriteFieldBegin("tagValuePrefix", thrift.STRING, 7); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 7:tagValuePrefix: ", p), err)
 		}
-		if err := oprot.WriteBool(bool(*p.IncludeSizes)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.includeSizes (7) field write error: ", p), err)
+		if err := oprot.WriteBinary(p.TagValuePrefix); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.tagValuePrefix (7) field write error: ", p), err)
 		}
 		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 7:includeSizes: ", p), err)
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 7:tagValuePrefix: ", p), err)
 		}
 	}
 	return err
 }
 
-func (p *FetchBlocksMetadataRawRequest) writeField8(oprot thrift.TProtocol) (err error) {
-	if p.IsSetIncludeChecksums() {
-		if err := oprot.WriteFieldBegin("includeChecksums", thrift.BOOL, 8); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 8:includeChecksums: ", p), err)
+func (p *AggregateTagValuesRequest) writeField8(oprot thrift.TProtocol) (err error) {
+	if p.IsSetCursor() {
+		if err := oprot.WriteFieldBegin("cursor", thrift.STRING, 8); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 8:cursor: ", p), err)
 		}
-		if err := oprot.WriteBool(bool(*p.IncludeChecksums)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.includeChecksums (8) field write error: ", p), err)
+		if err := oprot.WriteBinary(p.Cursor); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.cursor (8) field write error: ", p), err)
 		}
 		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 8:includeChecksums: ", p), err)
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 8:cursor: ", p), err)
 		}
 	}
 	return err
 }
 
-func (p *FetchBlocksMetadataRawRequest) writeField9(oprot thrift.TProtocol) (err error) {
-	if p.IsSetIncludeLastRead() {
-		if err := oprot.WriteFieldBegin("includeLastRead", thrift.BOOL, 9); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 9:includeLastRead: ", p), err)
-		}
-		if err := oprot.WriteBool(bool(*p.IncludeLastRead)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.includeLastRead (9) field write error: ", p), err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 9:includeLastRead: ", p), err)
-		}
-	}
-	return err
-}
-
-func (p *FetchBlocksMetadataRawRequest) String() string {
+func (p *AggregateTagValuesRequest) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("FetchBlocksMetadataRawRequest(%+v)", *p)
+	return fmt.Sprintf("AggregateTagValuesRequest(%+v)", *p)
 }
 
 // Attributes:
-//  - Elements
-//  - NextPageToken
-type FetchBlocksMetadataRawResult_ struct {
-	Elements      []*BlocksMetadata `thrift:"elements,1,required" db:"elements" json:"elements"`
-	NextPageToken *int64            `thrift:"nextPageToken,2" db:"nextPageToken" json:"nextPageToken,omitempty"`
+//  - Values
+//  - Exhaustive
+//  - Cursor
+type AggregateTagValuesResult_ struct {
+	Values     []*AggregateQueryResultTerm `thrift:"values,1,required" db:"values" json:"values"`
+	Exhaustive bool                        `thrift:"exhaustive,2,required" db:"exhaustive" json:"exhaustive"`
+	Cursor     []byte                      `thrift:"cursor,3" db:"cursor" json:"cursor,omitempty"`
 }
 
-func NewFetchBlocksMetadataRawResult_() *FetchBlocksMetadataRawResult_ {
-	return &FetchBlocksMetadataRawResult_{}
+func NewAggregateTagValuesResult_() *AggregateTagValuesResult_ {
+	return &AggregateTagValuesResult_{}
 }
 
-func (p *FetchBlocksMetadataRawResult_) GetElements() []*BlocksMetadata {
-	return p.Elements
+func (p *AggregateTagValuesResult_) GetValues() []*AggregateQueryResultTerm {
+	return p.Values
 }
 
-var FetchBlocksMetadataRawResult__NextPageToken_DEFAULT int64
+func (p *AggregateTagValuesResult_) GetExhaustive() bool {
+	return p.Exhaustive
+}
 
-func (p *FetchBlocksMetadataRawResult_) GetNextPageToken() int64 {
-	if !p.IsSetNextPageToken() {
-		return FetchBlocksMetadataRawResult__NextPageToken_DEFAULT
-	}
-	return *p.NextPageToken
+var AggregateTagValuesResult__Cursor_DEFAULT []byte
+
+func (p *AggregateTagValuesResult_) GetCursor() []byte {
+	return p.Cursor
 }
-func (p *FetchBlocksMetadataRawResult_) IsSetNextPageToken() bool {
-	return p.NextPageToken != nil
+func (p *AggregateTagValuesResult_) IsSetCursor() bool {
+	return p.Cursor != nil
 }
 
-func (p *FetchBlocksMetadataRawResult_) Read(iprot thrift.TProtocol) error {
+func (p *AggregateTagValuesResult_) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetElements bool = false
+	var issetValues bool = false
+	var issetExhaustive bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -4789,11 +5347,16 @@ func (p *FetchBlocksMetadataRawResult_) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetElements = true
+			issetValues = true
 		case 2:
 			if err := p.ReadField2(iprot); err != nil {
 				return err
 			}
+			issetExhaustive = true
+		case 3:
+			if err := p.ReadField3(iprot); err != nil {
+				return err
+			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -4806,25 +5369,28 @@ func (p *FetchBlocksMetadataRawResult_) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetElements {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Elements is not set"))
+	if !issetValues {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Values is not set"))
+	}
+	if !issetExhaustive {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Exhaustive is not set"))
 	}
 	return nil
 }
 
-func (p *FetchBlocksMetadataRawResult_) ReadField1(iprot thrift.TProtocol) error {
+func (p *AggregateTagValuesResult_) ReadField1(iprot thrift.TProtocol) error {
 	_, size, err := iprot.ReadListBegin()
 	if err != nil {
 		return thrift.PrependError("error reading list begin: ", err)
 	}
-	tSlice := make([]*BlocksMetadata, 0, size)
-	p.Elements = tSlice
+	tSlice := make([]*AggregateQueryResultTerm, 0, size)
+	p.Values = tSlice
 	for i := 0; i < size; i++ {
-		_elem13 := &BlocksMetadata{}
-		if err := _elem13.Read(iprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem13), err)
+		_elem185 := &AggregateQueryResultTerm{}
+		if err := _elem185.Read(iprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem185), err)
 		}
-		p.Elements = append(p.Elements, _elem13)
+		p.Values = append(p.Values, _elem185)
 	}
 	if err := iprot.ReadListEnd(); err != nil {
 		return thrift.PrependError("error reading list end: ", err)
@@ -4832,17 +5398,26 @@ func (p *FetchBlocksMetadataRawResult_) ReadField1(iprot thrift.TProtocol) error
 	return nil
 }
 
-func (p *FetchBlocksMetadataRawResult_) ReadField2(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI64(); err != nil {
+func (p *AggregateTagValuesResult_) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
 		return thrift.PrependError("error reading field 2: ", err)
 	} else {
-		p.NextPageToken = &v
+		p.Exhaustive = v
 	}
 	return nil
 }
 
-func (p *FetchBlocksMetadataRawResult_) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("FetchBlocksMetadataRawResult"); err != nil {
+func (p *AggregateTagValuesResult_) ReadField3(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
+		return thrift.PrependError("error reading field 3: ", err)
+	} else {
+		p.Cursor = v
+	}
+	return nil
+}
+
+func (p *AggregateTagValuesResult_) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("AggregateTagValuesResult"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -4852,6 +5427,9 @@ func (p *FetchBlocksMetadataRawResult_) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField2(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField3(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -4862,14 +5440,14 @@ func (p *FetchBlocksMetadataRawResult_) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *FetchBlocksMetadataRawResult_) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("elements", thrift.LIST, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:elements: ", p), err)
+func (p *AggregateTagValuesResult_) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("values", thrift.LIST, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:values: ", p), err)
 	}
-	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Elements)); err != nil {
+	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Values)); err != nil {
 		return thrift.PrependError("error writing list begin: ", err)
 	}
-	for _, v := range p.Elements {
+	for _, v := range p.Values {
 		if err := v.Write(oprot); err != nil {
 			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
 		}
@@ -4878,59 +5456,79 @@ func (p *FetchBlocksMetadataRawResult_) writeField1(oprot thrift.TProtocol) (err
 		return thrift.PrependError("error writing list end: ", err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:elements: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:values: ", p), err)
 	}
 	return err
 }
 
-func (p *FetchBlocksMetadataRawResult_) writeField2(oprot thrift.TProtocol) (err error) {
-	if p.IsSetNextPageToken() {
-		if err := oprot.WriteFieldBegin("nextPageToken", thrift.I64, 2); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:nextPageToken: ", p), err)
+func (p *AggregateTagValuesResult_) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("exhaustive", thrift.BOOL, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:exhaustive: ", p), err)
+	}
+	if err := oprot.WriteBool(bool(p.Exhaustive)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.exhaustive (2) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:exhaustive: ", p), err)
+	}
+	return err
+}
+
+func (p *AggregateTagValuesResult_) writeField3(oprot thrift.TProtocol) (err error) {
+	if p.IsSetCursor() {
+		if err := oprot.WriteFieldBegin("cursor", thrift.STRING, 3); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:cursor: ", p), err)
 		}
-		if err := oprot.WriteI64(int64(*p.NextPageToken)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.nextPageToken (2) field write error: ", p), err)
+		if err := oprot.WriteBinary(p.Cursor); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.cursor (3) field write error: ", p), err)
 		}
 		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 2:nextPageToken: ", p), err)
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 3:cursor: ", p), err)
 		}
 	}
 	return err
 }
 
-func (p *FetchBlocksMetadataRawResult_) String() string {
+func (p *AggregateTagValuesResult_) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("FetchBlocksMetadataRawResult_(%+v)", *p)
+	return fmt.Sprintf("AggregateTagValuesResult_(%+v)", *p)
 }
 
 // Attributes:
-//  - ID
-//  - Blocks
-type BlocksMetadata struct {
-	ID     []byte           `thrift:"id,1,required" db:"id" json:"id"`
-	Blocks []*BlockMetadata `thrift:"blocks,2,required" db:"blocks" json:"blocks"`
+//  - NameSpace
+//  - Shard
+//  - Elements
+type FetchBlocksRawRequest struct {
+	NameSpace []byte                          `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
+	Shard     int32                           `thrift:"shard,2,required" db:"shard" json:"shard"`
+	Elements  []*FetchBlocksRawRequestElement `thrift:"elements,3,required" db:"elements" json:"elements"`
 }
 
-func NewBlocksMetadata() *BlocksMetadata {
-	return &BlocksMetadata{}
+func NewFetchBlocksRawRequest() *FetchBlocksRawRequest {
+	return &FetchBlocksRawRequest{}
 }
 
-func (p *BlocksMetadata) GetID() []byte {
-	return p.ID
+func (p *FetchBlocksRawRequest) GetNameSpace() []byte {
+	return p.NameSpace
 }
 
-func (p *BlocksMetadata) GetBlocks() []*BlockMetadata {
-	return p.Blocks
+func (p *FetchBlocksRawRequest) GetShard() int32 {
+	return p.Shard
 }
-func (p *BlocksMetadata) Read(iprot thrift.TProtocol) error {
+
+func (p *FetchBlocksRawRequest) GetElements() []*FetchBlocksRawRequestElement {
+	return p.Elements
+}
+func (p *FetchBlocksRawRequest) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetID bool = false
-	var issetBlocks bool = false
+	var issetNameSpace bool = false
+	var issetShard bool = false
+	var issetElements bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -4945,12 +5543,17 @@ func (p *BlocksMetadata) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetID = true
+			issetNameSpace = true
 		case 2:
 			if err := p.ReadField2(iprot); err != nil {
 				return err
 			}
-			issetBlocks = true
+			issetShard = true
+		case 3:
+			if err := p.ReadField3(iprot); err != nil {
+				return err
+			}
+			issetElements = true
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -4963,39 +5566,49 @@ func (p *BlocksMetadata) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetID {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field ID is not set"))
+	if !issetNameSpace {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field NameSpace is not set"))
 	}
-	if !issetBlocks {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Blocks is not set"))
+	if !issetShard {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Shard is not set"))
+	}
+	if !issetElements {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Elements is not set"))
 	}
 	return nil
 }
 
-func (p *BlocksMetadata) ReadField1(iprot thrift.TProtocol) error {
+func (p *FetchBlocksRawRequest) ReadField1(iprot thrift.TProtocol) error {
 	if v, err := iprot.ReadBinary(); err != nil {
 		return thrift.PrependError("error reading field 1: ", err)
 	} else {
-		p.ID = v
+		p.NameSpace = v
 	}
 	return nil
 }
 
-func (p *BlocksMetadata) ReadField2(iprot thrift.TProtocol) error {
+func (p *FetchBlocksRawRequest) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI32(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		p.Shard = v
+	}
+	return nil
+}
+
+func (p *FetchBlocksRawRequest) ReadField3(iprot thrift.TProtocol) error {
 	_, size, err := iprot.ReadListBegin()
 	if err != nil {
 		return thrift.PrependError("error reading list begin: ", err)
 	}
-	tSlice := make([]*BlockMetadata, 0, size)
-	p.Blocks = tSlice
+	tSlice := make([]*FetchBlocksRawRequestElement, 0, size)
+	p.Elements = tSlice
 	for i := 0; i < size; i++ {
-		_elem14 := &BlockMetadata{
-			LastReadTimeType: 0,
-		}
-		if err := _elem14.Read(iprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem14), err)
+		_elem9 := &FetchBlocksRawRequestElement{}
+		if err := _elem9.Read(iprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem9), err)
 		}
-		p.Blocks = append(p.Blocks, _elem14)
+		p.Elements = append(p.Elements, _elem9)
 	}
 	if err := iprot.ReadListEnd(); err != nil {
 		return thrift.PrependError("error reading list end: ", err)
@@ -5003,8 +5616,8 @@ func (p *BlocksMetadata) ReadField2(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *BlocksMetadata) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("BlocksMetadata"); err != nil {
+func (p *FetchBlocksRawRequest) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("FetchBlocksRawRequest"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -5014,6 +5627,9 @@ func (p *BlocksMetadata) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField2(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField3(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -5024,27 +5640,40 @@ func (p *BlocksMetadata) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *BlocksMetadata) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("id", thrift.STRING, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:id: ", p), err)
+func (p *FetchBlocksRawRequest) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("nameSpace", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:nameSpace: ", p), err)
 	}
-	if err := oprot.WriteBinary(p.ID); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.id (1) field write error: ", p), err)
+	if err := oprot.WriteBinary(p.NameSpace); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.nameSpace (1) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:id: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:nameSpace: ", p), err)
 	}
 	return err
 }
 
-func (p *BlocksMetadata) writeField2(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("blocks", thrift.LIST, 2); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:blocks: ", p), err)
+func (p *FetchBlocksRawRequest) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("shard", thrift.I32, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:shard: ", p), err)
 	}
-	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Blocks)); err != nil {
+	if err := oprot.WriteI32(int32(p.Shard)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.shard (2) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:shard: ", p), err)
+	}
+	return err
+}
+
+func (p *FetchBlocksRawRequest) writeField3(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("elements", thrift.LIST, 3); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:elements: ", p), err)
+	}
+	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Elements)); err != nil {
 		return thrift.PrependError("error writing list begin: ", err)
 	}
-	for _, v := range p.Blocks {
+	for _, v := range p.Elements {
 		if err := v.Write(oprot); err != nil {
 			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
 		}
@@ -5053,111 +5682,44 @@ func (p *BlocksMetadata) writeField2(oprot thrift.TProtocol) (err error) {
 		return thrift.PrependError("error writing list end: ", err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:blocks: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:elements: ", p), err)
 	}
 	return err
 }
 
-func (p *BlocksMetadata) String() string {
+func (p *FetchBlocksRawRequest) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("BlocksMetadata(%+v)", *p)
+	return fmt.Sprintf("FetchBlocksRawRequest(%+v)", *p)
 }
 
 // Attributes:
-//  - Err
-//  - Start
-//  - Size
-//  - Checksum
-//  - LastRead
-//  - LastReadTimeType
-type BlockMetadata struct {
-	Err              *Error   `thrift:"err,1" db:"err" json:"err,omitempty"`
-	Start            int64    `thrift:"start,2,required" db:"start" json:"start"`
-	Size             *int64   `thrift:"size,3" db:"size" json:"size,omitempty"`
-	Checksum         *int64   `thrift:"checksum,4" db:"checksum" json:"checksum,omitempty"`
-	LastRead         *int64   `thrift:"lastRead,5" db:"lastRead" json:"lastRead,omitempty"`
-	LastReadTimeType TimeType `thrift:"lastReadTimeType,6" db:"lastReadTimeType" json:"lastReadTimeType,omitempty"`
+//  - ID
+//  - Starts
+type FetchBlocksRawRequestElement struct {
+	ID     []byte  `thrift:"id,1,required" db:"id" json:"id"`
+	Starts []int64 `thrift:"starts,2,required" db:"starts" json:"starts"`
 }
 
-func NewBlockMetadata() *BlockMetadata {
-	return &BlockMetadata{
-		LastReadTimeType: 0,
-	}
+func NewFetchBlocksRawRequestElement() *FetchBlocksRawRequestElement {
+	return &FetchBlocksRawRequestElement{}
 }
 
-var BlockMetadata_Err_DEFAULT *Error
-
-func (p *BlockMetadata) GetErr() *Error {
-	if !p.IsSetErr() {
-		return BlockMetadata_Err_DEFAULT
-	}
-	return p.Err
-}
-
-func (p *BlockMetadata) GetStart() int64 {
-	return p.Start
-}
-
-var BlockMetadata_Size_DEFAULT int64
-
-func (p *BlockMetadata) GetSize() int64 {
-	if !p.IsSetSize() {
-		return BlockMetadata_Size_DEFAULT
-	}
-	return *p.Size
-}
-
-var BlockMetadata_Checksum_DEFAULT int64
-
-func (p *BlockMetadata) GetChecksum() int64 {
-	if !p.IsSetChecksum() {
-		return BlockMetadata_Checksum_DEFAULT
-	}
-	return *p.Checksum
-}
-
-var BlockMetadata_LastRead_DEFAULT int64
-
-func (p *BlockMetadata) GetLastRead() int64 {
-	if !p.IsSetLastRead() {
-		return BlockMetadata_LastRead_DEFAULT
-	}
-	return *p.LastRead
-}
-
-var BlockMetadata_LastReadTimeType_DEFAULT TimeType = 0
-
-func (p *BlockMetadata) GetLastReadTimeType() TimeType {
-	return p.LastReadTimeType
-}
-func (p *BlockMetadata) IsSetErr() bool {
-	return p.Err != nil
-}
-
-func (p *BlockMetadata) IsSetSize() bool {
-	return p.Size != nil
-}
-
-func (p *BlockMetadata) IsSetChecksum() bool {
-	return p.Checksum != nil
-}
-
-func (p *BlockMetadata) IsSetLastRead() bool {
-	return p.LastRead != nil
+func (p *FetchBlocksRawRequestElement) GetID() []byte {
+	return p.ID
 }
 
-func (p *BlockMetadata) IsSetLastReadTimeType() bool {
-	return p.LastReadTimeType != BlockMetadata_LastReadTimeType_DEFAULT
+func (p *FetchBlocksRawRequestElement) GetStarts() []int64 {
+	return p.Starts
 }
-
-func (p *BlockMetadata) Read(iprot thrift.TProtocol) error {
+func (p *FetchBlocksRawRequestElement) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetStart bool = false
+	var issetID bool = false
+	var issetStarts bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -5172,27 +5734,12 @@ func (p *BlockMetadata) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
+			issetID = true
 		case 2:
 			if err := p.ReadField2(iprot); err != nil {
 				return err
 			}
-			issetStart = true
-		case 3:
-			if err := p.ReadField3(iprot); err != nil {
-				return err
-			}
-		case 4:
-			if err := p.ReadField4(iprot); err != nil {
-				return err
-			}
-		case 5:
-			if err := p.ReadField5(iprot); err != nil {
-				return err
-			}
-		case 6:
-			if err := p.ReadField6(iprot); err != nil {
-				return err
-			}
+			issetStarts = true
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -5205,70 +5752,48 @@ func (p *BlockMetadata) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetStart {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Start is not set"))
-	}
-	return nil
-}
-
-func (p *BlockMetadata) ReadField1(iprot thrift.TProtocol) error {
-	p.Err = &Error{
-		Type: 0,
-	}
-	if err := p.Err.Read(iprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Err), err)
+	if !issetID {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field ID is not set"))
 	}
-	return nil
-}
-
-func (p *BlockMetadata) ReadField2(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI64(); err != nil {
-		return thrift.PrependError("error reading field 2: ", err)
-	} else {
-		p.Start = v
+	if !issetStarts {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Starts is not set"))
 	}
 	return nil
 }
 
-func (p *BlockMetadata) ReadField3(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI64(); err != nil {
-		return thrift.PrependError("error reading field 3: ", err)
+func (p *FetchBlocksRawRequestElement) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
 	} else {
-		p.Size = &v
+		p.ID = v
 	}
 	return nil
 }
 
-func (p *BlockMetadata) ReadField4(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI64(); err != nil {
-		return thrift.PrependError("error reading field 4: ", err)
-	} else {
-		p.Checksum = &v
+func (p *FetchBlocksRawRequestElement) ReadField2(iprot thrift.TProtocol) error {
+	_, size, err := iprot.ReadListBegin()
+	if err != nil {
+		return thrift.PrependError("error reading list begin: ", err)
 	}
-	return nil
-}
-
-func (p *BlockMetadata) ReadField5(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI64(); err != nil {
-		return thrift.PrependError("error reading field 5: ", err)
-	} else {
-		p.LastRead = &v
+	tSlice := make([]int64, 0, size)
+	p.Starts = tSlice
+	for i := 0; i < size; i++ {
+		var _elem10 int64
+		if v, err := iprot.ReadI64(); err != nil {
+			return thrift.PrependError("error reading field 0: ", err)
+		} else {
+			_elem10 = v
+		}
+		p.Starts = append(p.Starts, _elem10)
 	}
-	return nil
-}
-
-func (p *BlockMetadata) ReadField6(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI32(); err != nil {
-		return thrift.PrependError("error reading field 6: ", err)
-	} else {
-		temp := TimeType(v)
-		p.LastReadTimeType = temp
+	if err := iprot.ReadListEnd(); err != nil {
+		return thrift.PrependError("error reading list end: ", err)
 	}
 	return nil
 }
 
-func (p *BlockMetadata) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("BlockMetadata"); err != nil {
+func (p *FetchBlocksRawRequestElement) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("FetchBlocksRawRequestElement"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -5278,18 +5803,6 @@ func (p *BlockMetadata) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField2(oprot); err != nil {
 			return err
 		}
-		if err := p.writeField3(oprot); err != nil {
-			return err
-		}
-		if err := p.writeField4(oprot); err != nil {
-			return err
-		}
-		if err := p.writeField5(oprot); err != nil {
-			return err
-		}
-		if err := p.writeField6(oprot); err != nil {
-			return err
-		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -5300,205 +5813,191 @@ func (p *BlockMetadata) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *BlockMetadata) writeField1(oprot thrift.TProtocol) (err error) {
-	if p.IsSetErr() {
-		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
-		}
-		if err := p.Err.Write(oprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Err), err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 1:err: ", p), err)
-		}
+func (p *FetchBlocksRawRequestElement) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("id", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:id: ", p), err)
+	}
+	if err := oprot.WriteBinary(p.ID); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.id (1) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:id: ", p), err)
 	}
 	return err
 }
 
-func (p *BlockMetadata) writeField2(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("start", thrift.I64, 2); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:start: ", p), err)
+func (p *FetchBlocksRawRequestElement) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("starts", thrift.LIST, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:starts: ", p), err)
 	}
-	if err := oprot.WriteI64(int64(p.Start)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.start (2) field write error: ", p), err)
+	if err := oprot.WriteListBegin(thrift.I64, len(p.Starts)); err != nil {
+		return thrift.PrependError("error writing list begin: ", err)
+	}
+	for _, v := range p.Starts {
+		if err := oprot.WriteI64(int64(v)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T. (0) field write error: ", p), err)
+		}
+	}
+	if err := oprot.WriteListEnd(); err != nil {
+		return thrift.PrependError("error writing list end: ", err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:start: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:starts: ", p), err)
 	}
 	return err
 }
 
-func (p *BlockMetadata) writeField3(oprot thrift.TProtocol) (err error) {
-	if p.IsSetSize() {
-		if err := oprot.WriteFieldBegin("size", thrift.I64, 3); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:size: ", p), err)
-		}
-		if err := oprot.WriteI64(int64(*p.Size)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.size (3) field write error: ", p), err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 3:size: ", p), err)
-		}
+func (p *FetchBlocksRawRequestElement) String() string {
+	if p == nil {
+		return "<nil>"
 	}
-	return err
+	return fmt.Sprintf("FetchBlocksRawRequestElement(%+v)", *p)
 }
 
-func (p *BlockMetadata) writeField4(oprot thrift.TProtocol) (err error) {
-	if p.IsSetChecksum() {
-		if err := oprot.WriteFieldBegin("checksum", thrift.I64, 4); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:checksum: ", p), err)
-		}
-		if err := oprot.WriteI64(int64(*p.Checksum)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.checksum (4) field write error: ", p), err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 4:checksum: ", p), err)
-		}
-	}
-	return err
+// Attributes:
+//  - Elements
+type FetchBlocksRawResult_ struct {
+	Elements []*Blocks `thrift:"elements,1,required" db:"elements" json:"elements"`
 }
 
-func (p *BlockMetadata) writeField5(oprot thrift.TProtocol) (err error) {
-	if p.IsSetLastRead() {
-		if err := oprot.WriteFieldBegin("lastRead", thrift.I64, 5); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 5:lastRead: ", p), err)
-		}
-		if err := oprot.WriteI64(int64(*p.LastRead)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.lastRead (5) field write error: ", p), err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 5:lastRead: ", p), err)
-		}
-	}
-	return err
+func NewFetchBlocksRawResult_() *FetchBlocksRawResult_ {
+	return &FetchBlocksRawResult_{}
 }
 
-func (p *BlockMetadata) writeField6(oprot thrift.TProtocol) (err error) {
-	if p.IsSetLastReadTimeType() {
-		if err := oprot.WriteFieldBegin("lastReadTimeType", thrift.I32, 6); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 6:lastReadTimeType: ", p), err)
+func (p *FetchBlocksRawResult_) GetElements() []*Blocks {
+	return p.Elements
+}
+func (p *FetchBlocksRawResult_) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	var issetElements bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
 		}
-		if err := oprot.WriteI32(int32(p.LastReadTimeType)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.lastReadTimeType (6) field write error: ", p), err)
+		if fieldTypeId == thrift.STOP {
+			break
 		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 6:lastReadTimeType: ", p), err)
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetElements = true
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
 		}
 	}
-	return err
-}
-
-func (p *BlockMetadata) String() string {
-	if p == nil {
-		return "<nil>"
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	return fmt.Sprintf("BlockMetadata(%+v)", *p)
-}
-
-// Attributes:
-//  - NameSpace
-//  - Shard
-//  - RangeStart
-//  - RangeEnd
-//  - Limit
-//  - PageToken
-//  - IncludeSizes
-//  - IncludeChecksums
-//  - IncludeLastRead
-type FetchBlocksMetadataRawV2Request struct {
-	NameSpace        []byte `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
-	Shard            int32  `thrift:"shard,2,required" db:"shard" json:"shard"`
-	RangeStart       int64  `thrift:"rangeStart,3,required" db:"rangeStart" json:"rangeStart"`
-	RangeEnd         int64  `thrift:"rangeEnd,4,required" db:"rangeEnd" json:"rangeEnd"`
-	Limit            int64  `thrift:"limit,5,required" db:"limit" json:"limit"`
-	PageToken        []byte `thrift:"pageToken,6" db:"pageToken" json:"pageToken,omitempty"`
-	IncludeSizes     *bool  `thrift:"includeSizes,7" db:"includeSizes" json:"includeSizes,omitempty"`
-	IncludeChecksums *bool  `thrift:"includeChecksums,8" db:"includeChecksums" json:"includeChecksums,omitempty"`
-	IncludeLastRead  *bool  `thrift:"includeLastRead,9" db:"includeLastRead" json:"includeLastRead,omitempty"`
-}
-
-func NewFetchBlocksMetadataRawV2Request() *FetchBlocksMetadataRawV2Request {
-	return &FetchBlocksMetadataRawV2Request{}
-}
-
-func (p *FetchBlocksMetadataRawV2Request) GetNameSpace() []byte {
-	return p.NameSpace
-}
-
-func (p *FetchBlocksMetadataRawV2Request) GetShard() int32 {
-	return p.Shard
-}
-
-func (p *FetchBlocksMetadataRawV2Request) GetRangeStart() int64 {
-	return p.RangeStart
-}
-
-func (p *FetchBlocksMetadataRawV2Request) GetRangeEnd() int64 {
-	return p.RangeEnd
-}
-
-func (p *FetchBlocksMetadataRawV2Request) GetLimit() int64 {
-	return p.Limit
+	if !issetElements {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Elements is not set"))
+	}
+	return nil
 }
 
-var FetchBlocksMetadataRawV2Request_PageToken_DEFAULT []byte
-
-func (p *FetchBlocksMetadataRawV2Request) GetPageToken() []byte {
-	return p.PageToken
+func (p *FetchBlocksRawResult_) ReadField1(iprot thrift.TProtocol) error {
+	_, size, err := iprot.ReadListBegin()
+	if err != nil {
+		return thrift.PrependError("error reading list begin: ", err)
+	}
+	tSlice := make([]*Blocks, 0, size)
+	p.Elements = tSlice
+	for i := 0; i < size; i++ {
+		_elem11 := &Blocks{}
+		if err := _elem11.Read(iprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem11), err)
+		}
+		p.Elements = append(p.Elements, _elem11)
+	}
+	if err := iprot.ReadListEnd(); err != nil {
+		return thrift.PrependError("error reading list end: ", err)
+	}
+	return nil
 }
 
-var FetchBlocksMetadataRawV2Request_IncludeSizes_DEFAULT bool
-
-func (p *FetchBlocksMetadataRawV2Request) GetIncludeSizes() bool {
-	if !p.IsSetIncludeSizes() {
-		return FetchBlocksMetadataRawV2Request_IncludeSizes_DEFAULT
+func (p *FetchBlocksRawResult_) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("FetchBlocksRawResult"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
-	return *p.IncludeSizes
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
 }
 
-var FetchBlocksMetadataRawV2Request_IncludeChecksums_DEFAULT bool
-
-func (p *FetchBlocksMetadataRawV2Request) GetIncludeChecksums() bool {
-	if !p.IsSetIncludeChecksums() {
-		return FetchBlocksMetadataRawV2Request_IncludeChecksums_DEFAULT
+func (p *FetchBlocksRawResult_) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("elements", thrift.LIST, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:elements: ", p), err)
 	}
-	return *p.IncludeChecksums
+	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Elements)); err != nil {
+		return thrift.PrependError("error writing list begin: ", err)
+	}
+	for _, v := range p.Elements {
+		if err := v.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
+		}
+	}
+	if err := oprot.WriteListEnd(); err != nil {
+		return thrift.PrependError("error writing list end: ", err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:elements: ", p), err)
+	}
+	return err
 }
 
-var FetchBlocksMetadataRawV2Request_IncludeLastRead_DEFAULT bool
-
-func (p *FetchBlocksMetadataRawV2Request) GetIncludeLastRead() bool {
-	if !p.IsSetIncludeLastRead() {
-		return FetchBlocksMetadataRawV2Request_IncludeLastRead_DEFAULT
+func (p *FetchBlocksRawResult_) String() string {
+	if p == nil {
+		return "<nil>"
 	}
-	return *p.IncludeLastRead
-}
-func (p *FetchBlocksMetadataRawV2Request) IsSetPageToken() bool {
-	return p.PageToken != nil
+	return fmt.Sprintf("FetchBlocksRawResult_(%+v)", *p)
 }
 
-func (p *FetchBlocksMetadataRawV2Request) IsSetIncludeSizes() bool {
-	return p.IncludeSizes != nil
+// Attributes:
+//  - ID
+//  - Blocks
+type Blocks struct {
+	ID     []byte   `thrift:"id,1,required" db:"id" json:"id"`
+	Blocks []*Block `thrift:"blocks,2,required" db:"blocks" json:"blocks"`
 }
 
-func (p *FetchBlocksMetadataRawV2Request) IsSetIncludeChecksums() bool {
-	return p.IncludeChecksums != nil
+func NewBlocks() *Blocks {
+	return &Blocks{}
 }
 
-func (p *FetchBlocksMetadataRawV2Request) IsSetIncludeLastRead() bool {
-	return p.IncludeLastRead != nil
+func (p *Blocks) GetID() []byte {
+	return p.ID
 }
 
-func (p *FetchBlocksMetadataRawV2Request) Read(iprot thrift.TProtocol) error {
+func (p *Blocks) GetBlocks() []*Block {
+	return p.Blocks
+}
+func (p *Blocks) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetNameSpace bool = false
-	var issetShard bool = false
-	var issetRangeStart bool = false
-	var issetRangeEnd bool = false
-	var issetLimit bool = false
+	var issetID bool = false
+	var issetBlocks bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -5513,43 +6012,12 @@ func (p *FetchBlocksMetadataRawV2Request) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetNameSpace = true
+			issetID = true
 		case 2:
 			if err := p.ReadField2(iprot); err != nil {
 				return err
 			}
-			issetShard = true
-		case 3:
-			if err := p.ReadField3(iprot); err != nil {
-				return err
-			}
-			issetRangeStart = true
-		case 4:
-			if err := p.ReadField4(iprot); err != nil {
-				return err
-			}
-			issetRangeEnd = true
-		case 5:
-			if err := p.ReadField5(iprot); err != nil {
-				return err
-			}
-			issetLimit = true
-		case 6:
-			if err := p.ReadField6(iprot); err != nil {
-				return err
-			}
-		case 7:
-			if err := p.ReadField7(iprot); err != nil {
-				return err
-			}
-		case 8:
-			if err := p.ReadField8(iprot); err != nil {
-				return err
-			}
-		case 9:
-			if err := p.ReadField9(iprot); err != nil {
-				return err
-			}
+			issetBlocks = true
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -5562,107 +6030,46 @@ func (p *FetchBlocksMetadataRawV2Request) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetNameSpace {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field NameSpace is not set"))
+	if !issetID {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field ID is not set"))
 	}
-	if !issetShard {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Shard is not set"))
-	}
-	if !issetRangeStart {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field RangeStart is not set"))
-	}
-	if !issetRangeEnd {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field RangeEnd is not set"))
-	}
-	if !issetLimit {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Limit is not set"))
+	if !issetBlocks {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Blocks is not set"))
 	}
 	return nil
 }
 
-func (p *FetchBlocksMetadataRawV2Request) ReadField1(iprot thrift.TProtocol) error {
+func (p *Blocks) ReadField1(iprot thrift.TProtocol) error {
 	if v, err := iprot.ReadBinary(); err != nil {
 		return thrift.PrependError("error reading field 1: ", err)
 	} else {
-		p.NameSpace = v
-	}
-	return nil
-}
-
-func (p *FetchBlocksMetadataRawV2Request) ReadField2(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI32(); err != nil {
-		return thrift.PrependError("error reading field 2: ", err)
-	} else {
-		p.Shard = v
-	}
-	return nil
-}
-
-func (p *FetchBlocksMetadataRawV2Request) ReadField3(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI64(); err != nil {
-		return thrift.PrependError("error reading field 3: ", err)
-	} else {
-		p.RangeStart = v
-	}
-	return nil
-}
-
-func (p *FetchBlocksMetadataRawV2Request) ReadField4(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI64(); err != nil {
-		return thrift.PrependError("error reading field 4: ", err)
-	} else {
-		p.RangeEnd = v
-	}
-	return nil
-}
-
-func (p *FetchBlocksMetadataRawV2Request) ReadField5(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI64(); err != nil {
-		return thrift.PrependError("error reading field 5: ", err)
-	} else {
-		p.Limit = v
-	}
-	return nil
-}
-
-func (p *FetchBlocksMetadataRawV2Request) ReadField6(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBinary(); err != nil {
-		return thrift.PrependError("error reading field 6: ", err)
-	} else {
-		p.PageToken = v
+		p.ID = v
 	}
 	return nil
 }
 
-func (p *FetchBlocksMetadataRawV2Request) ReadField7(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBool(); err != nil {
-		return thrift.PrependError("error reading field 7: ", err)
-	} else {
-		p.IncludeSizes = &v
+func (p *Blocks) ReadField2(iprot thrift.TProtocol) error {
+	_, size, err := iprot.ReadListBegin()
+	if err != nil {
+		return thrift.PrependError("error reading list begin: ", err)
 	}
-	return nil
-}
-
-func (p *FetchBlocksMetadataRawV2Request) ReadField8(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBool(); err != nil {
-		return thrift.PrependError("error reading field 8: ", err)
-	} else {
-		p.IncludeChecksums = &v
+	tSlice := make([]*Block, 0, size)
+	p.Blocks = tSlice
+	for i := 0; i < size; i++ {
+		_elem12 := &Block{}
+		if err := _elem12.Read(iprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem12), err)
+		}
+		p.Blocks = append(p.Blocks, _elem12)
 	}
-	return nil
-}
-
-func (p *FetchBlocksMetadataRawV2Request) ReadField9(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBool(); err != nil {
-		return thrift.PrependError("error reading field 9: ", err)
-	} else {
-		p.IncludeLastRead = &v
+	if err := iprot.ReadListEnd(); err != nil {
+		return thrift.PrependError("error reading list end: ", err)
 	}
 	return nil
 }
 
-func (p *FetchBlocksMetadataRawV2Request) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("FetchBlocksMetadataRawV2Request"); err != nil {
+func (p *Blocks) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("Blocks"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -5672,27 +6079,6 @@ func (p *FetchBlocksMetadataRawV2Request) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField2(oprot); err != nil {
 			return err
 		}
-		if err := p.writeField3(oprot); err != nil {
-			return err
-		}
-		if err := p.writeField4(oprot); err != nil {
-			return err
-		}
-		if err := p.writeField5(oprot); err != nil {
-			return err
-		}
-		if err := p.writeField6(oprot); err != nil {
-			return err
-		}
-		if err := p.writeField7(oprot); err != nil {
-			return err
-		}
-		if err := p.writeField8(oprot); err != nil {
-			return err
-		}
-		if err := p.writeField9(oprot); err != nil {
-			return err
-		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -5703,169 +6089,111 @@ func (p *FetchBlocksMetadataRawV2Request) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *FetchBlocksMetadataRawV2Request) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("nameSpace", thrift.STRING, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:nameSpace: ", p), err)
+func (p *Blocks) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("id", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:id: ", p), err)
 	}
-	if err := oprot.WriteBinary(p.NameSpace); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.nameSpace (1) field write error: ", p), err)
+	if err := oprot.WriteBinary(p.ID); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.id (1) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:nameSpace: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:id: ", p), err)
 	}
 	return err
 }
 
-func (p *FetchBlocksMetadataRawV2Request) writeField2(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("shard", thrift.I32, 2); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:shard: ", p), err)
-	}
-	if err := oprot.WriteI32(int32(p.Shard)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.shard (2) field write error: ", p), err)
+func (p *Blocks) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("blocks", thrift.LIST, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:blocks: ", p), err)
 	}
-	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:shard: ", p), err)
+	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Blocks)); err != nil {
+		return thrift.PrependError("error writing list begin: ", err)
 	}
-	return err
-}
-
-func (p *FetchBlocksMetadataRawV2Request) writeField3(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("rangeStart", thrift.I64, 3); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:rangeStart: ", p), err)
+	for _, v := range p.Blocks {
+		if err := v.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
+		}
 	}
-	if err := oprot.WriteI64(int64(p.RangeStart)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.rangeStart (3) field write error: ", p), err)
+	if err := oprot.WriteListEnd(); err != nil {
+		return thrift.PrependError("error writing list end: ", err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:rangeStart: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:blocks: ", p), err)
 	}
 	return err
 }
 
-func (p *FetchBlocksMetadataRawV2Request) writeField4(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("rangeEnd", thrift.I64, 4); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:rangeEnd: ", p), err)
-	}
-	if err := oprot.WriteI64(int64(p.RangeEnd)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.rangeEnd (4) field write error: ", p), err)
-	}
-	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 4:rangeEnd: ", p), err)
+func (p *Blocks) String() string {
+	if p == nil {
+		return "<nil>"
 	}
-	return err
+	return fmt.Sprintf("Blocks(%+v)", *p)
 }
 
-func (p *FetchBlocksMetadataRawV2Request) writeField5(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("limit", thrift.I64, 5); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 5:limit: ", p), err)
-	}
-	if err := oprot.WriteI64(int64(p.Limit)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.limit (5) field write error: ", p), err)
-	}
-	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 5:limit: ", p), err)
-	}
-	return err
+// Attributes:
+//  - Start
+//  - Segments
+//  - Err
+//  - Checksum
+type Block struct {
+	Start    int64     `thrift:"start,1,required" db:"start" json:"start"`
+	Segments *Segments `thrift:"segments,2" db:"segments" json:"segments,omitempty"`
+	Err      *Error    `thrift:"err,3" db:"err" json:"err,omitempty"`
+	Checksum *int64    `thrift:"checksum,4" db:"checksum" json:"checksum,omitempty"`
 }
 
-func (p *FetchBlocksMetadataRawV2Request) writeField6(oprot thrift.TProtocol) (err error) {
-	if p.IsSetPageToken() {
-		if err := oprot.WriteFieldBegin("pageToken", thrift.STRING, 6); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 6:pageToken: ", p), err)
-		}
-		if err := oprot.WriteBinary(p.PageToken); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.pageToken (6) field write error: ", p), err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 6:pageToken: ", p), err)
-		}
-	}
-	return err
+func NewBlock() *Block {
+	return &Block{}
 }
 
-func (p *FetchBlocksMetadataRawV2Request) writeField7(oprot thrift.TProtocol) (err error) {
-	if p.IsSetIncludeSizes() {
-		if err := oprot.WriteFieldBegin("includeSizes", thrift.BOOL, 7); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 7:includeSizes: ", p), err)
-		}
-		if err := oprot.WriteBool(bool(*p.IncludeSizes)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.includeSizes (7) field write error: ", p), err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 7:includeSizes: ", p), err)
-		}
-	}
-	return err
+func (p *Block) GetStart() int64 {
+	return p.Start
 }
 
-func (p *FetchBlocksMetadataRawV2Request) writeField8(oprot thrift.TProtocol) (err error) {
-	if p.IsSetIncludeChecksums() {
-		if err := oprot.WriteFieldBegin("includeChecksums", thrift.BOOL, 8); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 8:includeChecksums: ", p), err)
-		}
-		if err := oprot.WriteBool(bool(*p.IncludeChecksums)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.includeChecksums (8) field write error: ", p), err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 8:includeChecksums: ", p), err)
-		}
-	}
-	return err
-}
+var Block_Segments_DEFAULT *Segments
 
-func (p *FetchBlocksMetadataRawV2Request) writeField9(oprot thrift.TProtocol) (err error) {
-	if p.IsSetIncludeLastRead() {
-		if err := oprot.WriteFieldBegin("includeLastRead", thrift.BOOL, 9); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 9:includeLastRead: ", p), err)
-		}
-		if err := oprot.WriteBool(bool(*p.IncludeLastRead)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.includeLastRead (9) field write error: ", p), err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 9:includeLastRead: ", p), err)
-		}
+func (p *Block) GetSegments() *Segments {
+	if !p.IsSetSegments() {
+		return Block_Segments_DEFAULT
 	}
-	return err
+	return p.Segments
 }
 
-func (p *FetchBlocksMetadataRawV2Request) String() string {
-	if p == nil {
-		return "<nil>"
+var Block_Err_DEFAULT *Error
+
+func (p *Block) GetErr() *Error {
+	if !p.IsSetErr() {
+		return Block_Err_DEFAULT
 	}
-	return fmt.Sprintf("FetchBlocksMetadataRawV2Request(%+v)", *p)
+	return p.Err
 }
 
-// Attributes:
-//  - Elements
-//  - NextPageToken
-type FetchBlocksMetadataRawV2Result_ struct {
-	Elements      []*BlockMetadataV2 `thrift:"elements,1,required" db:"elements" json:"elements"`
-	NextPageToken []byte             `thrift:"nextPageToken,2" db:"nextPageToken" json:"nextPageToken,omitempty"`
-}
+var Block_Checksum_DEFAULT int64
 
-func NewFetchBlocksMetadataRawV2Result_() *FetchBlocksMetadataRawV2Result_ {
-	return &FetchBlocksMetadataRawV2Result_{}
+func (p *Block) GetChecksum() int64 {
+	if !p.IsSetChecksum() {
+		return Block_Checksum_DEFAULT
+	}
+	return *p.Checksum
 }
-
-func (p *FetchBlocksMetadataRawV2Result_) GetElements() []*BlockMetadataV2 {
-	return p.Elements
+func (p *Block) IsSetSegments() bool {
+	return p.Segments != nil
 }
 
-var FetchBlocksMetadataRawV2Result__NextPageToken_DEFAULT []byte
-
-func (p *FetchBlocksMetadataRawV2Result_) GetNextPageToken() []byte {
-	return p.NextPageToken
+func (p *Block) IsSetErr() bool {
+	return p.Err != nil
 }
-func (p *FetchBlocksMetadataRawV2Result_) IsSetNextPageToken() bool {
-	return p.NextPageToken != nil
+
+func (p *Block) IsSetChecksum() bool {
+	return p.Checksum != nil
 }
 
-func (p *FetchBlocksMetadataRawV2Result_) Read(iprot thrift.TProtocol) error {
+func (p *Block) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetElements bool = false
+	var issetStart bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -5880,11 +6208,19 @@ func (p *FetchBlocksMetadataRawV2Result_) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetElements = true
+			issetStart = true
 		case 2:
 			if err := p.ReadField2(iprot); err != nil {
 				return err
 			}
+		case 3:
+			if err := p.ReadField3(iprot); err != nil {
+				return err
+			}
+		case 4:
+			if err := p.ReadField4(iprot); err != nil {
+				return err
+			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -5897,45 +6233,50 @@ func (p *FetchBlocksMetadataRawV2Result_) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetElements {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Elements is not set"))
+	if !issetStart {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Start is not set"))
 	}
 	return nil
 }
 
-func (p *FetchBlocksMetadataRawV2Result_) ReadField1(iprot thrift.TProtocol) error {
-	_, size, err := iprot.ReadListBegin()
-	if err != nil {
-		return thrift.PrependError("error reading list begin: ", err)
+func (p *Block) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
+	} else {
+		p.Start = v
 	}
-	tSlice := make([]*BlockMetadataV2, 0, size)
-	p.Elements = tSlice
-	for i := 0; i < size; i++ {
-		_elem15 := &BlockMetadataV2{
-			LastReadTimeType: 0,
-		}
-		if err := _elem15.Read(iprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem15), err)
-		}
-		p.Elements = append(p.Elements, _elem15)
+	return nil
+}
+
+func (p *Block) ReadField2(iprot thrift.TProtocol) error {
+	p.Segments = &Segments{}
+	if err := p.Segments.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Segments), err)
 	}
-	if err := iprot.ReadListEnd(); err != nil {
-		return thrift.PrependError("error reading list end: ", err)
+	return nil
+}
+
+func (p *Block) ReadField3(iprot thrift.TProtocol) error {
+	p.Err = &Error{
+		Type: 0,
+	}
+	if err := p.Err.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Err), err)
 	}
 	return nil
 }
 
-func (p *FetchBlocksMetadataRawV2Result_) ReadField2(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBinary(); err != nil {
-		return thrift.PrependError("error reading field 2: ", err)
+func (p *Block) ReadField4(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 4: ", err)
 	} else {
-		p.NextPageToken = v
+		p.Checksum = &v
 	}
 	return nil
 }
 
-func (p *FetchBlocksMetadataRawV2Result_) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("FetchBlocksMetadataRawV2Result"); err != nil {
+func (p *Block) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("Block"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -5945,6 +6286,12 @@ func (p *FetchBlocksMetadataRawV2Result_) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField2(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField3(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField4(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -5955,161 +6302,97 @@ func (p *FetchBlocksMetadataRawV2Result_) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *FetchBlocksMetadataRawV2Result_) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("elements", thrift.LIST, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:elements: ", p), err)
-	}
-	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Elements)); err != nil {
-		return thrift.PrependError("error writing list begin: ", err)
-	}
-	for _, v := range p.Elements {
-		if err := v.Write(oprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
-		}
+func (p *Block) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("start", thrift.I64, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:start: ", p), err)
 	}
-	if err := oprot.WriteListEnd(); err != nil {
-		return thrift.PrependError("error writing list end: ", err)
+	if err := oprot.WriteI64(int64(p.Start)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.start (1) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:elements: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:start: ", p), err)
 	}
 	return err
 }
 
-func (p *FetchBlocksMetadataRawV2Result_) writeField2(oprot thrift.TProtocol) (err error) {
-	if p.IsSetNextPageToken() {
-		if err := oprot.WriteFieldBegin("nextPageToken", thrift.STRING, 2); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:nextPageToken: ", p), err)
+func (p *Block) writeField2(oprot thrift.TProtocol) (err error) {
+	if p.IsSetSegments() {
+		if err := oprot.WriteFieldBegin("segments", thrift.STRUCT, 2); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:segments: ", p), err)
 		}
-		if err := oprot.WriteBinary(p.NextPageToken); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.nextPageToken (2) field write error: ", p), err)
+		if err := p.Segments.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Segments), err)
 		}
 		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 2:nextPageToken: ", p), err)
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 2:segments: ", p), err)
 		}
 	}
 	return err
 }
 
-func (p *FetchBlocksMetadataRawV2Result_) String() string {
-	if p == nil {
-		return "<nil>"
+func (p *Block) writeField3(oprot thrift.TProtocol) (err error) {
+	if p.IsSetErr() {
+		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 3); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:err: ", p), err)
+		}
+		if err := p.Err.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Err), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 3:err: ", p), err)
+		}
 	}
-	return fmt.Sprintf("FetchBlocksMetadataRawV2Result_(%+v)", *p)
-}
-
-// Attributes:
-//  - ID
-//  - Start
-//  - Err
-//  - Size
-//  - Checksum
-//  - LastRead
-//  - LastReadTimeType
-//  - EncodedTags
-type BlockMetadataV2 struct {
-	ID               []byte   `thrift:"id,1,required" db:"id" json:"id"`
-	Start            int64    `thrift:"start,2,required" db:"start" json:"start"`
-	Err              *Error   `thrift:"err,3" db:"err" json:"err,omitempty"`
-	Size             *int64   `thrift:"size,4" db:"size" json:"size,omitempty"`
-	Checksum         *int64   `thrift:"checksum,5" db:"checksum" json:"checksum,omitempty"`
-	LastRead         *int64   `thrift:"lastRead,6" db:"lastRead" json:"lastRead,omitempty"`
-	LastReadTimeType TimeType `thrift:"lastReadTimeType,7" db:"lastReadTimeType" json:"lastReadTimeType,omitempty"`
-	EncodedTags      []byte   `thrift:"encodedTags,8" db:"encodedTags" json:"encodedTags,omitempty"`
+	return err
 }
 
-func NewBlockMetadataV2() *BlockMetadataV2 {
-	return &BlockMetadataV2{
-		LastReadTimeType: 0,
+func (p *Block) writeField4(oprot thrift.TProtocol) (err error) {
+	if p.IsSetChecksum() {
+		if err := oprot.WriteFieldBegin("checksum", thrift.I64, 4); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:checksum: ", p), err)
+		}
+		if err := oprot.WriteI64(int64(*p.Checksum)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.checksum (4) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 4:checksum: ", p), err)
+		}
 	}
+	return err
 }
 
-func (p *BlockMetadataV2) GetID() []byte {
-	return p.ID
+func (p *Block) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("Block(%+v)", *p)
 }
 
-func (p *BlockMetadataV2) GetStart() int64 {
-	return p.Start
+// Attributes:
+//  - Name
+//  - Value
+type Tag struct {
+	Name  string `thrift:"name,1,required" db:"name" json:"name"`
+	Value string `thrift:"value,2,required" db:"value" json:"value"`
 }
 
-var BlockMetadataV2_Err_DEFAULT *Error
-
-func (p *BlockMetadataV2) GetErr() *Error {
-	if !p.IsSetErr() {
-		return BlockMetadataV2_Err_DEFAULT
-	}
-	return p.Err
+func NewTag() *Tag {
+	return &Tag{}
 }
 
-var BlockMetadataV2_Size_DEFAULT int64
-
-func (p *BlockMetadataV2) GetSize() int64 {
-	if !p.IsSetSize() {
-		return BlockMetadataV2_Size_DEFAULT
-	}
-	return *p.Size
+func (p *Tag) GetName() string {
+	return p.Name
 }
 
-var BlockMetadataV2_Checksum_DEFAULT int64
-
-func (p *BlockMetadataV2) GetChecksum() int64 {
-	if !p.IsSetChecksum() {
-		return BlockMetadataV2_Checksum_DEFAULT
-	}
-	return *p.Checksum
+func (p *Tag) GetValue() string {
+	return p.Value
 }
-
-var BlockMetadataV2_LastRead_DEFAULT int64
-
-func (p *BlockMetadataV2) GetLastRead() int64 {
-	if !p.IsSetLastRead() {
-		return BlockMetadataV2_LastRead_DEFAULT
+func (p *Tag) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
-	return *p.LastRead
-}
-
-var BlockMetadataV2_LastReadTimeType_DEFAULT TimeType = 0
 
-func (p *BlockMetadataV2) GetLastReadTimeType() TimeType {
-	return p.LastReadTimeType
-}
-
-var BlockMetadataV2_EncodedTags_DEFAULT []byte
-
-func (p *BlockMetadataV2) GetEncodedTags() []byte {
-	return p.EncodedTags
-}
-func (p *BlockMetadataV2) IsSetErr() bool {
-	return p.Err != nil
-}
-
-func (p *BlockMetadataV2) IsSetSize() bool {
-	return p.Size != nil
-}
-
-func (p *BlockMetadataV2) IsSetChecksum() bool {
-	return p.Checksum != nil
-}
-
-func (p *BlockMetadataV2) IsSetLastRead() bool {
-	return p.LastRead != nil
-}
-
-func (p *BlockMetadataV2) IsSetLastReadTimeType() bool {
-	return p.LastReadTimeType != BlockMetadataV2_LastReadTimeType_DEFAULT
-}
-
-func (p *BlockMetadataV2) IsSetEncodedTags() bool {
-	return p.EncodedTags != nil
-}
-
-func (p *BlockMetadataV2) Read(iprot thrift.TProtocol) error {
-	if _, err := iprot.ReadStructBegin(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
-	}
-
-	var issetID bool = false
-	var issetStart bool = false
+	var issetName bool = false
+	var issetValue bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -6124,36 +6407,12 @@ func (p *BlockMetadataV2) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetID = true
+			issetName = true
 		case 2:
 			if err := p.ReadField2(iprot); err != nil {
 				return err
 			}
-			issetStart = true
-		case 3:
-			if err := p.ReadField3(iprot); err != nil {
-				return err
-			}
-		case 4:
-			if err := p.ReadField4(iprot); err != nil {
-				return err
-			}
-		case 5:
-			if err := p.ReadField5(iprot); err != nil {
-				return err
-			}
-		case 6:
-			if err := p.ReadField6(iprot); err != nil {
-				return err
-			}
-		case 7:
-			if err := p.ReadField7(iprot); err != nil {
-				return err
-			}
-		case 8:
-			if err := p.ReadField8(iprot); err != nil {
-				return err
-			}
+			issetValue = true
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -6166,91 +6425,35 @@ func (p *BlockMetadataV2) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetID {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field ID is not set"))
+	if !issetName {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Name is not set"))
 	}
-	if !issetStart {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Start is not set"))
+	if !issetValue {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Value is not set"))
 	}
 	return nil
 }
 
-func (p *BlockMetadataV2) ReadField1(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBinary(); err != nil {
+func (p *Tag) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
 		return thrift.PrependError("error reading field 1: ", err)
 	} else {
-		p.ID = v
+		p.Name = v
 	}
 	return nil
 }
 
-func (p *BlockMetadataV2) ReadField2(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI64(); err != nil {
+func (p *Tag) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
 		return thrift.PrependError("error reading field 2: ", err)
 	} else {
-		p.Start = v
-	}
-	return nil
-}
-
-func (p *BlockMetadataV2) ReadField3(iprot thrift.TProtocol) error {
-	p.Err = &Error{
-		Type: 0,
-	}
-	if err := p.Err.Read(iprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Err), err)
-	}
-	return nil
-}
-
-func (p *BlockMetadataV2) ReadField4(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI64(); err != nil {
-		return thrift.PrependError("error reading field 4: ", err)
-	} else {
-		p.Size = &v
-	}
-	return nil
-}
-
-func (p *BlockMetadataV2) ReadField5(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI64(); err != nil {
-		return thrift.PrependError("error reading field 5: ", err)
-	} else {
-		p.Checksum = &v
-	}
-	return nil
-}
-
-func (p *BlockMetadataV2) ReadField6(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI64(); err != nil {
-		return thrift.PrependError("error reading field 6: ", err)
-	} else {
-		p.LastRead = &v
-	}
-	return nil
-}
-
-func (p *BlockMetadataV2) ReadField7(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI32(); err != nil {
-		return thrift.PrependError("error reading field 7: ", err)
-	} else {
-		temp := TimeType(v)
-		p.LastReadTimeType = temp
-	}
-	return nil
-}
-
-func (p *BlockMetadataV2) ReadField8(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBinary(); err != nil {
-		return thrift.PrependError("error reading field 8: ", err)
-	} else {
-		p.EncodedTags = v
+		p.Value = v
 	}
 	return nil
 }
 
-func (p *BlockMetadataV2) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("BlockMetadataV2"); err != nil {
+func (p *Tag) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("Tag"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -6260,24 +6463,6 @@ func (p *BlockMetadataV2) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField2(oprot); err != nil {
 			return err
 		}
-		if err := p.writeField3(oprot); err != nil {
-			return err
-		}
-		if err := p.writeField4(oprot); err != nil {
-			return err
-		}
-		if err := p.writeField5(oprot); err != nil {
-			return err
-		}
-		if err := p.writeField6(oprot); err != nil {
-			return err
-		}
-		if err := p.writeField7(oprot); err != nil {
-			return err
-		}
-		if err := p.writeField8(oprot); err != nil {
-			return err
-		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -6288,155 +6473,146 @@ func (p *BlockMetadataV2) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *BlockMetadataV2) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("id", thrift.STRING, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:id: ", p), err)
+func (p *Tag) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("name", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:name: ", p), err)
 	}
-	if err := oprot.WriteBinary(p.ID); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.id (1) field write error: ", p), err)
+	if err := oprot.WriteString(string(p.Name)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.name (1) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:id: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:name: ", p), err)
 	}
 	return err
 }
 
-func (p *BlockMetadataV2) writeField2(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("start", thrift.I64, 2); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:start: ", p), err)
+func (p *Tag) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("value", thrift.STRING, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:value: ", p), err)
 	}
-	if err := oprot.WriteI64(int64(p.Start)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.start (2) field write error: ", p), err)
+	if err := oprot.WriteString(string(p.Value)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.value (2) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:start: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:value: ", p), err)
 	}
 	return err
 }
 
-func (p *BlockMetadataV2) writeField3(oprot thrift.TProtocol) (err error) {
-	if p.IsSetErr() {
-		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 3); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:err: ", p), err)
-		}
-		if err := p.Err.Write(oprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Err), err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 3:err: ", p), err)
-		}
+func (p *Tag) String() string {
+	if p == nil {
+		return "<nil>"
 	}
-	return err
+	return fmt.Sprintf("Tag(%+v)", *p)
 }
 
-func (p *BlockMetadataV2) writeField4(oprot thrift.TProtocol) (err error) {
-	if p.IsSetSize() {
-		if err := oprot.WriteFieldBegin("size", thrift.I64, 4); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:size: ", p), err)
-		}
-		if err := oprot.WriteI64(int64(*p.Size)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.size (4) field write error: ", p), err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 4:size: ", p), err)
-		}
-	}
-	return err
+// Attributes:
+//  - NameSpace
+//  - Shard
+//  - RangeStart
+//  - RangeEnd
+//  - Limit
+//  - PageToken
+//  - IncludeSizes
+//  - IncludeChecksums
+//  - IncludeLastRead
+type FetchBlocksMetadataRawRequest struct {
+	NameSpace        []byte `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
+	Shard            int32  `thrift:"shard,2,required" db:"shard" json:"shard"`
+	RangeStart       int64  `thrift:"rangeStart,3,required" db:"rangeStart" json:"rangeStart"`
+	RangeEnd         int64  `thrift:"rangeEnd,4,required" db:"rangeEnd" json:"rangeEnd"`
+	Limit            int64  `thrift:"limit,5,required" db:"limit" json:"limit"`
+	PageToken        *int64 `thrift:"pageToken,6" db:"pageToken" json:"pageToken,omitempty"`
+	IncludeSizes     *bool  `thrift:"includeSizes,7" db:"includeSizes" json:"includeSizes,omitempty"`
+	IncludeChecksums *bool  `thrift:"includeChecksums,8" db:"includeChecksums" json:"includeChecksums,omitempty"`
+	IncludeLastRead  *bool  `thrift:"includeLastRead,9" db:"includeLastRead" json:"includeLastRead,omitempty"`
 }
 
-func (p *BlockMetadataV2) writeField5(oprot thrift.TProtocol) (err error) {
-	if p.IsSetChecksum() {
-		if err := oprot.WriteFieldBegin("checksum", thrift.I64, 5); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 5:checksum: ", p), err)
-		}
-		if err := oprot.WriteI64(int64(*p.Checksum)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.checksum (5) field write error: ", p), err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 5:checksum: ", p), err)
-		}
-	}
-	return err
+func NewFetchBlocksMetadataRawRequest() *FetchBlocksMetadataRawRequest {
+	return &FetchBlocksMetadataRawRequest{}
 }
 
-func (p *BlockMetadataV2) writeField6(oprot thrift.TProtocol) (err error) {
-	if p.IsSetLastRead() {
-		if err := oprot.WriteFieldBegin("lastRead", thrift.I64, 6); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 6:lastRead: ", p), err)
-		}
-		if err := oprot.WriteI64(int64(*p.LastRead)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.lastRead (6) field write error: ", p), err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 6:lastRead: ", p), err)
-		}
-	}
-	return err
+func (p *FetchBlocksMetadataRawRequest) GetNameSpace() []byte {
+	return p.NameSpace
 }
 
-func (p *BlockMetadataV2) writeField7(oprot thrift.TProtocol) (err error) {
-	if p.IsSetLastReadTimeType() {
-		if err := oprot.WriteFieldBegin("lastReadTimeType", thrift.I32, 7); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 7:lastReadTimeType: ", p), err)
-		}
-		if err := oprot.WriteI32(int32(p.LastReadTimeType)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.lastReadTimeType (7) field write error: ", p), err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 7:lastReadTimeType: ", p), err)
-		}
+func (p *FetchBlocksMetadataRawRequest) GetShard() int32 {
+	return p.Shard
+}
+
+func (p *FetchBlocksMetadataRawRequest) GetRangeStart() int64 {
+	return p.RangeStart
+}
+
+func (p *FetchBlocksMetadataRawRequest) GetRangeEnd() int64 {
+	return p.RangeEnd
+}
+
+func (p *FetchBlocksMetadataRawRequest) GetLimit() int64 {
+	return p.Limit
+}
+
+var FetchBlocksMetadataRawRequest_PageToken_DEFAULT int64
+
+func (p *FetchBlocksMetadataRawRequest) GetPageToken() int64 {
+	if !p.IsSetPageToken() {
+		return FetchBlocksMetadataRawRequest_PageToken_DEFAULT
 	}
-	return err
+	return *p.PageToken
 }
 
-func (p *BlockMetadataV2) writeField8(oprot thrift.TProtocol) (err error) {
-	if p.IsSetEncodedTags() {
-		if err := oprot.WriteFieldBegin("encodedTags", thrift.STRING, 8); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 8:encodedTags: ", p), err)
-		}
-		if err := oprot.WriteBinary(p.EncodedTags); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.encodedTags (8) field write error: ", p), err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 8:encodedTags: ", p), err)
-		}
+var FetchBlocksMetadataRawRequest_IncludeSizes_DEFAULT bool
+
+func (p *FetchBlocksMetadataRawRequest) GetIncludeSizes() bool {
+	if !p.IsSetIncludeSizes() {
+		return FetchBlocksMetadataRawRequest_IncludeSizes_DEFAULT
 	}
-	return err
+	return *p.IncludeSizes
 }
 
-func (p *BlockMetadataV2) String() string {
-	if p == nil {
-		return "<nil>"
+var FetchBlocksMetadataRawRequest_IncludeChecksums_DEFAULT bool
+
+func (p *FetchBlocksMetadataRawRequest) GetIncludeChecksums() bool {
+	if !p.IsSetIncludeChecksums() {
+		return FetchBlocksMetadataRawRequest_IncludeChecksums_DEFAULT
 	}
-	return fmt.Sprintf("BlockMetadataV2(%+v)", *p)
+	return *p.IncludeChecksums
 }
 
-// Attributes:
-//  - NameSpace
-//  - Elements
-type WriteBatchRawRequest struct {
-	NameSpace []byte                         `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
-	Elements  []*WriteBatchRawRequestElement `thrift:"elements,2,required" db:"elements" json:"elements"`
+var FetchBlocksMetadataRawRequest_IncludeLastRead_DEFAULT bool
+
+func (p *FetchBlocksMetadataRawRequest) GetIncludeLastRead() bool {
+	if !p.IsSetIncludeLastRead() {
+		return FetchBlocksMetadataRawRequest_IncludeLastRead_DEFAULT
+	}
+	return *p.IncludeLastRead
+}
+func (p *FetchBlocksMetadataRawRequest) IsSetPageToken() bool {
+	return p.PageToken != nil
 }
 
-func NewWriteBatchRawRequest() *WriteBatchRawRequest {
-	return &WriteBatchRawRequest{}
+func (p *FetchBlocksMetadataRawRequest) IsSetIncludeSizes() bool {
+	return p.IncludeSizes != nil
 }
 
-func (p *WriteBatchRawRequest) GetNameSpace() []byte {
-	return p.NameSpace
+func (p *FetchBlocksMetadataRawRequest) IsSetIncludeChecksums() bool {
+	return p.IncludeChecksums != nil
 }
 
-func (p *WriteBatchRawRequest) GetElements() []*WriteBatchRawRequestElement {
-	return p.Elements
+func (p *FetchBlocksMetadataRawRequest) IsSetIncludeLastRead() bool {
+	return p.IncludeLastRead != nil
 }
-func (p *WriteBatchRawRequest) Read(iprot thrift.TProtocol) error {
+
+func (p *FetchBlocksMetadataRawRequest) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
 	var issetNameSpace bool = false
-	var issetElements bool = false
+	var issetShard bool = false
+	var issetRangeStart bool = false
+	var issetRangeEnd bool = false
+	var issetLimit bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -6456,7 +6632,38 @@ func (p *WriteBatchRawRequest) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField2(iprot); err != nil {
 				return err
 			}
-			issetElements = true
+			issetShard = true
+		case 3:
+			if err := p.ReadField3(iprot); err != nil {
+				return err
+			}
+			issetRangeStart = true
+		case 4:
+			if err := p.ReadField4(iprot); err != nil {
+				return err
+			}
+			issetRangeEnd = true
+		case 5:
+			if err := p.ReadField5(iprot); err != nil {
+				return err
+			}
+			issetLimit = true
+		case 6:
+			if err := p.ReadField6(iprot); err != nil {
+				return err
+			}
+		case 7:
+			if err := p.ReadField7(iprot); err != nil {
+				return err
+			}
+		case 8:
+			if err := p.ReadField8(iprot); err != nil {
+				return err
+			}
+		case 9:
+			if err := p.ReadField9(iprot); err != nil {
+				return err
+			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -6472,13 +6679,22 @@ func (p *WriteBatchRawRequest) Read(iprot thrift.TProtocol) error {
 	if !issetNameSpace {
 		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field NameSpace is not set"))
 	}
-	if !issetElements {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Elements is not set"))
+	if !issetShard {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Shard is not set"))
+	}
+	if !issetRangeStart {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field RangeStart is not set"))
+	}
+	if !issetRangeEnd {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field RangeEnd is not set"))
+	}
+	if !issetLimit {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Limit is not set"))
 	}
 	return nil
 }
 
-func (p *WriteBatchRawRequest) ReadField1(iprot thrift.TProtocol) error {
+func (p *FetchBlocksMetadataRawRequest) ReadField1(iprot thrift.TProtocol) error {
 	if v, err := iprot.ReadBinary(); err != nil {
 		return thrift.PrependError("error reading field 1: ", err)
 	} else {
@@ -6487,185 +6703,80 @@ func (p *WriteBatchRawRequest) ReadField1(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *WriteBatchRawRequest) ReadField2(iprot thrift.TProtocol) error {
-	_, size, err := iprot.ReadListBegin()
-	if err != nil {
-		return thrift.PrependError("error reading list begin: ", err)
-	}
-	tSlice := make([]*WriteBatchRawRequestElement, 0, size)
-	p.Elements = tSlice
-	for i := 0; i < size; i++ {
-		_elem16 := &WriteBatchRawRequestElement{}
-		if err := _elem16.Read(iprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem16), err)
-		}
-		p.Elements = append(p.Elements, _elem16)
-	}
-	if err := iprot.ReadListEnd(); err != nil {
-		return thrift.PrependError("error reading list end: ", err)
+func (p *FetchBlocksMetadataRawRequest) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI32(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		p.Shard = v
 	}
 	return nil
 }
 
-func (p *WriteBatchRawRequest) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("WriteBatchRawRequest"); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
-	}
-	if p != nil {
-		if err := p.writeField1(oprot); err != nil {
-			return err
-		}
-		if err := p.writeField2(oprot); err != nil {
-			return err
-		}
-	}
-	if err := oprot.WriteFieldStop(); err != nil {
-		return thrift.PrependError("write field stop error: ", err)
-	}
-	if err := oprot.WriteStructEnd(); err != nil {
-		return thrift.PrependError("write struct stop error: ", err)
+func (p *FetchBlocksMetadataRawRequest) ReadField3(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 3: ", err)
+	} else {
+		p.RangeStart = v
 	}
 	return nil
 }
 
-func (p *WriteBatchRawRequest) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("nameSpace", thrift.STRING, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:nameSpace: ", p), err)
-	}
-	if err := oprot.WriteBinary(p.NameSpace); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.nameSpace (1) field write error: ", p), err)
-	}
-	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:nameSpace: ", p), err)
+func (p *FetchBlocksMetadataRawRequest) ReadField4(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 4: ", err)
+	} else {
+		p.RangeEnd = v
 	}
-	return err
+	return nil
 }
 
-func (p *WriteBatchRawRequest) writeField2(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("elements", thrift.LIST, 2); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:elements: ", p), err)
-	}
-	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Elements)); err != nil {
-		return thrift.PrependError("error writing list begin: ", err)
-	}
-	for _, v := range p.Elements {
-		if err := v.Write(oprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
-		}
-	}
-	if err := oprot.WriteListEnd(); err != nil {
-		return thrift.PrependError("error writing list end: ", err)
+func (p *FetchBlocksMetadataRawRequest) ReadField5(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 5: ", err)
+	} else {
+		p.Limit = v
 	}
-	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:elements: ", p), err)
+	return nil
+}
+
+func (p *FetchBlocksMetadataRawRequest) ReadField6(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 6: ", err)
+	} else {
+		p.PageToken = &v
 	}
-	return err
+	return nil
 }
 
-func (p *WriteBatchRawRequest) String() string {
-	if p == nil {
-		return "<nil>"
+func (p *FetchBlocksMetadataRawRequest) ReadField7(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 7: ", err)
+	} else {
+		p.IncludeSizes = &v
 	}
-	return fmt.Sprintf("WriteBatchRawRequest(%+v)", *p)
+	return nil
 }
 
-// Attributes:
-//  - ID
-//  - Datapoint
-type WriteBatchRawRequestElement struct {
-	ID        []byte     `thrift:"id,1,required" db:"id" json:"id"`
-	Datapoint *Datapoint `thrift:"datapoint,2,required" db:"datapoint" json:"datapoint"`
-}
-
-func NewWriteBatchRawRequestElement() *WriteBatchRawRequestElement {
-	return &WriteBatchRawRequestElement{}
-}
-
-func (p *WriteBatchRawRequestElement) GetID() []byte {
-	return p.ID
-}
-
-var WriteBatchRawRequestElement_Datapoint_DEFAULT *Datapoint
-
-func (p *WriteBatchRawRequestElement) GetDatapoint() *Datapoint {
-	if !p.IsSetDatapoint() {
-		return WriteBatchRawRequestElement_Datapoint_DEFAULT
-	}
-	return p.Datapoint
-}
-func (p *WriteBatchRawRequestElement) IsSetDatapoint() bool {
-	return p.Datapoint != nil
-}
-
-func (p *WriteBatchRawRequestElement) Read(iprot thrift.TProtocol) error {
-	if _, err := iprot.ReadStructBegin(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
-	}
-
-	var issetID bool = false
-	var issetDatapoint bool = false
-
-	for {
-		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
-		if err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
-		}
-		if fieldTypeId == thrift.STOP {
-			break
-		}
-		switch fieldId {
-		case 1:
-			if err := p.ReadField1(iprot); err != nil {
-				return err
-			}
-			issetID = true
-		case 2:
-			if err := p.ReadField2(iprot); err != nil {
-				return err
-			}
-			issetDatapoint = true
-		default:
-			if err := iprot.Skip(fieldTypeId); err != nil {
-				return err
-			}
-		}
-		if err := iprot.ReadFieldEnd(); err != nil {
-			return err
-		}
-	}
-	if err := iprot.ReadStructEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
-	}
-	if !issetID {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field ID is not set"))
-	}
-	if !issetDatapoint {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Datapoint is not set"))
-	}
-	return nil
-}
-
-func (p *WriteBatchRawRequestElement) ReadField1(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBinary(); err != nil {
-		return thrift.PrependError("error reading field 1: ", err)
+func (p *FetchBlocksMetadataRawRequest) ReadField8(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 8: ", err)
 	} else {
-		p.ID = v
+		p.IncludeChecksums = &v
 	}
 	return nil
 }
 
-func (p *WriteBatchRawRequestElement) ReadField2(iprot thrift.TProtocol) error {
-	p.Datapoint = &Datapoint{
-		TimestampTimeType: 0,
-	}
-	if err := p.Datapoint.Read(iprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Datapoint), err)
+func (p *FetchBlocksMetadataRawRequest) ReadField9(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 9: ", err)
+	} else {
+		p.IncludeLastRead = &v
 	}
 	return nil
 }
 
-func (p *WriteBatchRawRequestElement) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("WriteBatchRawRequestElement"); err != nil {
+func (p *FetchBlocksMetadataRawRequest) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("FetchBlocksMetadataRawRequest"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -6675,6 +6786,27 @@ func (p *WriteBatchRawRequestElement) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField2(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField3(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField4(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField5(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField6(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField7(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField8(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField9(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -6685,128 +6817,222 @@ func (p *WriteBatchRawRequestElement) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *WriteBatchRawRequestElement) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("id", thrift.STRING, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:id: ", p), err)
+func (p *FetchBlocksMetadataRawRequest) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("nameSpace", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:nameSpace: ", p), err)
 	}
-	if err := oprot.WriteBinary(p.ID); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.id (1) field write error: ", p), err)
+	if err := oprot.WriteBinary(p.NameSpace); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.nameSpace (1) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:id: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:nameSpace: ", p), err)
 	}
 	return err
 }
 
-func (p *WriteBatchRawRequestElement) writeField2(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("datapoint", thrift.STRUCT, 2); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:datapoint: ", p), err)
+func (p *FetchBlocksMetadataRawRequest) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("shard", thrift.I32, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:shard: ", p), err)
 	}
-	if err := p.Datapoint.Write(oprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Datapoint), err)
+	if err := oprot.WriteI32(int32(p.Shard)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.shard (2) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:datapoint: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:shard: ", p), err)
 	}
 	return err
 }
 
-func (p *WriteBatchRawRequestElement) String() string {
-	if p == nil {
-		return "<nil>"
+func (p *FetchBlocksMetadataRawRequest) writeField3(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("rangeStart", thrift.I64, 3); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:rangeStart: ", p), err)
 	}
-	return fmt.Sprintf("WriteBatchRawRequestElement(%+v)", *p)
+	if err := oprot.WriteI64(int64(p.RangeStart)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.rangeStart (3) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:rangeStart: ", p), err)
+	}
+	return err
 }
 
-// Attributes:
-//  - NameSpace
-//  - Elements
-type WriteTaggedBatchRawRequest struct {
-	NameSpace []byte                               `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
-	Elements  []*WriteTaggedBatchRawRequestElement `thrift:"elements,2,required" db:"elements" json:"elements"`
+func (p *FetchBlocksMetadataRawRequest) writeField4(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("rangeEnd", thrift.I64, 4); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:rangeEnd: ", p), err)
+	}
+	if err := oprot.WriteI64(int64(p.RangeEnd)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.rangeEnd (4) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 4:rangeEnd: ", p), err)
+	}
+	return err
 }
 
-func NewWriteTaggedBatchRawRequest() *WriteTaggedBatchRawRequest {
-	return &WriteTaggedBatchRawRequest{}
+func (p *FetchBlocksMetadataRawRequest) writeField5(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("limit", thrift.I64, 5); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 5:limit: ", p), err)
+	}
+	if err := oprot.WriteI64(int64(p.Limit)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.limit (5) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 5:limit: ", p), err)
+	}
+	return err
 }
 
-func (p *WriteTaggedBatchRawRequest) GetNameSpace() []byte {
-	return p.NameSpace
+func (p *FetchBlocksMetadataRawRequest) writeField6(oprot thrift.TProtocol) (err error) {
+	if p.IsSetPageToken() {
+		if err := oprot.WriteFieldBegin("pageToken", thrift.I64, 6); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 6:pageToken: ", p), err)
+		}
+		if err := oprot.WriteI64(int64(*p.PageToken)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.pageToken (6) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 6:pageToken: ", p), err)
+		}
+	}
+	return err
 }
 
-func (p *WriteTaggedBatchRawRequest) GetElements() []*WriteTaggedBatchRawRequestElement {
-	return p.Elements
-}
-func (p *WriteTaggedBatchRawRequest) Read(iprot thrift.TProtocol) error {
-	if _, err := iprot.ReadStructBegin(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+func (p *FetchBlocksMetadataRawRequest) writeField7(oprot thrift.TProtocol) (err error) {
+	if p.IsSetIncludeSizes() {
+		if err := oprot.WriteFieldBegin("includeSizes", thrift.BOOL, 7); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 7:includeSizes: ", p), err)
+		}
+		if err := oprot.WriteBool(bool(*p.IncludeSizes)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.includeSizes (7) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 7:includeSizes: ", p), err)
+		}
 	}
+	return err
+}
 
-	var issetNameSpace bool = false
-	var issetElements bool = false
-
-	for {
-		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
-		if err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+func (p *FetchBlocksMetadataRawRequest) writeField8(oprot thrift.TProtocol) (err error) {
+	if p.IsSetIncludeChecksums() {
+		if err := oprot.WriteFieldBegin("includeChecksums", thrift.BOOL, 8); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 8:includeChecksums: ", p), err)
 		}
-		if fieldTypeId == thrift.STOP {
-			break
+		if err := oprot.WriteBool(bool(*p.IncludeChecksums)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.includeChecksums (8) field write error: ", p), err)
 		}
-		switch fieldId {
-		case 1:
-			if err := p.ReadField1(iprot); err != nil {
-				return err
-			}
-			issetNameSpace = true
-		case 2:
-			if err := p.ReadField2(iprot); err != nil {
-				return err
-			}
-			issetElements = true
-		default:
-			if err := iprot.Skip(fieldTypeId); err != nil {
-				return err
-			}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 8:includeChecksums: ", p), err)
 		}
-		if err := iprot.ReadFieldEnd(); err != nil {
-			return err
+	}
+	return err
+}
+
+func (p *FetchBlocksMetadataRawRequest) writeField9(oprot thrift.TProtocol) (err error) {
+	if p.IsSetIncludeLastRead() {
+		if err := oprot.WriteFieldBegin("includeLastRead", thrift.BOOL, 9); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 9:includeLastRead: ", p), err)
+		}
+		if err := oprot.WriteBool(bool(*p.IncludeLastRead)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.includeLastRead (9) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 9:includeLastRead: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *FetchBlocksMetadataRawRequest) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("FetchBlocksMetadataRawRequest(%+v)", *p)
+}
+
+// Attributes:
+//  - Elements
+//  - NextPageToken
+type FetchBlocksMetadataRawResult_ struct {
+	Elements      []*BlocksMetadata `thrift:"elements,1,required" db:"elements" json:"elements"`
+	NextPageToken *int64            `thrift:"nextPageToken,2" db:"nextPageToken" json:"nextPageToken,omitempty"`
+}
+
+func NewFetchBlocksMetadataRawResult_() *FetchBlocksMetadataRawResult_ {
+	return &FetchBlocksMetadataRawResult_{}
+}
+
+func (p *FetchBlocksMetadataRawResult_) GetElements() []*BlocksMetadata {
+	return p.Elements
+}
+
+var FetchBlocksMetadataRawResult__NextPageToken_DEFAULT int64
+
+func (p *FetchBlocksMetadataRawResult_) GetNextPageToken() int64 {
+	if !p.IsSetNextPageToken() {
+		return FetchBlocksMetadataRawResult__NextPageToken_DEFAULT
+	}
+	return *p.NextPageToken
+}
+func (p *FetchBlocksMetadataRawResult_) IsSetNextPageToken() bool {
+	return p.NextPageToken != nil
+}
+
+func (p *FetchBlocksMetadataRawResult_) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	var issetElements bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetElements = true
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
+				return err
+			}
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
 		}
 	}
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetNameSpace {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field NameSpace is not set"))
-	}
 	if !issetElements {
 		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Elements is not set"))
 	}
 	return nil
 }
 
-func (p *WriteTaggedBatchRawRequest) ReadField1(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBinary(); err != nil {
-		return thrift.PrependError("error reading field 1: ", err)
-	} else {
-		p.NameSpace = v
-	}
-	return nil
-}
-
-func (p *WriteTaggedBatchRawRequest) ReadField2(iprot thrift.TProtocol) error {
+func (p *FetchBlocksMetadataRawResult_) ReadField1(iprot thrift.TProtocol) error {
 	_, size, err := iprot.ReadListBegin()
 	if err != nil {
 		return thrift.PrependError("error reading list begin: ", err)
 	}
-	tSlice := make([]*WriteTaggedBatchRawRequestElement, 0, size)
+	tSlice := make([]*BlocksMetadata, 0, size)
 	p.Elements = tSlice
 	for i := 0; i < size; i++ {
-		_elem17 := &WriteTaggedBatchRawRequestElement{}
-		if err := _elem17.Read(iprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem17), err)
+		_elem13 := &BlocksMetadata{}
+		if err := _elem13.Read(iprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem13), err)
 		}
-		p.Elements = append(p.Elements, _elem17)
+		p.Elements = append(p.Elements, _elem13)
 	}
 	if err := iprot.ReadListEnd(); err != nil {
 		return thrift.PrependError("error reading list end: ", err)
@@ -6814,8 +7040,17 @@ func (p *WriteTaggedBatchRawRequest) ReadField2(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *WriteTaggedBatchRawRequest) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("WriteTaggedBatchRawRequest"); err != nil {
+func (p *FetchBlocksMetadataRawResult_) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		p.NextPageToken = &v
+	}
+	return nil
+}
+
+func (p *FetchBlocksMetadataRawResult_) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("FetchBlocksMetadataRawResult"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -6835,22 +7070,9 @@ func (p *WriteTaggedBatchRawRequest) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *WriteTaggedBatchRawRequest) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("nameSpace", thrift.STRING, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:nameSpace: ", p), err)
-	}
-	if err := oprot.WriteBinary(p.NameSpace); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.nameSpace (1) field write error: ", p), err)
-	}
-	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:nameSpace: ", p), err)
-	}
-	return err
-}
-
-func (p *WriteTaggedBatchRawRequest) writeField2(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("elements", thrift.LIST, 2); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:elements: ", p), err)
+func (p *FetchBlocksMetadataRawResult_) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("elements", thrift.LIST, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:elements: ", p), err)
 	}
 	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Elements)); err != nil {
 		return thrift.PrependError("error writing list begin: ", err)
@@ -6864,60 +7086,59 @@ func (p *WriteTaggedBatchRawRequest) writeField2(oprot thrift.TProtocol) (err er
 		return thrift.PrependError("error writing list end: ", err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:elements: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:elements: ", p), err)
 	}
 	return err
 }
 
-func (p *WriteTaggedBatchRawRequest) String() string {
+func (p *FetchBlocksMetadataRawResult_) writeField2(oprot thrift.TProtocol) (err error) {
+	if p.IsSetNextPageToken() {
+		if err := oprot.WriteFieldBegin("nextPageToken", thrift.I64, 2); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:nextPageToken: ", p), err)
+		}
+		if err := oprot.WriteI64(int64(*p.NextPageToken)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.nextPageToken (2) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 2:nextPageToken: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *FetchBlocksMetadataRawResult_) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("WriteTaggedBatchRawRequest(%+v)", *p)
+	return fmt.Sprintf("FetchBlocksMetadataRawResult_(%+v)", *p)
 }
 
 // Attributes:
 //  - ID
-//  - EncodedTags
-//  - Datapoint
-type WriteTaggedBatchRawRequestElement struct {
-	ID          []byte     `thrift:"id,1,required" db:"id" json:"id"`
-	EncodedTags []byte     `thrift:"encodedTags,2,required" db:"encodedTags" json:"encodedTags"`
-	Datapoint   *Datapoint `thrift:"datapoint,3,required" db:"datapoint" json:"datapoint"`
+//  - Blocks
+type BlocksMetadata struct {
+	ID     []byte           `thrift:"id,1,required" db:"id" json:"id"`
+	Blocks []*BlockMetadata `thrift:"blocks,2,required" db:"blocks" json:"blocks"`
 }
 
-func NewWriteTaggedBatchRawRequestElement() *WriteTaggedBatchRawRequestElement {
-	return &WriteTaggedBatchRawRequestElement{}
+func NewBlocksMetadata() *BlocksMetadata {
+	return &BlocksMetadata{}
 }
 
-func (p *WriteTaggedBatchRawRequestElement) GetID() []byte {
+func (p *BlocksMetadata) GetID() []byte {
 	return p.ID
 }
 
-func (p *WriteTaggedBatchRawRequestElement) GetEncodedTags() []byte {
-	return p.EncodedTags
-}
-
-var WriteTaggedBatchRawRequestElement_Datapoint_DEFAULT *Datapoint
-
-func (p *WriteTaggedBatchRawRequestElement) GetDatapoint() *Datapoint {
-	if !p.IsSetDatapoint() {
-		return WriteTaggedBatchRawRequestElement_Datapoint_DEFAULT
-	}
-	return p.Datapoint
-}
-func (p *WriteTaggedBatchRawRequestElement) IsSetDatapoint() bool {
-	return p.Datapoint != nil
+func (p *BlocksMetadata) GetBlocks() []*BlockMetadata {
+	return p.Blocks
 }
-
-func (p *WriteTaggedBatchRawRequestElement) Read(iprot thrift.TProtocol) error {
+func (p *BlocksMetadata) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
 	var issetID bool = false
-	var issetEncodedTags bool = false
-	var issetDatapoint bool = false
+	var issetBlocks bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -6937,12 +7158,7 @@ func (p *WriteTaggedBatchRawRequestElement) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField2(iprot); err != nil {
 				return err
 			}
-			issetEncodedTags = true
-		case 3:
-			if err := p.ReadField3(iprot); err != nil {
-				return err
-			}
-			issetDatapoint = true
+			issetBlocks = true
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -6958,16 +7174,13 @@ func (p *WriteTaggedBatchRawRequestElement) Read(iprot thrift.TProtocol) error {
 	if !issetID {
 		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field ID is not set"))
 	}
-	if !issetEncodedTags {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field EncodedTags is not set"))
-	}
-	if !issetDatapoint {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Datapoint is not set"))
+	if !issetBlocks {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Blocks is not set"))
 	}
 	return nil
 }
 
-func (p *WriteTaggedBatchRawRequestElement) ReadField1(iprot thrift.TProtocol) error {
+func (p *BlocksMetadata) ReadField1(iprot thrift.TProtocol) error {
 	if v, err := iprot.ReadBinary(); err != nil {
 		return thrift.PrependError("error reading field 1: ", err)
 	} else {
@@ -6976,27 +7189,30 @@ func (p *WriteTaggedBatchRawRequestElement) ReadField1(iprot thrift.TProtocol) e
 	return nil
 }
 
-func (p *WriteTaggedBatchRawRequestElement) ReadField2(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBinary(); err != nil {
-		return thrift.PrependError("error reading field 2: ", err)
-	} else {
-		p.EncodedTags = v
+func (p *BlocksMetadata) ReadField2(iprot thrift.TProtocol) error {
+	_, size, err := iprot.ReadListBegin()
+	if err != nil {
+		return thrift.PrependError("error reading list begin: ", err)
 	}
-	return nil
-}
-
-func (p *WriteTaggedBatchRawRequestElement) ReadField3(iprot thrift.TProtocol) error {
-	p.Datapoint = &Datapoint{
-		TimestampTimeType: 0,
+	tSlice := make([]*BlockMetadata, 0, size)
+	p.Blocks = tSlice
+	for i := 0; i < size; i++ {
+		_elem14 := &BlockMetadata{
+			LastReadTimeType: 0,
+		}
+		if err := _elem14.Read(iprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem14), err)
+		}
+		p.Blocks = append(p.Blocks, _elem14)
 	}
-	if err := p.Datapoint.Read(iprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Datapoint), err)
+	if err := iprot.ReadListEnd(); err != nil {
+		return thrift.PrependError("error reading list end: ", err)
 	}
 	return nil
 }
 
-func (p *WriteTaggedBatchRawRequestElement) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("WriteTaggedBatchRawRequestElement"); err != nil {
+func (p *BlocksMetadata) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("BlocksMetadata"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -7006,9 +7222,6 @@ func (p *WriteTaggedBatchRawRequestElement) Write(oprot thrift.TProtocol) error
 		if err := p.writeField2(oprot); err != nil {
 			return err
 		}
-		if err := p.writeField3(oprot); err != nil {
-			return err
-		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -7019,7 +7232,7 @@ func (p *WriteTaggedBatchRawRequestElement) Write(oprot thrift.TProtocol) error
 	return nil
 }
 
-func (p *WriteTaggedBatchRawRequestElement) writeField1(oprot thrift.TProtocol) (err error) {
+func (p *BlocksMetadata) writeField1(oprot thrift.TProtocol) (err error) {
 	if err := oprot.WriteFieldBegin("id", thrift.STRING, 1); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:id: ", p), err)
 	}
@@ -7032,74 +7245,127 @@ func (p *WriteTaggedBatchRawRequestElement) writeField1(oprot thrift.TProtocol)
 	return err
 }
 
-func (p *WriteTaggedBatchRawRequestElement) writeField2(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("encodedTags", thrift.STRING, 2); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:encodedTags: ", p), err)
-	}
-	if err := oprot.WriteBinary(p.EncodedTags); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.encodedTags (2) field write error: ", p), err)
+func (p *BlocksMetadata) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("blocks", thrift.LIST, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:blocks: ", p), err)
 	}
-	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:encodedTags: ", p), err)
+	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Blocks)); err != nil {
+		return thrift.PrependError("error writing list begin: ", err)
 	}
-	return err
-}
-
-func (p *WriteTaggedBatchRawRequestElement) writeField3(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("datapoint", thrift.STRUCT, 3); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:datapoint: ", p), err)
+	for _, v := range p.Blocks {
+		if err := v.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
+		}
 	}
-	if err := p.Datapoint.Write(oprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Datapoint), err)
+	if err := oprot.WriteListEnd(); err != nil {
+		return thrift.PrependError("error writing list end: ", err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:datapoint: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:blocks: ", p), err)
 	}
 	return err
 }
 
-func (p *WriteTaggedBatchRawRequestElement) String() string {
+func (p *BlocksMetadata) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("WriteTaggedBatchRawRequestElement(%+v)", *p)
+	return fmt.Sprintf("BlocksMetadata(%+v)", *p)
 }
 
 // Attributes:
-//  - Index
 //  - Err
-type WriteBatchRawError struct {
-	Index int64  `thrift:"index,1,required" db:"index" json:"index"`
-	Err   *Error `thrift:"err,2,required" db:"err" json:"err"`
-}
-
-func NewWriteBatchRawError() *WriteBatchRawError {
-	return &WriteBatchRawError{}
+//  - Start
+//  - Size
+//  - Checksum
+//  - LastRead
+//  - LastReadTimeType
+type BlockMetadata struct {
+	Err              *Error   `thrift:"err,1" db:"err" json:"err,omitempty"`
+	Start            int64    `thrift:"start,2,required" db:"start" json:"start"`
+	Size             *int64   `thrift:"size,3" db:"size" json:"size,omitempty"`
+	Checksum         *int64   `thrift:"checksum,4" db:"checksum" json:"checksum,omitempty"`
+	LastRead         *int64   `thrift:"lastRead,5" db:"lastRead" json:"lastRead,omitempty"`
+	LastReadTimeType TimeType `thrift:"lastReadTimeType,6" db:"lastReadTimeType" json:"lastReadTimeType,omitempty"`
 }
 
-func (p *WriteBatchRawError) GetIndex() int64 {
-	return p.Index
+func NewBlockMetadata() *BlockMetadata {
+	return &BlockMetadata{
+		LastReadTimeType: 0,
+	}
 }
 
-var WriteBatchRawError_Err_DEFAULT *Error
+var BlockMetadata_Err_DEFAULT *Error
 
-func (p *WriteBatchRawError) GetErr() *Error {
+func (p *BlockMetadata) GetErr() *Error {
 	if !p.IsSetErr() {
-		return WriteBatchRawError_Err_DEFAULT
+		return BlockMetadata_Err_DEFAULT
 	}
 	return p.Err
 }
-func (p *WriteBatchRawError) IsSetErr() bool {
+
+func (p *BlockMetadata) GetStart() int64 {
+	return p.Start
+}
+
+var BlockMetadata_Size_DEFAULT int64
+
+func (p *BlockMetadata) GetSize() int64 {
+	if !p.IsSetSize() {
+		return BlockMetadata_Size_DEFAULT
+	}
+	return *p.Size
+}
+
+var BlockMetadata_Checksum_DEFAULT int64
+
+func (p *BlockMetadata) GetChecksum() int64 {
+	if !p.IsSetChecksum() {
+		return BlockMetadata_Checksum_DEFAULT
+	}
+	return *p.Checksum
+}
+
+var BlockMetadata_LastRead_DEFAULT int64
+
+func (p *BlockMetadata) GetLastRead() int64 {
+	if !p.IsSetLastRead() {
+		return BlockMetadata_LastRead_DEFAULT
+	}
+	return *p.LastRead
+}
+
+var BlockMetadata_LastReadTimeType_DEFAULT TimeType = 0
+
+func (p *BlockMetadata) GetLastReadTimeType() TimeType {
+	return p.LastReadTimeType
+}
+func (p *BlockMetadata) IsSetErr() bool {
 	return p.Err != nil
 }
 
-func (p *WriteBatchRawError) Read(iprot thrift.TProtocol) error {
+func (p *BlockMetadata) IsSetSize() bool {
+	return p.Size != nil
+}
+
+func (p *BlockMetadata) IsSetChecksum() bool {
+	return p.Checksum != nil
+}
+
+func (p *BlockMetadata) IsSetLastRead() bool {
+	return p.LastRead != nil
+}
+
+func (p *BlockMetadata) IsSetLastReadTimeType() bool {
+	return p.LastReadTimeType != BlockMetadata_LastReadTimeType_DEFAULT
+}
+
+func (p *BlockMetadata) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetIndex bool = false
-	var issetErr bool = false
+	var issetStart bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -7114,12 +7380,27 @@ func (p *WriteBatchRawError) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetIndex = true
 		case 2:
 			if err := p.ReadField2(iprot); err != nil {
 				return err
 			}
-			issetErr = true
+			issetStart = true
+		case 3:
+			if err := p.ReadField3(iprot); err != nil {
+				return err
+			}
+		case 4:
+			if err := p.ReadField4(iprot); err != nil {
+				return err
+			}
+		case 5:
+			if err := p.ReadField5(iprot); err != nil {
+				return err
+			}
+		case 6:
+			if err := p.ReadField6(iprot); err != nil {
+				return err
+			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -7132,36 +7413,70 @@ func (p *WriteBatchRawError) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetIndex {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Index is not set"))
+	if !issetStart {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Start is not set"))
 	}
-	if !issetErr {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Err is not set"))
+	return nil
+}
+
+func (p *BlockMetadata) ReadField1(iprot thrift.TProtocol) error {
+	p.Err = &Error{
+		Type: 0,
+	}
+	if err := p.Err.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Err), err)
 	}
 	return nil
 }
 
-func (p *WriteBatchRawError) ReadField1(iprot thrift.TProtocol) error {
+func (p *BlockMetadata) ReadField2(iprot thrift.TProtocol) error {
 	if v, err := iprot.ReadI64(); err != nil {
-		return thrift.PrependError("error reading field 1: ", err)
+		return thrift.PrependError("error reading field 2: ", err)
 	} else {
-		p.Index = v
+		p.Start = v
 	}
 	return nil
 }
 
-func (p *WriteBatchRawError) ReadField2(iprot thrift.TProtocol) error {
-	p.Err = &Error{
-		Type: 0,
+func (p *BlockMetadata) ReadField3(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 3: ", err)
+	} else {
+		p.Size = &v
 	}
-	if err := p.Err.Read(iprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Err), err)
+	return nil
+}
+
+func (p *BlockMetadata) ReadField4(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 4: ", err)
+	} else {
+		p.Checksum = &v
 	}
 	return nil
 }
 
-func (p *WriteBatchRawError) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("WriteBatchRawError"); err != nil {
+func (p *BlockMetadata) ReadField5(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 5: ", err)
+	} else {
+		p.LastRead = &v
+	}
+	return nil
+}
+
+func (p *BlockMetadata) ReadField6(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI32(); err != nil {
+		return thrift.PrependError("error reading field 6: ", err)
+	} else {
+		temp := TimeType(v)
+		p.LastReadTimeType = temp
+	}
+	return nil
+}
+
+func (p *BlockMetadata) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("BlockMetadata"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -7171,6 +7486,18 @@ func (p *WriteBatchRawError) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField2(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField3(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField4(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField5(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField6(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -7181,58 +7508,220 @@ func (p *WriteBatchRawError) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *WriteBatchRawError) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("index", thrift.I64, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:index: ", p), err)
-	}
-	if err := oprot.WriteI64(int64(p.Index)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.index (1) field write error: ", p), err)
-	}
-	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:index: ", p), err)
+func (p *BlockMetadata) writeField1(oprot thrift.TProtocol) (err error) {
+	if p.IsSetErr() {
+		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
+		}
+		if err := p.Err.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Err), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 1:err: ", p), err)
+		}
 	}
 	return err
 }
 
-func (p *WriteBatchRawError) writeField2(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 2); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:err: ", p), err)
+func (p *BlockMetadata) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("start", thrift.I64, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:start: ", p), err)
 	}
-	if err := p.Err.Write(oprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Err), err)
+	if err := oprot.WriteI64(int64(p.Start)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.start (2) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:err: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:start: ", p), err)
 	}
 	return err
 }
 
-func (p *WriteBatchRawError) String() string {
-	if p == nil {
-		return "<nil>"
-	}
-	return fmt.Sprintf("WriteBatchRawError(%+v)", *p)
-}
-
+func (p *BlockMetadata) writeField3(oprot thrift.TProtocol) (err error) {
+	if p.IsSetSize() {
+		if err := oprot.WriteFieldBegin("size", thrift.I64, 3); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:size: ", p), err)
+		}
+		if err := oprot.WriteI64(int64(*p.Size)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.size (3) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 3:size: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *BlockMetadata) writeField4(oprot thrift.TProtocol) (err error) {
+	if p.IsSetChecksum() {
+		if err := oprot.WriteFieldBegin("checksum", thrift.I64, 4); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:checksum: ", p), err)
+		}
+		if err := oprot.WriteI64(int64(*p.Checksum)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.checksum (4) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 4:checksum: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *BlockMetadata) writeField5(oprot thrift.TProtocol) (err error) {
+	if p.IsSetLastRead() {
+		if err := oprot.WriteFieldBegin("lastRead", thrift.I64, 5); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 5:lastRead: ", p), err)
+		}
+		if err := oprot.WriteI64(int64(*p.LastRead)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.lastRead (5) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 5:lastRead: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *BlockMetadata) writeField6(oprot thrift.TProtocol) (err error) {
+	if p.IsSetLastReadTimeType() {
+		if err := oprot.WriteFieldBegin("lastReadTimeType", thrift.I32, 6); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 6:lastReadTimeType: ", p), err)
+		}
+		if err := oprot.WriteI32(int32(p.LastReadTimeType)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.lastReadTimeType (6) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 6:lastReadTimeType: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *BlockMetadata) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("BlockMetadata(%+v)", *p)
+}
+
 // Attributes:
 //  - NameSpace
-type TruncateRequest struct {
-	NameSpace []byte `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
+//  - Shard
+//  - RangeStart
+//  - RangeEnd
+//  - Limit
+//  - PageToken
+//  - IncludeSizes
+//  - IncludeChecksums
+//  - IncludeLastRead
+//  - IncludeBufferChecksums
+type FetchBlocksMetadataRawV2Request struct {
+	NameSpace              []byte `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
+	Shard                  int32  `thrift:"shard,2,required" db:"shard" json:"shard"`
+	RangeStart             int64  `thrift:"rangeStart,3,required" db:"rangeStart" json:"rangeStart"`
+	RangeEnd               int64  `thrift:"rangeEnd,4,required" db:"rangeEnd" json:"rangeEnd"`
+	Limit                  int64  `thrift:"limit,5,required" db:"limit" json:"limit"`
+	PageToken              []byte `thrift:"pageToken,6" db:"pageToken" json:"pageToken,omitempty"`
+	IncludeSizes           *bool  `thrift:"includeSizes,7" db:"includeSizes" json:"includeSizes,omitempty"`
+	IncludeChecksums       *bool  `thrift:"includeChecksums,8" db:"includeChecksums" json:"includeChecksums,omitempty"`
+	IncludeLastRead        *bool  `thrift:"includeLastRead,9" db:"includeLastRead" json:"includeLastRead,omitempty"`
+	IncludeBufferChecksums *bool  `thrift:"includeBufferChecksums,10" db:"includeBufferChecksums" json:"includeBufferChecksums,omitempty"`
 }
 
-func NewTruncateRequest() *TruncateRequest {
-	return &TruncateRequest{}
+func NewFetchBlocksMetadataRawV2Request() *FetchBlocksMetadataRawV2Request {
+	return &FetchBlocksMetadataRawV2Request{}
 }
 
-func (p *TruncateRequest) GetNameSpace() []byte {
+func (p *FetchBlocksMetadataRawV2Request) GetNameSpace() []byte {
 	return p.NameSpace
 }
-func (p *TruncateRequest) Read(iprot thrift.TProtocol) error {
+
+func (p *FetchBlocksMetadataRawV2Request) GetShard() int32 {
+	return p.Shard
+}
+
+func (p *FetchBlocksMetadataRawV2Request) GetRangeStart() int64 {
+	return p.RangeStart
+}
+
+func (p *FetchBlocksMetadataRawV2Request) GetRangeEnd() int64 {
+	return p.RangeEnd
+}
+
+func (p *FetchBlocksMetadataRawV2Request) GetLimit() int64 {
+	return p.Limit
+}
+
+var FetchBlocksMetadataRawV2Request_PageToken_DEFAULT []byte
+
+func (p *FetchBlocksMetadataRawV2Request) GetPageToken() []byte {
+	return p.PageToken
+}
+
+var FetchBlocksMetadataRawV2Request_IncludeSizes_DEFAULT bool
+
+func (p *FetchBlocksMetadataRawV2Request) GetIncludeSizes() bool {
+	if !p.IsSetIncludeSizes() {
+		return FetchBlocksMetadataRawV2Request_IncludeSizes_DEFAULT
+	}
+	return *p.IncludeSizes
+}
+
+var FetchBlocksMetadataRawV2Request_IncludeChecksums_DEFAULT bool
+
+func (p *FetchBlocksMetadataRawV2Request) GetIncludeChecksums() bool {
+	if !p.IsSetIncludeChecksums() {
+		return FetchBlocksMetadataRawV2Request_IncludeChecksums_DEFAULT
+	}
+	return *p.IncludeChecksums
+}
+
+var FetchBlocksMetadataRawV2Request_IncludeLastRead_DEFAULT bool
+
+func (p *FetchBlocksMetadataRawV2Request) GetIncludeLastRead() bool {
+	if !p.IsSetIncludeLastRead() {
+		return FetchBlocksMetadataRawV2Request_IncludeLastRead_DEFAULT
+	}
+	return *p.IncludeLastRead
+}
+
+var FetchBlocksMetadataRawV2Request_IncludeBufferChecksums_DEFAULT bool
+
+func (p *FetchBlocksMetadataRawV2Request) GetIncludeBufferChecksums() bool {
+	if !p.IsSetIncludeBufferChecksums() {
+		return FetchBlocksMetadataRawV2Request_IncludeBufferChecksums_DEFAULT
+	}
+	return *p.IncludeBufferChecksums
+}
+func (p *FetchBlocksMetadataRawV2Request) IsSetPageToken() bool {
+	return p.PageToken != nil
+}
+
+func (p *FetchBlocksMetadataRawV2Request) IsSetIncludeSizes() bool {
+	return p.IncludeSizes != nil
+}
+
+func (p *FetchBlocksMetadataRawV2Request) IsSetIncludeChecksums() bool {
+	return p.IncludeChecksums != nil
+}
+
+func (p *FetchBlocksMetadataRawV2Request) IsSetIncludeLastRead() bool {
+	return p.IncludeLastRead != nil
+}
+
+func (p *FetchBlocksMetadataRawV2Request) IsSetIncludeBufferChecksums() bool {
+	return p.IncludeBufferChecksums != nil
+}
+
+func (p *FetchBlocksMetadataRawV2Request) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
 	var issetNameSpace bool = false
+	var issetShard bool = false
+	var issetRangeStart bool = false
+	var issetRangeEnd bool = false
+	var issetLimit bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -7248,6 +7737,46 @@ func (p *TruncateRequest) Read(iprot thrift.TProtocol) error {
 				return err
 			}
 			issetNameSpace = true
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
+				return err
+			}
+			issetShard = true
+		case 3:
+			if err := p.ReadField3(iprot); err != nil {
+				return err
+			}
+			issetRangeStart = true
+		case 4:
+			if err := p.ReadField4(iprot); err != nil {
+				return err
+			}
+			issetRangeEnd = true
+		case 5:
+			if err := p.ReadField5(iprot); err != nil {
+				return err
+			}
+			issetLimit = true
+		case 6:
+			if err := p.ReadField6(iprot); err != nil {
+				return err
+			}
+		case 7:
+			if err := p.ReadField7(iprot); err != nil {
+				return err
+			}
+		case 8:
+			if err := p.ReadField8(iprot); err != nil {
+				return err
+			}
+		case 9:
+			if err := p.ReadField9(iprot); err != nil {
+				return err
+			}
+		case 10:
+			if err := p.ReadField10(iprot); err != nil {
+				return err
+			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -7263,10 +7792,22 @@ func (p *TruncateRequest) Read(iprot thrift.TProtocol) error {
 	if !issetNameSpace {
 		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field NameSpace is not set"))
 	}
+	if !issetShard {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Shard is not set"))
+	}
+	if !issetRangeStart {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field RangeStart is not set"))
+	}
+	if !issetRangeEnd {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field RangeEnd is not set"))
+	}
+	if !issetLimit {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Limit is not set"))
+	}
 	return nil
 }
 
-func (p *TruncateRequest) ReadField1(iprot thrift.TProtocol) error {
+func (p *FetchBlocksMetadataRawV2Request) ReadField1(iprot thrift.TProtocol) error {
 	if v, err := iprot.ReadBinary(); err != nil {
 		return thrift.PrependError("error reading field 1: ", err)
 	} else {
@@ -7275,254 +7816,89 @@ func (p *TruncateRequest) ReadField1(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *TruncateRequest) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("TruncateRequest"); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
-	}
-	if p != nil {
-		if err := p.writeField1(oprot); err != nil {
-			return err
-		}
-	}
-	if err := oprot.WriteFieldStop(); err != nil {
-		return thrift.PrependError("write field stop error: ", err)
-	}
-	if err := oprot.WriteStructEnd(); err != nil {
-		return thrift.PrependError("write struct stop error: ", err)
+func (p *FetchBlocksMetadataRawV2Request) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI32(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		p.Shard = v
 	}
 	return nil
 }
 
-func (p *TruncateRequest) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("nameSpace", thrift.STRING, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:nameSpace: ", p), err)
-	}
-	if err := oprot.WriteBinary(p.NameSpace); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.nameSpace (1) field write error: ", p), err)
-	}
-	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:nameSpace: ", p), err)
+func (p *FetchBlocksMetadataRawV2Request) ReadField3(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 3: ", err)
+	} else {
+		p.RangeStart = v
 	}
-	return err
+	return nil
 }
 
-func (p *TruncateRequest) String() string {
-	if p == nil {
-		return "<nil>"
-	}
-	return fmt.Sprintf("TruncateRequest(%+v)", *p)
-}
-
-// Attributes:
-//  - NumSeries
-type TruncateResult_ struct {
-	NumSeries int64 `thrift:"numSeries,1,required" db:"numSeries" json:"numSeries"`
-}
-
-func NewTruncateResult_() *TruncateResult_ {
-	return &TruncateResult_{}
-}
-
-func (p *TruncateResult_) GetNumSeries() int64 {
-	return p.NumSeries
-}
-func (p *TruncateResult_) Read(iprot thrift.TProtocol) error {
-	if _, err := iprot.ReadStructBegin(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
-	}
-
-	var issetNumSeries bool = false
-
-	for {
-		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
-		if err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
-		}
-		if fieldTypeId == thrift.STOP {
-			break
-		}
-		switch fieldId {
-		case 1:
-			if err := p.ReadField1(iprot); err != nil {
-				return err
-			}
-			issetNumSeries = true
-		default:
-			if err := iprot.Skip(fieldTypeId); err != nil {
-				return err
-			}
-		}
-		if err := iprot.ReadFieldEnd(); err != nil {
-			return err
-		}
-	}
-	if err := iprot.ReadStructEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
-	}
-	if !issetNumSeries {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field NumSeries is not set"))
+func (p *FetchBlocksMetadataRawV2Request) ReadField4(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 4: ", err)
+	} else {
+		p.RangeEnd = v
 	}
 	return nil
 }
 
-func (p *TruncateResult_) ReadField1(iprot thrift.TProtocol) error {
+func (p *FetchBlocksMetadataRawV2Request) ReadField5(iprot thrift.TProtocol) error {
 	if v, err := iprot.ReadI64(); err != nil {
-		return thrift.PrependError("error reading field 1: ", err)
+		return thrift.PrependError("error reading field 5: ", err)
 	} else {
-		p.NumSeries = v
+		p.Limit = v
 	}
 	return nil
 }
 
-func (p *TruncateResult_) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("TruncateResult"); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
-	}
-	if p != nil {
-		if err := p.writeField1(oprot); err != nil {
-			return err
-		}
-	}
-	if err := oprot.WriteFieldStop(); err != nil {
-		return thrift.PrependError("write field stop error: ", err)
-	}
-	if err := oprot.WriteStructEnd(); err != nil {
-		return thrift.PrependError("write struct stop error: ", err)
+func (p *FetchBlocksMetadataRawV2Request) ReadField6(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
+		return thrift.PrependError("error reading field 6: ", err)
+	} else {
+		p.PageToken = v
 	}
 	return nil
 }
 
-func (p *TruncateResult_) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("numSeries", thrift.I64, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:numSeries: ", p), err)
-	}
-	if err := oprot.WriteI64(int64(p.NumSeries)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.numSeries (1) field write error: ", p), err)
-	}
-	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:numSeries: ", p), err)
-	}
-	return err
-}
-
-func (p *TruncateResult_) String() string {
-	if p == nil {
-		return "<nil>"
-	}
-	return fmt.Sprintf("TruncateResult_(%+v)", *p)
-}
-
-// Attributes:
-//  - Ok
-//  - Status
-//  - Bootstrapped
-type NodeHealthResult_ struct {
-	Ok           bool   `thrift:"ok,1,required" db:"ok" json:"ok"`
-	Status       string `thrift:"status,2,required" db:"status" json:"status"`
-	Bootstrapped bool   `thrift:"bootstrapped,3,required" db:"bootstrapped" json:"bootstrapped"`
-}
-
-func NewNodeHealthResult_() *NodeHealthResult_ {
-	return &NodeHealthResult_{}
-}
-
-func (p *NodeHealthResult_) GetOk() bool {
-	return p.Ok
-}
-
-func (p *NodeHealthResult_) GetStatus() string {
-	return p.Status
-}
-
-func (p *NodeHealthResult_) GetBootstrapped() bool {
-	return p.Bootstrapped
-}
-func (p *NodeHealthResult_) Read(iprot thrift.TProtocol) error {
-	if _, err := iprot.ReadStructBegin(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
-	}
-
-	var issetOk bool = false
-	var issetStatus bool = false
-	var issetBootstrapped bool = false
-
-	for {
-		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
-		if err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
-		}
-		if fieldTypeId == thrift.STOP {
-			break
-		}
-		switch fieldId {
-		case 1:
-			if err := p.ReadField1(iprot); err != nil {
-				return err
-			}
-			issetOk = true
-		case 2:
-			if err := p.ReadField2(iprot); err != nil {
-				return err
-			}
-			issetStatus = true
-		case 3:
-			if err := p.ReadField3(iprot); err != nil {
-				return err
-			}
-			issetBootstrapped = true
-		default:
-			if err := iprot.Skip(fieldTypeId); err != nil {
-				return err
-			}
-		}
-		if err := iprot.ReadFieldEnd(); err != nil {
-			return err
-		}
-	}
-	if err := iprot.ReadStructEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
-	}
-	if !issetOk {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Ok is not set"))
-	}
-	if !issetStatus {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Status is not set"))
-	}
-	if !issetBootstrapped {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Bootstrapped is not set"))
+func (p *FetchBlocksMetadataRawV2Request) ReadField7(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 7: ", err)
+	} else {
+		p.IncludeSizes = &v
 	}
 	return nil
 }
 
-func (p *NodeHealthResult_) ReadField1(iprot thrift.TProtocol) error {
+func (p *FetchBlocksMetadataRawV2Request) ReadField8(iprot thrift.TProtocol) error {
 	if v, err := iprot.ReadBool(); err != nil {
-		return thrift.PrependError("error reading field 1: ", err)
+		return thrift.PrependError("error reading field 8: ", err)
 	} else {
-		p.Ok = v
+		p.IncludeChecksums = &v
 	}
 	return nil
 }
 
-func (p *NodeHealthResult_) ReadField2(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadString(); err != nil {
-		return thrift.PrependError("error reading field 2: ", err)
+func (p *FetchBlocksMetadataRawV2Request) ReadField9(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 9: ", err)
 	} else {
-		p.Status = v
+		p.IncludeLastRead = &v
 	}
 	return nil
 }
 
-func (p *NodeHealthResult_) ReadField3(iprot thrift.TProtocol) error {
+func (p *FetchBlocksMetadataRawV2Request) ReadField10(iprot thrift.TProtocol) error {
 	if v, err := iprot.ReadBool(); err != nil {
-		return thrift.PrependError("error reading field 3: ", err)
+		return thrift.PrependError("error reading field 10: ", err)
 	} else {
-		p.Bootstrapped = v
+		p.IncludeBufferChecksums = &v
 	}
 	return nil
 }
 
-func (p *NodeHealthResult_) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("NodeHealthResult"); err != nil {
+func (p *FetchBlocksMetadataRawV2Request) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("FetchBlocksMetadataRawV2Request"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -7535,6 +7911,27 @@ func (p *NodeHealthResult_) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField3(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField4(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField5(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField6(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField7(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField8(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField9(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField10(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -7545,140 +7942,184 @@ func (p *NodeHealthResult_) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeHealthResult_) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("ok", thrift.BOOL, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:ok: ", p), err)
+func (p *FetchBlocksMetadataRawV2Request) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("nameSpace", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:nameSpace: ", p), err)
 	}
-	if err := oprot.WriteBool(bool(p.Ok)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.ok (1) field write error: ", p), err)
+	if err := oprot.WriteBinary(p.NameSpace); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.nameSpace (1) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:ok: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:nameSpace: ", p), err)
 	}
 	return err
 }
 
-func (p *NodeHealthResult_) writeField2(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("status", thrift.STRING, 2); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:status: ", p), err)
+func (p *FetchBlocksMetadataRawV2Request) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("shard", thrift.I32, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:shard: ", p), err)
 	}
-	if err := oprot.WriteString(string(p.Status)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.status (2) field write error: ", p), err)
+	if err := oprot.WriteI32(int32(p.Shard)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.shard (2) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:status: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:shard: ", p), err)
 	}
 	return err
 }
 
-func (p *NodeHealthResult_) writeField3(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("bootstrapped", thrift.BOOL, 3); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:bootstrapped: ", p), err)
+func (p *FetchBlocksMetadataRawV2Request) writeField3(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("rangeStart", thrift.I64, 3); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:rangeStart: ", p), err)
 	}
-	if err := oprot.WriteBool(bool(p.Bootstrapped)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.bootstrapped (3) field write error: ", p), err)
+	if err := oprot.WriteI64(int64(p.RangeStart)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.rangeStart (3) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:bootstrapped: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:rangeStart: ", p), err)
 	}
 	return err
 }
 
-func (p *NodeHealthResult_) String() string {
-	if p == nil {
-		return "<nil>"
+func (p *FetchBlocksMetadataRawV2Request) writeField4(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("rangeEnd", thrift.I64, 4); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:rangeEnd: ", p), err)
 	}
-	return fmt.Sprintf("NodeHealthResult_(%+v)", *p)
+	if err := oprot.WriteI64(int64(p.RangeEnd)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.rangeEnd (4) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 4:rangeEnd: ", p), err)
+	}
+	return err
 }
 
-type NodeBootstrappedResult_ struct {
+func (p *FetchBlocksMetadataRawV2Request) writeField5(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("limit", thrift.I64, 5); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 5:limit: ", p), err)
+	}
+	if err := oprot.WriteI64(int64(p.Limit)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.limit (5) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 5:limit: ", p), err)
+	}
+	return err
 }
 
-func NewNodeBootstrappedResult_() *NodeBootstrappedResult_ {
-	return &NodeBootstrappedResult_{}
+func (p *FetchBlocksMetadataRawV2Request) writeField6(oprot thrift.TProtocol) (err error) {
+	if p.IsSetPageToken() {
+		if err := oprot.WriteFieldBegin("pageToken", thrift.STRING, 6); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 6:pageToken: ", p), err)
+		}
+		if err := oprot.WriteBinary(p.PageToken); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.pageToken (6) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 6:pageToken: ", p), err)
+		}
+	}
+	return err
 }
 
-func (p *NodeBootstrappedResult_) Read(iprot thrift.TProtocol) error {
-	if _, err := iprot.ReadStructBegin(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+func (p *FetchBlocksMetadataRawV2Request) writeField7(oprot thrift.TProtocol) (err error) {
+	if p.IsSetIncludeSizes() {
+		if err := oprot.WriteFieldBegin("includeSizes", thrift.BOOL, 7); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 7:includeSizes: ", p), err)
+		}
+		if err := oprot.WriteBool(bool(*p.IncludeSizes)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.includeSizes (7) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 7:includeSizes: ", p), err)
+		}
 	}
+	return err
+}
 
-	for {
-		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
-		if err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
-		}
-		if fieldTypeId == thrift.STOP {
-			break
+func (p *FetchBlocksMetadataRawV2Request) writeField8(oprot thrift.TProtocol) (err error) {
+	if p.IsSetIncludeChecksums() {
+		if err := oprot.WriteFieldBegin("includeChecksums", thrift.BOOL, 8); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 8:includeChecksums: ", p), err)
 		}
-		if err := iprot.Skip(fieldTypeId); err != nil {
-			return err
+		if err := oprot.WriteBool(bool(*p.IncludeChecksums)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.includeChecksums (8) field write error: ", p), err)
 		}
-		if err := iprot.ReadFieldEnd(); err != nil {
-			return err
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 8:includeChecksums: ", p), err)
 		}
 	}
-	if err := iprot.ReadStructEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
-	}
-	return nil
+	return err
 }
 
-func (p *NodeBootstrappedResult_) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("NodeBootstrappedResult"); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
-	}
-	if p != nil {
-	}
-	if err := oprot.WriteFieldStop(); err != nil {
-		return thrift.PrependError("write field stop error: ", err)
+func (p *FetchBlocksMetadataRawV2Request) writeField9(oprot thrift.TProtocol) (err error) {
+	if p.IsSetIncludeLastRead() {
+		if err := oprot.WriteFieldBegin("includeLastRead", thrift.BOOL, 9); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 9:includeLastRead: ", p), err)
+		}
+		if err := oprot.WriteBool(bool(*p.IncludeLastRead)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.includeLastRead (9) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 9:includeLastRead: ", p), err)
+		}
 	}
-	if err := oprot.WriteStructEnd(); err != nil {
-		return thrift.PrependError("write struct stop error: ", err)
+	return err
+}
+
+func (p *FetchBlocksMetadataRawV2Request) writeField10(oprot thrift.TProtocol) (err error) {
+	if p.IsSetIncludeBufferChecksums() {
+		if err := oprot.WriteFieldBegin("includeBufferChecksums", thrift.BOOL, 10); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 10:includeBufferChecksums: ", p), err)
+		}
+		if err := oprot.WriteBool(bool(*p.IncludeBufferChecksums)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.includeBufferChecksums (10) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 10:includeBufferChecksums: ", p), err)
+		}
 	}
-	return nil
+	return err
 }
 
-func (p *NodeBootstrappedResult_) String() string {
+func (p *FetchBlocksMetadataRawV2Request) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeBootstrappedResult_(%+v)", *p)
+	return fmt.Sprintf("FetchBlocksMetadataRawV2Request(%+v)", *p)
 }
 
 // Attributes:
-//  - LimitEnabled
-//  - LimitMbps
-//  - LimitCheckEvery
-type NodePersistRateLimitResult_ struct {
-	LimitEnabled    bool    `thrift:"limitEnabled,1,required" db:"limitEnabled" json:"limitEnabled"`
-	LimitMbps       float64 `thrift:"limitMbps,2,required" db:"limitMbps" json:"limitMbps"`
-	LimitCheckEvery int64   `thrift:"limitCheckEvery,3,required" db:"limitCheckEvery" json:"limitCheckEvery"`
+//  - Elements
+//  - NextPageToken
+type FetchBlocksMetadataRawV2Result_ struct {
+	Elements      []*BlockMetadataV2 `thrift:"elements,1,required" db:"elements" json:"elements"`
+	NextPageToken []byte             `thrift:"nextPageToken,2" db:"nextPageToken" json:"nextPageToken,omitempty"`
 }
 
-func NewNodePersistRateLimitResult_() *NodePersistRateLimitResult_ {
-	return &NodePersistRateLimitResult_{}
+func NewFetchBlocksMetadataRawV2Result_() *FetchBlocksMetadataRawV2Result_ {
+	return &FetchBlocksMetadataRawV2Result_{}
 }
 
-func (p *NodePersistRateLimitResult_) GetLimitEnabled() bool {
-	return p.LimitEnabled
+func (p *FetchBlocksMetadataRawV2Result_) GetElements() []*BlockMetadataV2 {
+	return p.Elements
 }
 
-func (p *NodePersistRateLimitResult_) GetLimitMbps() float64 {
-	return p.LimitMbps
-}
+var FetchBlocksMetadataRawV2Result__NextPageToken_DEFAULT []byte
 
-func (p *NodePersistRateLimitResult_) GetLimitCheckEvery() int64 {
-	return p.LimitCheckEvery
+func (p *FetchBlocksMetadataRawV2Result_) GetNextPageToken() []byte {
+	return p.NextPageToken
 }
-func (p *NodePersistRateLimitResult_) Read(iprot thrift.TProtocol) error {
+func (p *FetchBlocksMetadataRawV2Result_) IsSetNextPageToken() bool {
+	return p.NextPageToken != nil
+}
+
+func (p *FetchBlocksMetadataRawV2Result_) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetLimitEnabled bool = false
-	var issetLimitMbps bool = false
-	var issetLimitCheckEvery bool = false
+	var issetElements bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -7693,17 +8134,11 @@ func (p *NodePersistRateLimitResult_) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetLimitEnabled = true
+			issetElements = true
 		case 2:
 			if err := p.ReadField2(iprot); err != nil {
 				return err
 			}
-			issetLimitMbps = true
-		case 3:
-			if err := p.ReadField3(iprot); err != nil {
-				return err
-			}
-			issetLimitCheckEvery = true
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -7716,47 +8151,45 @@ func (p *NodePersistRateLimitResult_) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetLimitEnabled {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field LimitEnabled is not set"))
-	}
-	if !issetLimitMbps {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field LimitMbps is not set"))
-	}
-	if !issetLimitCheckEvery {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field LimitCheckEvery is not set"))
+	if !issetElements {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Elements is not set"))
 	}
 	return nil
 }
 
-func (p *NodePersistRateLimitResult_) ReadField1(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBool(); err != nil {
-		return thrift.PrependError("error reading field 1: ", err)
-	} else {
-		p.LimitEnabled = v
+func (p *FetchBlocksMetadataRawV2Result_) ReadField1(iprot thrift.TProtocol) error {
+	_, size, err := iprot.ReadListBegin()
+	if err != nil {
+		return thrift.PrependError("error reading list begin: ", err)
 	}
-	return nil
-}
-
-func (p *NodePersistRateLimitResult_) ReadField2(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadDouble(); err != nil {
-		return thrift.PrependError("error reading field 2: ", err)
-	} else {
-		p.LimitMbps = v
+	tSlice := make([]*BlockMetadataV2, 0, size)
+	p.Elements = tSlice
+	for i := 0; i < size; i++ {
+		_elem15 := &BlockMetadataV2{
+			LastReadTimeType: 0,
+		}
+		if err := _elem15.Read(iprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem15), err)
+		}
+		p.Elements = append(p.Elements, _elem15)
+	}
+	if err := iprot.ReadListEnd(); err != nil {
+		return thrift.PrependError("error reading list end: ", err)
 	}
 	return nil
 }
 
-func (p *NodePersistRateLimitResult_) ReadField3(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI64(); err != nil {
-		return thrift.PrependError("error reading field 3: ", err)
+func (p *FetchBlocksMetadataRawV2Result_) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
 	} else {
-		p.LimitCheckEvery = v
+		p.NextPageToken = v
 	}
 	return nil
 }
 
-func (p *NodePersistRateLimitResult_) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("NodePersistRateLimitResult"); err != nil {
+func (p *FetchBlocksMetadataRawV2Result_) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("FetchBlocksMetadataRawV2Result"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -7766,9 +8199,6 @@ func (p *NodePersistRateLimitResult_) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField2(oprot); err != nil {
 			return err
 		}
-		if err := p.writeField3(oprot); err != nil {
-			return err
-		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -7779,109 +8209,177 @@ func (p *NodePersistRateLimitResult_) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodePersistRateLimitResult_) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("limitEnabled", thrift.BOOL, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:limitEnabled: ", p), err)
-	}
-	if err := oprot.WriteBool(bool(p.LimitEnabled)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.limitEnabled (1) field write error: ", p), err)
+func (p *FetchBlocksMetadataRawV2Result_) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("elements", thrift.LIST, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:elements: ", p), err)
 	}
-	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:limitEnabled: ", p), err)
+	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Elements)); err != nil {
+		return thrift.PrependError("error writing list begin: ", err)
 	}
-	return err
-}
-
-func (p *NodePersistRateLimitResult_) writeField2(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("limitMbps", thrift.DOUBLE, 2); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:limitMbps: ", p), err)
+	for _, v := range p.Elements {
+		if err := v.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
+		}
 	}
-	if err := oprot.WriteDouble(float64(p.LimitMbps)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.limitMbps (2) field write error: ", p), err)
+	if err := oprot.WriteListEnd(); err != nil {
+		return thrift.PrependError("error writing list end: ", err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:limitMbps: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:elements: ", p), err)
 	}
 	return err
 }
 
-func (p *NodePersistRateLimitResult_) writeField3(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("limitCheckEvery", thrift.I64, 3); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:limitCheckEvery: ", p), err)
-	}
-	if err := oprot.WriteI64(int64(p.LimitCheckEvery)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.limitCheckEvery (3) field write error: ", p), err)
-	}
-	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:limitCheckEvery: ", p), err)
-	}
+func (p *FetchBlocksMetadataRawV2Result_) writeField2(oprot thrift.TProtocol) (err error) {
+	if p.IsSetNextPageToken() {
+		if err := oprot.WriteFieldBegin("nextPageToken", thrift.STRING, 2); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:nextPageToken: ", p), err)
+		}
+		if err := oprot.WriteBinary(p.NextPageToken); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.nextPageToken (2) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 2:nextPageToken: ", p), err)
+		}
+	}
 	return err
 }
 
-func (p *NodePersistRateLimitResult_) String() string {
+func (p *FetchBlocksMetadataRawV2Result_) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodePersistRateLimitResult_(%+v)", *p)
+	return fmt.Sprintf("FetchBlocksMetadataRawV2Result_(%+v)", *p)
 }
 
 // Attributes:
-//  - LimitEnabled
-//  - LimitMbps
-//  - LimitCheckEvery
-type NodeSetPersistRateLimitRequest struct {
-	LimitEnabled    *bool    `thrift:"limitEnabled,1" db:"limitEnabled" json:"limitEnabled,omitempty"`
-	LimitMbps       *float64 `thrift:"limitMbps,2" db:"limitMbps" json:"limitMbps,omitempty"`
-	LimitCheckEvery *int64   `thrift:"limitCheckEvery,3" db:"limitCheckEvery" json:"limitCheckEvery,omitempty"`
+//  - ID
+//  - Start
+//  - Err
+//  - Size
+//  - Checksum
+//  - LastRead
+//  - LastReadTimeType
+//  - EncodedTags
+//  - Buffered
+type BlockMetadataV2 struct {
+	ID               []byte   `thrift:"id,1,required" db:"id" json:"id"`
+	Start            int64    `thrift:"start,2,required" db:"start" json:"start"`
+	Err              *Error   `thrift:"err,3" db:"err" json:"err,omitempty"`
+	Size             *int64   `thrift:"size,4" db:"size" json:"size,omitempty"`
+	Checksum         *int64   `thrift:"checksum,5" db:"checksum" json:"checksum,omitempty"`
+	LastRead         *int64   `thrift:"lastRead,6" db:"lastRead" json:"lastRead,omitempty"`
+	LastReadTimeType TimeType `thrift:"lastReadTimeType,7" db:"lastReadTimeType" json:"lastReadTimeType,omitempty"`
+	EncodedTags      []byte   `thrift:"encodedTags,8" db:"encodedTags" json:"encodedTags,omitempty"`
+	Buffered         *bool    `thrift:"buffered,9" db:"buffered" json:"buffered,omitempty"`
 }
 
-func NewNodeSetPersistRateLimitRequest() *NodeSetPersistRateLimitRequest {
-	return &NodeSetPersistRateLimitRequest{}
+func NewBlockMetadataV2() *BlockMetadataV2 {
+	return &BlockMetadataV2{
+		LastReadTimeType: 0,
+	}
 }
 
-var NodeSetPersistRateLimitRequest_LimitEnabled_DEFAULT bool
+func (p *BlockMetadataV2) GetID() []byte {
+	return p.ID
+}
 
-func (p *NodeSetPersistRateLimitRequest) GetLimitEnabled() bool {
-	if !p.IsSetLimitEnabled() {
-		return NodeSetPersistRateLimitRequest_LimitEnabled_DEFAULT
+func (p *BlockMetadataV2) GetStart() int64 {
+	return p.Start
+}
+
+var BlockMetadataV2_Err_DEFAULT *Error
+
+func (p *BlockMetadataV2) GetErr() *Error {
+	if !p.IsSetErr() {
+		return BlockMetadataV2_Err_DEFAULT
 	}
-	return *p.LimitEnabled
+	return p.Err
 }
 
-var NodeSetPersistRateLimitRequest_LimitMbps_DEFAULT float64
+var BlockMetadataV2_Size_DEFAULT int64
 
-func (p *NodeSetPersistRateLimitRequest) GetLimitMbps() float64 {
-	if !p.IsSetLimitMbps() {
-		return NodeSetPersistRateLimitRequest_LimitMbps_DEFAULT
+func (p *BlockMetadataV2) GetSize() int64 {
+	if !p.IsSetSize() {
+		return BlockMetadataV2_Size_DEFAULT
 	}
-	return *p.LimitMbps
+	return *p.Size
 }
 
-var NodeSetPersistRateLimitRequest_LimitCheckEvery_DEFAULT int64
+var BlockMetadataV2_Checksum_DEFAULT int64
 
-func (p *NodeSetPersistRateLimitRequest) GetLimitCheckEvery() int64 {
-	if !p.IsSetLimitCheckEvery() {
-		return NodeSetPersistRateLimitRequest_LimitCheckEvery_DEFAULT
+func (p *BlockMetadataV2) GetChecksum() int64 {
+	if !p.IsSetChecksum() {
+		return BlockMetadataV2_Checksum_DEFAULT
 	}
-	return *p.LimitCheckEvery
+	return *p.Checksum
 }
-func (p *NodeSetPersistRateLimitRequest) IsSetLimitEnabled() bool {
-	return p.LimitEnabled != nil
+
+var BlockMetadataV2_LastRead_DEFAULT int64
+
+func (p *BlockMetadataV2) GetLastRead() int64 {
+	if !p.IsSetLastRead() {
+		return BlockMetadataV2_LastRead_DEFAULT
+	}
+	return *p.LastRead
 }
 
-func (p *NodeSetPersistRateLimitRequest) IsSetLimitMbps() bool {
-	return p.LimitMbps != nil
+var BlockMetadataV2_LastReadTimeType_DEFAULT TimeType = 0
+
+func (p *BlockMetadataV2) GetLastReadTimeType() TimeType {
+	return p.LastReadTimeType
 }
 
-func (p *NodeSetPersistRateLimitRequest) IsSetLimitCheckEvery() bool {
-	return p.LimitCheckEvery != nil
+var BlockMetadataV2_EncodedTags_DEFAULT []byte
+
+func (p *BlockMetadataV2) GetEncodedTags() []byte {
+	return p.EncodedTags
 }
 
-func (p *NodeSetPersistRateLimitRequest) Read(iprot thrift.TProtocol) error {
+var BlockMetadataV2_Buffered_DEFAULT bool
+
+func (p *BlockMetadataV2) GetBuffered() bool {
+	if !p.IsSetBuffered() {
+		return BlockMetadataV2_Buffered_DEFAULT
+	}
+	return *p.Buffered
+}
+func (p *BlockMetadataV2) IsSetErr() bool {
+	return p.Err != nil
+}
+
+func (p *BlockMetadataV2) IsSetSize() bool {
+	return p.Size != nil
+}
+
+func (p *BlockMetadataV2) IsSetChecksum() bool {
+	return p.Checksum != nil
+}
+
+func (p *BlockMetadataV2) IsSetLastRead() bool {
+	return p.LastRead != nil
+}
+
+func (p *BlockMetadataV2) IsSetLastReadTimeType() bool {
+	return p.LastReadTimeType != BlockMetadataV2_LastReadTimeType_DEFAULT
+}
+
+func (p *BlockMetadataV2) IsSetEncodedTags() bool {
+	return p.EncodedTags != nil
+}
+
+func (p *BlockMetadataV2) IsSetBuffered() bool {
+	return p.Buffered != nil
+}
+
+func (p *BlockMetadataV2) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
+	var issetID bool = false
+	var issetStart bool = false
+
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
 		if err != nil {
@@ -7895,14 +8393,40 @@ func (p *NodeSetPersistRateLimitRequest) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
+			issetID = true
 		case 2:
 			if err := p.ReadField2(iprot); err != nil {
 				return err
 			}
+			issetStart = true
 		case 3:
 			if err := p.ReadField3(iprot); err != nil {
 				return err
 			}
+		case 4:
+			if err := p.ReadField4(iprot); err != nil {
+				return err
+			}
+		case 5:
+			if err := p.ReadField5(iprot); err != nil {
+				return err
+			}
+		case 6:
+			if err := p.ReadField6(iprot); err != nil {
+				return err
+			}
+		case 7:
+			if err := p.ReadField7(iprot); err != nil {
+				return err
+			}
+		case 8:
+			if err := p.ReadField8(iprot); err != nil {
+				return err
+			}
+		case 9:
+			if err := p.ReadField9(iprot); err != nil {
+				return err
+			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -7915,38 +8439,100 @@ func (p *NodeSetPersistRateLimitRequest) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
+	if !issetID {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field ID is not set"))
+	}
+	if !issetStart {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Start is not set"))
+	}
 	return nil
 }
 
-func (p *NodeSetPersistRateLimitRequest) ReadField1(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBool(); err != nil {
+func (p *BlockMetadataV2) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
 		return thrift.PrependError("error reading field 1: ", err)
 	} else {
-		p.LimitEnabled = &v
+		p.ID = v
 	}
 	return nil
 }
 
-func (p *NodeSetPersistRateLimitRequest) ReadField2(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadDouble(); err != nil {
+func (p *BlockMetadataV2) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
 		return thrift.PrependError("error reading field 2: ", err)
 	} else {
-		p.LimitMbps = &v
+		p.Start = v
 	}
 	return nil
 }
 
-func (p *NodeSetPersistRateLimitRequest) ReadField3(iprot thrift.TProtocol) error {
+func (p *BlockMetadataV2) ReadField3(iprot thrift.TProtocol) error {
+	p.Err = &Error{
+		Type: 0,
+	}
+	if err := p.Err.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Err), err)
+	}
+	return nil
+}
+
+func (p *BlockMetadataV2) ReadField4(iprot thrift.TProtocol) error {
 	if v, err := iprot.ReadI64(); err != nil {
-		return thrift.PrependError("error reading field 3: ", err)
+		return thrift.PrependError("error reading field 4: ", err)
 	} else {
-		p.LimitCheckEvery = &v
+		p.Size = &v
 	}
 	return nil
 }
 
-func (p *NodeSetPersistRateLimitRequest) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("NodeSetPersistRateLimitRequest"); err != nil {
+func (p *BlockMetadataV2) ReadField5(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 5: ", err)
+	} else {
+		p.Checksum = &v
+	}
+	return nil
+}
+
+func (p *BlockMetadataV2) ReadField6(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 6: ", err)
+	} else {
+		p.LastRead = &v
+	}
+	return nil
+}
+
+func (p *BlockMetadataV2) ReadField7(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI32(); err != nil {
+		return thrift.PrependError("error reading field 7: ", err)
+	} else {
+		temp := TimeType(v)
+		p.LastReadTimeType = temp
+	}
+	return nil
+}
+
+func (p *BlockMetadataV2) ReadField8(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
+		return thrift.PrependError("error reading field 8: ", err)
+	} else {
+		p.EncodedTags = v
+	}
+	return nil
+}
+
+func (p *BlockMetadataV2) ReadField9(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 9: ", err)
+	} else {
+		p.Buffered = &v
+	}
+	return nil
+}
+
+func (p *BlockMetadataV2) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("BlockMetadataV2"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -7959,87 +8545,198 @@ func (p *NodeSetPersistRateLimitRequest) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField3(oprot); err != nil {
 			return err
 		}
-	}
-	if err := oprot.WriteFieldStop(); err != nil {
-		return thrift.PrependError("write field stop error: ", err)
-	}
-	if err := oprot.WriteStructEnd(); err != nil {
-		return thrift.PrependError("write struct stop error: ", err)
-	}
-	return nil
+		if err := p.writeField4(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField5(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField6(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField7(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField8(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField9(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
 }
 
-func (p *NodeSetPersistRateLimitRequest) writeField1(oprot thrift.TProtocol) (err error) {
-	if p.IsSetLimitEnabled() {
-		if err := oprot.WriteFieldBegin("limitEnabled", thrift.BOOL, 1); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:limitEnabled: ", p), err)
+func (p *BlockMetadataV2) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("id", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:id: ", p), err)
+	}
+	if err := oprot.WriteBinary(p.ID); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.id (1) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:id: ", p), err)
+	}
+	return err
+}
+
+func (p *BlockMetadataV2) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("start", thrift.I64, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:start: ", p), err)
+	}
+	if err := oprot.WriteI64(int64(p.Start)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.start (2) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:start: ", p), err)
+	}
+	return err
+}
+
+func (p *BlockMetadataV2) writeField3(oprot thrift.TProtocol) (err error) {
+	if p.IsSetErr() {
+		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 3); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:err: ", p), err)
 		}
-		if err := oprot.WriteBool(bool(*p.LimitEnabled)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.limitEnabled (1) field write error: ", p), err)
+		if err := p.Err.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Err), err)
 		}
 		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 1:limitEnabled: ", p), err)
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 3:err: ", p), err)
 		}
 	}
 	return err
 }
 
-func (p *NodeSetPersistRateLimitRequest) writeField2(oprot thrift.TProtocol) (err error) {
-	if p.IsSetLimitMbps() {
-		if err := oprot.WriteFieldBegin("limitMbps", thrift.DOUBLE, 2); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:limitMbps: ", p), err)
+func (p *BlockMetadataV2) writeField4(oprot thrift.TProtocol) (err error) {
+	if p.IsSetSize() {
+		if err := oprot.WriteFieldBegin("size", thrift.I64, 4); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:size: ", p), err)
 		}
-		if err := oprot.WriteDouble(float64(*p.LimitMbps)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.limitMbps (2) field write error: ", p), err)
+		if err := oprot.WriteI64(int64(*p.Size)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.size (4) field write error: ", p), err)
 		}
 		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 2:limitMbps: ", p), err)
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 4:size: ", p), err)
 		}
 	}
 	return err
 }
 
-func (p *NodeSetPersistRateLimitRequest) writeField3(oprot thrift.TProtocol) (err error) {
-	if p.IsSetLimitCheckEvery() {
-		if err := oprot.WriteFieldBegin("limitCheckEvery", thrift.I64, 3); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:limitCheckEvery: ", p), err)
+func (p *BlockMetadataV2) writeField5(oprot thrift.TProtocol) (err error) {
+	if p.IsSetChecksum() {
+		if err := oprot.WriteFieldBegin("checksum", thrift.I64, 5); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 5:checksum: ", p), err)
 		}
-		if err := oprot.WriteI64(int64(*p.LimitCheckEvery)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.limitCheckEvery (3) field write error: ", p), err)
+		if err := oprot.WriteI64(int64(*p.Checksum)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.checksum (5) field write error: ", p), err)
 		}
 		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 3:limitCheckEvery: ", p), err)
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 5:checksum: ", p), err)
 		}
 	}
 	return err
 }
 
-func (p *NodeSetPersistRateLimitRequest) String() string {
+func (p *BlockMetadataV2) writeField6(oprot thrift.TProtocol) (err error) {
+	if p.IsSetLastRead() {
+		if err := oprot.WriteFieldBegin("lastRead", thrift.I64, 6); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 6:lastRead: ", p), err)
+		}
+		if err := oprot.WriteI64(int64(*p.LastRead)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.lastRead (6) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 6:lastRead: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *BlockMetadataV2) writeField7(oprot thrift.TProtocol) (err error) {
+	if p.IsSetLastReadTimeType() {
+		if err := oprot.WriteFieldBegin("lastReadTimeType", thrift.I32, 7); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 7:lastReadTimeType: ", p), err)
+		}
+		if err := oprot.WriteI32(int32(p.LastReadTimeType)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.lastReadTimeType (7) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 7:lastReadTimeType: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *BlockMetadataV2) writeField8(oprot thrift.TProtocol) (err error) {
+	if p.IsSetEncodedTags() {
+		if err := oprot.WriteFieldBegin("encodedTags", thrift.STRING, 8); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 8:encodedTags: ", p), err)
+		}
+		if err := oprot.WriteBinary(p.EncodedTags); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.encodedTags (8) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 8:encodedTags: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *BlockMetadataV2) writeField9(oprot thrift.TProtocol) (err error) {
+	if p.IsSetBuffered() {
+		if err := oprot.WriteFieldBegin("buffered", thrift.BOOL, 9); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 9:buffered: ", p), err)
+		}
+		if err := oprot.WriteBool(bool(*p.Buffered)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.buffered (9) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 9:buffered: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *BlockMetadataV2) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeSetPersistRateLimitRequest(%+v)", *p)
+	return fmt.Sprintf("BlockMetadataV2(%+v)", *p)
 }
 
 // Attributes:
-//  - WriteNewSeriesAsync
-type NodeWriteNewSeriesAsyncResult_ struct {
-	WriteNewSeriesAsync bool `thrift:"writeNewSeriesAsync,1,required" db:"writeNewSeriesAsync" json:"writeNewSeriesAsync"`
+//  - NameSpace
+//  - Elements
+type WriteBatchRawRequest struct {
+	NameSpace []byte                         `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
+	Elements  []*WriteBatchRawRequestElement `thrift:"elements,2,required" db:"elements" json:"elements"`
 }
 
-func NewNodeWriteNewSeriesAsyncResult_() *NodeWriteNewSeriesAsyncResult_ {
-	return &NodeWriteNewSeriesAsyncResult_{}
+func NewWriteBatchRawRequest() *WriteBatchRawRequest {
+	return &WriteBatchRawRequest{}
 }
 
-func (p *NodeWriteNewSeriesAsyncResult_) GetWriteNewSeriesAsync() bool {
-	return p.WriteNewSeriesAsync
+func (p *WriteBatchRawRequest) GetNameSpace() []byte {
+	return p.NameSpace
 }
-func (p *NodeWriteNewSeriesAsyncResult_) Read(iprot thrift.TProtocol) error {
+
+func (p *WriteBatchRawRequest) GetElements() []*WriteBatchRawRequestElement {
+	return p.Elements
+}
+func (p *WriteBatchRawRequest) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetWriteNewSeriesAsync bool = false
+	var issetNameSpace bool = false
+	var issetElements bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -8054,7 +8751,12 @@ func (p *NodeWriteNewSeriesAsyncResult_) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetWriteNewSeriesAsync = true
+			issetNameSpace = true
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
+				return err
+			}
+			issetElements = true
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -8067,29 +8769,55 @@ func (p *NodeWriteNewSeriesAsyncResult_) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetWriteNewSeriesAsync {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field WriteNewSeriesAsync is not set"))
+	if !issetNameSpace {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field NameSpace is not set"))
+	}
+	if !issetElements {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Elements is not set"))
 	}
 	return nil
 }
 
-func (p *NodeWriteNewSeriesAsyncResult_) ReadField1(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBool(); err != nil {
+func (p *WriteBatchRawRequest) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
 		return thrift.PrependError("error reading field 1: ", err)
 	} else {
-		p.WriteNewSeriesAsync = v
+		p.NameSpace = v
 	}
 	return nil
 }
 
-func (p *NodeWriteNewSeriesAsyncResult_) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("NodeWriteNewSeriesAsyncResult"); err != nil {
+func (p *WriteBatchRawRequest) ReadField2(iprot thrift.TProtocol) error {
+	_, size, err := iprot.ReadListBegin()
+	if err != nil {
+		return thrift.PrependError("error reading list begin: ", err)
+	}
+	tSlice := make([]*WriteBatchRawRequestElement, 0, size)
+	p.Elements = tSlice
+	for i := 0; i < size; i++ {
+		_elem16 := &WriteBatchRawRequestElement{}
+		if err := _elem16.Read(iprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem16), err)
+		}
+		p.Elements = append(p.Elements, _elem16)
+	}
+	if err := iprot.ReadListEnd(); err != nil {
+		return thrift.PrependError("error reading list end: ", err)
+	}
+	return nil
+}
+
+func (p *WriteBatchRawRequest) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("WriteBatchRawRequest"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
 		if err := p.writeField1(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField2(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -8100,45 +8828,82 @@ func (p *NodeWriteNewSeriesAsyncResult_) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeWriteNewSeriesAsyncResult_) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("writeNewSeriesAsync", thrift.BOOL, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:writeNewSeriesAsync: ", p), err)
+func (p *WriteBatchRawRequest) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("nameSpace", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:nameSpace: ", p), err)
 	}
-	if err := oprot.WriteBool(bool(p.WriteNewSeriesAsync)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.writeNewSeriesAsync (1) field write error: ", p), err)
+	if err := oprot.WriteBinary(p.NameSpace); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.nameSpace (1) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:writeNewSeriesAsync: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:nameSpace: ", p), err)
 	}
 	return err
 }
 
-func (p *NodeWriteNewSeriesAsyncResult_) String() string {
-	if p == nil {
-		return "<nil>"
+func (p *WriteBatchRawRequest) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("elements", thrift.LIST, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:elements: ", p), err)
 	}
-	return fmt.Sprintf("NodeWriteNewSeriesAsyncResult_(%+v)", *p)
-}
-
-// Attributes:
-//  - WriteNewSeriesAsync
-type NodeSetWriteNewSeriesAsyncRequest struct {
-	WriteNewSeriesAsync bool `thrift:"writeNewSeriesAsync,1,required" db:"writeNewSeriesAsync" json:"writeNewSeriesAsync"`
-}
-
-func NewNodeSetWriteNewSeriesAsyncRequest() *NodeSetWriteNewSeriesAsyncRequest {
-	return &NodeSetWriteNewSeriesAsyncRequest{}
-}
-
-func (p *NodeSetWriteNewSeriesAsyncRequest) GetWriteNewSeriesAsync() bool {
-	return p.WriteNewSeriesAsync
+	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Elements)); err != nil {
+		return thrift.PrependError("error writing list begin: ", err)
+	}
+	for _, v := range p.Elements {
+		if err := v.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
+		}
+	}
+	if err := oprot.WriteListEnd(); err != nil {
+		return thrift.PrependError("error writing list end: ", err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:elements: ", p), err)
+	}
+	return err
 }
-func (p *NodeSetWriteNewSeriesAsyncRequest) Read(iprot thrift.TProtocol) error {
+
+func (p *WriteBatchRawRequest) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("WriteBatchRawRequest(%+v)", *p)
+}
+
+// Attributes:
+//  - ID
+//  - Datapoint
+type WriteBatchRawRequestElement struct {
+	ID        []byte     `thrift:"id,1,required" db:"id" json:"id"`
+	Datapoint *Datapoint `thrift:"datapoint,2,required" db:"datapoint" json:"datapoint"`
+}
+
+func NewWriteBatchRawRequestElement() *WriteBatchRawRequestElement {
+	return &WriteBatchRawRequestElement{}
+}
+
+func (p *WriteBatchRawRequestElement) GetID() []byte {
+	return p.ID
+}
+
+var WriteBatchRawRequestElement_Datapoint_DEFAULT *Datapoint
+
+func (p *WriteBatchRawRequestElement) GetDatapoint() *Datapoint {
+	if !p.IsSetDatapoint() {
+		return WriteBatchRawRequestElement_Datapoint_DEFAULT
+	}
+	return p.Datapoint
+}
+func (p *WriteBatchRawRequestElement) IsSetDatapoint() bool {
+	return p.Datapoint != nil
+}
+
+func (p *WriteBatchRawRequestElement) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetWriteNewSeriesAsync bool = false
+	var issetID bool = false
+	var issetDatapoint bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -8153,7 +8918,12 @@ func (p *NodeSetWriteNewSeriesAsyncRequest) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetWriteNewSeriesAsync = true
+			issetID = true
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
+				return err
+			}
+			issetDatapoint = true
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -8166,29 +8936,45 @@ func (p *NodeSetWriteNewSeriesAsyncRequest) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetWriteNewSeriesAsync {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field WriteNewSeriesAsync is not set"))
+	if !issetID {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field ID is not set"))
+	}
+	if !issetDatapoint {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Datapoint is not set"))
 	}
 	return nil
 }
 
-func (p *NodeSetWriteNewSeriesAsyncRequest) ReadField1(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBool(); err != nil {
+func (p *WriteBatchRawRequestElement) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
 		return thrift.PrependError("error reading field 1: ", err)
 	} else {
-		p.WriteNewSeriesAsync = v
+		p.ID = v
 	}
 	return nil
 }
 
-func (p *NodeSetWriteNewSeriesAsyncRequest) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("NodeSetWriteNewSeriesAsyncRequest"); err != nil {
+func (p *WriteBatchRawRequestElement) ReadField2(iprot thrift.TProtocol) error {
+	p.Datapoint = &Datapoint{
+		TimestampTimeType: 0,
+	}
+	if err := p.Datapoint.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Datapoint), err)
+	}
+	return nil
+}
+
+func (p *WriteBatchRawRequestElement) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("WriteBatchRawRequestElement"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
 		if err := p.writeField1(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField2(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -8199,52 +8985,65 @@ func (p *NodeSetWriteNewSeriesAsyncRequest) Write(oprot thrift.TProtocol) error
 	return nil
 }
 
-func (p *NodeSetWriteNewSeriesAsyncRequest) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("writeNewSeriesAsync", thrift.BOOL, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:writeNewSeriesAsync: ", p), err)
+func (p *WriteBatchRawRequestElement) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("id", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:id: ", p), err)
 	}
-	if err := oprot.WriteBool(bool(p.WriteNewSeriesAsync)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.writeNewSeriesAsync (1) field write error: ", p), err)
+	if err := oprot.WriteBinary(p.ID); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.id (1) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:writeNewSeriesAsync: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:id: ", p), err)
 	}
 	return err
 }
 
-func (p *NodeSetWriteNewSeriesAsyncRequest) String() string {
+func (p *WriteBatchRawRequestElement) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("datapoint", thrift.STRUCT, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:datapoint: ", p), err)
+	}
+	if err := p.Datapoint.Write(oprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Datapoint), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:datapoint: ", p), err)
+	}
+	return err
+}
+
+func (p *WriteBatchRawRequestElement) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeSetWriteNewSeriesAsyncRequest(%+v)", *p)
+	return fmt.Sprintf("WriteBatchRawRequestElement(%+v)", *p)
 }
 
 // Attributes:
-//  - WriteNewSeriesBackoffDuration
-//  - DurationType
-type NodeWriteNewSeriesBackoffDurationResult_ struct {
-	WriteNewSeriesBackoffDuration int64    `thrift:"writeNewSeriesBackoffDuration,1,required" db:"writeNewSeriesBackoffDuration" json:"writeNewSeriesBackoffDuration"`
-	DurationType                  TimeType `thrift:"durationType,2,required" db:"durationType" json:"durationType"`
+//  - NameSpace
+//  - Elements
+type WriteTaggedBatchRawRequest struct {
+	NameSpace []byte                               `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
+	Elements  []*WriteTaggedBatchRawRequestElement `thrift:"elements,2,required" db:"elements" json:"elements"`
 }
 
-func NewNodeWriteNewSeriesBackoffDurationResult_() *NodeWriteNewSeriesBackoffDurationResult_ {
-	return &NodeWriteNewSeriesBackoffDurationResult_{}
+func NewWriteTaggedBatchRawRequest() *WriteTaggedBatchRawRequest {
+	return &WriteTaggedBatchRawRequest{}
 }
 
-func (p *NodeWriteNewSeriesBackoffDurationResult_) GetWriteNewSeriesBackoffDuration() int64 {
-	return p.WriteNewSeriesBackoffDuration
+func (p *WriteTaggedBatchRawRequest) GetNameSpace() []byte {
+	return p.NameSpace
 }
 
-func (p *NodeWriteNewSeriesBackoffDurationResult_) GetDurationType() TimeType {
-	return p.DurationType
+func (p *WriteTaggedBatchRawRequest) GetElements() []*WriteTaggedBatchRawRequestElement {
+	return p.Elements
 }
-func (p *NodeWriteNewSeriesBackoffDurationResult_) Read(iprot thrift.TProtocol) error {
+func (p *WriteTaggedBatchRawRequest) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetWriteNewSeriesBackoffDuration bool = false
-	var issetDurationType bool = false
+	var issetNameSpace bool = false
+	var issetElements bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -8259,12 +9058,12 @@ func (p *NodeWriteNewSeriesBackoffDurationResult_) Read(iprot thrift.TProtocol)
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetWriteNewSeriesBackoffDuration = true
+			issetNameSpace = true
 		case 2:
 			if err := p.ReadField2(iprot); err != nil {
 				return err
 			}
-			issetDurationType = true
+			issetElements = true
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -8277,36 +9076,46 @@ func (p *NodeWriteNewSeriesBackoffDurationResult_) Read(iprot thrift.TProtocol)
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetWriteNewSeriesBackoffDuration {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field WriteNewSeriesBackoffDuration is not set"))
+	if !issetNameSpace {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field NameSpace is not set"))
 	}
-	if !issetDurationType {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field DurationType is not set"))
+	if !issetElements {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Elements is not set"))
 	}
 	return nil
 }
 
-func (p *NodeWriteNewSeriesBackoffDurationResult_) ReadField1(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI64(); err != nil {
+func (p *WriteTaggedBatchRawRequest) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
 		return thrift.PrependError("error reading field 1: ", err)
 	} else {
-		p.WriteNewSeriesBackoffDuration = v
+		p.NameSpace = v
 	}
 	return nil
 }
 
-func (p *NodeWriteNewSeriesBackoffDurationResult_) ReadField2(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI32(); err != nil {
-		return thrift.PrependError("error reading field 2: ", err)
-	} else {
-		temp := TimeType(v)
-		p.DurationType = temp
+func (p *WriteTaggedBatchRawRequest) ReadField2(iprot thrift.TProtocol) error {
+	_, size, err := iprot.ReadListBegin()
+	if err != nil {
+		return thrift.PrependError("error reading list begin: ", err)
+	}
+	tSlice := make([]*WriteTaggedBatchRawRequestElement, 0, size)
+	p.Elements = tSlice
+	for i := 0; i < size; i++ {
+		_elem17 := &WriteTaggedBatchRawRequestElement{}
+		if err := _elem17.Read(iprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem17), err)
+		}
+		p.Elements = append(p.Elements, _elem17)
+	}
+	if err := iprot.ReadListEnd(); err != nil {
+		return thrift.PrependError("error reading list end: ", err)
 	}
 	return nil
 }
 
-func (p *NodeWriteNewSeriesBackoffDurationResult_) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("NodeWriteNewSeriesBackoffDurationResult"); err != nil {
+func (p *WriteTaggedBatchRawRequest) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("WriteTaggedBatchRawRequest"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -8326,72 +9135,89 @@ func (p *NodeWriteNewSeriesBackoffDurationResult_) Write(oprot thrift.TProtocol)
 	return nil
 }
 
-func (p *NodeWriteNewSeriesBackoffDurationResult_) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("writeNewSeriesBackoffDuration", thrift.I64, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:writeNewSeriesBackoffDuration: ", p), err)
+func (p *WriteTaggedBatchRawRequest) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("nameSpace", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:nameSpace: ", p), err)
 	}
-	if err := oprot.WriteI64(int64(p.WriteNewSeriesBackoffDuration)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.writeNewSeriesBackoffDuration (1) field write error: ", p), err)
+	if err := oprot.WriteBinary(p.NameSpace); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.nameSpace (1) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:writeNewSeriesBackoffDuration: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:nameSpace: ", p), err)
 	}
 	return err
 }
 
-func (p *NodeWriteNewSeriesBackoffDurationResult_) writeField2(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("durationType", thrift.I32, 2); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:durationType: ", p), err)
+func (p *WriteTaggedBatchRawRequest) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("elements", thrift.LIST, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:elements: ", p), err)
 	}
-	if err := oprot.WriteI32(int32(p.DurationType)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.durationType (2) field write error: ", p), err)
+	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Elements)); err != nil {
+		return thrift.PrependError("error writing list begin: ", err)
+	}
+	for _, v := range p.Elements {
+		if err := v.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
+		}
+	}
+	if err := oprot.WriteListEnd(); err != nil {
+		return thrift.PrependError("error writing list end: ", err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:durationType: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:elements: ", p), err)
 	}
 	return err
 }
 
-func (p *NodeWriteNewSeriesBackoffDurationResult_) String() string {
+func (p *WriteTaggedBatchRawRequest) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeWriteNewSeriesBackoffDurationResult_(%+v)", *p)
+	return fmt.Sprintf("WriteTaggedBatchRawRequest(%+v)", *p)
 }
 
 // Attributes:
-//  - WriteNewSeriesBackoffDuration
-//  - DurationType
-type NodeSetWriteNewSeriesBackoffDurationRequest struct {
-	WriteNewSeriesBackoffDuration int64    `thrift:"writeNewSeriesBackoffDuration,1,required" db:"writeNewSeriesBackoffDuration" json:"writeNewSeriesBackoffDuration"`
-	DurationType                  TimeType `thrift:"durationType,2" db:"durationType" json:"durationType,omitempty"`
+//  - ID
+//  - EncodedTags
+//  - Datapoint
+type WriteTaggedBatchRawRequestElement struct {
+	ID          []byte     `thrift:"id,1,required" db:"id" json:"id"`
+	EncodedTags []byte     `thrift:"encodedTags,2,required" db:"encodedTags" json:"encodedTags"`
+	Datapoint   *Datapoint `thrift:"datapoint,3,required" db:"datapoint" json:"datapoint"`
 }
 
-func NewNodeSetWriteNewSeriesBackoffDurationRequest() *NodeSetWriteNewSeriesBackoffDurationRequest {
-	return &NodeSetWriteNewSeriesBackoffDurationRequest{
-		DurationType: 2,
-	}
+func NewWriteTaggedBatchRawRequestElement() *WriteTaggedBatchRawRequestElement {
+	return &WriteTaggedBatchRawRequestElement{}
 }
 
-func (p *NodeSetWriteNewSeriesBackoffDurationRequest) GetWriteNewSeriesBackoffDuration() int64 {
-	return p.WriteNewSeriesBackoffDuration
+func (p *WriteTaggedBatchRawRequestElement) GetID() []byte {
+	return p.ID
 }
 
-var NodeSetWriteNewSeriesBackoffDurationRequest_DurationType_DEFAULT TimeType = 2
+func (p *WriteTaggedBatchRawRequestElement) GetEncodedTags() []byte {
+	return p.EncodedTags
+}
 
-func (p *NodeSetWriteNewSeriesBackoffDurationRequest) GetDurationType() TimeType {
-	return p.DurationType
+var WriteTaggedBatchRawRequestElement_Datapoint_DEFAULT *Datapoint
+
+func (p *WriteTaggedBatchRawRequestElement) GetDatapoint() *Datapoint {
+	if !p.IsSetDatapoint() {
+		return WriteTaggedBatchRawRequestElement_Datapoint_DEFAULT
+	}
+	return p.Datapoint
 }
-func (p *NodeSetWriteNewSeriesBackoffDurationRequest) IsSetDurationType() bool {
-	return p.DurationType != NodeSetWriteNewSeriesBackoffDurationRequest_DurationType_DEFAULT
+func (p *WriteTaggedBatchRawRequestElement) IsSetDatapoint() bool {
+	return p.Datapoint != nil
 }
 
-func (p *NodeSetWriteNewSeriesBackoffDurationRequest) Read(iprot thrift.TProtocol) error {
+func (p *WriteTaggedBatchRawRequestElement) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetWriteNewSeriesBackoffDuration bool = false
+	var issetID bool = false
+	var issetEncodedTags bool = false
+	var issetDatapoint bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -8406,11 +9232,17 @@ func (p *NodeSetWriteNewSeriesBackoffDurationRequest) Read(iprot thrift.TProtoco
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetWriteNewSeriesBackoffDuration = true
+			issetID = true
 		case 2:
 			if err := p.ReadField2(iprot); err != nil {
 				return err
 			}
+			issetEncodedTags = true
+		case 3:
+			if err := p.ReadField3(iprot); err != nil {
+				return err
+			}
+			issetDatapoint = true
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -8423,33 +9255,48 @@ func (p *NodeSetWriteNewSeriesBackoffDurationRequest) Read(iprot thrift.TProtoco
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetWriteNewSeriesBackoffDuration {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field WriteNewSeriesBackoffDuration is not set"))
+	if !issetID {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field ID is not set"))
+	}
+	if !issetEncodedTags {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field EncodedTags is not set"))
+	}
+	if !issetDatapoint {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Datapoint is not set"))
 	}
 	return nil
 }
 
-func (p *NodeSetWriteNewSeriesBackoffDurationRequest) ReadField1(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI64(); err != nil {
+func (p *WriteTaggedBatchRawRequestElement) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
 		return thrift.PrependError("error reading field 1: ", err)
 	} else {
-		p.WriteNewSeriesBackoffDuration = v
+		p.ID = v
 	}
 	return nil
 }
 
-func (p *NodeSetWriteNewSeriesBackoffDurationRequest) ReadField2(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI32(); err != nil {
+func (p *WriteTaggedBatchRawRequestElement) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
 		return thrift.PrependError("error reading field 2: ", err)
 	} else {
-		temp := TimeType(v)
-		p.DurationType = temp
+		p.EncodedTags = v
 	}
 	return nil
 }
 
-func (p *NodeSetWriteNewSeriesBackoffDurationRequest) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("NodeSetWriteNewSeriesBackoffDurationRequest"); err != nil {
+func (p *WriteTaggedBatchRawRequestElement) ReadField3(iprot thrift.TProtocol) error {
+	p.Datapoint = &Datapoint{
+		TimestampTimeType: 0,
+	}
+	if err := p.Datapoint.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Datapoint), err)
+	}
+	return nil
+}
+
+func (p *WriteTaggedBatchRawRequestElement) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("WriteTaggedBatchRawRequestElement"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -8459,6 +9306,9 @@ func (p *NodeSetWriteNewSeriesBackoffDurationRequest) Write(oprot thrift.TProtoc
 		if err := p.writeField2(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField3(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -8469,60 +9319,87 @@ func (p *NodeSetWriteNewSeriesBackoffDurationRequest) Write(oprot thrift.TProtoc
 	return nil
 }
 
-func (p *NodeSetWriteNewSeriesBackoffDurationRequest) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("writeNewSeriesBackoffDuration", thrift.I64, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:writeNewSeriesBackoffDuration: ", p), err)
+func (p *WriteTaggedBatchRawRequestElement) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("id", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:id: ", p), err)
 	}
-	if err := oprot.WriteI64(int64(p.WriteNewSeriesBackoffDuration)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.writeNewSeriesBackoffDuration (1) field write error: ", p), err)
+	if err := oprot.WriteBinary(p.ID); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.id (1) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:writeNewSeriesBackoffDuration: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:id: ", p), err)
 	}
 	return err
 }
 
-func (p *NodeSetWriteNewSeriesBackoffDurationRequest) writeField2(oprot thrift.TProtocol) (err error) {
-	if p.IsSetDurationType() {
-		if err := oprot.WriteFieldBegin("durationType", thrift.I32, 2); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:durationType: ", p), err)
-		}
-		if err := oprot.WriteI32(int32(p.DurationType)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.durationType (2) field write error: ", p), err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 2:durationType: ", p), err)
-		}
+func (p *WriteTaggedBatchRawRequestElement) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("encodedTags", thrift.STRING, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:encodedTags: ", p), err)
+	}
+	if err := oprot.WriteBinary(p.EncodedTags); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.encodedTags (2) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:encodedTags: ", p), err)
 	}
 	return err
 }
 
-func (p *NodeSetWriteNewSeriesBackoffDurationRequest) String() string {
+func (p *WriteTaggedBatchRawRequestElement) writeField3(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("datapoint", thrift.STRUCT, 3); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:datapoint: ", p), err)
+	}
+	if err := p.Datapoint.Write(oprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Datapoint), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:datapoint: ", p), err)
+	}
+	return err
+}
+
+func (p *WriteTaggedBatchRawRequestElement) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeSetWriteNewSeriesBackoffDurationRequest(%+v)", *p)
+	return fmt.Sprintf("WriteTaggedBatchRawRequestElement(%+v)", *p)
 }
 
 // Attributes:
-//  - WriteNewSeriesLimitPerShardPerSecond
-type NodeWriteNewSeriesLimitPerShardPerSecondResult_ struct {
-	WriteNewSeriesLimitPerShardPerSecond int64 `thrift:"writeNewSeriesLimitPerShardPerSecond,1,required" db:"writeNewSeriesLimitPerShardPerSecond" json:"writeNewSeriesLimitPerShardPerSecond"`
+//  - Index
+//  - Err
+type WriteBatchRawError struct {
+	Index int64  `thrift:"index,1,required" db:"index" json:"index"`
+	Err   *Error `thrift:"err,2,required" db:"err" json:"err"`
 }
 
-func NewNodeWriteNewSeriesLimitPerShardPerSecondResult_() *NodeWriteNewSeriesLimitPerShardPerSecondResult_ {
-	return &NodeWriteNewSeriesLimitPerShardPerSecondResult_{}
+func NewWriteBatchRawError() *WriteBatchRawError {
+	return &WriteBatchRawError{}
 }
 
-func (p *NodeWriteNewSeriesLimitPerShardPerSecondResult_) GetWriteNewSeriesLimitPerShardPerSecond() int64 {
-	return p.WriteNewSeriesLimitPerShardPerSecond
+func (p *WriteBatchRawError) GetIndex() int64 {
+	return p.Index
 }
-func (p *NodeWriteNewSeriesLimitPerShardPerSecondResult_) Read(iprot thrift.TProtocol) error {
+
+var WriteBatchRawError_Err_DEFAULT *Error
+
+func (p *WriteBatchRawError) GetErr() *Error {
+	if !p.IsSetErr() {
+		return WriteBatchRawError_Err_DEFAULT
+	}
+	return p.Err
+}
+func (p *WriteBatchRawError) IsSetErr() bool {
+	return p.Err != nil
+}
+
+func (p *WriteBatchRawError) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetWriteNewSeriesLimitPerShardPerSecond bool = false
+	var issetIndex bool = false
+	var issetErr bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -8537,7 +9414,12 @@ func (p *NodeWriteNewSeriesLimitPerShardPerSecondResult_) Read(iprot thrift.TPro
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetWriteNewSeriesLimitPerShardPerSecond = true
+			issetIndex = true
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
+				return err
+			}
+			issetErr = true
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -8550,29 +9432,45 @@ func (p *NodeWriteNewSeriesLimitPerShardPerSecondResult_) Read(iprot thrift.TPro
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetWriteNewSeriesLimitPerShardPerSecond {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field WriteNewSeriesLimitPerShardPerSecond is not set"))
+	if !issetIndex {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Index is not set"))
+	}
+	if !issetErr {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Err is not set"))
 	}
 	return nil
 }
 
-func (p *NodeWriteNewSeriesLimitPerShardPerSecondResult_) ReadField1(iprot thrift.TProtocol) error {
+func (p *WriteBatchRawError) ReadField1(iprot thrift.TProtocol) error {
 	if v, err := iprot.ReadI64(); err != nil {
 		return thrift.PrependError("error reading field 1: ", err)
 	} else {
-		p.WriteNewSeriesLimitPerShardPerSecond = v
+		p.Index = v
 	}
 	return nil
 }
 
-func (p *NodeWriteNewSeriesLimitPerShardPerSecondResult_) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("NodeWriteNewSeriesLimitPerShardPerSecondResult"); err != nil {
+func (p *WriteBatchRawError) ReadField2(iprot thrift.TProtocol) error {
+	p.Err = &Error{
+		Type: 0,
+	}
+	if err := p.Err.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Err), err)
+	}
+	return nil
+}
+
+func (p *WriteBatchRawError) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("WriteBatchRawError"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
 		if err := p.writeField1(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField2(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -8583,45 +9481,58 @@ func (p *NodeWriteNewSeriesLimitPerShardPerSecondResult_) Write(oprot thrift.TPr
 	return nil
 }
 
-func (p *NodeWriteNewSeriesLimitPerShardPerSecondResult_) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("writeNewSeriesLimitPerShardPerSecond", thrift.I64, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:writeNewSeriesLimitPerShardPerSecond: ", p), err)
+func (p *WriteBatchRawError) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("index", thrift.I64, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:index: ", p), err)
 	}
-	if err := oprot.WriteI64(int64(p.WriteNewSeriesLimitPerShardPerSecond)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.writeNewSeriesLimitPerShardPerSecond (1) field write error: ", p), err)
+	if err := oprot.WriteI64(int64(p.Index)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.index (1) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:writeNewSeriesLimitPerShardPerSecond: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:index: ", p), err)
 	}
 	return err
 }
 
-func (p *NodeWriteNewSeriesLimitPerShardPerSecondResult_) String() string {
+func (p *WriteBatchRawError) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:err: ", p), err)
+	}
+	if err := p.Err.Write(oprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Err), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:err: ", p), err)
+	}
+	return err
+}
+
+func (p *WriteBatchRawError) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeWriteNewSeriesLimitPerShardPerSecondResult_(%+v)", *p)
+	return fmt.Sprintf("WriteBatchRawError(%+v)", *p)
 }
 
 // Attributes:
-//  - WriteNewSeriesLimitPerShardPerSecond
-type NodeSetWriteNewSeriesLimitPerShardPerSecondRequest struct {
-	WriteNewSeriesLimitPerShardPerSecond int64 `thrift:"writeNewSeriesLimitPerShardPerSecond,1,required" db:"writeNewSeriesLimitPerShardPerSecond" json:"writeNewSeriesLimitPerShardPerSecond"`
+//  - NameSpace
+type TruncateRequest struct {
+	NameSpace []byte `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
 }
 
-func NewNodeSetWriteNewSeriesLimitPerShardPerSecondRequest() *NodeSetWriteNewSeriesLimitPerShardPerSecondRequest {
-	return &NodeSetWriteNewSeriesLimitPerShardPerSecondRequest{}
+func NewTruncateRequest() *TruncateRequest {
+	return &TruncateRequest{}
 }
 
-func (p *NodeSetWriteNewSeriesLimitPerShardPerSecondRequest) GetWriteNewSeriesLimitPerShardPerSecond() int64 {
-	return p.WriteNewSeriesLimitPerShardPerSecond
+func (p *TruncateRequest) GetNameSpace() []byte {
+	return p.NameSpace
 }
-func (p *NodeSetWriteNewSeriesLimitPerShardPerSecondRequest) Read(iprot thrift.TProtocol) error {
+func (p *TruncateRequest) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetWriteNewSeriesLimitPerShardPerSecond bool = false
+	var issetNameSpace bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -8636,7 +9547,7 @@ func (p *NodeSetWriteNewSeriesLimitPerShardPerSecondRequest) Read(iprot thrift.T
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetWriteNewSeriesLimitPerShardPerSecond = true
+			issetNameSpace = true
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -8649,23 +9560,23 @@ func (p *NodeSetWriteNewSeriesLimitPerShardPerSecondRequest) Read(iprot thrift.T
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetWriteNewSeriesLimitPerShardPerSecond {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field WriteNewSeriesLimitPerShardPerSecond is not set"))
+	if !issetNameSpace {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field NameSpace is not set"))
 	}
 	return nil
 }
 
-func (p *NodeSetWriteNewSeriesLimitPerShardPerSecondRequest) ReadField1(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI64(); err != nil {
+func (p *TruncateRequest) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
 		return thrift.PrependError("error reading field 1: ", err)
 	} else {
-		p.WriteNewSeriesLimitPerShardPerSecond = v
+		p.NameSpace = v
 	}
 	return nil
 }
 
-func (p *NodeSetWriteNewSeriesLimitPerShardPerSecondRequest) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("NodeSetWriteNewSeriesLimitPerShardPerSecondRequest"); err != nil {
+func (p *TruncateRequest) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("TruncateRequest"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -8682,52 +9593,45 @@ func (p *NodeSetWriteNewSeriesLimitPerShardPerSecondRequest) Write(oprot thrift.
 	return nil
 }
 
-func (p *NodeSetWriteNewSeriesLimitPerShardPerSecondRequest) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("writeNewSeriesLimitPerShardPerSecond", thrift.I64, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:writeNewSeriesLimitPerShardPerSecond: ", p), err)
+func (p *TruncateRequest) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("nameSpace", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:nameSpace: ", p), err)
 	}
-	if err := oprot.WriteI64(int64(p.WriteNewSeriesLimitPerShardPerSecond)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.writeNewSeriesLimitPerShardPerSecond (1) field write error: ", p), err)
+	if err := oprot.WriteBinary(p.NameSpace); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.nameSpace (1) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:writeNewSeriesLimitPerShardPerSecond: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:nameSpace: ", p), err)
 	}
 	return err
 }
 
-func (p *NodeSetWriteNewSeriesLimitPerShardPerSecondRequest) String() string {
+func (p *TruncateRequest) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeSetWriteNewSeriesLimitPerShardPerSecondRequest(%+v)", *p)
+	return fmt.Sprintf("TruncateRequest(%+v)", *p)
 }
 
 // Attributes:
-//  - Ok
-//  - Status
-type HealthResult_ struct {
-	Ok     bool   `thrift:"ok,1,required" db:"ok" json:"ok"`
-	Status string `thrift:"status,2,required" db:"status" json:"status"`
-}
-
-func NewHealthResult_() *HealthResult_ {
-	return &HealthResult_{}
+//  - NumSeries
+type TruncateResult_ struct {
+	NumSeries int64 `thrift:"numSeries,1,required" db:"numSeries" json:"numSeries"`
 }
 
-func (p *HealthResult_) GetOk() bool {
-	return p.Ok
+func NewTruncateResult_() *TruncateResult_ {
+	return &TruncateResult_{}
 }
 
-func (p *HealthResult_) GetStatus() string {
-	return p.Status
+func (p *TruncateResult_) GetNumSeries() int64 {
+	return p.NumSeries
 }
-func (p *HealthResult_) Read(iprot thrift.TProtocol) error {
+func (p *TruncateResult_) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetOk bool = false
-	var issetStatus bool = false
+	var issetNumSeries bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -8742,12 +9646,7 @@ func (p *HealthResult_) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetOk = true
-		case 2:
-			if err := p.ReadField2(iprot); err != nil {
-				return err
-			}
-			issetStatus = true
+			issetNumSeries = true
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -8760,44 +9659,29 @@ func (p *HealthResult_) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetOk {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Ok is not set"))
-	}
-	if !issetStatus {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Status is not set"))
+	if !issetNumSeries {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field NumSeries is not set"))
 	}
 	return nil
 }
 
-func (p *HealthResult_) ReadField1(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBool(); err != nil {
+func (p *TruncateResult_) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
 		return thrift.PrependError("error reading field 1: ", err)
 	} else {
-		p.Ok = v
-	}
-	return nil
-}
-
-func (p *HealthResult_) ReadField2(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadString(); err != nil {
-		return thrift.PrependError("error reading field 2: ", err)
-	} else {
-		p.Status = v
+		p.NumSeries = v
 	}
 	return nil
 }
 
-func (p *HealthResult_) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("HealthResult"); err != nil {
+func (p *TruncateResult_) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("TruncateResult"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
 		if err := p.writeField1(oprot); err != nil {
 			return err
 		}
-		if err := p.writeField2(oprot); err != nil {
-			return err
-		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -8808,146 +9692,103 @@ func (p *HealthResult_) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *HealthResult_) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("ok", thrift.BOOL, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:ok: ", p), err)
-	}
-	if err := oprot.WriteBool(bool(p.Ok)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.ok (1) field write error: ", p), err)
-	}
-	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:ok: ", p), err)
-	}
-	return err
-}
-
-func (p *HealthResult_) writeField2(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("status", thrift.STRING, 2); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:status: ", p), err)
+func (p *TruncateResult_) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("numSeries", thrift.I64, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:numSeries: ", p), err)
 	}
-	if err := oprot.WriteString(string(p.Status)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.status (2) field write error: ", p), err)
+	if err := oprot.WriteI64(int64(p.NumSeries)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.numSeries (1) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:status: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:numSeries: ", p), err)
 	}
 	return err
 }
 
-func (p *HealthResult_) String() string {
+func (p *TruncateResult_) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("HealthResult_(%+v)", *p)
+	return fmt.Sprintf("TruncateResult_(%+v)", *p)
 }
 
 // Attributes:
-//  - Query
+//  - NameSpace
 //  - RangeStart
 //  - RangeEnd
-//  - NameSpace
-//  - Limit
-//  - NoData
 //  - RangeType
-//  - ResultTimeType
-type QueryRequest struct {
-	Query          *Query   `thrift:"query,1,required" db:"query" json:"query"`
-	RangeStart     int64    `thrift:"rangeStart,2,required" db:"rangeStart" json:"rangeStart"`
-	RangeEnd       int64    `thrift:"rangeEnd,3,required" db:"rangeEnd" json:"rangeEnd"`
-	NameSpace      string   `thrift:"nameSpace,4,required" db:"nameSpace" json:"nameSpace"`
-	Limit          *int64   `thrift:"limit,5" db:"limit" json:"limit,omitempty"`
-	NoData         *bool    `thrift:"noData,6" db:"noData" json:"noData,omitempty"`
-	RangeType      TimeType `thrift:"rangeType,7" db:"rangeType" json:"rangeType,omitempty"`
-	ResultTimeType TimeType `thrift:"resultTimeType,8" db:"resultTimeType" json:"resultTimeType,omitempty"`
-}
-
-func NewQueryRequest() *QueryRequest {
-	return &QueryRequest{
+//  - Shard
+//  - Force
+type ForceFlushRequest struct {
+	NameSpace  []byte   `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
+	RangeStart int64    `thrift:"rangeStart,2,required" db:"rangeStart" json:"rangeStart"`
+	RangeEnd   int64    `thrift:"rangeEnd,3,required" db:"rangeEnd" json:"rangeEnd"`
+	RangeType  TimeType `thrift:"rangeType,4" db:"rangeType" json:"rangeType,omitempty"`
+	Shard      *int32   `thrift:"shard,5" db:"shard" json:"shard,omitempty"`
+	Force      *bool    `thrift:"force,6" db:"force" json:"force,omitempty"`
+}
+
+func NewForceFlushRequest() *ForceFlushRequest {
+	return &ForceFlushRequest{
 		RangeType: 0,
-
-		ResultTimeType: 0,
 	}
 }
 
-var QueryRequest_Query_DEFAULT *Query
-
-func (p *QueryRequest) GetQuery() *Query {
-	if !p.IsSetQuery() {
-		return QueryRequest_Query_DEFAULT
-	}
-	return p.Query
+func (p *ForceFlushRequest) GetNameSpace() []byte {
+	return p.NameSpace
 }
 
-func (p *QueryRequest) GetRangeStart() int64 {
+func (p *ForceFlushRequest) GetRangeStart() int64 {
 	return p.RangeStart
 }
 
-func (p *QueryRequest) GetRangeEnd() int64 {
+func (p *ForceFlushRequest) GetRangeEnd() int64 {
 	return p.RangeEnd
 }
 
-func (p *QueryRequest) GetNameSpace() string {
-	return p.NameSpace
-}
-
-var QueryRequest_Limit_DEFAULT int64
+var ForceFlushRequest_RangeType_DEFAULT TimeType = 0
 
-func (p *QueryRequest) GetLimit() int64 {
-	if !p.IsSetLimit() {
-		return QueryRequest_Limit_DEFAULT
-	}
-	return *p.Limit
+func (p *ForceFlushRequest) GetRangeType() TimeType {
+	return p.RangeType
 }
 
-var QueryRequest_NoData_DEFAULT bool
+var ForceFlushRequest_Shard_DEFAULT int32
 
-func (p *QueryRequest) GetNoData() bool {
-	if !p.IsSetNoData() {
-		return QueryRequest_NoData_DEFAULT
+func (p *ForceFlushRequest) GetShard() int32 {
+	if !p.IsSetShard() {
+		return ForceFlushRequest_Shard_DEFAULT
 	}
-	return *p.NoData
-}
-
-var QueryRequest_RangeType_DEFAULT TimeType = 0
-
-func (p *QueryRequest) GetRangeType() TimeType {
-	return p.RangeType
+	return *p.Shard
 }
 
-var QueryRequest_ResultTimeType_DEFAULT TimeType = 0
-
-func (p *QueryRequest) GetResultTimeType() TimeType {
-	return p.ResultTimeType
-}
-func (p *QueryRequest) IsSetQuery() bool {
-	return p.Query != nil
-}
+var ForceFlushRequest_Force_DEFAULT bool
 
-func (p *QueryRequest) IsSetLimit() bool {
-	return p.Limit != nil
+func (p *ForceFlushRequest) GetForce() bool {
+	if !p.IsSetForce() {
+		return ForceFlushRequest_Force_DEFAULT
+	}
+	return *p.Force
 }
-
-func (p *QueryRequest) IsSetNoData() bool {
-	return p.NoData != nil
+func (p *ForceFlushRequest) IsSetRangeType() bool {
+	return p.RangeType != ForceFlushRequest_RangeType_DEFAULT
 }
 
-func (p *QueryRequest) IsSetRangeType() bool {
-	return p.RangeType != QueryRequest_RangeType_DEFAULT
+func (p *ForceFlushRequest) IsSetShard() bool {
+	return p.Shard != nil
 }
 
-func (p *QueryRequest) IsSetResultTimeType() bool {
-	return p.ResultTimeType != QueryRequest_ResultTimeType_DEFAULT
+func (p *ForceFlushRequest) IsSetForce() bool {
+	return p.Force != nil
 }
 
-func (p *QueryRequest) Read(iprot thrift.TProtocol) error {
+func (p *ForceFlushRequest) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetQuery bool = false
+	var issetNameSpace bool = false
 	var issetRangeStart bool = false
 	var issetRangeEnd bool = false
-	var issetNameSpace bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -8962,7 +9803,7 @@ func (p *QueryRequest) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetQuery = true
+			issetNameSpace = true
 		case 2:
 			if err := p.ReadField2(iprot); err != nil {
 				return err
@@ -8977,7 +9818,6 @@ func (p *QueryRequest) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField4(iprot); err != nil {
 				return err
 			}
-			issetNameSpace = true
 		case 5:
 			if err := p.ReadField5(iprot); err != nil {
 				return err
@@ -8986,14 +9826,6 @@ func (p *QueryRequest) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField6(iprot); err != nil {
 				return err
 			}
-		case 7:
-			if err := p.ReadField7(iprot); err != nil {
-				return err
-			}
-		case 8:
-			if err := p.ReadField8(iprot); err != nil {
-				return err
-			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -9006,8 +9838,8 @@ func (p *QueryRequest) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetQuery {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Query is not set"))
+	if !issetNameSpace {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field NameSpace is not set"))
 	}
 	if !issetRangeStart {
 		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field RangeStart is not set"))
@@ -9015,21 +9847,19 @@ func (p *QueryRequest) Read(iprot thrift.TProtocol) error {
 	if !issetRangeEnd {
 		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field RangeEnd is not set"))
 	}
-	if !issetNameSpace {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field NameSpace is not set"))
-	}
 	return nil
 }
 
-func (p *QueryRequest) ReadField1(iprot thrift.TProtocol) error {
-	p.Query = &Query{}
-	if err := p.Query.Read(iprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Query), err)
+func (p *ForceFlushRequest) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
+	} else {
+		p.NameSpace = v
 	}
 	return nil
 }
 
-func (p *QueryRequest) ReadField2(iprot thrift.TProtocol) error {
+func (p *ForceFlushRequest) ReadField2(iprot thrift.TProtocol) error {
 	if v, err := iprot.ReadI64(); err != nil {
 		return thrift.PrependError("error reading field 2: ", err)
 	} else {
@@ -9038,7 +9868,7 @@ func (p *QueryRequest) ReadField2(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *QueryRequest) ReadField3(iprot thrift.TProtocol) error {
+func (p *ForceFlushRequest) ReadField3(iprot thrift.TProtocol) error {
 	if v, err := iprot.ReadI64(); err != nil {
 		return thrift.PrependError("error reading field 3: ", err)
 	} else {
@@ -9047,55 +9877,36 @@ func (p *QueryRequest) ReadField3(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *QueryRequest) ReadField4(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadString(); err != nil {
+func (p *ForceFlushRequest) ReadField4(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI32(); err != nil {
 		return thrift.PrependError("error reading field 4: ", err)
 	} else {
-		p.NameSpace = v
+		temp := TimeType(v)
+		p.RangeType = temp
 	}
 	return nil
 }
 
-func (p *QueryRequest) ReadField5(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI64(); err != nil {
+func (p *ForceFlushRequest) ReadField5(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI32(); err != nil {
 		return thrift.PrependError("error reading field 5: ", err)
 	} else {
-		p.Limit = &v
+		p.Shard = &v
 	}
 	return nil
 }
 
-func (p *QueryRequest) ReadField6(iprot thrift.TProtocol) error {
+func (p *ForceFlushRequest) ReadField6(iprot thrift.TProtocol) error {
 	if v, err := iprot.ReadBool(); err != nil {
 		return thrift.PrependError("error reading field 6: ", err)
 	} else {
-		p.NoData = &v
-	}
-	return nil
-}
-
-func (p *QueryRequest) ReadField7(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI32(); err != nil {
-		return thrift.PrependError("error reading field 7: ", err)
-	} else {
-		temp := TimeType(v)
-		p.RangeType = temp
-	}
-	return nil
-}
-
-func (p *QueryRequest) ReadField8(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI32(); err != nil {
-		return thrift.PrependError("error reading field 8: ", err)
-	} else {
-		temp := TimeType(v)
-		p.ResultTimeType = temp
+		p.Force = &v
 	}
 	return nil
 }
 
-func (p *QueryRequest) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("QueryRequest"); err != nil {
+func (p *ForceFlushRequest) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("ForceFlushRequest"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -9117,12 +9928,6 @@ func (p *QueryRequest) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField6(oprot); err != nil {
 			return err
 		}
-		if err := p.writeField7(oprot); err != nil {
-			return err
-		}
-		if err := p.writeField8(oprot); err != nil {
-			return err
-		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -9133,20 +9938,20 @@ func (p *QueryRequest) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *QueryRequest) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("query", thrift.STRUCT, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:query: ", p), err)
+func (p *ForceFlushRequest) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("nameSpace", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:nameSpace: ", p), err)
 	}
-	if err := p.Query.Write(oprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Query), err)
+	if err := oprot.WriteBinary(p.NameSpace); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.nameSpace (1) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:query: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:nameSpace: ", p), err)
 	}
 	return err
 }
 
-func (p *QueryRequest) writeField2(oprot thrift.TProtocol) (err error) {
+func (p *ForceFlushRequest) writeField2(oprot thrift.TProtocol) (err error) {
 	if err := oprot.WriteFieldBegin("rangeStart", thrift.I64, 2); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:rangeStart: ", p), err)
 	}
@@ -9159,7 +9964,7 @@ func (p *QueryRequest) writeField2(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
-func (p *QueryRequest) writeField3(oprot thrift.TProtocol) (err error) {
+func (p *ForceFlushRequest) writeField3(oprot thrift.TProtocol) (err error) {
 	if err := oprot.WriteFieldBegin("rangeEnd", thrift.I64, 3); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:rangeEnd: ", p), err)
 	}
@@ -9172,112 +9977,84 @@ func (p *QueryRequest) writeField3(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
-func (p *QueryRequest) writeField4(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("nameSpace", thrift.STRING, 4); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:nameSpace: ", p), err)
-	}
-	if err := oprot.WriteString(string(p.NameSpace)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.nameSpace (4) field write error: ", p), err)
-	}
-	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 4:nameSpace: ", p), err)
-	}
-	return err
-}
-
-func (p *QueryRequest) writeField5(oprot thrift.TProtocol) (err error) {
-	if p.IsSetLimit() {
-		if err := oprot.WriteFieldBegin("limit", thrift.I64, 5); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 5:limit: ", p), err)
-		}
-		if err := oprot.WriteI64(int64(*p.Limit)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.limit (5) field write error: ", p), err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 5:limit: ", p), err)
-		}
-	}
-	return err
-}
-
-func (p *QueryRequest) writeField6(oprot thrift.TProtocol) (err error) {
-	if p.IsSetNoData() {
-		if err := oprot.WriteFieldBegin("noData", thrift.BOOL, 6); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 6:noData: ", p), err)
+func (p *ForceFlushRequest) writeField4(oprot thrift.TProtocol) (err error) {
+	if p.IsSetRangeType() {
+		if err := oprot.WriteFieldBegin("rangeType", thrift.I32, 4); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:rangeType: ", p), err)
 		}
-		if err := oprot.WriteBool(bool(*p.NoData)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.noData (6) field write error: ", p), err)
+		if err := oprot.WriteI32(int32(p.RangeType)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.rangeType (4) field write error: ", p), err)
 		}
 		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 6:noData: ", p), err)
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 4:rangeType: ", p), err)
 		}
 	}
 	return err
 }
 
-func (p *QueryRequest) writeField7(oprot thrift.TProtocol) (err error) {
-	if p.IsSetRangeType() {
-		if err := oprot.WriteFieldBegin("rangeType", thrift.I32, 7); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 7:rangeType: ", p), err)
+func (p *ForceFlushRequest) writeField5(oprot thrift.TProtocol) (err error) {
+	if p.IsSetShard() {
+		if err := oprot.WriteFieldBegin("shard", thrift.I32, 5); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 5:shard: ", p), err)
 		}
-		if err := oprot.WriteI32(int32(p.RangeType)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.rangeType (7) field write error: ", p), err)
+		if err := oprot.WriteI32(int32(*p.Shard)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.shard (5) field write error: ", p), err)
 		}
 		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 7:rangeType: ", p), err)
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 5:shard: ", p), err)
 		}
 	}
 	return err
 }
 
-func (p *QueryRequest) writeField8(oprot thrift.TProtocol) (err error) {
-	if p.IsSetResultTimeType() {
-		if err := oprot.WriteFieldBegin("resultTimeType", thrift.I32, 8); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 8:resultTimeType: ", p), err)
+func (p *ForceFlushRequest) writeField6(oprot thrift.TProtocol) (err error) {
+	if p.IsSetForce() {
+		if err := oprot.WriteFieldBegin("force", thrift.BOOL, 6); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 6:force: ", p), err)
 		}
-		if err := oprot.WriteI32(int32(p.ResultTimeType)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.resultTimeType (8) field write error: ", p), err)
+		if err := oprot.WriteBool(bool(*p.Force)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.force (6) field write error: ", p), err)
 		}
 		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 8:resultTimeType: ", p), err)
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 6:force: ", p), err)
 		}
 	}
 	return err
 }
 
-func (p *QueryRequest) String() string {
+func (p *ForceFlushRequest) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("QueryRequest(%+v)", *p)
+	return fmt.Sprintf("ForceFlushRequest(%+v)", *p)
 }
 
 // Attributes:
-//  - Results
-//  - Exhaustive
-type QueryResult_ struct {
-	Results    []*QueryResultElement `thrift:"results,1,required" db:"results" json:"results"`
-	Exhaustive bool                  `thrift:"exhaustive,2,required" db:"exhaustive" json:"exhaustive"`
+//  - NumSeriesFlushed
+//  - NumBytesWritten
+type ForceFlushResult_ struct {
+	NumSeriesFlushed int64 `thrift:"numSeriesFlushed,1,required" db:"numSeriesFlushed" json:"numSeriesFlushed"`
+	NumBytesWritten  int64 `thrift:"numBytesWritten,2,required" db:"numBytesWritten" json:"numBytesWritten"`
 }
 
-func NewQueryResult_() *QueryResult_ {
-	return &QueryResult_{}
+func NewForceFlushResult_() *ForceFlushResult_ {
+	return &ForceFlushResult_{}
 }
 
-func (p *QueryResult_) GetResults() []*QueryResultElement {
-	return p.Results
+func (p *ForceFlushResult_) GetNumSeriesFlushed() int64 {
+	return p.NumSeriesFlushed
 }
 
-func (p *QueryResult_) GetExhaustive() bool {
-	return p.Exhaustive
+func (p *ForceFlushResult_) GetNumBytesWritten() int64 {
+	return p.NumBytesWritten
 }
-func (p *QueryResult_) Read(iprot thrift.TProtocol) error {
+func (p *ForceFlushResult_) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetResults bool = false
-	var issetExhaustive bool = false
+	var issetNumSeriesFlushed bool = false
+	var issetNumBytesWritten bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -9292,12 +10069,12 @@ func (p *QueryResult_) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetResults = true
+			issetNumSeriesFlushed = true
 		case 2:
 			if err := p.ReadField2(iprot); err != nil {
 				return err
 			}
-			issetExhaustive = true
+			issetNumBytesWritten = true
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -9310,46 +10087,35 @@ func (p *QueryResult_) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetResults {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Results is not set"))
+	if !issetNumSeriesFlushed {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field NumSeriesFlushed is not set"))
 	}
-	if !issetExhaustive {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Exhaustive is not set"))
+	if !issetNumBytesWritten {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field NumBytesWritten is not set"))
 	}
 	return nil
 }
 
-func (p *QueryResult_) ReadField1(iprot thrift.TProtocol) error {
-	_, size, err := iprot.ReadListBegin()
-	if err != nil {
-		return thrift.PrependError("error reading list begin: ", err)
-	}
-	tSlice := make([]*QueryResultElement, 0, size)
-	p.Results = tSlice
-	for i := 0; i < size; i++ {
-		_elem18 := &QueryResultElement{}
-		if err := _elem18.Read(iprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem18), err)
-		}
-		p.Results = append(p.Results, _elem18)
-	}
-	if err := iprot.ReadListEnd(); err != nil {
-		return thrift.PrependError("error reading list end: ", err)
+func (p *ForceFlushResult_) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
+	} else {
+		p.NumSeriesFlushed = v
 	}
 	return nil
 }
 
-func (p *QueryResult_) ReadField2(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBool(); err != nil {
+func (p *ForceFlushResult_) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
 		return thrift.PrependError("error reading field 2: ", err)
 	} else {
-		p.Exhaustive = v
+		p.NumBytesWritten = v
 	}
 	return nil
 }
 
-func (p *QueryResult_) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("QueryResult"); err != nil {
+func (p *ForceFlushResult_) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("ForceFlushResult"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -9369,80 +10135,72 @@ func (p *QueryResult_) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *QueryResult_) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("results", thrift.LIST, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:results: ", p), err)
-	}
-	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Results)); err != nil {
-		return thrift.PrependError("error writing list begin: ", err)
-	}
-	for _, v := range p.Results {
-		if err := v.Write(oprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
-		}
+func (p *ForceFlushResult_) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("numSeriesFlushed", thrift.I64, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:numSeriesFlushed: ", p), err)
 	}
-	if err := oprot.WriteListEnd(); err != nil {
-		return thrift.PrependError("error writing list end: ", err)
+	if err := oprot.WriteI64(int64(p.NumSeriesFlushed)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.numSeriesFlushed (1) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:results: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:numSeriesFlushed: ", p), err)
 	}
 	return err
 }
 
-func (p *QueryResult_) writeField2(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("exhaustive", thrift.BOOL, 2); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:exhaustive: ", p), err)
+func (p *ForceFlushResult_) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("numBytesWritten", thrift.I64, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:numBytesWritten: ", p), err)
 	}
-	if err := oprot.WriteBool(bool(p.Exhaustive)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.exhaustive (2) field write error: ", p), err)
+	if err := oprot.WriteI64(int64(p.NumBytesWritten)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.numBytesWritten (2) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:exhaustive: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:numBytesWritten: ", p), err)
 	}
 	return err
 }
 
-func (p *QueryResult_) String() string {
+func (p *ForceFlushResult_) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("QueryResult_(%+v)", *p)
+	return fmt.Sprintf("ForceFlushResult_(%+v)", *p)
 }
 
 // Attributes:
-//  - ID
-//  - Tags
-//  - Datapoints
-type QueryResultElement struct {
-	ID         string       `thrift:"id,1,required" db:"id" json:"id"`
-	Tags       []*Tag       `thrift:"tags,2,required" db:"tags" json:"tags"`
-	Datapoints []*Datapoint `thrift:"datapoints,3,required" db:"datapoints" json:"datapoints"`
+//  - Ok
+//  - Status
+//  - Bootstrapped
+type NodeHealthResult_ struct {
+	Ok           bool   `thrift:"ok,1,required" db:"ok" json:"ok"`
+	Status       string `thrift:"status,2,required" db:"status" json:"status"`
+	Bootstrapped bool   `thrift:"bootstrapped,3,required" db:"bootstrapped" json:"bootstrapped"`
 }
 
-func NewQueryResultElement() *QueryResultElement {
-	return &QueryResultElement{}
+func NewNodeHealthResult_() *NodeHealthResult_ {
+	return &NodeHealthResult_{}
 }
 
-func (p *QueryResultElement) GetID() string {
-	return p.ID
+func (p *NodeHealthResult_) GetOk() bool {
+	return p.Ok
 }
 
-func (p *QueryResultElement) GetTags() []*Tag {
-	return p.Tags
+func (p *NodeHealthResult_) GetStatus() string {
+	return p.Status
 }
 
-func (p *QueryResultElement) GetDatapoints() []*Datapoint {
-	return p.Datapoints
+func (p *NodeHealthResult_) GetBootstrapped() bool {
+	return p.Bootstrapped
 }
-func (p *QueryResultElement) Read(iprot thrift.TProtocol) error {
+func (p *NodeHealthResult_) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetID bool = false
-	var issetTags bool = false
-	var issetDatapoints bool = false
+	var issetOk bool = false
+	var issetStatus bool = false
+	var issetBootstrapped bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -9457,17 +10215,17 @@ func (p *QueryResultElement) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetID = true
+			issetOk = true
 		case 2:
 			if err := p.ReadField2(iprot); err != nil {
 				return err
 			}
-			issetTags = true
+			issetStatus = true
 		case 3:
 			if err := p.ReadField3(iprot); err != nil {
 				return err
 			}
-			issetDatapoints = true
+			issetBootstrapped = true
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -9480,71 +10238,47 @@ func (p *QueryResultElement) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetID {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field ID is not set"))
+	if !issetOk {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Ok is not set"))
 	}
-	if !issetTags {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Tags is not set"))
+	if !issetStatus {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Status is not set"))
 	}
-	if !issetDatapoints {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Datapoints is not set"))
+	if !issetBootstrapped {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Bootstrapped is not set"))
 	}
 	return nil
 }
 
-func (p *QueryResultElement) ReadField1(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadString(); err != nil {
+func (p *NodeHealthResult_) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
 		return thrift.PrependError("error reading field 1: ", err)
 	} else {
-		p.ID = v
+		p.Ok = v
 	}
 	return nil
 }
 
-func (p *QueryResultElement) ReadField2(iprot thrift.TProtocol) error {
-	_, size, err := iprot.ReadListBegin()
-	if err != nil {
-		return thrift.PrependError("error reading list begin: ", err)
-	}
-	tSlice := make([]*Tag, 0, size)
-	p.Tags = tSlice
-	for i := 0; i < size; i++ {
-		_elem19 := &Tag{}
-		if err := _elem19.Read(iprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem19), err)
-		}
-		p.Tags = append(p.Tags, _elem19)
-	}
-	if err := iprot.ReadListEnd(); err != nil {
-		return thrift.PrependError("error reading list end: ", err)
+func (p *NodeHealthResult_) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		p.Status = v
 	}
 	return nil
 }
 
-func (p *QueryResultElement) ReadField3(iprot thrift.TProtocol) error {
-	_, size, err := iprot.ReadListBegin()
-	if err != nil {
-		return thrift.PrependError("error reading list begin: ", err)
-	}
-	tSlice := make([]*Datapoint, 0, size)
-	p.Datapoints = tSlice
-	for i := 0; i < size; i++ {
-		_elem20 := &Datapoint{
-			TimestampTimeType: 0,
-		}
-		if err := _elem20.Read(iprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem20), err)
-		}
-		p.Datapoints = append(p.Datapoints, _elem20)
-	}
-	if err := iprot.ReadListEnd(); err != nil {
-		return thrift.PrependError("error reading list end: ", err)
+func (p *NodeHealthResult_) ReadField3(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 3: ", err)
+	} else {
+		p.Bootstrapped = v
 	}
 	return nil
 }
 
-func (p *QueryResultElement) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("QueryResultElement"); err != nil {
+func (p *NodeHealthResult_) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("NodeHealthResult"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -9567,95 +10301,64 @@ func (p *QueryResultElement) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *QueryResultElement) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("id", thrift.STRING, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:id: ", p), err)
+func (p *NodeHealthResult_) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("ok", thrift.BOOL, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:ok: ", p), err)
 	}
-	if err := oprot.WriteString(string(p.ID)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.id (1) field write error: ", p), err)
+	if err := oprot.WriteBool(bool(p.Ok)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.ok (1) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:id: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:ok: ", p), err)
 	}
 	return err
 }
 
-func (p *QueryResultElement) writeField2(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("tags", thrift.LIST, 2); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:tags: ", p), err)
-	}
-	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Tags)); err != nil {
-		return thrift.PrependError("error writing list begin: ", err)
+func (p *NodeHealthResult_) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("status", thrift.STRING, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:status: ", p), err)
 	}
-	for _, v := range p.Tags {
-		if err := v.Write(oprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
-		}
-	}
-	if err := oprot.WriteListEnd(); err != nil {
-		return thrift.PrependError("error writing list end: ", err)
+	if err := oprot.WriteString(string(p.Status)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.status (2) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:tags: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:status: ", p), err)
 	}
 	return err
 }
 
-func (p *QueryResultElement) writeField3(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("datapoints", thrift.LIST, 3); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:datapoints: ", p), err)
-	}
-	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Datapoints)); err != nil {
-		return thrift.PrependError("error writing list begin: ", err)
-	}
-	for _, v := range p.Datapoints {
-		if err := v.Write(oprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
-		}
+func (p *NodeHealthResult_) writeField3(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("bootstrapped", thrift.BOOL, 3); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:bootstrapped: ", p), err)
 	}
-	if err := oprot.WriteListEnd(); err != nil {
-		return thrift.PrependError("error writing list end: ", err)
+	if err := oprot.WriteBool(bool(p.Bootstrapped)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.bootstrapped (3) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:datapoints: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:bootstrapped: ", p), err)
 	}
 	return err
 }
 
-func (p *QueryResultElement) String() string {
+func (p *NodeHealthResult_) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("QueryResultElement(%+v)", *p)
-}
-
-// Attributes:
-//  - Field
-//  - Term
-type TermQuery struct {
-	Field string `thrift:"field,1,required" db:"field" json:"field"`
-	Term  string `thrift:"term,2,required" db:"term" json:"term"`
+	return fmt.Sprintf("NodeHealthResult_(%+v)", *p)
 }
 
-func NewTermQuery() *TermQuery {
-	return &TermQuery{}
+type NodeBootstrappedResult_ struct {
 }
 
-func (p *TermQuery) GetField() string {
-	return p.Field
+func NewNodeBootstrappedResult_() *NodeBootstrappedResult_ {
+	return &NodeBootstrappedResult_{}
 }
 
-func (p *TermQuery) GetTerm() string {
-	return p.Term
-}
-func (p *TermQuery) Read(iprot thrift.TProtocol) error {
+func (p *NodeBootstrappedResult_) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetField bool = false
-	var issetTerm bool = false
-
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
 		if err != nil {
@@ -9664,21 +10367,8 @@ func (p *TermQuery) Read(iprot thrift.TProtocol) error {
 		if fieldTypeId == thrift.STOP {
 			break
 		}
-		switch fieldId {
-		case 1:
-			if err := p.ReadField1(iprot); err != nil {
-				return err
-			}
-			issetField = true
-		case 2:
-			if err := p.ReadField2(iprot); err != nil {
-				return err
-			}
-			issetTerm = true
-		default:
-			if err := iprot.Skip(fieldTypeId); err != nil {
-				return err
-			}
+		if err := iprot.Skip(fieldTypeId); err != nil {
+			return err
 		}
 		if err := iprot.ReadFieldEnd(); err != nil {
 			return err
@@ -9687,44 +10377,14 @@ func (p *TermQuery) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetField {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Field is not set"))
-	}
-	if !issetTerm {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Term is not set"))
-	}
-	return nil
-}
-
-func (p *TermQuery) ReadField1(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadString(); err != nil {
-		return thrift.PrependError("error reading field 1: ", err)
-	} else {
-		p.Field = v
-	}
-	return nil
-}
-
-func (p *TermQuery) ReadField2(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadString(); err != nil {
-		return thrift.PrependError("error reading field 2: ", err)
-	} else {
-		p.Term = v
-	}
 	return nil
 }
 
-func (p *TermQuery) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("TermQuery"); err != nil {
+func (p *NodeBootstrappedResult_) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("NodeBootstrappedResult"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
-		if err := p.writeField1(oprot); err != nil {
-			return err
-		}
-		if err := p.writeField2(oprot); err != nil {
-			return err
-		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -9735,65 +10395,46 @@ func (p *TermQuery) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *TermQuery) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("field", thrift.STRING, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:field: ", p), err)
-	}
-	if err := oprot.WriteString(string(p.Field)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.field (1) field write error: ", p), err)
-	}
-	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:field: ", p), err)
-	}
-	return err
-}
-
-func (p *TermQuery) writeField2(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("term", thrift.STRING, 2); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:term: ", p), err)
-	}
-	if err := oprot.WriteString(string(p.Term)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.term (2) field write error: ", p), err)
-	}
-	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:term: ", p), err)
-	}
-	return err
-}
-
-func (p *TermQuery) String() string {
+func (p *NodeBootstrappedResult_) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("TermQuery(%+v)", *p)
+	return fmt.Sprintf("NodeBootstrappedResult_(%+v)", *p)
 }
 
 // Attributes:
-//  - Field
-//  - Regexp
-type RegexpQuery struct {
-	Field  string `thrift:"field,1,required" db:"field" json:"field"`
-	Regexp string `thrift:"regexp,2,required" db:"regexp" json:"regexp"`
+//  - LimitEnabled
+//  - LimitMbps
+//  - LimitCheckEvery
+type NodePersistRateLimitResult_ struct {
+	LimitEnabled    bool    `thrift:"limitEnabled,1,required" db:"limitEnabled" json:"limitEnabled"`
+	LimitMbps       float64 `thrift:"limitMbps,2,required" db:"limitMbps" json:"limitMbps"`
+	LimitCheckEvery int64   `thrift:"limitCheckEvery,3,required" db:"limitCheckEvery" json:"limitCheckEvery"`
 }
 
-func NewRegexpQuery() *RegexpQuery {
-	return &RegexpQuery{}
+func NewNodePersistRateLimitResult_() *NodePersistRateLimitResult_ {
+	return &NodePersistRateLimitResult_{}
 }
 
-func (p *RegexpQuery) GetField() string {
-	return p.Field
+func (p *NodePersistRateLimitResult_) GetLimitEnabled() bool {
+	return p.LimitEnabled
 }
 
-func (p *RegexpQuery) GetRegexp() string {
-	return p.Regexp
+func (p *NodePersistRateLimitResult_) GetLimitMbps() float64 {
+	return p.LimitMbps
 }
-func (p *RegexpQuery) Read(iprot thrift.TProtocol) error {
+
+func (p *NodePersistRateLimitResult_) GetLimitCheckEvery() int64 {
+	return p.LimitCheckEvery
+}
+func (p *NodePersistRateLimitResult_) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetField bool = false
-	var issetRegexp bool = false
+	var issetLimitEnabled bool = false
+	var issetLimitMbps bool = false
+	var issetLimitCheckEvery bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -9808,12 +10449,17 @@ func (p *RegexpQuery) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetField = true
+			issetLimitEnabled = true
 		case 2:
 			if err := p.ReadField2(iprot); err != nil {
 				return err
 			}
-			issetRegexp = true
+			issetLimitMbps = true
+		case 3:
+			if err := p.ReadField3(iprot); err != nil {
+				return err
+			}
+			issetLimitCheckEvery = true
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -9826,35 +10472,47 @@ func (p *RegexpQuery) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetField {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Field is not set"))
+	if !issetLimitEnabled {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field LimitEnabled is not set"))
 	}
-	if !issetRegexp {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Regexp is not set"))
+	if !issetLimitMbps {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field LimitMbps is not set"))
+	}
+	if !issetLimitCheckEvery {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field LimitCheckEvery is not set"))
 	}
 	return nil
 }
 
-func (p *RegexpQuery) ReadField1(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadString(); err != nil {
+func (p *NodePersistRateLimitResult_) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
 		return thrift.PrependError("error reading field 1: ", err)
 	} else {
-		p.Field = v
+		p.LimitEnabled = v
 	}
 	return nil
 }
 
-func (p *RegexpQuery) ReadField2(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadString(); err != nil {
+func (p *NodePersistRateLimitResult_) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadDouble(); err != nil {
 		return thrift.PrependError("error reading field 2: ", err)
 	} else {
-		p.Regexp = v
+		p.LimitMbps = v
 	}
 	return nil
 }
 
-func (p *RegexpQuery) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("RegexpQuery"); err != nil {
+func (p *NodePersistRateLimitResult_) ReadField3(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 3: ", err)
+	} else {
+		p.LimitCheckEvery = v
+	}
+	return nil
+}
+
+func (p *NodePersistRateLimitResult_) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("NodePersistRateLimitResult"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -9864,6 +10522,9 @@ func (p *RegexpQuery) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField2(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField3(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -9874,68 +10535,109 @@ func (p *RegexpQuery) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *RegexpQuery) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("field", thrift.STRING, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:field: ", p), err)
+func (p *NodePersistRateLimitResult_) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("limitEnabled", thrift.BOOL, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:limitEnabled: ", p), err)
 	}
-	if err := oprot.WriteString(string(p.Field)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.field (1) field write error: ", p), err)
+	if err := oprot.WriteBool(bool(p.LimitEnabled)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.limitEnabled (1) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:field: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:limitEnabled: ", p), err)
 	}
 	return err
 }
 
-func (p *RegexpQuery) writeField2(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("regexp", thrift.STRING, 2); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:regexp: ", p), err)
+func (p *NodePersistRateLimitResult_) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("limitMbps", thrift.DOUBLE, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:limitMbps: ", p), err)
 	}
-	if err := oprot.WriteString(string(p.Regexp)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.regexp (2) field write error: ", p), err)
+	if err := oprot.WriteDouble(float64(p.LimitMbps)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.limitMbps (2) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:regexp: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:limitMbps: ", p), err)
 	}
 	return err
 }
 
-func (p *RegexpQuery) String() string {
-	if p == nil {
-		return "<nil>"
+func (p *NodePersistRateLimitResult_) writeField3(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("limitCheckEvery", thrift.I64, 3); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:limitCheckEvery: ", p), err)
 	}
-	return fmt.Sprintf("RegexpQuery(%+v)", *p)
+	if err := oprot.WriteI64(int64(p.LimitCheckEvery)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.limitCheckEvery (3) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:limitCheckEvery: ", p), err)
+	}
+	return err
 }
 
-// Attributes:
-//  - Query
-type NegationQuery struct {
-	Query *Query `thrift:"query,1,required" db:"query" json:"query"`
+func (p *NodePersistRateLimitResult_) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("NodePersistRateLimitResult_(%+v)", *p)
 }
 
-func NewNegationQuery() *NegationQuery {
-	return &NegationQuery{}
+// Attributes:
+//  - LimitEnabled
+//  - LimitMbps
+//  - LimitCheckEvery
+type NodeSetPersistRateLimitRequest struct {
+	LimitEnabled    *bool    `thrift:"limitEnabled,1" db:"limitEnabled" json:"limitEnabled,omitempty"`
+	LimitMbps       *float64 `thrift:"limitMbps,2" db:"limitMbps" json:"limitMbps,omitempty"`
+	LimitCheckEvery *int64   `thrift:"limitCheckEvery,3" db:"limitCheckEvery" json:"limitCheckEvery,omitempty"`
 }
 
-var NegationQuery_Query_DEFAULT *Query
+func NewNodeSetPersistRateLimitRequest() *NodeSetPersistRateLimitRequest {
+	return &NodeSetPersistRateLimitRequest{}
+}
 
-func (p *NegationQuery) GetQuery() *Query {
-	if !p.IsSetQuery() {
-		return NegationQuery_Query_DEFAULT
+var NodeSetPersistRateLimitRequest_LimitEnabled_DEFAULT bool
+
+func (p *NodeSetPersistRateLimitRequest) GetLimitEnabled() bool {
+	if !p.IsSetLimitEnabled() {
+		return NodeSetPersistRateLimitRequest_LimitEnabled_DEFAULT
 	}
-	return p.Query
+	return *p.LimitEnabled
 }
-func (p *NegationQuery) IsSetQuery() bool {
-	return p.Query != nil
+
+var NodeSetPersistRateLimitRequest_LimitMbps_DEFAULT float64
+
+func (p *NodeSetPersistRateLimitRequest) GetLimitMbps() float64 {
+	if !p.IsSetLimitMbps() {
+		return NodeSetPersistRateLimitRequest_LimitMbps_DEFAULT
+	}
+	return *p.LimitMbps
 }
 
-func (p *NegationQuery) Read(iprot thrift.TProtocol) error {
+var NodeSetPersistRateLimitRequest_LimitCheckEvery_DEFAULT int64
+
+func (p *NodeSetPersistRateLimitRequest) GetLimitCheckEvery() int64 {
+	if !p.IsSetLimitCheckEvery() {
+		return NodeSetPersistRateLimitRequest_LimitCheckEvery_DEFAULT
+	}
+	return *p.LimitCheckEvery
+}
+func (p *NodeSetPersistRateLimitRequest) IsSetLimitEnabled() bool {
+	return p.LimitEnabled != nil
+}
+
+func (p *NodeSetPersistRateLimitRequest) IsSetLimitMbps() bool {
+	return p.LimitMbps != nil
+}
+
+func (p *NodeSetPersistRateLimitRequest) IsSetLimitCheckEvery() bool {
+	return p.LimitCheckEvery != nil
+}
+
+func (p *NodeSetPersistRateLimitRequest) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetQuery bool = false
-
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
 		if err != nil {
@@ -9949,7 +10651,14 @@ func (p *NegationQuery) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetQuery = true
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
+				return err
+			}
+		case 3:
+			if err := p.ReadField3(iprot); err != nil {
+				return err
+			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -9962,28 +10671,50 @@ func (p *NegationQuery) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetQuery {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Query is not set"))
+	return nil
+}
+
+func (p *NodeSetPersistRateLimitRequest) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
+	} else {
+		p.LimitEnabled = &v
 	}
 	return nil
 }
 
-func (p *NegationQuery) ReadField1(iprot thrift.TProtocol) error {
-	p.Query = &Query{}
-	if err := p.Query.Read(iprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Query), err)
+func (p *NodeSetPersistRateLimitRequest) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadDouble(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		p.LimitMbps = &v
 	}
 	return nil
 }
 
-func (p *NegationQuery) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("NegationQuery"); err != nil {
+func (p *NodeSetPersistRateLimitRequest) ReadField3(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 3: ", err)
+	} else {
+		p.LimitCheckEvery = &v
+	}
+	return nil
+}
+
+func (p *NodeSetPersistRateLimitRequest) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("NodeSetPersistRateLimitRequest"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
 		if err := p.writeField1(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField2(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField3(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -9994,45 +10725,77 @@ func (p *NegationQuery) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NegationQuery) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("query", thrift.STRUCT, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:query: ", p), err)
+func (p *NodeSetPersistRateLimitRequest) writeField1(oprot thrift.TProtocol) (err error) {
+	if p.IsSetLimitEnabled() {
+		if err := oprot.WriteFieldBegin("limitEnabled", thrift.BOOL, 1); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:limitEnabled: ", p), err)
+		}
+		if err := oprot.WriteBool(bool(*p.LimitEnabled)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.limitEnabled (1) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 1:limitEnabled: ", p), err)
+		}
 	}
-	if err := p.Query.Write(oprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Query), err)
+	return err
+}
+
+func (p *NodeSetPersistRateLimitRequest) writeField2(oprot thrift.TProtocol) (err error) {
+	if p.IsSetLimitMbps() {
+		if err := oprot.WriteFieldBegin("limitMbps", thrift.DOUBLE, 2); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:limitMbps: ", p), err)
+		}
+		if err := oprot.WriteDouble(float64(*p.LimitMbps)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.limitMbps (2) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 2:limitMbps: ", p), err)
+		}
 	}
-	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:query: ", p), err)
+	return err
+}
+
+func (p *NodeSetPersistRateLimitRequest) writeField3(oprot thrift.TProtocol) (err error) {
+	if p.IsSetLimitCheckEvery() {
+		if err := oprot.WriteFieldBegin("limitCheckEvery", thrift.I64, 3); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:limitCheckEvery: ", p), err)
+		}
+		if err := oprot.WriteI64(int64(*p.LimitCheckEvery)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.limitCheckEvery (3) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 3:limitCheckEvery: ", p), err)
+		}
 	}
 	return err
 }
 
-func (p *NegationQuery) String() string {
+func (p *NodeSetPersistRateLimitRequest) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NegationQuery(%+v)", *p)
+	return fmt.Sprintf("NodeSetPersistRateLimitRequest(%+v)", *p)
 }
 
 // Attributes:
-//  - Queries
-type ConjunctionQuery struct {
-	Queries []*Query `thrift:"queries,1,required" db:"queries" json:"queries"`
+//  - WriteNewSeriesAsync
+type NodeWriteNewSeriesAsyncResult_ struct {
+	WriteNewSeriesAsync bool `thrift:"writeNewSeriesAsync,1,required" db:"writeNewSeriesAsync" json:"writeNewSeriesAsync"`
 }
 
-func NewConjunctionQuery() *ConjunctionQuery {
-	return &ConjunctionQuery{}
+func NewNodeWriteNewSeriesAsyncResult_() *NodeWriteNewSeriesAsyncResult_ {
+	return &NodeWriteNewSeriesAsyncResult_{}
 }
 
-func (p *ConjunctionQuery) GetQueries() []*Query {
-	return p.Queries
+func (p *NodeWriteNewSeriesAsyncResult_) GetWriteNewSeriesAsync() bool {
+	return p.WriteNewSeriesAsync
 }
-func (p *ConjunctionQuery) Read(iprot thrift.TProtocol) error {
+func (p *NodeWriteNewSeriesAsyncResult_) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetQueries bool = false
+	var issetWriteNewSeriesAsync bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -10047,7 +10810,7 @@ func (p *ConjunctionQuery) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetQueries = true
+			issetWriteNewSeriesAsync = true
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -10060,34 +10823,23 @@ func (p *ConjunctionQuery) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetQueries {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Queries is not set"))
+	if !issetWriteNewSeriesAsync {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field WriteNewSeriesAsync is not set"))
 	}
 	return nil
 }
 
-func (p *ConjunctionQuery) ReadField1(iprot thrift.TProtocol) error {
-	_, size, err := iprot.ReadListBegin()
-	if err != nil {
-		return thrift.PrependError("error reading list begin: ", err)
-	}
-	tSlice := make([]*Query, 0, size)
-	p.Queries = tSlice
-	for i := 0; i < size; i++ {
-		_elem21 := &Query{}
-		if err := _elem21.Read(iprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem21), err)
-		}
-		p.Queries = append(p.Queries, _elem21)
-	}
-	if err := iprot.ReadListEnd(); err != nil {
-		return thrift.PrependError("error reading list end: ", err)
+func (p *NodeWriteNewSeriesAsyncResult_) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
+	} else {
+		p.WriteNewSeriesAsync = v
 	}
 	return nil
 }
 
-func (p *ConjunctionQuery) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("ConjunctionQuery"); err != nil {
+func (p *NodeWriteNewSeriesAsyncResult_) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("NodeWriteNewSeriesAsyncResult"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -10104,53 +10856,45 @@ func (p *ConjunctionQuery) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *ConjunctionQuery) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("queries", thrift.LIST, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:queries: ", p), err)
-	}
-	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Queries)); err != nil {
-		return thrift.PrependError("error writing list begin: ", err)
-	}
-	for _, v := range p.Queries {
-		if err := v.Write(oprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
-		}
+func (p *NodeWriteNewSeriesAsyncResult_) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("writeNewSeriesAsync", thrift.BOOL, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:writeNewSeriesAsync: ", p), err)
 	}
-	if err := oprot.WriteListEnd(); err != nil {
-		return thrift.PrependError("error writing list end: ", err)
+	if err := oprot.WriteBool(bool(p.WriteNewSeriesAsync)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.writeNewSeriesAsync (1) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:queries: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:writeNewSeriesAsync: ", p), err)
 	}
 	return err
 }
 
-func (p *ConjunctionQuery) String() string {
+func (p *NodeWriteNewSeriesAsyncResult_) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("ConjunctionQuery(%+v)", *p)
+	return fmt.Sprintf("NodeWriteNewSeriesAsyncResult_(%+v)", *p)
 }
 
 // Attributes:
-//  - Queries
-type DisjunctionQuery struct {
-	Queries []*Query `thrift:"queries,1,required" db:"queries" json:"queries"`
+//  - WriteNewSeriesAsync
+type NodeSetWriteNewSeriesAsyncRequest struct {
+	WriteNewSeriesAsync bool `thrift:"writeNewSeriesAsync,1,required" db:"writeNewSeriesAsync" json:"writeNewSeriesAsync"`
 }
 
-func NewDisjunctionQuery() *DisjunctionQuery {
-	return &DisjunctionQuery{}
+func NewNodeSetWriteNewSeriesAsyncRequest() *NodeSetWriteNewSeriesAsyncRequest {
+	return &NodeSetWriteNewSeriesAsyncRequest{}
 }
 
-func (p *DisjunctionQuery) GetQueries() []*Query {
-	return p.Queries
+func (p *NodeSetWriteNewSeriesAsyncRequest) GetWriteNewSeriesAsync() bool {
+	return p.WriteNewSeriesAsync
 }
-func (p *DisjunctionQuery) Read(iprot thrift.TProtocol) error {
+func (p *NodeSetWriteNewSeriesAsyncRequest) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetQueries bool = false
+	var issetWriteNewSeriesAsync bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -10165,7 +10909,7 @@ func (p *DisjunctionQuery) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetQueries = true
+			issetWriteNewSeriesAsync = true
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -10178,34 +10922,23 @@ func (p *DisjunctionQuery) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetQueries {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Queries is not set"))
+	if !issetWriteNewSeriesAsync {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field WriteNewSeriesAsync is not set"))
 	}
 	return nil
 }
 
-func (p *DisjunctionQuery) ReadField1(iprot thrift.TProtocol) error {
-	_, size, err := iprot.ReadListBegin()
-	if err != nil {
-		return thrift.PrependError("error reading list begin: ", err)
-	}
-	tSlice := make([]*Query, 0, size)
-	p.Queries = tSlice
-	for i := 0; i < size; i++ {
-		_elem22 := &Query{}
-		if err := _elem22.Read(iprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem22), err)
-		}
-		p.Queries = append(p.Queries, _elem22)
-	}
-	if err := iprot.ReadListEnd(); err != nil {
-		return thrift.PrependError("error reading list end: ", err)
+func (p *NodeSetWriteNewSeriesAsyncRequest) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
+	} else {
+		p.WriteNewSeriesAsync = v
 	}
 	return nil
 }
 
-func (p *DisjunctionQuery) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("DisjunctionQuery"); err != nil {
+func (p *NodeSetWriteNewSeriesAsyncRequest) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("NodeSetWriteNewSeriesAsyncRequest"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -10222,121 +10955,53 @@ func (p *DisjunctionQuery) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *DisjunctionQuery) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("queries", thrift.LIST, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:queries: ", p), err)
-	}
-	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Queries)); err != nil {
-		return thrift.PrependError("error writing list begin: ", err)
-	}
-	for _, v := range p.Queries {
-		if err := v.Write(oprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
-		}
+func (p *NodeSetWriteNewSeriesAsyncRequest) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("writeNewSeriesAsync", thrift.BOOL, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:writeNewSeriesAsync: ", p), err)
 	}
-	if err := oprot.WriteListEnd(); err != nil {
-		return thrift.PrependError("error writing list end: ", err)
+	if err := oprot.WriteBool(bool(p.WriteNewSeriesAsync)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.writeNewSeriesAsync (1) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:queries: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:writeNewSeriesAsync: ", p), err)
 	}
 	return err
 }
 
-func (p *DisjunctionQuery) String() string {
+func (p *NodeSetWriteNewSeriesAsyncRequest) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("DisjunctionQuery(%+v)", *p)
+	return fmt.Sprintf("NodeSetWriteNewSeriesAsyncRequest(%+v)", *p)
 }
 
 // Attributes:
-//  - Term
-//  - Regexp
-//  - Negation
-//  - Conjunction
-//  - Disjunction
-type Query struct {
-	Term        *TermQuery        `thrift:"term,1" db:"term" json:"term,omitempty"`
-	Regexp      *RegexpQuery      `thrift:"regexp,2" db:"regexp" json:"regexp,omitempty"`
-	Negation    *NegationQuery    `thrift:"negation,3" db:"negation" json:"negation,omitempty"`
-	Conjunction *ConjunctionQuery `thrift:"conjunction,4" db:"conjunction" json:"conjunction,omitempty"`
-	Disjunction *DisjunctionQuery `thrift:"disjunction,5" db:"disjunction" json:"disjunction,omitempty"`
-}
-
-func NewQuery() *Query {
-	return &Query{}
-}
-
-var Query_Term_DEFAULT *TermQuery
-
-func (p *Query) GetTerm() *TermQuery {
-	if !p.IsSetTerm() {
-		return Query_Term_DEFAULT
-	}
-	return p.Term
-}
-
-var Query_Regexp_DEFAULT *RegexpQuery
-
-func (p *Query) GetRegexp() *RegexpQuery {
-	if !p.IsSetRegexp() {
-		return Query_Regexp_DEFAULT
-	}
-	return p.Regexp
-}
-
-var Query_Negation_DEFAULT *NegationQuery
-
-func (p *Query) GetNegation() *NegationQuery {
-	if !p.IsSetNegation() {
-		return Query_Negation_DEFAULT
-	}
-	return p.Negation
-}
-
-var Query_Conjunction_DEFAULT *ConjunctionQuery
-
-func (p *Query) GetConjunction() *ConjunctionQuery {
-	if !p.IsSetConjunction() {
-		return Query_Conjunction_DEFAULT
-	}
-	return p.Conjunction
-}
-
-var Query_Disjunction_DEFAULT *DisjunctionQuery
-
-func (p *Query) GetDisjunction() *DisjunctionQuery {
-	if !p.IsSetDisjunction() {
-		return Query_Disjunction_DEFAULT
-	}
-	return p.Disjunction
-}
-func (p *Query) IsSetTerm() bool {
-	return p.Term != nil
-}
-
-func (p *Query) IsSetRegexp() bool {
-	return p.Regexp != nil
+//  - WriteNewSeriesBackoffDuration
+//  - DurationType
+type NodeWriteNewSeriesBackoffDurationResult_ struct {
+	WriteNewSeriesBackoffDuration int64    `thrift:"writeNewSeriesBackoffDuration,1,required" db:"writeNewSeriesBackoffDuration" json:"writeNewSeriesBackoffDuration"`
+	DurationType                  TimeType `thrift:"durationType,2,required" db:"durationType" json:"durationType"`
 }
 
-func (p *Query) IsSetNegation() bool {
-	return p.Negation != nil
+func NewNodeWriteNewSeriesBackoffDurationResult_() *NodeWriteNewSeriesBackoffDurationResult_ {
+	return &NodeWriteNewSeriesBackoffDurationResult_{}
 }
 
-func (p *Query) IsSetConjunction() bool {
-	return p.Conjunction != nil
+func (p *NodeWriteNewSeriesBackoffDurationResult_) GetWriteNewSeriesBackoffDuration() int64 {
+	return p.WriteNewSeriesBackoffDuration
 }
 
-func (p *Query) IsSetDisjunction() bool {
-	return p.Disjunction != nil
+func (p *NodeWriteNewSeriesBackoffDurationResult_) GetDurationType() TimeType {
+	return p.DurationType
 }
-
-func (p *Query) Read(iprot thrift.TProtocol) error {
+func (p *NodeWriteNewSeriesBackoffDurationResult_) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
+	var issetWriteNewSeriesBackoffDuration bool = false
+	var issetDurationType bool = false
+
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
 		if err != nil {
@@ -10350,22 +11015,12 @@ func (p *Query) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
+			issetWriteNewSeriesBackoffDuration = true
 		case 2:
 			if err := p.ReadField2(iprot); err != nil {
 				return err
 			}
-		case 3:
-			if err := p.ReadField3(iprot); err != nil {
-				return err
-			}
-		case 4:
-			if err := p.ReadField4(iprot); err != nil {
-				return err
-			}
-		case 5:
-			if err := p.ReadField5(iprot); err != nil {
-				return err
-			}
+			issetDurationType = true
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -10378,51 +11033,36 @@ func (p *Query) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	return nil
-}
-
-func (p *Query) ReadField1(iprot thrift.TProtocol) error {
-	p.Term = &TermQuery{}
-	if err := p.Term.Read(iprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Term), err)
-	}
-	return nil
-}
-
-func (p *Query) ReadField2(iprot thrift.TProtocol) error {
-	p.Regexp = &RegexpQuery{}
-	if err := p.Regexp.Read(iprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Regexp), err)
+	if !issetWriteNewSeriesBackoffDuration {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field WriteNewSeriesBackoffDuration is not set"))
 	}
-	return nil
-}
-
-func (p *Query) ReadField3(iprot thrift.TProtocol) error {
-	p.Negation = &NegationQuery{}
-	if err := p.Negation.Read(iprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Negation), err)
+	if !issetDurationType {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field DurationType is not set"))
 	}
 	return nil
 }
 
-func (p *Query) ReadField4(iprot thrift.TProtocol) error {
-	p.Conjunction = &ConjunctionQuery{}
-	if err := p.Conjunction.Read(iprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Conjunction), err)
+func (p *NodeWriteNewSeriesBackoffDurationResult_) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
+	} else {
+		p.WriteNewSeriesBackoffDuration = v
 	}
 	return nil
 }
 
-func (p *Query) ReadField5(iprot thrift.TProtocol) error {
-	p.Disjunction = &DisjunctionQuery{}
-	if err := p.Disjunction.Read(iprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Disjunction), err)
+func (p *NodeWriteNewSeriesBackoffDurationResult_) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI32(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		temp := TimeType(v)
+		p.DurationType = temp
 	}
 	return nil
 }
 
-func (p *Query) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("Query"); err != nil {
+func (p *NodeWriteNewSeriesBackoffDurationResult_) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("NodeWriteNewSeriesBackoffDurationResult"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -10432,15 +11072,6 @@ func (p *Query) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField2(oprot); err != nil {
 			return err
 		}
-		if err := p.writeField3(oprot); err != nil {
-			return err
-		}
-		if err := p.writeField4(oprot); err != nil {
-			return err
-		}
-		if err := p.writeField5(oprot); err != nil {
-			return err
-		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -10451,562 +11082,3740 @@ func (p *Query) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *Query) writeField1(oprot thrift.TProtocol) (err error) {
-	if p.IsSetTerm() {
-		if err := oprot.WriteFieldBegin("term", thrift.STRUCT, 1); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:term: ", p), err)
-		}
-		if err := p.Term.Write(oprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Term), err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 1:term: ", p), err)
-		}
+func (p *NodeWriteNewSeriesBackoffDurationResult_) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("writeNewSeriesBackoffDuration", thrift.I64, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:writeNewSeriesBackoffDuration: ", p), err)
+	}
+	if err := oprot.WriteI64(int64(p.WriteNewSeriesBackoffDuration)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.writeNewSeriesBackoffDuration (1) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:writeNewSeriesBackoffDuration: ", p), err)
 	}
 	return err
 }
 
-func (p *Query) writeField2(oprot thrift.TProtocol) (err error) {
-	if p.IsSetRegexp() {
-		if err := oprot.WriteFieldBegin("regexp", thrift.STRUCT, 2); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:regexp: ", p), err)
-		}
-		if err := p.Regexp.Write(oprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Regexp), err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 2:regexp: ", p), err)
-		}
+func (p *NodeWriteNewSeriesBackoffDurationResult_) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("durationType", thrift.I32, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:durationType: ", p), err)
+	}
+	if err := oprot.WriteI32(int32(p.DurationType)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.durationType (2) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:durationType: ", p), err)
 	}
 	return err
 }
 
-func (p *Query) writeField3(oprot thrift.TProtocol) (err error) {
-	if p.IsSetNegation() {
-		if err := oprot.WriteFieldBegin("negation", thrift.STRUCT, 3); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:negation: ", p), err)
+func (p *NodeWriteNewSeriesBackoffDurationResult_) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("NodeWriteNewSeriesBackoffDurationResult_(%+v)", *p)
+}
+
+// Attributes:
+//  - WriteNewSeriesBackoffDuration
+//  - DurationType
+type NodeSetWriteNewSeriesBackoffDurationRequest struct {
+	WriteNewSeriesBackoffDuration int64    `thrift:"writeNewSeriesBackoffDuration,1,required" db:"writeNewSeriesBackoffDuration" json:"writeNewSeriesBackoffDuration"`
+	DurationType                  TimeType `thrift:"durationType,2" db:"durationType" json:"durationType,omitempty"`
+}
+
+func NewNodeSetWriteNewSeriesBackoffDurationRequest() *NodeSetWriteNewSeriesBackoffDurationRequest {
+	return &NodeSetWriteNewSeriesBackoffDurationRequest{
+		DurationType: 2,
+	}
+}
+
+func (p *NodeSetWriteNewSeriesBackoffDurationRequest) GetWriteNewSeriesBackoffDuration() int64 {
+	return p.WriteNewSeriesBackoffDuration
+}
+
+var NodeSetWriteNewSeriesBackoffDurationRequest_DurationType_DEFAULT TimeType = 2
+
+func (p *NodeSetWriteNewSeriesBackoffDurationRequest) GetDurationType() TimeType {
+	return p.DurationType
+}
+func (p *NodeSetWriteNewSeriesBackoffDurationRequest) IsSetDurationType() bool {
+	return p.DurationType != NodeSetWriteNewSeriesBackoffDurationRequest_DurationType_DEFAULT
+}
+
+func (p *NodeSetWriteNewSeriesBackoffDurationRequest) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	var issetWriteNewSeriesBackoffDuration bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
 		}
-		if err := p.Negation.Write(oprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Negation), err)
+		if fieldTypeId == thrift.STOP {
+			break
 		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 3:negation: ", p), err)
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetWriteNewSeriesBackoffDuration = true
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
+				return err
+			}
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
 		}
 	}
-	return err
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	if !issetWriteNewSeriesBackoffDuration {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field WriteNewSeriesBackoffDuration is not set"))
+	}
+	return nil
 }
 
-func (p *Query) writeField4(oprot thrift.TProtocol) (err error) {
-	if p.IsSetConjunction() {
-		if err := oprot.WriteFieldBegin("conjunction", thrift.STRUCT, 4); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:conjunction: ", p), err)
-		}
-		if err := p.Conjunction.Write(oprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Conjunction), err)
+func (p *NodeSetWriteNewSeriesBackoffDurationRequest) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
+	} else {
+		p.WriteNewSeriesBackoffDuration = v
+	}
+	return nil
+}
+
+func (p *NodeSetWriteNewSeriesBackoffDurationRequest) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI32(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		temp := TimeType(v)
+		p.DurationType = temp
+	}
+	return nil
+}
+
+func (p *NodeSetWriteNewSeriesBackoffDurationRequest) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("NodeSetWriteNewSeriesBackoffDurationRequest"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
 		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 4:conjunction: ", p), err)
+		if err := p.writeField2(oprot); err != nil {
+			return err
 		}
 	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *NodeSetWriteNewSeriesBackoffDurationRequest) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("writeNewSeriesBackoffDuration", thrift.I64, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:writeNewSeriesBackoffDuration: ", p), err)
+	}
+	if err := oprot.WriteI64(int64(p.WriteNewSeriesBackoffDuration)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.writeNewSeriesBackoffDuration (1) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:writeNewSeriesBackoffDuration: ", p), err)
+	}
 	return err
 }
 
-func (p *Query) writeField5(oprot thrift.TProtocol) (err error) {
-	if p.IsSetDisjunction() {
-		if err := oprot.WriteFieldBegin("disjunction", thrift.STRUCT, 5); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 5:disjunction: ", p), err)
+func (p *NodeSetWriteNewSeriesBackoffDurationRequest) writeField2(oprot thrift.TProtocol) (err error) {
+	if p.IsSetDurationType() {
+		if err := oprot.WriteFieldBegin("durationType", thrift.I32, 2); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:durationType: ", p), err)
 		}
-		if err := p.Disjunction.Write(oprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Disjunction), err)
+		if err := oprot.WriteI32(int32(p.DurationType)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.durationType (2) field write error: ", p), err)
 		}
 		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 5:disjunction: ", p), err)
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 2:durationType: ", p), err)
 		}
 	}
 	return err
 }
 
-func (p *Query) String() string {
+func (p *NodeSetWriteNewSeriesBackoffDurationRequest) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("Query(%+v)", *p)
+	return fmt.Sprintf("NodeSetWriteNewSeriesBackoffDurationRequest(%+v)", *p)
 }
 
-type Node interface {
-	// Parameters:
-	//  - Req
-	Query(req *QueryRequest) (r *QueryResult_, err error)
-	// Parameters:
-	//  - Req
-	Fetch(req *FetchRequest) (r *FetchResult_, err error)
-	// Parameters:
-	//  - Req
-	FetchTagged(req *FetchTaggedRequest) (r *FetchTaggedResult_, err error)
-	// Parameters:
-	//  - Req
-	Write(req *WriteRequest) (err error)
-	// Parameters:
-	//  - Req
-	WriteTagged(req *WriteTaggedRequest) (err error)
-	// Parameters:
-	//  - Req
-	FetchBatchRaw(req *FetchBatchRawRequest) (r *FetchBatchRawResult_, err error)
-	// Parameters:
-	//  - Req
-	FetchBlocksRaw(req *FetchBlocksRawRequest) (r *FetchBlocksRawResult_, err error)
-	// Parameters:
-	//  - Req
-	FetchBlocksMetadataRaw(req *FetchBlocksMetadataRawRequest) (r *FetchBlocksMetadataRawResult_, err error)
-	// Parameters:
-	//  - Req
-	FetchBlocksMetadataRawV2(req *FetchBlocksMetadataRawV2Request) (r *FetchBlocksMetadataRawV2Result_, err error)
-	// Parameters:
-	//  - Req
-	WriteBatchRaw(req *WriteBatchRawRequest) (err error)
-	// Parameters:
-	//  - Req
-	WriteTaggedBatchRaw(req *WriteTaggedBatchRawRequest) (err error)
-	Repair() (err error)
-	// Parameters:
-	//  - Req
-	Truncate(req *TruncateRequest) (r *TruncateResult_, err error)
-	Health() (r *NodeHealthResult_, err error)
-	Bootstrapped() (r *NodeBootstrappedResult_, err error)
-	GetPersistRateLimit() (r *NodePersistRateLimitResult_, err error)
-	// Parameters:
-	//  - Req
-	SetPersistRateLimit(req *NodeSetPersistRateLimitRequest) (r *NodePersistRateLimitResult_, err error)
-	GetWriteNewSeriesAsync() (r *NodeWriteNewSeriesAsyncResult_, err error)
-	// Parameters:
-	//  - Req
-	SetWriteNewSeriesAsync(req *NodeSetWriteNewSeriesAsyncRequest) (r *NodeWriteNewSeriesAsyncResult_, err error)
-	GetWriteNewSeriesBackoffDuration() (r *NodeWriteNewSeriesBackoffDurationResult_, err error)
-	// Parameters:
-	//  - Req
-	SetWriteNewSeriesBackoffDuration(req *NodeSetWriteNewSeriesBackoffDurationRequest) (r *NodeWriteNewSeriesBackoffDurationResult_, err error)
-	GetWriteNewSeriesLimitPerShardPerSecond() (r *NodeWriteNewSeriesLimitPerShardPerSecondResult_, err error)
-	// Parameters:
-	//  - Req
-	SetWriteNewSeriesLimitPerShardPerSecond(req *NodeSetWriteNewSeriesLimitPerShardPerSecondRequest) (r *NodeWriteNewSeriesLimitPerShardPerSecondResult_, err error)
+// Attributes:
+//  - WriteNewSeriesLimitPerShardPerSecond
+type NodeWriteNewSeriesLimitPerShardPerSecondResult_ struct {
+	WriteNewSeriesLimitPerShardPerSecond int64 `thrift:"writeNewSeriesLimitPerShardPerSecond,1,required" db:"writeNewSeriesLimitPerShardPerSecond" json:"writeNewSeriesLimitPerShardPerSecond"`
 }
 
-type NodeClient struct {
-	Transport       thrift.TTransport
-	ProtocolFactory thrift.TProtocolFactory
-	InputProtocol   thrift.TProtocol
-	OutputProtocol  thrift.TProtocol
-	SeqId           int32
+func NewNodeWriteNewSeriesLimitPerShardPerSecondResult_() *NodeWriteNewSeriesLimitPerShardPerSecondResult_ {
+	return &NodeWriteNewSeriesLimitPerShardPerSecondResult_{}
 }
 
-func NewNodeClientFactory(t thrift.TTransport, f thrift.TProtocolFactory) *NodeClient {
-	return &NodeClient{Transport: t,
-		ProtocolFactory: f,
-		InputProtocol:   f.GetProtocol(t),
-		OutputProtocol:  f.GetProtocol(t),
-		SeqId:           0,
-	}
+func (p *NodeWriteNewSeriesLimitPerShardPerSecondResult_) GetWriteNewSeriesLimitPerShardPerSecond() int64 {
+	return p.WriteNewSeriesLimitPerShardPerSecond
 }
-
-func NewNodeClientProtocol(t thrift.TTransport, iprot thrift.TProtocol, oprot thrift.TProtocol) *NodeClient {
-	return &NodeClient{Transport: t,
-		ProtocolFactory: nil,
-		InputProtocol:   iprot,
-		OutputProtocol:  oprot,
-		SeqId:           0,
+func (p *NodeWriteNewSeriesLimitPerShardPerSecondResult_) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
-}
 
-// Parameters:
-//  - Req
-func (p *NodeClient) Query(req *QueryRequest) (r *QueryResult_, err error) {
-	if err = p.sendQuery(req); err != nil {
-		return
-	}
-	return p.recvQuery()
-}
+	var issetWriteNewSeriesLimitPerShardPerSecond bool = false
 
-func (p *NodeClient) sendQuery(req *QueryRequest) (err error) {
-	oprot := p.OutputProtocol
-	if oprot == nil {
-		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.OutputProtocol = oprot
-	}
-	p.SeqId++
-	if err = oprot.WriteMessageBegin("query", thrift.CALL, p.SeqId); err != nil {
-		return
-	}
-	args := NodeQueryArgs{
-		Req: req,
-	}
-	if err = args.Write(oprot); err != nil {
-		return
-	}
-	if err = oprot.WriteMessageEnd(); err != nil {
-		return
-	}
-	return oprot.Flush()
-}
-
-func (p *NodeClient) recvQuery() (value *QueryResult_, err error) {
-	iprot := p.InputProtocol
-	if iprot == nil {
-		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.InputProtocol = iprot
-	}
-	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
-	if err != nil {
-		return
-	}
-	if method != "query" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "query failed: wrong method name")
-		return
-	}
-	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "query failed: out of sequence response")
-		return
-	}
-	if mTypeId == thrift.EXCEPTION {
-		error23 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error24 error
-		error24, err = error23.Read(iprot)
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
 		if err != nil {
-			return
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
 		}
-		if err = iprot.ReadMessageEnd(); err != nil {
-			return
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetWriteNewSeriesLimitPerShardPerSecond = true
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
 		}
-		err = error24
-		return
-	}
-	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "query failed: invalid message type")
-		return
-	}
-	result := NodeQueryResult{}
-	if err = result.Read(iprot); err != nil {
-		return
 	}
-	if err = iprot.ReadMessageEnd(); err != nil {
-		return
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if result.Err != nil {
-		err = result.Err
-		return
+	if !issetWriteNewSeriesLimitPerShardPerSecond {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field WriteNewSeriesLimitPerShardPerSecond is not set"))
 	}
-	value = result.GetSuccess()
-	return
+	return nil
 }
 
-// Parameters:
-//  - Req
-func (p *NodeClient) Fetch(req *FetchRequest) (r *FetchResult_, err error) {
-	if err = p.sendFetch(req); err != nil {
-		return
+func (p *NodeWriteNewSeriesLimitPerShardPerSecondResult_) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
+	} else {
+		p.WriteNewSeriesLimitPerShardPerSecond = v
 	}
-	return p.recvFetch()
+	return nil
 }
 
-func (p *NodeClient) sendFetch(req *FetchRequest) (err error) {
-	oprot := p.OutputProtocol
-	if oprot == nil {
-		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.OutputProtocol = oprot
-	}
-	p.SeqId++
-	if err = oprot.WriteMessageBegin("fetch", thrift.CALL, p.SeqId); err != nil {
-		return
+func (p *NodeWriteNewSeriesLimitPerShardPerSecondResult_) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("NodeWriteNewSeriesLimitPerShardPerSecondResult"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
-	args := NodeFetchArgs{
-		Req: req,
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
 	}
-	if err = args.Write(oprot); err != nil {
-		return
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
 	}
-	if err = oprot.WriteMessageEnd(); err != nil {
-		return
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
 	}
-	return oprot.Flush()
+	return nil
 }
 
-func (p *NodeClient) recvFetch() (value *FetchResult_, err error) {
-	iprot := p.InputProtocol
-	if iprot == nil {
-		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.InputProtocol = iprot
+func (p *NodeWriteNewSeriesLimitPerShardPerSecondResult_) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("writeNewSeriesLimitPerShardPerSecond", thrift.I64, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:writeNewSeriesLimitPerShardPerSecond: ", p), err)
 	}
-	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
-	if err != nil {
-		return
+	if err := oprot.WriteI64(int64(p.WriteNewSeriesLimitPerShardPerSecond)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.writeNewSeriesLimitPerShardPerSecond (1) field write error: ", p), err)
 	}
-	if method != "fetch" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "fetch failed: wrong method name")
-		return
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:writeNewSeriesLimitPerShardPerSecond: ", p), err)
 	}
-	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "fetch failed: out of sequence response")
-		return
+	return err
+}
+
+func (p *NodeWriteNewSeriesLimitPerShardPerSecondResult_) String() string {
+	if p == nil {
+		return "<nil>"
 	}
-	if mTypeId == thrift.EXCEPTION {
-		error25 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error26 error
-		error26, err = error25.Read(iprot)
+	return fmt.Sprintf("NodeWriteNewSeriesLimitPerShardPerSecondResult_(%+v)", *p)
+}
+
+// Attributes:
+//  - WriteNewSeriesLimitPerShardPerSecond
+type NodeSetWriteNewSeriesLimitPerShardPerSecondRequest struct {
+	WriteNewSeriesLimitPerShardPerSecond int64 `thrift:"writeNewSeriesLimitPerShardPerSecond,1,required" db:"writeNewSeriesLimitPerShardPerSecond" json:"writeNewSeriesLimitPerShardPerSecond"`
+}
+
+func NewNodeSetWriteNewSeriesLimitPerShardPerSecondRequest() *NodeSetWriteNewSeriesLimitPerShardPerSecondRequest {
+	return &NodeSetWriteNewSeriesLimitPerShardPerSecondRequest{}
+}
+
+func (p *NodeSetWriteNewSeriesLimitPerShardPerSecondRequest) GetWriteNewSeriesLimitPerShardPerSecond() int64 {
+	return p.WriteNewSeriesLimitPerShardPerSecond
+}
+func (p *NodeSetWriteNewSeriesLimitPerShardPerSecondRequest) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	var issetWriteNewSeriesLimitPerShardPerSecond bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
 		if err != nil {
-			return
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
 		}
-		if err = iprot.ReadMessageEnd(); err != nil {
-			return
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetWriteNewSeriesLimitPerShardPerSecond = true
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
 		}
-		err = error26
-		return
-	}
-	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "fetch failed: invalid message type")
-		return
-	}
-	result := NodeFetchResult{}
-	if err = result.Read(iprot); err != nil {
-		return
 	}
-	if err = iprot.ReadMessageEnd(); err != nil {
-		return
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if result.Err != nil {
-		err = result.Err
-		return
+	if !issetWriteNewSeriesLimitPerShardPerSecond {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field WriteNewSeriesLimitPerShardPerSecond is not set"))
 	}
-	value = result.GetSuccess()
-	return
+	return nil
 }
 
-// Parameters:
-//  - Req
-func (p *NodeClient) FetchTagged(req *FetchTaggedRequest) (r *FetchTaggedResult_, err error) {
-	if err = p.sendFetchTagged(req); err != nil {
-		return
+func (p *NodeSetWriteNewSeriesLimitPerShardPerSecondRequest) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
+	} else {
+		p.WriteNewSeriesLimitPerShardPerSecond = v
 	}
-	return p.recvFetchTagged()
+	return nil
 }
 
-func (p *NodeClient) sendFetchTagged(req *FetchTaggedRequest) (err error) {
-	oprot := p.OutputProtocol
-	if oprot == nil {
-		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.OutputProtocol = oprot
+func (p *NodeSetWriteNewSeriesLimitPerShardPerSecondRequest) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("NodeSetWriteNewSeriesLimitPerShardPerSecondRequest"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
-	p.SeqId++
-	if err = oprot.WriteMessageBegin("fetchTagged", thrift.CALL, p.SeqId); err != nil {
-		return
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
 	}
-	args := NodeFetchTaggedArgs{
-		Req: req,
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
 	}
-	if err = args.Write(oprot); err != nil {
-		return
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
 	}
-	if err = oprot.WriteMessageEnd(); err != nil {
-		return
-	}
-	return oprot.Flush()
+	return nil
 }
 
-func (p *NodeClient) recvFetchTagged() (value *FetchTaggedResult_, err error) {
-	iprot := p.InputProtocol
-	if iprot == nil {
-		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.InputProtocol = iprot
+func (p *NodeSetWriteNewSeriesLimitPerShardPerSecondRequest) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("writeNewSeriesLimitPerShardPerSecond", thrift.I64, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:writeNewSeriesLimitPerShardPerSecond: ", p), err)
 	}
-	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
-	if err != nil {
-		return
+	if err := oprot.WriteI64(int64(p.WriteNewSeriesLimitPerShardPerSecond)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.writeNewSeriesLimitPerShardPerSecond (1) field write error: ", p), err)
 	}
-	if method != "fetchTagged" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "fetchTagged failed: wrong method name")
-		return
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:writeNewSeriesLimitPerShardPerSecond: ", p), err)
 	}
-	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "fetchTagged failed: out of sequence response")
-		return
+	return err
+}
+
+func (p *NodeSetWriteNewSeriesLimitPerShardPerSecondRequest) String() string {
+	if p == nil {
+		return "<nil>"
 	}
-	if mTypeId == thrift.EXCEPTION {
-		error27 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error28 error
-		error28, err = error27.Read(iprot)
+	return fmt.Sprintf("NodeSetWriteNewSeriesLimitPerShardPerSecondRequest(%+v)", *p)
+}
+
+// Attributes:
+//  - Ok
+//  - Status
+type HealthResult_ struct {
+	Ok     bool   `thrift:"ok,1,required" db:"ok" json:"ok"`
+	Status string `thrift:"status,2,required" db:"status" json:"status"`
+}
+
+func NewHealthResult_() *HealthResult_ {
+	return &HealthResult_{}
+}
+
+func (p *HealthResult_) GetOk() bool {
+	return p.Ok
+}
+
+func (p *HealthResult_) GetStatus() string {
+	return p.Status
+}
+func (p *HealthResult_) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	var issetOk bool = false
+	var issetStatus bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
 		if err != nil {
-			return
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
 		}
-		if err = iprot.ReadMessageEnd(); err != nil {
-			return
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetOk = true
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
+				return err
+			}
+			issetStatus = true
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
 		}
-		err = error28
-		return
-	}
-	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "fetchTagged failed: invalid message type")
-		return
 	}
-	result := NodeFetchTaggedResult{}
-	if err = result.Read(iprot); err != nil {
-		return
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if err = iprot.ReadMessageEnd(); err != nil {
-		return
+	if !issetOk {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Ok is not set"))
 	}
-	if result.Err != nil {
-		err = result.Err
-		return
+	if !issetStatus {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Status is not set"))
 	}
-	value = result.GetSuccess()
-	return
+	return nil
 }
 
-// Parameters:
-//  - Req
-func (p *NodeClient) Write(req *WriteRequest) (err error) {
-	if err = p.sendWrite(req); err != nil {
-		return
+func (p *HealthResult_) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
+	} else {
+		p.Ok = v
 	}
-	return p.recvWrite()
+	return nil
 }
 
-func (p *NodeClient) sendWrite(req *WriteRequest) (err error) {
-	oprot := p.OutputProtocol
-	if oprot == nil {
-		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.OutputProtocol = oprot
-	}
-	p.SeqId++
-	if err = oprot.WriteMessageBegin("write", thrift.CALL, p.SeqId); err != nil {
-		return
-	}
-	args := NodeWriteArgs{
-		Req: req,
-	}
-	if err = args.Write(oprot); err != nil {
-		return
-	}
-	if err = oprot.WriteMessageEnd(); err != nil {
-		return
+func (p *HealthResult_) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		p.Status = v
 	}
-	return oprot.Flush()
+	return nil
 }
 
-func (p *NodeClient) recvWrite() (err error) {
-	iprot := p.InputProtocol
-	if iprot == nil {
-		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.InputProtocol = iprot
-	}
-	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
-	if err != nil {
-		return
-	}
-	if method != "write" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "write failed: wrong method name")
-		return
-	}
-	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "write failed: out of sequence response")
-		return
+func (p *HealthResult_) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("HealthResult"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
-	if mTypeId == thrift.EXCEPTION {
-		error29 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error30 error
-		error30, err = error29.Read(iprot)
-		if err != nil {
-			return
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
 		}
-		if err = iprot.ReadMessageEnd(); err != nil {
-			return
+		if err := p.writeField2(oprot); err != nil {
+			return err
 		}
-		err = error30
-		return
-	}
-	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "write failed: invalid message type")
-		return
-	}
-	result := NodeWriteResult{}
-	if err = result.Read(iprot); err != nil {
-		return
 	}
-	if err = iprot.ReadMessageEnd(); err != nil {
-		return
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
 	}
-	if result.Err != nil {
-		err = result.Err
-		return
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
 	}
-	return
+	return nil
 }
 
-// Parameters:
-//  - Req
-func (p *NodeClient) WriteTagged(req *WriteTaggedRequest) (err error) {
-	if err = p.sendWriteTagged(req); err != nil {
-		return
+func (p *HealthResult_) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("ok", thrift.BOOL, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:ok: ", p), err)
 	}
-	return p.recvWriteTagged()
-}
-
-func (p *NodeClient) sendWriteTagged(req *WriteTaggedRequest) (err error) {
-	oprot := p.OutputProtocol
-	if oprot == nil {
-		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.OutputProtocol = oprot
+	if err := oprot.WriteBool(bool(p.Ok)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.ok (1) field write error: ", p), err)
 	}
-	p.SeqId++
-	if err = oprot.WriteMessageBegin("writeTagged", thrift.CALL, p.SeqId); err != nil {
-		return
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:ok: ", p), err)
 	}
-	args := NodeWriteTaggedArgs{
-		Req: req,
+	return err
+}
+
+func (p *HealthResult_) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("status", thrift.STRING, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:status: ", p), err)
 	}
-	if err = args.Write(oprot); err != nil {
-		return
+	if err := oprot.WriteString(string(p.Status)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.status (2) field write error: ", p), err)
 	}
-	if err = oprot.WriteMessageEnd(); err != nil {
-		return
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:status: ", p), err)
 	}
-	return oprot.Flush()
+	return err
 }
 
-func (p *NodeClient) recvWriteTagged() (err error) {
-	iprot := p.InputProtocol
-	if iprot == nil {
-		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.InputProtocol = iprot
+func (p *HealthResult_) String() string {
+	if p == nil {
+		return "<nil>"
 	}
-	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
-	if err != nil {
+	return fmt.Sprintf("HealthResult_(%+v)", *p)
+}
+
+// Attributes:
+//  - Query
+//  - RangeStart
+//  - RangeEnd
+//  - NameSpace
+//  - Limit
+//  - NoData
+//  - RangeType
+//  - ResultTimeType
+type QueryRequest struct {
+	Query          *Query   `thrift:"query,1,required" db:"query" json:"query"`
+	RangeStart     int64    `thrift:"rangeStart,2,required" db:"rangeStart" json:"rangeStart"`
+	RangeEnd       int64    `thrift:"rangeEnd,3,required" db:"rangeEnd" json:"rangeEnd"`
+	NameSpace      string   `thrift:"nameSpace,4,required" db:"nameSpace" json:"nameSpace"`
+	Limit          *int64   `thrift:"limit,5" db:"limit" json:"limit,omitempty"`
+	NoData         *bool    `thrift:"noData,6" db:"noData" json:"noData,omitempty"`
+	RangeType      TimeType `thrift:"rangeType,7" db:"rangeType" json:"rangeType,omitempty"`
+	ResultTimeType TimeType `thrift:"resultTimeType,8" db:"resultTimeType" json:"resultTimeType,omitempty"`
+}
+
+func NewQueryRequest() *QueryRequest {
+	return &QueryRequest{
+		RangeType: 0,
+
+		ResultTimeType: 0,
+	}
+}
+
+var QueryRequest_Query_DEFAULT *Query
+
+func (p *QueryRequest) GetQuery() *Query {
+	if !p.IsSetQuery() {
+		return QueryRequest_Query_DEFAULT
+	}
+	return p.Query
+}
+
+func (p *QueryRequest) GetRangeStart() int64 {
+	return p.RangeStart
+}
+
+func (p *QueryRequest) GetRangeEnd() int64 {
+	return p.RangeEnd
+}
+
+func (p *QueryRequest) GetNameSpace() string {
+	return p.NameSpace
+}
+
+var QueryRequest_Limit_DEFAULT int64
+
+func (p *QueryRequest) GetLimit() int64 {
+	if !p.IsSetLimit() {
+		return QueryRequest_Limit_DEFAULT
+	}
+	return *p.Limit
+}
+
+var QueryRequest_NoData_DEFAULT bool
+
+func (p *QueryRequest) GetNoData() bool {
+	if !p.IsSetNoData() {
+		return QueryRequest_NoData_DEFAULT
+	}
+	return *p.NoData
+}
+
+var QueryRequest_RangeType_DEFAULT TimeType = 0
+
+func (p *QueryRequest) GetRangeType() TimeType {
+	return p.RangeType
+}
+
+var QueryRequest_ResultTimeType_DEFAULT TimeType = 0
+
+func (p *QueryRequest) GetResultTimeType() TimeType {
+	return p.ResultTimeType
+}
+func (p *QueryRequest) IsSetQuery() bool {
+	return p.Query != nil
+}
+
+func (p *QueryRequest) IsSetLimit() bool {
+	return p.Limit != nil
+}
+
+func (p *QueryRequest) IsSetNoData() bool {
+	return p.NoData != nil
+}
+
+func (p *QueryRequest) IsSetRangeType() bool {
+	return p.RangeType != QueryRequest_RangeType_DEFAULT
+}
+
+func (p *QueryRequest) IsSetResultTimeType() bool {
+	return p.ResultTimeType != QueryRequest_ResultTimeType_DEFAULT
+}
+
+func (p *QueryRequest) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	var issetQuery bool = false
+	var issetRangeStart bool = false
+	var issetRangeEnd bool = false
+	var issetNameSpace bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetQuery = true
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
+				return err
+			}
+			issetRangeStart = true
+		case 3:
+			if err := p.ReadField3(iprot); err != nil {
+				return err
+			}
+			issetRangeEnd = true
+		case 4:
+			if err := p.ReadField4(iprot); err != nil {
+				return err
+			}
+			issetNameSpace = true
+		case 5:
+			if err := p.ReadField5(iprot); err != nil {
+				return err
+			}
+		case 6:
+			if err := p.ReadField6(iprot); err != nil {
+				return err
+			}
+		case 7:
+			if err := p.ReadField7(iprot); err != nil {
+				return err
+			}
+		case 8:
+			if err := p.ReadField8(iprot); err != nil {
+				return err
+			}
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	if !issetQuery {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Query is not set"))
+	}
+	if !issetRangeStart {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field RangeStart is not set"))
+	}
+	if !issetRangeEnd {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field RangeEnd is not set"))
+	}
+	if !issetNameSpace {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field NameSpace is not set"))
+	}
+	return nil
+}
+
+func (p *QueryRequest) ReadField1(iprot thrift.TProtocol) error {
+	p.Query = &Query{}
+	if err := p.Query.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Query), err)
+	}
+	return nil
+}
+
+func (p *QueryRequest) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		p.RangeStart = v
+	}
+	return nil
+}
+
+func (p *QueryRequest) ReadField3(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 3: ", err)
+	} else {
+		p.RangeEnd = v
+	}
+	return nil
+}
+
+func (p *QueryRequest) ReadField4(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
+		return thrift.PrependError("error reading field 4: ", err)
+	} else {
+		p.NameSpace = v
+	}
+	return nil
+}
+
+func (p *QueryRequest) ReadField5(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 5: ", err)
+	} else {
+		p.Limit = &v
+	}
+	return nil
+}
+
+func (p *QueryRequest) ReadField6(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 6: ", err)
+	} else {
+		p.NoData = &v
+	}
+	return nil
+}
+
+func (p *QueryRequest) ReadField7(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI32(); err != nil {
+		return thrift.PrependError("error reading field 7: ", err)
+	} else {
+		temp := TimeType(v)
+		p.RangeType = temp
+	}
+	return nil
+}
+
+func (p *QueryRequest) ReadField8(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI32(); err != nil {
+		return thrift.PrependError("error reading field 8: ", err)
+	} else {
+		temp := TimeType(v)
+		p.ResultTimeType = temp
+	}
+	return nil
+}
+
+func (p *QueryRequest) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("QueryRequest"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField2(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField3(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField4(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField5(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField6(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField7(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField8(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *QueryRequest) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("query", thrift.STRUCT, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:query: ", p), err)
+	}
+	if err := p.Query.Write(oprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Query), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:query: ", p), err)
+	}
+	return err
+}
+
+func (p *QueryRequest) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("rangeStart", thrift.I64, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:rangeStart: ", p), err)
+	}
+	if err := oprot.WriteI64(int64(p.RangeStart)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.rangeStart (2) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:rangeStart: ", p), err)
+	}
+	return err
+}
+
+func (p *QueryRequest) writeField3(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("rangeEnd", thrift.I64, 3); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:rangeEnd: ", p), err)
+	}
+	if err := oprot.WriteI64(int64(p.RangeEnd)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.rangeEnd (3) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:rangeEnd: ", p), err)
+	}
+	return err
+}
+
+func (p *QueryRequest) writeField4(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("nameSpace", thrift.STRING, 4); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:nameSpace: ", p), err)
+	}
+	if err := oprot.WriteString(string(p.NameSpace)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.nameSpace (4) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 4:nameSpace: ", p), err)
+	}
+	return err
+}
+
+func (p *QueryRequest) writeField5(oprot thrift.TProtocol) (err error) {
+	if p.IsSetLimit() {
+		if err := oprot.WriteFieldBegin("limit", thrift.I64, 5); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 5:limit: ", p), err)
+		}
+		if err := oprot.WriteI64(int64(*p.Limit)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.limit (5) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 5:limit: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *QueryRequest) writeField6(oprot thrift.TProtocol) (err error) {
+	if p.IsSetNoData() {
+		if err := oprot.WriteFieldBegin("noData", thrift.BOOL, 6); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 6:noData: ", p), err)
+		}
+		if err := oprot.WriteBool(bool(*p.NoData)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.noData (6) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 6:noData: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *QueryRequest) writeField7(oprot thrift.TProtocol) (err error) {
+	if p.IsSetRangeType() {
+		if err := oprot.WriteFieldBegin("rangeType", thrift.I32, 7); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 7:rangeType: ", p), err)
+		}
+		if err := oprot.WriteI32(int32(p.RangeType)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.rangeType (7) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 7:rangeType: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *QueryRequest) writeField8(oprot thrift.TProtocol) (err error) {
+	if p.IsSetResultTimeType() {
+		if err := oprot.WriteFieldBegin("resultTimeType", thrift.I32, 8); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 8:resultTimeType: ", p), err)
+		}
+		if err := oprot.WriteI32(int32(p.ResultTimeType)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.resultTimeType (8) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 8:resultTimeType: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *QueryRequest) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("QueryRequest(%+v)", *p)
+}
+
+// Attributes:
+//  - Results
+//  - Exhaustive
+type QueryResult_ struct {
+	Results    []*QueryResultElement `thrift:"results,1,required" db:"results" json:"results"`
+	Exhaustive bool                  `thrift:"exhaustive,2,required" db:"exhaustive" json:"exhaustive"`
+}
+
+func NewQueryResult_() *QueryResult_ {
+	return &QueryResult_{}
+}
+
+func (p *QueryResult_) GetResults() []*QueryResultElement {
+	return p.Results
+}
+
+func (p *QueryResult_) GetExhaustive() bool {
+	return p.Exhaustive
+}
+func (p *QueryResult_) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	var issetResults bool = false
+	var issetExhaustive bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetResults = true
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
+				return err
+			}
+			issetExhaustive = true
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	if !issetResults {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Results is not set"))
+	}
+	if !issetExhaustive {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Exhaustive is not set"))
+	}
+	return nil
+}
+
+func (p *QueryResult_) ReadField1(iprot thrift.TProtocol) error {
+	_, size, err := iprot.ReadListBegin()
+	if err != nil {
+		return thrift.PrependError("error reading list begin: ", err)
+	}
+	tSlice := make([]*QueryResultElement, 0, size)
+	p.Results = tSlice
+	for i := 0; i < size; i++ {
+		_elem18 := &QueryResultElement{}
+		if err := _elem18.Read(iprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem18), err)
+		}
+		p.Results = append(p.Results, _elem18)
+	}
+	if err := iprot.ReadListEnd(); err != nil {
+		return thrift.PrependError("error reading list end: ", err)
+	}
+	return nil
+}
+
+func (p *QueryResult_) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		p.Exhaustive = v
+	}
+	return nil
+}
+
+func (p *QueryResult_) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("QueryResult"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField2(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *QueryResult_) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("results", thrift.LIST, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:results: ", p), err)
+	}
+	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Results)); err != nil {
+		return thrift.PrependError("error writing list begin: ", err)
+	}
+	for _, v := range p.Results {
+		if err := v.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
+		}
+	}
+	if err := oprot.WriteListEnd(); err != nil {
+		return thrift.PrependError("error writing list end: ", err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:results: ", p), err)
+	}
+	return err
+}
+
+func (p *QueryResult_) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("exhaustive", thrift.BOOL, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:exhaustive: ", p), err)
+	}
+	if err := oprot.WriteBool(bool(p.Exhaustive)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.exhaustive (2) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:exhaustive: ", p), err)
+	}
+	return err
+}
+
+func (p *QueryResult_) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("QueryResult_(%+v)", *p)
+}
+
+// Attributes:
+//  - ID
+//  - Tags
+//  - Datapoints
+type QueryResultElement struct {
+	ID         string       `thrift:"id,1,required" db:"id" json:"id"`
+	Tags       []*Tag       `thrift:"tags,2,required" db:"tags" json:"tags"`
+	Datapoints []*Datapoint `thrift:"datapoints,3,required" db:"datapoints" json:"datapoints"`
+}
+
+func NewQueryResultElement() *QueryResultElement {
+	return &QueryResultElement{}
+}
+
+func (p *QueryResultElement) GetID() string {
+	return p.ID
+}
+
+func (p *QueryResultElement) GetTags() []*Tag {
+	return p.Tags
+}
+
+func (p *QueryResultElement) GetDatapoints() []*Datapoint {
+	return p.Datapoints
+}
+func (p *QueryResultElement) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	var issetID bool = false
+	var issetTags bool = false
+	var issetDatapoints bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetID = true
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
+				return err
+			}
+			issetTags = true
+		case 3:
+			if err := p.ReadField3(iprot); err != nil {
+				return err
+			}
+			issetDatapoints = true
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	if !issetID {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field ID is not set"))
+	}
+	if !issetTags {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Tags is not set"))
+	}
+	if !issetDatapoints {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Datapoints is not set"))
+	}
+	return nil
+}
+
+func (p *QueryResultElement) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
+	} else {
+		p.ID = v
+	}
+	return nil
+}
+
+func (p *QueryResultElement) ReadField2(iprot thrift.TProtocol) error {
+	_, size, err := iprot.ReadListBegin()
+	if err != nil {
+		return thrift.PrependError("error reading list begin: ", err)
+	}
+	tSlice := make([]*Tag, 0, size)
+	p.Tags = tSlice
+	for i := 0; i < size; i++ {
+		_elem19 := &Tag{}
+		if err := _elem19.Read(iprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem19), err)
+		}
+		p.Tags = append(p.Tags, _elem19)
+	}
+	if err := iprot.ReadListEnd(); err != nil {
+		return thrift.PrependError("error reading list end: ", err)
+	}
+	return nil
+}
+
+func (p *QueryResultElement) ReadField3(iprot thrift.TProtocol) error {
+	_, size, err := iprot.ReadListBegin()
+	if err != nil {
+		return thrift.PrependError("error reading list begin: ", err)
+	}
+	tSlice := make([]*Datapoint, 0, size)
+	p.Datapoints = tSlice
+	for i := 0; i < size; i++ {
+		_elem20 := &Datapoint{
+			TimestampTimeType: 0,
+		}
+		if err := _elem20.Read(iprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem20), err)
+		}
+		p.Datapoints = append(p.Datapoints, _elem20)
+	}
+	if err := iprot.ReadListEnd(); err != nil {
+		return thrift.PrependError("error reading list end: ", err)
+	}
+	return nil
+}
+
+func (p *QueryResultElement) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("QueryResultElement"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField2(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField3(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *QueryResultElement) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("id", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:id: ", p), err)
+	}
+	if err := oprot.WriteString(string(p.ID)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.id (1) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:id: ", p), err)
+	}
+	return err
+}
+
+func (p *QueryResultElement) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("tags", thrift.LIST, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:tags: ", p), err)
+	}
+	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Tags)); err != nil {
+		return thrift.PrependError("error writing list begin: ", err)
+	}
+	for _, v := range p.Tags {
+		if err := v.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
+		}
+	}
+	if err := oprot.WriteListEnd(); err != nil {
+		return thrift.PrependError("error writing list end: ", err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:tags: ", p), err)
+	}
+	return err
+}
+
+func (p *QueryResultElement) writeField3(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("datapoints", thrift.LIST, 3); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:datapoints: ", p), err)
+	}
+	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Datapoints)); err != nil {
+		return thrift.PrependError("error writing list begin: ", err)
+	}
+	for _, v := range p.Datapoints {
+		if err := v.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
+		}
+	}
+	if err := oprot.WriteListEnd(); err != nil {
+		return thrift.PrependError("error writing list end: ", err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:datapoints: ", p), err)
+	}
+	return err
+}
+
+func (p *QueryResultElement) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("QueryResultElement(%+v)", *p)
+}
+
+// Attributes:
+//  - Field
+//  - Term
+type TermQuery struct {
+	Field string `thrift:"field,1,required" db:"field" json:"field"`
+	Term  string `thrift:"term,2,required" db:"term" json:"term"`
+}
+
+func NewTermQuery() *TermQuery {
+	return &TermQuery{}
+}
+
+func (p *TermQuery) GetField() string {
+	return p.Field
+}
+
+func (p *TermQuery) GetTerm() string {
+	return p.Term
+}
+func (p *TermQuery) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	var issetField bool = false
+	var issetTerm bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetField = true
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
+				return err
+			}
+			issetTerm = true
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	if !issetField {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Field is not set"))
+	}
+	if !issetTerm {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Term is not set"))
+	}
+	return nil
+}
+
+func (p *TermQuery) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
+	} else {
+		p.Field = v
+	}
+	return nil
+}
+
+func (p *TermQuery) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		p.Term = v
+	}
+	return nil
+}
+
+func (p *TermQuery) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("TermQuery"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField2(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *TermQuery) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("field", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:field: ", p), err)
+	}
+	if err := oprot.WriteString(string(p.Field)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.field (1) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:field: ", p), err)
+	}
+	return err
+}
+
+func (p *TermQuery) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("term", thrift.STRING, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:term: ", p), err)
+	}
+	if err := oprot.WriteString(string(p.Term)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.term (2) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:term: ", p), err)
+	}
+	return err
+}
+
+func (p *TermQuery) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("TermQuery(%+v)", *p)
+}
+
+// Attributes:
+//  - Field
+//  - Regexp
+type RegexpQuery struct {
+	Field  string `thrift:"field,1,required" db:"field" json:"field"`
+	Regexp string `thrift:"regexp,2,required" db:"regexp" json:"regexp"`
+}
+
+func NewRegexpQuery() *RegexpQuery {
+	return &RegexpQuery{}
+}
+
+func (p *RegexpQuery) GetField() string {
+	return p.Field
+}
+
+func (p *RegexpQuery) GetRegexp() string {
+	return p.Regexp
+}
+func (p *RegexpQuery) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	var issetField bool = false
+	var issetRegexp bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetField = true
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
+				return err
+			}
+			issetRegexp = true
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	if !issetField {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Field is not set"))
+	}
+	if !issetRegexp {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Regexp is not set"))
+	}
+	return nil
+}
+
+func (p *RegexpQuery) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
+	} else {
+		p.Field = v
+	}
+	return nil
+}
+
+func (p *RegexpQuery) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		p.Regexp = v
+	}
+	return nil
+}
+
+func (p *RegexpQuery) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("RegexpQuery"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField2(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *RegexpQuery) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("field", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:field: ", p), err)
+	}
+	if err := oprot.WriteString(string(p.Field)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.field (1) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:field: ", p), err)
+	}
+	return err
+}
+
+func (p *RegexpQuery) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("regexp", thrift.STRING, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:regexp: ", p), err)
+	}
+	if err := oprot.WriteString(string(p.Regexp)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.regexp (2) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:regexp: ", p), err)
+	}
+	return err
+}
+
+func (p *RegexpQuery) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("RegexpQuery(%+v)", *p)
+}
+
+// Attributes:
+//  - Query
+type NegationQuery struct {
+	Query *Query `thrift:"query,1,required" db:"query" json:"query"`
+}
+
+func NewNegationQuery() *NegationQuery {
+	return &NegationQuery{}
+}
+
+var NegationQuery_Query_DEFAULT *Query
+
+func (p *NegationQuery) GetQuery() *Query {
+	if !p.IsSetQuery() {
+		return NegationQuery_Query_DEFAULT
+	}
+	return p.Query
+}
+func (p *NegationQuery) IsSetQuery() bool {
+	return p.Query != nil
+}
+
+func (p *NegationQuery) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	var issetQuery bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetQuery = true
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	if !issetQuery {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Query is not set"))
+	}
+	return nil
+}
+
+func (p *NegationQuery) ReadField1(iprot thrift.TProtocol) error {
+	p.Query = &Query{}
+	if err := p.Query.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Query), err)
+	}
+	return nil
+}
+
+func (p *NegationQuery) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("NegationQuery"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *NegationQuery) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("query", thrift.STRUCT, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:query: ", p), err)
+	}
+	if err := p.Query.Write(oprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Query), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:query: ", p), err)
+	}
+	return err
+}
+
+func (p *NegationQuery) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("NegationQuery(%+v)", *p)
+}
+
+// Attributes:
+//  - Queries
+type ConjunctionQuery struct {
+	Queries []*Query `thrift:"queries,1,required" db:"queries" json:"queries"`
+}
+
+func NewConjunctionQuery() *ConjunctionQuery {
+	return &ConjunctionQuery{}
+}
+
+func (p *ConjunctionQuery) GetQueries() []*Query {
+	return p.Queries
+}
+func (p *ConjunctionQuery) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	var issetQueries bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetQueries = true
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	if !issetQueries {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Queries is not set"))
+	}
+	return nil
+}
+
+func (p *ConjunctionQuery) ReadField1(iprot thrift.TProtocol) error {
+	_, size, err := iprot.ReadListBegin()
+	if err != nil {
+		return thrift.PrependError("error reading list begin: ", err)
+	}
+	tSlice := make([]*Query, 0, size)
+	p.Queries = tSlice
+	for i := 0; i < size; i++ {
+		_elem21 := &Query{}
+		if err := _elem21.Read(iprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem21), err)
+		}
+		p.Queries = append(p.Queries, _elem21)
+	}
+	if err := iprot.ReadListEnd(); err != nil {
+		return thrift.PrependError("error reading list end: ", err)
+	}
+	return nil
+}
+
+func (p *ConjunctionQuery) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("ConjunctionQuery"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *ConjunctionQuery) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("queries", thrift.LIST, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:queries: ", p), err)
+	}
+	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Queries)); err != nil {
+		return thrift.PrependError("error writing list begin: ", err)
+	}
+	for _, v := range p.Queries {
+		if err := v.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
+		}
+	}
+	if err := oprot.WriteListEnd(); err != nil {
+		return thrift.PrependError("error writing list end: ", err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:queries: ", p), err)
+	}
+	return err
+}
+
+func (p *ConjunctionQuery) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("ConjunctionQuery(%+v)", *p)
+}
+
+// Attributes:
+//  - Queries
+type DisjunctionQuery struct {
+	Queries []*Query `thrift:"queries,1,required" db:"queries" json:"queries"`
+}
+
+func NewDisjunctionQuery() *DisjunctionQuery {
+	return &DisjunctionQuery{}
+}
+
+func (p *DisjunctionQuery) GetQueries() []*Query {
+	return p.Queries
+}
+func (p *DisjunctionQuery) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	var issetQueries bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetQueries = true
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	if !issetQueries {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Queries is not set"))
+	}
+	return nil
+}
+
+func (p *DisjunctionQuery) ReadField1(iprot thrift.TProtocol) error {
+	_, size, err := iprot.ReadListBegin()
+	if err != nil {
+		return thrift.PrependError("error reading list begin: ", err)
+	}
+	tSlice := make([]*Query, 0, size)
+	p.Queries = tSlice
+	for i := 0; i < size; i++ {
+		_elem22 := &Query{}
+		if err := _elem22.Read(iprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem22), err)
+		}
+		p.Queries = append(p.Queries, _elem22)
+	}
+	if err := iprot.ReadListEnd(); err != nil {
+		return thrift.PrependError("error reading list end: ", err)
+	}
+	return nil
+}
+
+func (p *DisjunctionQuery) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("DisjunctionQuery"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *DisjunctionQuery) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("queries", thrift.LIST, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:queries: ", p), err)
+	}
+	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Queries)); err != nil {
+		return thrift.PrependError("error writing list begin: ", err)
+	}
+	for _, v := range p.Queries {
+		if err := v.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
+		}
+	}
+	if err := oprot.WriteListEnd(); err != nil {
+		return thrift.PrependError("error writing list end: ", err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:queries: ", p), err)
+	}
+	return err
+}
+
+func (p *DisjunctionQuery) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("DisjunctionQuery(%+v)", *p)
+}
+
+// Attributes:
+//  - Term
+//  - Regexp
+//  - Negation
+//  - Conjunction
+//  - Disjunction
+type Query struct {
+	Term        *TermQuery        `thrift:"term,1" db:"term" json:"term,omitempty"`
+	Regexp      *RegexpQuery      `thrift:"regexp,2" db:"regexp" json:"regexp,omitempty"`
+	Negation    *NegationQuery    `thrift:"negation,3" db:"negation" json:"negation,omitempty"`
+	Conjunction *ConjunctionQuery `thrift:"conjunction,4" db:"conjunction" json:"conjunction,omitempty"`
+	Disjunction *DisjunctionQuery `thrift:"disjunction,5" db:"disjunction" json:"disjunction,omitempty"`
+}
+
+func NewQuery() *Query {
+	return &Query{}
+}
+
+var Query_Term_DEFAULT *TermQuery
+
+func (p *Query) GetTerm() *TermQuery {
+	if !p.IsSetTerm() {
+		return Query_Term_DEFAULT
+	}
+	return p.Term
+}
+
+var Query_Regexp_DEFAULT *RegexpQuery
+
+func (p *Query) GetRegexp() *RegexpQuery {
+	if !p.IsSetRegexp() {
+		return Query_Regexp_DEFAULT
+	}
+	return p.Regexp
+}
+
+var Query_Negation_DEFAULT *NegationQuery
+
+func (p *Query) GetNegation() *NegationQuery {
+	if !p.IsSetNegation() {
+		return Query_Negation_DEFAULT
+	}
+	return p.Negation
+}
+
+var Query_Conjunction_DEFAULT *ConjunctionQuery
+
+func (p *Query) GetConjunction() *ConjunctionQuery {
+	if !p.IsSetConjunction() {
+		return Query_Conjunction_DEFAULT
+	}
+	return p.Conjunction
+}
+
+var Query_Disjunction_DEFAULT *DisjunctionQuery
+
+func (p *Query) GetDisjunction() *DisjunctionQuery {
+	if !p.IsSetDisjunction() {
+		return Query_Disjunction_DEFAULT
+	}
+	return p.Disjunction
+}
+func (p *Query) IsSetTerm() bool {
+	return p.Term != nil
+}
+
+func (p *Query) IsSetRegexp() bool {
+	return p.Regexp != nil
+}
+
+func (p *Query) IsSetNegation() bool {
+	return p.Negation != nil
+}
+
+func (p *Query) IsSetConjunction() bool {
+	return p.Conjunction != nil
+}
+
+func (p *Query) IsSetDisjunction() bool {
+	return p.Disjunction != nil
+}
+
+func (p *Query) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
+				return err
+			}
+		case 3:
+			if err := p.ReadField3(iprot); err != nil {
+				return err
+			}
+		case 4:
+			if err := p.ReadField4(iprot); err != nil {
+				return err
+			}
+		case 5:
+			if err := p.ReadField5(iprot); err != nil {
+				return err
+			}
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	return nil
+}
+
+func (p *Query) ReadField1(iprot thrift.TProtocol) error {
+	p.Term = &TermQuery{}
+	if err := p.Term.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Term), err)
+	}
+	return nil
+}
+
+func (p *Query) ReadField2(iprot thrift.TProtocol) error {
+	p.Regexp = &RegexpQuery{}
+	if err := p.Regexp.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Regexp), err)
+	}
+	return nil
+}
+
+func (p *Query) ReadField3(iprot thrift.TProtocol) error {
+	p.Negation = &NegationQuery{}
+	if err := p.Negation.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Negation), err)
+	}
+	return nil
+}
+
+func (p *Query) ReadField4(iprot thrift.TProtocol) error {
+	p.Conjunction = &ConjunctionQuery{}
+	if err := p.Conjunction.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Conjunction), err)
+	}
+	return nil
+}
+
+func (p *Query) ReadField5(iprot thrift.TProtocol) error {
+	p.Disjunction = &DisjunctionQuery{}
+	if err := p.Disjunction.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Disjunction), err)
+	}
+	return nil
+}
+
+func (p *Query) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("Query"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField2(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField3(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField4(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField5(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *Query) writeField1(oprot thrift.TProtocol) (err error) {
+	if p.IsSetTerm() {
+		if err := oprot.WriteFieldBegin("term", thrift.STRUCT, 1); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:term: ", p), err)
+		}
+		if err := p.Term.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Term), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 1:term: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *Query) writeField2(oprot thrift.TProtocol) (err error) {
+	if p.IsSetRegexp() {
+		if err := oprot.WriteFieldBegin("regexp", thrift.STRUCT, 2); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:regexp: ", p), err)
+		}
+		if err := p.Regexp.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Regexp), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 2:regexp: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *Query) writeField3(oprot thrift.TProtocol) (err error) {
+	if p.IsSetNegation() {
+		if err := oprot.WriteFieldBegin("negation", thrift.STRUCT, 3); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:negation: ", p), err)
+		}
+		if err := p.Negation.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Negation), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 3:negation: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *Query) writeField4(oprot thrift.TProtocol) (err error) {
+	if p.IsSetConjunction() {
+		if err := oprot.WriteFieldBegin("conjunction", thrift.STRUCT, 4); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:conjunction: ", p), err)
+		}
+		if err := p.Conjunction.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Conjunction), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 4:conjunction: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *Query) writeField5(oprot thrift.TProtocol) (err error) {
+	if p.IsSetDisjunction() {
+		if err := oprot.WriteFieldBegin("disjunction", thrift.STRUCT, 5); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 5:disjunction: ", p), err)
+		}
+		if err := p.Disjunction.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Disjunction), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 5:disjunction: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *Query) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("Query(%+v)", *p)
+}
+
+type Node interface {
+	// Parameters:
+	//  - Req
+	Query(req *QueryRequest) (r *QueryResult_, err error)
+	// Parameters:
+	//  - Req
+	Fetch(req *FetchRequest) (r *FetchResult_, err error)
+	// Parameters:
+	//  - Req
+	FetchTagged(req *FetchTaggedRequest) (r *FetchTaggedResult_, err error)
+	// Parameters:
+	//  - Req
+	FetchTaggedPage(req *FetchTaggedPageRequest) (r *FetchTaggedPageResult_, err error)
+	// Parameters:
+	//  - Req
+	Aggregate(req *AggregateQueryRequest) (r *AggregateQueryResult_, err error)
+	// Parameters:
+	//  - Req
+	AggregateTagValues(req *AggregateTagValuesRequest) (r *AggregateTagValuesResult_, err error)
+	// Parameters:
+	//  - Req
+	Write(req *WriteRequest) (err error)
+	// Parameters:
+	//  - Req
+	WriteTagged(req *WriteTaggedRequest) (err error)
+	// Parameters:
+	//  - Req
+	FetchBatchRaw(req *FetchBatchRawRequest) (r *FetchBatchRawResult_, err error)
+	// Parameters:
+	//  - Req
+	FetchBlocksRaw(req *FetchBlocksRawRequest) (r *FetchBlocksRawResult_, err error)
+	// Parameters:
+	//  - Req
+	FetchBlocksMetadataRaw(req *FetchBlocksMetadataRawRequest) (r *FetchBlocksMetadataRawResult_, err error)
+	// Parameters:
+	//  - Req
+	FetchBlocksMetadataRawV2(req *FetchBlocksMetadataRawV2Request) (r *FetchBlocksMetadataRawV2Result_, err error)
+	// Parameters:
+	//  - Req
+	WriteBatchRaw(req *WriteBatchRawRequest) (err error)
+	// Parameters:
+	//  - Req
+	WriteTaggedBatchRaw(req *WriteTaggedBatchRawRequest) (err error)
+	Repair() (err error)
+	// Parameters:
+	//  - Req
+	Truncate(req *TruncateRequest) (r *TruncateResult_, err error)
+	// Parameters:
+	//  - Req
+	ForceFlush(req *ForceFlushRequest) (r *ForceFlushResult_, err error)
+	Health() (r *NodeHealthResult_, err error)
+	Bootstrapped() (r *NodeBootstrappedResult_, err error)
+	GetPersistRateLimit() (r *NodePersistRateLimitResult_, err error)
+	// Parameters:
+	//  - Req
+	SetPersistRateLimit(req *NodeSetPersistRateLimitRequest) (r *NodePersistRateLimitResult_, err error)
+	GetWriteNewSeriesAsync() (r *NodeWriteNewSeriesAsyncResult_, err error)
+	// Parameters:
+	//  - Req
+	SetWriteNewSeriesAsync(req *NodeSetWriteNewSeriesAsyncRequest) (r *NodeWriteNewSeriesAsyncResult_, err error)
+	GetWriteNewSeriesBackoffDuration() (r *NodeWriteNewSeriesBackoffDurationResult_, err error)
+	// Parameters:
+	//  - Req
+	SetWriteNewSeriesBackoffDuration(req *NodeSetWriteNewSeriesBackoffDurationRequest) (r *NodeWriteNewSeriesBackoffDurationResult_, err error)
+	GetWriteNewSeriesLimitPerShardPerSecond() (r *NodeWriteNewSeriesLimitPerShardPerSecondResult_, err error)
+	// Parameters:
+	//  - Req
+	SetWriteNewSeriesLimitPerShardPerSecond(req *NodeSetWriteNewSeriesLimitPerShardPerSecondRequest) (r *NodeWriteNewSeriesLimitPerShardPerSecondResult_, err error)
+}
+
+type NodeClient struct {
+	Transport       thrift.TTransport
+	ProtocolFactory thrift.TProtocolFactory
+	InputProtocol   thrift.TProtocol
+	OutputProtocol  thrift.TProtocol
+	SeqId           int32
+}
+
+func NewNodeClientFactory(t thrift.TTransport, f thrift.TProtocolFactory) *NodeClient {
+	return &NodeClient{Transport: t,
+		ProtocolFactory: f,
+		InputProtocol:   f.GetProtocol(t),
+		OutputProtocol:  f.GetProtocol(t),
+		SeqId:           0,
+	}
+}
+
+func NewNodeClientProtocol(t thrift.TTransport, iprot thrift.TProtocol, oprot thrift.TProtocol) *NodeClient {
+	return &NodeClient{Transport: t,
+		ProtocolFactory: nil,
+		InputProtocol:   iprot,
+		OutputProtocol:  oprot,
+		SeqId:           0,
+	}
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) Query(req *QueryRequest) (r *QueryResult_, err error) {
+	if err = p.sendQuery(req); err != nil {
+		return
+	}
+	return p.recvQuery()
+}
+
+func (p *NodeClient) sendQuery(req *QueryRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("query", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeQueryArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvQuery() (value *QueryResult_, err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "query" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "query failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "query failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error23 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error24 error
+		error24, err = error23.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error24
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "query failed: invalid message type")
+		return
+	}
+	result := NodeQueryResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	value = result.GetSuccess()
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) Fetch(req *FetchRequest) (r *FetchResult_, err error) {
+	if err = p.sendFetch(req); err != nil {
+		return
+	}
+	return p.recvFetch()
+}
+
+func (p *NodeClient) sendFetch(req *FetchRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("fetch", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeFetchArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvFetch() (value *FetchResult_, err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "fetch" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "fetch failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "fetch failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error25 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error26 error
+		error26, err = error25.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error26
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "fetch failed: invalid message type")
+		return
+	}
+	result := NodeFetchResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	value = result.GetSuccess()
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) FetchTagged(req *FetchTaggedRequest) (r *FetchTaggedResult_, err error) {
+	if err = p.sendFetchTagged(req); err != nil {
+		return
+	}
+	return p.recvFetchTagged()
+}
+
+func (p *NodeClient) sendFetchTagged(req *FetchTaggedRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("fetchTagged", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeFetchTaggedArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvFetchTagged() (value *FetchTaggedResult_, err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "fetchTagged" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "fetchTagged failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "fetchTagged failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error27 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error28 error
+		error28, err = error27.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error28
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "fetchTagged failed: invalid message type")
+		return
+	}
+	result := NodeFetchTaggedResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	value = result.GetSuccess()
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) FetchTaggedPage(req *FetchTaggedPageRequest) (r *FetchTaggedPageResult_, err error) {
+	if err = p.sendFetchTaggedPage(req); err != nil {
+		return
+	}
+	return p.recvFetchTaggedPage()
+}
+
+func (p *NodeClient) sendFetchTaggedPage(req *FetchTaggedPageRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("fetchTaggedPage", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeFetchTaggedPageArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvFetchTaggedPage() (value *FetchTaggedPageResult_, err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "fetchTaggedPage" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "fetchTaggedPage failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "fetchTaggedPage failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error186 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error187 error
+		error187, err = error186.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error187
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "fetchTaggedPage failed: invalid message type")
+		return
+	}
+	result := NodeFetchTaggedPageResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	value = result.GetSuccess()
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) Aggregate(req *AggregateQueryRequest) (r *AggregateQueryResult_, err error) {
+	if err = p.sendAggregate(req); err != nil {
+		return
+	}
+	return p.recvAggregate()
+}
+
+func (p *NodeClient) sendAggregate(req *AggregateQueryRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("aggregate", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeAggregateArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvAggregate() (value *AggregateQueryResult_, err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "aggregate" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "aggregate failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "aggregate failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error183 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error184 error
+		error184, err = error183.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error184
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "aggregate failed: invalid message type")
+		return
+	}
+	result := NodeAggregateResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	value = result.GetSuccess()
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) AggregateTagValues(req *AggregateTagValuesRequest) (r *AggregateTagValuesResult_, err error) {
+	if err = p.sendAggregateTagValues(req); err != nil {
+		return
+	}
+	return p.recvAggregateTagValues()
+}
+
+func (p *NodeClient) sendAggregateTagValues(req *AggregateTagValuesRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("aggregateTagValues", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeAggregateTagValuesArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvAggregateTagValues() (value *AggregateTagValuesResult_, err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "aggregateTagValues" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "aggregateTagValues failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "aggregateTagValues failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error186 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error187 error
+		error187, err = error186.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error187
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "aggregateTagValues failed: invalid message type")
+		return
+	}
+	result := NodeAggregateTagValuesResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	value = result.GetSuccess()
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) Write(req *WriteRequest) (err error) {
+	if err = p.sendWrite(req); err != nil {
+		return
+	}
+	return p.recvWrite()
+}
+
+func (p *NodeClient) sendWrite(req *WriteRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("write", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeWriteArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvWrite() (err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "write" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "write failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "write failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error29 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error30 error
+		error30, err = error29.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error30
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "write failed: invalid message type")
+		return
+	}
+	result := NodeWriteResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) WriteTagged(req *WriteTaggedRequest) (err error) {
+	if err = p.sendWriteTagged(req); err != nil {
+		return
+	}
+	return p.recvWriteTagged()
+}
+
+func (p *NodeClient) sendWriteTagged(req *WriteTaggedRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("writeTagged", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeWriteTaggedArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvWriteTagged() (err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "writeTagged" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "writeTagged failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "writeTagged failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error31 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error32 error
+		error32, err = error31.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error32
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "writeTagged failed: invalid message type")
+		return
+	}
+	result := NodeWriteTaggedResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) FetchBatchRaw(req *FetchBatchRawRequest) (r *FetchBatchRawResult_, err error) {
+	if err = p.sendFetchBatchRaw(req); err != nil {
+		return
+	}
+	return p.recvFetchBatchRaw()
+}
+
+func (p *NodeClient) sendFetchBatchRaw(req *FetchBatchRawRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("fetchBatchRaw", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeFetchBatchRawArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvFetchBatchRaw() (value *FetchBatchRawResult_, err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "fetchBatchRaw" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "fetchBatchRaw failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "fetchBatchRaw failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error33 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error34 error
+		error34, err = error33.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error34
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "fetchBatchRaw failed: invalid message type")
+		return
+	}
+	result := NodeFetchBatchRawResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	value = result.GetSuccess()
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) FetchBlocksRaw(req *FetchBlocksRawRequest) (r *FetchBlocksRawResult_, err error) {
+	if err = p.sendFetchBlocksRaw(req); err != nil {
+		return
+	}
+	return p.recvFetchBlocksRaw()
+}
+
+func (p *NodeClient) sendFetchBlocksRaw(req *FetchBlocksRawRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("fetchBlocksRaw", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeFetchBlocksRawArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvFetchBlocksRaw() (value *FetchBlocksRawResult_, err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "fetchBlocksRaw" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "fetchBlocksRaw failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "fetchBlocksRaw failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error35 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error36 error
+		error36, err = error35.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error36
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "fetchBlocksRaw failed: invalid message type")
+		return
+	}
+	result := NodeFetchBlocksRawResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	value = result.GetSuccess()
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) FetchBlocksMetadataRaw(req *FetchBlocksMetadataRawRequest) (r *FetchBlocksMetadataRawResult_, err error) {
+	if err = p.sendFetchBlocksMetadataRaw(req); err != nil {
+		return
+	}
+	return p.recvFetchBlocksMetadataRaw()
+}
+
+func (p *NodeClient) sendFetchBlocksMetadataRaw(req *FetchBlocksMetadataRawRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("fetchBlocksMetadataRaw", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeFetchBlocksMetadataRawArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvFetchBlocksMetadataRaw() (value *FetchBlocksMetadataRawResult_, err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "fetchBlocksMetadataRaw" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "fetchBlocksMetadataRaw failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "fetchBlocksMetadataRaw failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error37 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error38 error
+		error38, err = error37.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error38
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "fetchBlocksMetadataRaw failed: invalid message type")
+		return
+	}
+	result := NodeFetchBlocksMetadataRawResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	value = result.GetSuccess()
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) FetchBlocksMetadataRawV2(req *FetchBlocksMetadataRawV2Request) (r *FetchBlocksMetadataRawV2Result_, err error) {
+	if err = p.sendFetchBlocksMetadataRawV2(req); err != nil {
+		return
+	}
+	return p.recvFetchBlocksMetadataRawV2()
+}
+
+func (p *NodeClient) sendFetchBlocksMetadataRawV2(req *FetchBlocksMetadataRawV2Request) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("fetchBlocksMetadataRawV2", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeFetchBlocksMetadataRawV2Args{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvFetchBlocksMetadataRawV2() (value *FetchBlocksMetadataRawV2Result_, err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "fetchBlocksMetadataRawV2" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "fetchBlocksMetadataRawV2 failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "fetchBlocksMetadataRawV2 failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error39 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error40 error
+		error40, err = error39.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error40
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "fetchBlocksMetadataRawV2 failed: invalid message type")
+		return
+	}
+	result := NodeFetchBlocksMetadataRawV2Result{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	value = result.GetSuccess()
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) WriteBatchRaw(req *WriteBatchRawRequest) (err error) {
+	if err = p.sendWriteBatchRaw(req); err != nil {
+		return
+	}
+	return p.recvWriteBatchRaw()
+}
+
+func (p *NodeClient) sendWriteBatchRaw(req *WriteBatchRawRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("writeBatchRaw", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeWriteBatchRawArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvWriteBatchRaw() (err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "writeBatchRaw" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "writeBatchRaw failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "writeBatchRaw failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error41 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error42 error
+		error42, err = error41.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error42
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "writeBatchRaw failed: invalid message type")
+		return
+	}
+	result := NodeWriteBatchRawResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) WriteTaggedBatchRaw(req *WriteTaggedBatchRawRequest) (err error) {
+	if err = p.sendWriteTaggedBatchRaw(req); err != nil {
+		return
+	}
+	return p.recvWriteTaggedBatchRaw()
+}
+
+func (p *NodeClient) sendWriteTaggedBatchRaw(req *WriteTaggedBatchRawRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("writeTaggedBatchRaw", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeWriteTaggedBatchRawArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvWriteTaggedBatchRaw() (err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "writeTaggedBatchRaw" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "writeTaggedBatchRaw failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "writeTaggedBatchRaw failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error43 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error44 error
+		error44, err = error43.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error44
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "writeTaggedBatchRaw failed: invalid message type")
+		return
+	}
+	result := NodeWriteTaggedBatchRawResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	return
+}
+
+func (p *NodeClient) Repair() (err error) {
+	if err = p.sendRepair(); err != nil {
+		return
+	}
+	return p.recvRepair()
+}
+
+func (p *NodeClient) sendRepair() (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("repair", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeRepairArgs{}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvRepair() (err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "repair" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "repair failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "repair failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error45 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error46 error
+		error46, err = error45.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error46
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "repair failed: invalid message type")
+		return
+	}
+	result := NodeRepairResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) Truncate(req *TruncateRequest) (r *TruncateResult_, err error) {
+	if err = p.sendTruncate(req); err != nil {
+		return
+	}
+	return p.recvTruncate()
+}
+
+func (p *NodeClient) sendTruncate(req *TruncateRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("truncate", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeTruncateArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvTruncate() (value *TruncateResult_, err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "truncate" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "truncate failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "truncate failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error47 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error48 error
+		error48, err = error47.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error48
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "truncate failed: invalid message type")
+		return
+	}
+	result := NodeTruncateResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	value = result.GetSuccess()
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) ForceFlush(req *ForceFlushRequest) (r *ForceFlushResult_, err error) {
+	if err = p.sendForceFlush(req); err != nil {
+		return
+	}
+	return p.recvForceFlush()
+}
+
+func (p *NodeClient) sendForceFlush(req *ForceFlushRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("forceFlush", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeForceFlushArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvForceFlush() (value *ForceFlushResult_, err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "forceFlush" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "forceFlush failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "forceFlush failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error47 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error48 error
+		error48, err = error47.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error48
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "forceFlush failed: invalid message type")
+		return
+	}
+	result := NodeForceFlushResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	value = result.GetSuccess()
+	return
+}
+
+func (p *NodeClient) Health() (r *NodeHealthResult_, err error) {
+	if err = p.sendHealth(); err != nil {
+		return
+	}
+	return p.recvHealth()
+}
+
+func (p *NodeClient) sendHealth() (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("health", thrift.CALL, p.SeqId); err != nil {
 		return
 	}
-	if method != "writeTagged" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "writeTagged failed: wrong method name")
+	args := NodeHealthArgs{}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvHealth() (value *NodeHealthResult_, err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "health" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "health failed: wrong method name")
 		return
 	}
 	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "writeTagged failed: out of sequence response")
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "health failed: out of sequence response")
 		return
 	}
 	if mTypeId == thrift.EXCEPTION {
-		error31 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error32 error
-		error32, err = error31.Read(iprot)
+		error49 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error50 error
+		error50, err = error49.Read(iprot)
 		if err != nil {
 			return
 		}
 		if err = iprot.ReadMessageEnd(); err != nil {
 			return
 		}
-		err = error32
+		err = error50
 		return
 	}
 	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "writeTagged failed: invalid message type")
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "health failed: invalid message type")
 		return
 	}
-	result := NodeWriteTaggedResult{}
+	result := NodeHealthResult{}
 	if err = result.Read(iprot); err != nil {
 		return
 	}
@@ -11017,31 +14826,28 @@ func (p *NodeClient) recvWriteTagged() (err error) {
 		err = result.Err
 		return
 	}
+	value = result.GetSuccess()
 	return
 }
 
-// Parameters:
-//  - Req
-func (p *NodeClient) FetchBatchRaw(req *FetchBatchRawRequest) (r *FetchBatchRawResult_, err error) {
-	if err = p.sendFetchBatchRaw(req); err != nil {
+func (p *NodeClient) Bootstrapped() (r *NodeBootstrappedResult_, err error) {
+	if err = p.sendBootstrapped(); err != nil {
 		return
 	}
-	return p.recvFetchBatchRaw()
+	return p.recvBootstrapped()
 }
 
-func (p *NodeClient) sendFetchBatchRaw(req *FetchBatchRawRequest) (err error) {
+func (p *NodeClient) sendBootstrapped() (err error) {
 	oprot := p.OutputProtocol
 	if oprot == nil {
 		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
 		p.OutputProtocol = oprot
 	}
 	p.SeqId++
-	if err = oprot.WriteMessageBegin("fetchBatchRaw", thrift.CALL, p.SeqId); err != nil {
+	if err = oprot.WriteMessageBegin("bootstrapped", thrift.CALL, p.SeqId); err != nil {
 		return
 	}
-	args := NodeFetchBatchRawArgs{
-		Req: req,
-	}
+	args := NodeBootstrappedArgs{}
 	if err = args.Write(oprot); err != nil {
 		return
 	}
@@ -11051,7 +14857,7 @@ func (p *NodeClient) sendFetchBatchRaw(req *FetchBatchRawRequest) (err error) {
 	return oprot.Flush()
 }
 
-func (p *NodeClient) recvFetchBatchRaw() (value *FetchBatchRawResult_, err error) {
+func (p *NodeClient) recvBootstrapped() (value *NodeBootstrappedResult_, err error) {
 	iprot := p.InputProtocol
 	if iprot == nil {
 		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
@@ -11061,32 +14867,32 @@ func (p *NodeClient) recvFetchBatchRaw() (value *FetchBatchRawResult_, err error
 	if err != nil {
 		return
 	}
-	if method != "fetchBatchRaw" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "fetchBatchRaw failed: wrong method name")
+	if method != "bootstrapped" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "bootstrapped failed: wrong method name")
 		return
 	}
 	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "fetchBatchRaw failed: out of sequence response")
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "bootstrapped failed: out of sequence response")
 		return
 	}
 	if mTypeId == thrift.EXCEPTION {
-		error33 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error34 error
-		error34, err = error33.Read(iprot)
+		error51 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error52 error
+		error52, err = error51.Read(iprot)
 		if err != nil {
 			return
 		}
 		if err = iprot.ReadMessageEnd(); err != nil {
 			return
 		}
-		err = error34
+		err = error52
 		return
 	}
 	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "fetchBatchRaw failed: invalid message type")
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "bootstrapped failed: invalid message type")
 		return
 	}
-	result := NodeFetchBatchRawResult{}
+	result := NodeBootstrappedResult{}
 	if err = result.Read(iprot); err != nil {
 		return
 	}
@@ -11101,28 +14907,24 @@ func (p *NodeClient) recvFetchBatchRaw() (value *FetchBatchRawResult_, err error
 	return
 }
 
-// Parameters:
-//  - Req
-func (p *NodeClient) FetchBlocksRaw(req *FetchBlocksRawRequest) (r *FetchBlocksRawResult_, err error) {
-	if err = p.sendFetchBlocksRaw(req); err != nil {
+func (p *NodeClient) GetPersistRateLimit() (r *NodePersistRateLimitResult_, err error) {
+	if err = p.sendGetPersistRateLimit(); err != nil {
 		return
 	}
-	return p.recvFetchBlocksRaw()
+	return p.recvGetPersistRateLimit()
 }
 
-func (p *NodeClient) sendFetchBlocksRaw(req *FetchBlocksRawRequest) (err error) {
+func (p *NodeClient) sendGetPersistRateLimit() (err error) {
 	oprot := p.OutputProtocol
 	if oprot == nil {
 		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
 		p.OutputProtocol = oprot
 	}
 	p.SeqId++
-	if err = oprot.WriteMessageBegin("fetchBlocksRaw", thrift.CALL, p.SeqId); err != nil {
+	if err = oprot.WriteMessageBegin("getPersistRateLimit", thrift.CALL, p.SeqId); err != nil {
 		return
 	}
-	args := NodeFetchBlocksRawArgs{
-		Req: req,
-	}
+	args := NodeGetPersistRateLimitArgs{}
 	if err = args.Write(oprot); err != nil {
 		return
 	}
@@ -11132,7 +14934,7 @@ func (p *NodeClient) sendFetchBlocksRaw(req *FetchBlocksRawRequest) (err error)
 	return oprot.Flush()
 }
 
-func (p *NodeClient) recvFetchBlocksRaw() (value *FetchBlocksRawResult_, err error) {
+func (p *NodeClient) recvGetPersistRateLimit() (value *NodePersistRateLimitResult_, err error) {
 	iprot := p.InputProtocol
 	if iprot == nil {
 		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
@@ -11142,32 +14944,32 @@ func (p *NodeClient) recvFetchBlocksRaw() (value *FetchBlocksRawResult_, err err
 	if err != nil {
 		return
 	}
-	if method != "fetchBlocksRaw" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "fetchBlocksRaw failed: wrong method name")
+	if method != "getPersistRateLimit" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "getPersistRateLimit failed: wrong method name")
 		return
 	}
 	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "fetchBlocksRaw failed: out of sequence response")
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "getPersistRateLimit failed: out of sequence response")
 		return
 	}
 	if mTypeId == thrift.EXCEPTION {
-		error35 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error36 error
-		error36, err = error35.Read(iprot)
+		error53 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error54 error
+		error54, err = error53.Read(iprot)
 		if err != nil {
 			return
 		}
 		if err = iprot.ReadMessageEnd(); err != nil {
 			return
 		}
-		err = error36
+		err = error54
 		return
 	}
 	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "fetchBlocksRaw failed: invalid message type")
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "getPersistRateLimit failed: invalid message type")
 		return
 	}
-	result := NodeFetchBlocksRawResult{}
+	result := NodeGetPersistRateLimitResult{}
 	if err = result.Read(iprot); err != nil {
 		return
 	}
@@ -11184,24 +14986,24 @@ func (p *NodeClient) recvFetchBlocksRaw() (value *FetchBlocksRawResult_, err err
 
 // Parameters:
 //  - Req
-func (p *NodeClient) FetchBlocksMetadataRaw(req *FetchBlocksMetadataRawRequest) (r *FetchBlocksMetadataRawResult_, err error) {
-	if err = p.sendFetchBlocksMetadataRaw(req); err != nil {
+func (p *NodeClient) SetPersistRateLimit(req *NodeSetPersistRateLimitRequest) (r *NodePersistRateLimitResult_, err error) {
+	if err = p.sendSetPersistRateLimit(req); err != nil {
 		return
 	}
-	return p.recvFetchBlocksMetadataRaw()
+	return p.recvSetPersistRateLimit()
 }
 
-func (p *NodeClient) sendFetchBlocksMetadataRaw(req *FetchBlocksMetadataRawRequest) (err error) {
+func (p *NodeClient) sendSetPersistRateLimit(req *NodeSetPersistRateLimitRequest) (err error) {
 	oprot := p.OutputProtocol
 	if oprot == nil {
 		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
 		p.OutputProtocol = oprot
 	}
 	p.SeqId++
-	if err = oprot.WriteMessageBegin("fetchBlocksMetadataRaw", thrift.CALL, p.SeqId); err != nil {
+	if err = oprot.WriteMessageBegin("setPersistRateLimit", thrift.CALL, p.SeqId); err != nil {
 		return
 	}
-	args := NodeFetchBlocksMetadataRawArgs{
+	args := NodeSetPersistRateLimitArgs{
 		Req: req,
 	}
 	if err = args.Write(oprot); err != nil {
@@ -11213,7 +15015,7 @@ func (p *NodeClient) sendFetchBlocksMetadataRaw(req *FetchBlocksMetadataRawReque
 	return oprot.Flush()
 }
 
-func (p *NodeClient) recvFetchBlocksMetadataRaw() (value *FetchBlocksMetadataRawResult_, err error) {
+func (p *NodeClient) recvSetPersistRateLimit() (value *NodePersistRateLimitResult_, err error) {
 	iprot := p.InputProtocol
 	if iprot == nil {
 		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
@@ -11223,32 +15025,32 @@ func (p *NodeClient) recvFetchBlocksMetadataRaw() (value *FetchBlocksMetadataRaw
 	if err != nil {
 		return
 	}
-	if method != "fetchBlocksMetadataRaw" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "fetchBlocksMetadataRaw failed: wrong method name")
+	if method != "setPersistRateLimit" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "setPersistRateLimit failed: wrong method name")
 		return
 	}
 	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "fetchBlocksMetadataRaw failed: out of sequence response")
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "setPersistRateLimit failed: out of sequence response")
 		return
 	}
 	if mTypeId == thrift.EXCEPTION {
-		error37 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error38 error
-		error38, err = error37.Read(iprot)
+		error55 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error56 error
+		error56, err = error55.Read(iprot)
 		if err != nil {
 			return
 		}
 		if err = iprot.ReadMessageEnd(); err != nil {
 			return
 		}
-		err = error38
+		err = error56
 		return
 	}
 	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "fetchBlocksMetadataRaw failed: invalid message type")
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "setPersistRateLimit failed: invalid message type")
 		return
 	}
-	result := NodeFetchBlocksMetadataRawResult{}
+	result := NodeSetPersistRateLimitResult{}
 	if err = result.Read(iprot); err != nil {
 		return
 	}
@@ -11263,28 +15065,24 @@ func (p *NodeClient) recvFetchBlocksMetadataRaw() (value *FetchBlocksMetadataRaw
 	return
 }
 
-// Parameters:
-//  - Req
-func (p *NodeClient) FetchBlocksMetadataRawV2(req *FetchBlocksMetadataRawV2Request) (r *FetchBlocksMetadataRawV2Result_, err error) {
-	if err = p.sendFetchBlocksMetadataRawV2(req); err != nil {
+func (p *NodeClient) GetWriteNewSeriesAsync() (r *NodeWriteNewSeriesAsyncResult_, err error) {
+	if err = p.sendGetWriteNewSeriesAsync(); err != nil {
 		return
 	}
-	return p.recvFetchBlocksMetadataRawV2()
+	return p.recvGetWriteNewSeriesAsync()
 }
 
-func (p *NodeClient) sendFetchBlocksMetadataRawV2(req *FetchBlocksMetadataRawV2Request) (err error) {
+func (p *NodeClient) sendGetWriteNewSeriesAsync() (err error) {
 	oprot := p.OutputProtocol
 	if oprot == nil {
 		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
 		p.OutputProtocol = oprot
 	}
 	p.SeqId++
-	if err = oprot.WriteMessageBegin("fetchBlocksMetadataRawV2", thrift.CALL, p.SeqId); err != nil {
+	if err = oprot.WriteMessageBegin("getWriteNewSeriesAsync", thrift.CALL, p.SeqId); err != nil {
 		return
 	}
-	args := NodeFetchBlocksMetadataRawV2Args{
-		Req: req,
-	}
+	args := NodeGetWriteNewSeriesAsyncArgs{}
 	if err = args.Write(oprot); err != nil {
 		return
 	}
@@ -11294,7 +15092,7 @@ func (p *NodeClient) sendFetchBlocksMetadataRawV2(req *FetchBlocksMetadataRawV2R
 	return oprot.Flush()
 }
 
-func (p *NodeClient) recvFetchBlocksMetadataRawV2() (value *FetchBlocksMetadataRawV2Result_, err error) {
+func (p *NodeClient) recvGetWriteNewSeriesAsync() (value *NodeWriteNewSeriesAsyncResult_, err error) {
 	iprot := p.InputProtocol
 	if iprot == nil {
 		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
@@ -11304,32 +15102,32 @@ func (p *NodeClient) recvFetchBlocksMetadataRawV2() (value *FetchBlocksMetadataR
 	if err != nil {
 		return
 	}
-	if method != "fetchBlocksMetadataRawV2" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "fetchBlocksMetadataRawV2 failed: wrong method name")
+	if method != "getWriteNewSeriesAsync" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "getWriteNewSeriesAsync failed: wrong method name")
 		return
 	}
 	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "fetchBlocksMetadataRawV2 failed: out of sequence response")
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "getWriteNewSeriesAsync failed: out of sequence response")
 		return
 	}
 	if mTypeId == thrift.EXCEPTION {
-		error39 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error40 error
-		error40, err = error39.Read(iprot)
+		error57 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error58 error
+		error58, err = error57.Read(iprot)
 		if err != nil {
 			return
 		}
 		if err = iprot.ReadMessageEnd(); err != nil {
 			return
 		}
-		err = error40
+		err = error58
 		return
 	}
 	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "fetchBlocksMetadataRawV2 failed: invalid message type")
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "getWriteNewSeriesAsync failed: invalid message type")
 		return
 	}
-	result := NodeFetchBlocksMetadataRawV2Result{}
+	result := NodeGetWriteNewSeriesAsyncResult{}
 	if err = result.Read(iprot); err != nil {
 		return
 	}
@@ -11346,24 +15144,24 @@ func (p *NodeClient) recvFetchBlocksMetadataRawV2() (value *FetchBlocksMetadataR
 
 // Parameters:
 //  - Req
-func (p *NodeClient) WriteBatchRaw(req *WriteBatchRawRequest) (err error) {
-	if err = p.sendWriteBatchRaw(req); err != nil {
+func (p *NodeClient) SetWriteNewSeriesAsync(req *NodeSetWriteNewSeriesAsyncRequest) (r *NodeWriteNewSeriesAsyncResult_, err error) {
+	if err = p.sendSetWriteNewSeriesAsync(req); err != nil {
 		return
 	}
-	return p.recvWriteBatchRaw()
+	return p.recvSetWriteNewSeriesAsync()
 }
 
-func (p *NodeClient) sendWriteBatchRaw(req *WriteBatchRawRequest) (err error) {
+func (p *NodeClient) sendSetWriteNewSeriesAsync(req *NodeSetWriteNewSeriesAsyncRequest) (err error) {
 	oprot := p.OutputProtocol
 	if oprot == nil {
 		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
 		p.OutputProtocol = oprot
 	}
 	p.SeqId++
-	if err = oprot.WriteMessageBegin("writeBatchRaw", thrift.CALL, p.SeqId); err != nil {
+	if err = oprot.WriteMessageBegin("setWriteNewSeriesAsync", thrift.CALL, p.SeqId); err != nil {
 		return
 	}
-	args := NodeWriteBatchRawArgs{
+	args := NodeSetWriteNewSeriesAsyncArgs{
 		Req: req,
 	}
 	if err = args.Write(oprot); err != nil {
@@ -11375,7 +15173,7 @@ func (p *NodeClient) sendWriteBatchRaw(req *WriteBatchRawRequest) (err error) {
 	return oprot.Flush()
 }
 
-func (p *NodeClient) recvWriteBatchRaw() (err error) {
+func (p *NodeClient) recvSetWriteNewSeriesAsync() (value *NodeWriteNewSeriesAsyncResult_, err error) {
 	iprot := p.InputProtocol
 	if iprot == nil {
 		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
@@ -11385,32 +15183,32 @@ func (p *NodeClient) recvWriteBatchRaw() (err error) {
 	if err != nil {
 		return
 	}
-	if method != "writeBatchRaw" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "writeBatchRaw failed: wrong method name")
+	if method != "setWriteNewSeriesAsync" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "setWriteNewSeriesAsync failed: wrong method name")
 		return
 	}
 	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "writeBatchRaw failed: out of sequence response")
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "setWriteNewSeriesAsync failed: out of sequence response")
 		return
 	}
 	if mTypeId == thrift.EXCEPTION {
-		error41 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error42 error
-		error42, err = error41.Read(iprot)
+		error59 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error60 error
+		error60, err = error59.Read(iprot)
 		if err != nil {
 			return
 		}
 		if err = iprot.ReadMessageEnd(); err != nil {
 			return
 		}
-		err = error42
+		err = error60
 		return
 	}
 	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "writeBatchRaw failed: invalid message type")
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "setWriteNewSeriesAsync failed: invalid message type")
 		return
 	}
-	result := NodeWriteBatchRawResult{}
+	result := NodeSetWriteNewSeriesAsyncResult{}
 	if err = result.Read(iprot); err != nil {
 		return
 	}
@@ -11421,31 +15219,28 @@ func (p *NodeClient) recvWriteBatchRaw() (err error) {
 		err = result.Err
 		return
 	}
+	value = result.GetSuccess()
 	return
 }
 
-// Parameters:
-//  - Req
-func (p *NodeClient) WriteTaggedBatchRaw(req *WriteTaggedBatchRawRequest) (err error) {
-	if err = p.sendWriteTaggedBatchRaw(req); err != nil {
+func (p *NodeClient) GetWriteNewSeriesBackoffDuration() (r *NodeWriteNewSeriesBackoffDurationResult_, err error) {
+	if err = p.sendGetWriteNewSeriesBackoffDuration(); err != nil {
 		return
 	}
-	return p.recvWriteTaggedBatchRaw()
+	return p.recvGetWriteNewSeriesBackoffDuration()
 }
 
-func (p *NodeClient) sendWriteTaggedBatchRaw(req *WriteTaggedBatchRawRequest) (err error) {
+func (p *NodeClient) sendGetWriteNewSeriesBackoffDuration() (err error) {
 	oprot := p.OutputProtocol
 	if oprot == nil {
 		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
 		p.OutputProtocol = oprot
 	}
 	p.SeqId++
-	if err = oprot.WriteMessageBegin("writeTaggedBatchRaw", thrift.CALL, p.SeqId); err != nil {
+	if err = oprot.WriteMessageBegin("getWriteNewSeriesBackoffDuration", thrift.CALL, p.SeqId); err != nil {
 		return
 	}
-	args := NodeWriteTaggedBatchRawArgs{
-		Req: req,
-	}
+	args := NodeGetWriteNewSeriesBackoffDurationArgs{}
 	if err = args.Write(oprot); err != nil {
 		return
 	}
@@ -11455,7 +15250,7 @@ func (p *NodeClient) sendWriteTaggedBatchRaw(req *WriteTaggedBatchRawRequest) (e
 	return oprot.Flush()
 }
 
-func (p *NodeClient) recvWriteTaggedBatchRaw() (err error) {
+func (p *NodeClient) recvGetWriteNewSeriesBackoffDuration() (value *NodeWriteNewSeriesBackoffDurationResult_, err error) {
 	iprot := p.InputProtocol
 	if iprot == nil {
 		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
@@ -11465,32 +15260,32 @@ func (p *NodeClient) recvWriteTaggedBatchRaw() (err error) {
 	if err != nil {
 		return
 	}
-	if method != "writeTaggedBatchRaw" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "writeTaggedBatchRaw failed: wrong method name")
+	if method != "getWriteNewSeriesBackoffDuration" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "getWriteNewSeriesBackoffDuration failed: wrong method name")
 		return
 	}
 	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "writeTaggedBatchRaw failed: out of sequence response")
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "getWriteNewSeriesBackoffDuration failed: out of sequence response")
 		return
 	}
 	if mTypeId == thrift.EXCEPTION {
-		error43 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error44 error
-		error44, err = error43.Read(iprot)
+		error61 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error62 error
+		error62, err = error61.Read(iprot)
 		if err != nil {
 			return
 		}
 		if err = iprot.ReadMessageEnd(); err != nil {
 			return
 		}
-		err = error44
+		err = error62
 		return
 	}
 	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "writeTaggedBatchRaw failed: invalid message type")
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "getWriteNewSeriesBackoffDuration failed: invalid message type")
 		return
 	}
-	result := NodeWriteTaggedBatchRawResult{}
+	result := NodeGetWriteNewSeriesBackoffDurationResult{}
 	if err = result.Read(iprot); err != nil {
 		return
 	}
@@ -11501,27 +15296,32 @@ func (p *NodeClient) recvWriteTaggedBatchRaw() (err error) {
 		err = result.Err
 		return
 	}
+	value = result.GetSuccess()
 	return
 }
 
-func (p *NodeClient) Repair() (err error) {
-	if err = p.sendRepair(); err != nil {
+// Parameters:
+//  - Req
+func (p *NodeClient) SetWriteNewSeriesBackoffDuration(req *NodeSetWriteNewSeriesBackoffDurationRequest) (r *NodeWriteNewSeriesBackoffDurationResult_, err error) {
+	if err = p.sendSetWriteNewSeriesBackoffDuration(req); err != nil {
 		return
 	}
-	return p.recvRepair()
+	return p.recvSetWriteNewSeriesBackoffDuration()
 }
 
-func (p *NodeClient) sendRepair() (err error) {
+func (p *NodeClient) sendSetWriteNewSeriesBackoffDuration(req *NodeSetWriteNewSeriesBackoffDurationRequest) (err error) {
 	oprot := p.OutputProtocol
 	if oprot == nil {
 		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
 		p.OutputProtocol = oprot
 	}
 	p.SeqId++
-	if err = oprot.WriteMessageBegin("repair", thrift.CALL, p.SeqId); err != nil {
+	if err = oprot.WriteMessageBegin("setWriteNewSeriesBackoffDuration", thrift.CALL, p.SeqId); err != nil {
 		return
 	}
-	args := NodeRepairArgs{}
+	args := NodeSetWriteNewSeriesBackoffDurationArgs{
+		Req: req,
+	}
 	if err = args.Write(oprot); err != nil {
 		return
 	}
@@ -11531,7 +15331,7 @@ func (p *NodeClient) sendRepair() (err error) {
 	return oprot.Flush()
 }
 
-func (p *NodeClient) recvRepair() (err error) {
+func (p *NodeClient) recvSetWriteNewSeriesBackoffDuration() (value *NodeWriteNewSeriesBackoffDurationResult_, err error) {
 	iprot := p.InputProtocol
 	if iprot == nil {
 		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
@@ -11541,32 +15341,32 @@ func (p *NodeClient) recvRepair() (err error) {
 	if err != nil {
 		return
 	}
-	if method != "repair" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "repair failed: wrong method name")
+	if method != "setWriteNewSeriesBackoffDuration" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "setWriteNewSeriesBackoffDuration failed: wrong method name")
 		return
 	}
 	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "repair failed: out of sequence response")
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "setWriteNewSeriesBackoffDuration failed: out of sequence response")
 		return
 	}
 	if mTypeId == thrift.EXCEPTION {
-		error45 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error46 error
-		error46, err = error45.Read(iprot)
+		error63 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error64 error
+		error64, err = error63.Read(iprot)
 		if err != nil {
 			return
 		}
 		if err = iprot.ReadMessageEnd(); err != nil {
 			return
 		}
-		err = error46
+		err = error64
 		return
 	}
 	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "repair failed: invalid message type")
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "setWriteNewSeriesBackoffDuration failed: invalid message type")
 		return
 	}
-	result := NodeRepairResult{}
+	result := NodeSetWriteNewSeriesBackoffDurationResult{}
 	if err = result.Read(iprot); err != nil {
 		return
 	}
@@ -11577,31 +15377,28 @@ func (p *NodeClient) recvRepair() (err error) {
 		err = result.Err
 		return
 	}
+	value = result.GetSuccess()
 	return
 }
 
-// Parameters:
-//  - Req
-func (p *NodeClient) Truncate(req *TruncateRequest) (r *TruncateResult_, err error) {
-	if err = p.sendTruncate(req); err != nil {
+func (p *NodeClient) GetWriteNewSeriesLimitPerShardPerSecond() (r *NodeWriteNewSeriesLimitPerShardPerSecondResult_, err error) {
+	if err = p.sendGetWriteNewSeriesLimitPerShardPerSecond(); err != nil {
 		return
 	}
-	return p.recvTruncate()
+	return p.recvGetWriteNewSeriesLimitPerShardPerSecond()
 }
 
-func (p *NodeClient) sendTruncate(req *TruncateRequest) (err error) {
+func (p *NodeClient) sendGetWriteNewSeriesLimitPerShardPerSecond() (err error) {
 	oprot := p.OutputProtocol
 	if oprot == nil {
 		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
 		p.OutputProtocol = oprot
 	}
 	p.SeqId++
-	if err = oprot.WriteMessageBegin("truncate", thrift.CALL, p.SeqId); err != nil {
+	if err = oprot.WriteMessageBegin("getWriteNewSeriesLimitPerShardPerSecond", thrift.CALL, p.SeqId); err != nil {
 		return
 	}
-	args := NodeTruncateArgs{
-		Req: req,
-	}
+	args := NodeGetWriteNewSeriesLimitPerShardPerSecondArgs{}
 	if err = args.Write(oprot); err != nil {
 		return
 	}
@@ -11611,7 +15408,7 @@ func (p *NodeClient) sendTruncate(req *TruncateRequest) (err error) {
 	return oprot.Flush()
 }
 
-func (p *NodeClient) recvTruncate() (value *TruncateResult_, err error) {
+func (p *NodeClient) recvGetWriteNewSeriesLimitPerShardPerSecond() (value *NodeWriteNewSeriesLimitPerShardPerSecondResult_, err error) {
 	iprot := p.InputProtocol
 	if iprot == nil {
 		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
@@ -11621,32 +15418,32 @@ func (p *NodeClient) recvTruncate() (value *TruncateResult_, err error) {
 	if err != nil {
 		return
 	}
-	if method != "truncate" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "truncate failed: wrong method name")
+	if method != "getWriteNewSeriesLimitPerShardPerSecond" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "getWriteNewSeriesLimitPerShardPerSecond failed: wrong method name")
 		return
 	}
 	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "truncate failed: out of sequence response")
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "getWriteNewSeriesLimitPerShardPerSecond failed: out of sequence response")
 		return
 	}
 	if mTypeId == thrift.EXCEPTION {
-		error47 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error48 error
-		error48, err = error47.Read(iprot)
+		error65 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error66 error
+		error66, err = error65.Read(iprot)
 		if err != nil {
 			return
 		}
 		if err = iprot.ReadMessageEnd(); err != nil {
 			return
 		}
-		err = error48
+		err = error66
 		return
 	}
 	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "truncate failed: invalid message type")
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "getWriteNewSeriesLimitPerShardPerSecond failed: invalid message type")
 		return
 	}
-	result := NodeTruncateResult{}
+	result := NodeGetWriteNewSeriesLimitPerShardPerSecondResult{}
 	if err = result.Read(iprot); err != nil {
 		return
 	}
@@ -11661,24 +15458,28 @@ func (p *NodeClient) recvTruncate() (value *TruncateResult_, err error) {
 	return
 }
 
-func (p *NodeClient) Health() (r *NodeHealthResult_, err error) {
-	if err = p.sendHealth(); err != nil {
+// Parameters:
+//  - Req
+func (p *NodeClient) SetWriteNewSeriesLimitPerShardPerSecond(req *NodeSetWriteNewSeriesLimitPerShardPerSecondRequest) (r *NodeWriteNewSeriesLimitPerShardPerSecondResult_, err error) {
+	if err = p.sendSetWriteNewSeriesLimitPerShardPerSecond(req); err != nil {
 		return
 	}
-	return p.recvHealth()
+	return p.recvSetWriteNewSeriesLimitPerShardPerSecond()
 }
 
-func (p *NodeClient) sendHealth() (err error) {
+func (p *NodeClient) sendSetWriteNewSeriesLimitPerShardPerSecond(req *NodeSetWriteNewSeriesLimitPerShardPerSecondRequest) (err error) {
 	oprot := p.OutputProtocol
 	if oprot == nil {
 		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
 		p.OutputProtocol = oprot
 	}
 	p.SeqId++
-	if err = oprot.WriteMessageBegin("health", thrift.CALL, p.SeqId); err != nil {
+	if err = oprot.WriteMessageBegin("setWriteNewSeriesLimitPerShardPerSecond", thrift.CALL, p.SeqId); err != nil {
 		return
 	}
-	args := NodeHealthArgs{}
+	args := NodeSetWriteNewSeriesLimitPerShardPerSecondArgs{
+		Req: req,
+	}
 	if err = args.Write(oprot); err != nil {
 		return
 	}
@@ -11688,7 +15489,7 @@ func (p *NodeClient) sendHealth() (err error) {
 	return oprot.Flush()
 }
 
-func (p *NodeClient) recvHealth() (value *NodeHealthResult_, err error) {
+func (p *NodeClient) recvSetWriteNewSeriesLimitPerShardPerSecond() (value *NodeWriteNewSeriesLimitPerShardPerSecondResult_, err error) {
 	iprot := p.InputProtocol
 	if iprot == nil {
 		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
@@ -11698,32 +15499,32 @@ func (p *NodeClient) recvHealth() (value *NodeHealthResult_, err error) {
 	if err != nil {
 		return
 	}
-	if method != "health" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "health failed: wrong method name")
+	if method != "setWriteNewSeriesLimitPerShardPerSecond" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "setWriteNewSeriesLimitPerShardPerSecond failed: wrong method name")
 		return
 	}
 	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "health failed: out of sequence response")
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "setWriteNewSeriesLimitPerShardPerSecond failed: out of sequence response")
 		return
 	}
 	if mTypeId == thrift.EXCEPTION {
-		error49 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error50 error
-		error50, err = error49.Read(iprot)
+		error67 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error68 error
+		error68, err = error67.Read(iprot)
 		if err != nil {
 			return
 		}
 		if err = iprot.ReadMessageEnd(); err != nil {
 			return
 		}
-		err = error50
+		err = error68
 		return
 	}
 	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "health failed: invalid message type")
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "setWriteNewSeriesLimitPerShardPerSecond failed: invalid message type")
 		return
 	}
-	result := NodeHealthResult{}
+	result := NodeSetWriteNewSeriesLimitPerShardPerSecondResult{}
 	if err = result.Read(iprot); err != nil {
 		return
 	}
@@ -11738,791 +15539,1078 @@ func (p *NodeClient) recvHealth() (value *NodeHealthResult_, err error) {
 	return
 }
 
-func (p *NodeClient) Bootstrapped() (r *NodeBootstrappedResult_, err error) {
-	if err = p.sendBootstrapped(); err != nil {
+type NodeProcessor struct {
+	processorMap map[string]thrift.TProcessorFunction
+	handler      Node
+}
+
+func (p *NodeProcessor) AddToProcessorMap(key string, processor thrift.TProcessorFunction) {
+	p.processorMap[key] = processor
+}
+
+func (p *NodeProcessor) GetProcessorFunction(key string) (processor thrift.TProcessorFunction, ok bool) {
+	processor, ok = p.processorMap[key]
+	return processor, ok
+}
+
+func (p *NodeProcessor) ProcessorMap() map[string]thrift.TProcessorFunction {
+	return p.processorMap
+}
+
+func NewNodeProcessor(handler Node) *NodeProcessor {
+
+	self69 := &NodeProcessor{handler: handler, processorMap: make(map[string]thrift.TProcessorFunction)}
+	self69.processorMap["query"] = &nodeProcessorQuery{handler: handler}
+	self69.processorMap["fetch"] = &nodeProcessorFetch{handler: handler}
+	self69.processorMap["fetchTagged"] = &nodeProcessorFetchTagged{handler: handler}
+	self69.processorMap["fetchTaggedPage"] = &nodeProcessorFetchTaggedPage{handler: handler}
+	self69.processorMap["aggregate"] = &nodeProcessorAggregate{handler: handler}
+	self69.processorMap["aggregateTagValues"] = &nodeProcessorAggregateTagValues{handler: handler}
+	self69.processorMap["write"] = &nodeProcessorWrite{handler: handler}
+	self69.processorMap["writeTagged"] = &nodeProcessorWriteTagged{handler: handler}
+	self69.processorMap["fetchBatchRaw"] = &nodeProcessorFetchBatchRaw{handler: handler}
+	self69.processorMap["fetchBlocksRaw"] = &nodeProcessorFetchBlocksRaw{handler: handler}
+	self69.processorMap["fetchBlocksMetadataRaw"] = &nodeProcessorFetchBlocksMetadataRaw{handler: handler}
+	self69.processorMap["fetchBlocksMetadataRawV2"] = &nodeProcessorFetchBlocksMetadataRawV2{handler: handler}
+	self69.processorMap["writeBatchRaw"] = &nodeProcessorWriteBatchRaw{handler: handler}
+	self69.processorMap["writeTaggedBatchRaw"] = &nodeProcessorWriteTaggedBatchRaw{handler: handler}
+	self69.processorMap["repair"] = &nodeProcessorRepair{handler: handler}
+	self69.processorMap["truncate"] = &nodeProcessorTruncate{handler: handler}
+	self69.processorMap["forceFlush"] = &nodeProcessorForceFlush{handler: handler}
+	self69.processorMap["health"] = &nodeProcessorHealth{handler: handler}
+	self69.processorMap["bootstrapped"] = &nodeProcessorBootstrapped{handler: handler}
+	self69.processorMap["getPersistRateLimit"] = &nodeProcessorGetPersistRateLimit{handler: handler}
+	self69.processorMap["setPersistRateLimit"] = &nodeProcessorSetPersistRateLimit{handler: handler}
+	self69.processorMap["getWriteNewSeriesAsync"] = &nodeProcessorGetWriteNewSeriesAsync{handler: handler}
+	self69.processorMap["setWriteNewSeriesAsync"] = &nodeProcessorSetWriteNewSeriesAsync{handler: handler}
+	self69.processorMap["getWriteNewSeriesBackoffDuration"] = &nodeProcessorGetWriteNewSeriesBackoffDuration{handler: handler}
+	self69.processorMap["setWriteNewSeriesBackoffDuration"] = &nodeProcessorSetWriteNewSeriesBackoffDuration{handler: handler}
+	self69.processorMap["getWriteNewSeriesLimitPerShardPerSecond"] = &nodeProcessorGetWriteNewSeriesLimitPerShardPerSecond{handler: handler}
+	self69.processorMap["setWriteNewSeriesLimitPerShardPerSecond"] = &nodeProcessorSetWriteNewSeriesLimitPerShardPerSecond{handler: handler}
+	return self69
+}
+
+func (p *NodeProcessor) Process(iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	name, _, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return false, err
+	}
+	if processor, ok := p.GetProcessorFunction(name); ok {
+		return processor.Process(seqId, iprot, oprot)
+	}
+	iprot.Skip(thrift.STRUCT)
+	iprot.ReadMessageEnd()
+	x70 := thrift.NewTApplicationException(thrift.UNKNOWN_METHOD, "Unknown function "+name)
+	oprot.WriteMessageBegin(name, thrift.EXCEPTION, seqId)
+	x70.Write(oprot)
+	oprot.WriteMessageEnd()
+	oprot.Flush()
+	return false, x70
+
+}
+
+type nodeProcessorQuery struct {
+	handler Node
+}
+
+func (p *nodeProcessorQuery) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeQueryArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("query", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
+	}
+
+	iprot.ReadMessageEnd()
+	result := NodeQueryResult{}
+	var retval *QueryResult_
+	var err2 error
+	if retval, err2 = p.handler.Query(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing query: "+err2.Error())
+			oprot.WriteMessageBegin("query", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	} else {
+		result.Success = retval
+	}
+	if err2 = oprot.WriteMessageBegin("query", thrift.REPLY, seqId); err2 != nil {
+		err = err2
+	}
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
 		return
 	}
-	return p.recvBootstrapped()
+	return true, err
 }
 
-func (p *NodeClient) sendBootstrapped() (err error) {
-	oprot := p.OutputProtocol
-	if oprot == nil {
-		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.OutputProtocol = oprot
+type nodeProcessorFetch struct {
+	handler Node
+}
+
+func (p *nodeProcessorFetch) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeFetchArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("fetch", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
+	}
+
+	iprot.ReadMessageEnd()
+	result := NodeFetchResult{}
+	var retval *FetchResult_
+	var err2 error
+	if retval, err2 = p.handler.Fetch(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing fetch: "+err2.Error())
+			oprot.WriteMessageBegin("fetch", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	} else {
+		result.Success = retval
 	}
-	p.SeqId++
-	if err = oprot.WriteMessageBegin("bootstrapped", thrift.CALL, p.SeqId); err != nil {
-		return
+	if err2 = oprot.WriteMessageBegin("fetch", thrift.REPLY, seqId); err2 != nil {
+		err = err2
 	}
-	args := NodeBootstrappedArgs{}
-	if err = args.Write(oprot); err != nil {
-		return
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
 	}
-	if err = oprot.WriteMessageEnd(); err != nil {
-		return
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
 	}
-	return oprot.Flush()
-}
-
-func (p *NodeClient) recvBootstrapped() (value *NodeBootstrappedResult_, err error) {
-	iprot := p.InputProtocol
-	if iprot == nil {
-		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.InputProtocol = iprot
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
 	}
-	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
 	if err != nil {
 		return
 	}
-	if method != "bootstrapped" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "bootstrapped failed: wrong method name")
-		return
-	}
-	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "bootstrapped failed: out of sequence response")
-		return
+	return true, err
+}
+
+type nodeProcessorFetchTagged struct {
+	handler Node
+}
+
+func (p *nodeProcessorFetchTagged) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeFetchTaggedArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("fetchTagged", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
 	}
-	if mTypeId == thrift.EXCEPTION {
-		error51 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error52 error
-		error52, err = error51.Read(iprot)
-		if err != nil {
-			return
-		}
-		if err = iprot.ReadMessageEnd(); err != nil {
-			return
+
+	iprot.ReadMessageEnd()
+	result := NodeFetchTaggedResult{}
+	var retval *FetchTaggedResult_
+	var err2 error
+	if retval, err2 = p.handler.FetchTagged(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing fetchTagged: "+err2.Error())
+			oprot.WriteMessageBegin("fetchTagged", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
 		}
-		err = error52
-		return
+	} else {
+		result.Success = retval
 	}
-	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "bootstrapped failed: invalid message type")
-		return
+	if err2 = oprot.WriteMessageBegin("fetchTagged", thrift.REPLY, seqId); err2 != nil {
+		err = err2
 	}
-	result := NodeBootstrappedResult{}
-	if err = result.Read(iprot); err != nil {
-		return
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
 	}
-	if err = iprot.ReadMessageEnd(); err != nil {
-		return
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
 	}
-	if result.Err != nil {
-		err = result.Err
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
 		return
 	}
-	value = result.GetSuccess()
-	return
+	return true, err
 }
 
-func (p *NodeClient) GetPersistRateLimit() (r *NodePersistRateLimitResult_, err error) {
-	if err = p.sendGetPersistRateLimit(); err != nil {
-		return
-	}
-	return p.recvGetPersistRateLimit()
+type nodeProcessorFetchTaggedPage struct {
+	handler Node
 }
 
-func (p *NodeClient) sendGetPersistRateLimit() (err error) {
-	oprot := p.OutputProtocol
-	if oprot == nil {
-		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.OutputProtocol = oprot
+func (p *nodeProcessorFetchTaggedPage) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeFetchTaggedPageArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("fetchTaggedPage", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
 	}
-	p.SeqId++
-	if err = oprot.WriteMessageBegin("getPersistRateLimit", thrift.CALL, p.SeqId); err != nil {
-		return
+
+	iprot.ReadMessageEnd()
+	result := NodeFetchTaggedPageResult{}
+	var retval *FetchTaggedPageResult_
+	var err2 error
+	if retval, err2 = p.handler.FetchTaggedPage(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing fetchTaggedPage: "+err2.Error())
+			oprot.WriteMessageBegin("fetchTaggedPage", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	} else {
+		result.Success = retval
 	}
-	args := NodeGetPersistRateLimitArgs{}
-	if err = args.Write(oprot); err != nil {
-		return
+	if err2 = oprot.WriteMessageBegin("fetchTaggedPage", thrift.REPLY, seqId); err2 != nil {
+		err = err2
 	}
-	if err = oprot.WriteMessageEnd(); err != nil {
-		return
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
 	}
-	return oprot.Flush()
-}
-
-func (p *NodeClient) recvGetPersistRateLimit() (value *NodePersistRateLimitResult_, err error) {
-	iprot := p.InputProtocol
-	if iprot == nil {
-		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.InputProtocol = iprot
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
 	}
-	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
-	if err != nil {
-		return
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
 	}
-	if method != "getPersistRateLimit" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "getPersistRateLimit failed: wrong method name")
+	if err != nil {
 		return
 	}
-	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "getPersistRateLimit failed: out of sequence response")
-		return
+	return true, err
+}
+
+type nodeProcessorAggregate struct {
+	handler Node
+}
+
+func (p *nodeProcessorAggregate) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeAggregateArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("aggregate", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
 	}
-	if mTypeId == thrift.EXCEPTION {
-		error53 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error54 error
-		error54, err = error53.Read(iprot)
-		if err != nil {
-			return
-		}
-		if err = iprot.ReadMessageEnd(); err != nil {
-			return
+
+	iprot.ReadMessageEnd()
+	result := NodeAggregateResult{}
+	var retval *AggregateQueryResult_
+	var err2 error
+	if retval, err2 = p.handler.Aggregate(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing aggregate: "+err2.Error())
+			oprot.WriteMessageBegin("aggregate", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
 		}
-		err = error54
-		return
+	} else {
+		result.Success = retval
 	}
-	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "getPersistRateLimit failed: invalid message type")
-		return
+	if err2 = oprot.WriteMessageBegin("aggregate", thrift.REPLY, seqId); err2 != nil {
+		err = err2
 	}
-	result := NodeGetPersistRateLimitResult{}
-	if err = result.Read(iprot); err != nil {
-		return
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
 	}
-	if err = iprot.ReadMessageEnd(); err != nil {
-		return
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
 	}
-	if result.Err != nil {
-		err = result.Err
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
 		return
 	}
-	value = result.GetSuccess()
-	return
+	return true, err
 }
 
-// Parameters:
-//  - Req
-func (p *NodeClient) SetPersistRateLimit(req *NodeSetPersistRateLimitRequest) (r *NodePersistRateLimitResult_, err error) {
-	if err = p.sendSetPersistRateLimit(req); err != nil {
-		return
-	}
-	return p.recvSetPersistRateLimit()
+type nodeProcessorAggregateTagValues struct {
+	handler Node
 }
 
-func (p *NodeClient) sendSetPersistRateLimit(req *NodeSetPersistRateLimitRequest) (err error) {
-	oprot := p.OutputProtocol
-	if oprot == nil {
-		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.OutputProtocol = oprot
+func (p *nodeProcessorAggregateTagValues) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeAggregateTagValuesArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("aggregateTagValues", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
 	}
-	p.SeqId++
-	if err = oprot.WriteMessageBegin("setPersistRateLimit", thrift.CALL, p.SeqId); err != nil {
-		return
+
+	iprot.ReadMessageEnd()
+	result := NodeAggregateTagValuesResult{}
+	var retval *AggregateTagValuesResult_
+	var err2 error
+	if retval, err2 = p.handler.AggregateTagValues(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing aggregateTagValues: "+err2.Error())
+			oprot.WriteMessageBegin("aggregateTagValues", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	} else {
+		result.Success = retval
 	}
-	args := NodeSetPersistRateLimitArgs{
-		Req: req,
+	if err2 = oprot.WriteMessageBegin("aggregateTagValues", thrift.REPLY, seqId); err2 != nil {
+		err = err2
 	}
-	if err = args.Write(oprot); err != nil {
-		return
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
 	}
-	if err = oprot.WriteMessageEnd(); err != nil {
-		return
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
 	}
-	return oprot.Flush()
-}
-
-func (p *NodeClient) recvSetPersistRateLimit() (value *NodePersistRateLimitResult_, err error) {
-	iprot := p.InputProtocol
-	if iprot == nil {
-		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.InputProtocol = iprot
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
 	}
-	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
 	if err != nil {
 		return
 	}
-	if method != "setPersistRateLimit" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "setPersistRateLimit failed: wrong method name")
-		return
-	}
-	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "setPersistRateLimit failed: out of sequence response")
-		return
+	return true, err
+}
+
+type nodeProcessorWrite struct {
+	handler Node
+}
+
+func (p *nodeProcessorWrite) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeWriteArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("write", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
 	}
-	if mTypeId == thrift.EXCEPTION {
-		error55 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error56 error
-		error56, err = error55.Read(iprot)
-		if err != nil {
-			return
-		}
-		if err = iprot.ReadMessageEnd(); err != nil {
-			return
+
+	iprot.ReadMessageEnd()
+	result := NodeWriteResult{}
+	var err2 error
+	if err2 = p.handler.Write(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing write: "+err2.Error())
+			oprot.WriteMessageBegin("write", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
 		}
-		err = error56
-		return
 	}
-	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "setPersistRateLimit failed: invalid message type")
-		return
+	if err2 = oprot.WriteMessageBegin("write", thrift.REPLY, seqId); err2 != nil {
+		err = err2
 	}
-	result := NodeSetPersistRateLimitResult{}
-	if err = result.Read(iprot); err != nil {
-		return
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
 	}
-	if err = iprot.ReadMessageEnd(); err != nil {
-		return
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
 	}
-	if result.Err != nil {
-		err = result.Err
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
 		return
 	}
-	value = result.GetSuccess()
-	return
+	return true, err
 }
 
-func (p *NodeClient) GetWriteNewSeriesAsync() (r *NodeWriteNewSeriesAsyncResult_, err error) {
-	if err = p.sendGetWriteNewSeriesAsync(); err != nil {
-		return
-	}
-	return p.recvGetWriteNewSeriesAsync()
+type nodeProcessorWriteTagged struct {
+	handler Node
 }
 
-func (p *NodeClient) sendGetWriteNewSeriesAsync() (err error) {
-	oprot := p.OutputProtocol
-	if oprot == nil {
-		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.OutputProtocol = oprot
+func (p *nodeProcessorWriteTagged) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeWriteTaggedArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("writeTagged", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
 	}
-	p.SeqId++
-	if err = oprot.WriteMessageBegin("getWriteNewSeriesAsync", thrift.CALL, p.SeqId); err != nil {
-		return
+
+	iprot.ReadMessageEnd()
+	result := NodeWriteTaggedResult{}
+	var err2 error
+	if err2 = p.handler.WriteTagged(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing writeTagged: "+err2.Error())
+			oprot.WriteMessageBegin("writeTagged", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
 	}
-	args := NodeGetWriteNewSeriesAsyncArgs{}
-	if err = args.Write(oprot); err != nil {
-		return
+	if err2 = oprot.WriteMessageBegin("writeTagged", thrift.REPLY, seqId); err2 != nil {
+		err = err2
 	}
-	if err = oprot.WriteMessageEnd(); err != nil {
-		return
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
 	}
-	return oprot.Flush()
-}
-
-func (p *NodeClient) recvGetWriteNewSeriesAsync() (value *NodeWriteNewSeriesAsyncResult_, err error) {
-	iprot := p.InputProtocol
-	if iprot == nil {
-		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.InputProtocol = iprot
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
 	}
-	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
-	if err != nil {
-		return
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
 	}
-	if method != "getWriteNewSeriesAsync" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "getWriteNewSeriesAsync failed: wrong method name")
+	if err != nil {
 		return
 	}
-	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "getWriteNewSeriesAsync failed: out of sequence response")
-		return
+	return true, err
+}
+
+type nodeProcessorFetchBatchRaw struct {
+	handler Node
+}
+
+func (p *nodeProcessorFetchBatchRaw) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeFetchBatchRawArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("fetchBatchRaw", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
 	}
-	if mTypeId == thrift.EXCEPTION {
-		error57 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error58 error
-		error58, err = error57.Read(iprot)
-		if err != nil {
-			return
-		}
-		if err = iprot.ReadMessageEnd(); err != nil {
-			return
+
+	iprot.ReadMessageEnd()
+	result := NodeFetchBatchRawResult{}
+	var retval *FetchBatchRawResult_
+	var err2 error
+	if retval, err2 = p.handler.FetchBatchRaw(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing fetchBatchRaw: "+err2.Error())
+			oprot.WriteMessageBegin("fetchBatchRaw", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
 		}
-		err = error58
-		return
+	} else {
+		result.Success = retval
 	}
-	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "getWriteNewSeriesAsync failed: invalid message type")
-		return
+	if err2 = oprot.WriteMessageBegin("fetchBatchRaw", thrift.REPLY, seqId); err2 != nil {
+		err = err2
 	}
-	result := NodeGetWriteNewSeriesAsyncResult{}
-	if err = result.Read(iprot); err != nil {
-		return
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
 	}
-	if err = iprot.ReadMessageEnd(); err != nil {
-		return
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
 	}
-	if result.Err != nil {
-		err = result.Err
-		return
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
 	}
-	value = result.GetSuccess()
-	return
-}
-
-// Parameters:
-//  - Req
-func (p *NodeClient) SetWriteNewSeriesAsync(req *NodeSetWriteNewSeriesAsyncRequest) (r *NodeWriteNewSeriesAsyncResult_, err error) {
-	if err = p.sendSetWriteNewSeriesAsync(req); err != nil {
+	if err != nil {
 		return
 	}
-	return p.recvSetWriteNewSeriesAsync()
+	return true, err
 }
 
-func (p *NodeClient) sendSetWriteNewSeriesAsync(req *NodeSetWriteNewSeriesAsyncRequest) (err error) {
-	oprot := p.OutputProtocol
-	if oprot == nil {
-		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.OutputProtocol = oprot
+type nodeProcessorFetchBlocksRaw struct {
+	handler Node
+}
+
+func (p *nodeProcessorFetchBlocksRaw) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeFetchBlocksRawArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("fetchBlocksRaw", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
 	}
-	p.SeqId++
-	if err = oprot.WriteMessageBegin("setWriteNewSeriesAsync", thrift.CALL, p.SeqId); err != nil {
-		return
+
+	iprot.ReadMessageEnd()
+	result := NodeFetchBlocksRawResult{}
+	var retval *FetchBlocksRawResult_
+	var err2 error
+	if retval, err2 = p.handler.FetchBlocksRaw(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing fetchBlocksRaw: "+err2.Error())
+			oprot.WriteMessageBegin("fetchBlocksRaw", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	} else {
+		result.Success = retval
 	}
-	args := NodeSetWriteNewSeriesAsyncArgs{
-		Req: req,
+	if err2 = oprot.WriteMessageBegin("fetchBlocksRaw", thrift.REPLY, seqId); err2 != nil {
+		err = err2
 	}
-	if err = args.Write(oprot); err != nil {
-		return
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
 	}
-	if err = oprot.WriteMessageEnd(); err != nil {
-		return
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
 	}
-	return oprot.Flush()
-}
-
-func (p *NodeClient) recvSetWriteNewSeriesAsync() (value *NodeWriteNewSeriesAsyncResult_, err error) {
-	iprot := p.InputProtocol
-	if iprot == nil {
-		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.InputProtocol = iprot
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
 	}
-	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
 	if err != nil {
 		return
 	}
-	if method != "setWriteNewSeriesAsync" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "setWriteNewSeriesAsync failed: wrong method name")
-		return
-	}
-	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "setWriteNewSeriesAsync failed: out of sequence response")
-		return
+	return true, err
+}
+
+type nodeProcessorFetchBlocksMetadataRaw struct {
+	handler Node
+}
+
+func (p *nodeProcessorFetchBlocksMetadataRaw) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeFetchBlocksMetadataRawArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("fetchBlocksMetadataRaw", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
 	}
-	if mTypeId == thrift.EXCEPTION {
-		error59 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error60 error
-		error60, err = error59.Read(iprot)
-		if err != nil {
-			return
-		}
-		if err = iprot.ReadMessageEnd(); err != nil {
-			return
+
+	iprot.ReadMessageEnd()
+	result := NodeFetchBlocksMetadataRawResult{}
+	var retval *FetchBlocksMetadataRawResult_
+	var err2 error
+	if retval, err2 = p.handler.FetchBlocksMetadataRaw(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing fetchBlocksMetadataRaw: "+err2.Error())
+			oprot.WriteMessageBegin("fetchBlocksMetadataRaw", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
 		}
-		err = error60
-		return
+	} else {
+		result.Success = retval
 	}
-	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "setWriteNewSeriesAsync failed: invalid message type")
-		return
+	if err2 = oprot.WriteMessageBegin("fetchBlocksMetadataRaw", thrift.REPLY, seqId); err2 != nil {
+		err = err2
 	}
-	result := NodeSetWriteNewSeriesAsyncResult{}
-	if err = result.Read(iprot); err != nil {
-		return
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
 	}
-	if err = iprot.ReadMessageEnd(); err != nil {
-		return
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
 	}
-	if result.Err != nil {
-		err = result.Err
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
 		return
 	}
-	value = result.GetSuccess()
-	return
+	return true, err
 }
 
-func (p *NodeClient) GetWriteNewSeriesBackoffDuration() (r *NodeWriteNewSeriesBackoffDurationResult_, err error) {
-	if err = p.sendGetWriteNewSeriesBackoffDuration(); err != nil {
-		return
-	}
-	return p.recvGetWriteNewSeriesBackoffDuration()
+type nodeProcessorFetchBlocksMetadataRawV2 struct {
+	handler Node
 }
 
-func (p *NodeClient) sendGetWriteNewSeriesBackoffDuration() (err error) {
-	oprot := p.OutputProtocol
-	if oprot == nil {
-		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.OutputProtocol = oprot
+func (p *nodeProcessorFetchBlocksMetadataRawV2) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeFetchBlocksMetadataRawV2Args{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("fetchBlocksMetadataRawV2", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
 	}
-	p.SeqId++
-	if err = oprot.WriteMessageBegin("getWriteNewSeriesBackoffDuration", thrift.CALL, p.SeqId); err != nil {
-		return
+
+	iprot.ReadMessageEnd()
+	result := NodeFetchBlocksMetadataRawV2Result{}
+	var retval *FetchBlocksMetadataRawV2Result_
+	var err2 error
+	if retval, err2 = p.handler.FetchBlocksMetadataRawV2(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing fetchBlocksMetadataRawV2: "+err2.Error())
+			oprot.WriteMessageBegin("fetchBlocksMetadataRawV2", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	} else {
+		result.Success = retval
 	}
-	args := NodeGetWriteNewSeriesBackoffDurationArgs{}
-	if err = args.Write(oprot); err != nil {
-		return
+	if err2 = oprot.WriteMessageBegin("fetchBlocksMetadataRawV2", thrift.REPLY, seqId); err2 != nil {
+		err = err2
 	}
-	if err = oprot.WriteMessageEnd(); err != nil {
-		return
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
 	}
-	return oprot.Flush()
-}
-
-func (p *NodeClient) recvGetWriteNewSeriesBackoffDuration() (value *NodeWriteNewSeriesBackoffDurationResult_, err error) {
-	iprot := p.InputProtocol
-	if iprot == nil {
-		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.InputProtocol = iprot
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
 	}
-	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
-	if err != nil {
-		return
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
 	}
-	if method != "getWriteNewSeriesBackoffDuration" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "getWriteNewSeriesBackoffDuration failed: wrong method name")
+	if err != nil {
 		return
 	}
-	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "getWriteNewSeriesBackoffDuration failed: out of sequence response")
-		return
+	return true, err
+}
+
+type nodeProcessorWriteBatchRaw struct {
+	handler Node
+}
+
+func (p *nodeProcessorWriteBatchRaw) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeWriteBatchRawArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("writeBatchRaw", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
 	}
-	if mTypeId == thrift.EXCEPTION {
-		error61 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error62 error
-		error62, err = error61.Read(iprot)
-		if err != nil {
-			return
-		}
-		if err = iprot.ReadMessageEnd(); err != nil {
-			return
+
+	iprot.ReadMessageEnd()
+	result := NodeWriteBatchRawResult{}
+	var err2 error
+	if err2 = p.handler.WriteBatchRaw(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *WriteBatchRawErrors:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing writeBatchRaw: "+err2.Error())
+			oprot.WriteMessageBegin("writeBatchRaw", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
 		}
-		err = error62
-		return
 	}
-	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "getWriteNewSeriesBackoffDuration failed: invalid message type")
-		return
+	if err2 = oprot.WriteMessageBegin("writeBatchRaw", thrift.REPLY, seqId); err2 != nil {
+		err = err2
 	}
-	result := NodeGetWriteNewSeriesBackoffDurationResult{}
-	if err = result.Read(iprot); err != nil {
-		return
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
 	}
-	if err = iprot.ReadMessageEnd(); err != nil {
-		return
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
 	}
-	if result.Err != nil {
-		err = result.Err
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
 		return
 	}
-	value = result.GetSuccess()
-	return
+	return true, err
 }
 
-// Parameters:
-//  - Req
-func (p *NodeClient) SetWriteNewSeriesBackoffDuration(req *NodeSetWriteNewSeriesBackoffDurationRequest) (r *NodeWriteNewSeriesBackoffDurationResult_, err error) {
-	if err = p.sendSetWriteNewSeriesBackoffDuration(req); err != nil {
-		return
-	}
-	return p.recvSetWriteNewSeriesBackoffDuration()
+type nodeProcessorWriteTaggedBatchRaw struct {
+	handler Node
 }
 
-func (p *NodeClient) sendSetWriteNewSeriesBackoffDuration(req *NodeSetWriteNewSeriesBackoffDurationRequest) (err error) {
-	oprot := p.OutputProtocol
-	if oprot == nil {
-		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.OutputProtocol = oprot
+func (p *nodeProcessorWriteTaggedBatchRaw) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeWriteTaggedBatchRawArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("writeTaggedBatchRaw", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
 	}
-	p.SeqId++
-	if err = oprot.WriteMessageBegin("setWriteNewSeriesBackoffDuration", thrift.CALL, p.SeqId); err != nil {
-		return
+
+	iprot.ReadMessageEnd()
+	result := NodeWriteTaggedBatchRawResult{}
+	var err2 error
+	if err2 = p.handler.WriteTaggedBatchRaw(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *WriteBatchRawErrors:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing writeTaggedBatchRaw: "+err2.Error())
+			oprot.WriteMessageBegin("writeTaggedBatchRaw", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
 	}
-	args := NodeSetWriteNewSeriesBackoffDurationArgs{
-		Req: req,
+	if err2 = oprot.WriteMessageBegin("writeTaggedBatchRaw", thrift.REPLY, seqId); err2 != nil {
+		err = err2
 	}
-	if err = args.Write(oprot); err != nil {
-		return
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
 	}
-	if err = oprot.WriteMessageEnd(); err != nil {
-		return
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
 	}
-	return oprot.Flush()
-}
-
-func (p *NodeClient) recvSetWriteNewSeriesBackoffDuration() (value *NodeWriteNewSeriesBackoffDurationResult_, err error) {
-	iprot := p.InputProtocol
-	if iprot == nil {
-		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.InputProtocol = iprot
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
 	}
-	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
 	if err != nil {
 		return
 	}
-	if method != "setWriteNewSeriesBackoffDuration" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "setWriteNewSeriesBackoffDuration failed: wrong method name")
-		return
-	}
-	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "setWriteNewSeriesBackoffDuration failed: out of sequence response")
-		return
+	return true, err
+}
+
+type nodeProcessorRepair struct {
+	handler Node
+}
+
+func (p *nodeProcessorRepair) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeRepairArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("repair", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
 	}
-	if mTypeId == thrift.EXCEPTION {
-		error63 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error64 error
-		error64, err = error63.Read(iprot)
-		if err != nil {
-			return
-		}
-		if err = iprot.ReadMessageEnd(); err != nil {
-			return
+
+	iprot.ReadMessageEnd()
+	result := NodeRepairResult{}
+	var err2 error
+	if err2 = p.handler.Repair(); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing repair: "+err2.Error())
+			oprot.WriteMessageBegin("repair", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
 		}
-		err = error64
-		return
 	}
-	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "setWriteNewSeriesBackoffDuration failed: invalid message type")
-		return
+	if err2 = oprot.WriteMessageBegin("repair", thrift.REPLY, seqId); err2 != nil {
+		err = err2
 	}
-	result := NodeSetWriteNewSeriesBackoffDurationResult{}
-	if err = result.Read(iprot); err != nil {
-		return
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
 	}
-	if err = iprot.ReadMessageEnd(); err != nil {
-		return
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
 	}
-	if result.Err != nil {
-		err = result.Err
-		return
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
 	}
-	value = result.GetSuccess()
-	return
-}
-
-func (p *NodeClient) GetWriteNewSeriesLimitPerShardPerSecond() (r *NodeWriteNewSeriesLimitPerShardPerSecondResult_, err error) {
-	if err = p.sendGetWriteNewSeriesLimitPerShardPerSecond(); err != nil {
+	if err != nil {
 		return
 	}
-	return p.recvGetWriteNewSeriesLimitPerShardPerSecond()
+	return true, err
 }
 
-func (p *NodeClient) sendGetWriteNewSeriesLimitPerShardPerSecond() (err error) {
-	oprot := p.OutputProtocol
-	if oprot == nil {
-		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.OutputProtocol = oprot
-	}
-	p.SeqId++
-	if err = oprot.WriteMessageBegin("getWriteNewSeriesLimitPerShardPerSecond", thrift.CALL, p.SeqId); err != nil {
-		return
-	}
-	args := NodeGetWriteNewSeriesLimitPerShardPerSecondArgs{}
-	if err = args.Write(oprot); err != nil {
-		return
-	}
-	if err = oprot.WriteMessageEnd(); err != nil {
-		return
-	}
-	return oprot.Flush()
+type nodeProcessorTruncate struct {
+	handler Node
 }
 
-func (p *NodeClient) recvGetWriteNewSeriesLimitPerShardPerSecond() (value *NodeWriteNewSeriesLimitPerShardPerSecondResult_, err error) {
-	iprot := p.InputProtocol
-	if iprot == nil {
-		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.InputProtocol = iprot
-	}
-	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
-	if err != nil {
-		return
-	}
-	if method != "getWriteNewSeriesLimitPerShardPerSecond" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "getWriteNewSeriesLimitPerShardPerSecond failed: wrong method name")
-		return
-	}
-	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "getWriteNewSeriesLimitPerShardPerSecond failed: out of sequence response")
-		return
+func (p *nodeProcessorTruncate) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeTruncateArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("truncate", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
 	}
-	if mTypeId == thrift.EXCEPTION {
-		error65 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error66 error
-		error66, err = error65.Read(iprot)
-		if err != nil {
-			return
-		}
-		if err = iprot.ReadMessageEnd(); err != nil {
-			return
+
+	iprot.ReadMessageEnd()
+	result := NodeTruncateResult{}
+	var retval *TruncateResult_
+	var err2 error
+	if retval, err2 = p.handler.Truncate(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing truncate: "+err2.Error())
+			oprot.WriteMessageBegin("truncate", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
 		}
-		err = error66
-		return
+	} else {
+		result.Success = retval
 	}
-	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "getWriteNewSeriesLimitPerShardPerSecond failed: invalid message type")
-		return
+	if err2 = oprot.WriteMessageBegin("truncate", thrift.REPLY, seqId); err2 != nil {
+		err = err2
 	}
-	result := NodeGetWriteNewSeriesLimitPerShardPerSecondResult{}
-	if err = result.Read(iprot); err != nil {
-		return
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
 	}
-	if err = iprot.ReadMessageEnd(); err != nil {
-		return
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
 	}
-	if result.Err != nil {
-		err = result.Err
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
 		return
 	}
-	value = result.GetSuccess()
-	return
+	return true, err
 }
 
-// Parameters:
-//  - Req
-func (p *NodeClient) SetWriteNewSeriesLimitPerShardPerSecond(req *NodeSetWriteNewSeriesLimitPerShardPerSecondRequest) (r *NodeWriteNewSeriesLimitPerShardPerSecondResult_, err error) {
-	if err = p.sendSetWriteNewSeriesLimitPerShardPerSecond(req); err != nil {
-		return
-	}
-	return p.recvSetWriteNewSeriesLimitPerShardPerSecond()
+type nodeProcessorForceFlush struct {
+	handler Node
 }
 
-func (p *NodeClient) sendSetWriteNewSeriesLimitPerShardPerSecond(req *NodeSetWriteNewSeriesLimitPerShardPerSecondRequest) (err error) {
-	oprot := p.OutputProtocol
-	if oprot == nil {
-		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.OutputProtocol = oprot
+func (p *nodeProcessorForceFlush) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeForceFlushArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("forceFlush", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
 	}
-	p.SeqId++
-	if err = oprot.WriteMessageBegin("setWriteNewSeriesLimitPerShardPerSecond", thrift.CALL, p.SeqId); err != nil {
-		return
+
+	iprot.ReadMessageEnd()
+	result := NodeForceFlushResult{}
+	var retval *ForceFlushResult_
+	var err2 error
+	if retval, err2 = p.handler.ForceFlush(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing forceFlush: "+err2.Error())
+			oprot.WriteMessageBegin("forceFlush", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	} else {
+		result.Success = retval
 	}
-	args := NodeSetWriteNewSeriesLimitPerShardPerSecondArgs{
-		Req: req,
+	if err2 = oprot.WriteMessageBegin("forceFlush", thrift.REPLY, seqId); err2 != nil {
+		err = err2
 	}
-	if err = args.Write(oprot); err != nil {
-		return
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
 	}
-	if err = oprot.WriteMessageEnd(); err != nil {
-		return
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
 	}
-	return oprot.Flush()
-}
-
-func (p *NodeClient) recvSetWriteNewSeriesLimitPerShardPerSecond() (value *NodeWriteNewSeriesLimitPerShardPerSecondResult_, err error) {
-	iprot := p.InputProtocol
-	if iprot == nil {
-		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.InputProtocol = iprot
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
 	}
-	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
 	if err != nil {
 		return
 	}
-	if method != "setWriteNewSeriesLimitPerShardPerSecond" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "setWriteNewSeriesLimitPerShardPerSecond failed: wrong method name")
-		return
-	}
-	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "setWriteNewSeriesLimitPerShardPerSecond failed: out of sequence response")
-		return
+	return true, err
+}
+
+type nodeProcessorHealth struct {
+	handler Node
+}
+
+func (p *nodeProcessorHealth) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeHealthArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("health", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
 	}
-	if mTypeId == thrift.EXCEPTION {
-		error67 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error68 error
-		error68, err = error67.Read(iprot)
-		if err != nil {
-			return
-		}
-		if err = iprot.ReadMessageEnd(); err != nil {
-			return
+
+	iprot.ReadMessageEnd()
+	result := NodeHealthResult{}
+	var retval *NodeHealthResult_
+	var err2 error
+	if retval, err2 = p.handler.Health(); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing health: "+err2.Error())
+			oprot.WriteMessageBegin("health", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
 		}
-		err = error68
-		return
+	} else {
+		result.Success = retval
 	}
-	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "setWriteNewSeriesLimitPerShardPerSecond failed: invalid message type")
-		return
+	if err2 = oprot.WriteMessageBegin("health", thrift.REPLY, seqId); err2 != nil {
+		err = err2
 	}
-	result := NodeSetWriteNewSeriesLimitPerShardPerSecondResult{}
-	if err = result.Read(iprot); err != nil {
-		return
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
 	}
-	if err = iprot.ReadMessageEnd(); err != nil {
-		return
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
 	}
-	if result.Err != nil {
-		err = result.Err
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
 		return
 	}
-	value = result.GetSuccess()
-	return
-}
-
-type NodeProcessor struct {
-	processorMap map[string]thrift.TProcessorFunction
-	handler      Node
-}
-
-func (p *NodeProcessor) AddToProcessorMap(key string, processor thrift.TProcessorFunction) {
-	p.processorMap[key] = processor
-}
-
-func (p *NodeProcessor) GetProcessorFunction(key string) (processor thrift.TProcessorFunction, ok bool) {
-	processor, ok = p.processorMap[key]
-	return processor, ok
-}
-
-func (p *NodeProcessor) ProcessorMap() map[string]thrift.TProcessorFunction {
-	return p.processorMap
+	return true, err
 }
 
-func NewNodeProcessor(handler Node) *NodeProcessor {
-
-	self69 := &NodeProcessor{handler: handler, processorMap: make(map[string]thrift.TProcessorFunction)}
-	self69.processorMap["query"] = &nodeProcessorQuery{handler: handler}
-	self69.processorMap["fetch"] = &nodeProcessorFetch{handler: handler}
-	self69.processorMap["fetchTagged"] = &nodeProcessorFetchTagged{handler: handler}
-	self69.processorMap["write"] = &nodeProcessorWrite{handler: handler}
-	self69.processorMap["writeTagged"] = &nodeProcessorWriteTagged{handler: handler}
-	self69.processorMap["fetchBatchRaw"] = &nodeProcessorFetchBatchRaw{handler: handler}
-	self69.processorMap["fetchBlocksRaw"] = &nodeProcessorFetchBlocksRaw{handler: handler}
-	self69.processorMap["fetchBlocksMetadataRaw"] = &nodeProcessorFetchBlocksMetadataRaw{handler: handler}
-	self69.processorMap["fetchBlocksMetadataRawV2"] = &nodeProcessorFetchBlocksMetadataRawV2{handler: handler}
-	self69.processorMap["writeBatchRaw"] = &nodeProcessorWriteBatchRaw{handler: handler}
-	self69.processorMap["writeTaggedBatchRaw"] = &nodeProcessorWriteTaggedBatchRaw{handler: handler}
-	self69.processorMap["repair"] = &nodeProcessorRepair{handler: handler}
-	self69.processorMap["truncate"] = &nodeProcessorTruncate{handler: handler}
-	self69.processorMap["health"] = &nodeProcessorHealth{handler: handler}
-	self69.processorMap["bootstrapped"] = &nodeProcessorBootstrapped{handler: handler}
-	self69.processorMap["getPersistRateLimit"] = &nodeProcessorGetPersistRateLimit{handler: handler}
-	self69.processorMap["setPersistRateLimit"] = &nodeProcessorSetPersistRateLimit{handler: handler}
-	self69.processorMap["getWriteNewSeriesAsync"] = &nodeProcessorGetWriteNewSeriesAsync{handler: handler}
-	self69.processorMap["setWriteNewSeriesAsync"] = &nodeProcessorSetWriteNewSeriesAsync{handler: handler}
-	self69.processorMap["getWriteNewSeriesBackoffDuration"] = &nodeProcessorGetWriteNewSeriesBackoffDuration{handler: handler}
-	self69.processorMap["setWriteNewSeriesBackoffDuration"] = &nodeProcessorSetWriteNewSeriesBackoffDuration{handler: handler}
-	self69.processorMap["getWriteNewSeriesLimitPerShardPerSecond"] = &nodeProcessorGetWriteNewSeriesLimitPerShardPerSecond{handler: handler}
-	self69.processorMap["setWriteNewSeriesLimitPerShardPerSecond"] = &nodeProcessorSetWriteNewSeriesLimitPerShardPerSecond{handler: handler}
-	return self69
+type nodeProcessorBootstrapped struct {
+	handler Node
 }
 
-func (p *NodeProcessor) Process(iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	name, _, seqId, err := iprot.ReadMessageBegin()
-	if err != nil {
+func (p *nodeProcessorBootstrapped) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeBootstrappedArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("bootstrapped", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
 		return false, err
 	}
-	if processor, ok := p.GetProcessorFunction(name); ok {
-		return processor.Process(seqId, iprot, oprot)
-	}
-	iprot.Skip(thrift.STRUCT)
-	iprot.ReadMessageEnd()
-	x70 := thrift.NewTApplicationException(thrift.UNKNOWN_METHOD, "Unknown function "+name)
-	oprot.WriteMessageBegin(name, thrift.EXCEPTION, seqId)
-	x70.Write(oprot)
-	oprot.WriteMessageEnd()
-	oprot.Flush()
-	return false, x70
 
+	iprot.ReadMessageEnd()
+	result := NodeBootstrappedResult{}
+	var retval *NodeBootstrappedResult_
+	var err2 error
+	if retval, err2 = p.handler.Bootstrapped(); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing bootstrapped: "+err2.Error())
+			oprot.WriteMessageBegin("bootstrapped", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	} else {
+		result.Success = retval
+	}
+	if err2 = oprot.WriteMessageBegin("bootstrapped", thrift.REPLY, seqId); err2 != nil {
+		err = err2
+	}
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
+		return
+	}
+	return true, err
 }
 
-type nodeProcessorQuery struct {
+type nodeProcessorGetPersistRateLimit struct {
 	handler Node
 }
 
-func (p *nodeProcessorQuery) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeQueryArgs{}
+func (p *nodeProcessorGetPersistRateLimit) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeGetPersistRateLimitArgs{}
 	if err = args.Read(iprot); err != nil {
 		iprot.ReadMessageEnd()
 		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("query", thrift.EXCEPTION, seqId)
+		oprot.WriteMessageBegin("getPersistRateLimit", thrift.EXCEPTION, seqId)
 		x.Write(oprot)
 		oprot.WriteMessageEnd()
 		oprot.Flush()
@@ -12530,16 +16618,16 @@ func (p *nodeProcessorQuery) Process(seqId int32, iprot, oprot thrift.TProtocol)
 	}
 
 	iprot.ReadMessageEnd()
-	result := NodeQueryResult{}
-	var retval *QueryResult_
+	result := NodeGetPersistRateLimitResult{}
+	var retval *NodePersistRateLimitResult_
 	var err2 error
-	if retval, err2 = p.handler.Query(args.Req); err2 != nil {
+	if retval, err2 = p.handler.GetPersistRateLimit(); err2 != nil {
 		switch v := err2.(type) {
 		case *Error:
 			result.Err = v
 		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing query: "+err2.Error())
-			oprot.WriteMessageBegin("query", thrift.EXCEPTION, seqId)
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing getPersistRateLimit: "+err2.Error())
+			oprot.WriteMessageBegin("getPersistRateLimit", thrift.EXCEPTION, seqId)
 			x.Write(oprot)
 			oprot.WriteMessageEnd()
 			oprot.Flush()
@@ -12548,7 +16636,7 @@ func (p *nodeProcessorQuery) Process(seqId int32, iprot, oprot thrift.TProtocol)
 	} else {
 		result.Success = retval
 	}
-	if err2 = oprot.WriteMessageBegin("query", thrift.REPLY, seqId); err2 != nil {
+	if err2 = oprot.WriteMessageBegin("getPersistRateLimit", thrift.REPLY, seqId); err2 != nil {
 		err = err2
 	}
 	if err2 = result.Write(oprot); err == nil && err2 != nil {
@@ -12566,16 +16654,16 @@ func (p *nodeProcessorQuery) Process(seqId int32, iprot, oprot thrift.TProtocol)
 	return true, err
 }
 
-type nodeProcessorFetch struct {
+type nodeProcessorSetPersistRateLimit struct {
 	handler Node
 }
 
-func (p *nodeProcessorFetch) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeFetchArgs{}
+func (p *nodeProcessorSetPersistRateLimit) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeSetPersistRateLimitArgs{}
 	if err = args.Read(iprot); err != nil {
 		iprot.ReadMessageEnd()
 		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("fetch", thrift.EXCEPTION, seqId)
+		oprot.WriteMessageBegin("setPersistRateLimit", thrift.EXCEPTION, seqId)
 		x.Write(oprot)
 		oprot.WriteMessageEnd()
 		oprot.Flush()
@@ -12583,16 +16671,16 @@ func (p *nodeProcessorFetch) Process(seqId int32, iprot, oprot thrift.TProtocol)
 	}
 
 	iprot.ReadMessageEnd()
-	result := NodeFetchResult{}
-	var retval *FetchResult_
+	result := NodeSetPersistRateLimitResult{}
+	var retval *NodePersistRateLimitResult_
 	var err2 error
-	if retval, err2 = p.handler.Fetch(args.Req); err2 != nil {
+	if retval, err2 = p.handler.SetPersistRateLimit(args.Req); err2 != nil {
 		switch v := err2.(type) {
 		case *Error:
 			result.Err = v
 		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing fetch: "+err2.Error())
-			oprot.WriteMessageBegin("fetch", thrift.EXCEPTION, seqId)
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing setPersistRateLimit: "+err2.Error())
+			oprot.WriteMessageBegin("setPersistRateLimit", thrift.EXCEPTION, seqId)
 			x.Write(oprot)
 			oprot.WriteMessageEnd()
 			oprot.Flush()
@@ -12601,7 +16689,7 @@ func (p *nodeProcessorFetch) Process(seqId int32, iprot, oprot thrift.TProtocol)
 	} else {
 		result.Success = retval
 	}
-	if err2 = oprot.WriteMessageBegin("fetch", thrift.REPLY, seqId); err2 != nil {
+	if err2 = oprot.WriteMessageBegin("setPersistRateLimit", thrift.REPLY, seqId); err2 != nil {
 		err = err2
 	}
 	if err2 = result.Write(oprot); err == nil && err2 != nil {
@@ -12619,16 +16707,16 @@ func (p *nodeProcessorFetch) Process(seqId int32, iprot, oprot thrift.TProtocol)
 	return true, err
 }
 
-type nodeProcessorFetchTagged struct {
+type nodeProcessorGetWriteNewSeriesAsync struct {
 	handler Node
 }
 
-func (p *nodeProcessorFetchTagged) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeFetchTaggedArgs{}
+func (p *nodeProcessorGetWriteNewSeriesAsync) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeGetWriteNewSeriesAsyncArgs{}
 	if err = args.Read(iprot); err != nil {
 		iprot.ReadMessageEnd()
 		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("fetchTagged", thrift.EXCEPTION, seqId)
+		oprot.WriteMessageBegin("getWriteNewSeriesAsync", thrift.EXCEPTION, seqId)
 		x.Write(oprot)
 		oprot.WriteMessageEnd()
 		oprot.Flush()
@@ -12636,16 +16724,16 @@ func (p *nodeProcessorFetchTagged) Process(seqId int32, iprot, oprot thrift.TPro
 	}
 
 	iprot.ReadMessageEnd()
-	result := NodeFetchTaggedResult{}
-	var retval *FetchTaggedResult_
+	result := NodeGetWriteNewSeriesAsyncResult{}
+	var retval *NodeWriteNewSeriesAsyncResult_
 	var err2 error
-	if retval, err2 = p.handler.FetchTagged(args.Req); err2 != nil {
+	if retval, err2 = p.handler.GetWriteNewSeriesAsync(); err2 != nil {
 		switch v := err2.(type) {
 		case *Error:
 			result.Err = v
 		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing fetchTagged: "+err2.Error())
-			oprot.WriteMessageBegin("fetchTagged", thrift.EXCEPTION, seqId)
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing getWriteNewSeriesAsync: "+err2.Error())
+			oprot.WriteMessageBegin("getWriteNewSeriesAsync", thrift.EXCEPTION, seqId)
 			x.Write(oprot)
 			oprot.WriteMessageEnd()
 			oprot.Flush()
@@ -12654,7 +16742,7 @@ func (p *nodeProcessorFetchTagged) Process(seqId int32, iprot, oprot thrift.TPro
 	} else {
 		result.Success = retval
 	}
-	if err2 = oprot.WriteMessageBegin("fetchTagged", thrift.REPLY, seqId); err2 != nil {
+	if err2 = oprot.WriteMessageBegin("getWriteNewSeriesAsync", thrift.REPLY, seqId); err2 != nil {
 		err = err2
 	}
 	if err2 = result.Write(oprot); err == nil && err2 != nil {
@@ -12672,16 +16760,16 @@ func (p *nodeProcessorFetchTagged) Process(seqId int32, iprot, oprot thrift.TPro
 	return true, err
 }
 
-type nodeProcessorWrite struct {
+type nodeProcessorSetWriteNewSeriesAsync struct {
 	handler Node
 }
 
-func (p *nodeProcessorWrite) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeWriteArgs{}
+func (p *nodeProcessorSetWriteNewSeriesAsync) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeSetWriteNewSeriesAsyncArgs{}
 	if err = args.Read(iprot); err != nil {
 		iprot.ReadMessageEnd()
 		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("write", thrift.EXCEPTION, seqId)
+		oprot.WriteMessageBegin("setWriteNewSeriesAsync", thrift.EXCEPTION, seqId)
 		x.Write(oprot)
 		oprot.WriteMessageEnd()
 		oprot.Flush()
@@ -12689,22 +16777,25 @@ func (p *nodeProcessorWrite) Process(seqId int32, iprot, oprot thrift.TProtocol)
 	}
 
 	iprot.ReadMessageEnd()
-	result := NodeWriteResult{}
+	result := NodeSetWriteNewSeriesAsyncResult{}
+	var retval *NodeWriteNewSeriesAsyncResult_
 	var err2 error
-	if err2 = p.handler.Write(args.Req); err2 != nil {
+	if retval, err2 = p.handler.SetWriteNewSeriesAsync(args.Req); err2 != nil {
 		switch v := err2.(type) {
 		case *Error:
 			result.Err = v
 		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing write: "+err2.Error())
-			oprot.WriteMessageBegin("write", thrift.EXCEPTION, seqId)
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing setWriteNewSeriesAsync: "+err2.Error())
+			oprot.WriteMessageBegin("setWriteNewSeriesAsync", thrift.EXCEPTION, seqId)
 			x.Write(oprot)
 			oprot.WriteMessageEnd()
 			oprot.Flush()
 			return true, err2
 		}
+	} else {
+		result.Success = retval
 	}
-	if err2 = oprot.WriteMessageBegin("write", thrift.REPLY, seqId); err2 != nil {
+	if err2 = oprot.WriteMessageBegin("setWriteNewSeriesAsync", thrift.REPLY, seqId); err2 != nil {
 		err = err2
 	}
 	if err2 = result.Write(oprot); err == nil && err2 != nil {
@@ -12722,16 +16813,16 @@ func (p *nodeProcessorWrite) Process(seqId int32, iprot, oprot thrift.TProtocol)
 	return true, err
 }
 
-type nodeProcessorWriteTagged struct {
+type nodeProcessorGetWriteNewSeriesBackoffDuration struct {
 	handler Node
 }
 
-func (p *nodeProcessorWriteTagged) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeWriteTaggedArgs{}
+func (p *nodeProcessorGetWriteNewSeriesBackoffDuration) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeGetWriteNewSeriesBackoffDurationArgs{}
 	if err = args.Read(iprot); err != nil {
 		iprot.ReadMessageEnd()
 		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("writeTagged", thrift.EXCEPTION, seqId)
+		oprot.WriteMessageBegin("getWriteNewSeriesBackoffDuration", thrift.EXCEPTION, seqId)
 		x.Write(oprot)
 		oprot.WriteMessageEnd()
 		oprot.Flush()
@@ -12739,22 +16830,25 @@ func (p *nodeProcessorWriteTagged) Process(seqId int32, iprot, oprot thrift.TPro
 	}
 
 	iprot.ReadMessageEnd()
-	result := NodeWriteTaggedResult{}
+	result := NodeGetWriteNewSeriesBackoffDurationResult{}
+	var retval *NodeWriteNewSeriesBackoffDurationResult_
 	var err2 error
-	if err2 = p.handler.WriteTagged(args.Req); err2 != nil {
+	if retval, err2 = p.handler.GetWriteNewSeriesBackoffDuration(); err2 != nil {
 		switch v := err2.(type) {
 		case *Error:
 			result.Err = v
 		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing writeTagged: "+err2.Error())
-			oprot.WriteMessageBegin("writeTagged", thrift.EXCEPTION, seqId)
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing getWriteNewSeriesBackoffDuration: "+err2.Error())
+			oprot.WriteMessageBegin("getWriteNewSeriesBackoffDuration", thrift.EXCEPTION, seqId)
 			x.Write(oprot)
 			oprot.WriteMessageEnd()
 			oprot.Flush()
 			return true, err2
 		}
+	} else {
+		result.Success = retval
 	}
-	if err2 = oprot.WriteMessageBegin("writeTagged", thrift.REPLY, seqId); err2 != nil {
+	if err2 = oprot.WriteMessageBegin("getWriteNewSeriesBackoffDuration", thrift.REPLY, seqId); err2 != nil {
 		err = err2
 	}
 	if err2 = result.Write(oprot); err == nil && err2 != nil {
@@ -12772,16 +16866,16 @@ func (p *nodeProcessorWriteTagged) Process(seqId int32, iprot, oprot thrift.TPro
 	return true, err
 }
 
-type nodeProcessorFetchBatchRaw struct {
+type nodeProcessorSetWriteNewSeriesBackoffDuration struct {
 	handler Node
 }
 
-func (p *nodeProcessorFetchBatchRaw) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeFetchBatchRawArgs{}
+func (p *nodeProcessorSetWriteNewSeriesBackoffDuration) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeSetWriteNewSeriesBackoffDurationArgs{}
 	if err = args.Read(iprot); err != nil {
 		iprot.ReadMessageEnd()
 		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("fetchBatchRaw", thrift.EXCEPTION, seqId)
+		oprot.WriteMessageBegin("setWriteNewSeriesBackoffDuration", thrift.EXCEPTION, seqId)
 		x.Write(oprot)
 		oprot.WriteMessageEnd()
 		oprot.Flush()
@@ -12789,16 +16883,16 @@ func (p *nodeProcessorFetchBatchRaw) Process(seqId int32, iprot, oprot thrift.TP
 	}
 
 	iprot.ReadMessageEnd()
-	result := NodeFetchBatchRawResult{}
-	var retval *FetchBatchRawResult_
+	result := NodeSetWriteNewSeriesBackoffDurationResult{}
+	var retval *NodeWriteNewSeriesBackoffDurationResult_
 	var err2 error
-	if retval, err2 = p.handler.FetchBatchRaw(args.Req); err2 != nil {
+	if retval, err2 = p.handler.SetWriteNewSeriesBackoffDuration(args.Req); err2 != nil {
 		switch v := err2.(type) {
 		case *Error:
 			result.Err = v
 		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing fetchBatchRaw: "+err2.Error())
-			oprot.WriteMessageBegin("fetchBatchRaw", thrift.EXCEPTION, seqId)
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing setWriteNewSeriesBackoffDuration: "+err2.Error())
+			oprot.WriteMessageBegin("setWriteNewSeriesBackoffDuration", thrift.EXCEPTION, seqId)
 			x.Write(oprot)
 			oprot.WriteMessageEnd()
 			oprot.Flush()
@@ -12807,7 +16901,7 @@ func (p *nodeProcessorFetchBatchRaw) Process(seqId int32, iprot, oprot thrift.TP
 	} else {
 		result.Success = retval
 	}
-	if err2 = oprot.WriteMessageBegin("fetchBatchRaw", thrift.REPLY, seqId); err2 != nil {
+	if err2 = oprot.WriteMessageBegin("setWriteNewSeriesBackoffDuration", thrift.REPLY, seqId); err2 != nil {
 		err = err2
 	}
 	if err2 = result.Write(oprot); err == nil && err2 != nil {
@@ -12825,16 +16919,16 @@ func (p *nodeProcessorFetchBatchRaw) Process(seqId int32, iprot, oprot thrift.TP
 	return true, err
 }
 
-type nodeProcessorFetchBlocksRaw struct {
+type nodeProcessorGetWriteNewSeriesLimitPerShardPerSecond struct {
 	handler Node
 }
 
-func (p *nodeProcessorFetchBlocksRaw) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeFetchBlocksRawArgs{}
+func (p *nodeProcessorGetWriteNewSeriesLimitPerShardPerSecond) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeGetWriteNewSeriesLimitPerShardPerSecondArgs{}
 	if err = args.Read(iprot); err != nil {
 		iprot.ReadMessageEnd()
 		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("fetchBlocksRaw", thrift.EXCEPTION, seqId)
+		oprot.WriteMessageBegin("getWriteNewSeriesLimitPerShardPerSecond", thrift.EXCEPTION, seqId)
 		x.Write(oprot)
 		oprot.WriteMessageEnd()
 		oprot.Flush()
@@ -12842,16 +16936,16 @@ func (p *nodeProcessorFetchBlocksRaw) Process(seqId int32, iprot, oprot thrift.T
 	}
 
 	iprot.ReadMessageEnd()
-	result := NodeFetchBlocksRawResult{}
-	var retval *FetchBlocksRawResult_
+	result := NodeGetWriteNewSeriesLimitPerShardPerSecondResult{}
+	var retval *NodeWriteNewSeriesLimitPerShardPerSecondResult_
 	var err2 error
-	if retval, err2 = p.handler.FetchBlocksRaw(args.Req); err2 != nil {
+	if retval, err2 = p.handler.GetWriteNewSeriesLimitPerShardPerSecond(); err2 != nil {
 		switch v := err2.(type) {
 		case *Error:
 			result.Err = v
 		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing fetchBlocksRaw: "+err2.Error())
-			oprot.WriteMessageBegin("fetchBlocksRaw", thrift.EXCEPTION, seqId)
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing getWriteNewSeriesLimitPerShardPerSecond: "+err2.Error())
+			oprot.WriteMessageBegin("getWriteNewSeriesLimitPerShardPerSecond", thrift.EXCEPTION, seqId)
 			x.Write(oprot)
 			oprot.WriteMessageEnd()
 			oprot.Flush()
@@ -12860,7 +16954,7 @@ func (p *nodeProcessorFetchBlocksRaw) Process(seqId int32, iprot, oprot thrift.T
 	} else {
 		result.Success = retval
 	}
-	if err2 = oprot.WriteMessageBegin("fetchBlocksRaw", thrift.REPLY, seqId); err2 != nil {
+	if err2 = oprot.WriteMessageBegin("getWriteNewSeriesLimitPerShardPerSecond", thrift.REPLY, seqId); err2 != nil {
 		err = err2
 	}
 	if err2 = result.Write(oprot); err == nil && err2 != nil {
@@ -12878,16 +16972,16 @@ func (p *nodeProcessorFetchBlocksRaw) Process(seqId int32, iprot, oprot thrift.T
 	return true, err
 }
 
-type nodeProcessorFetchBlocksMetadataRaw struct {
+type nodeProcessorSetWriteNewSeriesLimitPerShardPerSecond struct {
 	handler Node
 }
 
-func (p *nodeProcessorFetchBlocksMetadataRaw) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeFetchBlocksMetadataRawArgs{}
+func (p *nodeProcessorSetWriteNewSeriesLimitPerShardPerSecond) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeSetWriteNewSeriesLimitPerShardPerSecondArgs{}
 	if err = args.Read(iprot); err != nil {
 		iprot.ReadMessageEnd()
 		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("fetchBlocksMetadataRaw", thrift.EXCEPTION, seqId)
+		oprot.WriteMessageBegin("setWriteNewSeriesLimitPerShardPerSecond", thrift.EXCEPTION, seqId)
 		x.Write(oprot)
 		oprot.WriteMessageEnd()
 		oprot.Flush()
@@ -12895,853 +16989,830 @@ func (p *nodeProcessorFetchBlocksMetadataRaw) Process(seqId int32, iprot, oprot
 	}
 
 	iprot.ReadMessageEnd()
-	result := NodeFetchBlocksMetadataRawResult{}
-	var retval *FetchBlocksMetadataRawResult_
+	result := NodeSetWriteNewSeriesLimitPerShardPerSecondResult{}
+	var retval *NodeWriteNewSeriesLimitPerShardPerSecondResult_
 	var err2 error
-	if retval, err2 = p.handler.FetchBlocksMetadataRaw(args.Req); err2 != nil {
+	if retval, err2 = p.handler.SetWriteNewSeriesLimitPerShardPerSecond(args.Req); err2 != nil {
 		switch v := err2.(type) {
 		case *Error:
 			result.Err = v
 		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing fetchBlocksMetadataRaw: "+err2.Error())
-			oprot.WriteMessageBegin("fetchBlocksMetadataRaw", thrift.EXCEPTION, seqId)
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing setWriteNewSeriesLimitPerShardPerSecond: "+err2.Error())
+			oprot.WriteMessageBegin("setWriteNewSeriesLimitPerShardPerSecond", thrift.EXCEPTION, seqId)
 			x.Write(oprot)
 			oprot.WriteMessageEnd()
 			oprot.Flush()
 			return true, err2
 		}
-	} else {
-		result.Success = retval
+	} else {
+		result.Success = retval
+	}
+	if err2 = oprot.WriteMessageBegin("setWriteNewSeriesLimitPerShardPerSecond", thrift.REPLY, seqId); err2 != nil {
+		err = err2
+	}
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
+		return
+	}
+	return true, err
+}
+
+// HELPER FUNCTIONS AND STRUCTURES
+
+// Attributes:
+//  - Req
+type NodeQueryArgs struct {
+	Req *QueryRequest `thrift:"req,1" db:"req" json:"req"`
+}
+
+func NewNodeQueryArgs() *NodeQueryArgs {
+	return &NodeQueryArgs{}
+}
+
+var NodeQueryArgs_Req_DEFAULT *QueryRequest
+
+func (p *NodeQueryArgs) GetReq() *QueryRequest {
+	if !p.IsSetReq() {
+		return NodeQueryArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+func (p *NodeQueryArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *NodeQueryArgs) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	return nil
+}
+
+func (p *NodeQueryArgs) ReadField1(iprot thrift.TProtocol) error {
+	p.Req = &QueryRequest{
+		RangeType: 0,
+
+		ResultTimeType: 0,
+	}
+	if err := p.Req.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Req), err)
+	}
+	return nil
+}
+
+func (p *NodeQueryArgs) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("query_args"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
 	}
-	if err2 = oprot.WriteMessageBegin("fetchBlocksMetadataRaw", thrift.REPLY, seqId); err2 != nil {
-		err = err2
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
 	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
 	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
+	return nil
+}
+
+func (p *NodeQueryArgs) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("req", thrift.STRUCT, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:req: ", p), err)
 	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
+	if err := p.Req.Write(oprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Req), err)
 	}
-	if err != nil {
-		return
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:req: ", p), err)
 	}
-	return true, err
+	return err
 }
 
-type nodeProcessorFetchBlocksMetadataRawV2 struct {
-	handler Node
+func (p *NodeQueryArgs) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("NodeQueryArgs(%+v)", *p)
 }
 
-func (p *nodeProcessorFetchBlocksMetadataRawV2) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeFetchBlocksMetadataRawV2Args{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("fetchBlocksMetadataRawV2", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
-		oprot.Flush()
-		return false, err
-	}
+// Attributes:
+//  - Success
+//  - Err
+type NodeQueryResult struct {
+	Success *QueryResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
+	Err     *Error        `thrift:"err,1" db:"err" json:"err,omitempty"`
+}
 
-	iprot.ReadMessageEnd()
-	result := NodeFetchBlocksMetadataRawV2Result{}
-	var retval *FetchBlocksMetadataRawV2Result_
-	var err2 error
-	if retval, err2 = p.handler.FetchBlocksMetadataRawV2(args.Req); err2 != nil {
-		switch v := err2.(type) {
-		case *Error:
-			result.Err = v
-		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing fetchBlocksMetadataRawV2: "+err2.Error())
-			oprot.WriteMessageBegin("fetchBlocksMetadataRawV2", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
-			oprot.Flush()
-			return true, err2
-		}
-	} else {
-		result.Success = retval
-	}
-	if err2 = oprot.WriteMessageBegin("fetchBlocksMetadataRawV2", thrift.REPLY, seqId); err2 != nil {
-		err = err2
-	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
-	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
-	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
+func NewNodeQueryResult() *NodeQueryResult {
+	return &NodeQueryResult{}
+}
+
+var NodeQueryResult_Success_DEFAULT *QueryResult_
+
+func (p *NodeQueryResult) GetSuccess() *QueryResult_ {
+	if !p.IsSetSuccess() {
+		return NodeQueryResult_Success_DEFAULT
 	}
-	if err != nil {
-		return
+	return p.Success
+}
+
+var NodeQueryResult_Err_DEFAULT *Error
+
+func (p *NodeQueryResult) GetErr() *Error {
+	if !p.IsSetErr() {
+		return NodeQueryResult_Err_DEFAULT
 	}
-	return true, err
+	return p.Err
+}
+func (p *NodeQueryResult) IsSetSuccess() bool {
+	return p.Success != nil
 }
 
-type nodeProcessorWriteBatchRaw struct {
-	handler Node
+func (p *NodeQueryResult) IsSetErr() bool {
+	return p.Err != nil
 }
 
-func (p *nodeProcessorWriteBatchRaw) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeWriteBatchRawArgs{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("writeBatchRaw", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
-		oprot.Flush()
-		return false, err
+func (p *NodeQueryResult) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	iprot.ReadMessageEnd()
-	result := NodeWriteBatchRawResult{}
-	var err2 error
-	if err2 = p.handler.WriteBatchRaw(args.Req); err2 != nil {
-		switch v := err2.(type) {
-		case *WriteBatchRawErrors:
-			result.Err = v
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 0:
+			if err := p.ReadField0(iprot); err != nil {
+				return err
+			}
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
 		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing writeBatchRaw: "+err2.Error())
-			oprot.WriteMessageBegin("writeBatchRaw", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
-			oprot.Flush()
-			return true, err2
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
 		}
 	}
-	if err2 = oprot.WriteMessageBegin("writeBatchRaw", thrift.REPLY, seqId); err2 != nil {
-		err = err2
-	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
+	return nil
+}
+
+func (p *NodeQueryResult) ReadField0(iprot thrift.TProtocol) error {
+	p.Success = &QueryResult_{}
+	if err := p.Success.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
 	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
+	return nil
+}
+
+func (p *NodeQueryResult) ReadField1(iprot thrift.TProtocol) error {
+	p.Err = &Error{
+		Type: 0,
 	}
-	if err != nil {
-		return
+	if err := p.Err.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Err), err)
 	}
-	return true, err
+	return nil
 }
 
-type nodeProcessorWriteTaggedBatchRaw struct {
-	handler Node
+func (p *NodeQueryResult) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("query_result"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField0(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
 }
 
-func (p *nodeProcessorWriteTaggedBatchRaw) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeWriteTaggedBatchRawArgs{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("writeTaggedBatchRaw", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
-		oprot.Flush()
-		return false, err
+func (p *NodeQueryResult) writeField0(oprot thrift.TProtocol) (err error) {
+	if p.IsSetSuccess() {
+		if err := oprot.WriteFieldBegin("success", thrift.STRUCT, 0); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err)
+		}
+		if err := p.Success.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Success), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 0:success: ", p), err)
+		}
 	}
+	return err
+}
 
-	iprot.ReadMessageEnd()
-	result := NodeWriteTaggedBatchRawResult{}
-	var err2 error
-	if err2 = p.handler.WriteTaggedBatchRaw(args.Req); err2 != nil {
-		switch v := err2.(type) {
-		case *WriteBatchRawErrors:
-			result.Err = v
-		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing writeTaggedBatchRaw: "+err2.Error())
-			oprot.WriteMessageBegin("writeTaggedBatchRaw", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
-			oprot.Flush()
-			return true, err2
+func (p *NodeQueryResult) writeField1(oprot thrift.TProtocol) (err error) {
+	if p.IsSetErr() {
+		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
+		}
+		if err := p.Err.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Err), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 1:err: ", p), err)
 		}
 	}
-	if err2 = oprot.WriteMessageBegin("writeTaggedBatchRaw", thrift.REPLY, seqId); err2 != nil {
-		err = err2
-	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
-	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
-	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
-	}
-	if err != nil {
-		return
+	return err
+}
+
+func (p *NodeQueryResult) String() string {
+	if p == nil {
+		return "<nil>"
 	}
-	return true, err
+	return fmt.Sprintf("NodeQueryResult(%+v)", *p)
 }
 
-type nodeProcessorRepair struct {
-	handler Node
+// Attributes:
+//  - Req
+type NodeFetchArgs struct {
+	Req *FetchRequest `thrift:"req,1" db:"req" json:"req"`
 }
 
-func (p *nodeProcessorRepair) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeRepairArgs{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("repair", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
-		oprot.Flush()
-		return false, err
+func NewNodeFetchArgs() *NodeFetchArgs {
+	return &NodeFetchArgs{}
+}
+
+var NodeFetchArgs_Req_DEFAULT *FetchRequest
+
+func (p *NodeFetchArgs) GetReq() *FetchRequest {
+	if !p.IsSetReq() {
+		return NodeFetchArgs_Req_DEFAULT
 	}
+	return p.Req
+}
+func (p *NodeFetchArgs) IsSetReq() bool {
+	return p.Req != nil
+}
 
-	iprot.ReadMessageEnd()
-	result := NodeRepairResult{}
-	var err2 error
-	if err2 = p.handler.Repair(); err2 != nil {
-		switch v := err2.(type) {
-		case *Error:
-			result.Err = v
+func (p *NodeFetchArgs) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
 		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing repair: "+err2.Error())
-			oprot.WriteMessageBegin("repair", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
-			oprot.Flush()
-			return true, err2
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
 		}
 	}
-	if err2 = oprot.WriteMessageBegin("repair", thrift.REPLY, seqId); err2 != nil {
-		err = err2
-	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
-	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
-	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
-	}
-	if err != nil {
-		return
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	return true, err
+	return nil
 }
 
-type nodeProcessorTruncate struct {
-	handler Node
-}
+func (p *NodeFetchArgs) ReadField1(iprot thrift.TProtocol) error {
+	p.Req = &FetchRequest{
+		RangeType: 0,
 
-func (p *nodeProcessorTruncate) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeTruncateArgs{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("truncate", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
-		oprot.Flush()
-		return false, err
+		ResultTimeType: 0,
+	}
+	if err := p.Req.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Req), err)
 	}
+	return nil
+}
 
-	iprot.ReadMessageEnd()
-	result := NodeTruncateResult{}
-	var retval *TruncateResult_
-	var err2 error
-	if retval, err2 = p.handler.Truncate(args.Req); err2 != nil {
-		switch v := err2.(type) {
-		case *Error:
-			result.Err = v
-		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing truncate: "+err2.Error())
-			oprot.WriteMessageBegin("truncate", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
-			oprot.Flush()
-			return true, err2
+func (p *NodeFetchArgs) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("fetch_args"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
 		}
-	} else {
-		result.Success = retval
 	}
-	if err2 = oprot.WriteMessageBegin("truncate", thrift.REPLY, seqId); err2 != nil {
-		err = err2
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
 	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
 	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
+	return nil
+}
+
+func (p *NodeFetchArgs) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("req", thrift.STRUCT, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:req: ", p), err)
 	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
+	if err := p.Req.Write(oprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Req), err)
 	}
-	if err != nil {
-		return
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:req: ", p), err)
 	}
-	return true, err
+	return err
 }
 
-type nodeProcessorHealth struct {
-	handler Node
+func (p *NodeFetchArgs) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("NodeFetchArgs(%+v)", *p)
 }
 
-func (p *nodeProcessorHealth) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeHealthArgs{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("health", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
-		oprot.Flush()
-		return false, err
-	}
+// Attributes:
+//  - Success
+//  - Err
+type NodeFetchResult struct {
+	Success *FetchResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
+	Err     *Error        `thrift:"err,1" db:"err" json:"err,omitempty"`
+}
 
-	iprot.ReadMessageEnd()
-	result := NodeHealthResult{}
-	var retval *NodeHealthResult_
-	var err2 error
-	if retval, err2 = p.handler.Health(); err2 != nil {
-		switch v := err2.(type) {
-		case *Error:
-			result.Err = v
-		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing health: "+err2.Error())
-			oprot.WriteMessageBegin("health", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
-			oprot.Flush()
-			return true, err2
-		}
-	} else {
-		result.Success = retval
-	}
-	if err2 = oprot.WriteMessageBegin("health", thrift.REPLY, seqId); err2 != nil {
-		err = err2
-	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
-	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
-	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
+func NewNodeFetchResult() *NodeFetchResult {
+	return &NodeFetchResult{}
+}
+
+var NodeFetchResult_Success_DEFAULT *FetchResult_
+
+func (p *NodeFetchResult) GetSuccess() *FetchResult_ {
+	if !p.IsSetSuccess() {
+		return NodeFetchResult_Success_DEFAULT
 	}
-	if err != nil {
-		return
+	return p.Success
+}
+
+var NodeFetchResult_Err_DEFAULT *Error
+
+func (p *NodeFetchResult) GetErr() *Error {
+	if !p.IsSetErr() {
+		return NodeFetchResult_Err_DEFAULT
 	}
-	return true, err
+	return p.Err
+}
+func (p *NodeFetchResult) IsSetSuccess() bool {
+	return p.Success != nil
 }
 
-type nodeProcessorBootstrapped struct {
-	handler Node
+func (p *NodeFetchResult) IsSetErr() bool {
+	return p.Err != nil
 }
 
-func (p *nodeProcessorBootstrapped) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeBootstrappedArgs{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("bootstrapped", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
-		oprot.Flush()
-		return false, err
+func (p *NodeFetchResult) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	iprot.ReadMessageEnd()
-	result := NodeBootstrappedResult{}
-	var retval *NodeBootstrappedResult_
-	var err2 error
-	if retval, err2 = p.handler.Bootstrapped(); err2 != nil {
-		switch v := err2.(type) {
-		case *Error:
-			result.Err = v
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 0:
+			if err := p.ReadField0(iprot); err != nil {
+				return err
+			}
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
 		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing bootstrapped: "+err2.Error())
-			oprot.WriteMessageBegin("bootstrapped", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
-			oprot.Flush()
-			return true, err2
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
 		}
-	} else {
-		result.Success = retval
-	}
-	if err2 = oprot.WriteMessageBegin("bootstrapped", thrift.REPLY, seqId); err2 != nil {
-		err = err2
-	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
-	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
-	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
 	}
-	if err != nil {
-		return
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	return true, err
+	return nil
 }
 
-type nodeProcessorGetPersistRateLimit struct {
-	handler Node
+func (p *NodeFetchResult) ReadField0(iprot thrift.TProtocol) error {
+	p.Success = &FetchResult_{}
+	if err := p.Success.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
+	}
+	return nil
 }
 
-func (p *nodeProcessorGetPersistRateLimit) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeGetPersistRateLimitArgs{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("getPersistRateLimit", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
-		oprot.Flush()
-		return false, err
+func (p *NodeFetchResult) ReadField1(iprot thrift.TProtocol) error {
+	p.Err = &Error{
+		Type: 0,
+	}
+	if err := p.Err.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Err), err)
 	}
+	return nil
+}
 
-	iprot.ReadMessageEnd()
-	result := NodeGetPersistRateLimitResult{}
-	var retval *NodePersistRateLimitResult_
-	var err2 error
-	if retval, err2 = p.handler.GetPersistRateLimit(); err2 != nil {
-		switch v := err2.(type) {
-		case *Error:
-			result.Err = v
-		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing getPersistRateLimit: "+err2.Error())
-			oprot.WriteMessageBegin("getPersistRateLimit", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
-			oprot.Flush()
-			return true, err2
+func (p *NodeFetchResult) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("fetch_result"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField0(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField1(oprot); err != nil {
+			return err
 		}
-	} else {
-		result.Success = retval
 	}
-	if err2 = oprot.WriteMessageBegin("getPersistRateLimit", thrift.REPLY, seqId); err2 != nil {
-		err = err2
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
 	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
 	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
+	return nil
+}
+
+func (p *NodeFetchResult) writeField0(oprot thrift.TProtocol) (err error) {
+	if p.IsSetSuccess() {
+		if err := oprot.WriteFieldBegin("success", thrift.STRUCT, 0); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err)
+		}
+		if err := p.Success.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Success), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 0:success: ", p), err)
+		}
 	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
+	return err
+}
+
+func (p *NodeFetchResult) writeField1(oprot thrift.TProtocol) (err error) {
+	if p.IsSetErr() {
+		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
+		}
+		if err := p.Err.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Err), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 1:err: ", p), err)
+		}
 	}
-	if err != nil {
-		return
+	return err
+}
+
+func (p *NodeFetchResult) String() string {
+	if p == nil {
+		return "<nil>"
 	}
-	return true, err
+	return fmt.Sprintf("NodeFetchResult(%+v)", *p)
 }
 
-type nodeProcessorSetPersistRateLimit struct {
-	handler Node
+// Attributes:
+//  - Req
+type NodeFetchTaggedArgs struct {
+	Req *FetchTaggedRequest `thrift:"req,1" db:"req" json:"req"`
 }
 
-func (p *nodeProcessorSetPersistRateLimit) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeSetPersistRateLimitArgs{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("setPersistRateLimit", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
-		oprot.Flush()
-		return false, err
-	}
+func NewNodeFetchTaggedArgs() *NodeFetchTaggedArgs {
+	return &NodeFetchTaggedArgs{}
+}
 
-	iprot.ReadMessageEnd()
-	result := NodeSetPersistRateLimitResult{}
-	var retval *NodePersistRateLimitResult_
-	var err2 error
-	if retval, err2 = p.handler.SetPersistRateLimit(args.Req); err2 != nil {
-		switch v := err2.(type) {
-		case *Error:
-			result.Err = v
-		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing setPersistRateLimit: "+err2.Error())
-			oprot.WriteMessageBegin("setPersistRateLimit", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
-			oprot.Flush()
-			return true, err2
-		}
-	} else {
-		result.Success = retval
-	}
-	if err2 = oprot.WriteMessageBegin("setPersistRateLimit", thrift.REPLY, seqId); err2 != nil {
-		err = err2
-	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
-	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
-	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
-	}
-	if err != nil {
-		return
+var NodeFetchTaggedArgs_Req_DEFAULT *FetchTaggedRequest
+
+func (p *NodeFetchTaggedArgs) GetReq() *FetchTaggedRequest {
+	if !p.IsSetReq() {
+		return NodeFetchTaggedArgs_Req_DEFAULT
 	}
-	return true, err
+	return p.Req
 }
-
-type nodeProcessorGetWriteNewSeriesAsync struct {
-	handler Node
+func (p *NodeFetchTaggedArgs) IsSetReq() bool {
+	return p.Req != nil
 }
 
-func (p *nodeProcessorGetWriteNewSeriesAsync) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeGetWriteNewSeriesAsyncArgs{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("getWriteNewSeriesAsync", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
-		oprot.Flush()
-		return false, err
+func (p *NodeFetchTaggedArgs) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	iprot.ReadMessageEnd()
-	result := NodeGetWriteNewSeriesAsyncResult{}
-	var retval *NodeWriteNewSeriesAsyncResult_
-	var err2 error
-	if retval, err2 = p.handler.GetWriteNewSeriesAsync(); err2 != nil {
-		switch v := err2.(type) {
-		case *Error:
-			result.Err = v
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
 		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing getWriteNewSeriesAsync: "+err2.Error())
-			oprot.WriteMessageBegin("getWriteNewSeriesAsync", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
-			oprot.Flush()
-			return true, err2
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
 		}
-	} else {
-		result.Success = retval
-	}
-	if err2 = oprot.WriteMessageBegin("getWriteNewSeriesAsync", thrift.REPLY, seqId); err2 != nil {
-		err = err2
-	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
-	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
-	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
 	}
-	if err != nil {
-		return
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	return true, err
+	return nil
 }
 
-type nodeProcessorSetWriteNewSeriesAsync struct {
-	handler Node
+func (p *NodeFetchTaggedArgs) ReadField1(iprot thrift.TProtocol) error {
+	p.Req = &FetchTaggedRequest{
+		RangeTimeType: 0,
+	}
+	if err := p.Req.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Req), err)
+	}
+	return nil
 }
 
-func (p *nodeProcessorSetWriteNewSeriesAsync) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeSetWriteNewSeriesAsyncArgs{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("setWriteNewSeriesAsync", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
-		oprot.Flush()
-		return false, err
+func (p *NodeFetchTaggedArgs) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("fetchTagged_args"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
-
-	iprot.ReadMessageEnd()
-	result := NodeSetWriteNewSeriesAsyncResult{}
-	var retval *NodeWriteNewSeriesAsyncResult_
-	var err2 error
-	if retval, err2 = p.handler.SetWriteNewSeriesAsync(args.Req); err2 != nil {
-		switch v := err2.(type) {
-		case *Error:
-			result.Err = v
-		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing setWriteNewSeriesAsync: "+err2.Error())
-			oprot.WriteMessageBegin("setWriteNewSeriesAsync", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
-			oprot.Flush()
-			return true, err2
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
 		}
-	} else {
-		result.Success = retval
 	}
-	if err2 = oprot.WriteMessageBegin("setWriteNewSeriesAsync", thrift.REPLY, seqId); err2 != nil {
-		err = err2
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
 	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
 	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
+	return nil
+}
+
+func (p *NodeFetchTaggedArgs) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("req", thrift.STRUCT, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:req: ", p), err)
 	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
+	if err := p.Req.Write(oprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Req), err)
 	}
-	if err != nil {
-		return
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:req: ", p), err)
 	}
-	return true, err
+	return err
 }
 
-type nodeProcessorGetWriteNewSeriesBackoffDuration struct {
-	handler Node
+func (p *NodeFetchTaggedArgs) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("NodeFetchTaggedArgs(%+v)", *p)
 }
 
-func (p *nodeProcessorGetWriteNewSeriesBackoffDuration) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeGetWriteNewSeriesBackoffDurationArgs{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("getWriteNewSeriesBackoffDuration", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
-		oprot.Flush()
-		return false, err
-	}
+// Attributes:
+//  - Success
+//  - Err
+type NodeFetchTaggedResult struct {
+	Success *FetchTaggedResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
+	Err     *Error              `thrift:"err,1" db:"err" json:"err,omitempty"`
+}
 
-	iprot.ReadMessageEnd()
-	result := NodeGetWriteNewSeriesBackoffDurationResult{}
-	var retval *NodeWriteNewSeriesBackoffDurationResult_
-	var err2 error
-	if retval, err2 = p.handler.GetWriteNewSeriesBackoffDuration(); err2 != nil {
-		switch v := err2.(type) {
-		case *Error:
-			result.Err = v
-		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing getWriteNewSeriesBackoffDuration: "+err2.Error())
-			oprot.WriteMessageBegin("getWriteNewSeriesBackoffDuration", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
-			oprot.Flush()
-			return true, err2
-		}
-	} else {
-		result.Success = retval
-	}
-	if err2 = oprot.WriteMessageBegin("getWriteNewSeriesBackoffDuration", thrift.REPLY, seqId); err2 != nil {
-		err = err2
-	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
-	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
-	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
+func NewNodeFetchTaggedResult() *NodeFetchTaggedResult {
+	return &NodeFetchTaggedResult{}
+}
+
+var NodeFetchTaggedResult_Success_DEFAULT *FetchTaggedResult_
+
+func (p *NodeFetchTaggedResult) GetSuccess() *FetchTaggedResult_ {
+	if !p.IsSetSuccess() {
+		return NodeFetchTaggedResult_Success_DEFAULT
 	}
-	if err != nil {
-		return
+	return p.Success
+}
+
+var NodeFetchTaggedResult_Err_DEFAULT *Error
+
+func (p *NodeFetchTaggedResult) GetErr() *Error {
+	if !p.IsSetErr() {
+		return NodeFetchTaggedResult_Err_DEFAULT
 	}
-	return true, err
+	return p.Err
+}
+func (p *NodeFetchTaggedResult) IsSetSuccess() bool {
+	return p.Success != nil
 }
 
-type nodeProcessorSetWriteNewSeriesBackoffDuration struct {
-	handler Node
+func (p *NodeFetchTaggedResult) IsSetErr() bool {
+	return p.Err != nil
 }
 
-func (p *nodeProcessorSetWriteNewSeriesBackoffDuration) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeSetWriteNewSeriesBackoffDurationArgs{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("setWriteNewSeriesBackoffDuration", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
-		oprot.Flush()
-		return false, err
+func (p *NodeFetchTaggedResult) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	iprot.ReadMessageEnd()
-	result := NodeSetWriteNewSeriesBackoffDurationResult{}
-	var retval *NodeWriteNewSeriesBackoffDurationResult_
-	var err2 error
-	if retval, err2 = p.handler.SetWriteNewSeriesBackoffDuration(args.Req); err2 != nil {
-		switch v := err2.(type) {
-		case *Error:
-			result.Err = v
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 0:
+			if err := p.ReadField0(iprot); err != nil {
+				return err
+			}
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
 		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing setWriteNewSeriesBackoffDuration: "+err2.Error())
-			oprot.WriteMessageBegin("setWriteNewSeriesBackoffDuration", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
-			oprot.Flush()
-			return true, err2
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
 		}
-	} else {
-		result.Success = retval
-	}
-	if err2 = oprot.WriteMessageBegin("setWriteNewSeriesBackoffDuration", thrift.REPLY, seqId); err2 != nil {
-		err = err2
-	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
-	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
-	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
 	}
-	if err != nil {
-		return
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	return true, err
-}
-
-type nodeProcessorGetWriteNewSeriesLimitPerShardPerSecond struct {
-	handler Node
+	return nil
 }
 
-func (p *nodeProcessorGetWriteNewSeriesLimitPerShardPerSecond) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeGetWriteNewSeriesLimitPerShardPerSecondArgs{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("getWriteNewSeriesLimitPerShardPerSecond", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
-		oprot.Flush()
-		return false, err
+func (p *NodeFetchTaggedResult) ReadField0(iprot thrift.TProtocol) error {
+	p.Success = &FetchTaggedResult_{}
+	if err := p.Success.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
 	}
+	return nil
+}
 
-	iprot.ReadMessageEnd()
-	result := NodeGetWriteNewSeriesLimitPerShardPerSecondResult{}
-	var retval *NodeWriteNewSeriesLimitPerShardPerSecondResult_
-	var err2 error
-	if retval, err2 = p.handler.GetWriteNewSeriesLimitPerShardPerSecond(); err2 != nil {
-		switch v := err2.(type) {
-		case *Error:
-			result.Err = v
-		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing getWriteNewSeriesLimitPerShardPerSecond: "+err2.Error())
-			oprot.WriteMessageBegin("getWriteNewSeriesLimitPerShardPerSecond", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
-			oprot.Flush()
-			return true, err2
-		}
-	} else {
-		result.Success = retval
+func (p *NodeFetchTaggedResult) ReadField1(iprot thrift.TProtocol) error {
+	p.Err = &Error{
+		Type: 0,
 	}
-	if err2 = oprot.WriteMessageBegin("getWriteNewSeriesLimitPerShardPerSecond", thrift.REPLY, seqId); err2 != nil {
-		err = err2
+	if err := p.Err.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Err), err)
 	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
+	return nil
+}
+
+func (p *NodeFetchTaggedResult) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("fetchTagged_result"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
+	if p != nil {
+		if err := p.writeField0(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
 	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
 	}
-	if err != nil {
-		return
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
 	}
-	return true, err
-}
-
-type nodeProcessorSetWriteNewSeriesLimitPerShardPerSecond struct {
-	handler Node
+	return nil
 }
 
-func (p *nodeProcessorSetWriteNewSeriesLimitPerShardPerSecond) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeSetWriteNewSeriesLimitPerShardPerSecondArgs{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("setWriteNewSeriesLimitPerShardPerSecond", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
-		oprot.Flush()
-		return false, err
+func (p *NodeFetchTaggedResult) writeField0(oprot thrift.TProtocol) (err error) {
+	if p.IsSetSuccess() {
+		if err := oprot.WriteFieldBegin("success", thrift.STRUCT, 0); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err)
+		}
+		if err := p.Success.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Success), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 0:success: ", p), err)
+		}
 	}
+	return err
+}
 
-	iprot.ReadMessageEnd()
-	result := NodeSetWriteNewSeriesLimitPerShardPerSecondResult{}
-	var retval *NodeWriteNewSeriesLimitPerShardPerSecondResult_
-	var err2 error
-	if retval, err2 = p.handler.SetWriteNewSeriesLimitPerShardPerSecond(args.Req); err2 != nil {
-		switch v := err2.(type) {
-		case *Error:
-			result.Err = v
-		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing setWriteNewSeriesLimitPerShardPerSecond: "+err2.Error())
-			oprot.WriteMessageBegin("setWriteNewSeriesLimitPerShardPerSecond", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
-			oprot.Flush()
-			return true, err2
+func (p *NodeFetchTaggedResult) writeField1(oprot thrift.TProtocol) (err error) {
+	if p.IsSetErr() {
+		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
+		}
+		if err := p.Err.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Err), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 1:err: ", p), err)
 		}
-	} else {
-		result.Success = retval
-	}
-	if err2 = oprot.WriteMessageBegin("setWriteNewSeriesLimitPerShardPerSecond", thrift.REPLY, seqId); err2 != nil {
-		err = err2
-	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
-	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
-	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
-	}
-	if err != nil {
-		return
 	}
-	return true, err
+	return err
 }
 
-// HELPER FUNCTIONS AND STRUCTURES
+func (p *NodeFetchTaggedResult) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("NodeFetchTaggedResult(%+v)", *p)
+}
 
 // Attributes:
 //  - Req
-type NodeQueryArgs struct {
-	Req *QueryRequest `thrift:"req,1" db:"req" json:"req"`
+type NodeFetchTaggedPageArgs struct {
+	Req *FetchTaggedPageRequest `thrift:"req,1" db:"req" json:"req"`
 }
 
-func NewNodeQueryArgs() *NodeQueryArgs {
-	return &NodeQueryArgs{}
+func NewNodeFetchTaggedPageArgs() *NodeFetchTaggedPageArgs {
+	return &NodeFetchTaggedPageArgs{}
 }
 
-var NodeQueryArgs_Req_DEFAULT *QueryRequest
+var NodeFetchTaggedPageArgs_Req_DEFAULT *FetchTaggedPageRequest
 
-func (p *NodeQueryArgs) GetReq() *QueryRequest {
+func (p *NodeFetchTaggedPageArgs) GetReq() *FetchTaggedPageRequest {
 	if !p.IsSetReq() {
-		return NodeQueryArgs_Req_DEFAULT
+		return NodeFetchTaggedPageArgs_Req_DEFAULT
 	}
 	return p.Req
 }
-func (p *NodeQueryArgs) IsSetReq() bool {
+func (p *NodeFetchTaggedPageArgs) IsSetReq() bool {
 	return p.Req != nil
 }
 
-func (p *NodeQueryArgs) Read(iprot thrift.TProtocol) error {
+func (p *NodeFetchTaggedPageArgs) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -13774,20 +17845,16 @@ func (p *NodeQueryArgs) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeQueryArgs) ReadField1(iprot thrift.TProtocol) error {
-	p.Req = &QueryRequest{
-		RangeType: 0,
-
-		ResultTimeType: 0,
-	}
+func (p *NodeFetchTaggedPageArgs) ReadField1(iprot thrift.TProtocol) error {
+	p.Req = &FetchTaggedPageRequest{}
 	if err := p.Req.Read(iprot); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Req), err)
 	}
 	return nil
 }
 
-func (p *NodeQueryArgs) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("query_args"); err != nil {
+func (p *NodeFetchTaggedPageArgs) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("fetchTaggedPage_args"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -13804,7 +17871,7 @@ func (p *NodeQueryArgs) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeQueryArgs) writeField1(oprot thrift.TProtocol) (err error) {
+func (p *NodeFetchTaggedPageArgs) writeField1(oprot thrift.TProtocol) (err error) {
 	if err := oprot.WriteFieldBegin("req", thrift.STRUCT, 1); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:req: ", p), err)
 	}
@@ -13817,51 +17884,51 @@ func (p *NodeQueryArgs) writeField1(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
-func (p *NodeQueryArgs) String() string {
+func (p *NodeFetchTaggedPageArgs) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeQueryArgs(%+v)", *p)
+	return fmt.Sprintf("NodeFetchTaggedPageArgs(%+v)", *p)
 }
 
 // Attributes:
 //  - Success
 //  - Err
-type NodeQueryResult struct {
-	Success *QueryResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
-	Err     *Error        `thrift:"err,1" db:"err" json:"err,omitempty"`
+type NodeFetchTaggedPageResult struct {
+	Success *FetchTaggedPageResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
+	Err     *Error                     `thrift:"err,1" db:"err" json:"err,omitempty"`
 }
 
-func NewNodeQueryResult() *NodeQueryResult {
-	return &NodeQueryResult{}
+func NewNodeFetchTaggedPageResult() *NodeFetchTaggedPageResult {
+	return &NodeFetchTaggedPageResult{}
 }
 
-var NodeQueryResult_Success_DEFAULT *QueryResult_
+var NodeFetchTaggedPageResult_Success_DEFAULT *FetchTaggedPageResult_
 
-func (p *NodeQueryResult) GetSuccess() *QueryResult_ {
+func (p *NodeFetchTaggedPageResult) GetSuccess() *FetchTaggedPageResult_ {
 	if !p.IsSetSuccess() {
-		return NodeQueryResult_Success_DEFAULT
+		return NodeFetchTaggedPageResult_Success_DEFAULT
 	}
 	return p.Success
 }
 
-var NodeQueryResult_Err_DEFAULT *Error
+var NodeFetchTaggedPageResult_Err_DEFAULT *Error
 
-func (p *NodeQueryResult) GetErr() *Error {
+func (p *NodeFetchTaggedPageResult) GetErr() *Error {
 	if !p.IsSetErr() {
-		return NodeQueryResult_Err_DEFAULT
+		return NodeFetchTaggedPageResult_Err_DEFAULT
 	}
 	return p.Err
 }
-func (p *NodeQueryResult) IsSetSuccess() bool {
+func (p *NodeFetchTaggedPageResult) IsSetSuccess() bool {
 	return p.Success != nil
 }
 
-func (p *NodeQueryResult) IsSetErr() bool {
+func (p *NodeFetchTaggedPageResult) IsSetErr() bool {
 	return p.Err != nil
 }
 
-func (p *NodeQueryResult) Read(iprot thrift.TProtocol) error {
+func (p *NodeFetchTaggedPageResult) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -13898,15 +17965,15 @@ func (p *NodeQueryResult) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeQueryResult) ReadField0(iprot thrift.TProtocol) error {
-	p.Success = &QueryResult_{}
+func (p *NodeFetchTaggedPageResult) ReadField0(iprot thrift.TProtocol) error {
+	p.Success = &FetchTaggedPageResult_{}
 	if err := p.Success.Read(iprot); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
 	}
 	return nil
 }
 
-func (p *NodeQueryResult) ReadField1(iprot thrift.TProtocol) error {
+func (p *NodeFetchTaggedPageResult) ReadField1(iprot thrift.TProtocol) error {
 	p.Err = &Error{
 		Type: 0,
 	}
@@ -13916,8 +17983,8 @@ func (p *NodeQueryResult) ReadField1(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeQueryResult) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("query_result"); err != nil {
+func (p *NodeFetchTaggedPageResult) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("fetchTaggedPage_result"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -13937,7 +18004,7 @@ func (p *NodeQueryResult) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeQueryResult) writeField0(oprot thrift.TProtocol) (err error) {
+func (p *NodeFetchTaggedPageResult) writeField0(oprot thrift.TProtocol) (err error) {
 	if p.IsSetSuccess() {
 		if err := oprot.WriteFieldBegin("success", thrift.STRUCT, 0); err != nil {
 			return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err)
@@ -13952,7 +18019,7 @@ func (p *NodeQueryResult) writeField0(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
-func (p *NodeQueryResult) writeField1(oprot thrift.TProtocol) (err error) {
+func (p *NodeFetchTaggedPageResult) writeField1(oprot thrift.TProtocol) (err error) {
 	if p.IsSetErr() {
 		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
 			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
@@ -13967,36 +18034,36 @@ func (p *NodeQueryResult) writeField1(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
-func (p *NodeQueryResult) String() string {
+func (p *NodeFetchTaggedPageResult) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeQueryResult(%+v)", *p)
+	return fmt.Sprintf("NodeFetchTaggedPageResult(%+v)", *p)
 }
 
 // Attributes:
 //  - Req
-type NodeFetchArgs struct {
-	Req *FetchRequest `thrift:"req,1" db:"req" json:"req"`
+type NodeAggregateArgs struct {
+	Req *AggregateQueryRequest `thrift:"req,1" db:"req" json:"req"`
 }
 
-func NewNodeFetchArgs() *NodeFetchArgs {
-	return &NodeFetchArgs{}
+func NewNodeAggregateArgs() *NodeAggregateArgs {
+	return &NodeAggregateArgs{}
 }
 
-var NodeFetchArgs_Req_DEFAULT *FetchRequest
+var NodeAggregateArgs_Req_DEFAULT *AggregateQueryRequest
 
-func (p *NodeFetchArgs) GetReq() *FetchRequest {
+func (p *NodeAggregateArgs) GetReq() *AggregateQueryRequest {
 	if !p.IsSetReq() {
-		return NodeFetchArgs_Req_DEFAULT
+		return NodeAggregateArgs_Req_DEFAULT
 	}
 	return p.Req
 }
-func (p *NodeFetchArgs) IsSetReq() bool {
+func (p *NodeAggregateArgs) IsSetReq() bool {
 	return p.Req != nil
 }
 
-func (p *NodeFetchArgs) Read(iprot thrift.TProtocol) error {
+func (p *NodeAggregateArgs) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -14029,11 +18096,9 @@ func (p *NodeFetchArgs) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchArgs) ReadField1(iprot thrift.TProtocol) error {
-	p.Req = &FetchRequest{
-		RangeType: 0,
-
-		ResultTimeType: 0,
+func (p *NodeAggregateArgs) ReadField1(iprot thrift.TProtocol) error {
+	p.Req = &AggregateQueryRequest{
+		RangeTimeType: 0,
 	}
 	if err := p.Req.Read(iprot); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Req), err)
@@ -14041,8 +18106,8 @@ func (p *NodeFetchArgs) ReadField1(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchArgs) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("fetch_args"); err != nil {
+func (p *NodeAggregateArgs) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("aggregate_args"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -14059,7 +18124,7 @@ func (p *NodeFetchArgs) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchArgs) writeField1(oprot thrift.TProtocol) (err error) {
+func (p *NodeAggregateArgs) writeField1(oprot thrift.TProtocol) (err error) {
 	if err := oprot.WriteFieldBegin("req", thrift.STRUCT, 1); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:req: ", p), err)
 	}
@@ -14072,51 +18137,51 @@ func (p *NodeFetchArgs) writeField1(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
-func (p *NodeFetchArgs) String() string {
+func (p *NodeAggregateArgs) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeFetchArgs(%+v)", *p)
+	return fmt.Sprintf("NodeAggregateArgs(%+v)", *p)
 }
 
 // Attributes:
 //  - Success
 //  - Err
-type NodeFetchResult struct {
-	Success *FetchResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
-	Err     *Error        `thrift:"err,1" db:"err" json:"err,omitempty"`
+type NodeAggregateResult struct {
+	Success *AggregateQueryResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
+	Err     *Error              `thrift:"err,1" db:"err" json:"err,omitempty"`
 }
 
-func NewNodeFetchResult() *NodeFetchResult {
-	return &NodeFetchResult{}
+func NewNodeAggregateResult() *NodeAggregateResult {
+	return &NodeAggregateResult{}
 }
 
-var NodeFetchResult_Success_DEFAULT *FetchResult_
+var NodeAggregateQueryResult_Success_DEFAULT *AggregateQueryResult_
 
-func (p *NodeFetchResult) GetSuccess() *FetchResult_ {
+func (p *NodeAggregateResult) GetSuccess() *AggregateQueryResult_ {
 	if !p.IsSetSuccess() {
-		return NodeFetchResult_Success_DEFAULT
+		return NodeAggregateQueryResult_Success_DEFAULT
 	}
 	return p.Success
 }
 
-var NodeFetchResult_Err_DEFAULT *Error
+var NodeAggregateQueryResult_Err_DEFAULT *Error
 
-func (p *NodeFetchResult) GetErr() *Error {
+func (p *NodeAggregateResult) GetErr() *Error {
 	if !p.IsSetErr() {
-		return NodeFetchResult_Err_DEFAULT
+		return NodeAggregateQueryResult_Err_DEFAULT
 	}
 	return p.Err
 }
-func (p *NodeFetchResult) IsSetSuccess() bool {
+func (p *NodeAggregateResult) IsSetSuccess() bool {
 	return p.Success != nil
 }
 
-func (p *NodeFetchResult) IsSetErr() bool {
+func (p *NodeAggregateResult) IsSetErr() bool {
 	return p.Err != nil
 }
 
-func (p *NodeFetchResult) Read(iprot thrift.TProtocol) error {
+func (p *NodeAggregateResult) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -14153,15 +18218,15 @@ func (p *NodeFetchResult) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchResult) ReadField0(iprot thrift.TProtocol) error {
-	p.Success = &FetchResult_{}
+func (p *NodeAggregateResult) ReadField0(iprot thrift.TProtocol) error {
+	p.Success = &AggregateQueryResult_{}
 	if err := p.Success.Read(iprot); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
 	}
 	return nil
 }
 
-func (p *NodeFetchResult) ReadField1(iprot thrift.TProtocol) error {
+func (p *NodeAggregateResult) ReadField1(iprot thrift.TProtocol) error {
 	p.Err = &Error{
 		Type: 0,
 	}
@@ -14171,8 +18236,8 @@ func (p *NodeFetchResult) ReadField1(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchResult) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("fetch_result"); err != nil {
+func (p *NodeAggregateResult) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("aggregate_result"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -14192,7 +18257,7 @@ func (p *NodeFetchResult) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchResult) writeField0(oprot thrift.TProtocol) (err error) {
+func (p *NodeAggregateResult) writeField0(oprot thrift.TProtocol) (err error) {
 	if p.IsSetSuccess() {
 		if err := oprot.WriteFieldBegin("success", thrift.STRUCT, 0); err != nil {
 			return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err)
@@ -14207,7 +18272,7 @@ func (p *NodeFetchResult) writeField0(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
-func (p *NodeFetchResult) writeField1(oprot thrift.TProtocol) (err error) {
+func (p *NodeAggregateResult) writeField1(oprot thrift.TProtocol) (err error) {
 	if p.IsSetErr() {
 		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
 			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
@@ -14222,36 +18287,36 @@ func (p *NodeFetchResult) writeField1(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
-func (p *NodeFetchResult) String() string {
+func (p *NodeAggregateResult) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeFetchResult(%+v)", *p)
+	return fmt.Sprintf("NodeAggregateResult(%+v)", *p)
 }
 
 // Attributes:
 //  - Req
-type NodeFetchTaggedArgs struct {
-	Req *FetchTaggedRequest `thrift:"req,1" db:"req" json:"req"`
+type NodeAggregateTagValuesArgs struct {
+	Req *AggregateTagValuesRequest `thrift:"req,1" db:"req" json:"req"`
 }
 
-func NewNodeFetchTaggedArgs() *NodeFetchTaggedArgs {
-	return &NodeFetchTaggedArgs{}
+func NewNodeAggregateTagValuesArgs() *NodeAggregateTagValuesArgs {
+	return &NodeAggregateTagValuesArgs{}
 }
 
-var NodeFetchTaggedArgs_Req_DEFAULT *FetchTaggedRequest
+var NodeAggregateTagValuesArgs_Req_DEFAULT *AggregateTagValuesRequest
 
-func (p *NodeFetchTaggedArgs) GetReq() *FetchTaggedRequest {
+func (p *NodeAggregateTagValuesArgs) GetReq() *AggregateTagValuesRequest {
 	if !p.IsSetReq() {
-		return NodeFetchTaggedArgs_Req_DEFAULT
+		return NodeAggregateTagValuesArgs_Req_DEFAULT
 	}
 	return p.Req
 }
-func (p *NodeFetchTaggedArgs) IsSetReq() bool {
+func (p *NodeAggregateTagValuesArgs) IsSetReq() bool {
 	return p.Req != nil
 }
 
-func (p *NodeFetchTaggedArgs) Read(iprot thrift.TProtocol) error {
+func (p *NodeAggregateTagValuesArgs) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -14284,8 +18349,8 @@ func (p *NodeFetchTaggedArgs) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchTaggedArgs) ReadField1(iprot thrift.TProtocol) error {
-	p.Req = &FetchTaggedRequest{
+func (p *NodeAggregateTagValuesArgs) ReadField1(iprot thrift.TProtocol) error {
+	p.Req = &AggregateTagValuesRequest{
 		RangeTimeType: 0,
 	}
 	if err := p.Req.Read(iprot); err != nil {
@@ -14294,8 +18359,8 @@ func (p *NodeFetchTaggedArgs) ReadField1(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchTaggedArgs) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("fetchTagged_args"); err != nil {
+func (p *NodeAggregateTagValuesArgs) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("aggregateTagValues_args"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -14312,7 +18377,7 @@ func (p *NodeFetchTaggedArgs) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchTaggedArgs) writeField1(oprot thrift.TProtocol) (err error) {
+func (p *NodeAggregateTagValuesArgs) writeField1(oprot thrift.TProtocol) (err error) {
 	if err := oprot.WriteFieldBegin("req", thrift.STRUCT, 1); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:req: ", p), err)
 	}
@@ -14325,51 +18390,51 @@ func (p *NodeFetchTaggedArgs) writeField1(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
-func (p *NodeFetchTaggedArgs) String() string {
+func (p *NodeAggregateTagValuesArgs) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeFetchTaggedArgs(%+v)", *p)
+	return fmt.Sprintf("NodeAggregateTagValuesArgs(%+v)", *p)
 }
 
 // Attributes:
 //  - Success
 //  - Err
-type NodeFetchTaggedResult struct {
-	Success *FetchTaggedResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
-	Err     *Error              `thrift:"err,1" db:"err" json:"err,omitempty"`
+type NodeAggregateTagValuesResult struct {
+	Success *AggregateTagValuesResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
+	Err     *Error                     `thrift:"err,1" db:"err" json:"err,omitempty"`
 }
 
-func NewNodeFetchTaggedResult() *NodeFetchTaggedResult {
-	return &NodeFetchTaggedResult{}
+func NewNodeAggregateTagValuesResult() *NodeAggregateTagValuesResult {
+	return &NodeAggregateTagValuesResult{}
 }
 
-var NodeFetchTaggedResult_Success_DEFAULT *FetchTaggedResult_
+var NodeAggregateTagValuesResult_Success_DEFAULT *AggregateTagValuesResult_
 
-func (p *NodeFetchTaggedResult) GetSuccess() *FetchTaggedResult_ {
+func (p *NodeAggregateTagValuesResult) GetSuccess() *AggregateTagValuesResult_ {
 	if !p.IsSetSuccess() {
-		return NodeFetchTaggedResult_Success_DEFAULT
+		return NodeAggregateTagValuesResult_Success_DEFAULT
 	}
 	return p.Success
 }
 
-var NodeFetchTaggedResult_Err_DEFAULT *Error
+var NodeAggregateTagValuesResult_Err_DEFAULT *Error
 
-func (p *NodeFetchTaggedResult) GetErr() *Error {
+func (p *NodeAggregateTagValuesResult) GetErr() *Error {
 	if !p.IsSetErr() {
-		return NodeFetchTaggedResult_Err_DEFAULT
+		return NodeAggregateTagValuesResult_Err_DEFAULT
 	}
 	return p.Err
 }
-func (p *NodeFetchTaggedResult) IsSetSuccess() bool {
+func (p *NodeAggregateTagValuesResult) IsSetSuccess() bool {
 	return p.Success != nil
 }
 
-func (p *NodeFetchTaggedResult) IsSetErr() bool {
+func (p *NodeAggregateTagValuesResult) IsSetErr() bool {
 	return p.Err != nil
 }
 
-func (p *NodeFetchTaggedResult) Read(iprot thrift.TProtocol) error {
+func (p *NodeAggregateTagValuesResult) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -14406,15 +18471,15 @@ func (p *NodeFetchTaggedResult) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchTaggedResult) ReadField0(iprot thrift.TProtocol) error {
-	p.Success = &FetchTaggedResult_{}
+func (p *NodeAggregateTagValuesResult) ReadField0(iprot thrift.TProtocol) error {
+	p.Success = &AggregateTagValuesResult_{}
 	if err := p.Success.Read(iprot); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
 	}
 	return nil
 }
 
-func (p *NodeFetchTaggedResult) ReadField1(iprot thrift.TProtocol) error {
+func (p *NodeAggregateTagValuesResult) ReadField1(iprot thrift.TProtocol) error {
 	p.Err = &Error{
 		Type: 0,
 	}
@@ -14424,8 +18489,8 @@ func (p *NodeFetchTaggedResult) ReadField1(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchTaggedResult) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("fetchTagged_result"); err != nil {
+func (p *NodeAggregateTagValuesResult) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("aggregateTagValues_result"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -14445,7 +18510,7 @@ func (p *NodeFetchTaggedResult) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchTaggedResult) writeField0(oprot thrift.TProtocol) (err error) {
+func (p *NodeAggregateTagValuesResult) writeField0(oprot thrift.TProtocol) (err error) {
 	if p.IsSetSuccess() {
 		if err := oprot.WriteFieldBegin("success", thrift.STRUCT, 0); err != nil {
 			return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err)
@@ -14460,7 +18525,7 @@ func (p *NodeFetchTaggedResult) writeField0(oprot thrift.TProtocol) (err error)
 	return err
 }
 
-func (p *NodeFetchTaggedResult) writeField1(oprot thrift.TProtocol) (err error) {
+func (p *NodeAggregateTagValuesResult) writeField1(oprot thrift.TProtocol) (err error) {
 	if p.IsSetErr() {
 		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
 			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
@@ -14475,11 +18540,11 @@ func (p *NodeFetchTaggedResult) writeField1(oprot thrift.TProtocol) (err error)
 	return err
 }
 
-func (p *NodeFetchTaggedResult) String() string {
+func (p *NodeAggregateTagValuesResult) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeFetchTaggedResult(%+v)", *p)
+	return fmt.Sprintf("NodeAggregateTagValuesResult(%+v)", *p)
 }
 
 // Attributes:
@@ -16719,6 +20784,257 @@ func (p *NodeTruncateResult) String() string {
 	return fmt.Sprintf("NodeTruncateResult(%+v)", *p)
 }
 
+// Attributes:
+//  - Req
+type NodeForceFlushArgs struct {
+	Req *ForceFlushRequest `thrift:"req,1" db:"req" json:"req"`
+}
+
+func NewNodeForceFlushArgs() *NodeForceFlushArgs {
+	return &NodeForceFlushArgs{}
+}
+
+var NodeForceFlushArgs_Req_DEFAULT *ForceFlushRequest
+
+func (p *NodeForceFlushArgs) GetReq() *ForceFlushRequest {
+	if !p.IsSetReq() {
+		return NodeForceFlushArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+func (p *NodeForceFlushArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *NodeForceFlushArgs) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	return nil
+}
+
+func (p *NodeForceFlushArgs) ReadField1(iprot thrift.TProtocol) error {
+	p.Req = &ForceFlushRequest{}
+	if err := p.Req.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Req), err)
+	}
+	return nil
+}
+
+func (p *NodeForceFlushArgs) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("forceFlush_args"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *NodeForceFlushArgs) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("req", thrift.STRUCT, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:req: ", p), err)
+	}
+	if err := p.Req.Write(oprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Req), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:req: ", p), err)
+	}
+	return err
+}
+
+func (p *NodeForceFlushArgs) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("NodeForceFlushArgs(%+v)", *p)
+}
+
+// Attributes:
+//  - Success
+//  - Err
+type NodeForceFlushResult struct {
+	Success *ForceFlushResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
+	Err     *Error           `thrift:"err,1" db:"err" json:"err,omitempty"`
+}
+
+func NewNodeForceFlushResult() *NodeForceFlushResult {
+	return &NodeForceFlushResult{}
+}
+
+var NodeForceFlushResult_Success_DEFAULT *ForceFlushResult_
+
+func (p *NodeForceFlushResult) GetSuccess() *ForceFlushResult_ {
+	if !p.IsSetSuccess() {
+		return NodeForceFlushResult_Success_DEFAULT
+	}
+	return p.Success
+}
+
+var NodeForceFlushResult_Err_DEFAULT *Error
+
+func (p *NodeForceFlushResult) GetErr() *Error {
+	if !p.IsSetErr() {
+		return NodeForceFlushResult_Err_DEFAULT
+	}
+	return p.Err
+}
+func (p *NodeForceFlushResult) IsSetSuccess() bool {
+	return p.Success != nil
+}
+
+func (p *NodeForceFlushResult) IsSetErr() bool {
+	return p.Err != nil
+}
+
+func (p *NodeForceFlushResult) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 0:
+			if err := p.ReadField0(iprot); err != nil {
+				return err
+			}
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	return nil
+}
+
+func (p *NodeForceFlushResult) ReadField0(iprot thrift.TProtocol) error {
+	p.Success = &ForceFlushResult_{}
+	if err := p.Success.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
+	}
+	return nil
+}
+
+func (p *NodeForceFlushResult) ReadField1(iprot thrift.TProtocol) error {
+	p.Err = &Error{
+		Type: 0,
+	}
+	if err := p.Err.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Err), err)
+	}
+	return nil
+}
+
+func (p *NodeForceFlushResult) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("forceFlush_result"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField0(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *NodeForceFlushResult) writeField0(oprot thrift.TProtocol) (err error) {
+	if p.IsSetSuccess() {
+		if err := oprot.WriteFieldBegin("success", thrift.STRUCT, 0); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err)
+		}
+		if err := p.Success.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Success), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 0:success: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *NodeForceFlushResult) writeField1(oprot thrift.TProtocol) (err error) {
+	if p.IsSetErr() {
+		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
+		}
+		if err := p.Err.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Err), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 1:err: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *NodeForceFlushResult) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("NodeForceFlushResult(%+v)", *p)
+}
+
 type NodeHealthArgs struct {
 }
 
@@ -45,6 +45,8 @@ type TChanCluster interface {
 
 // TChanNode is the interface that defines the server handler and client interface.
 type TChanNode interface {
+	Aggregate(ctx thrift.Context, req *AggregateQueryRequest) (*AggregateQueryResult_, error)
+	AggregateTagValues(ctx thrift.Context, req *AggregateTagValuesRequest) (*AggregateTagValuesResult_, error)
 	Bootstrapped(ctx thrift.Context) (*NodeBootstrappedResult_, error)
 	Fetch(ctx thrift.Context, req *FetchRequest) (*FetchResult_, error)
 	FetchBatchRaw(ctx thrift.Context, req *FetchBatchRawRequest) (*FetchBatchRawResult_, error)
@@ -52,6 +54,8 @@ type TChanNode interface {
 	FetchBlocksMetadataRawV2(ctx thrift.Context, req *FetchBlocksMetadataRawV2Request) (*FetchBlocksMetadataRawV2Result_, error)
 	FetchBlocksRaw(ctx thrift.Context, req *FetchBlocksRawRequest) (*FetchBlocksRawResult_, error)
 	FetchTagged(ctx thrift.Context, req *FetchTaggedRequest) (*FetchTaggedResult_, error)
+	FetchTaggedPage(ctx thrift.Context, req *FetchTaggedPageRequest) (*FetchTaggedPageResult_, error)
+	ForceFlush(ctx thrift.Context, req *ForceFlushRequest) (*ForceFlushResult_, error)
 	GetPersistRateLimit(ctx thrift.Context) (*NodePersistRateLimitResult_, error)
 	GetWriteNewSeriesAsync(ctx thrift.Context) (*NodeWriteNewSeriesAsyncResult_, error)
 	GetWriteNewSeriesBackoffDuration(ctx thrift.Context) (*NodeWriteNewSeriesBackoffDurationResult_, error)
@@ -474,6 +478,42 @@ func NewTChanNodeClient(client thrift.TChanClient) TChanNode {
 	return NewTChanNodeInheritedClient("Node", client)
 }
 
+func (c *tchanNodeClient) Aggregate(ctx thrift.Context, req *AggregateQueryRequest) (*AggregateQueryResult_, error) {
+	var resp NodeAggregateResult
+	args := NodeAggregateArgs{
+		Req: req,
+	}
+	success, err := c.client.Call(ctx, c.thriftService, "aggregate", &args, &resp)
+	if err == nil && !success {
+		switch {
+		case resp.Err != nil:
+			err = resp.Err
+		default:
+			err = fmt.Errorf("received no result or unknown exception for aggregate")
+		}
+	}
+
+	return resp.GetSuccess(), err
+}
+
+func (c *tchanNodeClient) AggregateTagValues(ctx thrift.Context, req *AggregateTagValuesRequest) (*AggregateTagValuesResult_, error) {
+	var resp NodeAggregateTagValuesResult
+	args := NodeAggregateTagValuesArgs{
+		Req: req,
+	}
+	success, err := c.client.Call(ctx, c.thriftService, "aggregateTagValues", &args, &resp)
+	if err == nil && !success {
+		switch {
+		case resp.Err != nil:
+			err = resp.Err
+		default:
+			err = fmt.Errorf("received no result or unknown exception for aggregateTagValues")
+		}
+	}
+
+	return resp.GetSuccess(), err
+}
+
 func (c *tchanNodeClient) Bootstrapped(ctx thrift.Context) (*NodeBootstrappedResult_, error) {
 	var resp NodeBootstrappedResult
 	args := NodeBootstrappedArgs{}
@@ -598,6 +638,42 @@ func (c *tchanNodeClient) FetchTagged(ctx thrift.Context, req *FetchTaggedReques
 	return resp.GetSuccess(), err
 }
 
+func (c *tchanNodeClient) FetchTaggedPage(ctx thrift.Context, req *FetchTaggedPageRequest) (*FetchTaggedPageResult_, error) {
+	var resp NodeFetchTaggedPageResult
+	args := NodeFetchTaggedPageArgs{
+		Req: req,
+	}
+	success, err := c.client.Call(ctx, c.thriftService, "fetchTaggedPage", &args, &resp)
+	if err == nil && !success {
+		switch {
+		case resp.Err != nil:
+			err = resp.Err
+		default:
+			err = fmt.Errorf("received no result or unknown exception for fetchTaggedPage")
+		}
+	}
+
+	return resp.GetSuccess(), err
+}
+
+func (c *tchanNodeClient) ForceFlush(ctx thrift.Context, req *ForceFlushRequest) (*ForceFlushResult_, error) {
+	var resp NodeForceFlushResult
+	args := NodeForceFlushArgs{
+		Req: req,
+	}
+	success, err := c.client.Call(ctx, c.thriftService, "forceFlush", &args, &resp)
+	if err == nil && !success {
+		switch {
+		case resp.Err != nil:
+			err = resp.Err
+		default:
+			err = fmt.Errorf("received no result or unknown exception for forceFlush")
+		}
+	}
+
+	return resp.GetSuccess(), err
+}
+
 func (c *tchanNodeClient) GetPersistRateLimit(ctx thrift.Context) (*NodePersistRateLimitResult_, error) {
 	var resp NodeGetPersistRateLimitResult
 	args := NodeGetPersistRateLimitArgs{}
@@ -892,6 +968,8 @@ func (s *tchanNodeServer) Service() string {
 
 func (s *tchanNodeServer) Methods() []string {
 	return []string{
+		"aggregate",
+		"aggregateTagValues",
 		"bootstrapped",
 		"fetch",
 		"fetchBatchRaw",
@@ -899,6 +977,8 @@ func (s *tchanNodeServer) Methods() []string {
 		"fetchBlocksMetadataRawV2",
 		"fetchBlocksRaw",
 		"fetchTagged",
+		"fetchTaggedPage",
+		"forceFlush",
 		"getPersistRateLimit",
 		"getWriteNewSeriesAsync",
 		"getWriteNewSeriesBackoffDuration",
@@ -920,6 +1000,10 @@ func (s *tchanNodeServer) Methods() []string {
 
 func (s *tchanNodeServer) Handle(ctx thrift.Context, methodName string, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	switch methodName {
+	case "aggregate":
+		return s.handleAggregate(ctx, protocol)
+	case "aggregateTagValues":
+		return s.handleAggregateTagValues(ctx, protocol)
 	case "bootstrapped":
 		return s.handleBootstrapped(ctx, protocol)
 	case "fetch":
@@ -934,6 +1018,10 @@ func (s *tchanNodeServer) Handle(ctx thrift.Context, methodName string, protocol
 		return s.handleFetchBlocksRaw(ctx, protocol)
 	case "fetchTagged":
 		return s.handleFetchTagged(ctx, protocol)
+	case "fetchTaggedPage":
+		return s.handleFetchTaggedPage(ctx, protocol)
+	case "forceFlush":
+		return s.handleForceFlush(ctx, protocol)
 	case "getPersistRateLimit":
 		return s.handleGetPersistRateLimit(ctx, protocol)
 	case "getWriteNewSeriesAsync":
@@ -972,6 +1060,62 @@ func (s *tchanNodeServer) Handle(ctx thrift.Context, methodName string, protocol
 	}
 }
 
+func (s *tchanNodeServer) handleAggregate(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
+	var req NodeAggregateArgs
+	var res NodeAggregateResult
+
+	if err := req.Read(protocol); err != nil {
+		return false, nil, err
+	}
+
+	r, err :=
+		s.handler.Aggregate(ctx, req.Req)
+
+	if err != nil {
+		switch v := err.(type) {
+		case *Error:
+			if v == nil {
+				return false, nil, fmt.Errorf("Handler for err returned non-nil error type *Error but nil value")
+			}
+			res.Err = v
+		default:
+			return false, nil, err
+		}
+	} else {
+		res.Success = r
+	}
+
+	return err == nil, &res, nil
+}
+
+func (s *tchanNodeServer) handleAggregateTagValues(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
+	var req NodeAggregateTagValuesArgs
+	var res NodeAggregateTagValuesResult
+
+	if err := req.Read(protocol); err != nil {
+		return false, nil, err
+	}
+
+	r, err :=
+		s.handler.AggregateTagValues(ctx, req.Req)
+
+	if err != nil {
+		switch v := err.(type) {
+		case *Error:
+			if v == nil {
+				return false, nil, fmt.Errorf("Handler for err returned non-nil error type *Error but nil value")
+			}
+			res.Err = v
+		default:
+			return false, nil, err
+		}
+	} else {
+		res.Success = r
+	}
+
+	return err == nil, &res, nil
+}
+
 func (s *tchanNodeServer) handleBootstrapped(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	var req NodeBootstrappedArgs
 	var res NodeBootstrappedResult
@@ -1168,6 +1312,62 @@ func (s *tchanNodeServer) handleFetchTagged(ctx thrift.Context, protocol athrift
 	return err == nil, &res, nil
 }
 
+func (s *tchanNodeServer) handleFetchTaggedPage(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
+	var req NodeFetchTaggedPageArgs
+	var res NodeFetchTaggedPageResult
+
+	if err := req.Read(protocol); err != nil {
+		return false, nil, err
+	}
+
+	r, err :=
+		s.handler.FetchTaggedPage(ctx, req.Req)
+
+	if err != nil {
+		switch v := err.(type) {
+		case *Error:
+			if v == nil {
+				return false, nil, fmt.Errorf("Handler for err returned non-nil error type *Error but nil value")
+			}
+			res.Err = v
+		default:
+			return false, nil, err
+		}
+	} else {
+		res.Success = r
+	}
+
+	return err == nil, &res, nil
+}
+
+func (s *tchanNodeServer) handleForceFlush(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
+	var req NodeForceFlushArgs
+	var res NodeForceFlushResult
+
+	if err := req.Read(protocol); err != nil {
+		return false, nil, err
+	}
+
+	r, err :=
+		s.handler.ForceFlush(ctx, req.Req)
+
+	if err != nil {
+		switch v := err.(type) {
+		case *Error:
+			if v == nil {
+				return false, nil, fmt.Errorf("Handler for err returned non-nil error type *Error but nil value")
+			}
+			res.Err = v
+		default:
+			return false, nil, err
+		}
+	} else {
+		res.Success = r
+	}
+
+	return err == nil, &res, nil
+}
+
 func (s *tchanNodeServer) handleGetPersistRateLimit(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	var req NodeGetPersistRateLimitArgs
 	var res NodeGetPersistRateLimitResult
@@ -211,8 +211,8 @@ func m3dbClientFetchBlocksMetadata(
 		}
 
 		var metadatas []block.ReplicaMetadata
-		iter, err := session.FetchBlocksMetadataFromPeers(namespace,
-			shardID, start, end, consistencyLevel, result.NewOptions(), version)
+		iter, err := session.FetchBlocksMetadataFromPeers(namespace, shardID,
+			start, end, consistencyLevel, result.NewOptions(), version, false)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,181 @@
+// +build integration
+
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package integration
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/integration/generate"
+	"github.com/m3db/m3/src/dbnode/storage/namespace"
+	"github.com/m3db/m3cluster/client"
+	m3clusterkvmem "github.com/m3db/m3cluster/kv/mem"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDynamicNamespaceAddRemoveWhileWriting(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow() // Just skip if we're doing a short run
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// test options
+	testOpts := newTestOptions(t).
+		SetTickMinimumInterval(time.Second)
+	require.True(t, len(testOpts.Namespaces()) >= 2)
+	ns0 := testOpts.Namespaces()[0]
+	ns1 := testOpts.Namespaces()[1]
+
+	// in-memory kv
+	kvStore := m3clusterkvmem.NewStore()
+	csClient := client.NewMockClient(ctrl)
+	csClient.EXPECT().KV().Return(kvStore, nil).AnyTimes()
+
+	// namespace maps
+	protoKey := func(nses ...namespace.Metadata) proto.Message {
+		nsMap, err := namespace.NewMap(nses)
+		require.NoError(t, err)
+		return namespace.ToProto(nsMap)
+	}
+
+	// dynamic namespace registry options
+	dynamicOpts := namespace.NewDynamicOptions().
+		SetConfigServiceClient(csClient)
+	dynamicInit := namespace.NewDynamicInitializer(dynamicOpts)
+	testOpts = testOpts.SetNamespaceInitializer(dynamicInit)
+
+	// initialize value in kv with only the first namespace
+	_, err := kvStore.Set(dynamicOpts.NamespaceRegistryKey(), protoKey(ns0))
+	require.NoError(t, err)
+
+	// Test setup
+	testSetup, err := newTestSetup(t, testOpts, nil)
+	require.NoError(t, err)
+	defer testSetup.close()
+
+	testSetup.storageOpts = testSetup.storageOpts.
+		SetNamespaceRemovalFlushEnabled(true)
+
+	// Start the server
+	log := testSetup.storageOpts.InstrumentOptions().Logger()
+	require.NoError(t, testSetup.startServer())
+
+	// Stop the server
+	defer func() {
+		require.NoError(t, testSetup.stopServer())
+		log.Infof("server is now down")
+	}()
+
+	// keep writing to the existing namespace throughout the test
+	now := testSetup.getNowFn()
+	var (
+		wg          sync.WaitGroup
+		doneCh      = make(chan struct{})
+		writeErrsMu sync.Mutex
+		writeErrs   []error
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		testData := generate.Block(generate.BlockConfig{
+			IDs: []string{"foo", "bar"}, NumPoints: 10, Start: now,
+		})
+		for {
+			select {
+			case <-doneCh:
+				return
+			default:
+			}
+			if err := testSetup.writeBatch(ns0.ID(), testData); err != nil {
+				writeErrsMu.Lock()
+				writeErrs = append(writeErrs, err)
+				writeErrsMu.Unlock()
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	// add the second namespace to kv
+	_, err = kvStore.Set(dynamicOpts.NamespaceRegistryKey(), protoKey(ns0, ns1))
+	require.NoError(t, err)
+	log.Infof("new namespace added to kv")
+
+	// wait until the new namespace is registered and bootstrapped
+	nsBootstrapped := func() bool {
+		ns, ok := testSetup.db.Namespace(ns1.ID())
+		if !ok {
+			return false
+		}
+		for _, shard := range ns.Shards() {
+			if !shard.IsBootstrapped() {
+				return false
+			}
+		}
+		return true
+	}
+	require.True(t, waitUntil(nsBootstrapped, 5*time.Second))
+	log.Infof("new namespace propagated from KV to testSetup and bootstrapped")
+
+	// write to and read back from the new namespace
+	seriesMaps := map[xtime.UnixNano]generate.SeriesBlock{
+		xtime.ToUnixNano(now): generate.Block(generate.BlockConfig{
+			IDs: []string{"foo", "baz"}, NumPoints: 50, Start: now,
+		}),
+	}
+	for _, testData := range seriesMaps {
+		require.NoError(t, testSetup.writeBatch(ns1.ID(), testData))
+	}
+	verifySeriesMaps(t, testSetup, ns1.ID(), seriesMaps)
+	log.Infof("new namespace data is verified")
+
+	// remove the second namespace from kv
+	_, err = kvStore.Set(dynamicOpts.NamespaceRegistryKey(), protoKey(ns0))
+	require.NoError(t, err)
+	log.Infof("namespace removed from kv")
+
+	nsRemoved := func() bool {
+		_, ok := testSetup.db.Namespace(ns1.ID())
+		return !ok
+	}
+	require.True(t, waitUntil(nsRemoved, 5*time.Second))
+	log.Infof("namespace removal propagated from KV to testSetup")
+
+	// writes to the removed namespace are rejected
+	for _, testData := range seriesMaps {
+		require.Error(t, testSetup.writeBatch(ns1.ID(), testData))
+	}
+
+	close(doneCh)
+	wg.Wait()
+
+	writeErrsMu.Lock()
+	require.Empty(t, writeErrs)
+	writeErrsMu.Unlock()
+}
@@ -0,0 +1,153 @@
+// +build integration
+
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+	"github.com/m3db/m3/src/dbnode/integration/generate"
+	hjnode "github.com/m3db/m3/src/dbnode/network/server/httpjson/node"
+	"github.com/m3db/m3/src/dbnode/persist/fs"
+	"github.com/m3db/m3/src/dbnode/retention"
+	"github.com/m3db/m3/src/dbnode/storage/namespace"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryNamespaceExpiresByRetention(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow() // Just skip if we're doing a short run
+	}
+
+	var (
+		blockSize       = 2 * time.Minute
+		retentionPeriod = 10 * time.Minute
+		rOpts           = retention.NewOptions().
+				SetRetentionPeriod(retentionPeriod).
+				SetBlockSize(blockSize).
+				SetBufferPast(time.Minute).
+				SetBufferFuture(time.Minute)
+		nsOpts = namespace.NewOptions().
+			SetRetentionOptions(rOpts).
+			SetFlushEnabled(false).
+			SetWritesToCommitLog(false)
+	)
+	ns, err := namespace.NewMetadata(testNamespaces[0], nsOpts)
+	require.NoError(t, err)
+
+	// Test setup
+	testOpts := newTestOptions(t).
+		SetTickMinimumInterval(time.Second).
+		SetNamespaces([]namespace.Metadata{ns})
+	testSetup, err := newTestSetup(t, testOpts, nil)
+	require.NoError(t, err)
+	defer testSetup.close()
+
+	filePathPrefix := testSetup.storageOpts.CommitLogOptions().FilesystemOptions().FilePathPrefix()
+
+	// Start the server
+	log := testSetup.storageOpts.InstrumentOptions().Logger()
+	log.Debug("in memory namespace test")
+	require.NoError(t, testSetup.startServer())
+	log.Debug("server is now up")
+
+	// Stop the server
+	defer func() {
+		require.NoError(t, testSetup.stopServer())
+		log.Debug("server is now down")
+	}()
+
+	// Write test data
+	now := testSetup.getNowFn().Truncate(blockSize)
+	testSetup.setNowFn(now)
+	testData := generate.Block(generate.BlockConfig{
+		IDs: []string{"foo", "bar"}, NumPoints: 30, Start: now,
+	})
+	require.NoError(t, testSetup.writeBatch(testNamespaces[0], testData))
+	log.Debug("test data is now written")
+
+	fetchReq := rpc.NewFetchRequest()
+	fetchReq.ID = "foo"
+	fetchReq.NameSpace = testNamespaces[0].String()
+	fetchReq.RangeStart = xtime.ToNormalizedTime(now, time.Second)
+	fetchReq.RangeEnd = xtime.ToNormalizedTime(now.Add(blockSize), time.Second)
+	fetchReq.ResultTimeType = rpc.TimeType_UNIX_SECONDS
+
+	// Data remains readable throughout the retention window, well after
+	// the buffer has drained the block it was written to
+	for _, elapsed := range []time.Duration{0, blockSize * 2, retentionPeriod} {
+		testSetup.setNowFn(now.Add(elapsed))
+		testSetup.sleepFor10xTickMinimumInterval()
+
+		res, err := testSetup.fetch(fetchReq)
+		require.NoError(t, err)
+		require.Equal(t, 30, len(res), fmt.Sprintf("elapsed %v", elapsed))
+	}
+
+	// Once out of retention the data is expired by the tick
+	testSetup.setNowFn(now.Add(retentionPeriod + 2*blockSize))
+	testSetup.sleepFor10xTickMinimumInterval()
+
+	res, err := testSetup.fetch(fetchReq)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(res))
+
+	// No fileset or snapshot files were ever written for the namespace
+	for _, dir := range []string{
+		fs.NamespaceDataDirPath(filePathPrefix, testNamespaces[0]),
+		fs.NamespaceSnapshotsDirPath(filePathPrefix, testNamespaces[0]),
+	} {
+		files, err := ioutil.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, 0, len(files), dir)
+	}
+
+	// The namespace is marked in memory only in the debug output
+	resp, err := http.Get(fmt.Sprintf("http://%s%s",
+		testSetup.httpNodeAddr(), hjnode.DebugNamespacesPath))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var debug struct {
+		Namespaces []struct {
+			ID           string `json:"id"`
+			InMemoryOnly bool   `json:"inMemoryOnly"`
+		} `json:"namespaces"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&debug))
+	require.Equal(t, 1, len(debug.Namespaces))
+	require.Equal(t, testNamespaces[0].String(), debug.Namespaces[0].ID)
+	require.True(t, debug.Namespaces[0].InMemoryOnly)
+}
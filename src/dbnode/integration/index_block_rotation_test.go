@@ -114,7 +114,7 @@ func TestIndexBlockRotation(t *testing.T) {
 
 	// "shared":"shared", is a common tag across all written metrics
 	query := index.Query{
-		idx.NewTermQuery([]byte("shared"), []byte("shared"))}
+		idx.MustCreateTermQuery([]byte("shared"), []byte("shared"))}
 
 	// ensure all data is present
 	log.Infof("querying period0 results")
@@ -182,7 +182,7 @@ func newQuery(t *testing.T, tags ident.TagIterator) idx.Query {
 	filters := make([]idx.Query, 0, tags.Remaining())
 	for tags.Next() {
 		tag := tags.Current()
-		tq := idx.NewTermQuery(tag.Name.Bytes(), tag.Value.Bytes())
+		tq := idx.MustCreateTermQuery(tag.Name.Bytes(), tag.Value.Bytes())
 		filters = append(filters, tq)
 	}
 	return idx.NewConjunctionQuery(filters...)
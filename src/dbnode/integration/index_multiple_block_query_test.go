@@ -119,7 +119,7 @@ func TestIndexMultipleBlockQuery(t *testing.T) {
 
 	// "shared":"shared", is a common tag across all written metrics
 	query := index.Query{
-		idx.NewTermQuery([]byte("shared"), []byte("shared"))}
+		idx.MustCreateTermQuery([]byte("shared"), []byte("shared"))}
 
 	log.Infof("querying period0 results")
 	period0Results, _, err := session.FetchTagged(
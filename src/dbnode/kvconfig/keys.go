@@ -33,6 +33,12 @@ const (
 	// configuration specifying a hard limit for a cluster new series insertions.
 	ClusterNewSeriesInsertLimitKey = "m3db.node.cluster-new-series-insert-limit"
 
+	// ClusterNewSeriesInsertBacklogKey is the KV config key for the runtime
+	// configuration specifying how many cluster new series insertions exceeding
+	// the insert limit are queued and admitted at the limit rate rather than
+	// rejected.
+	ClusterNewSeriesInsertBacklogKey = "m3db.node.cluster-new-series-insert-backlog"
+
 	// ClientBootstrapConsistencyLevel is the KV config key for the runtime
 	// configuration specifying the client bootstrap consistency level
 	ClientBootstrapConsistencyLevel = "m3db.client.bootstrap-consistency-level"
@@ -44,4 +50,51 @@ const (
 	// ClientWriteConsistencyLevel is the KV config key for the runtime
 	// configuration specifying the client write consistency level
 	ClientWriteConsistencyLevel = "m3db.client.write-consistency-level"
+
+	// ClientWriteConsistencyLevelPrefix is the prefix of the KV config keys for
+	// the runtime configuration specifying the client write consistency level of
+	// a namespace, overriding the client write consistency level, the key for a
+	// namespace is the prefix followed by the namespace ID
+	ClientWriteConsistencyLevelPrefix = "m3db.client.write-consistency-level."
+
+	// TickMinimumInterval is the KV config key for the runtime configuration
+	// specifying the minimum interval between ticks as a duration string
+	TickMinimumInterval = "m3db.node.tick-minimum-interval"
+
+	// TickSeriesBatchSize is the KV config key for the runtime configuration
+	// specifying the number of series processed in each batch of a tick
+	TickSeriesBatchSize = "m3db.node.tick-series-batch-size"
+
+	// TickAdaptivePacingEnabled is the KV config key for the runtime
+	// configuration specifying whether ticks are paced adaptively by the
+	// observed write latency as a bool
+	TickAdaptivePacingEnabled = "m3db.node.tick-adaptive-pacing-enabled"
+
+	// RepairEnabled is the KV config key for the runtime configuration
+	// specifying whether repairs are enabled as a bool
+	RepairEnabled = "m3db.node.repair-enabled"
+
+	// NodeReadOnlyPrefix is the prefix of the KV config keys for the runtime
+	// configuration specifying whether a node is read only as a bool, the key
+	// for a node is the prefix followed by its host ID
+	NodeReadOnlyPrefix = "m3db.node.read-only."
+
+	// RepairThrottle is the KV config key for the runtime configuration
+	// specifying the repair throttle between shard repairs as a duration string
+	RepairThrottle = "m3db.node.repair-throttle"
+
+	// CommitLogQueuePolicy is the KV config key for the runtime configuration
+	// specifying the policy applied to commit log writes when the commit log
+	// queue is full, one of drop-current, drop-oldest or block
+	CommitLogQueuePolicy = "m3db.node.commit-log-queue-policy"
+
+	// CommitLogQueueLimit is the KV config key for the runtime configuration
+	// specifying the number of writes queued in front of the commit log
+	// before the commit log queue policy applies
+	CommitLogQueueLimit = "m3db.node.commit-log-queue-limit"
+
+	// WriteShadowingRules is the KV config key for the runtime configuration
+	// specifying the rules that mirror a fraction of the writes to a namespace
+	// to another namespace as a JSON array of write shadowing rules
+	WriteShadowingRules = "m3db.node.write-shadowing-rules"
 )
@@ -0,0 +1,204 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package carbon
+
+import (
+	"errors"
+	"time"
+
+	"github.com/m3db/m3x/clock"
+	"github.com/m3db/m3x/ident"
+	"github.com/m3db/m3x/instrument"
+)
+
+const (
+	defaultMaxConnections = 1024
+	defaultReadTimeout    = 5 * time.Minute
+	defaultBatchSize      = 1024
+)
+
+var (
+	errNamespaceNotSet           = errors.New("namespace not set")
+	errMaxConnectionsNotPositive = errors.New("max connections must be positive")
+	errBatchSizeNotPositive      = errors.New("batch size must be positive")
+)
+
+// Options is a set of carbon plaintext ingester options.
+type Options interface {
+	// Validate validates the options.
+	Validate() error
+
+	// SetNamespace sets the namespace that all metrics are written to.
+	SetNamespace(value ident.ID) Options
+
+	// Namespace returns the namespace that all metrics are written to.
+	Namespace() ident.ID
+
+	// SetRules sets the rules that extract tags from metric paths, the first
+	// rule that matches a path is applied.
+	SetRules(value []Rule) Options
+
+	// Rules returns the rules that extract tags from metric paths, the first
+	// rule that matches a path is applied.
+	Rules() []Rule
+
+	// SetClockOptions sets the clock options, the clock is used to timestamp
+	// metrics written with a timestamp of -1.
+	SetClockOptions(value clock.Options) Options
+
+	// ClockOptions returns the clock options, the clock is used to timestamp
+	// metrics written with a timestamp of -1.
+	ClockOptions() clock.Options
+
+	// SetInstrumentOptions sets the instrumentation options.
+	SetInstrumentOptions(value instrument.Options) Options
+
+	// InstrumentOptions returns the instrumentation options.
+	InstrumentOptions() instrument.Options
+
+	// SetMaxConnections sets the maximum number of concurrent connections,
+	// connections above the limit are closed as soon as they are accepted.
+	SetMaxConnections(value int) Options
+
+	// MaxConnections returns the maximum number of concurrent connections,
+	// connections above the limit are closed as soon as they are accepted.
+	MaxConnections() int
+
+	// SetReadTimeout sets how long a connection may be idle before it is
+	// closed, zero disables the timeout.
+	SetReadTimeout(value time.Duration) Options
+
+	// ReadTimeout returns how long a connection may be idle before it is
+	// closed, zero disables the timeout.
+	ReadTimeout() time.Duration
+
+	// SetBatchSize sets the maximum number of datapoints read from a
+	// connection before they are written.
+	SetBatchSize(value int) Options
+
+	// BatchSize returns the maximum number of datapoints read from a
+	// connection before they are written.
+	BatchSize() int
+}
+
+type options struct {
+	namespace      ident.ID
+	rules          []Rule
+	clockOpts      clock.Options
+	instrumentOpts instrument.Options
+	maxConnections int
+	readTimeout    time.Duration
+	batchSize      int
+}
+
+// NewOptions creates a new set of carbon plaintext ingester options.
+func NewOptions() Options {
+	return &options{
+		clockOpts:      clock.NewOptions(),
+		instrumentOpts: instrument.NewOptions(),
+		maxConnections: defaultMaxConnections,
+		readTimeout:    defaultReadTimeout,
+		batchSize:      defaultBatchSize,
+	}
+}
+
+func (o *options) Validate() error {
+	if o.namespace == nil || len(o.namespace.Bytes()) == 0 {
+		return errNamespaceNotSet
+	}
+	if o.maxConnections <= 0 {
+		return errMaxConnectionsNotPositive
+	}
+	if o.batchSize <= 0 {
+		return errBatchSizeNotPositive
+	}
+	return nil
+}
+
+func (o *options) SetNamespace(value ident.ID) Options {
+	opts := *o
+	opts.namespace = value
+	return &opts
+}
+
+func (o *options) Namespace() ident.ID {
+	return o.namespace
+}
+
+func (o *options) SetRules(value []Rule) Options {
+	opts := *o
+	opts.rules = value
+	return &opts
+}
+
+func (o *options) Rules() []Rule {
+	return o.rules
+}
+
+func (o *options) SetClockOptions(value clock.Options) Options {
+	opts := *o
+	opts.clockOpts = value
+	return &opts
+}
+
+func (o *options) ClockOptions() clock.Options {
+	return o.clockOpts
+}
+
+func (o *options) SetInstrumentOptions(value instrument.Options) Options {
+	opts := *o
+	opts.instrumentOpts = value
+	return &opts
+}
+
+func (o *options) InstrumentOptions() instrument.Options {
+	return o.instrumentOpts
+}
+
+func (o *options) SetMaxConnections(value int) Options {
+	opts := *o
+	opts.maxConnections = value
+	return &opts
+}
+
+func (o *options) MaxConnections() int {
+	return o.maxConnections
+}
+
+func (o *options) SetReadTimeout(value time.Duration) Options {
+	opts := *o
+	opts.readTimeout = value
+	return &opts
+}
+
+func (o *options) ReadTimeout() time.Duration {
+	return o.readTimeout
+}
+
+func (o *options) SetBatchSize(value int) Options {
+	opts := *o
+	opts.batchSize = value
+	return &opts
+}
+
+func (o *options) BatchSize() int {
+	return o.batchSize
+}
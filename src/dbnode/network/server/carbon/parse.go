@@ -0,0 +1,68 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package carbon
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/ts"
+)
+
+const (
+	// NB: carbon treats a timestamp of -1 as the time the metric is received.
+	nowTimestamp = -1
+)
+
+var (
+	errInvalidFieldCount = errors.New("expected a path, value and timestamp")
+	errLineTooLong       = errors.New("line is too long")
+)
+
+// parseLine parses a line of the carbon plaintext protocol of the form:
+// metric.path value timestamp
+// where the timestamp is in seconds, fractions of seconds are truncated.
+func parseLine(line []byte, now time.Time) ([]byte, ts.Datapoint, error) {
+	fields := bytes.Fields(line)
+	if len(fields) != 3 {
+		return nil, ts.Datapoint{}, errInvalidFieldCount
+	}
+
+	value, err := strconv.ParseFloat(string(fields[1]), 64)
+	if err != nil {
+		return nil, ts.Datapoint{}, fmt.Errorf("invalid value %s", fields[1])
+	}
+
+	secs, err := strconv.ParseFloat(string(fields[2]), 64)
+	if err != nil || math.IsNaN(secs) || math.IsInf(secs, 0) {
+		return nil, ts.Datapoint{}, fmt.Errorf("invalid timestamp %s", fields[2])
+	}
+
+	timestamp := time.Unix(int64(secs), 0)
+	if secs == nowTimestamp {
+		timestamp = now.Truncate(time.Second)
+	}
+	return fields[0], ts.Datapoint{Timestamp: timestamp, Value: value}, nil
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package carbon
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/m3db/m3x/ident"
+)
+
+const (
+	// NB: paths that no rule matches are tagged by the position of their
+	// components the same as by the coordinator, i.e. __g0__, __g1__ etc.
+	graphiteTagPrefix = "__g"
+	graphiteTagSuffix = "__"
+	pathSeparator     = '.'
+
+	seriesIDTagEq  = '='
+	seriesIDTagSep = ','
+)
+
+var (
+	errRuleExtractsNoTags = errors.New("rule has no named capture groups or positions")
+	errNoTagsExtracted    = errors.New("no tags extracted from path")
+)
+
+// RuleConfiguration is the configuration of a rule that extracts tags from
+// metric paths.
+type RuleConfiguration struct {
+	// Pattern is a regular expression that paths must match for the rule to
+	// apply, the values of its named capture groups are added as tags named
+	// after the groups. All paths match if not set.
+	Pattern string `yaml:"pattern"`
+
+	// Positions names the tags that the dot separated components of paths
+	// are added as by position, components at positions with an empty name
+	// or beyond the last position are dropped.
+	Positions []string `yaml:"positions"`
+}
+
+// NewRule creates a new rule from the configuration.
+func (c RuleConfiguration) NewRule() (Rule, error) {
+	var (
+		pattern *regexp.Regexp
+		err     error
+	)
+	if c.Pattern != "" {
+		pattern, err = regexp.Compile(c.Pattern)
+		if err != nil {
+			return Rule{}, fmt.Errorf("invalid rule pattern %s: %v", c.Pattern, err)
+		}
+	}
+
+	extractsTags := false
+	if pattern != nil {
+		for _, name := range pattern.SubexpNames() {
+			extractsTags = extractsTags || name != ""
+		}
+	}
+	for _, name := range c.Positions {
+		extractsTags = extractsTags || name != ""
+	}
+	if !extractsTags {
+		return Rule{}, errRuleExtractsNoTags
+	}
+
+	return Rule{pattern: pattern, positions: c.Positions}, nil
+}
+
+// Rule extracts tags from the metric paths that it matches.
+type Rule struct {
+	pattern   *regexp.Regexp
+	positions []string
+}
+
+// extract returns the tags of a path with the tags extracted by the rule
+// appended, or false if the rule does not match the path.
+func (r Rule) extract(path []byte, tags []ident.Tag) ([]ident.Tag, bool) {
+	if r.pattern != nil {
+		match := r.pattern.FindSubmatchIndex(path)
+		if match == nil {
+			return tags, false
+		}
+		for i, name := range r.pattern.SubexpNames() {
+			if name == "" || match[2*i] < 0 {
+				continue
+			}
+			tags = appendTag(tags, name, path[match[2*i]:match[2*i+1]])
+		}
+	}
+
+	position := 0
+	forEachComponent(path, func(component []byte) bool {
+		if position >= len(r.positions) {
+			return false
+		}
+		tags = appendTag(tags, r.positions[position], component)
+		position++
+		return true
+	})
+	return tags, true
+}
+
+// tagsFromPath returns the series ID and tags of a path, the tags are
+// extracted by the first rule that matches it or are the graphite positional
+// tags if no rule matches.
+func tagsFromPath(rules []Rule, path []byte) (ident.ID, ident.Tags, error) {
+	var (
+		tags    []ident.Tag
+		matched bool
+	)
+	for _, rule := range rules {
+		if tags, matched = rule.extract(path, tags); matched {
+			break
+		}
+	}
+
+	if !matched {
+		position := 0
+		forEachComponent(path, func(component []byte) bool {
+			tags = appendTag(tags, graphiteTagName(position), component)
+			position++
+			return true
+		})
+	}
+
+	if len(tags) == 0 {
+		return nil, ident.Tags{}, errNoTagsExtracted
+	}
+
+	sort.Sort(tagsByName(tags))
+
+	var id []byte
+	for _, tag := range tags {
+		id = append(id, tag.Name.Bytes()...)
+		id = append(id, seriesIDTagEq)
+		id = append(id, tag.Value.Bytes()...)
+		id = append(id, seriesIDTagSep)
+	}
+	return ident.BytesID(id), ident.NewTags(tags...), nil
+}
+
+func forEachComponent(path []byte, fn func(component []byte) bool) {
+	for len(path) > 0 {
+		component := path
+		if i := bytes.IndexByte(path, pathSeparator); i >= 0 {
+			component, path = path[:i], path[i+1:]
+		} else {
+			path = nil
+		}
+		if !fn(component) {
+			return
+		}
+	}
+}
+
+func appendTag(tags []ident.Tag, name string, value []byte) []ident.Tag {
+	// NB: tags with empty names or values are not indexable.
+	if name == "" || len(value) == 0 {
+		return tags
+	}
+	return append(tags, ident.StringTag(name, string(value)))
+}
+
+type tagsByName []ident.Tag
+
+func (t tagsByName) Len() int      { return len(t) }
+func (t tagsByName) Swap(i, j int) { t[i], t[j] = t[j], t[i] }
+func (t tagsByName) Less(i, j int) bool {
+	return bytes.Compare(t[i].Name.Bytes(), t[j].Name.Bytes()) < 0
+}
+
+func graphiteTagName(position int) string {
+	return graphiteTagPrefix + strconv.Itoa(position) + graphiteTagSuffix
+}
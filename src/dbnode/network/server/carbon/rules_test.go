@@ -0,0 +1,156 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package carbon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3x/ident"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRules(t *testing.T, configs ...RuleConfiguration) []Rule {
+	rules := make([]Rule, 0, len(configs))
+	for _, config := range configs {
+		rule, err := config.NewRule()
+		require.NoError(t, err)
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func TestTagsFromPathRegexpRule(t *testing.T) {
+	rules := newTestRules(t, RuleConfiguration{
+		Pattern: `^servers\.(?P<host>[^.]+)\.(?P<__name__>cpu\.(user|system))$`,
+	})
+
+	id, tags, err := tagsFromPath(rules, []byte("servers.web-1.cpu.user"))
+	require.NoError(t, err)
+	require.Equal(t, "__name__=cpu.user,host=web-1,", id.String())
+	require.True(t, ident.NewTags(
+		ident.StringTag("__name__", "cpu.user"),
+		ident.StringTag("host", "web-1"),
+	).Equal(tags))
+}
+
+func TestTagsFromPathPositionRule(t *testing.T) {
+	rules := newTestRules(t, RuleConfiguration{
+		Pattern:   `^servers\.`,
+		Positions: []string{"", "host", "__name__"},
+	})
+
+	id, tags, err := tagsFromPath(rules, []byte("servers.web-1.load.extra"))
+	require.NoError(t, err)
+	require.Equal(t, "__name__=load,host=web-1,", id.String())
+	require.True(t, ident.NewTags(
+		ident.StringTag("__name__", "load"),
+		ident.StringTag("host", "web-1"),
+	).Equal(tags))
+}
+
+func TestTagsFromPathFirstMatchingRule(t *testing.T) {
+	rules := newTestRules(t,
+		RuleConfiguration{
+			Pattern: `^apps\.(?P<app>[^.]+)\.`,
+		},
+		RuleConfiguration{
+			Pattern:   `^apps\.`,
+			Positions: []string{"", "service"},
+		},
+		RuleConfiguration{
+			Positions: []string{"root"},
+		},
+	)
+
+	id, tags, err := tagsFromPath(rules, []byte("apps.billing.requests"))
+	require.NoError(t, err)
+	require.Equal(t, "app=billing,", id.String())
+	require.True(t, ident.NewTags(
+		ident.StringTag("app", "billing"),
+	).Equal(tags))
+
+	id, tags, err = tagsFromPath(rules, []byte("jobs.nightly"))
+	require.NoError(t, err)
+	require.Equal(t, "root=jobs,", id.String())
+	require.True(t, ident.NewTags(
+		ident.StringTag("root", "jobs"),
+	).Equal(tags))
+}
+
+func TestTagsFromPathNoMatchingRule(t *testing.T) {
+	rules := newTestRules(t, RuleConfiguration{
+		Pattern: `^servers\.(?P<host>[^.]+)`,
+	})
+
+	id, tags, err := tagsFromPath(rules, []byte("stats..gauges.mem"))
+	require.NoError(t, err)
+	require.Equal(t, "__g0__=stats,__g2__=gauges,__g3__=mem,", id.String())
+	require.True(t, ident.NewTags(
+		ident.StringTag("__g0__", "stats"),
+		ident.StringTag("__g2__", "gauges"),
+		ident.StringTag("__g3__", "mem"),
+	).Equal(tags))
+}
+
+func TestTagsFromPathNoTags(t *testing.T) {
+	rules := newTestRules(t, RuleConfiguration{
+		Pattern: `^(?P<host>[a-z]*)\.`,
+	})
+
+	_, _, err := tagsFromPath(rules, []byte("."))
+	require.Equal(t, errNoTagsExtracted, err)
+}
+
+func TestNewRuleInvalid(t *testing.T) {
+	_, err := RuleConfiguration{Pattern: `(`}.NewRule()
+	require.Error(t, err)
+
+	_, err = RuleConfiguration{Pattern: `^servers\.([^.]+)`}.NewRule()
+	require.Equal(t, errRuleExtractsNoTags, err)
+
+	_, err = RuleConfiguration{Positions: []string{"", ""}}.NewRule()
+	require.Equal(t, errRuleExtractsNoTags, err)
+}
+
+func TestParseLine(t *testing.T) {
+	now := time.Unix(1500000000, 500)
+
+	path, dp, err := parseLine([]byte("servers.web-1.load 1.5 1400000000\n"), now)
+	require.NoError(t, err)
+	require.Equal(t, "servers.web-1.load", string(path))
+	require.Equal(t, ts.Datapoint{Timestamp: time.Unix(1400000000, 0), Value: 1.5}, dp)
+
+	_, dp, err = parseLine([]byte("servers.web-1.load\t-2 -1"), now)
+	require.NoError(t, err)
+	require.Equal(t, ts.Datapoint{Timestamp: time.Unix(1500000000, 0), Value: -2}, dp)
+
+	_, _, err = parseLine([]byte("servers.web-1.load 1.5"), now)
+	require.Equal(t, errInvalidFieldCount, err)
+
+	_, _, err = parseLine([]byte("servers.web-1.load high 1400000000"), now)
+	require.Error(t, err)
+
+	_, _, err = parseLine([]byte("servers.web-1.load 1 yesterday"), now)
+	require.Error(t, err)
+}
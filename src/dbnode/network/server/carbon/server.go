@@ -0,0 +1,338 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package carbon serves the carbon plaintext protocol against the local
+// database, so that Graphite clients can write straight to a node.
+package carbon
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	ns "github.com/m3db/m3/src/dbnode/network/server"
+	"github.com/m3db/m3/src/dbnode/storage"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3x/clock"
+	"github.com/m3db/m3x/context"
+	"github.com/m3db/m3x/ident"
+	xlog "github.com/m3db/m3x/log"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/uber-go/tally"
+)
+
+const (
+	readBufferSize      = 4096
+	acceptRetryInterval = 100 * time.Millisecond
+
+	// NB: malformed lines and failed writes are logged at most once per
+	// interval so a misbehaving client cannot flood the logs.
+	logSampleInterval = 10 * time.Second
+)
+
+type server struct {
+	address     string
+	db          storage.Database
+	namespace   ident.ID
+	contextPool context.Pool
+	rules       []Rule
+	nowFn       clock.NowFn
+	readTimeout time.Duration
+	batchSize   int
+	logger      xlog.Logger
+	metrics     serverMetrics
+
+	// connSlots holds a slot for each open connection to bound the number of
+	// concurrent connections.
+	connSlots chan struct{}
+
+	sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+	wg       sync.WaitGroup
+
+	lastLogNanos int64
+}
+
+// NewServer creates a carbon plaintext TCP network service.
+func NewServer(
+	db storage.Database,
+	address string,
+	contextPool context.Pool,
+	opts Options,
+) (ns.NetworkService, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	iopts := opts.InstrumentOptions()
+	return &server{
+		address:     address,
+		db:          db,
+		namespace:   opts.Namespace(),
+		contextPool: contextPool,
+		rules:       opts.Rules(),
+		nowFn:       opts.ClockOptions().NowFn(),
+		readTimeout: opts.ReadTimeout(),
+		batchSize:   opts.BatchSize(),
+		logger:      iopts.Logger(),
+		metrics:     newServerMetrics(iopts.MetricsScope()),
+		connSlots:   make(chan struct{}, opts.MaxConnections()),
+		conns:       make(map[net.Conn]struct{}),
+	}, nil
+}
+
+func (s *server) ListenAndServe() (ns.Close, error) {
+	listener, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Lock()
+	s.listener = listener
+	s.Unlock()
+
+	s.wg.Add(1)
+	go s.serve(listener)
+
+	return s.close, nil
+}
+
+func (s *server) serve(listener net.Listener) {
+	defer s.wg.Done()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Temporary() {
+				time.Sleep(acceptRetryInterval)
+				continue
+			}
+			return
+		}
+
+		select {
+		case s.connSlots <- struct{}{}:
+		default:
+			s.metrics.connectionsRejected.Inc(1)
+			conn.Close()
+			continue
+		}
+
+		s.Lock()
+		if s.closed {
+			s.Unlock()
+			conn.Close()
+			<-s.connSlots
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.Unlock()
+
+		s.metrics.connectionsAccepted.Inc(1)
+		go s.handleConn(conn)
+	}
+}
+
+func (s *server) handleConn(conn net.Conn) {
+	defer func() {
+		s.Lock()
+		delete(s.conns, conn)
+		s.Unlock()
+		conn.Close()
+		<-s.connSlots
+		s.wg.Done()
+	}()
+
+	var (
+		reader  = bufio.NewReaderSize(conn, readBufferSize)
+		batch   = newSeriesBatch()
+		tooLong bool
+	)
+	for {
+		// NB: write the batch before blocking on the connection so that
+		// metrics from clients that write infrequently are not delayed.
+		if reader.Buffered() == 0 || batch.size >= s.batchSize {
+			s.write(batch)
+		}
+
+		if s.readTimeout > 0 {
+			conn.SetReadDeadline(s.nowFn().Add(s.readTimeout))
+		}
+		line, err := reader.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			// NB: discard the rest of lines longer than the read buffer
+			// rather than buffering them without bound.
+			tooLong = true
+			continue
+		}
+		if err != nil && err != io.EOF {
+			// NB: the connection was closed or timed out, drop any partial
+			// line rather than write a truncated value.
+			break
+		}
+		if tooLong {
+			tooLong = false
+			s.malformed(line, errLineTooLong)
+		} else if len(bytes.TrimSpace(line)) > 0 {
+			s.add(batch, line)
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	s.write(batch)
+}
+
+func (s *server) add(batch *seriesBatch, line []byte) {
+	path, dp, err := parseLine(line, s.nowFn())
+	if err != nil {
+		s.malformed(line, err)
+		return
+	}
+	id, tags, err := tagsFromPath(s.rules, path)
+	if err != nil {
+		s.malformed(line, err)
+		return
+	}
+	batch.add(id, tags, dp)
+}
+
+func (s *server) write(batch *seriesBatch) {
+	if batch.size == 0 {
+		return
+	}
+
+	ctx := s.contextPool.Get()
+	for _, series := range batch.series {
+		writeErrs, err := s.db.WriteTaggedBatch(ctx, s.namespace, series.id,
+			ident.NewTagsIterator(series.tags), series.datapoints, xtime.Second, nil)
+		if err != nil {
+			s.metrics.writeErrors.Inc(int64(len(series.datapoints)))
+			s.logSampled("carbon write error: %v", err)
+			continue
+		}
+		written := len(series.datapoints)
+		for _, writeErr := range writeErrs {
+			if writeErr != nil {
+				written--
+				s.metrics.writeErrors.Inc(1)
+				s.logSampled("carbon write error: %v", writeErr)
+			}
+		}
+		s.metrics.datapointsWritten.Inc(int64(written))
+	}
+	ctx.Close()
+
+	batch.reset()
+}
+
+func (s *server) malformed(line []byte, err error) {
+	s.metrics.linesMalformed.Inc(1)
+	s.logSampled("carbon malformed line %q: %v", bytes.TrimSpace(line), err)
+}
+
+func (s *server) logSampled(format string, args ...interface{}) {
+	var (
+		now  = s.nowFn().UnixNano()
+		last = atomic.LoadInt64(&s.lastLogNanos)
+	)
+	if now-last < int64(logSampleInterval) ||
+		!atomic.CompareAndSwapInt64(&s.lastLogNanos, last, now) {
+		return
+	}
+	s.logger.Warnf(format, args...)
+}
+
+func (s *server) close() {
+	s.Lock()
+	if s.closed {
+		s.Unlock()
+		return
+	}
+	s.closed = true
+	s.listener.Close()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.Unlock()
+
+	s.wg.Wait()
+}
+
+type series struct {
+	id         ident.ID
+	tags       ident.Tags
+	datapoints []ts.Datapoint
+}
+
+// seriesBatch is the datapoints read from a connection grouped by series.
+type seriesBatch struct {
+	series []*series
+	byID   map[string]*series
+	size   int
+}
+
+func newSeriesBatch() *seriesBatch {
+	return &seriesBatch{byID: make(map[string]*series)}
+}
+
+func (b *seriesBatch) add(id ident.ID, tags ident.Tags, dp ts.Datapoint) {
+	s, ok := b.byID[string(id.Bytes())]
+	if !ok {
+		s = &series{id: id, tags: tags}
+		b.byID[string(id.Bytes())] = s
+		b.series = append(b.series, s)
+	}
+	s.datapoints = append(s.datapoints, dp)
+	b.size++
+}
+
+func (b *seriesBatch) reset() {
+	for id := range b.byID {
+		delete(b.byID, id)
+	}
+	b.series = b.series[:0]
+	b.size = 0
+}
+
+type serverMetrics struct {
+	connectionsAccepted tally.Counter
+	connectionsRejected tally.Counter
+	linesMalformed      tally.Counter
+	datapointsWritten   tally.Counter
+	writeErrors         tally.Counter
+}
+
+func newServerMetrics(scope tally.Scope) serverMetrics {
+	return serverMetrics{
+		connectionsAccepted: scope.Counter("connections-accepted"),
+		connectionsRejected: scope.Counter("connections-rejected"),
+		linesMalformed:      scope.Counter("lines-malformed"),
+		datapointsWritten:   scope.Counter("datapoints-written"),
+		writeErrors:         scope.Counter("write-errors"),
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package carbon
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/storage"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3x/context"
+	"github.com/m3db/m3x/ident"
+	"github.com/m3db/m3x/instrument"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+type testWrites struct {
+	sync.Mutex
+	datapoints map[string][]ts.Datapoint
+	total      int
+}
+
+func (w *testWrites) numWritten() int {
+	w.Lock()
+	defer w.Unlock()
+	return w.total
+}
+
+func (w *testWrites) waitForWritten(t *testing.T, expected int) {
+	deadline := time.Now().Add(10 * time.Second)
+	for w.numWritten() < expected && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.Equal(t, expected, w.numWritten())
+}
+
+func newTestServer(
+	t *testing.T,
+	ctrl *gomock.Controller,
+	opts Options,
+) (*server, func(), *testWrites, tally.TestScope) {
+	var (
+		nsID   = ident.StringID("metrics")
+		scope  = tally.NewTestScope("", nil)
+		writes = &testWrites{datapoints: make(map[string][]ts.Datapoint)}
+		db     = storage.NewMockDatabase(ctrl)
+	)
+	db.EXPECT().
+		WriteTaggedBatch(gomock.Any(), nsID, gomock.Any(), gomock.Any(), gomock.Any(), xtime.Second, nil).
+		DoAndReturn(func(
+			_ context.Context,
+			_ ident.ID,
+			id ident.ID,
+			_ ident.TagIterator,
+			datapoints []ts.Datapoint,
+			_ xtime.Unit,
+			_ [][]byte,
+		) ([]error, error) {
+			writes.Lock()
+			writes.datapoints[id.String()] = append(writes.datapoints[id.String()], datapoints...)
+			writes.total += len(datapoints)
+			writes.Unlock()
+			return make([]error, len(datapoints)), nil
+		}).
+		AnyTimes()
+
+	opts = opts.
+		SetNamespace(nsID).
+		SetInstrumentOptions(instrument.NewOptions().SetMetricsScope(scope))
+	service, err := NewServer(db, "127.0.0.1:0", context.NewPool(context.NewOptions()), opts)
+	require.NoError(t, err)
+	closer, err := service.ListenAndServe()
+	require.NoError(t, err)
+	return service.(*server), closer, writes, scope
+}
+
+func dialTestServer(t *testing.T, s *server) net.Conn {
+	conn, err := net.Dial("tcp", s.listener.Addr().String())
+	require.NoError(t, err)
+	return conn
+}
+
+func TestServerManyConcurrentConnections(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rule, err := RuleConfiguration{
+		Pattern: `^servers\.(?P<host>[^.]+)\.(?P<__name__>.+)$`,
+	}.NewRule()
+	require.NoError(t, err)
+
+	s, closer, writes, scope := newTestServer(t, ctrl,
+		NewOptions().SetRules([]Rule{rule}).SetBatchSize(64))
+
+	const (
+		numConns     = 64
+		linesPerConn = 500
+		malformedMod = 50
+	)
+	var wg sync.WaitGroup
+	for i := 0; i < numConns; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			conn := dialTestServer(t, s)
+			defer conn.Close()
+
+			var lines []string
+			for j := 0; j < linesPerConn; j++ {
+				if j%malformedMod == malformedMod/2 {
+					lines = append(lines, "servers.malformed")
+					continue
+				}
+				lines = append(lines, fmt.Sprintf("servers.host-%d.load %d %d", i, j, 1500000000+j))
+			}
+			// NB: write in chunks that split lines to exercise buffering.
+			payload := strings.Join(lines, "\n") + "\n"
+			for len(payload) > 0 {
+				n := 1000
+				if n > len(payload) {
+					n = len(payload)
+				}
+				_, err := conn.Write([]byte(payload[:n]))
+				require.NoError(t, err)
+				payload = payload[n:]
+			}
+		}()
+	}
+	wg.Wait()
+
+	malformedPerConn := linesPerConn / malformedMod
+	expected := numConns * (linesPerConn - malformedPerConn)
+	writes.waitForWritten(t, expected)
+	closer()
+
+	writes.Lock()
+	require.Equal(t, numConns, len(writes.datapoints))
+	for i := 0; i < numConns; i++ {
+		id := fmt.Sprintf("__name__=load,host=host-%d,", i)
+		datapoints := writes.datapoints[id]
+		require.Equal(t, linesPerConn-malformedPerConn, len(datapoints), id)
+		for _, dp := range datapoints {
+			require.Equal(t, time.Unix(1500000000+int64(dp.Value), 0), dp.Timestamp)
+		}
+	}
+	writes.Unlock()
+
+	counters := scope.Snapshot().Counters()
+	require.Equal(t, int64(numConns), counters["connections-accepted+"].Value())
+	require.Equal(t, int64(numConns*malformedPerConn), counters["lines-malformed+"].Value())
+	require.Equal(t, int64(expected), counters["datapoints-written+"].Value())
+}
+
+func TestServerMalformedLinesKeepConnection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s, closer, writes, scope := newTestServer(t, ctrl, NewOptions())
+	defer closer()
+
+	conn := dialTestServer(t, s)
+	defer conn.Close()
+
+	payload := strings.Join([]string{
+		"stats.requests 1 1500000000",
+		"stats.requests one 1500000001",
+		"stats." + strings.Repeat("x", 2*readBufferSize) + " 2 1500000002",
+		"stats.requests 3 1500000003",
+	}, "\n") + "\n"
+	_, err := conn.Write([]byte(payload))
+	require.NoError(t, err)
+
+	writes.waitForWritten(t, 2)
+	writes.Lock()
+	require.Equal(t, []ts.Datapoint{
+		{Timestamp: time.Unix(1500000000, 0), Value: 1},
+		{Timestamp: time.Unix(1500000003, 0), Value: 3},
+	}, writes.datapoints["__g0__=stats,__g1__=requests,"])
+	writes.Unlock()
+
+	counters := scope.Snapshot().Counters()
+	require.Equal(t, int64(2), counters["lines-malformed+"].Value())
+}
+
+func TestServerConnectionLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s, closer, writes, scope := newTestServer(t, ctrl, NewOptions().SetMaxConnections(1))
+	defer closer()
+
+	accepted := dialTestServer(t, s)
+	defer accepted.Close()
+	_, err := accepted.Write([]byte("stats.requests 1 1500000000\n"))
+	require.NoError(t, err)
+	writes.waitForWritten(t, 1)
+
+	rejected := dialTestServer(t, s)
+	defer rejected.Close()
+	require.NoError(t, rejected.SetReadDeadline(time.Now().Add(10*time.Second)))
+	_, err = rejected.Read(make([]byte, 1))
+	require.Equal(t, io.EOF, err)
+
+	counters := scope.Snapshot().Counters()
+	require.Equal(t, int64(1), counters["connections-accepted+"].Value())
+	require.Equal(t, int64(1), counters["connections-rejected+"].Value())
+}
@@ -122,11 +122,11 @@ func RegisterHandlers(mux *http.ServeMux, service interface{}, opts ServerOption
 
 			httpMethod := strings.ToUpper(r.Method)
 			if reqIn == nil && httpMethod != "GET" {
-				writeError(w, errRequestMustBeGet)
+				WriteError(w, errRequestMustBeGet)
 				return
 			}
 			if reqIn != nil && httpMethod != "POST" {
-				writeError(w, errRequestMustBePost)
+				WriteError(w, errRequestMustBePost)
 				return
 			}
 
@@ -141,7 +141,7 @@ func RegisterHandlers(mux *http.ServeMux, service interface{}, opts ServerOption
 			if reqIn != nil {
 				in = reflect.New(reqIn.Elem()).Interface()
 				if err := json.NewDecoder(r.Body).Decode(in); err != nil {
-					writeError(w, errInvalidRequestBody)
+					WriteError(w, errInvalidRequestBody)
 					return
 				}
 			}
@@ -176,7 +176,7 @@ func RegisterHandlers(mux *http.ServeMux, service interface{}, opts ServerOption
 
 				// Deal with error case
 				if !ret[0].IsNil() {
-					writeError(w, ret[0].Interface())
+					WriteError(w, ret[0].Interface())
 					return
 				}
 				json.NewEncoder(w).Encode(&respSuccess{})
@@ -196,13 +196,13 @@ func RegisterHandlers(mux *http.ServeMux, service interface{}, opts ServerOption
 
 			// Deal with error case
 			if !ret[1].IsNil() {
-				writeError(w, ret[1].Interface())
+				WriteError(w, ret[1].Interface())
 				return
 			}
 
 			buff := bytes.NewBuffer(nil)
 			if err := json.NewEncoder(buff).Encode(ret[0].Interface()); err != nil {
-				writeError(w, fmt.Errorf("failed to encode response body: %v", err))
+				WriteError(w, fmt.Errorf("failed to encode response body: %v", err))
 				return
 			}
 
@@ -212,7 +212,9 @@ func RegisterHandlers(mux *http.ServeMux, service interface{}, opts ServerOption
 	return nil
 }
 
-func writeError(w http.ResponseWriter, errValue interface{}) {
+// WriteError writes an error as a JSON response, invalid params errors are
+// returned with a bad request status code
+func WriteError(w http.ResponseWriter, errValue interface{}) {
 	result := respErrorResult{respError{}}
 	if value, ok := errValue.(error); ok {
 		result.Error.Message = value.Error()
@@ -0,0 +1,166 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package node
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/m3db/m3/src/dbnode/network/server/httpjson"
+	"github.com/m3db/m3/src/dbnode/storage"
+	"github.com/m3db/m3/src/dbnode/storage/series"
+	xerrors "github.com/m3db/m3x/errors"
+	"github.com/m3db/m3x/ident"
+)
+
+const (
+	// DebugBucketsInfoPath is the path of the handler that dumps the
+	// buffer buckets of a series.
+	DebugBucketsInfoPath = "/debug/buckets"
+
+	// DebugNamespacesPath is the path of the handler that dumps the
+	// persistence settings of each namespace.
+	DebugNamespacesPath = "/debug/namespaces"
+
+	debugNamespaceParam = "namespace"
+	debugIDParam        = "id"
+)
+
+var (
+	errDebugRequestMustBeGet = xerrors.NewInvalidParamsError(
+		errors.New("debug request must be GET"))
+	errDebugMissingParams = xerrors.NewInvalidParamsError(
+		fmt.Errorf("debug request requires %s and %s params",
+			debugNamespaceParam, debugIDParam))
+)
+
+type bucketsInfoResponse struct {
+	Namespace    string              `json:"namespace"`
+	InMemoryOnly bool                `json:"inMemoryOnly"`
+	ID           string              `json:"id"`
+	Buckets      []series.BucketInfo `json:"buckets"`
+}
+
+type namespaceInfo struct {
+	ID                string `json:"id"`
+	InMemoryOnly      bool   `json:"inMemoryOnly"`
+	FlushEnabled      bool   `json:"flushEnabled"`
+	SnapshotEnabled   bool   `json:"snapshotEnabled"`
+	WritesToCommitLog bool   `json:"writesToCommitLog"`
+	RetentionPeriod   string `json:"retentionPeriod"`
+}
+
+type namespacesResponse struct {
+	Namespaces []namespaceInfo `json:"namespaces"`
+}
+
+// newNamespaceInfo describes the persistence of a namespace, namespaces that
+// are never flushed are marked in memory only as they hold their data in
+// memory until it expires by retention and never write files.
+func newNamespaceInfo(ns storage.Namespace) namespaceInfo {
+	opts := ns.Options()
+	return namespaceInfo{
+		ID:                ns.ID().String(),
+		InMemoryOnly:      !opts.FlushEnabled(),
+		FlushEnabled:      opts.FlushEnabled(),
+		SnapshotEnabled:   opts.FlushEnabled() && opts.SnapshotEnabled(),
+		WritesToCommitLog: opts.WritesToCommitLog(),
+		RetentionPeriod:   opts.RetentionOptions().RetentionPeriod().String(),
+	}
+}
+
+func newNamespacesHandler(db storage.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method != http.MethodGet {
+			httpjson.WriteError(w, errDebugRequestMustBeGet)
+			return
+		}
+
+		namespaces := db.Namespaces()
+		sort.Sort(storage.NamespacesByID(namespaces))
+
+		infos := make([]namespaceInfo, 0, len(namespaces))
+		for _, ns := range namespaces {
+			infos = append(infos, newNamespaceInfo(ns))
+		}
+
+		buff := bytes.NewBuffer(nil)
+		if err := json.NewEncoder(buff).Encode(&namespacesResponse{
+			Namespaces: infos,
+		}); err != nil {
+			httpjson.WriteError(w, fmt.Errorf("failed to encode response body: %v", err))
+			return
+		}
+
+		w.Write(buff.Bytes())
+	}
+}
+
+func newBucketsInfoHandler(db storage.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method != http.MethodGet {
+			httpjson.WriteError(w, errDebugRequestMustBeGet)
+			return
+		}
+
+		var (
+			query     = r.URL.Query()
+			namespace = query.Get(debugNamespaceParam)
+			id        = query.Get(debugIDParam)
+		)
+		if namespace == "" || id == "" {
+			httpjson.WriteError(w, errDebugMissingParams)
+			return
+		}
+
+		buckets, err := db.BucketsInfo(ident.StringID(namespace), ident.StringID(id))
+		if err != nil {
+			httpjson.WriteError(w, err)
+			return
+		}
+
+		var inMemoryOnly bool
+		if ns, ok := db.Namespace(ident.StringID(namespace)); ok {
+			inMemoryOnly = !ns.Options().FlushEnabled()
+		}
+
+		buff := bytes.NewBuffer(nil)
+		if err := json.NewEncoder(buff).Encode(&bucketsInfoResponse{
+			Namespace:    namespace,
+			InMemoryOnly: inMemoryOnly,
+			ID:           id,
+			Buckets:      buckets,
+		}); err != nil {
+			httpjson.WriteError(w, fmt.Errorf("failed to encode response body: %v", err))
+			return
+		}
+
+		w.Write(buff.Bytes())
+	}
+}
@@ -69,6 +69,8 @@ func (s *server) ListenAndServe() (ns.Close, error) {
 	if err := httpjson.RegisterHandlers(mux, ttnode.NewService(s.db, s.ttopts), s.opts); err != nil {
 		return nil, err
 	}
+	mux.HandleFunc(DebugBucketsInfoPath, newBucketsInfoHandler(s.db))
+	mux.HandleFunc(DebugNamespacesPath, newNamespacesHandler(s.db))
 
 	listener, err := net.Listen("tcp", s.address)
 	if err != nil {
@@ -0,0 +1,177 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package influxdb
+
+import (
+	"errors"
+	"time"
+
+	"github.com/m3db/m3x/clock"
+	"github.com/m3db/m3x/ident"
+	"github.com/m3db/m3x/instrument"
+)
+
+const (
+	defaultReadTimeout     = 10 * time.Second
+	defaultWriteTimeout    = 10 * time.Second
+	defaultMaxRequestBytes = 16 * 1024 * 1024
+)
+
+var (
+	errNamespaceNotSet            = errors.New("namespace not set")
+	errMaxRequestBytesNotPositive = errors.New("max request bytes must be positive")
+)
+
+// Options is a set of InfluxDB line protocol write server options.
+type Options interface {
+	// Validate validates the options.
+	Validate() error
+
+	// SetNamespace sets the namespace that all writes are written to.
+	SetNamespace(value ident.ID) Options
+
+	// Namespace returns the namespace that all writes are written to.
+	Namespace() ident.ID
+
+	// SetClockOptions sets the clock options, the clock is used to timestamp
+	// points written without a timestamp.
+	SetClockOptions(value clock.Options) Options
+
+	// ClockOptions returns the clock options, the clock is used to timestamp
+	// points written without a timestamp.
+	ClockOptions() clock.Options
+
+	// SetInstrumentOptions sets the instrumentation options.
+	SetInstrumentOptions(value instrument.Options) Options
+
+	// InstrumentOptions returns the instrumentation options.
+	InstrumentOptions() instrument.Options
+
+	// SetReadTimeout sets the read timeout of the HTTP server.
+	SetReadTimeout(value time.Duration) Options
+
+	// ReadTimeout returns the read timeout of the HTTP server.
+	ReadTimeout() time.Duration
+
+	// SetWriteTimeout sets the write timeout of the HTTP server.
+	SetWriteTimeout(value time.Duration) Options
+
+	// WriteTimeout returns the write timeout of the HTTP server.
+	WriteTimeout() time.Duration
+
+	// SetMaxRequestBytes sets the maximum size of a single write request
+	// body after it is decompressed.
+	SetMaxRequestBytes(value int) Options
+
+	// MaxRequestBytes returns the maximum size of a single write request
+	// body after it is decompressed.
+	MaxRequestBytes() int
+}
+
+type options struct {
+	namespace       ident.ID
+	clockOpts       clock.Options
+	instrumentOpts  instrument.Options
+	readTimeout     time.Duration
+	writeTimeout    time.Duration
+	maxRequestBytes int
+}
+
+// NewOptions creates a new set of InfluxDB line protocol write server options.
+func NewOptions() Options {
+	return &options{
+		clockOpts:       clock.NewOptions(),
+		instrumentOpts:  instrument.NewOptions(),
+		readTimeout:     defaultReadTimeout,
+		writeTimeout:    defaultWriteTimeout,
+		maxRequestBytes: defaultMaxRequestBytes,
+	}
+}
+
+func (o *options) Validate() error {
+	if o.namespace == nil || len(o.namespace.Bytes()) == 0 {
+		return errNamespaceNotSet
+	}
+	if o.maxRequestBytes <= 0 {
+		return errMaxRequestBytesNotPositive
+	}
+	return nil
+}
+
+func (o *options) SetNamespace(value ident.ID) Options {
+	opts := *o
+	opts.namespace = value
+	return &opts
+}
+
+func (o *options) Namespace() ident.ID {
+	return o.namespace
+}
+
+func (o *options) SetClockOptions(value clock.Options) Options {
+	opts := *o
+	opts.clockOpts = value
+	return &opts
+}
+
+func (o *options) ClockOptions() clock.Options {
+	return o.clockOpts
+}
+
+func (o *options) SetInstrumentOptions(value instrument.Options) Options {
+	opts := *o
+	opts.instrumentOpts = value
+	return &opts
+}
+
+func (o *options) InstrumentOptions() instrument.Options {
+	return o.instrumentOpts
+}
+
+func (o *options) SetReadTimeout(value time.Duration) Options {
+	opts := *o
+	opts.readTimeout = value
+	return &opts
+}
+
+func (o *options) ReadTimeout() time.Duration {
+	return o.readTimeout
+}
+
+func (o *options) SetWriteTimeout(value time.Duration) Options {
+	opts := *o
+	opts.writeTimeout = value
+	return &opts
+}
+
+func (o *options) WriteTimeout() time.Duration {
+	return o.writeTimeout
+}
+
+func (o *options) SetMaxRequestBytes(value int) Options {
+	opts := *o
+	opts.maxRequestBytes = value
+	return &opts
+}
+
+func (o *options) MaxRequestBytes() int {
+	return o.maxRequestBytes
+}
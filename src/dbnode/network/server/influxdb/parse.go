@@ -0,0 +1,252 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package influxdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	// NB: the measurement may contain escaped commas and spaces, tag keys,
+	// tag values and field keys may additionally contain escaped equals signs.
+	measurementEscapes = ", "
+	keyEscapes         = ",= "
+)
+
+var (
+	errMissingMeasurement = errors.New("missing measurement")
+	errMissingTagKey      = errors.New("missing tag key")
+	errMissingTagValue    = errors.New("missing tag value")
+	errMissingFields      = errors.New("missing fields")
+	errMissingFieldKey    = errors.New("missing field key")
+	errMissingFieldValue  = errors.New("missing field value")
+	errUnterminatedString = errors.New("unterminated string field value")
+	errTrailingData       = errors.New("unexpected data after timestamp")
+)
+
+type tag struct {
+	name  []byte
+	value []byte
+}
+
+type field struct {
+	name  []byte
+	value float64
+}
+
+// point is a single parsed line of line protocol.
+type point struct {
+	measurement  []byte
+	tags         []tag
+	fields       []field
+	timestamp    int64
+	hasTimestamp bool
+}
+
+// parsePoint parses a single line of line protocol of the form:
+// measurement[,tag=value...] field=value[,field=value...] [timestamp]
+// String field values are parsed but skipped since only numeric values
+// can be stored, booleans are stored as one or zero.
+func parsePoint(line []byte) (point, error) {
+	var (
+		p   point
+		tok []byte
+		i   int
+	)
+
+	p.measurement, i = scanToken(line, 0, ", ", measurementEscapes)
+	if len(p.measurement) == 0 {
+		return point{}, errMissingMeasurement
+	}
+
+	for i < len(line) && line[i] == ',' {
+		var t tag
+		t.name, i = scanToken(line, i+1, ",= ", keyEscapes)
+		if len(t.name) == 0 || i >= len(line) || line[i] != '=' {
+			return point{}, errMissingTagKey
+		}
+		t.value, i = scanToken(line, i+1, ", ", keyEscapes)
+		if len(t.value) == 0 {
+			return point{}, errMissingTagValue
+		}
+		p.tags = append(p.tags, t)
+	}
+
+	i = skipSpaces(line, i)
+	if i >= len(line) {
+		return point{}, errMissingFields
+	}
+
+	for {
+		var (
+			name  []byte
+			value float64
+			skip  bool
+			err   error
+		)
+		name, i = scanToken(line, i, ",= ", keyEscapes)
+		if len(name) == 0 || i >= len(line) || line[i] != '=' {
+			return point{}, errMissingFieldKey
+		}
+		i++
+		if i < len(line) && line[i] == '"' {
+			i, err = skipString(line, i+1)
+			skip = true
+		} else {
+			tok, i = scanToken(line, i, ", ", "")
+			value, err = parseFieldValue(tok)
+		}
+		if err != nil {
+			return point{}, fmt.Errorf("field %s: %v", name, err)
+		}
+		if !skip {
+			p.fields = append(p.fields, field{name: name, value: value})
+		}
+		if i >= len(line) || line[i] != ',' {
+			break
+		}
+		i++
+	}
+
+	i = skipSpaces(line, i)
+	if i >= len(line) {
+		return p, nil
+	}
+
+	tok, i = scanToken(line, i, " ", "")
+	timestamp, err := strconv.ParseInt(string(tok), 10, 64)
+	if err != nil {
+		return point{}, fmt.Errorf("invalid timestamp %s", tok)
+	}
+	if i = skipSpaces(line, i); i < len(line) {
+		return point{}, errTrailingData
+	}
+	p.timestamp = timestamp
+	p.hasTimestamp = true
+	return p, nil
+}
+
+// scanToken returns the token starting at start and ending before the first
+// unescaped byte in stops along with the index it ended at, a backslash
+// followed by a byte in escapes is unescaped.
+func scanToken(line []byte, start int, stops, escapes string) ([]byte, int) {
+	var (
+		tok     []byte
+		escaped bool
+		i       = start
+	)
+	for ; i < len(line); i++ {
+		c := line[i]
+		if c == '\\' && i+1 < len(line) && bytes.IndexByte([]byte(escapes), line[i+1]) >= 0 {
+			if !escaped {
+				// NB: only copy the token when it contains escapes.
+				tok = append(tok, line[start:i]...)
+				escaped = true
+			}
+			i++
+			tok = append(tok, line[i])
+			continue
+		}
+		if bytes.IndexByte([]byte(stops), c) >= 0 {
+			break
+		}
+		if escaped {
+			tok = append(tok, c)
+		}
+	}
+	if !escaped {
+		tok = line[start:i]
+	}
+	return tok, i
+}
+
+// skipString returns the index after the closing quote of a string field value
+// that starts at start, quotes and backslashes may be escaped.
+func skipString(line []byte, start int) (int, error) {
+	for i := start; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1, nil
+		}
+	}
+	return 0, errUnterminatedString
+}
+
+func skipSpaces(line []byte, i int) int {
+	for i < len(line) && line[i] == ' ' {
+		i++
+	}
+	return i
+}
+
+func parseFieldValue(tok []byte) (float64, error) {
+	if len(tok) == 0 {
+		return 0, errMissingFieldValue
+	}
+	s := string(tok)
+	switch s {
+	case "t", "T", "true", "True", "TRUE":
+		return 1, nil
+	case "f", "F", "false", "False", "FALSE":
+		return 0, nil
+	}
+	switch s[len(s)-1] {
+	case 'i':
+		v, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid integer value %s", s)
+		}
+		return float64(v), nil
+	case 'u':
+		v, err := strconv.ParseUint(s[:len(s)-1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid unsigned integer value %s", s)
+		}
+		return float64(v), nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid float value %s", s)
+	}
+	return v, nil
+}
+
+// parsePrecision returns the unit of timestamps for the precision query
+// parameter of a write request, timestamps are in nanoseconds by default.
+func parsePrecision(precision string) (time.Duration, error) {
+	switch precision {
+	case "", "n", "ns":
+		return time.Nanosecond, nil
+	case "u", "us":
+		return time.Microsecond, nil
+	case "ms":
+		return time.Millisecond, nil
+	case "s":
+		return time.Second, nil
+	}
+	return 0, fmt.Errorf("unknown precision %s", precision)
+}
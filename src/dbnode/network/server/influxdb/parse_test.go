@@ -0,0 +1,135 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package influxdb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePoint(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected point
+	}{
+		{
+			line: "cpu value=1",
+			expected: point{
+				measurement: []byte("cpu"),
+				fields:      []field{{name: []byte("value"), value: 1}},
+			},
+		},
+		{
+			line: "cpu,host=a,region=us-west usage_idle=0.5,usage_user=12i,up=true,count=3u 1465839830100400200",
+			expected: point{
+				measurement: []byte("cpu"),
+				tags: []tag{
+					{name: []byte("host"), value: []byte("a")},
+					{name: []byte("region"), value: []byte("us-west")},
+				},
+				fields: []field{
+					{name: []byte("usage_idle"), value: 0.5},
+					{name: []byte("usage_user"), value: 12},
+					{name: []byte("up"), value: 1},
+					{name: []byte("count"), value: 3},
+				},
+				timestamp:    1465839830100400200,
+				hasTimestamp: true,
+			},
+		},
+		{
+			line: `disk\ io,path=/var\,log,dev\=ice=sd\ a read\ bytes=-1.5e3,down=F`,
+			expected: point{
+				measurement: []byte("disk io"),
+				tags: []tag{
+					{name: []byte("path"), value: []byte("/var,log")},
+					{name: []byte("dev=ice"), value: []byte("sd a")},
+				},
+				fields: []field{
+					{name: []byte("read bytes"), value: -1500},
+					{name: []byte("down"), value: 0},
+				},
+			},
+		},
+		{
+			line: `events,host=a message="disk \"full\", retrying",count=2i 10`,
+			expected: point{
+				measurement:  []byte("events"),
+				tags:         []tag{{name: []byte("host"), value: []byte("a")}},
+				fields:       []field{{name: []byte("count"), value: 2}},
+				timestamp:    10,
+				hasTimestamp: true,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		p, err := parsePoint([]byte(test.line))
+		require.NoError(t, err, test.line)
+		require.Equal(t, test.expected, p, test.line)
+	}
+}
+
+func TestParsePointInvalid(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected string
+	}{
+		{line: ",host=a value=1", expected: errMissingMeasurement.Error()},
+		{line: "cpu,host value=1", expected: errMissingTagKey.Error()},
+		{line: "cpu,host= value=1", expected: errMissingTagValue.Error()},
+		{line: "cpu,host=a", expected: errMissingFields.Error()},
+		{line: "cpu =1", expected: errMissingFieldKey.Error()},
+		{line: "cpu value=", expected: "field value: " + errMissingFieldValue.Error()},
+		{line: "cpu value=abc", expected: "field value: invalid float value abc"},
+		{line: "cpu value=1.5i", expected: "field value: invalid integer value 1.5i"},
+		{line: `cpu value="open`, expected: "field value: " + errUnterminatedString.Error()},
+		{line: "cpu value=1 soon", expected: "invalid timestamp soon"},
+		{line: "cpu value=1 10 20", expected: errTrailingData.Error()},
+	}
+
+	for _, test := range tests {
+		_, err := parsePoint([]byte(test.line))
+		require.Error(t, err, test.line)
+		require.Equal(t, test.expected, err.Error(), test.line)
+	}
+}
+
+func TestParsePrecision(t *testing.T) {
+	for precision, expected := range map[string]time.Duration{
+		"":   time.Nanosecond,
+		"n":  time.Nanosecond,
+		"ns": time.Nanosecond,
+		"u":  time.Microsecond,
+		"us": time.Microsecond,
+		"ms": time.Millisecond,
+		"s":  time.Second,
+	} {
+		unit, err := parsePrecision(precision)
+		require.NoError(t, err)
+		require.Equal(t, expected, unit)
+	}
+
+	_, err := parsePrecision("h")
+	require.Error(t, err)
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package influxdb serves an InfluxDB line protocol write endpoint against
+// the local database, so that Influx clients can write straight to a node.
+package influxdb
+
+import (
+	"net"
+	"net/http"
+
+	ns "github.com/m3db/m3/src/dbnode/network/server"
+	"github.com/m3db/m3/src/dbnode/storage"
+	"github.com/m3db/m3x/context"
+)
+
+type server struct {
+	address     string
+	db          storage.Database
+	contextPool context.Pool
+	opts        Options
+}
+
+// NewServer creates an InfluxDB line protocol write HTTP network service.
+func NewServer(
+	db storage.Database,
+	address string,
+	contextPool context.Pool,
+	opts Options,
+) (ns.NetworkService, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return &server{
+		address:     address,
+		db:          db,
+		contextPool: contextPool,
+		opts:        opts,
+	}, nil
+}
+
+func (s *server) ListenAndServe() (ns.Close, error) {
+	mux := http.NewServeMux()
+	mux.Handle(WriteURL, newWriteHandler(s.db, s.contextPool, s.opts))
+
+	listener, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return nil, err
+	}
+
+	server := http.Server{
+		Handler:      mux,
+		ReadTimeout:  s.opts.ReadTimeout(),
+		WriteTimeout: s.opts.WriteTimeout(),
+	}
+
+	go func() {
+		server.Serve(listener)
+	}()
+
+	return func() {
+		listener.Close()
+	}, nil
+}
@@ -0,0 +1,319 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package influxdb
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/network/server/httpjson"
+	"github.com/m3db/m3/src/dbnode/storage"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3x/clock"
+	"github.com/m3db/m3x/context"
+	xerrors "github.com/m3db/m3x/errors"
+	"github.com/m3db/m3x/ident"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/uber-go/tally"
+)
+
+const (
+	// WriteURL is the path of the InfluxDB line protocol write handler.
+	WriteURL = "/api/v1/influxdb/write"
+
+	// NB: series names are formed the same as by Telegraf's Prometheus output
+	// so that series written through either are the same series.
+	nameTag            = "__name__"
+	nameFieldSeparator = '_'
+	seriesIDTagEq      = '='
+	seriesIDTagSep     = ','
+)
+
+var (
+	errRequestMustBePost = xerrors.NewInvalidParamsError(
+		errors.New("influxdb write request must be POST"))
+	errRequestTooLarge = xerrors.NewInvalidParamsError(
+		errors.New("influxdb write request body is too large"))
+)
+
+// lineError is an error parsing a single line of a write request.
+type lineError struct {
+	line int
+	err  error
+}
+
+func (e lineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.line, e.err)
+}
+
+// seriesBatch is the datapoints of a single series in a write request.
+type seriesBatch struct {
+	id         ident.ID
+	tags       ident.Tags
+	datapoints []ts.Datapoint
+}
+
+type writeHandler struct {
+	db              storage.Database
+	namespace       ident.ID
+	contextPool     context.Pool
+	nowFn           clock.NowFn
+	maxRequestBytes int
+	metrics         writeHandlerMetrics
+}
+
+func newWriteHandler(
+	db storage.Database,
+	contextPool context.Pool,
+	opts Options,
+) http.Handler {
+	scope := opts.InstrumentOptions().MetricsScope().SubScope("write")
+	return &writeHandler{
+		db:              db,
+		namespace:       opts.Namespace(),
+		contextPool:     contextPool,
+		nowFn:           opts.ClockOptions().NowFn(),
+		maxRequestBytes: opts.MaxRequestBytes(),
+		metrics:         newWriteHandlerMetrics(scope),
+	}
+}
+
+func (h *writeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.writeError(w, errRequestMustBePost)
+		return
+	}
+
+	precision, err := parsePrecision(r.URL.Query().Get("precision"))
+	if err != nil {
+		h.writeError(w, xerrors.NewInvalidParamsError(err))
+		return
+	}
+
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gzipReader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			h.writeError(w, xerrors.NewInvalidParamsError(err))
+			return
+		}
+		defer gzipReader.Close()
+		body = gzipReader
+	}
+	defer r.Body.Close()
+
+	batches, lineErrs, err := h.parse(body, precision)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	ctx := h.contextPool.Get()
+	defer ctx.Close()
+
+	if err := h.write(ctx, batches, lineErrs); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.metrics.success.Inc(1)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parse reads the request body a line at a time and groups the datapoints of
+// each valid line by series, lines that fail to parse are returned as errors
+// and do not prevent valid lines from being written.
+func (h *writeHandler) parse(
+	body io.Reader,
+	precision time.Duration,
+) ([]*seriesBatch, []error, error) {
+	var (
+		// NB: read up to one byte more than the limit to detect bodies
+		// that are too large.
+		reader   = bufio.NewReader(io.LimitReader(body, int64(h.maxRequestBytes)+1))
+		now      = h.nowFn()
+		read     int
+		lineNum  int
+		batches  []*seriesBatch
+		byID     = make(map[string]*seriesBatch)
+		lineErrs []error
+	)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, nil, xerrors.NewInvalidParamsError(err)
+		}
+		read += len(line)
+		if read > h.maxRequestBytes {
+			return nil, nil, errRequestTooLarge
+		}
+		lineNum++
+
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 && line[0] != '#' {
+			p, parseErr := parsePoint(line)
+			if parseErr != nil {
+				lineErrs = append(lineErrs, lineError{line: lineNum, err: parseErr})
+			} else {
+				timestamp := now
+				if p.hasTimestamp {
+					timestamp = time.Unix(0, p.timestamp*int64(precision))
+				}
+				for _, f := range p.fields {
+					id, tags := seriesIDAndTags(p.measurement, f.name, p.tags)
+					batch, ok := byID[id.String()]
+					if !ok {
+						batch = &seriesBatch{id: id, tags: tags}
+						byID[id.String()] = batch
+						batches = append(batches, batch)
+					}
+					batch.datapoints = append(batch.datapoints, ts.Datapoint{
+						Timestamp: timestamp,
+						Value:     f.value,
+					})
+				}
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	h.metrics.linesInvalid.Inc(int64(len(lineErrs)))
+	return batches, lineErrs, nil
+}
+
+func (h *writeHandler) write(
+	ctx context.Context,
+	batches []*seriesBatch,
+	lineErrs []error,
+) error {
+	var (
+		multiErr      xerrors.MultiError
+		invalidParams = true
+	)
+	addErr := func(err error) {
+		if err == nil {
+			return
+		}
+		invalidParams = invalidParams && xerrors.IsInvalidParams(err)
+		multiErr = multiErr.Add(err)
+	}
+
+	for _, lineErr := range lineErrs {
+		addErr(xerrors.NewInvalidParamsError(lineErr))
+	}
+
+	for _, batch := range batches {
+		writeErrs, err := h.db.WriteTaggedBatch(ctx, h.namespace, batch.id,
+			ident.NewTagsIterator(batch.tags), batch.datapoints,
+			xtime.Nanosecond, nil)
+		if err != nil {
+			addErr(err)
+			continue
+		}
+		written := len(batch.datapoints)
+		for _, writeErr := range writeErrs {
+			if writeErr != nil {
+				written--
+				addErr(writeErr)
+			}
+		}
+		h.metrics.datapointsWritten.Inc(int64(written))
+	}
+
+	err := multiErr.FinalError()
+	if err != nil && invalidParams {
+		return xerrors.NewInvalidParamsError(err)
+	}
+	return err
+}
+
+func (h *writeHandler) writeError(w http.ResponseWriter, err error) {
+	if xerrors.IsInvalidParams(err) {
+		h.metrics.errorsClient.Inc(1)
+	} else {
+		h.metrics.errorsServer.Inc(1)
+	}
+	if err == errRequestTooLarge {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		return
+	}
+	httpjson.WriteError(w, err)
+}
+
+// seriesIDAndTags returns the ID and tags of the series of a field of a
+// measurement with the given tags.
+func seriesIDAndTags(measurement, fieldName []byte, pointTags []tag) (ident.ID, ident.Tags) {
+	name := make([]byte, 0, len(measurement)+1+len(fieldName))
+	name = append(name, measurement...)
+	name = append(name, nameFieldSeparator)
+	name = append(name, fieldName...)
+
+	sorted := make([]tag, 0, len(pointTags)+1)
+	sorted = append(sorted, tag{name: []byte(nameTag), value: name})
+	sorted = append(sorted, pointTags...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].name, sorted[j].name) < 0
+	})
+
+	// NB: series IDs are formed the same as by the coordinator so that
+	// series written through either are the same series.
+	var (
+		id   []byte
+		tags ident.Tags
+	)
+	for _, t := range sorted {
+		id = append(id, t.name...)
+		id = append(id, seriesIDTagEq)
+		id = append(id, t.value...)
+		id = append(id, seriesIDTagSep)
+		tags.Append(ident.StringTag(string(t.name), string(t.value)))
+	}
+	return ident.BytesID(id), tags
+}
+
+type writeHandlerMetrics struct {
+	success           tally.Counter
+	errorsClient      tally.Counter
+	errorsServer      tally.Counter
+	linesInvalid      tally.Counter
+	datapointsWritten tally.Counter
+}
+
+func newWriteHandlerMetrics(scope tally.Scope) writeHandlerMetrics {
+	return writeHandlerMetrics{
+		success:           scope.Counter("success"),
+		errorsClient:      scope.Tagged(map[string]string{"code": "4XX"}).Counter("errors"),
+		errorsServer:      scope.Tagged(map[string]string{"code": "5XX"}).Counter("errors"),
+		linesInvalid:      scope.Counter("lines-invalid"),
+		datapointsWritten: scope.Counter("datapoints-written"),
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package influxdb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/storage"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3x/clock"
+	"github.com/m3db/m3x/context"
+	"github.com/m3db/m3x/ident"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type testWrites map[string][]ts.Datapoint
+
+func newTestWriteHandler(
+	ctrl *gomock.Controller,
+	now time.Time,
+) (http.Handler, testWrites) {
+	var (
+		nsID   = ident.StringID("metrics")
+		writes = make(testWrites)
+		db     = storage.NewMockDatabase(ctrl)
+		opts   = NewOptions().
+			SetNamespace(nsID).
+			SetMaxRequestBytes(1024).
+			SetClockOptions(clock.NewOptions().SetNowFn(func() time.Time {
+				return now
+			}))
+	)
+	db.EXPECT().
+		WriteTaggedBatch(gomock.Any(), nsID, gomock.Any(), gomock.Any(), gomock.Any(), xtime.Nanosecond, nil).
+		DoAndReturn(func(
+			_ context.Context,
+			_ ident.ID,
+			id ident.ID,
+			_ ident.TagIterator,
+			datapoints []ts.Datapoint,
+			_ xtime.Unit,
+			_ [][]byte,
+		) ([]error, error) {
+			writes[id.String()] = append(writes[id.String()], datapoints...)
+			return nil, nil
+		}).
+		AnyTimes()
+	return newWriteHandler(db, storage.NewOptions().ContextPool(), opts), writes
+}
+
+func TestWriteHandlerWritesValidLines(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Unix(1500000000, 0)
+	handler, writes := newTestWriteHandler(ctrl, now)
+
+	body := strings.Join([]string{
+		"# comment",
+		"cpu,host=a idle=0.5,user=10i 1500000000",
+		"",
+		"cpu,host=a idle=0.25 1500000010",
+		"mem,host=b used=2048u",
+	}, "\n")
+	r := httptest.NewRequest(http.MethodPost, WriteURL+"?precision=s", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	require.Equal(t, testWrites{
+		"__name__=cpu_idle,host=a,": {
+			{Timestamp: time.Unix(1500000000, 0), Value: 0.5},
+			{Timestamp: time.Unix(1500000010, 0), Value: 0.25},
+		},
+		"__name__=cpu_user,host=a,": {
+			{Timestamp: time.Unix(1500000000, 0), Value: 10},
+		},
+		"__name__=mem_used,host=b,": {
+			{Timestamp: now, Value: 2048},
+		},
+	}, writes)
+}
+
+func TestWriteHandlerMixedValidity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler, writes := newTestWriteHandler(ctrl, time.Now())
+
+	body := strings.Join([]string{
+		"cpu,host=a idle=0.5 1500000000000",
+		"cpu,host=a idle=oops 1500000001000",
+		"cpu,host=a idle=0.75 1500000002000",
+		"cpu,host= idle=1 1500000003000",
+	}, "\n")
+	r := httptest.NewRequest(http.MethodPost, WriteURL+"?precision=ms", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "line 2: field idle: invalid float value oops")
+	require.Contains(t, w.Body.String(), "line 4: missing tag value")
+
+	require.Equal(t, testWrites{
+		"__name__=cpu_idle,host=a,": {
+			{Timestamp: time.Unix(1500000000, 0), Value: 0.5},
+			{Timestamp: time.Unix(1500000002, 0), Value: 0.75},
+		},
+	}, writes)
+}
+
+func TestWriteHandlerGzip(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler, writes := newTestWriteHandler(ctrl, time.Now())
+
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	_, err := gzipWriter.Write([]byte("cpu idle=1 1500000000000000000\n"))
+	require.NoError(t, err)
+	require.NoError(t, gzipWriter.Close())
+
+	r := httptest.NewRequest(http.MethodPost, WriteURL, &buf)
+	r.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	require.Equal(t, testWrites{
+		"__name__=cpu_idle,": {
+			{Timestamp: time.Unix(1500000000, 0), Value: 1},
+		},
+	}, writes)
+}
+
+func TestWriteHandlerRejectsInvalidRequests(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler, writes := newTestWriteHandler(ctrl, time.Now())
+
+	r := httptest.NewRequest(http.MethodGet, WriteURL, nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	r = httptest.NewRequest(http.MethodPost, WriteURL+"?precision=h", strings.NewReader("cpu idle=1"))
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	body := strings.Repeat("cpu idle=1\n", 100)
+	r = httptest.NewRequest(http.MethodPost, WriteURL, strings.NewReader(body))
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	require.Equal(t, 0, len(writes))
+}
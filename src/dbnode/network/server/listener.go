@@ -0,0 +1,102 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"io"
+	"net"
+	"sync"
+
+	"github.com/uber-go/tally"
+)
+
+type instrumentedListener struct {
+	net.Listener
+
+	metrics listenerMetrics
+}
+
+type listenerMetrics struct {
+	accepted         tally.Counter
+	acceptErrors     tally.Counter
+	closed           tally.Counter
+	connectionErrors tally.Counter
+}
+
+func newListenerMetrics(scope tally.Scope) listenerMetrics {
+	return listenerMetrics{
+		accepted:         scope.Counter("connections-accepted"),
+		acceptErrors:     scope.Counter("accept-errors"),
+		closed:           scope.Counter("connections-closed"),
+		connectionErrors: scope.Counter("connection-errors"),
+	}
+}
+
+// NewInstrumentedListener returns a listener which reports the connections it
+// accepts and closes, and the errors accepting and reading from or writing to
+// its connections, under the given scope. The scope is expected to be tagged
+// with the name of the listener so that each listener's metrics are distinct.
+func NewInstrumentedListener(l net.Listener, scope tally.Scope) net.Listener {
+	return &instrumentedListener{
+		Listener: l,
+		metrics:  newListenerMetrics(scope),
+	}
+}
+
+func (l *instrumentedListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		l.metrics.acceptErrors.Inc(1)
+		return nil, err
+	}
+	l.metrics.accepted.Inc(1)
+	return &instrumentedConn{Conn: conn, metrics: l.metrics}, nil
+}
+
+type instrumentedConn struct {
+	net.Conn
+
+	metrics   listenerMetrics
+	closeOnce sync.Once
+}
+
+func (c *instrumentedConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if err != nil && err != io.EOF {
+		c.metrics.connectionErrors.Inc(1)
+	}
+	return n, err
+}
+
+func (c *instrumentedConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	if err != nil {
+		c.metrics.connectionErrors.Inc(1)
+	}
+	return n, err
+}
+
+func (c *instrumentedConn) Close() error {
+	c.closeOnce.Do(func() {
+		c.metrics.closed.Inc(1)
+	})
+	return c.Conn.Close()
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func TestInstrumentedListener(t *testing.T) {
+	raw, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	scope := tally.NewTestScope("", nil)
+	l := NewInstrumentedListener(raw, scope.Tagged(map[string]string{"listener": "test"}))
+
+	client, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+	defer client.Close()
+
+	conn, err := l.Accept()
+	require.NoError(t, err)
+
+	_, err = client.Write([]byte("ping"))
+	require.NoError(t, err)
+	buf := make([]byte, 4)
+	_, err = conn.Read(buf)
+	require.NoError(t, err)
+
+	// Closing twice only counts a single closed connection.
+	require.NoError(t, conn.Close())
+	conn.Close()
+	_, err = conn.Read(buf)
+	require.Error(t, err)
+
+	require.NoError(t, l.Close())
+	_, err = l.Accept()
+	require.Error(t, err)
+
+	counters := scope.Snapshot().Counters()
+	for name, expected := range map[string]int64{
+		"connections-accepted": 1,
+		"connections-closed":   1,
+		"connection-errors":    1,
+		"accept-errors":        1,
+	} {
+		counter, ok := counters[name+"+listener=test"]
+		require.True(t, ok, name)
+		require.Equal(t, expected, counter.Value(), name)
+	}
+}
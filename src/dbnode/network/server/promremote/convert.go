@@ -0,0 +1,157 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package promremote
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/generated/proto/prompb"
+	"github.com/m3db/m3/src/dbnode/storage/index"
+	"github.com/m3db/m3/src/dbnode/ts"
+	idxmatchers "github.com/m3db/m3/src/m3ninx/idx/matchers"
+	xerrors "github.com/m3db/m3x/errors"
+	"github.com/m3db/m3x/ident"
+)
+
+const (
+	// NB: series IDs are formed the same as by the coordinator so that
+	// series written through either are the same series.
+	seriesIDTagEq  = '='
+	seriesIDTagSep = ','
+)
+
+var (
+	matcherTypes = map[prompb.LabelMatcher_Type]idxmatchers.Type{
+		prompb.LabelMatcher_EQ:  idxmatchers.EQ,
+		prompb.LabelMatcher_NEQ: idxmatchers.NEQ,
+		prompb.LabelMatcher_RE:  idxmatchers.RE,
+		prompb.LabelMatcher_NRE: idxmatchers.NRE,
+	}
+)
+
+var (
+	errNoLabels      = xerrors.NewInvalidParamsError(errors.New("series has no labels"))
+	errEmptyLabel    = xerrors.NewInvalidParamsError(errors.New("series has a label with an empty name"))
+	errNoMatchers    = xerrors.NewInvalidParamsError(errors.New("query has no matchers"))
+	errInvalidTimes  = xerrors.NewInvalidParamsError(errors.New("query end is before start"))
+	errEmptyMatchers = xerrors.NewInvalidParamsError(errors.New("query has a matcher with an empty name"))
+)
+
+type labelsByName []*prompb.Label
+
+func (l labelsByName) Len() int           { return len(l) }
+func (l labelsByName) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
+func (l labelsByName) Less(i, j int) bool { return l[i].Name < l[j].Name }
+
+// seriesIDAndTags returns the ID and tags of the series with the given
+// labels, the labels are sorted by name in place.
+func seriesIDAndTags(labels []*prompb.Label) (ident.ID, ident.Tags, error) {
+	if len(labels) == 0 {
+		return nil, ident.Tags{}, errNoLabels
+	}
+
+	sort.Sort(labelsByName(labels))
+
+	var (
+		id   []byte
+		tags ident.Tags
+	)
+	for _, label := range labels {
+		if label.Name == "" {
+			return nil, ident.Tags{}, errEmptyLabel
+		}
+		id = append(id, label.Name...)
+		id = append(id, seriesIDTagEq)
+		id = append(id, label.Value...)
+		id = append(id, seriesIDTagSep)
+		tags.Append(ident.StringTag(label.Name, label.Value))
+	}
+	return ident.BytesID(id), tags, nil
+}
+
+// labelsFromTags returns the Prometheus labels of a series with the given tags.
+func labelsFromTags(tags ident.Tags) []*prompb.Label {
+	values := tags.Values()
+	labels := make([]*prompb.Label, 0, len(values))
+	for _, tag := range values {
+		labels = append(labels, &prompb.Label{
+			Name:  tag.Name.String(),
+			Value: tag.Value.String(),
+		})
+	}
+	sort.Sort(labelsByName(labels))
+	return labels
+}
+
+// datapointsFromSamples returns the datapoints of Prometheus samples, the
+// timestamps of samples are in milliseconds.
+func datapointsFromSamples(samples []*prompb.Sample) []ts.Datapoint {
+	datapoints := make([]ts.Datapoint, 0, len(samples))
+	for _, sample := range samples {
+		datapoints = append(datapoints, ts.Datapoint{
+			Timestamp: timeFromMillis(sample.Timestamp),
+			Value:     sample.Value,
+		})
+	}
+	return datapoints
+}
+
+func timeFromMillis(millis int64) time.Time {
+	return time.Unix(0, millis*int64(time.Millisecond))
+}
+
+func millisFromTime(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// queryFromMatchers returns the index query that matches the series selected
+// by Prometheus label matchers.
+func queryFromMatchers(matchers []*prompb.LabelMatcher) (index.Query, error) {
+	if len(matchers) == 0 {
+		return index.Query{}, errNoMatchers
+	}
+
+	ms := make([]idxmatchers.Matcher, 0, len(matchers))
+	for _, matcher := range matchers {
+		if matcher.Name == "" {
+			return index.Query{}, errEmptyMatchers
+		}
+		matcherType, ok := matcherTypes[matcher.Type]
+		if !ok {
+			return index.Query{}, xerrors.NewInvalidParamsError(
+				fmt.Errorf("unknown matcher type: %v", matcher.Type))
+		}
+		ms = append(ms, idxmatchers.Matcher{
+			Type:  matcherType,
+			Name:  []byte(matcher.Name),
+			Value: []byte(matcher.Value),
+		})
+	}
+
+	q, err := idxmatchers.NewQuery(ms...)
+	if err != nil {
+		return index.Query{}, xerrors.NewInvalidParamsError(err)
+	}
+	return index.Query{Query: q}, nil
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package promremote
+
+import (
+	"testing"
+
+	"github.com/m3db/m3/src/dbnode/generated/proto/prompb"
+	"github.com/m3db/m3/src/m3ninx/idx"
+	xerrors "github.com/m3db/m3x/errors"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeriesIDAndTags(t *testing.T) {
+	labels := []*prompb.Label{
+		{Name: "job", Value: "node"},
+		{Name: "__name__", Value: "up"},
+		{Name: "instance", Value: "host-1"},
+	}
+
+	id, tags, err := seriesIDAndTags(labels)
+	require.NoError(t, err)
+	require.Equal(t, "__name__=up,instance=host-1,job=node,", id.String())
+
+	values := tags.Values()
+	require.Equal(t, 3, len(values))
+	require.Equal(t, "__name__", values[0].Name.String())
+	require.Equal(t, "up", values[0].Value.String())
+	require.Equal(t, "job", values[2].Name.String())
+	require.Equal(t, "node", values[2].Value.String())
+
+	require.Equal(t, labels, labelsFromTags(tags))
+}
+
+func TestSeriesIDAndTagsInvalid(t *testing.T) {
+	_, _, err := seriesIDAndTags(nil)
+	require.Equal(t, errNoLabels, err)
+
+	_, _, err = seriesIDAndTags([]*prompb.Label{{Name: "", Value: "foo"}})
+	require.Equal(t, errEmptyLabel, err)
+}
+
+func TestQueryFromMatchers(t *testing.T) {
+	re, err := idx.NewRegexpQuery([]byte("job"), []byte("node.*"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		matcher  *prompb.LabelMatcher
+		expected idx.Query
+	}{
+		{
+			matcher:  &prompb.LabelMatcher{Type: prompb.LabelMatcher_EQ, Name: "job", Value: "node"},
+			expected: idx.MustCreateTermQuery([]byte("job"), []byte("node")),
+		},
+		{
+			matcher:  &prompb.LabelMatcher{Type: prompb.LabelMatcher_EQ, Name: "job"},
+			expected: idx.NewNegationQuery(idx.MustCreateFieldQuery([]byte("job"))),
+		},
+		{
+			matcher:  &prompb.LabelMatcher{Type: prompb.LabelMatcher_NEQ, Name: "job", Value: "node"},
+			expected: idx.NewNegationQuery(idx.MustCreateTermQuery([]byte("job"), []byte("node"))),
+		},
+		{
+			matcher:  &prompb.LabelMatcher{Type: prompb.LabelMatcher_NEQ, Name: "job"},
+			expected: idx.MustCreateFieldQuery([]byte("job")),
+		},
+		{
+			matcher:  &prompb.LabelMatcher{Type: prompb.LabelMatcher_RE, Name: "job", Value: "node.*"},
+			expected: re,
+		},
+		{
+			matcher:  &prompb.LabelMatcher{Type: prompb.LabelMatcher_NRE, Name: "job", Value: "node.*"},
+			expected: idx.NewNegationQuery(re),
+		},
+	}
+
+	for _, test := range tests {
+		q, err := queryFromMatchers([]*prompb.LabelMatcher{test.matcher})
+		require.NoError(t, err)
+		expected := idx.NewConjunctionQuery(idx.NewMatchAllQuery(), test.expected)
+		require.True(t, expected.Equal(q.Query),
+			"expected %s, actual %s", expected.String(), q.Query.String())
+	}
+}
+
+func TestQueryFromMatchersInvalid(t *testing.T) {
+	_, err := queryFromMatchers(nil)
+	require.Equal(t, errNoMatchers, err)
+
+	_, err = queryFromMatchers([]*prompb.LabelMatcher{{Type: prompb.LabelMatcher_EQ, Value: "node"}})
+	require.Equal(t, errEmptyMatchers, err)
+
+	_, err = queryFromMatchers([]*prompb.LabelMatcher{{Type: prompb.LabelMatcher_RE, Name: "job", Value: "("}})
+	require.Error(t, err)
+	require.True(t, xerrors.IsInvalidParams(err))
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package promremote
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/m3db/m3/src/dbnode/network/server/httpjson"
+	xerrors "github.com/m3db/m3x/errors"
+
+	"github.com/golang/snappy"
+	"github.com/uber-go/tally"
+)
+
+const (
+	// WriteURL is the path of the Prometheus remote write handler.
+	WriteURL = "/api/v1/prom/remote/write"
+
+	// ReadURL is the path of the Prometheus remote read handler.
+	ReadURL = "/api/v1/prom/remote/read"
+)
+
+var (
+	errRequestMustBePost = xerrors.NewInvalidParamsError(
+		errors.New("prometheus remote request must be POST"))
+	errEmptyRequestBody = xerrors.NewInvalidParamsError(
+		errors.New("prometheus remote request body is empty"))
+)
+
+type unmarshaler interface {
+	Unmarshal(data []byte) error
+}
+
+// parseRequest decodes the snappy compressed protobuf body of a Prometheus
+// remote request into the given request.
+func parseRequest(r *http.Request, req unmarshaler) error {
+	if r.Method != http.MethodPost {
+		return errRequestMustBePost
+	}
+	if r.Body == nil {
+		return errEmptyRequestBody
+	}
+	defer r.Body.Close()
+
+	compressed, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if len(compressed) == 0 {
+		return errEmptyRequestBody
+	}
+
+	data, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return xerrors.NewInvalidParamsError(
+			fmt.Errorf("unable to decode snappy request body: %v", err))
+	}
+	if err := req.Unmarshal(data); err != nil {
+		return xerrors.NewInvalidParamsError(
+			fmt.Errorf("unable to decode protobuf request body: %v", err))
+	}
+	return nil
+}
+
+type handlerMetrics struct {
+	success      tally.Counter
+	errorsClient tally.Counter
+	errorsServer tally.Counter
+}
+
+func newHandlerMetrics(scope tally.Scope) handlerMetrics {
+	return handlerMetrics{
+		success:      scope.Counter("success"),
+		errorsClient: scope.Tagged(map[string]string{"code": "4XX"}).Counter("errors"),
+		errorsServer: scope.Tagged(map[string]string{"code": "5XX"}).Counter("errors"),
+	}
+}
+
+func (m handlerMetrics) writeError(w http.ResponseWriter, err error) {
+	if xerrors.IsInvalidParams(err) {
+		m.errorsClient.Inc(1)
+	} else {
+		m.errorsServer.Inc(1)
+	}
+	httpjson.WriteError(w, err)
+}
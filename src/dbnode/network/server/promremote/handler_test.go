@@ -0,0 +1,256 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package promremote
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
+	"github.com/m3db/m3/src/dbnode/generated/proto/prompb"
+	"github.com/m3db/m3/src/dbnode/storage"
+	"github.com/m3db/m3/src/dbnode/storage/index"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3/src/dbnode/x/xio"
+	"github.com/m3db/m3/src/m3ninx/doc"
+	"github.com/m3db/m3x/context"
+	"github.com/m3db/m3x/ident"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/require"
+)
+
+type testSeries struct {
+	tags       ident.Tags
+	datapoints []ts.Datapoint
+}
+
+// newTestDatabase returns a mock database that holds written series in memory
+// and returns all of them for any query.
+func newTestDatabase(
+	t *testing.T,
+	ctrl *gomock.Controller,
+	nsID ident.ID,
+) *storage.MockDatabase {
+	var (
+		series   = make(map[string]*testSeries)
+		storOpts = storage.NewOptions()
+		db       = storage.NewMockDatabase(ctrl)
+	)
+	db.EXPECT().Options().Return(storOpts).AnyTimes()
+	db.EXPECT().
+		WriteTaggedBatch(gomock.Any(), nsID, gomock.Any(), gomock.Any(), gomock.Any(), xtime.Millisecond, nil).
+		DoAndReturn(func(
+			_ context.Context,
+			_ ident.ID,
+			id ident.ID,
+			tags ident.TagIterator,
+			datapoints []ts.Datapoint,
+			_ xtime.Unit,
+			_ [][]byte,
+		) ([]error, error) {
+			s, ok := series[id.String()]
+			if !ok {
+				s = &testSeries{}
+				for tags.Next() {
+					tag := tags.Current()
+					s.tags.Append(ident.StringTag(tag.Name.String(), tag.Value.String()))
+				}
+				series[id.String()] = s
+			}
+			s.datapoints = append(s.datapoints, datapoints...)
+			return nil, nil
+		}).
+		AnyTimes()
+	db.EXPECT().
+		QueryIDs(gomock.Any(), nsID, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ ident.ID,
+			_ index.Query,
+			_ index.QueryOptions,
+		) (index.QueryResults, error) {
+			results := index.NewResults(index.NewOptions())
+			for id, s := range series {
+				d := doc.Document{ID: []byte(id)}
+				for _, tag := range s.tags.Values() {
+					d.Fields = append(d.Fields, doc.Field{
+						Name:  tag.Name.Bytes(),
+						Value: tag.Value.Bytes(),
+					})
+				}
+				_, _, err := results.Add(d)
+				require.NoError(t, err)
+			}
+			return index.QueryResults{Results: results, Exhaustive: true}, nil
+		}).
+		AnyTimes()
+	db.EXPECT().
+		ReadEncoded(gomock.Any(), nsID, gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ ident.ID,
+			id ident.ID,
+			start, end time.Time,
+		) ([][]xio.BlockReader, error) {
+			s, ok := series[id.String()]
+			if !ok {
+				return nil, nil
+			}
+			encoder := m3tsz.NewEncoder(start, nil, true, encoding.NewOptions())
+			for _, dp := range s.datapoints {
+				if dp.Timestamp.Before(start) || !dp.Timestamp.Before(end) {
+					continue
+				}
+				if err := encoder.Encode(dp, xtime.Millisecond, nil); err != nil {
+					return nil, err
+				}
+			}
+			stream := encoder.Stream()
+			if stream == nil {
+				return nil, nil
+			}
+			return [][]xio.BlockReader{{{SegmentReader: stream, Start: start}}}, nil
+		}).
+		AnyTimes()
+	return db
+}
+
+func postRequest(
+	t *testing.T,
+	handler http.Handler,
+	url string,
+	req interface {
+		Marshal() ([]byte, error)
+	},
+) *httptest.ResponseRecorder {
+	data, err := req.Marshal()
+	require.NoError(t, err)
+
+	r := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(snappy.Encode(nil, data)))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func TestWriteAndReadRoundTrip(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		nsID  = ident.StringID("metrics")
+		db    = newTestDatabase(t, ctrl, nsID)
+		opts  = NewOptions().SetNamespace(nsID)
+		ctxs  = db.Options().ContextPool()
+		write = newWriteHandler(db, ctxs, opts)
+		read  = newReadHandler(db, ctxs, opts)
+		start = time.Now().Truncate(time.Hour)
+	)
+
+	series := []*prompb.TimeSeries{
+		{
+			Labels: []*prompb.Label{
+				{Name: "job", Value: "node"},
+				{Name: "__name__", Value: "up"},
+			},
+			Samples: []*prompb.Sample{
+				{Timestamp: millisFromTime(start), Value: 1},
+				{Timestamp: millisFromTime(start.Add(time.Second)), Value: 2},
+			},
+		},
+		{
+			Labels: []*prompb.Label{
+				{Name: "__name__", Value: "up"},
+				{Name: "job", Value: "api"},
+			},
+			Samples: []*prompb.Sample{
+				{Timestamp: millisFromTime(start.Add(time.Second)), Value: 3.5},
+			},
+		},
+	}
+	w := postRequest(t, write, WriteURL, &prompb.WriteRequest{Timeseries: series})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = postRequest(t, read, ReadURL, &prompb.ReadRequest{
+		Queries: []*prompb.Query{
+			{
+				StartTimestampMs: millisFromTime(start),
+				EndTimestampMs:   millisFromTime(start.Add(time.Second)),
+				Matchers: []*prompb.LabelMatcher{
+					{Type: prompb.LabelMatcher_EQ, Name: "__name__", Value: "up"},
+				},
+			},
+		},
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "snappy", w.Header().Get("Content-Encoding"))
+
+	data, err := snappy.Decode(nil, w.Body.Bytes())
+	require.NoError(t, err)
+	var resp prompb.ReadResponse
+	require.NoError(t, resp.Unmarshal(data))
+
+	// NB: labels are sorted on write and series are returned in label order.
+	require.Equal(t, 1, len(resp.Results))
+	require.Equal(t, []*prompb.TimeSeries{series[1], series[0]}, resp.Results[0].Timeseries)
+}
+
+func TestHandlersRejectInvalidRequests(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		nsID  = ident.StringID("metrics")
+		db    = newTestDatabase(t, ctrl, nsID)
+		opts  = NewOptions().SetNamespace(nsID)
+		ctxs  = db.Options().ContextPool()
+		write = newWriteHandler(db, ctxs, opts)
+		read  = newReadHandler(db, ctxs, opts)
+	)
+
+	r := httptest.NewRequest(http.MethodGet, WriteURL, nil)
+	w := httptest.NewRecorder()
+	write.ServeHTTP(w, r)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	r = httptest.NewRequest(http.MethodPost, ReadURL, bytes.NewReader([]byte("not snappy")))
+	w = httptest.NewRecorder()
+	read.ServeHTTP(w, r)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = postRequest(t, write, WriteURL, &prompb.WriteRequest{
+		Timeseries: []*prompb.TimeSeries{
+			{Samples: []*prompb.Sample{{Timestamp: 1, Value: 1}}},
+		},
+	})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = postRequest(t, read, ReadURL, &prompb.ReadRequest{
+		Queries: []*prompb.Query{{StartTimestampMs: 2, EndTimestampMs: 1}},
+	})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package promremote
+
+import (
+	"errors"
+	"time"
+
+	"github.com/m3db/m3x/ident"
+	"github.com/m3db/m3x/instrument"
+)
+
+const (
+	defaultReadTimeout    = 10 * time.Second
+	defaultWriteTimeout   = 10 * time.Second
+	defaultFetchSeriesMax = 10000
+)
+
+var (
+	errNamespaceNotSet           = errors.New("namespace not set")
+	errFetchSeriesMaxNotPositive = errors.New("fetch series max must be positive")
+)
+
+// Options is a set of Prometheus remote storage server options.
+type Options interface {
+	// Validate validates the options.
+	Validate() error
+
+	// SetNamespace sets the namespace that all remote writes and reads
+	// are served from.
+	SetNamespace(value ident.ID) Options
+
+	// Namespace returns the namespace that all remote writes and reads
+	// are served from.
+	Namespace() ident.ID
+
+	// SetInstrumentOptions sets the instrumentation options.
+	SetInstrumentOptions(value instrument.Options) Options
+
+	// InstrumentOptions returns the instrumentation options.
+	InstrumentOptions() instrument.Options
+
+	// SetReadTimeout sets the read timeout of the HTTP server.
+	SetReadTimeout(value time.Duration) Options
+
+	// ReadTimeout returns the read timeout of the HTTP server.
+	ReadTimeout() time.Duration
+
+	// SetWriteTimeout sets the write timeout of the HTTP server.
+	SetWriteTimeout(value time.Duration) Options
+
+	// WriteTimeout returns the write timeout of the HTTP server.
+	WriteTimeout() time.Duration
+
+	// SetFetchSeriesMax sets the maximum number of series a single remote
+	// read query returns.
+	SetFetchSeriesMax(value int) Options
+
+	// FetchSeriesMax returns the maximum number of series a single remote
+	// read query returns.
+	FetchSeriesMax() int
+}
+
+type options struct {
+	namespace      ident.ID
+	instrumentOpts instrument.Options
+	readTimeout    time.Duration
+	writeTimeout   time.Duration
+	fetchSeriesMax int
+}
+
+// NewOptions creates a new set of Prometheus remote storage server options.
+func NewOptions() Options {
+	return &options{
+		instrumentOpts: instrument.NewOptions(),
+		readTimeout:    defaultReadTimeout,
+		writeTimeout:   defaultWriteTimeout,
+		fetchSeriesMax: defaultFetchSeriesMax,
+	}
+}
+
+func (o *options) Validate() error {
+	if o.namespace == nil || len(o.namespace.Bytes()) == 0 {
+		return errNamespaceNotSet
+	}
+	if o.fetchSeriesMax <= 0 {
+		return errFetchSeriesMaxNotPositive
+	}
+	return nil
+}
+
+func (o *options) SetNamespace(value ident.ID) Options {
+	opts := *o
+	opts.namespace = value
+	return &opts
+}
+
+func (o *options) Namespace() ident.ID {
+	return o.namespace
+}
+
+func (o *options) SetInstrumentOptions(value instrument.Options) Options {
+	opts := *o
+	opts.instrumentOpts = value
+	return &opts
+}
+
+func (o *options) InstrumentOptions() instrument.Options {
+	return o.instrumentOpts
+}
+
+func (o *options) SetReadTimeout(value time.Duration) Options {
+	opts := *o
+	opts.readTimeout = value
+	return &opts
+}
+
+func (o *options) ReadTimeout() time.Duration {
+	return o.readTimeout
+}
+
+func (o *options) SetWriteTimeout(value time.Duration) Options {
+	opts := *o
+	opts.writeTimeout = value
+	return &opts
+}
+
+func (o *options) WriteTimeout() time.Duration {
+	return o.writeTimeout
+}
+
+func (o *options) SetFetchSeriesMax(value int) Options {
+	opts := *o
+	opts.fetchSeriesMax = value
+	return &opts
+}
+
+func (o *options) FetchSeriesMax() int {
+	return o.fetchSeriesMax
+}
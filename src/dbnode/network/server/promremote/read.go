@@ -0,0 +1,193 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package promremote
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/generated/proto/prompb"
+	"github.com/m3db/m3/src/dbnode/storage"
+	"github.com/m3db/m3/src/dbnode/storage/index"
+	"github.com/m3db/m3/src/dbnode/x/xio"
+	"github.com/m3db/m3x/context"
+	xerrors "github.com/m3db/m3x/errors"
+	"github.com/m3db/m3x/ident"
+
+	"github.com/golang/snappy"
+)
+
+var (
+	errFetchSeriesMaxExceeded = xerrors.NewInvalidParamsError(
+		errors.New("query matched more series than the fetch series max"))
+)
+
+type readHandler struct {
+	db             storage.Database
+	namespace      ident.ID
+	contextPool    context.Pool
+	fetchSeriesMax int
+	metrics        handlerMetrics
+}
+
+func newReadHandler(
+	db storage.Database,
+	contextPool context.Pool,
+	opts Options,
+) http.Handler {
+	scope := opts.InstrumentOptions().MetricsScope().SubScope("read")
+	return &readHandler{
+		db:             db,
+		namespace:      opts.Namespace(),
+		contextPool:    contextPool,
+		fetchSeriesMax: opts.FetchSeriesMax(),
+		metrics:        newHandlerMetrics(scope),
+	}
+}
+
+func (h *readHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req prompb.ReadRequest
+	if err := parseRequest(r, &req); err != nil {
+		h.metrics.writeError(w, err)
+		return
+	}
+
+	ctx := h.contextPool.Get()
+	defer ctx.Close()
+
+	resp := &prompb.ReadResponse{
+		Results: make([]*prompb.QueryResult, 0, len(req.Queries)),
+	}
+	for _, q := range req.Queries {
+		result, err := h.read(ctx, q)
+		if err != nil {
+			h.metrics.writeError(w, err)
+			return
+		}
+		resp.Results = append(resp.Results, result)
+	}
+
+	data, err := resp.Marshal()
+	if err != nil {
+		h.metrics.writeError(w, err)
+		return
+	}
+
+	h.metrics.success.Inc(1)
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Header().Set("Content-Encoding", "snappy")
+	w.Write(snappy.Encode(nil, data))
+}
+
+func (h *readHandler) read(ctx context.Context, q *prompb.Query) (*prompb.QueryResult, error) {
+	if q.EndTimestampMs < q.StartTimestampMs {
+		return nil, errInvalidTimes
+	}
+
+	query, err := queryFromMatchers(q.Matchers)
+	if err != nil {
+		return nil, err
+	}
+
+	// NB: the end of Prometheus queries is inclusive.
+	var (
+		start = timeFromMillis(q.StartTimestampMs)
+		end   = timeFromMillis(q.EndTimestampMs).Add(time.Millisecond)
+	)
+	queryResult, err := h.db.QueryIDs(ctx, h.namespace, query, index.QueryOptions{
+		StartInclusive: start,
+		EndExclusive:   end,
+		Limit:          h.fetchSeriesMax,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !queryResult.Exhaustive {
+		return nil, errFetchSeriesMaxExceeded
+	}
+
+	entries := queryResult.Results.Map().Iter()
+	result := &prompb.QueryResult{
+		Timeseries: make([]*prompb.TimeSeries, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		samples, err := h.readSamples(ctx, entry.Key(), start, end)
+		if err != nil {
+			return nil, err
+		}
+		if len(samples) == 0 {
+			continue
+		}
+		result.Timeseries = append(result.Timeseries, &prompb.TimeSeries{
+			Labels:  labelsFromTags(entry.Value()),
+			Samples: samples,
+		})
+	}
+
+	// Return series in a deterministic order.
+	sort.Slice(result.Timeseries, func(i, j int) bool {
+		return compareLabels(result.Timeseries[i].Labels, result.Timeseries[j].Labels) < 0
+	})
+	return result, nil
+}
+
+func (h *readHandler) readSamples(
+	ctx context.Context,
+	id ident.ID,
+	start, end time.Time,
+) ([]*prompb.Sample, error) {
+	encoded, err := h.db.ReadEncoded(ctx, h.namespace, id, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	iter := h.db.Options().MultiReaderIteratorPool().Get()
+	iter.ResetSliceOfSlices(xio.NewReaderSliceOfSlicesFromBlockReadersIterator(encoded))
+	defer iter.Close()
+
+	var samples []*prompb.Sample
+	for iter.Next() {
+		dp, _, _ := iter.Current()
+		samples = append(samples, &prompb.Sample{
+			Timestamp: millisFromTime(dp.Timestamp),
+			Value:     dp.Value,
+		})
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
+
+func compareLabels(a, b []*prompb.Label) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := strings.Compare(a[i].Name, b[i].Name); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a[i].Value, b[i].Value); c != 0 {
+			return c
+		}
+	}
+	return len(a) - len(b)
+}
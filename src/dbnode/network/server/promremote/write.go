@@ -0,0 +1,117 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package promremote
+
+import (
+	"net/http"
+
+	"github.com/m3db/m3/src/dbnode/generated/proto/prompb"
+	"github.com/m3db/m3/src/dbnode/storage"
+	"github.com/m3db/m3x/context"
+	xerrors "github.com/m3db/m3x/errors"
+	"github.com/m3db/m3x/ident"
+	xtime "github.com/m3db/m3x/time"
+)
+
+type writeHandler struct {
+	db          storage.Database
+	namespace   ident.ID
+	contextPool context.Pool
+	metrics     handlerMetrics
+}
+
+func newWriteHandler(
+	db storage.Database,
+	contextPool context.Pool,
+	opts Options,
+) http.Handler {
+	scope := opts.InstrumentOptions().MetricsScope().SubScope("write")
+	return &writeHandler{
+		db:          db,
+		namespace:   opts.Namespace(),
+		contextPool: contextPool,
+		metrics:     newHandlerMetrics(scope),
+	}
+}
+
+func (h *writeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req prompb.WriteRequest
+	if err := parseRequest(r, &req); err != nil {
+		h.metrics.writeError(w, err)
+		return
+	}
+
+	ctx := h.contextPool.Get()
+	defer ctx.Close()
+
+	if err := h.write(ctx, &req); err != nil {
+		h.metrics.writeError(w, err)
+		return
+	}
+
+	h.metrics.success.Inc(1)
+	w.WriteHeader(http.StatusOK)
+}
+
+// write writes the samples of each series as a single batch, the error
+// returned is only an invalid params error if every failed write was.
+func (h *writeHandler) write(ctx context.Context, req *prompb.WriteRequest) error {
+	var (
+		multiErr      xerrors.MultiError
+		invalidParams = true
+	)
+	addErr := func(err error) {
+		if err == nil {
+			return
+		}
+		invalidParams = invalidParams && xerrors.IsInvalidParams(err)
+		multiErr = multiErr.Add(err)
+	}
+
+	for _, series := range req.Timeseries {
+		if len(series.Samples) == 0 {
+			continue
+		}
+
+		id, tags, err := seriesIDAndTags(series.Labels)
+		if err != nil {
+			addErr(err)
+			continue
+		}
+
+		writeErrs, err := h.db.WriteTaggedBatch(ctx, h.namespace, id,
+			ident.NewTagsIterator(tags), datapointsFromSamples(series.Samples),
+			xtime.Millisecond, nil)
+		if err != nil {
+			addErr(err)
+			continue
+		}
+		for _, writeErr := range writeErrs {
+			addErr(writeErr)
+		}
+	}
+
+	err := multiErr.FinalError()
+	if err != nil && invalidParams {
+		return xerrors.NewInvalidParamsError(err)
+	}
+	return err
+}
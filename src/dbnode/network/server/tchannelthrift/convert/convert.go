@@ -28,11 +28,14 @@ import (
 	"github.com/m3db/m3/src/dbnode/digest"
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	tterrors "github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/errors"
+	m3dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
 	"github.com/m3db/m3/src/dbnode/storage/index"
 	"github.com/m3db/m3/src/dbnode/x/xio"
 	"github.com/m3db/m3/src/dbnode/x/xpool"
 	"github.com/m3db/m3/src/m3ninx/generated/proto/querypb"
 	"github.com/m3db/m3/src/m3ninx/idx"
+	m3ninxindex "github.com/m3db/m3/src/m3ninx/index"
+	"github.com/m3db/m3/src/m3ninx/search"
 	"github.com/m3db/m3x/checked"
 	xerrors "github.com/m3db/m3x/errors"
 	"github.com/m3db/m3x/ident"
@@ -44,11 +47,19 @@ var (
 	errUnknownUnit      = errors.New("unknown unit")
 	errNilTaggedRequest = errors.New("nil write tagged request")
 
+	errNilFetchTaggedPageRequest  = errors.New("nil fetch tagged page request")
+	errFetchTaggedPageSizeInvalid = errors.New("fetch tagged page size must be positive")
+
+	errQueryTooManyTermsMatched = fmt.Errorf(
+		"%v: narrow the query, e.g. by anchoring regexps to a literal prefix",
+		m3ninxindex.ErrTooManyTermsMatched)
+
 	timeZero time.Time
 )
 
 const (
-	fetchTaggedTimeType = rpc.TimeType_UNIX_NANOSECONDS
+	fetchTaggedTimeType  = rpc.TimeType_UNIX_NANOSECONDS
+	fetchTaggedTraceName = "fetchTagged"
 )
 
 // ToTime converts a value to a time
@@ -190,6 +201,18 @@ func ToRPCError(err error) *rpc.Error {
 	if xerrors.IsInvalidParams(err) {
 		return tterrors.NewBadRequestError(err)
 	}
+	if m3dberrors.IsResourceExhausted(err) {
+		return tterrors.NewResourceExhaustedError(err)
+	}
+	if m3dberrors.IsDeadlineExceeded(err) {
+		return tterrors.NewDeadlineExceededError(err)
+	}
+	if m3dberrors.IsShardUnavailable(err) {
+		return tterrors.NewShardUnavailableError(err)
+	}
+	if err == m3ninxindex.ErrTooManyTermsMatched {
+		return tterrors.NewBadRequestError(errQueryTooManyTermsMatched)
+	}
 	return tterrors.NewInternalError(err)
 }
 
@@ -223,6 +246,15 @@ func FromRPCFetchTaggedRequest(
 	if l := req.Limit; l != nil {
 		opts.Limit = int(*l)
 	}
+	if l := req.SeriesLimit; l != nil {
+		opts.SeriesLimit = int(*l)
+	}
+	if l := req.DocsLimit; l != nil {
+		opts.DocsLimit = int(*l)
+	}
+	if req.GetTrace() {
+		opts.Trace = search.NewTrace(fetchTaggedTraceName)
+	}
 
 	q, err := idx.Unmarshal(req.Query)
 	if err != nil {
@@ -239,6 +271,135 @@ func FromRPCFetchTaggedRequest(
 	return ns, index.Query{Query: q}, opts, req.FetchData, nil
 }
 
+// FromRPCFetchTaggedPageRequest converts the rpc request type for FetchTaggedPageRequest into
+// corresponding Go API types.
+func FromRPCFetchTaggedPageRequest(
+	req *rpc.FetchTaggedPageRequest, pools FetchTaggedConversionPools,
+) (ident.ID, index.Query, index.QueryOptions, bool, error) {
+	if req.Request == nil {
+		return nil, index.Query{}, index.QueryOptions{}, false, errNilFetchTaggedPageRequest
+	}
+	if req.PageSize <= 0 {
+		return nil, index.Query{}, index.QueryOptions{}, false, errFetchTaggedPageSizeInvalid
+	}
+
+	ns, q, opts, fetchData, err := FromRPCFetchTaggedRequest(req.Request, pools)
+	if err != nil {
+		return nil, index.Query{}, index.QueryOptions{}, false, err
+	}
+	opts.PageSize = int(req.PageSize)
+	opts.PageToken = req.PageToken
+	return ns, q, opts, fetchData, nil
+}
+
+// FromRPCAggregateQueryRequest converts the rpc request type for AggregateQueryRequest
+// into corresponding Go types.
+func FromRPCAggregateQueryRequest(
+	req *rpc.AggregateQueryRequest, pools FetchTaggedConversionPools,
+) (ident.ID, index.Query, index.QueryOptions, error) {
+	start, rangeStartErr := ToTime(req.RangeStart, req.RangeTimeType)
+	if rangeStartErr != nil {
+		return nil, index.Query{}, index.QueryOptions{}, rangeStartErr
+	}
+
+	end, rangeEndErr := ToTime(req.RangeEnd, req.RangeTimeType)
+	if rangeEndErr != nil {
+		return nil, index.Query{}, index.QueryOptions{}, rangeEndErr
+	}
+
+	opts := index.QueryOptions{
+		StartInclusive: start,
+		EndExclusive:   end,
+	}
+	if l := req.Limit; l != nil {
+		opts.Limit = int(*l)
+	}
+
+	q, err := idx.Unmarshal(req.Query)
+	if err != nil {
+		return nil, index.Query{}, index.QueryOptions{}, err
+	}
+
+	var ns ident.ID
+	if pools != nil {
+		nsBytes := pools.CheckedBytesWrapper().Get(req.NameSpace)
+		ns = pools.ID().BinaryID(nsBytes)
+	} else {
+		ns = ident.StringID(string(req.NameSpace))
+	}
+	return ns, index.Query{Query: q}, opts, nil
+}
+
+// FromRPCAggregateTagValuesRequest converts the rpc request type for AggregateTagValuesRequest
+// into corresponding Go types.
+func FromRPCAggregateTagValuesRequest(
+	req *rpc.AggregateTagValuesRequest, pools FetchTaggedConversionPools,
+) (ident.ID, index.AggregateTagValuesOptions, error) {
+	start, rangeStartErr := ToTime(req.RangeStart, req.RangeTimeType)
+	if rangeStartErr != nil {
+		return nil, index.AggregateTagValuesOptions{}, rangeStartErr
+	}
+
+	end, rangeEndErr := ToTime(req.RangeEnd, req.RangeTimeType)
+	if rangeEndErr != nil {
+		return nil, index.AggregateTagValuesOptions{}, rangeEndErr
+	}
+
+	opts := index.AggregateTagValuesOptions{
+		StartInclusive: start,
+		EndExclusive:   end,
+		TagName:        req.TagName,
+		TagValuePrefix: req.TagValuePrefix,
+		Cursor:         req.Cursor,
+	}
+	if l := req.Limit; l != nil {
+		opts.Limit = int(*l)
+	}
+
+	var ns ident.ID
+	if pools != nil {
+		nsBytes := pools.CheckedBytesWrapper().Get(req.NameSpace)
+		ns = pools.ID().BinaryID(nsBytes)
+	} else {
+		ns = ident.StringID(string(req.NameSpace))
+	}
+	return ns, opts, nil
+}
+
+// ToRPCAggregateTagValuesRequest converts the Go `client/` types into rpc request type
+// for AggregateTagValuesRequest.
+func ToRPCAggregateTagValuesRequest(
+	ns ident.ID,
+	opts index.AggregateTagValuesOptions,
+) (rpc.AggregateTagValuesRequest, error) {
+	rangeStart, tsErr := ToValue(opts.StartInclusive, fetchTaggedTimeType)
+	if tsErr != nil {
+		return rpc.AggregateTagValuesRequest{}, tsErr
+	}
+
+	rangeEnd, tsErr := ToValue(opts.EndExclusive, fetchTaggedTimeType)
+	if tsErr != nil {
+		return rpc.AggregateTagValuesRequest{}, tsErr
+	}
+
+	request := rpc.AggregateTagValuesRequest{
+		NameSpace:      ns.Bytes(),
+		TagName:        opts.TagName,
+		RangeStart:     rangeStart,
+		RangeEnd:       rangeEnd,
+		RangeTimeType:  fetchTaggedTimeType,
+		TagValuePrefix: opts.TagValuePrefix,
+		Cursor:         opts.Cursor,
+	}
+
+	if opts.Limit > 0 {
+		l := int64(opts.Limit)
+		request.Limit = &l
+	}
+
+	return request, nil
+}
+
 // ToRPCFetchTaggedRequest converts the Go `client/` types into rpc request type for FetchTaggedRequest.
 func ToRPCFetchTaggedRequest(
 	ns ident.ID,
@@ -273,10 +434,53 @@ func ToRPCFetchTaggedRequest(
 		l := int64(opts.Limit)
 		request.Limit = &l
 	}
+	if opts.SeriesLimit > 0 {
+		l := int64(opts.SeriesLimit)
+		request.SeriesLimit = &l
+	}
+	if opts.DocsLimit > 0 {
+		l := int64(opts.DocsLimit)
+		request.DocsLimit = &l
+	}
+	if opts.Trace != nil {
+		trace := true
+		request.Trace = &trace
+	}
 
 	return request, nil
 }
 
+// ToRPCQueryTrace converts a search trace into its rpc representation.
+func ToRPCQueryTrace(t *search.Trace) *rpc.QueryTrace {
+	trace := &rpc.QueryTrace{
+		Name:            t.Name,
+		TermsScanned:    t.TermsScanned,
+		PostingsDecoded: t.PostingsDecoded,
+		DocsEmitted:     t.DocsEmitted,
+		DurationNanos:   int64(t.Duration),
+		Children:        make([]*rpc.QueryTrace, 0, len(t.Children)),
+	}
+	for _, child := range t.Children {
+		trace.Children = append(trace.Children, ToRPCQueryTrace(child))
+	}
+	return trace
+}
+
+// FromRPCQueryTrace converts an rpc query trace into a search trace.
+func FromRPCQueryTrace(t *rpc.QueryTrace) *search.Trace {
+	trace := &search.Trace{
+		Name:            t.Name,
+		TermsScanned:    t.TermsScanned,
+		PostingsDecoded: t.PostingsDecoded,
+		DocsEmitted:     t.DocsEmitted,
+		Duration:        time.Duration(t.DurationNanos),
+	}
+	for _, child := range t.Children {
+		trace.Children = append(trace.Children, FromRPCQueryTrace(child))
+	}
+	return trace
+}
+
 // ToTagsIter returns a tag iterator over the given request.
 func ToTagsIter(r *rpc.WriteTaggedRequest) (ident.TagIterator, error) {
 	if r == nil {
@@ -27,9 +27,13 @@ import (
 
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/convert"
+	tterrors "github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/errors"
+	m3dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
 	"github.com/m3db/m3/src/dbnode/storage/index"
 	"github.com/m3db/m3/src/dbnode/x/xpool"
 	"github.com/m3db/m3/src/m3ninx/idx"
+	m3ninxindex "github.com/m3db/m3/src/m3ninx/index"
+	"github.com/m3db/m3/src/m3ninx/search"
 	"github.com/m3db/m3x/ident"
 	"github.com/m3db/m3x/pool"
 
@@ -45,7 +49,7 @@ func mustToRpcTime(t *testing.T, ts time.Time) int64 {
 }
 
 func termQueryTestCase(t *testing.T) (idx.Query, []byte) {
-	q1 := idx.NewTermQuery([]byte("dat"), []byte("baz"))
+	q1 := idx.MustCreateTermQuery([]byte("dat"), []byte("baz"))
 	data, err := idx.Marshal(q1)
 	require.NoError(t, err)
 	return q1, data
@@ -60,7 +64,7 @@ func regexpQueryTestCase(t *testing.T) (idx.Query, []byte) {
 }
 
 func negateTermQueryTestCase(t *testing.T) (idx.Query, []byte) {
-	q3 := idx.NewNegationQuery(idx.NewTermQuery([]byte("foo"), []byte("bar")))
+	q3 := idx.NewNegationQuery(idx.MustCreateTermQuery([]byte("foo"), []byte("bar")))
 	data, err := idx.Marshal(q3)
 	require.NoError(t, err)
 	return q3, data
@@ -150,6 +154,73 @@ func TestConvertFetchTaggedRequest(t *testing.T) {
 	}
 }
 
+func TestConvertFetchTaggedRequestTrace(t *testing.T) {
+	q, _ := termQueryTestCase(t)
+	opts := index.QueryOptions{
+		StartInclusive: time.Unix(0, 0),
+		EndExclusive:   time.Unix(0, 0).Add(time.Hour),
+		Trace:          search.NewTrace("test"),
+	}
+
+	req, err := convert.ToRPCFetchTaggedRequest(ident.StringID("abc"), index.Query{Query: q}, opts, false)
+	require.NoError(t, err)
+	require.True(t, req.GetTrace())
+
+	_, _, observedOpts, _, err := convert.FromRPCFetchTaggedRequest(&req, nil)
+	require.NoError(t, err)
+	require.NotNil(t, observedOpts.Trace)
+
+	req.Trace = nil
+	_, _, observedOpts, _, err = convert.FromRPCFetchTaggedRequest(&req, nil)
+	require.NoError(t, err)
+	require.Nil(t, observedOpts.Trace)
+}
+
+func TestConvertFetchTaggedRequestLimits(t *testing.T) {
+	q, _ := termQueryTestCase(t)
+	opts := index.QueryOptions{
+		StartInclusive: time.Unix(0, 0),
+		EndExclusive:   time.Unix(0, 0).Add(time.Hour),
+		SeriesLimit:    100,
+		DocsLimit:      1000,
+	}
+
+	req, err := convert.ToRPCFetchTaggedRequest(ident.StringID("abc"), index.Query{Query: q}, opts, false)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), req.GetSeriesLimit())
+	require.Equal(t, int64(1000), req.GetDocsLimit())
+
+	_, _, observedOpts, _, err := convert.FromRPCFetchTaggedRequest(&req, nil)
+	require.NoError(t, err)
+	require.Equal(t, 100, observedOpts.SeriesLimit)
+	require.Equal(t, 1000, observedOpts.DocsLimit)
+
+	// Unset limits are left unset so that the node defaults apply.
+	opts.SeriesLimit, opts.DocsLimit = 0, 0
+	req, err = convert.ToRPCFetchTaggedRequest(ident.StringID("abc"), index.Query{Query: q}, opts, false)
+	require.NoError(t, err)
+	require.False(t, req.IsSetSeriesLimit())
+	require.False(t, req.IsSetDocsLimit())
+}
+
+func TestConvertQueryTrace(t *testing.T) {
+	trace := search.NewTrace("root")
+	trace.TermsScanned = 10
+	trace.PostingsDecoded = 20
+	trace.DocsEmitted = 5
+	trace.Duration = time.Millisecond
+	child := trace.NewChild("child")
+	child.TermsScanned = 1
+	child.NewChild("grandchild").DocsEmitted = 2
+
+	rpcTrace := convert.ToRPCQueryTrace(trace)
+	require.Equal(t, "root", rpcTrace.Name)
+	require.Equal(t, int64(time.Millisecond), rpcTrace.DurationNanos)
+	require.Len(t, rpcTrace.Children, 1)
+	require.Equal(t, "grandchild", rpcTrace.Children[0].Children[0].Name)
+	require.Equal(t, trace, convert.FromRPCQueryTrace(rpcTrace))
+}
+
 type testPools struct {
 	id      ident.Pool
 	wrapper xpool.CheckedBytesWrapperPool
@@ -171,3 +242,48 @@ var _ convert.FetchTaggedConversionPools = &testPools{}
 
 func (t *testPools) ID() ident.Pool                                     { return t.id }
 func (t *testPools) CheckedBytesWrapper() xpool.CheckedBytesWrapperPool { return t.wrapper }
+
+func TestToRPCErrorWriteTimeBoundsAreBadRequests(t *testing.T) {
+	for _, err := range []error{m3dberrors.ErrTooPast, m3dberrors.ErrTooFuture} {
+		rpcErr := convert.ToRPCError(err)
+		require.NotNil(t, rpcErr)
+		assert.True(t, tterrors.IsBadRequestError(rpcErr))
+		assert.Equal(t, err.Error(), rpcErr.Message)
+	}
+
+	rpcErr := convert.ToRPCError(fmt.Errorf("internal"))
+	require.NotNil(t, rpcErr)
+	assert.True(t, tterrors.IsInternalError(rpcErr))
+}
+
+func TestToRPCErrorClassification(t *testing.T) {
+	inner := fmt.Errorf("inner")
+
+	rpcErr := convert.ToRPCError(m3dberrors.NewResourceExhaustedError(inner))
+	require.NotNil(t, rpcErr)
+	assert.True(t, tterrors.IsResourceExhaustedError(rpcErr))
+	assert.Equal(t, inner.Error(), rpcErr.Message)
+
+	rpcErr = convert.ToRPCError(m3dberrors.NewDeadlineExceededError(inner))
+	require.NotNil(t, rpcErr)
+	assert.True(t, tterrors.IsDeadlineExceededError(rpcErr))
+	assert.Equal(t, inner.Error(), rpcErr.Message)
+
+	rpcErr = convert.ToRPCError(m3dberrors.NewShardUnavailableError(inner))
+	require.NotNil(t, rpcErr)
+	assert.True(t, tterrors.IsShardUnavailableError(rpcErr))
+	assert.Equal(t, inner.Error(), rpcErr.Message)
+
+	rpcErr = convert.ToRPCError(m3dberrors.NewInternalError(inner))
+	require.NotNil(t, rpcErr)
+	assert.True(t, tterrors.IsInternalError(rpcErr))
+	assert.Equal(t, inner.Error(), rpcErr.Message)
+}
+
+func TestToRPCErrorTooManyTermsMatchedIsBadRequest(t *testing.T) {
+	rpcErr := convert.ToRPCError(m3ninxindex.ErrTooManyTermsMatched)
+	require.NotNil(t, rpcErr)
+	assert.True(t, tterrors.IsBadRequestError(rpcErr))
+	assert.Contains(t, rpcErr.Message, m3ninxindex.ErrTooManyTermsMatched.Error())
+	assert.Contains(t, rpcErr.Message, "narrow the query")
+}
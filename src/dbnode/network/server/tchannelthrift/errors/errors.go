@@ -43,6 +43,29 @@ func IsBadRequestError(err *rpc.Error) bool {
 	return err != nil && err.Type == rpc.ErrorType_BAD_REQUEST
 }
 
+// IsReadOnlyError returns whether the error is a read only error
+func IsReadOnlyError(err *rpc.Error) bool {
+	return err != nil && err.Type == rpc.ErrorType_READ_ONLY
+}
+
+// IsResourceExhaustedError returns whether the error is a resource exhausted
+// error
+func IsResourceExhaustedError(err *rpc.Error) bool {
+	return err != nil && err.Type == rpc.ErrorType_RESOURCE_EXHAUSTED
+}
+
+// IsDeadlineExceededError returns whether the error is a deadline exceeded
+// error
+func IsDeadlineExceededError(err *rpc.Error) bool {
+	return err != nil && err.Type == rpc.ErrorType_DEADLINE_EXCEEDED
+}
+
+// IsShardUnavailableError returns whether the error is a shard unavailable
+// error
+func IsShardUnavailableError(err *rpc.Error) bool {
+	return err != nil && err.Type == rpc.ErrorType_SHARD_UNAVAILABLE
+}
+
 // NewInternalError creates a new internal error
 func NewInternalError(err error) *rpc.Error {
 	return newError(rpc.ErrorType_INTERNAL_ERROR, err)
@@ -53,6 +76,26 @@ func NewBadRequestError(err error) *rpc.Error {
 	return newError(rpc.ErrorType_BAD_REQUEST, err)
 }
 
+// NewReadOnlyError creates a new read only error
+func NewReadOnlyError(err error) *rpc.Error {
+	return newError(rpc.ErrorType_READ_ONLY, err)
+}
+
+// NewResourceExhaustedError creates a new resource exhausted error
+func NewResourceExhaustedError(err error) *rpc.Error {
+	return newError(rpc.ErrorType_RESOURCE_EXHAUSTED, err)
+}
+
+// NewDeadlineExceededError creates a new deadline exceeded error
+func NewDeadlineExceededError(err error) *rpc.Error {
+	return newError(rpc.ErrorType_DEADLINE_EXCEEDED, err)
+}
+
+// NewShardUnavailableError creates a new shard unavailable error
+func NewShardUnavailableError(err error) *rpc.Error {
+	return newError(rpc.ErrorType_SHARD_UNAVAILABLE, err)
+}
+
 // NewWriteBatchRawError creates a new write batch error
 func NewWriteBatchRawError(index int, err error) *rpc.WriteBatchRawError {
 	batchErr := rpc.NewWriteBatchRawError()
@@ -68,3 +111,29 @@ func NewBadRequestWriteBatchRawError(index int, err error) *rpc.WriteBatchRawErr
 	batchErr.Err = NewBadRequestError(err)
 	return batchErr
 }
+
+// NewReadOnlyWriteBatchRawError creates a new read only write batch error
+func NewReadOnlyWriteBatchRawError(index int, err error) *rpc.WriteBatchRawError {
+	batchErr := rpc.NewWriteBatchRawError()
+	batchErr.Index = int64(index)
+	batchErr.Err = NewReadOnlyError(err)
+	return batchErr
+}
+
+// NewResourceExhaustedWriteBatchRawError creates a new resource exhausted
+// write batch error
+func NewResourceExhaustedWriteBatchRawError(index int, err error) *rpc.WriteBatchRawError {
+	batchErr := rpc.NewWriteBatchRawError()
+	batchErr.Index = int64(index)
+	batchErr.Err = NewResourceExhaustedError(err)
+	return batchErr
+}
+
+// NewShardUnavailableWriteBatchRawError creates a new shard unavailable write
+// batch error
+func NewShardUnavailableWriteBatchRawError(index int, err error) *rpc.WriteBatchRawError {
+	batchErr := rpc.NewWriteBatchRawError()
+	batchErr.Index = int64(index)
+	batchErr.Err = NewShardUnavailableError(err)
+	return batchErr
+}
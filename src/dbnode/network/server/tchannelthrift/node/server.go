@@ -21,6 +21,8 @@
 package node
 
 import (
+	"net"
+
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
 	ns "github.com/m3db/m3/src/dbnode/network/server"
 	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift"
@@ -34,6 +36,7 @@ import (
 type server struct {
 	db          storage.Database
 	address     string
+	listeners   []net.Listener
 	contextPool context.Pool
 	opts        *tchannel.ChannelOptions
 	ttopts      tchannelthrift.Options
@@ -47,6 +50,32 @@ func NewServer(
 	opts *tchannel.ChannelOptions,
 	ttopts tchannelthrift.Options,
 ) ns.NetworkService {
+	s := newServer(db, contextPool, opts, ttopts)
+	s.address = address
+	return s
+}
+
+// NewServerWithListeners creates a new node TChannel Thrift network service
+// serving the same service on each of the given listeners, which are closed
+// when the service is closed.
+func NewServerWithListeners(
+	db storage.Database,
+	listeners []net.Listener,
+	contextPool context.Pool,
+	opts *tchannel.ChannelOptions,
+	ttopts tchannelthrift.Options,
+) ns.NetworkService {
+	s := newServer(db, contextPool, opts, ttopts)
+	s.listeners = listeners
+	return s
+}
+
+func newServer(
+	db storage.Database,
+	contextPool context.Pool,
+	opts *tchannel.ChannelOptions,
+	ttopts tchannelthrift.Options,
+) *server {
 	// Make the opts immutable on the way in
 	if opts != nil {
 		immutableOpts := *opts
@@ -57,7 +86,6 @@ func NewServer(
 	}
 	return &server{
 		db:          db,
-		address:     address,
 		contextPool: contextPool,
 		opts:        opts,
 		ttopts:      ttopts,
@@ -65,15 +93,52 @@ func NewServer(
 }
 
 func (s *server) ListenAndServe() (ns.Close, error) {
+	service := NewService(s.db, s.ttopts)
+
+	if len(s.listeners) == 0 {
+		channel, err := s.newChannel(service)
+		if err != nil {
+			return nil, err
+		}
+
+		channel.ListenAndServe(s.address)
+
+		return channel.Close, nil
+	}
+
+	// Each listener is served by its own channel since a channel can only
+	// serve a single listener, the channels all share the same service.
+	channels := make([]*tchannel.Channel, 0, len(s.listeners))
+	closeChannels := func() {
+		for _, channel := range channels {
+			channel.Close()
+		}
+	}
+	for i, l := range s.listeners {
+		channel, err := s.newChannel(service)
+		if err == nil {
+			err = channel.Serve(l)
+		}
+		if err != nil {
+			closeChannels()
+			// Close the listeners not yet owned by a channel.
+			for _, l := range s.listeners[i:] {
+				l.Close()
+			}
+			return nil, err
+		}
+		channels = append(channels, channel)
+	}
+
+	return closeChannels, nil
+}
+
+func (s *server) newChannel(service rpc.TChanNode) (*tchannel.Channel, error) {
 	channel, err := tchannel.NewChannel(channel.ChannelName, s.opts)
 	if err != nil {
 		return nil, err
 	}
 
-	service := NewService(s.db, s.ttopts)
 	tchannelthrift.RegisterServer(channel, rpc.NewTChanNodeServer(service), s.contextPool)
-
-	channel.ListenAndServe(s.address)
-
-	return channel.Close, nil
+	return channel, nil
 }
@@ -0,0 +1,182 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package node
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/node/channel"
+	"github.com/m3db/m3/src/dbnode/storage"
+	"github.com/m3db/m3x/ident"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/tchannel-go"
+	"github.com/uber/tchannel-go/thrift"
+)
+
+func newTestTLSCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, pool
+}
+
+// newTestTLSProxy returns the address of a plaintext listener which forwards
+// each connection over TLS to the given address, since tchannel clients can
+// only dial plaintext connections.
+func newTestTLSProxy(t *testing.T, address string, rootCAs *x509.CertPool) (string, func()) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				tlsConn, err := tls.Dial("tcp", address, &tls.Config{RootCAs: rootCAs})
+				if err != nil {
+					return
+				}
+				defer tlsConn.Close()
+				go io.Copy(tlsConn, conn)
+				io.Copy(conn, tlsConn)
+			}()
+		}
+	}()
+
+	return l.Addr().String(), func() { l.Close() }
+}
+
+func newTestNodeClient(t *testing.T, address string) (rpc.TChanNode, func()) {
+	ch, err := tchannel.NewChannel("test-client", nil)
+	require.NoError(t, err)
+
+	client := rpc.NewTChanNodeClient(thrift.NewClient(ch, channel.ChannelName,
+		&thrift.ClientOptions{HostPort: address}))
+	return client, ch.Close
+}
+
+func TestServerWithListenersServesEachListener(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cert, rootCAs := newTestTLSCertificate(t)
+
+	plaintext, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	tlsRaw, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	tlsListener := tls.NewListener(tlsRaw, &tls.Config{Certificates: []tls.Certificate{cert}})
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().IsOverloaded().Return(false).AnyTimes()
+
+	closer, err := NewServerWithListeners(mockDB, []net.Listener{plaintext, tlsListener},
+		testStorageOpts.ContextPool(), nil, nil).ListenAndServe()
+	require.NoError(t, err)
+
+	proxyAddress, closeProxy := newTestTLSProxy(t, tlsRaw.Addr().String(), rootCAs)
+	defer closeProxy()
+
+	at := time.Now().Truncate(time.Second)
+	for _, address := range []string{plaintext.Addr().String(), proxyAddress} {
+		id := "foo-" + address
+
+		mockDB.EXPECT().
+			Write(gomock.Any(), ident.NewIDMatcher("metrics"), ident.NewIDMatcher(id),
+				at, 42.0, gomock.Any(), nil).
+			Return(nil)
+		mockDB.EXPECT().
+			ReadEncoded(gomock.Any(), ident.NewIDMatcher("metrics"), ident.NewIDMatcher(id),
+				at, at.Add(time.Minute)).
+			Return(nil, nil)
+
+		client, closeClient := newTestNodeClient(t, address)
+
+		ctx, cancel := thrift.NewContext(5 * time.Second)
+		err := client.Write(ctx, &rpc.WriteRequest{
+			NameSpace: "metrics",
+			ID:        id,
+			Datapoint: &rpc.Datapoint{
+				Timestamp:         at.Unix(),
+				TimestampTimeType: rpc.TimeType_UNIX_SECONDS,
+				Value:             42.0,
+			},
+		})
+		require.NoError(t, err)
+
+		result, err := client.Fetch(ctx, &rpc.FetchRequest{
+			NameSpace:      "metrics",
+			ID:             id,
+			RangeStart:     at.Unix(),
+			RangeEnd:       at.Add(time.Minute).Unix(),
+			RangeType:      rpc.TimeType_UNIX_SECONDS,
+			ResultTimeType: rpc.TimeType_UNIX_SECONDS,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 0, len(result.Datapoints))
+
+		cancel()
+		closeClient()
+	}
+
+	// Closing the server closes all of the listeners.
+	closer()
+	for _, l := range []net.Listener{plaintext, tlsRaw} {
+		_, err := net.DialTimeout("tcp", l.Addr().String(), time.Second)
+		require.Error(t, err)
+	}
+}
@@ -21,9 +21,11 @@
 package node
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/client"
@@ -32,10 +34,14 @@ import (
 	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift"
 	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/convert"
 	tterrors "github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/errors"
+	"github.com/m3db/m3/src/dbnode/ratelimit"
+	"github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/serialize"
 	"github.com/m3db/m3/src/dbnode/storage"
 	"github.com/m3db/m3/src/dbnode/storage/block"
+	m3dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
 	"github.com/m3db/m3/src/dbnode/storage/index"
+	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/xio"
 	"github.com/m3db/m3/src/dbnode/x/xpool"
 	"github.com/m3db/m3x/checked"
@@ -51,6 +57,7 @@ import (
 	apachethrift "github.com/apache/thrift/lib/go/thrift"
 	"github.com/uber-go/tally"
 	"github.com/uber/tchannel-go/thrift"
+	xnetcontext "golang.org/x/net/context"
 )
 
 var (
@@ -63,6 +70,10 @@ var (
 const (
 	initSegmentArrayPoolLength  = 4
 	maxSegmentArrayPooledLength = 32
+
+	// fetchDeadlineCheckInterval is the number of datapoints decoded
+	// between checks of whether the deadline of a fetch has expired.
+	fetchDeadlineCheckInterval = 4096
 )
 
 var (
@@ -77,37 +88,64 @@ var (
 
 	// errNodeIsNotBootstrapped
 	errNodeIsNotBootstrapped = errors.New("node is not bootstrapped")
+
+	// errNodeIsReadOnly raised when trying to write to a read only node
+	errNodeIsReadOnly = errors.New("node is read only")
+
+	// errFetchDeadlineExceeded raised when a fetch is aborted as the deadline
+	// propagated by the caller has expired
+	errFetchDeadlineExceeded = m3dberrors.NewDeadlineExceededError(
+		errors.New("fetch aborted as request deadline exceeded"))
+)
+
+const (
+	healthStatusUp       = "up"
+	healthStatusReadOnly = "read-only"
 )
 
 type serviceMetrics struct {
-	fetch               instrument.MethodMetrics
-	fetchTagged         instrument.MethodMetrics
-	write               instrument.MethodMetrics
-	writeTagged         instrument.MethodMetrics
-	fetchBlocks         instrument.MethodMetrics
-	fetchBlocksMetadata instrument.MethodMetrics
-	repair              instrument.MethodMetrics
-	truncate            instrument.MethodMetrics
-	fetchBatchRaw       instrument.BatchMethodMetrics
-	writeBatchRaw       instrument.BatchMethodMetrics
-	writeTaggedBatchRaw instrument.BatchMethodMetrics
-	overloadRejected    tally.Counter
+	fetch                 instrument.MethodMetrics
+	fetchTagged           instrument.MethodMetrics
+	fetchTaggedPage       instrument.MethodMetrics
+	aggregate             instrument.MethodMetrics
+	aggregateTagValues    instrument.MethodMetrics
+	write                 instrument.MethodMetrics
+	writeTagged           instrument.MethodMetrics
+	fetchBlocks           instrument.MethodMetrics
+	fetchBlocksMetadata   instrument.MethodMetrics
+	repair                instrument.MethodMetrics
+	truncate              instrument.MethodMetrics
+	forceFlush            instrument.MethodMetrics
+	fetchBatchRaw         instrument.BatchMethodMetrics
+	writeBatchRaw         instrument.BatchMethodMetrics
+	writeTaggedBatchRaw   instrument.BatchMethodMetrics
+	overloadRejected      tally.Counter
+	readOnlyRejected      tally.Counter
+	readOnly              tally.Gauge
+	fetchDeadlineExceeded tally.Counter
 }
 
 func newServiceMetrics(scope tally.Scope, samplingRate float64) serviceMetrics {
 	return serviceMetrics{
-		fetch:               instrument.NewMethodMetrics(scope, "fetch", samplingRate),
-		fetchTagged:         instrument.NewMethodMetrics(scope, "fetchTagged", samplingRate),
-		write:               instrument.NewMethodMetrics(scope, "write", samplingRate),
-		writeTagged:         instrument.NewMethodMetrics(scope, "writeTagged", samplingRate),
-		fetchBlocks:         instrument.NewMethodMetrics(scope, "fetchBlocks", samplingRate),
-		fetchBlocksMetadata: instrument.NewMethodMetrics(scope, "fetchBlocksMetadata", samplingRate),
-		repair:              instrument.NewMethodMetrics(scope, "repair", samplingRate),
-		truncate:            instrument.NewMethodMetrics(scope, "truncate", samplingRate),
-		fetchBatchRaw:       instrument.NewBatchMethodMetrics(scope, "fetchBatchRaw", samplingRate),
-		writeBatchRaw:       instrument.NewBatchMethodMetrics(scope, "writeBatchRaw", samplingRate),
-		writeTaggedBatchRaw: instrument.NewBatchMethodMetrics(scope, "writeTaggedBatchRaw", samplingRate),
-		overloadRejected:    scope.Counter("overload-rejected"),
+		fetch:                 instrument.NewMethodMetrics(scope, "fetch", samplingRate),
+		fetchTagged:           instrument.NewMethodMetrics(scope, "fetchTagged", samplingRate),
+		fetchTaggedPage:       instrument.NewMethodMetrics(scope, "fetchTaggedPage", samplingRate),
+		aggregate:             instrument.NewMethodMetrics(scope, "aggregate", samplingRate),
+		aggregateTagValues:    instrument.NewMethodMetrics(scope, "aggregateTagValues", samplingRate),
+		write:                 instrument.NewMethodMetrics(scope, "write", samplingRate),
+		writeTagged:           instrument.NewMethodMetrics(scope, "writeTagged", samplingRate),
+		fetchBlocks:           instrument.NewMethodMetrics(scope, "fetchBlocks", samplingRate),
+		fetchBlocksMetadata:   instrument.NewMethodMetrics(scope, "fetchBlocksMetadata", samplingRate),
+		repair:                instrument.NewMethodMetrics(scope, "repair", samplingRate),
+		truncate:              instrument.NewMethodMetrics(scope, "truncate", samplingRate),
+		forceFlush:            instrument.NewMethodMetrics(scope, "forceFlush", samplingRate),
+		fetchBatchRaw:         instrument.NewBatchMethodMetrics(scope, "fetchBatchRaw", samplingRate),
+		writeBatchRaw:         instrument.NewBatchMethodMetrics(scope, "writeBatchRaw", samplingRate),
+		writeTaggedBatchRaw:   instrument.NewBatchMethodMetrics(scope, "writeTaggedBatchRaw", samplingRate),
+		overloadRejected:      scope.Counter("overload-rejected"),
+		readOnlyRejected:      scope.Counter("read-only-rejected"),
+		readOnly:              scope.Gauge("read-only"),
+		fetchDeadlineExceeded: scope.Counter("fetch-deadline-exceeded"),
 	}
 }
 
@@ -122,6 +160,14 @@ type service struct {
 	pools   pools
 	metrics serviceMetrics
 	health  *rpc.NodeHealthResult_
+
+	// bufferChecksumsLimiter paces checksumming buffered blocks when
+	// requested by peers repairing their shards.
+	bufferChecksumsLimiter ratelimit.Limiter
+
+	// readOnly is set to one while the runtime options mark the node as read
+	// only, in which case writes are refused.
+	readOnly int32
 }
 
 type pools struct {
@@ -182,6 +228,11 @@ func NewService(db storage.Database, opts tchannelthrift.Options) rpc.TChanNode
 	writeBatchPooledReqPool := newWriteBatchPooledReqPool(iopts)
 	writeBatchPooledReqPool.Init(opts.TagDecoderPool())
 
+	var bufferChecksumsPerSecond float64
+	if repairOpts := db.Options().RepairOptions(); repairOpts != nil {
+		bufferChecksumsPerSecond = repairOpts.RepairBufferChecksumsPerSecond()
+	}
+
 	s := &service{
 		db:      db,
 		logger:  iopts.Logger(),
@@ -204,26 +255,47 @@ func NewService(db storage.Database, opts tchannelthrift.Options) rpc.TChanNode
 		},
 		health: &rpc.NodeHealthResult_{
 			Ok:           true,
-			Status:       "up",
+			Status:       healthStatusUp,
 			Bootstrapped: false,
 		},
+		bufferChecksumsLimiter: ratelimit.NewLimiter(bufferChecksumsPerSecond),
 	}
 
+	db.Options().RuntimeOptionsManager().RegisterListener(s)
+
 	return s
 }
 
+func (s *service) SetRuntimeOptions(value runtime.Options) {
+	var readOnly int32
+	if value.ReadOnly() {
+		readOnly = 1
+	}
+	atomic.StoreInt32(&s.readOnly, readOnly)
+	s.metrics.readOnly.Update(float64(readOnly))
+}
+
+func (s *service) isReadOnly() bool {
+	return atomic.LoadInt32(&s.readOnly) == 1
+}
+
 func (s *service) Health(ctx thrift.Context) (*rpc.NodeHealthResult_, error) {
 	s.RLock()
 	health := s.health
 	s.RUnlock()
 
-	// Update bootstrapped field if not up to date
+	// Update bootstrapped and status fields if not up to date
 	bootstrapped := s.db.IsBootstrapped()
+	status := healthStatusUp
+	if s.isReadOnly() {
+		status = healthStatusReadOnly
+	}
 
-	if health.Bootstrapped != bootstrapped {
+	if health.Bootstrapped != bootstrapped || health.Status != status {
 		newHealth := &rpc.NodeHealthResult_{}
 		*newHealth = *health
 		newHealth.Bootstrapped = bootstrapped
+		newHealth.Status = status
 
 		s.Lock()
 		s.health = newHealth
@@ -305,7 +377,7 @@ func (s *service) Query(tctx thrift.Context, req *rpc.QueryRequest) (*rpc.QueryR
 			continue
 		}
 		tsID := entry.Key()
-		datapoints, err := s.readDatapoints(ctx, nsID, tsID, start, end,
+		datapoints, err := s.readDatapoints(ctx, tctx, nsID, tsID, start, end,
 			req.ResultTimeType)
 		if err != nil {
 			return nil, convert.ToRPCError(err)
@@ -324,6 +396,8 @@ func (s *service) Fetch(tctx thrift.Context, req *rpc.FetchRequest) (*rpc.FetchR
 
 	callStart := s.nowFn()
 	ctx := tchannelthrift.Context(tctx)
+	reqCtx, cancel := requestContext(tctx, req.TimeoutNanos)
+	defer cancel()
 
 	start, rangeStartErr := convert.ToTime(req.RangeStart, req.RangeType)
 	end, rangeEndErr := convert.ToTime(req.RangeEnd, req.RangeType)
@@ -337,7 +411,7 @@ func (s *service) Fetch(tctx thrift.Context, req *rpc.FetchRequest) (*rpc.FetchR
 	nsID := s.pools.id.GetStringID(ctx, req.NameSpace)
 
 	// Make datapoints an initialized empty array for JSON serialization as empty array than null
-	datapoints, err := s.readDatapoints(ctx, nsID, tsID, start, end,
+	datapoints, err := s.readDatapoints(ctx, reqCtx, nsID, tsID, start, end,
 		req.ResultTimeType)
 	if err != nil {
 		s.metrics.fetch.ReportError(s.nowFn().Sub(callStart))
@@ -350,6 +424,7 @@ func (s *service) Fetch(tctx thrift.Context, req *rpc.FetchRequest) (*rpc.FetchR
 
 func (s *service) readDatapoints(
 	ctx context.Context,
+	reqCtx xnetcontext.Context,
 	nsID, tsID ident.ID,
 	start, end time.Time,
 	timeType rpc.TimeType,
@@ -367,6 +442,12 @@ func (s *service) readDatapoints(
 	defer multiIt.Close()
 
 	for multiIt.Next() {
+		if len(datapoints)%fetchDeadlineCheckInterval == 0 {
+			if err := s.checkFetchDeadline(reqCtx); err != nil {
+				return nil, err
+			}
+		}
+
 		dp, _, annotation := multiIt.Current()
 
 		timestamp, timestampErr := convert.ToValue(dp.Timestamp, timeType)
@@ -397,6 +478,9 @@ func (s *service) FetchTagged(tctx thrift.Context, req *rpc.FetchTaggedRequest)
 
 	callStart := s.nowFn()
 	ctx := tchannelthrift.Context(tctx)
+	reqCtx, cancel := requestContext(tctx, req.TimeoutNanos)
+	defer cancel()
+
 	ns, query, opts, fetchData, err := convert.FromRPCFetchTaggedRequest(req, s.pools)
 	if err != nil {
 		s.metrics.fetchTagged.ReportError(s.nowFn().Sub(callStart))
@@ -406,12 +490,74 @@ func (s *service) FetchTagged(tctx thrift.Context, req *rpc.FetchTaggedRequest)
 	queryResult, err := s.db.QueryIDs(ctx, ns, query, opts)
 	if err != nil {
 		s.metrics.fetchTagged.ReportError(s.nowFn().Sub(callStart))
-		return nil, tterrors.NewInternalError(err)
+		return nil, convert.ToRPCError(err)
+	}
+
+	response, err := s.fetchTaggedResult(ctx, reqCtx, queryResult, opts, fetchData)
+	if err != nil {
+		s.metrics.fetchTagged.ReportError(s.nowFn().Sub(callStart))
+		return nil, err
+	}
+
+	s.metrics.fetchTagged.ReportSuccess(s.nowFn().Sub(callStart))
+	return response, nil
+}
+
+func (s *service) FetchTaggedPage(
+	tctx thrift.Context,
+	req *rpc.FetchTaggedPageRequest,
+) (*rpc.FetchTaggedPageResult_, error) {
+	if s.isOverloaded() {
+		s.metrics.overloadRejected.Inc(1)
+		return nil, tterrors.NewInternalError(errServerIsOverloaded)
+	}
+
+	callStart := s.nowFn()
+	ctx := tchannelthrift.Context(tctx)
+	ns, query, opts, fetchData, err := convert.FromRPCFetchTaggedPageRequest(req, s.pools)
+	if err != nil {
+		s.metrics.fetchTaggedPage.ReportError(s.nowFn().Sub(callStart))
+		return nil, tterrors.NewBadRequestError(err)
+	}
+	reqCtx, cancel := requestContext(tctx, req.Request.TimeoutNanos)
+	defer cancel()
+
+	// NB: only the series of the page are held by the query results, so the data
+	// read for them is bounded by the page size rather than the full result.
+	queryResult, err := s.db.QueryIDs(ctx, ns, query, opts)
+	if err != nil {
+		s.metrics.fetchTaggedPage.ReportError(s.nowFn().Sub(callStart))
+		return nil, convert.ToRPCError(err)
 	}
 
+	result, err := s.fetchTaggedResult(ctx, reqCtx, queryResult, opts, fetchData)
+	if err != nil {
+		s.metrics.fetchTaggedPage.ReportError(s.nowFn().Sub(callStart))
+		return nil, err
+	}
+
+	s.metrics.fetchTaggedPage.ReportSuccess(s.nowFn().Sub(callStart))
+	return &rpc.FetchTaggedPageResult_{
+		Result_:   result,
+		PageToken: queryResult.NextPageToken,
+	}, nil
+}
+
+// fetchTaggedResult builds the response for the results of a fetch tagged query,
+// reading the data of each series if requested.
+func (s *service) fetchTaggedResult(
+	ctx context.Context,
+	reqCtx xnetcontext.Context,
+	queryResult index.QueryResults,
+	opts index.QueryOptions,
+	fetchData bool,
+) (*rpc.FetchTaggedResult_, error) {
 	response := &rpc.FetchTaggedResult_{
 		Exhaustive: queryResult.Exhaustive,
 	}
+	if opts.Trace != nil {
+		response.Trace = convert.ToRPCQueryTrace(opts.Trace)
+	}
 	results := queryResult.Results
 	nsID := results.Namespace()
 	tagsIter := ident.NewTagsIterator(ident.Tags{})
@@ -423,7 +569,6 @@ func (s *service) FetchTagged(tctx thrift.Context, req *rpc.FetchTaggedRequest)
 		tagsIter.Reset(tags)
 		encodedTags, err := s.encodeTags(enc, tagsIter)
 		if err != nil { // This is an invariant, should never happen
-			s.metrics.fetchTagged.ReportError(s.nowFn().Sub(callStart))
 			return nil, tterrors.NewInternalError(err)
 		}
 
@@ -436,7 +581,10 @@ func (s *service) FetchTagged(tctx thrift.Context, req *rpc.FetchTaggedRequest)
 		if !fetchData {
 			continue
 		}
-		segments, rpcErr := s.readEncoded(ctx, nsID, tsID, opts.StartInclusive, opts.EndExclusive)
+		segments, rpcErr := s.readEncoded(ctx, reqCtx, nsID, tsID, opts.StartInclusive, opts.EndExclusive)
+		if tterrors.IsDeadlineExceededError(rpcErr) {
+			return nil, rpcErr
+		}
 		if rpcErr != nil {
 			elem.Err = rpcErr
 			continue
@@ -444,7 +592,79 @@ func (s *service) FetchTagged(tctx thrift.Context, req *rpc.FetchTaggedRequest)
 		elem.Segments = segments
 	}
 
-	s.metrics.fetchTagged.ReportSuccess(s.nowFn().Sub(callStart))
+	return response, nil
+}
+
+func (s *service) Aggregate(tctx thrift.Context, req *rpc.AggregateQueryRequest) (*rpc.AggregateQueryResult_, error) {
+	if s.isOverloaded() {
+		s.metrics.overloadRejected.Inc(1)
+		return nil, tterrors.NewInternalError(errServerIsOverloaded)
+	}
+
+	callStart := s.nowFn()
+	ctx := tchannelthrift.Context(tctx)
+	ns, query, opts, err := convert.FromRPCAggregateQueryRequest(req, s.pools)
+	if err != nil {
+		s.metrics.aggregate.ReportError(s.nowFn().Sub(callStart))
+		return nil, tterrors.NewBadRequestError(err)
+	}
+
+	queryResult, err := s.db.AggregateTerms(ctx, ns, query, opts)
+	if err != nil {
+		s.metrics.aggregate.ReportError(s.nowFn().Sub(callStart))
+		return nil, convert.ToRPCError(err)
+	}
+
+	response := &rpc.AggregateQueryResult_{
+		Exhaustive: queryResult.Exhaustive,
+	}
+	for _, t := range queryResult.Results.Terms(opts.Limit) {
+		response.Terms = append(response.Terms, &rpc.AggregateQueryResultTerm{
+			Term:  t.Term,
+			Count: int64(t.Count),
+		})
+	}
+
+	s.metrics.aggregate.ReportSuccess(s.nowFn().Sub(callStart))
+	return response, nil
+}
+
+func (s *service) AggregateTagValues(
+	tctx thrift.Context,
+	req *rpc.AggregateTagValuesRequest,
+) (*rpc.AggregateTagValuesResult_, error) {
+	if s.isOverloaded() {
+		s.metrics.overloadRejected.Inc(1)
+		return nil, tterrors.NewInternalError(errServerIsOverloaded)
+	}
+
+	callStart := s.nowFn()
+	ctx := tchannelthrift.Context(tctx)
+	ns, opts, err := convert.FromRPCAggregateTagValuesRequest(req, s.pools)
+	if err != nil {
+		s.metrics.aggregateTagValues.ReportError(s.nowFn().Sub(callStart))
+		return nil, tterrors.NewBadRequestError(err)
+	}
+
+	tagValues, err := s.db.AggregateTagValues(ctx, ns, opts)
+	if err != nil {
+		s.metrics.aggregateTagValues.ReportError(s.nowFn().Sub(callStart))
+		return nil, convert.ToRPCError(err)
+	}
+
+	response := &rpc.AggregateTagValuesResult_{
+		Values:     make([]*rpc.AggregateQueryResultTerm, 0, len(tagValues.Values)),
+		Exhaustive: tagValues.Exhaustive,
+		Cursor:     tagValues.Cursor,
+	}
+	for _, v := range tagValues.Values {
+		response.Values = append(response.Values, &rpc.AggregateQueryResultTerm{
+			Term:  v.Term,
+			Count: int64(v.Count),
+		})
+	}
+
+	s.metrics.aggregateTagValues.ReportSuccess(s.nowFn().Sub(callStart))
 	return response, nil
 }
 
@@ -478,6 +698,8 @@ func (s *service) FetchBatchRaw(tctx thrift.Context, req *rpc.FetchBatchRawReque
 
 	callStart := s.nowFn()
 	ctx := tchannelthrift.Context(tctx)
+	reqCtx, cancel := requestContext(tctx, req.TimeoutNanos)
+	defer cancel()
 
 	start, rangeStartErr := convert.ToTime(req.RangeStart, req.RangeTimeType)
 	end, rangeEndErr := convert.ToTime(req.RangeEnd, req.RangeTimeType)
@@ -503,7 +725,15 @@ func (s *service) FetchBatchRaw(tctx thrift.Context, req *rpc.FetchBatchRawReque
 		result.Elements = append(result.Elements, rawResult)
 
 		tsID := s.newID(ctx, req.Ids[i])
-		segments, rpcErr := s.readEncoded(ctx, nsID, tsID, start, end)
+		segments, rpcErr := s.readEncoded(ctx, reqCtx, nsID, tsID, start, end)
+		if tterrors.IsDeadlineExceededError(rpcErr) {
+			// Abort the remaining fetches as the caller has given up
+			s.metrics.fetchBatchRaw.ReportSuccess(success)
+			s.metrics.fetchBatchRaw.ReportRetryableErrors(len(req.Ids) - success)
+			s.metrics.fetchBatchRaw.ReportNonRetryableErrors(nonRetryableErrors)
+			s.metrics.fetchBatchRaw.ReportLatency(s.nowFn().Sub(callStart))
+			return nil, rpcErr
+		}
 		if rpcErr != nil {
 			rawResult.Err = rpcErr
 			if tterrors.IsBadRequestError(rawResult.Err) {
@@ -731,6 +961,10 @@ func (s *service) FetchBlocksMetadataRawV2(tctx thrift.Context, req *rpc.FetchBl
 	if req.IncludeLastRead != nil {
 		opts.IncludeLastRead = *req.IncludeLastRead
 	}
+	if req.IncludeBufferChecksums != nil {
+		opts.IncludeBufferChecksums = *req.IncludeBufferChecksums
+		opts.BufferChecksumsLimiter = s.bufferChecksumsLimiter
+	}
 
 	var (
 		nsID  = s.newID(ctx, req.NameSpace)
@@ -825,6 +1059,13 @@ func (s *service) getBlocksMetadataV2FromResult(
 				blockMetadata.LastReadTimeType = rpc.TimeType(0)
 			}
 
+			if fetchedMetadataBlock.Buffered {
+				buffered := true
+				blockMetadata.Buffered = &buffered
+			} else {
+				blockMetadata.Buffered = nil
+			}
+
 			if err := fetchedMetadataBlock.Err; err != nil {
 				blockMetadata.Err = convert.ToRPCError(err)
 			} else {
@@ -842,6 +1083,12 @@ func (s *service) Write(tctx thrift.Context, req *rpc.WriteRequest) error {
 	callStart := s.nowFn()
 	ctx := tchannelthrift.Context(tctx)
 
+	if s.isReadOnly() {
+		s.metrics.readOnlyRejected.Inc(1)
+		s.metrics.write.ReportError(s.nowFn().Sub(callStart))
+		return tterrors.NewReadOnlyError(errNodeIsReadOnly)
+	}
+
 	if req.Datapoint == nil {
 		s.metrics.write.ReportError(s.nowFn().Sub(callStart))
 		return tterrors.NewBadRequestError(errRequiresDatapoint)
@@ -878,6 +1125,12 @@ func (s *service) WriteTagged(tctx thrift.Context, req *rpc.WriteTaggedRequest)
 	callStart := s.nowFn()
 	ctx := tchannelthrift.Context(tctx)
 
+	if s.isReadOnly() {
+		s.metrics.readOnlyRejected.Inc(1)
+		s.metrics.writeTagged.ReportError(s.nowFn().Sub(callStart))
+		return tterrors.NewReadOnlyError(errNodeIsReadOnly)
+	}
+
 	if req.Datapoint == nil {
 		s.metrics.writeTagged.ReportError(s.nowFn().Sub(callStart))
 		return tterrors.NewBadRequestError(errRequiresDatapoint)
@@ -926,6 +1179,11 @@ func (s *service) WriteBatchRaw(tctx thrift.Context, req *rpc.WriteBatchRawReque
 	callStart := s.nowFn()
 	ctx := tchannelthrift.Context(tctx)
 
+	if s.isReadOnly() {
+		return s.readOnlyWriteBatchRawErrors(len(req.Elements),
+			s.metrics.writeBatchRaw, callStart)
+	}
+
 	// NB(r): Use the pooled request tracking to return thrift alloc'd bytes
 	// to the thrift bytes pool and to return ident.ID wrappers to a pool for
 	// reuse. We also reduce contention on pools by getting one per batch request
@@ -965,6 +1223,12 @@ func (s *service) WriteBatchRaw(tctx thrift.Context, req *rpc.WriteBatchRawReque
 		); err != nil && xerrors.IsInvalidParams(err) {
 			nonRetryableErrors++
 			errs = append(errs, tterrors.NewBadRequestWriteBatchRawError(i, err))
+		} else if err != nil && m3dberrors.IsResourceExhausted(err) {
+			nonRetryableErrors++
+			errs = append(errs, tterrors.NewResourceExhaustedWriteBatchRawError(i, err))
+		} else if err != nil && m3dberrors.IsShardUnavailable(err) {
+			retryableErrors++
+			errs = append(errs, tterrors.NewShardUnavailableWriteBatchRawError(i, err))
 		} else if err != nil {
 			retryableErrors++
 			errs = append(errs, tterrors.NewWriteBatchRawError(i, err))
@@ -991,6 +1255,11 @@ func (s *service) WriteTaggedBatchRaw(tctx thrift.Context, req *rpc.WriteTaggedB
 	callStart := s.nowFn()
 	ctx := tchannelthrift.Context(tctx)
 
+	if s.isReadOnly() {
+		return s.readOnlyWriteBatchRawErrors(len(req.Elements),
+			s.metrics.writeTaggedBatchRaw, callStart)
+	}
+
 	// NB(r): Use the pooled request tracking to return thrift alloc'd bytes
 	// to the thrift bytes pool and to return ident.ID wrappers to a pool for
 	// reuse. We also reduce contention on pools by getting one per batch request
@@ -1007,11 +1276,30 @@ func (s *service) WriteTaggedBatchRaw(tctx thrift.Context, req *rpc.WriteTaggedB
 		retryableErrors    int
 		nonRetryableErrors int
 	)
-	for i, elem := range req.Elements {
+	recordResult := func(i int, err error) {
+		if err != nil && xerrors.IsInvalidParams(err) {
+			nonRetryableErrors++
+			errs = append(errs, tterrors.NewBadRequestWriteBatchRawError(i, err))
+		} else if err != nil && m3dberrors.IsResourceExhausted(err) {
+			nonRetryableErrors++
+			errs = append(errs, tterrors.NewResourceExhaustedWriteBatchRawError(i, err))
+		} else if err != nil && m3dberrors.IsShardUnavailable(err) {
+			retryableErrors++
+			errs = append(errs, tterrors.NewShardUnavailableWriteBatchRawError(i, err))
+		} else if err != nil {
+			retryableErrors++
+			errs = append(errs, tterrors.NewWriteBatchRawError(i, err))
+		} else {
+			success++
+		}
+	}
+	for i := 0; i < len(req.Elements); {
+		elem := req.Elements[i]
 		unit, unitErr := convert.ToUnit(elem.Datapoint.TimestampTimeType)
 		if unitErr != nil {
 			nonRetryableErrors++
 			errs = append(errs, tterrors.NewBadRequestWriteBatchRawError(i, unitErr))
+			i++
 			continue
 		}
 
@@ -1019,6 +1307,7 @@ func (s *service) WriteTaggedBatchRaw(tctx thrift.Context, req *rpc.WriteTaggedB
 		if err != nil {
 			nonRetryableErrors++
 			errs = append(errs, tterrors.NewBadRequestWriteBatchRawError(i, err))
+			i++
 			continue
 		}
 
@@ -1026,23 +1315,53 @@ func (s *service) WriteTaggedBatchRaw(tctx thrift.Context, req *rpc.WriteTaggedB
 		if err != nil {
 			nonRetryableErrors++
 			errs = append(errs, tterrors.NewBadRequestWriteBatchRawError(i, err))
+			i++
 			continue
 		}
 
+		// Elements that follow for the same series and time unit are written
+		// as a single batch to amortize the cost of the series write.
+		end := i + 1
+		for end < len(req.Elements) &&
+			bytes.Equal(req.Elements[end].ID, elem.ID) &&
+			req.Elements[end].Datapoint.TimestampTimeType == elem.Datapoint.TimestampTimeType {
+			end++
+		}
+
 		seriesID := s.newPooledID(ctx, elem.ID, pooledReq)
-		if err = s.db.WriteTagged(
-			ctx, nsID, seriesID, dec,
-			xtime.FromNormalizedTime(elem.Datapoint.Timestamp, d),
-			elem.Datapoint.Value, unit, elem.Datapoint.Annotation,
-		); err != nil && xerrors.IsInvalidParams(err) {
-			nonRetryableErrors++
-			errs = append(errs, tterrors.NewBadRequestWriteBatchRawError(i, err))
-		} else if err != nil {
-			retryableErrors++
-			errs = append(errs, tterrors.NewWriteBatchRawError(i, err))
-		} else {
-			success++
+		if end-i == 1 {
+			err = s.db.WriteTagged(
+				ctx, nsID, seriesID, dec,
+				xtime.FromNormalizedTime(elem.Datapoint.Timestamp, d),
+				elem.Datapoint.Value, unit, elem.Datapoint.Annotation,
+			)
+			recordResult(i, err)
+			i = end
+			continue
+		}
+
+		var (
+			datapoints  = make([]ts.Datapoint, 0, end-i)
+			annotations = make([][]byte, 0, end-i)
+		)
+		for _, elem := range req.Elements[i:end] {
+			datapoints = append(datapoints, ts.Datapoint{
+				Timestamp: xtime.FromNormalizedTime(elem.Datapoint.Timestamp, d),
+				Value:     elem.Datapoint.Value,
+			})
+			annotations = append(annotations, elem.Datapoint.Annotation)
+		}
+
+		writeErrs, err := s.db.WriteTaggedBatch(ctx, nsID, seriesID, dec,
+			datapoints, unit, annotations)
+		for j := i; j < end; j++ {
+			if err != nil {
+				recordResult(j, err)
+			} else {
+				recordResult(j, writeErrs[j-i])
+			}
 		}
+		i = end
 	}
 
 	s.metrics.writeTaggedBatchRaw.ReportSuccess(success)
@@ -1059,6 +1378,27 @@ func (s *service) WriteTaggedBatchRaw(tctx thrift.Context, req *rpc.WriteTaggedB
 	return nil
 }
 
+// readOnlyWriteBatchRawErrors refuses every element of a write batch as the
+// node is read only.
+func (s *service) readOnlyWriteBatchRawErrors(
+	numElements int,
+	metrics instrument.BatchMethodMetrics,
+	callStart time.Time,
+) error {
+	errs := make([]*rpc.WriteBatchRawError, 0, numElements)
+	for i := 0; i < numElements; i++ {
+		errs = append(errs, tterrors.NewReadOnlyWriteBatchRawError(i, errNodeIsReadOnly))
+	}
+
+	s.metrics.readOnlyRejected.Inc(int64(numElements))
+	metrics.ReportRetryableErrors(numElements)
+	metrics.ReportLatency(s.nowFn().Sub(callStart))
+
+	batchErrs := rpc.NewWriteBatchRawErrors()
+	batchErrs.Errors = errs
+	return batchErrs
+}
+
 func (s *service) Repair(tctx thrift.Context) error {
 	callStart := s.nowFn()
 
@@ -1090,6 +1430,42 @@ func (s *service) Truncate(tctx thrift.Context, req *rpc.TruncateRequest) (r *rp
 	return res, nil
 }
 
+func (s *service) ForceFlush(tctx thrift.Context, req *rpc.ForceFlushRequest) (*rpc.ForceFlushResult_, error) {
+	callStart := s.nowFn()
+	ctx := tchannelthrift.Context(tctx)
+
+	start, rangeStartErr := convert.ToTime(req.RangeStart, req.RangeType)
+	end, rangeEndErr := convert.ToTime(req.RangeEnd, req.RangeType)
+
+	if rangeStartErr != nil || rangeEndErr != nil {
+		s.metrics.forceFlush.ReportError(s.nowFn().Sub(callStart))
+		return nil, tterrors.NewBadRequestError(xerrors.FirstError(rangeStartErr, rangeEndErr))
+	}
+
+	opts := storage.ForceFlushOptions{
+		Start: start,
+		End:   end,
+		Force: req.GetForce(),
+	}
+	if req.IsSetShard() {
+		opts.Shards = []uint32{uint32(req.GetShard())}
+	}
+
+	result, err := s.db.ForceFlush(s.newID(ctx, req.NameSpace), opts)
+	if err != nil {
+		s.metrics.forceFlush.ReportError(s.nowFn().Sub(callStart))
+		return nil, convert.ToRPCError(err)
+	}
+
+	res := rpc.NewForceFlushResult_()
+	res.NumSeriesFlushed = result.NumSeriesFlushed
+	res.NumBytesWritten = result.NumBytesWritten
+
+	s.metrics.forceFlush.ReportSuccess(s.nowFn().Sub(callStart))
+
+	return res, nil
+}
+
 func (s *service) GetPersistRateLimit(
 	ctx thrift.Context,
 ) (*rpc.NodePersistRateLimitResult_, error) {
@@ -1238,9 +1614,14 @@ func (s *service) newPooledID(
 
 func (s *service) readEncoded(
 	ctx context.Context,
+	reqCtx xnetcontext.Context,
 	nsID, tsID ident.ID,
 	start, end time.Time,
 ) ([]*rpc.Segments, *rpc.Error) {
+	if err := s.checkFetchDeadline(reqCtx); err != nil {
+		return nil, convert.ToRPCError(err)
+	}
+
 	encoded, err := s.db.ReadEncoded(ctx, nsID, tsID, start, end)
 	if err != nil {
 		return nil, convert.ToRPCError(err)
@@ -1254,6 +1635,12 @@ func (s *service) readEncoded(
 	}))
 
 	for _, readers := range encoded {
+		// NB: Converting the readers waits on any blocks being retrieved
+		// from disk so check the deadline before each block.
+		if err := s.checkFetchDeadline(reqCtx); err != nil {
+			return nil, convert.ToRPCError(err)
+		}
+
 		converted, err := convert.ToSegments(readers)
 		if err != nil {
 			return nil, convert.ToRPCError(err)
@@ -1267,6 +1654,30 @@ func (s *service) readEncoded(
 	return segments, nil
 }
 
+// requestContext returns the context to check for cancellation while serving
+// a fetch, bounded by the timeout the caller propagated with the request if
+// one was set.
+func requestContext(
+	tctx thrift.Context,
+	timeoutNanos *int64,
+) (xnetcontext.Context, xnetcontext.CancelFunc) {
+	if timeoutNanos == nil || *timeoutNanos <= 0 {
+		return tctx, func() {}
+	}
+	return xnetcontext.WithTimeout(tctx, time.Duration(*timeoutNanos))
+}
+
+// checkFetchDeadline returns an error if the request a fetch is serving has
+// been cancelled or its deadline has expired, so that the node stops doing
+// work for a caller that has already given up on the fetch.
+func (s *service) checkFetchDeadline(reqCtx xnetcontext.Context) error {
+	if reqCtx.Err() == nil {
+		return nil
+	}
+	s.metrics.fetchDeadlineExceeded.Inc(1)
+	return errFetchDeadlineExceeded
+}
+
 func (s *service) newTagsDecoder(ctx context.Context, encodedTags []byte) (serialize.TagDecoder, error) {
 	checkedBytes := s.pools.checkedBytesWrapper.Get(encodedTags)
 	dec := s.pools.tagDecoder.Get()
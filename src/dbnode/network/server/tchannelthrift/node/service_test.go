@@ -36,18 +36,23 @@ import (
 	"github.com/m3db/m3/src/dbnode/serialize"
 	"github.com/m3db/m3/src/dbnode/storage"
 	"github.com/m3db/m3/src/dbnode/storage/block"
+	m3dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
 	"github.com/m3db/m3/src/dbnode/storage/index"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/xio"
 	"github.com/m3db/m3/src/m3ninx/idx"
+	m3ninxindex "github.com/m3db/m3/src/m3ninx/index"
 	"github.com/m3db/m3x/checked"
+	xclock "github.com/m3db/m3x/clock"
 	"github.com/m3db/m3x/ident"
+	"github.com/m3db/m3x/instrument"
 	xtime "github.com/m3db/m3x/time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
 	"github.com/uber/tchannel-go/thrift"
 )
 
@@ -391,6 +396,80 @@ func TestServiceFetchIsOverloaded(t *testing.T) {
 	require.Equal(t, tterrors.NewInternalError(errServerIsOverloaded), err)
 }
 
+func TestServiceFetchDeadlineExceeded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().IsOverloaded().Return(false)
+
+	scope := tally.NewTestScope("", nil)
+	opts := tchannelthrift.NewOptions().
+		SetInstrumentOptions(instrument.NewOptions().SetMetricsScope(scope))
+	service := NewService(mockDB, opts).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	var (
+		blockSize = time.Hour
+		numBlocks = 24
+		end       = time.Now().Truncate(blockSize)
+		start     = end.Add(-time.Duration(numBlocks) * blockSize)
+		timeout   = 10 * time.Millisecond
+		nsID      = "metrics"
+	)
+
+	// Synthesize a day of datapoints at a one second resolution
+	var readers [][]xio.BlockReader
+	for i := 0; i < numBlocks; i++ {
+		blockStart := start.Add(time.Duration(i) * blockSize)
+		enc := testStorageOpts.EncoderPool().Get()
+		enc.Reset(blockStart, 0)
+		for curr := blockStart; curr.Before(blockStart.Add(blockSize)); curr = curr.Add(time.Second) {
+			dp := ts.Datapoint{Timestamp: curr, Value: float64(curr.Unix())}
+			require.NoError(t, enc.Encode(dp, xtime.Second, nil))
+		}
+		readers = append(readers, []xio.BlockReader{
+			xio.BlockReader{
+				SegmentReader: enc.Stream(),
+				Start:         blockStart,
+				BlockSize:     blockSize,
+			},
+		})
+	}
+
+	// Take longer than the deadline to read the series
+	mockDB.EXPECT().
+		ReadEncoded(ctx, ident.NewIDMatcher(nsID), ident.NewIDMatcher("foo"), start, end).
+		Do(func(_ interface{}, _ ident.ID, _ ident.ID, _ time.Time, _ time.Time) {
+			time.Sleep(2 * timeout)
+		}).
+		Return(readers, nil)
+
+	timeoutNanos := int64(timeout)
+	_, err := service.Fetch(tctx, &rpc.FetchRequest{
+		RangeStart:     start.Unix(),
+		RangeEnd:       end.Unix(),
+		RangeType:      rpc.TimeType_UNIX_SECONDS,
+		NameSpace:      nsID,
+		ID:             "foo",
+		ResultTimeType: rpc.TimeType_UNIX_SECONDS,
+		TimeoutNanos:   &timeoutNanos,
+	})
+	require.Error(t, err)
+	rpcErr, ok := err.(*rpc.Error)
+	require.True(t, ok)
+	assert.True(t, tterrors.IsDeadlineExceededError(rpcErr))
+
+	counters := scope.Snapshot().Counters()
+	counter, ok := counters["service.fetch-deadline-exceeded+service-name=node"]
+	require.True(t, ok)
+	assert.Equal(t, int64(1), counter.Value())
+}
+
 func TestServiceFetchBatchRaw(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -521,6 +600,51 @@ func TestServiceFetchBatchRawIsOverloaded(t *testing.T) {
 	require.Equal(t, tterrors.NewInternalError(errServerIsOverloaded), err)
 }
 
+func TestServiceFetchBatchRawDeadlineExceeded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().IsOverloaded().Return(false)
+
+	service := NewService(mockDB, nil).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	var (
+		start   = time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+		end     = start.Add(2 * time.Hour)
+		timeout = 10 * time.Millisecond
+		nsID    = "metrics"
+	)
+
+	// Only the first series is read as it takes longer than the deadline,
+	// the fetch of the second series is aborted
+	mockDB.EXPECT().
+		ReadEncoded(ctx, ident.NewIDMatcher(nsID), ident.NewIDMatcher("foo"), start, end).
+		Do(func(_ interface{}, _ ident.ID, _ ident.ID, _ time.Time, _ time.Time) {
+			time.Sleep(2 * timeout)
+		}).
+		Return(nil, nil)
+
+	timeoutNanos := int64(timeout)
+	_, err := service.FetchBatchRaw(tctx, &rpc.FetchBatchRawRequest{
+		RangeStart:    start.Unix(),
+		RangeEnd:      end.Unix(),
+		RangeTimeType: rpc.TimeType_UNIX_SECONDS,
+		NameSpace:     []byte(nsID),
+		Ids:           [][]byte{[]byte("foo"), []byte("bar")},
+		TimeoutNanos:  &timeoutNanos,
+	})
+	require.Error(t, err)
+	rpcErr, ok := err.(*rpc.Error)
+	require.True(t, ok)
+	assert.True(t, tterrors.IsDeadlineExceededError(rpcErr))
+}
+
 func TestServiceFetchBlocksRaw(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -966,6 +1090,73 @@ func TestServiceFetchBlocksMetadataEndpointV2Raw(t *testing.T) {
 	}
 }
 
+func TestServiceFetchBlocksMetadataEndpointV2RawBufferChecksums(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().IsOverloaded().Return(false)
+	service := NewService(mockDB, nil).(*service)
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	var (
+		start                  = time.Now().Truncate(time.Hour)
+		end                    = start.Add(2 * time.Hour)
+		limit                  = int64(2)
+		includeChecksums       = true
+		includeBufferChecksums = true
+		nsID                   = "metrics"
+		flushedChecksum        = uint32(111)
+		bufferedChecksum       = uint32(222)
+	)
+
+	blocks := block.NewFetchBlockMetadataResults()
+	blocks.Add(block.FetchBlockMetadataResult{
+		Start:    start,
+		Checksum: &flushedChecksum,
+	})
+	blocks.Add(block.FetchBlockMetadataResult{
+		Start:    start.Add(time.Hour),
+		Checksum: &bufferedChecksum,
+		Buffered: true,
+	})
+	mockResult := block.NewFetchBlocksMetadataResults()
+	mockResult.Add(block.NewFetchBlocksMetadataResult(ident.StringID("foo"),
+		ident.EmptyTagIterator, blocks))
+
+	// Buffer checksums are paced by the service limiter
+	opts := block.FetchBlocksMetadataOptions{
+		IncludeChecksums:       includeChecksums,
+		IncludeBufferChecksums: includeBufferChecksums,
+		BufferChecksumsLimiter: service.bufferChecksumsLimiter,
+	}
+	mockDB.EXPECT().
+		FetchBlocksMetadataV2(ctx, ident.NewIDMatcher(nsID), uint32(0), start, end,
+			limit, nil, opts).
+		Return(mockResult, nil, nil)
+
+	r, err := service.FetchBlocksMetadataRawV2(tctx, &rpc.FetchBlocksMetadataRawV2Request{
+		NameSpace:              []byte(nsID),
+		Shard:                  0,
+		RangeStart:             start.UnixNano(),
+		RangeEnd:               end.UnixNano(),
+		Limit:                  limit,
+		IncludeChecksums:       &includeChecksums,
+		IncludeBufferChecksums: &includeBufferChecksums,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, 2, len(r.Elements))
+	require.Equal(t, int64(flushedChecksum), *r.Elements[0].Checksum)
+	require.Nil(t, r.Elements[0].Buffered)
+	require.Equal(t, int64(bufferedChecksum), *r.Elements[1].Checksum)
+	require.NotNil(t, r.Elements[1].Buffered)
+	require.True(t, *r.Elements[1].Buffered)
+}
+
 func TestServiceFetchBlocksMetadataEndpointV2RawIsOverloaded(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -1262,6 +1453,120 @@ func TestServiceFetchTaggedNoData(t *testing.T) {
 	}
 }
 
+func TestServiceFetchTaggedTrace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().IsOverloaded().Return(false)
+
+	service := NewService(mockDB, nil).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	end := start.Add(2 * time.Hour)
+	nsID := "metrics"
+
+	req, err := idx.NewRegexpQuery([]byte("foo"), []byte("b.*"))
+	require.NoError(t, err)
+
+	resMap := index.NewResults(index.NewOptions())
+	resMap.Reset(ident.StringID(nsID))
+	mockDB.EXPECT().QueryIDs(
+		ctx,
+		ident.NewIDMatcher(nsID),
+		index.NewQueryMatcher(index.Query{Query: req}),
+		gomock.Any(),
+	).Do(func(_, _, _ interface{}, opts index.QueryOptions) {
+		// Simulate the index recording the query of a block in the trace.
+		require.NotNil(t, opts.Trace)
+		opts.Trace.NewChild("block").DocsEmitted = 3
+	}).Return(index.QueryResults{Results: resMap, Exhaustive: true}, nil)
+
+	startNanos, err := convert.ToValue(start, rpc.TimeType_UNIX_NANOSECONDS)
+	require.NoError(t, err)
+	endNanos, err := convert.ToValue(end, rpc.TimeType_UNIX_NANOSECONDS)
+	require.NoError(t, err)
+	data, err := idx.Marshal(req)
+	require.NoError(t, err)
+	trace := true
+	r, err := service.FetchTagged(tctx, &rpc.FetchTaggedRequest{
+		NameSpace:  []byte(nsID),
+		Query:      data,
+		RangeStart: startNanos,
+		RangeEnd:   endNanos,
+		Trace:      &trace,
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, r.Trace)
+	require.Len(t, r.Trace.Children, 1)
+	require.Equal(t, "block", r.Trace.Children[0].Name)
+	require.Equal(t, int64(3), r.Trace.Children[0].DocsEmitted)
+}
+
+func TestServiceFetchTaggedLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().IsOverloaded().Return(false)
+
+	service := NewService(mockDB, nil).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	end := start.Add(2 * time.Hour)
+	nsID := "metrics"
+
+	req, err := idx.NewRegexpQuery([]byte("foo"), []byte("b.*"))
+	require.NoError(t, err)
+
+	resMap := index.NewResults(index.NewOptions())
+	resMap.Reset(ident.StringID(nsID))
+	resMap.Map().Set(ident.StringID("foo"), ident.Tags{})
+	mockDB.EXPECT().QueryIDs(
+		ctx,
+		ident.NewIDMatcher(nsID),
+		index.NewQueryMatcher(index.Query{Query: req}),
+		index.QueryOptions{
+			StartInclusive: start,
+			EndExclusive:   end,
+			SeriesLimit:    1,
+			DocsLimit:      100,
+		}).Return(index.QueryResults{Results: resMap, Exhaustive: false}, nil)
+
+	startNanos, err := convert.ToValue(start, rpc.TimeType_UNIX_NANOSECONDS)
+	require.NoError(t, err)
+	endNanos, err := convert.ToValue(end, rpc.TimeType_UNIX_NANOSECONDS)
+	require.NoError(t, err)
+	data, err := idx.Marshal(req)
+	require.NoError(t, err)
+	var seriesLimit, docsLimit int64 = 1, 100
+	r, err := service.FetchTagged(tctx, &rpc.FetchTaggedRequest{
+		NameSpace:   []byte(nsID),
+		Query:       data,
+		RangeStart:  startNanos,
+		RangeEnd:    endNanos,
+		SeriesLimit: &seriesLimit,
+		DocsLimit:   &docsLimit,
+	})
+	require.NoError(t, err)
+
+	// A truncated result is returned as a partial result rather than an error.
+	require.False(t, r.Exhaustive)
+	require.Len(t, r.Elements, 1)
+	require.Equal(t, []byte("foo"), r.Elements[0].ID)
+}
+
 func TestServiceFetchTaggedErrs(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -1314,12 +1619,13 @@ func TestServiceFetchTaggedErrs(t *testing.T) {
 	require.Error(t, err)
 }
 
-func TestServiceWrite(t *testing.T) {
+func TestServiceFetchTaggedPage(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockDB := storage.NewMockDatabase(ctrl)
 	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().IsOverloaded().Return(false)
 
 	service := NewService(mockDB, nil).(*service)
 
@@ -1327,35 +1633,74 @@ func TestServiceWrite(t *testing.T) {
 	ctx := tchannelthrift.Context(tctx)
 	defer ctx.Close()
 
-	nsID := "metrics"
+	start := time.Now().Add(-2 * time.Hour)
+	end := start.Add(2 * time.Hour)
 
-	id := "foo"
+	start, end = start.Truncate(time.Second), end.Truncate(time.Second)
+	nsID := "metrics"
 
-	at := time.Now().Truncate(time.Second)
-	value := 42.42
+	req, err := idx.NewRegexpQuery([]byte("foo"), []byte("b.*"))
+	require.NoError(t, err)
+	qry := index.Query{Query: req}
 
-	mockDB.EXPECT().
-		Write(ctx, ident.NewIDMatcher(nsID), ident.NewIDMatcher(id), at, value, xtime.Second, nil).
-		Return(nil)
+	resMap := index.NewResults(index.NewOptions())
+	resMap.Reset(ident.StringID(nsID))
+	resMap.Map().Set(ident.StringID("bar"), ident.Tags{})
+	resMap.Map().Set(ident.StringID("baz"), ident.Tags{})
+	mockDB.EXPECT().QueryIDs(
+		ctx,
+		ident.NewIDMatcher(nsID),
+		index.NewQueryMatcher(qry),
+		index.QueryOptions{
+			StartInclusive: start,
+			EndExclusive:   end,
+			PageSize:       2,
+			PageToken:      []byte("abc"),
+		}).Return(index.QueryResults{
+		Results:       resMap,
+		Exhaustive:    true,
+		NextPageToken: []byte("baz"),
+	}, nil)
 
-	err := service.Write(tctx, &rpc.WriteRequest{
-		NameSpace: nsID,
-		ID:        id,
-		Datapoint: &rpc.Datapoint{
-			Timestamp:         at.Unix(),
-			TimestampTimeType: rpc.TimeType_UNIX_SECONDS,
-			Value:             value,
+	startNanos, err := convert.ToValue(start, rpc.TimeType_UNIX_NANOSECONDS)
+	require.NoError(t, err)
+	endNanos, err := convert.ToValue(end, rpc.TimeType_UNIX_NANOSECONDS)
+	require.NoError(t, err)
+	data, err := idx.Marshal(req)
+	require.NoError(t, err)
+	r, err := service.FetchTaggedPage(tctx, &rpc.FetchTaggedPageRequest{
+		Request: &rpc.FetchTaggedRequest{
+			NameSpace:  []byte(nsID),
+			Query:      data,
+			RangeStart: startNanos,
+			RangeEnd:   endNanos,
+			FetchData:  false,
 		},
+		PageSize:  2,
+		PageToken: []byte("abc"),
 	})
 	require.NoError(t, err)
+	require.Equal(t, []byte("baz"), r.PageToken)
+	require.True(t, r.Result_.Exhaustive)
+
+	// sort to order results to make test deterministic.
+	sort.Slice(r.Result_.Elements, func(i, j int) bool {
+		return bytes.Compare(r.Result_.Elements[i].ID, r.Result_.Elements[j].ID) < 0
+	})
+	ids := [][]byte{[]byte("bar"), []byte("baz")}
+	require.Equal(t, len(ids), len(r.Result_.Elements))
+	for i, id := range ids {
+		require.Equal(t, id, r.Result_.Elements[i].ID)
+	}
 }
 
-func TestServiceWriteTagged(t *testing.T) {
+func TestServiceFetchTaggedPageSizeInvalid(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockDB := storage.NewMockDatabase(ctrl)
 	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().IsOverloaded().Return(false)
 
 	service := NewService(mockDB, nil).(*service)
 
@@ -1363,23 +1708,333 @@ func TestServiceWriteTagged(t *testing.T) {
 	ctx := tchannelthrift.Context(tctx)
 	defer ctx.Close()
 
-	var (
-		nsID      = "metrics"
-		id        = "foo"
-		tagNames  = []string{"foo", "bar", "baz"}
-		tagValues = []string{"cmon", "keep", "going"}
-		at        = time.Now().Truncate(time.Second)
-		value     = 42.42
-	)
+	req, err := idx.NewRegexpQuery([]byte("foo"), []byte("b.*"))
+	require.NoError(t, err)
+	data, err := idx.Marshal(req)
+	require.NoError(t, err)
+	_, err = service.FetchTaggedPage(tctx, &rpc.FetchTaggedPageRequest{
+		Request: &rpc.FetchTaggedRequest{
+			NameSpace: []byte("metrics"),
+			Query:     data,
+		},
+		PageSize: 0,
+	})
+	rpcErr, ok := err.(*rpc.Error)
+	require.True(t, ok)
+	require.True(t, tterrors.IsBadRequestError(rpcErr))
+}
 
-	mockDB.EXPECT().WriteTagged(ctx,
-		ident.NewIDMatcher(nsID),
-		ident.NewIDMatcher(id),
-		gomock.Any(),
-		at, value, xtime.Second, nil,
-	).Return(nil)
+func TestServiceAggregate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 
-	request := &rpc.WriteTaggedRequest{
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().IsOverloaded().Return(false)
+
+	service := NewService(mockDB, nil).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	start := time.Now().Add(-2 * time.Hour)
+	end := start.Add(2 * time.Hour)
+
+	start, end = start.Truncate(time.Second), end.Truncate(time.Second)
+	nsID := "metrics"
+
+	startNanos, err := convert.ToValue(start, rpc.TimeType_UNIX_NANOSECONDS)
+	require.NoError(t, err)
+	endNanos, err := convert.ToValue(end, rpc.TimeType_UNIX_NANOSECONDS)
+	require.NoError(t, err)
+	var limit int64 = 2
+
+	req, err := idx.NewWildcardQuery([]byte("city"), []byte("new*"))
+	require.NoError(t, err)
+	data, err := idx.Marshal(req)
+	require.NoError(t, err)
+	qry := index.Query{Query: req}
+
+	results := index.NewAggregateResults()
+	results.Add([]m3ninxindex.TermFrequency{
+		{Term: []byte("newark"), Count: 2},
+		{Term: []byte("new york"), Count: 7},
+		{Term: []byte("newcastle"), Count: 3},
+	})
+	mockDB.EXPECT().AggregateTerms(
+		ctx,
+		ident.NewIDMatcher(nsID),
+		index.NewQueryMatcher(qry),
+		index.QueryOptions{
+			StartInclusive: start,
+			EndExclusive:   end,
+			Limit:          2,
+		}).Return(index.AggregateQueryResults{Results: results}, nil)
+
+	resp, err := service.Aggregate(tctx, &rpc.AggregateQueryRequest{
+		NameSpace:     []byte(nsID),
+		Query:         data,
+		RangeStart:    startNanos,
+		RangeEnd:      endNanos,
+		Limit:         &limit,
+		RangeTimeType: rpc.TimeType_UNIX_NANOSECONDS,
+	})
+	require.NoError(t, err)
+
+	require.False(t, resp.Exhaustive)
+	require.Equal(t, []*rpc.AggregateQueryResultTerm{
+		{Term: []byte("new york"), Count: 7},
+		{Term: []byte("newcastle"), Count: 3},
+	}, resp.Terms)
+}
+
+func TestServiceAggregateErrs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().IsOverloaded().Return(false).Times(2)
+
+	service := NewService(mockDB, nil).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	// Malformed queries are bad requests.
+	_, err := service.Aggregate(tctx, &rpc.AggregateQueryRequest{
+		NameSpace: []byte("metrics"),
+		Query:     []byte("not a query"),
+	})
+	require.Error(t, err)
+	rpcErr, ok := err.(*rpc.Error)
+	require.True(t, ok)
+	require.True(t, tterrors.IsBadRequestError(rpcErr))
+
+	req := idx.MustCreateTermQuery([]byte("city"), []byte("new york"))
+	data, err := idx.Marshal(req)
+	require.NoError(t, err)
+
+	mockDB.EXPECT().AggregateTerms(ctx, ident.NewIDMatcher("metrics"), gomock.Any(), gomock.Any()).
+		Return(index.AggregateQueryResults{}, fmt.Errorf("random err"))
+	_, err = service.Aggregate(tctx, &rpc.AggregateQueryRequest{
+		NameSpace: []byte("metrics"),
+		Query:     data,
+	})
+	require.Error(t, err)
+}
+
+func TestServiceAggregateTagValues(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().IsOverloaded().Return(false)
+
+	service := NewService(mockDB, nil).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	end := start.Add(2 * time.Hour)
+	nsID := "metrics"
+
+	startNanos, err := convert.ToValue(start, rpc.TimeType_UNIX_NANOSECONDS)
+	require.NoError(t, err)
+	endNanos, err := convert.ToValue(end, rpc.TimeType_UNIX_NANOSECONDS)
+	require.NoError(t, err)
+	var limit int64 = 2
+	cursor := index.NewTagValuesCursor([]byte("new"))
+
+	mockDB.EXPECT().AggregateTagValues(
+		ctx,
+		ident.NewIDMatcher(nsID),
+		index.AggregateTagValuesOptions{
+			StartInclusive: start,
+			EndExclusive:   end,
+			TagName:        []byte("city"),
+			TagValuePrefix: []byte("new"),
+			Limit:          2,
+			Cursor:         cursor,
+		}).Return(index.AggregateTagValuesResults{
+		Values: []m3ninxindex.TermFrequency{
+			{Term: []byte("new york"), Count: 7},
+			{Term: []byte("newark"), Count: 2},
+		},
+		Cursor: index.NewTagValuesCursor([]byte("newark")),
+	}, nil)
+
+	resp, err := service.AggregateTagValues(tctx, &rpc.AggregateTagValuesRequest{
+		NameSpace:      []byte(nsID),
+		TagName:        []byte("city"),
+		TagValuePrefix: []byte("new"),
+		RangeStart:     startNanos,
+		RangeEnd:       endNanos,
+		Limit:          &limit,
+		RangeTimeType:  rpc.TimeType_UNIX_NANOSECONDS,
+		Cursor:         cursor,
+	})
+	require.NoError(t, err)
+
+	require.False(t, resp.Exhaustive)
+	require.Equal(t, index.NewTagValuesCursor([]byte("newark")), resp.Cursor)
+	require.Equal(t, []*rpc.AggregateQueryResultTerm{
+		{Term: []byte("new york"), Count: 7},
+		{Term: []byte("newark"), Count: 2},
+	}, resp.Values)
+}
+
+func TestServiceWrite(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+
+	service := NewService(mockDB, nil).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	nsID := "metrics"
+
+	id := "foo"
+
+	at := time.Now().Truncate(time.Second)
+	value := 42.42
+
+	mockDB.EXPECT().
+		Write(ctx, ident.NewIDMatcher(nsID), ident.NewIDMatcher(id), at, value, xtime.Second, nil).
+		Return(nil)
+
+	err := service.Write(tctx, &rpc.WriteRequest{
+		NameSpace: nsID,
+		ID:        id,
+		Datapoint: &rpc.Datapoint{
+			Timestamp:         at.Unix(),
+			TimestampTimeType: rpc.TimeType_UNIX_SECONDS,
+			Value:             value,
+		},
+	})
+	require.NoError(t, err)
+}
+
+func TestServiceWriteReadOnly(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	runtimeOptsMgr := runtime.NewOptionsManager()
+	defer runtimeOptsMgr.Close()
+	require.NoError(t, runtimeOptsMgr.Update(runtimeOptsMgr.Get().SetReadOnly(true)))
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().
+		Return(testStorageOpts.SetRuntimeOptionsManager(runtimeOptsMgr)).
+		AnyTimes()
+	mockDB.EXPECT().IsBootstrapped().Return(true).AnyTimes()
+
+	service := NewService(mockDB, nil).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	var (
+		nsID  = "metrics"
+		id    = "foo"
+		at    = time.Now().Truncate(time.Second)
+		value = 42.42
+		req   = &rpc.WriteRequest{
+			NameSpace: nsID,
+			ID:        id,
+			Datapoint: &rpc.Datapoint{
+				Timestamp:         at.Unix(),
+				TimestampTimeType: rpc.TimeType_UNIX_SECONDS,
+				Value:             value,
+			},
+		}
+	)
+
+	// Writes are refused while the node is read only.
+	err := service.Write(tctx, req)
+	require.Error(t, err)
+	rpcErr, ok := err.(*rpc.Error)
+	require.True(t, ok)
+	assert.True(t, tterrors.IsReadOnlyError(rpcErr))
+
+	err = service.WriteBatchRaw(tctx, &rpc.WriteBatchRawRequest{
+		NameSpace: []byte(nsID),
+		Elements: []*rpc.WriteBatchRawRequestElement{
+			{ID: []byte(id), Datapoint: req.Datapoint},
+			{ID: []byte("bar"), Datapoint: req.Datapoint},
+		},
+	})
+	require.Error(t, err)
+	batchErrs, ok := err.(*rpc.WriteBatchRawErrors)
+	require.True(t, ok)
+	require.Equal(t, 2, len(batchErrs.Errors))
+	for i, batchErr := range batchErrs.Errors {
+		assert.Equal(t, int64(i), batchErr.Index)
+		assert.True(t, tterrors.IsReadOnlyError(batchErr.Err))
+	}
+
+	health, err := service.Health(tctx)
+	require.NoError(t, err)
+	assert.True(t, health.Ok)
+	assert.Equal(t, "read-only", health.Status)
+
+	// Writes are accepted again once the node is no longer read only.
+	require.NoError(t, runtimeOptsMgr.Update(runtimeOptsMgr.Get().SetReadOnly(false)))
+	require.True(t, xclock.WaitUntil(func() bool {
+		return !service.isReadOnly()
+	}, 5*time.Second))
+
+	mockDB.EXPECT().
+		Write(ctx, ident.NewIDMatcher(nsID), ident.NewIDMatcher(id), at, value, xtime.Second, nil).
+		Return(nil)
+	require.NoError(t, service.Write(tctx, req))
+
+	health, err = service.Health(tctx)
+	require.NoError(t, err)
+	assert.Equal(t, "up", health.Status)
+}
+
+func TestServiceWriteTagged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+
+	service := NewService(mockDB, nil).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	var (
+		nsID      = "metrics"
+		id        = "foo"
+		tagNames  = []string{"foo", "bar", "baz"}
+		tagValues = []string{"cmon", "keep", "going"}
+		at        = time.Now().Truncate(time.Second)
+		value     = 42.42
+	)
+
+	mockDB.EXPECT().WriteTagged(ctx,
+		ident.NewIDMatcher(nsID),
+		ident.NewIDMatcher(id),
+		gomock.Any(),
+		at, value, xtime.Second, nil,
+	).Return(nil)
+
+	request := &rpc.WriteTaggedRequest{
 		NameSpace: nsID,
 		ID:        id,
 		Datapoint: &rpc.Datapoint{
@@ -1449,6 +2104,65 @@ func TestServiceWriteBatchRaw(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestServiceWriteBatchRawErrorClassification(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+
+	service := NewService(mockDB, nil).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	nsID := "metrics"
+
+	values := []struct {
+		id  string
+		t   time.Time
+		v   float64
+		err error
+	}{
+		{"foo", time.Now().Truncate(time.Second), 12.34, m3dberrors.ErrTooPast},
+		{"bar", time.Now().Truncate(time.Second), 42.42,
+			m3dberrors.NewResourceExhaustedError(fmt.Errorf("rate limited"))},
+		{"baz", time.Now().Truncate(time.Second), 1.0,
+			m3dberrors.NewInternalError(fmt.Errorf("invariant violated"))},
+	}
+	for _, w := range values {
+		mockDB.EXPECT().
+			Write(ctx, ident.NewIDMatcher(nsID), ident.NewIDMatcher(w.id), w.t, w.v, xtime.Second, nil).
+			Return(w.err)
+	}
+
+	var elements []*rpc.WriteBatchRawRequestElement
+	for _, w := range values {
+		elem := &rpc.WriteBatchRawRequestElement{
+			ID: []byte(w.id),
+			Datapoint: &rpc.Datapoint{
+				Timestamp:         w.t.Unix(),
+				TimestampTimeType: rpc.TimeType_UNIX_SECONDS,
+				Value:             w.v,
+			},
+		}
+		elements = append(elements, elem)
+	}
+
+	err := service.WriteBatchRaw(tctx, &rpc.WriteBatchRawRequest{
+		NameSpace: []byte(nsID),
+		Elements:  elements,
+	})
+	require.Error(t, err)
+	batchErrs, ok := err.(*rpc.WriteBatchRawErrors)
+	require.True(t, ok)
+	require.Equal(t, 3, len(batchErrs.Errors))
+	assert.True(t, tterrors.IsBadRequestError(batchErrs.Errors[0].Err))
+	assert.True(t, tterrors.IsResourceExhaustedError(batchErrs.Errors[1].Err))
+	assert.True(t, tterrors.IsInternalError(batchErrs.Errors[2].Err))
+}
+
 func TestServiceWriteTaggedBatchRaw(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -1511,6 +2225,85 @@ func TestServiceWriteTaggedBatchRaw(t *testing.T) {
 	})
 	require.NoError(t, err)
 }
+
+func TestServiceWriteTaggedBatchRawGroupsSeriesWrites(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+
+	mockDecoder := serialize.NewMockTagDecoder(ctrl)
+	mockDecoder.EXPECT().Reset(gomock.Any()).AnyTimes()
+	mockDecoder.EXPECT().Err().Return(nil).AnyTimes()
+	mockDecoder.EXPECT().Close().AnyTimes()
+	mockDecoderPool := serialize.NewMockTagDecoderPool(ctrl)
+	mockDecoderPool.EXPECT().Get().Return(mockDecoder).AnyTimes()
+
+	opts := tchannelthrift.NewOptions().
+		SetTagDecoderPool(mockDecoderPool)
+
+	service := NewService(mockDB, opts).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	nsID := "metrics"
+	now := time.Now().Truncate(time.Second)
+
+	values := []struct {
+		id        string
+		tagEncode string
+		t         time.Time
+		v         float64
+	}{
+		{"foo", "a|b", now, 1},
+		{"foo", "a|b", now.Add(time.Second), 2},
+		{"foo", "a|b", now.Add(2 * time.Second), 3},
+		{"bar", "c|dd", now, 42.42},
+	}
+
+	// Contiguous writes to the same series are written as a single batch
+	mockDB.EXPECT().
+		WriteTaggedBatch(ctx, ident.NewIDMatcher(nsID), ident.NewIDMatcher("foo"),
+			mockDecoder, []ts.Datapoint{
+				{Timestamp: now, Value: 1},
+				{Timestamp: now.Add(time.Second), Value: 2},
+				{Timestamp: now.Add(2 * time.Second), Value: 3},
+			}, xtime.Second, [][]byte{nil, nil, nil}).
+		Return([]error{nil, fmt.Errorf("write failed"), nil}, nil)
+	mockDB.EXPECT().
+		WriteTagged(ctx, ident.NewIDMatcher(nsID), ident.NewIDMatcher("bar"),
+			mockDecoder, now, 42.42, xtime.Second, nil).
+		Return(nil)
+
+	var elements []*rpc.WriteTaggedBatchRawRequestElement
+	for _, w := range values {
+		elem := &rpc.WriteTaggedBatchRawRequestElement{
+			ID:          []byte(w.id),
+			EncodedTags: []byte(w.tagEncode),
+			Datapoint: &rpc.Datapoint{
+				Timestamp:         w.t.Unix(),
+				TimestampTimeType: rpc.TimeType_UNIX_SECONDS,
+				Value:             w.v,
+			},
+		}
+		elements = append(elements, elem)
+	}
+
+	err := service.WriteTaggedBatchRaw(tctx, &rpc.WriteTaggedBatchRawRequest{
+		NameSpace: []byte(nsID),
+		Elements:  elements,
+	})
+	require.Error(t, err)
+
+	batchErrs, ok := err.(*rpc.WriteBatchRawErrors)
+	require.True(t, ok)
+	require.Equal(t, 1, len(batchErrs.Errors))
+	assert.Equal(t, int64(1), batchErrs.Errors[0].Index)
+}
+
 func TestServiceRepair(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -1554,6 +2347,47 @@ func TestServiceTruncate(t *testing.T) {
 	assert.Equal(t, truncated, r.NumSeries)
 }
 
+func TestServiceForceFlush(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+
+	service := NewService(mockDB, nil).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	var (
+		nsID  = "metrics"
+		start = time.Now().Truncate(time.Second).Add(-2 * time.Hour)
+		end   = start.Add(2 * time.Hour)
+		shard = int32(3)
+		force = true
+	)
+
+	mockDB.EXPECT().ForceFlush(ident.NewIDMatcher(nsID), storage.ForceFlushOptions{
+		Start:  start,
+		End:    end,
+		Shards: []uint32{uint32(shard)},
+		Force:  force,
+	}).Return(storage.ForceFlushResult{NumSeriesFlushed: 2, NumBytesWritten: 64}, nil)
+
+	r, err := service.ForceFlush(tctx, &rpc.ForceFlushRequest{
+		NameSpace:  []byte(nsID),
+		RangeStart: start.Unix(),
+		RangeEnd:   end.Unix(),
+		RangeType:  rpc.TimeType_UNIX_SECONDS,
+		Shard:      &shard,
+		Force:      &force,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), r.NumSeriesFlushed)
+	assert.Equal(t, int64(64), r.NumBytesWritten)
+}
+
 func TestServiceSetPersistRateLimit(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -31,7 +31,8 @@ import (
 // ManagedConcurrentBloomFilter is a container object that implements lifecycle
 // management on-top of a BloomFilter. I.E it wraps a bloom filter such that
 // all resources are released when the Close() method is called. It's also safe
-// for concurrent access
+// for concurrent access. A nil bloom filter is used for filesets written
+// without a bloom filter and reports that every value may be present.
 type ManagedConcurrentBloomFilter struct {
 	bloomFilter *bloom.ConcurrentReadOnlyBloomFilter
 	mmapBytes   []byte
@@ -39,21 +40,33 @@ type ManagedConcurrentBloomFilter struct {
 
 // Test tests whether a value is in the bloom filter
 func (bf *ManagedConcurrentBloomFilter) Test(value []byte) bool {
+	if bf == nil {
+		return true
+	}
 	return bf.bloomFilter.Test(value)
 }
 
 // M returns the number of elements in the bloom filter
 func (bf *ManagedConcurrentBloomFilter) M() uint {
+	if bf == nil {
+		return 0
+	}
 	return bf.bloomFilter.M()
 }
 
 // K returns the number of hash functions in the bloom filter
 func (bf *ManagedConcurrentBloomFilter) K() uint {
+	if bf == nil {
+		return 0
+	}
 	return bf.bloomFilter.K()
 }
 
 // Close closes the bloom filter, releasing any held resources
 func (bf *ManagedConcurrentBloomFilter) Close() error {
+	if bf == nil {
+		return nil
+	}
 	return mmap.Munmap(bf.mmapBytes)
 }
 
@@ -67,6 +80,16 @@ func newManagedConcurrentBloomFilter(
 	}
 }
 
+// openBloomFilterFile opens the bloom filter file of a fileset, returning a
+// nil file if the fileset was written without a bloom filter.
+func openBloomFilterFile(filePath string) (*os.File, error) {
+	fd, err := os.Open(filePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return fd, err
+}
+
 func newManagedConcurrentBloomFilterFromFile(
 	bloomFilterFd *os.File,
 	bloomFilterFdWithDigest digest.FdWithDigestReader,
@@ -74,6 +97,11 @@ func newManagedConcurrentBloomFilterFromFile(
 	numElementsM uint,
 	numHashesK uint,
 ) (*ManagedConcurrentBloomFilter, error) {
+	if bloomFilterFd == nil {
+		// Fileset was written without a bloom filter
+		return nil, nil
+	}
+
 	// Determine how many bytes to request for the mmap'd region
 	bloomFilterFdWithDigest.Reset(bloomFilterFd)
 	stat, err := bloomFilterFd.Stat()
@@ -23,8 +23,11 @@ package commitlog
 import (
 	"bufio"
 	"os"
+	"time"
 
 	"github.com/m3db/m3/src/dbnode/digest"
+
+	"github.com/uber-go/tally"
 )
 
 const (
@@ -41,12 +44,31 @@ type chunkReader struct {
 	buffer    *bufio.Reader
 	remaining int
 	charBuff  []byte
+
+	// decompressed holds the data of the current chunk if it was compressed,
+	// otherwise chunk data is read directly from the buffer.
+	compressed   bool
+	decompressed []byte
+	metrics      chunkReaderMetrics
+}
+
+type chunkReaderMetrics struct {
+	decompressLatency tally.Timer
+	decompressErrors  tally.Counter
 }
 
-func newChunkReader(bufferLen int) *chunkReader {
+func newChunkReaderMetrics(scope tally.Scope) chunkReaderMetrics {
+	return chunkReaderMetrics{
+		decompressLatency: scope.Timer("reads.chunk-decompress-latency"),
+		decompressErrors:  scope.Counter("reads.chunk-decompress-errors"),
+	}
+}
+
+func newChunkReader(bufferLen int, scope tally.Scope) *chunkReader {
 	return &chunkReader{
 		buffer:   bufio.NewReaderSize(nil, bufferLen),
 		charBuff: make([]byte, 1),
+		metrics:  newChunkReaderMetrics(scope),
 	}
 }
 
@@ -54,6 +76,8 @@ func (r *chunkReader) reset(fd *os.File) {
 	r.fd = fd
 	r.buffer.Reset(fd)
 	r.remaining = 0
+	r.compressed = false
+	r.decompressed = r.decompressed[:0]
 }
 
 func (r *chunkReader) readHeader() error {
@@ -75,6 +99,10 @@ func (r *chunkReader) readHeader() error {
 		return errCommitLogReaderChunkSizeChecksumMismatch
 	}
 
+	// Chunks written before compression was supported never set the flag
+	compressed := size&compressedChunkFlag != 0
+	size &^= compressedChunkFlag
+
 	// Discard the peeked header
 	if _, err := r.buffer.Discard(chunkHeaderLen); err != nil {
 		return err
@@ -90,12 +118,59 @@ func (r *chunkReader) readHeader() error {
 		return errCommitLogReaderChunkSizeChecksumMismatch
 	}
 
-	// Set remaining data to be consumed
-	r.remaining = int(size)
+	if !compressed {
+		// Set remaining data to be consumed
+		r.compressed = false
+		r.remaining = int(size)
+		return nil
+	}
+
+	err = r.decompress(data)
+
+	// Discard the compressed chunk whether or not it could be decompressed
+	// so the reader is never left positioned in the middle of a chunk
+	if _, discardErr := r.buffer.Discard(int(size)); discardErr != nil {
+		return discardErr
+	}
+	if err != nil {
+		r.metrics.decompressErrors.Inc(1)
+		return err
+	}
+
+	// Set remaining decompressed data to be consumed
+	r.compressed = true
+	r.remaining = len(r.decompressed)
 
 	return nil
 }
 
+func (r *chunkReader) decompress(data []byte) error {
+	start := time.Now()
+
+	if len(data) < compressedChunkHeaderLen {
+		return errCommitLogReaderChunkDecompress
+	}
+
+	var (
+		compression = CompressionType(data[0])
+		size        = int(endianness.Uint32(
+			data[compressedChunkHeaderTypeLen:compressedChunkHeaderLen]))
+	)
+	if cap(r.decompressed) < size {
+		r.decompressed = make([]byte, size)
+	}
+
+	decompressed, err := decompress(compression, r.decompressed[:size],
+		data[compressedChunkHeaderLen:])
+	if err != nil || len(decompressed) != size {
+		return errCommitLogReaderChunkDecompress
+	}
+
+	r.decompressed = decompressed
+	r.metrics.decompressLatency.Record(time.Since(start))
+	return nil
+}
+
 func (r *chunkReader) Read(p []byte) (int, error) {
 	size := len(p)
 	read := 0
@@ -103,8 +178,7 @@ func (r *chunkReader) Read(p []byte) (int, error) {
 	if r.remaining < size {
 		// Copy any remaining
 		if r.remaining > 0 {
-			n, err := r.buffer.Read(p[:r.remaining])
-			r.remaining -= n
+			n, err := r.readChunk(p[:r.remaining])
 			read += n
 			if err != nil {
 				return read, err
@@ -125,12 +199,26 @@ func (r *chunkReader) Read(p []byte) (int, error) {
 		return read, err
 	}
 
-	n, err := r.buffer.Read(p)
-	r.remaining -= n
+	n, err := r.readChunk(p)
 	read += n
 	return read, err
 }
 
+// readChunk reads from the current chunk, p must be no larger than the
+// remaining data in the current chunk.
+func (r *chunkReader) readChunk(p []byte) (int, error) {
+	if !r.compressed {
+		n, err := r.buffer.Read(p)
+		r.remaining -= n
+		return n, err
+	}
+
+	offset := len(r.decompressed) - r.remaining
+	n := copy(p, r.decompressed[offset:])
+	r.remaining -= n
+	return n, nil
+}
+
 func (r *chunkReader) ReadByte() (c byte, err error) {
 	if _, err := r.Read(r.charBuff); err != nil {
 		return byte(0), err
@@ -23,10 +23,14 @@ package commitlog
 import (
 	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/clock"
+	"github.com/m3db/m3/src/dbnode/runtime"
+	m3dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
 	"github.com/m3db/m3/src/dbnode/ts"
+	xclose "github.com/m3db/m3x/close"
 	"github.com/m3db/m3x/context"
 	xlog "github.com/m3db/m3x/log"
 	xtime "github.com/m3db/m3x/time"
@@ -37,7 +41,11 @@ import (
 var (
 	// ErrCommitLogQueueFull is raised when trying to write to the commit log
 	// when the queue is full
-	ErrCommitLogQueueFull = errors.New("commit log queue is full")
+	ErrCommitLogQueueFull = m3dberrors.NewResourceExhaustedError(errors.New("commit log queue is full"))
+
+	// ErrCommitLogWriteEvicted is raised for a queued write that was evicted
+	// from the commit log queue to make room for a newer write
+	ErrCommitLogWriteEvicted = m3dberrors.NewResourceExhaustedError(errors.New("commit log write evicted from full queue"))
 
 	errCommitLogClosed = errors.New("commit log is closed")
 
@@ -76,6 +84,16 @@ type commitLog struct {
 	// circular buffer to avoid central write lock contention
 	writes chan commitLogWrite
 
+	// The queue limit and policy are set by the runtime options and
+	// read atomically on each write so they can be changed at any time
+	queueLimit          int64
+	queuePolicy         int64
+	queueHighWatermark  int64
+	queueBlocked        int64
+	queueBlockedCond    *sync.Cond
+	queueClosing        int32
+	runtimeOptsListener xclose.SimpleCloser
+
 	flushMutex      sync.RWMutex
 	lastFlushAt     time.Time
 	pendingFlushFns []completionFn
@@ -88,13 +106,38 @@ type commitLog struct {
 }
 
 type commitLogMetrics struct {
-	queued      tally.Gauge
-	success     tally.Counter
-	errors      tally.Counter
-	openErrors  tally.Counter
-	closeErrors tally.Counter
-	flushErrors tally.Counter
-	flushDone   tally.Counter
+	queued             tally.Gauge
+	queueHighWatermark tally.Gauge
+	droppedCurrent     tally.Counter
+	droppedOldest      tally.Counter
+	blocked            tally.Counter
+	success            tally.Counter
+	errors             tally.Counter
+	openErrors         tally.Counter
+	closeErrors        tally.Counter
+	flushErrors        tally.Counter
+	flushDone          tally.Counter
+}
+
+func newCommitLogMetrics(scope tally.Scope) commitLogMetrics {
+	droppedScope := func(policy runtime.CommitLogQueuePolicy) tally.Scope {
+		return scope.Tagged(map[string]string{"policy": policy.String()})
+	}
+	return commitLogMetrics{
+		queued:             scope.Gauge("writes.queued"),
+		queueHighWatermark: scope.Gauge("writes.queue-high-watermark"),
+		droppedCurrent: droppedScope(runtime.CommitLogQueuePolicyDropCurrent).
+			Counter("writes.queue-dropped"),
+		droppedOldest: droppedScope(runtime.CommitLogQueuePolicyDropOldest).
+			Counter("writes.queue-dropped"),
+		blocked:     scope.Counter("writes.queue-blocked"),
+		success:     scope.Counter("writes.success"),
+		errors:      scope.Counter("writes.errors"),
+		openErrors:  scope.Counter("writes.open-errors"),
+		closeErrors: scope.Counter("writes.close-errors"),
+		flushErrors: scope.Counter("writes.flush-errors"),
+		flushDone:   scope.Counter("writes.flush-done"),
+	}
 }
 
 type valueType int
@@ -130,16 +173,11 @@ func NewCommitLog(opts Options) (CommitLog, error) {
 		log:                  iopts.Logger(),
 		newCommitLogWriterFn: newCommitLogWriter,
 		writes:               make(chan commitLogWrite, opts.BacklogQueueSize()),
+		queueLimit:           int64(opts.BacklogQueueSize()),
+		queuePolicy:          int64(runtime.CommitLogQueuePolicyDropCurrent),
+		queueBlockedCond:     sync.NewCond(&sync.Mutex{}),
 		closeErr:             make(chan error),
-		metrics: commitLogMetrics{
-			queued:      scope.Gauge("writes.queued"),
-			success:     scope.Counter("writes.success"),
-			errors:      scope.Counter("writes.errors"),
-			openErrors:  scope.Counter("writes.open-errors"),
-			closeErrors: scope.Counter("writes.close-errors"),
-			flushErrors: scope.Counter("writes.flush-errors"),
-			flushDone:   scope.Counter("writes.flush-done"),
-		},
+		metrics:              newCommitLogMetrics(scope),
 	}
 
 	switch opts.Strategy() {
@@ -171,6 +209,10 @@ func (l *commitLog) Open() error {
 		l.log.Fatalf("fatal commit log error: %v", err)
 	}
 
+	// Apply the queue limit and policy and watch for updates to them
+	runtimeOptsMgr := l.opts.FilesystemOptions().RuntimeOptionsManager()
+	l.runtimeOptsListener = runtimeOptsMgr.RegisterListener(l)
+
 	// Asynchronously write
 	go l.write()
 
@@ -189,6 +231,8 @@ func (l *commitLog) flushEvery(interval time.Duration) {
 
 	for {
 		l.metrics.queued.Update(float64(len(l.writes)))
+		l.metrics.queueHighWatermark.Update(float64(
+			atomic.SwapInt64(&l.queueHighWatermark, int64(len(l.writes)))))
 
 		sleepFor := interval
 
@@ -223,6 +267,13 @@ func (l *commitLog) flushEvery(interval time.Duration) {
 
 func (l *commitLog) write() {
 	for write := range l.writes {
+		if atomic.LoadInt64(&l.queueBlocked) > 0 {
+			// Wake writers blocked on the queue now there is room
+			l.queueBlockedCond.L.Lock()
+			l.queueBlockedCond.Broadcast()
+			l.queueBlockedCond.L.Unlock()
+		}
+
 		// For writes requiring acks add to pending acks
 		if write.completionFn != nil {
 			l.pendingFlushFns = append(l.pendingFlushFns, write.completionFn)
@@ -374,18 +425,12 @@ func (l *commitLog) writeWait(
 		completionFn: completion,
 	}
 
-	enqueued := false
-
-	select {
-	case l.writes <- write:
-		enqueued = true
-	default:
-	}
+	err := l.enqueue(write)
 
 	l.RUnlock()
 
-	if !enqueued {
-		return ErrCommitLogQueueFull
+	if err != nil {
+		return err
 	}
 
 	wg.Wait()
@@ -413,24 +458,125 @@ func (l *commitLog) writeBehind(
 		annotation: annotation,
 	}
 
-	enqueued := false
+	err := l.enqueue(write)
+
+	l.RUnlock()
+
+	return err
+}
+
+// enqueue enqueues a write applying the queue policy if the queue is full,
+// it must be called while holding the read lock so the queue remains open.
+func (l *commitLog) enqueue(write commitLogWrite) error {
+	for {
+		if len(l.writes) < int(atomic.LoadInt64(&l.queueLimit)) {
+			select {
+			case l.writes <- write:
+				l.updateQueueHighWatermark()
+				return nil
+			default:
+			}
+		}
+
+		policy := runtime.CommitLogQueuePolicy(atomic.LoadInt64(&l.queuePolicy))
+		if policy == runtime.CommitLogQueuePolicyDropOldest &&
+			l.opts.Strategy() != StrategyWriteWait {
+			// NB: queued writes behind have already been acknowledged, evicting
+			// one would lose acknowledged data so the incoming write is rejected.
+			policy = runtime.CommitLogQueuePolicyDropCurrent
+		}
+
+		switch policy {
+		case runtime.CommitLogQueuePolicyDropOldest:
+			l.evictOldest()
+		case runtime.CommitLogQueuePolicyBlock:
+			l.metrics.blocked.Inc(1)
+			if err := l.waitForQueue(); err != nil {
+				return err
+			}
+		default:
+			l.metrics.droppedCurrent.Inc(1)
+			return ErrCommitLogQueueFull
+		}
+	}
+}
 
+func (l *commitLog) evictOldest() {
 	select {
-	case l.writes <- write:
-		enqueued = true
+	case write := <-l.writes:
+		if write.valueType == flushValueType {
+			// Flushes are requested periodically, no need to count the eviction
+			return
+		}
+		l.metrics.droppedOldest.Inc(1)
+		if write.completionFn != nil {
+			write.completionFn(ErrCommitLogWriteEvicted)
+		}
 	default:
 	}
+}
 
-	l.RUnlock()
+// waitForQueue waits until there is room in the queue or the policy no longer
+// blocks, it returns an error if the commit log is closed while waiting.
+func (l *commitLog) waitForQueue() error {
+	var err error
+	l.queueBlockedCond.L.Lock()
+	atomic.AddInt64(&l.queueBlocked, 1)
+	for l.queueFullAndBlocking() {
+		if atomic.LoadInt32(&l.queueClosing) == 1 {
+			err = errCommitLogClosed
+			break
+		}
+		l.queueBlockedCond.Wait()
+	}
+	atomic.AddInt64(&l.queueBlocked, -1)
+	l.queueBlockedCond.L.Unlock()
+	return err
+}
+
+func (l *commitLog) queueFullAndBlocking() bool {
+	policy := runtime.CommitLogQueuePolicy(atomic.LoadInt64(&l.queuePolicy))
+	return policy == runtime.CommitLogQueuePolicyBlock &&
+		len(l.writes) >= int(atomic.LoadInt64(&l.queueLimit))
+}
 
-	if !enqueued {
-		return ErrCommitLogQueueFull
+func (l *commitLog) updateQueueHighWatermark() {
+	queued := int64(len(l.writes))
+	for {
+		curr := atomic.LoadInt64(&l.queueHighWatermark)
+		if queued <= curr {
+			return
+		}
+		if atomic.CompareAndSwapInt64(&l.queueHighWatermark, curr, queued) {
+			return
+		}
 	}
+}
 
-	return nil
+func (l *commitLog) SetRuntimeOptions(value runtime.Options) {
+	// The queue limit can't exceed the capacity of the queue
+	limit := value.CommitLogQueueLimit()
+	if limit <= 0 || limit > cap(l.writes) {
+		limit = cap(l.writes)
+	}
+	atomic.StoreInt64(&l.queueLimit, int64(limit))
+	atomic.StoreInt64(&l.queuePolicy, int64(value.CommitLogQueuePolicy()))
+
+	// Wake any blocked writers in case the limit was raised or the
+	// policy no longer blocks
+	l.queueBlockedCond.L.Lock()
+	l.queueBlockedCond.Broadcast()
+	l.queueBlockedCond.L.Unlock()
 }
 
 func (l *commitLog) Close() error {
+	// Writers blocked on a full queue hold the read lock, wake them so they
+	// fail rather than preventing the write lock from being acquired
+	atomic.StoreInt32(&l.queueClosing, 1)
+	l.queueBlockedCond.L.Lock()
+	l.queueBlockedCond.Broadcast()
+	l.queueBlockedCond.L.Unlock()
+
 	l.Lock()
 	if l.closed {
 		l.Unlock()
@@ -439,6 +585,10 @@ func (l *commitLog) Close() error {
 
 	l.closed = true
 	close(l.writes)
+	if l.runtimeOptsListener != nil {
+		l.runtimeOptsListener.Close()
+		l.runtimeOptsListener = nil
+	}
 	l.Unlock()
 
 	// Receive the result of closing the writer from asynchronous writer
@@ -34,6 +34,7 @@ import (
 	"github.com/m3db/bitset"
 	"github.com/m3db/m3/src/dbnode/clock"
 	"github.com/m3db/m3/src/dbnode/persist/fs"
+	"github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3x/context"
 	"github.com/m3db/m3x/ident"
@@ -51,6 +52,7 @@ type overrides struct {
 	flushInterval    *time.Duration
 	backlogQueueSize *int
 	strategy         Strategy
+	compression      CompressionType
 }
 
 func newTestOptions(
@@ -89,7 +91,8 @@ func newTestOptions(
 		opts = opts.SetBacklogQueueSize(*overrides.backlogQueueSize)
 	}
 
-	opts = opts.SetStrategy(overrides.strategy)
+	opts = opts.SetStrategy(overrides.strategy).
+		SetCompression(overrides.compression)
 
 	return opts, scope
 }
@@ -556,6 +559,39 @@ func TestCommitLogWriteBehind(t *testing.T) {
 	assertCommitLogWritesByIterating(t, commitLog, writes)
 }
 
+func TestCommitLogWriteCompressed(t *testing.T) {
+	opts, scope := newTestOptions(t, overrides{
+		strategy:    StrategyWriteWait,
+		compression: CompressionSnappy,
+	})
+	defer cleanup(t, opts)
+
+	commitLog := newTestCommitLog(t, opts)
+
+	var writes []testWrite
+	for i := 0; i < 100; i++ {
+		series := testSeries(uint64(i), fmt.Sprintf("foo.bar.%d", i), testTags1, uint32(i))
+		for j := 0; j < 10; j++ {
+			writes = append(writes, testWrite{series, time.Now(), float64(j), xtime.Second, []byte{1, 2, 3}, nil})
+		}
+	}
+
+	// Call write sync
+	writeCommitLogs(t, scope, commitLog, writes).Wait()
+
+	// Close the commit log and consequently flush
+	require.NoError(t, commitLog.Close())
+
+	// Assert writes occurred by reading the commit log
+	assertCommitLogWritesByIterating(t, commitLog, writes)
+
+	uncompressed, ok := snapshotCounterValue(scope, "commitlog.writes.chunk-uncompressed-bytes")
+	require.True(t, ok)
+	compressed, ok := snapshotCounterValue(scope, "commitlog.writes.chunk-compressed-bytes")
+	require.True(t, ok)
+	require.True(t, compressed.Value() < uncompressed.Value())
+}
+
 func TestCommitLogWriteErrorOnClosed(t *testing.T) {
 	opts, _ := newTestOptions(t, overrides{})
 	defer cleanup(t, opts)
@@ -823,6 +859,284 @@ func TestCommitLogFailOnFlushError(t *testing.T) {
 	require.Equal(t, int64(1), flushErrors.Value())
 }
 
+type slowCommitLogWriter struct {
+	sync.Mutex
+	*mockCommitLogWriter
+
+	started chan struct{}
+	release chan struct{}
+	written []uint64
+}
+
+// newTestSlowCommitLog returns a commit log with a writer that blocks on its
+// first write until released so that subsequent writes fill the queue.
+func newTestSlowCommitLog(
+	t *testing.T,
+	strategy Strategy,
+	policy runtime.CommitLogQueuePolicy,
+	queueLimit int,
+) (*commitLog, *slowCommitLogWriter, tally.TestScope) {
+	backlogQueueSize := 8
+	flushInterval := time.Duration(0)
+	opts, scope := newTestOptions(t, overrides{
+		backlogQueueSize: &backlogQueueSize,
+		flushInterval:    &flushInterval,
+		strategy:         strategy,
+	})
+
+	runtimeOptsMgr := opts.FilesystemOptions().RuntimeOptionsManager()
+	require.NoError(t, runtimeOptsMgr.Update(runtimeOptsMgr.Get().
+		SetCommitLogQueuePolicy(policy).
+		SetCommitLogQueueLimit(queueLimit)))
+
+	commitLogI, err := NewCommitLog(opts)
+	require.NoError(t, err)
+	commitLog := commitLogI.(*commitLog)
+
+	writer := &slowCommitLogWriter{
+		mockCommitLogWriter: newMockCommitLogWriter(),
+		started:             make(chan struct{}),
+		release:             make(chan struct{}),
+	}
+	writer.writeFn = func(series Series, _ ts.Datapoint, _ xtime.Unit, _ ts.Annotation) error {
+		writer.Lock()
+		first := len(writer.written) == 0
+		writer.written = append(writer.written, series.UniqueIndex)
+		writer.Unlock()
+		if first {
+			close(writer.started)
+			<-writer.release
+		}
+		return nil
+	}
+	commitLog.newCommitLogWriterFn = func(
+		onFlush flushFn,
+		_ Options,
+	) commitLogWriter {
+		// Acknowledge the writes waiting for a flush once the writer is closed
+		writer.closeFn = func() error {
+			onFlush(nil)
+			return nil
+		}
+		return writer
+	}
+
+	require.NoError(t, commitLog.Open())
+	return commitLog, writer, scope
+}
+
+// fillTestSlowCommitLog writes to the commit log until the writer is stalled
+// on the first write and the queue holds the queue limit of writes.
+func fillTestSlowCommitLog(
+	t *testing.T,
+	commitLog *commitLog,
+	writer *slowCommitLogWriter,
+	queueLimit int,
+) {
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	dp := ts.Datapoint{Timestamp: time.Now(), Value: 1}
+	series := testSeries(0, "foo.0", testTags1, 0)
+	require.NoError(t, commitLog.Write(ctx, series, dp, xtime.Second, nil))
+	<-writer.started
+
+	for i := 1; i <= queueLimit; i++ {
+		series := testSeries(uint64(i), fmt.Sprintf("foo.%d", i), testTags1, 0)
+		require.NoError(t, commitLog.Write(ctx, series, dp, xtime.Second, nil))
+	}
+	require.Equal(t, int64(queueLimit), atomic.LoadInt64(&commitLog.queueHighWatermark))
+}
+
+func snapshotDroppedValue(
+	scope tally.TestScope,
+	policy runtime.CommitLogQueuePolicy,
+) int64 {
+	counters := scope.Snapshot().Counters()
+	c, ok := counters[tally.KeyForPrefixedStringMap("commitlog.writes.queue-dropped",
+		map[string]string{"policy": policy.String()})]
+	if !ok {
+		return 0
+	}
+	return c.Value()
+}
+
+func TestCommitLogQueuePolicyDropCurrent(t *testing.T) {
+	queueLimit := 2
+	commitLog, writer, scope := newTestSlowCommitLog(t, StrategyWriteBehind,
+		runtime.CommitLogQueuePolicyDropCurrent, queueLimit)
+	defer cleanup(t, commitLog.opts)
+
+	fillTestSlowCommitLog(t, commitLog, writer, queueLimit)
+
+	// The queue is at its limit so the incoming write is rejected
+	ctx := context.NewContext()
+	defer ctx.Close()
+	series := testSeries(3, "foo.3", testTags1, 0)
+	dp := ts.Datapoint{Timestamp: time.Now(), Value: 1}
+	err := commitLog.Write(ctx, series, dp, xtime.Second, nil)
+	require.Equal(t, ErrCommitLogQueueFull, err)
+	require.Equal(t, int64(1), snapshotDroppedValue(scope, runtime.CommitLogQueuePolicyDropCurrent))
+
+	close(writer.release)
+	require.NoError(t, commitLog.Close())
+	require.Equal(t, []uint64{0, 1, 2}, writer.written)
+}
+
+func TestCommitLogQueuePolicyDropOldest(t *testing.T) {
+	queueLimit := 2
+	commitLog, writer, scope := newTestSlowCommitLog(t, StrategyWriteWait,
+		runtime.CommitLogQueuePolicyDropOldest, queueLimit)
+	defer cleanup(t, commitLog.opts)
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	dp := ts.Datapoint{Timestamp: time.Now(), Value: 1}
+	write := func(i int) chan error {
+		result := make(chan error, 1)
+		go func() {
+			series := testSeries(uint64(i), fmt.Sprintf("foo.%d", i), testTags1, 0)
+			result <- commitLog.Write(ctx, series, dp, xtime.Second, nil)
+		}()
+		return result
+	}
+
+	// Fill the queue while the writer is stalled on the first write
+	results := []chan error{write(0)}
+	<-writer.started
+	for i := 1; i <= queueLimit; i++ {
+		results = append(results, write(i))
+		for len(commitLog.writes) != i {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// The queue is at its limit so the oldest queued write, which is yet to be
+	// acknowledged, is evicted
+	results = append(results, write(3))
+	require.Equal(t, ErrCommitLogWriteEvicted, <-results[1])
+	require.Equal(t, int64(1), snapshotDroppedValue(scope, runtime.CommitLogQueuePolicyDropOldest))
+
+	close(writer.release)
+	require.NoError(t, commitLog.Close())
+	for _, i := range []int{0, 2, 3} {
+		require.NoError(t, <-results[i])
+	}
+	require.Equal(t, []uint64{0, 2, 3}, writer.written)
+}
+
+func TestCommitLogQueuePolicyDropOldestWriteBehind(t *testing.T) {
+	queueLimit := 2
+	commitLog, writer, scope := newTestSlowCommitLog(t, StrategyWriteBehind,
+		runtime.CommitLogQueuePolicyDropOldest, queueLimit)
+	defer cleanup(t, commitLog.opts)
+
+	fillTestSlowCommitLog(t, commitLog, writer, queueLimit)
+
+	// The queued writes have already been acknowledged so the incoming write
+	// is rejected rather than evicting one of them
+	ctx := context.NewContext()
+	defer ctx.Close()
+	series := testSeries(3, "foo.3", testTags1, 0)
+	dp := ts.Datapoint{Timestamp: time.Now(), Value: 1}
+	err := commitLog.Write(ctx, series, dp, xtime.Second, nil)
+	require.Equal(t, ErrCommitLogQueueFull, err)
+	require.Equal(t, int64(1), snapshotDroppedValue(scope, runtime.CommitLogQueuePolicyDropCurrent))
+	require.Equal(t, int64(0), snapshotDroppedValue(scope, runtime.CommitLogQueuePolicyDropOldest))
+
+	close(writer.release)
+	require.NoError(t, commitLog.Close())
+	require.Equal(t, []uint64{0, 1, 2}, writer.written)
+}
+
+func TestCommitLogQueuePolicyBlock(t *testing.T) {
+	queueLimit := 2
+	commitLog, writer, scope := newTestSlowCommitLog(t, StrategyWriteBehind,
+		runtime.CommitLogQueuePolicyBlock, queueLimit)
+	defer cleanup(t, commitLog.opts)
+
+	fillTestSlowCommitLog(t, commitLog, writer, queueLimit)
+
+	// The queue is at its limit so the incoming write blocks
+	ctx := context.NewContext()
+	defer ctx.Close()
+	dp := ts.Datapoint{Timestamp: time.Now(), Value: 1}
+	blockedWrite := func(i int) chan error {
+		result := make(chan error, 1)
+		go func() {
+			series := testSeries(uint64(i), fmt.Sprintf("foo.%d", i), testTags1, 0)
+			result <- commitLog.Write(ctx, series, dp, xtime.Second, nil)
+		}()
+		for {
+			blocked, ok := snapshotCounterValue(scope, "commitlog.writes.queue-blocked")
+			if ok && blocked.Value() == int64(i-queueLimit) {
+				return result
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	first := blockedWrite(3)
+	select {
+	case err := <-first:
+		require.FailNow(t, "write did not block", "err: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// Changing the policy at runtime rejects the blocked write
+	runtimeOptsMgr := commitLog.opts.FilesystemOptions().RuntimeOptionsManager()
+	require.NoError(t, runtimeOptsMgr.Update(runtimeOptsMgr.Get().
+		SetCommitLogQueuePolicy(runtime.CommitLogQueuePolicyDropCurrent)))
+	require.Equal(t, ErrCommitLogQueueFull, <-first)
+
+	// Blocked writes are enqueued once the slow writer makes room
+	require.NoError(t, runtimeOptsMgr.Update(runtimeOptsMgr.Get().
+		SetCommitLogQueuePolicy(runtime.CommitLogQueuePolicyBlock)))
+	second := blockedWrite(4)
+	close(writer.release)
+	require.NoError(t, <-second)
+
+	require.NoError(t, commitLog.Close())
+	require.Equal(t, []uint64{0, 1, 2, 4}, writer.written)
+}
+
+func TestCommitLogQueuePolicyBlockClose(t *testing.T) {
+	queueLimit := 2
+	commitLog, writer, scope := newTestSlowCommitLog(t, StrategyWriteBehind,
+		runtime.CommitLogQueuePolicyBlock, queueLimit)
+	defer cleanup(t, commitLog.opts)
+
+	fillTestSlowCommitLog(t, commitLog, writer, queueLimit)
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+	blocked := make(chan error, 1)
+	go func() {
+		series := testSeries(3, "foo.3", testTags1, 0)
+		dp := ts.Datapoint{Timestamp: time.Now(), Value: 1}
+		blocked <- commitLog.Write(ctx, series, dp, xtime.Second, nil)
+	}()
+	for {
+		c, ok := snapshotCounterValue(scope, "commitlog.writes.queue-blocked")
+		if ok && c.Value() == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Closing fails the blocked write rather than waiting behind it
+	closed := make(chan error, 1)
+	go func() {
+		closed <- commitLog.Close()
+	}()
+	require.Equal(t, errCommitLogClosed, <-blocked)
+
+	close(writer.release)
+	require.NoError(t, <-closed)
+	require.Equal(t, []uint64{0, 1, 2}, writer.written)
+}
+
 var (
 	testTag1 = ident.StringTag("name1", "val1")
 	testTag2 = ident.StringTag("name2", "val2")
@@ -0,0 +1,130 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package commitlog
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang/snappy"
+)
+
+var (
+	errCompressionTypeUnspecified = errors.New("commit log compression type unspecified")
+)
+
+// CompressionType is the type of compression applied to each chunk of a
+// commit log as it is flushed to disk.
+type CompressionType uint8
+
+const (
+	// CompressionNone does not compress chunks, chunks written without
+	// compression are readable by readers that predate compression.
+	CompressionNone CompressionType = iota
+	// CompressionSnappy compresses chunks with snappy.
+	CompressionSnappy
+)
+
+// ValidCompressionTypes returns the valid compression types.
+func ValidCompressionTypes() []CompressionType {
+	return []CompressionType{CompressionNone, CompressionSnappy}
+}
+
+func (t CompressionType) String() string {
+	switch t {
+	case CompressionNone:
+		return "none"
+	case CompressionSnappy:
+		return "snappy"
+	}
+	return "unknown"
+}
+
+// ValidateCompressionType validates a compression type.
+func ValidateCompressionType(v CompressionType) error {
+	for _, valid := range ValidCompressionTypes() {
+		if valid == v {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid commit log CompressionType '%d' valid types are: %v",
+		uint8(v), ValidCompressionTypes())
+}
+
+// ParseCompressionType parses a CompressionType from a string.
+func ParseCompressionType(str string) (CompressionType, error) {
+	var r CompressionType
+	if str == "" {
+		return r, errCompressionTypeUnspecified
+	}
+	for _, valid := range ValidCompressionTypes() {
+		if str == valid.String() {
+			return valid, nil
+		}
+	}
+	return r, fmt.Errorf("invalid commit log CompressionType '%s' valid types are: %v",
+		str, ValidCompressionTypes())
+}
+
+// UnmarshalYAML unmarshals a CompressionType into a valid type from string.
+func (t *CompressionType) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	r, err := ParseCompressionType(str)
+	if err != nil {
+		return err
+	}
+	*t = r
+	return nil
+}
+
+// maxCompressedLen returns the max length of data once compressed.
+func maxCompressedLen(t CompressionType, dataLen int) int {
+	switch t {
+	case CompressionSnappy:
+		return snappy.MaxEncodedLen(dataLen)
+	}
+	return dataLen
+}
+
+// compress compresses data into dst which must be at least the max compressed
+// length of the data, returning the compressed slice of dst.
+func compress(t CompressionType, dst, data []byte) ([]byte, error) {
+	switch t {
+	case CompressionSnappy:
+		return snappy.Encode(dst, data), nil
+	}
+	return nil, ValidateCompressionType(t)
+}
+
+// decompress decompresses data into dst, returning the decompressed slice
+// of dst if dst is large enough otherwise a newly allocated slice.
+func decompress(t CompressionType, dst, data []byte) ([]byte, error) {
+	switch t {
+	case CompressionSnappy:
+		return snappy.Decode(dst, data)
+	case CompressionNone:
+		return nil, fmt.Errorf("commit log chunk marked compressed with CompressionType '%s'", t)
+	}
+	return nil, ValidateCompressionType(t)
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package commitlog
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/m3db/m3/src/dbnode/digest"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestCompressionTypeUnmarshalYAML(t *testing.T) {
+	for _, value := range ValidCompressionTypes() {
+		str := []byte(value.String() + "\n")
+
+		var compression CompressionType
+		require.NoError(t, yaml.Unmarshal(str, &compression))
+		require.Equal(t, value, compression)
+	}
+
+	var compression CompressionType
+	require.Error(t, yaml.Unmarshal([]byte("lz4\n"), &compression))
+}
+
+func newTestChunkFile(t *testing.T) (*os.File, func()) {
+	fd, err := ioutil.TempFile("", "commitlog-chunks")
+	require.NoError(t, err)
+	return fd, func() {
+		fd.Close()
+		os.Remove(fd.Name())
+	}
+}
+
+func writeTestChunks(
+	t *testing.T,
+	w *chunkWriter,
+	compression []CompressionType,
+	chunks [][]byte,
+) {
+	for i, chunk := range chunks {
+		w.compression = compression[i]
+		n, err := w.Write(chunk)
+		require.NoError(t, err)
+		require.Equal(t, chunkHeaderLen+len(chunk), n)
+	}
+}
+
+func testChunk(b byte, size int) []byte {
+	return bytes.Repeat([]byte{b}, size)
+}
+
+func TestChunkReaderReadsCompressedAndLegacyChunks(t *testing.T) {
+	fd, cleanup := newTestChunkFile(t)
+	defer cleanup()
+
+	scope := tally.NewTestScope("", nil)
+	w := newChunkWriter(func(error) {}, false, CompressionNone, scope)
+	w.fd = fd
+
+	var (
+		chunks = [][]byte{
+			testChunk(1, 1024),
+			testChunk(2, 1024),
+			// Incompressible chunks fallback to being written uncompressed
+			{3},
+			testChunk(4, 1024),
+		}
+		compression = []CompressionType{
+			CompressionNone,
+			CompressionSnappy,
+			CompressionSnappy,
+			CompressionNone,
+		}
+	)
+	writeTestChunks(t, w, compression, chunks)
+
+	fd, err := os.Open(fd.Name())
+	require.NoError(t, err)
+	defer fd.Close()
+
+	r := newChunkReader(4096, scope)
+	r.reset(fd)
+
+	for _, chunk := range chunks {
+		read := make([]byte, len(chunk))
+		_, err := io.ReadFull(r, read)
+		require.NoError(t, err)
+		require.Equal(t, chunk, read)
+	}
+
+	_, err = r.Read(make([]byte, 1))
+	require.Equal(t, io.EOF, err)
+}
+
+func TestChunkReaderSkipsCorruptCompressedChunk(t *testing.T) {
+	fd, cleanup := newTestChunkFile(t)
+	defer cleanup()
+
+	scope := tally.NewTestScope("", nil)
+	w := newChunkWriter(func(error) {}, false, CompressionNone, scope)
+	w.fd = fd
+
+	chunks := [][]byte{testChunk(1, 1024), testChunk(2, 1024)}
+	writeTestChunks(t, w, []CompressionType{CompressionSnappy, CompressionSnappy}, chunks)
+
+	// Corrupt the uncompressed size of the first chunk and write a valid
+	// checksum for the corrupted data so only decompression detects it
+	data, err := ioutil.ReadFile(fd.Name())
+	require.NoError(t, err)
+
+	size := int(endianness.Uint32(data[sizeStart:sizeEnd]) &^ compressedChunkFlag)
+	chunkData := data[chunkHeaderLen : chunkHeaderLen+size]
+	endianness.PutUint32(chunkData[compressedChunkHeaderTypeLen:compressedChunkHeaderLen], 1)
+	digest.
+		Buffer(data[checksumDataStart:checksumDataEnd]).
+		WriteDigest(digest.Checksum(chunkData))
+	require.NoError(t, ioutil.WriteFile(fd.Name(), data, 0666))
+
+	fd, err = os.Open(fd.Name())
+	require.NoError(t, err)
+	defer fd.Close()
+
+	r := newChunkReader(4096, scope)
+	r.reset(fd)
+
+	_, err = r.Read(make([]byte, 1))
+	require.Equal(t, errCommitLogReaderChunkDecompress, err)
+
+	errs, ok := snapshotCounterValue(scope, "reads.chunk-decompress-errors")
+	require.True(t, ok)
+	require.Equal(t, int64(1), errs.Value())
+
+	// The corrupt chunk is skipped and the next chunk is still readable
+	read := make([]byte, len(chunks[1]))
+	_, err = io.ReadFull(r, read)
+	require.NoError(t, err)
+	require.Equal(t, chunks[1], read)
+}
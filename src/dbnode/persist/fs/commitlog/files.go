@@ -53,7 +53,8 @@ func ReadLogInfo(filePath string, opts Options) (time.Time, time.Duration, int64
 		return time.Time{}, 0, 0, err
 	}
 
-	chunkReader := newChunkReader(opts.FlushSize())
+	scope := opts.InstrumentOptions().MetricsScope().SubScope("commitlog")
+	chunkReader := newChunkReader(opts.FlushSize(), scope)
 	chunkReader.reset(fd)
 	size, err := binary.ReadUvarint(chunkReader)
 	if err != nil {
@@ -42,6 +42,9 @@ const (
 	// defaultFlushSize is the default commit log flush size
 	defaultFlushSize = 65536
 
+	// defaultCompression is the default commit log chunk compression
+	defaultCompression = CompressionNone
+
 	// defaultBlockSize is the default commit log block size
 	defaultBlockSize = 15 * time.Minute
 
@@ -67,6 +70,7 @@ type options struct {
 	fsOpts           fs.Options
 	strategy         Strategy
 	flushSize        int
+	compression      CompressionType
 	flushInterval    time.Duration
 	backlogQueueSize int
 	bytesPool        pool.CheckedBytesPool
@@ -83,6 +87,7 @@ func NewOptions() Options {
 		fsOpts:           fs.NewOptions(),
 		strategy:         defaultStrategy,
 		flushSize:        defaultFlushSize,
+		compression:      defaultCompression,
 		flushInterval:    defaultFlushInterval,
 		backlogQueueSize: defaultBacklogQueueSize,
 		bytesPool: pool.NewCheckedBytesPool(nil, nil, func(s []pool.Bucket) pool.BytesPool {
@@ -105,6 +110,9 @@ func (o *options) Validate() error {
 	if o.ReadConcurrency() <= 0 {
 		return errReadConcurrencyPositive
 	}
+	if err := ValidateCompressionType(o.Compression()); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -168,6 +176,16 @@ func (o *options) FlushSize() int {
 	return o.flushSize
 }
 
+func (o *options) SetCompression(value CompressionType) Options {
+	opts := *o
+	opts.compression = value
+	return &opts
+}
+
+func (o *options) Compression() CompressionType {
+	return o.compression
+}
+
 func (o *options) SetFlushInterval(value time.Duration) Options {
 	opts := *o
 	opts.flushInterval = value
@@ -48,6 +48,7 @@ var (
 	emptyLogInfo schema.LogInfo
 
 	errCommitLogReaderChunkSizeChecksumMismatch = errors.New("commit log reader encountered chunk size checksum mismatch")
+	errCommitLogReaderChunkDecompress           = errors.New("commit log reader encountered chunk that could not be decompressed")
 	errCommitLogReaderIsNotReusable             = errors.New("commit log reader is not reusable")
 	errCommitLogReaderMultipleReadloops         = errors.New("commit log reader tried to open multiple readLoops, do not call Read() concurrently")
 	errCommitLogReaderMissingMetadata           = errors.New("commit log reader encountered a datapoint without corresponding metadata")
@@ -120,6 +121,7 @@ type reader struct {
 func newCommitLogReader(opts Options, seriesPredicate SeriesFilterPredicate) commitLogReader {
 	decodingOpts := opts.FilesystemOptions().DecodingOptions()
 	cancelCtx, cancelFunc := context.WithCancel(context.Background())
+	scope := opts.InstrumentOptions().MetricsScope().SubScope("commitlog")
 
 	numConc := opts.ReadConcurrency()
 	decoderQueues := make([]chan decoderArg, 0, numConc)
@@ -140,7 +142,7 @@ func newCommitLogReader(opts Options, seriesPredicate SeriesFilterPredicate) com
 		opts:              opts,
 		numConc:           int64(numConc),
 		checkedBytesPool:  opts.BytesPool(),
-		chunkReader:       newChunkReader(opts.FlushSize()),
+		chunkReader:       newChunkReader(opts.FlushSize(), scope),
 		infoDecoder:       msgpack.NewDecoder(decodingOpts),
 		infoDecoderStream: msgpack.NewDecoderStream(nil),
 		decoderQueues:     decoderQueues,
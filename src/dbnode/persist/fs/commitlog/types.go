@@ -141,6 +141,12 @@ type Options interface {
 	// FlushSize returns the flush size
 	FlushSize() int
 
+	// SetCompression sets the compression applied to each chunk as it is flushed
+	SetCompression(value CompressionType) Options
+
+	// Compression returns the compression applied to each chunk as it is flushed
+	Compression() CompressionType
+
 	// SetStrategy sets the strategy
 	SetStrategy(value Strategy) Options
 
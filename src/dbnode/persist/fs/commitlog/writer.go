@@ -37,6 +37,8 @@ import (
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3x/ident"
 	xtime "github.com/m3db/m3x/time"
+
+	"github.com/uber-go/tally"
 )
 
 const (
@@ -51,6 +53,19 @@ const (
 		chunkHeaderChecksumSizeLen +
 		chunkHeaderChecksumDataLen
 
+	// The lengths to reserve at the start of the data of a compressed chunk:
+	// - compression type uint8
+	// - uncompressed size uint32
+	compressedChunkHeaderTypeLen = 1
+	compressedChunkHeaderSizeLen = 4
+	compressedChunkHeaderLen     = compressedChunkHeaderTypeLen +
+		compressedChunkHeaderSizeLen
+
+	// compressedChunkFlag is set on the size of the chunk header when the
+	// chunk is compressed, chunks are never large enough to set this bit
+	// otherwise so legacy chunks are detected by its absence.
+	compressedChunkFlag uint32 = 1 << 31
+
 	defaultBitSetLength = 65536
 )
 
@@ -104,14 +119,18 @@ func newCommitLogWriter(
 	flushFn flushFn,
 	opts Options,
 ) commitLogWriter {
-	shouldFsync := opts.Strategy() == StrategyWriteWait
+	var (
+		shouldFsync = opts.Strategy() == StrategyWriteWait
+		scope       = opts.InstrumentOptions().MetricsScope().SubScope("commitlog")
+		chunkWriter = newChunkWriter(flushFn, shouldFsync, opts.Compression(), scope)
+	)
 
 	return &writer{
 		filePathPrefix:     opts.FilesystemOptions().FilePathPrefix(),
 		newFileMode:        opts.FilesystemOptions().NewFileMode(),
 		newDirectoryMode:   opts.FilesystemOptions().NewDirectoryMode(),
 		nowFn:              opts.ClockOptions().NowFn(),
-		chunkWriter:        newChunkWriter(flushFn, shouldFsync),
+		chunkWriter:        chunkWriter,
 		chunkReserveHeader: make([]byte, chunkHeaderLen),
 		buffer:             bufio.NewWriterSize(nil, opts.FlushSize()),
 		sizeBuffer:         make([]byte, binary.MaxVarintLen64),
@@ -278,22 +297,61 @@ func (w *writer) write(data []byte) error {
 }
 
 type chunkWriter struct {
-	fd      *os.File
-	flushFn flushFn
-	buff    []byte
-	fsync   bool
+	fd           *os.File
+	flushFn      flushFn
+	buff         []byte
+	fsync        bool
+	compression  CompressionType
+	compressBuff []byte
+	metrics      chunkWriterMetrics
+}
+
+type chunkWriterMetrics struct {
+	uncompressedBytes tally.Counter
+	compressedBytes   tally.Counter
+	compressionRatio  tally.Gauge
+	compressLatency   tally.Timer
+	compressErrors    tally.Counter
+}
+
+func newChunkWriterMetrics(scope tally.Scope) chunkWriterMetrics {
+	return chunkWriterMetrics{
+		uncompressedBytes: scope.Counter("writes.chunk-uncompressed-bytes"),
+		compressedBytes:   scope.Counter("writes.chunk-compressed-bytes"),
+		compressionRatio:  scope.Gauge("writes.chunk-compression-ratio"),
+		compressLatency:   scope.Timer("writes.chunk-compress-latency"),
+		compressErrors:    scope.Counter("writes.chunk-compress-errors"),
+	}
 }
 
-func newChunkWriter(flushFn flushFn, fsync bool) *chunkWriter {
+func newChunkWriter(
+	flushFn flushFn,
+	fsync bool,
+	compression CompressionType,
+	scope tally.Scope,
+) *chunkWriter {
 	return &chunkWriter{
-		flushFn: flushFn,
-		buff:    make([]byte, chunkHeaderLen),
-		fsync:   fsync,
+		flushFn:     flushFn,
+		buff:        make([]byte, chunkHeaderLen),
+		fsync:       fsync,
+		compression: compression,
+		metrics:     newChunkWriterMetrics(scope),
 	}
 }
 
 func (w *chunkWriter) Write(p []byte) (int, error) {
-	size := len(p)
+	var (
+		data = p
+		flag uint32
+	)
+	if w.compression != CompressionNone {
+		if compressed, ok := w.compress(p); ok {
+			data = compressed
+			flag = compressedChunkFlag
+		}
+	}
+
+	size := len(data)
 
 	sizeStart, sizeEnd :=
 		0, chunkHeaderSizeLen
@@ -302,12 +360,12 @@ func (w *chunkWriter) Write(p []byte) (int, error) {
 	checksumDataStart, checksumDataEnd :=
 		checksumSizeEnd, checksumSizeEnd+chunkHeaderChecksumDataLen
 
-	// Write size
-	endianness.PutUint32(w.buff[sizeStart:sizeEnd], uint32(size))
+	// Write size, flagged if the chunk is compressed
+	endianness.PutUint32(w.buff[sizeStart:sizeEnd], uint32(size)|flag)
 
 	// Calculate checksums
 	checksumSize := digest.Checksum(w.buff[sizeStart:sizeEnd])
-	checksumData := digest.Checksum(p)
+	checksumData := digest.Checksum(data)
 
 	// Write checksums
 	digest.
@@ -318,7 +376,7 @@ func (w *chunkWriter) Write(p []byte) (int, error) {
 		WriteDigest(checksumData)
 
 	// Combine buffers to reduce to a single syscall
-	w.buff = append(w.buff[:chunkHeaderLen], p...)
+	w.buff = append(w.buff[:chunkHeaderLen], data...)
 
 	// Write contents to file descriptor
 	n, err := w.fd.Write(w.buff)
@@ -334,5 +392,50 @@ func (w *chunkWriter) Write(p []byte) (int, error) {
 
 	// Fire flush callback
 	w.flushFn(err)
-	return n, err
+
+	// NB: Report the length of the uncompressed data written on success
+	// since compressed chunks can be smaller than the data passed in and
+	// the buffered writer otherwise treats that as a short write.
+	return chunkHeaderLen + len(p), err
+}
+
+// compress returns the compressed chunk data prefixed with the compressed
+// chunk header, or false if the data should be written uncompressed.
+func (w *chunkWriter) compress(p []byte) ([]byte, bool) {
+	start := time.Now()
+
+	maxLen := compressedChunkHeaderLen + maxCompressedLen(w.compression, len(p))
+	if cap(w.compressBuff) < maxLen {
+		w.compressBuff = make([]byte, maxLen)
+	}
+	w.compressBuff = w.compressBuff[:maxLen]
+
+	w.compressBuff[0] = byte(w.compression)
+	endianness.PutUint32(
+		w.compressBuff[compressedChunkHeaderTypeLen:compressedChunkHeaderLen],
+		uint32(len(p)))
+
+	compressed, err := compress(w.compression,
+		w.compressBuff[compressedChunkHeaderLen:], p)
+	if err != nil {
+		w.metrics.compressErrors.Inc(1)
+		return nil, false
+	}
+
+	w.metrics.compressLatency.Record(time.Since(start))
+
+	compressedLen := compressedChunkHeaderLen + len(compressed)
+	w.metrics.uncompressedBytes.Inc(int64(len(p)))
+	if len(p) > 0 {
+		w.metrics.compressionRatio.Update(float64(compressedLen) / float64(len(p)))
+	}
+
+	// Fallback to an uncompressed chunk if compression does not save space
+	if compressedLen >= len(p) {
+		w.metrics.compressedBytes.Inc(int64(len(p)))
+		return nil, false
+	}
+
+	w.metrics.compressedBytes.Inc(int64(compressedLen))
+	return w.compressBuff[:compressedLen], true
 }
@@ -231,6 +231,12 @@ func (dec *Decoder) decodeIndexInfo() schema.IndexInfo {
 		opts.numExpectedMinFields = 6
 		opts.numExpectedCurrFields = 6
 	}
+	if dec.legacy.decodeLegacyV2IndexInfo {
+		// v2 had 8 fields
+		opts.override = true
+		opts.numExpectedMinFields = 6
+		opts.numExpectedCurrFields = 8
+	}
 	numFieldsToSkip, actual, ok := dec.checkNumFieldsFor(indexInfoType, opts)
 	if !ok {
 		return emptyIndexInfo
@@ -252,6 +258,13 @@ func (dec *Decoder) decodeIndexInfo() schema.IndexInfo {
 	indexInfo.SnapshotTime = dec.decodeVarint()
 	indexInfo.FileType = persist.FileSetType(dec.decodeVarint())
 
+	if dec.legacy.decodeLegacyV2IndexInfo || actual < 9 {
+		dec.skip(numFieldsToSkip)
+		return indexInfo
+	}
+
+	indexInfo.DataLayoutVersion = dec.decodeVarint()
+
 	dec.skip(numFieldsToSkip)
 	return indexInfo
 }
@@ -293,6 +306,18 @@ func (dec *Decoder) decodeIndexEntry() schema.IndexEntry {
 		opts.numExpectedMinFields = 5
 		opts.numExpectedCurrFields = 5
 	}
+	if dec.legacy.decodeLegacyV2IndexEntry {
+		// v2 had 6 fields
+		opts.override = true
+		opts.numExpectedMinFields = 5
+		opts.numExpectedCurrFields = 6
+	}
+	if dec.legacy.decodeLegacyV3IndexEntry {
+		// v3 had 9 fields
+		opts.override = true
+		opts.numExpectedMinFields = 5
+		opts.numExpectedCurrFields = 9
+	}
 	numFieldsToSkip, actual, ok := dec.checkNumFieldsFor(indexEntryType, opts)
 	if !ok {
 		return emptyIndexEntry
@@ -312,6 +337,22 @@ func (dec *Decoder) decodeIndexEntry() schema.IndexEntry {
 
 	indexEntry.EncodedTags, _, _ = dec.decodeBytes()
 
+	if dec.legacy.decodeLegacyV2IndexEntry || actual < 9 {
+		dec.skip(numFieldsToSkip)
+		return indexEntry
+	}
+
+	indexEntry.FirstTimestamp = dec.decodeVarint()
+	indexEntry.LastTimestamp = dec.decodeVarint()
+	indexEntry.NumDatapoints = dec.decodeVarint()
+
+	if dec.legacy.decodeLegacyV3IndexEntry || actual < 10 {
+		dec.skip(numFieldsToSkip)
+		return indexEntry
+	}
+
+	indexEntry.PageOffset = dec.decodeVarint()
+
 	dec.skip(numFieldsToSkip)
 	return indexEntry
 }
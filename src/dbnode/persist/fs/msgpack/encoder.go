@@ -55,16 +55,28 @@ type Encoder struct {
 
 type legacyEncodingOptions struct {
 	encodeLegacyV1IndexInfo  bool
+	encodeLegacyV2IndexInfo  bool
 	encodeLegacyV1IndexEntry bool
+	encodeLegacyV2IndexEntry bool
+	encodeLegacyV3IndexEntry bool
 	decodeLegacyV1IndexInfo  bool
+	decodeLegacyV2IndexInfo  bool
 	decodeLegacyV1IndexEntry bool
+	decodeLegacyV2IndexEntry bool
+	decodeLegacyV3IndexEntry bool
 }
 
 var defaultlegacyEncodingOptions = legacyEncodingOptions{
 	encodeLegacyV1IndexInfo:  false,
+	encodeLegacyV2IndexInfo:  false,
 	encodeLegacyV1IndexEntry: false,
+	encodeLegacyV2IndexEntry: false,
+	encodeLegacyV3IndexEntry: false,
 	decodeLegacyV1IndexInfo:  false,
+	decodeLegacyV2IndexInfo:  false,
 	decodeLegacyV1IndexEntry: false,
+	decodeLegacyV2IndexEntry: false,
+	decodeLegacyV3IndexEntry: false,
 }
 
 // NewEncoder creates a new encoder
@@ -108,10 +120,13 @@ func (enc *Encoder) EncodeIndexInfo(info schema.IndexInfo) error {
 		return enc.err
 	}
 	enc.encodeRootObject(indexInfoVersion, indexInfoType)
-	if enc.legacy.encodeLegacyV1IndexInfo {
+	switch {
+	case enc.legacy.encodeLegacyV1IndexInfo:
 		enc.encodeIndexInfoV1(info)
-	} else {
+	case enc.legacy.encodeLegacyV2IndexInfo:
 		enc.encodeIndexInfoV2(info)
+	default:
+		enc.encodeIndexInfoV3(info)
 	}
 	return enc.err
 }
@@ -122,10 +137,15 @@ func (enc *Encoder) EncodeIndexEntry(entry schema.IndexEntry) error {
 		return enc.err
 	}
 	enc.encodeRootObject(indexEntryVersion, indexEntryType)
-	if enc.legacy.encodeLegacyV1IndexEntry {
+	switch {
+	case enc.legacy.encodeLegacyV1IndexEntry:
 		enc.encodeIndexEntryV1(entry)
-	} else {
+	case enc.legacy.encodeLegacyV2IndexEntry:
 		enc.encodeIndexEntryV2(entry)
+	case enc.legacy.encodeLegacyV3IndexEntry:
+		enc.encodeIndexEntryV3(entry)
+	default:
+		enc.encodeIndexEntryV4(entry)
 	}
 	return enc.err
 }
@@ -183,7 +203,22 @@ func (enc *Encoder) encodeIndexInfoV1(info schema.IndexInfo) {
 	enc.encodeIndexBloomFilterInfo(info.BloomFilter)
 }
 
+// We only keep this method around for the sake of testing
+// backwards-compatbility
 func (enc *Encoder) encodeIndexInfoV2(info schema.IndexInfo) {
+	// Manually encode num fields for testing purposes
+	enc.encodeArrayLenFn(8) // v2 had 8 fields
+	enc.encodeVarintFn(info.BlockStart)
+	enc.encodeVarintFn(info.BlockSize)
+	enc.encodeVarintFn(info.Entries)
+	enc.encodeVarintFn(info.MajorVersion)
+	enc.encodeIndexSummariesInfo(info.Summaries)
+	enc.encodeIndexBloomFilterInfo(info.BloomFilter)
+	enc.encodeVarintFn(info.SnapshotTime)
+	enc.encodeVarintFn(int64(info.FileType))
+}
+
+func (enc *Encoder) encodeIndexInfoV3(info schema.IndexInfo) {
 	enc.encodeNumObjectFieldsForFn(indexInfoType)
 	enc.encodeVarintFn(info.BlockStart)
 	enc.encodeVarintFn(info.BlockSize)
@@ -193,6 +228,7 @@ func (enc *Encoder) encodeIndexInfoV2(info schema.IndexInfo) {
 	enc.encodeIndexBloomFilterInfo(info.BloomFilter)
 	enc.encodeVarintFn(info.SnapshotTime)
 	enc.encodeVarintFn(int64(info.FileType))
+	enc.encodeVarintFn(info.DataLayoutVersion)
 }
 
 func (enc *Encoder) encodeIndexSummariesInfo(info schema.IndexSummariesInfo) {
@@ -218,7 +254,36 @@ func (enc *Encoder) encodeIndexEntryV1(entry schema.IndexEntry) {
 	enc.encodeVarintFn(entry.Checksum)
 }
 
+// We only keep this method around for the sake of testing
+// backwards-compatbility
 func (enc *Encoder) encodeIndexEntryV2(entry schema.IndexEntry) {
+	// Manually encode num fields for testing purposes
+	enc.encodeArrayLenFn(6) // v2 had 6 fields
+	enc.encodeVarintFn(entry.Index)
+	enc.encodeBytesFn(entry.ID)
+	enc.encodeVarintFn(entry.Size)
+	enc.encodeVarintFn(entry.Offset)
+	enc.encodeVarintFn(entry.Checksum)
+	enc.encodeBytesFn(entry.EncodedTags)
+}
+
+// We only keep this method around for the sake of testing
+// backwards-compatbility
+func (enc *Encoder) encodeIndexEntryV3(entry schema.IndexEntry) {
+	// Manually encode num fields for testing purposes
+	enc.encodeArrayLenFn(9) // v3 had 9 fields
+	enc.encodeVarintFn(entry.Index)
+	enc.encodeBytesFn(entry.ID)
+	enc.encodeVarintFn(entry.Size)
+	enc.encodeVarintFn(entry.Offset)
+	enc.encodeVarintFn(entry.Checksum)
+	enc.encodeBytesFn(entry.EncodedTags)
+	enc.encodeVarintFn(entry.FirstTimestamp)
+	enc.encodeVarintFn(entry.LastTimestamp)
+	enc.encodeVarintFn(entry.NumDatapoints)
+}
+
+func (enc *Encoder) encodeIndexEntryV4(entry schema.IndexEntry) {
 	enc.encodeNumObjectFieldsForFn(indexEntryType)
 	enc.encodeVarintFn(entry.Index)
 	enc.encodeBytesFn(entry.ID)
@@ -226,6 +291,10 @@ func (enc *Encoder) encodeIndexEntryV2(entry schema.IndexEntry) {
 	enc.encodeVarintFn(entry.Offset)
 	enc.encodeVarintFn(entry.Checksum)
 	enc.encodeBytesFn(entry.EncodedTags)
+	enc.encodeVarintFn(entry.FirstTimestamp)
+	enc.encodeVarintFn(entry.LastTimestamp)
+	enc.encodeVarintFn(entry.NumDatapoints)
+	enc.encodeVarintFn(entry.PageOffset)
 }
 
 func (enc *Encoder) encodeIndexSummary(summary schema.IndexSummary) {
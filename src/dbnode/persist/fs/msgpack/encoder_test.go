@@ -97,6 +97,9 @@ func testExpectedResultForIndexEntry(t *testing.T, indexEntry schema.IndexEntry)
 		indexEntry.Offset,
 		indexEntry.Checksum,
 		indexEntry.EncodedTags,
+		indexEntry.FirstTimestamp,
+		indexEntry.LastTimestamp,
+		indexEntry.NumDatapoints,
 	}
 }
 
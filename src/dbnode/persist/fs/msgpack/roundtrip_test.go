@@ -48,12 +48,15 @@ var (
 	}
 
 	testIndexEntry = schema.IndexEntry{
-		Index:       234,
-		ID:          []byte("testIndexEntry"),
-		Size:        5456,
-		Offset:      2390423,
-		Checksum:    134245634534,
-		EncodedTags: []byte("testEncodedTags"),
+		Index:          234,
+		ID:             []byte("testIndexEntry"),
+		Size:           5456,
+		Offset:         2390423,
+		Checksum:       134245634534,
+		EncodedTags:    []byte("testEncodedTags"),
+		FirstTimestamp: time.Now().Add(-time.Hour).UnixNano(),
+		LastTimestamp:  time.Now().UnixNano(),
+		NumDatapoints:  720,
 	}
 
 	testIndexSummary = schema.IndexSummary{
@@ -157,6 +160,47 @@ func TestIndexInfoRoundTripForwardsCompatibilityV2(t *testing.T) {
 	require.Equal(t, testIndexInfo, res)
 }
 
+// Make sure the new decoding code can handle the V2 file format
+func TestIndexInfoRoundTripBackwardsCompatibilityV2(t *testing.T) {
+	var (
+		opts = legacyEncodingOptions{encodeLegacyV2IndexInfo: true}
+		enc  = newEncoder(opts)
+		dec  = newDecoder(opts, nil)
+	)
+
+	info := testIndexInfo
+	info.DataLayoutVersion = schema.DataLayoutVersionPaged
+	require.NoError(t, enc.EncodeIndexInfo(info))
+	dec.Reset(NewDecoderStream(enc.Bytes()))
+	res, err := dec.DecodeIndexInfo()
+	require.NoError(t, err)
+
+	// The V2 file format predates the data layout version
+	info.DataLayoutVersion = schema.DataLayoutVersionContiguous
+	require.Equal(t, info, res)
+}
+
+// Make sure the V2 decoder code can handle the new file format
+func TestIndexInfoRoundTripForwardsCompatibilityV3(t *testing.T) {
+	var (
+		opts = legacyEncodingOptions{decodeLegacyV2IndexInfo: true}
+		enc  = newEncoder(opts)
+		dec  = newDecoder(opts, nil)
+	)
+
+	info := testIndexInfo
+	info.DataLayoutVersion = schema.DataLayoutVersionPaged
+	require.NoError(t, enc.EncodeIndexInfo(info))
+	stream := NewDecoderStream(enc.Bytes())
+	dec.Reset(stream)
+	res, err := dec.DecodeIndexInfo()
+	require.NoError(t, err)
+
+	info.DataLayoutVersion = schema.DataLayoutVersionContiguous
+	require.Equal(t, info, res)
+	require.Equal(t, int64(0), stream.Remaining())
+}
+
 func TestIndexEntryRoundtrip(t *testing.T) {
 	var (
 		enc = NewEncoder()
@@ -182,9 +226,12 @@ func TestIndexEntryRoundTripBackwardsCompatibilityV1(t *testing.T) {
 	// because the new decoder won't try and read the new fields from
 	// the old file format
 	currEncodedTags := testIndexEntry.EncodedTags
+	currDatapointStats := testIndexEntryDatapointStats()
 	testIndexEntry.EncodedTags = nil
+	setTestIndexEntryDatapointStats(0, 0, 0)
 	defer func() {
 		testIndexEntry.EncodedTags = currEncodedTags
+		setTestIndexEntryDatapointStats(currDatapointStats...)
 	}()
 
 	enc.EncodeIndexEntry(testIndexEntry)
@@ -206,14 +253,17 @@ func TestIndexEntryRoundTripForwardsCompatibilityV2(t *testing.T) {
 	// and then restore them at the end of the test - This is required
 	// because the old decoder won't read the new fields
 	currEncodedTags := testIndexEntry.EncodedTags
+	currDatapointStats := testIndexEntryDatapointStats()
 
 	enc.EncodeIndexEntry(testIndexEntry)
 
 	// Make sure to zero them before we compare, but after we have
 	// encoded the data
 	testIndexEntry.EncodedTags = nil
+	setTestIndexEntryDatapointStats(0, 0, 0)
 	defer func() {
 		testIndexEntry.EncodedTags = currEncodedTags
+		setTestIndexEntryDatapointStats(currDatapointStats...)
 	}()
 
 	dec.Reset(NewDecoderStream(enc.Bytes()))
@@ -222,6 +272,126 @@ func TestIndexEntryRoundTripForwardsCompatibilityV2(t *testing.T) {
 	require.Equal(t, testIndexEntry, res)
 }
 
+// Make sure the new decoding code can handle the V2 file format
+func TestIndexEntryRoundTripBackwardsCompatibilityV2(t *testing.T) {
+	var (
+		opts = legacyEncodingOptions{encodeLegacyV2IndexEntry: true}
+		enc  = newEncoder(opts)
+		dec  = newDecoder(opts, nil)
+	)
+
+	// Set the default values on the fields that did not exist in V2
+	// and then restore them at the end of the test - This is required
+	// because the new decoder won't try and read the new fields from
+	// the old file format
+	currDatapointStats := testIndexEntryDatapointStats()
+	setTestIndexEntryDatapointStats(0, 0, 0)
+	defer setTestIndexEntryDatapointStats(currDatapointStats...)
+
+	enc.EncodeIndexEntry(testIndexEntry)
+	dec.Reset(NewDecoderStream(enc.Bytes()))
+	res, err := dec.DecodeIndexEntry()
+	require.NoError(t, err)
+	require.Equal(t, testIndexEntry, res)
+}
+
+// Make sure the V2 decoder code can handle the new file format
+func TestIndexEntryRoundTripForwardsCompatibilityV3(t *testing.T) {
+	var (
+		opts = legacyEncodingOptions{decodeLegacyV2IndexEntry: true}
+		enc  = newEncoder(opts)
+		dec  = newDecoder(opts, nil)
+	)
+
+	currDatapointStats := testIndexEntryDatapointStats()
+
+	enc.EncodeIndexEntry(testIndexEntry)
+
+	// Make sure to zero them before we compare, but after we have
+	// encoded the data
+	setTestIndexEntryDatapointStats(0, 0, 0)
+	defer setTestIndexEntryDatapointStats(currDatapointStats...)
+
+	stream := NewDecoderStream(enc.Bytes())
+	dec.Reset(stream)
+	res, err := dec.DecodeIndexEntry()
+	require.NoError(t, err)
+	require.Equal(t, testIndexEntry, res)
+
+	// The V2 decoder must skip over the new fields so that it can continue
+	// to decode any entries that follow
+	require.Equal(t, int64(0), stream.Remaining())
+}
+
+func TestIndexEntryRoundtripPageOffset(t *testing.T) {
+	var (
+		enc = NewEncoder()
+		dec = NewDecoder(nil)
+	)
+	entry := testIndexEntry
+	entry.PageOffset = 4096
+	require.NoError(t, enc.EncodeIndexEntry(entry))
+	dec.Reset(NewDecoderStream(enc.Bytes()))
+	res, err := dec.DecodeIndexEntry()
+	require.NoError(t, err)
+	require.Equal(t, entry, res)
+}
+
+// Make sure the new decoding code can handle the V3 file format
+func TestIndexEntryRoundTripBackwardsCompatibilityV3(t *testing.T) {
+	var (
+		opts = legacyEncodingOptions{encodeLegacyV3IndexEntry: true}
+		enc  = newEncoder(opts)
+		dec  = newDecoder(opts, nil)
+	)
+
+	entry := testIndexEntry
+	entry.PageOffset = 4096
+	require.NoError(t, enc.EncodeIndexEntry(entry))
+	dec.Reset(NewDecoderStream(enc.Bytes()))
+	res, err := dec.DecodeIndexEntry()
+	require.NoError(t, err)
+
+	// The V3 file format predates page offsets
+	entry.PageOffset = 0
+	require.Equal(t, entry, res)
+}
+
+// Make sure the V3 decoder code can handle the new file format
+func TestIndexEntryRoundTripForwardsCompatibilityV4(t *testing.T) {
+	var (
+		opts = legacyEncodingOptions{decodeLegacyV3IndexEntry: true}
+		enc  = newEncoder(opts)
+		dec  = newDecoder(opts, nil)
+	)
+
+	entry := testIndexEntry
+	entry.PageOffset = 4096
+	require.NoError(t, enc.EncodeIndexEntry(entry))
+	stream := NewDecoderStream(enc.Bytes())
+	dec.Reset(stream)
+	res, err := dec.DecodeIndexEntry()
+	require.NoError(t, err)
+
+	entry.PageOffset = 0
+	require.Equal(t, entry, res)
+	require.Equal(t, int64(0), stream.Remaining())
+}
+
+func testIndexEntryDatapointStats() []int64 {
+	return []int64{
+		testIndexEntry.FirstTimestamp,
+		testIndexEntry.LastTimestamp,
+		testIndexEntry.NumDatapoints,
+	}
+}
+
+func setTestIndexEntryDatapointStats(values ...int64) {
+	testIndexEntry.FirstTimestamp = values[0]
+	testIndexEntry.LastTimestamp = values[1]
+	testIndexEntry.NumDatapoints = values[2]
+}
+
 func TestIndexSummaryRoundtrip(t *testing.T) {
 	var (
 		enc = NewEncoder()
@@ -39,11 +39,15 @@ const (
 	defaultIndexSummariesPercent = 0.03
 
 	// defaultIndexBloomFilterFalsePositivePercent is the false positive percent to use to calculate size for when writing bloom filters
-	defaultIndexBloomFilterFalsePositivePercent = 0.02
+	defaultIndexBloomFilterFalsePositivePercent = 0.01
 
 	// defaultWriterBufferSize is the default buffer size for writing TSDB files
 	defaultWriterBufferSize = 65536
 
+	// defaultWriterDataPageSize is the default size of the pages of series data written to TSDB data files,
+	// the paged data layout is opt-in as older readers can't read it and would fail on rollback
+	defaultWriterDataPageSize = 0
+
 	// defaultDataReaderBufferSize is the default buffer size for reading TSDB data and index files
 	defaultDataReaderBufferSize = 65536
 
@@ -80,6 +84,7 @@ type options struct {
 	indexSummariesPercent                float64
 	indexBloomFilterFalsePositivePercent float64
 	writerBufferSize                     int
+	writerDataPageSize                   int
 	dataReaderBufferSize                 int
 	infoReaderBufferSize                 int
 	seekReaderBufferSize                 int
@@ -111,6 +116,7 @@ func NewOptions() Options {
 		indexSummariesPercent:                defaultIndexSummariesPercent,
 		indexBloomFilterFalsePositivePercent: defaultIndexBloomFilterFalsePositivePercent,
 		writerBufferSize:                     defaultWriterBufferSize,
+		writerDataPageSize:                   defaultWriterDataPageSize,
 		dataReaderBufferSize:                 defaultDataReaderBufferSize,
 		infoReaderBufferSize:                 defaultInfoReaderBufferSize,
 		seekReaderBufferSize:                 defaultSeekReaderBufferSize,
@@ -133,6 +139,11 @@ func (o *options) Validate() error {
 			"invalid index bloom filter false positive percent, must be >= 0 and <= 1: instead %f",
 			o.indexBloomFilterFalsePositivePercent)
 	}
+	if o.writerDataPageSize < 0 {
+		return fmt.Errorf(
+			"invalid writer data page size, must be >= 0: instead %d",
+			o.writerDataPageSize)
+	}
 	if o.tagEncoderPool == nil {
 		return errTagEncoderPoolNotSet
 	}
@@ -242,6 +253,16 @@ func (o *options) WriterBufferSize() int {
 	return o.writerBufferSize
 }
 
+func (o *options) SetWriterDataPageSize(value int) Options {
+	opts := *o
+	opts.writerDataPageSize = value
+	return &opts
+}
+
+func (o *options) WriterDataPageSize() int {
+	return o.writerDataPageSize
+}
+
 func (o *options) SetDataReaderBufferSize(value int) Options {
 	opts := *o
 	opts.dataReaderBufferSize = value
@@ -450,6 +450,7 @@ func (pm *persistManager) persist(
 	tags ident.Tags,
 	segment ts.Segment,
 	checksum uint32,
+	stats ts.DatapointStats,
 ) error {
 	pm.RLock()
 	// Rate limit options can change dynamically
@@ -479,7 +480,8 @@ func (pm *persistManager) persist(
 
 	pm.dataPM.segmentHolder[0] = segment.Head
 	pm.dataPM.segmentHolder[1] = segment.Tail
-	err := pm.dataPM.writer.WriteAll(id, tags, pm.dataPM.segmentHolder, checksum)
+	err := pm.dataPM.writer.WriteAllWithDatapointStats(id, tags,
+		pm.dataPM.segmentHolder, checksum, stats)
 	pm.count++
 	pm.bytesWritten += int64(segment.Len())
 
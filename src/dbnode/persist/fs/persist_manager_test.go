@@ -189,8 +189,13 @@ func TestPersistenceManagerPrepareSuccess(t *testing.T) {
 		tail     = checked.NewBytes([]byte{0x3, 0x4}, nil)
 		segment  = ts.NewSegment(head, tail, ts.FinalizeNone)
 		checksum = digest.SegmentChecksum(segment)
+		stats    = ts.DatapointStats{
+			FirstTimestamp: blockStart,
+			LastTimestamp:  blockStart.Add(time.Minute),
+			Count:          2,
+		}
 	)
-	writer.EXPECT().WriteAll(id, tags, gomock.Any(), checksum).Return(nil)
+	writer.EXPECT().WriteAllWithDatapointStats(id, tags, gomock.Any(), checksum, stats).Return(nil)
 	writer.EXPECT().Close()
 
 	flush, err := pm.StartDataPersist()
@@ -215,7 +220,7 @@ func TestPersistenceManagerPrepareSuccess(t *testing.T) {
 
 	require.Nil(t, err)
 
-	require.Nil(t, prepared.Persist(id, tags, segment, checksum))
+	require.Nil(t, prepared.Persist(id, tags, segment, checksum, stats))
 
 	require.True(t, pm.start.Equal(now))
 	require.Equal(t, 124, pm.count)
@@ -425,7 +430,7 @@ func TestPersistenceManagerNoRateLimit(t *testing.T) {
 	pm.nowFn = func() time.Time { return now }
 	pm.sleepFn = func(d time.Duration) { slept += d }
 
-	writer.EXPECT().WriteAll(id, tags, pm.dataPM.segmentHolder, checksum).Return(nil).Times(2)
+	writer.EXPECT().WriteAllWithDatapointStats(id, tags, pm.dataPM.segmentHolder, checksum, ts.DatapointStats{}).Return(nil).Times(2)
 
 	flush, err := pm.StartDataPersist()
 	require.NoError(t, err)
@@ -445,11 +450,11 @@ func TestPersistenceManagerNoRateLimit(t *testing.T) {
 
 	// Start persistence
 	now = time.Now()
-	require.NoError(t, prepared.Persist(id, tags, segment, checksum))
+	require.NoError(t, prepared.Persist(id, tags, segment, checksum, ts.DatapointStats{}))
 
 	// Advance time and write again
 	now = now.Add(time.Millisecond)
-	require.NoError(t, prepared.Persist(id, tags, segment, checksum))
+	require.NoError(t, prepared.Persist(id, tags, segment, checksum, ts.DatapointStats{}))
 
 	// Check there is no rate limiting
 	require.Equal(t, time.Duration(0), slept)
@@ -489,7 +494,7 @@ func TestPersistenceManagerWithRateLimit(t *testing.T) {
 		BlockSize: testBlockSize,
 	})
 	writer.EXPECT().Open(writerOpts).Return(nil).Times(iter)
-	writer.EXPECT().WriteAll(id, ident.Tags{}, pm.dataPM.segmentHolder, checksum).Return(nil).AnyTimes()
+	writer.EXPECT().WriteAllWithDatapointStats(id, ident.Tags{}, pm.dataPM.segmentHolder, checksum, ts.DatapointStats{}).Return(nil).AnyTimes()
 	writer.EXPECT().Close().Times(iter)
 
 	// Enable rate limiting
@@ -528,21 +533,21 @@ func TestPersistenceManagerWithRateLimit(t *testing.T) {
 
 		// Start persistence
 		now = time.Now()
-		require.NoError(t, prepared.Persist(id, ident.Tags{}, segment, checksum))
+		require.NoError(t, prepared.Persist(id, ident.Tags{}, segment, checksum, ts.DatapointStats{}))
 
 		// Assert we don't rate limit if the count is not enough yet
-		require.NoError(t, prepared.Persist(id, ident.Tags{}, segment, checksum))
+		require.NoError(t, prepared.Persist(id, ident.Tags{}, segment, checksum, ts.DatapointStats{}))
 		require.Equal(t, time.Duration(0), slept)
 
 		// Advance time and check we rate limit if the disk throughput exceeds the limit
 		now = now.Add(time.Microsecond)
-		require.NoError(t, prepared.Persist(id, ident.Tags{}, segment, checksum))
+		require.NoError(t, prepared.Persist(id, ident.Tags{}, segment, checksum, ts.DatapointStats{}))
 		require.Equal(t, time.Duration(1861), slept)
 
 		// Advance time and check we don't rate limit if the disk throughput is below the limit
-		require.NoError(t, prepared.Persist(id, ident.Tags{}, segment, checksum))
+		require.NoError(t, prepared.Persist(id, ident.Tags{}, segment, checksum, ts.DatapointStats{}))
 		now = now.Add(time.Second - time.Microsecond)
-		require.NoError(t, prepared.Persist(id, ident.Tags{}, segment, checksum))
+		require.NoError(t, prepared.Persist(id, ident.Tags{}, segment, checksum, ts.DatapointStats{}))
 		require.Equal(t, time.Duration(1861), slept)
 
 		require.Equal(t, int64(15), pm.bytesWritten)
@@ -75,14 +75,15 @@ type reader struct {
 
 	bloomFilterFd *os.File
 
-	entries         int
-	bloomFilterInfo schema.IndexBloomFilterInfo
-	entriesRead     int
-	metadataRead    int
-	decoder         *msgpack.Decoder
-	digestBuf       digest.Buffer
-	bytesPool       pool.CheckedBytesPool
-	tagDecoderPool  serialize.TagDecoderPool
+	entries           int
+	bloomFilterInfo   schema.IndexBloomFilterInfo
+	dataLayoutVersion int64
+	entriesRead       int
+	metadataRead      int
+	decoder           *msgpack.Decoder
+	digestBuf         digest.Buffer
+	bytesPool         pool.CheckedBytesPool
+	tagDecoderPool    serialize.TagDecoderPool
 
 	expectedInfoDigest        uint32
 	expectedIndexDigest       uint32
@@ -170,14 +171,22 @@ func (r *reader) Open(opts DataReaderOpenOptions) error {
 
 	var infoFd, digestFd *os.File
 	err = openFiles(os.Open, map[string]**os.File{
-		infoFilepath:        &infoFd,
-		digestFilepath:      &digestFd,
-		bloomFilterFilepath: &r.bloomFilterFd,
+		infoFilepath:   &infoFd,
+		digestFilepath: &digestFd,
 	})
 	if err != nil {
 		return err
 	}
 
+	// Filesets written before bloom filters were introduced have no bloom
+	// filter file, reading the bloom filter of these returns a nil filter
+	r.bloomFilterFd, err = openBloomFilterFile(bloomFilterFilepath)
+	if err != nil {
+		infoFd.Close()
+		digestFd.Close()
+		return err
+	}
+
 	r.infoFdWithDigest.Reset(infoFd)
 	r.digestFdWithDigestContents.Reset(digestFd)
 
@@ -300,12 +309,16 @@ func (r *reader) readInfo(size int) error {
 	if err != nil {
 		return err
 	}
+	if err := validateDataLayoutVersion(info.DataLayoutVersion); err != nil {
+		return err
+	}
 	r.start = xtime.FromNanoseconds(info.BlockStart)
 	r.blockSize = time.Duration(info.BlockSize)
 	r.entries = int(info.Entries)
 	r.entriesRead = 0
 	r.metadataRead = 0
 	r.bloomFilterInfo = info.BloomFilter
+	r.dataLayoutVersion = info.DataLayoutVersion
 	return nil
 }
 
@@ -316,6 +329,10 @@ func (r *reader) readIndexAndSortByOffsetAsc() error {
 		if err != nil {
 			return err
 		}
+		// NB: entries of the same page share the offset of the page, resolve the
+		// offset of their data so they are ordered the same for both layouts.
+		entry.Offset = indexEntryDataOffset(entry, r.dataLayoutVersion)
+		entry.PageOffset = 0
 		r.indexEntriesByOffsetAsc = append(r.indexEntriesByOffsetAsc, entry)
 	}
 	// NB(r): As we decode each block we need access to each index entry
@@ -470,7 +487,10 @@ func (r *reader) Close() error {
 	multiErr = multiErr.Add(mmap.Munmap(r.dataMmap))
 	multiErr = multiErr.Add(r.indexFd.Close())
 	multiErr = multiErr.Add(r.dataFd.Close())
-	multiErr = multiErr.Add(r.bloomFilterFd.Close())
+	if r.bloomFilterFd != nil {
+		multiErr = multiErr.Add(r.bloomFilterFd.Close())
+		r.bloomFilterFd = nil
+	}
 	r.indexDecoderStream.Reset(nil)
 	r.dataReader.Reset(nil)
 	for i := 0; i < len(r.indexEntriesByOffsetAsc); i++ {
@@ -514,6 +534,25 @@ func (r *reader) Close() error {
 	return multiErr.FinalError()
 }
 
+// validateDataLayoutVersion returns an error if the data layout version of a
+// fileset is not one that can be read.
+func validateDataLayoutVersion(version int64) error {
+	switch version {
+	case schema.DataLayoutVersionContiguous, schema.DataLayoutVersionPaged:
+		return nil
+	}
+	return fmt.Errorf("unknown fileset data layout version: %d", version)
+}
+
+// indexEntryDataOffset returns the offset of the data of an index entry in the
+// data file of a fileset with the given data layout version.
+func indexEntryDataOffset(entry schema.IndexEntry, dataLayoutVersion int64) int64 {
+	if dataLayoutVersion == schema.DataLayoutVersionPaged {
+		return entry.Offset + entry.PageOffset
+	}
+	return entry.Offset
+}
+
 // indexEntriesByOffsetAsc implements sort.Sort
 type indexEntriesByOffsetAsc []schema.IndexEntry
 
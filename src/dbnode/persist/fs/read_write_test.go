@@ -32,12 +32,15 @@ import (
 	"github.com/m3db/bloom"
 	"github.com/m3db/m3/src/dbnode/digest"
 	"github.com/m3db/m3/src/dbnode/persist"
+	"github.com/m3db/m3/src/dbnode/persist/schema"
 	"github.com/m3db/m3x/checked"
 	"github.com/m3db/m3x/ident"
+	"github.com/m3db/m3x/instrument"
 	xtime "github.com/m3db/m3x/time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
 )
 
 type testEntry struct {
@@ -455,3 +458,53 @@ func TestWriterOnlyWritesNonNilBytes(t *testing.T) {
 		{"foo", nil, []byte{1, 2, 3, 4, 5, 6}},
 	})
 }
+
+func TestWriterDataPages(t *testing.T) {
+	dir := createTempDir(t)
+	filePathPrefix := filepath.Join(dir, "")
+	defer os.RemoveAll(dir)
+
+	scope := tally.NewTestScope("", nil)
+	dataWriter, err := NewWriter(testDefaultOpts.
+		SetFilePathPrefix(filePathPrefix).
+		SetWriterBufferSize(testWriterBufferSize).
+		SetWriterDataPageSize(10).
+		SetInstrumentOptions(instrument.NewOptions().SetMetricsScope(scope)))
+	require.NoError(t, err)
+
+	entries := []testEntry{
+		{"foo", nil, []byte{1, 2, 3, 4}},
+		{"bar", nil, []byte{5, 6, 7, 8}},
+		{"baz", nil, []byte{9, 10, 11, 12}},
+		{"qux", nil, []byte{13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24}},
+		{"quux", nil, []byte{25, 26}},
+	}
+	writeTestData(t, dataWriter, 0, testWriterStart, entries, persist.FileSetFlushType)
+
+	// Series never straddle pages and a series larger than a page is
+	// written as a page of its own
+	type pageLocation struct {
+		dataFileOffset int64
+		pageOffset     int64
+	}
+	expected := []pageLocation{{0, 0}, {0, 4}, {8, 0}, {12, 0}, {24, 0}}
+	w := dataWriter.(*writer)
+	require.Equal(t, len(expected), len(w.indexEntries))
+	for i, entry := range w.indexEntries {
+		assert.Equal(t, expected[i], pageLocation{entry.dataFileOffset, entry.pageOffset})
+	}
+
+	counters := scope.Snapshot().Counters()
+	assert.Equal(t, int64(4), counters["writer.data-pages-written+"].Value())
+	assert.Equal(t, int64(26), counters["writer.data-bytes-written+"].Value())
+	assert.Equal(t, int64(5), counters["writer.series-written+"].Value())
+
+	readInfoFileResults := ReadInfoFiles(filePathPrefix, testNs1ID, 0, 16, nil)
+	require.Equal(t, 1, len(readInfoFileResults))
+	require.NoError(t, readInfoFileResults[0].Err.Error())
+	assert.Equal(t, int64(schema.DataLayoutVersionPaged),
+		readInfoFileResults[0].Info.DataLayoutVersion)
+
+	r := newTestReader(t, filePathPrefix)
+	readTestData(t, r, 0, testWriterStart, entries)
+}
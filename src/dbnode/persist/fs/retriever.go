@@ -28,6 +28,13 @@
 // The block retriever also handles batching of requests for data, as well as
 // re-arranging the order of requests to increase data locality when seeking
 // through and across files.
+//
+// When read ahead is enabled and a stream is hinted to be part of a range
+// read, the block retriever also prefetches the subsequent blocks of the
+// series. Prefetched blocks are handed to the onRetrieve callback of the
+// originating stream so they are cached (and accounted against the wired
+// list) exactly as if they had been read, and streams for a block that is
+// still being prefetched join the prefetch rather than issue another read.
 
 package fs
 
@@ -47,6 +54,8 @@ import (
 	"github.com/m3db/m3x/ident"
 	"github.com/m3db/m3x/log"
 	"github.com/m3db/m3x/pool"
+
+	"github.com/uber-go/tally"
 )
 
 var (
@@ -58,6 +67,9 @@ var (
 
 const (
 	defaultRetrieveRequestQueueCapacity = 4096
+	// maxPendingReadAheads bounds the number of prefetches that can be
+	// outstanding at once, further read ahead hints are dropped.
+	maxPendingReadAheads = 4096
 )
 
 type blockRetrieverStatus int
@@ -96,6 +108,47 @@ type blockRetriever struct {
 	notifyFetch                chan struct{}
 	fetchLoopsShouldShutdownCh chan struct{}
 	fetchLoopsHaveShutdownCh   chan struct{}
+
+	readAheadLock    sync.Mutex
+	readAheadPending map[readAheadKey]*retrieveRequest
+
+	metrics blockRetrieverMetrics
+}
+
+type blockRetrieverMetrics struct {
+	readAheadPrefetched       tally.Counter
+	readAheadDropped          tally.Counter
+	readAheadHit              tally.Counter
+	readAheadMiss             tally.Counter
+	bloomFilterNegatives      tally.Counter
+	bloomFilterFalsePositives tally.Counter
+}
+
+func newBlockRetrieverMetrics(scope tally.Scope) blockRetrieverMetrics {
+	readAheadScope := scope.SubScope("read-ahead")
+	bloomFilterScope := scope.SubScope("bloom-filter")
+	return blockRetrieverMetrics{
+		readAheadPrefetched:       readAheadScope.Counter("prefetched"),
+		readAheadDropped:          readAheadScope.Counter("dropped"),
+		readAheadHit:              readAheadScope.Counter("hit"),
+		readAheadMiss:             readAheadScope.Counter("miss"),
+		bloomFilterNegatives:      bloomFilterScope.Counter("negatives"),
+		bloomFilterFalsePositives: bloomFilterScope.Counter("false-positives"),
+	}
+}
+
+type readAheadKey struct {
+	shard      uint32
+	blockStart int64
+	id         string
+}
+
+func newReadAheadKey(shard uint32, id ident.ID, blockStart time.Time) readAheadKey {
+	return readAheadKey{
+		shard:      shard,
+		blockStart: blockStart.UnixNano(),
+		id:         string(id.Bytes()),
+	}
 }
 
 // NewBlockRetriever returns a new block retriever for TSDB file sets.
@@ -107,6 +160,7 @@ func NewBlockRetriever(
 	reqPoolOpts := opts.RequestPoolOptions()
 	reqPool := newRetrieveRequestPool(segmentReaderPool, reqPoolOpts)
 	reqPool.Init()
+	scope := fsOpts.InstrumentOptions().MetricsScope().SubScope("retriever")
 	return &blockRetriever{
 		opts:           opts,
 		fsOpts:         fsOpts,
@@ -121,6 +175,8 @@ func NewBlockRetriever(
 		// buffering is required
 		fetchLoopsShouldShutdownCh: make(chan struct{}),
 		fetchLoopsHaveShutdownCh:   make(chan struct{}, opts.FetchConcurrency()),
+		readAheadPending:           make(map[readAheadKey]*retrieveRequest),
+		metrics:                    newBlockRetrieverMetrics(scope),
 	}
 }
 
@@ -265,7 +321,7 @@ func (r *blockRetriever) fetchBatch(
 	seeker, err := seekerMgr.Borrow(shard, blockStart)
 	if err != nil {
 		for _, req := range reqs {
-			req.onError(err)
+			r.onRequestError(req, err)
 		}
 		return
 	}
@@ -275,12 +331,18 @@ func (r *blockRetriever) fetchBatch(
 	for _, req := range reqs {
 		entry, err := seeker.SeekIndexEntry(req.id)
 		if err != nil && err != errSeekIDNotFound {
-			req.onError(err)
+			r.onRequestError(req, err)
 			continue
 		}
 
 		if err == errSeekIDNotFound {
 			req.notFound = true
+			if seeker.ConcurrentIDBloomFilter() != nil {
+				// Requests are only enqueued if the bloom filter reports
+				// the ID may be present, filesets without a bloom filter
+				// can't have false positives
+				r.metrics.bloomFilterFalsePositives.Inc(1)
+			}
 		}
 		req.indexEntry = entry
 	}
@@ -298,11 +360,26 @@ func (r *blockRetriever) fetchBatch(
 		if !req.notFound {
 			data, err = seeker.SeekByIndexEntry(req.indexEntry)
 			if err != nil && err != errSeekIDNotFound {
-				req.onError(err)
+				r.onRequestError(req, err)
 				continue
 			}
 		}
 
+		if req.readAhead {
+			// Complete any streams that joined this prefetch while it was
+			// in flight with their own copy of the data.
+			for _, follower := range r.takeReadAheadFollowers(req) {
+				var followerSeg ts.Segment
+				if data != nil {
+					followerCopy := r.bytesPool.Get(data.Len())
+					followerSeg = ts.NewSegment(followerCopy, nil, ts.FinalizeHead)
+					followerCopy.AppendAll(data.Bytes())
+				}
+				follower.onRetrieved(followerSeg)
+				follower.onCallerOrRetrieverDone()
+			}
+		}
+
 		var (
 			seg, onRetrieveSeg ts.Segment
 		)
@@ -358,12 +435,60 @@ func (r *blockRetriever) fetchBatch(
 	}
 }
 
+func (r *blockRetriever) onRequestError(req *retrieveRequest, err error) {
+	if req.readAhead {
+		for _, follower := range r.takeReadAheadFollowers(req) {
+			follower.onError(err)
+			follower.onCallerOrRetrieverDone()
+		}
+	}
+	req.onError(err)
+}
+
 func (r *blockRetriever) Stream(
 	ctx context.Context,
 	shard uint32,
 	id ident.ID,
 	startTime time.Time,
 	onRetrieve block.OnRetrieveBlock,
+) (xio.BlockReader, error) {
+	return r.stream(ctx, shard, id, startTime, onRetrieve)
+}
+
+func (r *blockRetriever) StreamWithReadAhead(
+	ctx context.Context,
+	shard uint32,
+	id ident.ID,
+	startTime time.Time,
+	readAheadEnd time.Time,
+	onRetrieve block.OnRetrieveBlock,
+) (xio.BlockReader, error) {
+	if !r.opts.ReadAheadEnabled() {
+		return r.stream(ctx, shard, id, startTime, onRetrieve)
+	}
+
+	// Join a prefetch of this block if one is still in flight.
+	if follower, ok := r.joinReadAhead(ctx, shard, id, startTime); ok {
+		r.metrics.readAheadHit.Inc(1)
+		r.readAhead(shard, id, startTime, readAheadEnd, onRetrieve)
+		return follower.toBlock(), nil
+	}
+	r.metrics.readAheadMiss.Inc(1)
+
+	result, err := r.stream(ctx, shard, id, startTime, onRetrieve)
+	if err != nil {
+		return result, err
+	}
+	r.readAhead(shard, id, startTime, readAheadEnd, onRetrieve)
+	return result, nil
+}
+
+func (r *blockRetriever) stream(
+	ctx context.Context,
+	shard uint32,
+	id ident.ID,
+	startTime time.Time,
+	onRetrieve block.OnRetrieveBlock,
 ) (xio.BlockReader, error) {
 	req := r.reqPool.Get()
 	req.shard = shard
@@ -397,15 +522,24 @@ func (r *blockRetriever) Stream(
 	// If the ID is not in the seeker's bloom filter, then it's definitely not on
 	// disk and we can return immediately
 	if !bloomFilter.Test(id.Bytes()) {
+		r.metrics.bloomFilterNegatives.Inc(1)
 		// No need to call req.onRetrieve.OnRetrieveBlock if there is no data
 		req.onRetrieved(ts.Segment{})
 		return req.toBlock(), nil
 	}
-	reqs, err := r.shardRequests(shard)
-	if err != nil {
+	if err := r.enqueue(req); err != nil {
 		return xio.EmptyBlockReader, err
 	}
 
+	return req.toBlock(), nil
+}
+
+func (r *blockRetriever) enqueue(req *retrieveRequest) error {
+	reqs, err := r.shardRequests(req.shard)
+	if err != nil {
+		return err
+	}
+
 	reqs.Lock()
 	reqs.queued = append(reqs.queued, req)
 	reqs.Unlock()
@@ -417,7 +551,133 @@ func (r *blockRetriever) Stream(
 		// Loop busy, already ready to consume notification
 	}
 
-	return req.toBlock(), nil
+	return nil
+}
+
+// readAhead prefetches the blocks following blockStart, up to the configured
+// number of read ahead blocks and no further than readAheadEnd.
+func (r *blockRetriever) readAhead(
+	shard uint32,
+	id ident.ID,
+	blockStart time.Time,
+	readAheadEnd time.Time,
+	onRetrieve block.OnRetrieveBlock,
+) {
+	// NB: Prefetched blocks are only retained by being handed to the
+	// onRetrieve callback, which caches them in the series and the wired
+	// list, without one there is nothing to gain from reading ahead.
+	if onRetrieve == nil {
+		return
+	}
+
+	r.RLock()
+	seekerMgr, blockSize := r.seekerMgr, r.blockSize
+	r.RUnlock()
+	if seekerMgr == nil || blockSize <= 0 {
+		return
+	}
+
+	for i := 1; i <= r.opts.ReadAheadBlocks(); i++ {
+		start := blockStart.Add(time.Duration(i) * blockSize)
+		if start.After(readAheadEnd) {
+			return
+		}
+
+		// Only prefetch blocks that have been flushed and may contain the ID.
+		bloomFilter, err := seekerMgr.ConcurrentIDBloomFilter(shard, start)
+		if err != nil || !bloomFilter.Test(id.Bytes()) {
+			continue
+		}
+
+		r.prefetch(shard, id, start, onRetrieve)
+	}
+}
+
+func (r *blockRetriever) prefetch(
+	shard uint32,
+	id ident.ID,
+	blockStart time.Time,
+	onRetrieve block.OnRetrieveBlock,
+) {
+	key := newReadAheadKey(shard, id, blockStart)
+
+	r.readAheadLock.Lock()
+	if _, ok := r.readAheadPending[key]; ok {
+		// Already being prefetched
+		r.readAheadLock.Unlock()
+		return
+	}
+	if len(r.readAheadPending) >= maxPendingReadAheads {
+		r.readAheadLock.Unlock()
+		r.metrics.readAheadDropped.Inc(1)
+		return
+	}
+
+	req := r.reqPool.Get()
+	req.shard = shard
+	req.id = r.idPool.Clone(id)
+	req.start = blockStart
+	req.blockSize = r.blockSize
+	req.onRetrieve = onRetrieve
+	req.readAhead = true
+	req.readAheadKey = key
+	req.resultWg.Add(1)
+	r.readAheadPending[key] = req
+	r.readAheadLock.Unlock()
+
+	// There is no caller for a prefetch so mark the caller as done up front,
+	// the request is finalized once the fetch loop is done with it.
+	req.onCallerOrRetrieverDone()
+
+	if err := r.enqueue(req); err != nil {
+		r.onRequestError(req, err)
+		req.onCallerOrRetrieverDone()
+		return
+	}
+	r.metrics.readAheadPrefetched.Inc(1)
+}
+
+// joinReadAhead returns a request that is completed along with the in flight
+// prefetch of the block, if there is one.
+func (r *blockRetriever) joinReadAhead(
+	ctx context.Context,
+	shard uint32,
+	id ident.ID,
+	blockStart time.Time,
+) (*retrieveRequest, bool) {
+	key := newReadAheadKey(shard, id, blockStart)
+
+	r.readAheadLock.Lock()
+	defer r.readAheadLock.Unlock()
+
+	prefetch, ok := r.readAheadPending[key]
+	if !ok {
+		return nil, false
+	}
+
+	req := r.reqPool.Get()
+	req.shard = shard
+	req.id = r.idPool.Clone(id)
+	req.start = blockStart
+	req.blockSize = prefetch.blockSize
+	req.resultWg.Add(1)
+	ctx.RegisterFinalizer(req)
+
+	prefetch.readAheadFollowers = append(prefetch.readAheadFollowers, req)
+	return req, true
+}
+
+// takeReadAheadFollowers removes a completed prefetch from the pending
+// prefetches and returns the requests that joined it.
+func (r *blockRetriever) takeReadAheadFollowers(
+	req *retrieveRequest,
+) []*retrieveRequest {
+	r.readAheadLock.Lock()
+	delete(r.readAheadPending, req.readAheadKey)
+	followers := req.readAheadFollowers
+	req.readAheadFollowers = nil
+	r.readAheadLock.Unlock()
+	return followers
 }
 
 func (req *retrieveRequest) toBlock() xio.BlockReader {
@@ -533,6 +793,12 @@ type retrieveRequest struct {
 	shard     uint32
 
 	notFound bool
+
+	// readAhead is set for prefetches, which complete the requests of
+	// streams that joined them while in flight.
+	readAhead          bool
+	readAheadKey       readAheadKey
+	readAheadFollowers []*retrieveRequest
 }
 
 func (req *retrieveRequest) onError(err error) {
@@ -625,6 +891,9 @@ func (req *retrieveRequest) resetForReuse() {
 	req.reader = nil
 	req.err = nil
 	req.notFound = false
+	req.readAhead = false
+	req.readAheadKey = readAheadKey{}
+	req.readAheadFollowers = nil
 }
 
 type retrieveRequestByStartAscShardAsc []*retrieveRequest
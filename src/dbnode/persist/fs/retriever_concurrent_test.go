@@ -31,17 +31,22 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/digest"
+	"github.com/m3db/m3/src/dbnode/persist"
+	"github.com/m3db/m3/src/dbnode/retention"
+	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/xio"
 	"github.com/m3db/m3x/checked"
 	"github.com/m3db/m3x/context"
 	"github.com/m3db/m3x/ident"
+	"github.com/m3db/m3x/instrument"
 	"github.com/m3db/m3x/pool"
 	xtime "github.com/m3db/m3x/time"
 
 	"github.com/fortytw2/leaktest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
 )
 
 type testBlockRetrieverOptions struct {
@@ -306,3 +311,236 @@ func TestBlockRetrieverIDDoesNotExist(t *testing.T) {
 	assert.Equal(t, nil, segment.Head)
 	assert.Equal(t, nil, segment.Tail)
 }
+
+// TestBlockRetrieverBloomFilterMetrics verifies that lookups of IDs not in a
+// fileset are counted as bloom filter negatives when the bloom filter rules
+// them out and as false positives when the index lookup has to rule them out.
+func TestBlockRetrieverBloomFilterMetrics(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testdb")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	filePathPrefix := filepath.Join(dir, "")
+
+	// Use a high false positive rate so that some lookups pass the filter
+	scope := tally.NewTestScope("", nil)
+	fsOpts := testDefaultOpts.
+		SetFilePathPrefix(filePathPrefix).
+		SetInstrumentOptions(instrument.NewOptions().SetMetricsScope(scope)).
+		SetIndexBloomFilterFalsePositivePercent(0.5)
+	rOpts := testNs1Metadata(t).Options().RetentionOptions()
+	shard := uint32(0)
+	blockStart := time.Now().Truncate(rOpts.BlockSize())
+
+	opts := testBlockRetrieverOptions{
+		retrieverOpts: NewBlockRetrieverOptions(),
+		fsOpts:        fsOpts,
+	}
+	retriever, cleanup := newOpenTestBlockRetriever(t, opts)
+	defer cleanup()
+
+	w, closer := newOpenTestWriter(t, fsOpts, shard, blockStart)
+	data := checked.NewBytes([]byte("Hello world!"), nil)
+	data.IncRef()
+	defer data.DecRef()
+	err = w.Write(ident.StringID("exists"), ident.Tags{}, data, digest.Checksum(data.Bytes()))
+	require.NoError(t, err)
+	closer()
+
+	numMisses := 100
+	for i := 0; i < numMisses; i++ {
+		ctx := context.NewContext()
+		segmentReader, err := retriever.Stream(ctx, shard,
+			ident.StringID(fmt.Sprintf("not-exists-%d", i)), blockStart, nil)
+		require.NoError(t, err)
+		segment, err := segmentReader.Segment()
+		require.NoError(t, err)
+		require.Nil(t, segment.Head)
+		ctx.Close()
+	}
+
+	counters := scope.Snapshot().Counters()
+	negatives := counters["retriever.bloom-filter.negatives+"].Value()
+	falsePositives := counters["retriever.bloom-filter.false-positives+"].Value()
+	assert.True(t, negatives > 0)
+	assert.True(t, falsePositives > 0)
+	assert.Equal(t, int64(numMisses), negatives+falsePositives)
+}
+
+// TestBlockRetrieverMissingBloomFilter verifies that filesets written without
+// a bloom filter can still be read by the retriever.
+func TestBlockRetrieverMissingBloomFilter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testdb")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	filePathPrefix := filepath.Join(dir, "")
+
+	fsOpts := testDefaultOpts.SetFilePathPrefix(filePathPrefix)
+	rOpts := testNs1Metadata(t).Options().RetentionOptions()
+	shard := uint32(0)
+	blockStart := time.Now().Truncate(rOpts.BlockSize())
+
+	opts := testBlockRetrieverOptions{
+		retrieverOpts: NewBlockRetrieverOptions(),
+		fsOpts:        fsOpts,
+	}
+	retriever, cleanup := newOpenTestBlockRetriever(t, opts)
+	defer cleanup()
+
+	w, closer := newOpenTestWriter(t, fsOpts, shard, blockStart)
+	data := checked.NewBytes([]byte("Hello world!"), nil)
+	data.IncRef()
+	defer data.DecRef()
+	err = w.Write(ident.StringID("exists"), ident.Tags{}, data, digest.Checksum(data.Bytes()))
+	require.NoError(t, err)
+	closer()
+
+	// Remove the bloom filter as filesets written before bloom filters
+	// were introduced do not have one
+	shardDir := ShardDataDirPath(filePathPrefix, testNs1ID, shard)
+	require.NoError(t, os.Remove(
+		filesetPathFromTime(shardDir, blockStart, bloomFilterFileSuffix)))
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	segmentReader, err := retriever.Stream(ctx, shard,
+		ident.StringID("exists"), blockStart, nil)
+	require.NoError(t, err)
+	segment, err := segmentReader.Segment()
+	require.NoError(t, err)
+	require.NotNil(t, segment.Head)
+	assert.Equal(t, data.Bytes(), segment.Head.Bytes())
+
+	segmentReader, err = retriever.Stream(ctx, shard,
+		ident.StringID("not-exists"), blockStart, nil)
+	require.NoError(t, err)
+	segment, err = segmentReader.Segment()
+	require.NoError(t, err)
+	assert.Nil(t, segment.Head)
+}
+
+// TestBlockRetrieverContiguousAndPagedDataLayouts verifies that filesets
+// written with the contiguous data layout of older versions and filesets
+// written with the paged data layout are read the same by the reader and
+// the retriever.
+func TestBlockRetrieverContiguousAndPagedDataLayouts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testdb")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	filePathPrefix := filepath.Join(dir, "")
+
+	fsOpts := testDefaultOpts.
+		SetFilePathPrefix(filePathPrefix).
+		SetWriterBufferSize(testWriterBufferSize)
+	rOpts := testNs1Metadata(t).Options().RetentionOptions()
+	shard := uint32(0)
+	contiguousBlockStart := time.Now().Truncate(rOpts.BlockSize())
+	pagedBlockStart := contiguousBlockStart.Add(-rOpts.BlockSize())
+
+	opts := testBlockRetrieverOptions{
+		retrieverOpts: NewBlockRetrieverOptions(),
+		fsOpts:        fsOpts,
+	}
+	retriever, cleanup := newOpenTestBlockRetriever(t, opts)
+	defer cleanup()
+
+	var entries []testEntry
+	for i := 0; i < 32; i++ {
+		entries = append(entries, testEntry{
+			id:   fmt.Sprintf("foo-%d", i),
+			data: []byte(fmt.Sprintf("data for series %d", i*i*i)),
+		})
+	}
+
+	for _, test := range []struct {
+		blockStart time.Time
+		pageSize   int
+	}{
+		{blockStart: contiguousBlockStart, pageSize: 0},
+		{blockStart: pagedBlockStart, pageSize: 64},
+	} {
+		w, err := NewWriter(fsOpts.SetWriterDataPageSize(test.pageSize))
+		require.NoError(t, err)
+		writeTestData(t, w, shard, test.blockStart, entries, persist.FileSetFlushType)
+
+		r := newTestReader(t, filePathPrefix)
+		readTestData(t, r, shard, test.blockStart, entries)
+	}
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	for _, blockStart := range []time.Time{contiguousBlockStart, pagedBlockStart} {
+		for _, entry := range entries {
+			segmentReader, err := retriever.Stream(ctx, shard,
+				ident.StringID(entry.id), blockStart, nil)
+			require.NoError(t, err)
+			segment, err := segmentReader.Segment()
+			require.NoError(t, err)
+			require.NotNil(t, segment.Head)
+			assert.Equal(t, entry.data, segment.Head.Bytes())
+		}
+	}
+}
+
+func BenchmarkBlockRetrieverMisses(b *testing.B) {
+	dir, err := ioutil.TempDir("", "testdb")
+	require.NoError(b, err)
+	defer os.RemoveAll(dir)
+	filePathPrefix := filepath.Join(dir, "")
+
+	fsOpts := testDefaultOpts.
+		SetFilePathPrefix(filePathPrefix).
+		SetWriterBufferSize(testWriterBufferSize)
+	md, err := namespace.NewMetadata(testNs1ID, namespace.NewOptions().
+		SetRetentionOptions(retention.NewOptions().SetBlockSize(testBlockSize)))
+	require.NoError(b, err)
+	shard := uint32(0)
+	blockStart := time.Now().Truncate(testBlockSize)
+
+	r := NewBlockRetriever(NewBlockRetrieverOptions(), fsOpts)
+	retriever := r.(*blockRetriever)
+	nsPath := NamespaceDataDirPath(filePathPrefix, testNs1ID)
+	require.NoError(b, os.MkdirAll(nsPath, fsOpts.NewDirectoryMode()))
+	require.NoError(b, retriever.Open(md))
+	defer retriever.Close()
+
+	// Write a fileset of IDs that are never read
+	w, err := NewWriter(fsOpts)
+	require.NoError(b, err)
+	require.NoError(b, w.Open(DataWriterOpenOptions{
+		BlockSize: testBlockSize,
+		Identifier: FileSetFileIdentifier{
+			Namespace:  testNs1ID,
+			Shard:      shard,
+			BlockStart: blockStart,
+		},
+	}))
+	data := checked.NewBytes([]byte("Hello world!"), nil)
+	data.IncRef()
+	defer data.DecRef()
+	for i := 0; i < 10000; i++ {
+		id := ident.StringID(fmt.Sprintf("exists-%d", i))
+		require.NoError(b, w.Write(id, ident.Tags{}, data, digest.Checksum(data.Bytes())))
+	}
+	require.NoError(b, w.Close())
+
+	ids := make([]ident.ID, 1024)
+	for i := range ids {
+		ids[i] = ident.StringID(fmt.Sprintf("not-exists-%d", i))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ctx := context.NewContext()
+		segmentReader, err := retriever.Stream(ctx, shard,
+			ids[i%len(ids)], blockStart, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := segmentReader.Segment(); err != nil {
+			b.Fatal(err)
+		}
+		ctx.Close()
+	}
+}
@@ -29,6 +29,8 @@ import (
 const (
 	defaultRequestPoolSize  = 16384
 	defaultFetchConcurrency = 2
+	defaultReadAheadEnabled = false
+	defaultReadAheadBlocks  = 2
 )
 
 type blockRetrieverOptions struct {
@@ -37,6 +39,8 @@ type blockRetrieverOptions struct {
 	segmentReaderPool xio.SegmentReaderPool
 	fetchConcurrency  int
 	identifierPool    ident.Pool
+	readAheadEnabled  bool
+	readAheadBlocks   int
 }
 
 // NewBlockRetrieverOptions creates a new set of block retriever options
@@ -54,6 +58,8 @@ func NewBlockRetrieverOptions() BlockRetrieverOptions {
 		segmentReaderPool: xio.NewSegmentReaderPool(nil),
 		fetchConcurrency:  defaultFetchConcurrency,
 		identifierPool:    ident.NewPool(bytesPool, ident.PoolOptions{}),
+		readAheadEnabled:  defaultReadAheadEnabled,
+		readAheadBlocks:   defaultReadAheadBlocks,
 	}
 	o.segmentReaderPool.Init()
 	return o
@@ -108,3 +114,23 @@ func (o *blockRetrieverOptions) SetIdentifierPool(value ident.Pool) BlockRetriev
 func (o *blockRetrieverOptions) IdentifierPool() ident.Pool {
 	return o.identifierPool
 }
+
+func (o *blockRetrieverOptions) SetReadAheadEnabled(value bool) BlockRetrieverOptions {
+	opts := *o
+	opts.readAheadEnabled = value
+	return &opts
+}
+
+func (o *blockRetrieverOptions) ReadAheadEnabled() bool {
+	return o.readAheadEnabled
+}
+
+func (o *blockRetrieverOptions) SetReadAheadBlocks(value int) BlockRetrieverOptions {
+	opts := *o
+	opts.readAheadBlocks = value
+	return &opts
+}
+
+func (o *blockRetrieverOptions) ReadAheadBlocks() int {
+	return o.readAheadBlocks
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/digest"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3x/checked"
+	"github.com/m3db/m3x/context"
+	"github.com/m3db/m3x/ident"
+	"github.com/m3db/m3x/instrument"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+type testOnRetrieveBlock struct {
+	sync.Mutex
+	wg        sync.WaitGroup
+	retrieved map[time.Time][]byte
+}
+
+func (o *testOnRetrieveBlock) OnRetrieveBlock(
+	id ident.ID,
+	tags ident.TagIterator,
+	startTime time.Time,
+	segment ts.Segment,
+) {
+	o.Lock()
+	o.retrieved[startTime] = append([]byte(nil), segment.Head.Bytes()...)
+	o.Unlock()
+	o.wg.Done()
+}
+
+func TestBlockRetrieverReadAheadPrefetchesSubsequentBlocks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testdb")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	filePathPrefix := filepath.Join(dir, "")
+
+	scope := tally.NewTestScope("", nil)
+	fsOpts := testDefaultOpts.
+		SetFilePathPrefix(filePathPrefix).
+		SetInstrumentOptions(instrument.NewOptions().SetMetricsScope(scope))
+	shard := uint32(0)
+	start := time.Now().Truncate(testBlockSize).Add(-4 * testBlockSize)
+
+	// Write out the same ID to four consecutive blocks
+	id := ident.StringID("foo")
+	for i := 0; i < 4; i++ {
+		blockStart := start.Add(time.Duration(i) * testBlockSize)
+		w, closer := newOpenTestWriter(t, fsOpts, shard, blockStart)
+		data := checked.NewBytes([]byte{byte(i)}, nil)
+		data.IncRef()
+		err := w.Write(id, ident.Tags{}, data, digest.Checksum(data.Bytes()))
+		require.NoError(t, err)
+		data.DecRef()
+		closer()
+	}
+
+	opts := testBlockRetrieverOptions{
+		retrieverOpts: NewBlockRetrieverOptions().
+			SetReadAheadEnabled(true).
+			SetReadAheadBlocks(2),
+		fsOpts: fsOpts,
+	}
+	retriever, cleanup := newOpenTestBlockRetriever(t, opts)
+	defer cleanup()
+
+	// Read the first block hinting a range covering the first three blocks,
+	// only the two blocks within the range should be prefetched.
+	onRetrieve := &testOnRetrieveBlock{retrieved: make(map[time.Time][]byte)}
+	onRetrieve.wg.Add(3)
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	last := start.Add(2 * testBlockSize)
+	reader, err := retriever.StreamWithReadAhead(ctx, shard, id,
+		start, last, onRetrieve)
+	require.NoError(t, err)
+
+	segment, err := reader.Segment()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0}, segment.Head.Bytes())
+
+	onRetrieve.wg.Wait()
+	onRetrieve.Lock()
+	for i := 0; i < 3; i++ {
+		blockStart := start.Add(time.Duration(i) * testBlockSize)
+		assert.Equal(t, []byte{byte(i)}, onRetrieve.retrieved[blockStart])
+	}
+	onRetrieve.Unlock()
+
+	counters := scope.Snapshot().Counters()
+	prefetched, ok := counters["retriever.read-ahead.prefetched+"]
+	require.True(t, ok)
+	assert.Equal(t, int64(2), prefetched.Value())
+	miss, ok := counters["retriever.read-ahead.miss+"]
+	require.True(t, ok)
+	assert.Equal(t, int64(1), miss.Value())
+}
+
+func TestBlockRetrieverReadAheadJoinsPendingPrefetch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testdb")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	filePathPrefix := filepath.Join(dir, "")
+
+	scope := tally.NewTestScope("", nil)
+	fsOpts := testDefaultOpts.
+		SetFilePathPrefix(filePathPrefix).
+		SetInstrumentOptions(instrument.NewOptions().SetMetricsScope(scope))
+	shard := uint32(0)
+	start := time.Now().Truncate(testBlockSize).Add(-2 * testBlockSize)
+
+	opts := testBlockRetrieverOptions{
+		retrieverOpts: NewBlockRetrieverOptions().
+			SetReadAheadEnabled(true),
+		fsOpts: fsOpts,
+	}
+	retriever, cleanup := newOpenTestBlockRetriever(t, opts)
+	defer cleanup()
+
+	// Register a pending prefetch directly, so that it is not raced by the
+	// fetch loop, and check a stream for the block joins it.
+	id := ident.StringID("foo")
+	prefetch := retriever.reqPool.Get()
+	prefetch.readAhead = true
+	prefetch.readAheadKey = newReadAheadKey(shard, id, start)
+	retriever.readAheadLock.Lock()
+	retriever.readAheadPending[prefetch.readAheadKey] = prefetch
+	retriever.readAheadLock.Unlock()
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	reader, err := retriever.StreamWithReadAhead(ctx, shard, id,
+		start, start, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(prefetch.readAheadFollowers))
+
+	// Completing the prefetch completes the stream that joined it.
+	data := checked.NewBytes([]byte("bar"), nil)
+	data.IncRef()
+	defer data.DecRef()
+	for _, follower := range retriever.takeReadAheadFollowers(prefetch) {
+		follower.onRetrieved(ts.NewSegment(data, nil, ts.FinalizeNone))
+		follower.onCallerOrRetrieverDone()
+	}
+
+	segment, err := reader.Segment()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("bar"), segment.Head.Bytes())
+
+	retriever.readAheadLock.Lock()
+	assert.Equal(t, 0, len(retriever.readAheadPending))
+	retriever.readAheadLock.Unlock()
+
+	counters := scope.Snapshot().Counters()
+	hit, ok := counters["retriever.read-ahead.hit+"]
+	require.True(t, ok)
+	assert.Equal(t, int64(1), hit.Value())
+}
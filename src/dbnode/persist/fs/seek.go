@@ -30,6 +30,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/digest"
 	"github.com/m3db/m3/src/dbnode/persist/fs/msgpack"
 	"github.com/m3db/m3/src/dbnode/persist/schema"
+	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/x/mmap"
 	"github.com/m3db/m3x/checked"
 	xerrors "github.com/m3db/m3x/errors"
@@ -60,11 +61,12 @@ type seeker struct {
 	filePathPrefix string
 
 	// Data read from the indexInfo file
-	start           time.Time
-	blockSize       time.Duration
-	entries         int
-	bloomFilterInfo schema.IndexBloomFilterInfo
-	summariesInfo   schema.IndexSummariesInfo
+	start             time.Time
+	blockSize         time.Duration
+	entries           int
+	bloomFilterInfo   schema.IndexBloomFilterInfo
+	summariesInfo     schema.IndexSummariesInfo
+	dataLayoutVersion int64
 
 	dataMmap  []byte
 	indexMmap []byte
@@ -88,10 +90,11 @@ type seeker struct {
 // IndexEntry is an entry from the index file which can be passed to
 // SeekUsingIndexEntry to seek to the data for that entry
 type IndexEntry struct {
-	Size        uint32
-	Checksum    uint32
-	Offset      int64
-	EncodedTags []byte
+	Size           uint32
+	Checksum       uint32
+	Offset         int64
+	EncodedTags    []byte
+	DatapointStats ts.DatapointStats
 }
 
 // NewSeeker returns a new seeker.
@@ -162,20 +165,30 @@ func (s *seeker) Open(namespace ident.ID, shard uint32, blockStart time.Time) er
 	}
 
 	shardDir := ShardDataDirPath(s.filePathPrefix, namespace, shard)
-	var infoFd, indexFd, dataFd, digestFd, bloomFilterFd, summariesFd *os.File
+	var infoFd, indexFd, dataFd, digestFd, summariesFd *os.File
 
 	// Open necessary files
 	if err := openFiles(os.Open, map[string]**os.File{
-		filesetPathFromTime(shardDir, blockStart, infoFileSuffix):        &infoFd,
-		filesetPathFromTime(shardDir, blockStart, indexFileSuffix):       &indexFd,
-		filesetPathFromTime(shardDir, blockStart, dataFileSuffix):        &dataFd,
-		filesetPathFromTime(shardDir, blockStart, digestFileSuffix):      &digestFd,
-		filesetPathFromTime(shardDir, blockStart, bloomFilterFileSuffix): &bloomFilterFd,
-		filesetPathFromTime(shardDir, blockStart, summariesFileSuffix):   &summariesFd,
+		filesetPathFromTime(shardDir, blockStart, infoFileSuffix):      &infoFd,
+		filesetPathFromTime(shardDir, blockStart, indexFileSuffix):     &indexFd,
+		filesetPathFromTime(shardDir, blockStart, dataFileSuffix):      &dataFd,
+		filesetPathFromTime(shardDir, blockStart, digestFileSuffix):    &digestFd,
+		filesetPathFromTime(shardDir, blockStart, summariesFileSuffix): &summariesFd,
 	}); err != nil {
 		return err
 	}
 
+	// Filesets written before bloom filters were introduced have no
+	// bloom filter file, every ID is looked up in the index for these
+	bloomFilterFd, err := openBloomFilterFile(
+		filesetPathFromTime(shardDir, blockStart, bloomFilterFileSuffix))
+	if err != nil {
+		for _, fd := range []*os.File{infoFd, indexFd, dataFd, digestFd, summariesFd} {
+			fd.Close()
+		}
+		return err
+	}
+
 	// Setup digest readers
 	var (
 		infoFdWithDigest           = digest.NewFdWithDigestReader(s.opts.infoBufferSize)
@@ -315,12 +328,16 @@ func (s *seeker) readInfo(size int, infoDigestReader digest.FdWithDigestReader,
 	if err != nil {
 		return err
 	}
+	if err := validateDataLayoutVersion(info.DataLayoutVersion); err != nil {
+		return err
+	}
 
 	s.start = xtime.FromNanoseconds(info.BlockStart)
 	s.blockSize = time.Duration(info.BlockSize)
 	s.entries = int(info.Entries)
 	s.bloomFilterInfo = info.BloomFilter
 	s.summariesInfo = info.Summaries
+	s.dataLayoutVersion = info.DataLayoutVersion
 
 	return nil
 }
@@ -404,10 +421,11 @@ func (s *seeker) SeekIndexEntry(id ident.ID) (IndexEntry, error) {
 		comparison := bytes.Compare(entry.ID, idBytes)
 		if comparison == 0 {
 			return IndexEntry{
-				Size:        uint32(entry.Size),
-				Checksum:    uint32(entry.Checksum),
-				Offset:      entry.Offset,
-				EncodedTags: entry.EncodedTags,
+				Size:           uint32(entry.Size),
+				Checksum:       uint32(entry.Checksum),
+				Offset:         indexEntryDataOffset(entry, s.dataLayoutVersion),
+				EncodedTags:    entry.EncodedTags,
+				DatapointStats: indexEntryDatapointStats(entry),
 			}, nil
 		}
 
@@ -424,6 +442,19 @@ func (s *seeker) SeekIndexEntry(id ident.ID) (IndexEntry, error) {
 	return IndexEntry{}, errSeekIDNotFound
 }
 
+// indexEntryDatapointStats returns the datapoint stats recorded in an index
+// entry, which are zero for entries written before the stats were recorded.
+func indexEntryDatapointStats(entry schema.IndexEntry) ts.DatapointStats {
+	if entry.NumDatapoints <= 0 {
+		return ts.DatapointStats{}
+	}
+	return ts.DatapointStats{
+		FirstTimestamp: time.Unix(0, entry.FirstTimestamp),
+		LastTimestamp:  time.Unix(0, entry.LastTimestamp),
+		Count:          int(entry.NumDatapoints),
+	}
+}
+
 func (s *seeker) Range() xtime.Range {
 	return xtime.Range{Start: s.start, End: s.start.Add(s.blockSize)}
 }
@@ -474,8 +505,9 @@ func (s *seeker) ConcurrentClone() (ConcurrentDataFileSetSeeker, error) {
 		dataMmap:  s.dataMmap,
 		indexMmap: s.indexMmap,
 		// bloomFilter is concurrency safe
-		bloomFilter: s.bloomFilter,
-		indexLookup: indexLookupClone,
-		isClone:     true,
+		bloomFilter:       s.bloomFilter,
+		indexLookup:       indexLookupClone,
+		dataLayoutVersion: s.dataLayoutVersion,
+		isClone:           true,
 	}, nil
 }
@@ -28,6 +28,8 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/digest"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3x/checked"
 	"github.com/m3db/m3x/ident"
 	"github.com/m3db/m3x/pool"
 
@@ -227,6 +229,58 @@ func TestSeek(t *testing.T) {
 	assert.NoError(t, s.Close())
 }
 
+func TestSeekIndexEntryDatapointStats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filePathPrefix := filepath.Join(dir, "")
+	defer os.RemoveAll(dir)
+
+	w := newTestWriter(t, filePathPrefix)
+	writerOpts := DataWriterOpenOptions{
+		BlockSize: testBlockSize,
+		Identifier: FileSetFileIdentifier{
+			Namespace:  testNs1ID,
+			Shard:      0,
+			BlockStart: testWriterStart,
+		},
+	}
+	stats := ts.DatapointStats{
+		FirstTimestamp: testWriterStart.Add(time.Minute),
+		LastTimestamp:  testWriterStart.Add(time.Hour),
+		Count:          61,
+	}
+	err = w.Open(writerOpts)
+	assert.NoError(t, err)
+	assert.NoError(t, w.WriteAllWithDatapointStats(
+		ident.StringID("foo1"), ident.Tags{},
+		[]checked.Bytes{bytesRefd([]byte{1, 2, 1})},
+		digest.Checksum([]byte{1, 2, 1}), stats))
+	assert.NoError(t, w.Write(
+		ident.StringID("foo2"), ident.Tags{},
+		bytesRefd([]byte{1, 2, 2}),
+		digest.Checksum([]byte{1, 2, 2})))
+	assert.NoError(t, w.Close())
+
+	s := newTestSeeker(filePathPrefix)
+	err = s.Open(testNs1ID, 0, testWriterStart)
+	assert.NoError(t, err)
+
+	entry, err := s.SeekIndexEntry(ident.StringID("foo1"))
+	require.NoError(t, err)
+	assert.True(t, stats.FirstTimestamp.Equal(entry.DatapointStats.FirstTimestamp))
+	assert.True(t, stats.LastTimestamp.Equal(entry.DatapointStats.LastTimestamp))
+	assert.Equal(t, stats.Count, entry.DatapointStats.Count)
+
+	// Entries written without stats report them as unknown.
+	entry, err = s.SeekIndexEntry(ident.StringID("foo2"))
+	require.NoError(t, err)
+	assert.True(t, entry.DatapointStats.IsZero())
+
+	assert.NoError(t, s.Close())
+}
+
 // TestSeekIDNotExists is similar to TestSeek, but it covers more edge cases
 // around IDs not existing.
 func TestSeekIDNotExists(t *testing.T) {
@@ -32,6 +32,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/serialize"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
+	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/xio"
 	"github.com/m3db/m3/src/m3ninx/index/segment/fst"
 	idxpersist "github.com/m3db/m3/src/m3ninx/persist"
@@ -86,6 +87,17 @@ type DataFileSetWriter interface {
 	// WriteAll will write the id and all byte slices and returns an error on a write error.
 	// Callers must not call this method with a given ID more than once.
 	WriteAll(id ident.ID, tags ident.Tags, data []checked.Bytes, checksum uint32) error
+
+	// WriteAllWithDatapointStats behaves like WriteAll but also records the
+	// stats of the datapoints in the index entry so that they can be read
+	// without decoding the data.
+	WriteAllWithDatapointStats(
+		id ident.ID,
+		tags ident.Tags,
+		data []checked.Bytes,
+		checksum uint32,
+		stats ts.DatapointStats,
+	) error
 }
 
 // DataFileSetReaderStatus describes the status of a file set reader
@@ -379,6 +391,16 @@ type Options interface {
 	// WriterBufferSize returns the buffer size for writing TSDB files
 	WriterBufferSize() int
 
+	// SetWriterDataPageSize sets the size of the pages that series data is buffered
+	// into before being written to TSDB data files, zero writes series data directly
+	// using the legacy contiguous data layout
+	SetWriterDataPageSize(value int) Options
+
+	// WriterDataPageSize returns the size of the pages that series data is buffered
+	// into before being written to TSDB data files, zero writes series data directly
+	// using the legacy contiguous data layout
+	WriterDataPageSize() int
+
 	// SetInfoReaderBufferSize sets the buffer size for reading TSDB info, digest and checkpoint files
 	SetInfoReaderBufferSize(value int) Options
 
@@ -459,4 +481,18 @@ type BlockRetrieverOptions interface {
 
 	// IdentifierPool returns the identifierPool
 	IdentifierPool() ident.Pool
+
+	// SetReadAheadEnabled sets whether to prefetch subsequent blocks of a
+	// series when a stream is hinted to be part of a larger range read
+	SetReadAheadEnabled(value bool) BlockRetrieverOptions
+
+	// ReadAheadEnabled returns whether to prefetch subsequent blocks of a
+	// series when a stream is hinted to be part of a larger range read
+	ReadAheadEnabled() bool
+
+	// SetReadAheadBlocks sets the number of subsequent blocks to prefetch
+	SetReadAheadBlocks(value int) BlockRetrieverOptions
+
+	// ReadAheadBlocks returns the number of subsequent blocks to prefetch
+	ReadAheadBlocks() int
 }
@@ -30,14 +30,18 @@ import (
 	"time"
 
 	"github.com/m3db/bloom"
+	"github.com/m3db/m3/src/dbnode/clock"
 	"github.com/m3db/m3/src/dbnode/digest"
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/persist/fs/msgpack"
 	"github.com/m3db/m3/src/dbnode/persist/schema"
 	"github.com/m3db/m3/src/dbnode/serialize"
+	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3x/checked"
 	"github.com/m3db/m3x/ident"
 	xtime "github.com/m3db/m3x/time"
+
+	"github.com/uber-go/tally"
 )
 
 var (
@@ -72,6 +76,32 @@ type writer struct {
 	singleCheckedBytes []checked.Bytes
 	tagEncoderPool     serialize.TagEncoderPool
 	err                error
+
+	// NB: when the data page size is set the data of series is buffered into a
+	// page which is written to the data file with a single write once full.
+	dataPageSize   int
+	dataPage       []byte
+	dataPageOffset int64
+
+	nowFn    clock.NowFn
+	openedAt time.Time
+	metrics  writerMetrics
+}
+
+type writerMetrics struct {
+	dataPagesWritten tally.Counter
+	dataBytesWritten tally.Counter
+	seriesWritten    tally.Counter
+	writeLatency     tally.Timer
+}
+
+func newWriterMetrics(scope tally.Scope) writerMetrics {
+	return writerMetrics{
+		dataPagesWritten: scope.Counter("data-pages-written"),
+		dataBytesWritten: scope.Counter("data-bytes-written"),
+		seriesWritten:    scope.Counter("series-written"),
+		writeLatency:     scope.Timer("write-latency"),
+	}
 }
 
 type indexEntry struct {
@@ -79,9 +109,11 @@ type indexEntry struct {
 	id              ident.ID
 	tags            ident.Tags
 	dataFileOffset  int64
+	pageOffset      int64
 	indexFileOffset int64
 	size            uint32
 	checksum        uint32
+	datapointStats  ts.DatapointStats
 }
 
 type indexEntries []indexEntry
@@ -126,6 +158,9 @@ func NewWriter(opts Options) (DataFileSetWriter, error) {
 		digestBuf:                       digest.NewBuffer(),
 		singleCheckedBytes:              make([]checked.Bytes, 1),
 		tagEncoderPool:                  opts.TagEncoderPool(),
+		dataPageSize:                    opts.WriterDataPageSize(),
+		nowFn:                           opts.ClockOptions().NowFn(),
+		metrics:                         newWriterMetrics(opts.InstrumentOptions().MetricsScope().SubScope("writer")),
 	}, nil
 }
 
@@ -147,6 +182,12 @@ func (w *writer) Open(opts DataWriterOpenOptions) error {
 	w.currIdx = 0
 	w.currOffset = 0
 	w.err = nil
+	w.dataPageOffset = 0
+	w.dataPage = w.dataPage[:0]
+	if cap(w.dataPage) < w.dataPageSize {
+		w.dataPage = make([]byte, 0, w.dataPageSize)
+	}
+	w.openedAt = w.nowFn()
 
 	var (
 		shardDir            string
@@ -220,6 +261,11 @@ func (w *writer) writeData(data []byte) error {
 	if len(data) == 0 {
 		return nil
 	}
+	if w.dataPageSize > 0 {
+		w.dataPage = append(w.dataPage, data...)
+		w.currOffset += int64(len(data))
+		return nil
+	}
 	written, err := w.dataFdWithDigest.Write(data)
 	if err != nil {
 		return err
@@ -228,6 +274,29 @@ func (w *writer) writeData(data []byte) error {
 	return nil
 }
 
+// writeDataPage writes the current data page to the data file, since only whole
+// pages are written the buffered data file writer is always empty and passes
+// pages at least as large as its buffer straight through with a single write.
+func (w *writer) writeDataPage() error {
+	if len(w.dataPage) == 0 {
+		return nil
+	}
+	if _, err := w.dataFdWithDigest.Write(w.dataPage); err != nil {
+		return err
+	}
+	w.dataPageOffset += int64(len(w.dataPage))
+	w.dataPage = w.dataPage[:0]
+	w.metrics.dataPagesWritten.Inc(1)
+	return nil
+}
+
+func (w *writer) dataLayoutVersion() int64 {
+	if w.dataPageSize > 0 {
+		return schema.DataLayoutVersionPaged
+	}
+	return schema.DataLayoutVersionContiguous
+}
+
 func (w *writer) Write(
 	id ident.ID,
 	tags ident.Tags,
@@ -243,12 +312,22 @@ func (w *writer) WriteAll(
 	tags ident.Tags,
 	data []checked.Bytes,
 	checksum uint32,
+) error {
+	return w.WriteAllWithDatapointStats(id, tags, data, checksum, ts.DatapointStats{})
+}
+
+func (w *writer) WriteAllWithDatapointStats(
+	id ident.ID,
+	tags ident.Tags,
+	data []checked.Bytes,
+	checksum uint32,
+	stats ts.DatapointStats,
 ) error {
 	if w.err != nil {
 		return w.err
 	}
 
-	if err := w.writeAll(id, tags, data, checksum); err != nil {
+	if err := w.writeAll(id, tags, data, checksum, stats); err != nil {
 		w.err = err
 		return err
 	}
@@ -260,6 +339,7 @@ func (w *writer) writeAll(
 	tags ident.Tags,
 	data []checked.Bytes,
 	checksum uint32,
+	stats ts.DatapointStats,
 ) error {
 	var size int64
 	for _, d := range data {
@@ -279,6 +359,18 @@ func (w *writer) writeAll(
 		dataFileOffset: w.currOffset,
 		size:           uint32(size),
 		checksum:       checksum,
+		datapointStats: stats,
+	}
+	if w.dataPageSize > 0 {
+		// Series data never straddles two pages, series larger than a page
+		// are written as a page of their own.
+		if len(w.dataPage) > 0 && int64(len(w.dataPage))+size > int64(w.dataPageSize) {
+			if err := w.writeDataPage(); err != nil {
+				return err
+			}
+		}
+		entry.dataFileOffset = w.dataPageOffset
+		entry.pageOffset = int64(len(w.dataPage))
 	}
 	for _, d := range data {
 		if d == nil {
@@ -288,6 +380,11 @@ func (w *writer) writeAll(
 			return err
 		}
 	}
+	if w.dataPageSize > 0 && len(w.dataPage) >= w.dataPageSize {
+		if err := w.writeDataPage(); err != nil {
+			return err
+		}
+	}
 
 	w.indexEntries = append(w.indexEntries, entry)
 	w.currIdx++
@@ -310,10 +407,17 @@ func (w *writer) Close() error {
 		w.err = err
 		return err
 	}
+	w.metrics.dataBytesWritten.Inc(w.currOffset)
+	w.metrics.seriesWritten.Inc(w.currIdx)
+	w.metrics.writeLatency.Record(w.nowFn().Sub(w.openedAt))
 	return nil
 }
 
 func (w *writer) close() error {
+	if err := w.writeDataPage(); err != nil {
+		return err
+	}
+
 	if err := w.writeIndexRelatedFiles(); err != nil {
 		return err
 	}
@@ -443,6 +547,12 @@ func (w *writer) writeIndexFileContents(
 			Offset:      w.indexEntries[i].dataFileOffset,
 			Checksum:    int64(w.indexEntries[i].checksum),
 			EncodedTags: encodedTags,
+			PageOffset:  w.indexEntries[i].pageOffset,
+		}
+		if stats := w.indexEntries[i].datapointStats; !stats.IsZero() {
+			entry.FirstTimestamp = stats.FirstTimestamp.UnixNano()
+			entry.LastTimestamp = stats.LastTimestamp.UnixNano()
+			entry.NumDatapoints = int64(stats.Count)
 		}
 
 		w.encoder.Reset()
@@ -516,11 +626,12 @@ func (w *writer) writeInfoFileContents(
 	summaries int,
 ) error {
 	info := schema.IndexInfo{
-		BlockStart:   xtime.ToNanoseconds(w.start),
-		SnapshotTime: xtime.ToNanoseconds(w.snapshotTime),
-		BlockSize:    int64(w.blockSize),
-		Entries:      w.currIdx,
-		MajorVersion: schema.MajorVersion,
+		BlockStart:        xtime.ToNanoseconds(w.start),
+		SnapshotTime:      xtime.ToNanoseconds(w.snapshotTime),
+		BlockSize:         int64(w.blockSize),
+		Entries:           w.currIdx,
+		MajorVersion:      schema.MajorVersion,
+		DataLayoutVersion: w.dataLayoutVersion(),
 		Summaries: schema.IndexSummariesInfo{
 			Summaries: int64(summaries),
 		},
@@ -29,16 +29,29 @@ import (
 // tooling needs to upgrade older files to newer files before a server restart
 const MajorVersion = 1
 
+const (
+	// DataLayoutVersionContiguous is the data layout version of filesets where
+	// the offset of each index entry is the offset of its data in the data file.
+	DataLayoutVersionContiguous = 0
+
+	// DataLayoutVersionPaged is the data layout version of filesets where the
+	// data of series is written in pages, the offset of each index entry is the
+	// offset of its page in the data file and the page offset of the entry is the
+	// offset of its data within the page.
+	DataLayoutVersionPaged = 1
+)
+
 // IndexInfo stores metadata information about block filesets
 type IndexInfo struct {
-	MajorVersion int64
-	BlockStart   int64
-	BlockSize    int64
-	Entries      int64
-	Summaries    IndexSummariesInfo
-	BloomFilter  IndexBloomFilterInfo
-	SnapshotTime int64
-	FileType     persist.FileSetType
+	MajorVersion      int64
+	BlockStart        int64
+	BlockSize         int64
+	Entries           int64
+	Summaries         IndexSummariesInfo
+	BloomFilter       IndexBloomFilterInfo
+	SnapshotTime      int64
+	FileType          persist.FileSetType
+	DataLayoutVersion int64
 }
 
 // IndexSummariesInfo stores metadata about the summaries
@@ -54,12 +67,16 @@ type IndexBloomFilterInfo struct {
 
 // IndexEntry stores entry-level data indexing
 type IndexEntry struct {
-	Index       int64
-	ID          []byte
-	Size        int64
-	Offset      int64
-	Checksum    int64
-	EncodedTags []byte
+	Index          int64
+	ID             []byte
+	Size           int64
+	Offset         int64
+	Checksum       int64
+	EncodedTags    []byte
+	FirstTimestamp int64
+	LastTimestamp  int64
+	NumDatapoints  int64
+	PageOffset     int64
 }
 
 // IndexSummary stores a summary of an index entry to lookup
@@ -30,8 +30,15 @@ import (
 	"github.com/m3db/m3x/ident"
 )
 
-// DataFn is a function that persists a m3db segment for a given ID.
-type DataFn func(id ident.ID, tags ident.Tags, segment ts.Segment, checksum uint32) error
+// DataFn is a function that persists a m3db segment for a given ID along with
+// the stats of the datapoints it holds, which are zero if unknown.
+type DataFn func(
+	id ident.ID,
+	tags ident.Tags,
+	segment ts.Segment,
+	checksum uint32,
+	stats ts.DatapointStats,
+) error
 
 // DataCloser is a function that performs cleanup after persisting the data
 // blocks for a (shard, blockStart) combination.
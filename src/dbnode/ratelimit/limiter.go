@@ -0,0 +1,72 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+type nowFn func() time.Time
+
+type sleepFn func(time.Duration)
+
+type limiter struct {
+	sync.Mutex
+
+	interval time.Duration
+	next     time.Time
+	nowFn    nowFn
+	sleepFn  sleepFn
+}
+
+// NewLimiter returns a limiter that allows at most perSecond operations
+// per second, a limit of zero or less does not limit operations at all.
+func NewLimiter(perSecond float64) Limiter {
+	if perSecond <= 0 {
+		return noopLimiter{}
+	}
+	return &limiter{
+		interval: time.Duration(float64(time.Second) / perSecond),
+		nowFn:    time.Now,
+		sleepFn:  time.Sleep,
+	}
+}
+
+func (l *limiter) Wait() {
+	l.Lock()
+	now := l.nowFn()
+	if l.next.Before(now) {
+		// NB: Do not accumulate a burst of operations while idle.
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.Unlock()
+
+	if wait > 0 {
+		l.sleepFn(wait)
+	}
+}
+
+type noopLimiter struct{}
+
+func (noopLimiter) Wait() {}
@@ -0,0 +1,56 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiterPacesOperations(t *testing.T) {
+	var (
+		now    = time.Now()
+		slept  []time.Duration
+		l      = NewLimiter(10).(*limiter)
+		second = time.Second
+	)
+	l.nowFn = func() time.Time { return now }
+	l.sleepFn = func(d time.Duration) { slept = append(slept, d) }
+
+	for i := 0; i < 3; i++ {
+		l.Wait()
+	}
+	assert.Equal(t, []time.Duration{second / 10, 2 * second / 10}, slept)
+
+	// After being idle the limiter does not allow a burst
+	now = now.Add(time.Minute)
+	slept = slept[:0]
+	l.Wait()
+	l.Wait()
+	assert.Equal(t, []time.Duration{second / 10}, slept)
+}
+
+func TestLimiterZeroDoesNotLimit(t *testing.T) {
+	_, ok := NewLimiter(0).(noopLimiter)
+	assert.True(t, ok)
+}
@@ -40,3 +40,9 @@ type Options interface {
 	// LimitCheckEvery returns the limit check frequency
 	LimitCheckEvery() int
 }
+
+// Limiter paces operations to a maximum rate
+type Limiter interface {
+	// Wait blocks until the next operation is allowed to proceed
+	Wait()
+}
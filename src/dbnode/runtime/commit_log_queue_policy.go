@@ -0,0 +1,120 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package runtime
+
+import (
+	"errors"
+	"fmt"
+)
+
+// CommitLogQueuePolicy is the policy applied to writes to the commit log
+// when its queue is full.
+type CommitLogQueuePolicy int
+
+const (
+	// CommitLogQueuePolicyDropCurrent rejects the incoming write with a
+	// retryable error when the queue is full.
+	CommitLogQueuePolicyDropCurrent CommitLogQueuePolicy = iota
+
+	// CommitLogQueuePolicyDropOldest evicts the oldest queued write to make
+	// room for the incoming write when the queue is full, the evicted write
+	// fails with a retryable error. Only commit logs which wait for writes to
+	// be flushed evict writes, the queued writes of commit logs which write
+	// behind have already been acknowledged so the incoming write is rejected
+	// instead, as with CommitLogQueuePolicyDropCurrent.
+	CommitLogQueuePolicyDropOldest
+
+	// CommitLogQueuePolicyBlock blocks the incoming write until there is
+	// room in the queue.
+	CommitLogQueuePolicyBlock
+)
+
+var validCommitLogQueuePolicies = []CommitLogQueuePolicy{
+	CommitLogQueuePolicyDropCurrent,
+	CommitLogQueuePolicyDropOldest,
+	CommitLogQueuePolicyBlock,
+}
+
+var (
+	errCommitLogQueuePolicyUnspecified = errors.New("commit log queue policy not specified")
+)
+
+// String returns the commit log queue policy as a string
+func (p CommitLogQueuePolicy) String() string {
+	switch p {
+	case CommitLogQueuePolicyDropCurrent:
+		return "drop-current"
+	case CommitLogQueuePolicyDropOldest:
+		return "drop-oldest"
+	case CommitLogQueuePolicyBlock:
+		return "block"
+	}
+	return "unknown"
+}
+
+// ValidCommitLogQueuePolicies returns a copy of the valid commit log queue
+// policies to avoid callers mutating the set of valid policies
+func ValidCommitLogQueuePolicies() []CommitLogQueuePolicy {
+	result := make([]CommitLogQueuePolicy, len(validCommitLogQueuePolicies))
+	copy(result, validCommitLogQueuePolicies)
+	return result
+}
+
+// ValidateCommitLogQueuePolicy returns nil when the commit log queue policy
+// is valid, otherwise it returns an error
+func ValidateCommitLogQueuePolicy(v CommitLogQueuePolicy) error {
+	for _, policy := range validCommitLogQueuePolicies {
+		if policy == v {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid CommitLogQueuePolicy '%d' valid policies are: %v",
+		int(v), validCommitLogQueuePolicies)
+}
+
+// ParseCommitLogQueuePolicy parses a CommitLogQueuePolicy from a string.
+func ParseCommitLogQueuePolicy(str string) (CommitLogQueuePolicy, error) {
+	var r CommitLogQueuePolicy
+	if str == "" {
+		return r, errCommitLogQueuePolicyUnspecified
+	}
+	for _, valid := range validCommitLogQueuePolicies {
+		if str == valid.String() {
+			return valid, nil
+		}
+	}
+	return r, fmt.Errorf("invalid CommitLogQueuePolicy '%s' valid policies are: %v",
+		str, validCommitLogQueuePolicies)
+}
+
+// UnmarshalYAML unmarshals a CommitLogQueuePolicy into a valid type from string.
+func (p *CommitLogQueuePolicy) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	r, err := ParseCommitLogQueuePolicy(str)
+	if err != nil {
+		return err
+	}
+	*p = r
+	return nil
+}
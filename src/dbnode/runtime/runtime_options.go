@@ -46,10 +46,39 @@ const (
 	defaultWriteNewSeriesAsync                  = false
 	defaultWriteNewSeriesBackoffDuration        = time.Duration(0)
 	defaultWriteNewSeriesLimitPerShardPerSecond = 0
+	defaultWriteNewSeriesBacklogPerShard        = 0
 	defaultTickSeriesBatchSize                  = 512
 	defaultTickPerSeriesSleepDuration           = 100 * time.Microsecond
 	defaultTickMinimumInterval                  = time.Minute
 	defaultMaxWiredBlocks                       = uint(1 << 18) // 262,144
+	defaultRepairEnabled                        = true
+	defaultRepairThrottle                       = 90 * time.Second
+	defaultReadOnly                             = false
+	defaultCommitLogQueuePolicy                 = CommitLogQueuePolicyDropCurrent
+	defaultCommitLogQueueLimit                  = 0
+)
+
+var (
+	defaultTickAdaptivePacing = TickAdaptivePacing{
+		Enabled:                   false,
+		TargetWriteLatency:        time.Millisecond,
+		MinSeriesBatchSize:        64,
+		MaxSeriesBatchSize:        4096,
+		MinPerSeriesSleepDuration: 10 * time.Microsecond,
+		MaxPerSeriesSleepDuration: time.Millisecond,
+	}
+	defaultIndexCompactionScheduling = IndexCompactionScheduling{
+		InsertRateThreshold: 0,
+		InsertRateWindow:    10 * time.Second,
+		MaxDeferral:         time.Minute,
+	}
+	defaultShardCircuitBreaker = ShardCircuitBreaker{
+		Enabled:            false,
+		ErrorRateThreshold: 0.5,
+		MinimumRequests:    20,
+		Window:             10 * time.Second,
+		ProbeInterval:      5 * time.Second,
+	}
 )
 
 var (
@@ -57,10 +86,48 @@ var (
 		"write new series backoff duration cannot be negative")
 	errWriteNewSeriesLimitPerShardPerSecondIsNegative = errors.New(
 		"write new series limit per shard per cannot be negative")
+	errWriteNewSeriesBacklogPerShardIsNegative = errors.New(
+		"write new series backlog per shard cannot be negative")
 	errTickSeriesBatchSizeMustBePositive = errors.New(
 		"tick series batch size must be positive")
 	errTickPerSeriesSleepDurationMustBePositive = errors.New(
 		"tick per series sleep duration must be positive")
+	errTickAdaptivePacingTargetWriteLatencyMustBePositive = errors.New(
+		"tick adaptive pacing target write latency must be positive")
+	errTickAdaptivePacingMinSeriesBatchSizeMustBePositive = errors.New(
+		"tick adaptive pacing min series batch size must be positive")
+	errTickAdaptivePacingSeriesBatchSizeBoundsInvalid = errors.New(
+		"tick adaptive pacing max series batch size must not be less than min")
+	errTickAdaptivePacingMinPerSeriesSleepDurationMustBePositive = errors.New(
+		"tick adaptive pacing min per series sleep duration must be positive")
+	errTickAdaptivePacingPerSeriesSleepDurationBoundsInvalid = errors.New(
+		"tick adaptive pacing max per series sleep duration must not be less than min")
+	errRepairThrottleIsNegative = errors.New(
+		"repair throttle cannot be negative")
+	errCommitLogQueueLimitIsNegative = errors.New(
+		"commit log queue limit cannot be negative")
+	errIndexQueryLimitsIsNegative = errors.New(
+		"index query limits cannot be negative")
+	errIndexCompactionInsertRateThresholdIsNegative = errors.New(
+		"index compaction insert rate threshold cannot be negative")
+	errIndexCompactionInsertRateWindowMustBePositive = errors.New(
+		"index compaction insert rate window must be positive")
+	errIndexCompactionMaxDeferralIsNegative = errors.New(
+		"index compaction max deferral cannot be negative")
+	errWriteShadowingRuleNamespaceMissing = errors.New(
+		"write shadowing rule source and target namespaces must be set")
+	errWriteShadowingRuleSameNamespace = errors.New(
+		"write shadowing rule source and target namespaces must differ")
+	errWriteShadowingRuleFractionInvalid = errors.New(
+		"write shadowing rule fraction must be between zero and one")
+	errShardCircuitBreakerErrorRateThresholdInvalid = errors.New(
+		"shard circuit breaker error rate threshold must be above zero and at most one")
+	errShardCircuitBreakerMinimumRequestsMustBePositive = errors.New(
+		"shard circuit breaker minimum requests must be positive")
+	errShardCircuitBreakerWindowMustBePositive = errors.New(
+		"shard circuit breaker window must be positive")
+	errShardCircuitBreakerProbeIntervalMustBePositive = errors.New(
+		"shard circuit breaker probe interval must be positive")
 )
 
 type options struct {
@@ -68,14 +135,27 @@ type options struct {
 	writeNewSeriesAsync                  bool
 	writeNewSeriesBackoffDuration        time.Duration
 	writeNewSeriesLimitPerShardPerSecond int
+	writeNewSeriesBacklogPerShard        int
 	tickSeriesBatchSize                  int
 	tickPerSeriesSleepDuration           time.Duration
 	tickMinimumInterval                  time.Duration
+	tickAdaptivePacing                   TickAdaptivePacing
 	maxWiredBlocks                       uint
 	clientBootstrapConsistencyLevel      topology.ReadConsistencyLevel
 	clientReadConsistencyLevel           topology.ReadConsistencyLevel
 	clientWriteConsistencyLevel          topology.ConsistencyLevel
+	clientNamespaceWriteConsistency      map[string]topology.ConsistencyLevel
 	flushIndexBlockNumSegments           uint
+	repairEnabled                        bool
+	repairThrottle                       time.Duration
+	readOnly                             bool
+	commitLogQueuePolicy                 CommitLogQueuePolicy
+	commitLogQueueLimit                  int
+	indexQueryLimits                     IndexQueryLimits
+	namespaceIndexQueryLimits            map[string]IndexQueryLimits
+	indexCompactionScheduling            IndexCompactionScheduling
+	writeShadowingRules                  []WriteShadowingRule
+	shardCircuitBreaker                  ShardCircuitBreaker
 }
 
 // NewOptions creates a new set of runtime options with defaults
@@ -85,14 +165,23 @@ func NewOptions() Options {
 		writeNewSeriesAsync:                  defaultWriteNewSeriesAsync,
 		writeNewSeriesBackoffDuration:        defaultWriteNewSeriesBackoffDuration,
 		writeNewSeriesLimitPerShardPerSecond: defaultWriteNewSeriesLimitPerShardPerSecond,
+		writeNewSeriesBacklogPerShard:        defaultWriteNewSeriesBacklogPerShard,
 		tickSeriesBatchSize:                  defaultTickSeriesBatchSize,
 		tickPerSeriesSleepDuration:           defaultTickPerSeriesSleepDuration,
 		tickMinimumInterval:                  defaultTickMinimumInterval,
+		tickAdaptivePacing:                   defaultTickAdaptivePacing,
 		maxWiredBlocks:                       defaultMaxWiredBlocks,
 		clientBootstrapConsistencyLevel:      DefaultBootstrapConsistencyLevel,
 		clientReadConsistencyLevel:           DefaultReadConsistencyLevel,
 		clientWriteConsistencyLevel:          DefaultWriteConsistencyLevel,
 		flushIndexBlockNumSegments:           DefaultFlushIndexBlockNumSegments,
+		repairEnabled:                        defaultRepairEnabled,
+		repairThrottle:                       defaultRepairThrottle,
+		readOnly:                             defaultReadOnly,
+		commitLogQueuePolicy:                 defaultCommitLogQueuePolicy,
+		commitLogQueueLimit:                  defaultCommitLogQueueLimit,
+		indexCompactionScheduling:            defaultIndexCompactionScheduling,
+		shardCircuitBreaker:                  defaultShardCircuitBreaker,
 	}
 }
 
@@ -108,6 +197,12 @@ func (o *options) Validate() error {
 		return errWriteNewSeriesLimitPerShardPerSecondIsNegative
 	}
 
+	// writeNewSeriesBacklogPerShard can be zero to specify that new series
+	// insertions exceeding the limit are rejected rather than queued
+	if o.writeNewSeriesBacklogPerShard < 0 {
+		return errWriteNewSeriesBacklogPerShardIsNegative
+	}
+
 	if !(o.tickSeriesBatchSize > 0) {
 		return errTickSeriesBatchSizeMustBePositive
 	}
@@ -118,6 +213,47 @@ func (o *options) Validate() error {
 
 	// tickMinimumInterval can be zero if user desires
 
+	if err := o.tickAdaptivePacing.validate(); err != nil {
+		return err
+	}
+
+	// repairThrottle can be zero to specify no throttle
+	if o.repairThrottle < 0 {
+		return errRepairThrottleIsNegative
+	}
+
+	if err := ValidateCommitLogQueuePolicy(o.commitLogQueuePolicy); err != nil {
+		return err
+	}
+
+	// commitLogQueueLimit can be zero to specify the backlog queue size is used
+	if o.commitLogQueueLimit < 0 {
+		return errCommitLogQueueLimitIsNegative
+	}
+
+	if err := o.indexQueryLimits.validate(); err != nil {
+		return err
+	}
+	for _, limits := range o.namespaceIndexQueryLimits {
+		if err := limits.validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := o.indexCompactionScheduling.validate(); err != nil {
+		return err
+	}
+
+	for _, rule := range o.writeShadowingRules {
+		if err := rule.validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := o.shardCircuitBreaker.validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -161,6 +297,16 @@ func (o *options) WriteNewSeriesLimitPerShardPerSecond() int {
 	return o.writeNewSeriesLimitPerShardPerSecond
 }
 
+func (o *options) SetWriteNewSeriesBacklogPerShard(value int) Options {
+	opts := *o
+	opts.writeNewSeriesBacklogPerShard = value
+	return &opts
+}
+
+func (o *options) WriteNewSeriesBacklogPerShard() int {
+	return o.writeNewSeriesBacklogPerShard
+}
+
 func (o *options) SetTickSeriesBatchSize(value int) Options {
 	opts := *o
 	opts.tickSeriesBatchSize = value
@@ -191,6 +337,16 @@ func (o *options) TickMinimumInterval() time.Duration {
 	return o.tickMinimumInterval
 }
 
+func (o *options) SetTickAdaptivePacing(value TickAdaptivePacing) Options {
+	opts := *o
+	opts.tickAdaptivePacing = value
+	return &opts
+}
+
+func (o *options) TickAdaptivePacing() TickAdaptivePacing {
+	return o.tickAdaptivePacing
+}
+
 func (o *options) SetMaxWiredBlocks(value uint) Options {
 	opts := *o
 	opts.maxWiredBlocks = value
@@ -231,6 +387,25 @@ func (o *options) ClientWriteConsistencyLevel() topology.ConsistencyLevel {
 	return o.clientWriteConsistencyLevel
 }
 
+func (o *options) SetClientNamespaceWriteConsistencyLevels(
+	value map[string]topology.ConsistencyLevel,
+) Options {
+	opts := *o
+	opts.clientNamespaceWriteConsistency = nil
+	if len(value) > 0 {
+		// Copy so that the caller can't mutate these (immutable) options.
+		opts.clientNamespaceWriteConsistency = make(map[string]topology.ConsistencyLevel, len(value))
+		for namespace, level := range value {
+			opts.clientNamespaceWriteConsistency[namespace] = level
+		}
+	}
+	return &opts
+}
+
+func (o *options) ClientNamespaceWriteConsistencyLevels() map[string]topology.ConsistencyLevel {
+	return o.clientNamespaceWriteConsistency
+}
+
 func (o *options) SetFlushIndexBlockNumSegments(value uint) Options {
 	opts := *o
 	opts.flushIndexBlockNumSegments = value
@@ -240,3 +415,188 @@ func (o *options) SetFlushIndexBlockNumSegments(value uint) Options {
 func (o *options) FlushIndexBlockNumSegments() uint {
 	return o.flushIndexBlockNumSegments
 }
+
+func (o *options) SetRepairEnabled(value bool) Options {
+	opts := *o
+	opts.repairEnabled = value
+	return &opts
+}
+
+func (o *options) RepairEnabled() bool {
+	return o.repairEnabled
+}
+
+func (o *options) SetRepairThrottle(value time.Duration) Options {
+	opts := *o
+	opts.repairThrottle = value
+	return &opts
+}
+
+func (o *options) RepairThrottle() time.Duration {
+	return o.repairThrottle
+}
+
+func (o *options) SetReadOnly(value bool) Options {
+	opts := *o
+	opts.readOnly = value
+	return &opts
+}
+
+func (o *options) ReadOnly() bool {
+	return o.readOnly
+}
+
+func (o *options) SetCommitLogQueuePolicy(value CommitLogQueuePolicy) Options {
+	opts := *o
+	opts.commitLogQueuePolicy = value
+	return &opts
+}
+
+func (o *options) CommitLogQueuePolicy() CommitLogQueuePolicy {
+	return o.commitLogQueuePolicy
+}
+
+func (o *options) SetCommitLogQueueLimit(value int) Options {
+	opts := *o
+	opts.commitLogQueueLimit = value
+	return &opts
+}
+
+func (o *options) CommitLogQueueLimit() int {
+	return o.commitLogQueueLimit
+}
+
+func (o *options) SetIndexQueryLimits(value IndexQueryLimits) Options {
+	opts := *o
+	opts.indexQueryLimits = value
+	return &opts
+}
+
+func (o *options) IndexQueryLimits() IndexQueryLimits {
+	return o.indexQueryLimits
+}
+
+func (o *options) SetNamespaceIndexQueryLimits(
+	value map[string]IndexQueryLimits,
+) Options {
+	opts := *o
+	opts.namespaceIndexQueryLimits = nil
+	if len(value) > 0 {
+		// Copy so that the caller can't mutate these (immutable) options.
+		opts.namespaceIndexQueryLimits = make(map[string]IndexQueryLimits, len(value))
+		for namespace, limits := range value {
+			opts.namespaceIndexQueryLimits[namespace] = limits
+		}
+	}
+	return &opts
+}
+
+func (o *options) NamespaceIndexQueryLimits() map[string]IndexQueryLimits {
+	return o.namespaceIndexQueryLimits
+}
+
+func (o *options) SetIndexCompactionScheduling(value IndexCompactionScheduling) Options {
+	opts := *o
+	opts.indexCompactionScheduling = value
+	return &opts
+}
+
+func (o *options) IndexCompactionScheduling() IndexCompactionScheduling {
+	return o.indexCompactionScheduling
+}
+
+func (o *options) SetWriteShadowingRules(value []WriteShadowingRule) Options {
+	opts := *o
+	opts.writeShadowingRules = nil
+	if len(value) > 0 {
+		// Copy so that the caller can't mutate these (immutable) options.
+		opts.writeShadowingRules = append([]WriteShadowingRule(nil), value...)
+	}
+	return &opts
+}
+
+func (o *options) WriteShadowingRules() []WriteShadowingRule {
+	return o.writeShadowingRules
+}
+
+func (o *options) SetShardCircuitBreaker(value ShardCircuitBreaker) Options {
+	opts := *o
+	opts.shardCircuitBreaker = value
+	return &opts
+}
+
+func (o *options) ShardCircuitBreaker() ShardCircuitBreaker {
+	return o.shardCircuitBreaker
+}
+
+func (b ShardCircuitBreaker) validate() error {
+	// The thresholds are validated even when disabled so that enabling the
+	// breakers at runtime can't apply invalid thresholds.
+	if !(b.ErrorRateThreshold > 0 && b.ErrorRateThreshold <= 1) {
+		return errShardCircuitBreakerErrorRateThresholdInvalid
+	}
+	if !(b.MinimumRequests > 0) {
+		return errShardCircuitBreakerMinimumRequestsMustBePositive
+	}
+	if !(b.Window > 0) {
+		return errShardCircuitBreakerWindowMustBePositive
+	}
+	if !(b.ProbeInterval > 0) {
+		return errShardCircuitBreakerProbeIntervalMustBePositive
+	}
+	return nil
+}
+
+func (r WriteShadowingRule) validate() error {
+	if r.SourceNamespace == "" || r.TargetNamespace == "" {
+		return errWriteShadowingRuleNamespaceMissing
+	}
+	if r.SourceNamespace == r.TargetNamespace {
+		return errWriteShadowingRuleSameNamespace
+	}
+	if !(r.Fraction >= 0 && r.Fraction <= 1) {
+		return errWriteShadowingRuleFractionInvalid
+	}
+	return nil
+}
+
+func (s IndexCompactionScheduling) validate() error {
+	if s.InsertRateThreshold < 0 {
+		return errIndexCompactionInsertRateThresholdIsNegative
+	}
+	if !(s.InsertRateWindow > 0) {
+		return errIndexCompactionInsertRateWindowMustBePositive
+	}
+	if s.MaxDeferral < 0 {
+		return errIndexCompactionMaxDeferralIsNegative
+	}
+	return nil
+}
+
+func (l IndexQueryLimits) validate() error {
+	if l.SeriesLimit < 0 || l.DocsLimit < 0 {
+		return errIndexQueryLimitsIsNegative
+	}
+	return nil
+}
+
+func (p TickAdaptivePacing) validate() error {
+	// The bounds are validated even when disabled so that enabling the
+	// adaptive pacing at runtime can't apply invalid bounds.
+	if !(p.TargetWriteLatency > 0) {
+		return errTickAdaptivePacingTargetWriteLatencyMustBePositive
+	}
+	if !(p.MinSeriesBatchSize > 0) {
+		return errTickAdaptivePacingMinSeriesBatchSizeMustBePositive
+	}
+	if p.MaxSeriesBatchSize < p.MinSeriesBatchSize {
+		return errTickAdaptivePacingSeriesBatchSizeBoundsInvalid
+	}
+	if !(p.MinPerSeriesSleepDuration > 0) {
+		return errTickAdaptivePacingMinPerSeriesSleepDurationMustBePositive
+	}
+	if p.MaxPerSeriesSleepDuration < p.MinPerSeriesSleepDuration {
+		return errTickAdaptivePacingPerSeriesSleepDurationBoundsInvalid
+	}
+	return nil
+}
@@ -22,6 +22,9 @@ package runtime
 
 import (
 	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/topology"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -30,3 +33,259 @@ func TestRuntimeOptionsDefaultsIsValid(t *testing.T) {
 	v := NewOptions()
 	assert.NoError(t, v.Validate())
 }
+
+func TestRuntimeOptionsRepair(t *testing.T) {
+	v := NewOptions()
+	assert.True(t, v.RepairEnabled())
+	assert.Equal(t, defaultRepairThrottle, v.RepairThrottle())
+
+	v = v.SetRepairEnabled(false).SetRepairThrottle(10 * time.Second)
+	assert.False(t, v.RepairEnabled())
+	assert.Equal(t, 10*time.Second, v.RepairThrottle())
+	assert.NoError(t, v.Validate())
+
+	v = v.SetRepairThrottle(-time.Second)
+	assert.Equal(t, errRepairThrottleIsNegative, v.Validate())
+}
+
+func TestRuntimeOptionsWriteNewSeriesBacklogPerShard(t *testing.T) {
+	v := NewOptions()
+	assert.Equal(t, 0, v.WriteNewSeriesBacklogPerShard())
+
+	v = v.SetWriteNewSeriesBacklogPerShard(128)
+	assert.Equal(t, 128, v.WriteNewSeriesBacklogPerShard())
+	assert.NoError(t, v.Validate())
+
+	v = v.SetWriteNewSeriesBacklogPerShard(-1)
+	assert.Equal(t, errWriteNewSeriesBacklogPerShardIsNegative, v.Validate())
+}
+
+func TestRuntimeOptionsClientNamespaceWriteConsistencyLevels(t *testing.T) {
+	v := NewOptions()
+	assert.Nil(t, v.ClientNamespaceWriteConsistencyLevels())
+
+	levels := map[string]topology.ConsistencyLevel{
+		"metrics": topology.ConsistencyLevelMajority,
+		"logs":    topology.ConsistencyLevelOne,
+	}
+	v = v.SetClientNamespaceWriteConsistencyLevels(levels)
+	assert.Equal(t, levels, v.ClientNamespaceWriteConsistencyLevels())
+
+	// Mutating the map set must not mutate the options.
+	levels["logs"] = topology.ConsistencyLevelAll
+	assert.Equal(t, topology.ConsistencyLevelOne,
+		v.ClientNamespaceWriteConsistencyLevels()["logs"])
+
+	v = v.SetClientNamespaceWriteConsistencyLevels(nil)
+	assert.Nil(t, v.ClientNamespaceWriteConsistencyLevels())
+}
+
+func TestRuntimeOptionsReadOnly(t *testing.T) {
+	v := NewOptions()
+	assert.False(t, v.ReadOnly())
+
+	v = v.SetReadOnly(true)
+	assert.True(t, v.ReadOnly())
+	assert.NoError(t, v.Validate())
+}
+
+func TestRuntimeOptionsTickAdaptivePacing(t *testing.T) {
+	v := NewOptions()
+	assert.Equal(t, defaultTickAdaptivePacing, v.TickAdaptivePacing())
+	assert.False(t, v.TickAdaptivePacing().Enabled)
+
+	pacing := TickAdaptivePacing{
+		Enabled:                   true,
+		TargetWriteLatency:        5 * time.Millisecond,
+		MinSeriesBatchSize:        16,
+		MaxSeriesBatchSize:        1024,
+		MinPerSeriesSleepDuration: time.Microsecond,
+		MaxPerSeriesSleepDuration: 10 * time.Millisecond,
+	}
+	v = v.SetTickAdaptivePacing(pacing)
+	assert.Equal(t, pacing, v.TickAdaptivePacing())
+	assert.NoError(t, v.Validate())
+
+	invalid := pacing
+	invalid.TargetWriteLatency = 0
+	assert.Equal(t, errTickAdaptivePacingTargetWriteLatencyMustBePositive,
+		v.SetTickAdaptivePacing(invalid).Validate())
+
+	invalid = pacing
+	invalid.MinSeriesBatchSize = 0
+	assert.Equal(t, errTickAdaptivePacingMinSeriesBatchSizeMustBePositive,
+		v.SetTickAdaptivePacing(invalid).Validate())
+
+	invalid = pacing
+	invalid.MaxSeriesBatchSize = 8
+	assert.Equal(t, errTickAdaptivePacingSeriesBatchSizeBoundsInvalid,
+		v.SetTickAdaptivePacing(invalid).Validate())
+
+	invalid = pacing
+	invalid.MinPerSeriesSleepDuration = 0
+	assert.Equal(t, errTickAdaptivePacingMinPerSeriesSleepDurationMustBePositive,
+		v.SetTickAdaptivePacing(invalid).Validate())
+
+	invalid = pacing
+	invalid.MaxPerSeriesSleepDuration = time.Nanosecond
+	assert.Equal(t, errTickAdaptivePacingPerSeriesSleepDurationBoundsInvalid,
+		v.SetTickAdaptivePacing(invalid).Validate())
+}
+
+func TestRuntimeOptionsCommitLogQueue(t *testing.T) {
+	v := NewOptions()
+	assert.Equal(t, CommitLogQueuePolicyDropCurrent, v.CommitLogQueuePolicy())
+	assert.Equal(t, 0, v.CommitLogQueueLimit())
+
+	v = v.SetCommitLogQueuePolicy(CommitLogQueuePolicyDropOldest).
+		SetCommitLogQueueLimit(1024)
+	assert.Equal(t, CommitLogQueuePolicyDropOldest, v.CommitLogQueuePolicy())
+	assert.Equal(t, 1024, v.CommitLogQueueLimit())
+	assert.NoError(t, v.Validate())
+
+	assert.Equal(t, errCommitLogQueueLimitIsNegative,
+		v.SetCommitLogQueueLimit(-1).Validate())
+	assert.Error(t, v.SetCommitLogQueuePolicy(CommitLogQueuePolicy(-1)).Validate())
+
+	for _, policy := range ValidCommitLogQueuePolicies() {
+		parsed, err := ParseCommitLogQueuePolicy(policy.String())
+		assert.NoError(t, err)
+		assert.Equal(t, policy, parsed)
+	}
+	_, err := ParseCommitLogQueuePolicy("drop-newest")
+	assert.Error(t, err)
+}
+
+func TestRuntimeOptionsIndexQueryLimits(t *testing.T) {
+	v := NewOptions()
+	assert.Equal(t, IndexQueryLimits{}, v.IndexQueryLimits())
+	assert.Nil(t, v.NamespaceIndexQueryLimits())
+
+	limits := map[string]IndexQueryLimits{
+		"metrics": {SeriesLimit: 10000, DocsLimit: 100000},
+	}
+	v = v.SetIndexQueryLimits(IndexQueryLimits{SeriesLimit: 1000}).
+		SetNamespaceIndexQueryLimits(limits)
+	assert.Equal(t, IndexQueryLimits{SeriesLimit: 1000}, v.IndexQueryLimits())
+	assert.Equal(t, limits, v.NamespaceIndexQueryLimits())
+	assert.NoError(t, v.Validate())
+
+	// Ensure the limits are copied.
+	limits["metrics"] = IndexQueryLimits{}
+	assert.Equal(t, IndexQueryLimits{SeriesLimit: 10000, DocsLimit: 100000},
+		v.NamespaceIndexQueryLimits()["metrics"])
+
+	assert.Equal(t, errIndexQueryLimitsIsNegative,
+		v.SetIndexQueryLimits(IndexQueryLimits{DocsLimit: -1}).Validate())
+	assert.Equal(t, errIndexQueryLimitsIsNegative,
+		v.SetNamespaceIndexQueryLimits(map[string]IndexQueryLimits{
+			"metrics": {SeriesLimit: -1},
+		}).Validate())
+}
+
+func TestRuntimeOptionsIndexCompactionScheduling(t *testing.T) {
+	v := NewOptions()
+	scheduling := v.IndexCompactionScheduling()
+	assert.Equal(t, 0.0, scheduling.InsertRateThreshold)
+	assert.NoError(t, v.Validate())
+
+	scheduling.InsertRateThreshold = 10000
+	v = v.SetIndexCompactionScheduling(scheduling)
+	assert.Equal(t, scheduling, v.IndexCompactionScheduling())
+	assert.NoError(t, v.Validate())
+
+	invalid := scheduling
+	invalid.InsertRateThreshold = -1
+	assert.Equal(t, errIndexCompactionInsertRateThresholdIsNegative,
+		v.SetIndexCompactionScheduling(invalid).Validate())
+
+	invalid = scheduling
+	invalid.InsertRateWindow = 0
+	assert.Equal(t, errIndexCompactionInsertRateWindowMustBePositive,
+		v.SetIndexCompactionScheduling(invalid).Validate())
+
+	invalid = scheduling
+	invalid.MaxDeferral = -time.Second
+	assert.Equal(t, errIndexCompactionMaxDeferralIsNegative,
+		v.SetIndexCompactionScheduling(invalid).Validate())
+}
+
+func TestRuntimeOptionsWriteShadowingRules(t *testing.T) {
+	v := NewOptions()
+	assert.Nil(t, v.WriteShadowingRules())
+
+	rules := []WriteShadowingRule{
+		{SourceNamespace: "a", TargetNamespace: "b", Fraction: 0.05, Enabled: true},
+	}
+	v = v.SetWriteShadowingRules(rules)
+	assert.Equal(t, rules, v.WriteShadowingRules())
+	assert.NoError(t, v.Validate())
+
+	// Ensure the rules are copied.
+	rules[0].Fraction = 1
+	assert.Equal(t, 0.05, v.WriteShadowingRules()[0].Fraction)
+
+	for _, test := range []struct {
+		rule     WriteShadowingRule
+		expected error
+	}{
+		{
+			rule:     WriteShadowingRule{TargetNamespace: "b"},
+			expected: errWriteShadowingRuleNamespaceMissing,
+		},
+		{
+			rule:     WriteShadowingRule{SourceNamespace: "a", TargetNamespace: "a"},
+			expected: errWriteShadowingRuleSameNamespace,
+		},
+		{
+			rule:     WriteShadowingRule{SourceNamespace: "a", TargetNamespace: "b", Fraction: 1.5},
+			expected: errWriteShadowingRuleFractionInvalid,
+		},
+		{
+			rule:     WriteShadowingRule{SourceNamespace: "a", TargetNamespace: "b", Fraction: -0.1},
+			expected: errWriteShadowingRuleFractionInvalid,
+		},
+	} {
+		assert.Equal(t, test.expected,
+			v.SetWriteShadowingRules([]WriteShadowingRule{test.rule}).Validate())
+	}
+}
+
+func TestRuntimeOptionsShardCircuitBreaker(t *testing.T) {
+	v := NewOptions()
+	breaker := v.ShardCircuitBreaker()
+	assert.Equal(t, defaultShardCircuitBreaker, breaker)
+	assert.False(t, breaker.Enabled)
+	assert.NoError(t, v.Validate())
+
+	breaker.Enabled = true
+	breaker.ErrorRateThreshold = 0.2
+	v = v.SetShardCircuitBreaker(breaker)
+	assert.Equal(t, breaker, v.ShardCircuitBreaker())
+	assert.NoError(t, v.Validate())
+
+	invalid := breaker
+	invalid.ErrorRateThreshold = 0
+	assert.Equal(t, errShardCircuitBreakerErrorRateThresholdInvalid,
+		v.SetShardCircuitBreaker(invalid).Validate())
+
+	invalid = breaker
+	invalid.ErrorRateThreshold = 1.5
+	assert.Equal(t, errShardCircuitBreakerErrorRateThresholdInvalid,
+		v.SetShardCircuitBreaker(invalid).Validate())
+
+	invalid = breaker
+	invalid.MinimumRequests = 0
+	assert.Equal(t, errShardCircuitBreakerMinimumRequestsMustBePositive,
+		v.SetShardCircuitBreaker(invalid).Validate())
+
+	invalid = breaker
+	invalid.Window = 0
+	assert.Equal(t, errShardCircuitBreakerWindowMustBePositive,
+		v.SetShardCircuitBreaker(invalid).Validate())
+
+	invalid = breaker
+	invalid.ProbeInterval = 0
+	assert.Equal(t, errShardCircuitBreakerProbeIntervalMustBePositive,
+		v.SetShardCircuitBreaker(invalid).Validate())
+}
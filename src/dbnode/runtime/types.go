@@ -77,6 +77,18 @@ type Options interface {
 	// time series being inserted.
 	WriteNewSeriesLimitPerShardPerSecond() int
 
+	// SetWriteNewSeriesBacklogPerShard sets the number of new series insertions
+	// exceeding the insert rate limit that are queued per shard and admitted at
+	// the limit rate, the oldest queued insertion is dropped when the backlog is
+	// full. Setting to zero rejects insertions that exceed the limit.
+	SetWriteNewSeriesBacklogPerShard(value int) Options
+
+	// WriteNewSeriesBacklogPerShard returns the number of new series insertions
+	// exceeding the insert rate limit that are queued per shard and admitted at
+	// the limit rate, the oldest queued insertion is dropped when the backlog is
+	// full. Setting to zero rejects insertions that exceed the limit.
+	WriteNewSeriesBacklogPerShard() int
+
 	// SetTickSeriesBatchSize sets the batch size to process series together
 	// during a tick before yielding and sleeping the per series duration
 	// multiplied by the batch size.
@@ -117,6 +129,18 @@ type Options interface {
 	// on a per series basis is short.
 	TickMinimumInterval() time.Duration
 
+	// SetTickAdaptivePacing sets the adaptive tick pacing, when enabled the
+	// tick series batch size and per series sleep duration are adjusted within
+	// the configured bounds by the observed write latency so that ticks back
+	// off while writes are contended and speed up while they are not.
+	SetTickAdaptivePacing(value TickAdaptivePacing) Options
+
+	// TickAdaptivePacing returns the adaptive tick pacing, when enabled the
+	// tick series batch size and per series sleep duration are adjusted within
+	// the configured bounds by the observed write latency so that ticks back
+	// off while writes are contended and speed up while they are not.
+	TickAdaptivePacing() TickAdaptivePacing
+
 	// SetMaxWiredBlocks sets the max blocks to keep wired; zero is used
 	// to specify no limit. Wired blocks that are in the buffer, I.E are
 	// being written to, cannot be unwired. Similarly, blocks which have
@@ -161,6 +185,16 @@ type Options interface {
 	// used when fetching data from peers for coordinated writes
 	ClientWriteConsistencyLevel() topology.ConsistencyLevel
 
+	// SetClientNamespaceWriteConsistencyLevels sets the client write consistency
+	// levels overriding the client write consistency level for writes to specific
+	// namespaces, keyed by namespace ID
+	SetClientNamespaceWriteConsistencyLevels(value map[string]topology.ConsistencyLevel) Options
+
+	// ClientNamespaceWriteConsistencyLevels returns the client write consistency
+	// levels overriding the client write consistency level for writes to specific
+	// namespaces, keyed by namespace ID, the returned map must not be mutated
+	ClientNamespaceWriteConsistencyLevels() map[string]topology.ConsistencyLevel
+
 	// SetFlushIndexBlockNumSegments sets the number of index block segments to
 	// divide into and flush separately to disk, the bigger the number the
 	// greater amount of segments that need to be searched independently but
@@ -172,6 +206,190 @@ type Options interface {
 	// greater amount of segments that need to be searched independently but
 	// a higher number reduces the memory pressure when flushing an index block.
 	FlushIndexBlockNumSegments() uint
+
+	// SetRepairEnabled sets whether repairs are enabled, when disabled the
+	// repairer skips each repair cycle until repairs are enabled again. This
+	// has no effect if repairs are not enabled in the storage options since
+	// the repairer is never started.
+	SetRepairEnabled(value bool) Options
+
+	// RepairEnabled returns whether repairs are enabled, when disabled the
+	// repairer skips each repair cycle until repairs are enabled again. This
+	// has no effect if repairs are not enabled in the storage options since
+	// the repairer is never started.
+	RepairEnabled() bool
+
+	// SetRepairThrottle sets the total time to sleep between the repairs of
+	// the shards of a namespace, the throttle is divided evenly between shards.
+	SetRepairThrottle(value time.Duration) Options
+
+	// RepairThrottle returns the total time to sleep between the repairs of
+	// the shards of a namespace, the throttle is divided evenly between shards.
+	RepairThrottle() time.Duration
+
+	// SetReadOnly sets whether the node is read only, when read only the node
+	// refuses writes from clients while it continues to serve reads, bootstrap
+	// and repair, allowing a node to join the topology as a standby.
+	SetReadOnly(value bool) Options
+
+	// ReadOnly returns whether the node is read only, when read only the node
+	// refuses writes from clients while it continues to serve reads, bootstrap
+	// and repair, allowing a node to join the topology as a standby.
+	ReadOnly() bool
+
+	// SetCommitLogQueuePolicy sets the policy applied to writes to the commit
+	// log when its queue is full, either rejecting the incoming write,
+	// evicting the oldest queued write or blocking until there is room.
+	SetCommitLogQueuePolicy(value CommitLogQueuePolicy) Options
+
+	// CommitLogQueuePolicy returns the policy applied to writes to the commit
+	// log when its queue is full, either rejecting the incoming write,
+	// evicting the oldest queued write or blocking until there is room.
+	CommitLogQueuePolicy() CommitLogQueuePolicy
+
+	// SetCommitLogQueueLimit sets the number of writes that can be queued
+	// in front of the commit log before the queue policy applies, setting to
+	// zero uses the commit log backlog queue size. The limit can not exceed
+	// the commit log backlog queue size, larger values are capped to it.
+	SetCommitLogQueueLimit(value int) Options
+
+	// CommitLogQueueLimit returns the number of writes that can be queued
+	// in front of the commit log before the queue policy applies, setting to
+	// zero uses the commit log backlog queue size. The limit can not exceed
+	// the commit log backlog queue size, larger values are capped to it.
+	CommitLogQueueLimit() int
+
+	// SetIndexQueryLimits sets the default limits applied to index queries
+	// which don't set their own, queries exceeding them return partial results.
+	SetIndexQueryLimits(value IndexQueryLimits) Options
+
+	// IndexQueryLimits returns the default limits applied to index queries
+	// which don't set their own, queries exceeding them return partial results.
+	IndexQueryLimits() IndexQueryLimits
+
+	// SetNamespaceIndexQueryLimits sets the per namespace index query limits,
+	// which take precedence over the default index query limits.
+	SetNamespaceIndexQueryLimits(value map[string]IndexQueryLimits) Options
+
+	// NamespaceIndexQueryLimits returns the per namespace index query limits,
+	// which take precedence over the default index query limits.
+	NamespaceIndexQueryLimits() map[string]IndexQueryLimits
+
+	// SetIndexCompactionScheduling sets the index compaction scheduling, which
+	// defers non-essential index compactions while the index insert rate is high.
+	SetIndexCompactionScheduling(value IndexCompactionScheduling) Options
+
+	// IndexCompactionScheduling returns the index compaction scheduling, which
+	// defers non-essential index compactions while the index insert rate is high.
+	IndexCompactionScheduling() IndexCompactionScheduling
+
+	// SetWriteShadowingRules sets the write shadowing rules, which mirror the
+	// writes of a fraction of the series of a namespace to another namespace.
+	SetWriteShadowingRules(value []WriteShadowingRule) Options
+
+	// WriteShadowingRules returns the write shadowing rules, which mirror the
+	// writes of a fraction of the series of a namespace to another namespace.
+	WriteShadowingRules() []WriteShadowingRule
+
+	// SetShardCircuitBreaker sets the shard circuit breaker, which fast fails
+	// the reads or writes of a shard while their recent error rate is high.
+	SetShardCircuitBreaker(value ShardCircuitBreaker) Options
+
+	// ShardCircuitBreaker returns the shard circuit breaker, which fast fails
+	// the reads or writes of a shard while their recent error rate is high.
+	ShardCircuitBreaker() ShardCircuitBreaker
+}
+
+// WriteShadowingRule mirrors the writes of a fraction of the series of a
+// source namespace to a target namespace. Series are selected by a hash of
+// their ID so the same series are consistently mirrored.
+//
+// NB: the mirrored writes are made synchronously once the write to the source
+// namespace succeeds, so a rule adds the latency of a write to its target
+// namespace to every write it selects. There are no rules by default and the
+// fraction of a rule should be raised gradually while watching write latency.
+type WriteShadowingRule struct {
+	// SourceNamespace is the namespace whose writes are mirrored.
+	SourceNamespace string `json:"sourceNamespace"`
+
+	// TargetNamespace is the namespace the writes are mirrored to.
+	TargetNamespace string `json:"targetNamespace"`
+
+	// Fraction is the fraction of the series of the source namespace that
+	// are mirrored, between zero and one.
+	Fraction float64 `json:"fraction"`
+
+	// Enabled is whether the rule is applied.
+	Enabled bool `json:"enabled"`
+}
+
+// ShardCircuitBreaker is the shard circuit breaker configuration, the reads
+// and writes of each shard are tracked by separate breakers.
+type ShardCircuitBreaker struct {
+	// Enabled is whether the shard circuit breakers are enabled.
+	Enabled bool
+
+	// ErrorRateThreshold is the fraction of failed requests within a window
+	// at or above which the breaker opens, between zero and one.
+	ErrorRateThreshold float64
+
+	// MinimumRequests is the number of requests a window needs before its
+	// error rate can open the breaker.
+	MinimumRequests int
+
+	// Window is the duration over which the error rate is tracked.
+	Window time.Duration
+
+	// ProbeInterval is how long an open breaker fast fails requests before
+	// letting a single request through to probe whether the shard recovered.
+	ProbeInterval time.Duration
+}
+
+// IndexQueryLimits are the limits applied to index queries, a zero limit
+// is not enforced.
+type IndexQueryLimits struct {
+	// SeriesLimit is the maximum number of distinct series returned.
+	SeriesLimit int
+
+	// DocsLimit is the maximum number of documents visited.
+	DocsLimit int
+}
+
+// TickAdaptivePacing is the adaptive tick pacing configuration.
+type TickAdaptivePacing struct {
+	// Enabled is whether the adaptive tick pacing is enabled, when disabled
+	// the tick series batch size and per series sleep duration are used as is.
+	Enabled bool
+
+	// TargetWriteLatency is the p99 write latency above which ticks back off.
+	TargetWriteLatency time.Duration
+
+	// MinSeriesBatchSize is the lower bound of the tick series batch size.
+	MinSeriesBatchSize int
+
+	// MaxSeriesBatchSize is the upper bound of the tick series batch size.
+	MaxSeriesBatchSize int
+
+	// MinPerSeriesSleepDuration is the lower bound of the tick per series
+	// sleep duration.
+	MinPerSeriesSleepDuration time.Duration
+
+	// MaxPerSeriesSleepDuration is the upper bound of the tick per series
+	// sleep duration.
+	MaxPerSeriesSleepDuration time.Duration
+}
+
+// IndexCompactionScheduling is the index compaction scheduling configuration.
+type IndexCompactionScheduling struct {
+	// InsertRateThreshold is the number of index inserts per second above which
+	// non-essential index compactions are deferred, zero disables deferral.
+	InsertRateThreshold float64
+
+	// InsertRateWindow is the window over which the index insert rate is measured.
+	InsertRateWindow time.Duration
+
+	// MaxDeferral is the longest an index compaction can be deferred for.
+	MaxDeferral time.Duration
 }
 
 // OptionsManager updates and supplies runtime options.
@@ -0,0 +1,56 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/m3db/m3/src/dbnode/storage/bootstrap"
+)
+
+const (
+	bootstrapProgressPath = "/debug/bootstrap"
+)
+
+var (
+	bootstrapProgressRegisterOnce sync.Once
+	bootstrapProgressCurrent      atomic.Value
+)
+
+// registerBootstrapProgress serves the progress of the current bootstrap as JSON
+// on the debug listener, replacing any previously registered progress.
+func registerBootstrapProgress(progress *bootstrap.Progress) {
+	bootstrapProgressCurrent.Store(progress)
+	bootstrapProgressRegisterOnce.Do(func() {
+		http.HandleFunc(bootstrapProgressPath, serveBootstrapProgress)
+	})
+}
+
+func serveBootstrapProgress(w http.ResponseWriter, _ *http.Request) {
+	progress := bootstrapProgressCurrent.Load().(*bootstrap.Progress)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(progress.Snapshot()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
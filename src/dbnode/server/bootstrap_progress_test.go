@@ -0,0 +1,60 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/storage/bootstrap"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBootstrapProgressHandler(t *testing.T) {
+	registerBootstrapProgress(bootstrap.NewProgress())
+	// Registering again replaces the progress rather than registering the path twice.
+	progress := bootstrap.NewProgress()
+	registerBootstrapProgress(progress)
+
+	progress.Start(2, time.Now())
+	progress.AddNamespaces(2)
+	progress.CompleteNamespace()
+	progress.AddShards(8)
+	progress.AddBytesRead(bootstrap.ProgressSourcePeers, 4096)
+
+	req := httptest.NewRequest(http.MethodGet, bootstrapProgressPath, nil)
+	recorder := httptest.NewRecorder()
+	http.DefaultServeMux.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var snapshot bootstrap.ProgressSnapshot
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &snapshot))
+	require.True(t, snapshot.Bootstrapping)
+	require.Equal(t, 2, snapshot.Retries)
+	require.Equal(t, 2, snapshot.NamespacesTotal)
+	require.Equal(t, 1, snapshot.NamespacesComplete)
+	require.Equal(t, 8, snapshot.ShardsTotal)
+	require.Equal(t, int64(4096), snapshot.BytesRead["peers"])
+}
@@ -0,0 +1,451 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3/src/cmd/services/m3dbnode/config"
+	m3dbruntime "github.com/m3db/m3/src/dbnode/runtime"
+	xconfig "github.com/m3db/m3x/config"
+	xlog "github.com/m3db/m3x/log"
+)
+
+var (
+	errReloadConfigNoDB = errors.New("reloaded config has no db configuration")
+)
+
+// configFieldApplyFn applies the value of a single configuration field to the
+// runtime options, leaving every other option untouched.
+type configFieldApplyFn func(
+	cfg config.DBConfiguration,
+	opts m3dbruntime.Options,
+) m3dbruntime.Options
+
+// reloadableConfigFields are the YAML paths of the configuration fields which are
+// applied to the runtime options when the configuration is reloaded, any other
+// changed fields only take effect once the process is restarted. Removing an
+// optional section reverts its fields to their default values.
+var reloadableConfigFields = map[string]configFieldApplyFn{
+	"fs.throughputLimitMbps": func(cfg config.DBConfiguration, opts m3dbruntime.Options) m3dbruntime.Options {
+		return opts.SetPersistRateLimitOptions(opts.PersistRateLimitOptions().
+			SetLimitMbps(cfg.Filesystem.ThroughputLimitMbps))
+	},
+	"fs.throughputCheckEvery": func(cfg config.DBConfiguration, opts m3dbruntime.Options) m3dbruntime.Options {
+		return opts.SetPersistRateLimitOptions(opts.PersistRateLimitOptions().
+			SetLimitCheckEvery(cfg.Filesystem.ThroughputCheckEvery))
+	},
+	"writeNewSeriesAsync": func(cfg config.DBConfiguration, opts m3dbruntime.Options) m3dbruntime.Options {
+		return opts.SetWriteNewSeriesAsync(cfg.WriteNewSeriesAsync)
+	},
+	"writeNewSeriesBackoffDuration": func(cfg config.DBConfiguration, opts m3dbruntime.Options) m3dbruntime.Options {
+		return opts.SetWriteNewSeriesBackoffDuration(cfg.WriteNewSeriesBackoffDuration)
+	},
+	"cache.series.lru.maxBlocks": func(cfg config.DBConfiguration, opts m3dbruntime.Options) m3dbruntime.Options {
+		if lruCfg := cfg.Cache.SeriesConfiguration().LRU; lruCfg != nil {
+			return opts.SetMaxWiredBlocks(lruCfg.MaxBlocks)
+		}
+		return opts.SetMaxWiredBlocks(m3dbruntime.NewOptions().MaxWiredBlocks())
+	},
+	"tick.seriesBatchSize": func(cfg config.DBConfiguration, opts m3dbruntime.Options) m3dbruntime.Options {
+		if cfg.Tick != nil {
+			return opts.SetTickSeriesBatchSize(cfg.Tick.SeriesBatchSize)
+		}
+		return opts.SetTickSeriesBatchSize(m3dbruntime.NewOptions().TickSeriesBatchSize())
+	},
+	"tick.perSeriesSleepDuration": func(cfg config.DBConfiguration, opts m3dbruntime.Options) m3dbruntime.Options {
+		if cfg.Tick != nil {
+			return opts.SetTickPerSeriesSleepDuration(cfg.Tick.PerSeriesSleepDuration)
+		}
+		return opts.SetTickPerSeriesSleepDuration(m3dbruntime.NewOptions().TickPerSeriesSleepDuration())
+	},
+	"tick.minimumInterval": func(cfg config.DBConfiguration, opts m3dbruntime.Options) m3dbruntime.Options {
+		if cfg.Tick != nil {
+			return opts.SetTickMinimumInterval(cfg.Tick.MinimumInterval)
+		}
+		return opts.SetTickMinimumInterval(m3dbruntime.NewOptions().TickMinimumInterval())
+	},
+	"tick.adaptivePacing.enabled": applyTickAdaptivePacingField(
+		func(pacing *m3dbruntime.TickAdaptivePacing, cfg m3dbruntime.TickAdaptivePacing) {
+			pacing.Enabled = cfg.Enabled
+		}),
+	"tick.adaptivePacing.targetWriteLatency": applyTickAdaptivePacingField(
+		func(pacing *m3dbruntime.TickAdaptivePacing, cfg m3dbruntime.TickAdaptivePacing) {
+			pacing.TargetWriteLatency = cfg.TargetWriteLatency
+		}),
+	"tick.adaptivePacing.minSeriesBatchSize": applyTickAdaptivePacingField(
+		func(pacing *m3dbruntime.TickAdaptivePacing, cfg m3dbruntime.TickAdaptivePacing) {
+			pacing.MinSeriesBatchSize = cfg.MinSeriesBatchSize
+		}),
+	"tick.adaptivePacing.maxSeriesBatchSize": applyTickAdaptivePacingField(
+		func(pacing *m3dbruntime.TickAdaptivePacing, cfg m3dbruntime.TickAdaptivePacing) {
+			pacing.MaxSeriesBatchSize = cfg.MaxSeriesBatchSize
+		}),
+	"tick.adaptivePacing.minPerSeriesSleepDuration": applyTickAdaptivePacingField(
+		func(pacing *m3dbruntime.TickAdaptivePacing, cfg m3dbruntime.TickAdaptivePacing) {
+			pacing.MinPerSeriesSleepDuration = cfg.MinPerSeriesSleepDuration
+		}),
+	"tick.adaptivePacing.maxPerSeriesSleepDuration": applyTickAdaptivePacingField(
+		func(pacing *m3dbruntime.TickAdaptivePacing, cfg m3dbruntime.TickAdaptivePacing) {
+			pacing.MaxPerSeriesSleepDuration = cfg.MaxPerSeriesSleepDuration
+		}),
+	"repair.enabled": func(cfg config.DBConfiguration, opts m3dbruntime.Options) m3dbruntime.Options {
+		return opts.SetRepairEnabled(cfg.Repair.Enabled)
+	},
+	"repair.throttle": func(cfg config.DBConfiguration, opts m3dbruntime.Options) m3dbruntime.Options {
+		return opts.SetRepairThrottle(cfg.Repair.Throttle)
+	},
+	"commitlog.queue.policy": func(cfg config.DBConfiguration, opts m3dbruntime.Options) m3dbruntime.Options {
+		if policy := cfg.CommitLog.Queue.Policy; policy != nil {
+			return opts.SetCommitLogQueuePolicy(*policy)
+		}
+		return opts.SetCommitLogQueuePolicy(m3dbruntime.NewOptions().CommitLogQueuePolicy())
+	},
+	"commitlog.queue.limit": func(cfg config.DBConfiguration, opts m3dbruntime.Options) m3dbruntime.Options {
+		return opts.SetCommitLogQueueLimit(cfg.CommitLog.Queue.Limit)
+	},
+	"index.queryLimits.seriesLimit": func(cfg config.DBConfiguration, opts m3dbruntime.Options) m3dbruntime.Options {
+		limits := opts.IndexQueryLimits()
+		limits.SeriesLimit = cfg.Index.QueryLimits.SeriesLimit
+		return opts.SetIndexQueryLimits(limits)
+	},
+	"index.queryLimits.docsLimit": func(cfg config.DBConfiguration, opts m3dbruntime.Options) m3dbruntime.Options {
+		limits := opts.IndexQueryLimits()
+		limits.DocsLimit = cfg.Index.QueryLimits.DocsLimit
+		return opts.SetIndexQueryLimits(limits)
+	},
+	"index.namespaceQueryLimits": func(cfg config.DBConfiguration, opts m3dbruntime.Options) m3dbruntime.Options {
+		namespaceQueryLimits := make(map[string]m3dbruntime.IndexQueryLimits,
+			len(cfg.Index.NamespaceQueryLimits))
+		for namespace, limitsCfg := range cfg.Index.NamespaceQueryLimits {
+			namespaceQueryLimits[namespace] = indexQueryLimitsFromConfig(limitsCfg)
+		}
+		return opts.SetNamespaceIndexQueryLimits(namespaceQueryLimits)
+	},
+	"index.compactionScheduling.insertRateThreshold": applyIndexCompactionSchedulingField(
+		func(scheduling *m3dbruntime.IndexCompactionScheduling, cfg m3dbruntime.IndexCompactionScheduling) {
+			scheduling.InsertRateThreshold = cfg.InsertRateThreshold
+		}),
+	"index.compactionScheduling.insertRateWindow": applyIndexCompactionSchedulingField(
+		func(scheduling *m3dbruntime.IndexCompactionScheduling, cfg m3dbruntime.IndexCompactionScheduling) {
+			scheduling.InsertRateWindow = cfg.InsertRateWindow
+		}),
+	"index.compactionScheduling.maxDeferral": applyIndexCompactionSchedulingField(
+		func(scheduling *m3dbruntime.IndexCompactionScheduling, cfg m3dbruntime.IndexCompactionScheduling) {
+			scheduling.MaxDeferral = cfg.MaxDeferral
+		}),
+	"shardCircuitBreaker.enabled": applyShardCircuitBreakerField(
+		func(breaker *m3dbruntime.ShardCircuitBreaker, cfg m3dbruntime.ShardCircuitBreaker) {
+			breaker.Enabled = cfg.Enabled
+		}),
+	"shardCircuitBreaker.errorRateThreshold": applyShardCircuitBreakerField(
+		func(breaker *m3dbruntime.ShardCircuitBreaker, cfg m3dbruntime.ShardCircuitBreaker) {
+			breaker.ErrorRateThreshold = cfg.ErrorRateThreshold
+		}),
+	"shardCircuitBreaker.minimumRequests": applyShardCircuitBreakerField(
+		func(breaker *m3dbruntime.ShardCircuitBreaker, cfg m3dbruntime.ShardCircuitBreaker) {
+			breaker.MinimumRequests = cfg.MinimumRequests
+		}),
+	"shardCircuitBreaker.window": applyShardCircuitBreakerField(
+		func(breaker *m3dbruntime.ShardCircuitBreaker, cfg m3dbruntime.ShardCircuitBreaker) {
+			breaker.Window = cfg.Window
+		}),
+	"shardCircuitBreaker.probeInterval": applyShardCircuitBreakerField(
+		func(breaker *m3dbruntime.ShardCircuitBreaker, cfg m3dbruntime.ShardCircuitBreaker) {
+			breaker.ProbeInterval = cfg.ProbeInterval
+		}),
+}
+
+// applyTickAdaptivePacingField returns a configFieldApplyFn which copies a single
+// field of the configured adaptive tick pacing onto the current one.
+func applyTickAdaptivePacingField(
+	fn func(pacing *m3dbruntime.TickAdaptivePacing, cfg m3dbruntime.TickAdaptivePacing),
+) configFieldApplyFn {
+	return func(cfg config.DBConfiguration, opts m3dbruntime.Options) m3dbruntime.Options {
+		var pacingCfg config.TickAdaptivePacingConfiguration
+		if cfg.Tick != nil && cfg.Tick.AdaptivePacing != nil {
+			pacingCfg = *cfg.Tick.AdaptivePacing
+		}
+		pacing := opts.TickAdaptivePacing()
+		fn(&pacing, tickAdaptivePacingFromConfig(pacingCfg))
+		return opts.SetTickAdaptivePacing(pacing)
+	}
+}
+
+// applyIndexCompactionSchedulingField returns a configFieldApplyFn which copies a
+// single field of the configured index compaction scheduling onto the current one.
+func applyIndexCompactionSchedulingField(
+	fn func(scheduling *m3dbruntime.IndexCompactionScheduling, cfg m3dbruntime.IndexCompactionScheduling),
+) configFieldApplyFn {
+	return func(cfg config.DBConfiguration, opts m3dbruntime.Options) m3dbruntime.Options {
+		var schedulingCfg config.IndexCompactionSchedulingConfiguration
+		if cfg.Index.CompactionScheduling != nil {
+			schedulingCfg = *cfg.Index.CompactionScheduling
+		}
+		scheduling := opts.IndexCompactionScheduling()
+		fn(&scheduling, indexCompactionSchedulingFromConfig(schedulingCfg))
+		return opts.SetIndexCompactionScheduling(scheduling)
+	}
+}
+
+// applyShardCircuitBreakerField returns a configFieldApplyFn which copies a single
+// field of the configured shard circuit breaker onto the current one.
+func applyShardCircuitBreakerField(
+	fn func(breaker *m3dbruntime.ShardCircuitBreaker, cfg m3dbruntime.ShardCircuitBreaker),
+) configFieldApplyFn {
+	return func(cfg config.DBConfiguration, opts m3dbruntime.Options) m3dbruntime.Options {
+		var breakerCfg config.ShardCircuitBreakerConfiguration
+		if cfg.ShardCircuitBreaker != nil {
+			breakerCfg = *cfg.ShardCircuitBreaker
+		}
+		breaker := opts.ShardCircuitBreaker()
+		fn(&breaker, shardCircuitBreakerFromConfig(breakerCfg))
+		return opts.SetShardCircuitBreaker(breaker)
+	}
+}
+
+// runtimeOptionsFromConfig returns the runtime options with every reloadable field
+// of the configuration applied.
+func runtimeOptionsFromConfig(
+	cfg config.DBConfiguration,
+	opts m3dbruntime.Options,
+) m3dbruntime.Options {
+	opts = opts.SetPersistRateLimitOptions(opts.PersistRateLimitOptions().
+		SetLimitEnabled(true))
+	for _, applyFn := range reloadableConfigFields {
+		opts = applyFn(cfg, opts)
+	}
+	return opts
+}
+
+// indexQueryLimitsFromConfig returns the index query limits of the configuration.
+func indexQueryLimitsFromConfig(
+	cfg config.IndexQueryLimitsConfiguration,
+) m3dbruntime.IndexQueryLimits {
+	return m3dbruntime.IndexQueryLimits{
+		SeriesLimit: cfg.SeriesLimit,
+		DocsLimit:   cfg.DocsLimit,
+	}
+}
+
+// indexCompactionSchedulingFromConfig returns the index compaction scheduling of
+// the configuration, using the default values for the unset fields.
+func indexCompactionSchedulingFromConfig(
+	cfg config.IndexCompactionSchedulingConfiguration,
+) m3dbruntime.IndexCompactionScheduling {
+	scheduling := m3dbruntime.NewOptions().IndexCompactionScheduling()
+	scheduling.InsertRateThreshold = cfg.InsertRateThreshold
+	if cfg.InsertRateWindow > 0 {
+		scheduling.InsertRateWindow = cfg.InsertRateWindow
+	}
+	if cfg.MaxDeferral > 0 {
+		scheduling.MaxDeferral = cfg.MaxDeferral
+	}
+	return scheduling
+}
+
+// tickAdaptivePacingFromConfig returns the adaptive tick pacing of the
+// configuration, using the default values for the unset fields.
+func tickAdaptivePacingFromConfig(
+	cfg config.TickAdaptivePacingConfiguration,
+) m3dbruntime.TickAdaptivePacing {
+	pacing := m3dbruntime.NewOptions().TickAdaptivePacing()
+	pacing.Enabled = cfg.Enabled
+	if cfg.TargetWriteLatency > 0 {
+		pacing.TargetWriteLatency = cfg.TargetWriteLatency
+	}
+	if cfg.MinSeriesBatchSize > 0 {
+		pacing.MinSeriesBatchSize = cfg.MinSeriesBatchSize
+	}
+	if cfg.MaxSeriesBatchSize > 0 {
+		pacing.MaxSeriesBatchSize = cfg.MaxSeriesBatchSize
+	}
+	if cfg.MinPerSeriesSleepDuration > 0 {
+		pacing.MinPerSeriesSleepDuration = cfg.MinPerSeriesSleepDuration
+	}
+	if cfg.MaxPerSeriesSleepDuration > 0 {
+		pacing.MaxPerSeriesSleepDuration = cfg.MaxPerSeriesSleepDuration
+	}
+	return pacing
+}
+
+// shardCircuitBreakerFromConfig returns the shard circuit breaker of the
+// configuration, using the default values for the unset fields.
+func shardCircuitBreakerFromConfig(
+	cfg config.ShardCircuitBreakerConfiguration,
+) m3dbruntime.ShardCircuitBreaker {
+	breaker := m3dbruntime.NewOptions().ShardCircuitBreaker()
+	breaker.Enabled = cfg.Enabled
+	if cfg.ErrorRateThreshold > 0 {
+		breaker.ErrorRateThreshold = cfg.ErrorRateThreshold
+	}
+	if cfg.MinimumRequests > 0 {
+		breaker.MinimumRequests = cfg.MinimumRequests
+	}
+	if cfg.Window > 0 {
+		breaker.Window = cfg.Window
+	}
+	if cfg.ProbeInterval > 0 {
+		breaker.ProbeInterval = cfg.ProbeInterval
+	}
+	return breaker
+}
+
+type loadConfigFn func() (config.DBConfiguration, error)
+
+func loadConfigFileFn(file string) loadConfigFn {
+	return func() (config.DBConfiguration, error) {
+		var rootCfg config.Configuration
+		if err := xconfig.LoadFile(&rootCfg, file, xconfig.Options{}); err != nil {
+			return config.DBConfiguration{}, err
+		}
+		if rootCfg.DB == nil {
+			return config.DBConfiguration{}, errReloadConfigNoDB
+		}
+		return *rootCfg.DB, nil
+	}
+}
+
+// configReloader reloads the configuration and applies the changes to its
+// reloadable fields to the runtime options.
+type configReloader struct {
+	cfg            config.DBConfiguration
+	loadFn         loadConfigFn
+	runtimeOptsMgr m3dbruntime.OptionsManager
+	logger         xlog.Logger
+}
+
+func newConfigReloader(
+	cfg config.DBConfiguration,
+	loadFn loadConfigFn,
+	runtimeOptsMgr m3dbruntime.OptionsManager,
+	logger xlog.Logger,
+) *configReloader {
+	return &configReloader{
+		cfg:            cfg,
+		loadFn:         loadFn,
+		runtimeOptsMgr: runtimeOptsMgr,
+		logger:         logger,
+	}
+}
+
+// Reload loads the configuration and applies its reloadable fields, returning
+// the changed fields that were applied. The runtime options are left untouched
+// if the configuration cannot be loaded or results in invalid runtime options.
+func (r *configReloader) Reload() ([]string, error) {
+	cfg, err := r.loadFn()
+	if err != nil {
+		return nil, fmt.Errorf("could not load config: %v", err)
+	}
+
+	var applied, ignored []string
+	for _, field := range configChanges(r.cfg, cfg) {
+		if _, ok := reloadableConfigFields[field]; ok {
+			applied = append(applied, field)
+		} else {
+			ignored = append(ignored, field)
+		}
+	}
+
+	if len(applied) > 0 {
+		// NB: only the changed fields are applied on top of the current options so
+		// that the values set since, such as by the KV watches, are left untouched.
+		err := updateRuntimeOptions(r.runtimeOptsMgr,
+			func(opts m3dbruntime.Options) m3dbruntime.Options {
+				for _, field := range applied {
+					opts = reloadableConfigFields[field](cfg, opts)
+				}
+				return opts
+			})
+		if err != nil {
+			return nil, fmt.Errorf("could not update runtime options: %v", err)
+		}
+	}
+
+	for _, field := range ignored {
+		r.logger.Warnf("ignoring changed config field %s, it is not reloadable", field)
+	}
+	r.cfg = cfg
+	return applied, nil
+}
+
+// configChanges returns the sorted YAML paths of the fields which differ between
+// two configurations.
+func configChanges(prev, next config.DBConfiguration) []string {
+	var changes []string
+	appendValueChanges("", reflect.ValueOf(prev), reflect.ValueOf(next), &changes)
+	sort.Strings(changes)
+	return changes
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func appendValueChanges(path string, prev, next reflect.Value, changes *[]string) {
+	// Compare pointers to structs by their fields, treating nil as the zero value
+	// so that setting a section reports which of its fields were set.
+	if prev.Kind() == reflect.Ptr && prev.Type().Elem().Kind() == reflect.Struct {
+		if prev.IsNil() && next.IsNil() {
+			return
+		}
+		zero := reflect.Zero(prev.Type().Elem())
+		prevElem, nextElem := zero, zero
+		if !prev.IsNil() {
+			prevElem = prev.Elem()
+		}
+		if !next.IsNil() {
+			nextElem = next.Elem()
+		}
+		appendValueChanges(path, prevElem, nextElem, changes)
+		return
+	}
+
+	if prev.Kind() != reflect.Struct || prev.Type() == durationType {
+		if !reflect.DeepEqual(prev.Interface(), next.Interface()) {
+			*changes = append(*changes, path)
+		}
+		return
+	}
+
+	t := prev.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			// Unexported fields can't be set by the configuration.
+			continue
+		}
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if path != "" {
+			name = path + "." + name
+		}
+		appendValueChanges(name, prev.Field(i), next.Field(i), changes)
+	}
+}
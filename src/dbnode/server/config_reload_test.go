@@ -0,0 +1,372 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cmd/services/m3dbnode/config"
+	m3dbruntime "github.com/m3db/m3/src/dbnode/runtime"
+	xlog "github.com/m3db/m3x/log"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestReloadConfig() config.DBConfiguration {
+	return config.DBConfiguration{
+		ListenAddress: "0.0.0.0:9000",
+		Filesystem: config.FilesystemConfiguration{
+			ThroughputLimitMbps:  100,
+			ThroughputCheckEvery: 128,
+		},
+		WriteNewSeriesBackoffDuration: time.Millisecond,
+	}
+}
+
+func newTestConfigReloader(
+	t *testing.T,
+	cfg config.DBConfiguration,
+	loadFn loadConfigFn,
+) (*configReloader, m3dbruntime.OptionsManager) {
+	runtimeOptsMgr := m3dbruntime.NewOptionsManager()
+	require.NoError(t, runtimeOptsMgr.Update(
+		runtimeOptionsFromConfig(cfg, m3dbruntime.NewOptions())))
+	return newConfigReloader(cfg, loadFn, runtimeOptsMgr, xlog.NullLogger), runtimeOptsMgr
+}
+
+func TestConfigChanges(t *testing.T) {
+	prev := newTestReloadConfig()
+	next := newTestReloadConfig()
+	require.Empty(t, configChanges(prev, next))
+
+	next.ListenAddress = "0.0.0.0:9100"
+	next.Filesystem.ThroughputLimitMbps = 200
+	next.Tick = &config.TickConfiguration{MinimumInterval: time.Minute}
+	require.Equal(t, []string{
+		"fs.throughputLimitMbps",
+		"listenAddress",
+		"tick.minimumInterval",
+	}, configChanges(prev, next))
+}
+
+func TestConfigReloaderAppliesReloadableFields(t *testing.T) {
+	prev := newTestReloadConfig()
+	next := newTestReloadConfig()
+	next.ListenAddress = "0.0.0.0:9100"
+	next.Filesystem.ThroughputLimitMbps = 200
+	next.WriteNewSeriesAsync = true
+	next.Tick = &config.TickConfiguration{
+		SeriesBatchSize:        256,
+		PerSeriesSleepDuration: time.Microsecond,
+		MinimumInterval:        time.Minute,
+	}
+
+	reloader, runtimeOptsMgr := newTestConfigReloader(t, prev,
+		func() (config.DBConfiguration, error) { return next, nil })
+
+	applied, err := reloader.Reload()
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"fs.throughputLimitMbps",
+		"tick.minimumInterval",
+		"tick.perSeriesSleepDuration",
+		"tick.seriesBatchSize",
+		"writeNewSeriesAsync",
+	}, applied)
+
+	opts := runtimeOptsMgr.Get()
+	require.Equal(t, 200.0, opts.PersistRateLimitOptions().LimitMbps())
+	require.Equal(t, 128, opts.PersistRateLimitOptions().LimitCheckEvery())
+	require.True(t, opts.WriteNewSeriesAsync())
+	require.Equal(t, 256, opts.TickSeriesBatchSize())
+	require.Equal(t, time.Microsecond, opts.TickPerSeriesSleepDuration())
+	require.Equal(t, time.Minute, opts.TickMinimumInterval())
+
+	// Reloading the same configuration again applies nothing.
+	applied, err = reloader.Reload()
+	require.NoError(t, err)
+	require.Empty(t, applied)
+}
+
+func TestConfigReloaderAppliesTickAdaptivePacing(t *testing.T) {
+	prev := newTestReloadConfig()
+	next := newTestReloadConfig()
+	next.Tick = &config.TickConfiguration{
+		SeriesBatchSize:        256,
+		PerSeriesSleepDuration: time.Microsecond,
+		AdaptivePacing: &config.TickAdaptivePacingConfiguration{
+			Enabled:            true,
+			TargetWriteLatency: 5 * time.Millisecond,
+			MaxSeriesBatchSize: 1024,
+		},
+	}
+
+	reloader, runtimeOptsMgr := newTestConfigReloader(t, prev,
+		func() (config.DBConfiguration, error) { return next, nil })
+
+	applied, err := reloader.Reload()
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"tick.adaptivePacing.enabled",
+		"tick.adaptivePacing.maxSeriesBatchSize",
+		"tick.adaptivePacing.targetWriteLatency",
+		"tick.perSeriesSleepDuration",
+		"tick.seriesBatchSize",
+	}, applied)
+
+	defaults := m3dbruntime.NewOptions().TickAdaptivePacing()
+	pacing := runtimeOptsMgr.Get().TickAdaptivePacing()
+	require.True(t, pacing.Enabled)
+	require.Equal(t, 5*time.Millisecond, pacing.TargetWriteLatency)
+	require.Equal(t, defaults.MinSeriesBatchSize, pacing.MinSeriesBatchSize)
+	require.Equal(t, 1024, pacing.MaxSeriesBatchSize)
+	require.Equal(t, defaults.MinPerSeriesSleepDuration, pacing.MinPerSeriesSleepDuration)
+	require.Equal(t, defaults.MaxPerSeriesSleepDuration, pacing.MaxPerSeriesSleepDuration)
+}
+
+func TestConfigReloaderAppliesCommitLogQueue(t *testing.T) {
+	prev := newTestReloadConfig()
+	next := newTestReloadConfig()
+	policy := m3dbruntime.CommitLogQueuePolicyDropOldest
+	next.CommitLog.Queue.Policy = &policy
+	next.CommitLog.Queue.Limit = 4096
+
+	reloader, runtimeOptsMgr := newTestConfigReloader(t, prev,
+		func() (config.DBConfiguration, error) { return next, nil })
+
+	applied, err := reloader.Reload()
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"commitlog.queue.limit",
+		"commitlog.queue.policy",
+	}, applied)
+
+	opts := runtimeOptsMgr.Get()
+	require.Equal(t, m3dbruntime.CommitLogQueuePolicyDropOldest, opts.CommitLogQueuePolicy())
+	require.Equal(t, 4096, opts.CommitLogQueueLimit())
+}
+
+func TestConfigReloaderAppliesIndexQueryLimits(t *testing.T) {
+	prev := newTestReloadConfig()
+	next := newTestReloadConfig()
+	next.Index.QueryLimits.SeriesLimit = 10000
+	next.Index.NamespaceQueryLimits = map[string]config.IndexQueryLimitsConfiguration{
+		"metrics": {SeriesLimit: 1000, DocsLimit: 100000},
+	}
+
+	reloader, runtimeOptsMgr := newTestConfigReloader(t, prev,
+		func() (config.DBConfiguration, error) { return next, nil })
+
+	applied, err := reloader.Reload()
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"index.namespaceQueryLimits",
+		"index.queryLimits.seriesLimit",
+	}, applied)
+
+	opts := runtimeOptsMgr.Get()
+	require.Equal(t, m3dbruntime.IndexQueryLimits{SeriesLimit: 10000}, opts.IndexQueryLimits())
+	require.Equal(t, map[string]m3dbruntime.IndexQueryLimits{
+		"metrics": {SeriesLimit: 1000, DocsLimit: 100000},
+	}, opts.NamespaceIndexQueryLimits())
+}
+
+func TestConfigReloaderAppliesIndexCompactionScheduling(t *testing.T) {
+	prev := newTestReloadConfig()
+	next := newTestReloadConfig()
+	next.Index.CompactionScheduling = &config.IndexCompactionSchedulingConfiguration{
+		InsertRateThreshold: 50000,
+		MaxDeferral:         5 * time.Minute,
+	}
+
+	reloader, runtimeOptsMgr := newTestConfigReloader(t, prev,
+		func() (config.DBConfiguration, error) { return next, nil })
+
+	applied, err := reloader.Reload()
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"index.compactionScheduling.insertRateThreshold",
+		"index.compactionScheduling.maxDeferral",
+	}, applied)
+
+	defaults := m3dbruntime.NewOptions().IndexCompactionScheduling()
+	scheduling := runtimeOptsMgr.Get().IndexCompactionScheduling()
+	require.Equal(t, 50000.0, scheduling.InsertRateThreshold)
+	require.Equal(t, defaults.InsertRateWindow, scheduling.InsertRateWindow)
+	require.Equal(t, 5*time.Minute, scheduling.MaxDeferral)
+}
+
+func TestConfigReloaderAppliesShardCircuitBreaker(t *testing.T) {
+	prev := newTestReloadConfig()
+	next := newTestReloadConfig()
+	next.ShardCircuitBreaker = &config.ShardCircuitBreakerConfiguration{
+		Enabled:            true,
+		ErrorRateThreshold: 0.25,
+		ProbeInterval:      time.Second,
+	}
+
+	reloader, runtimeOptsMgr := newTestConfigReloader(t, prev,
+		func() (config.DBConfiguration, error) { return next, nil })
+
+	applied, err := reloader.Reload()
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"shardCircuitBreaker.enabled",
+		"shardCircuitBreaker.errorRateThreshold",
+		"shardCircuitBreaker.probeInterval",
+	}, applied)
+
+	defaults := m3dbruntime.NewOptions().ShardCircuitBreaker()
+	breaker := runtimeOptsMgr.Get().ShardCircuitBreaker()
+	require.True(t, breaker.Enabled)
+	require.Equal(t, 0.25, breaker.ErrorRateThreshold)
+	require.Equal(t, defaults.MinimumRequests, breaker.MinimumRequests)
+	require.Equal(t, defaults.Window, breaker.Window)
+	require.Equal(t, time.Second, breaker.ProbeInterval)
+}
+
+func TestConfigReloaderOnlyAppliesChangedFields(t *testing.T) {
+	prev := newTestReloadConfig()
+	next := newTestReloadConfig()
+	next.WriteNewSeriesAsync = true
+	next.ShardCircuitBreaker = &config.ShardCircuitBreakerConfiguration{
+		MinimumRequests: 100,
+	}
+
+	reloader, runtimeOptsMgr := newTestConfigReloader(t, prev,
+		func() (config.DBConfiguration, error) { return next, nil })
+	// As if these options had been set through KV since the config was loaded.
+	breaker := runtimeOptsMgr.Get().ShardCircuitBreaker()
+	breaker.Enabled = true
+	require.NoError(t, runtimeOptsMgr.Update(runtimeOptsMgr.Get().
+		SetRepairEnabled(false).
+		SetTickMinimumInterval(time.Minute).
+		SetCommitLogQueueLimit(4096).
+		SetShardCircuitBreaker(breaker)))
+
+	applied, err := reloader.Reload()
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"shardCircuitBreaker.minimumRequests",
+		"writeNewSeriesAsync",
+	}, applied)
+
+	opts := runtimeOptsMgr.Get()
+	require.True(t, opts.WriteNewSeriesAsync())
+	require.Equal(t, 100, opts.ShardCircuitBreaker().MinimumRequests)
+	require.True(t, opts.ShardCircuitBreaker().Enabled)
+	require.False(t, opts.RepairEnabled())
+	require.Equal(t, time.Minute, opts.TickMinimumInterval())
+	require.Equal(t, 4096, opts.CommitLogQueueLimit())
+}
+
+func TestConfigReloaderLeavesReadOnly(t *testing.T) {
+	prev := newTestReloadConfig()
+	next := newTestReloadConfig()
+	next.ReadOnly = false
+	next.WriteNewSeriesAsync = true
+
+	reloader, runtimeOptsMgr := newTestConfigReloader(t, prev,
+		func() (config.DBConfiguration, error) { return next, nil })
+	// As if the node had been set read only through KV.
+	require.NoError(t, runtimeOptsMgr.Update(runtimeOptsMgr.Get().SetReadOnly(true)))
+
+	applied, err := reloader.Reload()
+	require.NoError(t, err)
+	require.Equal(t, []string{"writeNewSeriesAsync"}, applied)
+	require.True(t, runtimeOptsMgr.Get().ReadOnly())
+	require.True(t, runtimeOptsMgr.Get().WriteNewSeriesAsync())
+
+	// Changing the configured value is ignored too.
+	next.ReadOnly = true
+	applied, err = reloader.Reload()
+	require.NoError(t, err)
+	require.Empty(t, applied)
+}
+
+func TestConfigReloaderOnlyNonReloadableFieldsChanged(t *testing.T) {
+	prev := newTestReloadConfig()
+	next := newTestReloadConfig()
+	next.ListenAddress = "0.0.0.0:9100"
+
+	reloader, runtimeOptsMgr := newTestConfigReloader(t, prev,
+		func() (config.DBConfiguration, error) { return next, nil })
+	opts := runtimeOptsMgr.Get()
+
+	applied, err := reloader.Reload()
+	require.NoError(t, err)
+	require.Empty(t, applied)
+	require.True(t, opts == runtimeOptsMgr.Get())
+}
+
+func TestConfigReloaderLoadErrorLeavesOptions(t *testing.T) {
+	reloader, runtimeOptsMgr := newTestConfigReloader(t, newTestReloadConfig(),
+		func() (config.DBConfiguration, error) {
+			return config.DBConfiguration{}, errors.New("bad config")
+		})
+	opts := runtimeOptsMgr.Get()
+
+	_, err := reloader.Reload()
+	require.Error(t, err)
+	require.True(t, opts == runtimeOptsMgr.Get())
+}
+
+func TestConfigReloaderInvalidOptionsLeavesOptions(t *testing.T) {
+	prev := newTestReloadConfig()
+	next := newTestReloadConfig()
+	next.Filesystem.ThroughputLimitMbps = 200
+	next.Tick = &config.TickConfiguration{
+		SeriesBatchSize:        -1,
+		PerSeriesSleepDuration: time.Microsecond,
+	}
+
+	reloader, runtimeOptsMgr := newTestConfigReloader(t, prev,
+		func() (config.DBConfiguration, error) { return next, nil })
+	opts := runtimeOptsMgr.Get()
+
+	_, err := reloader.Reload()
+	require.Error(t, err)
+	require.True(t, opts == runtimeOptsMgr.Get())
+
+	// The configuration is diffed against the last applied one once it is fixed.
+	next.Tick.SeriesBatchSize = 256
+	applied, err := reloader.Reload()
+	require.NoError(t, err)
+	require.Contains(t, applied, "fs.throughputLimitMbps")
+	require.Equal(t, 200.0, runtimeOptsMgr.Get().PersistRateLimitOptions().LimitMbps())
+}
+
+func TestLoadConfigFileMalformed(t *testing.T) {
+	fd, err := ioutil.TempFile("", "config.yaml")
+	require.NoError(t, err)
+	defer os.Remove(fd.Name())
+
+	_, err = fd.Write([]byte("db: [not: valid"))
+	require.NoError(t, err)
+	require.NoError(t, fd.Close())
+
+	_, err = loadConfigFileFn(fd.Name())()
+	require.Error(t, err)
+}
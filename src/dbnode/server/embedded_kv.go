@@ -0,0 +1,456 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/environment"
+	xlog "github.com/m3db/m3x/log"
+
+	"github.com/coreos/etcd/etcdserver"
+	pb "github.com/coreos/etcd/etcdserver/etcdserverpb"
+	"github.com/coreos/etcd/mvcc"
+	"github.com/uber-go/tally"
+)
+
+const (
+	embeddedKVCompactTimeout = time.Minute
+
+	embeddedKVHealthPath          = "/debug/embedded-kv/health"
+	embeddedKVHealthReportEvery   = 10 * time.Second
+	embeddedKVAlarmsTimeout       = time.Second
+	defaultEmbeddedKVReadyTimeout = time.Minute
+
+	embeddedKVStatusNotApplicable = "embedded etcd: not applicable"
+	embeddedKVStatusNotReady      = "embedded etcd: not ready"
+	embeddedKVStatusNoLeader      = "embedded etcd: no leader"
+	embeddedKVStatusAlarms        = "embedded etcd: alarms raised"
+	embeddedKVStatusHealthy       = "embedded etcd: healthy"
+)
+
+var (
+	embeddedKVHealthRegisterOnce sync.Once
+	embeddedKVHealthCurrent      atomic.Value
+)
+
+// embeddedKVMaintainer periodically compacts and defragments an embedded etcd server
+// so that its keyspace does not grow without bound.
+type embeddedKVMaintainer struct {
+	sync.Mutex
+
+	server               *etcdserver.EtcdServer
+	compactionRetention  time.Duration
+	defragInterval       time.Duration
+	maintainOnlyAsLeader bool
+	logger               xlog.Logger
+	metrics              embeddedKVMaintainerMetrics
+
+	running bool
+	closeCh chan struct{}
+	doneWg  sync.WaitGroup
+}
+
+type embeddedKVMaintainerMetrics struct {
+	compactSuccess   tally.Counter
+	compactErrors    tally.Counter
+	compactSkipped   tally.Counter
+	compactLatency   tally.Timer
+	compactRevision  tally.Gauge
+	defragSuccess    tally.Counter
+	defragErrors     tally.Counter
+	defragSkipped    tally.Counter
+	defragLatency    tally.Timer
+	backendSizeBytes tally.Gauge
+}
+
+func newEmbeddedKVMaintainerMetrics(scope tally.Scope) embeddedKVMaintainerMetrics {
+	compactScope := scope.SubScope("compact")
+	defragScope := scope.SubScope("defrag")
+	return embeddedKVMaintainerMetrics{
+		compactSuccess:   compactScope.Counter("success"),
+		compactErrors:    compactScope.Counter("errors"),
+		compactSkipped:   compactScope.Counter("skipped-not-leader"),
+		compactLatency:   compactScope.Timer("latency"),
+		compactRevision:  compactScope.Gauge("revision"),
+		defragSuccess:    defragScope.Counter("success"),
+		defragErrors:     defragScope.Counter("errors"),
+		defragSkipped:    defragScope.Counter("skipped-not-leader"),
+		defragLatency:    defragScope.Timer("latency"),
+		backendSizeBytes: scope.Gauge("backend-size-bytes"),
+	}
+}
+
+func newEmbeddedKVMaintainer(
+	server *etcdserver.EtcdServer,
+	cfg environment.SeedNodesConfig,
+	logger xlog.Logger,
+	scope tally.Scope,
+) *embeddedKVMaintainer {
+	return &embeddedKVMaintainer{
+		server:               server,
+		compactionRetention:  cfg.AutoCompactionRetention,
+		defragInterval:       cfg.DefragInterval,
+		maintainOnlyAsLeader: cfg.MaintainOnlyAsLeader,
+		logger:               logger,
+		metrics:              newEmbeddedKVMaintainerMetrics(scope),
+	}
+}
+
+// Start begins compacting and defragmenting the embedded etcd server in the
+// background, doing nothing for either if its interval is not positive.
+func (m *embeddedKVMaintainer) Start() {
+	m.Lock()
+	defer m.Unlock()
+	if m.running {
+		return
+	}
+	m.running = true
+	m.closeCh = make(chan struct{})
+
+	if m.compactionRetention > 0 {
+		m.doneWg.Add(1)
+		go m.compactLoop()
+	}
+	if m.defragInterval > 0 {
+		m.doneWg.Add(1)
+		go m.defragLoop()
+	}
+}
+
+// Stop stops the background maintenance and waits for any compaction or
+// defragmentation in progress to complete.
+func (m *embeddedKVMaintainer) Stop() {
+	m.Lock()
+	if !m.running {
+		m.Unlock()
+		return
+	}
+	m.running = false
+	close(m.closeCh)
+	m.Unlock()
+
+	m.doneWg.Wait()
+}
+
+func (m *embeddedKVMaintainer) compactLoop() {
+	defer m.doneWg.Done()
+
+	ticker := time.NewTicker(m.compactionRetention)
+	defer ticker.Stop()
+
+	// Each compaction discards the history before the revision observed at the previous
+	// tick, which retains at least the configured period of history.
+	retainFromRev := m.server.KV().Rev()
+	for {
+		select {
+		case <-m.closeCh:
+			return
+		case <-m.server.StopNotify():
+			return
+		case <-ticker.C:
+		}
+
+		rev := m.server.KV().Rev()
+		if m.shouldSkip() {
+			m.metrics.compactSkipped.Inc(1)
+		} else {
+			m.compact(retainFromRev)
+		}
+		retainFromRev = rev
+	}
+}
+
+func (m *embeddedKVMaintainer) compact(rev int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), embeddedKVCompactTimeout)
+	defer cancel()
+
+	start := time.Now()
+	_, err := m.server.Compact(ctx, &pb.CompactionRequest{Revision: rev})
+	m.metrics.compactLatency.Record(time.Since(start))
+	if err == mvcc.ErrCompacted {
+		// Another member has already compacted past the revision.
+		err = nil
+	}
+	if err != nil {
+		m.metrics.compactErrors.Inc(1)
+		m.logger.Errorf("could not compact embedded kv at revision %d: %v", rev, err)
+		return
+	}
+
+	m.metrics.compactSuccess.Inc(1)
+	m.metrics.compactRevision.Update(float64(rev))
+	m.logger.Infof("compacted embedded kv at revision %d, took %s", rev, time.Since(start))
+}
+
+func (m *embeddedKVMaintainer) defragLoop() {
+	defer m.doneWg.Done()
+
+	ticker := time.NewTicker(m.defragInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.closeCh:
+			return
+		case <-m.server.StopNotify():
+			return
+		case <-ticker.C:
+		}
+
+		if m.shouldSkip() {
+			m.metrics.defragSkipped.Inc(1)
+			continue
+		}
+		m.defrag()
+	}
+}
+
+func (m *embeddedKVMaintainer) defrag() {
+	backend := m.server.Backend()
+
+	start := time.Now()
+	err := backend.Defrag()
+	m.metrics.defragLatency.Record(time.Since(start))
+	if err != nil {
+		m.metrics.defragErrors.Inc(1)
+		m.logger.Errorf("could not defrag embedded kv: %v", err)
+		return
+	}
+
+	size := backend.Size()
+	m.metrics.defragSuccess.Inc(1)
+	m.metrics.backendSizeBytes.Update(float64(size))
+	m.logger.Infof("defragged embedded kv to %d bytes, took %s", size, time.Since(start))
+}
+
+func (m *embeddedKVMaintainer) shouldSkip() bool {
+	return m.maintainOnlyAsLeader && m.server.Leader() != m.server.ID()
+}
+
+// waitEmbeddedKVReady waits for the embedded etcd server to be ready to serve
+// requests, warning each time the timeout elapses while it is still not ready.
+func waitEmbeddedKVReady(
+	server *etcdserver.EtcdServer,
+	timeout time.Duration,
+	logger xlog.Logger,
+	scope tally.Scope,
+) {
+	if timeout <= 0 {
+		timeout = defaultEmbeddedKVReadyTimeout
+	}
+
+	var (
+		start    = time.Now()
+		timedOut = scope.Counter("ready-timeout")
+	)
+	for {
+		select {
+		case <-server.ReadyNotify():
+			logger.Infof("embedded kv ready, took %s", time.Since(start).String())
+			return
+		case <-server.StopNotify():
+			return
+		case <-time.After(timeout):
+			timedOut.Inc(1)
+			logger.Warnf("embedded kv not ready after %s", time.Since(start).String())
+		}
+	}
+}
+
+// embeddedKVHealth is the health of the embedded etcd server of a seed node.
+type embeddedKVHealth struct {
+	Status       string   `json:"status"`
+	Ready        bool     `json:"ready"`
+	MemberID     string   `json:"memberID,omitempty"`
+	LeaderID     string   `json:"leaderID,omitempty"`
+	LeaderKnown  bool     `json:"leaderKnown"`
+	DBSizeBytes  int64    `json:"dbSizeBytes"`
+	AppliedIndex uint64   `json:"appliedIndex"`
+	Alarms       []string `json:"alarms"`
+	AlarmsError  string   `json:"alarmsError,omitempty"`
+}
+
+// embeddedKVHealthReporter reports the health of the embedded etcd server, if any,
+// as gauges and on the debug listener.
+type embeddedKVHealthReporter struct {
+	server *etcdserver.EtcdServer
+
+	ready        tally.Gauge
+	leaderKnown  tally.Gauge
+	dbSizeBytes  tally.Gauge
+	appliedIndex tally.Gauge
+	alarms       tally.Gauge
+
+	closeCh chan struct{}
+	doneWg  sync.WaitGroup
+}
+
+// newEmbeddedKVHealthReporter returns a health reporter for the embedded etcd
+// server, which is nil if the node is not a seed node.
+func newEmbeddedKVHealthReporter(
+	server *etcdserver.EtcdServer,
+	scope tally.Scope,
+) *embeddedKVHealthReporter {
+	return &embeddedKVHealthReporter{
+		server:       server,
+		ready:        scope.Gauge("ready"),
+		leaderKnown:  scope.Gauge("leader-known"),
+		dbSizeBytes:  scope.Gauge("db-size-bytes"),
+		appliedIndex: scope.Gauge("applied-index"),
+		alarms:       scope.Gauge("alarms"),
+		closeCh:      make(chan struct{}),
+	}
+}
+
+// Start begins periodically reporting the health as gauges.
+func (r *embeddedKVHealthReporter) Start() {
+	if r.server == nil {
+		return
+	}
+
+	r.doneWg.Add(1)
+	go func() {
+		defer r.doneWg.Done()
+
+		ticker := time.NewTicker(embeddedKVHealthReportEvery)
+		defer ticker.Stop()
+
+		for {
+			r.report(r.Health())
+			select {
+			case <-r.closeCh:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops reporting the health as gauges.
+func (r *embeddedKVHealthReporter) Stop() {
+	close(r.closeCh)
+	r.doneWg.Wait()
+}
+
+// Health returns the current health of the embedded etcd server.
+func (r *embeddedKVHealthReporter) Health() embeddedKVHealth {
+	if r.server == nil {
+		return embeddedKVHealth{Status: embeddedKVStatusNotApplicable}
+	}
+
+	var (
+		memberID = r.server.ID()
+		leaderID = r.server.Leader()
+		health   = embeddedKVHealth{
+			MemberID:     memberID.String(),
+			LeaderKnown:  leaderID != 0,
+			DBSizeBytes:  r.server.Backend().Size(),
+			AppliedIndex: r.server.Index(),
+			Alarms:       []string{},
+		}
+	)
+	if health.LeaderKnown {
+		health.LeaderID = leaderID.String()
+	}
+
+	select {
+	case <-r.server.ReadyNotify():
+		health.Ready = true
+	default:
+	}
+
+	// NB: listing the alarms requires a quorum, so failing to list them is
+	// itself a sign that the cluster is unhealthy.
+	if health.Ready {
+		alarms, err := r.listAlarms()
+		if err != nil {
+			health.AlarmsError = err.Error()
+		}
+		health.Alarms = append(health.Alarms, alarms...)
+	}
+
+	switch {
+	case !health.Ready:
+		health.Status = embeddedKVStatusNotReady
+	case !health.LeaderKnown:
+		health.Status = embeddedKVStatusNoLeader
+	case len(health.Alarms) > 0:
+		health.Status = embeddedKVStatusAlarms
+	default:
+		health.Status = embeddedKVStatusHealthy
+	}
+	return health
+}
+
+func (r *embeddedKVHealthReporter) listAlarms() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), embeddedKVAlarmsTimeout)
+	defer cancel()
+
+	resp, err := r.server.Alarm(ctx, &pb.AlarmRequest{Action: pb.AlarmRequest_GET})
+	if err != nil {
+		return nil, err
+	}
+
+	alarms := make([]string, 0, len(resp.Alarms))
+	for _, alarm := range resp.Alarms {
+		alarms = append(alarms, alarm.Alarm.String())
+	}
+	return alarms, nil
+}
+
+func (r *embeddedKVHealthReporter) report(health embeddedKVHealth) {
+	r.ready.Update(boolGaugeValue(health.Ready))
+	r.leaderKnown.Update(boolGaugeValue(health.LeaderKnown))
+	r.dbSizeBytes.Update(float64(health.DBSizeBytes))
+	r.appliedIndex.Update(float64(health.AppliedIndex))
+	r.alarms.Update(float64(len(health.Alarms)))
+}
+
+func (r *embeddedKVHealthReporter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(r.Health()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// registerEmbeddedKVHealth serves the health of the embedded etcd server on the
+// debug listener, replacing any previously registered reporter.
+func registerEmbeddedKVHealth(r *embeddedKVHealthReporter) {
+	embeddedKVHealthCurrent.Store(r)
+	embeddedKVHealthRegisterOnce.Do(func() {
+		http.HandleFunc(embeddedKVHealthPath, func(w http.ResponseWriter, req *http.Request) {
+			embeddedKVHealthCurrent.Load().(*embeddedKVHealthReporter).ServeHTTP(w, req)
+		})
+	})
+}
+
+func boolGaugeValue(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func TestEmbeddedKVHealthNotApplicable(t *testing.T) {
+	reporter := newEmbeddedKVHealthReporter(nil, tally.NoopScope)
+	reporter.Start()
+	defer reporter.Stop()
+
+	require.Equal(t, embeddedKVHealth{Status: embeddedKVStatusNotApplicable},
+		reporter.Health())
+}
+
+func TestEmbeddedKVHealthHandler(t *testing.T) {
+	registerEmbeddedKVHealth(newEmbeddedKVHealthReporter(nil, tally.NoopScope))
+	// Registering again replaces the reporter rather than registering the path twice.
+	registerEmbeddedKVHealth(newEmbeddedKVHealthReporter(nil, tally.NoopScope))
+
+	req := httptest.NewRequest(http.MethodGet, embeddedKVHealthPath, nil)
+	recorder := httptest.NewRecorder()
+	http.DefaultServeMux.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var health embeddedKVHealth
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &health))
+	require.Equal(t, embeddedKVStatusNotApplicable, health.Status)
+}
@@ -0,0 +1,306 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3cluster/generated/proto/commonpb"
+	"github.com/m3db/m3cluster/kv"
+	xlog "github.com/m3db/m3x/log"
+)
+
+const (
+	// kvWatchLogLimit is the number of identical messages logged for a KV key
+	// each kvWatchLogLimitInterval, further messages are suppressed and counted.
+	kvWatchLogLimit         = 5
+	kvWatchLogLimitInterval = time.Minute
+
+	// kvLogValuePreviewMaxBytes bounds the raw value logged for a KV key.
+	kvLogValuePreviewMaxBytes = 64
+)
+
+// kvParseFn parses the value of a KV key.
+type kvParseFn func(value kv.Value) (interface{}, error)
+
+// kvWatchValue eagerly applies the current value of a KV key and then watches the key,
+// calling onValue with each parsed value set and onDelete each time it is deleted.
+// Values which can't be parsed or applied are logged and otherwise ignored.
+func kvWatchValue(
+	store kv.Store,
+	logger xlog.Logger,
+	key string,
+	parseFn kvParseFn,
+	onValue func(value interface{}) error,
+	onDelete func() error,
+) {
+	log := newKVWatchLogger(logger, key)
+
+	// The current value is nil while the key is unset and its default applies.
+	var current interface{}
+	apply := func(value kv.Value) {
+		if value == nil {
+			if err := onDelete(); err != nil {
+				log.Error("could not set default for deleted KV key",
+					xlog.NewField("oldValue", kvLogValue(current)),
+					xlog.NewErrField(err))
+				return
+			}
+			log.Info("set default for deleted KV key",
+				xlog.NewField("oldValue", kvLogValue(current)))
+			current = nil
+			return
+		}
+
+		newValue, err := parseFn(value)
+		if err != nil {
+			log.Error("could not parse KV key",
+				xlog.NewField("version", value.Version()),
+				xlog.NewField("value", kvLogValuePreview(value)),
+				xlog.NewErrField(err))
+			return
+		}
+
+		fields := []xlog.Field{
+			xlog.NewField("version", value.Version()),
+			xlog.NewField("oldValue", kvLogValue(current)),
+			xlog.NewField("newValue", kvLogValue(newValue)),
+		}
+		if err := onValue(newValue); err != nil {
+			log.Error("could not apply KV key", append(fields, xlog.NewErrField(err))...)
+			return
+		}
+		log.Info("set KV key", fields...)
+		current = newValue
+	}
+
+	// First try to eagerly set the value so it doesn't flap if the
+	// watch returns but not immediately for an existing value
+	value, err := store.Get(key)
+	if err != nil && err != kv.ErrNotFound {
+		log.Error("could not resolve KV key", xlog.NewErrField(err))
+	}
+	if err == nil {
+		apply(value)
+	}
+
+	watch, err := store.Watch(key)
+	if err != nil {
+		log.Error("could not watch KV key", xlog.NewErrField(err))
+		return
+	}
+
+	go func() {
+		for range watch.C() {
+			apply(watch.Get())
+		}
+	}()
+}
+
+func kvLogValue(value interface{}) string {
+	if value == nil {
+		return "<default>"
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// kvLogValuePreview returns the raw value of a KV key quoted for logging,
+// truncated to at most kvLogValuePreviewMaxBytes.
+func kvLogValuePreview(value kv.Value) string {
+	var raw kvRawValue
+	if err := value.Unmarshal(&raw); err != nil {
+		return "<unknown>"
+	}
+	if len(raw) > kvLogValuePreviewMaxBytes {
+		return fmt.Sprintf("%q...", []byte(raw[:kvLogValuePreviewMaxBytes]))
+	}
+	return fmt.Sprintf("%q", []byte(raw))
+}
+
+// kvRawValue is a proto message that unmarshals to the raw bytes of a KV value.
+type kvRawValue []byte
+
+func (v *kvRawValue) Reset()         { *v = nil }
+func (v *kvRawValue) String() string { return string(*v) }
+func (v *kvRawValue) ProtoMessage()  {}
+
+func (v *kvRawValue) Unmarshal(data []byte) error {
+	*v = append((*v)[:0], data...)
+	return nil
+}
+
+// kvWatchLogger logs the messages of the watch of a KV key with the key as a
+// field, logging at most kvWatchLogLimit identical messages each interval so
+// that a bad value or a flapping KV can't flood the logs. The number of messages
+// suppressed is logged with the next message logged once the interval elapses.
+type kvWatchLogger struct {
+	sync.Mutex
+
+	logger   xlog.Logger
+	limit    int
+	interval time.Duration
+	nowFn    func() time.Time
+	windows  map[string]*kvWatchLogWindow
+}
+
+type kvWatchLogWindow struct {
+	start      time.Time
+	logged     int
+	suppressed int
+}
+
+func newKVWatchLogger(logger xlog.Logger, key string) *kvWatchLogger {
+	return &kvWatchLogger{
+		logger:   logger.WithFields(xlog.NewField("key", key)),
+		limit:    kvWatchLogLimit,
+		interval: kvWatchLogLimitInterval,
+		nowFn:    time.Now,
+		windows:  make(map[string]*kvWatchLogWindow),
+	}
+}
+
+// Info logs a message at info priority unless rate limited.
+func (l *kvWatchLogger) Info(msg string, fields ...xlog.Field) {
+	if logger, ok := l.allow(msg, fields); ok {
+		logger.Info(msg)
+	}
+}
+
+// Error logs a message at error priority unless rate limited.
+func (l *kvWatchLogger) Error(msg string, fields ...xlog.Field) {
+	if logger, ok := l.allow(msg, fields); ok {
+		logger.Error(msg)
+	}
+}
+
+func (l *kvWatchLogger) allow(msg string, fields []xlog.Field) (xlog.Logger, bool) {
+	l.Lock()
+	defer l.Unlock()
+
+	now := l.nowFn()
+	window, ok := l.windows[msg]
+	if !ok {
+		window = &kvWatchLogWindow{start: now}
+		l.windows[msg] = window
+	}
+	if now.Sub(window.start) >= l.interval {
+		if window.suppressed > 0 {
+			fields = append(fields, xlog.NewField("suppressed", window.suppressed))
+		}
+		*window = kvWatchLogWindow{start: now}
+	}
+	if window.logged >= l.limit {
+		window.suppressed++
+		return nil, false
+	}
+	window.logged++
+	return l.logger.WithFields(fields...), true
+}
+
+// kvWatchStringValue watches a KV key holding a string.
+func kvWatchStringValue(
+	store kv.Store,
+	logger xlog.Logger,
+	key string,
+	onValue func(value string) error,
+	onDelete func() error,
+) {
+	kvWatchValue(store, logger, key, parseKVString,
+		func(value interface{}) error {
+			return onValue(value.(string))
+		}, onDelete)
+}
+
+// kvWatchIntValue watches a KV key holding an integer.
+func kvWatchIntValue(
+	store kv.Store,
+	logger xlog.Logger,
+	key string,
+	onValue func(value int) error,
+	onDelete func() error,
+) {
+	kvWatchValue(store, logger, key, parseKVInt,
+		func(value interface{}) error {
+			return onValue(value.(int))
+		}, onDelete)
+}
+
+// kvWatchDurationValue watches a KV key holding a duration string, such as "30s".
+func kvWatchDurationValue(
+	store kv.Store,
+	logger xlog.Logger,
+	key string,
+	onValue func(value time.Duration) error,
+	onDelete func() error,
+) {
+	kvWatchValue(store, logger, key, parseKVDuration,
+		func(value interface{}) error {
+			return onValue(value.(time.Duration))
+		}, onDelete)
+}
+
+// kvWatchBoolValue watches a KV key holding a bool.
+func kvWatchBoolValue(
+	store kv.Store,
+	logger xlog.Logger,
+	key string,
+	onValue func(value bool) error,
+	onDelete func() error,
+) {
+	kvWatchValue(store, logger, key, parseKVBool,
+		func(value interface{}) error {
+			return onValue(value.(bool))
+		}, onDelete)
+}
+
+func parseKVString(value kv.Value) (interface{}, error) {
+	var protoValue commonpb.StringProto
+	if err := value.Unmarshal(&protoValue); err != nil {
+		return nil, err
+	}
+	return protoValue.Value, nil
+}
+
+func parseKVInt(value kv.Value) (interface{}, error) {
+	var protoValue commonpb.Int64Proto
+	if err := value.Unmarshal(&protoValue); err != nil {
+		return nil, err
+	}
+	return int(protoValue.Value), nil
+}
+
+func parseKVDuration(value kv.Value) (interface{}, error) {
+	str, err := parseKVString(value)
+	if err != nil {
+		return nil, err
+	}
+	return time.ParseDuration(str.(string))
+}
+
+func parseKVBool(value kv.Value) (interface{}, error) {
+	var protoValue commonpb.BoolProto
+	if err := value.Unmarshal(&protoValue); err != nil {
+		return nil, err
+	}
+	return protoValue.Value, nil
+}
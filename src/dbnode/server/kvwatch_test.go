@@ -0,0 +1,288 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3cluster/generated/proto/commonpb"
+	"github.com/m3db/m3cluster/kv"
+	m3clusterkvmem "github.com/m3db/m3cluster/kv/mem"
+	xclock "github.com/m3db/m3x/clock"
+	xlog "github.com/m3db/m3x/log"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+)
+
+const testKVWatchKey = "test-key"
+
+// kvWatchRecorder records the values and deletes applied by a KV watch.
+type kvWatchRecorder struct {
+	sync.Mutex
+	events []string
+}
+
+func (r *kvWatchRecorder) onValue(value interface{}) error {
+	r.Lock()
+	r.events = append(r.events, fmt.Sprintf("%v", value))
+	r.Unlock()
+	return nil
+}
+
+func (r *kvWatchRecorder) onDelete() error {
+	r.Lock()
+	r.events = append(r.events, "<deleted>")
+	r.Unlock()
+	return nil
+}
+
+// appliedEvents returns the recorded events with consecutive duplicates removed, as
+// the watch may deliver the same value more than once.
+func (r *kvWatchRecorder) appliedEvents() []string {
+	r.Lock()
+	defer r.Unlock()
+	var events []string
+	for _, event := range r.events {
+		if len(events) == 0 || events[len(events)-1] != event {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func (r *kvWatchRecorder) waitUntilLastEvent(t *testing.T, event string) {
+	require.True(t, xclock.WaitUntil(func() bool {
+		events := r.appliedEvents()
+		return len(events) > 0 && events[len(events)-1] == event
+	}, 5*time.Second))
+}
+
+func testKVWatchSequence(
+	t *testing.T,
+	watchFn func(store kv.Store, r *kvWatchRecorder),
+	initial proto.Message,
+	updated proto.Message,
+	malformed proto.Message,
+	expected []string,
+) {
+	store := m3clusterkvmem.NewStore()
+	_, err := store.Set(testKVWatchKey, initial)
+	require.NoError(t, err)
+
+	r := &kvWatchRecorder{}
+	watchFn(store, r)
+
+	// The initial value is applied eagerly.
+	require.Equal(t, expected[:1], r.appliedEvents())
+
+	_, err = store.Set(testKVWatchKey, updated)
+	require.NoError(t, err)
+	r.waitUntilLastEvent(t, expected[1])
+
+	_, err = store.Delete(testKVWatchKey)
+	require.NoError(t, err)
+	r.waitUntilLastEvent(t, "<deleted>")
+
+	// Malformed values are ignored.
+	_, err = store.Set(testKVWatchKey, malformed)
+	require.NoError(t, err)
+	_, err = store.Set(testKVWatchKey, initial)
+	require.NoError(t, err)
+	r.waitUntilLastEvent(t, expected[0])
+
+	require.Equal(t, []string{expected[0], expected[1], "<deleted>", expected[0]},
+		r.appliedEvents())
+}
+
+func TestKVWatchStringValue(t *testing.T) {
+	testKVWatchSequence(t,
+		func(store kv.Store, r *kvWatchRecorder) {
+			kvWatchStringValue(store, xlog.NullLogger, testKVWatchKey,
+				func(value string) error { return r.onValue(value) }, r.onDelete)
+		},
+		&commonpb.StringProto{Value: "foo"},
+		&commonpb.StringProto{Value: "bar"},
+		&commonpb.Int64Proto{Value: 42},
+		[]string{"foo", "bar"})
+}
+
+func TestKVWatchIntValue(t *testing.T) {
+	testKVWatchSequence(t,
+		func(store kv.Store, r *kvWatchRecorder) {
+			kvWatchIntValue(store, xlog.NullLogger, testKVWatchKey,
+				func(value int) error { return r.onValue(value) }, r.onDelete)
+		},
+		&commonpb.Int64Proto{Value: 5},
+		&commonpb.Int64Proto{Value: 6},
+		&commonpb.StringProto{Value: "abc"},
+		[]string{"5", "6"})
+}
+
+func TestKVWatchDurationValue(t *testing.T) {
+	testKVWatchSequence(t,
+		func(store kv.Store, r *kvWatchRecorder) {
+			kvWatchDurationValue(store, xlog.NullLogger, testKVWatchKey,
+				func(value time.Duration) error { return r.onValue(value) }, r.onDelete)
+		},
+		&commonpb.StringProto{Value: "30s"},
+		&commonpb.StringProto{Value: "1m"},
+		&commonpb.StringProto{Value: "thirty seconds"},
+		[]string{"30s", "1m0s"})
+}
+
+func TestKVWatchBoolValue(t *testing.T) {
+	testKVWatchSequence(t,
+		func(store kv.Store, r *kvWatchRecorder) {
+			kvWatchBoolValue(store, xlog.NullLogger, testKVWatchKey,
+				func(value bool) error { return r.onValue(value) }, r.onDelete)
+		},
+		&commonpb.BoolProto{Value: true},
+		&commonpb.BoolProto{Value: false},
+		&commonpb.StringProto{Value: "true"},
+		[]string{"true", "false"})
+}
+
+func TestKVWatchValueApplyErrorIgnored(t *testing.T) {
+	store := m3clusterkvmem.NewStore()
+	r := &kvWatchRecorder{}
+	kvWatchIntValue(store, xlog.NullLogger, testKVWatchKey,
+		func(value int) error {
+			if value < 0 {
+				return errors.New("negative value")
+			}
+			return r.onValue(value)
+		}, r.onDelete)
+
+	_, err := store.Set(testKVWatchKey, &commonpb.Int64Proto{Value: 1})
+	require.NoError(t, err)
+	r.waitUntilLastEvent(t, "1")
+
+	_, err = store.Set(testKVWatchKey, &commonpb.Int64Proto{Value: -1})
+	require.NoError(t, err)
+	_, err = store.Set(testKVWatchKey, &commonpb.Int64Proto{Value: 2})
+	require.NoError(t, err)
+	r.waitUntilLastEvent(t, "2")
+	require.Equal(t, []string{"1", "2"}, r.appliedEvents())
+}
+
+// kvWatchLogRecorder records the messages and fields logged.
+type kvWatchLogRecorder struct {
+	xlog.Logger
+
+	fields   map[string]interface{}
+	messages *[]kvWatchLogRecord
+}
+
+type kvWatchLogRecord struct {
+	msg    string
+	fields map[string]interface{}
+}
+
+func newKVWatchLogRecorder() kvWatchLogRecorder {
+	return kvWatchLogRecorder{
+		Logger:   xlog.NullLogger,
+		fields:   make(map[string]interface{}),
+		messages: &[]kvWatchLogRecord{},
+	}
+}
+
+func (r kvWatchLogRecorder) Info(msg string) {
+	*r.messages = append(*r.messages, kvWatchLogRecord{msg: msg, fields: r.fields})
+}
+
+func (r kvWatchLogRecorder) Error(msg string) {
+	*r.messages = append(*r.messages, kvWatchLogRecord{msg: msg, fields: r.fields})
+}
+
+func (r kvWatchLogRecorder) WithFields(fields ...xlog.Field) xlog.Logger {
+	withFields := make(map[string]interface{}, len(r.fields)+len(fields))
+	for k, v := range r.fields {
+		withFields[k] = v
+	}
+	for _, f := range fields {
+		withFields[f.Key()] = f.Value()
+	}
+	return kvWatchLogRecorder{Logger: r.Logger, fields: withFields, messages: r.messages}
+}
+
+func TestKVWatchLoggerRateLimit(t *testing.T) {
+	var (
+		recorder = newKVWatchLogRecorder()
+		log      = newKVWatchLogger(recorder, testKVWatchKey)
+		now      = time.Now()
+	)
+	log.nowFn = func() time.Time { return now }
+
+	for i := 0; i < 2*kvWatchLogLimit; i++ {
+		log.Error("could not parse KV key", xlog.NewField("version", i))
+	}
+	// Other messages are limited separately.
+	log.Info("set KV key")
+
+	messages := *recorder.messages
+	require.Equal(t, kvWatchLogLimit+1, len(messages))
+	for i := 0; i < kvWatchLogLimit; i++ {
+		require.Equal(t, "could not parse KV key", messages[i].msg)
+		require.Equal(t, testKVWatchKey, messages[i].fields["key"])
+		require.Equal(t, i, messages[i].fields["version"])
+	}
+	require.Equal(t, "set KV key", messages[kvWatchLogLimit].msg)
+
+	// The next message logged once the interval elapses reports how many
+	// messages were suppressed.
+	now = now.Add(kvWatchLogLimitInterval)
+	log.Error("could not parse KV key")
+	messages = *recorder.messages
+	require.Equal(t, kvWatchLogLimit+2, len(messages))
+	last := messages[len(messages)-1]
+	require.Equal(t, "could not parse KV key", last.msg)
+	require.Equal(t, kvWatchLogLimit, last.fields["suppressed"])
+}
+
+func TestKVLogValuePreview(t *testing.T) {
+	store := m3clusterkvmem.NewStore()
+	_, err := store.Set(testKVWatchKey, &commonpb.StringProto{Value: "foo"})
+	require.NoError(t, err)
+	value, err := store.Get(testKVWatchKey)
+	require.NoError(t, err)
+	require.Equal(t, `"\n\x03foo"`, kvLogValuePreview(value))
+
+	long := make([]byte, 2*kvLogValuePreviewMaxBytes)
+	for i := range long {
+		long[i] = 'a'
+	}
+	_, err = store.Set(testKVWatchKey, &commonpb.StringProto{Value: string(long)})
+	require.NoError(t, err)
+	value, err = store.Get(testKVWatchKey)
+	require.NoError(t, err)
+	preview := kvLogValuePreview(value)
+	require.True(t, strings.HasSuffix(preview, "..."))
+	unquoted, err := strconv.Unquote(strings.TrimSuffix(preview, "..."))
+	require.NoError(t, err)
+	require.Equal(t, kvLogValuePreviewMaxBytes, len(unquoted))
+}
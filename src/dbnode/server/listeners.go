@@ -0,0 +1,63 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/m3db/m3/src/cmd/services/m3dbnode/config"
+	ns "github.com/m3db/m3/src/dbnode/network/server"
+
+	"github.com/uber-go/tally"
+)
+
+// nodeListenEndpoints returns the endpoints the node service listens on, the
+// plaintext listen address followed by any additional listen endpoints.
+func nodeListenEndpoints(cfg config.DBConfiguration) []config.ListenEndpointConfiguration {
+	endpoints := make([]config.ListenEndpointConfiguration, 0, 1+len(cfg.ListenEndpoints))
+	endpoints = append(endpoints, config.ListenEndpointConfiguration{Address: cfg.ListenAddress})
+	return append(endpoints, cfg.ListenEndpoints...)
+}
+
+// listenEndpoints listens on each of the endpoints, tagging the metrics of each
+// listener with the name of its endpoint. If any endpoint can't be listened on
+// the listeners already opened are closed.
+func listenEndpoints(
+	endpoints []config.ListenEndpointConfiguration,
+	scope tally.Scope,
+) ([]net.Listener, error) {
+	listeners := make([]net.Listener, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		l, err := endpoint.Listen()
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, fmt.Errorf("could not listen on %s: %v", endpoint.NameOrDefault(), err)
+		}
+		listenerScope := scope.Tagged(map[string]string{
+			"listener": endpoint.NameOrDefault(),
+		})
+		listeners = append(listeners, ns.NewInstrumentedListener(l, listenerScope))
+	}
+	return listeners, nil
+}
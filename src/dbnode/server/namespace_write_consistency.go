@@ -0,0 +1,172 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/m3db/m3/src/dbnode/kvconfig"
+	m3dbruntime "github.com/m3db/m3/src/dbnode/runtime"
+	"github.com/m3db/m3/src/dbnode/storage/namespace"
+	"github.com/m3db/m3/src/dbnode/topology"
+	"github.com/m3db/m3cluster/kv"
+	xlog "github.com/m3db/m3x/log"
+)
+
+// namespaceWriteConsistencyWatcher applies the client write consistency levels
+// set in KV for individual namespaces. KV can't watch a key prefix, so the keys
+// of each registered namespace are watched as the namespace is registered.
+type namespaceWriteConsistencyWatcher struct {
+	sync.Mutex
+
+	store          kv.Store
+	logger         xlog.Logger
+	runtimeOptsMgr m3dbruntime.OptionsManager
+
+	// watched is the set of namespaces whose keys are watched, known is the set
+	// of currently registered namespaces and levels are the levels set in KV,
+	// including those of namespaces that are no longer registered.
+	watched map[string]struct{}
+	known   map[string]struct{}
+	levels  map[string]topology.ConsistencyLevel
+}
+
+func newNamespaceWriteConsistencyWatcher(
+	store kv.Store,
+	logger xlog.Logger,
+	runtimeOptsMgr m3dbruntime.OptionsManager,
+) *namespaceWriteConsistencyWatcher {
+	return &namespaceWriteConsistencyWatcher{
+		store:          store,
+		logger:         logger,
+		runtimeOptsMgr: runtimeOptsMgr,
+		watched:        make(map[string]struct{}),
+		known:          make(map[string]struct{}),
+		levels:         make(map[string]topology.ConsistencyLevel),
+	}
+}
+
+// kvWatchNamespaceWriteConsistencyLevels watches the client write consistency
+// levels set in KV for the namespaces of the registry, which override the client
+// write consistency level for writes to those namespaces.
+func kvWatchNamespaceWriteConsistencyLevels(
+	store kv.Store,
+	logger xlog.Logger,
+	nsInit namespace.Initializer,
+	runtimeOptsMgr m3dbruntime.OptionsManager,
+) {
+	nsReg, err := nsInit.Init()
+	if err != nil {
+		logger.Errorf("could not init namespace registry to watch write consistency levels: %v", err)
+		return
+	}
+
+	nsWatch, err := nsReg.Watch()
+	if err != nil {
+		logger.Errorf("could not watch namespace registry to watch write consistency levels: %v", err)
+		return
+	}
+
+	w := newNamespaceWriteConsistencyWatcher(store, logger, runtimeOptsMgr)
+	go func() {
+		for range nsWatch.C() {
+			w.updateNamespaces(nsWatch.Get())
+		}
+	}()
+}
+
+func (w *namespaceWriteConsistencyWatcher) updateNamespaces(nsMap namespace.Map) {
+	var unwatched []string
+	w.Lock()
+	w.known = make(map[string]struct{})
+	if nsMap != nil {
+		for _, id := range nsMap.IDs() {
+			ns := id.String()
+			w.known[ns] = struct{}{}
+			if _, ok := w.watched[ns]; !ok {
+				w.watched[ns] = struct{}{}
+				unwatched = append(unwatched, ns)
+			}
+		}
+	}
+	if err := w.applyWithLock(); err != nil {
+		w.logger.Errorf("could not apply namespace write consistency levels: %v", err)
+	}
+	w.Unlock()
+
+	// NB: the watch applies the current value eagerly, so it must be started
+	// without holding the lock.
+	for _, ns := range unwatched {
+		w.watch(ns)
+	}
+}
+
+func (w *namespaceWriteConsistencyWatcher) watch(ns string) {
+	kvWatchStringValue(w.store, w.logger,
+		kvconfig.ClientWriteConsistencyLevelPrefix+ns,
+		func(value string) error {
+			level, err := parseConsistencyLevel(value)
+			if err != nil {
+				return err
+			}
+			w.Lock()
+			defer w.Unlock()
+			w.levels[ns] = level
+			return w.applyWithLock()
+		},
+		func() error {
+			w.Lock()
+			defer w.Unlock()
+			delete(w.levels, ns)
+			return w.applyWithLock()
+		})
+}
+
+// applyWithLock sets the levels of the registered namespaces as the runtime
+// overrides, levels of namespaces that are not registered are skipped with a
+// warning rather than failing the update.
+func (w *namespaceWriteConsistencyWatcher) applyWithLock() error {
+	overrides := make(map[string]topology.ConsistencyLevel, len(w.levels))
+	for ns, level := range w.levels {
+		if _, ok := w.known[ns]; !ok {
+			w.logger.WithFields(
+				xlog.NewField("namespace", ns),
+				xlog.NewField("level", level.String()),
+			).Warn("ignoring write consistency level of unknown namespace")
+			continue
+		}
+		overrides[ns] = level
+	}
+	return updateRuntimeOptions(w.runtimeOptsMgr,
+		func(opts m3dbruntime.Options) m3dbruntime.Options {
+			return opts.SetClientNamespaceWriteConsistencyLevels(overrides)
+		})
+}
+
+func parseConsistencyLevel(v string) (topology.ConsistencyLevel, error) {
+	for _, level := range topology.ValidConsistencyLevels() {
+		if level.String() == v {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid consistency level set: %s", v)
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/kvconfig"
+	m3dbruntime "github.com/m3db/m3/src/dbnode/runtime"
+	"github.com/m3db/m3/src/dbnode/storage/namespace"
+	"github.com/m3db/m3/src/dbnode/topology"
+	"github.com/m3db/m3cluster/generated/proto/commonpb"
+	m3clusterkvmem "github.com/m3db/m3cluster/kv/mem"
+	xclock "github.com/m3db/m3x/clock"
+	"github.com/m3db/m3x/ident"
+	xlog "github.com/m3db/m3x/log"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestNamespaceMap(t *testing.T, ids ...string) namespace.Map {
+	mds := make([]namespace.Metadata, 0, len(ids))
+	for _, id := range ids {
+		md, err := namespace.NewMetadata(ident.StringID(id), namespace.NewOptions())
+		require.NoError(t, err)
+		mds = append(mds, md)
+	}
+	nsMap, err := namespace.NewMap(mds)
+	require.NoError(t, err)
+	return nsMap
+}
+
+func TestKVWatchNamespaceWriteConsistencyLevels(t *testing.T) {
+	store := m3clusterkvmem.NewStore()
+	for ns, level := range map[string]string{
+		"metrics": "one",
+		"logs":    "all",
+		"other":   "one",
+	} {
+		_, err := store.Set(kvconfig.ClientWriteConsistencyLevelPrefix+ns,
+			&commonpb.StringProto{Value: level})
+		require.NoError(t, err)
+	}
+
+	runtimeOptsMgr := m3dbruntime.NewOptionsManager()
+	waitUntil := func(expected map[string]topology.ConsistencyLevel) {
+		require.True(t, xclock.WaitUntil(func() bool {
+			levels := runtimeOptsMgr.Get().ClientNamespaceWriteConsistencyLevels()
+			if len(levels) != len(expected) {
+				return false
+			}
+			for ns, level := range expected {
+				if levels[ns] != level {
+					return false
+				}
+			}
+			return true
+		}, 5*time.Second))
+	}
+
+	// Only the levels of registered namespaces are applied.
+	kvWatchNamespaceWriteConsistencyLevels(store, xlog.NullLogger,
+		namespace.NewStaticInitializer(newTestNamespaceMap(t, "metrics", "logs").Metadatas()),
+		runtimeOptsMgr)
+	waitUntil(map[string]topology.ConsistencyLevel{
+		"metrics": topology.ConsistencyLevelOne,
+		"logs":    topology.ConsistencyLevelAll,
+	})
+}
+
+func TestNamespaceWriteConsistencyWatcherUpdates(t *testing.T) {
+	store := m3clusterkvmem.NewStore()
+	_, err := store.Set(kvconfig.ClientWriteConsistencyLevelPrefix+"metrics",
+		&commonpb.StringProto{Value: "one"})
+	require.NoError(t, err)
+	_, err = store.Set(kvconfig.ClientWriteConsistencyLevelPrefix+"logs",
+		&commonpb.StringProto{Value: "all"})
+	require.NoError(t, err)
+
+	runtimeOptsMgr := m3dbruntime.NewOptionsManager()
+	w := newNamespaceWriteConsistencyWatcher(store, xlog.NullLogger, runtimeOptsMgr)
+	levels := func() map[string]topology.ConsistencyLevel {
+		return runtimeOptsMgr.Get().ClientNamespaceWriteConsistencyLevels()
+	}
+
+	// The existing values are applied eagerly.
+	w.updateNamespaces(newTestNamespaceMap(t, "metrics", "logs"))
+	require.Equal(t, map[string]topology.ConsistencyLevel{
+		"metrics": topology.ConsistencyLevelOne,
+		"logs":    topology.ConsistencyLevelAll,
+	}, levels())
+
+	// Invalid levels are ignored.
+	_, err = store.Set(kvconfig.ClientWriteConsistencyLevelPrefix+"metrics",
+		&commonpb.StringProto{Value: "invalid"})
+	require.NoError(t, err)
+	_, err = store.Set(kvconfig.ClientWriteConsistencyLevelPrefix+"metrics",
+		&commonpb.StringProto{Value: "majority"})
+	require.NoError(t, err)
+	require.True(t, xclock.WaitUntil(func() bool {
+		return levels()["metrics"] == topology.ConsistencyLevelMajority
+	}, 5*time.Second))
+
+	// The level of a namespace that is no longer registered is skipped.
+	w.updateNamespaces(newTestNamespaceMap(t, "metrics"))
+	require.Equal(t, map[string]topology.ConsistencyLevel{
+		"metrics": topology.ConsistencyLevelMajority,
+	}, levels())
+
+	// And applied again once the namespace is registered again.
+	w.updateNamespaces(newTestNamespaceMap(t, "metrics", "logs"))
+	require.Equal(t, topology.ConsistencyLevelAll, levels()["logs"])
+
+	// Deleting the keys reverts to the client write consistency level.
+	_, err = store.Delete(kvconfig.ClientWriteConsistencyLevelPrefix + "metrics")
+	require.NoError(t, err)
+	_, err = store.Delete(kvconfig.ClientWriteConsistencyLevelPrefix + "logs")
+	require.NoError(t, err)
+	require.True(t, xclock.WaitUntil(func() bool {
+		return len(levels()) == 0
+	}, 5*time.Second))
+}
@@ -0,0 +1,390 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/m3db/m3/src/cmd/services/m3dbnode/config"
+	"github.com/m3db/m3/src/dbnode/persist/fs"
+	etcd "github.com/m3db/m3cluster/client/etcd"
+	xerrors "github.com/m3db/m3x/errors"
+)
+
+var (
+	errPreflightNoInitialCluster = errors.New("seed nodes configured without an initial cluster")
+	errPreflightNoService        = errors.New("no service configuration to resolve etcd endpoints")
+	errPreflightServiceAndStatic = errors.New("service and static configuration are both set")
+)
+
+type preflightListenFn func(network, address string) (net.Listener, error)
+
+type preflightDialFn func(network, address string, timeout time.Duration) (net.Conn, error)
+
+type preflightFreeSpaceFn func(dir string) (uint64, error)
+
+// preflightCheck is a named check of the configuration or of the environment.
+type preflightCheck struct {
+	name string
+	fn   func() error
+}
+
+type preflightFailure struct {
+	check string
+	err   error
+}
+
+// preflightError lists every preflight check which failed.
+type preflightError []preflightFailure
+
+func (e preflightError) Error() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%d preflight checks failed:", len(e))
+	for _, failure := range e {
+		fmt.Fprintf(&buf, "\n  - %s: %v", failure.check, failure.err)
+	}
+	return buf.String()
+}
+
+// Preflight validates the configuration and the environment the server is about
+// to run in, returning an error describing every failed check rather than only
+// the first so that they can all be fixed before the server is restarted.
+func Preflight(cfg config.DBConfiguration) error {
+	return newPreflighter(cfg).run()
+}
+
+type preflighter struct {
+	cfg         config.DBConfiguration
+	listenFn    preflightListenFn
+	dialFn      preflightDialFn
+	freeSpaceFn preflightFreeSpaceFn
+
+	// listeners are held open until all checks have run, so that the same
+	// address configured twice fails to bind.
+	listeners []net.Listener
+}
+
+func newPreflighter(cfg config.DBConfiguration) *preflighter {
+	return &preflighter{
+		cfg:         cfg,
+		listenFn:    net.Listen,
+		dialFn:      net.DialTimeout,
+		freeSpaceFn: freeSpaceBytes,
+	}
+}
+
+func (p *preflighter) run() error {
+	defer func() {
+		for _, l := range p.listeners {
+			l.Close()
+		}
+		p.listeners = nil
+	}()
+
+	var failures preflightError
+	for _, check := range p.checks() {
+		if err := check.fn(); err != nil {
+			failures = append(failures, preflightFailure{check: check.name, err: err})
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return failures
+}
+
+func (p *preflighter) checks() []preflightCheck {
+	var (
+		cfg    = p.cfg
+		prefix = cfg.Filesystem.FilePathPrefix
+	)
+
+	hostID, hostIDErr := cfg.HostID.Resolve()
+	checks := []preflightCheck{
+		{name: "host ID", fn: func() error { return hostIDErr }},
+		{name: "data directory", fn: p.checkDirWritable(fs.DataDirPath(prefix))},
+		{name: "commit log directory", fn: p.checkDirWritable(fs.CommitLogsDirPath(prefix))},
+		{name: "free space", fn: p.checkFreeSpace(prefix)},
+	}
+
+	listenAddresses := []struct {
+		name    string
+		address string
+	}{
+		{name: "listenAddress", address: cfg.ListenAddress},
+		{name: "clusterListenAddress", address: cfg.ClusterListenAddress},
+		{name: "httpNodeListenAddress", address: cfg.HTTPNodeListenAddress},
+		{name: "httpClusterListenAddress", address: cfg.HTTPClusterListenAddress},
+		{name: "debugListenAddress", address: cfg.DebugListenAddress},
+	}
+	for _, l := range listenAddresses {
+		if l.address == "" {
+			continue
+		}
+		checks = append(checks, preflightCheck{
+			name: l.name,
+			fn:   p.checkBindable(l.address),
+		})
+	}
+
+	for _, endpoint := range cfg.ListenEndpoints {
+		checks = append(checks, preflightCheck{
+			name: "listenEndpoint " + endpoint.NameOrDefault(),
+			fn:   p.checkListenEndpoint(endpoint),
+		})
+	}
+
+	seedNodes := cfg.EnvironmentConfig.SeedNodes
+	if seedNodes == nil {
+		return append(checks, preflightCheck{
+			name: "etcd endpoints",
+			fn:   p.checkEtcdReachable(),
+		})
+	}
+
+	checks = append(checks, preflightCheck{
+		name: "seed nodes",
+		fn:   p.checkSeedNodes(),
+	})
+	if hostIDErr != nil {
+		// The remaining checks depend on whether this host is a seed node.
+		return checks
+	}
+
+	if !config.IsSeedNode(seedNodes.InitialCluster, hostID) {
+		checks = append(checks, preflightCheck{
+			name: "seed node membership",
+			fn: func() error {
+				if len(seedNodes.InitialAdvertisePeerUrls) == 0 &&
+					len(seedNodes.AdvertiseClientUrls) == 0 {
+					return nil
+				}
+				return fmt.Errorf("host ID %s has etcd advertise URLs configured "+
+					"but is not in the initial cluster", hostID)
+			},
+		})
+		return append(checks, preflightCheck{
+			name: "etcd endpoints",
+			fn:   p.checkEtcdReachable(),
+		})
+	}
+
+	// NB: the etcd endpoints of a seed node are not checked as the other seed
+	// nodes are expected to be unreachable while the cluster is first started.
+	etcdCfg, err := config.NewEtcdEmbedConfig(cfg)
+	if err != nil {
+		return append(checks, preflightCheck{
+			name: "embedded etcd",
+			fn:   func() error { return err },
+		})
+	}
+	checks = append(checks, preflightCheck{
+		name: "embedded etcd directory",
+		fn:   p.checkDirWritable(etcdCfg.Dir),
+	})
+	for _, u := range append(etcdCfg.LPUrls, etcdCfg.LCUrls...) {
+		checks = append(checks, preflightCheck{
+			name: "embedded etcd listen URL " + u.String(),
+			fn:   p.checkBindable(u.Host),
+		})
+	}
+	return checks
+}
+
+// checkDirWritable checks a file can be created in the directory, or if it
+// doesn't exist yet in the closest parent directory which does exist since
+// the directory is created when the server starts.
+func (p *preflighter) checkDirWritable(dir string) func() error {
+	return func() error {
+		existing, err := closestExistingDir(dir)
+		if err != nil {
+			return err
+		}
+		f, err := ioutil.TempFile(existing, ".m3dbnode-preflight")
+		if err != nil {
+			return fmt.Errorf("directory %s is not writable: %v", dir, err)
+		}
+		f.Close()
+		return os.Remove(f.Name())
+	}
+}
+
+func (p *preflighter) checkFreeSpace(dir string) func() error {
+	return func() error {
+		existing, err := closestExistingDir(dir)
+		if err != nil {
+			return err
+		}
+		free, err := p.freeSpaceFn(existing)
+		if err != nil {
+			return fmt.Errorf("could not determine free space of %s: %v", dir, err)
+		}
+		min := p.cfg.Preflight.MinimumFreeSpaceBytesOrDefault()
+		if free < min {
+			return fmt.Errorf("filesystem of %s has %d bytes free, at least %d required",
+				dir, free, min)
+		}
+		return nil
+	}
+}
+
+func (p *preflighter) checkBindable(address string) func() error {
+	return func() error {
+		l, err := p.listenFn("tcp", address)
+		if err != nil {
+			return fmt.Errorf("could not bind %s: %v", address, err)
+		}
+		p.listeners = append(p.listeners, l)
+		return nil
+	}
+}
+
+func (p *preflighter) checkListenEndpoint(
+	endpoint config.ListenEndpointConfiguration,
+) func() error {
+	checkBindable := p.checkBindable(endpoint.Address)
+	return func() error {
+		if endpoint.TLS != nil {
+			if _, err := endpoint.TLS.ServerConfig(); err != nil {
+				return err
+			}
+		}
+		return checkBindable()
+	}
+}
+
+func (p *preflighter) checkSeedNodes() func() error {
+	return func() error {
+		initialCluster := p.cfg.EnvironmentConfig.SeedNodes.InitialCluster
+		if len(initialCluster) == 0 {
+			return errPreflightNoInitialCluster
+		}
+		multiErr := xerrors.NewMultiError()
+		hostIDs := make(map[string]struct{}, len(initialCluster))
+		for _, seedNode := range initialCluster {
+			if _, ok := hostIDs[seedNode.HostID]; ok {
+				multiErr = multiErr.Add(fmt.Errorf(
+					"host ID %s is in the initial cluster more than once", seedNode.HostID))
+			}
+			hostIDs[seedNode.HostID] = struct{}{}
+		}
+		if _, err := config.InitialClusterEndpoints(initialCluster); err != nil {
+			multiErr = multiErr.Add(err)
+		}
+		return multiErr.FinalError()
+	}
+}
+
+// checkEtcdReachable checks at least one endpoint of each etcd cluster accepts
+// connections, the endpoints of the seed nodes are used if no clusters are set.
+func (p *preflighter) checkEtcdReachable() func() error {
+	return func() error {
+		envCfg := p.cfg.EnvironmentConfig
+		if envCfg.Service != nil && envCfg.Static != nil {
+			return errPreflightServiceAndStatic
+		}
+		if envCfg.Service == nil {
+			if envCfg.Static != nil {
+				return nil
+			}
+			return errPreflightNoService
+		}
+
+		clusters := envCfg.Service.ETCDClusters
+		if len(clusters) == 0 && envCfg.SeedNodes != nil {
+			endpoints, err := config.InitialClusterEndpoints(envCfg.SeedNodes.InitialCluster)
+			if err != nil {
+				// Reported by the seed nodes check.
+				return nil
+			}
+			clusters = []etcd.ClusterConfig{{Zone: envCfg.Service.Zone, Endpoints: endpoints}}
+		}
+
+		timeout := p.cfg.Preflight.EtcdDialTimeoutOrDefault()
+		multiErr := xerrors.NewMultiError()
+		for _, cluster := range clusters {
+			if !p.anyEndpointReachable(cluster.Endpoints, timeout) {
+				multiErr = multiErr.Add(fmt.Errorf(
+					"no etcd endpoints reachable: zone=%s, endpoints=%v",
+					cluster.Zone, cluster.Endpoints))
+			}
+		}
+		return multiErr.FinalError()
+	}
+}
+
+func (p *preflighter) anyEndpointReachable(endpoints []string, timeout time.Duration) bool {
+	for _, endpoint := range endpoints {
+		address := endpoint
+		if strings.Contains(endpoint, "://") {
+			u, err := url.Parse(endpoint)
+			if err != nil {
+				continue
+			}
+			address = u.Host
+		}
+		conn, err := p.dialFn("tcp", address, timeout)
+		if err != nil {
+			continue
+		}
+		conn.Close()
+		return true
+	}
+	return false
+}
+
+func closestExistingDir(dir string) (string, error) {
+	for curr := filepath.Clean(dir); ; {
+		info, err := os.Stat(curr)
+		if err == nil {
+			if !info.IsDir() {
+				return "", fmt.Errorf("%s is not a directory", curr)
+			}
+			return curr, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := filepath.Dir(curr)
+		if parent == curr {
+			return "", fmt.Errorf("no parent directory of %s exists", dir)
+		}
+		curr = parent
+	}
+}
+
+func freeSpaceBytes(dir string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return 0, err
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
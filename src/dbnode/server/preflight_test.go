@@ -0,0 +1,223 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cmd/services/m3dbnode/config"
+	"github.com/m3db/m3/src/dbnode/environment"
+	etcd "github.com/m3db/m3cluster/client/etcd"
+	"github.com/m3db/m3x/config/hostid"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPreflightConfig(dir string) config.DBConfiguration {
+	hostID := "host1"
+	return config.DBConfiguration{
+		ListenAddress:            "127.0.0.1:0",
+		ClusterListenAddress:     "127.0.0.1:0",
+		HTTPNodeListenAddress:    "127.0.0.1:0",
+		HTTPClusterListenAddress: "127.0.0.1:0",
+		HostID: config.HostIDConfiguration{
+			Resolver: hostid.ConfigResolver,
+			Value:    &hostID,
+		},
+		Filesystem: config.FilesystemConfiguration{
+			FilePathPrefix: path.Join(dir, "m3db"),
+		},
+		EnvironmentConfig: environment.Configuration{
+			Static: &environment.StaticConfiguration{},
+		},
+	}
+}
+
+func newTestPreflighter(cfg config.DBConfiguration, freeSpace uint64) *preflighter {
+	p := newPreflighter(cfg)
+	p.freeSpaceFn = func(string) (uint64, error) {
+		return freeSpace, nil
+	}
+	return p
+}
+
+func preflightFailedChecks(t *testing.T, err error) []string {
+	require.Error(t, err)
+	failures, ok := err.(preflightError)
+	require.True(t, ok)
+
+	checks := make([]string, 0, len(failures))
+	for _, failure := range failures {
+		checks = append(checks, failure.check)
+	}
+	return checks
+}
+
+func TestPreflightPasses(t *testing.T) {
+	dir, err := ioutil.TempDir("", "preflight")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := newTestPreflightConfig(dir)
+	require.NoError(t, newTestPreflighter(cfg, 1<<40).run())
+
+	// Preflight has no side effects on the directories.
+	_, err = os.Stat(cfg.Filesystem.FilePathPrefix)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestPreflightReportsAllFailures(t *testing.T) {
+	dir, err := ioutil.TempDir("", "preflight")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Use a file as the file path prefix so that no directory can be created.
+	filePathPrefix := path.Join(dir, "file")
+	require.NoError(t, ioutil.WriteFile(filePathPrefix, nil, 0666))
+
+	inUse, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer inUse.Close()
+
+	unreachable, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	require.NoError(t, unreachable.Close())
+
+	free, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	require.NoError(t, free.Close())
+
+	envVarName := "PREFLIGHT_TEST_MISSING_HOST_ID"
+	cfg := newTestPreflightConfig(dir)
+	cfg.HostID = config.HostIDConfiguration{
+		Resolver:   hostid.EnvironmentResolver,
+		EnvVarName: &envVarName,
+	}
+	cfg.Filesystem.FilePathPrefix = filePathPrefix
+	cfg.ListenAddress = inUse.Addr().String()
+	// The same address configured twice fails to bind the second time.
+	cfg.HTTPNodeListenAddress = free.Addr().String()
+	cfg.HTTPClusterListenAddress = free.Addr().String()
+	cfg.EnvironmentConfig = environment.Configuration{
+		Service: &etcd.Configuration{
+			Zone: "embedded",
+			ETCDClusters: []etcd.ClusterConfig{{
+				Zone:      "embedded",
+				Endpoints: []string{"http://" + unreachable.Addr().String()},
+			}},
+		},
+	}
+	cfg.Preflight = &config.PreflightConfiguration{EtcdDialTimeout: time.Second}
+
+	checks := preflightFailedChecks(t, newTestPreflighter(cfg, 1<<20).run())
+	require.Equal(t, []string{
+		"host ID",
+		"data directory",
+		"commit log directory",
+		"free space",
+		"listenAddress",
+		"httpClusterListenAddress",
+		"etcd endpoints",
+	}, checks)
+}
+
+func TestPreflightMinimumFreeSpace(t *testing.T) {
+	dir, err := ioutil.TempDir("", "preflight")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := newTestPreflightConfig(dir)
+	cfg.Preflight = &config.PreflightConfiguration{MinimumFreeSpaceBytes: 1 << 10}
+	require.NoError(t, newTestPreflighter(cfg, 1<<20).run())
+
+	cfg.Preflight.MinimumFreeSpaceBytes = 1 << 30
+	checks := preflightFailedChecks(t, newTestPreflighter(cfg, 1<<20).run())
+	require.Equal(t, []string{"free space"}, checks)
+}
+
+func TestPreflightSeedNode(t *testing.T) {
+	dir, err := ioutil.TempDir("", "preflight")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := newTestPreflightConfig(dir)
+	cfg.EnvironmentConfig = environment.Configuration{
+		Service: &etcd.Configuration{Zone: "embedded"},
+		SeedNodes: &environment.SeedNodesConfig{
+			RootDir:          path.Join(dir, "etcd"),
+			ListenPeerUrls:   []string{"http://127.0.0.1:0"},
+			ListenClientUrls: []string{"http://127.0.0.1:0"},
+			InitialCluster: []environment.SeedNode{
+				{HostID: "host1", Endpoint: "http://127.0.0.1:2380"},
+				{HostID: "host2", Endpoint: "http://127.0.0.2:2380"},
+			},
+		},
+	}
+
+	// The endpoints of seed nodes are not checked since the other seed nodes
+	// may not have started yet.
+	p := newTestPreflighter(cfg, 1<<40)
+	p.dialFn = func(string, string, time.Duration) (net.Conn, error) {
+		require.FailNow(t, "unexpected dial")
+		return nil, nil
+	}
+	require.NoError(t, p.run())
+}
+
+func TestPreflightSeedNodeMembership(t *testing.T) {
+	dir, err := ioutil.TempDir("", "preflight")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	hostID := "host3"
+	cfg := newTestPreflightConfig(dir)
+	cfg.HostID.Value = &hostID
+	cfg.EnvironmentConfig = environment.Configuration{
+		Service: &etcd.Configuration{Zone: "embedded"},
+		SeedNodes: &environment.SeedNodesConfig{
+			InitialAdvertisePeerUrls: []string{"http://127.0.0.3:2380"},
+			InitialCluster: []environment.SeedNode{
+				{HostID: "host1", Endpoint: "http://127.0.0.1:2380"},
+				{HostID: "host1", Endpoint: "http://127.0.0.2:2380"},
+			},
+		},
+	}
+
+	var dialed []string
+	p := newTestPreflighter(cfg, 1<<40)
+	p.dialFn = func(network, address string, timeout time.Duration) (net.Conn, error) {
+		dialed = append(dialed, address)
+		server, client := net.Pipe()
+		server.Close()
+		return client, nil
+	}
+
+	checks := preflightFailedChecks(t, p.run())
+	require.Equal(t, []string{"seed nodes", "seed node membership"}, checks)
+
+	// The seed node client endpoints are checked if no etcd clusters are set.
+	require.Equal(t, []string{"127.0.0.1:2379"}, dialed)
+}
@@ -21,6 +21,7 @@
 package server
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"math"
@@ -29,6 +30,7 @@ import (
 	"os/signal"
 	"runtime"
 	"runtime/debug"
+	"sync"
 	"syscall"
 	"time"
 
@@ -38,14 +40,16 @@ import (
 	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
 	"github.com/m3db/m3/src/dbnode/environment"
 	"github.com/m3db/m3/src/dbnode/kvconfig"
+	"github.com/m3db/m3/src/dbnode/network/server/carbon"
 	hjcluster "github.com/m3db/m3/src/dbnode/network/server/httpjson/cluster"
 	hjnode "github.com/m3db/m3/src/dbnode/network/server/httpjson/node"
+	"github.com/m3db/m3/src/dbnode/network/server/influxdb"
+	"github.com/m3db/m3/src/dbnode/network/server/promremote"
 	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift"
 	ttcluster "github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/cluster"
 	ttnode "github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/node"
 	"github.com/m3db/m3/src/dbnode/persist/fs"
 	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
-	"github.com/m3db/m3/src/dbnode/ratelimit"
 	"github.com/m3db/m3/src/dbnode/retention"
 	m3dbruntime "github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/serialize"
@@ -60,10 +64,10 @@ import (
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/tchannel"
 	"github.com/m3db/m3/src/dbnode/x/xio"
+	"github.com/m3db/m3/src/m3ninx/search"
 	"github.com/m3db/m3/src/x/mmap"
 	clusterclient "github.com/m3db/m3cluster/client"
 	"github.com/m3db/m3cluster/client/etcd"
-	"github.com/m3db/m3cluster/generated/proto/commonpb"
 	"github.com/m3db/m3cluster/kv"
 	"github.com/m3db/m3cluster/kv/util"
 	xconfig "github.com/m3db/m3x/config"
@@ -82,6 +86,21 @@ import (
 const (
 	bootstrapConfigInitTimeout = 10 * time.Second
 	serverGracefulCloseTimeout = 10 * time.Second
+
+	defaultShutdownFlushTimeout = time.Minute
+
+	// shutdownFlushAbortTimeout bounds the wait for the shard being snapshotted
+	// once the shutdown flush is aborted.
+	shutdownFlushAbortTimeout = 30 * time.Second
+
+	// defaultClusterNewSeriesBacklog rejects new series insertions exceeding the
+	// limit unless a backlog is set in KV.
+	defaultClusterNewSeriesBacklog = 0
+
+	minKVTickMinimumInterval = time.Second
+	maxKVTickMinimumInterval = 10 * time.Minute
+	minKVTickSeriesBatchSize = 16
+	maxKVTickSeriesBatchSize = 100000
 )
 
 // RunOptions provides options for running the server
@@ -133,6 +152,12 @@ func Run(runOpts RunOptions) {
 		os.Exit(1)
 	}
 
+	// Check everything which would otherwise fail the server part way through
+	// starting up, so that every problem is reported at once.
+	if err := Preflight(cfg); err != nil {
+		logger.Fatalf("%v", err)
+	}
+
 	debug.SetGCPercent(cfg.GCPercentage)
 
 	scope, _, err := cfg.Metrics.NewRootScope()
@@ -148,6 +173,10 @@ func Run(runOpts RunOptions) {
 	capnslog.SetGlobalLogLevel(capnslog.WARNING)
 
 	// Presence of KV server config indicates embedded etcd cluster
+	var (
+		kvMaintainer *embeddedKVMaintainer
+		kvHealth     = newEmbeddedKVHealthReporter(nil, scope.SubScope("embedded-kv"))
+	)
 	if cfg.EnvironmentConfig.SeedNodes == nil {
 		logger.Info("no seed nodes set, using dedicated etcd cluster")
 	} else {
@@ -193,14 +222,29 @@ func Run(runOpts RunOptions) {
 				logger.Fatalf("could not start embedded etcd: %v", err)
 			}
 
-			if runOpts.EmbeddedKVCh != nil {
-				// Notify on embedded KV bootstrap chan if specified
-				runOpts.EmbeddedKVCh <- struct{}{}
-			}
-
 			defer e.Close()
+
+			kvScope := scope.SubScope("embedded-kv")
+			go func() {
+				waitEmbeddedKVReady(e.Server, cfg.EnvironmentConfig.SeedNodes.ReadyTimeout,
+					logger, kvScope)
+				if runOpts.EmbeddedKVCh != nil {
+					// Notify on embedded KV bootstrap chan if specified
+					runOpts.EmbeddedKVCh <- struct{}{}
+				}
+			}()
+
+			kvMaintainer = newEmbeddedKVMaintainer(e.Server,
+				*cfg.EnvironmentConfig.SeedNodes, logger, kvScope)
+			kvMaintainer.Start()
+			defer kvMaintainer.Stop()
+
+			kvHealth = newEmbeddedKVHealthReporter(e.Server, kvScope)
+			kvHealth.Start()
+			defer kvHealth.Stop()
 		}
 	}
+	registerEmbeddedKVHealth(kvHealth)
 
 	opts := storage.NewOptions()
 	iopts := opts.InstrumentOptions().
@@ -223,16 +267,9 @@ func Run(runOpts RunOptions) {
 	}
 	defer buildReporter.Stop()
 
-	runtimeOpts := m3dbruntime.NewOptions().
-		SetPersistRateLimitOptions(ratelimit.NewOptions().
-			SetLimitEnabled(true).
-			SetLimitMbps(cfg.Filesystem.ThroughputLimitMbps).
-			SetLimitCheckEvery(cfg.Filesystem.ThroughputCheckEvery)).
-		SetWriteNewSeriesAsync(cfg.WriteNewSeriesAsync).
-		SetWriteNewSeriesBackoffDuration(cfg.WriteNewSeriesBackoffDuration)
-	if lruCfg := cfg.Cache.SeriesConfiguration().LRU; lruCfg != nil {
-		runtimeOpts = runtimeOpts.SetMaxWiredBlocks(lruCfg.MaxBlocks)
-	}
+	// NB: read only is not reloadable, once started it's only set by the KV watch.
+	runtimeOpts := runtimeOptionsFromConfig(cfg, m3dbruntime.NewOptions()).
+		SetReadOnly(cfg.ReadOnly)
 
 	// FOLLOWUP(prateek): remove this once we have the runtime options<->index wiring done
 	indexOpts := opts.IndexOptions()
@@ -240,15 +277,16 @@ func Run(runOpts RunOptions) {
 	if cfg.WriteNewSeriesAsync {
 		insertMode = index.InsertAsync
 	}
-	opts = opts.SetIndexOptions(
-		indexOpts.SetInsertMode(insertMode))
-
-	if tick := cfg.Tick; tick != nil {
-		runtimeOpts = runtimeOpts.
-			SetTickSeriesBatchSize(tick.SeriesBatchSize).
-			SetTickPerSeriesSleepDuration(tick.PerSeriesSleepDuration).
-			SetTickMinimumInterval(tick.MinimumInterval)
+	indexOpts = indexOpts.
+		SetInsertMode(insertMode).
+		SetMaxQueryTermsMatched(cfg.Index.MaxQueryTermsMatched).
+		SetBlockRetentionGracePeriod(cfg.Index.BlockRetentionGracePeriod)
+	if cfg.Index.PostingsCacheMaxBytes > 0 {
+		postingsCache := search.NewPostingsCache(cfg.Index.PostingsCacheMaxBytes,
+			scope.SubScope("index").SubScope("postings-cache"))
+		indexOpts = indexOpts.SetPostingsCache(postingsCache)
 	}
+	opts = opts.SetIndexOptions(indexOpts)
 
 	runtimeOptsMgr := m3dbruntime.NewOptionsManager()
 	if err := runtimeOptsMgr.Update(runtimeOpts); err != nil {
@@ -256,6 +294,13 @@ func Run(runOpts RunOptions) {
 	}
 	defer runtimeOptsMgr.Close()
 
+	if runOpts.ConfigFile != "" {
+		reloader := newConfigReloader(cfg, loadConfigFileFn(runOpts.ConfigFile),
+			runtimeOptsMgr, logger)
+		stopReload := reloadConfigOnHangup(reloader, logger)
+		defer stopReload()
+	}
+
 	opts = opts.SetRuntimeOptionsManager(runtimeOptsMgr)
 
 	newFileMode, err := cfg.Filesystem.ParseNewFileMode()
@@ -308,6 +353,12 @@ func Run(runOpts RunOptions) {
 		SetRuntimeOptionsManager(runtimeOptsMgr).
 		SetTagEncoderPool(tagEncoderPool).
 		SetTagDecoderPool(tagDecoderPool)
+	if v := cfg.Filesystem.BloomFilterFalsePositivePercent; v != nil {
+		fsopts = fsopts.SetIndexBloomFilterFalsePositivePercent(*v)
+	}
+	if v := cfg.Filesystem.DataPageSize; v != nil {
+		fsopts = fsopts.SetWriterDataPageSize(*v)
+	}
 
 	var commitLogQueueSize int
 	specified := cfg.CommitLog.Queue.Size
@@ -329,6 +380,10 @@ func Run(runOpts RunOptions) {
 		SetFlushInterval(cfg.CommitLog.FlushEvery).
 		SetBacklogQueueSize(commitLogQueueSize).
 		SetBlockSize(cfg.CommitLog.BlockSize))
+	if v := cfg.CommitLog.Compression; v != nil {
+		opts = opts.SetCommitLogOptions(opts.CommitLogOptions().
+			SetCompression(*v))
+	}
 
 	// Set the series cache policy
 	seriesCachePolicy := cfg.Cache.SeriesConfiguration().Policy
@@ -350,7 +405,12 @@ func Run(runOpts RunOptions) {
 			SetIdentifierPool(opts.IdentifierPool())
 		if blockRetrieveCfg := cfg.BlockRetrieve; blockRetrieveCfg != nil {
 			retrieverOpts = retrieverOpts.
-				SetFetchConcurrency(blockRetrieveCfg.FetchConcurrency)
+				SetFetchConcurrency(blockRetrieveCfg.FetchConcurrency).
+				SetReadAheadEnabled(blockRetrieveCfg.ReadAhead)
+			if blockRetrieveCfg.ReadAheadBlocks > 0 {
+				retrieverOpts = retrieverOpts.
+					SetReadAheadBlocks(blockRetrieveCfg.ReadAheadBlocks)
+			}
 		}
 		blockRetrieverMgr := block.NewDatabaseBlockRetrieverManager(
 			func(md namespace.Metadata) (block.DatabaseBlockRetriever, error) {
@@ -399,7 +459,8 @@ func Run(runOpts RunOptions) {
 		runOpts.ClusterClientCh <- envCfg.ClusterClient
 	}
 
-	opts = opts.SetNamespaceInitializer(envCfg.NamespaceInitializer)
+	opts = opts.SetNamespaceInitializer(envCfg.NamespaceInitializer).
+		SetNamespaceRemovalFlushEnabled(cfg.NamespaceRemovalFlushEnabled)
 
 	topo, err := envCfg.TopologyInitializer.Init()
 	if err != nil {
@@ -433,6 +494,14 @@ func Run(runOpts RunOptions) {
 	clientAdminOpts := m3dbClient.Options().(client.AdminOptions)
 	kvWatchClientConsistencyLevels(envCfg.KVStore, logger,
 		clientAdminOpts, runtimeOptsMgr)
+	kvWatchNamespaceWriteConsistencyLevels(envCfg.KVStore, logger,
+		envCfg.NamespaceInitializer, runtimeOptsMgr)
+	kvWatchTickOptions(envCfg.KVStore, logger, scope.SubScope("runtime"),
+		runtimeOptsMgr)
+	kvWatchRepairOptions(envCfg.KVStore, logger, runtimeOptsMgr)
+	kvWatchReadOnly(envCfg.KVStore, logger, hostID, runtimeOptsMgr)
+	kvWatchCommitLogQueueOptions(envCfg.KVStore, logger, runtimeOptsMgr)
+	kvWatchWriteShadowingRules(envCfg.KVStore, logger, runtimeOptsMgr)
 
 	// Set bootstrap options
 	bs, err := cfg.Bootstrap.New(opts, m3dbClient)
@@ -441,6 +510,7 @@ func Run(runOpts RunOptions) {
 	}
 
 	opts = opts.SetBootstrapProcessProvider(bs)
+	registerBootstrapProgress(bs.ProcessOptions().Progress())
 
 	timeout := bootstrapConfigInitTimeout
 	kvWatchBootstrappers(envCfg.KVStore, logger, timeout, cfg.Bootstrap.Bootstrappers,
@@ -466,16 +536,21 @@ func Run(runOpts RunOptions) {
 			scope.SubScope("host-block-metadata-slice-pool")),
 		policy.HostBlockMetadataSlicePool.Capacity)
 
+	repairOpts := opts.RepairOptions().
+		SetAdminClient(m3dbClient).
+		SetRepairInterval(cfg.Repair.Interval).
+		SetRepairTimeOffset(cfg.Repair.Offset).
+		SetRepairTimeJitter(cfg.Repair.Jitter).
+		SetRepairThrottle(cfg.Repair.Throttle).
+		SetRepairCheckInterval(cfg.Repair.CheckInterval).
+		SetRepairBufferChecksums(!cfg.Repair.SkipBufferChecksums).
+		SetHostBlockMetadataSlicePool(hostBlockMetadataSlicePool)
+	if v := cfg.Repair.BufferChecksumsPerSecond; v != nil {
+		repairOpts = repairOpts.SetRepairBufferChecksumsPerSecond(*v)
+	}
 	opts = opts.
 		SetRepairEnabled(cfg.Repair.Enabled).
-		SetRepairOptions(opts.RepairOptions().
-			SetAdminClient(m3dbClient).
-			SetRepairInterval(cfg.Repair.Interval).
-			SetRepairTimeOffset(cfg.Repair.Offset).
-			SetRepairTimeJitter(cfg.Repair.Jitter).
-			SetRepairThrottle(cfg.Repair.Throttle).
-			SetRepairCheckInterval(cfg.Repair.CheckInterval).
-			SetHostBlockMetadataSlicePool(hostBlockMetadataSlicePool))
+		SetRepairOptions(repairOpts)
 
 	// Set tchannelthrift options
 	blockMetadataPool := tchannelthrift.NewBlockMetadataPool(
@@ -509,14 +584,21 @@ func Run(runOpts RunOptions) {
 	contextPool := opts.ContextPool()
 
 	tchannelOpts := xtchannel.NewDefaultChannelOptions()
-	tchannelthriftNodeClose, err := ttnode.NewServer(db,
-		cfg.ListenAddress, contextPool, tchannelOpts, ttopts).ListenAndServe()
+	nodeEndpoints := nodeListenEndpoints(cfg)
+	nodeListeners, err := listenEndpoints(nodeEndpoints, scope.SubScope("node-listener"))
 	if err != nil {
-		logger.Fatalf("could not open tchannelthrift interface on %s: %v",
-			cfg.ListenAddress, err)
+		logger.Fatalf("could not open tchannelthrift interface: %v", err)
+	}
+	tchannelthriftNodeClose, err := ttnode.NewServerWithListeners(db,
+		nodeListeners, contextPool, tchannelOpts, ttopts).ListenAndServe()
+	if err != nil {
+		logger.Fatalf("could not open tchannelthrift interface: %v", err)
 	}
 	defer tchannelthriftNodeClose()
-	logger.Infof("node tchannelthrift: listening on %v", cfg.ListenAddress)
+	for _, endpoint := range nodeEndpoints {
+		logger.Infof("node tchannelthrift: listening on %v, tls=%v",
+			endpoint.Address, endpoint.TLS != nil)
+	}
 
 	tchannelthriftClusterClose, err := ttcluster.NewServer(m3dbClient,
 		cfg.ClusterListenAddress, contextPool, tchannelOpts).ListenAndServe()
@@ -545,6 +627,83 @@ func Run(runOpts RunOptions) {
 	defer httpjsonClusterClose()
 	logger.Infof("cluster httpjson: listening on %v", cfg.HTTPClusterListenAddress)
 
+	if promCfg := cfg.PrometheusRemote; promCfg != nil {
+		promOpts := promremote.NewOptions().
+			SetNamespace(ident.StringID(promCfg.Namespace)).
+			SetInstrumentOptions(iopts.SetMetricsScope(iopts.MetricsScope().SubScope("prom-remote")))
+		if promCfg.FetchSeriesMax > 0 {
+			promOpts = promOpts.SetFetchSeriesMax(promCfg.FetchSeriesMax)
+		}
+		promServer, err := promremote.NewServer(db, promCfg.ListenAddress,
+			contextPool, promOpts)
+		if err != nil {
+			logger.Fatalf("could not create prometheus remote interface: %v", err)
+		}
+		promRemoteClose, err := promServer.ListenAndServe()
+		if err != nil {
+			logger.Fatalf("could not open prometheus remote interface on %s: %v",
+				promCfg.ListenAddress, err)
+		}
+		defer promRemoteClose()
+		logger.Infof("prometheus remote: listening on %v, namespace=%s",
+			promCfg.ListenAddress, promCfg.Namespace)
+	}
+
+	if influxCfg := cfg.InfluxDB; influxCfg != nil {
+		influxOpts := influxdb.NewOptions().
+			SetNamespace(ident.StringID(influxCfg.Namespace)).
+			SetClockOptions(opts.ClockOptions()).
+			SetInstrumentOptions(iopts.SetMetricsScope(iopts.MetricsScope().SubScope("influxdb")))
+		if influxCfg.MaxRequestBytes > 0 {
+			influxOpts = influxOpts.SetMaxRequestBytes(influxCfg.MaxRequestBytes)
+		}
+		influxServer, err := influxdb.NewServer(db, influxCfg.ListenAddress,
+			contextPool, influxOpts)
+		if err != nil {
+			logger.Fatalf("could not create influxdb interface: %v", err)
+		}
+		influxClose, err := influxServer.ListenAndServe()
+		if err != nil {
+			logger.Fatalf("could not open influxdb interface on %s: %v",
+				influxCfg.ListenAddress, err)
+		}
+		defer influxClose()
+		logger.Infof("influxdb: listening on %v, namespace=%s",
+			influxCfg.ListenAddress, influxCfg.Namespace)
+	}
+
+	if carbonCfg := cfg.Carbon; carbonCfg != nil {
+		rules := make([]carbon.Rule, 0, len(carbonCfg.Rules))
+		for _, ruleCfg := range carbonCfg.Rules {
+			rule, err := ruleCfg.NewRule()
+			if err != nil {
+				logger.Fatalf("could not create carbon rule: %v", err)
+			}
+			rules = append(rules, rule)
+		}
+		carbonOpts := carbon.NewOptions().
+			SetNamespace(ident.StringID(carbonCfg.Namespace)).
+			SetRules(rules).
+			SetClockOptions(opts.ClockOptions()).
+			SetInstrumentOptions(iopts.SetMetricsScope(iopts.MetricsScope().SubScope("carbon")))
+		if carbonCfg.MaxConnections > 0 {
+			carbonOpts = carbonOpts.SetMaxConnections(carbonCfg.MaxConnections)
+		}
+		carbonServer, err := carbon.NewServer(db, carbonCfg.ListenAddress,
+			contextPool, carbonOpts)
+		if err != nil {
+			logger.Fatalf("could not create carbon interface: %v", err)
+		}
+		carbonClose, err := carbonServer.ListenAndServe()
+		if err != nil {
+			logger.Fatalf("could not open carbon interface on %s: %v",
+				carbonCfg.ListenAddress, err)
+		}
+		defer carbonClose()
+		logger.Infof("carbon: listening on %v, namespace=%s",
+			carbonCfg.ListenAddress, carbonCfg.Namespace)
+	}
+
 	if cfg.DebugListenAddress != "" {
 		go func() {
 			if err := http.ListenAndServe(cfg.DebugListenAddress, nil); err != nil {
@@ -589,9 +748,16 @@ func Run(runOpts RunOptions) {
 
 	logger.Warnf("interrupt: %v", interruptErr)
 
+	if cfg.ShutdownFlushEnabled {
+		shutdownFlush(db, cfg.ShutdownFlushTimeout, interruptCh, logger)
+	}
+
 	// Attempt graceful server close
 	closedCh := make(chan struct{})
 	go func() {
+		if kvMaintainer != nil {
+			kvMaintainer.Stop()
+		}
 		err := db.Terminate()
 		if err != nil {
 			logger.Errorf("close database error: %v", err)
@@ -609,6 +775,87 @@ func Run(runOpts RunOptions) {
 	}
 }
 
+// shutdownFlush snapshots the database before it is closed so that it can be
+// bootstrapped quickly once restarted, giving up after the timeout or once the
+// server is interrupted again.
+func shutdownFlush(
+	db storage.Database,
+	timeout time.Duration,
+	interruptCh <-chan error,
+	logger xlog.Logger,
+) {
+	if timeout <= 0 {
+		timeout = defaultShutdownFlushTimeout
+	}
+
+	logger.Infof("shutdown flush starting, waiting up to %s", timeout.String())
+
+	var (
+		start    = time.Now()
+		abortCh  = make(chan struct{})
+		resultCh = make(chan error, 1)
+	)
+	go func() {
+		resultCh <- db.ShutdownSnapshot(start.Add(timeout), abortCh)
+	}()
+
+	select {
+	case err := <-resultCh:
+		if err != nil {
+			logger.Errorf("shutdown flush error: %v", err)
+			return
+		}
+		logger.Infof("shutdown flush completed, took %s", time.Since(start).String())
+		return
+	case err := <-interruptCh:
+		logger.Warnf("shutdown flush aborted by interrupt: %v", err)
+	case sig := <-interrupt():
+		logger.Warnf("shutdown flush aborted by interrupt: %v", sig)
+	case <-time.After(timeout):
+		logger.Errorf("shutdown flush timed out after %s", timeout.String())
+	}
+
+	// NB: the abort is only checked between shards, wait for the shard being
+	// snapshotted so that the database is not closed while it's in progress.
+	close(abortCh)
+	select {
+	case <-resultCh:
+		logger.Infof("shutdown flush stopped, took %s", time.Since(start).String())
+	case <-time.After(shutdownFlushAbortTimeout):
+		logger.Errorf("shutdown flush did not stop after %s", shutdownFlushAbortTimeout.String())
+	}
+}
+
+// reloadConfigOnHangup reloads the configuration each time the process receives
+// SIGHUP until the returned function is called.
+func reloadConfigOnHangup(reloader *configReloader, logger xlog.Logger) func() {
+	hangupCh := make(chan os.Signal, 1)
+	signal.Notify(hangupCh, syscall.SIGHUP)
+
+	doneCh := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-doneCh:
+				return
+			case <-hangupCh:
+			}
+
+			applied, err := reloader.Reload()
+			if err != nil {
+				logger.Errorf("could not reload config, keeping current options: %v", err)
+				continue
+			}
+			logger.Infof("reloaded config, applied changed fields: %v", applied)
+		}
+	}()
+
+	return func() {
+		signal.Stop(hangupCh)
+		close(doneCh)
+	}
+}
+
 func interrupt() <-chan os.Signal {
 	c := make(chan os.Signal)
 	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
@@ -622,54 +869,42 @@ func kvWatchNewSeriesLimitPerShard(
 	runtimeOptsMgr m3dbruntime.OptionsManager,
 	defaultClusterNewSeriesLimit int,
 ) {
-	var initClusterLimit int
-
-	value, err := store.Get(kvconfig.ClusterNewSeriesInsertLimitKey)
-	if err == nil {
-		protoValue := &commonpb.Int64Proto{}
-		err = value.Unmarshal(protoValue)
-		if err == nil {
-			initClusterLimit = int(protoValue.Value)
-		}
+	var (
+		lock           sync.Mutex
+		clusterLimit   = defaultClusterNewSeriesLimit
+		clusterBacklog = defaultClusterNewSeriesBacklog
+	)
+	setLimit := func(value int) error {
+		lock.Lock()
+		defer lock.Unlock()
+		clusterLimit = value
+		return setNewSeriesLimitPerShardOnChange(topo, runtimeOptsMgr,
+			clusterLimit, clusterBacklog)
 	}
-
-	if err != nil {
-		if err != kv.ErrNotFound {
-			logger.Warnf("error resolving cluster new series insert limit: %v", err)
-		}
-		initClusterLimit = defaultClusterNewSeriesLimit
+	setBacklog := func(value int) error {
+		lock.Lock()
+		defer lock.Unlock()
+		clusterBacklog = value
+		return setNewSeriesLimitPerShardOnChange(topo, runtimeOptsMgr,
+			clusterLimit, clusterBacklog)
 	}
 
-	err = setNewSeriesLimitPerShardOnChange(topo, runtimeOptsMgr, initClusterLimit)
-	if err != nil {
+	if err := setLimit(defaultClusterNewSeriesLimit); err != nil {
 		logger.Warnf("unable to set cluster new series insert limit: %v", err)
 	}
 
-	watch, err := store.Watch(kvconfig.ClusterNewSeriesInsertLimitKey)
-	if err != nil {
-		logger.Errorf("could not watch cluster new series insert limit: %v", err)
-		return
-	}
-
-	go func() {
-		protoValue := &commonpb.Int64Proto{}
-		for range watch.C() {
-			value := defaultClusterNewSeriesLimit
-			if newValue := watch.Get(); newValue != nil {
-				if err := newValue.Unmarshal(protoValue); err != nil {
-					logger.Warnf("unable to parse new cluster new series insert limit: %v", err)
-					continue
-				}
-				value = int(protoValue.Value)
-			}
-
-			err = setNewSeriesLimitPerShardOnChange(topo, runtimeOptsMgr, value)
-			if err != nil {
-				logger.Warnf("unable to set cluster new series insert limit: %v", err)
-				continue
-			}
-		}
-	}()
+	kvWatchIntValue(store, logger,
+		kvconfig.ClusterNewSeriesInsertLimitKey,
+		setLimit,
+		func() error {
+			return setLimit(defaultClusterNewSeriesLimit)
+		})
+	kvWatchIntValue(store, logger,
+		kvconfig.ClusterNewSeriesInsertBacklogKey,
+		setBacklog,
+		func() error {
+			return setBacklog(defaultClusterNewSeriesBacklog)
+		})
 }
 
 func kvWatchClientConsistencyLevels(
@@ -695,13 +930,12 @@ func kvWatchClientConsistencyLevels(
 		v string,
 		applyFn func(topology.ConsistencyLevel, m3dbruntime.Options) m3dbruntime.Options,
 	) error {
-		for _, level := range topology.ValidConsistencyLevels() {
-			if level.String() == v {
-				runtimeOpts := applyFn(level, runtimeOptsMgr.Get())
-				return runtimeOptsMgr.Update(runtimeOpts)
-			}
+		level, err := parseConsistencyLevel(v)
+		if err != nil {
+			return err
 		}
-		return fmt.Errorf("invalid consistency level set: %s", v)
+		runtimeOpts := applyFn(level, runtimeOptsMgr.Get())
+		return runtimeOptsMgr.Update(runtimeOpts)
 	}
 
 	kvWatchStringValue(store, logger,
@@ -744,75 +978,274 @@ func kvWatchClientConsistencyLevels(
 		})
 }
 
-func kvWatchStringValue(
+func kvWatchTickOptions(
 	store kv.Store,
 	logger xlog.Logger,
-	key string,
-	onValue func(value string) error,
-	onDelete func() error,
+	scope tally.Scope,
+	runtimeOptsMgr m3dbruntime.OptionsManager,
 ) {
-	protoValue := &commonpb.StringProto{}
-
-	// First try to eagerly set the value so it doesn't flap if the
-	// watch returns but not immediately for an existing value
-	value, err := store.Get(key)
-	if err != nil && err != kv.ErrNotFound {
-		logger.Errorf("could not resolve KV key %s: %v", key, err)
-	}
-	if err == nil {
-		if err := value.Unmarshal(protoValue); err != nil {
-			logger.Errorf("could not unmarshal KV key %s: %v", key, err)
-		} else if err := onValue(protoValue.Value); err != nil {
-			logger.Errorf("could not process value of KV key %s: %v", key, err)
-		} else {
-			logger.Infof("set KV key %s: %v", key, protoValue.Value)
-		}
-	}
+	// Deleting a key reverts to the values resolved from the config file.
+	var (
+		defaultOpts      = runtimeOptsMgr.Get()
+		defaultInterval  = defaultOpts.TickMinimumInterval()
+		defaultBatchSize = defaultOpts.TickSeriesBatchSize()
+		defaultPacing    = defaultOpts.TickAdaptivePacing()
+	)
 
-	watch, err := store.Watch(key)
-	if err != nil {
-		logger.Errorf("could not watch KV key %s: %v", key, err)
-		return
+	setInterval := func(value time.Duration) error {
+		return updateRuntimeOptions(runtimeOptsMgr,
+			func(opts m3dbruntime.Options) m3dbruntime.Options {
+				return opts.SetTickMinimumInterval(value)
+			})
+	}
+	setBatchSize := func(value int) error {
+		return updateRuntimeOptions(runtimeOptsMgr,
+			func(opts m3dbruntime.Options) m3dbruntime.Options {
+				return opts.SetTickSeriesBatchSize(value)
+			})
+	}
+	setPacingEnabled := func(value bool) error {
+		return updateRuntimeOptions(runtimeOptsMgr,
+			func(opts m3dbruntime.Options) m3dbruntime.Options {
+				pacing := opts.TickAdaptivePacing()
+				pacing.Enabled = value
+				return opts.SetTickAdaptivePacing(pacing)
+			})
 	}
 
-	go func() {
-		for range watch.C() {
-			newValue := watch.Get()
-			if newValue == nil {
-				if err := onDelete(); err != nil {
-					logger.Warnf("could not set default for KV key %s: %v", key, err)
-				}
-				continue
+	runtimeOptsMgr.RegisterListener(newTickOptionsReporter(scope))
+
+	kvWatchDurationValue(store, logger,
+		kvconfig.TickMinimumInterval,
+		func(interval time.Duration) error {
+			if interval < minKVTickMinimumInterval || interval > maxKVTickMinimumInterval {
+				return fmt.Errorf("tick minimum interval %s is not between %s and %s",
+					interval.String(), minKVTickMinimumInterval.String(),
+					maxKVTickMinimumInterval.String())
+			}
+			return setInterval(interval)
+		},
+		func() error {
+			return setInterval(defaultInterval)
+		})
+
+	kvWatchIntValue(store, logger,
+		kvconfig.TickSeriesBatchSize,
+		func(value int) error {
+			if value < minKVTickSeriesBatchSize || value > maxKVTickSeriesBatchSize {
+				return fmt.Errorf("tick series batch size %d is not between %d and %d",
+					value, minKVTickSeriesBatchSize, maxKVTickSeriesBatchSize)
 			}
+			return setBatchSize(value)
+		},
+		func() error {
+			return setBatchSize(defaultBatchSize)
+		})
+
+	kvWatchBoolValue(store, logger,
+		kvconfig.TickAdaptivePacingEnabled,
+		setPacingEnabled,
+		func() error {
+			return setPacingEnabled(defaultPacing.Enabled)
+		})
+}
+
+func kvWatchRepairOptions(
+	store kv.Store,
+	logger xlog.Logger,
+	runtimeOptsMgr m3dbruntime.OptionsManager,
+) {
+	// Deleting a key reverts to the values resolved from the config file.
+	var (
+		defaultOpts     = runtimeOptsMgr.Get()
+		defaultEnabled  = defaultOpts.RepairEnabled()
+		defaultThrottle = defaultOpts.RepairThrottle()
+	)
+
+	setEnabled := func(value bool) error {
+		return updateRuntimeOptions(runtimeOptsMgr,
+			func(opts m3dbruntime.Options) m3dbruntime.Options {
+				return opts.SetRepairEnabled(value)
+			})
+	}
+	setThrottle := func(value time.Duration) error {
+		return updateRuntimeOptions(runtimeOptsMgr,
+			func(opts m3dbruntime.Options) m3dbruntime.Options {
+				return opts.SetRepairThrottle(value)
+			})
+	}
+
+	kvWatchBoolValue(store, logger,
+		kvconfig.RepairEnabled,
+		setEnabled,
+		func() error {
+			return setEnabled(defaultEnabled)
+		})
+
+	kvWatchDurationValue(store, logger,
+		kvconfig.RepairThrottle,
+		setThrottle,
+		func() error {
+			return setThrottle(defaultThrottle)
+		})
+}
+
+func kvWatchCommitLogQueueOptions(
+	store kv.Store,
+	logger xlog.Logger,
+	runtimeOptsMgr m3dbruntime.OptionsManager,
+) {
+	// Deleting a key reverts to the values resolved from the config file.
+	var (
+		defaultOpts   = runtimeOptsMgr.Get()
+		defaultPolicy = defaultOpts.CommitLogQueuePolicy()
+		defaultLimit  = defaultOpts.CommitLogQueueLimit()
+	)
+
+	setPolicy := func(value m3dbruntime.CommitLogQueuePolicy) error {
+		return updateRuntimeOptions(runtimeOptsMgr,
+			func(opts m3dbruntime.Options) m3dbruntime.Options {
+				return opts.SetCommitLogQueuePolicy(value)
+			})
+	}
+	setLimit := func(value int) error {
+		return updateRuntimeOptions(runtimeOptsMgr,
+			func(opts m3dbruntime.Options) m3dbruntime.Options {
+				return opts.SetCommitLogQueueLimit(value)
+			})
+	}
 
-			err := newValue.Unmarshal(protoValue)
+	kvWatchStringValue(store, logger,
+		kvconfig.CommitLogQueuePolicy,
+		func(value string) error {
+			policy, err := m3dbruntime.ParseCommitLogQueuePolicy(value)
 			if err != nil {
-				logger.Warnf("could not unmarshal KV key %s: %v", key, err)
-				continue
+				return err
 			}
-			if err := onValue(protoValue.Value); err != nil {
-				logger.Warnf("could not process change for KV key %s: %v", key, err)
-				continue
+			return setPolicy(policy)
+		},
+		func() error {
+			return setPolicy(defaultPolicy)
+		})
+
+	kvWatchIntValue(store, logger,
+		kvconfig.CommitLogQueueLimit,
+		setLimit,
+		func() error {
+			return setLimit(defaultLimit)
+		})
+}
+
+func kvWatchWriteShadowingRules(
+	store kv.Store,
+	logger xlog.Logger,
+	runtimeOptsMgr m3dbruntime.OptionsManager,
+) {
+	// Deleting the key reverts to the rules resolved from the config file.
+	defaultRules := runtimeOptsMgr.Get().WriteShadowingRules()
+
+	setRules := func(value []m3dbruntime.WriteShadowingRule) error {
+		return updateRuntimeOptions(runtimeOptsMgr,
+			func(opts m3dbruntime.Options) m3dbruntime.Options {
+				return opts.SetWriteShadowingRules(value)
+			})
+	}
+
+	kvWatchStringValue(store, logger,
+		kvconfig.WriteShadowingRules,
+		func(value string) error {
+			var rules []m3dbruntime.WriteShadowingRule
+			if err := json.Unmarshal([]byte(value), &rules); err != nil {
+				return fmt.Errorf("could not parse write shadowing rules: %v", err)
 			}
-			logger.Infof("set KV key %s: %v", key, protoValue.Value)
-		}
-	}()
+			return setRules(rules)
+		},
+		func() error {
+			return setRules(defaultRules)
+		})
+}
+
+// kvRuntimeOptionsUpdateLock serializes the updates of the runtime options made by
+// the KV watches, which run concurrently and would otherwise be able to overwrite
+// the value just set by another watch.
+var kvRuntimeOptionsUpdateLock sync.Mutex
+
+func kvWatchReadOnly(
+	store kv.Store,
+	logger xlog.Logger,
+	hostID string,
+	runtimeOptsMgr m3dbruntime.OptionsManager,
+) {
+	// Deleting the key reverts to the value resolved from the config file.
+	defaultReadOnly := runtimeOptsMgr.Get().ReadOnly()
+
+	setReadOnly := func(value bool) error {
+		return updateRuntimeOptions(runtimeOptsMgr,
+			func(opts m3dbruntime.Options) m3dbruntime.Options {
+				return opts.SetReadOnly(value)
+			})
+	}
+
+	kvWatchBoolValue(store, logger,
+		kvconfig.NodeReadOnlyPrefix+hostID,
+		setReadOnly,
+		func() error {
+			return setReadOnly(defaultReadOnly)
+		})
+}
+
+func updateRuntimeOptions(
+	runtimeOptsMgr m3dbruntime.OptionsManager,
+	fn func(opts m3dbruntime.Options) m3dbruntime.Options,
+) error {
+	kvRuntimeOptionsUpdateLock.Lock()
+	defer kvRuntimeOptionsUpdateLock.Unlock()
+	return runtimeOptsMgr.Update(fn(runtimeOptsMgr.Get()))
+}
+
+// tickOptionsReporter reports the tick runtime options currently applied.
+type tickOptionsReporter struct {
+	minimumInterval       tally.Gauge
+	seriesBatchSize       tally.Gauge
+	adaptivePacingEnabled tally.Gauge
+}
+
+func newTickOptionsReporter(scope tally.Scope) *tickOptionsReporter {
+	return &tickOptionsReporter{
+		minimumInterval:       scope.Gauge("tick-minimum-interval-seconds"),
+		seriesBatchSize:       scope.Gauge("tick-series-batch-size"),
+		adaptivePacingEnabled: scope.Gauge("tick-adaptive-pacing-enabled"),
+	}
+}
+
+func (r *tickOptionsReporter) SetRuntimeOptions(value m3dbruntime.Options) {
+	r.minimumInterval.Update(value.TickMinimumInterval().Seconds())
+	r.seriesBatchSize.Update(float64(value.TickSeriesBatchSize()))
+	var adaptivePacingEnabled float64
+	if value.TickAdaptivePacing().Enabled {
+		adaptivePacingEnabled = 1
+	}
+	r.adaptivePacingEnabled.Update(adaptivePacingEnabled)
 }
 
 func setNewSeriesLimitPerShardOnChange(
 	topo topology.Topology,
 	runtimeOptsMgr m3dbruntime.OptionsManager,
 	clusterLimit int,
+	clusterBacklog int,
 ) error {
 	perPlacedShardLimit := clusterLimitToPlacedShardLimit(topo, clusterLimit)
+	perPlacedShardBacklog := clusterLimitToPlacedShardLimit(topo, clusterBacklog)
 	runtimeOpts := runtimeOptsMgr.Get()
-	if runtimeOpts.WriteNewSeriesLimitPerShardPerSecond() == perPlacedShardLimit {
+	if runtimeOpts.WriteNewSeriesLimitPerShardPerSecond() == perPlacedShardLimit &&
+		runtimeOpts.WriteNewSeriesBacklogPerShard() == perPlacedShardBacklog {
 		// Not changed, no need to set the value and trigger a runtime options update
 		return nil
 	}
 
 	newRuntimeOpts := runtimeOpts.
-		SetWriteNewSeriesLimitPerShardPerSecond(perPlacedShardLimit)
+		SetWriteNewSeriesLimitPerShardPerSecond(perPlacedShardLimit).
+		SetWriteNewSeriesBacklogPerShard(perPlacedShardBacklog)
 	return runtimeOptsMgr.Update(newRuntimeOpts)
 }
 
@@ -850,16 +1283,19 @@ func kvWatchBootstrappers(
 
 	var initialized bool
 	go func() {
-		opts := util.NewOptions().SetLogger(logger)
-
+		var (
+			opts = util.NewOptions().SetLogger(logger)
+			log  = newKVWatchLogger(logger, kvconfig.BootstrapperKey)
+		)
 		for range vw.C() {
-			v, err := util.StringArrayFromValue(vw.Get(),
+			value := vw.Get()
+			v, err := util.StringArrayFromValue(value,
 				kvconfig.BootstrapperKey, defaultBootstrappers, opts)
 			if err != nil {
-				logger.WithFields(
-					xlog.NewField("key", kvconfig.BootstrapperKey),
-					xlog.NewErrField(err),
-				).Error("error converting KV update to string array")
+				log.Error("error converting KV update to string array",
+					xlog.NewField("version", value.Version()),
+					xlog.NewField("value", kvLogValuePreview(value)),
+					xlog.NewErrField(err))
 				continue
 			}
 
@@ -959,13 +1395,28 @@ func withEncodingAndPoolingOptions(
 	encodingOpts := encoding.NewOptions().
 		SetEncoderPool(encoderPool).
 		SetReaderIteratorPool(iteratorPool).
-		SetBytesPool(bytesPool).
+		SetBytesPool(encoderPool.AccountedBytesPool(bytesPool)).
 		SetSegmentReaderPool(segmentReaderPool)
 
 	encoderPool.Init(func() encoding.Encoder {
 		return m3tsz.NewEncoder(time.Time{}, nil, m3tsz.DefaultIntOptimizationEnabled, encodingOpts)
 	})
 
+	// Give each namespace its own encoder pool reporting to a scope tagged
+	// with the namespace so that the buffer memory it pins is attributable,
+	// the encoders share the bytes pool so only the encoders are duplicated.
+	newNamespaceEncoderPool := func(iopts instrument.Options) encoding.EncoderPool {
+		nsEncoderPool := encoding.NewEncoderPool(
+			poolOptions(policy.EncoderPool, iopts.MetricsScope()))
+		nsEncodingOpts := encodingOpts.
+			SetEncoderPool(nsEncoderPool).
+			SetBytesPool(nsEncoderPool.AccountedBytesPool(bytesPool))
+		nsEncoderPool.Init(func() encoding.Encoder {
+			return m3tsz.NewEncoder(time.Time{}, nil, m3tsz.DefaultIntOptimizationEnabled, nsEncodingOpts)
+		})
+		return nsEncoderPool
+	}
+
 	iteratorPool.Init(func(r io.Reader) encoding.ReaderIterator {
 		return m3tsz.NewReaderIterator(r, m3tsz.DefaultIntOptimizationEnabled, encodingOpts)
 	})
@@ -980,6 +1431,7 @@ func withEncodingAndPoolingOptions(
 		SetBytesPool(bytesPool).
 		SetContextPool(contextPool).
 		SetEncoderPool(encoderPool).
+		SetNamespaceEncoderPoolFn(newNamespaceEncoderPool).
 		SetReaderIteratorPool(iteratorPool).
 		SetMultiReaderIteratorPool(multiIteratorPool).
 		SetIdentifierPool(identifierPool).
@@ -0,0 +1,262 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/kvconfig"
+	m3dbruntime "github.com/m3db/m3/src/dbnode/runtime"
+	"github.com/m3db/m3/src/dbnode/sharding"
+	"github.com/m3db/m3/src/dbnode/storage"
+	"github.com/m3db/m3/src/dbnode/topology"
+	"github.com/m3db/m3cluster/generated/proto/commonpb"
+	m3clusterkvmem "github.com/m3db/m3cluster/kv/mem"
+	"github.com/m3db/m3cluster/shard"
+	xclock "github.com/m3db/m3x/clock"
+	xlog "github.com/m3db/m3x/log"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func TestKVWatchTickOptions(t *testing.T) {
+	store := m3clusterkvmem.NewStore()
+	_, err := store.Set(kvconfig.TickSeriesBatchSize, &commonpb.Int64Proto{Value: 1024})
+	require.NoError(t, err)
+
+	runtimeOptsMgr := m3dbruntime.NewOptionsManager()
+	defaultOpts := runtimeOptsMgr.Get()
+	scope := tally.NewTestScope("", nil)
+	kvWatchTickOptions(store, xlog.NullLogger, scope, runtimeOptsMgr)
+
+	// The existing value is applied eagerly.
+	require.Equal(t, 1024, runtimeOptsMgr.Get().TickSeriesBatchSize())
+
+	waitUntil := func(fn func(opts m3dbruntime.Options) bool) {
+		require.True(t, xclock.WaitUntil(func() bool {
+			return fn(runtimeOptsMgr.Get())
+		}, 5*time.Second))
+	}
+	waitUntilGauge := func(name string, value float64) {
+		require.True(t, xclock.WaitUntil(func() bool {
+			gauge, ok := scope.Snapshot().Gauges()[name+"+"]
+			return ok && gauge.Value() == value
+		}, 5*time.Second))
+	}
+	waitUntilGauge("tick-series-batch-size", 1024)
+
+	_, err = store.Set(kvconfig.TickMinimumInterval, &commonpb.StringProto{Value: "30s"})
+	require.NoError(t, err)
+	waitUntil(func(opts m3dbruntime.Options) bool {
+		return opts.TickMinimumInterval() == 30*time.Second
+	})
+	waitUntilGauge("tick-minimum-interval-seconds", 30)
+
+	// Values outside of the bounds are ignored.
+	_, err = store.Set(kvconfig.TickMinimumInterval, &commonpb.StringProto{Value: "1h"})
+	require.NoError(t, err)
+	_, err = store.Set(kvconfig.TickSeriesBatchSize, &commonpb.Int64Proto{Value: 8})
+	require.NoError(t, err)
+	_, err = store.Set(kvconfig.TickSeriesBatchSize, &commonpb.Int64Proto{Value: 2048})
+	require.NoError(t, err)
+	waitUntil(func(opts m3dbruntime.Options) bool {
+		return opts.TickSeriesBatchSize() == 2048
+	})
+	require.Equal(t, 30*time.Second, runtimeOptsMgr.Get().TickMinimumInterval())
+
+	// Deleting the keys reverts to the defaults.
+	_, err = store.Delete(kvconfig.TickMinimumInterval)
+	require.NoError(t, err)
+	_, err = store.Delete(kvconfig.TickSeriesBatchSize)
+	require.NoError(t, err)
+	waitUntil(func(opts m3dbruntime.Options) bool {
+		return opts.TickMinimumInterval() == defaultOpts.TickMinimumInterval() &&
+			opts.TickSeriesBatchSize() == defaultOpts.TickSeriesBatchSize()
+	})
+
+	// Adaptive pacing can be toggled keeping its configured bounds.
+	_, err = store.Set(kvconfig.TickAdaptivePacingEnabled, &commonpb.BoolProto{Value: true})
+	require.NoError(t, err)
+	waitUntil(func(opts m3dbruntime.Options) bool {
+		return opts.TickAdaptivePacing().Enabled
+	})
+	waitUntilGauge("tick-adaptive-pacing-enabled", 1)
+	pacing := runtimeOptsMgr.Get().TickAdaptivePacing()
+	pacing.Enabled = false
+	require.Equal(t, defaultOpts.TickAdaptivePacing(), pacing)
+
+	_, err = store.Delete(kvconfig.TickAdaptivePacingEnabled)
+	require.NoError(t, err)
+	waitUntil(func(opts m3dbruntime.Options) bool {
+		return !opts.TickAdaptivePacing().Enabled
+	})
+	waitUntilGauge("tick-adaptive-pacing-enabled", 0)
+}
+
+func TestKVWatchRepairOptions(t *testing.T) {
+	store := m3clusterkvmem.NewStore()
+	_, err := store.Set(kvconfig.RepairThrottle, &commonpb.StringProto{Value: "10s"})
+	require.NoError(t, err)
+
+	runtimeOptsMgr := m3dbruntime.NewOptionsManager()
+	require.NoError(t, runtimeOptsMgr.Update(runtimeOptsMgr.Get().
+		SetRepairEnabled(true).
+		SetRepairThrottle(time.Minute)))
+	kvWatchRepairOptions(store, xlog.NullLogger, runtimeOptsMgr)
+
+	// The existing value is applied eagerly.
+	require.Equal(t, 10*time.Second, runtimeOptsMgr.Get().RepairThrottle())
+
+	waitUntil := func(fn func(opts m3dbruntime.Options) bool) {
+		require.True(t, xclock.WaitUntil(func() bool {
+			return fn(runtimeOptsMgr.Get())
+		}, 5*time.Second))
+	}
+
+	_, err = store.Set(kvconfig.RepairEnabled, &commonpb.BoolProto{Value: false})
+	require.NoError(t, err)
+	waitUntil(func(opts m3dbruntime.Options) bool {
+		return !opts.RepairEnabled()
+	})
+
+	// Invalid throttles are ignored.
+	_, err = store.Set(kvconfig.RepairThrottle, &commonpb.StringProto{Value: "-1s"})
+	require.NoError(t, err)
+	_, err = store.Set(kvconfig.RepairThrottle, &commonpb.StringProto{Value: "0s"})
+	require.NoError(t, err)
+	waitUntil(func(opts m3dbruntime.Options) bool {
+		return opts.RepairThrottle() == 0
+	})
+	require.False(t, runtimeOptsMgr.Get().RepairEnabled())
+
+	// Deleting the keys reverts to the values from the config.
+	_, err = store.Delete(kvconfig.RepairEnabled)
+	require.NoError(t, err)
+	_, err = store.Delete(kvconfig.RepairThrottle)
+	require.NoError(t, err)
+	waitUntil(func(opts m3dbruntime.Options) bool {
+		return opts.RepairEnabled() && opts.RepairThrottle() == time.Minute
+	})
+}
+
+func TestKVWatchReadOnly(t *testing.T) {
+	store := m3clusterkvmem.NewStore()
+	_, err := store.Set(kvconfig.NodeReadOnlyPrefix+"other", &commonpb.BoolProto{Value: false})
+	require.NoError(t, err)
+
+	runtimeOptsMgr := m3dbruntime.NewOptionsManager()
+	require.NoError(t, runtimeOptsMgr.Update(runtimeOptsMgr.Get().SetReadOnly(true)))
+	kvWatchReadOnly(store, xlog.NullLogger, "host", runtimeOptsMgr)
+
+	// The keys of other hosts are ignored.
+	require.True(t, runtimeOptsMgr.Get().ReadOnly())
+
+	waitUntil := func(readOnly bool) {
+		require.True(t, xclock.WaitUntil(func() bool {
+			return runtimeOptsMgr.Get().ReadOnly() == readOnly
+		}, 5*time.Second))
+	}
+
+	_, err = store.Set(kvconfig.NodeReadOnlyPrefix+"host", &commonpb.BoolProto{Value: false})
+	require.NoError(t, err)
+	waitUntil(false)
+
+	// Deleting the key reverts to the value from the config.
+	_, err = store.Delete(kvconfig.NodeReadOnlyPrefix + "host")
+	require.NoError(t, err)
+	waitUntil(true)
+}
+
+func TestKVWatchNewSeriesLimitPerShard(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// 4 shards with 2 replicas makes 8 placed shards.
+	shardSet, err := sharding.NewShardSet(sharding.NewShards([]uint32{0, 1, 2, 3},
+		shard.Available), sharding.DefaultHashFn(4))
+	require.NoError(t, err)
+	topoMap := topology.NewMockMap(ctrl)
+	topoMap.EXPECT().ShardSet().Return(shardSet).AnyTimes()
+	topoMap.EXPECT().Replicas().Return(2).AnyTimes()
+	topo := topology.NewMockTopology(ctrl)
+	topo.EXPECT().Get().Return(topoMap).AnyTimes()
+
+	store := m3clusterkvmem.NewStore()
+	_, err = store.Set(kvconfig.ClusterNewSeriesInsertBacklogKey, &commonpb.Int64Proto{Value: 800})
+	require.NoError(t, err)
+
+	runtimeOptsMgr := m3dbruntime.NewOptionsManager()
+	kvWatchNewSeriesLimitPerShard(store, xlog.NullLogger, topo, runtimeOptsMgr, 80)
+
+	// The default limit and existing backlog are applied eagerly.
+	require.Equal(t, 10, runtimeOptsMgr.Get().WriteNewSeriesLimitPerShardPerSecond())
+	require.Equal(t, 100, runtimeOptsMgr.Get().WriteNewSeriesBacklogPerShard())
+
+	waitUntil := func(fn func(opts m3dbruntime.Options) bool) {
+		require.True(t, xclock.WaitUntil(func() bool {
+			return fn(runtimeOptsMgr.Get())
+		}, 5*time.Second))
+	}
+
+	// Updating the limit keeps the backlog.
+	_, err = store.Set(kvconfig.ClusterNewSeriesInsertLimitKey, &commonpb.Int64Proto{Value: 160})
+	require.NoError(t, err)
+	waitUntil(func(opts m3dbruntime.Options) bool {
+		return opts.WriteNewSeriesLimitPerShardPerSecond() == 20 &&
+			opts.WriteNewSeriesBacklogPerShard() == 100
+	})
+
+	// Deleting the backlog reverts to rejecting inserts over the limit.
+	_, err = store.Delete(kvconfig.ClusterNewSeriesInsertBacklogKey)
+	require.NoError(t, err)
+	waitUntil(func(opts m3dbruntime.Options) bool {
+		return opts.WriteNewSeriesLimitPerShardPerSecond() == 20 &&
+			opts.WriteNewSeriesBacklogPerShard() == 0
+	})
+}
+
+func TestShutdownFlushWaitsForAbortedSnapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		db          = storage.NewMockDatabase(ctrl)
+		interruptCh = make(chan error, 1)
+		stopped     int32
+	)
+	db.EXPECT().ShutdownSnapshot(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ time.Time, abortCh <-chan struct{}) error {
+			// The abort is only noticed once the current shard is snapshotted.
+			<-abortCh
+			time.Sleep(10 * time.Millisecond)
+			atomic.StoreInt32(&stopped, 1)
+			return nil
+		})
+
+	interruptCh <- errors.New("interrupted")
+	shutdownFlush(db, time.Minute, interruptCh, xlog.NullLogger)
+	require.Equal(t, int32(1), atomic.LoadInt32(&stopped))
+}
@@ -66,7 +66,8 @@ type dbBlock struct {
 	// synchronization because the WiredList is not concurrent.
 	listState listState
 
-	checksum uint32
+	checksum       uint32
+	datapointStats ts.DatapointStats
 
 	wasRetrievedFromDisk bool
 	closed               bool
@@ -186,6 +187,22 @@ func (b *dbBlock) Checksum() (uint32, error) {
 	return b.checksum, nil
 }
 
+func (b *dbBlock) DatapointStats() ts.DatapointStats {
+	b.RLock()
+	defer b.RUnlock()
+	if b.mergeTarget != nil {
+		// The stats of the merged block are unknown until it is merged
+		return ts.DatapointStats{}
+	}
+	return b.datapointStats
+}
+
+func (b *dbBlock) SetDatapointStats(stats ts.DatapointStats) {
+	b.Lock()
+	b.datapointStats = stats
+	b.Unlock()
+}
+
 func (b *dbBlock) OnRetrieveBlock(
 	id ident.ID,
 	_ ident.TagIterator,
@@ -394,6 +411,7 @@ func (b *dbBlock) resetSegmentWithLock(seg ts.Segment) {
 	b.segment = seg
 	b.length = seg.Len()
 	b.checksum = digest.SegmentChecksum(seg)
+	b.datapointStats = ts.DatapointStats{}
 
 	b.retriever = nil
 	b.retrieveID = nil
@@ -407,12 +425,40 @@ func (b *dbBlock) resetRetrievableWithLock(
 	b.segment = ts.Segment{}
 	b.length = metadata.Length
 	b.checksum = metadata.Checksum
+	b.datapointStats = ts.DatapointStats{}
 
 	b.retriever = retriever
 	b.retrieveID = metadata.ID
 	b.wasRetrievedFromDisk = false
 }
 
+func (b *dbBlock) ReplaceSegment(segment ts.Segment, expectedChecksum uint32) bool {
+	b.Lock()
+	if b.closed ||
+		b.retriever != nil ||
+		b.wasRetrievedFromDisk ||
+		b.mergeTarget != nil ||
+		b.checksum != expectedChecksum ||
+		segment.Len() >= b.length {
+		b.Unlock()
+		return false
+	}
+
+	// The segment holds the same datapoints so the stats carry over, as
+	// does the checksum so that repair keeps comparing the block equal to
+	// replicas that have not recompressed it.
+	prev, stats := b.segment, b.datapointStats
+	b.resetSegmentWithLock(segment)
+	b.checksum = expectedChecksum
+	b.datapointStats = stats
+	b.Unlock()
+
+	// Safe to finalize outside of the lock since streams always take a
+	// copy of the segment data while holding the lock.
+	prev.Finalize()
+	return true
+}
+
 func (b *dbBlock) Discard() ts.Segment {
 	seg, _ := b.closeAndDiscardConditionally(nil)
 	return seg
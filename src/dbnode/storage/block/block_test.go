@@ -30,6 +30,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/xio"
+	"github.com/m3db/m3x/checked"
 	"github.com/m3db/m3x/context"
 	xtime "github.com/m3db/m3x/time"
 
@@ -563,6 +564,83 @@ func TestDatabaseBlockCloseIfFromDisk(t *testing.T) {
 	require.True(t, blockFromDisk.CloseIfFromDisk())
 }
 
+func TestDatabaseBlockReplaceSegment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	newSegment := func(data string) ts.Segment {
+		return ts.NewSegment(checked.NewBytes([]byte(data), nil), nil, ts.FinalizeNone)
+	}
+
+	var (
+		blockOpts = NewOptions()
+		block     = NewDatabaseBlock(time.Time{}, time.Hour, newSegment("0123456789"), blockOpts).(*dbBlock)
+		checksum  = block.checksum
+	)
+
+	// Reject when the checksum no longer matches.
+	require.False(t, block.ReplaceSegment(newSegment("01234"), checksum+1))
+
+	// Reject when the new segment is not smaller.
+	require.False(t, block.ReplaceSegment(newSegment("abcdefghij"), checksum))
+
+	// Reject when a merge is pending.
+	target := NewMockDatabaseBlock(ctrl)
+	block.mergeTarget = target
+	require.False(t, block.ReplaceSegment(newSegment("01234"), checksum))
+	block.mergeTarget = nil
+
+	// Reject when the block was retrieved from disk.
+	block.wasRetrievedFromDisk = true
+	require.False(t, block.ReplaceSegment(newSegment("01234"), checksum))
+	block.wasRetrievedFromDisk = false
+
+	require.True(t, block.ReplaceSegment(newSegment("01234"), checksum))
+	require.Equal(t, 5, block.Len())
+	require.Equal(t, checksum, block.checksum)
+
+	// Reject once the block is closed.
+	block.Close()
+	require.False(t, block.ReplaceSegment(newSegment("0"), block.checksum))
+}
+
+func TestDatabaseBlockDatapointStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	newSegment := func(data string) ts.Segment {
+		return ts.NewSegment(checked.NewBytes([]byte(data), nil), nil, ts.FinalizeNone)
+	}
+
+	var (
+		start     = time.Now().Truncate(time.Hour)
+		blockOpts = NewOptions()
+		block     = NewDatabaseBlock(start, time.Hour, newSegment("0123456789"), blockOpts).(*dbBlock)
+		stats     = ts.DatapointStats{
+			FirstTimestamp: start.Add(time.Minute),
+			LastTimestamp:  start.Add(time.Hour - time.Minute),
+			Count:          42,
+		}
+	)
+	require.True(t, block.DatapointStats().IsZero())
+
+	block.SetDatapointStats(stats)
+	require.Equal(t, stats, block.DatapointStats())
+
+	// Stats are unknown while a merge is pending.
+	block.mergeTarget = NewMockDatabaseBlock(ctrl)
+	require.True(t, block.DatapointStats().IsZero())
+	block.mergeTarget = nil
+
+	// Stats carry over when the segment is replaced with an equivalent one.
+	require.True(t, block.ReplaceSegment(newSegment("01234"), block.checksum))
+	require.Equal(t, stats, block.DatapointStats())
+
+	// Stats are cleared when the block is reset with a new segment.
+	block.Reset(start, time.Hour, newSegment("0123"))
+	require.True(t, block.DatapointStats().IsZero())
+}
+
 func TestDatabaseSeriesBlocksAddBlock(t *testing.T) {
 	now := time.Now()
 	blockTimes := []time.Time{now, now.Add(time.Second), now.Add(time.Minute), now.Add(-time.Second), now.Add(-time.Hour)}
@@ -237,6 +237,7 @@ func (it *filteredBlocksMetadataIter) Next() bool {
 	}
 	it.metadata = NewMetadata(it.id, tags, block.Start,
 		block.Size, block.Checksum, block.LastRead)
+	it.metadata.Buffered = block.Buffered
 	it.blockIdx++
 	return true
 }
@@ -108,6 +108,17 @@ func (r *shardBlockRetriever) Stream(
 		blockStart, onRetrieve)
 }
 
+func (r *shardBlockRetriever) StreamWithReadAhead(
+	ctx context.Context,
+	id ident.ID,
+	blockStart time.Time,
+	readAheadEnd time.Time,
+	onRetrieve OnRetrieveBlock,
+) (xio.BlockReader, error) {
+	return r.DatabaseBlockRetriever.StreamWithReadAhead(ctx, r.shard, id,
+		blockStart, readAheadEnd, onRetrieve)
+}
+
 type shardBlockRetrieverManager struct {
 	sync.RWMutex
 	retriever       DatabaseBlockRetriever
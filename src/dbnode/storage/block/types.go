@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/ratelimit"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3/src/dbnode/topology"
 	"github.com/m3db/m3/src/dbnode/ts"
@@ -44,6 +45,7 @@ type Metadata struct {
 	Size     int64
 	Checksum *uint32
 	LastRead time.Time
+	Buffered bool
 }
 
 // ReplicaMetadata captures block metadata along with corresponding peer identifier
@@ -76,18 +78,29 @@ type FetchBlockResult struct {
 
 // FetchBlocksMetadataOptions are options used when fetching blocks metadata.
 type FetchBlocksMetadataOptions struct {
-	IncludeSizes     bool
-	IncludeChecksums bool
-	IncludeLastRead  bool
+	IncludeSizes          bool
+	IncludeChecksums      bool
+	IncludeLastRead       bool
+	IncludeDatapointStats bool
+
+	// IncludeBufferChecksums specifies whether to include checksums for
+	// blocks still in the buffer, these require merging the buffered data.
+	IncludeBufferChecksums bool
+
+	// BufferChecksumsLimiter, if set, limits the rate of series that have
+	// their buffered blocks checksummed.
+	BufferChecksumsLimiter ratelimit.Limiter
 }
 
 // FetchBlockMetadataResult captures the block start time, the block size, and any errors encountered
 type FetchBlockMetadataResult struct {
-	Start    time.Time
-	Size     int64
-	Checksum *uint32
-	LastRead time.Time
-	Err      error
+	Start          time.Time
+	Size           int64
+	Checksum       *uint32
+	LastRead       time.Time
+	DatapointStats ts.DatapointStats
+	Buffered       bool
+	Err            error
 }
 
 // FetchBlockMetadataResults captures a collection of FetchBlockMetadataResult
@@ -155,6 +168,14 @@ type DatabaseBlock interface {
 	// Checksum returns the block checksum.
 	Checksum() (uint32, error)
 
+	// DatapointStats returns the stats of the datapoints held by the block,
+	// the stats are zero if unknown or if the block has a merge target.
+	DatapointStats() ts.DatapointStats
+
+	// SetDatapointStats sets the stats of the datapoints held by the block,
+	// the stats are cleared whenever the segment of the block changes.
+	SetDatapointStats(stats ts.DatapointStats)
+
 	// Stream returns the encoded byte stream.
 	Stream(blocker context.Context) (xio.BlockReader, error)
 
@@ -191,6 +212,13 @@ type DatabaseBlock interface {
 		metadata RetrievableBlockMetadata,
 	)
 
+	// ReplaceSegment atomically swaps the block's segment for an equivalent
+	// encoding of the same datapoints if the block's checksum still matches
+	// the expected checksum and the new segment is smaller. On success the
+	// block keeps its checksum, takes ownership of the segment and finalizes
+	// the previous one, otherwise ownership remains with the caller.
+	ReplaceSegment(segment ts.Segment, expectedChecksum uint32) bool
+
 	// Discard closes the block, but returns the (unfinalized) segment.
 	Discard() ts.Segment
 
@@ -283,6 +311,18 @@ type DatabaseBlockRetriever interface {
 		blockStart time.Time,
 		onRetrieve OnRetrieveBlock,
 	) (xio.BlockReader, error)
+
+	// StreamWithReadAhead will stream a block for a given shard, id and start,
+	// hinting that the blocks following it up to and including readAheadEnd
+	// will also be read so they may be prefetched.
+	StreamWithReadAhead(
+		ctx context.Context,
+		shard uint32,
+		id ident.ID,
+		blockStart time.Time,
+		readAheadEnd time.Time,
+		onRetrieve OnRetrieveBlock,
+	) (xio.BlockReader, error)
 }
 
 // DatabaseShardBlockRetriever is a block retriever bound to a shard.
@@ -294,6 +334,17 @@ type DatabaseShardBlockRetriever interface {
 		blockStart time.Time,
 		onRetrieve OnRetrieveBlock,
 	) (xio.BlockReader, error)
+
+	// StreamWithReadAhead will stream a block for a given id and start,
+	// hinting that the blocks following it up to and including readAheadEnd
+	// will also be read so they may be prefetched.
+	StreamWithReadAhead(
+		ctx context.Context,
+		id ident.ID,
+		blockStart time.Time,
+		readAheadEnd time.Time,
+		onRetrieve OnRetrieveBlock,
+	) (xio.BlockReader, error)
 }
 
 // DatabaseBlockRetrieverManager creates and holds block retrievers
@@ -22,7 +22,9 @@ package storage
 
 import (
 	"errors"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/m3db/m3/src/dbnode/clock"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap"
@@ -116,10 +118,16 @@ func (m *bootstrapManager) Bootstrap() error {
 	m.mediator.DisableFileOps()
 	defer m.mediator.EnableFileOps()
 
-	// Keep performing bootstraps until none pending
+	progress := m.processProvider.ProcessOptions().Progress()
+	stopReporting := m.startProgressReporter(progress)
+	defer stopReporting()
+
+	// Keep performing bootstraps until none pending, each pending bootstrap
+	// is a retry that starts tracking progress afresh
 	multiErr := xerrors.NewMultiError()
-	for {
-		err := m.bootstrap()
+	for retries := 0; ; retries++ {
+		progress.Start(retries, m.nowFn())
+		err := m.bootstrap(progress)
 		if err != nil {
 			multiErr = multiErr.Add(err)
 		}
@@ -138,6 +146,7 @@ func (m *bootstrapManager) Bootstrap() error {
 			break
 		}
 	}
+	progress.Complete(m.nowFn())
 
 	// NB(xichen): in order for bootstrapped data to be flushed to disk, a tick
 	// needs to happen to drain the in-memory buffers and a consequent flush will
@@ -159,7 +168,7 @@ func (m *bootstrapManager) Report() {
 	}
 }
 
-func (m *bootstrapManager) bootstrap() error {
+func (m *bootstrapManager) bootstrap(progress *bootstrap.Progress) error {
 	// NB(r): construct new instance of the bootstrap process to avoid
 	// state being kept around by bootstrappers.
 	process, err := m.processProvider.Provide()
@@ -176,6 +185,8 @@ func (m *bootstrapManager) bootstrap() error {
 		return err
 	}
 
+	progress.AddNamespaces(len(namespaces))
+
 	startBootstrap := m.nowFn()
 	for _, namespace := range namespaces {
 		startNamespaceBootstrap := m.nowFn()
@@ -187,7 +198,64 @@ func (m *bootstrapManager) bootstrap() error {
 			xlog.NewField("namespace", namespace.ID().String()),
 			xlog.NewField("duration", took.String()),
 		).Info("bootstrap finished")
+		progress.CompleteNamespace()
 	}
 
 	return multiErr.FinalError()
 }
+
+// startProgressReporter periodically logs the progress of the bootstrap until
+// the returned function is called.
+func (m *bootstrapManager) startProgressReporter(progress *bootstrap.Progress) func() {
+	interval := m.processProvider.ProcessOptions().ProgressLogInterval()
+	if interval <= 0 {
+		return func() {}
+	}
+
+	var (
+		ticker = time.NewTicker(interval)
+		doneCh = make(chan struct{})
+		wg     sync.WaitGroup
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-ticker.C:
+				m.logProgress(progress.Snapshot())
+			case <-doneCh:
+				return
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(doneCh)
+		wg.Wait()
+	}
+}
+
+func (m *bootstrapManager) logProgress(snapshot bootstrap.ProgressSnapshot) {
+	fields := []xlog.Field{
+		xlog.NewField("retries", snapshot.Retries),
+		xlog.NewField("namespacesComplete", snapshot.NamespacesComplete),
+		xlog.NewField("namespacesTotal", snapshot.NamespacesTotal),
+		xlog.NewField("shardsComplete", snapshot.ShardsComplete),
+		xlog.NewField("shardsTotal", snapshot.ShardsTotal),
+		xlog.NewField("timeRangesRemaining", snapshot.TimeRangesRemaining),
+	}
+	sources := make([]string, 0, len(snapshot.BytesRead))
+	for source := range snapshot.BytesRead {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	for _, source := range sources {
+		fields = append(fields, xlog.NewField(source+"BytesRead", snapshot.BytesRead[source]))
+	}
+	if snapshot.StartedAt != nil {
+		took := m.nowFn().Sub(*snapshot.StartedAt)
+		fields = append(fields, xlog.NewField("duration", took.String()))
+	}
+	m.log.WithFields(fields...).Info("bootstrap progress")
+}
@@ -24,6 +24,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"sync"
 	"time"
 
@@ -211,10 +212,12 @@ func (s *commitLogSource) ReadData(
 
 	// Setup the commit log iterator.
 	var (
-		nsID              = ns.ID()
-		seriesSkipped     int
-		datapointsSkipped int
-		datapointsRead    int
+		nsID                = ns.ID()
+		annotationsEnabled  = ns.Options().AnnotationsEnabled()
+		seriesSkipped       int
+		datapointsSkipped   int
+		datapointsRead      int
+		annotationsStripped int
 
 		// TODO(rartoul): When we implement caching data across namespaces, this will need
 		// to be commitlog.ReadAllSeriesPredicate() if CacheSeriesMetadata() is enabled
@@ -228,9 +231,10 @@ func (s *commitLogSource) ReadData(
 			return shouldReadSeries
 		}
 
-		iterOpts = commitlog.IteratorOpts{
+		commitLogBytes int64
+		iterOpts       = commitlog.IteratorOpts{
 			CommitLogOptions:      s.opts.CommitLogOptions(),
-			FileFilterPredicate:   readCommitLogPred,
+			FileFilterPredicate:   newReadCommitLogPredWithFileSizes(readCommitLogPred, &commitLogBytes),
 			SeriesFilterPredicate: readSeriesPredicate,
 		}
 	)
@@ -239,6 +243,10 @@ func (s *commitLogSource) ReadData(
 		s.log.Infof("seriesSkipped: %d", seriesSkipped)
 		s.log.Infof("datapointsSkipped: %d", datapointsSkipped)
 		s.log.Infof("datapointsRead: %d", datapointsRead)
+		if annotationsStripped > 0 {
+			s.log.Warnf("stripped annotations of %d datapoints, annotations are disabled for namespace %s",
+				annotationsStripped, nsID.String())
+		}
 	}()
 
 	iter, err := s.newIteratorFn(iterOpts)
@@ -284,6 +292,14 @@ func (s *commitLogSource) ReadData(
 
 		datapointsRead++
 
+		// NB: writes with annotations are rejected for namespaces with
+		// annotations disabled, however entries written before they were
+		// disabled may still carry them.
+		if !annotationsEnabled && len(annotation) > 0 {
+			annotationsStripped++
+			annotation = nil
+		}
+
 		// Distribute work such that each encoder goroutine is responsible for
 		// approximately numShards / numConc shards. This also means that all
 		// datapoints for a given shard/series will be processed in a serialized
@@ -304,6 +320,7 @@ func (s *commitLogSource) ReadData(
 	if iterErr := iter.Err(); iterErr != nil {
 		return nil, iterErr
 	}
+	runOpts.Progress().AddBytesRead(bootstrap.ProgressSourceCommitLog, commitLogBytes)
 
 	for _, encoderChan := range encoderChans {
 		close(encoderChan)
@@ -813,6 +830,23 @@ func (s *commitLogSource) newReadCommitLogPred(
 	}
 }
 
+// newReadCommitLogPredWithFileSizes wraps a commit log file predicate so that the
+// sizes of the files it selects for reading are totalled into fileSizes.
+func newReadCommitLogPredWithFileSizes(
+	pred commitlog.FileFilterPredicate,
+	fileSizes *int64,
+) commitlog.FileFilterPredicate {
+	return func(f commitlog.File) bool {
+		if !pred(f) {
+			return false
+		}
+		if info, err := os.Stat(f.FilePath); err == nil {
+			*fileSizes += info.Size()
+		}
+		return true
+	}
+}
+
 func (s *commitLogSource) startM3TSZEncodingWorker(
 	ns namespace.Metadata,
 	runOpts bootstrap.RunOptions,
@@ -1310,10 +1344,11 @@ func (s *commitLogSource) ReadIndex(
 	}
 
 	var (
+		commitLogBytes      int64
 		readSeriesPredicate = newReadSeriesPredicate(ns)
 		iterOpts            = commitlog.IteratorOpts{
 			CommitLogOptions:      s.opts.CommitLogOptions(),
-			FileFilterPredicate:   readCommitLogPredicate,
+			FileFilterPredicate:   newReadCommitLogPredWithFileSizes(readCommitLogPredicate, &commitLogBytes),
 			SeriesFilterPredicate: readSeriesPredicate,
 		}
 	)
@@ -1354,6 +1389,7 @@ func (s *commitLogSource) ReadIndex(
 			series.ID, series.Tags, series.Shard, highestShard, dp.Timestamp, bootstrapRangesByShard,
 			indexResults, indexOptions, indexBlockSize, resultOptions)
 	}
+	opts.Progress().AddBytesRead(bootstrap.ProgressSourceCommitLog, commitLogBytes)
 
 	// If all successful then we mark each index block as fulfilled
 	for _, block := range indexResult.IndexResults() {
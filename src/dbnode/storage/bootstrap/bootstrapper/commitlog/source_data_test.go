@@ -168,6 +168,48 @@ func TestReadOrderedValues(t *testing.T) {
 		values[:4], blockSize, res.ShardResults(), opts))
 }
 
+func TestReadStripsAnnotationsWhenDisabled(t *testing.T) {
+	opts := testDefaultOpts
+	md, err := namespace.NewMetadata(testNamespaceID,
+		namespace.NewOptions().SetAnnotationsEnabled(false))
+	require.NoError(t, err)
+	src := newCommitLogSource(opts, fs.Inspection{}).(*commitLogSource)
+
+	blockSize := md.Options().RetentionOptions().BlockSize()
+	now := time.Now()
+	start := now.Truncate(blockSize).Add(-blockSize)
+	end := now.Truncate(blockSize)
+
+	ranges := xtime.Ranges{}
+	ranges = ranges.AddRange(xtime.Range{
+		Start: start,
+		End:   end,
+	})
+
+	foo := commitlog.Series{Namespace: testNamespaceID, Shard: 0, ID: ident.StringID("foo")}
+
+	values := []testValue{
+		{foo, start, 1.0, xtime.Second, ts.Annotation("abc")},
+		{foo, start.Add(1 * time.Minute), 2.0, xtime.Second, nil},
+		{foo, start.Add(2 * time.Minute), 3.0, xtime.Second, ts.Annotation("def")},
+	}
+	src.newIteratorFn = func(_ commitlog.IteratorOpts) (commitlog.Iterator, error) {
+		return newTestCommitLogIterator(values, nil), nil
+	}
+
+	res, err := src.ReadData(md, result.ShardTimeRanges{0: ranges}, testDefaultRunOpts)
+	require.NoError(t, err)
+	require.NotNil(t, res)
+
+	expected := make([]testValue, 0, len(values))
+	for _, v := range values {
+		v.a = nil
+		expected = append(expected, v)
+	}
+	require.NoError(t, verifyShardResultsAreCorrect(
+		expected, blockSize, res.ShardResults(), opts))
+}
+
 func TestReadUnorderedValues(t *testing.T) {
 	opts := testDefaultOpts
 	md := testNsMetadata(t)
@@ -49,6 +49,10 @@ var (
 	// us splitting an index block into smaller pieces is moot because we'll
 	// pull a lot more data into memory if we create more than one at a time.
 	defaultBootstrapIndexNumProcessors = 1
+	// NB: Reading fileset files is mostly IO-bound so by default read
+	// as many shard fileset files concurrently as there are CPUs to keep
+	// the disks busy.
+	defaultBootstrapReaderNumWorkers = goruntime.NumCPU()
 )
 
 type options struct {
@@ -58,6 +62,7 @@ type options struct {
 	persistManager              persist.Manager
 	bootstrapDataNumProcessors  int
 	bootstrapIndexNumProcessors int
+	bootstrapReaderNumWorkers   int
 	blockRetrieverManager       block.DatabaseBlockRetrieverManager
 	runtimeOptsMgr              runtime.OptionsManager
 	identifierPool              ident.Pool
@@ -71,11 +76,12 @@ func NewOptions() Options {
 	bytesPool.Init()
 	idPool := ident.NewPool(bytesPool, ident.PoolOptions{})
 	return &options{
-		instrumentOpts: instrument.NewOptions(),
-		resultOpts:     result.NewOptions(),
-		fsOpts:         fs.NewOptions(),
+		instrumentOpts:              instrument.NewOptions(),
+		resultOpts:                  result.NewOptions(),
+		fsOpts:                      fs.NewOptions(),
 		bootstrapDataNumProcessors:  defaultBootstrapDataNumProcessors,
 		bootstrapIndexNumProcessors: defaultBootstrapIndexNumProcessors,
+		bootstrapReaderNumWorkers:   defaultBootstrapReaderNumWorkers,
 		runtimeOptsMgr:              runtime.NewOptionsManager(),
 		identifierPool:              idPool,
 	}
@@ -148,6 +154,16 @@ func (o *options) BoostrapIndexNumProcessors() int {
 	return o.bootstrapIndexNumProcessors
 }
 
+func (o *options) SetBootstrapReaderNumWorkers(value int) Options {
+	opts := *o
+	opts.bootstrapReaderNumWorkers = value
+	return &opts
+}
+
+func (o *options) BootstrapReaderNumWorkers() int {
+	return o.bootstrapReaderNumWorkers
+}
+
 func (o *options) SetDatabaseBlockRetrieverManager(
 	value block.DatabaseBlockRetrieverManager,
 ) Options {
@@ -37,6 +37,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/m3ninx/index/segment"
 	"github.com/m3db/m3x/checked"
+	xerrors "github.com/m3db/m3x/errors"
 	"github.com/m3db/m3x/ident"
 	"github.com/m3db/m3x/instrument"
 	xlog "github.com/m3db/m3x/log"
@@ -68,6 +69,7 @@ type fileSystemSource struct {
 	newReaderPoolOpts newReaderPoolOptions
 	dataProcessors    xsync.WorkerPool
 	indexProcessors   xsync.WorkerPool
+	readerWorkers     xsync.WorkerPool
 	persistManager    persistManager
 	metrics           fileSystemSourceMetrics
 }
@@ -80,6 +82,8 @@ type persistManager struct {
 type fileSystemSourceMetrics struct {
 	persistedIndexBlocksRead  tally.Counter
 	persistedIndexBlocksWrite tally.Counter
+	readUnitsSuccess          tally.Counter
+	readUnitsErrors           tally.Counter
 }
 
 func newFileSystemSource(opts Options) bootstrap.Source {
@@ -93,6 +97,14 @@ func newFileSystemSource(opts Options) bootstrap.Source {
 	indexProcessors := xsync.NewWorkerPool(opts.BoostrapIndexNumProcessors())
 	indexProcessors.Init()
 
+	// NB: Leave the reader workers unset when reading with a single worker
+	// so that the file sets of each shard are read inline and serially.
+	var readerWorkers xsync.WorkerPool
+	if n := opts.BootstrapReaderNumWorkers(); n > 1 {
+		readerWorkers = xsync.NewWorkerPool(n)
+		readerWorkers.Init()
+	}
+
 	s := &fileSystemSource{
 		opts:            opts,
 		fsopts:          opts.FilesystemOptions(),
@@ -101,12 +113,15 @@ func newFileSystemSource(opts Options) bootstrap.Source {
 		newReaderFn:     fs.NewReader,
 		dataProcessors:  dataProcessors,
 		indexProcessors: indexProcessors,
+		readerWorkers:   readerWorkers,
 		persistManager: persistManager{
 			mgr: opts.PersistManager(),
 		},
 		metrics: fileSystemSourceMetrics{
 			persistedIndexBlocksRead:  scope.Counter("persist-index-blocks-read"),
 			persistedIndexBlocksWrite: scope.Counter("persist-index-blocks-write"),
+			readUnitsSuccess:          scope.Counter("read-units-success"),
+			readUnitsErrors:           scope.Counter("read-units-errors"),
 		},
 	}
 	s.newReaderPoolOpts.alloc = s.newReader
@@ -168,6 +183,12 @@ func (s *fileSystemSource) availability(
 ) result.ShardTimeRanges {
 	result := make(map[uint32]xtime.Ranges)
 	for shard, ranges := range shardsTimeRanges {
+		if !md.Options().FlushEnabled() {
+			// Namespaces that are never flushed have no fileset files to
+			// bootstrap from, skip reading their info files entirely
+			result[shard] = xtime.Ranges{}
+			continue
+		}
 		result[shard] = s.shardAvailability(md.ID(), shard, ranges)
 	}
 	return result
@@ -281,17 +302,49 @@ func (s *fileSystemSource) enqueueReadersGroupedByBlockSize(
 	groupFn := newShardTimeRangesTimeWindowGroups
 	groupedByBlockSize := groupFn(shardTimeRanges, blockSize)
 
-	// Now enqueue across all shards by block size
+	// Now enqueue across all shards by block size, the info files of each
+	// shard are read and the readers opened concurrently while the readers
+	// of previously enqueued time windows are being read from
 	for _, group := range groupedByBlockSize {
-		readers := make(map[shardID]shardReaders, len(group.ranges))
-		for shard, tr := range group.ranges {
+		var (
+			readers     = make(map[shardID]shardReaders, len(group.ranges))
+			readersLock sync.Mutex
+		)
+		s.forEachShard(group.ranges, func(shard uint32, tr xtime.Ranges) {
 			shardReaders := s.newShardReaders(ns, readerPool, shard, tr)
+			readersLock.Lock()
 			readers[shardID(shard)] = shardReaders
-		}
+			readersLock.Unlock()
+		})
 		readersCh <- newTimeWindowReaders(group.ranges, readers)
 	}
 }
 
+// forEachShard calls fn for each shard, concurrently using the reader workers
+// if set, and returns once fn has returned for all shards.
+func (s *fileSystemSource) forEachShard(
+	shardTimeRanges result.ShardTimeRanges,
+	fn func(shard uint32, tr xtime.Ranges),
+) {
+	if s.readerWorkers == nil {
+		for shard, tr := range shardTimeRanges {
+			fn(shard, tr)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	for shard, tr := range shardTimeRanges {
+		shard, tr := shard, tr
+		wg.Add(1)
+		s.readerWorkers.Go(func() {
+			fn(shard, tr)
+			wg.Done()
+		})
+	}
+	wg.Wait()
+}
+
 func (s *fileSystemSource) newShardReaders(
 	ns namespace.Metadata,
 	readerPool *readerPool,
@@ -413,15 +466,15 @@ func (s *fileSystemSource) bootstrapFromReaders(
 	return runResult
 }
 
-// markRunResultErrorsAndUnfulfilled checks the list of times that had errors and makes
-// sure that we don't return any blocks or bloom filters for them. In addition,
+// markRunResultErrorsAndUnfulfilled checks the list of times per shard that had errors
+// and makes sure that we don't return any blocks or bloom filters for them. In addition,
 // it looks at any remaining (unfulfilled) ranges and makes sure they're marked
 // as unfulfilled
 func (s *fileSystemSource) markRunResultErrorsAndUnfulfilled(
 	runResult *runResult,
 	requestedRanges result.ShardTimeRanges,
 	remainingRanges result.ShardTimeRanges,
-	timesWithErrors []time.Time,
+	timesWithErrors map[uint32][]time.Time,
 ) {
 	// NB(xichen): this is the exceptional case where we encountered errors due to files
 	// being corrupted, which should be fairly rare so we can live with the overhead. We
@@ -430,9 +483,12 @@ func (s *fileSystemSource) markRunResultErrorsAndUnfulfilled(
 	// the current implementation saves the extra overhead of merging temporary map with the
 	// final result.
 	if len(timesWithErrors) > 0 {
-		timesWithErrorsString := make([]string, len(timesWithErrors))
-		for i := range timesWithErrors {
-			timesWithErrorsString[i] = timesWithErrors[i].String()
+		var timesWithErrorsString []string
+		for shard, times := range timesWithErrors {
+			for _, t := range times {
+				timesWithErrorsString = append(timesWithErrorsString,
+					fmt.Sprintf("shard=%d,time=%s", shard, t.String()))
+			}
 		}
 		s.log.WithFields(
 			xlog.NewField("requestedRanges", requestedRanges.SummaryString()),
@@ -440,13 +496,13 @@ func (s *fileSystemSource) markRunResultErrorsAndUnfulfilled(
 		).Info("deleting entries from results for times with errors")
 
 		runResult.Lock()
-		for shard := range requestedRanges {
-			// Delete all affected times from the data results.
+		for shard, times := range timesWithErrors {
+			// Delete all affected times from the data results of the shard.
 			shardResult, ok := runResult.data.ShardResults()[shard]
 			if ok {
 				for _, entry := range shardResult.AllSeries().Iter() {
 					series := entry.Value()
-					for _, t := range timesWithErrors {
+					for _, t := range times {
 						shardResult.RemoveBlockAt(series.ID, t)
 					}
 				}
@@ -486,112 +542,48 @@ func (s *fileSystemSource) loadShardReadersDataIntoShardResult(
 	readerPool *readerPool,
 ) {
 	var (
-		blockPool         = ropts.DatabaseBlockOptions().DatabaseBlockPool()
-		seriesCachePolicy = ropts.SeriesCachePolicy()
-		indexBlockSegment segment.MutableSegment
-		timesWithErrors   []time.Time
-		shardResult       result.ShardResult
-		shardRetriever    block.DatabaseShardBlockRetriever
+		requestedRanges = timeWindowReaders.ranges
+		remainingRanges = requestedRanges.Copy()
+		shardReaders    = timeWindowReaders.readers
+		timesWithErrors = make(map[uint32][]time.Time)
+		multiErr        = xerrors.NewMultiError()
+		numUnitErrors   int
+		unitsLock       sync.Mutex
 	)
 
-	requestedRanges := timeWindowReaders.ranges
-	remainingRanges := requestedRanges.Copy()
-	shardReaders := timeWindowReaders.readers
-	for shard, shardReaders := range shardReaders {
-		shard := uint32(shard)
-		readers := shardReaders.readers
-
-		if run == bootstrapDataRunType {
-			// For the bootstrap data case we need the shard retriever
-			if shardRetrieverMgr != nil {
-				shardRetriever = shardRetrieverMgr.ShardRetriever(shard)
-			}
-			if seriesCachePolicy == series.CacheAllMetadata && shardRetriever == nil {
-				s.log.WithFields(
-					xlog.NewField("has-shard-retriever-mgr", shardRetrieverMgr != nil),
-					xlog.NewField("has-shard-retriever", shardRetriever != nil),
-				).Errorf("shard retriever missing for shard: %d", shard)
-				s.markRunResultErrorsAndUnfulfilled(runResult, requestedRanges,
-					remainingRanges, timesWithErrors)
-				return
-			}
+	// Read each shard's readers as a unit concurrently, failures of any
+	// single unit leave just that unit's time ranges unfulfilled rather
+	// than aborting the reading of the entire time window
+	s.forEachShard(requestedRanges, func(shard uint32, _ xtime.Ranges) {
+		unitReaders, ok := shardReaders[shardID(shard)]
+		if !ok {
+			return
 		}
-
-		for _, r := range readers {
-			var (
-				timeRange = r.Range()
-				start     = timeRange.Start
-				blockSize = ns.Options().RetentionOptions().BlockSize()
-				err       error
-			)
-			switch run {
-			case bootstrapDataRunType:
-				capacity := r.Entries()
-				shardResult = runResult.getOrAddDataShardResult(shard, capacity, ropts)
-			case bootstrapIndexRunType:
-				indexBlockSegment, err = runResult.getOrAddIndexSegment(start, ns, ropts)
-			default:
-				// Unreachable unless an internal method calls with a run type casted from int
-				panic(fmt.Errorf("invalid run type: %d", run))
-			}
-
-			numEntries := r.Entries()
-			for i := 0; err == nil && i < numEntries; i++ {
-				switch run {
-				case bootstrapDataRunType:
-					err = s.readNextEntryAndRecordBlock(r, runResult, start, blockSize, shardResult,
-						shardRetriever, blockPool, seriesCachePolicy)
-				case bootstrapIndexRunType:
-					// We can just read the entry and index if performing an index run
-					err = s.readNextEntryAndIndex(r, runResult, indexBlockSegment)
-				default:
-					// Unreachable unless an internal method calls with a run type casted from int
-					panic(fmt.Errorf("invalid run type: %d", run))
-				}
-			}
-
-			if err == nil {
-				// Validate the read results
-				var validateErr error
-				switch run {
-				case bootstrapDataRunType:
-					switch seriesCachePolicy {
-					case series.CacheAll:
-						validateErr = r.Validate()
-					case series.CacheAllMetadata:
-						validateErr = r.ValidateMetadata()
-					default:
-						err = fmt.Errorf("invalid series cache policy: %s", seriesCachePolicy.String())
-					}
-				case bootstrapIndexRunType:
-					validateErr = r.ValidateMetadata()
-				default:
-					// Unreachable unless an internal method calls with a run type casted from int
-					panic(fmt.Errorf("invalid run type: %d", run))
-				}
-				if validateErr != nil {
-					err = fmt.Errorf("data validation failed: %v", validateErr)
-				}
-			}
-
-			if err == nil && run == bootstrapIndexRunType {
-				// Mark index block as fulfilled
-				fulfilled := result.ShardTimeRanges{
-					shard: xtime.Ranges{}.AddRange(timeRange),
-				}
-				err = runResult.index.IndexResults().MarkFulfilled(start, fulfilled,
-					ns.Options().IndexOptions())
-			}
-
-			if err == nil {
-				remainingRanges.Subtract(result.ShardTimeRanges{
-					shard: xtime.Ranges{}.AddRange(timeRange),
-				})
-			} else {
-				s.log.Errorf("%v", err)
-				timesWithErrors = append(timesWithErrors, timeRange.Start)
-			}
+		fulfilled, unitTimesWithErrors, err := s.loadShardReadersIntoRunResult(ns,
+			run, runOpts, runResult, ropts, shardRetrieverMgr, shard,
+			unitReaders.readers)
+
+		unitsLock.Lock()
+		remainingRanges.Subtract(result.ShardTimeRanges{shard: fulfilled})
+		if len(unitTimesWithErrors) > 0 {
+			timesWithErrors[shard] = unitTimesWithErrors
+		}
+		if err != nil {
+			multiErr = multiErr.Add(err)
+			numUnitErrors++
 		}
+		unitsLock.Unlock()
+	})
+
+	s.metrics.readUnitsSuccess.Inc(int64(len(shardReaders) - numUnitErrors))
+	if err := multiErr.FinalError(); err != nil {
+		s.metrics.readUnitsErrors.Inc(int64(numUnitErrors))
+		s.log.WithFields(
+			xlog.NewField("namespace", ns.ID().String()),
+			xlog.NewField("requestedRanges", requestedRanges.SummaryString()),
+			xlog.NewField("numUnitErrors", numUnitErrors),
+			xlog.NewField("error", err.Error()),
+		).Error("fs bootstrapper unable to read shard file sets")
 	}
 
 	var (
@@ -624,6 +616,125 @@ func (s *fileSystemSource) loadShardReadersDataIntoShardResult(
 		remainingRanges, timesWithErrors)
 }
 
+// loadShardReadersIntoRunResult reads the readers of a single shard into the
+// run result and returns the time ranges that were fulfilled, the block starts
+// of the readers that failed and an error if any of the readers failed.
+func (s *fileSystemSource) loadShardReadersIntoRunResult(
+	ns namespace.Metadata,
+	run runType,
+	runOpts bootstrap.RunOptions,
+	runResult *runResult,
+	ropts result.Options,
+	shardRetrieverMgr block.DatabaseShardBlockRetrieverManager,
+	shard uint32,
+	readers []fs.DataFileSetReader,
+) (xtime.Ranges, []time.Time, error) {
+	var (
+		blockPool         = ropts.DatabaseBlockOptions().DatabaseBlockPool()
+		seriesCachePolicy = ropts.SeriesCachePolicy()
+		indexBlockSegment segment.MutableSegment
+		shardResult       result.ShardResult
+		shardRetriever    block.DatabaseShardBlockRetriever
+		fulfilled         xtime.Ranges
+		timesWithErrors   []time.Time
+		multiErr          = xerrors.NewMultiError()
+	)
+
+	if run == bootstrapDataRunType {
+		// For the bootstrap data case we need the shard retriever
+		if shardRetrieverMgr != nil {
+			shardRetriever = shardRetrieverMgr.ShardRetriever(shard)
+		}
+		if seriesCachePolicy == series.CacheAllMetadata && shardRetriever == nil {
+			s.log.WithFields(
+				xlog.NewField("has-shard-retriever-mgr", shardRetrieverMgr != nil),
+				xlog.NewField("has-shard-retriever", shardRetriever != nil),
+			).Errorf("shard retriever missing for shard: %d", shard)
+			return fulfilled, nil, fmt.Errorf("shard retriever missing for shard: %d", shard)
+		}
+	}
+
+	for _, r := range readers {
+		var (
+			timeRange = r.Range()
+			start     = timeRange.Start
+			blockSize = ns.Options().RetentionOptions().BlockSize()
+			err       error
+		)
+		switch run {
+		case bootstrapDataRunType:
+			capacity := r.Entries()
+			shardResult = runResult.getOrAddDataShardResult(shard, capacity, ropts)
+		case bootstrapIndexRunType:
+			indexBlockSegment, err = runResult.getOrAddIndexSegment(start, ns, ropts)
+		default:
+			// Unreachable unless an internal method calls with a run type casted from int
+			panic(fmt.Errorf("invalid run type: %d", run))
+		}
+
+		numEntries := r.Entries()
+		for i := 0; err == nil && i < numEntries; i++ {
+			switch run {
+			case bootstrapDataRunType:
+				err = s.readNextEntryAndRecordBlock(r, runResult, start, blockSize, shardResult,
+					shardRetriever, blockPool, seriesCachePolicy, runOpts.Progress())
+			case bootstrapIndexRunType:
+				// We can just read the entry and index if performing an index run
+				err = s.readNextEntryAndIndex(r, runResult, indexBlockSegment)
+			default:
+				// Unreachable unless an internal method calls with a run type casted from int
+				panic(fmt.Errorf("invalid run type: %d", run))
+			}
+		}
+
+		if err == nil {
+			// Validate the read results
+			var validateErr error
+			switch run {
+			case bootstrapDataRunType:
+				switch seriesCachePolicy {
+				case series.CacheAll:
+					validateErr = r.Validate()
+				case series.CacheAllMetadata:
+					validateErr = r.ValidateMetadata()
+				default:
+					err = fmt.Errorf("invalid series cache policy: %s", seriesCachePolicy.String())
+				}
+			case bootstrapIndexRunType:
+				validateErr = r.ValidateMetadata()
+			default:
+				// Unreachable unless an internal method calls with a run type casted from int
+				panic(fmt.Errorf("invalid run type: %d", run))
+			}
+			if validateErr != nil {
+				err = fmt.Errorf("data validation failed: %v", validateErr)
+			}
+		}
+
+		if err == nil && run == bootstrapIndexRunType {
+			// Mark index block as fulfilled, the index results are shared
+			// by all shards being read concurrently so need the write lock
+			indexFulfilled := result.ShardTimeRanges{
+				shard: xtime.Ranges{}.AddRange(timeRange),
+			}
+			runResult.Lock()
+			err = runResult.index.IndexResults().MarkFulfilled(start, indexFulfilled,
+				ns.Options().IndexOptions())
+			runResult.Unlock()
+		}
+
+		if err == nil {
+			fulfilled = fulfilled.AddRange(timeRange)
+		} else {
+			multiErr = multiErr.Add(fmt.Errorf("shard %d block start %s: %v",
+				shard, start.String(), err))
+			timesWithErrors = append(timesWithErrors, timeRange.Start)
+		}
+	}
+
+	return fulfilled, timesWithErrors, multiErr.FinalError()
+}
+
 func (s *fileSystemSource) readNextEntryAndRecordBlock(
 	r fs.DataFileSetReader,
 	runResult *runResult,
@@ -633,6 +744,7 @@ func (s *fileSystemSource) readNextEntryAndRecordBlock(
 	shardRetriever block.DatabaseShardBlockRetriever,
 	blockPool block.DatabaseBlockPool,
 	seriesCachePolicy series.CachePolicy,
+	progress *bootstrap.Progress,
 ) error {
 	var (
 		seriesBlock = blockPool.Get()
@@ -683,6 +795,7 @@ func (s *fileSystemSource) readNextEntryAndRecordBlock(
 	case series.CacheAll:
 		seg := ts.NewSegment(data, nil, ts.FinalizeHead)
 		seriesBlock.Reset(blockStart, blockSize, seg)
+		progress.AddBytesRead(bootstrap.ProgressSourceFilesystem, int64(seg.Len()))
 	case series.CacheAllMetadata:
 		metadata := block.RetrievableBlockMetadata{
 			ID:       id,
@@ -922,6 +1035,14 @@ func (s *fileSystemSource) read(
 	if shardsTimeRanges.IsEmpty() {
 		return newRunResult(), nil
 	}
+	if !md.Options().FlushEnabled() {
+		// Nothing is ever flushed for the namespace, leave the ranges
+		// unfulfilled for the next bootstrapper
+		res = newRunResult()
+		res.data.SetUnfulfilled(shardsTimeRanges.Copy())
+		res.index.SetUnfulfilled(shardsTimeRanges.Copy())
+		return res, nil
+	}
 
 	setOrMergeResult := func(newResult *runResult) {
 		if newResult == nil {
@@ -0,0 +1,95 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	benchmarkNumShards          = 32
+	benchmarkNumBlocks          = 4
+	benchmarkNumSeriesPerBlock  = 1000
+	benchmarkSeriesDataNumBytes = 256
+)
+
+// writeBenchmarkFileSets generates file sets for every shard and block start
+// and returns the shard time ranges covering all of them.
+func writeBenchmarkFileSets(b *testing.B, dir string) result.ShardTimeRanges {
+	var (
+		start  = testStart.Add(-benchmarkNumBlocks * testBlockSize)
+		data   = make([]byte, benchmarkSeriesDataNumBytes)
+		series = make([]testSeries, 0, benchmarkNumSeriesPerBlock)
+		strs   = make(result.ShardTimeRanges, benchmarkNumShards)
+	)
+	for i := 0; i < benchmarkNumSeriesPerBlock; i++ {
+		series = append(series, testSeries{
+			id:   fmt.Sprintf("foo.%d", i),
+			tags: map[string]string{"n": fmt.Sprintf("%d", i)},
+			data: data,
+		})
+	}
+	for shard := uint32(0); shard < benchmarkNumShards; shard++ {
+		for i := 0; i < benchmarkNumBlocks; i++ {
+			blockStart := start.Add(time.Duration(i) * testBlockSize)
+			writeTSDBFiles(b, dir, testNs1ID, shard, blockStart, series)
+		}
+		strs[shard] = xtime.Ranges{}.AddRange(xtime.Range{
+			Start: start,
+			End:   testStart,
+		})
+	}
+	return strs
+}
+
+func BenchmarkReadData(b *testing.B) {
+	dir, err := ioutil.TempDir("", "fs-bootstrap-bench")
+	require.NoError(b, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		strs = writeBenchmarkFileSets(b, dir)
+		md   = testNsMetadata(b)
+	)
+	for _, numWorkers := range []int{1, 4, runtime.NumCPU()} {
+		b.Run(fmt.Sprintf("workers=%d", numWorkers), func(b *testing.B) {
+			opts := newTestOptions(dir).SetBootstrapReaderNumWorkers(numWorkers)
+			src := newFileSystemSource(opts)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				res, err := src.ReadData(md, strs, testDefaultRunOpts)
+				require.NoError(b, err)
+				require.True(b, res.Unfulfilled().IsEmpty())
+			}
+		})
+	}
+}
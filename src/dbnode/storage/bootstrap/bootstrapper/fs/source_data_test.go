@@ -82,7 +82,7 @@ func newTestFsOptions(filePathPrefix string) fs.Options {
 		SetNewDirectoryMode(testDirMode)
 }
 
-func testNsMetadata(t *testing.T) namespace.Metadata {
+func testNsMetadata(t testing.TB) namespace.Metadata {
 	ropts := retention.NewOptions().SetBlockSize(testBlockSize)
 	md, err := namespace.NewMetadata(testNs1ID, namespace.NewOptions().
 		SetRetentionOptions(ropts).
@@ -184,7 +184,7 @@ func (s testSeries) Tags() ident.Tags {
 }
 
 func writeTSDBFiles(
-	t *testing.T,
+	t testing.TB,
 	dir string,
 	namespace ident.ID,
 	shard uint32,
@@ -331,6 +331,28 @@ func TestAvailableTimeRangeFilter(t *testing.T) {
 	validateTimeRanges(t, res[testShard], expected)
 }
 
+func TestAvailableFlushDisabled(t *testing.T) {
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	shard := uint32(0)
+	writeGoodFiles(t, dir, testNs1ID, shard)
+
+	md := testNsMetadata(t)
+	md, err := namespace.NewMetadata(md.ID(), md.Options().SetFlushEnabled(false))
+	require.NoError(t, err)
+
+	src := newFileSystemSource(newTestOptions(dir))
+	res := src.AvailableData(md, testShardTimeRanges(), testDefaultRunOpts)
+	require.NotNil(t, res)
+	require.True(t, res.IsEmpty())
+
+	readRes, err := src.ReadData(md, testShardTimeRanges(), testDefaultRunOpts)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(readRes.ShardResults()))
+	validateTimeRanges(t, readRes.Unfulfilled()[testShard], testShardTimeRanges()[testShard])
+}
+
 func TestAvailableTimeRangePartialError(t *testing.T) {
 	dir := createTempDir(t)
 	defer os.RemoveAll(dir)
@@ -505,6 +527,73 @@ func TestReadPartialError(t *testing.T) {
 	validateReadResults(t, src, dir, testShardTimeRanges())
 }
 
+func corruptDataFile(t *testing.T, prefix string, namespace ident.ID, shard uint32, start time.Time) {
+	shardDir := fs.ShardDataDirPath(prefix, namespace, shard)
+	filePath := path.Join(shardDir, fmt.Sprintf("fileset-%d-data.db", xtime.ToNanoseconds(start)))
+	data, err := ioutil.ReadFile(filePath)
+	require.NoError(t, err)
+	for i := range data {
+		data[i] = ^data[i]
+	}
+	writeFile(t, filePath, data)
+}
+
+func TestReadShardsConcurrentlyPartialError(t *testing.T) {
+	for _, numWorkers := range []int{1, 4} {
+		t.Run(fmt.Sprintf("workers=%d", numWorkers), func(t *testing.T) {
+			testReadShardsConcurrentlyPartialError(t, numWorkers)
+		})
+	}
+}
+
+func testReadShardsConcurrentlyPartialError(t *testing.T, numWorkers int) {
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	var (
+		numShards    = uint32(8)
+		corruptShard = uint32(3)
+		strs         = make(result.ShardTimeRanges)
+	)
+	for shard := uint32(0); shard < numShards; shard++ {
+		writeTSDBFiles(t, dir, testNs1ID, shard, testStart, []testSeries{
+			{fmt.Sprintf("foo.%d", shard), nil, []byte{byte(shard)}},
+		})
+		strs[shard] = testTimeRanges()
+	}
+	// Intentionally corrupt the data file of a single shard
+	corruptDataFile(t, dir, testNs1ID, corruptShard, testStart)
+
+	opts := newTestOptions(dir).SetBootstrapReaderNumWorkers(numWorkers)
+	src := newFileSystemSource(opts)
+	res, err := src.ReadData(testNsMetadata(t), strs, testDefaultRunOpts)
+	require.NoError(t, err)
+	require.NotNil(t, res)
+
+	// Only the corrupt shard should be left unfulfilled for the block
+	for shard := uint32(0); shard < numShards; shard++ {
+		unfulfilled := res.Unfulfilled()[shard]
+		if shard == corruptShard {
+			_, ok := res.ShardResults()[shard]
+			require.False(t, ok)
+			validateTimeRanges(t, unfulfilled, testTimeRanges())
+			continue
+		}
+
+		shardResult, ok := res.ShardResults()[shard]
+		require.True(t, ok)
+		require.Equal(t, int64(1), shardResult.NumSeries())
+		_, ok = shardResult.AllSeries().Get(ident.StringID(fmt.Sprintf("foo.%d", shard)))
+		require.True(t, ok)
+
+		expected := xtime.Ranges{}.AddRange(xtime.Range{
+			Start: testStart.Add(testBlockSize),
+			End:   testStart.Add(11 * time.Hour),
+		})
+		validateTimeRanges(t, unfulfilled, expected)
+	}
+}
+
 func TestReadValidateError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -77,6 +77,15 @@ type Options interface {
 	// work for bootstrapping data file sets.
 	BoostrapIndexNumProcessors() int
 
+	// SetBootstrapReaderNumWorkers sets the number of workers used to open and
+	// read the file sets of each shard and block start concurrently, a value
+	// of one or less reads the file sets of each shard serially.
+	SetBootstrapReaderNumWorkers(value int) Options
+
+	// BootstrapReaderNumWorkers returns the number of workers used to open and
+	// read the file sets of each shard and block start concurrently.
+	BootstrapReaderNumWorkers() int
+
 	// SetDatabaseBlockRetrieverManager sets the block retriever manager to
 	// use when bootstrapping retrievable blocks instead of blocks
 	// containing data.
@@ -186,7 +186,7 @@ func (s *peersSource) ReadData(
 			defer wg.Done()
 			s.fetchBootstrapBlocksFromPeers(shard, ranges, nsMetadata, session,
 				resultOpts, result, &resultLock, shouldPersist, persistenceQueue,
-				shardRetrieverMgr, blockSize)
+				shardRetrieverMgr, blockSize, opts.Progress())
 		})
 	}
 
@@ -265,6 +265,7 @@ func (s *peersSource) fetchBootstrapBlocksFromPeers(
 	persistenceQueue chan persistenceFlush,
 	shardRetrieverMgr block.DatabaseShardBlockRetrieverManager,
 	blockSize time.Duration,
+	progress *bootstrap.Progress,
 ) {
 	it := ranges.Iter()
 	for it.Next() {
@@ -286,6 +287,8 @@ func (s *peersSource) fetchBootstrapBlocksFromPeers(
 				continue
 			}
 
+			progress.AddBytesRead(bootstrap.ProgressSourcePeers, shardResultBytes(shardResult))
+
 			if shouldPersist {
 				persistenceQueue <- persistenceFlush{
 					nsMetadata:        nsMetadata,
@@ -334,6 +337,17 @@ func (s *peersSource) logFetchBootstrapBlocksFromPeersOutcome(
 	}
 }
 
+// shardResultBytes returns the size of the data held by all blocks of a shard result.
+func shardResultBytes(shardResult result.ShardResult) int64 {
+	var total int64
+	for _, entry := range shardResult.AllSeries().Iter() {
+		for _, bl := range entry.Value().Blocks.AllBlocks() {
+			total += int64(bl.Len())
+		}
+	}
+	return total
+}
+
 // flush is used to flush peer-bootstrapped shards to disk as they finish so
 // that we're not (necessarily) holding everything in memory at once.
 // flush starts by looping through every block in a timerange for
@@ -426,7 +440,7 @@ func (s *peersSource) flush(
 				break
 			}
 
-			err = prepared.Persist(s.ID, s.Tags, segment, checksum)
+			err = prepared.Persist(s.ID, s.Tags, segment, checksum, bl.DatapointStats())
 			tmpCtx.BlockingClose()
 			if err != nil {
 				blockErr = err // Need to call prepared.Close, avoid return
@@ -181,7 +181,9 @@ func TestPeersSourceReturnsFulfilledAndUnfulfilled(t *testing.T) {
 	end := start.Add(ropts.BlockSize())
 
 	goodResult := result.NewShardResult(0, opts.ResultOptions())
-	fooBlock := block.NewDatabaseBlock(start, ropts.BlockSize(), ts.Segment{}, testBlockOpts)
+	fooBlock := block.NewDatabaseBlock(start, ropts.BlockSize(),
+		ts.NewSegment(checked.NewBytes([]byte{1, 2, 3}, nil), nil, ts.FinalizeNone),
+		testBlockOpts)
 	goodResult.AddBlock(ident.StringID("foo"), ident.NewTags(ident.StringTag("foo", "oof")), fooBlock)
 	badErr := fmt.Errorf("an error")
 
@@ -208,8 +210,10 @@ func TestPeersSourceReturnsFulfilledAndUnfulfilled(t *testing.T) {
 		1: xtime.NewRanges(xtime.Range{Start: start, End: end}),
 	}
 
-	r, err := src.ReadData(nsMetadata, target, testDefaultRunOpts)
+	progress := bootstrap.NewProgress()
+	r, err := src.ReadData(nsMetadata, target, testDefaultRunOpts.SetProgress(progress))
 	assert.NoError(t, err)
+	assert.Equal(t, int64(3), progress.Snapshot().BytesRead["peers"])
 
 	assert.Equal(t, 1, len(r.ShardResults()))
 	require.NotNil(t, r.ShardResults()[0])
@@ -313,7 +317,7 @@ func TestPeersSourceRunWithPersist(t *testing.T) {
 		mockFlush.EXPECT().
 			PrepareData(prepareOpts).
 			Return(persist.PreparedDataPersist{
-				Persist: func(id ident.ID, _ ident.Tags, segment ts.Segment, checksum uint32) error {
+				Persist: func(id ident.ID, _ ident.Tags, segment ts.Segment, checksum uint32, _ ts.DatapointStats) error {
 					persists["foo"]++
 					assert.Equal(t, "foo", id.String())
 					assert.Equal(t, []byte{1, 2, 3}, segment.Head.Bytes())
@@ -334,7 +338,7 @@ func TestPeersSourceRunWithPersist(t *testing.T) {
 		mockFlush.EXPECT().
 			PrepareData(prepareOpts).
 			Return(persist.PreparedDataPersist{
-				Persist: func(id ident.ID, _ ident.Tags, segment ts.Segment, checksum uint32) error {
+				Persist: func(id ident.ID, _ ident.Tags, segment ts.Segment, checksum uint32, _ ts.DatapointStats) error {
 					persists["bar"]++
 					assert.Equal(t, "bar", id.String())
 					assert.Equal(t, []byte{4, 5, 6}, segment.Head.Bytes())
@@ -355,7 +359,7 @@ func TestPeersSourceRunWithPersist(t *testing.T) {
 		mockFlush.EXPECT().
 			PrepareData(prepareOpts).
 			Return(persist.PreparedDataPersist{
-				Persist: func(id ident.ID, _ ident.Tags, segment ts.Segment, checksum uint32) error {
+				Persist: func(id ident.ID, _ ident.Tags, segment ts.Segment, checksum uint32, _ ts.DatapointStats) error {
 					persists["baz"]++
 					assert.Equal(t, "baz", id.String())
 					assert.Equal(t, []byte{7, 8, 9}, segment.Head.Bytes())
@@ -376,7 +380,7 @@ func TestPeersSourceRunWithPersist(t *testing.T) {
 		mockFlush.EXPECT().
 			PrepareData(prepareOpts).
 			Return(persist.PreparedDataPersist{
-				Persist: func(id ident.ID, _ ident.Tags, segment ts.Segment, checksum uint32) error {
+				Persist: func(id ident.ID, _ ident.Tags, segment ts.Segment, checksum uint32, _ ts.DatapointStats) error {
 					assert.Fail(t, "no expected shard 1 second block")
 					return nil
 				},
@@ -481,6 +485,7 @@ func TestPeersSourceMarksUnfulfilledOnPersistenceErrors(t *testing.T) {
 	var fooBlocks [2]block.DatabaseBlock
 	fooBlocks[0] = block.NewMockDatabaseBlock(ctrl)
 	fooBlocks[0].(*block.MockDatabaseBlock).EXPECT().StartTime().Return(start).AnyTimes()
+	fooBlocks[0].(*block.MockDatabaseBlock).EXPECT().Len().Return(0).AnyTimes()
 	fooBlocks[0].(*block.MockDatabaseBlock).EXPECT().Stream(gomock.Any()).Return(xio.EmptyBlockReader, fmt.Errorf("stream err"))
 	addResult(0, "foo", fooBlocks[0])
 
@@ -500,6 +505,7 @@ func TestPeersSourceMarksUnfulfilledOnPersistenceErrors(t *testing.T) {
 	var barBlocks [2]block.DatabaseBlock
 	barBlocks[0] = block.NewMockDatabaseBlock(ctrl)
 	barBlocks[0].(*block.MockDatabaseBlock).EXPECT().StartTime().Return(start).AnyTimes()
+	barBlocks[0].(*block.MockDatabaseBlock).EXPECT().Len().Return(0).AnyTimes()
 	barBlocks[0].(*block.MockDatabaseBlock).EXPECT().Stream(gomock.Any()).Return(b, nil)
 	addResult(1, "bar", barBlocks[0])
 
@@ -573,7 +579,7 @@ func TestPeersSourceMarksUnfulfilledOnPersistenceErrors(t *testing.T) {
 	mockFlush.EXPECT().
 		PrepareData(prepareOpts).
 		Return(persist.PreparedDataPersist{
-			Persist: func(id ident.ID, _ ident.Tags, segment ts.Segment, checksum uint32) error {
+			Persist: func(id ident.ID, _ ident.Tags, segment ts.Segment, checksum uint32, _ ts.DatapointStats) error {
 				assert.Fail(t, "not expecting to flush shard 0 at start")
 				return nil
 			},
@@ -591,7 +597,7 @@ func TestPeersSourceMarksUnfulfilledOnPersistenceErrors(t *testing.T) {
 	mockFlush.EXPECT().
 		PrepareData(prepareOpts).
 		Return(persist.PreparedDataPersist{
-			Persist: func(id ident.ID, _ ident.Tags, segment ts.Segment, checksum uint32) error {
+			Persist: func(id ident.ID, _ ident.Tags, segment ts.Segment, checksum uint32, _ ts.DatapointStats) error {
 				persists["foo"]++
 				return nil
 			},
@@ -611,7 +617,7 @@ func TestPeersSourceMarksUnfulfilledOnPersistenceErrors(t *testing.T) {
 	mockFlush.EXPECT().
 		PrepareData(prepareOpts).
 		Return(persist.PreparedDataPersist{
-			Persist: func(id ident.ID, _ ident.Tags, segment ts.Segment, checksum uint32) error {
+			Persist: func(id ident.ID, _ ident.Tags, segment ts.Segment, checksum uint32, _ ts.DatapointStats) error {
 				assert.Fail(t, "not expecting to flush shard 0 at start + block size")
 				return nil
 			},
@@ -629,7 +635,7 @@ func TestPeersSourceMarksUnfulfilledOnPersistenceErrors(t *testing.T) {
 	mockFlush.EXPECT().
 		PrepareData(prepareOpts).
 		Return(persist.PreparedDataPersist{
-			Persist: func(id ident.ID, _ ident.Tags, segment ts.Segment, checksum uint32) error {
+			Persist: func(id ident.ID, _ ident.Tags, segment ts.Segment, checksum uint32, _ ts.DatapointStats) error {
 				persists["bar"]++
 				return nil
 			},
@@ -649,7 +655,7 @@ func TestPeersSourceMarksUnfulfilledOnPersistenceErrors(t *testing.T) {
 	mockFlush.EXPECT().
 		PrepareData(prepareOpts).
 		Return(persist.PreparedDataPersist{
-			Persist: func(id ident.ID, _ ident.Tags, segment ts.Segment, checksum uint32) error {
+			Persist: func(id ident.ID, _ ident.Tags, segment ts.Segment, checksum uint32, _ ts.DatapointStats) error {
 				persists["baz"]++
 				return fmt.Errorf("a persist error")
 			},
@@ -667,7 +673,7 @@ func TestPeersSourceMarksUnfulfilledOnPersistenceErrors(t *testing.T) {
 	mockFlush.EXPECT().
 		PrepareData(prepareOpts).
 		Return(persist.PreparedDataPersist{
-			Persist: func(id ident.ID, _ ident.Tags, segment ts.Segment, checksum uint32) error {
+			Persist: func(id ident.ID, _ ident.Tags, segment ts.Segment, checksum uint32, _ ts.DatapointStats) error {
 				persists["baz"]++
 				return nil
 			},
@@ -687,7 +693,7 @@ func TestPeersSourceMarksUnfulfilledOnPersistenceErrors(t *testing.T) {
 	mockFlush.EXPECT().
 		PrepareData(prepareOpts).
 		Return(persist.PreparedDataPersist{
-			Persist: func(id ident.ID, _ ident.Tags, segment ts.Segment, checksum uint32) error {
+			Persist: func(id ident.ID, _ ident.Tags, segment ts.Segment, checksum uint32, _ ts.DatapointStats) error {
 				persists["qux"]++
 				return nil
 			},
@@ -705,7 +711,7 @@ func TestPeersSourceMarksUnfulfilledOnPersistenceErrors(t *testing.T) {
 	mockFlush.EXPECT().
 		PrepareData(prepareOpts).
 		Return(persist.PreparedDataPersist{
-			Persist: func(id ident.ID, _ ident.Tags, segment ts.Segment, checksum uint32) error {
+			Persist: func(id ident.ID, _ ident.Tags, segment ts.Segment, checksum uint32, _ ts.DatapointStats) error {
 				persists["qux"]++
 				return nil
 			},
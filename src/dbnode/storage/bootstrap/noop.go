@@ -27,11 +27,15 @@ import (
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
 )
 
-type noOpBootstrapProcessProvider struct{}
+type noOpBootstrapProcessProvider struct {
+	processOpts ProcessOptions
+}
 
 // NewNoOpProcessProvider creates a no-op bootstrap process proivder.
 func NewNoOpProcessProvider() ProcessProvider {
-	return noOpBootstrapProcessProvider{}
+	return noOpBootstrapProcessProvider{
+		processOpts: NewProcessOptions(),
+	}
 }
 
 func (b noOpBootstrapProcessProvider) SetBootstrapperProvider(provider BootstrapperProvider) {
@@ -45,6 +49,10 @@ func (b noOpBootstrapProcessProvider) Provide() (Process, error) {
 	return noOpBootstrapProcess{}, nil
 }
 
+func (b noOpBootstrapProcessProvider) ProcessOptions() ProcessOptions {
+	return b.processOpts
+}
+
 type noOpBootstrapProcess struct{}
 
 func (b noOpBootstrapProcess) Run(
@@ -104,6 +104,10 @@ func (b *bootstrapProcessProvider) Provide() (Process, error) {
 	}, nil
 }
 
+func (b *bootstrapProcessProvider) ProcessOptions() ProcessOptions {
+	return b.processOpts
+}
+
 func (b *bootstrapProcessProvider) newInitialTopologyState() (*topology.StateSnapshot, error) {
 	session, err := b.processOpts.AdminClient().DefaultAdminSession()
 	if err != nil {
@@ -158,16 +162,31 @@ func (b bootstrapProcess) Run(
 	namespace namespace.Metadata,
 	shards []uint32,
 ) (ProcessResult, error) {
-	dataResult, err := b.bootstrapData(start, namespace, shards)
+	var (
+		progress    = b.processOpts.Progress()
+		dataRanges  = b.targetRangesForData(start, namespace.Options().RetentionOptions())
+		indexRanges []TargetRange
+	)
+	// NB(r): If indexing not enable there are no index ranges to bootstrap
+	// and we just return an empty index result
+	if namespace.Options().IndexOptions().Enabled() {
+		indexRanges = b.targetRangesForIndex(start,
+			namespace.Options().RetentionOptions(), namespace.Options().IndexOptions())
+	}
+	progress.AddShards(len(shards))
+	progress.AddTimeRanges(len(dataRanges) + len(indexRanges))
+
+	dataResult, err := b.bootstrapData(dataRanges, namespace, shards)
 	if err != nil {
 		return ProcessResult{}, err
 	}
 
-	indexResult, err := b.bootstrapIndex(start, namespace, shards)
+	indexResult, err := b.bootstrapIndex(indexRanges, namespace, shards)
 	if err != nil {
 		return ProcessResult{}, err
 	}
 
+	progress.CompleteShards(len(shards))
 	return ProcessResult{
 		DataResult:  dataResult,
 		IndexResult: indexResult,
@@ -175,13 +194,11 @@ func (b bootstrapProcess) Run(
 }
 
 func (b bootstrapProcess) bootstrapData(
-	at time.Time,
+	targetRanges []TargetRange,
 	namespace namespace.Metadata,
 	shards []uint32,
 ) (result.DataBootstrapResult, error) {
 	bootstrapResult := result.NewDataBootstrapResult()
-	ropts := namespace.Options().RetentionOptions()
-	targetRanges := b.targetRangesForData(at, ropts)
 	for _, target := range targetRanges {
 		logFields := b.logFields(bootstrapDataRunType, namespace,
 			shards, target.Range)
@@ -197,6 +214,7 @@ func (b bootstrapProcess) bootstrapData(
 			return nil, err
 		}
 
+		b.processOpts.Progress().CompleteTimeRange()
 		bootstrapResult = result.MergedDataBootstrapResult(bootstrapResult, res)
 	}
 
@@ -204,19 +222,11 @@ func (b bootstrapProcess) bootstrapData(
 }
 
 func (b bootstrapProcess) bootstrapIndex(
-	at time.Time,
+	targetRanges []TargetRange,
 	namespace namespace.Metadata,
 	shards []uint32,
 ) (result.IndexBootstrapResult, error) {
 	bootstrapResult := result.NewIndexBootstrapResult()
-	ropts := namespace.Options().RetentionOptions()
-	idxopts := namespace.Options().IndexOptions()
-	if !idxopts.Enabled() {
-		// NB(r): If indexing not enable we just return an empty result
-		return result.NewIndexBootstrapResult(), nil
-	}
-
-	targetRanges := b.targetRangesForIndex(at, ropts, idxopts)
 	for _, target := range targetRanges {
 		logFields := b.logFields(bootstrapIndexRunType, namespace,
 			shards, target.Range)
@@ -232,6 +242,7 @@ func (b bootstrapProcess) bootstrapIndex(
 			return nil, err
 		}
 
+		b.processOpts.Progress().CompleteTimeRange()
 		bootstrapResult = result.MergedIndexBootstrapResult(bootstrapResult, res)
 	}
 
@@ -371,5 +382,6 @@ func (b bootstrapProcess) newRunOptions() RunOptions {
 		SetCacheSeriesMetadata(
 			b.processOpts.CacheSeriesMetadata(),
 		).
-		SetInitialTopologyState(b.initialTopologyState)
+		SetInitialTopologyState(b.initialTopologyState).
+		SetProgress(b.processOpts.Progress())
 }
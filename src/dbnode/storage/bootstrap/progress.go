@@ -0,0 +1,173 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package bootstrap
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// ProgressSource is a source of data read during a bootstrap.
+type ProgressSource int
+
+const (
+	// ProgressSourceFilesystem is data read from fileset files on disk.
+	ProgressSourceFilesystem ProgressSource = iota
+	// ProgressSourceCommitLog is data read from commit log files on disk.
+	ProgressSourceCommitLog
+	// ProgressSourcePeers is data streamed from peers.
+	ProgressSourcePeers
+
+	numProgressSources = iota
+)
+
+// String returns the name of the progress source.
+func (s ProgressSource) String() string {
+	switch s {
+	case ProgressSourceFilesystem:
+		return "filesystem"
+	case ProgressSourceCommitLog:
+		return "commitlog"
+	case ProgressSourcePeers:
+		return "peers"
+	}
+	return "unknown"
+}
+
+// Progress tracks how far along a bootstrap is so that it can be reported while
+// the bootstrap is still running. All updates are single atomic operations so it
+// is cheap to update from many goroutines at once.
+type Progress struct {
+	retries             int64
+	startedAt           int64
+	completedAt         int64
+	namespacesTotal     int64
+	namespacesComplete  int64
+	shardsTotal         int64
+	shardsComplete      int64
+	timeRangesRemaining int64
+	bytesRead           [numProgressSources]int64
+}
+
+// ProgressSnapshot is a point in time view of the progress of a bootstrap.
+type ProgressSnapshot struct {
+	Bootstrapping       bool             `json:"bootstrapping"`
+	Retries             int              `json:"retries"`
+	StartedAt           *time.Time       `json:"startedAt,omitempty"`
+	CompletedAt         *time.Time       `json:"completedAt,omitempty"`
+	NamespacesTotal     int              `json:"namespacesTotal"`
+	NamespacesComplete  int              `json:"namespacesComplete"`
+	ShardsTotal         int              `json:"shardsTotal"`
+	ShardsComplete      int              `json:"shardsComplete"`
+	TimeRangesRemaining int              `json:"timeRangesRemaining"`
+	BytesRead           map[string]int64 `json:"bytesRead"`
+}
+
+// NewProgress returns a new bootstrap progress tracker.
+func NewProgress() *Progress {
+	return &Progress{}
+}
+
+// Start resets the progress for a new bootstrap attempt, recording how many
+// times the bootstrap has been retried, e.g. due to a topology change.
+func (p *Progress) Start(retries int, at time.Time) {
+	atomic.StoreInt64(&p.namespacesTotal, 0)
+	atomic.StoreInt64(&p.namespacesComplete, 0)
+	atomic.StoreInt64(&p.shardsTotal, 0)
+	atomic.StoreInt64(&p.shardsComplete, 0)
+	atomic.StoreInt64(&p.timeRangesRemaining, 0)
+	for i := range p.bytesRead {
+		atomic.StoreInt64(&p.bytesRead[i], 0)
+	}
+	atomic.StoreInt64(&p.retries, int64(retries))
+	atomic.StoreInt64(&p.completedAt, 0)
+	atomic.StoreInt64(&p.startedAt, at.UnixNano())
+}
+
+// Complete marks the bootstrap as complete.
+func (p *Progress) Complete(at time.Time) {
+	atomic.StoreInt64(&p.completedAt, at.UnixNano())
+}
+
+// AddNamespaces adds to the number of namespaces to bootstrap.
+func (p *Progress) AddNamespaces(n int) {
+	atomic.AddInt64(&p.namespacesTotal, int64(n))
+}
+
+// CompleteNamespace marks a namespace as done bootstrapping.
+func (p *Progress) CompleteNamespace() {
+	atomic.AddInt64(&p.namespacesComplete, 1)
+}
+
+// AddShards adds to the number of shards to bootstrap, shards are counted
+// once for every namespace they are bootstrapped for.
+func (p *Progress) AddShards(n int) {
+	atomic.AddInt64(&p.shardsTotal, int64(n))
+}
+
+// CompleteShards marks a number of shards as done bootstrapping.
+func (p *Progress) CompleteShards(n int) {
+	atomic.AddInt64(&p.shardsComplete, int64(n))
+}
+
+// AddTimeRanges adds to the number of time ranges remaining to bootstrap.
+func (p *Progress) AddTimeRanges(n int) {
+	atomic.AddInt64(&p.timeRangesRemaining, int64(n))
+}
+
+// CompleteTimeRange marks a time range as done bootstrapping.
+func (p *Progress) CompleteTimeRange() {
+	atomic.AddInt64(&p.timeRangesRemaining, -1)
+}
+
+// AddBytesRead adds to the number of bytes read from a source.
+func (p *Progress) AddBytesRead(source ProgressSource, n int64) {
+	if source < 0 || source >= numProgressSources {
+		return
+	}
+	atomic.AddInt64(&p.bytesRead[source], n)
+}
+
+// Snapshot returns a point in time view of the progress.
+func (p *Progress) Snapshot() ProgressSnapshot {
+	snapshot := ProgressSnapshot{
+		Retries:             int(atomic.LoadInt64(&p.retries)),
+		NamespacesTotal:     int(atomic.LoadInt64(&p.namespacesTotal)),
+		NamespacesComplete:  int(atomic.LoadInt64(&p.namespacesComplete)),
+		ShardsTotal:         int(atomic.LoadInt64(&p.shardsTotal)),
+		ShardsComplete:      int(atomic.LoadInt64(&p.shardsComplete)),
+		TimeRangesRemaining: int(atomic.LoadInt64(&p.timeRangesRemaining)),
+		BytesRead:           make(map[string]int64, numProgressSources),
+	}
+	for i := range p.bytesRead {
+		snapshot.BytesRead[ProgressSource(i).String()] = atomic.LoadInt64(&p.bytesRead[i])
+	}
+	if startedAt := atomic.LoadInt64(&p.startedAt); startedAt != 0 {
+		t := time.Unix(0, startedAt)
+		snapshot.StartedAt = &t
+	}
+	if completedAt := atomic.LoadInt64(&p.completedAt); completedAt != 0 {
+		t := time.Unix(0, completedAt)
+		snapshot.CompletedAt = &t
+	}
+	snapshot.Bootstrapping = snapshot.StartedAt != nil && snapshot.CompletedAt == nil
+	return snapshot
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package bootstrap
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressConcurrentUpdates(t *testing.T) {
+	progress := NewProgress()
+	progress.Start(0, time.Now())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				progress.AddShards(1)
+				progress.AddTimeRanges(2)
+				progress.CompleteTimeRange()
+				progress.CompleteShards(1)
+				progress.AddBytesRead(ProgressSourceFilesystem, 10)
+			}
+		}()
+	}
+	wg.Wait()
+
+	snapshot := progress.Snapshot()
+	require.Equal(t, 800, snapshot.ShardsTotal)
+	require.Equal(t, 800, snapshot.ShardsComplete)
+	require.Equal(t, 800, snapshot.TimeRangesRemaining)
+	require.Equal(t, map[string]int64{
+		"filesystem": 8000,
+		"commitlog":  0,
+		"peers":      0,
+	}, snapshot.BytesRead)
+}
+
+func TestProgressStartResets(t *testing.T) {
+	progress := NewProgress()
+	require.False(t, progress.Snapshot().Bootstrapping)
+
+	start := time.Now()
+	progress.Start(0, start)
+	progress.AddNamespaces(3)
+	progress.CompleteNamespace()
+	progress.AddTimeRanges(4)
+	progress.AddBytesRead(ProgressSourceCommitLog, 100)
+
+	snapshot := progress.Snapshot()
+	require.True(t, snapshot.Bootstrapping)
+	require.True(t, start.Equal(*snapshot.StartedAt))
+	require.Nil(t, snapshot.CompletedAt)
+	require.Equal(t, 3, snapshot.NamespacesTotal)
+	require.Equal(t, 1, snapshot.NamespacesComplete)
+
+	retryStart := start.Add(time.Minute)
+	progress.Start(1, retryStart)
+
+	snapshot = progress.Snapshot()
+	require.True(t, snapshot.Bootstrapping)
+	require.Equal(t, 1, snapshot.Retries)
+	require.True(t, retryStart.Equal(*snapshot.StartedAt))
+	require.Equal(t, 0, snapshot.NamespacesTotal)
+	require.Equal(t, 0, snapshot.NamespacesComplete)
+	require.Equal(t, 0, snapshot.TimeRangesRemaining)
+	require.Equal(t, int64(0), snapshot.BytesRead["commitlog"])
+
+	progress.Complete(retryStart.Add(time.Minute))
+	snapshot = progress.Snapshot()
+	require.False(t, snapshot.Bootstrapping)
+	require.NotNil(t, snapshot.CompletedAt)
+}
@@ -22,6 +22,7 @@ package bootstrap
 
 import (
 	"errors"
+	"time"
 
 	"github.com/m3db/m3/src/dbnode/client"
 )
@@ -30,15 +31,23 @@ const (
 	// defaultCacheSeriesMetadata declares that by default bootstrap providers should
 	// cache series metadata between runs.
 	defaultCacheSeriesMetadata = true
+
+	// defaultProgressLogInterval is the default interval at which bootstrap
+	// progress is logged while bootstrapping.
+	defaultProgressLogInterval = 30 * time.Second
 )
 
 var (
-	errAdminClientShouldNotBeNil = errors.New("admin client should not be nil")
+	errAdminClientShouldNotBeNil   = errors.New("admin client should not be nil")
+	errProgressShouldNotBeNil      = errors.New("progress should not be nil")
+	errProgressLogIntervalNegative = errors.New("progress log interval must not be negative")
 )
 
 type processOptions struct {
 	cacheSeriesMetadata bool
 	adminClient         client.AdminClient
+	progress            *Progress
+	progressLogInterval time.Duration
 }
 
 // NewProcessOptions creates new bootstrap run options
@@ -46,6 +55,8 @@ func NewProcessOptions() ProcessOptions {
 	return &processOptions{
 		cacheSeriesMetadata: defaultCacheSeriesMetadata,
 		adminClient:         nil,
+		progress:            NewProgress(),
+		progressLogInterval: defaultProgressLogInterval,
 	}
 }
 
@@ -53,6 +64,12 @@ func (o *processOptions) Validate() error {
 	if o.adminClient == nil {
 		return errAdminClientShouldNotBeNil
 	}
+	if o.progress == nil {
+		return errProgressShouldNotBeNil
+	}
+	if o.progressLogInterval < 0 {
+		return errProgressLogIntervalNegative
+	}
 
 	return nil
 }
@@ -76,3 +93,23 @@ func (o *processOptions) SetAdminClient(value client.AdminClient) ProcessOptions
 func (o *processOptions) AdminClient() client.AdminClient {
 	return o.adminClient
 }
+
+func (o *processOptions) SetProgress(value *Progress) ProcessOptions {
+	opts := *o
+	opts.progress = value
+	return &opts
+}
+
+func (o *processOptions) Progress() *Progress {
+	return o.progress
+}
+
+func (o *processOptions) SetProgressLogInterval(value time.Duration) ProcessOptions {
+	opts := *o
+	opts.progressLogInterval = value
+	return &opts
+}
+
+func (o *processOptions) ProgressLogInterval() time.Duration {
+	return o.progressLogInterval
+}
@@ -44,6 +44,9 @@ type ProcessProvider interface {
 
 	// Provide constructs a bootstrap process.
 	Provide() (Process, error)
+
+	// ProcessOptions returns the options used to construct bootstrap processes.
+	ProcessOptions() ProcessOptions
 }
 
 // Process represents the bootstrap process. Note that a bootstrap process can and will
@@ -95,6 +98,20 @@ type ProcessOptions interface {
 	// AdminClient returns the admin client.
 	AdminClient() client.AdminClient
 
+	// SetProgress sets the progress tracker updated by bootstrap runs.
+	SetProgress(value *Progress) ProcessOptions
+
+	// Progress returns the progress tracker updated by bootstrap runs.
+	Progress() *Progress
+
+	// SetProgressLogInterval sets the interval at which bootstrap progress is
+	// logged while bootstrapping, zero disables logging progress.
+	SetProgressLogInterval(value time.Duration) ProcessOptions
+
+	// ProgressLogInterval returns the interval at which bootstrap progress is
+	// logged while bootstrapping, zero disables logging progress.
+	ProgressLogInterval() time.Duration
+
 	// Validate validates that the ProcessOptions are correct.
 	Validate() error
 }
@@ -122,6 +139,12 @@ type RunOptions interface {
 	// InitialTopologyState returns the initial topology as it was measured
 	// before the bootstrap process began.
 	InitialTopologyState() *topology.StateSnapshot
+
+	// SetProgress sets the progress tracker bootstrappers update as they read data.
+	SetProgress(value *Progress) RunOptions
+
+	// Progress returns the progress tracker bootstrappers update as they read data.
+	Progress() *Progress
 }
 
 // BootstrapperProvider constructs a bootstrapper.
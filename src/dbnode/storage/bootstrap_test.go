@@ -26,6 +26,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/storage/bootstrap"
 	"github.com/m3db/m3x/ident"
 
 	"github.com/golang/mock/gomock"
@@ -114,3 +115,67 @@ func TestDatabaseBootstrapSubsequentCallsQueued(t *testing.T) {
 	err := bsm.Bootstrap()
 	require.Nil(t, err)
 }
+
+func TestDatabaseBootstrapProgressResetsOnRetry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := testDatabaseOptions()
+	now := time.Now()
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return now
+	}))
+
+	m := NewMockdatabaseMediator(ctrl)
+	m.EXPECT().DisableFileOps()
+	m.EXPECT().EnableFileOps().AnyTimes()
+
+	db := NewMockdatabase(ctrl)
+
+	bsm := newBootstrapManager(db, m, opts).(*bootstrapManager)
+	progress := opts.BootstrapProcessProvider().ProcessOptions().Progress()
+
+	ns := NewMockdatabaseNamespace(ctrl)
+	ns.EXPECT().
+		Bootstrap(now, gomock.Any()).
+		Return(nil).
+		Do(func(arg0, arg1 interface{}) {
+			snapshot := progress.Snapshot()
+			assert.True(t, snapshot.Bootstrapping)
+			assert.Equal(t, 0, snapshot.Retries)
+			assert.Equal(t, 1, snapshot.NamespacesTotal)
+			assert.Equal(t, 0, snapshot.NamespacesComplete)
+			progress.AddBytesRead(bootstrap.ProgressSourcePeers, 1024)
+
+			// Enqueue a second bootstrap as a topology change would
+			assert.Equal(t, errBootstrapEnqueued, bsm.Bootstrap())
+
+			ns.EXPECT().
+				Bootstrap(now, gomock.Any()).
+				Return(nil).
+				Do(func(arg0, arg1 interface{}) {
+					snapshot := progress.Snapshot()
+					assert.True(t, snapshot.Bootstrapping)
+					assert.Equal(t, 1, snapshot.Retries)
+					assert.Equal(t, 0, snapshot.NamespacesComplete)
+					assert.Equal(t, int64(0), snapshot.BytesRead["peers"])
+				})
+		})
+	ns.EXPECT().
+		ID().
+		Return(ident.StringID("test")).
+		Times(2)
+	db.EXPECT().
+		GetOwnedNamespaces().
+		Return([]databaseNamespace{ns}, nil).
+		Times(2)
+
+	require.NoError(t, bsm.Bootstrap())
+
+	snapshot := progress.Snapshot()
+	require.False(t, snapshot.Bootstrapping)
+	require.NotNil(t, snapshot.CompletedAt)
+	require.Equal(t, 1, snapshot.Retries)
+	require.Equal(t, 1, snapshot.NamespacesTotal)
+	require.Equal(t, 1, snapshot.NamespacesComplete)
+}
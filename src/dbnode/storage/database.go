@@ -34,8 +34,11 @@ import (
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/index"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
+	"github.com/m3db/m3/src/dbnode/storage/series"
+	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/xcounter"
 	"github.com/m3db/m3/src/dbnode/x/xio"
+	xclose "github.com/m3db/m3x/close"
 	"github.com/m3db/m3x/context"
 	xerrors "github.com/m3db/m3x/errors"
 	"github.com/m3db/m3x/ident"
@@ -59,6 +62,12 @@ var (
 	errDatabaseIsClosed = errors.New("database is closed")
 )
 
+const (
+	// namespaceTeardownFlushRetryInterval is the interval at which the flush of a
+	// removed namespace is retried while other file operations are in progress.
+	namespaceTeardownFlushRetryInterval = time.Second
+)
+
 type databaseState int
 
 const (
@@ -88,6 +97,9 @@ type db struct {
 	created    uint64
 	bootstraps int
 
+	teardowns      sync.WaitGroup
+	teardownDoneCh chan struct{}
+
 	scope   tally.Scope
 	metrics databaseMetrics
 	log     xlog.Logger
@@ -95,31 +107,57 @@ type db struct {
 	errors       xcounter.FrequencyCounter
 	errWindow    time.Duration
 	errThreshold int64
+
+	writeShadower          *writeShadower
+	writeShadowingListener xclose.SimpleCloser
 }
 
 type databaseMetrics struct {
 	unknownNamespaceRead                tally.Counter
 	unknownNamespaceWrite               tally.Counter
 	unknownNamespaceWriteTagged         tally.Counter
+	unknownNamespaceWriteTaggedBatch    tally.Counter
 	unknownNamespaceFetchBlocks         tally.Counter
 	unknownNamespaceFetchBlocksMetadata tally.Counter
 	unknownNamespaceQueryIDs            tally.Counter
+	unknownNamespaceAggregateTerms      tally.Counter
+	unknownNamespaceAggregateTagValues  tally.Counter
 	errQueryIDsIndexDisabled            tally.Counter
 	errWriteTaggedIndexDisabled         tally.Counter
+	shutdownSnapshot                    shutdownSnapshotMetrics
+}
+
+type shutdownSnapshotMetrics struct {
+	completed tally.Counter
+	timedOut  tally.Counter
+	aborted   tally.Counter
+	errors    tally.Counter
+	latency   tally.Timer
 }
 
 func newDatabaseMetrics(scope tally.Scope) databaseMetrics {
 	unknownNamespaceScope := scope.SubScope("unknown-namespace")
 	indexDisabledScope := scope.SubScope("index-disabled")
+	shutdownSnapshotScope := scope.SubScope("shutdown-snapshot")
 	return databaseMetrics{
 		unknownNamespaceRead:                unknownNamespaceScope.Counter("read"),
 		unknownNamespaceWrite:               unknownNamespaceScope.Counter("write"),
 		unknownNamespaceWriteTagged:         unknownNamespaceScope.Counter("write-tagged"),
+		unknownNamespaceWriteTaggedBatch:    unknownNamespaceScope.Counter("write-tagged-batch"),
 		unknownNamespaceFetchBlocks:         unknownNamespaceScope.Counter("fetch-blocks"),
 		unknownNamespaceFetchBlocksMetadata: unknownNamespaceScope.Counter("fetch-blocks-metadata"),
 		unknownNamespaceQueryIDs:            unknownNamespaceScope.Counter("query-ids"),
+		unknownNamespaceAggregateTerms:      unknownNamespaceScope.Counter("aggregate-terms"),
+		unknownNamespaceAggregateTagValues:  unknownNamespaceScope.Counter("aggregate-tag-values"),
 		errQueryIDsIndexDisabled:            indexDisabledScope.Counter("err-query-ids"),
 		errWriteTaggedIndexDisabled:         indexDisabledScope.Counter("err-write-tagged"),
+		shutdownSnapshot: shutdownSnapshotMetrics{
+			completed: shutdownSnapshotScope.Counter("completed"),
+			timedOut:  shutdownSnapshotScope.Counter("timed-out"),
+			aborted:   shutdownSnapshotScope.Counter("aborted"),
+			errors:    shutdownSnapshotScope.Counter("errors"),
+			latency:   shutdownSnapshotScope.Timer("latency"),
+		},
 	}
 }
 
@@ -156,6 +194,13 @@ func NewDatabase(
 		errors:       xcounter.NewFrequencyCounter(opts.ErrorCounterOptions()),
 		errWindow:    opts.ErrorWindowForLoad(),
 		errThreshold: opts.ErrorThresholdForLoad(),
+
+		writeShadower:  newWriteShadower(scope),
+		teardownDoneCh: make(chan struct{}),
+	}
+
+	if runtimeOptsMgr := opts.RuntimeOptionsManager(); runtimeOptsMgr != nil {
+		d.writeShadowingListener = runtimeOptsMgr.RegisterListener(d.writeShadower)
 	}
 
 	databaseIOpts := iopts.SetMetricsScope(scope)
@@ -215,14 +260,18 @@ func (d *db) UpdateOwnedNamespaces(newNamespaces namespace.Map) error {
 		return err
 	}
 
-	// log that updates and removals are skipped
-	if len(removes) > 0 || len(updates) > 0 {
-		d.log.Warnf("skipping namespace removals and updates, restart process if you want changes to take effect.")
+	// stop accepting writes for any namespaces marked for removal and
+	// tear them down in the background
+	d.removeNamespacesWithLock(removes)
+
+	// log that updates are skipped
+	if len(updates) > 0 {
+		d.log.Warnf("skipping namespace updates, restart process if you want changes to take effect.")
 	}
 
-	// enqueue bootstraps if new namespaces
+	// bootstrap just the new namespaces, the others are already bootstrapped
 	if len(adds) > 0 {
-		d.queueBootstrapWithLock()
+		d.queueBootstrapNamespacesWithLock(adds)
 	}
 
 	return nil
@@ -294,7 +343,7 @@ func (d *db) logNamespaceUpdate(removes []ident.ID, adds, updates []namespace.Me
 	).Infof("updating database namespaces")
 
 	// NB(prateek): as noted in `UpdateOwnedNamespaces()` above, the current implementation
-	// does not apply updates, and removals until the m3dbnode process is restarted.
+	// does not apply updates until the m3dbnode process is restarted.
 
 	return nil
 }
@@ -317,6 +366,62 @@ func (d *db) addNamespacesWithLock(namespaces []namespace.Metadata) error {
 	return nil
 }
 
+func (d *db) removeNamespacesWithLock(ids []ident.ID) {
+	if len(ids) == 0 {
+		return
+	}
+
+	// NB: Removing the namespaces from the map immediately stops any new reads
+	// and writes from reaching them, the remaining teardown happens in the
+	// background so the namespace watch is not blocked by a flush.
+	removed := make([]databaseNamespace, 0, len(ids))
+	for _, id := range ids {
+		ns, ok := d.namespaces.Get(id)
+		if !ok {
+			continue
+		}
+		d.namespaces.Delete(id)
+		removed = append(removed, ns)
+	}
+
+	d.teardowns.Add(1)
+	go func() {
+		defer d.teardowns.Done()
+		for _, ns := range removed {
+			d.teardownNamespace(ns)
+		}
+	}()
+}
+
+func (d *db) teardownNamespace(ns databaseNamespace) {
+	id := ns.ID().String()
+	if d.opts.NamespaceRemovalFlushEnabled() {
+		for {
+			err := d.mediator.FlushNamespace(ns)
+			if err == nil {
+				break
+			}
+			if err != errFileOpsUnavailable {
+				d.log.Errorf("error flushing removed namespace %s: %v", id, err)
+				break
+			}
+
+			select {
+			case <-d.teardownDoneCh:
+				d.log.Warnf("database closed before removed namespace %s was flushed", id)
+				return
+			case <-time.After(namespaceTeardownFlushRetryInterval):
+			}
+		}
+	}
+
+	if err := ns.Close(); err != nil {
+		d.log.Errorf("error closing removed namespace %s: %v", id, err)
+		return
+	}
+	d.log.Infof("removed namespace %s", id)
+}
+
 func (d *db) newDatabaseNamespaceWithLock(
 	md namespace.Metadata,
 ) (databaseNamespace, error) {
@@ -324,7 +429,9 @@ func (d *db) newDatabaseNamespaceWithLock(
 		retriever block.DatabaseBlockRetriever
 		err       error
 	)
-	if mgr := d.opts.DatabaseBlockRetrieverManager(); mgr != nil {
+	// Namespaces that are never flushed have no fileset files to retrieve
+	// blocks from so their blocks are only ever held in memory
+	if mgr := d.opts.DatabaseBlockRetrieverManager(); mgr != nil && md.Options().FlushEnabled() {
 		retriever, err = mgr.Retriever(md)
 		if err != nil {
 			return nil, err
@@ -369,6 +476,51 @@ func (d *db) queueBootstrapWithLock() {
 	}
 }
 
+// queueBootstrapNamespacesWithLock bootstraps the given namespaces in the
+// background without bootstrapping the rest of the database.
+func (d *db) queueBootstrapNamespacesWithLock(mds []namespace.Metadata) {
+	// NB: until the database is first bootstrapped the new namespaces are
+	// bootstrapped along with every other namespace.
+	if d.bootstraps == 0 {
+		return
+	}
+
+	namespaces := make([]databaseNamespace, 0, len(mds))
+	for _, md := range mds {
+		if ns, ok := d.namespaces.Get(md.ID()); ok {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	go func() {
+		if err := d.bootstrapNamespaces(namespaces); err != nil {
+			d.log.Errorf("error while bootstrapping added namespaces: %v", err)
+		}
+	}()
+}
+
+func (d *db) bootstrapNamespaces(namespaces []databaseNamespace) error {
+	// NB: construct a new instance of the bootstrap process to avoid state
+	// being kept around by bootstrappers.
+	process, err := d.opts.BootstrapProcessProvider().Provide()
+	if err != nil {
+		return err
+	}
+
+	var (
+		start    = d.nowFn()
+		multiErr = xerrors.NewMultiError()
+	)
+	for _, ns := range namespaces {
+		if err := ns.Bootstrap(start, process); err != nil {
+			multiErr = multiErr.Add(fmt.Errorf(
+				"namespace %s failed to bootstrap: %v", ns.ID().String(), err))
+			continue
+		}
+		d.log.Infof("bootstrapped added namespace %s", ns.ID().String())
+	}
+	return multiErr.FinalError()
+}
+
 func (d *db) Namespace(id ident.ID) (Namespace, bool) {
 	d.RLock()
 	defer d.RUnlock()
@@ -420,6 +572,9 @@ func (d *db) terminateWithLock() error {
 	}
 	d.state = databaseClosed
 
+	// abort any pending flushes of removed namespaces
+	close(d.teardownDoneCh)
+
 	// close the mediator
 	if err := d.mediator.Close(); err != nil {
 		return err
@@ -430,6 +585,12 @@ func (d *db) terminateWithLock() error {
 		return err
 	}
 
+	// stop listening for write shadowing rule changes
+	if d.writeShadowingListener != nil {
+		d.writeShadowingListener.Close()
+		d.writeShadowingListener = nil
+	}
+
 	// Stop the wired list
 	if wiredList := d.opts.DatabaseBlockOptions().WiredList(); wiredList != nil {
 		err := wiredList.Stop()
@@ -454,6 +615,30 @@ func (d *db) Terminate() error {
 	return d.terminateWithLock()
 }
 
+func (d *db) ShutdownSnapshot(deadline time.Time, abortCh <-chan struct{}) error {
+	d.RLock()
+	if d.state != databaseOpen {
+		d.RUnlock()
+		return errDatabaseNotOpen
+	}
+	d.RUnlock()
+
+	start := d.nowFn()
+	err := d.mediator.ShutdownSnapshot(deadline, abortCh)
+	d.metrics.shutdownSnapshot.latency.Record(d.nowFn().Sub(start))
+	switch err {
+	case nil:
+		d.metrics.shutdownSnapshot.completed.Inc(1)
+	case errShutdownSnapshotDeadlineExceeded:
+		d.metrics.shutdownSnapshot.timedOut.Inc(1)
+	case errShutdownSnapshotAborted:
+		d.metrics.shutdownSnapshot.aborted.Inc(1)
+	default:
+		d.metrics.shutdownSnapshot.errors.Inc(1)
+	}
+	return err
+}
+
 func (d *db) Close() error {
 	d.Lock()
 	defer d.Unlock()
@@ -466,6 +651,9 @@ func (d *db) Close() error {
 		return err
 	}
 
+	// wait for any removed namespaces to finish their teardown
+	d.teardowns.Wait()
+
 	var multiErr xerrors.MultiError
 	for _, ns := range namespaces {
 		multiErr = multiErr.Add(ns.Close())
@@ -493,7 +681,16 @@ func (d *db) Write(
 	if err == commitlog.ErrCommitLogQueueFull {
 		d.errors.Record(1)
 	}
-	return err
+	if err != nil {
+		return err
+	}
+
+	if targets := d.writeShadower.targets(namespace, id); len(targets) > 0 {
+		d.shadowWrite(targets, func(n databaseNamespace) error {
+			return n.Write(ctx, id, timestamp, value, unit, annotation)
+		})
+	}
+	return nil
 }
 
 func (d *db) WriteTagged(
@@ -516,7 +713,120 @@ func (d *db) WriteTagged(
 	if err == commitlog.ErrCommitLogQueueFull {
 		d.errors.Record(1)
 	}
-	return err
+	if err != nil {
+		return err
+	}
+
+	if targets := d.writeShadower.targets(namespace, id); len(targets) > 0 {
+		d.shadowWrite(targets, func(n databaseNamespace) error {
+			return n.WriteTagged(ctx, id, tags, timestamp, value, unit, annotation)
+		})
+	}
+	return nil
+}
+
+func (d *db) WriteTaggedBatch(
+	ctx context.Context,
+	namespace ident.ID,
+	id ident.ID,
+	tags ident.TagIterator,
+	datapoints []ts.Datapoint,
+	unit xtime.Unit,
+	annotations [][]byte,
+) ([]error, error) {
+	n, err := d.namespaceFor(namespace)
+	if err != nil {
+		d.metrics.unknownNamespaceWriteTaggedBatch.Inc(1)
+		return nil, err
+	}
+
+	errs, err := n.WriteTaggedBatch(ctx, id, tags, datapoints, unit, annotations)
+	if err == commitlog.ErrCommitLogQueueFull {
+		d.errors.Record(1)
+	}
+	written := len(datapoints)
+	for _, err := range errs {
+		if err == commitlog.ErrCommitLogQueueFull {
+			d.errors.Record(1)
+		}
+		if err != nil {
+			written--
+		}
+	}
+	if err != nil || written == 0 {
+		return errs, err
+	}
+
+	if targets := d.writeShadower.targets(namespace, id); len(targets) > 0 {
+		// Only the datapoints that were written to the namespace are mirrored.
+		if written < len(datapoints) {
+			datapoints, annotations = writtenDatapoints(datapoints, annotations, errs, written)
+		}
+		d.shadowWrite(targets, func(n databaseNamespace) error {
+			mirrorErrs, err := n.WriteTaggedBatch(ctx, id, tags, datapoints, unit, annotations)
+			if err != nil {
+				return err
+			}
+			for _, err := range mirrorErrs {
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	return errs, nil
+}
+
+// shadowWrite mirrors a write that succeeded to the namespaces targeted by the
+// write shadowing rules, failures are counted but never returned since the
+// write to the namespace written to has already succeeded.
+// NB: the writes are mirrored synchronously and add to the latency of the
+// write being mirrored. The ID, tags and annotations of a write belong to the
+// caller's context, so mirroring asynchronously would mean copying them for
+// every mirrored write, which is why the rules default to off instead.
+func (d *db) shadowWrite(
+	targets []writeShadowingTarget,
+	write func(n databaseNamespace) error,
+) {
+	for _, target := range targets {
+		n, err := d.namespaceFor(target.namespace)
+		if err == nil {
+			err = write(n)
+		}
+		if err != nil {
+			d.writeShadower.metrics.errors.Inc(1)
+			continue
+		}
+		d.writeShadower.metrics.writes.Inc(1)
+	}
+}
+
+// writtenDatapoints returns the datapoints of a batch, and their annotations
+// if any, that were written without error.
+func writtenDatapoints(
+	datapoints []ts.Datapoint,
+	annotations [][]byte,
+	errs []error,
+	written int,
+) ([]ts.Datapoint, [][]byte) {
+	var (
+		filtered            = make([]ts.Datapoint, 0, written)
+		filteredAnnotations [][]byte
+	)
+	if annotations != nil {
+		filteredAnnotations = make([][]byte, 0, written)
+	}
+	for i, dp := range datapoints {
+		if i < len(errs) && errs[i] != nil {
+			continue
+		}
+		filtered = append(filtered, dp)
+		if annotations != nil {
+			filteredAnnotations = append(filteredAnnotations, annotations[i])
+		}
+	}
+	return filtered, filteredAnnotations
 }
 
 func (d *db) QueryIDs(
@@ -544,6 +854,55 @@ func (d *db) QueryIDs(
 	return queryResults, err
 }
 
+func (d *db) AggregateTerms(
+	ctx context.Context,
+	namespace ident.ID,
+	query index.Query,
+	opts index.QueryOptions,
+) (index.AggregateQueryResults, error) {
+	n, err := d.namespaceFor(namespace)
+	if err != nil {
+		d.metrics.unknownNamespaceAggregateTerms.Inc(1)
+		return index.AggregateQueryResults{}, err
+	}
+
+	var (
+		wg               = sync.WaitGroup{}
+		aggregateResults index.AggregateQueryResults
+	)
+	wg.Add(1)
+	d.opts.QueryIDsWorkerPool().Go(func() {
+		aggregateResults, err = n.AggregateTerms(ctx, query, opts)
+		wg.Done()
+	})
+	wg.Wait()
+	return aggregateResults, err
+}
+
+func (d *db) AggregateTagValues(
+	ctx context.Context,
+	namespace ident.ID,
+	opts index.AggregateTagValuesOptions,
+) (index.AggregateTagValuesResults, error) {
+	n, err := d.namespaceFor(namespace)
+	if err != nil {
+		d.metrics.unknownNamespaceAggregateTagValues.Inc(1)
+		return index.AggregateTagValuesResults{}, err
+	}
+
+	var (
+		wg        = sync.WaitGroup{}
+		tagValues index.AggregateTagValuesResults
+	)
+	wg.Add(1)
+	d.opts.QueryIDsWorkerPool().Go(func() {
+		tagValues, err = n.AggregateTagValues(ctx, opts)
+		wg.Done()
+	})
+	wg.Wait()
+	return tagValues, err
+}
+
 func (d *db) ReadEncoded(
 	ctx context.Context,
 	namespace ident.ID,
@@ -559,6 +918,18 @@ func (d *db) ReadEncoded(
 	return n.ReadEncoded(ctx, id, start, end)
 }
 
+func (d *db) BucketsInfo(
+	namespace ident.ID,
+	id ident.ID,
+) ([]series.BucketInfo, error) {
+	n, err := d.namespaceFor(namespace)
+	if err != nil {
+		return nil, xerrors.NewInvalidParamsError(err)
+	}
+
+	return n.BucketsInfo(id)
+}
+
 func (d *db) FetchBlocks(
 	ctx context.Context,
 	namespace ident.ID,
@@ -636,6 +1007,14 @@ func (d *db) Truncate(namespace ident.ID) (int64, error) {
 	return n.Truncate()
 }
 
+func (d *db) ForceFlush(namespace ident.ID, opts ForceFlushOptions) (ForceFlushResult, error) {
+	n, err := d.namespaceFor(namespace)
+	if err != nil {
+		return ForceFlushResult{}, err
+	}
+	return d.mediator.ForceFlush(n, opts)
+}
+
 func (d *db) IsOverloaded() bool {
 	return d.errors.Count(d.errWindow) > d.errThreshold
 }
@@ -33,10 +33,12 @@ import (
 	"github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/sharding"
 	"github.com/m3db/m3/src/dbnode/storage/block"
+	"github.com/m3db/m3/src/dbnode/storage/bootstrap"
 	"github.com/m3db/m3/src/dbnode/storage/index"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3/src/dbnode/storage/repair"
 	"github.com/m3db/m3/src/dbnode/storage/series"
+	"github.com/m3db/m3/src/dbnode/ts"
 	xmetrics "github.com/m3db/m3/src/dbnode/x/metrics"
 	"github.com/m3db/m3cluster/shard"
 	xclock "github.com/m3db/m3x/clock"
@@ -555,8 +557,16 @@ func TestDatabaseRemoveNamespace(t *testing.T) {
 		return ok && counter == 1
 	}, 2*time.Second))
 	require.True(t, xclock.WaitUntil(func() bool {
-		return len(d.Namespaces()) == 2
+		return len(d.Namespaces()) == 1
 	}, 2*time.Second))
+
+	// ensure the removed namespace no longer accepts writes
+	_, ok := d.Namespace(defaultTestNs2ID)
+	require.False(t, ok)
+	ctx := context.NewContext()
+	defer ctx.Close()
+	err = d.Write(ctx, defaultTestNs2ID, ident.StringID("foo"), time.Now(), 1.0, xtime.Second, nil)
+	require.Error(t, err)
 }
 
 func TestDatabaseAddNamespace(t *testing.T) {
@@ -620,6 +630,29 @@ func TestDatabaseAddNamespace(t *testing.T) {
 	require.Equal(t, md1.Options(), ns3.Options())
 }
 
+func TestDatabaseBootstrapNamespaces(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	d, mapCh, _ := newTestDatabase(t, ctrl, Bootstrapped)
+	defer func() {
+		close(mapCh)
+	}()
+
+	process := bootstrap.NewMockProcess(ctrl)
+	provider := bootstrap.NewMockProcessProvider(ctrl)
+	provider.EXPECT().Provide().Return(process, nil)
+	d.opts = d.opts.SetBootstrapProcessProvider(provider)
+
+	// Only the namespaces given are bootstrapped, without bootstrapping the
+	// rest of the database.
+	ns := NewMockdatabaseNamespace(ctrl)
+	ns.EXPECT().ID().Return(ident.StringID("and1")).AnyTimes()
+	ns.EXPECT().Bootstrap(gomock.Any(), process).Return(nil)
+	require.NoError(t, d.bootstrapNamespaces([]databaseNamespace{ns}))
+	require.True(t, d.IsBootstrapped())
+}
+
 func TestDatabaseUpdateNamespace(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -714,6 +747,67 @@ func TestDatabaseNamespaceIndexFunctions(t *testing.T) {
 	require.NoError(t, d.Close())
 }
 
+func TestDatabaseWriteShadowing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	d, mapCh, _ := newTestDatabase(t, ctrl, Bootstrapped)
+	defer func() {
+		close(mapCh)
+	}()
+
+	src := dbAddNewMockNamespace(ctrl, d, "src")
+	dst := dbAddNewMockNamespace(ctrl, d, "dst")
+	d.writeShadower.SetRuntimeOptions(runtime.NewOptions().SetWriteShadowingRules(
+		[]runtime.WriteShadowingRule{
+			{SourceNamespace: "src", TargetNamespace: "dst", Fraction: 1, Enabled: true},
+			{SourceNamespace: "src", TargetNamespace: "unknown", Fraction: 1, Enabled: true},
+		}))
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	// Successful writes are mirrored.
+	src.EXPECT().Write(ctx, ident.NewIDMatcher("foo"), time.Time{}, 1.0,
+		xtime.Second, nil).Return(nil)
+	dst.EXPECT().Write(ctx, ident.NewIDMatcher("foo"), time.Time{}, 1.0,
+		xtime.Second, nil).Return(nil)
+	require.NoError(t, d.Write(ctx, ident.StringID("src"), ident.StringID("foo"),
+		time.Time{}, 1.0, xtime.Second, nil))
+
+	// Mirror failures are not returned.
+	src.EXPECT().WriteTagged(ctx, ident.NewIDMatcher("foo"), gomock.Any(),
+		time.Time{}, 1.0, xtime.Second, nil).Return(nil)
+	dst.EXPECT().WriteTagged(ctx, ident.NewIDMatcher("foo"), gomock.Any(),
+		time.Time{}, 1.0, xtime.Second, nil).Return(fmt.Errorf("random err"))
+	require.NoError(t, d.WriteTagged(ctx, ident.StringID("src"),
+		ident.StringID("foo"), ident.EmptyTagIterator, time.Time{}, 1.0,
+		xtime.Second, nil))
+
+	// Failed writes are not mirrored.
+	src.EXPECT().Write(ctx, ident.NewIDMatcher("foo"), time.Time{}, 1.0,
+		xtime.Second, nil).Return(fmt.Errorf("random err"))
+	require.Error(t, d.Write(ctx, ident.StringID("src"), ident.StringID("foo"),
+		time.Time{}, 1.0, xtime.Second, nil))
+
+	// Only the datapoints of a batch that were written are mirrored.
+	var (
+		now         = time.Now()
+		datapoints  = []ts.Datapoint{{Timestamp: now, Value: 1}, {Timestamp: now, Value: 2}}
+		annotations = [][]byte{[]byte("a"), []byte("b")}
+		batchErrs   = []error{fmt.Errorf("random err"), nil}
+	)
+	src.EXPECT().WriteTaggedBatch(ctx, ident.NewIDMatcher("foo"), gomock.Any(),
+		datapoints, xtime.Second, annotations).Return(batchErrs, nil)
+	dst.EXPECT().WriteTaggedBatch(ctx, ident.NewIDMatcher("foo"), gomock.Any(),
+		datapoints[1:], xtime.Second, annotations[1:]).Return(nil, nil)
+	errs, err := d.WriteTaggedBatch(ctx, ident.StringID("src"),
+		ident.StringID("foo"), ident.EmptyTagIterator, datapoints, xtime.Second,
+		annotations)
+	require.NoError(t, err)
+	require.Equal(t, batchErrs, errs)
+}
+
 func TestDatabaseBootstrapState(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -33,3 +33,128 @@ var (
 	// ErrTooPast is returned for a write which is too far in the past.
 	ErrTooPast = xerrors.NewInvalidParamsError(errors.New("datapoint is too far in the past"))
 )
+
+type resourceExhaustedError struct {
+	err error
+}
+
+// NewResourceExhaustedError wraps an error to classify it as a write being
+// refused as a resource is exhausted, such as a limit on the rate of new
+// series or a full queue, which callers should back off from rather than
+// retry immediately.
+func NewResourceExhaustedError(err error) error {
+	return resourceExhaustedError{err: err}
+}
+
+func (e resourceExhaustedError) Error() string {
+	return e.err.Error()
+}
+
+func (e resourceExhaustedError) InnerError() error {
+	return e.err
+}
+
+// IsResourceExhausted returns whether the error or any error it wraps is a
+// resource exhausted error.
+func IsResourceExhausted(err error) bool {
+	for err != nil {
+		if _, ok := err.(resourceExhaustedError); ok {
+			return true
+		}
+		err = xerrors.InnerError(err)
+	}
+	return false
+}
+
+type internalError struct {
+	err error
+}
+
+// NewInternalError wraps an error to classify it as an internal failure,
+// such as an encoder error or an invariant violation, that is neither the
+// fault of the caller nor the result of a resource being exhausted.
+func NewInternalError(err error) error {
+	return internalError{err: err}
+}
+
+func (e internalError) Error() string {
+	return e.err.Error()
+}
+
+func (e internalError) InnerError() error {
+	return e.err
+}
+
+// IsInternal returns whether the error or any error it wraps is an internal
+// error.
+func IsInternal(err error) bool {
+	for err != nil {
+		if _, ok := err.(internalError); ok {
+			return true
+		}
+		err = xerrors.InnerError(err)
+	}
+	return false
+}
+
+type deadlineExceededError struct {
+	err error
+}
+
+// NewDeadlineExceededError wraps an error to classify it as an operation
+// being aborted as the deadline of the request it serves has expired.
+func NewDeadlineExceededError(err error) error {
+	return deadlineExceededError{err: err}
+}
+
+func (e deadlineExceededError) Error() string {
+	return e.err.Error()
+}
+
+func (e deadlineExceededError) InnerError() error {
+	return e.err
+}
+
+// IsDeadlineExceeded returns whether the error or any error it wraps is a
+// deadline exceeded error.
+func IsDeadlineExceeded(err error) bool {
+	for err != nil {
+		if _, ok := err.(deadlineExceededError); ok {
+			return true
+		}
+		err = xerrors.InnerError(err)
+	}
+	return false
+}
+
+type shardUnavailableError struct {
+	err error
+}
+
+// NewShardUnavailableError wraps an error to classify it as a request being
+// refused as the shard it targets is unavailable on this node, such as while
+// the circuit breaker of the shard is open, which callers should retry
+// against the other replicas of the shard.
+func NewShardUnavailableError(err error) error {
+	return shardUnavailableError{err: err}
+}
+
+func (e shardUnavailableError) Error() string {
+	return e.err.Error()
+}
+
+func (e shardUnavailableError) InnerError() error {
+	return e.err
+}
+
+// IsShardUnavailable returns whether the error or any error it wraps is a
+// shard unavailable error.
+func IsShardUnavailable(err error) bool {
+	for err != nil {
+		if _, ok := err.(shardUnavailableError); ok {
+			return true
+		}
+		err = xerrors.InnerError(err)
+	}
+	return false
+}
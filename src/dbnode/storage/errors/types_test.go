@@ -0,0 +1,71 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package errors
+
+import (
+	"errors"
+	"testing"
+
+	xerrors "github.com/m3db/m3x/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorClassification(t *testing.T) {
+	inner := errors.New("inner")
+
+	resourceExhausted := NewResourceExhaustedError(inner)
+	assert.Equal(t, inner.Error(), resourceExhausted.Error())
+	assert.True(t, IsResourceExhausted(resourceExhausted))
+	assert.False(t, IsInternal(resourceExhausted))
+	assert.False(t, xerrors.IsInvalidParams(resourceExhausted))
+
+	internal := NewInternalError(inner)
+	assert.Equal(t, inner.Error(), internal.Error())
+	assert.True(t, IsInternal(internal))
+	assert.False(t, IsResourceExhausted(internal))
+	assert.False(t, xerrors.IsInvalidParams(internal))
+
+	deadlineExceeded := NewDeadlineExceededError(inner)
+	assert.Equal(t, inner.Error(), deadlineExceeded.Error())
+	assert.True(t, IsDeadlineExceeded(deadlineExceeded))
+	assert.False(t, IsInternal(deadlineExceeded))
+	assert.False(t, IsResourceExhausted(deadlineExceeded))
+
+	shardUnavailable := NewShardUnavailableError(inner)
+	assert.Equal(t, inner.Error(), shardUnavailable.Error())
+	assert.True(t, IsShardUnavailable(shardUnavailable))
+	assert.False(t, IsInternal(shardUnavailable))
+	assert.False(t, IsResourceExhausted(shardUnavailable))
+
+	// Classification is retained when wrapped.
+	renamed := xerrors.NewRenamedError(resourceExhausted, errors.New("renamed"))
+	assert.True(t, IsResourceExhausted(renamed))
+
+	assert.False(t, IsResourceExhausted(inner))
+	assert.False(t, IsInternal(inner))
+	assert.False(t, IsResourceExhausted(nil))
+	assert.False(t, IsDeadlineExceeded(inner))
+	assert.False(t, IsInternal(nil))
+	assert.False(t, IsDeadlineExceeded(nil))
+	assert.False(t, IsShardUnavailable(inner))
+	assert.False(t, IsShardUnavailable(nil))
+}
@@ -29,12 +29,16 @@ import (
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/retention"
 	xerrors "github.com/m3db/m3x/errors"
+	xlog "github.com/m3db/m3x/log"
 
 	"github.com/uber-go/tally"
 )
 
 var (
 	errFlushOperationsInProgress = errors.New("flush operations already in progress")
+
+	errShutdownSnapshotDeadlineExceeded = errors.New("shutdown snapshot deadline exceeded")
+	errShutdownSnapshotAborted          = errors.New("shutdown snapshot aborted")
 )
 
 type flushManagerState int
@@ -168,6 +172,198 @@ func (m *flushManager) Flush(
 	return multiErr.FinalError()
 }
 
+func (m *flushManager) ShutdownSnapshot(
+	snapshotTime time.Time,
+	deadline time.Time,
+	abortCh <-chan struct{},
+) error {
+	m.Lock()
+	if m.state != flushManagerIdle {
+		m.Unlock()
+		return errFlushOperationsInProgress
+	}
+	m.state = flushManagerSnapshotInProgress
+	m.Unlock()
+
+	defer m.setState(flushManagerIdle)
+
+	flush, err := m.pm.StartDataPersist()
+	if err != nil {
+		return err
+	}
+
+	namespaces, err := m.database.GetOwnedNamespaces()
+	if err != nil {
+		return err
+	}
+
+	var (
+		log      = m.opts.InstrumentOptions().Logger()
+		nowFn    = m.opts.ClockOptions().NowFn()
+		multiErr = xerrors.NewMultiError()
+		stopErr  error
+	)
+	for _, ns := range namespaces {
+		if stopErr != nil {
+			break
+		}
+		if !ns.Options().FlushEnabled() || !ns.Options().SnapshotEnabled() {
+			continue
+		}
+
+		// NB: Unlike the regular snapshots the minimum snapshot interval is ignored and
+		// every shard is snapshotted, since this is the last chance to capture its buffers.
+		snapshotBlockStart := m.snapshotBlockStart(ns, snapshotTime)
+		for _, shard := range ns.GetOwnedShards() {
+			select {
+			case <-abortCh:
+				stopErr = errShutdownSnapshotAborted
+			default:
+				if !nowFn().Before(deadline) {
+					stopErr = errShutdownSnapshotDeadlineExceeded
+				}
+			}
+			if stopErr != nil {
+				break
+			}
+
+			logger := log.WithFields(
+				xlog.NewField("namespace", ns.ID().String()),
+				xlog.NewField("shard", shard.ID()),
+			)
+			start := nowFn()
+			if err := shard.Snapshot(snapshotBlockStart, snapshotTime, flush); err != nil {
+				logger.Errorf("shutdown snapshot failed: %v", err)
+				multiErr = multiErr.Add(fmt.Errorf(
+					"namespace %s shard %d failed to snapshot: %v", ns.ID().String(), shard.ID(), err))
+				continue
+			}
+			logger.Infof("shutdown snapshot completed, took %s", nowFn().Sub(start))
+		}
+	}
+
+	multiErr = multiErr.Add(flush.DoneData())
+	if stopErr != nil {
+		// Return the reason the snapshot stopped early in preference to any shard errors.
+		return stopErr
+	}
+	return multiErr.FinalError()
+}
+
+func (m *flushManager) FlushNamespace(ns databaseNamespace, tickStart time.Time) error {
+	m.Lock()
+	if m.state != flushManagerIdle {
+		m.Unlock()
+		return errFlushOperationsInProgress
+	}
+	m.state = flushManagerFlushInProgress
+	m.Unlock()
+
+	defer m.setState(flushManagerIdle)
+
+	flush, err := m.pm.StartDataPersist()
+	if err != nil {
+		return err
+	}
+
+	multiErr := xerrors.NewMultiError()
+	flushTimes := m.namespaceFlushTimes(ns, tickStart)
+	multiErr = multiErr.Add(m.flushNamespaceWithTimes(ns, ns.BootstrapState(), flushTimes, flush))
+
+	if ns.Options().FlushEnabled() && ns.Options().SnapshotEnabled() {
+		// NB: As with the shutdown snapshot every shard is snapshotted since this is
+		// the last chance to capture the buffers of the namespace.
+		m.setState(flushManagerSnapshotInProgress)
+		snapshotBlockStart := m.snapshotBlockStart(ns, tickStart)
+		for _, shard := range ns.GetOwnedShards() {
+			if err := shard.Snapshot(snapshotBlockStart, tickStart, flush); err != nil {
+				multiErr = multiErr.Add(fmt.Errorf(
+					"namespace %s shard %d failed to snapshot: %v", ns.ID().String(), shard.ID(), err))
+			}
+		}
+	}
+	multiErr = multiErr.Add(flush.DoneData())
+
+	if !ns.Options().IndexOptions().Enabled() {
+		return multiErr.FinalError()
+	}
+
+	indexFlush, err := m.pm.StartIndexPersist()
+	if err != nil {
+		multiErr = multiErr.Add(err)
+		return multiErr.FinalError()
+	}
+
+	m.setState(flushManagerIndexFlushInProgress)
+	multiErr = multiErr.Add(ns.FlushIndex(indexFlush))
+	multiErr = multiErr.Add(indexFlush.DoneIndex())
+
+	return multiErr.FinalError()
+}
+
+func (m *flushManager) ForceFlush(
+	ns databaseNamespace,
+	opts ForceFlushOptions,
+	t time.Time,
+) (ForceFlushResult, error) {
+	m.Lock()
+	if m.state != flushManagerIdle {
+		m.Unlock()
+		return ForceFlushResult{}, errFlushOperationsInProgress
+	}
+	m.state = flushManagerFlushInProgress
+	m.Unlock()
+
+	defer m.setState(flushManagerIdle)
+
+	flush, err := m.pm.StartDataPersist()
+	if err != nil {
+		return ForceFlushResult{}, err
+	}
+
+	var (
+		result   ForceFlushResult
+		multiErr = xerrors.NewMultiError()
+	)
+	for _, blockStart := range m.forceFlushTimes(ns, opts, t) {
+		r, err := ns.ForceFlush(blockStart, opts, flush)
+		result.add(r)
+		if err != nil {
+			detailedErr := fmt.Errorf("namespace %s failed to force flush data: %v",
+				ns.ID().String(), err)
+			multiErr = multiErr.Add(detailedErr)
+		}
+	}
+	multiErr = multiErr.Add(flush.DoneData())
+
+	return result, multiErr.FinalError()
+}
+
+// forceFlushTimes returns the block starts overlapping the range of the options
+// that can be flushed, which unless forced excludes the blocks that can still
+// be written to.
+func (m *flushManager) forceFlushTimes(
+	ns databaseNamespace,
+	opts ForceFlushOptions,
+	t time.Time,
+) []time.Time {
+	var (
+		rOpts            = ns.Options().RetentionOptions()
+		blockSize        = rOpts.BlockSize()
+		earliest, latest = m.flushRange(rOpts, t)
+	)
+	if opts.Force {
+		latest = t.Truncate(blockSize)
+	}
+	if start := opts.Start.Truncate(blockSize); start.After(earliest) {
+		earliest = start
+	}
+	if end := opts.End.Add(-1).Truncate(blockSize); end.Before(latest) {
+		latest = end
+	}
+	return timesInRange(earliest, latest, blockSize)
+}
+
 func (m *flushManager) Report() {
 	m.RLock()
 	state := m.state
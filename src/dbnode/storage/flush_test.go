@@ -457,6 +457,207 @@ func TestFlushManagerSnapshotBlockStart(t *testing.T) {
 	}
 }
 
+func newShutdownSnapshotFlushManager(
+	ctrl *gomock.Controller,
+	numShards int,
+) (*flushManager, *MockdatabaseNamespace, []*MockdatabaseShard) {
+	mockFlusher := persist.NewMockDataFlush(ctrl)
+	mockFlusher.EXPECT().DoneData().Return(nil)
+	mockPersistManager := persist.NewMockManager(ctrl)
+	mockPersistManager.EXPECT().StartDataPersist().Return(mockFlusher, nil)
+
+	ns := NewMockdatabaseNamespace(ctrl)
+	ns.EXPECT().Options().Return(namespace.NewOptions().SetSnapshotEnabled(true)).AnyTimes()
+	ns.EXPECT().ID().Return(defaultTestNs1ID).AnyTimes()
+
+	// Namespaces with snapshots disabled should be skipped entirely.
+	disabledNs := NewMockdatabaseNamespace(ctrl)
+	disabledNs.EXPECT().Options().Return(namespace.NewOptions()).AnyTimes()
+
+	var (
+		shards   = make([]*MockdatabaseShard, 0, numShards)
+		nsShards = make([]databaseShard, 0, numShards)
+	)
+	for i := 0; i < numShards; i++ {
+		shard := NewMockdatabaseShard(ctrl)
+		shard.EXPECT().ID().Return(uint32(i)).AnyTimes()
+		shards = append(shards, shard)
+		nsShards = append(nsShards, shard)
+	}
+	ns.EXPECT().GetOwnedShards().Return(nsShards)
+
+	db := newMockdatabase(ctrl, ns, disabledNs)
+	fm := newFlushManager(db, tally.NoopScope).(*flushManager)
+	fm.pm = mockPersistManager
+	return fm, ns, shards
+}
+
+func TestFlushManagerShutdownSnapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fm, ns, shards := newShutdownSnapshotFlushManager(ctrl, 3)
+	now := time.Now()
+	blockStart := fm.snapshotBlockStart(ns, now)
+
+	fakeErr := errors.New("fake error while snapshotting")
+	shards[0].EXPECT().Snapshot(blockStart, now, gomock.Any()).Return(nil)
+	shards[1].EXPECT().Snapshot(blockStart, now, gomock.Any()).Return(fakeErr)
+	shards[2].EXPECT().Snapshot(blockStart, now, gomock.Any()).Return(nil)
+
+	err := fm.ShutdownSnapshot(now, now.Add(time.Hour), nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), fakeErr.Error())
+}
+
+func TestFlushManagerShutdownSnapshotDeadlineExceeded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fm, _, _ := newShutdownSnapshotFlushManager(ctrl, 2)
+	now := time.Now()
+
+	require.Equal(t, errShutdownSnapshotDeadlineExceeded,
+		fm.ShutdownSnapshot(now, now.Add(-time.Second), nil))
+}
+
+func TestFlushManagerShutdownSnapshotAborted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fm, ns, shards := newShutdownSnapshotFlushManager(ctrl, 2)
+	now := time.Now()
+	blockStart := fm.snapshotBlockStart(ns, now)
+
+	// Abort while the first shard is snapshotting so the second is never snapshotted.
+	abortCh := make(chan struct{})
+	shards[0].EXPECT().
+		Snapshot(blockStart, now, gomock.Any()).
+		Do(func(_, _ time.Time, _ persist.DataFlush) { close(abortCh) }).
+		Return(nil)
+
+	require.Equal(t, errShutdownSnapshotAborted,
+		fm.ShutdownSnapshot(now, now.Add(time.Hour), abortCh))
+}
+
+func TestFlushManagerShutdownSnapshotAlreadyInProgress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fm := newFlushManager(newMockdatabase(ctrl), tally.NoopScope).(*flushManager)
+	fm.setState(flushManagerFlushInProgress)
+
+	now := time.Now()
+	require.Equal(t, errFlushOperationsInProgress,
+		fm.ShutdownSnapshot(now, now.Add(time.Hour), nil))
+}
+
+func TestFlushManagerForceFlushTimes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fm, ns, _ := newMultipleFlushManagerNeedsFlush(t, ctrl)
+	var (
+		blockSize  = ns.Options().RetentionOptions().BlockSize()
+		blockStart = time.Unix(0, 0).Add(100 * blockSize)
+		now        = blockStart.Add(blockSize / 4)
+		opts       = ForceFlushOptions{Start: now.Add(-3 * blockSize), End: now}
+	)
+
+	// The current block can still be written to so is only flushed if forced.
+	times := fm.forceFlushTimes(ns, opts, now)
+	sort.Sort(timesInOrder(times))
+	require.Equal(t, []time.Time{
+		blockStart.Add(-3 * blockSize),
+		blockStart.Add(-2 * blockSize),
+		blockStart.Add(-blockSize),
+	}, times)
+
+	opts.Force = true
+	times = fm.forceFlushTimes(ns, opts, now)
+	sort.Sort(timesInOrder(times))
+	require.Equal(t, []time.Time{
+		blockStart.Add(-3 * blockSize),
+		blockStart.Add(-2 * blockSize),
+		blockStart.Add(-blockSize),
+		blockStart,
+	}, times)
+
+	// The end of the range is exclusive.
+	opts.End = blockStart
+	times = fm.forceFlushTimes(ns, opts, now)
+	sort.Sort(timesInOrder(times))
+	require.Equal(t, []time.Time{
+		blockStart.Add(-3 * blockSize),
+		blockStart.Add(-2 * blockSize),
+		blockStart.Add(-blockSize),
+	}, times)
+}
+
+func TestFlushManagerForceFlushOverlappingTick(t *testing.T) {
+	ctrl := gomock.NewController(xtest.Reporter{t})
+	defer ctrl.Finish()
+
+	startCh := make(chan struct{}, 1)
+	doneCh := make(chan struct{}, 1)
+
+	mockFlusher := persist.NewMockDataFlush(ctrl)
+	mockFlusher.EXPECT().DoneData().Return(nil).Times(2)
+	mockPersistManager := persist.NewMockManager(ctrl)
+	gomock.InOrder(
+		mockPersistManager.EXPECT().StartDataPersist().Do(func() {
+			// channels used to hold the tick flush in progress
+			startCh <- struct{}{}
+			<-doneCh
+		}).Return(mockFlusher, nil),
+		mockPersistManager.EXPECT().StartDataPersist().Return(mockFlusher, nil),
+	)
+	mockIndexFlusher := persist.NewMockIndexFlush(ctrl)
+	mockIndexFlusher.EXPECT().DoneIndex().Return(nil)
+	mockPersistManager.EXPECT().StartIndexPersist().Return(mockIndexFlusher, nil)
+
+	options := namespace.NewOptions()
+	ns := NewMockdatabaseNamespace(ctrl)
+	ns.EXPECT().Options().Return(options).AnyTimes()
+	ns.EXPECT().ID().Return(defaultTestNs1ID).AnyTimes()
+
+	testOpts := testDatabaseOptions().SetPersistManager(mockPersistManager)
+	db := newMockdatabase(ctrl)
+	db.EXPECT().Options().Return(testOpts).AnyTimes()
+	db.EXPECT().GetOwnedNamespaces().Return(nil, nil)
+
+	fm := newFlushManager(db, tally.NoopScope).(*flushManager)
+	fm.pm = mockPersistManager
+
+	var (
+		blockSize  = options.RetentionOptions().BlockSize()
+		blockStart = time.Unix(0, 0).Add(100 * blockSize)
+		now        = blockStart.Add(blockSize / 4)
+		opts       = ForceFlushOptions{Start: blockStart, End: now, Force: true}
+		wg         sync.WaitGroup
+	)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		require.NoError(t, fm.Flush(now, DatabaseBootstrapState{}))
+	}()
+
+	// A force flush while the tick is flushing is rejected.
+	<-startCh
+	_, err := fm.ForceFlush(ns, opts, now)
+	require.Equal(t, errFlushOperationsInProgress, err)
+	doneCh <- struct{}{}
+	wg.Wait()
+
+	// Once the tick is done the force flush goes ahead.
+	ns.EXPECT().ForceFlush(blockStart, opts, mockFlusher).
+		Return(ForceFlushResult{NumSeriesFlushed: 1, NumBytesWritten: 8}, nil)
+	result, err := fm.ForceFlush(ns, opts, now)
+	require.NoError(t, err)
+	require.Equal(t, ForceFlushResult{NumSeriesFlushed: 1, NumBytesWritten: 8}, result)
+}
+
 type timesInOrder []time.Time
 
 func (a timesInOrder) Len() int           { return len(a) }
@@ -21,12 +21,17 @@
 package storage
 
 import (
+	"errors"
 	"sync"
 	"time"
 
 	xlog "github.com/m3db/m3x/log"
 )
 
+var (
+	errFileOpsUnavailable = errors.New("file operations are disabled or in progress")
+)
+
 type fileOpStatus int
 
 const (
@@ -145,6 +150,44 @@ func (m *fileSystemManager) Run(
 	return true
 }
 
+func (m *fileSystemManager) FlushNamespace(ns databaseNamespace, t time.Time) error {
+	m.Lock()
+	if !m.enabled || m.status == fileOpInProgress {
+		m.Unlock()
+		return errFileOpsUnavailable
+	}
+	m.status = fileOpInProgress
+	m.Unlock()
+
+	defer func() {
+		m.Lock()
+		m.status = fileOpNotStarted
+		m.Unlock()
+	}()
+	return m.databaseFlushManager.FlushNamespace(ns, t)
+}
+
+func (m *fileSystemManager) ForceFlush(
+	ns databaseNamespace,
+	opts ForceFlushOptions,
+	t time.Time,
+) (ForceFlushResult, error) {
+	m.Lock()
+	if !m.enabled || m.status == fileOpInProgress {
+		m.Unlock()
+		return ForceFlushResult{}, errFileOpsUnavailable
+	}
+	m.status = fileOpInProgress
+	m.Unlock()
+
+	defer func() {
+		m.Lock()
+		m.status = fileOpNotStarted
+		m.Unlock()
+	}()
+	return m.databaseFlushManager.ForceFlush(ns, opts, t)
+}
+
 func (m *fileSystemManager) Report() {
 	m.databaseCleanupManager.Report()
 	m.databaseFlushManager.Report()
@@ -92,3 +92,29 @@ func TestFileSystemManagerRun(t *testing.T) {
 	mgr.Run(ts, DatabaseBootstrapState{}, syncRun, noForce)
 	require.Equal(t, fileOpNotStarted, mgr.status)
 }
+
+func TestFileSystemManagerFlushNamespace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	database := newMockdatabase(ctrl)
+
+	fm := NewMockdatabaseFlushManager(ctrl)
+	fsm := newFileSystemManager(database, testDatabaseOptions())
+	mgr := fsm.(*fileSystemManager)
+	mgr.databaseFlushManager = fm
+
+	ns := NewMockdatabaseNamespace(ctrl)
+	ts := time.Now()
+
+	mgr.status = fileOpInProgress
+	require.Equal(t, errFileOpsUnavailable, mgr.FlushNamespace(ns, ts))
+
+	mgr.status = fileOpNotStarted
+	mgr.Disable()
+	require.Equal(t, errFileOpsUnavailable, mgr.FlushNamespace(ns, ts))
+
+	mgr.Enable()
+	fm.EXPECT().FlushNamespace(ns, ts).Return(nil)
+	require.NoError(t, mgr.FlushNamespace(ns, ts))
+	require.Equal(t, fileOpNotStarted, mgr.status)
+}
@@ -36,9 +36,11 @@ import (
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
 	m3dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
 	"github.com/m3db/m3/src/dbnode/storage/index"
+	"github.com/m3db/m3/src/dbnode/storage/index/compaction"
 	"github.com/m3db/m3/src/dbnode/storage/index/convert"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3/src/m3ninx/doc"
+	"github.com/m3db/m3/src/m3ninx/idx"
 	m3ninxindex "github.com/m3db/m3/src/m3ninx/index"
 	"github.com/m3db/m3/src/m3ninx/index/segment"
 	"github.com/m3db/m3/src/m3ninx/index/segment/mem"
@@ -74,11 +76,12 @@ type nsIndex struct {
 
 	// all the vars below this line are not modified past the ctor
 	// and don't require a lock when being accessed.
-	nowFn           clock.NowFn
-	blockSize       time.Duration
-	retentionPeriod time.Duration
-	bufferPast      time.Duration
-	bufferFuture    time.Duration
+	nowFn                clock.NowFn
+	blockSize            time.Duration
+	retentionPeriod      time.Duration
+	retentionGracePeriod time.Duration
+	bufferPast           time.Duration
+	bufferFuture         time.Duration
 
 	indexFilesetsBeforeFn indexFilesetsBeforeFn
 	deleteFilesFn         deleteFilesFn
@@ -89,6 +92,10 @@ type nsIndex struct {
 	nsMetadata          namespace.Metadata
 	runtimeOptsListener xclose.SimpleCloser
 
+	// compactionScheduler tracks the insert rate of the index to defer index
+	// compactions during write spikes.
+	compactionScheduler *compaction.Scheduler
+
 	metrics nsIndexMetrics
 }
 
@@ -122,6 +129,7 @@ type nsIndexRuntimeOptions struct {
 	insertMode            index.InsertMode
 	maxQueryLimit         int64
 	flushBlockNumSegments uint
+	queryLimits           runtime.IndexQueryLimits
 }
 
 type newBlockFn func(time.Time, namespace.Metadata, index.Options) (index.Block, error)
@@ -206,6 +214,12 @@ func newNamespaceIndexWithOptions(
 	indexOpts = indexOpts.SetInstrumentOptions(instrumentOpts)
 
 	nowFn := indexOpts.ClockOptions().NowFn()
+	compactionScheduler, err := compaction.NewScheduler(compaction.DefaultSchedulerOptions,
+		nowFn, scope.SubScope("compaction"))
+	if err != nil {
+		return nil, err
+	}
+
 	idx := &nsIndex{
 		state: nsIndexState{
 			runtimeOpts: nsIndexRuntimeOptions{
@@ -215,11 +229,12 @@ func newNamespaceIndexWithOptions(
 			blocksByTime: make(map[xtime.UnixNano]index.Block),
 		},
 
-		nowFn:           nowFn,
-		blockSize:       nsMD.Options().IndexOptions().BlockSize(),
-		retentionPeriod: nsMD.Options().RetentionOptions().RetentionPeriod(),
-		bufferPast:      nsMD.Options().RetentionOptions().BufferPast(),
-		bufferFuture:    nsMD.Options().RetentionOptions().BufferFuture(),
+		nowFn:                nowFn,
+		blockSize:            nsMD.Options().IndexOptions().BlockSize(),
+		retentionPeriod:      nsMD.Options().RetentionOptions().RetentionPeriod(),
+		retentionGracePeriod: indexOpts.BlockRetentionGracePeriod(),
+		bufferPast:           nsMD.Options().RetentionOptions().BufferPast(),
+		bufferFuture:         nsMD.Options().RetentionOptions().BufferFuture(),
 
 		indexFilesetsBeforeFn: fs.IndexFileSetsBefore,
 		deleteFilesFn:         fs.DeleteFiles,
@@ -229,6 +244,8 @@ func newNamespaceIndexWithOptions(
 		logger:     indexOpts.InstrumentOptions().Logger(),
 		nsMetadata: nsMD,
 
+		compactionScheduler: compactionScheduler,
+
 		metrics: newNamespaceIndexMetrics(instrumentOpts),
 	}
 	if runtimeOptsMgr != nil {
@@ -256,7 +273,20 @@ func newNamespaceIndexWithOptions(
 func (i *nsIndex) SetRuntimeOptions(value runtime.Options) {
 	i.state.Lock()
 	i.state.runtimeOpts.flushBlockNumSegments = value.FlushIndexBlockNumSegments()
+	i.state.runtimeOpts.queryLimits = value.IndexQueryLimits()
+	if limits, ok := value.NamespaceIndexQueryLimits()[i.nsMetadata.ID().String()]; ok {
+		i.state.runtimeOpts.queryLimits = limits
+	}
 	i.state.Unlock()
+
+	scheduling := value.IndexCompactionScheduling()
+	if err := i.compactionScheduler.SetOptions(compaction.SchedulerOptions{
+		InsertRateThreshold: scheduling.InsertRateThreshold,
+		InsertRateWindow:    scheduling.InsertRateWindow,
+		MaxDeferral:         scheduling.MaxDeferral,
+	}); err != nil {
+		i.logger.Errorf("unable to set index compaction scheduling: %v", err)
+	}
 }
 
 func (i *nsIndex) BlockStartForWriteTime(writeTime time.Time) xtime.UnixNano {
@@ -389,6 +419,7 @@ func (i *nsIndex) writeBatchForBlockStartWithRLock(
 
 	// i.e. we have the block and the inserts, perform the writes.
 	result, err := block.WriteBatch(batch)
+	i.compactionScheduler.RecordInserts(len(pending))
 
 	// record the end to end indexing latency
 	now := i.nowFn()
@@ -454,7 +485,8 @@ func (i *nsIndex) Bootstrap(
 func (i *nsIndex) Tick(c context.Cancellable, tickStart time.Time) (namespaceIndexTickResult, error) {
 	var (
 		result                     = namespaceIndexTickResult{}
-		earliestBlockStartToRetain = retention.FlushTimeStartForRetentionPeriod(i.retentionPeriod, i.blockSize, tickStart)
+		retentionPeriod            = i.retentionPeriod + i.retentionGracePeriod
+		earliestBlockStartToRetain = retention.FlushTimeStartForRetentionPeriod(retentionPeriod, i.blockSize, tickStart)
 		lastSealableBlockStart     = retention.FlushTimeEndForBlockSize(i.blockSize, tickStart.Add(-i.bufferPast))
 	)
 
@@ -473,11 +505,19 @@ func (i *nsIndex) Tick(c context.Cancellable, tickStart time.Time) (namespaceInd
 			return result, multiErr.FinalError()
 		}
 
-		// drop any blocks past the retention period
+		// seal and release any blocks past the retention period, queries holding
+		// a reference to the block keep its segments alive until they complete.
 		if blockStart.ToTime().Before(earliestBlockStartToRetain) {
+			blockTickResult, tickErr := block.Tick(c, tickStart)
+			multiErr = multiErr.Add(tickErr)
+			if !block.IsSealed() {
+				multiErr = multiErr.Add(block.Seal())
+			}
 			multiErr = multiErr.Add(block.Close())
 			delete(i.state.blocksByTime, blockStart)
 			result.NumBlocksEvicted++
+			result.NumSegmentsEvicted += blockTickResult.NumSegments
+			result.NumDocsEvicted += blockTickResult.NumDocs
 			result.NumBlocks--
 			continue
 		}
@@ -716,8 +756,8 @@ func (i *nsIndex) Query(
 	opts index.QueryOptions,
 ) (index.QueryResults, error) {
 	i.state.RLock()
-	defer i.state.RUnlock()
 	if !i.isOpenWithRLock() {
+		i.state.RUnlock()
 		return index.QueryResults{}, errDbIndexUnableToQueryClosed
 	}
 
@@ -728,14 +768,63 @@ func (i *nsIndex) Query(
 			opts.Limit, i.state.runtimeOpts.maxQueryLimit) // FOLLOWUP(prateek): log query too once it's serializable.
 		opts.Limit = int(i.state.runtimeOpts.maxQueryLimit)
 	}
+	// NB: the max query limit also bounds the size of a page.
+	if i.state.runtimeOpts.maxQueryLimit > 0 &&
+		int64(opts.PageSize) > i.state.runtimeOpts.maxQueryLimit {
+		opts.PageSize = int(i.state.runtimeOpts.maxQueryLimit)
+	}
+
+	// apply the configured series and docs limits unless the query sets its own.
+	if opts.SeriesLimit <= 0 {
+		opts.SeriesLimit = i.state.runtimeOpts.queryLimits.SeriesLimit
+	}
+	if opts.DocsLimit <= 0 {
+		opts.DocsLimit = i.state.runtimeOpts.queryLimits.DocsLimit
+	}
+
+	// NB: the blocks are referenced so the lock does not need to be held while
+	// querying them, any blocks evicted by a tick are released once we're done.
+	blocks, err := i.blocksForQueryWithRLock(opts.StartInclusive, opts.EndExclusive)
+	i.state.RUnlock()
+	if err != nil {
+		return index.QueryResults{}, err
+	}
+	defer decRefBlocks(blocks)
+
+	// apply the configured maximum number of terms matched unless the query sets its own.
+	if opts.MaxTermsMatched <= 0 {
+		opts.MaxTermsMatched = i.opts.IndexOptions().MaxQueryTermsMatched()
+	}
 
 	var (
 		exhaustive = true
 		results    = i.opts.IndexOptions().ResultsPool().Get()
-		err        error
 	)
 	results.Reset(i.nsMetadata.ID())
 	ctx.RegisterFinalizer(results)
+	if opts.PageSize > 0 {
+		results.SetPage(opts.PageToken, opts.PageSize)
+	}
+	if opts.PageSize > 0 && opts.PageToken != nil {
+		// NB: restrict the query to the IDs sorting after the page token so the
+		// postings of the pages already returned are skipped rather than having
+		// their documents read and discarded by every following page.
+		afterPageToken, err := idx.NewRangeQuery(doc.IDReservedFieldName,
+			opts.PageToken, nil, false, false)
+		if err != nil {
+			return index.QueryResults{}, err
+		}
+		query = index.Query{
+			Query: idx.NewConjunctionQuery(query.Query, afterPageToken),
+		}
+	}
+
+	if opts.Trace != nil {
+		start := time.Now()
+		defer func() {
+			opts.Trace.Finish(start, int64(results.Size()))
+		}()
+	}
 
 	// Chunk the query request into bounds based on applicable blocks and
 	// execute the requests to each of them; and merge results.
@@ -744,12 +833,7 @@ func (i *nsIndex) Query(
 
 	// iterate known blocks in a defined order of time (newest first) to enforce
 	// some determinism about the results returned.
-	for _, start := range i.state.blockStartsDescOrder {
-		block, ok := i.state.blocksByTime[start]
-		if !ok { // should never happen
-			return index.QueryResults{}, i.missingBlockInvariantError(start)
-		}
-
+	for _, block := range blocks {
 		// ensure the block has data requested by the query
 		blockRange := xtime.Range{Start: block.StartTime(), End: block.EndTime()}
 		if !queryRange.Overlaps(blockRange) {
@@ -757,12 +841,15 @@ func (i *nsIndex) Query(
 		}
 
 		// terminate early if we know we don't need any more results
-		if opts.Limit > 0 && results.Size() >= opts.Limit {
+		if opts.LimitsExceeded(results.Size(), results.TotalDocsCount()) {
 			exhaustive = false
 			break
 		}
 
 		exhaustive, err = block.Query(query, opts, results)
+		if err == m3ninxindex.ErrTooManyTermsMatched {
+			i.metrics.QueryTooManyTermsMatched.Inc(1)
+		}
 		if err != nil {
 			return index.QueryResults{}, err
 		}
@@ -783,12 +870,140 @@ func (i *nsIndex) Query(
 	// FOLLOWUP(prateek): do the above operation with controllable parallelism to optimize
 	// for latency at the cost of higher mem-usage.
 
+	if !exhaustive {
+		if opts.SeriesLimit > 0 && results.Size() >= opts.SeriesLimit {
+			i.metrics.QuerySeriesLimitExceeded.Inc(1)
+		}
+		if opts.DocsLimitExceeded(results.TotalDocsCount()) {
+			i.metrics.QueryDocsLimitExceeded.Inc(1)
+		}
+	}
+
 	return index.QueryResults{
-		Exhaustive: exhaustive,
+		Exhaustive:    exhaustive,
+		Results:       results,
+		NextPageToken: results.NextPageToken(),
+	}, nil
+}
+
+func (i *nsIndex) AggregateTerms(
+	ctx context.Context,
+	query index.Query,
+	opts index.QueryOptions,
+) (index.AggregateQueryResults, error) {
+	i.state.RLock()
+	if !i.isOpenWithRLock() {
+		i.state.RUnlock()
+		return index.AggregateQueryResults{}, errDbIndexUnableToQueryClosed
+	}
+	blocks, err := i.blocksForQueryWithRLock(opts.StartInclusive, opts.EndExclusive)
+	i.state.RUnlock()
+	if err != nil {
+		return index.AggregateQueryResults{}, err
+	}
+	defer decRefBlocks(blocks)
+
+	var (
+		exhaustive = true
+		results    = index.NewAggregateResults()
+	)
+
+	// NB: unlike queries, every block overlapping the query range contributes to the
+	// frequencies of the terms, so all of them are aggregated.
+	for _, block := range blocks {
+		blockExhaustive, err := block.AggregateTerms(query, opts, results)
+		if err != nil {
+			return index.AggregateQueryResults{}, err
+		}
+		exhaustive = exhaustive && blockExhaustive
+	}
+
+	if opts.Limit > 0 && results.Size() > opts.Limit {
+		exhaustive = false
+	}
+
+	return index.AggregateQueryResults{
 		Results:    results,
+		Exhaustive: exhaustive,
 	}, nil
 }
 
+func (i *nsIndex) AggregateTagValues(
+	ctx context.Context,
+	opts index.AggregateTagValuesOptions,
+) (index.AggregateTagValuesResults, error) {
+	results, err := index.NewTagValuesResults(opts)
+	if err != nil {
+		return index.AggregateTagValuesResults{}, err
+	}
+
+	i.state.RLock()
+	if !i.isOpenWithRLock() {
+		i.state.RUnlock()
+		return index.AggregateTagValuesResults{}, errDbIndexUnableToQueryClosed
+	}
+	blocks, err := i.blocksForQueryWithRLock(opts.StartInclusive, opts.EndExclusive)
+	i.state.RUnlock()
+	if err != nil {
+		return index.AggregateTagValuesResults{}, err
+	}
+	defer decRefBlocks(blocks)
+
+	// NB: the same values are typically present in many blocks, the results dedupe
+	// them and sum their frequencies which makes the frequencies approximate as a
+	// series present in several blocks is counted once per block.
+	for _, block := range blocks {
+		if err := block.AggregateTagValues(opts, results); err != nil {
+			return index.AggregateTagValuesResults{}, err
+		}
+	}
+
+	return results.Page(), nil
+}
+
+// blocksForQueryWithRLock returns the blocks overlapping the provided time range in
+// reverse chronological order, with a reference held to each of them so that a tick
+// evicting any of them does not release them while they are being queried. The
+// caller is responsible for releasing the references with decRefBlocks. Time ranges
+// for blocks that have already been evicted have no blocks and hence no results.
+// NB: blocks outside the time range are skipped here, before any searchers are
+// constructed against their segments.
+func (i *nsIndex) blocksForQueryWithRLock(
+	startInclusive, endExclusive time.Time,
+) ([]index.Block, error) {
+	var (
+		queryRange = xtime.Range{Start: startInclusive, End: endExclusive}
+		blocks     = make([]index.Block, 0, len(i.state.blockStartsDescOrder))
+		skipped    = 0
+	)
+	for _, start := range i.state.blockStartsDescOrder {
+		block, ok := i.state.blocksByTime[start]
+		if !ok { // should never happen
+			decRefBlocks(blocks)
+			return nil, i.missingBlockInvariantError(start)
+		}
+
+		blockRange := xtime.Range{Start: block.StartTime(), End: block.EndTime()}
+		if !queryRange.Overlaps(blockRange) {
+			skipped++
+			continue
+		}
+
+		block.IncRef()
+		blocks = append(blocks, block)
+	}
+
+	i.metrics.QueryBlocksQueried.Inc(int64(len(blocks)))
+	i.metrics.QueryBlocksSkipped.Inc(int64(skipped))
+	return blocks, nil
+}
+
+func decRefBlocks(blocks []index.Block) {
+	for _, block := range blocks {
+		block.DecRef()
+	}
+}
+
 // ensureBlockPresentWithRLock guarantees an index.Block exists for the specified
 // blockStart, allocating one if it does not. It returns the desired block, or
 // error if it's unable to do so.
@@ -947,6 +1162,11 @@ type nsIndexMetrics struct {
 	AsyncInsertErrors           tally.Counter
 	InsertAfterClose            tally.Counter
 	QueryAfterClose             tally.Counter
+	QueryTooManyTermsMatched    tally.Counter
+	QuerySeriesLimitExceeded    tally.Counter
+	QueryDocsLimitExceeded      tally.Counter
+	QueryBlocksQueried          tally.Counter
+	QueryBlocksSkipped          tally.Counter
 	InsertEndToEndLatency       tally.Timer
 	FlushEvictedMutableSegments tally.Counter
 }
@@ -965,6 +1185,21 @@ func newNamespaceIndexMetrics(
 		QueryAfterClose: scope.Tagged(map[string]string{
 			"error_type": "query-closed",
 		}).Counter("query-after-error"),
+		QueryTooManyTermsMatched: scope.Tagged(map[string]string{
+			"error_type": "query-too-many-terms-matched",
+		}).Counter("query-rejected"),
+		QuerySeriesLimitExceeded: scope.Tagged(map[string]string{
+			"limit": "series",
+		}).Counter("query-limit-exceeded"),
+		QueryDocsLimitExceeded: scope.Tagged(map[string]string{
+			"limit": "docs",
+		}).Counter("query-limit-exceeded"),
+		QueryBlocksQueried: scope.Tagged(map[string]string{
+			"result": "queried",
+		}).Counter("query-blocks"),
+		QueryBlocksSkipped: scope.Tagged(map[string]string{
+			"result": "skipped",
+		}).Counter("query-blocks"),
 		InsertEndToEndLatency: instrument.MustCreateSampledTimer(
 			scope.Timer("insert-end-to-end-latency"),
 			iopts.MetricsSamplingRate()),
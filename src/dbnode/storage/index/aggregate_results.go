@@ -0,0 +1,60 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package index
+
+import (
+	m3ninxindex "github.com/m3db/m3/src/m3ninx/index"
+)
+
+// AggregateResults accumulates the document frequencies of the terms matched by an
+// aggregate query across segments and blocks.
+type AggregateResults struct {
+	counts map[string]int
+}
+
+// NewAggregateResults returns a new, empty AggregateResults.
+func NewAggregateResults() *AggregateResults {
+	return &AggregateResults{
+		counts: make(map[string]int),
+	}
+}
+
+// Add adds the document frequencies of the given terms to those already accumulated.
+func (r *AggregateResults) Add(terms []m3ninxindex.TermFrequency) {
+	for _, t := range terms {
+		r.counts[string(t.Term)] += t.Count
+	}
+}
+
+// Size returns the number of distinct terms accumulated.
+func (r *AggregateResults) Size() int {
+	return len(r.counts)
+}
+
+// Terms returns at most limit of the terms accumulated, ordered by descending document
+// frequency. A non-positive limit returns every term.
+func (r *AggregateResults) Terms(limit int) []m3ninxindex.TermFrequency {
+	top := m3ninxindex.NewTopTerms(limit)
+	for term, count := range r.counts {
+		top.Add([]byte(term), count)
+	}
+	return top.Terms()
+}
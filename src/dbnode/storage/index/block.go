@@ -44,6 +44,7 @@ var (
 	errUnableToWriteBlockClosed     = errors.New("unable to write, index block is closed")
 	errUnableToWriteBlockSealed     = errors.New("unable to write, index block is sealed")
 	errUnableToQueryBlockClosed     = errors.New("unable to query, index block is closed")
+	errAggregateQueryNotTerms       = errors.New("unable to aggregate, query must be a regexp, prefix or wildcard query")
 	errUnableToBootstrapBlockClosed = errors.New("unable to bootstrap, block is closed")
 	errUnableToTickBlockClosed      = errors.New("unable to tick, block is closed")
 	errBlockAlreadyClosed           = errors.New("unable to close, block already closed")
@@ -60,11 +61,13 @@ const (
 	blockStateSealed
 )
 
-type newExecutorFn func() (search.Executor, error)
+type newExecutorFn func(opts search.Options) (search.Executor, error)
 
 type block struct {
 	sync.RWMutex
 	state               blockState
+	refs                int64
+	closePending        bool
 	activeSegment       segment.MutableSegment
 	shardRangesSegments []blockShardRangesSegments
 
@@ -180,7 +183,17 @@ func (b *block) WriteBatch(inserts *WriteBatch) (WriteBatchResult, error) {
 	}, partialErr
 }
 
-func (b *block) executorWithRLock() (search.Executor, error) {
+func (b *block) executorWithRLock(opts search.Options) (search.Executor, error) {
+	readers, err := b.readersWithRLock()
+	if err != nil {
+		return nil, err
+	}
+	return executor.NewExecutor(readers, opts), nil
+}
+
+// readersWithRLock returns a reader for each of the segments of the block. The caller
+// is responsible for closing them.
+func (b *block) readersWithRLock() ([]m3ninxindex.Reader, error) {
 	var expectedReaders int
 	if b.activeSegment != nil {
 		expectedReaders++
@@ -224,7 +237,7 @@ func (b *block) executorWithRLock() (search.Executor, error) {
 	}
 
 	success = true
-	return executor.NewExecutor(readers), nil
+	return readers, nil
 }
 
 func (b *block) Query(
@@ -238,7 +251,18 @@ func (b *block) Query(
 		return false, errUnableToQueryBlockClosed
 	}
 
-	exec, err := b.newExecutorFn()
+	searchOpts := search.NewOptions().
+		SetMaxTermsMatched(opts.MaxTermsMatched).
+		SetPostingsCache(b.opts.PostingsCache())
+	if opts.Trace != nil {
+		trace := opts.Trace.NewChild(fmt.Sprintf("block(%s)", b.startTime.Format(time.RFC3339)))
+		searchOpts = searchOpts.SetTrace(trace)
+		start, sizeBefore := time.Now(), results.Size()
+		defer func() {
+			trace.Finish(start, int64(results.Size()-sizeBefore))
+		}()
+	}
+	exec, err := b.newExecutorFn(searchOpts)
 	if err != nil {
 		return false, err
 	}
@@ -265,7 +289,7 @@ func (b *block) Query(
 	}()
 
 	for iter.Next() {
-		if opts.Limit > 0 && size >= opts.Limit {
+		if opts.LimitsExceeded(size, results.TotalDocsCount()) {
 			brokeEarly = true
 			break
 		}
@@ -292,6 +316,98 @@ func (b *block) Query(
 	return exhaustive, nil
 }
 
+func (b *block) AggregateTerms(
+	query Query,
+	opts QueryOptions,
+	results *AggregateResults,
+) (bool, error) {
+	b.RLock()
+	defer b.RUnlock()
+	if b.state == blockStateClosed {
+		return false, errUnableToQueryBlockClosed
+	}
+
+	s, err := query.Query.SearchQuery().Searcher(search.NewOptions())
+	if err != nil {
+		return false, err
+	}
+	termsSearcher, ok := s.(search.TermsSearcher)
+	if !ok {
+		return false, errAggregateQueryNotTerms
+	}
+
+	readers, err := b.readersWithRLock()
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		for _, reader := range readers {
+			reader.Close()
+		}
+	}()
+
+	// NB: each segment only returns its own most frequent terms, so a term outside the
+	// limit of every segment it occurs in is never counted. The results are only
+	// exhaustive if no segment had more terms than the limit.
+	exhaustive := true
+	for _, reader := range readers {
+		terms, err := termsSearcher.MatchTerms(reader, opts.Limit)
+		if err != nil {
+			return false, err
+		}
+		if opts.Limit > 0 && len(terms) >= opts.Limit {
+			exhaustive = false
+		}
+		results.Add(terms)
+	}
+
+	return exhaustive, nil
+}
+
+func (b *block) AggregateTagValues(
+	opts AggregateTagValuesOptions,
+	results *TagValuesResults,
+) error {
+	b.RLock()
+	defer b.RUnlock()
+	if b.state == blockStateClosed {
+		return errUnableToQueryBlockClosed
+	}
+
+	after, err := tagValuesCursorValue(opts.Cursor)
+	if err != nil {
+		return err
+	}
+
+	readers, err := b.readersWithRLock()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		for _, reader := range readers {
+			reader.Close()
+		}
+	}()
+
+	// NB: the values are paged in order so the page can only contain the lowest values
+	// following the cursor of each segment. One more value than the limit is requested
+	// so that a segment with more values than the page marks it as not exhaustive.
+	limit := 0
+	if opts.Limit > 0 {
+		limit = opts.Limit + 1
+	}
+	for _, reader := range readers {
+		values, err := reader.MatchPrefixTermsAfter(opts.TagName, opts.TagValuePrefix,
+			after, limit)
+		if err != nil {
+			return err
+		}
+		results.Add(values)
+	}
+
+	return nil
+}
+
 func (b *block) AddResults(
 	results result.IndexBlock,
 ) error {
@@ -492,13 +608,52 @@ func (b *block) EvictMutableSegments() (EvictMutableSegmentResults, error) {
 	return results, multiErr.FinalError()
 }
 
+func (b *block) IncRef() {
+	b.Lock()
+	b.refs++
+	b.Unlock()
+}
+
+func (b *block) DecRef() {
+	b.Lock()
+	defer b.Unlock()
+	b.refs--
+	if b.refs < 0 { // should never happen
+		b.refs = 0
+		instrument.EmitInvariantViolationAndGetLogger(b.opts.InstrumentOptions()).
+			Errorf("index block reference count is negative")
+		return
+	}
+	if b.refs > 0 || !b.closePending {
+		return
+	}
+
+	// NB: the last reference held by an in-flight query has been released after
+	// the block was closed, so the segments can now be released.
+	if err := b.closeWithLock(); err != nil {
+		b.opts.InstrumentOptions().Logger().
+			Errorf("error closing index block after releasing last reference: %v", err)
+	}
+}
+
 func (b *block) Close() error {
 	b.Lock()
 	defer b.Unlock()
-	if b.state == blockStateClosed {
+	if b.state == blockStateClosed || b.closePending {
 		return errBlockAlreadyClosed
 	}
+
+	// defer releasing the segments until any queries holding references complete.
+	if b.refs > 0 {
+		b.closePending = true
+		return nil
+	}
+	return b.closeWithLock()
+}
+
+func (b *block) closeWithLock() error {
 	b.state = blockStateClosed
+	b.closePending = false
 
 	var multiErr xerrors.MultiError
 
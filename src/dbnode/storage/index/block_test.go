@@ -433,6 +433,38 @@ func TestBlockQueryAfterClose(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestBlockCloseWithRefsDefersRelease(t *testing.T) {
+	testMD := newTestNSMetadata(t)
+	start := time.Now().Truncate(time.Hour)
+	blk, err := NewBlock(start, testMD, testOpts)
+	require.NoError(t, err)
+
+	b, ok := blk.(*block)
+	require.True(t, ok)
+
+	b.IncRef()
+	b.IncRef()
+	require.NoError(t, b.Close())
+	require.Equal(t, errBlockAlreadyClosed, b.Close())
+
+	// the block is still queryable while references are held.
+	require.Equal(t, blockStateOpen, b.state)
+	require.NotNil(t, b.activeSegment)
+	results := NewResults(testOpts)
+	_, err = b.Query(Query{idx.MustCreateTermQuery([]byte("foo"), []byte("bar"))}, QueryOptions{}, results)
+	require.NoError(t, err)
+
+	b.DecRef()
+	require.Equal(t, blockStateOpen, b.state)
+
+	// releasing the last reference releases the block.
+	b.DecRef()
+	require.Equal(t, blockStateClosed, b.state)
+	require.Nil(t, b.activeSegment)
+	_, err = b.Query(Query{}, QueryOptions{}, nil)
+	require.Error(t, err)
+}
+
 func TestBlockQueryExecutorError(t *testing.T) {
 	testMD := newTestNSMetadata(t)
 	start := time.Now().Truncate(time.Hour)
@@ -442,7 +474,7 @@ func TestBlockQueryExecutorError(t *testing.T) {
 	b, ok := blk.(*block)
 	require.True(t, ok)
 
-	b.newExecutorFn = func() (search.Executor, error) {
+	b.newExecutorFn = func(_ search.Options) (search.Executor, error) {
 		b.RLock() // ensures we call newExecutorFn with RLock, or this would deadlock
 		defer b.RUnlock()
 		return nil, fmt.Errorf("random-err")
@@ -452,6 +484,24 @@ func TestBlockQueryExecutorError(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestBlockQueryExecutorMaxTermsMatched(t *testing.T) {
+	testMD := newTestNSMetadata(t)
+	start := time.Now().Truncate(time.Hour)
+	blk, err := NewBlock(start, testMD, testOpts)
+	require.NoError(t, err)
+
+	b, ok := blk.(*block)
+	require.True(t, ok)
+
+	b.newExecutorFn = func(opts search.Options) (search.Executor, error) {
+		require.Equal(t, 42, opts.MaxTermsMatched())
+		return nil, fmt.Errorf("random-err")
+	}
+
+	_, err = b.Query(Query{}, QueryOptions{MaxTermsMatched: 42}, nil)
+	require.Error(t, err)
+}
+
 func TestBlockQuerySegmentReaderError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -522,7 +572,7 @@ func TestBlockMockQueryExecutorExecError(t *testing.T) {
 
 	// dIter:= doc.NewMockIterator(ctrl)
 	exec := search.NewMockExecutor(ctrl)
-	b.newExecutorFn = func() (search.Executor, error) {
+	b.newExecutorFn = func(_ search.Options) (search.Executor, error) {
 		return exec, nil
 	}
 	gomock.InOrder(
@@ -546,7 +596,7 @@ func TestBlockMockQueryExecutorExecIterErr(t *testing.T) {
 	require.True(t, ok)
 
 	exec := search.NewMockExecutor(ctrl)
-	b.newExecutorFn = func() (search.Executor, error) {
+	b.newExecutorFn = func(_ search.Options) (search.Executor, error) {
 		return exec, nil
 	}
 
@@ -577,7 +627,7 @@ func TestBlockMockQueryExecutorExecLimit(t *testing.T) {
 	require.True(t, ok)
 
 	exec := search.NewMockExecutor(ctrl)
-	b.newExecutorFn = func() (search.Executor, error) {
+	b.newExecutorFn = func(_ search.Options) (search.Executor, error) {
 		return exec, nil
 	}
 
@@ -617,7 +667,7 @@ func TestBlockMockQueryExecutorExecIterCloseErr(t *testing.T) {
 	require.True(t, ok)
 
 	exec := search.NewMockExecutor(ctrl)
-	b.newExecutorFn = func() (search.Executor, error) {
+	b.newExecutorFn = func(_ search.Options) (search.Executor, error) {
 		return exec, nil
 	}
 
@@ -647,7 +697,7 @@ func TestBlockMockQueryExecutorExecIterExecCloseErr(t *testing.T) {
 	require.True(t, ok)
 
 	exec := search.NewMockExecutor(ctrl)
-	b.newExecutorFn = func() (search.Executor, error) {
+	b.newExecutorFn = func(_ search.Options) (search.Executor, error) {
 		return exec, nil
 	}
 
@@ -677,7 +727,7 @@ func TestBlockMockQueryLimit(t *testing.T) {
 	require.True(t, ok)
 
 	exec := search.NewMockExecutor(ctrl)
-	b.newExecutorFn = func() (search.Executor, error) {
+	b.newExecutorFn = func(_ search.Options) (search.Executor, error) {
 		return exec, nil
 	}
 
@@ -705,6 +755,51 @@ func TestBlockMockQueryLimit(t *testing.T) {
 		ident.NewTagsIterator(t1)))
 }
 
+func TestBlockMockQueryTrace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testMD := newTestNSMetadata(t)
+	start := time.Now().Truncate(time.Hour)
+	blk, err := NewBlock(start, testMD, testOpts)
+	require.NoError(t, err)
+
+	b, ok := blk.(*block)
+	require.True(t, ok)
+
+	exec := search.NewMockExecutor(ctrl)
+	b.newExecutorFn = func(opts search.Options) (search.Executor, error) {
+		// Simulate the executor recording the search of the query in the block's trace.
+		child := opts.Trace().NewChild("query")
+		child.TermsScanned = 3
+		child.PostingsDecoded = 5
+		return exec, nil
+	}
+
+	dIter := doc.NewMockIterator(ctrl)
+	gomock.InOrder(
+		exec.EXPECT().Execute(gomock.Any()).Return(dIter, nil),
+		dIter.EXPECT().Next().Return(true),
+		dIter.EXPECT().Current().Return(testDoc1()),
+		dIter.EXPECT().Next().Return(false),
+		dIter.EXPECT().Err().Return(nil),
+		dIter.EXPECT().Close().Return(nil),
+		exec.EXPECT().Close().Return(nil),
+	)
+	trace := search.NewTrace("test")
+	results := NewResults(testOpts)
+	_, err = b.Query(Query{}, QueryOptions{Trace: trace}, results)
+	require.NoError(t, err)
+
+	require.Len(t, trace.Children, 1)
+	blockTrace := trace.Children[0]
+	require.Equal(t, fmt.Sprintf("block(%s)", start.Format(time.RFC3339)), blockTrace.Name)
+	require.Equal(t, int64(1), blockTrace.DocsEmitted)
+	require.Equal(t, int64(3), blockTrace.TermsScanned)
+	require.Equal(t, int64(5), blockTrace.PostingsDecoded)
+	require.Len(t, blockTrace.Children, 1)
+}
+
 func TestBlockMockQueryLimitExhaustive(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -718,7 +813,7 @@ func TestBlockMockQueryLimitExhaustive(t *testing.T) {
 	require.True(t, ok)
 
 	exec := search.NewMockExecutor(ctrl)
-	b.newExecutorFn = func() (search.Executor, error) {
+	b.newExecutorFn = func(_ search.Options) (search.Executor, error) {
 		return exec, nil
 	}
 
@@ -760,7 +855,7 @@ func TestBlockMockQueryMergeResultsMapLimit(t *testing.T) {
 	require.NoError(t, b.Seal())
 
 	exec := search.NewMockExecutor(ctrl)
-	b.newExecutorFn = func() (search.Executor, error) {
+	b.newExecutorFn = func(_ search.Options) (search.Executor, error) {
 		return exec, nil
 	}
 
@@ -789,6 +884,89 @@ func TestBlockMockQueryMergeResultsMapLimit(t *testing.T) {
 		ident.NewTagsIterator(t1)))
 }
 
+func TestBlockMockQueryDocsLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testMD := newTestNSMetadata(t)
+	start := time.Now().Truncate(time.Hour)
+	blk, err := NewBlock(start, testMD, testOpts)
+	require.NoError(t, err)
+
+	b, ok := blk.(*block)
+	require.True(t, ok)
+
+	exec := search.NewMockExecutor(ctrl)
+	b.newExecutorFn = func(_ search.Options) (search.Executor, error) {
+		return exec, nil
+	}
+
+	results := NewResults(testOpts)
+	_, _, err = results.Add(testDoc1())
+	require.NoError(t, err)
+
+	// NB: the duplicate document counts against the docs limit even though
+	// it doesn't add a series to the results.
+	dIter := doc.NewMockIterator(ctrl)
+	gomock.InOrder(
+		exec.EXPECT().Execute(gomock.Any()).Return(dIter, nil),
+		dIter.EXPECT().Next().Return(true),
+		dIter.EXPECT().Current().Return(testDoc1DupeID()),
+		dIter.EXPECT().Next().Return(true),
+		dIter.EXPECT().Err().Return(nil),
+		dIter.EXPECT().Close().Return(nil),
+		exec.EXPECT().Close().Return(nil),
+	)
+	exhaustive, err := b.Query(Query{}, QueryOptions{DocsLimit: 2}, results)
+	require.NoError(t, err)
+	require.False(t, exhaustive)
+	require.Equal(t, 1, results.Size())
+	require.Equal(t, 2, results.TotalDocsCount())
+}
+
+func TestBlockMockQueryPageIgnoresDocsLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testMD := newTestNSMetadata(t)
+	start := time.Now().Truncate(time.Hour)
+	blk, err := NewBlock(start, testMD, testOpts)
+	require.NoError(t, err)
+
+	b, ok := blk.(*block)
+	require.True(t, ok)
+
+	exec := search.NewMockExecutor(ctrl)
+	b.newExecutorFn = func(_ search.Options) (search.Executor, error) {
+		return exec, nil
+	}
+
+	results := NewResults(testOpts)
+	results.SetPage(nil, 10)
+	_, _, err = results.Add(testDoc1())
+	require.NoError(t, err)
+
+	// NB: a paged query visits every document despite the docs limit, as the
+	// series of any documents skipped would otherwise be missing from the pages.
+	dIter := doc.NewMockIterator(ctrl)
+	gomock.InOrder(
+		exec.EXPECT().Execute(gomock.Any()).Return(dIter, nil),
+		dIter.EXPECT().Next().Return(true),
+		dIter.EXPECT().Current().Return(testDoc1DupeID()),
+		dIter.EXPECT().Next().Return(true),
+		dIter.EXPECT().Current().Return(testDoc2()),
+		dIter.EXPECT().Next().Return(false),
+		dIter.EXPECT().Err().Return(nil),
+		dIter.EXPECT().Close().Return(nil),
+		exec.EXPECT().Close().Return(nil),
+	)
+	exhaustive, err := b.Query(Query{}, QueryOptions{DocsLimit: 2, PageSize: 10}, results)
+	require.NoError(t, err)
+	require.True(t, exhaustive)
+	require.Equal(t, 2, results.Size())
+	require.Equal(t, 3, results.TotalDocsCount())
+}
+
 func TestBlockMockQueryMergeResultsDupeID(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -802,7 +980,7 @@ func TestBlockMockQueryMergeResultsDupeID(t *testing.T) {
 	require.True(t, ok)
 
 	exec := search.NewMockExecutor(ctrl)
-	b.newExecutorFn = func() (search.Executor, error) {
+	b.newExecutorFn = func(_ search.Options) (search.Executor, error) {
 		return exec, nil
 	}
 
@@ -1322,6 +1500,56 @@ func TestBlockE2EInsertQueryLimit(t *testing.T) {
 	require.Equal(t, 1, numFound)
 }
 
+func TestBlockE2EInsertQuerySeriesLimitDeterministic(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testMD := newTestNSMetadata(t)
+	blockSize := time.Hour
+	blockStart := time.Now().Truncate(blockSize)
+
+	blk, err := NewBlock(blockStart, testMD, testOpts)
+	require.NoError(t, err)
+
+	batch := NewWriteBatch(WriteBatchOptions{
+		IndexBlockSize: blockSize,
+	})
+	for _, d := range []doc.Document{testDoc1(), testDoc2()} {
+		h := NewMockOnIndexSeries(ctrl)
+		h.EXPECT().OnIndexFinalize(xtime.ToUnixNano(blockStart))
+		h.EXPECT().OnIndexSuccess(xtime.ToUnixNano(blockStart))
+		batch.Append(WriteBatchEntry{
+			Timestamp:     blockStart.Add(time.Minute),
+			OnIndexSeries: h,
+		}, d)
+	}
+	res, err := blk.WriteBatch(batch)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), res.NumSuccess)
+
+	q, err := idx.NewRegexpQuery([]byte("bar"), []byte("b.*"))
+	require.NoError(t, err)
+
+	// Truncated queries return the same series each time they're run.
+	var expected []string
+	for i := 0; i < 5; i++ {
+		results := NewResults(testOpts)
+		exhaustive, err := blk.Query(Query{q}, QueryOptions{SeriesLimit: 1}, results)
+		require.NoError(t, err)
+		require.False(t, exhaustive)
+		require.Equal(t, 1, results.Size())
+
+		var ids []string
+		for _, entry := range results.Map().Iter() {
+			ids = append(ids, entry.Key().String())
+		}
+		if expected == nil {
+			expected = ids
+		}
+		require.Equal(t, expected, ids)
+	}
+}
+
 func TestBlockE2EInsertAddResultsQuery(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -1456,6 +1684,173 @@ func TestBlockE2EInsertAddResultsMergeQuery(t *testing.T) {
 		ident.NewTagsIterator(t2)))
 }
 
+func TestBlockE2EInsertAddResultsAggregateTerms(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testMD := newTestNSMetadata(t)
+	blockSize := time.Hour
+
+	now := time.Now()
+	blockStart := now.Truncate(blockSize)
+
+	nowNotBlockStartAligned := now.
+		Truncate(blockSize).
+		Add(time.Minute)
+
+	blk, err := NewBlock(blockStart, testMD, testOpts)
+	require.NoError(t, err)
+
+	h1 := NewMockOnIndexSeries(ctrl)
+	h1.EXPECT().OnIndexFinalize(xtime.ToUnixNano(blockStart))
+	h1.EXPECT().OnIndexSuccess(xtime.ToUnixNano(blockStart))
+
+	h2 := NewMockOnIndexSeries(ctrl)
+	h2.EXPECT().OnIndexFinalize(xtime.ToUnixNano(blockStart))
+	h2.EXPECT().OnIndexSuccess(xtime.ToUnixNano(blockStart))
+
+	batch := NewWriteBatch(WriteBatchOptions{
+		IndexBlockSize: blockSize,
+	})
+	batch.Append(WriteBatchEntry{
+		Timestamp:     nowNotBlockStartAligned,
+		OnIndexSeries: h1,
+	}, testDoc1())
+	batch.Append(WriteBatchEntry{
+		Timestamp:     nowNotBlockStartAligned,
+		OnIndexSeries: h2,
+	}, testDoc2())
+
+	res, err := blk.WriteBatch(batch)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), res.NumSuccess)
+
+	seg := testSegment(t, testDoc1DupeID(), doc.Document{
+		ID: []byte("other"),
+		Fields: []doc.Field{
+			doc.Field{
+				Name:  []byte("some"),
+				Value: []byte("more"),
+			},
+		},
+	}, doc.Document{
+		ID: []byte("another"),
+		Fields: []doc.Field{
+			doc.Field{
+				Name:  []byte("some"),
+				Value: []byte("moon"),
+			},
+		},
+	})
+	require.NoError(t, blk.AddResults(
+		result.NewIndexBlock(blockStart, []segment.Segment{seg},
+			result.NewShardTimeRanges(blockStart, blockStart.Add(blockSize), 1, 2, 3))))
+
+	q, err := idx.NewWildcardQuery([]byte("some"), []byte("m*"))
+	require.NoError(t, err)
+
+	// The frequencies of the terms are summed across the segments of the block.
+	results := NewAggregateResults()
+	exhaustive, err := blk.AggregateTerms(Query{q}, QueryOptions{}, results)
+	require.NoError(t, err)
+	require.True(t, exhaustive)
+	require.Equal(t, []index.TermFrequency{
+		{Term: []byte("more"), Count: 3},
+		{Term: []byte("moon"), Count: 1},
+	}, results.Terms(0))
+
+	// Segments with as many terms as the limit may have more, so the results are
+	// no longer exhaustive.
+	results = NewAggregateResults()
+	exhaustive, err = blk.AggregateTerms(Query{q}, QueryOptions{Limit: 1}, results)
+	require.NoError(t, err)
+	require.False(t, exhaustive)
+	require.Equal(t, []index.TermFrequency{
+		{Term: []byte("more"), Count: 3},
+	}, results.Terms(1))
+
+	q, err = idx.NewRegexpQuery([]byte("bar"), []byte("b.*"))
+	require.NoError(t, err)
+	results = NewAggregateResults()
+	exhaustive, err = blk.AggregateTerms(Query{q}, QueryOptions{Limit: 10}, results)
+	require.NoError(t, err)
+	require.True(t, exhaustive)
+	require.Equal(t, []index.TermFrequency{
+		{Term: []byte("baz"), Count: 2},
+	}, results.Terms(10))
+}
+
+func TestBlockAggregateTermsUnsupportedQuery(t *testing.T) {
+	testMD := newTestNSMetadata(t)
+	start := time.Now().Truncate(time.Hour)
+	b, err := NewBlock(start, testMD, testOpts)
+	require.NoError(t, err)
+
+	q := idx.MustCreateTermQuery([]byte("bar"), []byte("baz"))
+	_, err = b.AggregateTerms(Query{q}, QueryOptions{}, NewAggregateResults())
+	require.Equal(t, errAggregateQueryNotTerms, err)
+
+	require.NoError(t, b.Close())
+	_, err = b.AggregateTerms(Query{q}, QueryOptions{}, NewAggregateResults())
+	require.Equal(t, errUnableToQueryBlockClosed, err)
+}
+
+func TestBlockAggregateTagValues(t *testing.T) {
+	testMD := newTestNSMetadata(t)
+	blockSize := time.Hour
+	blockStart := time.Now().Truncate(blockSize)
+
+	blk, err := NewBlock(blockStart, testMD, testOpts)
+	require.NoError(t, err)
+
+	seg1 := testSegment(t, doc.Document{
+		ID:     []byte("one"),
+		Fields: []doc.Field{{Name: []byte("some"), Value: []byte("more")}},
+	}, doc.Document{
+		ID:     []byte("two"),
+		Fields: []doc.Field{{Name: []byte("some"), Value: []byte("moon")}},
+	})
+	seg2 := testSegment(t, doc.Document{
+		ID:     []byte("three"),
+		Fields: []doc.Field{{Name: []byte("some"), Value: []byte("more")}},
+	}, doc.Document{
+		ID:     []byte("four"),
+		Fields: []doc.Field{{Name: []byte("some"), Value: []byte("less")}},
+	})
+	require.NoError(t, blk.AddResults(
+		result.NewIndexBlock(blockStart, []segment.Segment{seg1, seg2},
+			result.NewShardTimeRanges(blockStart, blockStart.Add(blockSize), 1, 2, 3))))
+
+	opts := AggregateTagValuesOptions{
+		TagName:        []byte("some"),
+		TagValuePrefix: []byte("mo"),
+		Limit:          1,
+	}
+	results, err := NewTagValuesResults(opts)
+	require.NoError(t, err)
+	require.NoError(t, blk.AggregateTagValues(opts, results))
+
+	page := results.Page()
+	require.False(t, page.Exhaustive)
+	require.Equal(t, []index.TermFrequency{
+		{Term: []byte("moon"), Count: 1},
+	}, page.Values)
+
+	opts.Cursor = page.Cursor
+	results, err = NewTagValuesResults(opts)
+	require.NoError(t, err)
+	require.NoError(t, blk.AggregateTagValues(opts, results))
+
+	page = results.Page()
+	require.True(t, page.Exhaustive)
+	require.Equal(t, []index.TermFrequency{
+		{Term: []byte("more"), Count: 2},
+	}, page.Values)
+
+	require.NoError(t, blk.Close())
+	require.Equal(t, errUnableToQueryBlockClosed, blk.AggregateTagValues(opts, results))
+}
+
 func testSegment(t *testing.T, docs ...doc.Document) segment.Segment {
 	seg, err := mem.NewSegment(0, testOpts.MemSegmentOptions())
 	require.NoError(t, err)
@@ -0,0 +1,286 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package compaction
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/storage/index/segments"
+	"github.com/m3db/m3/src/m3ninx/index/segment"
+	"github.com/m3db/m3x/clock"
+
+	"github.com/uber-go/tally"
+)
+
+const (
+	// numInsertRateBuckets is the number of buckets the insert rate window is
+	// divided into, older buckets are dropped as the window slides forward.
+	numInsertRateBuckets = 10
+)
+
+var (
+	errInsertRateThresholdNegative = errors.New("insert rate threshold must not be negative")
+	errInsertRateWindowNotPositive = errors.New("insert rate window must be positive")
+	errMaxDeferralNegative         = errors.New("max deferral must not be negative")
+)
+
+var (
+	// DefaultSchedulerOptions are the default compaction SchedulerOptions.
+	DefaultSchedulerOptions = SchedulerOptions{
+		InsertRateThreshold: 0,                // never defer compactions
+		InsertRateWindow:    10 * time.Second, // insert rate measured over the last 10s
+		MaxDeferral:         time.Minute,      // a compaction is never deferred for more than 1m
+	}
+)
+
+// SchedulerOptions are the knobs to tweak compaction scheduling behaviour.
+type SchedulerOptions struct {
+	// InsertRateThreshold is the number of index inserts per second above which
+	// non-essential compactions are deferred. Zero disables deferral.
+	InsertRateThreshold float64
+	// InsertRateWindow is the window over which the index insert rate is measured.
+	InsertRateWindow time.Duration
+	// MaxDeferral is the longest a compaction can be deferred for, once a task
+	// has a segment deferred for longer it is performed regardless of load.
+	MaxDeferral time.Duration
+}
+
+// Validate ensures the receiver SchedulerOptions specify valid values
+// for each of the knobs.
+func (o SchedulerOptions) Validate() error {
+	if o.InsertRateThreshold < 0 {
+		return errInsertRateThresholdNegative
+	}
+	if o.InsertRateWindow <= 0 {
+		return errInsertRateWindowNotPositive
+	}
+	if o.MaxDeferral < 0 {
+		return errMaxDeferralNegative
+	}
+	return nil
+}
+
+// Essential returns whether the task must be performed regardless of the write
+// load, i.e. it compacts a mutable segment which has grown past the size it's
+// allowed to grow to. Any other task merges segments which are small enough to
+// be left as is for a while.
+func (t Task) Essential(opts PlannerOptions) bool {
+	for _, s := range t.Segments {
+		if s.Type == segments.MutableType && s.Size >= opts.MutableSegmentSizeThreshold {
+			return true
+		}
+	}
+	return false
+}
+
+// Scheduler decides which tasks of a compaction plan are performed now, it tracks
+// the recent index insert rate and defers the non-essential tasks while the rate is
+// above a threshold so that compactions don't compete with write spikes. Deferred
+// tasks are caught up on once the insert rate drops, or once they've been deferred
+// for longer than the max deferral so that segments don't accumulate unboundedly.
+type Scheduler struct {
+	sync.Mutex
+
+	opts    SchedulerOptions
+	nowFn   clock.NowFn
+	metrics schedulerMetrics
+
+	inserts  []insertRateBucket
+	deferred map[segment.Segment]time.Time
+}
+
+type insertRateBucket struct {
+	start time.Time
+	count int64
+}
+
+type schedulerMetrics struct {
+	deferred  tally.Counter
+	forced    tally.Counter
+	completed tally.Counter
+}
+
+func newSchedulerMetrics(scope tally.Scope) schedulerMetrics {
+	return schedulerMetrics{
+		deferred:  scope.Counter("deferred"),
+		forced:    scope.Counter("forced"),
+		completed: scope.Counter("completed"),
+	}
+}
+
+// NewScheduler returns a new compaction Scheduler.
+func NewScheduler(
+	opts SchedulerOptions,
+	nowFn clock.NowFn,
+	scope tally.Scope,
+) (*Scheduler, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return &Scheduler{
+		opts:     opts,
+		nowFn:    nowFn,
+		metrics:  newSchedulerMetrics(scope),
+		inserts:  make([]insertRateBucket, numInsertRateBuckets),
+		deferred: make(map[segment.Segment]time.Time),
+	}, nil
+}
+
+// SetOptions updates the options of the scheduler, the insert rate recorded
+// so far is kept.
+func (s *Scheduler) SetOptions(opts SchedulerOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	s.Lock()
+	s.opts = opts
+	s.Unlock()
+	return nil
+}
+
+// RecordInserts records the given number of index inserts.
+func (s *Scheduler) RecordInserts(n int) {
+	s.Lock()
+	bucket := s.insertRateBucketWithLock(s.nowFn())
+	bucket.count += int64(n)
+	s.Unlock()
+}
+
+// InsertRate returns the number of index inserts per second over the insert
+// rate window.
+func (s *Scheduler) InsertRate() float64 {
+	s.Lock()
+	defer s.Unlock()
+	return s.insertRateWithLock(s.nowFn())
+}
+
+// Schedule returns the tasks of the plan which are to be performed now, in the
+// order of the plan. Every other task is deferred, it's expected to be returned
+// by a subsequent plan once the load allows it to be performed.
+// NB: deferrals are tracked by the segment of each candidate, so the segments
+// of the planned tasks must be set.
+func (s *Scheduler) Schedule(plan *Plan, opts PlannerOptions) []Task {
+	s.Lock()
+	defer s.Unlock()
+
+	var (
+		now     = s.nowFn()
+		busy    = s.opts.InsertRateThreshold > 0 && s.insertRateWithLock(now) > s.opts.InsertRateThreshold
+		planned = make(map[segment.Segment]struct{}, len(s.deferred))
+		tasks   = make([]Task, 0, len(plan.Tasks))
+	)
+	for _, task := range plan.Tasks {
+		for _, seg := range task.Segments {
+			planned[seg.Segment] = struct{}{}
+		}
+
+		if task.Essential(opts) {
+			tasks = append(tasks, task)
+			continue
+		}
+
+		if s.deadlineReachedWithLock(task, now) {
+			s.metrics.forced.Inc(1)
+			tasks = append(tasks, task)
+			continue
+		}
+
+		if !busy {
+			// i.e. a quiet period, catch up on any compactions deferred so far.
+			tasks = append(tasks, task)
+			continue
+		}
+
+		s.metrics.deferred.Inc(1)
+		for _, seg := range task.Segments {
+			if _, ok := s.deferred[seg.Segment]; !ok {
+				s.deferred[seg.Segment] = now
+			}
+		}
+	}
+
+	// forget the deferrals of segments which are no longer candidates for compaction.
+	for seg := range s.deferred {
+		if _, ok := planned[seg]; !ok {
+			delete(s.deferred, seg)
+		}
+	}
+
+	return tasks
+}
+
+// Completed marks the given scheduled task as performed.
+func (s *Scheduler) Completed(task Task) {
+	s.Lock()
+	for _, seg := range task.Segments {
+		delete(s.deferred, seg.Segment)
+	}
+	s.Unlock()
+	s.metrics.completed.Inc(1)
+}
+
+func (s *Scheduler) deadlineReachedWithLock(task Task, now time.Time) bool {
+	for _, seg := range task.Segments {
+		deferredAt, ok := s.deferred[seg.Segment]
+		if ok && now.Sub(deferredAt) >= s.opts.MaxDeferral {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Scheduler) bucketSizeWithLock() time.Duration {
+	bucketSize := s.opts.InsertRateWindow / numInsertRateBuckets
+	if bucketSize <= 0 {
+		bucketSize = 1
+	}
+	return bucketSize
+}
+
+func (s *Scheduler) insertRateBucketWithLock(now time.Time) *insertRateBucket {
+	var (
+		bucketSize = s.bucketSizeWithLock()
+		start      = now.Truncate(bucketSize)
+		idx        = (start.UnixNano() / int64(bucketSize)) % numInsertRateBuckets
+		bucket     = &s.inserts[idx]
+	)
+	if !bucket.start.Equal(start) {
+		// i.e. the bucket holds the inserts of an earlier window.
+		*bucket = insertRateBucket{start: start}
+	}
+	return bucket
+}
+
+func (s *Scheduler) insertRateWithLock(now time.Time) float64 {
+	var (
+		bucketSize  = s.bucketSizeWithLock()
+		windowStart = now.Truncate(bucketSize).Add(-bucketSize * (numInsertRateBuckets - 1))
+		total       int64
+	)
+	for _, bucket := range s.inserts {
+		if !bucket.start.Before(windowStart) && !bucket.start.After(now) {
+			total += bucket.count
+		}
+	}
+	return float64(total) / (bucketSize * numInsertRateBuckets).Seconds()
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package compaction
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/storage/index/segments"
+	"github.com/m3db/m3/src/m3ninx/index/segment"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func TestSchedulerOptionsValidate(t *testing.T) {
+	require.NoError(t, DefaultSchedulerOptions.Validate())
+
+	opts := DefaultSchedulerOptions
+	opts.InsertRateThreshold = -1
+	require.Equal(t, errInsertRateThresholdNegative, opts.Validate())
+
+	opts = DefaultSchedulerOptions
+	opts.InsertRateWindow = 0
+	require.Equal(t, errInsertRateWindowNotPositive, opts.Validate())
+
+	opts = DefaultSchedulerOptions
+	opts.MaxDeferral = -time.Second
+	require.Equal(t, errMaxDeferralNegative, opts.Validate())
+}
+
+func TestSchedulerInsertRate(t *testing.T) {
+	now := time.Unix(0, 0)
+	s, err := NewScheduler(SchedulerOptions{
+		InsertRateWindow: 10 * time.Second,
+	}, func() time.Time { return now }, tally.NoopScope)
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		s.RecordInserts(100)
+		now = now.Add(time.Second)
+	}
+	require.Equal(t, 90.0, s.InsertRate())
+
+	// inserts older than the window no longer count towards the rate.
+	now = now.Add(5 * time.Second)
+	require.Equal(t, 40.0, s.InsertRate())
+	now = now.Add(time.Minute)
+	require.Equal(t, 0.0, s.InsertRate())
+}
+
+func TestSchedulerBurstyLoad(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		now   = time.Unix(0, 0)
+		scope = tally.NewTestScope("", nil)
+		opts  = testOptions()
+	)
+	s, err := NewScheduler(SchedulerOptions{
+		InsertRateThreshold: 100,
+		InsertRateWindow:    10 * time.Second,
+		MaxDeferral:         time.Minute,
+	}, func() time.Time { return now }, scope)
+	require.NoError(t, err)
+
+	var (
+		essential = Task{Segments: []Segment{
+			{Type: segments.MutableType, Size: opts.MutableSegmentSizeThreshold, Segment: segment.NewMockSegment(ctrl)},
+		}}
+		merge = Task{Segments: []Segment{
+			{Type: segments.FSTType, Size: 10, Segment: segment.NewMockSegment(ctrl)},
+			{Type: segments.FSTType, Size: 20, Segment: segment.NewMockSegment(ctrl)},
+		}}
+		plan = &Plan{Tasks: []Task{essential, merge}}
+	)
+	require.True(t, essential.Essential(opts))
+	require.False(t, merge.Essential(opts))
+
+	// advance moves the clock forward a second at a time, recording the given
+	// number of inserts every second.
+	advance := func(d time.Duration, insertsPerSecond int) {
+		for end := now.Add(d); now.Before(end); now = now.Add(time.Second) {
+			s.RecordInserts(insertsPerSecond)
+		}
+	}
+	requireCounters := func(deferred, forced, completed int64) {
+		counters := scope.Snapshot().Counters()
+		require.Equal(t, deferred, counters["deferred+"].Value())
+		require.Equal(t, forced, counters["forced+"].Value())
+		require.Equal(t, completed, counters["completed+"].Value())
+	}
+
+	// quiet period, every task is performed.
+	advance(10*time.Second, 10)
+	require.Equal(t, []Task{essential, merge}, s.Schedule(plan, opts))
+	requireCounters(0, 0, 0)
+
+	// a write spike defers the merge, but not the essential task.
+	advance(10*time.Second, 1000)
+	require.Equal(t, []Task{essential}, s.Schedule(plan, opts))
+	s.Completed(essential)
+	requireCounters(1, 0, 1)
+
+	advance(30*time.Second, 1000)
+	require.Equal(t, []Task{}, s.Schedule(&Plan{Tasks: []Task{merge}}, opts))
+	requireCounters(2, 0, 1)
+
+	// the merge is forced once it's been deferred for the max deferral.
+	advance(30*time.Second, 1000)
+	require.Equal(t, []Task{merge}, s.Schedule(&Plan{Tasks: []Task{merge}}, opts))
+	s.Completed(merge)
+	requireCounters(2, 1, 2)
+
+	// once completed, the segments are deferred afresh while the spike continues.
+	advance(10*time.Second, 1000)
+	require.Equal(t, []Task{}, s.Schedule(&Plan{Tasks: []Task{merge}}, opts))
+	requireCounters(3, 1, 2)
+
+	// deferred tasks are caught up on once the insert rate drops.
+	advance(10*time.Second, 10)
+	require.Equal(t, []Task{merge}, s.Schedule(&Plan{Tasks: []Task{merge}}, opts))
+	requireCounters(3, 1, 2)
+}
+
+func TestSchedulerForgetsUnplannedSegments(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Unix(0, 0)
+	s, err := NewScheduler(SchedulerOptions{
+		InsertRateThreshold: 1,
+		InsertRateWindow:    10 * time.Second,
+		MaxDeferral:         time.Minute,
+	}, func() time.Time { return now }, tally.NoopScope)
+	require.NoError(t, err)
+	s.RecordInserts(1000)
+
+	merge := Task{Segments: []Segment{
+		{Type: segments.FSTType, Size: 10, Segment: segment.NewMockSegment(ctrl)},
+		{Type: segments.FSTType, Size: 20, Segment: segment.NewMockSegment(ctrl)},
+	}}
+	require.Empty(t, s.Schedule(&Plan{Tasks: []Task{merge}}, testOptions()))
+	require.Len(t, s.deferred, 2)
+
+	require.Empty(t, s.Schedule(&Plan{}, testOptions()))
+	require.Empty(t, s.deferred)
+}
+
+func TestSchedulerSetOptions(t *testing.T) {
+	now := time.Unix(0, 0)
+	s, err := NewScheduler(DefaultSchedulerOptions,
+		func() time.Time { return now }, tally.NoopScope)
+	require.NoError(t, err)
+	s.RecordInserts(1000)
+
+	merge := Task{Segments: []Segment{{Type: segments.FSTType, Size: 10}}}
+	require.Len(t, s.Schedule(&Plan{Tasks: []Task{merge}}, testOptions()), 1)
+
+	opts := DefaultSchedulerOptions
+	opts.InsertRateThreshold = 1
+	require.NoError(t, s.SetOptions(opts))
+	require.Empty(t, s.Schedule(&Plan{Tasks: []Task{merge}}, testOptions()))
+
+	opts.InsertRateThreshold = -1
+	require.Error(t, s.SetOptions(opts))
+}
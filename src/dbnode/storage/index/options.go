@@ -22,9 +22,11 @@ package index
 
 import (
 	"errors"
+	"time"
 
 	"github.com/m3db/m3/src/dbnode/clock"
 	"github.com/m3db/m3/src/m3ninx/index/segment/mem"
+	"github.com/m3db/m3/src/m3ninx/search"
 	"github.com/m3db/m3x/ident"
 	"github.com/m3db/m3x/instrument"
 	"github.com/m3db/m3x/pool"
@@ -50,6 +52,9 @@ type opts struct {
 	idPool         ident.Pool
 	bytesPool      pool.CheckedBytesPool
 	resultsPool    ResultsPool
+	maxQueryTerms  int
+	postingsCache  *search.PostingsCache
+	blockGrace     time.Duration
 }
 
 var undefinedUUIDFn = func() ([]byte, error) { return nil, errIDGenerationDisabled }
@@ -159,3 +164,33 @@ func (o *opts) SetResultsPool(value ResultsPool) Options {
 func (o *opts) ResultsPool() ResultsPool {
 	return o.resultsPool
 }
+
+func (o *opts) SetMaxQueryTermsMatched(value int) Options {
+	opts := *o
+	opts.maxQueryTerms = value
+	return &opts
+}
+
+func (o *opts) MaxQueryTermsMatched() int {
+	return o.maxQueryTerms
+}
+
+func (o *opts) SetPostingsCache(value *search.PostingsCache) Options {
+	opts := *o
+	opts.postingsCache = value
+	return &opts
+}
+
+func (o *opts) PostingsCache() *search.PostingsCache {
+	return o.postingsCache
+}
+
+func (o *opts) SetBlockRetentionGracePeriod(value time.Duration) Options {
+	opts := *o
+	opts.blockGrace = value
+	return &opts
+}
+
+func (o *opts) BlockRetentionGracePeriod() time.Duration {
+	return o.blockGrace
+}
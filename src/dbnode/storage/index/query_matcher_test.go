@@ -30,7 +30,7 @@ import (
 )
 
 func TestQueryMatcherTermQuery(t *testing.T) {
-	tq := idx.NewTermQuery([]byte("abc"), []byte("def"))
+	tq := idx.MustCreateTermQuery([]byte("abc"), []byte("def"))
 	q := index.Query{tq}
 	require.True(t, index.NewQueryMatcher(q).Matches(q))
 }
@@ -21,6 +21,8 @@
 package index
 
 import (
+	"bytes"
+	"container/heap"
 	"errors"
 
 	"github.com/m3db/m3/src/m3ninx/doc"
@@ -35,8 +37,16 @@ var (
 type results struct {
 	nsID       ident.ID
 	size       int
+	docsCount  int
 	resultsMap *ResultsMap
 
+	// NB: when paging, pageIDs is a max heap of the IDs held so the highest
+	// can be evicted once the page is full.
+	pageToken []byte
+	pageSize  int
+	pageIDs   pageIDsHeap
+	pageFull  bool
+
 	idPool    ident.Pool
 	bytesPool pool.CheckedBytesPool
 
@@ -55,10 +65,15 @@ func NewResults(opts Options) Results {
 
 func (r *results) Add(d doc.Document) (added bool, size int, err error) {
 	added = false
+	r.docsCount++
 	if len(d.ID) == 0 {
 		return added, r.size, errUnableToAddDocMissingID
 	}
 
+	if r.pageSize > 0 && !r.inPage(d.ID) {
+		return added, r.size, nil
+	}
+
 	// NB: can cast the []byte -> ident.ID to avoid an alloc
 	// before we're sure we need it.
 	tsID := ident.BytesID(d.ID)
@@ -68,6 +83,13 @@ func (r *results) Add(d doc.Document) (added bool, size int, err error) {
 		return added, r.size, nil
 	}
 
+	if r.pageSize > 0 {
+		if r.size >= r.pageSize {
+			r.evictPageMax()
+		}
+		heap.Push(&r.pageIDs, append([]byte(nil), d.ID...))
+	}
+
 	// i.e. it doesn't exist in the map, so we create the tags wrapping
 	// fields prodided by the document.
 	tags := r.tags(d.Fields)
@@ -81,6 +103,45 @@ func (r *results) Add(d doc.Document) (added bool, size int, err error) {
 	return added, r.size, nil
 }
 
+// inPage returns whether the ID may be held by the current page, marking the
+// page as full if it's dropped for sorting after every ID held.
+func (r *results) inPage(id []byte) bool {
+	if r.pageToken != nil && bytes.Compare(id, r.pageToken) <= 0 {
+		return false
+	}
+	if r.size >= r.pageSize && bytes.Compare(id, r.pageIDs[0]) > 0 {
+		r.pageFull = true
+		return false
+	}
+	return true
+}
+
+func (r *results) evictPageMax() {
+	id := ident.BytesID(heap.Pop(&r.pageIDs).([]byte))
+	if tags, ok := r.resultsMap.Get(id); ok {
+		tags.Finalize()
+	}
+	r.resultsMap.Delete(id)
+	r.size--
+	r.pageFull = true
+}
+
+func (r *results) SetPage(token []byte, size int) {
+	// NB: take a copy as the token may be backed by a pooled request.
+	r.pageToken = nil
+	if token != nil {
+		r.pageToken = append([]byte(nil), token...)
+	}
+	r.pageSize = size
+}
+
+func (r *results) NextPageToken() []byte {
+	if r.pageSize <= 0 || !r.pageFull || len(r.pageIDs) == 0 {
+		return nil
+	}
+	return append([]byte(nil), r.pageIDs[0]...)
+}
+
 func (r *results) tags(fields doc.Fields) ident.Tags {
 	tags := r.idPool.Tags()
 	for _, f := range fields {
@@ -115,6 +176,10 @@ func (r *results) Size() int {
 	return r.size
 }
 
+func (r *results) TotalDocsCount() int {
+	return r.docsCount
+}
+
 func (r *results) Reset(nsID ident.ID) {
 	// finalize existing held nsID
 	if r.nsID != nil {
@@ -135,6 +200,14 @@ func (r *results) Reset(nsID ident.ID) {
 	// reset all keys in the map next
 	r.resultsMap.Reset()
 	r.size = 0
+	r.docsCount = 0
+	r.pageToken = nil
+	r.pageSize = 0
+	for i := range r.pageIDs {
+		r.pageIDs[i] = nil
+	}
+	r.pageIDs = r.pageIDs[:0]
+	r.pageFull = false
 
 	// NB: could do keys+value in one step but I'm trying to avoid
 	// using an internal method of a code-gen'd type.
@@ -148,3 +221,23 @@ func (r *results) Finalize() {
 	}
 	r.pool.Put(r)
 }
+
+// pageIDsHeap implements heap.Interface as a max heap of series IDs.
+type pageIDsHeap [][]byte
+
+func (h pageIDsHeap) Len() int           { return len(h) }
+func (h pageIDsHeap) Less(i, j int) bool { return bytes.Compare(h[i], h[j]) > 0 }
+func (h pageIDsHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *pageIDsHeap) Push(x interface{}) {
+	*h = append(*h, x.([]byte))
+}
+
+func (h *pageIDsHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return x
+}
@@ -61,6 +61,7 @@ func TestResultsInsertIdempotency(t *testing.T) {
 	require.NoError(t, err)
 	require.False(t, added)
 	require.Equal(t, 1, size)
+	require.Equal(t, 2, res.TotalDocsCount())
 }
 
 func TestResultsFirstInsertWins(t *testing.T) {
@@ -162,6 +163,7 @@ func TestResultsReset(t *testing.T) {
 	require.False(t, ok)
 	require.Equal(t, 0, len(tags.Values()))
 	require.Equal(t, 0, res.Size())
+	require.Equal(t, 0, res.TotalDocsCount())
 }
 
 func TestResultsResetNamespaceClones(t *testing.T) {
@@ -172,3 +174,73 @@ func TestResultsResetNamespaceClones(t *testing.T) {
 	nsID.Finalize()
 	require.Equal(t, "something", res.Namespace().String())
 }
+
+func TestResultsPageKeepsLowestIDs(t *testing.T) {
+	res := NewResults(testOpts)
+	res.SetPage(nil, 3)
+
+	for _, id := range []string{"e", "b", "g", "a", "f", "c", "d"} {
+		_, size, err := res.Add(doc.Document{ID: []byte(id)})
+		require.NoError(t, err)
+		require.True(t, size <= 3)
+		require.True(t, res.Map().Len() <= 3)
+	}
+
+	require.Equal(t, 3, res.Size())
+	require.Equal(t, 7, res.TotalDocsCount())
+	for _, id := range []string{"a", "b", "c"} {
+		require.True(t, res.Map().Contains(ident.StringID(id)), id)
+	}
+	require.Equal(t, []byte("c"), res.NextPageToken())
+}
+
+func TestResultsPageAfterToken(t *testing.T) {
+	res := NewResults(testOpts)
+	res.SetPage([]byte("c"), 3)
+
+	for _, id := range []string{"e", "b", "g", "a", "f", "c", "d"} {
+		_, _, err := res.Add(doc.Document{ID: []byte(id)})
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, 3, res.Size())
+	for _, id := range []string{"d", "e", "f"} {
+		require.True(t, res.Map().Contains(ident.StringID(id)), id)
+	}
+	require.Equal(t, []byte("f"), res.NextPageToken())
+}
+
+func TestResultsPageLastPage(t *testing.T) {
+	res := NewResults(testOpts)
+	res.SetPage([]byte("d"), 3)
+
+	for _, id := range []string{"e", "b", "g", "a", "f", "c", "d", "e"} {
+		_, _, err := res.Add(doc.Document{ID: []byte(id)})
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, 3, res.Size())
+	for _, id := range []string{"e", "f", "g"} {
+		require.True(t, res.Map().Contains(ident.StringID(id)), id)
+	}
+	require.Nil(t, res.NextPageToken())
+}
+
+func TestResultsResetClearsPage(t *testing.T) {
+	res := NewResults(testOpts)
+	res.SetPage(nil, 1)
+	for _, id := range []string{"b", "a"} {
+		_, _, err := res.Add(doc.Document{ID: []byte(id)})
+		require.NoError(t, err)
+	}
+	require.Equal(t, []byte("a"), res.NextPageToken())
+
+	res.Reset(nil)
+	require.Nil(t, res.NextPageToken())
+	for _, id := range []string{"b", "a"} {
+		_, _, err := res.Add(doc.Document{ID: []byte(id)})
+		require.NoError(t, err)
+	}
+	require.Equal(t, 2, res.Size())
+	require.Nil(t, res.NextPageToken())
+}
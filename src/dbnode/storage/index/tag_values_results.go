@@ -0,0 +1,146 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package index
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+
+	m3ninxindex "github.com/m3db/m3/src/m3ninx/index"
+	xerrors "github.com/m3db/m3x/errors"
+)
+
+const (
+	// tagValuesCursorVersion prefixes the cursors of pages of tag values so that
+	// the format of the cursors can change without misinterpreting older cursors.
+	tagValuesCursorVersion byte = 1
+)
+
+var (
+	errInvalidTagValuesCursor = xerrors.NewInvalidParamsError(errors.New("invalid tag values cursor"))
+)
+
+// TagValuesResults accumulates the distinct values of a tag along with their document
+// frequencies across segments and blocks, for a single page of values.
+type TagValuesResults struct {
+	after    []byte
+	limit    int
+	counts   map[string]int
+	upper    string
+	hasUpper bool
+	pruned   bool
+}
+
+// NewTagValuesResults returns a new, empty TagValuesResults for the page of values
+// following the cursor of the options, or an error if the cursor is invalid.
+func NewTagValuesResults(opts AggregateTagValuesOptions) (*TagValuesResults, error) {
+	after, err := tagValuesCursorValue(opts.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	return &TagValuesResults{
+		after:  after,
+		limit:  opts.Limit,
+		counts: make(map[string]int),
+	}, nil
+}
+
+// Add adds the document frequencies of the given values to those already accumulated,
+// any values at or before the cursor of the page are ignored.
+func (r *TagValuesResults) Add(values []m3ninxindex.TermFrequency) {
+	for _, v := range values {
+		if r.after != nil && bytes.Compare(v.Term, r.after) <= 0 {
+			continue
+		}
+		if r.hasUpper && string(v.Term) > r.upper {
+			continue
+		}
+		r.counts[string(v.Term)] += v.Count
+	}
+
+	// NB: only the lowest values can be part of the page so once there are plenty more
+	// values than the limit the highest ones are dropped, along with any added later.
+	if r.limit > 0 && len(r.counts) > 2*r.limit {
+		r.prune()
+	}
+}
+
+func (r *TagValuesResults) prune() {
+	values := make([]string, 0, len(r.counts))
+	for v := range r.counts {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+	for _, v := range values[r.limit:] {
+		delete(r.counts, v)
+	}
+	r.upper = values[r.limit-1]
+	r.hasUpper = true
+	r.pruned = true
+}
+
+// Size returns the number of distinct values accumulated.
+func (r *TagValuesResults) Size() int {
+	return len(r.counts)
+}
+
+// Page returns at most limit of the values accumulated in ascending order, along with
+// the cursor of the next page if there are more values.
+func (r *TagValuesResults) Page() AggregateTagValuesResults {
+	values := make([]m3ninxindex.TermFrequency, 0, len(r.counts))
+	for v, count := range r.counts {
+		values = append(values, m3ninxindex.TermFrequency{Term: []byte(v), Count: count})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return bytes.Compare(values[i].Term, values[j].Term) < 0
+	})
+
+	result := AggregateTagValuesResults{Exhaustive: !r.pruned}
+	if r.limit > 0 && len(values) > r.limit {
+		values = values[:r.limit]
+		result.Exhaustive = false
+	}
+	if !result.Exhaustive && len(values) > 0 {
+		result.Cursor = NewTagValuesCursor(values[len(values)-1].Term)
+	}
+	result.Values = values
+	return result
+}
+
+// NewTagValuesCursor returns the cursor of the page of values following the given value.
+func NewTagValuesCursor(value []byte) []byte {
+	cursor := make([]byte, 0, 1+len(value))
+	cursor = append(cursor, tagValuesCursorVersion)
+	return append(cursor, value...)
+}
+
+// tagValuesCursorValue returns the last value of the page preceding the cursor, or nil
+// for the first page.
+func tagValuesCursorValue(cursor []byte) ([]byte, error) {
+	if len(cursor) == 0 {
+		return nil, nil
+	}
+	if cursor[0] != tagValuesCursorVersion {
+		return nil, errInvalidTagValuesCursor
+	}
+	return cursor[1:], nil
+}
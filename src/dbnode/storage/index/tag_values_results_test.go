@@ -0,0 +1,103 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package index
+
+import (
+	"testing"
+
+	m3ninxindex "github.com/m3db/m3/src/m3ninx/index"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testTagValues(values ...string) []m3ninxindex.TermFrequency {
+	results := make([]m3ninxindex.TermFrequency, 0, len(values))
+	for _, v := range values {
+		results = append(results, m3ninxindex.TermFrequency{Term: []byte(v), Count: 1})
+	}
+	return results
+}
+
+func TestTagValuesResultsPageSumsCounts(t *testing.T) {
+	res, err := NewTagValuesResults(AggregateTagValuesOptions{})
+	require.NoError(t, err)
+
+	res.Add(testTagValues("c", "a"))
+	res.Add(testTagValues("b", "a"))
+	require.Equal(t, 3, res.Size())
+
+	page := res.Page()
+	require.True(t, page.Exhaustive)
+	require.Nil(t, page.Cursor)
+	require.Equal(t, []m3ninxindex.TermFrequency{
+		{Term: []byte("a"), Count: 2},
+		{Term: []byte("b"), Count: 1},
+		{Term: []byte("c"), Count: 1},
+	}, page.Values)
+}
+
+func TestTagValuesResultsPageLimitAndCursor(t *testing.T) {
+	opts := AggregateTagValuesOptions{Limit: 2}
+	res, err := NewTagValuesResults(opts)
+	require.NoError(t, err)
+
+	res.Add(testTagValues("d", "b", "a", "c"))
+	page := res.Page()
+	require.False(t, page.Exhaustive)
+	require.Equal(t, testTagValues("a", "b"), page.Values)
+	require.Equal(t, NewTagValuesCursor([]byte("b")), page.Cursor)
+
+	opts.Cursor = page.Cursor
+	res, err = NewTagValuesResults(opts)
+	require.NoError(t, err)
+
+	res.Add(testTagValues("d", "b", "a", "c"))
+	page = res.Page()
+	require.True(t, page.Exhaustive)
+	require.Nil(t, page.Cursor)
+	require.Equal(t, testTagValues("c", "d"), page.Values)
+}
+
+func TestTagValuesResultsPrune(t *testing.T) {
+	res, err := NewTagValuesResults(AggregateTagValuesOptions{Limit: 1})
+	require.NoError(t, err)
+
+	res.Add(testTagValues("c", "b", "d"))
+	require.Equal(t, 1, res.Size())
+
+	// Values above those retained once pruned can no longer be part of the page.
+	res.Add(testTagValues("e", "b", "a"))
+	require.Equal(t, 2, res.Size())
+
+	page := res.Page()
+	require.False(t, page.Exhaustive)
+	require.Equal(t, []m3ninxindex.TermFrequency{
+		{Term: []byte("a"), Count: 1},
+	}, page.Values)
+	require.Equal(t, NewTagValuesCursor([]byte("a")), page.Cursor)
+}
+
+func TestTagValuesResultsInvalidCursor(t *testing.T) {
+	_, err := NewTagValuesResults(AggregateTagValuesOptions{
+		Cursor: []byte{tagValuesCursorVersion + 1, 'a'},
+	})
+	require.Equal(t, errInvalidTagValuesCursor, err)
+}
@@ -29,7 +29,9 @@ import (
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
 	"github.com/m3db/m3/src/m3ninx/doc"
 	"github.com/m3db/m3/src/m3ninx/idx"
+	m3ninxindex "github.com/m3db/m3/src/m3ninx/index"
 	"github.com/m3db/m3/src/m3ninx/index/segment/mem"
+	"github.com/m3db/m3/src/m3ninx/search"
 	"github.com/m3db/m3x/context"
 	"github.com/m3db/m3x/ident"
 	"github.com/m3db/m3x/instrument"
@@ -59,15 +61,110 @@ type Query struct {
 
 // QueryOptions enables users to specify constraints on query execution.
 type QueryOptions struct {
-	StartInclusive time.Time
-	EndExclusive   time.Time
-	Limit          int
+	StartInclusive  time.Time
+	EndExclusive    time.Time
+	Limit           int
+	MaxTermsMatched int
+
+	// SeriesLimit, if positive, is the maximum number of distinct series IDs
+	// collected before the query stops and returns a non-exhaustive result.
+	SeriesLimit int
+
+	// DocsLimit, if positive, is the maximum number of documents visited across
+	// every block queried, duplicates included, before the query stops and
+	// returns a non-exhaustive result.
+	DocsLimit int
+
+	// PageSize, if positive, restricts the results to a page of at most that many
+	// series IDs, the lowest sorting after the page token. Paging bounds the number
+	// of series held at once so neither the series nor the docs limits apply to
+	// paged queries.
+	PageSize int
+
+	// PageToken, if set, is the token returned with the previous page of a paged
+	// query, only the series IDs sorting after that page are returned.
+	PageToken []byte
+
+	// Trace, if set, records the execution of the query in each block it's run against.
+	Trace *search.Trace
+}
+
+// SeriesLimitExceeded returns whether the given number of series IDs has reached
+// either the limit or the series limit of the options.
+func (o QueryOptions) SeriesLimitExceeded(size int) bool {
+	if o.PageSize > 0 {
+		// NB: a page evicts the highest series IDs rather than stopping the query.
+		return false
+	}
+	return (o.Limit > 0 && size >= o.Limit) ||
+		(o.SeriesLimit > 0 && size >= o.SeriesLimit)
+}
+
+// DocsLimitExceeded returns whether the given number of documents has reached
+// the docs limit of the options.
+func (o QueryOptions) DocsLimitExceeded(docs int) bool {
+	if o.PageSize > 0 {
+		// NB: a page is only correct if every document after the page token is
+		// visited, otherwise the series of those skipped would never be paged.
+		return false
+	}
+	return o.DocsLimit > 0 && docs >= o.DocsLimit
+}
+
+// LimitsExceeded returns whether either the series or the docs limits of the
+// options have been reached.
+func (o QueryOptions) LimitsExceeded(size, docs int) bool {
+	return o.SeriesLimitExceeded(size) || o.DocsLimitExceeded(docs)
 }
 
 // QueryResults is the collection of results for a query.
 type QueryResults struct {
 	Results    Results
 	Exhaustive bool
+
+	// NextPageToken is the token of the page following the results of a paged
+	// query, it is nil once the last page has been returned.
+	NextPageToken []byte
+}
+
+// AggregateQueryResults is the collection of results for an aggregate query.
+type AggregateQueryResults struct {
+	Results    *AggregateResults
+	Exhaustive bool
+}
+
+// AggregateTagValuesOptions enables users to specify constraints on the aggregation
+// of the values of a tag.
+type AggregateTagValuesOptions struct {
+	StartInclusive time.Time
+	EndExclusive   time.Time
+
+	// TagName is the name of the tag whose values are aggregated.
+	TagName []byte
+
+	// TagValuePrefix, if set, restricts the values to those beginning with it.
+	TagValuePrefix []byte
+
+	// Limit is the maximum number of values returned, a non-positive limit returns
+	// every value.
+	Limit int
+
+	// Cursor, if set, is the cursor returned with a previous page of values, only the
+	// values following that page are returned.
+	Cursor []byte
+}
+
+// AggregateTagValuesResults is a page of the distinct values of a tag.
+type AggregateTagValuesResults struct {
+	// Values are the values of the tag in ascending order, along with the approximate
+	// number of documents which contain each of them.
+	Values []m3ninxindex.TermFrequency
+
+	// Exhaustive is whether there are no more values following this page.
+	Exhaustive bool
+
+	// Cursor is the opaque cursor of the next page of values if the page isn't exhaustive.
+	Cursor []byte
 }
 
 // Results is a collection of results for a query.
@@ -88,12 +185,24 @@ type Results interface {
 	// Size returns the number of IDs tracked.
 	Size() int
 
+	// TotalDocsCount returns the number of documents added to the results,
+	// including those whose IDs were already tracked.
+	TotalDocsCount() int
+
 	// Add converts the provided document to a metric and adds it to the results.
 	// This method makes a copy of the bytes backing the document, so the original
 	// may be modified after this function returns without affecting the results map.
 	// NB: it returns a bool to indicate if the doc was added (it won't be added
 	// if it already existed in the ResultsMap).
 	Add(d doc.Document) (added bool, size int, err error)
+
+	// SetPage restricts the results to the size lowest series IDs sorting after
+	// the provided page token, until the results are next reset.
+	SetPage(token []byte, size int)
+
+	// NextPageToken returns the token of the page following the results, it is
+	// nil unless series IDs were dropped from a full page.
+	NextPageToken() []byte
 }
 
 // ResultsAllocator allocates Results types.
@@ -145,6 +254,23 @@ type Block interface {
 		results Results,
 	) (exhaustive bool, err error)
 
+	// AggregateTerms adds the terms matched by the given query to the results along
+	// with their document frequencies. The query must be a regexp, prefix or wildcard
+	// query, and the Limit of the options limits the terms matched in each segment.
+	AggregateTerms(
+		query Query,
+		opts QueryOptions,
+		results *AggregateResults,
+	) (exhaustive bool, err error)
+
+	// AggregateTagValues adds the values of the tag of the options to the results
+	// along with their document frequencies, walking the term dictionaries of the
+	// segments of the Block rather than matching any documents.
+	AggregateTagValues(
+		opts AggregateTagValuesOptions,
+		results *TagValuesResults,
+	) error
+
 	// AddResults adds bootstrap results to the block, if c.
 	AddResults(results result.IndexBlock) error
 
@@ -170,7 +296,16 @@ type Block interface {
 	// data the mutable segments should have held at this time.
 	EvictMutableSegments() (EvictMutableSegmentResults, error)
 
-	// Close will release any held resources and close the Block.
+	// IncRef increments the references held to the Block, a Block closed while
+	// references are held keeps its segments until all the references are released.
+	IncRef()
+
+	// DecRef releases a reference held to the Block, releasing its resources if it
+	// was closed and this was the last reference.
+	DecRef()
+
+	// Close will release any held resources and close the Block, if any references
+	// are held the resources are released once the last reference is released.
 	Close() error
 }
 
@@ -552,4 +687,29 @@ type Options interface {
 
 	// ResultsPool returns the results pool.
 	ResultsPool() ResultsPool
+
+	// SetMaxQueryTermsMatched sets the maximum number of terms a regexp or wildcard
+	// query may match in a single segment before the query is rejected, a non-positive
+	// value means there is no limit.
+	SetMaxQueryTermsMatched(value int) Options
+
+	// MaxQueryTermsMatched returns the maximum number of terms a regexp or wildcard
+	// query may match in a single segment before the query is rejected.
+	MaxQueryTermsMatched() int
+
+	// SetPostingsCache sets the cache of the postings lists matched by queries against
+	// immutable segments, a nil cache means matches are not cached.
+	SetPostingsCache(value *search.PostingsCache) Options
+
+	// PostingsCache returns the cache of the postings lists matched by queries against
+	// immutable segments.
+	PostingsCache() *search.PostingsCache
+
+	// SetBlockRetentionGracePeriod sets the period past the retention period that
+	// index blocks are held in memory before they are sealed and released.
+	SetBlockRetentionGracePeriod(value time.Duration) Options
+
+	// BlockRetentionGracePeriod returns the period past the retention period that
+	// index blocks are held in memory before they are sealed and released.
+	BlockRetentionGracePeriod() time.Duration
 }
@@ -22,15 +22,19 @@ package storage
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/retention"
+	"github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
 	"github.com/m3db/m3/src/dbnode/storage/index"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3/src/m3ninx/doc"
+	m3ninxidx "github.com/m3db/m3/src/m3ninx/idx"
+	m3ninxindex "github.com/m3db/m3/src/m3ninx/index"
 	"github.com/m3db/m3/src/m3ninx/index/segment"
 	"github.com/m3db/m3x/context"
 	"github.com/m3db/m3x/ident"
@@ -39,6 +43,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
 )
 
 type testWriteBatchOption func(index.WriteBatchOptions) index.WriteBatchOptions
@@ -345,14 +350,163 @@ func TestNamespaceIndexTickExpire(t *testing.T) {
 	nowLock.Unlock()
 
 	c := context.NewCancellable()
+	b0.EXPECT().Tick(c, nowFn()).Return(index.BlockTickResult{
+		NumDocs:     10,
+		NumSegments: 2,
+	}, nil)
+	b0.EXPECT().IsSealed().Return(false)
+	b0.EXPECT().Seal().Return(nil)
 	b0.EXPECT().Close().Return(nil)
 	result, err := idx.Tick(c, nowFn())
 	require.NoError(t, err)
 	require.Equal(t, namespaceIndexTickResult{
-		NumBlocksEvicted: 1,
+		NumBlocksEvicted:   1,
+		NumSegmentsEvicted: 2,
+		NumDocsEvicted:     10,
 	}, result)
 }
 
+func TestNamespaceIndexTickExpireGracePeriod(t *testing.T) {
+	ctrl := gomock.NewController(xtest.Reporter{t})
+	defer ctrl.Finish()
+
+	retentionPeriod := 4 * time.Hour
+	blockSize := time.Hour
+	now := time.Now().Truncate(blockSize).Add(2 * time.Minute)
+	t0 := now.Truncate(blockSize)
+	var nowLock sync.Mutex
+	nowFn := func() time.Time {
+		nowLock.Lock()
+		defer nowLock.Unlock()
+		return now
+	}
+	opts := testDatabaseOptions()
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(nowFn))
+	opts = opts.SetIndexOptions(opts.IndexOptions().SetBlockRetentionGracePeriod(2 * blockSize))
+
+	b0 := index.NewMockBlock(ctrl)
+	b0.EXPECT().StartTime().Return(t0).AnyTimes()
+	newBlockFn := func(ts time.Time, md namespace.Metadata, io index.Options) (index.Block, error) {
+		if ts.Equal(t0) {
+			return b0, nil
+		}
+		panic("should never get here")
+	}
+	md := testNamespaceMetadata(blockSize, retentionPeriod)
+	idx, err := newNamespaceIndexWithNewBlockFn(md, newBlockFn, opts)
+	require.NoError(t, err)
+
+	// the block is past the retention period but within the grace period.
+	nowLock.Lock()
+	now = now.Add(retentionPeriod).Add(blockSize)
+	nowLock.Unlock()
+
+	c := context.NewCancellable()
+	b0.EXPECT().Tick(c, nowFn()).Return(index.BlockTickResult{
+		NumDocs:     10,
+		NumSegments: 2,
+	}, nil)
+	b0.EXPECT().IsSealed().Return(true)
+	result, err := idx.Tick(c, nowFn())
+	require.NoError(t, err)
+	require.Equal(t, namespaceIndexTickResult{
+		NumBlocks:    1,
+		NumSegments:  2,
+		NumTotalDocs: 10,
+	}, result)
+
+	// the block is past the grace period.
+	nowLock.Lock()
+	now = now.Add(2 * blockSize)
+	nowLock.Unlock()
+
+	b0.EXPECT().Tick(c, nowFn()).Return(index.BlockTickResult{
+		NumDocs:     10,
+		NumSegments: 2,
+	}, nil)
+	b0.EXPECT().IsSealed().Return(true)
+	b0.EXPECT().Close().Return(nil)
+	result, err = idx.Tick(c, nowFn())
+	require.NoError(t, err)
+	require.Equal(t, namespaceIndexTickResult{
+		NumBlocksEvicted:   1,
+		NumSegmentsEvicted: 2,
+		NumDocsEvicted:     10,
+	}, result)
+}
+
+func TestNamespaceIndexTickExpireDuringQuery(t *testing.T) {
+	ctrl := gomock.NewController(xtest.Reporter{t})
+	defer ctrl.Finish()
+
+	retentionPeriod := 4 * time.Hour
+	blockSize := time.Hour
+	now := time.Now().Truncate(blockSize).Add(2 * time.Minute)
+	t0 := now.Truncate(blockSize)
+	var nowLock sync.Mutex
+	nowFn := func() time.Time {
+		nowLock.Lock()
+		defer nowLock.Unlock()
+		return now
+	}
+	opts := testDatabaseOptions()
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(nowFn))
+
+	var b0 index.Block
+	newBlockFn := func(ts time.Time, md namespace.Metadata, io index.Options) (index.Block, error) {
+		blk, err := index.NewBlock(ts, md, io)
+		if ts.Equal(t0) {
+			b0 = blk
+		}
+		return blk, err
+	}
+	md := testNamespaceMetadata(blockSize, retentionPeriod)
+	idx, err := newNamespaceIndexWithNewBlockFn(md, newBlockFn, opts)
+	require.NoError(t, err)
+	require.NotNil(t, b0)
+
+	// hold a reference to the block the way an in-flight query does.
+	nsIdx := idx.(*nsIndex)
+	nsIdx.state.RLock()
+	blocks, err := nsIdx.blocksForQueryWithRLock(t0, t0.Add(blockSize))
+	nsIdx.state.RUnlock()
+	require.NoError(t, err)
+	require.Equal(t, []index.Block{b0}, blocks)
+
+	nowLock.Lock()
+	now = now.Add(retentionPeriod).Add(blockSize)
+	nowLock.Unlock()
+
+	c := context.NewCancellable()
+	result, err := idx.Tick(c, nowFn())
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.NumBlocksEvicted)
+	require.True(t, b0.IsSealed())
+
+	// the in-flight query is still able to query the evicted block.
+	q, err := m3ninxidx.NewRegexpQuery([]byte("foo"), []byte("b.*"))
+	require.NoError(t, err)
+	results := index.NewResults(opts.IndexOptions())
+	_, err = b0.Query(index.Query{Query: q}, index.QueryOptions{}, results)
+	require.NoError(t, err)
+
+	// once the query completes the block is released.
+	decRefBlocks(blocks)
+	_, err = b0.Query(index.Query{Query: q}, index.QueryOptions{}, results)
+	require.Error(t, err)
+
+	// queries over the evicted range return no results rather than erroring.
+	ctx := context.NewContext()
+	res, err := idx.Query(ctx, index.Query{Query: q}, index.QueryOptions{
+		StartInclusive: t0,
+		EndExclusive:   t0.Add(blockSize),
+	})
+	require.NoError(t, err)
+	require.True(t, res.Exhaustive)
+	require.Equal(t, 0, res.Results.Size())
+	ctx.BlockingClose()
+}
+
 func TestNamespaceIndexTick(t *testing.T) {
 	ctrl := gomock.NewController(xtest.Reporter{t})
 	defer ctrl.Finish()
@@ -452,9 +606,13 @@ func TestNamespaceIndexBlockQuery(t *testing.T) {
 	b0 := index.NewMockBlock(ctrl)
 	b0.EXPECT().StartTime().Return(t0).AnyTimes()
 	b0.EXPECT().EndTime().Return(t0.Add(blockSize)).AnyTimes()
+	b0.EXPECT().IncRef().AnyTimes()
+	b0.EXPECT().DecRef().AnyTimes()
 	b1 := index.NewMockBlock(ctrl)
 	b1.EXPECT().StartTime().Return(t1).AnyTimes()
 	b1.EXPECT().EndTime().Return(t1.Add(blockSize)).AnyTimes()
+	b1.EXPECT().IncRef().AnyTimes()
+	b1.EXPECT().DecRef().AnyTimes()
 	newBlockFn := func(ts time.Time, md namespace.Metadata, io index.Options) (index.Block, error) {
 		if ts.Equal(t0) {
 			return b0, nil
@@ -510,3 +668,352 @@ func TestNamespaceIndexBlockQuery(t *testing.T) {
 	_, err = idx.Query(ctx, q, qOpts)
 	require.NoError(t, err)
 }
+
+func TestNamespaceIndexBlockAggregateTagValues(t *testing.T) {
+	ctrl := gomock.NewController(xtest.Reporter{t})
+	defer ctrl.Finish()
+
+	retention := 2 * time.Hour
+	blockSize := time.Hour
+	now := time.Now().Truncate(blockSize).Add(10 * time.Minute)
+	t0 := now.Truncate(blockSize)
+	t1 := t0.Add(1 * blockSize)
+	t2 := t1.Add(1 * blockSize)
+	opts := testDatabaseOptions()
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return now
+	}))
+
+	b0 := index.NewMockBlock(ctrl)
+	b0.EXPECT().StartTime().Return(t0).AnyTimes()
+	b0.EXPECT().EndTime().Return(t0.Add(blockSize)).AnyTimes()
+	b0.EXPECT().IncRef()
+	b0.EXPECT().DecRef()
+	b1 := index.NewMockBlock(ctrl)
+	b1.EXPECT().StartTime().Return(t1).AnyTimes()
+	b1.EXPECT().EndTime().Return(t1.Add(blockSize)).AnyTimes()
+	b1.EXPECT().IncRef()
+	b1.EXPECT().DecRef()
+	newBlockFn := func(ts time.Time, md namespace.Metadata, io index.Options) (index.Block, error) {
+		if ts.Equal(t0) {
+			return b0, nil
+		}
+		if ts.Equal(t1) {
+			return b1, nil
+		}
+		panic("should never get here")
+	}
+	md := testNamespaceMetadata(blockSize, retention)
+	idx, err := newNamespaceIndexWithNewBlockFn(md, newBlockFn, opts)
+	require.NoError(t, err)
+
+	t1Nanos := xtime.ToUnixNano(t1)
+	bootstrapResults := result.IndexResults{
+		t1Nanos: result.NewIndexBlock(t1, []segment.Segment{segment.NewMockSegment(ctrl)},
+			result.NewShardTimeRanges(t1, t2, 1, 2, 3)),
+	}
+	b1.EXPECT().AddResults(bootstrapResults[t1Nanos]).Return(nil)
+	require.NoError(t, idx.Bootstrap(bootstrapResults))
+
+	aggOpts := index.AggregateTagValuesOptions{
+		StartInclusive: t0,
+		EndExclusive:   t2,
+		TagName:        []byte("city"),
+		Limit:          2,
+	}
+	addValues := func(values ...string) func(index.AggregateTagValuesOptions, *index.TagValuesResults) error {
+		return func(_ index.AggregateTagValuesOptions, results *index.TagValuesResults) error {
+			for _, v := range values {
+				results.Add([]m3ninxindex.TermFrequency{{Term: []byte(v), Count: 1}})
+			}
+			return nil
+		}
+	}
+	b0.EXPECT().AggregateTagValues(aggOpts, gomock.Any()).DoAndReturn(addValues("b", "a"))
+	b1.EXPECT().AggregateTagValues(aggOpts, gomock.Any()).DoAndReturn(addValues("c", "a"))
+
+	// Values present in several blocks are deduped and their frequencies summed.
+	results, err := idx.AggregateTagValues(context.NewContext(), aggOpts)
+	require.NoError(t, err)
+	require.False(t, results.Exhaustive)
+	require.Equal(t, index.NewTagValuesCursor([]byte("b")), results.Cursor)
+	require.Equal(t, []m3ninxindex.TermFrequency{
+		{Term: []byte("a"), Count: 2},
+		{Term: []byte("b"), Count: 1},
+	}, results.Values)
+}
+
+func TestNamespaceIndexBlockQueryTooManyTermsMatched(t *testing.T) {
+	ctrl := gomock.NewController(xtest.Reporter{t})
+	defer ctrl.Finish()
+
+	retention := 2 * time.Hour
+	blockSize := time.Hour
+	now := time.Now().Truncate(blockSize).Add(10 * time.Minute)
+	t0 := now.Truncate(blockSize)
+	nowFn := func() time.Time { return now }
+
+	scope := tally.NewTestScope("", nil)
+	opts := testDatabaseOptions()
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(nowFn)).
+		SetInstrumentOptions(opts.InstrumentOptions().SetMetricsScope(scope))
+	opts = opts.SetIndexOptions(opts.IndexOptions().SetMaxQueryTermsMatched(10))
+
+	b0 := index.NewMockBlock(ctrl)
+	b0.EXPECT().StartTime().Return(t0).AnyTimes()
+	b0.EXPECT().EndTime().Return(t0.Add(blockSize)).AnyTimes()
+	b0.EXPECT().IncRef().AnyTimes()
+	b0.EXPECT().DecRef().AnyTimes()
+	newBlockFn := func(ts time.Time, md namespace.Metadata, io index.Options) (index.Block, error) {
+		return b0, nil
+	}
+	md := testNamespaceMetadata(blockSize, retention)
+	idx, err := newNamespaceIndexWithNewBlockFn(md, newBlockFn, opts)
+	require.NoError(t, err)
+
+	// the configured limit is used unless the query sets its own.
+	ctx := context.NewContext()
+	q := index.Query{}
+	qOpts := index.QueryOptions{
+		StartInclusive: t0,
+		EndExclusive:   now.Add(time.Minute),
+	}
+	limitedOpts := qOpts
+	limitedOpts.MaxTermsMatched = 10
+	b0.EXPECT().Query(q, limitedOpts, gomock.Any()).Return(false, m3ninxindex.ErrTooManyTermsMatched)
+	_, err = idx.Query(ctx, q, qOpts)
+	require.Equal(t, m3ninxindex.ErrTooManyTermsMatched, err)
+
+	qOpts.MaxTermsMatched = 20
+	b0.EXPECT().Query(q, qOpts, gomock.Any()).Return(true, nil)
+	_, err = idx.Query(ctx, q, qOpts)
+	require.NoError(t, err)
+
+	var rejected int64
+	for _, c := range scope.Snapshot().Counters() {
+		if c.Name() == "dbindex.query-rejected" {
+			rejected += c.Value()
+		}
+	}
+	require.Equal(t, int64(1), rejected)
+}
+
+func TestNamespaceIndexBlockQueryLimits(t *testing.T) {
+	ctrl := gomock.NewController(xtest.Reporter{t})
+	defer ctrl.Finish()
+
+	retention := 2 * time.Hour
+	blockSize := time.Hour
+	now := time.Now().Truncate(blockSize).Add(10 * time.Minute)
+	t0 := now.Truncate(blockSize)
+	nowFn := func() time.Time { return now }
+	md := testNamespaceMetadata(blockSize, retention)
+
+	// The namespace limits take precedence over the default limits.
+	runtimeOptsMgr := runtime.NewOptionsManager()
+	require.NoError(t, runtimeOptsMgr.Update(runtime.NewOptions().
+		SetIndexQueryLimits(runtime.IndexQueryLimits{SeriesLimit: 10, DocsLimit: 100}).
+		SetNamespaceIndexQueryLimits(map[string]runtime.IndexQueryLimits{
+			md.ID().String(): {SeriesLimit: 1},
+		})))
+
+	scope := tally.NewTestScope("", nil)
+	opts := testDatabaseOptions()
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(nowFn)).
+		SetInstrumentOptions(opts.InstrumentOptions().SetMetricsScope(scope)).
+		SetRuntimeOptionsManager(runtimeOptsMgr)
+
+	b0 := index.NewMockBlock(ctrl)
+	b0.EXPECT().StartTime().Return(t0).AnyTimes()
+	b0.EXPECT().EndTime().Return(t0.Add(blockSize)).AnyTimes()
+	b0.EXPECT().IncRef().AnyTimes()
+	b0.EXPECT().DecRef().AnyTimes()
+	newBlockFn := func(ts time.Time, md namespace.Metadata, io index.Options) (index.Block, error) {
+		return b0, nil
+	}
+	idx, err := newNamespaceIndexWithNewBlockFn(md, newBlockFn, opts)
+	require.NoError(t, err)
+
+	ctx := context.NewContext()
+	q := index.Query{}
+	qOpts := index.QueryOptions{
+		StartInclusive: t0,
+		EndExclusive:   now.Add(time.Minute),
+	}
+	limitedOpts := qOpts
+	limitedOpts.SeriesLimit = 1
+	b0.EXPECT().Query(q, limitedOpts, gomock.Any()).DoAndReturn(
+		func(_ index.Query, _ index.QueryOptions, results index.Results) (bool, error) {
+			_, _, err := results.Add(doc.Document{ID: []byte("foo")})
+			return false, err
+		})
+	res, err := idx.Query(ctx, q, qOpts)
+	require.NoError(t, err)
+	require.False(t, res.Exhaustive)
+	require.Equal(t, 1, res.Results.Size())
+
+	// The limits of the query are used when it sets its own.
+	qOpts.SeriesLimit = 5
+	b0.EXPECT().Query(q, qOpts, gomock.Any()).Return(true, nil)
+	res, err = idx.Query(ctx, q, qOpts)
+	require.NoError(t, err)
+	require.True(t, res.Exhaustive)
+
+	var exceeded int64
+	for _, c := range scope.Snapshot().Counters() {
+		if c.Name() == "dbindex.query-limit-exceeded" && c.Tags()["limit"] == "series" {
+			exceeded += c.Value()
+		}
+	}
+	require.Equal(t, int64(1), exceeded)
+}
+
+func TestNamespaceIndexBlockQueryPage(t *testing.T) {
+	ctrl := gomock.NewController(xtest.Reporter{t})
+	defer ctrl.Finish()
+
+	retention := 2 * time.Hour
+	blockSize := time.Hour
+	now := time.Now().Truncate(blockSize).Add(10 * time.Minute)
+	t0 := now.Truncate(blockSize)
+	nowFn := func() time.Time { return now }
+	md := testNamespaceMetadata(blockSize, retention)
+
+	opts := testDatabaseOptions()
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(nowFn))
+
+	b0 := index.NewMockBlock(ctrl)
+	b0.EXPECT().StartTime().Return(t0).AnyTimes()
+	b0.EXPECT().EndTime().Return(t0.Add(blockSize)).AnyTimes()
+	b0.EXPECT().IncRef().AnyTimes()
+	b0.EXPECT().DecRef().AnyTimes()
+	newBlockFn := func(ts time.Time, md namespace.Metadata, io index.Options) (index.Block, error) {
+		return b0, nil
+	}
+	idx, err := newNamespaceIndexWithNewBlockFn(md, newBlockFn, opts)
+	require.NoError(t, err)
+
+	var (
+		ctx    = context.NewContext()
+		q      = index.Query{Query: m3ninxidx.MustCreateTermQuery([]byte("foo"), []byte("bar"))}
+		qOpts  = index.QueryOptions{StartInclusive: t0, EndExclusive: now.Add(time.Minute), PageSize: 2}
+		ids    = []string{"d", "a", "e", "c", "b"}
+		pages  [][]string
+		blockQ = func(_ index.Query, _ index.QueryOptions, results index.Results) (bool, error) {
+			for _, id := range ids {
+				_, size, err := results.Add(doc.Document{ID: []byte(id)})
+				require.NoError(t, err)
+				require.True(t, size <= 2)
+			}
+			return true, nil
+		}
+	)
+	defer ctx.Close()
+	for {
+		// The pages following the first only query the IDs after the page token.
+		blockQuery := q
+		if qOpts.PageToken != nil {
+			afterPageToken, err := m3ninxidx.NewRangeQuery(doc.IDReservedFieldName,
+				qOpts.PageToken, nil, false, false)
+			require.NoError(t, err)
+			blockQuery = index.Query{Query: m3ninxidx.NewConjunctionQuery(q.Query, afterPageToken)}
+		}
+		b0.EXPECT().Query(index.NewQueryMatcher(blockQuery), gomock.Any(), gomock.Any()).DoAndReturn(blockQ)
+		res, err := idx.Query(ctx, q, qOpts)
+		require.NoError(t, err)
+		require.True(t, res.Exhaustive)
+
+		var page []string
+		for _, entry := range res.Results.Map().Iter() {
+			page = append(page, entry.Key().String())
+		}
+		sort.Strings(page)
+		pages = append(pages, page)
+
+		if res.NextPageToken == nil {
+			break
+		}
+		qOpts.PageToken = res.NextPageToken
+	}
+	require.Equal(t, [][]string{{"a", "b"}, {"c", "d"}, {"e"}}, pages)
+}
+
+func TestNamespaceIndexBlockQuerySkipsBlocksOutOfRange(t *testing.T) {
+	ctrl := gomock.NewController(xtest.Reporter{t})
+	defer ctrl.Finish()
+
+	retention := 3 * time.Hour
+	blockSize := time.Hour
+	now := time.Now().Truncate(blockSize).Add(10 * time.Minute)
+	t0 := now.Truncate(blockSize)
+	t0Nanos := xtime.ToUnixNano(t0)
+	t1 := t0.Add(1 * blockSize)
+	t1Nanos := xtime.ToUnixNano(t1)
+	t2 := t1.Add(1 * blockSize)
+	t2Nanos := xtime.ToUnixNano(t2)
+	t3 := t2.Add(1 * blockSize)
+	nowFn := func() time.Time { return now }
+
+	scope := tally.NewTestScope("", nil)
+	opts := testDatabaseOptions()
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(nowFn)).
+		SetInstrumentOptions(opts.InstrumentOptions().SetMetricsScope(scope))
+
+	// NB: the blocks outside of the query range have no expectations of a query
+	// so the mock controller fails the test if a search is run against them.
+	var queried int
+	blocks := make(map[xtime.UnixNano]*index.MockBlock)
+	for _, start := range []time.Time{t0, t1, t2} {
+		b := index.NewMockBlock(ctrl)
+		b.EXPECT().StartTime().Return(start).AnyTimes()
+		b.EXPECT().EndTime().Return(start.Add(blockSize)).AnyTimes()
+		b.EXPECT().IncRef().AnyTimes()
+		b.EXPECT().DecRef().AnyTimes()
+		blocks[xtime.ToUnixNano(start)] = b
+	}
+	newBlockFn := func(ts time.Time, md namespace.Metadata, io index.Options) (index.Block, error) {
+		b, ok := blocks[xtime.ToUnixNano(ts)]
+		if !ok {
+			panic("should never get here")
+		}
+		return b, nil
+	}
+	md := testNamespaceMetadata(blockSize, retention)
+	idx, err := newNamespaceIndexWithNewBlockFn(md, newBlockFn, opts)
+	require.NoError(t, err)
+
+	bootstrapResults := result.IndexResults{
+		t0Nanos: result.NewIndexBlock(t0, nil, result.NewShardTimeRanges(t0, t1, 1, 2, 3)),
+		t1Nanos: result.NewIndexBlock(t1, nil, result.NewShardTimeRanges(t1, t2, 1, 2, 3)),
+		t2Nanos: result.NewIndexBlock(t2, nil, result.NewShardTimeRanges(t2, t3, 1, 2, 3)),
+	}
+	for blockStart, b := range blocks {
+		b.EXPECT().AddResults(bootstrapResults[blockStart]).Return(nil)
+	}
+	require.NoError(t, idx.Bootstrap(bootstrapResults))
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+	q := index.Query{}
+	qOpts := index.QueryOptions{
+		StartInclusive: t2,
+		EndExclusive:   t3,
+	}
+	blocks[t2Nanos].EXPECT().Query(q, qOpts, gomock.Any()).DoAndReturn(
+		func(_ index.Query, _ index.QueryOptions, _ index.Results) (bool, error) {
+			queried++
+			return true, nil
+		})
+	res, err := idx.Query(ctx, q, qOpts)
+	require.NoError(t, err)
+	require.True(t, res.Exhaustive)
+	require.Equal(t, 1, queried)
+
+	counters := make(map[string]int64)
+	for _, c := range scope.Snapshot().Counters() {
+		if c.Name() == "dbindex.query-blocks" {
+			counters[c.Tags()["result"]] += c.Value()
+		}
+	}
+	require.Equal(t, map[string]int64{"queried": 1, "skipped": 2}, counters)
+}
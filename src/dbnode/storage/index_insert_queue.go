@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/clock"
+	m3dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
 	"github.com/m3db/m3/src/dbnode/storage/index"
 
 	"github.com/uber-go/tally"
@@ -34,7 +35,7 @@ import (
 var (
 	errIndexInsertQueueNotOpen             = errors.New("index insert queue is not open")
 	errIndexInsertQueueAlreadyOpenOrClosed = errors.New("index insert queue already open or is closed")
-	errNewSeriesIndexRateLimitExceeded     = errors.New("indexing new series exceeds rate limit")
+	errNewSeriesIndexRateLimitExceeded     = m3dberrors.NewResourceExhaustedError(errors.New("indexing new series exceeds rate limit"))
 )
 
 type nsIndexInsertQueueState int
@@ -27,6 +27,7 @@ import (
 	"testing"
 	"time"
 
+	m3dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
 	"github.com/m3db/m3/src/dbnode/storage/index"
 	"github.com/m3db/m3x/ident"
 
@@ -171,6 +172,7 @@ func TestIndexInsertQueueRateLimit(t *testing.T) {
 		testTags(112), time.Time{}, callback)))
 	assert.Error(t, err)
 	assert.Equal(t, errNewSeriesIndexRateLimitExceeded, err)
+	assert.True(t, m3dberrors.IsResourceExhausted(err))
 
 	// Start 3rd second
 	addTime(800 * time.Millisecond)
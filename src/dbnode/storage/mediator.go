@@ -133,6 +133,22 @@ func (m *mediator) EnableFileOps() {
 	m.databaseFileSystemManager.Enable()
 }
 
+func (m *mediator) ShutdownSnapshot(deadline time.Time, abortCh <-chan struct{}) error {
+	m.DisableFileOps()
+	return m.databaseFileSystemManager.ShutdownSnapshot(m.nowFn(), deadline, abortCh)
+}
+
+func (m *mediator) FlushNamespace(ns databaseNamespace) error {
+	return m.databaseFileSystemManager.FlushNamespace(ns, m.nowFn())
+}
+
+func (m *mediator) ForceFlush(
+	ns databaseNamespace,
+	opts ForceFlushOptions,
+) (ForceFlushResult, error) {
+	return m.databaseFileSystemManager.ForceFlush(ns, opts, m.nowFn())
+}
+
 // Tick mediates the relationship between ticks and flushes/snapshots/cleanups.
 //
 // For example, the requirements to perform a flush are:
@@ -186,6 +202,9 @@ func (m *mediator) Close() error {
 	}
 	m.state = mediatorClosed
 	close(m.closedCh)
+	// Cancel any tick in progress so that a long running tick does
+	// not hold up closing the database
+	m.databaseTickManager.Cancel()
 	m.databaseRepairer.Stop()
 	return nil
 }
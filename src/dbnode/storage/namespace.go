@@ -29,6 +29,7 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/clock"
+	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/persist/fs"
 	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
@@ -108,6 +109,7 @@ type dbNamespace struct {
 	metadata           namespace.Metadata
 	nopts              namespace.Options
 	seriesOpts         series.Options
+	encoderPool        encoding.EncoderPool
 	nowFn              clock.NowFn
 	snapshotFilesFn    snapshotFilesFn
 	log                xlog.Logger
@@ -144,14 +146,18 @@ type databaseNamespaceIndexStatsLastTick struct {
 type databaseNamespaceMetrics struct {
 	bootstrap           instrument.MethodMetrics
 	flush               instrument.MethodMetrics
+	forceFlush          instrument.MethodMetrics
 	flushIndex          instrument.MethodMetrics
 	snapshot            instrument.MethodMetrics
 	write               instrument.MethodMetrics
 	writeTagged         instrument.MethodMetrics
+	writeTaggedBatch    instrument.MethodMetrics
 	read                instrument.MethodMetrics
 	fetchBlocks         instrument.MethodMetrics
 	fetchBlocksMetadata instrument.MethodMetrics
 	queryIDs            instrument.MethodMetrics
+	aggregateTerms      instrument.MethodMetrics
+	aggregateTagValues  instrument.MethodMetrics
 	unfulfilled         tally.Counter
 	bootstrapStart      tally.Counter
 	bootstrapEnd        tally.Counter
@@ -174,19 +180,27 @@ type databaseNamespaceTickMetrics struct {
 	wiredBlocks            tally.Gauge
 	unwiredBlocks          tally.Gauge
 	pendingMergeBlocks     tally.Gauge
+	unflushedBytes         tally.Gauge
+	bufferEncoders         tally.Gauge
+	bufferBootstrapped     tally.Gauge
+	newSeriesBacklog       tally.Gauge
 	madeUnwiredBlocks      tally.Counter
 	madeExpiredBlocks      tally.Counter
 	mergedOutOfOrderBlocks tally.Counter
+	recompressedBlocks     tally.Counter
+	recompressedBytesSaved tally.Counter
 	errors                 tally.Counter
 	index                  databaseNamespaceIndexTickMetrics
 }
 
 type databaseNamespaceIndexTickMetrics struct {
-	numBlocks        tally.Gauge
-	numDocs          tally.Gauge
-	numSegments      tally.Gauge
-	numBlocksSealed  tally.Counter
-	numBlocksEvicted tally.Counter
+	numBlocks          tally.Gauge
+	numDocs            tally.Gauge
+	numSegments        tally.Gauge
+	numDocsEvicted     tally.Gauge
+	numSegmentsEvicted tally.Gauge
+	numBlocksSealed    tally.Counter
+	numBlocksEvicted   tally.Counter
 }
 
 // databaseNamespaceStatusMetrics are metrics emitted at a fixed interval
@@ -214,14 +228,18 @@ func newDatabaseNamespaceMetrics(scope tally.Scope, samplingRate float64) databa
 	return databaseNamespaceMetrics{
 		bootstrap:           instrument.NewMethodMetrics(scope, "bootstrap", samplingRate),
 		flush:               instrument.NewMethodMetrics(scope, "flush", samplingRate),
+		forceFlush:          instrument.NewMethodMetrics(scope, "forceFlush", samplingRate),
 		flushIndex:          instrument.NewMethodMetrics(scope, "flushIndex", samplingRate),
 		snapshot:            instrument.NewMethodMetrics(scope, "snapshot", samplingRate),
 		write:               instrument.NewMethodMetrics(scope, "write", samplingRate),
 		writeTagged:         instrument.NewMethodMetrics(scope, "write-tagged", samplingRate),
+		writeTaggedBatch:    instrument.NewMethodMetrics(scope, "write-tagged-batch", samplingRate),
 		read:                instrument.NewMethodMetrics(scope, "read", samplingRate),
 		fetchBlocks:         instrument.NewMethodMetrics(scope, "fetchBlocks", samplingRate),
 		fetchBlocksMetadata: instrument.NewMethodMetrics(scope, "fetchBlocksMetadata", samplingRate),
 		queryIDs:            instrument.NewMethodMetrics(scope, "queryIDs", samplingRate),
+		aggregateTerms:      instrument.NewMethodMetrics(scope, "aggregateTerms", samplingRate),
+		aggregateTagValues:  instrument.NewMethodMetrics(scope, "aggregateTagValues", samplingRate),
 		unfulfilled:         scope.Counter("bootstrap.unfulfilled"),
 		bootstrapStart:      scope.Counter("bootstrap.start"),
 		bootstrapEnd:        scope.Counter("bootstrap.end"),
@@ -238,16 +256,24 @@ func newDatabaseNamespaceMetrics(scope tally.Scope, samplingRate float64) databa
 			wiredBlocks:            tickScope.Gauge("wired-blocks"),
 			unwiredBlocks:          tickScope.Gauge("unwired-blocks"),
 			pendingMergeBlocks:     tickScope.Gauge("pending-merge-blocks"),
+			unflushedBytes:         tickScope.Gauge("unflushed-bytes"),
+			bufferEncoders:         tickScope.Gauge("buffer-encoders"),
+			bufferBootstrapped:     tickScope.Gauge("buffer-bootstrapped-blocks"),
+			newSeriesBacklog:       tickScope.Gauge("new-series-backlog"),
 			madeUnwiredBlocks:      tickScope.Counter("made-unwired-blocks"),
 			madeExpiredBlocks:      tickScope.Counter("made-expired-blocks"),
 			mergedOutOfOrderBlocks: tickScope.Counter("merged-out-of-order-blocks"),
+			recompressedBlocks:     tickScope.Counter("recompressed-blocks"),
+			recompressedBytesSaved: tickScope.Counter("recompressed-bytes-saved"),
 			errors:                 tickScope.Counter("errors"),
 			index: databaseNamespaceIndexTickMetrics{
-				numDocs:          indexTickScope.Gauge("num-docs"),
-				numBlocks:        indexTickScope.Gauge("num-blocks"),
-				numSegments:      indexTickScope.Gauge("num-segments"),
-				numBlocksSealed:  indexTickScope.Counter("num-blocks-sealed"),
-				numBlocksEvicted: indexTickScope.Counter("num-blocks-evicted"),
+				numDocs:            indexTickScope.Gauge("num-docs"),
+				numBlocks:          indexTickScope.Gauge("num-blocks"),
+				numSegments:        indexTickScope.Gauge("num-segments"),
+				numDocsEvicted:     indexTickScope.Gauge("num-docs-evicted"),
+				numSegmentsEvicted: indexTickScope.Gauge("num-segments-evicted"),
+				numBlocksSealed:    indexTickScope.Counter("num-blocks-sealed"),
+				numBlocksEvicted:   indexTickScope.Counter("num-blocks-evicted"),
 			},
 		},
 		status: databaseNamespaceStatusMetrics{
@@ -293,7 +319,20 @@ func newDatabaseNamespace(
 	tickWorkers.Init()
 
 	seriesOpts := NewSeriesOptionsFromOptions(opts, nopts.RetentionOptions()).
-		SetStats(series.NewStats(scope))
+		SetStats(series.NewStats(scope)).
+		SetWriteTimestampTruncateTo(nopts.WriteTimestampTruncateTo()).
+		SetAnnotationsEnabled(nopts.AnnotationsEnabled())
+
+	// NB: only report the accounting of an encoder pool owned by the
+	// namespace, a shared encoder pool is not attributable to it.
+	var encoderPool encoding.EncoderPool
+	if fn := opts.NamespaceEncoderPoolFn(); fn != nil {
+		encoderPool = fn(iops.SetMetricsScope(scope.SubScope("encoder-pool")))
+		seriesOpts = seriesOpts.
+			SetEncoderPool(encoderPool).
+			SetDatabaseBlockOptions(seriesOpts.DatabaseBlockOptions().
+				SetEncoderPool(encoderPool))
+	}
 	if err := seriesOpts.Validate(); err != nil {
 		return nil, fmt.Errorf(
 			"unable to create namespace %v, invalid series options: %v",
@@ -321,6 +360,7 @@ func newDatabaseNamespace(
 		metadata:               metadata,
 		nopts:                  nopts,
 		seriesOpts:             seriesOpts,
+		encoderPool:            encoderPool,
 		nowFn:                  opts.ClockOptions().NowFn(),
 		snapshotFilesFn:        fs.SnapshotFiles,
 		log:                    logger,
@@ -354,6 +394,9 @@ func (n *dbNamespace) reportStatusLoop() {
 			n.metrics.status.index.numBlocks.Update(float64(n.statsLastTick.index.numBlocks))
 			n.metrics.status.index.numSegments.Update(float64(n.statsLastTick.index.numSegments))
 			n.statsLastTick.RUnlock()
+			if n.encoderPool != nil {
+				n.encoderPool.Report()
+			}
 		}
 	}
 }
@@ -530,12 +573,20 @@ func (n *dbNamespace) Tick(c context.Cancellable, tickStart time.Time) error {
 	n.metrics.tick.wiredBlocks.Update(float64(r.wiredBlocks))
 	n.metrics.tick.unwiredBlocks.Update(float64(r.unwiredBlocks))
 	n.metrics.tick.pendingMergeBlocks.Update(float64(r.pendingMergeBlocks))
+	n.metrics.tick.unflushedBytes.Update(float64(r.unflushedBytes))
+	n.metrics.tick.bufferEncoders.Update(float64(r.bufferEncoders))
+	n.metrics.tick.bufferBootstrapped.Update(float64(r.bufferBootstrapped))
+	n.metrics.tick.newSeriesBacklog.Update(float64(r.newSeriesBacklog))
 	n.metrics.tick.madeExpiredBlocks.Inc(int64(r.madeExpiredBlocks))
 	n.metrics.tick.madeUnwiredBlocks.Inc(int64(r.madeUnwiredBlocks))
 	n.metrics.tick.mergedOutOfOrderBlocks.Inc(int64(r.mergedOutOfOrderBlocks))
+	n.metrics.tick.recompressedBlocks.Inc(int64(r.recompressedBlocks))
+	n.metrics.tick.recompressedBytesSaved.Inc(int64(r.recompressedBytesSaved))
 	n.metrics.tick.index.numDocs.Update(float64(indexTickResults.NumTotalDocs))
 	n.metrics.tick.index.numBlocks.Update(float64(indexTickResults.NumBlocks))
 	n.metrics.tick.index.numSegments.Update(float64(indexTickResults.NumSegments))
+	n.metrics.tick.index.numDocsEvicted.Update(float64(indexTickResults.NumDocsEvicted))
+	n.metrics.tick.index.numSegmentsEvicted.Update(float64(indexTickResults.NumSegmentsEvicted))
 	n.metrics.tick.index.numBlocksEvicted.Inc(indexTickResults.NumBlocksEvicted)
 	n.metrics.tick.index.numBlocksSealed.Inc(indexTickResults.NumBlocksSealed)
 	n.metrics.tick.errors.Inc(int64(r.errors))
@@ -586,6 +637,29 @@ func (n *dbNamespace) WriteTagged(
 	return err
 }
 
+func (n *dbNamespace) WriteTaggedBatch(
+	ctx context.Context,
+	id ident.ID,
+	tags ident.TagIterator,
+	datapoints []ts.Datapoint,
+	unit xtime.Unit,
+	annotations [][]byte,
+) ([]error, error) {
+	callStart := n.nowFn()
+	if n.reverseIndex == nil { // only happens if indexing is enabled.
+		n.metrics.writeTaggedBatch.ReportError(n.nowFn().Sub(callStart))
+		return nil, errNamespaceIndexingDisabled
+	}
+	shard, err := n.shardFor(id)
+	if err != nil {
+		n.metrics.writeTaggedBatch.ReportError(n.nowFn().Sub(callStart))
+		return nil, err
+	}
+	errs, err := shard.WriteTaggedBatch(ctx, id, tags, datapoints, unit, annotations)
+	n.metrics.writeTaggedBatch.ReportSuccessOrError(err, n.nowFn().Sub(callStart))
+	return errs, err
+}
+
 func (n *dbNamespace) QueryIDs(
 	ctx context.Context,
 	query index.Query,
@@ -601,6 +675,35 @@ func (n *dbNamespace) QueryIDs(
 	return res, err
 }
 
+func (n *dbNamespace) AggregateTerms(
+	ctx context.Context,
+	query index.Query,
+	opts index.QueryOptions,
+) (index.AggregateQueryResults, error) {
+	callStart := n.nowFn()
+	if n.reverseIndex == nil { // only happens if indexing is enabled.
+		n.metrics.aggregateTerms.ReportError(n.nowFn().Sub(callStart))
+		return index.AggregateQueryResults{}, errNamespaceIndexingDisabled
+	}
+	res, err := n.reverseIndex.AggregateTerms(ctx, query, opts)
+	n.metrics.aggregateTerms.ReportSuccessOrError(err, n.nowFn().Sub(callStart))
+	return res, err
+}
+
+func (n *dbNamespace) AggregateTagValues(
+	ctx context.Context,
+	opts index.AggregateTagValuesOptions,
+) (index.AggregateTagValuesResults, error) {
+	callStart := n.nowFn()
+	if n.reverseIndex == nil { // only happens if indexing is enabled.
+		n.metrics.aggregateTagValues.ReportError(n.nowFn().Sub(callStart))
+		return index.AggregateTagValuesResults{}, errNamespaceIndexingDisabled
+	}
+	res, err := n.reverseIndex.AggregateTagValues(ctx, opts)
+	n.metrics.aggregateTagValues.ReportSuccessOrError(err, n.nowFn().Sub(callStart))
+	return res, err
+}
+
 func (n *dbNamespace) ReadEncoded(
 	ctx context.Context,
 	id ident.ID,
@@ -617,6 +720,14 @@ func (n *dbNamespace) ReadEncoded(
 	return res, err
 }
 
+func (n *dbNamespace) BucketsInfo(id ident.ID) ([]series.BucketInfo, error) {
+	shard, err := n.readableShardFor(id)
+	if err != nil {
+		return nil, err
+	}
+	return shard.BucketsInfo(id)
+}
+
 func (n *dbNamespace) FetchBlocks(
 	ctx context.Context,
 	shardID uint32,
@@ -865,6 +976,78 @@ func (n *dbNamespace) Flush(
 	return res
 }
 
+func (n *dbNamespace) ForceFlush(
+	blockStart time.Time,
+	opts ForceFlushOptions,
+	flush persist.DataFlush,
+) (ForceFlushResult, error) {
+	callStart := n.nowFn()
+
+	n.RLock()
+	if n.bootstrapState != Bootstrapped {
+		n.RUnlock()
+		n.metrics.forceFlush.ReportError(n.nowFn().Sub(callStart))
+		return ForceFlushResult{}, errNamespaceNotBootstrapped
+	}
+	n.RUnlock()
+
+	if !n.nopts.FlushEnabled() {
+		n.metrics.forceFlush.ReportSuccess(n.nowFn().Sub(callStart))
+		return ForceFlushResult{}, nil
+	}
+
+	shards, err := n.forceFlushShards(opts.Shards)
+	if err != nil {
+		n.metrics.forceFlush.ReportError(n.nowFn().Sub(callStart))
+		return ForceFlushResult{}, err
+	}
+
+	var (
+		result   ForceFlushResult
+		multiErr = xerrors.NewMultiError()
+	)
+	for _, shard := range shards {
+		if !shard.IsBootstrapped() {
+			continue
+		}
+
+		// Skip shards that have already flushed data for the `blockStart`, this is
+		// what prevents a block from being flushed by both a tick and a force flush.
+		if s := shard.FlushState(blockStart); s.Status == fileOpSuccess {
+			continue
+		}
+		r, err := shard.ForceFlush(blockStart, opts.Force, flush)
+		result.add(r)
+		if err != nil {
+			detailedErr := fmt.Errorf("shard %d failed to force flush data: %v",
+				shard.ID(), err)
+			multiErr = multiErr.Add(detailedErr)
+		}
+	}
+
+	res := multiErr.FinalError()
+	n.metrics.forceFlush.ReportSuccessOrError(res, n.nowFn().Sub(callStart))
+	return result, res
+}
+
+func (n *dbNamespace) forceFlushShards(shardIDs []uint32) ([]databaseShard, error) {
+	if len(shardIDs) == 0 {
+		return n.GetOwnedShards(), nil
+	}
+
+	n.RLock()
+	defer n.RUnlock()
+	shards := make([]databaseShard, 0, len(shardIDs))
+	for _, shardID := range shardIDs {
+		shard, err := n.shardAtWithRLock(shardID)
+		if err != nil {
+			return nil, err
+		}
+		shards = append(shards, shard)
+	}
+	return shards, nil
+}
+
 func (n *dbNamespace) FlushIndex(
 	flush persist.IndexFlush,
 ) error {
@@ -900,7 +1083,9 @@ func (n *dbNamespace) Snapshot(blockStart, snapshotTime time.Time, flush persist
 	}
 	n.RUnlock()
 
-	if !n.nopts.SnapshotEnabled() {
+	// Namespaces that are never flushed are held in memory only and
+	// must not write any files, including snapshots
+	if !n.nopts.FlushEnabled() || !n.nopts.SnapshotEnabled() {
 		n.metrics.snapshot.ReportSuccess(n.nowFn().Sub(callStart))
 		return nil
 	}
@@ -1057,16 +1242,11 @@ func (n *dbNamespace) Repair(
 		numSizeDiffBlocks     int64
 		numChecksumDiffSeries int64
 		numChecksumDiffBlocks int64
-		throttlePerShard      time.Duration
 	)
 
 	multiErr := xerrors.NewMultiError()
 	shards := n.GetOwnedShards()
 	numShards := len(shards)
-	if numShards > 0 {
-		throttlePerShard = time.Duration(
-			int64(repairer.Options().RepairThrottle()) / int64(numShards))
-	}
 
 	workers := xsync.NewWorkerPool(repairer.Options().RepairShardConcurrency())
 	workers.Init()
@@ -1096,6 +1276,10 @@ func (n *dbNamespace) Repair(
 			}
 			mutex.Unlock()
 
+			// NB: the throttle is resolved after each shard repair so that
+			// changes to it take effect during a namespace repair.
+			throttlePerShard := time.Duration(
+				int64(repairer.Options().RepairThrottle()) / int64(numShards))
 			if throttlePerShard > 0 {
 				time.Sleep(throttlePerShard)
 			}
@@ -48,14 +48,16 @@ func (m *MapConfiguration) Map() (Map, error) {
 
 // MetadataConfiguration is the configuration for a single namespace
 type MetadataConfiguration struct {
-	ID                string                  `yaml:"id" validate:"nonzero"`
-	BootstrapEnabled  *bool                   `yaml:"bootstrapEnabled"`
-	FlushEnabled      *bool                   `yaml:"flushEnabled"`
-	WritesToCommitLog *bool                   `yaml:"writesToCommitLog"`
-	CleanupEnabled    *bool                   `yaml:"cleanupEnabled"`
-	RepairEnabled     *bool                   `yaml:"repairEnabled"`
-	Retention         retention.Configuration `yaml:"retention" validate:"nonzero"`
-	Index             IndexConfiguration      `yaml:"index"`
+	ID                       string                  `yaml:"id" validate:"nonzero"`
+	BootstrapEnabled         *bool                   `yaml:"bootstrapEnabled"`
+	FlushEnabled             *bool                   `yaml:"flushEnabled"`
+	WritesToCommitLog        *bool                   `yaml:"writesToCommitLog"`
+	CleanupEnabled           *bool                   `yaml:"cleanupEnabled"`
+	RepairEnabled            *bool                   `yaml:"repairEnabled"`
+	Retention                retention.Configuration `yaml:"retention" validate:"nonzero"`
+	Index                    IndexConfiguration      `yaml:"index"`
+	WriteTimestampTruncateTo time.Duration           `yaml:"writeTimestampTruncateTo" validate:"min=0"`
+	AnnotationsEnabled       *bool                   `yaml:"annotationsEnabled"`
 }
 
 // Metadata returns a Metadata corresponding to the receiver struct
@@ -64,7 +66,8 @@ func (mc *MetadataConfiguration) Metadata() (Metadata, error) {
 	ropts := mc.Retention.Options()
 	opts := NewOptions().
 		SetRetentionOptions(ropts).
-		SetIndexOptions(iopts)
+		SetIndexOptions(iopts).
+		SetWriteTimestampTruncateTo(mc.WriteTimestampTruncateTo)
 	if v := mc.BootstrapEnabled; v != nil {
 		opts = opts.SetBootstrapEnabled(*v)
 	}
@@ -80,6 +83,9 @@ func (mc *MetadataConfiguration) Metadata() (Metadata, error) {
 	if v := mc.RepairEnabled; v != nil {
 		opts = opts.SetRepairEnabled(*v)
 	}
+	if v := mc.AnnotationsEnabled; v != nil {
+		opts = opts.SetAnnotationsEnabled(*v)
+	}
 	return NewMetadata(ident.StringID(mc.ID), opts)
 }
 
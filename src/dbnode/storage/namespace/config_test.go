@@ -85,13 +85,14 @@ func TestRegistryConfig(t *testing.T) {
 
 func TestMetadataConfig(t *testing.T) {
 	var (
-		id                = "someLongString"
-		bootstrapEnabled  = true
-		flushEnabled      = false
-		writesToCommitLog = true
-		cleanupEnabled    = false
-		repairEnabled     = false
-		retention         = retention.Configuration{
+		id                 = "someLongString"
+		bootstrapEnabled   = true
+		flushEnabled       = false
+		writesToCommitLog  = true
+		cleanupEnabled     = false
+		repairEnabled      = false
+		annotationsEnabled = false
+		retention          = retention.Configuration{
 			BlockSize:       time.Hour,
 			RetentionPeriod: time.Hour,
 			BufferFuture:    time.Minute,
@@ -102,14 +103,15 @@ func TestMetadataConfig(t *testing.T) {
 			BlockSize: time.Hour,
 		}
 		config = &MetadataConfiguration{
-			ID:                id,
-			BootstrapEnabled:  &bootstrapEnabled,
-			FlushEnabled:      &flushEnabled,
-			WritesToCommitLog: &writesToCommitLog,
-			CleanupEnabled:    &cleanupEnabled,
-			RepairEnabled:     &repairEnabled,
-			Retention:         retention,
-			Index:             index,
+			ID:                 id,
+			BootstrapEnabled:   &bootstrapEnabled,
+			FlushEnabled:       &flushEnabled,
+			WritesToCommitLog:  &writesToCommitLog,
+			CleanupEnabled:     &cleanupEnabled,
+			RepairEnabled:      &repairEnabled,
+			Retention:          retention,
+			Index:              index,
+			AnnotationsEnabled: &annotationsEnabled,
 		}
 	)
 
@@ -123,6 +125,7 @@ func TestMetadataConfig(t *testing.T) {
 	require.Equal(t, writesToCommitLog, opts.WritesToCommitLog())
 	require.Equal(t, cleanupEnabled, opts.CleanupEnabled())
 	require.Equal(t, repairEnabled, opts.RepairEnabled())
+	require.Equal(t, annotationsEnabled, opts.AnnotationsEnabled())
 	require.Equal(t, retention.Options(), opts.RetentionOptions())
 	require.Equal(t, index.Options(), opts.IndexOptions())
 }
@@ -104,6 +104,7 @@ func ToMetadata(
 		SetRepairEnabled(opts.RepairEnabled).
 		SetWritesToCommitLog(opts.WritesToCommitLog).
 		SetSnapshotEnabled(opts.SnapshotEnabled).
+		SetAnnotationsEnabled(!opts.AnnotationsDisabled).
 		SetRetentionOptions(ropts).
 		SetIndexOptions(iopts)
 
@@ -142,12 +143,13 @@ func OptionsToProto(opts Options) *nsproto.NamespaceOptions {
 	iopts := opts.IndexOptions()
 
 	return &nsproto.NamespaceOptions{
-		BootstrapEnabled:  opts.BootstrapEnabled(),
-		FlushEnabled:      opts.FlushEnabled(),
-		CleanupEnabled:    opts.CleanupEnabled(),
-		SnapshotEnabled:   opts.SnapshotEnabled(),
-		RepairEnabled:     opts.RepairEnabled(),
-		WritesToCommitLog: opts.WritesToCommitLog(),
+		BootstrapEnabled:    opts.BootstrapEnabled(),
+		FlushEnabled:        opts.FlushEnabled(),
+		CleanupEnabled:      opts.CleanupEnabled(),
+		SnapshotEnabled:     opts.SnapshotEnabled(),
+		RepairEnabled:       opts.RepairEnabled(),
+		WritesToCommitLog:   opts.WritesToCommitLog(),
+		AnnotationsDisabled: !opts.AnnotationsEnabled(),
 		RetentionOptions: &nsproto.RetentionOptions{
 			BlockSizeNanos:                           ropts.BlockSize().Nanoseconds(),
 			RetentionPeriodNanos:                     ropts.RetentionPeriod().Nanoseconds(),
@@ -70,6 +70,14 @@ var (
 			RetentionOptions:  &validRetentionOpts,
 			IndexOptions:      &validIndexOpts,
 		},
+		nsproto.NamespaceOptions{
+			BootstrapEnabled:    true,
+			FlushEnabled:        true,
+			WritesToCommitLog:   true,
+			CleanupEnabled:      true,
+			RetentionOptions:    &validRetentionOpts,
+			AnnotationsDisabled: true,
+		},
 	}
 
 	invalidRetentionOpts = []nsproto.RetentionOptions{
@@ -226,6 +234,7 @@ func assertEqualMetadata(t *testing.T, name string, expected nsproto.NamespaceOp
 	require.Equal(t, expected.WritesToCommitLog, opts.WritesToCommitLog())
 	require.Equal(t, expected.CleanupEnabled, opts.CleanupEnabled())
 	require.Equal(t, expected.RepairEnabled, opts.RepairEnabled())
+	require.Equal(t, !expected.AnnotationsDisabled, opts.AnnotationsEnabled())
 
 	assertEqualRetentions(t, *expected.RetentionOptions, opts.RetentionOptions())
 }
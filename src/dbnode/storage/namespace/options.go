@@ -22,6 +22,7 @@ package namespace
 
 import (
 	"errors"
+	"time"
 
 	"github.com/m3db/m3/src/dbnode/retention"
 )
@@ -44,36 +45,48 @@ const (
 
 	// Namespace requires repair disabled by default
 	defaultRepairEnabled = false
+
+	// Namespace write timestamps are not truncated by default
+	defaultWriteTimestampTruncateTo = time.Duration(0)
+
+	// Namespace writes may carry annotations by default
+	defaultAnnotationsEnabled = true
 )
 
 var (
 	errIndexBlockSizePositive                       = errors.New("index block size must positive")
 	errIndexBlockSizeTooLarge                       = errors.New("index block size needs to be <= namespace retention period")
 	errIndexBlockSizeMustBeAMultipleOfDataBlockSize = errors.New("index block size must be a multiple of data block size")
+	errWriteTimestampTruncateToNegative             = errors.New("write timestamp truncate to cannot be negative")
+	errWriteTimestampTruncateToTooLarge             = errors.New("write timestamp truncate to must be <= data block size")
 )
 
 type options struct {
-	bootstrapEnabled  bool
-	flushEnabled      bool
-	snapshotEnabled   bool
-	writesToCommitLog bool
-	cleanupEnabled    bool
-	repairEnabled     bool
-	retentionOpts     retention.Options
-	indexOpts         IndexOptions
+	bootstrapEnabled   bool
+	flushEnabled       bool
+	snapshotEnabled    bool
+	writesToCommitLog  bool
+	cleanupEnabled     bool
+	repairEnabled      bool
+	retentionOpts      retention.Options
+	indexOpts          IndexOptions
+	truncateTo         time.Duration
+	annotationsEnabled bool
 }
 
 // NewOptions creates a new namespace options
 func NewOptions() Options {
 	return &options{
-		bootstrapEnabled:  defaultBootstrapEnabled,
-		flushEnabled:      defaultFlushEnabled,
-		snapshotEnabled:   defaultSnapshotEnabled,
-		writesToCommitLog: defaultWritesToCommitLog,
-		cleanupEnabled:    defaultCleanupEnabled,
-		repairEnabled:     defaultRepairEnabled,
-		retentionOpts:     retention.NewOptions(),
-		indexOpts:         NewIndexOptions(),
+		bootstrapEnabled:   defaultBootstrapEnabled,
+		flushEnabled:       defaultFlushEnabled,
+		snapshotEnabled:    defaultSnapshotEnabled,
+		writesToCommitLog:  defaultWritesToCommitLog,
+		cleanupEnabled:     defaultCleanupEnabled,
+		repairEnabled:      defaultRepairEnabled,
+		retentionOpts:      retention.NewOptions(),
+		indexOpts:          NewIndexOptions(),
+		truncateTo:         defaultWriteTimestampTruncateTo,
+		annotationsEnabled: defaultAnnotationsEnabled,
 	}
 }
 
@@ -81,6 +94,12 @@ func (o *options) Validate() error {
 	if err := o.retentionOpts.Validate(); err != nil {
 		return err
 	}
+	if o.truncateTo < 0 {
+		return errWriteTimestampTruncateToNegative
+	}
+	if o.truncateTo > 0 && o.truncateTo > o.retentionOpts.BlockSize() {
+		return errWriteTimestampTruncateToTooLarge
+	}
 	if !o.indexOpts.Enabled() {
 		return nil
 	}
@@ -108,6 +127,8 @@ func (o *options) Equal(value Options) bool {
 		o.snapshotEnabled == value.SnapshotEnabled() &&
 		o.cleanupEnabled == value.CleanupEnabled() &&
 		o.repairEnabled == value.RepairEnabled() &&
+		o.truncateTo == value.WriteTimestampTruncateTo() &&
+		o.annotationsEnabled == value.AnnotationsEnabled() &&
 		o.retentionOpts.Equal(value.RetentionOptions()) &&
 		o.indexOpts.Equal(value.IndexOptions())
 }
@@ -191,3 +212,23 @@ func (o *options) SetIndexOptions(value IndexOptions) Options {
 func (o *options) IndexOptions() IndexOptions {
 	return o.indexOpts
 }
+
+func (o *options) SetWriteTimestampTruncateTo(value time.Duration) Options {
+	opts := *o
+	opts.truncateTo = value
+	return &opts
+}
+
+func (o *options) WriteTimestampTruncateTo() time.Duration {
+	return o.truncateTo
+}
+
+func (o *options) SetAnnotationsEnabled(value bool) Options {
+	opts := *o
+	opts.annotationsEnabled = value
+	return &opts
+}
+
+func (o *options) AnnotationsEnabled() bool {
+	return o.annotationsEnabled
+}
@@ -164,3 +164,27 @@ func TestOptionsValidateNoIndexing(t *testing.T) {
 	rOpts.EXPECT().Validate().Return(nil)
 	require.NoError(t, o1.Validate())
 }
+
+func TestOptionsValidateWriteTimestampTruncateTo(t *testing.T) {
+	ropts := retention.NewOptions().SetBlockSize(time.Hour)
+	o1 := NewOptions().
+		SetRetentionOptions(ropts).
+		SetIndexOptions(NewIndexOptions().SetEnabled(false))
+
+	require.NoError(t, o1.SetWriteTimestampTruncateTo(time.Second).Validate())
+	require.NoError(t, o1.SetWriteTimestampTruncateTo(time.Hour).Validate())
+	require.Equal(t, errWriteTimestampTruncateToNegative,
+		o1.SetWriteTimestampTruncateTo(-time.Second).Validate())
+	require.Equal(t, errWriteTimestampTruncateToTooLarge,
+		o1.SetWriteTimestampTruncateTo(2*time.Hour).Validate())
+	require.False(t, o1.Equal(o1.SetWriteTimestampTruncateTo(time.Second)))
+}
+
+func TestOptionsAnnotationsEnabled(t *testing.T) {
+	o1 := NewOptions()
+	require.True(t, o1.AnnotationsEnabled())
+
+	o2 := o1.SetAnnotationsEnabled(false)
+	require.False(t, o2.AnnotationsEnabled())
+	require.False(t, o1.Equal(o2))
+}
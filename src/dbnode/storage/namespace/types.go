@@ -84,6 +84,22 @@ type Options interface {
 
 	// IndexOptions returns the IndexOptions.
 	IndexOptions() IndexOptions
+
+	// SetWriteTimestampTruncateTo sets the precision that timestamps of
+	// writes to this namespace are truncated to, zero disables truncation.
+	SetWriteTimestampTruncateTo(value time.Duration) Options
+
+	// WriteTimestampTruncateTo returns the precision that timestamps of
+	// writes to this namespace are truncated to, zero disables truncation.
+	WriteTimestampTruncateTo() time.Duration
+
+	// SetAnnotationsEnabled sets whether writes to this namespace may carry
+	// annotations, when disabled writes with annotations are rejected.
+	SetAnnotationsEnabled(value bool) Options
+
+	// AnnotationsEnabled returns whether writes to this namespace may carry
+	// annotations, when disabled writes with annotations are rejected.
+	AnnotationsEnabled() bool
 }
 
 // IndexOptions controls the indexing options for a namespace.
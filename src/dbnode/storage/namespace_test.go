@@ -27,6 +27,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/dbnode/persist/fs"
 	"github.com/m3db/m3/src/dbnode/retention"
 	"github.com/m3db/m3/src/dbnode/runtime"
@@ -42,6 +43,7 @@ import (
 	"github.com/m3db/m3x/context"
 	xerrors "github.com/m3db/m3x/errors"
 	"github.com/m3db/m3x/ident"
+	"github.com/m3db/m3x/instrument"
 	xtest "github.com/m3db/m3x/test"
 	xtime "github.com/m3db/m3x/time"
 
@@ -93,6 +95,35 @@ func TestNamespaceName(t *testing.T) {
 	require.True(t, defaultTestNs1ID.Equal(ns.ID()))
 }
 
+func TestNamespaceOwnsEncoderPoolFromFn(t *testing.T) {
+	var (
+		encoderPool = encoding.NewEncoderPool(nil)
+		poolIOpts   instrument.Options
+	)
+	metadata, err := namespace.NewMetadata(defaultTestNs1ID, defaultTestNs1Opts)
+	require.NoError(t, err)
+	shardSet, err := sharding.NewShardSet(testShardIDs, func(ident.ID) uint32 {
+		return testShardIDs[0].ID()
+	})
+	require.NoError(t, err)
+	dopts := testDatabaseOptions().
+		SetRuntimeOptionsManager(runtime.NewOptionsManager()).
+		SetNamespaceEncoderPoolFn(func(iopts instrument.Options) encoding.EncoderPool {
+			poolIOpts = iopts
+			return encoderPool
+		})
+	defer dopts.RuntimeOptionsManager().Close()
+
+	dbNs, err := newDatabaseNamespace(metadata, shardSet, nil, nil, nil, dopts)
+	require.NoError(t, err)
+	ns := dbNs.(*dbNamespace)
+
+	require.NotNil(t, poolIOpts)
+	assert.True(t, encoderPool == ns.encoderPool)
+	assert.True(t, encoderPool == ns.seriesOpts.EncoderPool())
+	assert.True(t, encoderPool == ns.seriesOpts.DatabaseBlockOptions().EncoderPool())
+}
+
 func TestNamespaceTick(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -458,6 +489,50 @@ func TestNamespaceFlushSkipFlushed(t *testing.T) {
 	require.NoError(t, ns.Flush(blockStart, ShardBootstrapStates, nil))
 }
 
+func TestNamespaceForceFlushSkipFlushed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ns, closer := newTestNamespace(t)
+	defer closer()
+
+	ns.bootstrapState = Bootstrapped
+	blockStart := time.Now().Truncate(ns.Options().RetentionOptions().BlockSize())
+
+	states := []fileOpState{
+		{Status: fileOpNotStarted},
+		{Status: fileOpSuccess},
+	}
+	for i, s := range states {
+		shard := NewMockdatabaseShard(ctrl)
+		shard.EXPECT().ID().Return(testShardIDs[i].ID()).AnyTimes()
+		shard.EXPECT().IsBootstrapped().Return(true).AnyTimes()
+		shard.EXPECT().FlushState(blockStart).Return(s).AnyTimes()
+		if s.Status != fileOpSuccess {
+			shard.EXPECT().ForceFlush(blockStart, true, nil).
+				Return(ForceFlushResult{NumSeriesFlushed: 2, NumBytesWritten: 64}, nil)
+		}
+		ns.shards[testShardIDs[i].ID()] = shard
+	}
+
+	// The second shard has already been flushed by a tick so is skipped.
+	result, err := ns.ForceFlush(blockStart, ForceFlushOptions{Force: true}, nil)
+	require.NoError(t, err)
+	require.Equal(t, ForceFlushResult{NumSeriesFlushed: 2, NumBytesWritten: 64}, result)
+
+	// Restricting to the flushed shard flushes nothing.
+	result, err = ns.ForceFlush(blockStart, ForceFlushOptions{
+		Shards: []uint32{testShardIDs[1].ID()},
+		Force:  true,
+	}, nil)
+	require.NoError(t, err)
+	require.Equal(t, ForceFlushResult{}, result)
+
+	// Requesting a shard that is not owned fails.
+	_, err = ns.ForceFlush(blockStart, ForceFlushOptions{Shards: []uint32{42}}, nil)
+	require.Error(t, err)
+}
+
 func TestNamespaceFlushSkipShardNotBootstrappedBeforeTick(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -505,6 +580,19 @@ func TestNamespaceSnapshotNotBootstrapped(t *testing.T) {
 	require.Equal(t, errNamespaceNotBootstrapped, ns.Snapshot(blockStart, blockStart, nil))
 }
 
+func TestNamespaceSnapshotFlushDisabled(t *testing.T) {
+	ns, close := newTestNamespaceWithIDOpts(t, defaultTestNs1ID,
+		namespace.NewOptions().SetFlushEnabled(false).SetSnapshotEnabled(true))
+	defer close()
+
+	ns.bootstrapState = Bootstrapped
+
+	// Namespaces that are never flushed never touch the shards to snapshot
+	blockSize := ns.Options().RetentionOptions().BlockSize()
+	blockStart := time.Now().Truncate(blockSize)
+	require.NoError(t, ns.Snapshot(blockStart, blockStart, nil))
+}
+
 func TestNamespaceSnapshotNotEnoughTimeSinceLastSnapshot(t *testing.T) {
 	shardMethodResults := []snapshotTestCase{
 		snapshotTestCase{
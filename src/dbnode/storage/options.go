@@ -120,6 +120,7 @@ type options struct {
 	errThresholdForLoad            int64
 	indexingEnabled                bool
 	repairEnabled                  bool
+	namespaceRemovalFlushEnabled   bool
 	indexOpts                      index.Options
 	repairOpts                     repair.Options
 	newEncoderFn                   encoding.NewEncoderFn
@@ -142,6 +143,7 @@ type options struct {
 	fetchBlockMetadataResultsPool  block.FetchBlockMetadataResultsPool
 	fetchBlocksMetadataResultsPool block.FetchBlocksMetadataResultsPool
 	queryIDsWorkerPool             xsync.WorkerPool
+	namespaceEncoderPoolFn         NewNamespaceEncoderPoolFn
 }
 
 // NewOptions creates a new set of storage options with defaults
@@ -360,6 +362,16 @@ func (o *options) RepairEnabled() bool {
 	return o.repairEnabled
 }
 
+func (o *options) SetNamespaceRemovalFlushEnabled(value bool) Options {
+	opts := *o
+	opts.namespaceRemovalFlushEnabled = value
+	return &opts
+}
+
+func (o *options) NamespaceRemovalFlushEnabled() bool {
+	return o.namespaceRemovalFlushEnabled
+}
+
 func (o *options) SetRepairOptions(value repair.Options) Options {
 	opts := *o
 	opts.repairOpts = value
@@ -398,7 +410,7 @@ func (o *options) SetEncodingM3TSZPooled() Options {
 	opts.segmentReaderPool = segmentReaderPool
 
 	encodingOpts := encoding.NewOptions().
-		SetBytesPool(bytesPool).
+		SetBytesPool(encoderPool.AccountedBytesPool(bytesPool)).
 		SetEncoderPool(encoderPool).
 		SetReaderIteratorPool(readerIteratorPool).
 		SetSegmentReaderPool(segmentReaderPool)
@@ -622,3 +634,13 @@ func (o *options) SetQueryIDsWorkerPool(value xsync.WorkerPool) Options {
 func (o *options) QueryIDsWorkerPool() xsync.WorkerPool {
 	return o.queryIDsWorkerPool
 }
+
+func (o *options) SetNamespaceEncoderPoolFn(value NewNamespaceEncoderPoolFn) Options {
+	opts := *o
+	opts.namespaceEncoderPoolFn = value
+	return &opts
+}
+
+func (o *options) NamespaceEncoderPoolFn() NewNamespaceEncoderPoolFn {
+	return o.namespaceEncoderPoolFn
+}
@@ -32,6 +32,8 @@ import (
 
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/clock"
+	"github.com/m3db/m3/src/dbnode/ratelimit"
+	m3dbruntime "github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
 	"github.com/m3db/m3/src/dbnode/storage/repair"
@@ -52,13 +54,18 @@ var (
 type recordFn func(namespace ident.ID, shard databaseShard, diffRes repair.MetadataComparisonResult)
 
 type shardRepairer struct {
-	opts     Options
-	rpopts   repair.Options
-	client   client.AdminClient
-	recordFn recordFn
-	logger   xlog.Logger
-	scope    tally.Scope
-	nowFn    clock.NowFn
+	opts           Options
+	rpopts         repair.Options
+	runtimeOptsMgr m3dbruntime.OptionsManager
+	client         client.AdminClient
+	recordFn       recordFn
+	logger         xlog.Logger
+	scope          tally.Scope
+	nowFn          clock.NowFn
+
+	// bufferChecksumsLimiter paces checksumming the local blocks still
+	// in the buffer since these need to be merged to be checksummed.
+	bufferChecksumsLimiter ratelimit.Limiter
 }
 
 func newShardRepairer(opts Options, rpopts repair.Options) databaseShardRepairer {
@@ -66,20 +73,25 @@ func newShardRepairer(opts Options, rpopts repair.Options) databaseShardRepairer
 	scope := iopts.MetricsScope().SubScope("repair")
 
 	r := shardRepairer{
-		opts:   opts,
-		rpopts: rpopts,
-		client: rpopts.AdminClient(),
-		logger: iopts.Logger(),
-		scope:  scope,
-		nowFn:  opts.ClockOptions().NowFn(),
+		opts:           opts,
+		rpopts:         rpopts,
+		runtimeOptsMgr: opts.RuntimeOptionsManager(),
+		client:         rpopts.AdminClient(),
+		logger:         iopts.Logger(),
+		scope:          scope,
+		nowFn:          opts.ClockOptions().NowFn(),
+		bufferChecksumsLimiter: ratelimit.NewLimiter(
+			rpopts.RepairBufferChecksumsPerSecond()),
 	}
 	r.recordFn = r.recordDifferences
 
 	return r
 }
 
+// Options returns the repair options with the repair throttle taken from the
+// current runtime options, so the throttle can be changed without a restart.
 func (r shardRepairer) Options() repair.Options {
-	return r.rpopts
+	return r.rpopts.SetRepairThrottle(r.runtimeOptsMgr.Get().RepairThrottle())
 }
 
 func (r shardRepairer) Repair(
@@ -103,11 +115,17 @@ func (r shardRepairer) Repair(
 	metadata := repair.NewReplicaMetadataComparer(replicas, r.rpopts)
 	ctx.RegisterFinalizer(metadata)
 
-	// Add local metadata
+	// Add local metadata, blocks still in the buffer are checksummed unless
+	// disabled so they can be compared with replicas that have flushed them
+	bufferChecksums := r.rpopts.RepairBufferChecksums()
 	opts := block.FetchBlocksMetadataOptions{
 		IncludeSizes:     true,
 		IncludeChecksums: true,
 	}
+	if bufferChecksums {
+		opts.IncludeBufferChecksums = true
+		opts.BufferChecksumsLimiter = r.bufferChecksumsLimiter
+	}
 	localMetadata, _, err := shard.FetchBlocksMetadata(ctx, start, end, math.MaxInt64, 0, opts)
 	if err != nil {
 		return repair.MetadataComparisonResult{}, err
@@ -123,7 +141,7 @@ func (r shardRepairer) Repair(
 	// Add peer metadata
 	level := r.rpopts.RepairConsistencyLevel()
 	peerIter, err := session.FetchBlocksMetadataFromPeers(namespace, shard.ID(), start, end,
-		level, result.NewOptions(), client.FetchBlocksMetadataEndpointV2)
+		level, result.NewOptions(), client.FetchBlocksMetadataEndpointV2, bufferChecksums)
 	if err != nil {
 		return repair.MetadataComparisonResult{}, err
 	}
@@ -231,6 +249,7 @@ func (r repairStatesByNs) setRepairState(
 type dbRepairer struct {
 	database         database
 	ropts            repair.Options
+	runtimeOptsMgr   m3dbruntime.OptionsManager
 	shardRepairer    databaseShardRepairer
 	repairStatesByNs repairStatesByNs
 
@@ -272,6 +291,7 @@ func newDatabaseRepairer(database database, opts Options) (databaseRepairer, err
 	r := &dbRepairer{
 		database:            database,
 		ropts:               ropts,
+		runtimeOptsMgr:      opts.RuntimeOptionsManager(),
 		shardRepairer:       shardRepairer,
 		repairStatesByNs:    newRepairStates(),
 		sleepFn:             time.Sleep,
@@ -317,6 +337,12 @@ func (r *dbRepairer) run() {
 			continue
 		}
 
+		// If repairs are disabled at runtime, skip without marking the interval
+		// as repaired so that the repair runs once repairs are enabled again
+		if !r.repairEnabled() {
+			continue
+		}
+
 		curIntervalStart = intervalStart
 		if err := r.repairFn(); err != nil {
 			r.logger.Errorf("error repairing database: %v", err)
@@ -353,6 +379,10 @@ func (r *dbRepairer) needsRepair(ns ident.ID, t time.Time) bool {
 		(repairState.Status == repairFailed && repairState.NumFailures < r.repairMaxRetries)
 }
 
+func (r *dbRepairer) repairEnabled() bool {
+	return r.runtimeOptsMgr.Get().RepairEnabled()
+}
+
 func (r *dbRepairer) Start() {
 	if r.repairInterval <= 0 {
 		return
@@ -373,6 +403,11 @@ func (r *dbRepairer) Repair() error {
 		return nil
 	}
 
+	// Don't attempt a repair if repairs are disabled at runtime
+	if !r.repairEnabled() {
+		return nil
+	}
+
 	if !atomic.CompareAndSwapInt32(&r.running, 0, 1) {
 		return errRepairInProgress
 	}
@@ -389,6 +424,13 @@ func (r *dbRepairer) Repair() error {
 	for _, n := range namespaces {
 		iter := r.namespaceRepairTimeRanges(n).Iter()
 		for iter.Next() {
+			// Stop the repair early if repairs were disabled at runtime
+			// while it was in progress, remaining ranges are repaired once
+			// repairs are enabled again since their state is left untouched.
+			if !r.repairEnabled() {
+				r.logger.Infof("repairs disabled at runtime, stopping repair")
+				return multiErr.FinalError()
+			}
 			multiErr = multiErr.Add(r.repairNamespaceWithTimeRange(n, iter.Value()))
 		}
 	}
@@ -182,6 +182,7 @@ func (m replicaMetadataComparer) AddLocalMetadata(origin topology.Host, localIte
 			Host:     origin,
 			Size:     block.Size,
 			Checksum: block.Checksum,
+			Buffered: block.Buffered,
 		})
 	}
 
@@ -196,6 +197,7 @@ func (m replicaMetadataComparer) AddPeerMetadata(peerIter client.PeerBlockMetada
 			Host:     peer,
 			Size:     peerBlock.Size,
 			Checksum: peerBlock.Checksum,
+			Buffered: peerBlock.Buffered,
 		})
 	}
 
@@ -222,9 +224,14 @@ func (m replicaMetadataComparer) Compare() MetadataComparisonResult {
 				checksumVal          uint32
 				sameChecksum         = true
 				firstChecksum        = true
+				numHostsBuffered     int
 			)
 
 			for _, hm := range bm {
+				if hm.Buffered {
+					numHostsBuffered++
+				}
+
 				// Check size
 				if hm.Size != 0 {
 					numHostsWithSize++
@@ -248,15 +255,26 @@ func (m replicaMetadataComparer) Compare() MetadataComparisonResult {
 				}
 			}
 
+			sizesMatch := numHostsWithSize == m.replicas && sameSize
+			checksumsMatch := numHostsWithChecksum == m.replicas && sameChecksum
+
+			// NB: The size of a block still in the buffer is the size of its
+			// unmerged encoders which differs from the size of the same data
+			// once flushed, so if any host only has the block in its buffer
+			// then matching checksums are enough for the block to be consistent.
+			if numHostsBuffered > 0 && checksumsMatch {
+				sizesMatch = true
+			}
+
 			// If only a subset of hosts in the replica set have sizes, or the sizes differ,
 			// we record this block
-			if !(numHostsWithSize == m.replicas && sameSize) {
+			if !sizesMatch {
 				sizeDiff.GetOrAdd(series.ID).Add(b)
 			}
 
 			// If only a subset of hosts in the replica set have checksums, or the checksums
 			// differ, we record this block
-			if !(numHostsWithChecksum == m.replicas && sameChecksum) {
+			if !checksumsMatch {
 				checkSumDiff.GetOrAdd(series.ID).Add(b)
 			}
 		}
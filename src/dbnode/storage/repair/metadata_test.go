@@ -47,8 +47,8 @@ func TestReplicaBlockMetadataAdd(t *testing.T) {
 	now := time.Now()
 	m := NewReplicaBlockMetadata(now, newHostBlockMetadataSlice())
 	inputs := []HostBlockMetadata{
-		{topology.NewHost("foo", "addrFoo"), 1, nil},
-		{topology.NewHost("bar", "addrBar"), 2, new(uint32)},
+		{topology.NewHost("foo", "addrFoo"), 1, nil, false},
+		{topology.NewHost("bar", "addrBar"), 2, new(uint32), false},
 	}
 	for _, input := range inputs {
 		m.Add(input)
@@ -151,9 +151,9 @@ func TestReplicaMetadataComparerAddLocalMetadata(t *testing.T) {
 	require.NoError(t, err)
 
 	expected := []testBlock{
-		{inputBlocks[0].ID, inputBlocks[0].Start, []HostBlockMetadata{{origin, inputBlocks[0].Size, inputBlocks[0].Checksum}}},
-		{inputBlocks[1].ID, inputBlocks[1].Start, []HostBlockMetadata{{origin, inputBlocks[1].Size, inputBlocks[1].Checksum}}},
-		{inputBlocks[2].ID, inputBlocks[2].Start, []HostBlockMetadata{{origin, inputBlocks[2].Size, inputBlocks[2].Checksum}}},
+		{inputBlocks[0].ID, inputBlocks[0].Start, []HostBlockMetadata{{origin, inputBlocks[0].Size, inputBlocks[0].Checksum, false}}},
+		{inputBlocks[1].ID, inputBlocks[1].Start, []HostBlockMetadata{{origin, inputBlocks[1].Size, inputBlocks[1].Checksum, false}}},
+		{inputBlocks[2].ID, inputBlocks[2].Start, []HostBlockMetadata{{origin, inputBlocks[2].Size, inputBlocks[2].Checksum, false}}},
 	}
 	assertEqual(t, expected, m.metadata)
 }
@@ -209,14 +209,14 @@ func TestReplicaMetadataComparerAddPeerMetadata(t *testing.T) {
 
 	expected := []testBlock{
 		{ident.StringID("foo"), inputBlocks[0].meta.Start, []HostBlockMetadata{
-			{inputBlocks[0].host, inputBlocks[0].meta.Size, inputBlocks[0].meta.Checksum},
-			{inputBlocks[2].host, inputBlocks[2].meta.Size, inputBlocks[2].meta.Checksum},
+			{inputBlocks[0].host, inputBlocks[0].meta.Size, inputBlocks[0].meta.Checksum, false},
+			{inputBlocks[2].host, inputBlocks[2].meta.Size, inputBlocks[2].meta.Checksum, false},
 		}},
 		{ident.StringID("foo"), inputBlocks[1].meta.Start, []HostBlockMetadata{
-			{inputBlocks[1].host, inputBlocks[1].meta.Size, inputBlocks[1].meta.Checksum},
+			{inputBlocks[1].host, inputBlocks[1].meta.Size, inputBlocks[1].meta.Checksum, false},
 		}},
 		{ident.StringID("bar"), inputBlocks[3].meta.Start, []HostBlockMetadata{
-			{inputBlocks[3].host, inputBlocks[3].meta.Size, inputBlocks[3].meta.Checksum},
+			{inputBlocks[3].host, inputBlocks[3].meta.Size, inputBlocks[3].meta.Checksum, false},
 		}},
 	}
 	assertEqual(t, expected, m.metadata)
@@ -262,21 +262,21 @@ func TestReplicaMetadataComparerCompare(t *testing.T) {
 
 	sizeExpected := []testBlock{
 		{ident.StringID("bar"), now.Add(time.Second), []HostBlockMetadata{
-			{hosts[0], int64(0), &inputs[2].checksum},
-			{hosts[1], int64(1), &inputs[3].checksum},
+			{hosts[0], int64(0), &inputs[2].checksum, false},
+			{hosts[1], int64(1), &inputs[3].checksum, false},
 		}},
 		{ident.StringID("gah"), now.Add(3 * time.Second), []HostBlockMetadata{
-			{hosts[0], int64(1), &inputs[6].checksum},
+			{hosts[0], int64(1), &inputs[6].checksum, false},
 		}},
 	}
 
 	checksumExpected := []testBlock{
 		{ident.StringID("baz"), now.Add(2 * time.Second), []HostBlockMetadata{
-			{hosts[0], int64(2), &inputs[4].checksum},
-			{hosts[1], int64(2), nil},
+			{hosts[0], int64(2), &inputs[4].checksum, false},
+			{hosts[1], int64(2), nil, false},
 		}},
 		{ident.StringID("gah"), now.Add(3 * time.Second), []HostBlockMetadata{
-			{hosts[0], int64(1), &inputs[6].checksum},
+			{hosts[0], int64(1), &inputs[6].checksum, false},
 		}},
 	}
 
@@ -289,3 +289,62 @@ func TestReplicaMetadataComparerCompare(t *testing.T) {
 	assertEqual(t, sizeExpected, res.SizeDifferences)
 	assertEqual(t, checksumExpected, res.ChecksumDifferences)
 }
+
+func TestReplicaMetadataComparerCompareBufferedAndFlushed(t *testing.T) {
+	var (
+		now   = time.Now()
+		hosts = []topology.Host{
+			topology.NewHost("foo", "foo"),
+			topology.NewHost("bar", "bar"),
+			topology.NewHost("baz", "baz"),
+		}
+	)
+
+	metadata := NewReplicaSeriesMetadata()
+	defer metadata.Close()
+
+	// Two replicas still have the blocks in their buffer while the third
+	// has already flushed them, so the sizes of the blocks differ
+	inputs := []struct {
+		host     topology.Host
+		id       string
+		ts       time.Time
+		size     int64
+		checksum uint32
+		buffered bool
+	}{
+		{hosts[0], "foo", now, int64(12), uint32(10), true},
+		{hosts[1], "foo", now, int64(12), uint32(10), true},
+		{hosts[2], "foo", now, int64(8), uint32(10), false},
+		{hosts[0], "bar", now, int64(12), uint32(20), true},
+		{hosts[1], "bar", now, int64(12), uint32(20), true},
+		{hosts[2], "bar", now, int64(8), uint32(30), false},
+	}
+	for _, input := range inputs {
+		checksum := input.checksum
+		metadata.GetOrAdd(ident.StringID(input.id)).GetOrAdd(input.ts, testHostBlockMetadataSlicePool()).Add(HostBlockMetadata{
+			Host:     input.host,
+			Size:     input.size,
+			Checksum: &checksum,
+			Buffered: input.buffered,
+		})
+	}
+
+	// Only the block with mismatched checksums is inconsistent
+	expected := []testBlock{
+		{ident.StringID("bar"), now, []HostBlockMetadata{
+			{hosts[0], int64(12), &inputs[3].checksum, true},
+			{hosts[1], int64(12), &inputs[4].checksum, true},
+			{hosts[2], int64(8), &inputs[5].checksum, false},
+		}},
+	}
+
+	m := NewReplicaMetadataComparer(3, testRepairOptions()).(replicaMetadataComparer)
+	m.metadata = metadata
+
+	res := m.Compare()
+	require.Equal(t, int64(2), res.NumSeries)
+	require.Equal(t, int64(2), res.NumBlocks)
+	assertEqual(t, expected, res.SizeDifferences)
+	assertEqual(t, expected, res.ChecksumDifferences)
+}
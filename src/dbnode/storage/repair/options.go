@@ -37,6 +37,14 @@ const (
 	defaultRepairThrottle         = 90 * time.Second
 	defaultRepairMaxRetries       = 3
 	defaultRepairShardConcurrency = 1
+
+	// defaultRepairBufferChecksums is the default for whether checksums
+	// of blocks still in the buffer are compared when repairing
+	defaultRepairBufferChecksums = true
+
+	// defaultRepairBufferChecksumsPerSecond is the default limit of series
+	// per second that have their buffered blocks checksummed
+	defaultRepairBufferChecksumsPerSecond = 10000
 )
 
 var (
@@ -49,6 +57,7 @@ var (
 	errRepairCheckIntervalTooBig    = errors.New("repair check interval too big in repair options")
 	errInvalidRepairThrottle        = errors.New("invalid repair throttle in repair options")
 	errInvalidRepairMaxRetries      = errors.New("invalid repair max retries in repair options")
+	errInvalidBufferChecksumsRate   = errors.New("invalid repair buffer checksums per second in repair options")
 	errNoHostBlockMetadataSlicePool = errors.New("no host block metadata pool in repair options")
 )
 
@@ -62,6 +71,8 @@ type options struct {
 	repairCheckInterval        time.Duration
 	repairThrottle             time.Duration
 	repairMaxRetries           int
+	repairBufferChecksums      bool
+	repairBufferChecksumsRate  float64
 	hostBlockMetadataSlicePool HostBlockMetadataSlicePool
 }
 
@@ -76,6 +87,8 @@ func NewOptions() Options {
 		repairCheckInterval:        defaultRepairCheckInterval,
 		repairThrottle:             defaultRepairThrottle,
 		repairMaxRetries:           defaultRepairMaxRetries,
+		repairBufferChecksums:      defaultRepairBufferChecksums,
+		repairBufferChecksumsRate:  defaultRepairBufferChecksumsPerSecond,
 		hostBlockMetadataSlicePool: NewHostBlockMetadataSlicePool(nil, 0),
 	}
 }
@@ -170,6 +183,26 @@ func (o *options) RepairMaxRetries() int {
 	return o.repairMaxRetries
 }
 
+func (o *options) SetRepairBufferChecksums(value bool) Options {
+	opts := *o
+	opts.repairBufferChecksums = value
+	return &opts
+}
+
+func (o *options) RepairBufferChecksums() bool {
+	return o.repairBufferChecksums
+}
+
+func (o *options) SetRepairBufferChecksumsPerSecond(value float64) Options {
+	opts := *o
+	opts.repairBufferChecksumsRate = value
+	return &opts
+}
+
+func (o *options) RepairBufferChecksumsPerSecond() float64 {
+	return o.repairBufferChecksumsRate
+}
+
 func (o *options) SetHostBlockMetadataSlicePool(value HostBlockMetadataSlicePool) Options {
 	opts := *o
 	opts.hostBlockMetadataSlicePool = value
@@ -208,6 +241,9 @@ func (o *options) Validate() error {
 	if o.repairMaxRetries < 0 {
 		return errInvalidRepairMaxRetries
 	}
+	if o.repairBufferChecksumsRate < 0 {
+		return errInvalidBufferChecksumsRate
+	}
 	if o.hostBlockMetadataSlicePool == nil {
 		return errNoHostBlockMetadataSlicePool
 	}
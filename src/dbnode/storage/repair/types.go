@@ -35,6 +35,7 @@ type HostBlockMetadata struct {
 	Host     topology.Host
 	Size     int64
 	Checksum *uint32
+	Buffered bool
 }
 
 // HostBlockMetadataSlice captures a slice of hostBlockMetadata
@@ -206,6 +207,22 @@ type Options interface {
 	// MaxRepairRetries returns the max number of retries for a block start
 	RepairMaxRetries() int
 
+	// SetRepairBufferChecksums sets whether to checksum blocks still in the
+	// buffer so they can be compared with the blocks of other replicas
+	SetRepairBufferChecksums(value bool) Options
+
+	// RepairBufferChecksums returns whether to checksum blocks still in the
+	// buffer so they can be compared with the blocks of other replicas
+	RepairBufferChecksums() bool
+
+	// SetRepairBufferChecksumsPerSecond sets the max number of series per second
+	// that have their buffered blocks checksummed, zero means unlimited
+	SetRepairBufferChecksumsPerSecond(value float64) Options
+
+	// RepairBufferChecksumsPerSecond returns the max number of series per second
+	// that have their buffered blocks checksummed, zero means unlimited
+	RepairBufferChecksumsPerSecond() float64
+
 	// SetHostBlockMetadataSlicePool sets the hostBlockMetadataSlice pool
 	SetHostBlockMetadataSlicePool(value HostBlockMetadataSlicePool) Options
 
@@ -28,6 +28,7 @@ import (
 
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/retention"
+	m3dbruntime "github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3/src/dbnode/storage/repair"
@@ -208,6 +209,77 @@ func TestDatabaseRepairerRepairNotBootstrapped(t *testing.T) {
 	require.Nil(t, repairer.Repair())
 }
 
+func TestDatabaseRepairerRuntimeDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		repairInterval   = 2 * time.Hour
+		repairTimeOffset = time.Hour
+		now              = time.Now().Truncate(repairInterval).Add(90 * time.Minute)
+		numRepairs       = 0
+		numIter          = 0
+	)
+
+	nowFn := func() time.Time { return now.Add(time.Duration(numIter) * time.Minute) }
+	runtimeOptsMgr := m3dbruntime.NewOptionsManager()
+	require.NoError(t, runtimeOptsMgr.Update(runtimeOptsMgr.Get().SetRepairEnabled(false)))
+	opts := testDatabaseOptions().SetRuntimeOptionsManager(runtimeOptsMgr)
+	repairOpts := testRepairOptions(ctrl).
+		SetRepairInterval(repairInterval).
+		SetRepairTimeOffset(repairTimeOffset)
+	opts = opts.
+		SetClockOptions(opts.ClockOptions().SetNowFn(nowFn)).
+		SetRepairOptions(repairOpts)
+	mockDatabase := NewMockdatabase(ctrl)
+	mockDatabase.EXPECT().Options().Return(opts).AnyTimes()
+
+	databaseRepairer, err := newDatabaseRepairer(mockDatabase, opts)
+	require.NoError(t, err)
+	repairer := databaseRepairer.(*dbRepairer)
+
+	repairer.repairFn = func() error {
+		numRepairs++
+		return nil
+	}
+
+	repairer.sleepFn = func(_ time.Duration) {
+		switch numIter {
+		case 2:
+			// Enabling repairs runs the repair skipped in the same interval.
+			require.NoError(t, runtimeOptsMgr.Update(runtimeOptsMgr.Get().SetRepairEnabled(true)))
+		case 4:
+			repairer.closed = true
+		}
+		numIter++
+	}
+
+	repairer.run()
+	require.Equal(t, 5, numIter)
+	require.Equal(t, 1, numRepairs)
+
+	// Repairs invoked directly are also skipped while disabled.
+	require.NoError(t, runtimeOptsMgr.Update(runtimeOptsMgr.Get().SetRepairEnabled(false)))
+	mockDatabase.EXPECT().IsBootstrapped().Return(true)
+	require.NoError(t, repairer.Repair())
+}
+
+func TestDatabaseShardRepairerRuntimeThrottle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	runtimeOptsMgr := m3dbruntime.NewOptionsManager()
+	require.NoError(t, runtimeOptsMgr.Update(runtimeOptsMgr.Get().SetRepairThrottle(time.Second)))
+	opts := testDatabaseOptions().SetRuntimeOptionsManager(runtimeOptsMgr)
+	rpOpts := testRepairOptions(ctrl).SetRepairThrottle(time.Minute)
+
+	repairer := newShardRepairer(opts, rpOpts)
+	require.Equal(t, time.Second, repairer.Options().RepairThrottle())
+
+	require.NoError(t, runtimeOptsMgr.Update(runtimeOptsMgr.Get().SetRepairThrottle(0)))
+	require.Equal(t, time.Duration(0), repairer.Options().RepairThrottle())
+}
+
 func TestDatabaseShardRepairerRepair(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -219,7 +291,9 @@ func TestDatabaseShardRepairerRepair(t *testing.T) {
 	mockClient := client.NewMockAdminClient(ctrl)
 	mockClient.EXPECT().DefaultAdminSession().Return(session, nil)
 
-	rpOpts := testRepairOptions(ctrl).SetAdminClient(mockClient)
+	rpOpts := testRepairOptions(ctrl).
+		SetAdminClient(mockClient).
+		SetRepairBufferChecksums(false)
 
 	now := time.Now()
 	nowFn := func() time.Time { return now }
@@ -292,7 +366,7 @@ func TestDatabaseShardRepairerRepair(t *testing.T) {
 	)
 	session.EXPECT().
 		FetchBlocksMetadataFromPeers(namespace, shardID, start, end,
-			rpOpts.RepairConsistencyLevel(), gomock.Any(), client.FetchBlocksMetadataEndpointV2).
+			rpOpts.RepairConsistencyLevel(), gomock.Any(), client.FetchBlocksMetadataEndpointV2, false).
 		Return(peerIter, nil)
 
 	var (
@@ -332,6 +406,127 @@ func TestDatabaseShardRepairerRepair(t *testing.T) {
 	require.Equal(t, expected, block.Metadata())
 }
 
+func TestDatabaseShardRepairerRepairBufferedBlocks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	session := client.NewMockAdminSession(ctrl)
+	session.EXPECT().Origin().Return(topology.NewHost("0", "addr0"))
+	session.EXPECT().Replicas().Return(3)
+
+	mockClient := client.NewMockAdminClient(ctrl)
+	mockClient.EXPECT().DefaultAdminSession().Return(session, nil)
+
+	rpOpts := testRepairOptions(ctrl).SetAdminClient(mockClient)
+
+	now := time.Now()
+	opts := testDatabaseOptions()
+	opts = opts.SetInstrumentOptions(
+		opts.InstrumentOptions().SetMetricsScope(tally.NoopScope))
+
+	var (
+		namespace       = ident.StringID("testNamespace")
+		start           = now
+		end             = now.Add(defaultTestRetentionOpts.BlockSize())
+		repairTimeRange = xtime.Range{Start: start, End: end}
+		shardID         = uint32(0)
+		bufferedSize    = int64(12)
+		flushedSize     = int64(8)
+		checksums       = []uint32{4, 5}
+	)
+
+	databaseShardRepairer := newShardRepairer(opts, rpOpts)
+	repairer := databaseShardRepairer.(shardRepairer)
+
+	// The local node only has the blocks in its buffer
+	results := block.NewFetchBlockMetadataResults()
+	results.Add(block.FetchBlockMetadataResult{
+		Start:    start,
+		Size:     bufferedSize,
+		Checksum: &checksums[0],
+		Buffered: true,
+	})
+	expectedResults := block.NewFetchBlocksMetadataResults()
+	expectedResults.Add(block.NewFetchBlocksMetadataResult(ident.StringID("foo"), nil, results))
+	results = block.NewFetchBlockMetadataResults()
+	results.Add(block.FetchBlockMetadataResult{
+		Start:    start,
+		Size:     bufferedSize,
+		Checksum: &checksums[0],
+		Buffered: true,
+	})
+	expectedResults.Add(block.NewFetchBlocksMetadataResult(ident.StringID("bar"), nil, results))
+
+	shard := NewMockdatabaseShard(ctrl)
+	fetchOpts := block.FetchBlocksMetadataOptions{
+		IncludeSizes:           true,
+		IncludeChecksums:       true,
+		IncludeBufferChecksums: true,
+		BufferChecksumsLimiter: repairer.bufferChecksumsLimiter,
+	}
+	shard.EXPECT().
+		FetchBlocksMetadata(gomock.Any(), start, end, gomock.Any(), int64(0), fetchOpts).
+		Return(expectedResults, nil, nil)
+	shard.EXPECT().ID().Return(shardID).AnyTimes()
+
+	// One peer agrees in its buffer while the other has already flushed the
+	// blocks, the flushed "bar" block differs from the buffered ones
+	newMetadata := func(id string, size int64, checksum *uint32, buffered bool) block.Metadata {
+		m := block.NewMetadata(ident.StringID(id), ident.Tags{}, start, size, checksum, time.Time{})
+		m.Buffered = buffered
+		return m
+	}
+	var (
+		peer1      = topology.NewHost("1", "addr1")
+		peer2      = topology.NewHost("2", "addr2")
+		peerBlocks = []struct {
+			host topology.Host
+			meta block.Metadata
+		}{
+			{peer1, newMetadata("foo", bufferedSize, &checksums[0], true)},
+			{peer2, newMetadata("foo", flushedSize, &checksums[0], false)},
+			{peer1, newMetadata("bar", bufferedSize, &checksums[0], true)},
+			{peer2, newMetadata("bar", flushedSize, &checksums[1], false)},
+		}
+		calls []*gomock.Call
+	)
+	peerIter := client.NewMockPeerBlockMetadataIter(ctrl)
+	for _, b := range peerBlocks {
+		calls = append(calls,
+			peerIter.EXPECT().Next().Return(true),
+			peerIter.EXPECT().Current().Return(b.host, b.meta))
+	}
+	calls = append(calls,
+		peerIter.EXPECT().Next().Return(false),
+		peerIter.EXPECT().Err().Return(nil))
+	gomock.InOrder(calls...)
+	session.EXPECT().
+		FetchBlocksMetadataFromPeers(namespace, shardID, start, end,
+			rpOpts.RepairConsistencyLevel(), gomock.Any(), client.FetchBlocksMetadataEndpointV2, true).
+		Return(peerIter, nil)
+
+	var resDiff repair.MetadataComparisonResult
+	repairer.recordFn = func(_ ident.ID, _ databaseShard, diffRes repair.MetadataComparisonResult) {
+		resDiff = diffRes
+	}
+
+	ctx := context.NewContext()
+	_, err := repairer.Repair(ctx, namespace, repairTimeRange, shard)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), resDiff.NumSeries)
+	require.Equal(t, int64(2), resDiff.NumBlocks)
+
+	// Only the block whose checksums differ is inconsistent
+	for _, diff := range []repair.ReplicaSeriesMetadata{
+		resDiff.SizeDifferences,
+		resDiff.ChecksumDifferences,
+	} {
+		require.Equal(t, 1, diff.Series().Len())
+		_, exists := diff.Series().Get(ident.StringID("bar"))
+		require.True(t, exists)
+	}
+}
+
 func TestRepairerRepairTimes(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
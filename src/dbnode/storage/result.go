@@ -28,10 +28,16 @@ type tickResult struct {
 	wiredBlocks            int
 	unwiredBlocks          int
 	pendingMergeBlocks     int
+	unflushedBytes         int
+	bufferEncoders         int
+	bufferBootstrapped     int
 	madeExpiredBlocks      int
 	madeUnwiredBlocks      int
 	mergedOutOfOrderBlocks int
+	recompressedBlocks     int
+	recompressedBytesSaved int
 	errors                 int
+	newSeriesBacklog       int
 }
 
 func (r tickResult) merge(other tickResult) tickResult {
@@ -43,9 +49,15 @@ func (r tickResult) merge(other tickResult) tickResult {
 		wiredBlocks:            r.wiredBlocks + other.wiredBlocks,
 		pendingMergeBlocks:     r.pendingMergeBlocks + other.pendingMergeBlocks,
 		unwiredBlocks:          r.unwiredBlocks + other.unwiredBlocks,
+		unflushedBytes:         r.unflushedBytes + other.unflushedBytes,
+		bufferEncoders:         r.bufferEncoders + other.bufferEncoders,
+		bufferBootstrapped:     r.bufferBootstrapped + other.bufferBootstrapped,
 		madeExpiredBlocks:      r.madeExpiredBlocks + other.madeExpiredBlocks,
 		madeUnwiredBlocks:      r.madeUnwiredBlocks + other.madeUnwiredBlocks,
 		mergedOutOfOrderBlocks: r.mergedOutOfOrderBlocks + other.mergedOutOfOrderBlocks,
+		recompressedBlocks:     r.recompressedBlocks + other.recompressedBlocks,
+		recompressedBytesSaved: r.recompressedBytesSaved + other.recompressedBytesSaved,
 		errors:                 r.errors + other.errors,
+		newSeriesBacklog:       r.newSeriesBacklog + other.newSeriesBacklog,
 	}
 }
@@ -23,23 +23,34 @@ package series
 import (
 	"errors"
 	"fmt"
+	"math"
+	"sort"
 	"sync/atomic"
 	"time"
+	"unsafe"
 
 	"github.com/m3db/m3/src/dbnode/clock"
+	"github.com/m3db/m3/src/dbnode/digest"
 	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	m3dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/xio"
 	"github.com/m3db/m3x/context"
+	xerrors "github.com/m3db/m3x/errors"
 	xtime "github.com/m3db/m3x/time"
 )
 
 var (
-	errMoreThanOneStreamAfterMerge = errors.New("buffer has more than one stream after merge")
-	errNoAvailableBuckets          = errors.New("[invariant violated] buffer has no available buckets")
-	timeZero                       time.Time
+	errNoAvailableBuckets    = m3dberrors.NewInternalError(errors.New("[invariant violated] buffer has no available buckets"))
+	errAnnotationTooLarge    = xerrors.NewInvalidParamsError(errors.New("annotation is too large"))
+	errMetricTypeChanged     = xerrors.NewInvalidParamsError(errors.New("metric type differs from earlier writes"))
+	errInvalidTimeUnit       = xerrors.NewInvalidParamsError(errors.New("time unit is invalid"))
+	errAnnotationDisabled    = xerrors.NewInvalidParamsError(errors.New("annotations are disabled"))
+	errMergeCancelled        = errors.New("buffer merge cancelled")
+	errMergeAbandoned        = errors.New("buffer merge abandoned")
+	errMergeChecksumMismatch = errors.New("buffer merge checksum mismatch")
+	timeZero                 time.Time
 )
 
 const (
@@ -49,6 +60,15 @@ const (
 	// 3. Bucket for the future that can be taking writes that is head of
 	// the current block if write is for the future within bounds
 	bucketsLen = 3
+
+	// mergeCancellationCheckInterval is the number of datapoints merged
+	// between checks of whether a cancellable merge has been cancelled.
+	mergeCancellationCheckInterval = 4096
+
+	// mergeChecksumOffset and mergeChecksumPrime are the FNV-1a 64 bit offset
+	// basis and prime used to fold datapoints into a merge checksum.
+	mergeChecksumOffset = 14695981039346656037
+	mergeChecksumPrime  = 1099511628211
 )
 
 type computeBucketIdxOp int
@@ -65,7 +85,17 @@ type databaseBuffer interface {
 		value float64,
 		unit xtime.Unit,
 		annotation []byte,
-	) error
+	) (WriteResult, error)
+
+	// WriteBatch writes a batch of datapoints, annotations may be nil or
+	// otherwise must be the same length as the datapoints. Returns the result
+	// and error of each write by the index of its datapoint.
+	WriteBatch(
+		ctx context.Context,
+		datapoints []ts.Datapoint,
+		unit xtime.Unit,
+		annotations [][]byte,
+	) ([]WriteResult, []error)
 
 	Snapshot(ctx context.Context, blockStart time.Time) (xio.SegmentReader, error)
 
@@ -74,6 +104,15 @@ type databaseBuffer interface {
 		start, end time.Time,
 	) [][]xio.BlockReader
 
+	// ReadEncodedWithOptions reads like ReadEncoded, except that when a
+	// downsample step is set each bucket is returned as a single stream
+	// downsampled to one datapoint per step.
+	ReadEncodedWithOptions(
+		ctx context.Context,
+		start, end time.Time,
+		opts ReadEncodedOptions,
+	) [][]xio.BlockReader
+
 	FetchBlocks(
 		ctx context.Context,
 		starts []time.Time,
@@ -87,6 +126,17 @@ type databaseBuffer interface {
 
 	IsEmpty() bool
 
+	// UnflushedBytes returns a best effort estimate of the bytes held by
+	// the buffer that have not yet been drained.
+	UnflushedBytes() int
+
+	// BucketsInfo returns a snapshot of the state of each bucket.
+	BucketsInfo() []BucketInfo
+
+	// WriteMetadata returns the last write time and number of datapoints
+	// encoded by the buffer, it is safe to call without the series lock.
+	WriteMetadata() WriteMetadata
+
 	Stats() bufferStats
 
 	// MinMax returns the minimum and maximum blockstarts for the buckets
@@ -94,7 +144,20 @@ type databaseBuffer interface {
 	// that have already been drained (as those buckets are no longer in use.)
 	MinMax() (time.Time, time.Time, error)
 
-	Tick() bufferTickResult
+	// Tick drains and resets the buckets as required and merges the out of
+	// order encoders of the buckets.
+	Tick(c context.Cancellable) bufferTickResult
+
+	// TickAndPrepareMerges drains and resets the buckets like Tick but rather
+	// than performing full merges returns them prepared to run without the
+	// series lock held, the merges must then be completed by CompleteMerges
+	// with the lock held again.
+	TickAndPrepareMerges(c context.Cancellable) (bufferTickResult, []*bufferBucketMerge)
+
+	// CompleteMerges swaps the results of merges prepared by
+	// TickAndPrepareMerges into their buckets, returning the number of
+	// buckets merged.
+	CompleteMerges(merges []*bufferBucketMerge) int
 
 	NeedsDrain() bool
 
@@ -106,8 +169,10 @@ type databaseBuffer interface {
 }
 
 type bufferStats struct {
-	openBlocks  int
-	wiredBlocks int
+	openBlocks         int
+	wiredBlocks        int
+	encoders           int
+	bootstrappedBlocks int
 }
 
 type drainAndResetResult struct {
@@ -119,6 +184,11 @@ type bufferTickResult struct {
 }
 
 type dbBuffer struct {
+	// lastWriteUnixNanos and numEncoded are accessed atomically and kept
+	// first for alignment on 32 bit platforms.
+	lastWriteUnixNanos int64
+	numEncoded         int64
+
 	opts              Options
 	nowFn             clock.NowFn
 	drainFn           databaseBufferDrainFn
@@ -127,6 +197,7 @@ type dbBuffer struct {
 	blockSize         time.Duration
 	bufferPast        time.Duration
 	bufferFuture      time.Duration
+	truncateTo        time.Duration
 }
 
 type databaseBufferDrainFn func(b block.DatabaseBlock)
@@ -147,6 +218,9 @@ func (b *dbBuffer) Reset(opts Options) {
 	b.blockSize = ropts.BlockSize()
 	b.bufferPast = ropts.BufferPast()
 	b.bufferFuture = ropts.BufferFuture()
+	b.truncateTo = opts.WriteTimestampTruncateTo()
+	atomic.StoreInt64(&b.lastWriteUnixNanos, 0)
+	atomic.StoreInt64(&b.numEncoded, 0)
 	// Avoid capturing any variables with callback
 	b.computedForEachBucketAsc(computeAndResetBucketIdx, bucketResetStart)
 }
@@ -181,8 +255,152 @@ func (b *dbBuffer) Write(
 	value float64,
 	unit xtime.Unit,
 	annotation []byte,
-) error {
+) (WriteResult, error) {
 	now := b.nowFn()
+	timestamp = b.truncateTimestamp(timestamp)
+	if err := b.validateWrite(now, timestamp, unit, annotation); err != nil {
+		return WriteResult{}, err
+	}
+
+	bucketStart := timestamp.Truncate(b.blockSize)
+	idx := b.writableBucketIdx(timestamp)
+	if b.buckets[idx].needsReset(bucketStart) {
+		// Needs reset
+		b.DrainAndReset()
+	}
+
+	result, err := b.buckets[idx].write(timestamp, value, unit, annotation)
+	if err == nil {
+		var wrote int64
+		if result.WroteNewDatapoint {
+			wrote = 1
+		}
+		b.recordWrites(now, wrote)
+	}
+	return result, err
+}
+
+func (b *dbBuffer) WriteBatch(
+	ctx context.Context,
+	datapoints []ts.Datapoint,
+	unit xtime.Unit,
+	annotations [][]byte,
+) ([]WriteResult, []error) {
+	var (
+		now     = b.nowFn()
+		results = make([]WriteResult, len(datapoints))
+		errs    = make([]error, len(datapoints))
+		order   = make([]int, 0, len(datapoints))
+		copied  = false
+	)
+	for i := range datapoints {
+		if t := b.truncateTimestamp(datapoints[i].Timestamp); !t.Equal(datapoints[i].Timestamp) {
+			// NB: the datapoints belong to the caller, so only truncate a copy.
+			if !copied {
+				datapoints = append([]ts.Datapoint(nil), datapoints...)
+				copied = true
+			}
+			datapoints[i].Timestamp = t
+		}
+		annotation := batchAnnotation(annotations, i)
+		if err := b.validateWrite(now, datapoints[i].Timestamp, unit, annotation); err != nil {
+			errs[i] = err
+			continue
+		}
+		order = append(order, i)
+	}
+
+	// Stable sort so that of datapoints with equal timestamps the last in the
+	// batch wins, the same as if they were written one at a time
+	sort.SliceStable(order, func(i, j int) bool {
+		return datapoints[order[i]].Timestamp.Before(datapoints[order[j]].Timestamp)
+	})
+
+	// Write each contiguous run of datapoints that fall in the same bucket
+	for start := 0; start < len(order); {
+		bucketStart := datapoints[order[start]].Timestamp.Truncate(b.blockSize)
+		end := start + 1
+		for end < len(order) &&
+			datapoints[order[end]].Timestamp.Truncate(b.blockSize).Equal(bucketStart) {
+			end++
+		}
+
+		idx := b.writableBucketIdx(bucketStart)
+		if b.buckets[idx].needsReset(bucketStart) {
+			// Needs reset
+			b.DrainAndReset()
+		}
+
+		b.buckets[idx].writeBatch(datapoints, unit, annotations,
+			order[start:end], results, errs)
+		start = end
+	}
+
+	var (
+		accepted int
+		wrote    int64
+	)
+	for _, i := range order {
+		if errs[i] != nil {
+			continue
+		}
+		accepted++
+		if results[i].WroteNewDatapoint {
+			wrote++
+		}
+	}
+	if accepted > 0 {
+		b.recordWrites(now, wrote)
+	}
+
+	return results, errs
+}
+
+// recordWrites records the time of accepted writes, the number of datapoints
+// encoded is only tracked incrementally between ticks and recomputed exactly
+// whenever the buckets are drained or merged.
+func (b *dbBuffer) recordWrites(now time.Time, wrote int64) {
+	atomic.StoreInt64(&b.lastWriteUnixNanos, now.UnixNano())
+	if wrote > 0 {
+		atomic.AddInt64(&b.numEncoded, wrote)
+	}
+}
+
+// updateNumEncoded recomputes the number of datapoints encoded by the
+// buckets that have not yet been drained.
+func (b *dbBuffer) updateNumEncoded() {
+	var numEncoded int64
+	for i := range b.buckets {
+		if b.buckets[i].drained {
+			continue
+		}
+		for _, elem := range b.buckets[i].encoders {
+			if elem.encoder != nil {
+				numEncoded += int64(elem.encoder.NumEncoded())
+			}
+		}
+	}
+	atomic.StoreInt64(&b.numEncoded, numEncoded)
+}
+
+func (b *dbBuffer) WriteMetadata() WriteMetadata {
+	var meta WriteMetadata
+	if lastWrite := atomic.LoadInt64(&b.lastWriteUnixNanos); lastWrite > 0 {
+		meta.LastWrite = time.Unix(0, lastWrite)
+	}
+	meta.NumEncoded = int(atomic.LoadInt64(&b.numEncoded))
+	return meta
+}
+
+func (b *dbBuffer) validateWrite(
+	now time.Time,
+	timestamp time.Time,
+	unit xtime.Unit,
+	annotation []byte,
+) error {
+	if !unit.IsValid() {
+		return errInvalidTimeUnit
+	}
 	futureLimit := now.Add(1 * b.bufferFuture)
 	pastLimit := now.Add(-1 * b.bufferPast)
 	if !futureLimit.After(timestamp) {
@@ -191,21 +409,55 @@ func (b *dbBuffer) Write(
 	if !pastLimit.Before(timestamp) {
 		return m3dberrors.ErrTooPast
 	}
+	if size := len(annotation); size > 0 {
+		if !b.opts.AnnotationsEnabled() {
+			return errAnnotationDisabled
+		}
+		b.opts.Stats().RecordAnnotationSize(size)
+		if max := b.opts.MaxAnnotationSize(); max > 0 && size > max {
+			return errAnnotationTooLarge
+		}
+	}
+	return nil
+}
 
-	bucketStart := timestamp.Truncate(b.blockSize)
-	idx := b.writableBucketIdx(timestamp)
-	if b.buckets[idx].needsReset(bucketStart) {
-		// Needs reset
-		b.DrainAndReset()
+// truncateTimestamp truncates a write timestamp to the configured precision,
+// datapoints truncated to the same timestamp are then resolved by the usual
+// write conflict resolution of the bucket.
+func (b *dbBuffer) truncateTimestamp(t time.Time) time.Time {
+	if b.truncateTo <= 0 {
+		return t
 	}
+	return t.Truncate(b.truncateTo)
+}
 
-	return b.buckets[idx].write(timestamp, value, unit, annotation)
+func batchAnnotation(annotations [][]byte, i int) []byte {
+	if i < len(annotations) {
+		return annotations[i]
+	}
+	return nil
 }
 
 func (b *dbBuffer) writableBucketIdx(t time.Time) int {
 	return int(t.Truncate(b.blockSize).UnixNano() / int64(b.blockSize) % bucketsLen)
 }
 
+func (b *dbBuffer) UnflushedBytes() int {
+	size := 0
+	for i := range b.buckets {
+		size += b.buckets[i].memorySize()
+	}
+	return size
+}
+
+func (b *dbBuffer) BucketsInfo() []BucketInfo {
+	infos := make([]BucketInfo, 0, len(b.buckets))
+	for i := range b.buckets {
+		infos = append(infos, b.buckets[i].info())
+	}
+	return infos
+}
+
 func (b *dbBuffer) IsEmpty() bool {
 	canReadAny := false
 	for i := range b.buckets {
@@ -225,6 +477,8 @@ func (b *dbBuffer) Stats() bufferStats {
 			stats.openBlocks++
 		}
 		stats.wiredBlocks++
+		stats.encoders += len(b.buckets[i].encoders)
+		stats.bootstrappedBlocks += len(b.buckets[i].bootstrapped)
 	}
 	return stats
 }
@@ -241,34 +495,93 @@ func bucketNeedsDrain(now time.Time, b *dbBuffer, idx int, start time.Time) int
 	return 0
 }
 
-func (b *dbBuffer) Tick() bufferTickResult {
-	// Avoid capturing any variables with callback
-	mergedOutOfOrder := b.computedForEachBucketAsc(computeAndResetBucketIdx, bucketTick)
+func (b *dbBuffer) Tick(c context.Cancellable) bufferTickResult {
+	result, merges := b.TickAndPrepareMerges(c)
+	for _, m := range merges {
+		m.Run(c)
+	}
+	result.mergedOutOfOrderBlocks += b.CompleteMerges(merges)
+	return result
+}
+
+func (b *dbBuffer) TickAndPrepareMerges(
+	c context.Cancellable,
+) (bufferTickResult, []*bufferBucketMerge) {
+	// Perform a drain and reset if necessary, avoid capturing any
+	// variables with callback
+	mergedOutOfOrder := b.computedForEachBucketAsc(computeAndResetBucketIdx,
+		bucketDrainAndReset)
+
+	// Try to merge any out of order encoders to amortize the cost of a drain,
+	// if the datapoint budget is exhausted or the tick is cancelled the merge
+	// resumes on the next tick. Full merges are only prepared here so that
+	// they can run without blocking reads and writes of the series.
+	var (
+		limit  = b.opts.MergeDatapointsPerTick()
+		merges []*bufferBucketMerge
+	)
+	b.forEachBucketAsc(func(bucket *dbBufferBucket) {
+		if c.IsCancelled() {
+			return
+		}
+		if limit <= 0 {
+			if m := bucket.prepareMerge(!b.opts.MergeBootstrappedAtRead()); m != nil {
+				merges = append(merges, m)
+			}
+			return
+		}
+		r, _, err := bucket.mergeWithLimit(c, limit)
+		if err != nil && err != errMergeCancelled {
+			log := b.opts.InstrumentOptions().Logger()
+			log.Errorf("buffer merge encode error: %v", err)
+		}
+		b.reportReadErrors(r.readErrors)
+		if r.merges > 0 {
+			mergedOutOfOrder++
+		}
+	})
+	b.updateNumEncoded()
+
 	return bufferTickResult{
 		mergedOutOfOrderBlocks: mergedOutOfOrder,
-	}
+	}, merges
 }
 
-func bucketTick(now time.Time, b *dbBuffer, idx int, start time.Time) int {
-	// Perform a drain and reset if necessary
-	mergedOutOfOrderBlocks := bucketDrainAndReset(now, b, idx, start)
+func (b *dbBuffer) CompleteMerges(merges []*bufferBucketMerge) int {
+	merged := 0
+	for _, m := range merges {
+		r, err := m.bucket.completeMerge(m)
+		if err != nil && err != errMergeCancelled && err != errMergeAbandoned {
+			log := b.opts.InstrumentOptions().Logger()
+			log.Errorf("buffer merge encode error: %v", err)
+		}
+		b.reportReadErrors(r.readErrors)
+		if r.merges > 0 {
+			merged++
+		}
+	}
+	b.updateNumEncoded()
+	return merged
+}
 
-	// Try to merge any out of order encoders to amortize the cost of a drain
-	r, err := b.buckets[idx].merge()
-	if err != nil {
-		log := b.opts.InstrumentOptions().Logger()
-		log.Errorf("buffer merge encode error: %v", err)
+// reportReadErrors logs and counts the readers that were skipped by a merge
+// as they failed to decode, the datapoints they held are lost.
+func (b *dbBuffer) reportReadErrors(readErrs []ReadError) {
+	if len(readErrs) == 0 {
+		return
 	}
-	if r.merges > 0 {
-		mergedOutOfOrderBlocks++
+	log := b.opts.InstrumentOptions().Logger()
+	for _, readErr := range readErrs {
+		log.Warnf("buffer merge skipped corrupt %s reader for block %s: %v",
+			readErr.Source, readErr.BlockStart.String(), readErr.Err)
 	}
-
-	return mergedOutOfOrderBlocks
+	b.opts.Stats().IncSkippedCorruptReaders(len(readErrs))
 }
 
 func (b *dbBuffer) DrainAndReset() drainAndResetResult {
 	// Avoid capturing any variables with callback
 	mergedOutOfOrder := b.computedForEachBucketAsc(computeAndResetBucketIdx, bucketDrainAndReset)
+	b.updateNumEncoded()
 	return drainAndResetResult{
 		mergedOutOfOrderBlocks: mergedOutOfOrder,
 	}
@@ -284,6 +597,7 @@ func bucketDrainAndReset(now time.Time, b *dbBuffer, idx int, start time.Time) i
 			log := b.opts.InstrumentOptions().Logger()
 			log.Errorf("buffer merge encode error: %v", err)
 		} else {
+			b.reportReadErrors(result.readErrors)
 			if result.merges > 0 {
 				mergedOutOfOrderBlocks++
 			}
@@ -385,33 +699,55 @@ func (b *dbBuffer) Snapshot(ctx context.Context, blockStart time.Time) (xio.Segm
 		}
 
 		// We need to merge all the bootstrapped blocks / encoders into a single stream for
-		// the sake of being able to persist it to disk as a single encoded stream.
-		_, err = bucket.merge()
+		// the sake of being able to persist it to disk as a single encoded stream. The
+		// snapshot is merged into a newly allocated segment rather than merging the
+		// bucket itself so that the encoders are left untouched.
+		var snapshot xio.BlockReader
+		snapshot, err = bucket.snapshot(ctx)
 		if err != nil {
 			return
 		}
 
-		// This operation is safe because all of the underlying resources will respect the
-		// lifecycle of the context in one way or another. The "bootstrapped blocks" that
-		// we stream from will mark their internal context as dependent on that of the passed
-		// context, and the Encoder's that we stream from actually perform a data copy and
-		// don't share a reference.
-		streams := bucket.streams(ctx)
-		if len(streams) != 1 {
-			// Should never happen as the call to merge above should result in only a single
-			// stream being present.
-			err = errMoreThanOneStreamAfterMerge
-			return
-		}
-
-		// Direct indexing is safe because canRead guarantees us at least one stream
-		res = streams[0]
+		res = snapshot.SegmentReader
 	})
 
 	return res, err
 }
 
 func (b *dbBuffer) ReadEncoded(ctx context.Context, start, end time.Time) [][]xio.BlockReader {
+	return b.readEncoded(ctx, start, end, func(bucket *dbBufferBucket) []xio.BlockReader {
+		return bucket.streams(ctx)
+	})
+}
+
+func (b *dbBuffer) ReadEncodedWithOptions(
+	ctx context.Context,
+	start, end time.Time,
+	opts ReadEncodedOptions,
+) [][]xio.BlockReader {
+	if opts.DownsampleStep <= 0 {
+		return b.ReadEncoded(ctx, start, end)
+	}
+
+	return b.readEncoded(ctx, start, end, func(bucket *dbBufferBucket) []xio.BlockReader {
+		stream, err := bucket.downsampled(ctx, opts)
+		if err != nil {
+			log := b.opts.InstrumentOptions().Logger()
+			log.Errorf("buffer downsample encode error: %v", err)
+			return nil
+		}
+		if !stream.IsNotEmpty() {
+			return nil
+		}
+		return []xio.BlockReader{stream}
+	})
+}
+
+func (b *dbBuffer) readEncoded(
+	ctx context.Context,
+	start, end time.Time,
+	streamsFn func(bucket *dbBufferBucket) []xio.BlockReader,
+) [][]xio.BlockReader {
 	// TODO(r): pool these results arrays
 	var res [][]xio.BlockReader
 	b.forEachBucketAsc(func(bucket *dbBufferBucket) {
@@ -425,7 +761,7 @@ func (b *dbBuffer) ReadEncoded(ctx context.Context, start, end time.Time) [][]xi
 			return
 		}
 
-		res = append(res, bucket.streams(ctx))
+		res = append(res, streamsFn(bucket))
 
 		// NB(r): Store the last read time, should not set this when
 		// calling FetchBlocks as a read is differentiated from
@@ -493,12 +829,35 @@ func (b *dbBuffer) FetchBlocksMetadata(
 		if opts.IncludeLastRead {
 			resultLastRead = bucket.lastRead()
 		}
-		// NB(r): Ignore if opts.IncludeChecksum because we avoid
-		// calculating checksum since block is open and is being mutated
+		var resultStats ts.DatapointStats
+		if opts.IncludeDatapointStats {
+			resultStats = bucket.datapointStats()
+		}
+		// NB(r): Unless enabled ignore opts.IncludeChecksum because the
+		// checksum requires merging the bucket into a temporary stream
+		// since the block is open and is being mutated.
+		var (
+			resultChecksum *uint32
+			resultErr      error
+		)
+		includeChecksum := opts.IncludeBufferChecksums ||
+			b.opts.BufferChecksumsInMetadata()
+		if opts.IncludeChecksums && includeChecksum {
+			checksum, err := bucket.checksum(ctx)
+			if err != nil {
+				resultErr = err
+			} else {
+				resultChecksum = &checksum
+			}
+		}
 		res.Add(block.FetchBlockMetadataResult{
-			Start:    bucket.start,
-			Size:     resultSize,
-			LastRead: resultLastRead,
+			Start:          bucket.start,
+			Size:           resultSize,
+			Checksum:       resultChecksum,
+			LastRead:       resultLastRead,
+			DatapointStats: resultStats,
+			Buffered:       true,
+			Err:            resultErr,
 		})
 	})
 
@@ -512,11 +871,27 @@ type dbBufferBucket struct {
 	bootstrapped      []block.DatabaseBlock
 	lastReadUnixNanos int64
 	drained           bool
+	metricType        ts.MetricType
+
+	// merging is the merge of the bucket in flight, if any, the encoders it
+	// captured must not be written to until it completes.
+	merging *bufferBucketMerge
 }
 
 type inOrderEncoder struct {
-	encoder     encoding.Encoder
-	lastWriteAt time.Time
+	encoder      encoding.Encoder
+	firstWriteAt time.Time
+	lastWriteAt  time.Time
+}
+
+// datapointStats returns the stats of the datapoints held by the encoder,
+// since an encoder is written to in order these are known without decoding.
+func (e inOrderEncoder) datapointStats() ts.DatapointStats {
+	return ts.DatapointStats{
+		FirstTimestamp: e.firstWriteAt,
+		LastTimestamp:  e.lastWriteAt,
+		Count:          e.encoder.NumEncoded(),
+	}
 }
 
 func (b *dbBufferBucket) resetTo(
@@ -536,9 +911,11 @@ func (b *dbBufferBucket) resetTo(
 	b.bootstrapped = nil
 	atomic.StoreInt64(&b.lastReadUnixNanos, 0)
 	b.drained = false
+	b.metricType = ts.MetricTypeUnknown
 }
 
 func (b *dbBufferBucket) finalize() {
+	b.abandonMerge()
 	b.resetEncoders()
 	b.resetBootstrapped()
 }
@@ -590,39 +967,48 @@ func (b *dbBufferBucket) write(
 	value float64,
 	unit xtime.Unit,
 	annotation []byte,
-) error {
+) (WriteResult, error) {
+	if err := b.validateMetricType(annotation); err != nil {
+		return WriteResult{}, err
+	}
+
 	datapoint := ts.Datapoint{
 		Timestamp: timestamp,
 		Value:     value,
 	}
 
 	// Find the correct encoder to write to
-	idx := -1
-	for i := range b.encoders {
-		lastWriteAt := b.encoders[i].lastWriteAt
-		if timestamp.Equal(lastWriteAt) {
-			last, err := b.encoders[i].encoder.LastEncoded()
-			if err != nil {
-				return err
+	idx, result, err := b.writableEncoderIndex(timestamp, value)
+	if err != nil {
+		return WriteResult{}, err
+	}
+	if !result.WroteNewDatapoint {
+		// No-op since matches the current value
+		b.opts.Stats().IncDuplicateNoOpWrites()
+		return result, nil
+	}
+
+	if idx == -1 && b.merging == nil {
+		if max := b.opts.MaxEncodersPerBucket(); max > 0 && len(b.encoders) >= max {
+			// Collapse the existing encoders before allocating another one so
+			// that the number of encoders a bucket holds stays bounded, the
+			// merged encoder may itself be writable for this datapoint.
+			if _, _, err := b.mergeWithLimit(nil, 0); err != nil {
+				return WriteResult{}, m3dberrors.NewInternalError(err)
 			}
-			if last.Value == value {
-				// No-op since matches the current value
-				// TODO(r): in the future we could return some metadata that
-				// this result was a no-op and hence does not need to be written
-				// to the commit log, otherwise high frequency write volumes
-				// that are using M3DB as a cache-like index of things seen
-				// in a time window will still cause a flood of disk/CPU resource
-				// usage writing values to the commit log, even if the memory
-				// profile is lean as a side effect of this write being a no-op.
-				return nil
+			if idx, result, err = b.writableEncoderIndex(timestamp, value); err != nil {
+				return WriteResult{}, err
+			}
+			if !result.WroteNewDatapoint {
+				// The merged encoder already holds the datapoint.
+				b.opts.Stats().IncDuplicateNoOpWrites()
+				return result, nil
 			}
-			continue
 		}
+	}
 
-		if timestamp.After(lastWriteAt) {
-			idx = i
-			break
-		}
+	if result.Upserted {
+		b.opts.Stats().IncUpsertWrites()
 	}
 
 	// Upsert/last-write-wins semantics.
@@ -631,7 +1017,10 @@ func (b *dbBufferBucket) write(
 	// since an encoder is immutable.
 	// The encoders pushed later will surface their values first.
 	if idx != -1 {
-		return b.writeToEncoderIndex(idx, datapoint, unit, annotation)
+		if err := b.writeToEncoderIndex(idx, datapoint, unit, annotation); err != nil {
+			return WriteResult{}, err
+		}
+		return result, nil
 	}
 
 	// Need a new encoder, we didn't find an encoder to write to
@@ -649,15 +1038,177 @@ func (b *dbBufferBucket) write(
 	})
 
 	idx = len(b.encoders) - 1
-	err := b.writeToEncoderIndex(idx, datapoint, unit, annotation)
+	err = b.writeToEncoderIndex(idx, datapoint, unit, annotation)
 	if err != nil {
 		encoder.Close()
 		b.encoders = b.encoders[:idx]
-		return err
+		return WriteResult{}, err
+	}
+	return result, nil
+}
+
+// writeBatch writes the datapoints at the given indexes, which must be sorted
+// by timestamp, recording the result and error of each write by index. Once
+// a datapoint is written to the encoder holding the latest datapoint of the
+// bucket, every later datapoint in the run is appended to that same encoder
+// since it is necessarily the closest fit, avoiding a scan of the encoders
+// per datapoint.
+func (b *dbBufferBucket) writeBatch(
+	datapoints []ts.Datapoint,
+	unit xtime.Unit,
+	annotations [][]byte,
+	indexes []int,
+	results []WriteResult,
+	errs []error,
+) {
+	tail := -1
+	for _, i := range indexes {
+		var (
+			datapoint  = datapoints[i]
+			annotation = batchAnnotation(annotations, i)
+		)
+		if tail != -1 && datapoint.Timestamp.After(b.encoders[tail].lastWriteAt) {
+			if err := b.validateMetricType(annotation); err != nil {
+				errs[i] = err
+				continue
+			}
+			if err := b.writeToEncoderIndex(tail, datapoint, unit, annotation); err != nil {
+				errs[i] = err
+				tail = -1
+				continue
+			}
+			results[i] = WriteResult{WroteNewDatapoint: true}
+			continue
+		}
+
+		results[i], errs[i] = b.write(datapoint.Timestamp, datapoint.Value,
+			unit, annotation)
+		tail = -1
+		if errs[i] == nil && results[i].WroteNewDatapoint {
+			tail = b.latestEncoderIndex(datapoint.Timestamp)
+			if tail < b.mergingEncoders() {
+				// Captured by the merge in flight so must not be appended to
+				tail = -1
+			}
+		}
+	}
+}
+
+// validateMetricType checks, if enabled, that the metric type carried by the
+// annotation of a write matches the metric type of earlier writes to the
+// bucket. Writes whose annotation does not carry a metric type are accepted.
+func (b *dbBufferBucket) validateMetricType(annotation []byte) error {
+	if !b.opts.ValidateMetricTypes() {
+		return nil
+	}
+	metricType, _, ok := ts.DecodeMetricTypeAnnotation(annotation)
+	if !ok {
+		return nil
+	}
+	if b.metricType == ts.MetricTypeUnknown {
+		b.metricType = metricType
+		return nil
+	}
+	if metricType != b.metricType {
+		b.opts.Stats().IncMetricTypeChanges()
+		return errMetricTypeChanged
 	}
 	return nil
 }
 
+// latestEncoderIndex returns the index of the only encoder last written at
+// the timestamp if every other encoder was last written before it, otherwise
+// -1.
+func (b *dbBufferBucket) latestEncoderIndex(timestamp time.Time) int {
+	idx := -1
+	for i := range b.encoders {
+		lastWriteAt := b.encoders[i].lastWriteAt
+		if lastWriteAt.Before(timestamp) {
+			continue
+		}
+		if idx != -1 || !lastWriteAt.Equal(timestamp) {
+			return -1
+		}
+		idx = i
+	}
+	return idx
+}
+
+// writableEncoderIndex returns the index of the encoder a datapoint should
+// be written to, or -1 if a new encoder is required. It also returns the
+// result of the write, which is a no-op if the last encoded value of an
+// encoder already matches the datapoint or an upsert if the last encoded
+// value of an encoder has the same timestamp but a different value. When the
+// first write wins any write at the last encoded timestamp of an encoder is
+// a no-op.
+//
+// The encoder selected must come after every encoder that was last written
+// at or after the timestamp since those may already hold a value for this
+// timestamp and encoders later in the stack must surface their values first
+// to retain upsert semantics. Of the remaining encoders the closest fit,
+// i.e. the one most recently written to before the timestamp, is selected
+// so that interleaved out of order writes can share encoders rather than
+// allocating a new encoder per datapoint. Encoders captured by a merge in
+// flight are never selected so that the write lands in an overlay encoder
+// that is preserved when the merge completes.
+func (b *dbBufferBucket) writableEncoderIndex(
+	timestamp time.Time,
+	value float64,
+) (int, WriteResult, error) {
+	var (
+		idx     = -1
+		latest  = -1
+		result  = WriteResult{WroteNewDatapoint: true}
+		merging = b.mergingEncoders()
+	)
+	for i := range b.encoders {
+		lastWriteAt := b.encoders[i].lastWriteAt
+		if timestamp.Equal(lastWriteAt) {
+			result.Upserted = true
+		}
+
+		if !timestamp.After(lastWriteAt) {
+			// Neither this encoder or any before it can take the write
+			idx = -1
+			latest = i
+			continue
+		}
+
+		if i < merging {
+			continue
+		}
+
+		if idx == -1 || lastWriteAt.After(b.encoders[idx].lastWriteAt) {
+			idx = i
+		}
+	}
+
+	if result.Upserted && b.firstWriteWins() {
+		// The value first written at the timestamp is kept
+		return -1, WriteResult{}, nil
+	}
+
+	// Only the latest encoder that could hold a value at the timestamp
+	// determines the value read back, so only compare against it.
+	if latest != -1 && timestamp.Equal(b.encoders[latest].lastWriteAt) {
+		last, err := b.encoders[latest].encoder.LastEncoded()
+		if err != nil {
+			return -1, WriteResult{}, m3dberrors.NewInternalError(err)
+		}
+		if last.Value == value {
+			// NB(r): Callers can use the result to skip writing the
+			// datapoint to the commit log, otherwise high frequency write
+			// volumes that are using M3DB as a cache-like index of things
+			// seen in a time window will still cause a flood of disk/CPU
+			// resource usage writing values to the commit log, even if the
+			// memory profile is lean as a side effect of this write being
+			// a no-op.
+			return -1, WriteResult{}, nil
+		}
+	}
+	return idx, result, nil
+}
+
 func (b *dbBufferBucket) writeToEncoderIndex(
 	idx int,
 	datapoint ts.Datapoint,
@@ -666,15 +1217,23 @@ func (b *dbBufferBucket) writeToEncoderIndex(
 ) error {
 	err := b.encoders[idx].encoder.Encode(datapoint, unit, annotation)
 	if err != nil {
-		return err
+		return m3dberrors.NewInternalError(err)
 	}
+	b.opts.Stats().IncDatapointsEncodedFirstTime()
 
+	if b.encoders[idx].firstWriteAt.IsZero() {
+		b.encoders[idx].firstWriteAt = datapoint.Timestamp
+	}
 	b.encoders[idx].lastWriteAt = datapoint.Timestamp
 	return nil
 }
 
 func (b *dbBufferBucket) streams(ctx context.Context) []xio.BlockReader {
-	streams := make([]xio.BlockReader, 0, len(b.bootstrapped)+len(b.encoders))
+	// NB: the slice is returned to the pool once the read context is closed,
+	// callers must not retain it past the lifetime of the context.
+	readers := b.opts.BlockReadersPool().Get(len(b.bootstrapped) + len(b.encoders))
+	ctx.RegisterFinalizer(readers)
+	streams := readers.Readers
 
 	for i := range b.bootstrapped {
 		if b.bootstrapped[i].Len() == 0 {
@@ -698,9 +1257,153 @@ func (b *dbBufferBucket) streams(ctx context.Context) []xio.BlockReader {
 		}
 	}
 
+	if b.firstWriteWins() {
+		reverseBlockReaders(streams)
+	}
+
+	// NB: keep the appended readers so they're cleared when returned to the pool.
+	readers.Readers = streams
 	return streams
 }
 
+// snapshot merges the bootstrapped blocks and encoders of the bucket into a
+// single stream backed by a newly allocated segment without mutating the
+// bucket, the segment is finalized when the context is closed.
+func (b *dbBufferBucket) snapshot(ctx context.Context) (xio.BlockReader, error) {
+	return b.reencode(ctx, nil)
+}
+
+// downsampled merges the bootstrapped blocks and encoders of the bucket into
+// a single stream downsampled to one datapoint per step, without mutating
+// the bucket, the segment is finalized when the context is closed.
+func (b *dbBufferBucket) downsampled(
+	ctx context.Context,
+	opts ReadEncodedOptions,
+) (xio.BlockReader, error) {
+	return b.reencode(ctx, &opts)
+}
+
+// reencode merges the streams of the bucket into a newly allocated segment,
+// downsampling the datapoints first if downsample options are given.
+func (b *dbBufferBucket) reencode(
+	ctx context.Context,
+	downsampleOpts *ReadEncodedOptions,
+) (xio.BlockReader, error) {
+	if b.empty() {
+		return xio.EmptyBlockReader, nil
+	}
+
+	var (
+		bopts     = b.opts.DatabaseBlockOptions()
+		blockSize = b.opts.RetentionOptions().BlockSize()
+		streams   = b.streams(ctx)
+		readers   = make([]xio.SegmentReader, 0, len(streams))
+		encoder   = bopts.EncoderPool().Get()
+		iter      = b.opts.MultiReaderIteratorPool().Get()
+	)
+	defer iter.Close()
+
+	// NB(r): The streams are registered as finalizers with the context so
+	// do not need to be finalized here after they have been read.
+	for i := range streams {
+		readers = append(readers, streams[i].SegmentReader)
+	}
+
+	encoder.Reset(b.start, bopts.DatabaseBlockAllocSize())
+	iter.Reset(readers, b.start, blockSize)
+
+	var (
+		downsampler *downsampler
+		dst         datapointEncoder = encoder
+	)
+	if downsampleOpts != nil {
+		downsampler = newDownsampler(b.start, *downsampleOpts, encoder)
+		dst = downsampler
+	}
+
+	for iter.Next() {
+		dp, unit, annotation := iter.Current()
+		if err := dst.Encode(dp, unit, annotation); err != nil {
+			encoder.Close()
+			return xio.EmptyBlockReader, err
+		}
+	}
+	if err := iter.Err(); err != nil {
+		encoder.Close()
+		return xio.EmptyBlockReader, err
+	}
+	if downsampler != nil {
+		if err := downsampler.Flush(); err != nil {
+			encoder.Close()
+			return xio.EmptyBlockReader, err
+		}
+	}
+
+	reader := xio.NewSegmentReader(encoder.Discard())
+	ctx.RegisterFinalizer(reader)
+
+	return xio.BlockReader{
+		SegmentReader: reader,
+		Start:         b.start,
+		BlockSize:     blockSize,
+	}, nil
+}
+
+// checksum returns the checksum of the data in the bucket as it will be
+// once drained as a single block, without mutating the bucket.
+func (b *dbBufferBucket) checksum(ctx context.Context) (uint32, error) {
+	if b.hasJustSingleBootstrappedBlock() {
+		return b.bootstrapped[0].Checksum()
+	}
+
+	snapshot, err := b.snapshot(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if snapshot.SegmentReader == nil {
+		return digest.SegmentChecksum(ts.Segment{}), nil
+	}
+	segment, err := snapshot.Segment()
+	if err != nil {
+		return 0, err
+	}
+	return digest.SegmentChecksum(segment), nil
+}
+
+// firstWriteWins returns whether the first value written at a timestamp is
+// kept, in which case readers are pushed to iterators in reverse order of
+// arrival since iterators surface the value of the last pushed reader.
+func (b *dbBufferBucket) firstWriteWins() bool {
+	return b.opts.WriteConflictResolution() == FirstWriteWins
+}
+
+func reverseSegmentReaders(readers []xio.SegmentReader) {
+	for i, j := 0, len(readers)-1; i < j; i, j = i+1, j-1 {
+		readers[i], readers[j] = readers[j], readers[i]
+	}
+}
+
+func reverseBlockReaders(readers []xio.BlockReader) {
+	for i, j := 0, len(readers)-1; i < j; i, j = i+1, j-1 {
+		readers[i], readers[j] = readers[j], readers[i]
+	}
+}
+
+// datapointStats returns the stats of the datapoints held by the bucket if
+// they can be determined without decoding, which is only the case when the
+// bucket holds just a single encoder or a single bootstrapped block since
+// the datapoints of several readers may overwrite one another. Otherwise
+// the returned stats are zero.
+func (b *dbBufferBucket) datapointStats() ts.DatapointStats {
+	switch {
+	case b.hasJustSingleEncoder():
+		return b.encoders[0].datapointStats()
+	case b.hasJustSingleBootstrappedBlock():
+		return b.bootstrapped[0].DatapointStats()
+	}
+	return ts.DatapointStats{}
+}
+
 func (b *dbBufferBucket) streamsLen() int {
 	length := 0
 	for i := range b.bootstrapped {
@@ -712,10 +1415,38 @@ func (b *dbBufferBucket) streamsLen() int {
 	return length
 }
 
+// memorySize returns a best effort estimate of the bytes held by the bucket,
+// the encoded bytes of the encoders and bootstrapped blocks plus the overhead
+// of the slices referencing them.
+func (b *dbBufferBucket) memorySize() int {
+	return b.streamsLen() +
+		cap(b.encoders)*int(unsafe.Sizeof(inOrderEncoder{})) +
+		cap(b.bootstrapped)*int(unsafe.Sizeof(block.DatabaseBlock(nil)))
+}
+
 func (b *dbBufferBucket) setLastRead(value time.Time) {
 	atomic.StoreInt64(&b.lastReadUnixNanos, value.UnixNano())
 }
 
+func (b *dbBufferBucket) info() BucketInfo {
+	info := BucketInfo{
+		Start:              b.start,
+		Encoders:           make([]EncoderInfo, 0, len(b.encoders)),
+		BootstrappedBlocks: len(b.bootstrapped),
+		Drained:            b.drained,
+	}
+	if lastRead := atomic.LoadInt64(&b.lastReadUnixNanos); lastRead > 0 {
+		info.LastRead = time.Unix(0, lastRead)
+	}
+	for i := range b.encoders {
+		info.Encoders = append(info.Encoders, EncoderInfo{
+			Len:         b.encoders[i].encoder.Len(),
+			LastWriteAt: b.encoders[i].lastWriteAt,
+		})
+	}
+	return info
+}
+
 func (b *dbBufferBucket) lastRead() time.Time {
 	return time.Unix(0, atomic.LoadInt64(&b.lastReadUnixNanos))
 }
@@ -743,6 +1474,12 @@ func (b *dbBufferBucket) needsMerge() bool {
 	return b.canRead() && !(b.hasJustSingleEncoder() || b.hasJustSingleBootstrappedBlock())
 }
 
+// needsEncodersMerge returns whether the encoders need merging regardless of
+// any bootstrapped blocks held by the bucket.
+func (b *dbBufferBucket) needsEncodersMerge() bool {
+	return b.canRead() && len(b.encoders) > 1
+}
+
 func (b *dbBufferBucket) hasJustSingleEncoder() bool {
 	return len(b.encoders) == 1 && len(b.bootstrapped) == 0
 }
@@ -756,22 +1493,91 @@ func (b *dbBufferBucket) hasJustSingleBootstrappedBlock() bool {
 
 type mergeResult struct {
 	merges int
+	// readErrors are the readers skipped as they failed to decode, only
+	// populated if skipping corrupt readers on merge is enabled.
+	readErrors []ReadError
 }
 
-func (b *dbBufferBucket) merge() (mergeResult, error) {
+// merge merges the bootstrapped blocks and encoders of the bucket into a
+// single encoder. If the cancellable is non-nil and is cancelled during the
+// merge then errMergeCancelled is returned and the bucket is left untouched.
+func (b *dbBufferBucket) merge(c context.Cancellable) (mergeResult, error) {
 	if !b.needsMerge() {
 		// Save unnecessary work
 		return mergeResult{}, nil
 	}
+	return b.mergeReaders(c, true)
+}
 
-	merges := 0
-	bopts := b.opts.DatabaseBlockOptions()
-	encoder := bopts.EncoderPool().Get()
-	encoder.Reset(b.start, bopts.DatabaseBlockAllocSize())
+// mergeEncoders merges just the encoders of the bucket into a single encoder,
+// leaving the bootstrapped blocks to be merged at read time or when drained.
+func (b *dbBufferBucket) mergeEncoders(c context.Cancellable) (mergeResult, error) {
+	if !b.needsEncodersMerge() {
+		// Save unnecessary work
+		return mergeResult{}, nil
+	}
+	return b.mergeReaders(c, false)
+}
+
+func (b *dbBufferBucket) mergeReaders(
+	c context.Cancellable,
+	includeBootstrapped bool,
+) (mergeResult, error) {
+	m := b.prepareMergeReaders(includeBootstrapped)
+	m.Run(c)
+	return b.completeMerge(m)
+}
+
+// bufferBucketMerge is a merge of the bootstrapped blocks and encoders of a
+// bucket that runs without the series lock held. Encoders are append only
+// once written so the streams captured when the merge is prepared are an
+// immutable view of them, writes made while the merge runs land in overlay
+// encoders created after the captured ones which are preserved when the
+// merged encoder is swapped in.
+type bufferBucketMerge struct {
+	bucket          *dbBufferBucket
+	opts            Options
+	start           time.Time
+	numEncoders     int
+	numBootstrapped int
+	readers         []xio.SegmentReader
+	streams         []xio.SegmentReader
+	ctx             context.Context
+	merges          int
+	// bootstrappedReaders is the number of readers that are streams of
+	// bootstrapped blocks, they precede the encoder streams unless reversed.
+	bootstrappedReaders int
+	reversed            bool
+
+	encoder      encoding.Encoder
+	firstWriteAt time.Time
+	lastWriteAt  time.Time
+	encoded      int
+	took         time.Duration
+	readErrors   []ReadError
+	err          error
+}
+
+// prepareMerge prepares a merge of the bucket if it needs merging, of the
+// encoders and if included the bootstrapped blocks, otherwise returns nil.
+func (b *dbBufferBucket) prepareMerge(includeBootstrapped bool) *bufferBucketMerge {
+	needsMerge := b.needsEncodersMerge()
+	if includeBootstrapped {
+		needsMerge = b.needsMerge()
+	}
+	if !needsMerge || b.merging != nil {
+		// Save unnecessary work
+		return nil
+	}
+	return b.prepareMergeReaders(includeBootstrapped)
+}
+
+func (b *dbBufferBucket) prepareMergeReaders(includeBootstrapped bool) *bufferBucketMerge {
+	b.abandonMerge()
 
 	// If we have to merge bootstrapped from disk during a merge then this
 	// can make ticking very slow, ensure to notify this bug
-	if len(b.bootstrapped) > 0 {
+	if includeBootstrapped && len(b.bootstrapped) > 0 {
 		unretrieved := 0
 		for i := range b.bootstrapped {
 			if !b.bootstrapped[i].IsRetrieved() {
@@ -784,35 +1590,309 @@ func (b *dbBufferBucket) merge() (mergeResult, error) {
 		}
 	}
 
+	m := &bufferBucketMerge{
+		bucket:      b,
+		opts:        b.opts,
+		start:       b.start,
+		numEncoders: len(b.encoders),
+		readers:     make([]xio.SegmentReader, 0, len(b.encoders)+len(b.bootstrapped)),
+		streams:     make([]xio.SegmentReader, 0, len(b.encoders)),
+		ctx:         b.opts.ContextPool().Get(),
+	}
+
+	// Rank bootstrapped blocks as data that has appeared before data that
+	// arrived locally in the buffer
+	if includeBootstrapped {
+		m.numBootstrapped = len(b.bootstrapped)
+		for i := range b.bootstrapped {
+			block, err := b.bootstrapped[i].Stream(m.ctx)
+			if err == nil && block.SegmentReader != nil {
+				m.merges++
+				m.bootstrappedReaders++
+				m.readers = append(m.readers, block.SegmentReader)
+			}
+		}
+	}
+
+	for i := range b.encoders {
+		if s := b.encoders[i].encoder.Stream(); s != nil {
+			m.merges++
+			m.readers = append(m.readers, s)
+			m.streams = append(m.streams, s)
+		}
+	}
+
+	if b.firstWriteWins() {
+		reverseSegmentReaders(m.readers)
+		m.reversed = true
+	}
+
+	b.merging = m
+	return m
+}
+
+// Run merges the captured streams into a single encoder, it does not access
+// the bucket so can run without the series lock held. If the cancellable is
+// non-nil and is cancelled during the merge then errMergeCancelled is set
+// and the bucket is left untouched once the merge is completed.
+func (m *bufferBucketMerge) Run(c context.Cancellable) {
+	var (
+		nowFn      = m.opts.ClockOptions().NowFn()
+		mergeStart = nowFn()
+		bopts      = m.opts.DatabaseBlockOptions()
+		encoder    = bopts.EncoderPool().Get()
+		iter       = m.opts.MultiReaderIteratorPool().Get()
+		verify     = m.opts.VerifyMergeChecksums()
+		checksum   = newMergeChecksum()
+	)
+	defer iter.Close()
+
+	encoder.Reset(m.start, bopts.DatabaseBlockAllocSize())
+	resetMergeIter(iter, m.readers, m.start, m.opts)
+	for iter.Next() {
+		if mergeCancelled(c, m.encoded) {
+			encoder.Close()
+			m.err = errMergeCancelled
+			return
+		}
+		dp, unit, annotation := iter.Current()
+		if err := encoder.Encode(dp, unit, annotation); err != nil {
+			encoder.Close()
+			m.err = err
+			return
+		}
+		if verify {
+			checksum.update(dp, unit)
+		}
+		if m.encoded == 0 {
+			m.firstWriteAt = dp.Timestamp
+		}
+		m.lastWriteAt = dp.Timestamp
+		m.encoded++
+	}
+	if err := iter.Err(); err != nil {
+		encoder.Close()
+		m.err = err
+		return
+	}
+	if verify {
+		if err := verifyMergedEncoder(encoder, m.start, checksum, m.opts); err != nil {
+			encoder.Close()
+			m.err = err
+			return
+		}
+	}
+
+	m.readErrors = mergeReadErrors(iter, len(m.readers),
+		m.bootstrappedReaders, m.reversed)
+	m.encoder = encoder
+	m.took = nowFn().Sub(mergeStart)
+}
+
+func (m *bufferBucketMerge) finalize() {
+	// NB(r): Only need to close the mutable encoder streams as
+	// the context we created for reading the bootstrap blocks
+	// when closed will close those streams.
+	m.ctx.Close()
+	for _, stream := range m.streams {
+		stream.Finalize()
+	}
+	m.readers, m.streams = nil, nil
+}
+
+// completeMerge swaps the merged encoder in for the encoders and bootstrapped
+// blocks captured by the merge, keeping any overlay encoders written to while
+// it ran after the merged encoder so that they continue to surface their
+// values first. If the bucket was mutated since the merge was prepared the
+// merge is abandoned and errMergeAbandoned is returned.
+func (b *dbBufferBucket) completeMerge(m *bufferBucketMerge) (mergeResult, error) {
+	defer m.finalize()
+
+	if b.merging != m {
+		if m.encoder != nil {
+			m.encoder.Close()
+		}
+		return mergeResult{}, errMergeAbandoned
+	}
+	b.merging = nil
+
+	if m.err != nil {
+		return mergeResult{}, m.err
+	}
+
+	for i := 0; i < m.numBootstrapped; i++ {
+		b.bootstrapped[i].Close()
+		b.bootstrapped[i] = nil
+	}
+	if n := copy(b.bootstrapped, b.bootstrapped[m.numBootstrapped:]); n > 0 {
+		b.bootstrapped = b.bootstrapped[:n]
+	} else {
+		b.bootstrapped = nil
+	}
+
+	var zeroed inOrderEncoder
+	for i := 0; i < m.numEncoders; i++ {
+		b.encoders[i].encoder.Close()
+	}
+	if m.numEncoders > 0 {
+		// Reuse the first captured slot for the merged encoder
+		n := copy(b.encoders[1:], b.encoders[m.numEncoders:])
+		for i := 1 + n; i < len(b.encoders); i++ {
+			b.encoders[i] = zeroed
+		}
+		b.encoders = b.encoders[:1+n]
+	} else {
+		b.encoders = append(b.encoders, zeroed)
+		copy(b.encoders[1:], b.encoders[:len(b.encoders)-1])
+	}
+	b.encoders[0] = inOrderEncoder{
+		encoder:      m.encoder,
+		firstWriteAt: m.firstWriteAt,
+		lastWriteAt:  m.lastWriteAt,
+	}
+
+	b.recordMerge(m.took, m.numEncoders, m.encoded)
+
+	return mergeResult{merges: m.merges, readErrors: m.readErrors}, nil
+}
+
+// abandonMerge abandons the merge in flight, if any, which must be done
+// before the encoders or bootstrapped blocks it captured are mutated.
+func (b *dbBufferBucket) abandonMerge() {
+	if b.merging != nil {
+		b.opts.Stats().IncAbandonedMerges()
+	}
+	b.merging = nil
+}
+
+// mergingEncoders returns the number of leading encoders captured by the
+// merge in flight which must not be written to.
+func (b *dbBufferBucket) mergingEncoders() int {
+	if b.merging == nil {
+		return 0
+	}
+	return b.merging.numEncoders
+}
+
+// mergeWithLimit merges the bootstrapped blocks and encoders of the bucket
+// incrementally, encoding at most roughly maxDatapoints before yielding. Readers
+// are merged a pair at a time so that partial progress only ever replaces the
+// fully consumed encoders and blocks, readers that are merged together are
+// always adjacent in read precedence so upserts resolve the same as with a
+// single full merge. The first pair is always merged regardless of the budget
+// to guarantee progress. Returns whether more merge work remains, a
+// non-positive maxDatapoints performs a full merge. If bootstrapped blocks
+// are merged at read time only the encoders are merged.
+func (b *dbBufferBucket) mergeWithLimit(
+	c context.Cancellable,
+	maxDatapoints int,
+) (mergeResult, bool, error) {
+	encodersOnly := b.opts.MergeBootstrappedAtRead()
+	if maxDatapoints <= 0 {
+		var (
+			r   mergeResult
+			err error
+		)
+		if encodersOnly {
+			r, err = b.mergeEncoders(c)
+		} else {
+			r, err = b.merge(c)
+		}
+		return r, false, err
+	}
+
 	var (
-		start   = b.start
-		readers = make([]xio.SegmentReader, 0, len(b.encoders)+len(b.bootstrapped))
-		streams = make([]xio.SegmentReader, 0, len(b.encoders))
-		iter    = b.opts.MultiReaderIteratorPool().Get()
-		ctx     = b.opts.ContextPool().Get()
+		needsMerge = b.needsMerge
+		merges     int
+		encoded    int
+		readErrs   []ReadError
 	)
+	if encodersOnly {
+		needsMerge = b.needsEncodersMerge
+	}
+	for needsMerge() {
+		if merges > 0 && encoded >= maxDatapoints {
+			return mergeResult{merges: merges, readErrors: readErrs}, true, nil
+		}
+		r, n, err := b.mergeAdjacentPair(c, encodersOnly)
+		if err != nil {
+			return mergeResult{merges: merges, readErrors: readErrs}, false, err
+		}
+		merges += r.merges
+		encoded += n
+		readErrs = append(readErrs, r.readErrors...)
+	}
+
+	return mergeResult{merges: merges, readErrors: readErrs}, false, nil
+}
+
+// mergeAdjacentPair merges two readers that are adjacent in read precedence,
+// bootstrapped blocks rank before encoders, into a single encoder placed at
+// the front of the encoders. The pair straddles the boundary between the
+// bootstrapped blocks and the encoders where possible so that the merged
+// encoder keeps its precedence relative to the remaining readers, unless
+// encodersOnly is set in which case only encoders are merged. Returns the
+// number of datapoints encoded.
+func (b *dbBufferBucket) mergeAdjacentPair(
+	c context.Cancellable,
+	encodersOnly bool,
+) (mergeResult, int, error) {
+	b.abandonMerge()
+
+	var (
+		nowFn           = b.opts.ClockOptions().NowFn()
+		mergeStart      = nowFn()
+		encodersAtMerge = len(b.encoders)
+		numBootstrapped = 0
+		numEncoders     = 0
+	)
+	switch {
+	case !encodersOnly && len(b.bootstrapped) > 0 && len(b.encoders) > 0:
+		numBootstrapped, numEncoders = 1, 1
+	case !encodersOnly && len(b.bootstrapped) > 1:
+		numBootstrapped = 2
+	case len(b.encoders) > 1:
+		numEncoders = 2
+	default:
+		return mergeResult{}, 0, nil
+	}
+
+	var (
+		bopts        = b.opts.DatabaseBlockOptions()
+		encoder      = bopts.EncoderPool().Get()
+		firstBlock   = len(b.bootstrapped) - numBootstrapped
+		readers      = make([]xio.SegmentReader, 0, 2)
+		streams      = make([]xio.SegmentReader, 0, numEncoders)
+		iter         = b.opts.MultiReaderIteratorPool().Get()
+		ctx          = b.opts.ContextPool().Get()
+		merges       = 0
+		encoded      = 0
+		bootstrapped = 0
+		reversed     = b.firstWriteWins()
+		verify       = b.opts.VerifyMergeChecksums()
+		checksum     = newMergeChecksum()
+		firstWriteAt time.Time
+		lastWriteAt  time.Time
+	)
+	encoder.Reset(b.start, bopts.DatabaseBlockAllocSize())
 	defer func() {
 		iter.Close()
 		ctx.Close()
-		// NB(r): Only need to close the mutable encoder streams as
-		// the context we created for reading the bootstrap blocks
-		// when closed will close those streams.
 		for _, stream := range streams {
 			stream.Finalize()
 		}
 	}()
 
-	// Rank bootstrapped blocks as data that has appeared before data that
-	// arrived locally in the buffer
-	for i := range b.bootstrapped {
+	for i := firstBlock; i < len(b.bootstrapped); i++ {
 		block, err := b.bootstrapped[i].Stream(ctx)
 		if err == nil && block.SegmentReader != nil {
 			merges++
+			bootstrapped++
 			readers = append(readers, block.SegmentReader)
 		}
 	}
 
-	for i := range b.encoders {
+	for i := 0; i < numEncoders; i++ {
 		if s := b.encoders[i].encoder.Stream(); s != nil {
 			merges++
 			readers = append(readers, s)
@@ -820,49 +1900,226 @@ func (b *dbBufferBucket) merge() (mergeResult, error) {
 		}
 	}
 
-	var lastWriteAt time.Time
-	iter.Reset(readers, start, b.opts.RetentionOptions().BlockSize())
+	if reversed {
+		reverseSegmentReaders(readers)
+	}
+
+	resetMergeIter(iter, readers, b.start, b.opts)
 	for iter.Next() {
+		if mergeCancelled(c, encoded) {
+			encoder.Close()
+			return mergeResult{}, 0, errMergeCancelled
+		}
 		dp, unit, annotation := iter.Current()
 		if err := encoder.Encode(dp, unit, annotation); err != nil {
-			return mergeResult{}, err
+			encoder.Close()
+			return mergeResult{}, 0, err
+		}
+		if verify {
+			checksum.update(dp, unit)
+		}
+		if encoded == 0 {
+			firstWriteAt = dp.Timestamp
 		}
 		lastWriteAt = dp.Timestamp
+		encoded++
 	}
 	if err := iter.Err(); err != nil {
-		return mergeResult{}, err
+		encoder.Close()
+		return mergeResult{}, 0, err
+	}
+	if verify {
+		if err := verifyMergedEncoder(encoder, b.start, checksum, b.opts); err != nil {
+			encoder.Close()
+			return mergeResult{}, 0, err
+		}
 	}
+	readErrs := mergeReadErrors(iter, len(readers), bootstrapped, reversed)
 
-	b.resetEncoders()
-	b.resetBootstrapped()
+	// Only now that the pair has been fully consumed replace it with the
+	// merged encoder
+	for i := firstBlock; i < len(b.bootstrapped); i++ {
+		b.bootstrapped[i].Close()
+		b.bootstrapped[i] = nil
+	}
+	b.bootstrapped = b.bootstrapped[:firstBlock]
+	if len(b.bootstrapped) == 0 {
+		b.bootstrapped = nil
+	}
 
-	b.encoders = append(b.encoders, inOrderEncoder{
-		encoder:     encoder,
-		lastWriteAt: lastWriteAt,
-	})
+	var zeroed inOrderEncoder
+	for i := 0; i < numEncoders; i++ {
+		b.encoders[i].encoder.Close()
+	}
+	if numEncoders > 0 {
+		// Reuse the first consumed slot for the merged encoder
+		n := copy(b.encoders[1:], b.encoders[numEncoders:])
+		for i := 1 + n; i < len(b.encoders); i++ {
+			b.encoders[i] = zeroed
+		}
+		b.encoders = b.encoders[:1+n]
+	} else {
+		b.encoders = append(b.encoders, zeroed)
+		copy(b.encoders[1:], b.encoders[:len(b.encoders)-1])
+	}
+	b.encoders[0] = inOrderEncoder{
+		encoder:      encoder,
+		firstWriteAt: firstWriteAt,
+		lastWriteAt:  lastWriteAt,
+	}
+
+	b.recordMerge(nowFn().Sub(mergeStart), encodersAtMerge, encoded)
+
+	return mergeResult{merges: merges, readErrors: readErrs}, encoded, nil
+}
+
+// resetMergeIter resets the iterator to merge the readers, tolerating readers
+// that fail to decode if skipping corrupt readers on merge is enabled.
+func resetMergeIter(
+	iter encoding.MultiReaderIterator,
+	readers []xio.SegmentReader,
+	start time.Time,
+	opts Options,
+) {
+	blockSize := opts.RetentionOptions().BlockSize()
+	if opts.SkipCorruptReadersOnMerge() {
+		iter.ResetTolerant(readers, start, blockSize)
+		return
+	}
+	iter.Reset(readers, start, blockSize)
+}
+
+// mergeReadErrors returns the readers skipped by the iterator of a merge with
+// their source, the first bootstrapped readers of the merge are streams of
+// bootstrapped blocks and the rest of encoders, unless the readers were
+// reversed for first write wins upserts.
+func mergeReadErrors(
+	iter encoding.MultiReaderIterator,
+	numReaders int,
+	bootstrapped int,
+	reversed bool,
+) []ReadError {
+	iterErrs := iter.ReadErrors()
+	if len(iterErrs) == 0 {
+		return nil
+	}
+	readErrs := make([]ReadError, 0, len(iterErrs))
+	for _, iterErr := range iterErrs {
+		idx := iterErr.Index
+		if reversed {
+			idx = numReaders - 1 - idx
+		}
+		source := ReadErrorSourceEncoder
+		if idx < bootstrapped {
+			source = ReadErrorSourceBootstrapped
+		}
+		readErrs = append(readErrs, ReadError{
+			BlockStart: iterErr.Start,
+			Source:     source,
+			Err:        iterErr.Err,
+		})
+	}
+	return readErrs
+}
+
+// mergeCancelled returns whether a merge that has encoded the given number
+// of datapoints should be abandoned, the cancellable is only checked every
+// mergeCancellationCheckInterval datapoints and may be nil if the merge
+// cannot be cancelled.
+func mergeCancelled(c context.Cancellable, encoded int) bool {
+	return c != nil && encoded%mergeCancellationCheckInterval == 0 &&
+		c.IsCancelled()
+}
 
-	return mergeResult{merges: merges}, nil
+// mergeChecksum is a rolling checksum of the datapoints encoded by a merge.
+// Annotations are excluded as encoders only write an annotation when it
+// changes so a repeated annotation does not decode on every datapoint.
+type mergeChecksum struct {
+	count int
+	sum   uint64
+}
+
+func newMergeChecksum() mergeChecksum {
+	return mergeChecksum{sum: mergeChecksumOffset}
+}
+
+func (c *mergeChecksum) update(dp ts.Datapoint, unit xtime.Unit) {
+	c.fold(uint64(dp.Timestamp.UnixNano()))
+	c.fold(math.Float64bits(dp.Value))
+	c.fold(uint64(unit))
+	c.count++
+}
+
+func (c *mergeChecksum) fold(v uint64) {
+	c.sum ^= v
+	c.sum *= mergeChecksumPrime
+}
+
+// verifyMergedEncoder decodes the datapoints held by the merged encoder and
+// returns errMergeChecksumMismatch if their count or checksum diverge from
+// those of the datapoints that were encoded, or if they fail to decode.
+func verifyMergedEncoder(
+	encoder encoding.Encoder,
+	start time.Time,
+	expected mergeChecksum,
+	opts Options,
+) error {
+	actual := newMergeChecksum()
+	if stream := encoder.Stream(); stream != nil {
+		iter := opts.MultiReaderIteratorPool().Get()
+		iter.Reset([]xio.SegmentReader{stream}, start,
+			opts.RetentionOptions().BlockSize())
+		for iter.Next() {
+			dp, unit, _ := iter.Current()
+			actual.update(dp, unit)
+		}
+		err := iter.Err()
+		iter.Close()
+		stream.Finalize()
+		if err != nil {
+			opts.Stats().IncMergeChecksumMismatches()
+			return errMergeChecksumMismatch
+		}
+	}
+	if actual != expected {
+		opts.Stats().IncMergeChecksumMismatches()
+		return errMergeChecksumMismatch
+	}
+	return nil
+}
+
+func (b *dbBufferBucket) recordMerge(took time.Duration, encoders, datapoints int) {
+	stats := b.opts.Stats()
+	stats.RecordMergeDuration(took)
+	stats.RecordEncodersPerBucketAtMerge(encoders)
+	stats.RecordMergedDatapoints(datapoints)
+	stats.IncDatapointsReencodedInMerge(datapoints)
 }
 
 type discardMergedResult struct {
-	block  block.DatabaseBlock
-	merges int
+	block      block.DatabaseBlock
+	merges     int
+	readErrors []ReadError
 }
 
 func (b *dbBufferBucket) discardMerged() (discardMergedResult, error) {
+	b.abandonMerge()
+
 	if b.hasJustSingleEncoder() {
 		// Already merged as a single encoder
 		encoder := b.encoders[0].encoder
+		stats := b.encoders[0].datapointStats()
 		newBlock := b.opts.DatabaseBlockOptions().DatabaseBlockPool().Get()
 		blockSize := b.opts.RetentionOptions().BlockSize()
 		newBlock.Reset(b.start, blockSize, encoder.Discard())
+		newBlock.SetDatapointStats(stats)
 
 		// The single encoder is already discarded, no need to call resetEncoders
 		// just remove it from the list of encoders
 		b.encoders = b.encoders[:0]
 		b.resetBootstrapped()
 
-		return discardMergedResult{newBlock, 0}, nil
+		return discardMergedResult{newBlock, 0, nil}, nil
 	}
 
 	if b.hasJustSingleBootstrappedBlock() {
@@ -874,10 +2131,10 @@ func (b *dbBufferBucket) discardMerged() (discardMergedResult, error) {
 		b.resetEncoders()
 		b.bootstrapped = nil
 
-		return discardMergedResult{existingBlock, 0}, nil
+		return discardMergedResult{existingBlock, 0, nil}, nil
 	}
 
-	result, err := b.merge()
+	result, err := b.merge(nil)
 	if err != nil {
 		b.resetEncoders()
 		b.resetBootstrapped()
@@ -885,15 +2142,17 @@ func (b *dbBufferBucket) discardMerged() (discardMergedResult, error) {
 	}
 
 	merged := b.encoders[0].encoder
+	stats := b.encoders[0].datapointStats()
 
 	newBlock := b.opts.DatabaseBlockOptions().DatabaseBlockPool().Get()
 	blockSize := b.opts.RetentionOptions().BlockSize()
 	newBlock.Reset(b.start, blockSize, merged.Discard())
+	newBlock.SetDatapointStats(stats)
 
 	// The merged encoder is already discarded, no need to call resetEncoders
 	// just remove it from the list of encoders
 	b.encoders = b.encoders[:0]
 	b.resetBootstrapped()
 
-	return discardMergedResult{newBlock, result.merges}, nil
+	return discardMergedResult{newBlock, result.merges, result.readErrors}, nil
 }
@@ -21,8 +21,10 @@
 package series
 
 import (
+	"errors"
 	"io"
 	"io/ioutil"
+	"math/rand"
 	"sort"
 	"testing"
 	"time"
@@ -30,14 +32,19 @@ import (
 	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
 	"github.com/m3db/m3/src/dbnode/storage/block"
+	m3dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/xio"
+	"github.com/m3db/m3x/checked"
 	"github.com/m3db/m3x/context"
 	xerrors "github.com/m3db/m3x/errors"
+	"github.com/m3db/m3x/pool"
 	xtime "github.com/m3db/m3x/time"
 
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
 )
 
 func newBufferTestOptions() Options {
@@ -80,9 +87,10 @@ func TestBufferWriteTooFuture(t *testing.T) {
 	ctx := context.NewContext()
 	defer ctx.Close()
 
-	err := buffer.Write(ctx, curr.Add(rops.BufferFuture()), 1, xtime.Second, nil)
+	_, err := buffer.Write(ctx, curr.Add(rops.BufferFuture()), 1, xtime.Second, nil)
 	assert.Error(t, err)
 	assert.True(t, xerrors.IsInvalidParams(err))
+	assert.Equal(t, m3dberrors.ErrTooFuture, err)
 }
 
 func TestBufferWriteTooPast(t *testing.T) {
@@ -98,9 +106,198 @@ func TestBufferWriteTooPast(t *testing.T) {
 	ctx := context.NewContext()
 	defer ctx.Close()
 
-	err := buffer.Write(ctx, curr.Add(-1*rops.BufferPast()), 1, xtime.Second, nil)
+	_, err := buffer.Write(ctx, curr.Add(-1*rops.BufferPast()), 1, xtime.Second, nil)
 	assert.Error(t, err)
 	assert.True(t, xerrors.IsInvalidParams(err))
+	assert.Equal(t, m3dberrors.ErrTooPast, err)
+
+	// Datapoints older than retention are always rejected since the buffer
+	// past is validated to be smaller than the block size and retention.
+	_, err = buffer.Write(ctx, curr.Add(-1*rops.RetentionPeriod()), 1, xtime.Second, nil)
+	assert.Equal(t, m3dberrors.ErrTooPast, err)
+}
+
+func TestBufferWriteInvalidTimeUnit(t *testing.T) {
+	opts := newBufferTestOptions()
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	buffer := newDatabaseBuffer(nil).(*dbBuffer)
+	buffer.Reset(opts)
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	_, err := buffer.Write(ctx, curr, 1, xtime.None, nil)
+	assert.Error(t, err)
+	assert.True(t, xerrors.IsInvalidParams(err))
+	assert.Equal(t, errInvalidTimeUnit, err)
+	assert.True(t, buffer.IsEmpty())
+}
+
+func TestBufferWriteEncoderErrorIsInternal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	encoder := encoding.NewMockEncoder(ctrl)
+	encoder.EXPECT().Reset(gomock.Any(), gomock.Any())
+	encoder.EXPECT().Encode(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(errors.New("encode failed"))
+	encoder.EXPECT().Close()
+	encoderPool := encoding.NewEncoderPool(nil)
+	encoderPool.Init(func() encoding.Encoder {
+		return encoder
+	})
+
+	opts := newBufferTestOptions()
+	opts = opts.SetDatabaseBlockOptions(opts.DatabaseBlockOptions().
+		SetEncoderPool(encoderPool))
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	buffer := newDatabaseBuffer(nil).(*dbBuffer)
+	buffer.Reset(opts)
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	_, err := buffer.Write(ctx, curr, 1, xtime.Second, nil)
+	assert.Error(t, err)
+	assert.True(t, m3dberrors.IsInternal(err))
+	assert.False(t, xerrors.IsInvalidParams(err))
+	assert.False(t, m3dberrors.IsResourceExhausted(err))
+}
+
+func TestBufferWriteAnnotationTooLarge(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	opts := newBufferTestOptions().
+		SetMaxAnnotationSize(4).
+		SetStats(NewStats(scope))
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	buffer := newDatabaseBuffer(nil).(*dbBuffer)
+	buffer.Reset(opts)
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	_, err := buffer.Write(ctx, curr, 1, xtime.Second, []byte("abcde"))
+	assert.Error(t, err)
+	assert.True(t, xerrors.IsInvalidParams(err))
+	assert.Equal(t, errAnnotationTooLarge, err)
+	assert.True(t, buffer.IsEmpty())
+
+	_, err = buffer.Write(ctx, curr, 1, xtime.Second, []byte("abcd"))
+	require.NoError(t, err)
+	assert.False(t, buffer.IsEmpty())
+
+	histograms := scope.Snapshot().Histograms()
+	require.Contains(t, histograms, "series.annotation-size+")
+	var recorded int64
+	for _, count := range histograms["series.annotation-size+"].Values() {
+		recorded += count
+	}
+	assert.Equal(t, int64(2), recorded)
+}
+
+func TestBufferWriteAnnotationsDisabled(t *testing.T) {
+	opts := newBufferTestOptions().
+		SetAnnotationsEnabled(false)
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	buffer := newDatabaseBuffer(nil).(*dbBuffer)
+	buffer.Reset(opts)
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	_, err := buffer.Write(ctx, curr, 1, xtime.Second, []byte("abcd"))
+	assert.Error(t, err)
+	assert.True(t, xerrors.IsInvalidParams(err))
+	assert.Equal(t, errAnnotationDisabled, err)
+
+	_, errs := buffer.WriteBatch(ctx, []ts.Datapoint{
+		{Timestamp: curr, Value: 1},
+	}, xtime.Second, [][]byte{[]byte("abcd")})
+	require.Equal(t, 1, len(errs))
+	assert.Equal(t, errAnnotationDisabled, errs[0])
+	assert.True(t, buffer.IsEmpty())
+
+	_, err = buffer.Write(ctx, curr, 1, xtime.Second, nil)
+	require.NoError(t, err)
+	assert.False(t, buffer.IsEmpty())
+}
+
+func TestBufferWriteMetricTypeChanged(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	opts := newBufferTestOptions().
+		SetValidateMetricTypes(true).
+		SetStats(NewStats(scope))
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr.Add(secs(5))
+	}))
+	buffer := newDatabaseBuffer(nil).(*dbBuffer)
+	buffer.Reset(opts)
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	gauge, err := ts.EncodeMetricTypeAnnotation(ts.MetricTypeGauge, nil)
+	require.NoError(t, err)
+	counter, err := ts.EncodeMetricTypeAnnotation(ts.MetricTypeCounter, nil)
+	require.NoError(t, err)
+
+	_, err = buffer.Write(ctx, curr.Add(secs(1)), 1, xtime.Second, gauge)
+	require.NoError(t, err)
+
+	// Writes without a metric type are not validated.
+	_, err = buffer.Write(ctx, curr.Add(secs(2)), 2, xtime.Second, []byte("foo"))
+	require.NoError(t, err)
+
+	_, err = buffer.Write(ctx, curr.Add(secs(3)), 3, xtime.Second, counter)
+	assert.True(t, xerrors.IsInvalidParams(err))
+	assert.Equal(t, errMetricTypeChanged, err)
+
+	_, errs := buffer.WriteBatch(ctx, []ts.Datapoint{
+		{Timestamp: curr.Add(secs(3)), Value: 3},
+		{Timestamp: curr.Add(secs(4)), Value: 4},
+	}, xtime.Second, [][]byte{gauge, counter})
+	require.NoError(t, errs[0])
+	assert.Equal(t, errMetricTypeChanged, errs[1])
+
+	counters := scope.Snapshot().Counters()
+	require.Contains(t, counters, "series.metric-type-changes+")
+	assert.Equal(t, int64(2), counters["series.metric-type-changes+"].Value())
+
+	// Reads decode the metric type of each datapoint from its annotation.
+	results := buffer.ReadEncoded(ctx, timeZero, timeDistantFuture)
+	iter := opts.MultiReaderIteratorPool().Get()
+	iter.ResetSliceOfSlices(xio.NewReaderSliceOfSlicesFromBlockReadersIterator(results))
+	defer iter.Close()
+
+	var metricTypes []ts.MetricType
+	for iter.Next() {
+		metricType, _, _ := encoding.CurrentMetricType(iter)
+		metricTypes = append(metricTypes, metricType)
+	}
+	require.NoError(t, iter.Err())
+	assert.Equal(t, []ts.MetricType{
+		ts.MetricTypeGauge,
+		ts.MetricTypeUnknown,
+		ts.MetricTypeGauge,
+	}, metricTypes)
 }
 
 func TestBufferWriteRead(t *testing.T) {
@@ -121,7 +318,8 @@ func TestBufferWriteRead(t *testing.T) {
 
 	for _, v := range data {
 		ctx := context.NewContext()
-		assert.NoError(t, buffer.Write(ctx, v.timestamp, v.value, v.unit, v.annotation))
+		_, err := buffer.Write(ctx, v.timestamp, v.value, v.unit, v.annotation)
+		assert.NoError(t, err)
 		ctx.Close()
 	}
 
@@ -134,6 +332,149 @@ func TestBufferWriteRead(t *testing.T) {
 	assertValuesEqual(t, data, results, opts)
 }
 
+func TestBufferWriteBatch(t *testing.T) {
+	opts := newBufferTestOptions()
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr.Add(secs(30))
+	}))
+	buffer := newDatabaseBuffer(nil).(*dbBuffer)
+	buffer.Reset(opts)
+
+	datapoints := []ts.Datapoint{
+		{Timestamp: curr.Add(secs(25)), Value: 1},
+		{Timestamp: curr.Add(secs(22)), Value: 2},
+		{Timestamp: curr.Add(secs(28)), Value: 3},
+		{Timestamp: curr.Add(secs(25)), Value: 4},
+		{Timestamp: curr.Add(secs(28)), Value: 3},
+		{Timestamp: curr.Add(secs(5)), Value: 5},
+		{Timestamp: curr.Add(secs(50)), Value: 6},
+	}
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	results, errs := buffer.WriteBatch(ctx, datapoints, xtime.Second, nil)
+	require.Equal(t, len(datapoints), len(results))
+	require.Equal(t, len(datapoints), len(errs))
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, errs[i])
+	}
+	assert.Equal(t, m3dberrors.ErrTooPast, errs[5])
+	assert.Equal(t, m3dberrors.ErrTooFuture, errs[6])
+
+	assert.Equal(t, WriteResult{WroteNewDatapoint: true}, results[0])
+	assert.Equal(t, WriteResult{WroteNewDatapoint: true}, results[1])
+	assert.Equal(t, WriteResult{WroteNewDatapoint: true}, results[2])
+	// The later datapoint in the batch for the same timestamp wins
+	assert.Equal(t, WriteResult{WroteNewDatapoint: true, Upserted: true}, results[3])
+	assert.Equal(t, WriteResult{}, results[4])
+
+	expected := []value{
+		{curr.Add(secs(22)), 2, xtime.Second, nil},
+		{curr.Add(secs(25)), 4, xtime.Second, nil},
+		{curr.Add(secs(28)), 3, xtime.Second, nil},
+	}
+	assertValuesEqual(t, expected,
+		buffer.ReadEncoded(ctx, timeZero, timeDistantFuture), opts)
+}
+
+// TestBufferWriteBatchMatchesWrites asserts that a batch with many out of
+// order and repeated timestamps reads back the same values as writing each
+// datapoint one at a time in the same order.
+func TestBufferWriteBatchMatchesWrites(t *testing.T) {
+	opts := newBufferTestOptions().SetMaxEncodersPerBucket(4)
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr.Add(secs(30))
+	}))
+
+	rng := rand.New(rand.NewSource(0))
+	datapoints := make([]ts.Datapoint, 1000)
+	annotations := make([][]byte, len(datapoints))
+	for i := range datapoints {
+		datapoints[i] = ts.Datapoint{
+			Timestamp: curr.Add(secs(21)).Add(time.Duration(rng.Intn(200)) * time.Millisecond),
+			Value:     float64(rng.Intn(4)),
+		}
+		annotations[i] = []byte{byte(i)}
+	}
+
+	single := newDatabaseBuffer(nil).(*dbBuffer)
+	single.Reset(opts)
+	batch := newDatabaseBuffer(nil).(*dbBuffer)
+	batch.Reset(opts)
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	for i, dp := range datapoints {
+		_, err := single.Write(ctx, dp.Timestamp, dp.Value,
+			xtime.Millisecond, annotations[i])
+		require.NoError(t, err)
+	}
+
+	_, errs := batch.WriteBatch(ctx, datapoints, xtime.Millisecond, annotations)
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	singleValues, err := decodedValues(single.ReadEncoded(ctx, timeZero, timeDistantFuture), opts)
+	require.NoError(t, err)
+	batchValues, err := decodedValues(batch.ReadEncoded(ctx, timeZero, timeDistantFuture), opts)
+	require.NoError(t, err)
+	require.Equal(t, len(singleValues), len(batchValues))
+	for i := range singleValues {
+		assert.True(t, singleValues[i].timestamp.Equal(batchValues[i].timestamp))
+		assert.Equal(t, singleValues[i].value, batchValues[i].value)
+	}
+}
+
+func TestBufferWriteMetadata(t *testing.T) {
+	opts := newBufferTestOptions()
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	buffer := newDatabaseBuffer(nil).(*dbBuffer)
+	buffer.Reset(opts)
+	require.Equal(t, WriteMetadata{}, buffer.WriteMetadata())
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	_, err := buffer.Write(ctx, curr.Add(secs(1)), 1, xtime.Second, nil)
+	require.NoError(t, err)
+	// A no-op write updates the last write time but encodes nothing
+	curr = curr.Add(secs(10))
+	_, err = buffer.Write(ctx, curr.Add(-secs(9)), 1, xtime.Second, nil)
+	require.NoError(t, err)
+	require.Equal(t, WriteMetadata{LastWrite: curr, NumEncoded: 1}, buffer.WriteMetadata())
+
+	curr = curr.Add(secs(10))
+	_, errs := buffer.WriteBatch(ctx, []ts.Datapoint{
+		{Timestamp: curr.Add(-secs(5)), Value: 2},
+		{Timestamp: curr.Add(-secs(15)), Value: 3},
+	}, xtime.Second, nil)
+	require.Equal(t, []error{nil, nil}, errs)
+	require.Equal(t, WriteMetadata{LastWrite: curr, NumEncoded: 3}, buffer.WriteMetadata())
+
+	// Rejected writes do not update the last write time
+	_, err = buffer.Write(ctx, curr.Add(rops.BufferFuture()), 4, xtime.Second, nil)
+	require.Error(t, err)
+	curr = curr.Add(secs(10))
+	buffer.Tick(context.NewNoOpCanncellable())
+	require.Equal(t, WriteMetadata{LastWrite: curr.Add(-secs(10)), NumEncoded: 3},
+		buffer.WriteMetadata())
+
+	buffer.Reset(opts)
+	require.Equal(t, WriteMetadata{}, buffer.WriteMetadata())
+}
+
 func TestBufferReadOnlyMatchingBuckets(t *testing.T) {
 	opts := newBufferTestOptions()
 	rops := opts.RetentionOptions()
@@ -153,7 +494,8 @@ func TestBufferReadOnlyMatchingBuckets(t *testing.T) {
 	for _, v := range data {
 		curr = v.timestamp
 		ctx := context.NewContext()
-		assert.NoError(t, buffer.Write(ctx, v.timestamp, v.value, v.unit, v.annotation))
+		_, err := buffer.Write(ctx, v.timestamp, v.value, v.unit, v.annotation)
+		assert.NoError(t, err)
 		ctx.Close()
 	}
 
@@ -201,7 +543,8 @@ func TestBufferDrain(t *testing.T) {
 	for _, v := range data {
 		curr = v.timestamp
 		ctx := context.NewContext()
-		assert.NoError(t, buffer.Write(ctx, v.timestamp, v.value, v.unit, v.annotation))
+		_, err := buffer.Write(ctx, v.timestamp, v.value, v.unit, v.annotation)
+		assert.NoError(t, err)
 		ctx.Close()
 	}
 
@@ -267,7 +610,8 @@ func TestBufferMinMax(t *testing.T) {
 	for _, v := range data {
 		curr = v.timestamp
 		ctx := context.NewContext()
-		assert.NoError(t, buffer.Write(ctx, v.timestamp, v.value, v.unit, v.annotation))
+		_, err := buffer.Write(ctx, v.timestamp, v.value, v.unit, v.annotation)
+		assert.NoError(t, err)
 		ctx.Close()
 	}
 
@@ -288,6 +632,112 @@ func TestBufferMinMax(t *testing.T) {
 	require.Equal(t, expectedMax.Sub(expectedMin), blockSize)
 }
 
+func TestBufferMinMaxNoAvailableBuckets(t *testing.T) {
+	opts := newBufferTestOptions()
+	buffer := newDatabaseBuffer(nil).(*dbBuffer)
+	buffer.Reset(opts)
+	for i := range buffer.buckets {
+		buffer.buckets[i].drained = true
+	}
+
+	_, _, err := buffer.MinMax()
+	assert.Equal(t, errNoAvailableBuckets, err)
+	assert.True(t, m3dberrors.IsInternal(err))
+}
+
+func TestBufferUnflushedBytes(t *testing.T) {
+	var drained []block.DatabaseBlock
+	drainFn := func(b block.DatabaseBlock) {
+		drained = append(drained, b)
+	}
+
+	opts := newBufferTestOptions()
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	buffer := newDatabaseBuffer(drainFn).(*dbBuffer)
+	buffer.Reset(opts)
+
+	// Only the slice overhead of the empty buckets is accounted for
+	empty := buffer.UnflushedBytes()
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	var last int
+	for i := 0; i < 10; i++ {
+		_, err := buffer.Write(ctx, curr.Add(secs(float64(i))), float64(i), xtime.Second, nil)
+		require.NoError(t, err)
+
+		size := buffer.UnflushedBytes()
+		assert.True(t, size >= last)
+		last = size
+	}
+	assert.True(t, last > empty)
+
+	// Once drained the encoded bytes are no longer held by the buffer
+	curr = curr.Add(rops.BlockSize() + rops.BufferPast() + time.Second)
+	buffer.DrainAndReset()
+	require.Equal(t, 1, len(drained))
+	assert.True(t, buffer.UnflushedBytes() < last)
+}
+
+func TestBufferBucketsInfo(t *testing.T) {
+	opts := newBufferTestOptions()
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+	start := curr
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	buffer := newDatabaseBuffer(nil).(*dbBuffer)
+	buffer.Reset(opts)
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	// Write out of order to create a second encoder
+	for _, v := range []value{
+		{curr.Add(secs(2)), 1, xtime.Second, nil},
+		{curr.Add(secs(3)), 2, xtime.Second, nil},
+		{curr.Add(secs(1)), 3, xtime.Second, nil},
+	} {
+		_, err := buffer.Write(ctx, v.timestamp, v.value, v.unit, v.annotation)
+		require.NoError(t, err)
+	}
+
+	findBucket := func(infos []BucketInfo) BucketInfo {
+		for _, info := range infos {
+			if info.Start.Equal(start) {
+				return info
+			}
+		}
+		require.FailNow(t, "bucket not found")
+		return BucketInfo{}
+	}
+
+	infos := buffer.BucketsInfo()
+	require.Equal(t, bucketsLen, len(infos))
+
+	info := findBucket(infos)
+	assert.False(t, info.Drained)
+	assert.Equal(t, 0, info.BootstrappedBlocks)
+	assert.True(t, info.LastRead.IsZero())
+	require.Equal(t, 2, len(info.Encoders))
+	assert.True(t, curr.Add(secs(3)).Equal(info.Encoders[0].LastWriteAt))
+	assert.True(t, curr.Add(secs(1)).Equal(info.Encoders[1].LastWriteAt))
+	for _, encoder := range info.Encoders {
+		assert.True(t, encoder.Len > 0)
+	}
+
+	// Reading the bucket records the last read time
+	buffer.ReadEncoded(ctx, timeZero, timeDistantFuture)
+	info = findBucket(buffer.BucketsInfo())
+	assert.True(t, curr.Equal(info.LastRead))
+}
+
 func TestBufferBootstrapAlreadyDrained(t *testing.T) {
 	// Setup
 	drainFn := func(b block.DatabaseBlock) {}
@@ -343,7 +793,8 @@ func TestBufferResetUndrainedBucketDrainsBucket(t *testing.T) {
 	for _, v := range data {
 		curr = v.timestamp
 		ctx := context.NewContext()
-		assert.NoError(t, buffer.Write(ctx, v.timestamp, v.value, v.unit, v.annotation))
+		_, err := buffer.Write(ctx, v.timestamp, v.value, v.unit, v.annotation)
+		assert.NoError(t, err)
 		ctx.Close()
 	}
 
@@ -388,7 +839,8 @@ func TestBufferWriteOutOfOrder(t *testing.T) {
 			curr = v.timestamp
 		}
 		ctx := context.NewContext()
-		assert.NoError(t, buffer.Write(ctx, v.timestamp, v.value, v.unit, v.annotation))
+		_, err := buffer.Write(ctx, v.timestamp, v.value, v.unit, v.annotation)
+		assert.NoError(t, err)
 		ctx.Close()
 	}
 
@@ -537,6 +989,30 @@ func TestBufferBucketMergeNilEncoderStreams(t *testing.T) {
 	require.Nil(t, b.bootstrapped)
 }
 
+func TestBufferBucketStreamsReturnedToPoolOnContextClose(t *testing.T) {
+	readersPool := xio.NewBlockReadersPool([]pool.Bucket{{Capacity: 4, Count: 1}})
+	readersPool.Init()
+	opts := newBufferTestOptions().SetBlockReadersPool(readersPool)
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+
+	b := &dbBufferBucket{opts: opts}
+	b.resetTo(curr)
+	_, err := b.write(curr, 1, xtime.Second, nil)
+	require.NoError(t, err)
+
+	ctx := context.NewContext()
+	streams := b.streams(ctx)
+	require.Equal(t, 1, len(streams))
+	require.Equal(t, 4, cap(streams))
+
+	// NB: the pool is empty until the context returns the readers to it.
+	require.False(t, &readersPool.Get(4).Readers[:1][0] == &streams[0])
+	ctx.BlockingClose()
+	require.Equal(t, xio.EmptyBlockReader, streams[0])
+	require.True(t, &readersPool.Get(4).Readers[:1][0] == &streams[0])
+}
+
 func TestBufferBucketWriteDuplicateUpserts(t *testing.T) {
 	opts := newBufferTestOptions()
 	rops := opts.RetentionOptions()
@@ -579,7 +1055,7 @@ func TestBufferBucketWriteDuplicateUpserts(t *testing.T) {
 
 	for _, values := range data {
 		for _, value := range values {
-			err := b.write(value.timestamp, value.value,
+			_, err := b.write(value.timestamp, value.value,
 				value.unit, value.annotation)
 			require.NoError(t, err)
 		}
@@ -607,26 +1083,174 @@ func TestBufferBucketWriteDuplicateUpserts(t *testing.T) {
 	assertValuesEqual(t, expected, results, opts)
 }
 
-func TestBufferFetchBlocks(t *testing.T) {
-	b, opts, expected := newTestBufferBucketWithData(t)
-	ctx := opts.ContextPool().Get()
-	defer ctx.Close()
+func TestBufferBucketWriteInterleavedDelayedProducers(t *testing.T) {
+	maxEncoders := 4
+	opts := newBufferTestOptions().SetMaxEncodersPerBucket(maxEncoders)
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
 
-	buffer := newDatabaseBuffer(nil).(*dbBuffer)
-	buffer.Reset(opts)
-	buffer.buckets[0] = *b
+	b := &dbBufferBucket{opts: opts}
+	b.resetTo(curr)
 
-	for i := 1; i < 3; i++ {
-		newBucketStart := b.start.Add(time.Duration(i) * time.Minute)
-		(&buffer.buckets[i]).resetTo(newBucketStart)
-		buffer.buckets[i].encoders = []inOrderEncoder{{}}
+	// Each producer emits datapoints in time order but delivers them with
+	// a random delay which causes them to arrive out of order within a
+	// small window, the two producers are then interleaved.
+	var (
+		rng       = rand.New(rand.NewSource(42))
+		numPoints = 10000
+		window    = 8
+		producers = make([][]value, 2)
+		expected  = make([]value, 0, numPoints)
+	)
+	for i := 0; i < numPoints; i++ {
+		v := value{
+			timestamp: curr.Add(time.Duration(i) * 5 * time.Millisecond),
+			value:     float64(i),
+			unit:      xtime.Millisecond,
+		}
+		producers[i%2] = append(producers[i%2], v)
+		expected = append(expected, v)
+	}
+	for _, produced := range producers {
+		for i := 0; i < len(produced); i += window {
+			end := i + window
+			if end > len(produced) {
+				end = len(produced)
+			}
+			delayed := produced[i:end]
+			rng.Shuffle(len(delayed), func(i, j int) {
+				delayed[i], delayed[j] = delayed[j], delayed[i]
+			})
+		}
 	}
 
-	res := buffer.FetchBlocks(ctx, []time.Time{b.start, b.start.Add(time.Second)})
-	require.Equal(t, 1, len(res))
-	require.Equal(t, b.start, res[0].Start)
-	assertValuesEqual(t, expected, [][]xio.BlockReader{res[0].Blocks}, opts)
-}
+	for i := range producers[0] {
+		for _, produced := range producers {
+			if i >= len(produced) {
+				continue
+			}
+			v := produced[i]
+			_, err := b.write(v.timestamp, v.value, v.unit, v.annotation)
+			require.NoError(t, err)
+			require.True(t, len(b.encoders) <= maxEncoders)
+		}
+	}
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	assertValuesEqual(t, expected, [][]xio.BlockReader{b.streams(ctx)}, opts)
+
+	mergeResult, err := b.discardMerged()
+	require.NoError(t, err)
+
+	stream, err := mergeResult.block.Stream(ctx)
+	require.NoError(t, err)
+
+	assertValuesEqual(t, expected, [][]xio.BlockReader{{stream}}, opts)
+}
+
+func TestBufferBucketWriteClosestFitEncoder(t *testing.T) {
+	opts := newBufferTestOptions()
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+
+	b := &dbBufferBucket{opts: opts}
+	b.resetTo(curr)
+
+	data := []value{
+		{curr.Add(secs(10)), 1, xtime.Second, nil},
+		{curr.Add(secs(5)), 2, xtime.Second, nil},
+		{curr.Add(secs(2)), 3, xtime.Second, nil},
+		// Fits after the second and third encoders, should land in the
+		// encoder last written to at 5s rather than the one last written
+		// to at 2s.
+		{curr.Add(secs(6)), 4, xtime.Second, nil},
+		// Only fits after the third encoder.
+		{curr.Add(secs(3)), 5, xtime.Second, nil},
+	}
+	for _, v := range data {
+		_, err := b.write(v.timestamp, v.value, v.unit, v.annotation)
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, 3, len(b.encoders))
+	assert.Equal(t, data[0].timestamp, b.encoders[0].lastWriteAt)
+	assert.Equal(t, data[3].timestamp, b.encoders[1].lastWriteAt)
+	assert.Equal(t, data[4].timestamp, b.encoders[2].lastWriteAt)
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	expected := make([]value, len(data))
+	copy(expected, data)
+	sort.Sort(valuesByTime(expected))
+	assertValuesEqual(t, expected, [][]xio.BlockReader{b.streams(ctx)}, opts)
+}
+
+func TestBufferBucketWriteNoOpAndUpsertResults(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	opts := newBufferTestOptions().SetStats(NewStats(scope))
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+
+	b := &dbBufferBucket{opts: opts}
+	b.resetTo(curr)
+
+	result, err := b.write(curr, 1, xtime.Second, nil)
+	require.NoError(t, err)
+	assert.Equal(t, WriteResult{WroteNewDatapoint: true}, result)
+
+	// Same timestamp and value is a no-op
+	result, err = b.write(curr, 1, xtime.Second, nil)
+	require.NoError(t, err)
+	assert.Equal(t, WriteResult{}, result)
+
+	// Same timestamp and different value is an upsert
+	result, err = b.write(curr, 2, xtime.Second, nil)
+	require.NoError(t, err)
+	assert.Equal(t, WriteResult{WroteNewDatapoint: true, Upserted: true}, result)
+
+	// Same timestamp and upserted value is a no-op
+	result, err = b.write(curr, 2, xtime.Second, nil)
+	require.NoError(t, err)
+	assert.Equal(t, WriteResult{}, result)
+
+	counters := scope.Snapshot().Counters()
+	noOps, ok := counters["series.duplicate-noop-writes+"]
+	require.True(t, ok)
+	assert.Equal(t, int64(2), noOps.Value())
+	upserts, ok := counters["series.upsert-writes+"]
+	require.True(t, ok)
+	assert.Equal(t, int64(1), upserts.Value())
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	assertValuesEqual(t, []value{{curr, 2, xtime.Second, nil}},
+		[][]xio.BlockReader{b.streams(ctx)}, opts)
+}
+
+func TestBufferFetchBlocks(t *testing.T) {
+	b, opts, expected := newTestBufferBucketWithData(t)
+	ctx := opts.ContextPool().Get()
+	defer ctx.Close()
+
+	buffer := newDatabaseBuffer(nil).(*dbBuffer)
+	buffer.Reset(opts)
+	buffer.buckets[0] = *b
+
+	for i := 1; i < 3; i++ {
+		newBucketStart := b.start.Add(time.Duration(i) * time.Minute)
+		(&buffer.buckets[i]).resetTo(newBucketStart)
+		buffer.buckets[i].encoders = []inOrderEncoder{{}}
+	}
+
+	res := buffer.FetchBlocks(ctx, []time.Time{b.start, b.start.Add(time.Second)})
+	require.Equal(t, 1, len(res))
+	require.Equal(t, b.start, res[0].Start)
+	assertValuesEqual(t, expected, [][]xio.BlockReader{res[0].Blocks}, opts)
+}
 
 func TestBufferFetchBlocksMetadata(t *testing.T) {
 	b, opts, _ := newTestBufferBucketWithData(t)
@@ -659,6 +1283,151 @@ func TestBufferFetchBlocksMetadata(t *testing.T) {
 	assert.Equal(t, expectedSize, res[0].Size)
 	assert.Equal(t, (*uint32)(nil), res[0].Checksum) // checksum is never available for buffer block
 	assert.True(t, expectedLastRead.Equal(res[0].LastRead))
+	assert.True(t, res[0].Buffered)
+}
+
+func TestBufferDatapointStats(t *testing.T) {
+	var drained []block.DatabaseBlock
+	drainFn := func(b block.DatabaseBlock) {
+		drained = append(drained, b)
+	}
+
+	opts := newBufferTestOptions()
+	rops := opts.RetentionOptions()
+	start := time.Now().Truncate(rops.BlockSize())
+	curr := start
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	buffer := newDatabaseBuffer(drainFn).(*dbBuffer)
+	buffer.Reset(opts)
+
+	write := func(now, timestamp time.Time, value float64) {
+		curr = now
+		ctx := context.NewContext()
+		defer ctx.Close()
+		_, err := buffer.Write(ctx, timestamp, value, xtime.Second, nil)
+		require.NoError(t, err)
+	}
+
+	fetchStats := func(blockStart time.Time) ts.DatapointStats {
+		ctx := context.NewContext()
+		defer ctx.Close()
+		fetchOpts := FetchBlocksMetadataOptions{
+			FetchBlocksMetadataOptions: block.FetchBlocksMetadataOptions{
+				IncludeDatapointStats: true,
+			},
+		}
+		results := buffer.FetchBlocksMetadata(ctx, blockStart,
+			blockStart.Add(time.Second), fetchOpts)
+		defer results.Close()
+		require.Equal(t, 1, len(results.Results()))
+		return results.Results()[0].DatapointStats
+	}
+
+	// A single in order encoder knows its stats without decoding.
+	write(start.Add(10*time.Second), start.Add(10*time.Second), 1)
+	require.Equal(t, ts.DatapointStats{
+		FirstTimestamp: start.Add(10 * time.Second),
+		LastTimestamp:  start.Add(10 * time.Second),
+		Count:          1,
+	}, fetchStats(start))
+
+	// An out of order write requires another encoder and the stats are then
+	// unknown until the encoders are merged.
+	write(start.Add(10*time.Second), start.Add(5*time.Second), 2)
+	write(start.Add(20*time.Second), start.Add(20*time.Second), 3)
+	write(start.Add(30*time.Second), start.Add(30*time.Second), 4)
+	require.True(t, fetchStats(start).IsZero())
+
+	next := start.Add(rops.BlockSize())
+	write(next.Add(30*time.Second), next.Add(30*time.Second), 5)
+	buffer.DrainAndReset()
+
+	require.Equal(t, 1, len(drained))
+	require.Equal(t, ts.DatapointStats{
+		FirstTimestamp: start.Add(5 * time.Second),
+		LastTimestamp:  start.Add(30 * time.Second),
+		Count:          4,
+	}, drained[0].DatapointStats())
+
+	require.Equal(t, ts.DatapointStats{
+		FirstTimestamp: next.Add(30 * time.Second),
+		LastTimestamp:  next.Add(30 * time.Second),
+		Count:          1,
+	}, fetchStats(next))
+}
+
+func TestBufferFetchBlocksMetadataChecksums(t *testing.T) {
+	opts := newBufferTestOptions().SetBufferChecksumsInMetadata(true)
+	b, _ := newTestBufferBucketWithUpserts(t, opts)
+
+	ctx := opts.ContextPool().Get()
+	defer ctx.Close()
+
+	start := b.start.Add(-time.Second)
+	end := b.start.Add(time.Second)
+
+	buffer := newDatabaseBuffer(nil).(*dbBuffer)
+	buffer.Reset(opts)
+	buffer.buckets[0] = *b
+
+	fetchOpts := FetchBlocksMetadataOptions{
+		FetchBlocksMetadataOptions: block.FetchBlocksMetadataOptions{
+			IncludeChecksums: true,
+		},
+	}
+	res := buffer.FetchBlocksMetadata(ctx, start, end, fetchOpts).Results()
+	require.Equal(t, 1, len(res))
+	require.NoError(t, res[0].Err)
+	require.NotNil(t, res[0].Checksum)
+
+	// Fetching the metadata must not have merged the bucket
+	bucket := &buffer.buckets[0]
+	require.True(t, bucket.needsMerge())
+
+	// Checksum must match that of the block once drained
+	result, err := bucket.discardMerged()
+	require.NoError(t, err)
+	expected, err := result.block.Checksum()
+	require.NoError(t, err)
+	assert.Equal(t, expected, *res[0].Checksum)
+}
+
+func TestBufferFetchBlocksMetadataIncludeBufferChecksums(t *testing.T) {
+	opts := newBufferTestOptions()
+	b, _ := newTestBufferBucketWithUpserts(t, opts)
+
+	ctx := opts.ContextPool().Get()
+	defer ctx.Close()
+
+	start := b.start.Add(-time.Second)
+	end := b.start.Add(time.Second)
+
+	buffer := newDatabaseBuffer(nil).(*dbBuffer)
+	buffer.Reset(opts)
+	buffer.buckets[0] = *b
+
+	// Checksums of buffered blocks can be requested per fetch without
+	// enabling them for all blocks metadata
+	fetchOpts := FetchBlocksMetadataOptions{
+		FetchBlocksMetadataOptions: block.FetchBlocksMetadataOptions{
+			IncludeChecksums:       true,
+			IncludeBufferChecksums: true,
+		},
+	}
+	res := buffer.FetchBlocksMetadata(ctx, start, end, fetchOpts).Results()
+	require.Equal(t, 1, len(res))
+	require.NoError(t, res[0].Err)
+	require.NotNil(t, res[0].Checksum)
+	assert.True(t, res[0].Buffered)
+
+	bucket := &buffer.buckets[0]
+	result, err := bucket.discardMerged()
+	require.NoError(t, err)
+	expected, err := result.block.Checksum()
+	require.NoError(t, err)
+	assert.Equal(t, expected, *res[0].Checksum)
 }
 
 func TestBufferReadEncodedValidAfterDrain(t *testing.T) {
@@ -690,7 +1459,8 @@ func TestBufferReadEncodedValidAfterDrain(t *testing.T) {
 	for _, v := range data {
 		curr = v.timestamp
 		ctx := context.NewContext()
-		assert.NoError(t, buffer.Write(ctx, v.timestamp, v.value, v.unit, v.annotation))
+		_, err := buffer.Write(ctx, v.timestamp, v.value, v.unit, v.annotation)
+		assert.NoError(t, err)
 		ctx.Close()
 	}
 
@@ -783,7 +1553,8 @@ func TestBufferTickReordersOutOfOrderBuffers(t *testing.T) {
 	for _, v := range data {
 		curr = v.timestamp
 		ctx := context.NewContext()
-		assert.NoError(t, buffer.Write(ctx, v.timestamp, v.value, v.unit, v.annotation))
+		_, err := buffer.Write(ctx, v.timestamp, v.value, v.unit, v.annotation)
+		assert.NoError(t, err)
 		ctx.Close()
 	}
 
@@ -804,7 +1575,7 @@ func TestBufferTickReordersOutOfOrderBuffers(t *testing.T) {
 	assert.Equal(t, 2, len(encoders))
 
 	// Perform a tick and ensure merged out of order blocks
-	r := buffer.Tick()
+	r := buffer.Tick(context.NewNoOpCanncellable())
 	assert.Equal(t, 1, r.mergedOutOfOrderBlocks)
 
 	// Check values correct
@@ -836,66 +1607,774 @@ func TestBufferTickReordersOutOfOrderBuffers(t *testing.T) {
 	assert.Equal(t, 1, len(encoders))
 }
 
-func TestBufferSnapshot(t *testing.T) {
-	// Setup
-	var (
-		drained []block.DatabaseBlock
-		drainFn = func(b block.DatabaseBlock) {
-			drained = append(drained, b)
+// newTestBufferBucketWithUpserts returns a bucket with bootstrapped blocks and
+// several encoders that upsert values over each other along with the values
+// expected to be read back from the bucket.
+func newTestBufferBucketWithUpserts(t *testing.T, opts Options) (*dbBufferBucket, []value) {
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+
+	b := &dbBufferBucket{opts: opts}
+	b.resetTo(curr)
+
+	bootstrapped := [][]value{
+		{
+			{curr, 100, xtime.Second, nil},
+			{curr.Add(secs(10)), 101, xtime.Second, nil},
+			{curr.Add(secs(90)), 102, xtime.Second, nil},
+		},
+		{
+			{curr.Add(secs(10)), 103, xtime.Second, nil},
+			{curr.Add(secs(95)), 104, xtime.Second, nil},
+		},
+	}
+	for _, values := range bootstrapped {
+		encoder := opts.EncoderPool().Get()
+		encoder.Reset(curr, 0)
+		for _, v := range values {
+			dp := ts.Datapoint{Timestamp: v.timestamp, Value: v.value}
+			require.NoError(t, encoder.Encode(dp, v.unit, v.annotation))
 		}
-		opts      = newBufferTestOptions()
-		rops      = opts.RetentionOptions()
-		blockSize = rops.BlockSize()
-		curr      = time.Now().Truncate(blockSize)
-		start     = curr
-		buffer    = newDatabaseBuffer(drainFn).(*dbBuffer)
-	)
-	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
-		return curr
-	}))
-	buffer.Reset(opts)
+		bl := block.NewDatabaseBlock(curr, rops.BlockSize(), encoder.Discard(),
+			opts.DatabaseBlockOptions())
+		b.bootstrap(bl)
+	}
 
-	// Create test data to perform out of order writes that will create two in-order
-	// encoders so we can verify that Snapshot will perform a merge
-	data := []value{
-		{curr, 1, xtime.Second, nil},
-		{curr.Add(mins(0.5)), 2, xtime.Second, nil},
-		{curr.Add(mins(0.5)).Add(-5 * time.Second), 3, xtime.Second, nil},
-		{curr.Add(mins(1.0)), 4, xtime.Second, nil},
-		{curr.Add(mins(1.5)), 5, xtime.Second, nil},
-		{curr.Add(mins(1.5)).Add(-5 * time.Second), 6, xtime.Second, nil},
+	data := [][]value{
+		{
+			{curr, 1, xtime.Second, nil},
+			{curr.Add(secs(10)), 2, xtime.Second, nil},
+			{curr.Add(secs(50)), 3, xtime.Second, nil},
+			{curr.Add(secs(50)), 4, xtime.Second, nil},
+		},
+		{
+			{curr.Add(secs(10)), 5, xtime.Second, nil},
+			{curr.Add(secs(40)), 6, xtime.Second, nil},
+			{curr.Add(secs(60)), 7, xtime.Second, nil},
+		},
+		{
+			{curr.Add(secs(40)), 8, xtime.Second, nil},
+			{curr.Add(secs(70)), 9, xtime.Second, nil},
+		},
+		{
+			{curr.Add(secs(10)), 10, xtime.Second, nil},
+			{curr.Add(secs(80)), 11, xtime.Second, nil},
+		},
+	}
+	for _, values := range data {
+		for _, value := range values {
+			_, err := b.write(value.timestamp, value.value,
+				value.unit, value.annotation)
+			require.NoError(t, err)
+		}
+	}
 
-		// Add one write for a different block to make sure Snapshot only returns
-		// date for the requested block
-		{curr.Add(blockSize), 6, xtime.Second, nil},
+	// Encoders take precedence over bootstrapped blocks and later encoders
+	// take precedence over earlier encoders.
+	expected := []value{
+		{curr, 1, xtime.Second, nil},
+		{curr.Add(secs(10)), 10, xtime.Second, nil},
+		{curr.Add(secs(40)), 8, xtime.Second, nil},
+		{curr.Add(secs(50)), 4, xtime.Second, nil},
+		{curr.Add(secs(60)), 7, xtime.Second, nil},
+		{curr.Add(secs(70)), 9, xtime.Second, nil},
+		{curr.Add(secs(80)), 11, xtime.Second, nil},
+		{curr.Add(secs(90)), 102, xtime.Second, nil},
+		{curr.Add(secs(95)), 104, xtime.Second, nil},
 	}
 
-	// Perform the writes
-	for _, v := range data {
-		curr = v.timestamp
+	return b, expected
+}
+
+func TestBufferBucketMergeWithLimitPartialReads(t *testing.T) {
+	opts := newBufferTestOptions()
+	b, expected := newTestBufferBucketWithUpserts(t, opts)
+
+	assertBucketValues := func() {
 		ctx := context.NewContext()
-		assert.NoError(t, buffer.Write(ctx, v.timestamp, v.value, v.unit, v.annotation))
-		ctx.Close()
+		defer ctx.Close()
+		assertValuesEqual(t, expected, [][]xio.BlockReader{b.streams(ctx)}, opts)
 	}
+	assertBucketValues()
 
-	// Verify internal state
-	var encoders []encoding.Encoder
-	for i := range buffer.buckets {
-		if !buffer.buckets[i].start.Equal(start) {
-			continue
-		}
-		// Current bucket encoders should all have data in them
-		for j := range buffer.buckets[i].encoders {
-			encoder := buffer.buckets[i].encoders[j].encoder
-			assert.NotNil(t, encoder.Stream())
+	require.Equal(t, 2, len(b.bootstrapped))
+	require.True(t, len(b.encoders) > 1)
 
-			encoders = append(encoders, encoder)
-		}
+	// Merge with a small budget and assert every partially merged state
+	// still reads back every datapoint exactly once.
+	var (
+		merges int
+		ticks  int
+		more   = true
+	)
+	for more {
+		var (
+			r   mergeResult
+			err error
+		)
+		r, more, err = b.mergeWithLimit(nil, 1)
+		require.NoError(t, err)
+		merges += r.merges
+		ticks++
+		assertBucketValues()
 	}
-	assert.Equal(t, 2, len(encoders))
 
-	// Perform a snapshot
-	ctx := context.NewContext()
+	assert.True(t, ticks > 1)
+	assert.True(t, merges > 0)
+	assert.Equal(t, 0, len(b.bootstrapped))
+	require.Equal(t, 1, len(b.encoders))
+
+	// Once fully merged there is no more work to do.
+	r, more, err := b.mergeWithLimit(nil, 1)
+	require.NoError(t, err)
+	assert.False(t, more)
+	assert.Equal(t, 0, r.merges)
+	assertBucketValues()
+}
+
+func newTestBufferBucketWithInterleavedData(
+	t require.TestingT,
+	opts Options,
+	numDatapoints int,
+) *dbBufferBucket {
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+	b := &dbBufferBucket{opts: opts}
+	b.resetTo(curr)
+
+	// Interleave the datapoints across two encoders so that they need merging
+	encoders := []encoding.Encoder{opts.EncoderPool().Get(), opts.EncoderPool().Get()}
+	for _, encoder := range encoders {
+		encoder.Reset(curr, 0)
+	}
+	for i := 0; i < numDatapoints; i++ {
+		dp := ts.Datapoint{
+			Timestamp: curr.Add(time.Duration(i) * time.Millisecond),
+			Value:     float64(i),
+		}
+		err := encoders[i%2].Encode(dp, xtime.Millisecond, nil)
+		require.NoError(t, err)
+	}
+
+	b.encoders = nil
+	for _, encoder := range encoders {
+		b.encoders = append(b.encoders, inOrderEncoder{encoder: encoder})
+	}
+	return b
+}
+
+func TestBufferBucketMergeCancelledLeavesEncodersUntouched(t *testing.T) {
+	opts := newBufferTestOptions()
+	b := newTestBufferBucketWithInterleavedData(t, opts, 1000)
+	lens := []int{b.encoders[0].encoder.Len(), b.encoders[1].encoder.Len()}
+
+	c := context.NewCancellable()
+	c.Cancel()
+
+	r, err := b.merge(c)
+	require.Equal(t, errMergeCancelled, err)
+	assert.Equal(t, 0, r.merges)
+
+	require.Equal(t, 2, len(b.encoders))
+	assert.Equal(t, lens[0], b.encoders[0].encoder.Len())
+	assert.Equal(t, lens[1], b.encoders[1].encoder.Len())
+
+	// The merge can still be completed once no longer cancelled
+	c.Reset()
+	r, err = b.merge(c)
+	require.NoError(t, err)
+	assert.Equal(t, 2, r.merges)
+	assert.Equal(t, 1, len(b.encoders))
+}
+
+func TestBufferBucketDiscardMergedEncoderPoolAccountingBalanced(t *testing.T) {
+	bytesPool := pool.NewCheckedBytesPool([]pool.Bucket{
+		{Capacity: 128, Count: 16},
+		{Capacity: 4096, Count: 16},
+	}, nil, func(s []pool.Bucket) pool.BytesPool {
+		return pool.NewBytesPool(s, nil)
+	})
+	bytesPool.Init()
+	encoderPool := encoding.NewEncoderPool(nil)
+	encodingOpts := encoding.NewOptions().
+		SetEncoderPool(encoderPool).
+		SetBytesPool(encoderPool.AccountedBytesPool(bytesPool))
+	encoderPool.Init(func() encoding.Encoder {
+		return m3tsz.NewEncoder(timeZero, nil, m3tsz.DefaultIntOptimizationEnabled, encodingOpts)
+	})
+
+	opts := newBufferTestOptions()
+	opts = opts.
+		SetEncoderPool(encoderPool).
+		SetDatabaseBlockOptions(opts.DatabaseBlockOptions().
+			SetEncoderPool(encoderPool))
+	b := newTestBufferBucketWithInterleavedData(t, opts, 1000)
+	require.True(t, encoderPool.Accounting().CheckedOutBytes > 0)
+
+	result, err := b.discardMerged()
+	require.NoError(t, err)
+	require.NotNil(t, result.block)
+	require.True(t, encoderPool.Accounting().CheckedOutBytes > 0)
+
+	result.block.Close()
+
+	accounting := encoderPool.Accounting()
+	assert.Equal(t, int64(0), accounting.CheckedOutBytes)
+	assert.Equal(t, accounting.Gets, accounting.Puts)
+}
+
+// corruptingEncoder is an encoder that encodes every datapoint after the
+// first with the wrong value.
+type corruptingEncoder struct {
+	encoding.Encoder
+	encoded int
+}
+
+func (e *corruptingEncoder) Encode(
+	dp ts.Datapoint,
+	unit xtime.Unit,
+	annotation ts.Annotation,
+) error {
+	if e.encoded > 0 {
+		dp.Value++
+	}
+	e.encoded++
+	return e.Encoder.Encode(dp, unit, annotation)
+}
+
+func newCorruptingEncoderOptions(opts Options) Options {
+	encoderPool := encoding.NewEncoderPool(nil)
+	encoderPool.Init(func() encoding.Encoder {
+		return &corruptingEncoder{Encoder: m3tsz.NewEncoder(timeZero, nil,
+			m3tsz.DefaultIntOptimizationEnabled, encoding.NewOptions())}
+	})
+	return opts.SetDatabaseBlockOptions(opts.DatabaseBlockOptions().
+		SetEncoderPool(encoderPool))
+}
+
+func TestBufferBucketMergeVerifyChecksums(t *testing.T) {
+	for _, maxDatapoints := range []int{0, 1} {
+		opts := newBufferTestOptions().SetVerifyMergeChecksums(true)
+		b := newTestBufferBucketWithInterleavedData(t, opts, 1000)
+
+		_, more, err := b.mergeWithLimit(nil, maxDatapoints)
+		require.NoError(t, err)
+		assert.False(t, more)
+		require.Equal(t, 1, len(b.encoders))
+		assert.Equal(t, 1000, b.encoders[0].encoder.NumEncoded())
+	}
+}
+
+func TestBufferBucketMergeChecksumMismatchLeavesEncodersUntouched(t *testing.T) {
+	for _, maxDatapoints := range []int{0, 1} {
+		scope := tally.NewTestScope("", nil)
+		opts := newBufferTestOptions().SetStats(NewStats(scope))
+		b := newTestBufferBucketWithInterleavedData(t, opts, 1000)
+		lens := []int{b.encoders[0].encoder.Len(), b.encoders[1].encoder.Len()}
+
+		// Merges only encode with the corrupting encoders once written.
+		b.opts = newCorruptingEncoderOptions(opts).SetVerifyMergeChecksums(true)
+
+		_, _, err := b.mergeWithLimit(nil, maxDatapoints)
+		require.Equal(t, errMergeChecksumMismatch, err)
+		assert.Equal(t, int64(1),
+			scope.Snapshot().Counters()["series.merge-checksum-mismatches+"].Value())
+
+		require.Equal(t, 2, len(b.encoders))
+		assert.Equal(t, lens[0], b.encoders[0].encoder.Len())
+		assert.Equal(t, lens[1], b.encoders[1].encoder.Len())
+	}
+}
+
+func TestBufferBucketMergeChecksumsNotVerifiedByDefault(t *testing.T) {
+	opts := newBufferTestOptions()
+	require.False(t, opts.VerifyMergeChecksums())
+	b := newTestBufferBucketWithInterleavedData(t, opts, 1000)
+	b.opts = newCorruptingEncoderOptions(opts)
+
+	_, err := b.merge(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(b.encoders))
+}
+
+func TestBufferBucketMergeCancelledPromptly(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	opts := newBufferTestOptions()
+	b := newTestBufferBucketWithInterleavedData(t, opts, 1000000)
+
+	var (
+		c          = context.NewCancellable()
+		doneCh     = make(chan error, 1)
+		cancelDone time.Time
+	)
+	go func() {
+		_, err := b.merge(c)
+		cancelDone = time.Now()
+		doneCh <- err
+	}()
+
+	// Let the merge get underway before cancelling it
+	time.Sleep(10 * time.Millisecond)
+	cancelAt := time.Now()
+	c.Cancel()
+
+	err := <-doneCh
+	require.Equal(t, errMergeCancelled, err)
+	assert.True(t, cancelDone.Sub(cancelAt) < 50*time.Millisecond,
+		"merge took %v to abort after cancellation", cancelDone.Sub(cancelAt))
+	assert.Equal(t, 2, len(b.encoders))
+}
+
+func TestBufferBucketMergeWritesDuringMergeLandInOverlay(t *testing.T) {
+	opts := newBufferTestOptions()
+	curr := time.Now().Truncate(opts.RetentionOptions().BlockSize())
+	b := &dbBufferBucket{opts: opts}
+	b.resetTo(curr)
+
+	write := func(v value) {
+		_, err := b.write(v.timestamp, v.value, v.unit, v.annotation)
+		require.NoError(t, err)
+	}
+
+	// Out of order writes spread across two encoders.
+	for _, v := range []value{
+		{curr.Add(secs(10)), 1, xtime.Second, nil},
+		{curr.Add(secs(5)), 2, xtime.Second, nil},
+		{curr.Add(secs(20)), 3, xtime.Second, nil},
+		{curr.Add(secs(15)), 4, xtime.Second, nil},
+	} {
+		write(v)
+	}
+	require.Equal(t, 2, len(b.encoders))
+	lens := []int{b.encoders[0].encoder.Len(), b.encoders[1].encoder.Len()}
+
+	m := b.prepareMerge(true)
+	require.NotNil(t, m)
+	require.Nil(t, b.prepareMerge(true))
+
+	// Writes after, between and upserting the captured datapoints must all
+	// land in overlay encoders leaving the captured encoders untouched.
+	for _, v := range []value{
+		{curr.Add(secs(30)), 5, xtime.Second, nil},
+		{curr.Add(secs(12)), 6, xtime.Second, nil},
+		{curr.Add(secs(20)), 7, xtime.Second, nil},
+	} {
+		write(v)
+	}
+	require.True(t, len(b.encoders) > 2)
+	assert.Equal(t, lens[0], b.encoders[0].encoder.Len())
+	assert.Equal(t, lens[1], b.encoders[1].encoder.Len())
+
+	m.Run(nil)
+
+	// Writes between the merge running and its swap are also preserved.
+	write(value{curr.Add(secs(40)), 8, xtime.Second, nil})
+	numOverlays := len(b.encoders) - 2
+
+	r, err := b.completeMerge(m)
+	require.NoError(t, err)
+	assert.Equal(t, 2, r.merges)
+	assert.Nil(t, b.merging)
+	require.Equal(t, 1+numOverlays, len(b.encoders))
+
+	expected := []value{
+		{curr.Add(secs(5)), 2, xtime.Second, nil},
+		{curr.Add(secs(10)), 1, xtime.Second, nil},
+		{curr.Add(secs(12)), 6, xtime.Second, nil},
+		{curr.Add(secs(15)), 4, xtime.Second, nil},
+		{curr.Add(secs(20)), 7, xtime.Second, nil},
+		{curr.Add(secs(30)), 5, xtime.Second, nil},
+		{curr.Add(secs(40)), 8, xtime.Second, nil},
+	}
+	ctx := context.NewContext()
+	defer ctx.Close()
+	assertValuesEqual(t, expected, [][]xio.BlockReader{b.streams(ctx)}, opts)
+
+	// Merging again collapses the overlays with the same values read back.
+	_, err = b.merge(nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(b.encoders))
+	assertValuesEqual(t, expected, [][]xio.BlockReader{b.streams(ctx)}, opts)
+}
+
+func TestBufferBucketMergeAbandonedWhenBucketMutated(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	opts := newBufferTestOptions().SetStats(NewStats(scope))
+	b, expected := newTestBufferBucketWithUpserts(t, opts)
+
+	m := b.prepareMerge(true)
+	require.NotNil(t, m)
+	m.Run(nil)
+
+	// Draining the bucket before the merge completes abandons it.
+	result, err := b.discardMerged()
+	require.NoError(t, err)
+	assert.Nil(t, b.merging)
+
+	r, err := b.completeMerge(m)
+	require.Equal(t, errMergeAbandoned, err)
+	assert.Equal(t, 0, r.merges)
+	assert.Equal(t, 0, len(b.encoders))
+	assert.Equal(t, int64(1),
+		scope.Snapshot().Counters()["series.merge-abandoned+"].Value())
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+	assertValuesEqual(t, expected, [][]xio.BlockReader{[]xio.BlockReader{
+		xio.BlockReader{
+			SegmentReader: requireDrainedStream(ctx, t, result.block),
+		},
+	}}, opts)
+}
+
+func TestBufferBucketMergeRecordsStats(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	opts := newBufferTestOptions().SetStats(NewStats(scope))
+	b, expected := newTestBufferBucketWithUpserts(t, opts)
+	numEncoders := len(b.encoders)
+
+	r, err := b.merge(nil)
+	require.NoError(t, err)
+	require.True(t, r.merges > 0)
+
+	snapshot := scope.Snapshot()
+	require.Contains(t, snapshot.Timers(), "series.merge-duration+")
+	assert.Equal(t, 1, len(snapshot.Timers()["series.merge-duration+"].Values()))
+
+	histograms := snapshot.Histograms()
+	assertRecorded := func(name string, value float64) {
+		require.Contains(t, histograms, name)
+		var recorded int64
+		for upperBound, count := range histograms[name].Values() {
+			if count == 0 {
+				continue
+			}
+			recorded += count
+			assert.True(t, value <= upperBound)
+		}
+		assert.Equal(t, int64(1), recorded)
+	}
+	assertRecorded("series.merge-encoders-per-bucket+", float64(numEncoders))
+	assertRecorded("series.merge-datapoints+", float64(len(expected)))
+}
+
+func TestBufferBucketMergeBootstrappedAtRead(t *testing.T) {
+	for _, maxDatapoints := range []int{0, 1} {
+		opts := newBufferTestOptions().SetMergeBootstrappedAtRead(true)
+		b, expected := newTestBufferBucketWithUpserts(t, opts)
+
+		assertBucketValues := func() {
+			ctx := context.NewContext()
+			defer ctx.Close()
+			assertValuesEqual(t, expected, [][]xio.BlockReader{b.streams(ctx)}, opts)
+		}
+
+		// Merges only collapse the encoders, buffered writes upserting over
+		// bootstrapped values must still win when merged at read time.
+		more := true
+		for more {
+			var err error
+			_, more, err = b.mergeWithLimit(nil, maxDatapoints)
+			require.NoError(t, err)
+			assertBucketValues()
+		}
+
+		assert.Equal(t, 2, len(b.bootstrapped))
+		require.Equal(t, 1, len(b.encoders))
+		assert.False(t, b.needsEncodersMerge())
+		assert.True(t, b.needsMerge())
+
+		// Draining merges the bootstrapped blocks with the encoders.
+		result, err := b.discardMerged()
+		require.NoError(t, err)
+		require.NotNil(t, result.block)
+		assert.True(t, b.empty())
+
+		ctx := context.NewContext()
+		assertValuesEqual(t, expected, [][]xio.BlockReader{[]xio.BlockReader{
+			xio.BlockReader{
+				SegmentReader: requireDrainedStream(ctx, t, result.block),
+			},
+		}}, opts)
+		ctx.Close()
+	}
+}
+
+func newTestBufferBucketWithCorruptBootstrapped(
+	t *testing.T,
+	opts Options,
+) (*dbBufferBucket, []value) {
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+
+	b := &dbBufferBucket{opts: opts}
+	b.resetTo(curr)
+	b.encoders = nil
+
+	// A segment truncated before the first timestamp is fully encoded.
+	head := checked.NewBytes([]byte{0x1, 0x2}, nil)
+	b.bootstrap(block.NewDatabaseBlock(curr, rops.BlockSize(),
+		ts.NewSegment(head, nil, ts.FinalizeNone), opts.DatabaseBlockOptions()))
+
+	data := [][]value{
+		{
+			{curr, 1, xtime.Second, nil},
+			{curr.Add(secs(30)), 3, xtime.Second, nil},
+		},
+		{
+			{curr.Add(secs(10)), 2, xtime.Second, nil},
+			{curr.Add(secs(40)), 4, xtime.Second, nil},
+		},
+	}
+	var expected []value
+	for _, d := range data {
+		encoder := opts.EncoderPool().Get()
+		encoder.Reset(curr, 0)
+		for _, v := range d {
+			dp := ts.Datapoint{Timestamp: v.timestamp, Value: v.value}
+			require.NoError(t, encoder.Encode(dp, v.unit, v.annotation))
+		}
+		b.encoders = append(b.encoders, inOrderEncoder{encoder: encoder})
+		expected = append(expected, d...)
+	}
+	sort.Sort(valuesByTime(expected))
+	return b, expected
+}
+
+func TestBufferBucketMergeSkipsCorruptReaders(t *testing.T) {
+	for _, maxDatapoints := range []int{0, 1} {
+		opts := newBufferTestOptions().SetSkipCorruptReadersOnMerge(true)
+		b, expected := newTestBufferBucketWithCorruptBootstrapped(t, opts)
+
+		var readErrs []ReadError
+		for more := true; more; {
+			var (
+				r   mergeResult
+				err error
+			)
+			r, more, err = b.mergeWithLimit(nil, maxDatapoints)
+			require.NoError(t, err)
+			readErrs = append(readErrs, r.readErrors...)
+		}
+
+		require.Equal(t, 1, len(readErrs))
+		assert.True(t, b.start.Equal(readErrs[0].BlockStart))
+		assert.Equal(t, ReadErrorSourceBootstrapped, readErrs[0].Source)
+		assert.Error(t, readErrs[0].Err)
+
+		assert.Equal(t, 0, len(b.bootstrapped))
+		require.Equal(t, 1, len(b.encoders))
+
+		ctx := context.NewContext()
+		assertValuesEqual(t, expected, [][]xio.BlockReader{b.streams(ctx)}, opts)
+		ctx.Close()
+	}
+}
+
+func TestBufferBucketMergeCorruptReadersStrictByDefault(t *testing.T) {
+	opts := newBufferTestOptions()
+	require.False(t, opts.SkipCorruptReadersOnMerge())
+	b, _ := newTestBufferBucketWithCorruptBootstrapped(t, opts)
+
+	_, err := b.merge(nil)
+	require.Error(t, err)
+
+	// The bucket is left untouched by the failed merge.
+	assert.Equal(t, 1, len(b.bootstrapped))
+	assert.Equal(t, 2, len(b.encoders))
+}
+
+func TestBufferBucketFirstWriteWins(t *testing.T) {
+	opts := newBufferTestOptions().SetWriteConflictResolution(FirstWriteWins)
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+
+	b := &dbBufferBucket{opts: opts}
+	b.resetTo(curr)
+
+	encoder := opts.EncoderPool().Get()
+	encoder.Reset(curr, 0)
+	for _, v := range []value{
+		{curr, 100, xtime.Second, nil},
+		{curr.Add(secs(10)), 101, xtime.Second, nil},
+	} {
+		dp := ts.Datapoint{Timestamp: v.timestamp, Value: v.value}
+		require.NoError(t, encoder.Encode(dp, v.unit, v.annotation))
+	}
+	b.bootstrap(block.NewDatabaseBlock(curr, rops.BlockSize(), encoder.Discard(),
+		opts.DatabaseBlockOptions()))
+
+	for _, write := range []struct {
+		value    value
+		expectNo bool
+	}{
+		// Conflicts with the bootstrapped block, resolved when read
+		{value: value{curr, 1, xtime.Second, nil}},
+		{value: value{curr.Add(secs(20)), 2, xtime.Second, nil}},
+		// Conflicts with the last write of an encoder, skipped on write
+		{value: value{curr.Add(secs(20)), 3, xtime.Second, nil}, expectNo: true},
+		{value: value{curr.Add(secs(30)), 4, xtime.Second, nil}},
+		// Conflicts within an encoder, resolved when read
+		{value: value{curr.Add(secs(20)), 5, xtime.Second, nil}},
+		{value: value{curr.Add(secs(10)), 6, xtime.Second, nil}},
+	} {
+		v := write.value
+		result, err := b.write(v.timestamp, v.value, v.unit, v.annotation)
+		require.NoError(t, err)
+		assert.Equal(t, !write.expectNo, result.WroteNewDatapoint)
+	}
+
+	expected := []value{
+		{curr, 100, xtime.Second, nil},
+		{curr.Add(secs(10)), 101, xtime.Second, nil},
+		{curr.Add(secs(20)), 2, xtime.Second, nil},
+		{curr.Add(secs(30)), 4, xtime.Second, nil},
+	}
+	assertBucketValues := func() {
+		ctx := context.NewContext()
+		defer ctx.Close()
+		assertValuesEqual(t, expected, [][]xio.BlockReader{b.streams(ctx)}, opts)
+	}
+	assertBucketValues()
+
+	// Merging pairwise and fully must keep the first written values
+	_, _, err := b.mergeWithLimit(nil, 1)
+	require.NoError(t, err)
+	assertBucketValues()
+
+	result, err := b.discardMerged()
+	require.NoError(t, err)
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+	assertValuesEqual(t, expected, [][]xio.BlockReader{[]xio.BlockReader{
+		xio.BlockReader{
+			SegmentReader: requireDrainedStream(ctx, t, result.block),
+		},
+	}}, opts)
+}
+
+func TestBufferTickMergesWithDatapointBudget(t *testing.T) {
+	opts := newBufferTestOptions().SetMergeDatapointsPerTick(1)
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+	start := curr
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return start.Add(secs(30))
+	}))
+	buffer := newDatabaseBuffer(nil).(*dbBuffer)
+	buffer.Reset(opts)
+
+	// Perform out of order writes that will create three in order encoders
+	data := []value{
+		{curr.Add(secs(28)), 1, xtime.Second, nil},
+		{curr.Add(secs(25)), 2, xtime.Second, nil},
+		{curr.Add(secs(22)), 3, xtime.Second, nil},
+	}
+	end := data[0].timestamp.Add(time.Nanosecond)
+
+	for _, v := range data {
+		ctx := context.NewContext()
+		_, err := buffer.Write(ctx, v.timestamp, v.value, v.unit, v.annotation)
+		require.NoError(t, err)
+		ctx.Close()
+	}
+
+	bucketEncoders := func() int {
+		for i := range buffer.buckets {
+			if buffer.buckets[i].start.Equal(start) {
+				return len(buffer.buckets[i].encoders)
+			}
+		}
+		return 0
+	}
+	require.Equal(t, 3, bucketEncoders())
+
+	expected := make([]value, len(data))
+	copy(expected, data)
+	sort.Sort(valuesByTime(expected))
+
+	// Each tick only merges a single pair of encoders given the budget
+	for _, remaining := range []int{2, 1} {
+		r := buffer.Tick(context.NewNoOpCanncellable())
+		assert.Equal(t, 1, r.mergedOutOfOrderBlocks)
+		assert.Equal(t, remaining, bucketEncoders())
+
+		ctx := context.NewContext()
+		results := buffer.ReadEncoded(ctx, start, end)
+		assertValuesEqual(t, expected, results, opts)
+		ctx.Close()
+	}
+
+	r := buffer.Tick(context.NewNoOpCanncellable())
+	assert.Equal(t, 0, r.mergedOutOfOrderBlocks)
+	assert.Equal(t, 1, bucketEncoders())
+}
+
+func TestBufferSnapshot(t *testing.T) {
+	// Setup
+	var (
+		drained []block.DatabaseBlock
+		drainFn = func(b block.DatabaseBlock) {
+			drained = append(drained, b)
+		}
+		opts      = newBufferTestOptions()
+		rops      = opts.RetentionOptions()
+		blockSize = rops.BlockSize()
+		curr      = time.Now().Truncate(blockSize)
+		start     = curr
+		buffer    = newDatabaseBuffer(drainFn).(*dbBuffer)
+	)
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	buffer.Reset(opts)
+
+	// Create test data to perform out of order writes that will create two in-order
+	// encoders so we can verify that Snapshot will perform a merge
+	data := []value{
+		{curr, 1, xtime.Second, nil},
+		{curr.Add(mins(0.5)), 2, xtime.Second, nil},
+		{curr.Add(mins(0.5)).Add(-5 * time.Second), 3, xtime.Second, nil},
+		{curr.Add(mins(1.0)), 4, xtime.Second, nil},
+		{curr.Add(mins(1.5)), 5, xtime.Second, nil},
+		{curr.Add(mins(1.5)).Add(-5 * time.Second), 6, xtime.Second, nil},
+
+		// Add one write for a different block to make sure Snapshot only returns
+		// date for the requested block
+		{curr.Add(blockSize), 6, xtime.Second, nil},
+	}
+
+	// Perform the writes
+	for _, v := range data {
+		curr = v.timestamp
+		ctx := context.NewContext()
+		_, err := buffer.Write(ctx, v.timestamp, v.value, v.unit, v.annotation)
+		assert.NoError(t, err)
+		ctx.Close()
+	}
+
+	// Verify internal state
+	var encoders []encoding.Encoder
+	for i := range buffer.buckets {
+		if !buffer.buckets[i].start.Equal(start) {
+			continue
+		}
+		// Current bucket encoders should all have data in them
+		for j := range buffer.buckets[i].encoders {
+			encoder := buffer.buckets[i].encoders[j].encoder
+			assert.NotNil(t, encoder.Stream())
+
+			encoders = append(encoders, encoder)
+		}
+	}
+	assert.Equal(t, 2, len(encoders))
+
+	// Perform a snapshot
+	ctx := context.NewContext()
 	defer ctx.Close()
 	result, err := buffer.Snapshot(ctx, start)
 	assert.NoError(t, err)
@@ -912,7 +2391,7 @@ func TestBufferSnapshot(t *testing.T) {
 	}}
 	assertValuesEqual(t, expectedCopy, actual, opts)
 
-	// Check internal state to make sure the merge happened and was persisted
+	// Check internal state to make sure the encoders were left untouched
 	encoders = encoders[:0]
 	for i := range buffer.buckets {
 		if !buffer.buckets[i].start.Equal(start) {
@@ -927,8 +2406,68 @@ func TestBufferSnapshot(t *testing.T) {
 		}
 	}
 
-	// Ensure single encoder again
-	assert.Equal(t, 1, len(encoders))
+	// Ensure still two encoders
+	assert.Equal(t, 2, len(encoders))
+}
+
+func TestBufferBucketSnapshot(t *testing.T) {
+	b, opts, expected := newTestBufferBucketWithData(t)
+
+	// Add a bootstrapped block to ensure it's merged into the snapshot
+	bootstrapped := value{b.start.Add(secs(5)), 7, xtime.Second, nil}
+	encoder := opts.EncoderPool().Get()
+	encoder.Reset(b.start, 0)
+	require.NoError(t, encoder.Encode(ts.Datapoint{
+		Timestamp: bootstrapped.timestamp,
+		Value:     bootstrapped.value,
+	}, bootstrapped.unit, bootstrapped.annotation))
+	b.bootstrap(block.NewDatabaseBlock(b.start, opts.RetentionOptions().BlockSize(),
+		encoder.Discard(), opts.DatabaseBlockOptions()))
+	expected = append(expected, bootstrapped)
+	sort.Sort(valuesByTime(expected))
+
+	numEncoders := len(b.encoders)
+	numBootstrapped := len(b.bootstrapped)
+	streamsLen := b.streamsLen()
+
+	ctx := context.NewContext()
+	snapshot, err := b.snapshot(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, snapshot.SegmentReader)
+	assert.True(t, b.start.Equal(snapshot.Start))
+
+	// Ensure the bucket was not mutated
+	assert.Equal(t, numEncoders, len(b.encoders))
+	assert.Equal(t, numBootstrapped, len(b.bootstrapped))
+	assert.Equal(t, streamsLen, b.streamsLen())
+
+	assertValuesEqual(t, expected, [][]xio.BlockReader{{snapshot}}, opts)
+
+	// Ensure the snapshot segment is finalized with the context
+	ctx.BlockingClose()
+	segment, err := snapshot.Segment()
+	require.NoError(t, err)
+	assert.Equal(t, 0, segment.Len())
+
+	// Ensure the bucket can still be read after the snapshot is finalized
+	ctx = context.NewContext()
+	defer ctx.Close()
+	assertValuesEqual(t, expected, [][]xio.BlockReader{b.streams(ctx)}, opts)
+}
+
+func TestBufferBucketSnapshotEmpty(t *testing.T) {
+	opts := newBufferTestOptions()
+	curr := time.Now().Truncate(opts.RetentionOptions().BlockSize())
+
+	b := &dbBufferBucket{opts: opts}
+	b.resetTo(curr)
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	snapshot, err := b.snapshot(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, xio.EmptyBlockReader, snapshot)
 }
 
 func mustGetLastEncoded(t *testing.T, entry inOrderEncoder) ts.Datapoint {
@@ -936,3 +2475,160 @@ func mustGetLastEncoded(t *testing.T, entry inOrderEncoder) ts.Datapoint {
 	require.NoError(t, err)
 	return last
 }
+
+func TestBufferReadEncodedWithOptionsDownsample(t *testing.T) {
+	opts := newBufferTestOptions()
+	opts = opts.SetRetentionOptions(opts.RetentionOptions().
+		SetBlockSize(4 * time.Minute).
+		SetBufferPast(5 * time.Minute))
+	start := time.Now().Truncate(opts.RetentionOptions().BlockSize())
+	curr := start.Add(3 * time.Minute)
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	buffer := newDatabaseBuffer(nil).(*dbBuffer)
+	buffer.Reset(opts)
+
+	// Write three minutes of datapoints at 1s resolution with a spike
+	// in the first minute.
+	var data []value
+	for i := 0; i < 180; i++ {
+		v := float64(i)
+		if i == 10 {
+			v = 1000
+		}
+		data = append(data, value{start.Add(secs(float64(i))), v, xtime.Second, nil})
+	}
+	for _, v := range data {
+		ctx := context.NewContext()
+		_, err := buffer.Write(ctx, v.timestamp, v.value, v.unit, v.annotation)
+		require.NoError(t, err)
+		ctx.Close()
+	}
+
+	// No downsample step reads the raw datapoints.
+	ctx := context.NewContext()
+	results := buffer.ReadEncodedWithOptions(ctx, timeZero, timeDistantFuture,
+		ReadEncodedOptions{})
+	assertValuesEqual(t, data, results, opts)
+	ctx.Close()
+
+	tests := []struct {
+		name        string
+		aggregation DownsampleAggregation
+		expected    []float64
+	}{
+		{name: "last", aggregation: DownsampleLast, expected: []float64{59, 119, 179}},
+		{name: "mean", aggregation: DownsampleMean, expected: []float64{46, 89.5, 149.5}},
+		{name: "max", aggregation: DownsampleMax, expected: []float64{1000, 119, 179}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.NewContext()
+			defer ctx.Close()
+
+			results := buffer.ReadEncodedWithOptions(ctx, timeZero, timeDistantFuture,
+				ReadEncodedOptions{
+					DownsampleStep:        time.Minute,
+					DownsampleAggregation: test.aggregation,
+				})
+			require.Len(t, results, 1)
+			require.Len(t, results[0], 1)
+
+			var expected []value
+			for i, v := range test.expected {
+				expected = append(expected, value{start.Add(mins(float64(i))), v, xtime.Second, nil})
+			}
+			assertValuesEqual(t, expected, results, opts)
+		})
+	}
+
+	// Ensure downsampling did not mutate the buffer.
+	ctx = context.NewContext()
+	defer ctx.Close()
+	assertValuesEqual(t, data, buffer.ReadEncoded(ctx, timeZero, timeDistantFuture), opts)
+}
+
+func TestBufferWriteTruncatesTimestamps(t *testing.T) {
+	opts := newBufferTestOptions().SetWriteTimestampTruncateTo(time.Second)
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr.Add(secs(5))
+	}))
+	buffer := newDatabaseBuffer(nil).(*dbBuffer)
+	buffer.Reset(opts)
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	// The first two writes share a timestamp once truncated so the last
+	// write wins the same as any other repeated write.
+	_, err := buffer.Write(ctx, curr.Add(secs(1.2)), 1, xtime.Nanosecond, nil)
+	require.NoError(t, err)
+	_, err = buffer.Write(ctx, curr.Add(secs(1.7)), 2, xtime.Nanosecond, nil)
+	require.NoError(t, err)
+
+	datapoints := []ts.Datapoint{
+		{Timestamp: curr.Add(secs(2.5)), Value: 3},
+		{Timestamp: curr.Add(secs(3.9)), Value: 4},
+	}
+	_, errs := buffer.WriteBatch(ctx, datapoints, xtime.Nanosecond, nil)
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	// The datapoints of the caller are left as they were.
+	assert.True(t, curr.Add(secs(2.5)).Equal(datapoints[0].Timestamp))
+	assert.True(t, curr.Add(secs(3.9)).Equal(datapoints[1].Timestamp))
+
+	expected := []value{
+		{curr.Add(secs(1)), 2, xtime.Nanosecond, nil},
+		{curr.Add(secs(2)), 3, xtime.Nanosecond, nil},
+		{curr.Add(secs(3)), 4, xtime.Nanosecond, nil},
+	}
+	assertValuesEqual(t, expected, buffer.ReadEncoded(ctx, timeZero, timeDistantFuture), opts)
+}
+
+func TestBufferWriteTruncatedTimestampsCompressBetter(t *testing.T) {
+	encodedSize := func(truncateTo time.Duration) int {
+		var (
+			opts = newBufferTestOptions().SetWriteTimestampTruncateTo(truncateTo)
+			curr = time.Now().Truncate(opts.RetentionOptions().BlockSize())
+			now  time.Time
+			rng  = rand.New(rand.NewSource(0))
+		)
+		opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+			return now
+		}))
+		buffer := newDatabaseBuffer(nil).(*dbBuffer)
+		buffer.Reset(opts)
+
+		ctx := context.NewContext()
+		defer ctx.Close()
+
+		// Per second data with up to a millisecond of nanosecond jitter.
+		for i := 0; i < 100; i++ {
+			now = curr.Add(secs(float64(i)))
+			jitter := time.Duration(rng.Int63n(int64(time.Millisecond)))
+			_, err := buffer.Write(ctx, now.Add(jitter), 42, xtime.Nanosecond, nil)
+			require.NoError(t, err)
+		}
+
+		size := 0
+		for _, readers := range buffer.ReadEncoded(ctx, timeZero, timeDistantFuture) {
+			for _, reader := range readers {
+				segment, err := reader.Segment()
+				require.NoError(t, err)
+				size += segment.Len()
+			}
+		}
+		return size
+	}
+
+	jittered := encodedSize(0)
+	truncated := encodedSize(time.Second)
+	assert.True(t, truncated*2 < jittered,
+		"expected truncated size %d to be less than half of jittered size %d",
+		truncated, jittered)
+}
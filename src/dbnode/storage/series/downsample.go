@@ -0,0 +1,122 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package series
+
+import (
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/ts"
+	xtime "github.com/m3db/m3x/time"
+)
+
+// datapointEncoder encodes datapoints in timestamp order.
+type datapointEncoder interface {
+	Encode(dp ts.Datapoint, unit xtime.Unit, annotation ts.Annotation) error
+}
+
+// downsampler is a streaming reducer that encodes a single datapoint per
+// step, it relies on datapoints being written in timestamp order.
+type downsampler struct {
+	start       time.Time
+	step        time.Duration
+	aggregation DownsampleAggregation
+	encoder     encoding.Encoder
+
+	hasStep   bool
+	stepStart time.Time
+	unit      xtime.Unit
+	value     float64
+	count     int
+}
+
+func newDownsampler(
+	start time.Time,
+	opts ReadEncodedOptions,
+	encoder encoding.Encoder,
+) *downsampler {
+	return &downsampler{
+		start:       start,
+		step:        opts.DownsampleStep,
+		aggregation: opts.DownsampleAggregation,
+		encoder:     encoder,
+	}
+}
+
+// Encode adds the datapoint to its step, encoding the reduced value of
+// the previous step if the datapoint starts a new one. Annotations are
+// dropped as they do not survive aggregation.
+func (d *downsampler) Encode(
+	dp ts.Datapoint,
+	unit xtime.Unit,
+	annotation ts.Annotation,
+) error {
+	stepStart := d.stepStartOf(dp.Timestamp)
+	if d.hasStep && !stepStart.Equal(d.stepStart) {
+		if err := d.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if !d.hasStep {
+		d.hasStep = true
+		d.stepStart = stepStart
+		d.value = dp.Value
+		d.count = 1
+		d.unit = unit
+		return nil
+	}
+
+	switch d.aggregation {
+	case DownsampleMean:
+		d.value += dp.Value
+	case DownsampleMax:
+		if dp.Value > d.value {
+			d.value = dp.Value
+		}
+	default:
+		d.value = dp.Value
+	}
+	d.count++
+	d.unit = unit
+	return nil
+}
+
+// Flush encodes the reduced value of the current step if any.
+func (d *downsampler) Flush() error {
+	if !d.hasStep {
+		return nil
+	}
+
+	value := d.value
+	if d.aggregation == DownsampleMean {
+		value /= float64(d.count)
+	}
+	d.hasStep = false
+
+	dp := ts.Datapoint{Timestamp: d.stepStart, Value: value}
+	return d.encoder.Encode(dp, d.unit, nil)
+}
+
+func (d *downsampler) stepStartOf(t time.Time) time.Time {
+	elapsed := t.Sub(d.start)
+	return d.start.Add(elapsed - elapsed%d.step)
+}
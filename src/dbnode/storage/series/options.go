@@ -21,16 +21,86 @@
 package series
 
 import (
+	"errors"
+	"time"
+
 	"github.com/m3db/m3/src/dbnode/clock"
 	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/dbnode/retention"
 	"github.com/m3db/m3/src/dbnode/storage/block"
+	"github.com/m3db/m3/src/dbnode/x/xio"
 	"github.com/m3db/m3x/context"
 	"github.com/m3db/m3x/ident"
 	"github.com/m3db/m3x/instrument"
 	"github.com/m3db/m3x/pool"
 )
 
+const (
+	// defaultMaxEncodersPerBucket is the default max number of encoders
+	// a buffer bucket may hold before an inline merge is triggered, zero
+	// means unlimited.
+	defaultMaxEncodersPerBucket = 0
+
+	// defaultMergeDatapointsPerTick is the default datapoint budget a buffer
+	// bucket may spend merging encoders per tick, zero means unlimited.
+	defaultMergeDatapointsPerTick = 0
+
+	// defaultMaxAnnotationSize is the default max size in bytes of an
+	// annotation accepted on write, zero means unlimited.
+	defaultMaxAnnotationSize = 0
+
+	// defaultMergeBootstrappedAtRead is the default for whether bootstrapped
+	// blocks are left unmerged by ticks and merged at read time instead.
+	defaultMergeBootstrappedAtRead = false
+
+	// defaultBufferChecksumsInMetadata is the default for whether blocks
+	// metadata for data still in the buffer includes checksums.
+	defaultBufferChecksumsInMetadata = false
+
+	// defaultWriteTimestampTruncateTo is the default precision that write
+	// timestamps are truncated to, zero means no truncation.
+	defaultWriteTimestampTruncateTo = time.Duration(0)
+
+	// defaultValidateMetricTypes is the default for whether writes that
+	// change the metric type of a buffer bucket are rejected.
+	defaultValidateMetricTypes = false
+
+	// defaultBlockRecompressionAge is the default age past its end after
+	// which a drained block is recompressed, zero disables recompression.
+	defaultBlockRecompressionAge = time.Duration(0)
+
+	// defaultSkipCorruptReadersOnMerge is the default for whether buffer
+	// merges skip readers that fail to decode rather than failing.
+	defaultSkipCorruptReadersOnMerge = false
+
+	// defaultVerifyMergeChecksums is the default for whether buffer merges
+	// decode the merged encoder to verify it against the merged datapoints.
+	defaultVerifyMergeChecksums = false
+
+	// defaultAnnotationsEnabled is the default for whether writes may
+	// carry annotations.
+	defaultAnnotationsEnabled = true
+)
+
+var (
+	// defaultBlockReadersPoolBuckets are the default size classes of the block
+	// reader slices pool, a bucket holds a bootstrapped block and a few encoders
+	// so reads of it rarely return more than a handful of readers.
+	defaultBlockReadersPoolBuckets = []pool.Bucket{
+		{Capacity: 1, Count: 4096},
+		{Capacity: 2, Count: 4096},
+		{Capacity: 4, Count: 4096},
+	}
+)
+
+var (
+	errMaxEncodersPerBucketNegative   = errors.New("max encoders per bucket cannot be negative")
+	errMergeDatapointsPerTickNegative = errors.New("merge datapoints per tick cannot be negative")
+	errMaxAnnotationSizeNegative      = errors.New("max annotation size cannot be negative")
+	errWriteTimestampTruncateNegative = errors.New("write timestamp truncate to cannot be negative")
+	errBlockRecompressionAgeNegative  = errors.New("block recompression age cannot be negative")
+)
+
 type options struct {
 	clockOpts                     clock.Options
 	instrumentOpts                instrument.Options
@@ -40,9 +110,22 @@ type options struct {
 	contextPool                   context.Pool
 	encoderPool                   encoding.EncoderPool
 	multiReaderIteratorPool       encoding.MultiReaderIteratorPool
+	blockReadersPool              xio.BlockReadersPool
 	fetchBlockMetadataResultsPool block.FetchBlockMetadataResultsPool
 	identifierPool                ident.Pool
 	stats                         Stats
+	maxEncodersPerBucket          int
+	mergeDatapointsPerTick        int
+	maxAnnotationSize             int
+	mergeBootstrappedAtRead       bool
+	writeConflictResolution       WriteConflictResolution
+	bufferChecksumsInMetadata     bool
+	writeTimestampTruncateTo      time.Duration
+	validateMetricTypes           bool
+	blockRecompressionAge         time.Duration
+	skipCorruptReadersOnMerge     bool
+	verifyMergeChecksums          bool
+	annotationsEnabled            bool
 }
 
 // NewOptions creates new database series options
@@ -53,6 +136,8 @@ func NewOptions() Options {
 		return pool.NewBytesPool(s, nil)
 	})
 	bytesPool.Init()
+	blockReadersPool := xio.NewBlockReadersPool(defaultBlockReadersPoolBuckets)
+	blockReadersPool.Init()
 	iopts := instrument.NewOptions()
 	return &options{
 		clockOpts:                     clock.NewOptions(),
@@ -63,9 +148,22 @@ func NewOptions() Options {
 		contextPool:                   context.NewPool(context.NewOptions()),
 		encoderPool:                   encoding.NewEncoderPool(nil),
 		multiReaderIteratorPool:       encoding.NewMultiReaderIteratorPool(nil),
+		blockReadersPool:              blockReadersPool,
 		fetchBlockMetadataResultsPool: block.NewFetchBlockMetadataResultsPool(nil, 0),
 		identifierPool:                ident.NewPool(bytesPool, ident.PoolOptions{}),
 		stats:                         NewStats(iopts.MetricsScope()),
+		maxEncodersPerBucket:          defaultMaxEncodersPerBucket,
+		mergeDatapointsPerTick:        defaultMergeDatapointsPerTick,
+		maxAnnotationSize:             defaultMaxAnnotationSize,
+		mergeBootstrappedAtRead:       defaultMergeBootstrappedAtRead,
+		writeConflictResolution:       DefaultWriteConflictResolution,
+		bufferChecksumsInMetadata:     defaultBufferChecksumsInMetadata,
+		writeTimestampTruncateTo:      defaultWriteTimestampTruncateTo,
+		validateMetricTypes:           defaultValidateMetricTypes,
+		blockRecompressionAge:         defaultBlockRecompressionAge,
+		skipCorruptReadersOnMerge:     defaultSkipCorruptReadersOnMerge,
+		verifyMergeChecksums:          defaultVerifyMergeChecksums,
+		annotationsEnabled:            defaultAnnotationsEnabled,
 	}
 }
 
@@ -73,6 +171,24 @@ func (o *options) Validate() error {
 	if err := o.retentionOpts.Validate(); err != nil {
 		return err
 	}
+	if o.maxEncodersPerBucket < 0 {
+		return errMaxEncodersPerBucketNegative
+	}
+	if o.mergeDatapointsPerTick < 0 {
+		return errMergeDatapointsPerTickNegative
+	}
+	if o.maxAnnotationSize < 0 {
+		return errMaxAnnotationSizeNegative
+	}
+	if o.writeTimestampTruncateTo < 0 {
+		return errWriteTimestampTruncateNegative
+	}
+	if o.blockRecompressionAge < 0 {
+		return errBlockRecompressionAgeNegative
+	}
+	if err := ValidateWriteConflictResolution(o.writeConflictResolution); err != nil {
+		return err
+	}
 	return ValidateCachePolicy(o.cachePolicy)
 }
 
@@ -156,6 +272,16 @@ func (o *options) MultiReaderIteratorPool() encoding.MultiReaderIteratorPool {
 	return o.multiReaderIteratorPool
 }
 
+func (o *options) SetBlockReadersPool(value xio.BlockReadersPool) Options {
+	opts := *o
+	opts.blockReadersPool = value
+	return &opts
+}
+
+func (o *options) BlockReadersPool() xio.BlockReadersPool {
+	return o.blockReadersPool
+}
+
 func (o *options) SetFetchBlockMetadataResultsPool(value block.FetchBlockMetadataResultsPool) Options {
 	opts := *o
 	opts.fetchBlockMetadataResultsPool = value
@@ -185,3 +311,123 @@ func (o *options) SetStats(value Stats) Options {
 func (o *options) Stats() Stats {
 	return o.stats
 }
+
+func (o *options) SetMaxEncodersPerBucket(value int) Options {
+	opts := *o
+	opts.maxEncodersPerBucket = value
+	return &opts
+}
+
+func (o *options) MaxEncodersPerBucket() int {
+	return o.maxEncodersPerBucket
+}
+
+func (o *options) SetMergeDatapointsPerTick(value int) Options {
+	opts := *o
+	opts.mergeDatapointsPerTick = value
+	return &opts
+}
+
+func (o *options) MergeDatapointsPerTick() int {
+	return o.mergeDatapointsPerTick
+}
+
+func (o *options) SetMaxAnnotationSize(value int) Options {
+	opts := *o
+	opts.maxAnnotationSize = value
+	return &opts
+}
+
+func (o *options) MaxAnnotationSize() int {
+	return o.maxAnnotationSize
+}
+
+func (o *options) SetMergeBootstrappedAtRead(value bool) Options {
+	opts := *o
+	opts.mergeBootstrappedAtRead = value
+	return &opts
+}
+
+func (o *options) MergeBootstrappedAtRead() bool {
+	return o.mergeBootstrappedAtRead
+}
+
+func (o *options) SetWriteConflictResolution(value WriteConflictResolution) Options {
+	opts := *o
+	opts.writeConflictResolution = value
+	return &opts
+}
+
+func (o *options) WriteConflictResolution() WriteConflictResolution {
+	return o.writeConflictResolution
+}
+
+func (o *options) SetBufferChecksumsInMetadata(value bool) Options {
+	opts := *o
+	opts.bufferChecksumsInMetadata = value
+	return &opts
+}
+
+func (o *options) BufferChecksumsInMetadata() bool {
+	return o.bufferChecksumsInMetadata
+}
+
+func (o *options) SetWriteTimestampTruncateTo(value time.Duration) Options {
+	opts := *o
+	opts.writeTimestampTruncateTo = value
+	return &opts
+}
+
+func (o *options) WriteTimestampTruncateTo() time.Duration {
+	return o.writeTimestampTruncateTo
+}
+
+func (o *options) SetValidateMetricTypes(value bool) Options {
+	opts := *o
+	opts.validateMetricTypes = value
+	return &opts
+}
+
+func (o *options) ValidateMetricTypes() bool {
+	return o.validateMetricTypes
+}
+
+func (o *options) SetBlockRecompressionAge(value time.Duration) Options {
+	opts := *o
+	opts.blockRecompressionAge = value
+	return &opts
+}
+
+func (o *options) BlockRecompressionAge() time.Duration {
+	return o.blockRecompressionAge
+}
+
+func (o *options) SetSkipCorruptReadersOnMerge(value bool) Options {
+	opts := *o
+	opts.skipCorruptReadersOnMerge = value
+	return &opts
+}
+
+func (o *options) SkipCorruptReadersOnMerge() bool {
+	return o.skipCorruptReadersOnMerge
+}
+
+func (o *options) SetVerifyMergeChecksums(value bool) Options {
+	opts := *o
+	opts.verifyMergeChecksums = value
+	return &opts
+}
+
+func (o *options) VerifyMergeChecksums() bool {
+	return o.verifyMergeChecksums
+}
+
+func (o *options) SetAnnotationsEnabled(value bool) Options {
+	opts := *o
+	opts.annotationsEnabled = value
+	return &opts
+}
+
+func (o *options) AnnotationsEnabled() bool {
+	return o.annotationsEnabled
+}
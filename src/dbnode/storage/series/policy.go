@@ -26,7 +26,8 @@ import (
 )
 
 var (
-	errCachePolicyUnspecified = errors.New("series cache policy unspecified")
+	errCachePolicyUnspecified             = errors.New("series cache policy unspecified")
+	errWriteConflictResolutionUnspecified = errors.New("series write conflict resolution unspecified")
 )
 
 // CachePolicy is the series cache policy.
@@ -123,3 +124,75 @@ func (p *CachePolicy) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	*p = r
 	return nil
 }
+
+// WriteConflictResolution is the policy of which value is kept when a series
+// is written more than once at the same timestamp.
+type WriteConflictResolution uint
+
+const (
+	// LastWriteWins specifies that the value of the latest write to a
+	// timestamp is kept.
+	LastWriteWins WriteConflictResolution = iota
+	// FirstWriteWins specifies that the value of the first write to a
+	// timestamp is kept and any later writes to the timestamp are ignored.
+	FirstWriteWins
+
+	// DefaultWriteConflictResolution is the default write conflict resolution.
+	DefaultWriteConflictResolution = LastWriteWins
+)
+
+// ValidWriteConflictResolutions returns the valid write conflict resolutions.
+func ValidWriteConflictResolutions() []WriteConflictResolution {
+	return []WriteConflictResolution{LastWriteWins, FirstWriteWins}
+}
+
+func (r WriteConflictResolution) String() string {
+	switch r {
+	case LastWriteWins:
+		return "last_write_wins"
+	case FirstWriteWins:
+		return "first_write_wins"
+	}
+	return "unknown"
+}
+
+// ValidateWriteConflictResolution validates a write conflict resolution.
+func ValidateWriteConflictResolution(v WriteConflictResolution) error {
+	for _, valid := range ValidWriteConflictResolutions() {
+		if valid == v {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid series WriteConflictResolution '%d' valid types are: %v",
+		uint(v), ValidWriteConflictResolutions())
+}
+
+// ParseWriteConflictResolution parses a WriteConflictResolution from a string.
+func ParseWriteConflictResolution(str string) (WriteConflictResolution, error) {
+	var r WriteConflictResolution
+	if str == "" {
+		return r, errWriteConflictResolutionUnspecified
+	}
+	for _, valid := range ValidWriteConflictResolutions() {
+		if str == valid.String() {
+			r = valid
+			return r, nil
+		}
+	}
+	return r, fmt.Errorf("invalid series WriteConflictResolution '%s' valid types are: %v",
+		str, ValidWriteConflictResolutions())
+}
+
+// UnmarshalYAML unmarshals a WriteConflictResolution into a valid type from string.
+func (r *WriteConflictResolution) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	v, err := ParseWriteConflictResolution(str)
+	if err != nil {
+		return err
+	}
+	*r = v
+	return nil
+}
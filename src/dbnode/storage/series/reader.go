@@ -145,7 +145,10 @@ func (r Reader) readersWithBlocksMapAndBuffer(
 		case r.retriever != nil:
 			// Try to stream from disk
 			if r.retriever.IsBlockRetrievable(blockAt) {
-				streamedBlock, err := r.retriever.Stream(ctx, r.id, blockAt, r.onRetrieve)
+				// NB: Hint the remaining range of the query so the retriever
+				// can read ahead the subsequent blocks of the series.
+				streamedBlock, err := r.retriever.StreamWithReadAhead(ctx, r.id,
+					blockAt, last, r.onRetrieve)
 				if err != nil {
 					return nil, err
 				}
@@ -60,13 +60,14 @@ func TestReaderUsingRetrieverReadEncoded(t *testing.T) {
 	ctx := opts.ContextPool().Get()
 	defer ctx.Close()
 
+	last := start.Add(ropts.BlockSize())
 	retriever.EXPECT().
-		Stream(ctx, ident.NewIDMatcher("foo"),
-			start, onRetrieveBlock).
+		StreamWithReadAhead(ctx, ident.NewIDMatcher("foo"),
+			start, last, onRetrieveBlock).
 		Return(blockReaders[0], nil)
 	retriever.EXPECT().
-		Stream(ctx, ident.NewIDMatcher("foo"),
-			start.Add(ropts.BlockSize()), onRetrieveBlock).
+		StreamWithReadAhead(ctx, ident.NewIDMatcher("foo"),
+			last, last, onRetrieveBlock).
 		Return(blockReaders[1], nil)
 
 	reader := NewReaderUsingRetriever(
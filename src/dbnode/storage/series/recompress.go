@@ -0,0 +1,85 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package series
+
+import (
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
+	"github.com/m3db/m3/src/dbnode/storage/block"
+)
+
+// recompressBlock re-encodes the datapoints of a block drained from the
+// buffer choosing the optimal XOR window for every value and swaps the
+// segment of the block in place if the re-encoding is smaller, returning
+// the number of bytes saved.
+func recompressBlock(bl block.DatabaseBlock, opts Options) (int, error) {
+	before := bl.Len()
+	if before == 0 {
+		return 0, nil
+	}
+
+	// NB: The checksum is taken before streaming so that if the block is
+	// mutated concurrently, by a merge for instance, the swap is refused.
+	checksum, err := bl.Checksum()
+	if err != nil {
+		return 0, err
+	}
+
+	ctx := opts.ContextPool().Get()
+	defer ctx.Close()
+
+	stream, err := bl.Stream(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		bopts        = opts.DatabaseBlockOptions()
+		encodingOpts = encoding.NewOptions().
+				SetBytesPool(bopts.BytesPool()).
+				SetOptimizeXORWindows(true)
+		encoder = m3tsz.NewEncoder(bl.StartTime(), nil,
+			m3tsz.DefaultIntOptimizationEnabled, encodingOpts)
+		iter = bopts.ReaderIteratorPool().Get()
+	)
+	defer iter.Close()
+
+	iter.Reset(stream)
+	for iter.Next() {
+		dp, unit, annotation := iter.Current()
+		if err := encoder.Encode(dp, unit, annotation); err != nil {
+			encoder.Close()
+			return 0, err
+		}
+	}
+	if err := iter.Err(); err != nil {
+		encoder.Close()
+		return 0, err
+	}
+
+	segment := encoder.Discard()
+	after := segment.Len()
+	if !bl.ReplaceSegment(segment, checksum) {
+		segment.Finalize()
+		return 0, nil
+	}
+	return before - after, nil
+}
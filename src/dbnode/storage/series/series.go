@@ -73,6 +73,15 @@ type dbSeries struct {
 	onRetrieveBlock             block.OnRetrieveBlock
 	blockOnEvictedFromWiredList block.OnEvictedFromWiredList
 	pool                        DatabaseSeriesPool
+
+	// recompressed tracks the blocks that were already considered for
+	// recompression so they are not revisited until their data changes.
+	recompressed []recompressedBlock
+}
+
+type recompressedBlock struct {
+	start    xtime.UnixNano
+	checksum uint32
 }
 
 // NewDatabaseSeries creates a new database series
@@ -119,12 +128,24 @@ func (s *dbSeries) Tags() ident.Tags {
 	return tags
 }
 
-func (s *dbSeries) Tick() (TickResult, error) {
+func (s *dbSeries) Tick(c context.Cancellable) (TickResult, error) {
 	var r TickResult
 
 	s.Lock()
+	bufferResult, merges := s.buffer.TickAndPrepareMerges(c)
+	s.Unlock()
+
+	// Merge without holding the lock so that reads of the series are not
+	// blocked behind long merges, writes made meanwhile land in overlay
+	// encoders and only the swap of the merged encoders holds the lock.
+	for _, m := range merges {
+		m.Run(c)
+	}
 
-	bufferResult := s.buffer.Tick()
+	s.Lock()
+	if len(merges) > 0 {
+		bufferResult.mergedOutOfOrderBlocks += s.buffer.CompleteMerges(merges)
+	}
 	r.MergedOutOfOrderBlocks = bufferResult.mergedOutOfOrderBlocks
 
 	update, err := s.updateBlocksWithLock()
@@ -138,6 +159,21 @@ func (s *dbSeries) Tick() (TickResult, error) {
 
 	s.Unlock()
 
+	// Recompress without holding the lock, the blocks guard against
+	// concurrent mutation themselves when swapping their segment.
+	for _, bl := range update.recompressBlocks {
+		saved, err := recompressBlock(bl, s.opts)
+		if err != nil {
+			s.opts.InstrumentOptions().Logger().Errorf(
+				"failed to recompress block: %v", err)
+			continue
+		}
+		if saved > 0 {
+			r.RecompressedBlocks++
+			r.RecompressedBytesSaved += saved
+		}
+	}
+
 	if update.ActiveBlocks == 0 {
 		return r, ErrSeriesAllDatapointsExpired
 	}
@@ -148,6 +184,7 @@ type updateBlocksResult struct {
 	TickStatus
 	madeExpiredBlocks int
 	madeUnwiredBlocks int
+	recompressBlocks  []block.DatabaseBlock
 }
 
 func (s *dbSeries) updateBlocksWithLock() (updateBlocksResult, error) {
@@ -159,7 +196,23 @@ func (s *dbSeries) updateBlocksWithLock() (updateBlocksResult, error) {
 		cachePolicy  = s.opts.CachePolicy()
 		expireCutoff = now.Add(-ropts.RetentionPeriod()).Truncate(ropts.BlockSize())
 		wiredTimeout = ropts.BlockDataExpiryAfterNotAccessedPeriod()
+		recompressed []recompressedBlock
 	)
+	maybeRecompress := func(start time.Time, currBlock block.DatabaseBlock) {
+		checksum, ok := s.shouldRecompressWithLock(now, start, currBlock)
+		if !ok {
+			return
+		}
+		considered := recompressedBlock{
+			start:    xtime.ToUnixNano(start),
+			checksum: checksum,
+		}
+		recompressed = append(recompressed, considered)
+		if s.wasRecompressedWithLock(considered) {
+			return
+		}
+		result.recompressBlocks = append(result.recompressBlocks, currBlock)
+	}
 	for startNano, currBlock := range s.blocks.AllBlocks() {
 		start := startNano.ToTime()
 		if start.Before(expireCutoff) {
@@ -199,6 +252,7 @@ func (s *dbSeries) updateBlocksWithLock() (updateBlocksResult, error) {
 		if cachePolicy == CacheAll || retriever == nil {
 			// Never unwire
 			result.WiredBlocks++
+			maybeRecompress(start, currBlock)
 			continue
 		}
 
@@ -269,17 +323,58 @@ func (s *dbSeries) updateBlocksWithLock() (updateBlocksResult, error) {
 			if currBlock.HasMergeTarget() {
 				result.PendingMergeBlocks++
 			}
+			maybeRecompress(start, currBlock)
 		}
 	}
+	s.recompressed = recompressed
 
 	bufferStats := s.buffer.Stats()
 	result.ActiveBlocks += bufferStats.wiredBlocks
 	result.WiredBlocks += bufferStats.wiredBlocks
 	result.OpenBlocks += bufferStats.openBlocks
+	result.UnflushedBytes = s.buffer.UnflushedBytes()
+	result.BufferEncoders = bufferStats.encoders
+	result.BufferBootstrappedBlocks = bufferStats.bootstrappedBlocks
 
 	return result, nil
 }
 
+// shouldRecompressWithLock returns whether a block drained from the buffer
+// has gone cold enough to be recompressed along with its checksum. A block
+// that is skipped while a merge into it is pending is considered again on a
+// later tick once the merge has completed.
+func (s *dbSeries) shouldRecompressWithLock(
+	now, start time.Time,
+	currBlock block.DatabaseBlock,
+) (uint32, bool) {
+	age := s.opts.BlockRecompressionAge()
+	if age <= 0 || now.Before(start.Add(currBlock.BlockSize()).Add(age)) {
+		return 0, false
+	}
+	if !currBlock.IsRetrieved() ||
+		currBlock.WasRetrievedFromDisk() ||
+		currBlock.HasMergeTarget() {
+		return 0, false
+	}
+	checksum, err := currBlock.Checksum()
+	if err != nil {
+		return 0, false
+	}
+	return checksum, true
+}
+
+// wasRecompressedWithLock returns whether a block was already considered for
+// recompression, recompression keeps the checksum of a block so a changed
+// checksum means the block holds new data and should be considered again.
+func (s *dbSeries) wasRecompressedWithLock(b recompressedBlock) bool {
+	for _, r := range s.recompressed {
+		if r == b {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *dbSeries) IsEmpty() bool {
 	s.RLock()
 	blocksLen := s.blocks.Len()
@@ -291,6 +386,26 @@ func (s *dbSeries) IsEmpty() bool {
 	return false
 }
 
+func (s *dbSeries) UnflushedBytes() int {
+	s.RLock()
+	value := s.buffer.UnflushedBytes()
+	s.RUnlock()
+	return value
+}
+
+func (s *dbSeries) WriteMetadata() WriteMetadata {
+	// The buffer tracks its write metadata atomically so there is no need
+	// to take the series lock
+	return s.buffer.WriteMetadata()
+}
+
+func (s *dbSeries) BucketsInfo() []BucketInfo {
+	s.RLock()
+	infos := s.buffer.BucketsInfo()
+	s.RUnlock()
+	return infos
+}
+
 func (s *dbSeries) NumActiveBlocks() int {
 	s.RLock()
 	value := s.blocks.Len() + s.buffer.Stats().wiredBlocks
@@ -311,11 +426,23 @@ func (s *dbSeries) Write(
 	value float64,
 	unit xtime.Unit,
 	annotation []byte,
-) error {
+) (WriteResult, error) {
+	s.Lock()
+	result, err := s.buffer.Write(ctx, timestamp, value, unit, annotation)
+	s.Unlock()
+	return result, err
+}
+
+func (s *dbSeries) WriteBatch(
+	ctx context.Context,
+	datapoints []ts.Datapoint,
+	unit xtime.Unit,
+	annotations [][]byte,
+) ([]WriteResult, []error) {
 	s.Lock()
-	err := s.buffer.Write(ctx, timestamp, value, unit, annotation)
+	results, errs := s.buffer.WriteBatch(ctx, datapoints, unit, annotations)
 	s.Unlock()
-	return err
+	return results, errs
 }
 
 func (s *dbSeries) ReadEncoded(
@@ -373,6 +500,7 @@ func (s *dbSeries) FetchBlocksMetadata(
 			size     int64
 			checksum *uint32
 			lastRead time.Time
+			stats    ts.DatapointStats
 		)
 		if opts.IncludeSizes {
 			size = int64(b.Len())
@@ -387,11 +515,15 @@ func (s *dbSeries) FetchBlocksMetadata(
 		if opts.IncludeLastRead {
 			lastRead = b.LastReadTime()
 		}
+		if opts.IncludeDatapointStats {
+			stats = b.DatapointStats()
+		}
 		res.Add(block.FetchBlockMetadataResult{
-			Start:    t,
-			Size:     size,
-			Checksum: checksum,
-			LastRead: lastRead,
+			Start:          t,
+			Size:           size,
+			Checksum:       checksum,
+			LastRead:       lastRead,
+			DatapointStats: stats,
 		})
 	}
 
@@ -642,6 +774,34 @@ func (s *dbSeries) Flush(
 		return FlushOutcomeErr, errSeriesNotBootstrapped
 	}
 
+	return s.flushWithLock(ctx, blockStart, persistFn)
+}
+
+func (s *dbSeries) ForceFlush(
+	ctx context.Context,
+	blockStart time.Time,
+	persistFn persist.DataFn,
+) (FlushOutcome, error) {
+	// Need a write lock because draining the buffer mutates it.
+	s.Lock()
+	defer s.Unlock()
+
+	if s.bs != bootstrapped {
+		return FlushOutcomeErr, errSeriesNotBootstrapped
+	}
+
+	// Drain the buffer buckets that the next tick would, so that the data of
+	// block starts that can no longer be written to is rotated into blocks.
+	s.buffer.DrainAndReset()
+
+	return s.flushWithLock(ctx, blockStart, persistFn)
+}
+
+func (s *dbSeries) flushWithLock(
+	ctx context.Context,
+	blockStart time.Time,
+	persistFn persist.DataFn,
+) (FlushOutcome, error) {
 	b, exists := s.blocks.BlockAt(blockStart)
 	if !exists {
 		return FlushOutcomeBlockDoesNotExist, nil
@@ -659,14 +819,16 @@ func (s *dbSeries) Flush(
 		return FlushOutcomeErr, err
 	}
 
-	checksum, err := b.Checksum()
-	if err != nil {
-		return FlushOutcomeErr, err
-	}
-	err = persistFn(s.id, s.tags, segment, checksum)
+	// NB: Checksum the segment rather than using the block checksum since a
+	// recompressed block keeps the checksum of its original encoding and the
+	// persisted checksum is validated against the data when read back.
+	checksum := digest.SegmentChecksum(segment)
+	stats := b.DatapointStats()
+	err = persistFn(s.id, s.tags, segment, checksum, stats)
 	if err != nil {
 		return FlushOutcomeErr, err
 	}
+	s.opts.Stats().IncDatapointsWrittenToDisk(stats.Count)
 
 	return FlushOutcomeFlushedToDisk, nil
 }
@@ -676,10 +838,10 @@ func (s *dbSeries) Snapshot(
 	blockStart time.Time,
 	persistFn persist.DataFn,
 ) error {
-	// Need a write lock because the buffer Snapshot method mutates
-	// state (by performing a pro-active merge).
-	s.Lock()
-	defer s.Unlock()
+	// Only need a read lock because the buffer Snapshot method merges
+	// into a new stream and does not mutate the buffer.
+	s.RLock()
+	defer s.RUnlock()
 
 	if s.bs != bootstrapped {
 		return errSeriesNotBootstrapped
@@ -713,7 +875,8 @@ func (s *dbSeries) Snapshot(
 		return err
 	}
 
-	return persistFn(s.id, s.tags, segment, digest.SegmentChecksum(segment))
+	return persistFn(s.id, s.tags, segment, digest.SegmentChecksum(segment),
+		ts.DatapointStats{})
 }
 
 func (s *dbSeries) Close() {
@@ -785,4 +948,5 @@ func (s *dbSeries) Reset(
 	s.blockRetriever = blockRetriever
 	s.onRetrieveBlock = onRetrieveBlock
 	s.blockOnEvictedFromWiredList = onEvictedFromWiredList
+	s.recompressed = nil
 }
@@ -0,0 +1,155 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package series
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3/src/dbnode/x/xio"
+	"github.com/m3db/m3x/context"
+	"github.com/m3db/m3x/ident"
+	xtime "github.com/m3db/m3x/time"
+)
+
+const benchmarkNumWritesPerSeries = 1000
+
+func newBenchmarkSeriesWrites() (Options, []ts.Datapoint) {
+	opts := newSeriesTestOptions()
+	now := time.Now().Truncate(opts.RetentionOptions().BlockSize()).
+		Add(opts.RetentionOptions().BlockSize() / 2)
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return now
+	}))
+
+	start := now.Add(-opts.RetentionOptions().BufferPast() / 2)
+	datapoints := make([]ts.Datapoint, benchmarkNumWritesPerSeries)
+	for i := range datapoints {
+		datapoints[i] = ts.Datapoint{
+			Timestamp: start.Add(time.Duration(i) * time.Millisecond),
+			Value:     float64(i),
+		}
+	}
+	return opts, datapoints
+}
+
+func BenchmarkSeriesWrite(b *testing.B) {
+	opts, datapoints := newBenchmarkSeriesWrites()
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts)
+		for _, dp := range datapoints {
+			_, err := series.Write(ctx, dp.Timestamp, dp.Value, xtime.Millisecond, nil)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkSeriesWriteBatch(b *testing.B) {
+	opts, datapoints := newBenchmarkSeriesWrites()
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts)
+		_, errs := series.WriteBatch(ctx, datapoints, xtime.Millisecond, nil)
+		for _, err := range errs {
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkSeriesReadEncoded(b *testing.B) {
+	for _, bench := range []struct {
+		name string
+		pool xio.BlockReadersPool
+	}{
+		{name: "pooled", pool: xio.NewBlockReadersPool(defaultBlockReadersPoolBuckets)},
+		// NB: a pool without buckets allocates a new slice on every read.
+		{name: "unpooled", pool: xio.NewBlockReadersPool(nil)},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			bench.pool.Init()
+			opts, datapoints := newBenchmarkSeriesWrites()
+			opts = opts.SetBlockReadersPool(bench.pool)
+
+			ctx := context.NewContext()
+			series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts)
+			for _, dp := range datapoints {
+				_, err := series.Write(ctx, dp.Timestamp, dp.Value, xtime.Millisecond, nil)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+			ctx.Close()
+
+			var (
+				blockSize = opts.RetentionOptions().BlockSize()
+				start     = datapoints[0].Timestamp.Truncate(blockSize)
+				end       = datapoints[len(datapoints)-1].Timestamp.Add(blockSize)
+			)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				ctx := context.NewContext()
+				if _, err := series.ReadEncoded(ctx, start, end); err != nil {
+					b.Fatal(err)
+				}
+				ctx.Close()
+			}
+		})
+	}
+}
+
+func BenchmarkBufferBucketMerge(b *testing.B) {
+	for _, bench := range []struct {
+		name   string
+		verify bool
+	}{
+		{name: "unverified", verify: false},
+		{name: "verified", verify: true},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			opts := newBufferTestOptions().SetVerifyMergeChecksums(bench.verify)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				bucket := newTestBufferBucketWithInterleavedData(b, opts,
+					benchmarkNumWritesPerSeries)
+				b.StartTimer()
+				if _, err := bucket.merge(nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -24,6 +24,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -44,6 +48,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
 )
 
 func newSeriesTestOptions() Options {
@@ -105,14 +110,15 @@ func TestSeriesWriteFlush(t *testing.T) {
 	for _, v := range data {
 		curr = v.timestamp
 		ctx := context.NewContext()
-		assert.NoError(t, series.Write(ctx, v.timestamp, v.value, xtime.Second, v.annotation))
+		_, err := series.Write(ctx, v.timestamp, v.value, xtime.Second, v.annotation)
+		assert.NoError(t, err)
 		ctx.Close()
 	}
 
 	assert.Equal(t, true, series.buffer.NeedsDrain())
 
 	// Tick the series which should cause a drain
-	_, err = series.Tick()
+	_, err = series.Tick(context.NewNoOpCanncellable())
 	assert.NoError(t, err)
 
 	assert.Equal(t, false, series.buffer.NeedsDrain())
@@ -133,6 +139,69 @@ func TestSeriesWriteFlush(t *testing.T) {
 	}}, opts)
 }
 
+func TestSeriesWriteAmplificationStats(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	opts := newSeriesTestOptions().SetStats(NewStats(scope))
+	ropts := opts.RetentionOptions()
+	curr := time.Now().Truncate(ropts.BlockSize())
+	start := curr
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
+	_, err := series.Bootstrap(nil)
+	require.NoError(t, err)
+
+	assertCounter := func(name string, expected int64) {
+		counters := scope.Snapshot().Counters()
+		require.Contains(t, counters, name+"+")
+		assert.Equal(t, expected, counters[name+"+"].Value(), name)
+	}
+
+	// The last write is out of order so lands in a second encoder.
+	ctx := context.NewContext()
+	for _, v := range []value{
+		{start.Add(secs(30)), 1, xtime.Second, nil},
+		{start.Add(secs(40)), 2, xtime.Second, nil},
+		{start.Add(secs(35)), 3, xtime.Second, nil},
+	} {
+		if v.timestamp.After(curr) {
+			curr = v.timestamp
+		}
+		_, err := series.Write(ctx, v.timestamp, v.value, v.unit, v.annotation)
+		require.NoError(t, err)
+	}
+	ctx.Close()
+	assertCounter("series.datapoints-encoded-first-time", 3)
+
+	// Ticking merges the encoders of the open block.
+	r, err := series.Tick(context.NewNoOpCanncellable())
+	require.NoError(t, err)
+	assert.Equal(t, 1, r.BufferEncoders)
+	assert.Equal(t, 0, r.BufferBootstrappedBlocks)
+	assertCounter("series.datapoints-reencoded-in-merge", 3)
+
+	// Draining the already merged encoder does not encode it again.
+	curr = start.Add(ropts.BlockSize() + ropts.BufferPast() + time.Second)
+	r, err = series.Tick(context.NewNoOpCanncellable())
+	require.NoError(t, err)
+	assert.Equal(t, 0, r.BufferEncoders)
+	assertCounter("series.datapoints-reencoded-in-merge", 3)
+
+	ctx = context.NewContext()
+	defer ctx.Close()
+	persistFn := func(_ ident.ID, _ ident.Tags, _ ts.Segment, _ uint32, _ ts.DatapointStats) error {
+		return nil
+	}
+	outcome, err := series.Flush(ctx, start, persistFn)
+	require.NoError(t, err)
+	assert.Equal(t, FlushOutcomeFlushedToDisk, outcome)
+
+	assertCounter("series.datapoints-encoded-first-time", 3)
+	assertCounter("series.datapoints-reencoded-in-merge", 3)
+	assertCounter("series.datapoints-written-to-disk", 3)
+}
+
 func TestSeriesWriteFlushRead(t *testing.T) {
 	opts := newSeriesTestOptions()
 	curr := time.Now().Truncate(opts.RetentionOptions().BlockSize())
@@ -155,7 +224,8 @@ func TestSeriesWriteFlushRead(t *testing.T) {
 	for _, v := range data {
 		curr = v.timestamp
 		ctx := context.NewContext()
-		assert.NoError(t, series.Write(ctx, v.timestamp, v.value, xtime.Second, v.annotation))
+		_, err := series.Write(ctx, v.timestamp, v.value, xtime.Second, v.annotation)
+		assert.NoError(t, err)
 		ctx.Close()
 	}
 
@@ -219,7 +289,7 @@ func TestSeriesFlush(t *testing.T) {
 
 	inputs := []error{errors.New("some error"), nil}
 	for _, input := range inputs {
-		persistFn := func(_ ident.ID, _ ident.Tags, _ ts.Segment, _ uint32) error {
+		persistFn := func(_ ident.ID, _ ident.Tags, _ ts.Segment, _ uint32, _ ts.DatapointStats) error {
 			return input
 		}
 		ctx := context.NewContext()
@@ -234,12 +304,57 @@ func TestSeriesFlush(t *testing.T) {
 	}
 }
 
+func TestSeriesForceFlush(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newSeriesTestOptions()
+	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
+	_, err := series.Bootstrap(nil)
+	assert.NoError(t, err)
+	buffer := NewMockdatabaseBuffer(ctrl)
+	series.buffer = buffer
+
+	var (
+		flushTime = time.Unix(7200, 0)
+		head      = checked.NewBytes([]byte{0x1, 0x2}, nil)
+		tail      = checked.NewBytes([]byte{0x3, 0x4}, nil)
+		segment   = ts.NewSegment(head, tail, ts.FinalizeNone)
+		persisted []ts.Segment
+	)
+	persistFn := func(_ ident.ID, _ ident.Tags, segment ts.Segment, _ uint32, _ ts.DatapointStats) error {
+		persisted = append(persisted, segment)
+		return nil
+	}
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	// A block that is still buffered is not flushed.
+	buffer.EXPECT().DrainAndReset()
+	outcome, err := series.ForceFlush(ctx, flushTime, persistFn)
+	require.NoError(t, err)
+	require.Equal(t, FlushOutcomeBlockDoesNotExist, outcome)
+	require.Empty(t, persisted)
+
+	// Once drained into a block the block is flushed.
+	block := opts.DatabaseBlockOptions().DatabaseBlockPool().Get()
+	block.Reset(flushTime, time.Hour, segment)
+	buffer.EXPECT().DrainAndReset().Do(func() {
+		series.blocks.AddBlock(block)
+	})
+	outcome, err = series.ForceFlush(ctx, flushTime, persistFn)
+	require.NoError(t, err)
+	require.Equal(t, FlushOutcomeFlushedToDisk, outcome)
+	require.Len(t, persisted, 1)
+}
+
 func TestSeriesTickEmptySeries(t *testing.T) {
 	opts := newSeriesTestOptions()
 	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
 	_, err := series.Bootstrap(nil)
 	assert.NoError(t, err)
-	_, err = series.Tick()
+	_, err = series.Tick(context.NewNoOpCanncellable())
 	require.Equal(t, ErrSeriesAllDatapointsExpired, err)
 }
 
@@ -253,14 +368,234 @@ func TestSeriesTickDrainAndResetBuffer(t *testing.T) {
 	assert.NoError(t, err)
 	buffer := NewMockdatabaseBuffer(ctrl)
 	series.buffer = buffer
-	buffer.EXPECT().Tick().Return(bufferTickResult{})
+	buffer.EXPECT().TickAndPrepareMerges(gomock.Any()).Return(bufferTickResult{}, nil)
 	buffer.EXPECT().Stats().Return(bufferStats{openBlocks: 1, wiredBlocks: 1})
-	r, err := series.Tick()
+	buffer.EXPECT().UnflushedBytes().Return(16)
+	r, err := series.Tick(context.NewNoOpCanncellable())
 	require.NoError(t, err)
 	assert.Equal(t, 1, r.ActiveBlocks)
 	assert.Equal(t, 1, r.WiredBlocks)
 	assert.Equal(t, 0, r.UnwiredBlocks)
 	assert.Equal(t, 1, r.OpenBlocks)
+	assert.Equal(t, 16, r.UnflushedBytes)
+}
+
+func TestSeriesConcurrentWritesDuringDrain(t *testing.T) {
+	var (
+		nowLock sync.RWMutex
+		now     = time.Unix(1477929600, 0)
+	)
+	nowFn := func() time.Time {
+		nowLock.RLock()
+		value := now
+		nowLock.RUnlock()
+		return value
+	}
+	// Concurrent writers race to the series lock and write out of order,
+	// bound the number of encoders so writes and merges stay cheap
+	opts := newSeriesTestOptions().
+		SetCachePolicy(CacheAll).
+		SetMaxEncodersPerBucket(4)
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(nowFn))
+	start := nowFn()
+
+	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
+	_, err := series.Bootstrap(nil)
+	require.NoError(t, err)
+
+	var (
+		blockSize     = opts.RetentionOptions().BlockSize()
+		numTicks      = int(2 * blockSize / time.Second)
+		numWriters    = 4
+		writesPerTick = int64(20)
+		ticks         int64
+		counter       int64
+		wg            sync.WaitGroup
+		done          = make(chan struct{})
+		written       = make([][]float64, numWriters)
+	)
+
+	// Drain several buckets as the clock moves forward while writes are
+	// in flight
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(done)
+		for i := 0; i < numTicks; i++ {
+			for atomic.LoadInt64(&counter) < int64(i)*writesPerTick {
+				time.Sleep(10 * time.Microsecond)
+			}
+			nowLock.Lock()
+			now = now.Add(time.Second)
+			nowLock.Unlock()
+			series.Tick(context.NewNoOpCanncellable())
+			atomic.AddInt64(&ticks, 1)
+		}
+	}()
+
+	for i := 0; i < numWriters; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx := context.NewContext()
+			defer ctx.Close()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				// Spread the writes across the ticks
+				if atomic.LoadInt64(&counter) >= (atomic.LoadInt64(&ticks)+1)*writesPerTick {
+					time.Sleep(10 * time.Microsecond)
+					continue
+				}
+
+				// Each write has a unique timestamp so no write is upserted
+				n := atomic.AddInt64(&counter, 1)
+				timestamp := nowFn().Truncate(time.Second).Add(time.Duration(n))
+				value := float64(n)
+				if _, err := series.Write(ctx, timestamp, value, xtime.Nanosecond, nil); err != nil {
+					// Rejected writes were never accepted so cannot be lost
+					continue
+				}
+				written[i] = append(written[i], value)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	// Drain all remaining buckets
+	nowLock.Lock()
+	now = now.Add(blockSize + opts.RetentionOptions().BufferPast() + time.Second)
+	nowLock.Unlock()
+	_, err = series.Tick(context.NewNoOpCanncellable())
+	require.NoError(t, err)
+	require.True(t, series.buffer.IsEmpty())
+	require.True(t, series.blocks.Len() > 1)
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	results, err := series.ReadEncoded(ctx, start, nowFn())
+	require.NoError(t, err)
+	values, err := decodedValues(results, opts)
+	require.NoError(t, err)
+
+	read := make(map[float64]struct{}, len(values))
+	for _, v := range values {
+		read[v.value] = struct{}{}
+	}
+
+	numWritten := 0
+	for _, values := range written {
+		for _, value := range values {
+			numWritten++
+			_, ok := read[value]
+			require.True(t, ok, fmt.Sprintf("missing value %v", value))
+		}
+	}
+	assert.True(t, numWritten > 0)
+	assert.Equal(t, numWritten, len(values))
+}
+
+// TestSeriesTickMergesWithoutBlockingWrites checks that merges run by ticks
+// without the series lock held neither lose nor duplicate datapoints written
+// and upserted concurrently, whether the writes interleave with preparing,
+// running or swapping in a merge.
+func TestSeriesTickMergesWithoutBlockingWrites(t *testing.T) {
+	opts := newSeriesTestOptions()
+	curr := time.Now().Truncate(opts.RetentionOptions().BlockSize()).Add(mins(1))
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
+	_, err := series.Bootstrap(nil)
+	require.NoError(t, err)
+
+	const numDatapoints = 10000
+	var (
+		start    = curr.Add(-5 * time.Second)
+		expected = make([]value, numDatapoints)
+		order    = rand.New(rand.NewSource(0)).Perm(numDatapoints)
+		wg       sync.WaitGroup
+		done     int32
+	)
+	for i := range expected {
+		expected[i] = value{start.Add(time.Duration(i) * time.Millisecond),
+			float64(i), xtime.Millisecond, nil}
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer atomic.StoreInt32(&done, 1)
+		ctx := context.NewContext()
+		defer ctx.Close()
+		// Writes in random order so that ticks have encoders to merge,
+		// upserting every tenth datapoint with a later write.
+		for n, i := range order {
+			v := expected[i]
+			_, err := series.Write(ctx, v.timestamp, v.value, v.unit, nil)
+			assert.NoError(t, err)
+			if n%10 == 0 {
+				upsert := order[n/2]
+				expected[upsert].value += 0.5
+				v := expected[upsert]
+				_, err := series.Write(ctx, v.timestamp, v.value, v.unit, nil)
+				assert.NoError(t, err)
+			}
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for atomic.LoadInt32(&done) == 0 {
+			_, err := series.Tick(context.NewNoOpCanncellable())
+			if err != ErrSeriesAllDatapointsExpired {
+				assert.NoError(t, err)
+			}
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for atomic.LoadInt32(&done) == 0 {
+			ctx := context.NewContext()
+			results, err := series.ReadEncoded(ctx, start, curr.Add(time.Minute))
+			assert.NoError(t, err)
+			_, err = decodedValues(results, opts)
+			assert.NoError(t, err)
+			ctx.Close()
+		}
+	}()
+
+	wg.Wait()
+
+	assertSeriesValues := func() {
+		ctx := context.NewContext()
+		defer ctx.Close()
+		results, err := series.ReadEncoded(ctx, start, curr.Add(time.Minute))
+		require.NoError(t, err)
+		assertValuesEqual(t, expected, results, opts)
+	}
+	assertSeriesValues()
+
+	// A final tick merges everything into a single encoder.
+	_, err = series.Tick(context.NewNoOpCanncellable())
+	require.NoError(t, err)
+	for _, bucket := range series.BucketsInfo() {
+		if !bucket.Start.Equal(start.Truncate(opts.RetentionOptions().BlockSize())) {
+			continue
+		}
+		assert.Equal(t, 1, len(bucket.Encoders))
+	}
+	assertSeriesValues()
 }
 
 func TestSeriesTickNeedsBlockExpiry(t *testing.T) {
@@ -290,9 +625,10 @@ func TestSeriesTickNeedsBlockExpiry(t *testing.T) {
 	require.Equal(t, 2, series.blocks.Len())
 	buffer := NewMockdatabaseBuffer(ctrl)
 	series.buffer = buffer
-	buffer.EXPECT().Tick().Return(bufferTickResult{})
+	buffer.EXPECT().TickAndPrepareMerges(gomock.Any()).Return(bufferTickResult{}, nil)
 	buffer.EXPECT().Stats().Return(bufferStats{openBlocks: 1, wiredBlocks: 1})
-	r, err := series.Tick()
+	buffer.EXPECT().UnflushedBytes().Return(16)
+	r, err := series.Tick(context.NewNoOpCanncellable())
 	require.NoError(t, err)
 	require.Equal(t, 2, r.ActiveBlocks)
 	require.Equal(t, 2, r.WiredBlocks)
@@ -304,6 +640,144 @@ func TestSeriesTickNeedsBlockExpiry(t *testing.T) {
 	require.True(t, exists)
 }
 
+func TestSeriesTickRecompressesColdBlocks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newSeriesTestOptions().
+		SetBlockRecompressionAge(time.Minute)
+	ropts := opts.RetentionOptions()
+	curr := time.Now().Truncate(ropts.BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
+	_, err := series.Bootstrap(nil)
+	assert.NoError(t, err)
+
+	b, data := newRecompressibleTestBlock(t, opts, curr.Add(-2*ropts.BlockSize()))
+	series.blocks.AddBlock(b)
+	before := b.Len()
+	checksum, err := b.Checksum()
+	require.NoError(t, err)
+
+	buffer := NewMockdatabaseBuffer(ctrl)
+	series.buffer = buffer
+	buffer.EXPECT().TickAndPrepareMerges(gomock.Any()).Return(bufferTickResult{}, nil).Times(2)
+	buffer.EXPECT().Stats().Return(bufferStats{}).Times(2)
+	buffer.EXPECT().UnflushedBytes().Return(0).Times(2)
+
+	r, err := series.Tick(context.NewNoOpCanncellable())
+	require.NoError(t, err)
+	require.Equal(t, 1, r.RecompressedBlocks)
+	require.True(t, r.RecompressedBytesSaved > 0)
+	require.Equal(t, before-r.RecompressedBytesSaved, b.Len())
+
+	// The checksum is kept so the block still matches other replicas.
+	recompressedChecksum, err := b.Checksum()
+	require.NoError(t, err)
+	require.Equal(t, checksum, recompressedChecksum)
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+	stream, err := b.Stream(ctx)
+	require.NoError(t, err)
+	iter := m3tsz.NewReaderIterator(stream, m3tsz.DefaultIntOptimizationEnabled, nil)
+	defer iter.Close()
+	i := 0
+	for iter.Next() {
+		dp, _, _ := iter.Current()
+		require.True(t, data[i].Timestamp.Equal(dp.Timestamp))
+		require.Equal(t, data[i].Value, dp.Value)
+		i++
+	}
+	require.NoError(t, iter.Err())
+	require.Equal(t, len(data), i)
+
+	// Blocks are only considered for recompression once.
+	r, err = series.Tick(context.NewNoOpCanncellable())
+	require.NoError(t, err)
+	require.Equal(t, 0, r.RecompressedBlocks)
+}
+
+func TestSeriesTickRecompressesBlockAfterPendingMerge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newSeriesTestOptions().
+		SetBlockRecompressionAge(time.Minute)
+	ropts := opts.RetentionOptions()
+	curr := time.Now().Truncate(ropts.BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
+	_, err := series.Bootstrap(nil)
+	assert.NoError(t, err)
+
+	// The older block has a pending merge while the newer one is recompressed.
+	olderStart := curr.Add(-3 * ropts.BlockSize())
+	older, _ := newRecompressibleTestBlock(t, opts, olderStart)
+	mergeSegment := ts.NewSegment(checked.NewBytes(nil, nil), nil, ts.FinalizeNone)
+	require.NoError(t, older.Merge(block.NewDatabaseBlock(olderStart,
+		ropts.BlockSize(), mergeSegment, opts.DatabaseBlockOptions())))
+	series.blocks.AddBlock(older)
+	newer, _ := newRecompressibleTestBlock(t, opts, curr.Add(-2*ropts.BlockSize()))
+	series.blocks.AddBlock(newer)
+
+	buffer := NewMockdatabaseBuffer(ctrl)
+	series.buffer = buffer
+	buffer.EXPECT().TickAndPrepareMerges(gomock.Any()).Return(bufferTickResult{}, nil).Times(2)
+	buffer.EXPECT().Stats().Return(bufferStats{}).Times(2)
+	buffer.EXPECT().UnflushedBytes().Return(0).Times(2)
+
+	r, err := series.Tick(context.NewNoOpCanncellable())
+	require.NoError(t, err)
+	require.Equal(t, 1, r.RecompressedBlocks)
+
+	// Streaming the block completes the merge.
+	ctx := context.NewContext()
+	_, err = older.Stream(ctx)
+	require.NoError(t, err)
+	ctx.Close()
+	require.False(t, older.HasMergeTarget())
+
+	r, err = series.Tick(context.NewNoOpCanncellable())
+	require.NoError(t, err)
+	require.Equal(t, 1, r.RecompressedBlocks)
+}
+
+// newRecompressibleTestBlock returns a block that alternates between a wide
+// and a narrow XOR so that the narrow XORs are written in the window of the
+// wide ones unless windows are optimized.
+func newRecompressibleTestBlock(
+	t *testing.T,
+	opts Options,
+	blockStart time.Time,
+) (block.DatabaseBlock, []ts.Datapoint) {
+	bits := uint64(0x3FF0000000000001)
+	var data []ts.Datapoint
+	for i := 0; i < 20; i++ {
+		if i%2 == 0 {
+			bits ^= 0x0008000000000001
+		} else {
+			bits ^= 0x0000000000000100
+		}
+		data = append(data, ts.Datapoint{
+			Timestamp: blockStart.Add(time.Duration(i) * time.Second),
+			Value:     math.Float64frombits(bits),
+		})
+	}
+	encoder := opts.EncoderPool().Get()
+	encoder.Reset(blockStart, 0)
+	for _, dp := range data {
+		require.NoError(t, encoder.Encode(dp, xtime.Second, nil))
+	}
+	b := block.NewDatabaseBlock(blockStart, opts.RetentionOptions().BlockSize(),
+		encoder.Discard(), opts.DatabaseBlockOptions())
+	return b, data
+}
+
 func TestSeriesTickNotRetrieved(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -327,7 +801,7 @@ func TestSeriesTickNotRetrieved(t *testing.T) {
 
 	series.blocks.AddBlock(b)
 
-	tickResult, err := series.Tick()
+	tickResult, err := series.Tick(context.NewNoOpCanncellable())
 	require.NoError(t, err)
 	require.Equal(t, 1, tickResult.UnwiredBlocks)
 }
@@ -362,7 +836,7 @@ func TestSeriesTickRecentlyRead(t *testing.T) {
 
 	blockRetriever.EXPECT().IsBlockRetrievable(curr).Return(true)
 
-	tickResult, err := series.Tick()
+	tickResult, err := series.Tick(context.NewNoOpCanncellable())
 	require.NoError(t, err)
 	require.Equal(t, 0, tickResult.UnwiredBlocks)
 	require.Equal(t, 1, tickResult.PendingMergeBlocks)
@@ -378,7 +852,7 @@ func TestSeriesTickRecentlyRead(t *testing.T) {
 
 	blockRetriever.EXPECT().IsBlockRetrievable(curr).Return(true)
 
-	tickResult, err = series.Tick()
+	tickResult, err = series.Tick(context.NewNoOpCanncellable())
 	require.NoError(t, err)
 	require.Equal(t, 1, tickResult.UnwiredBlocks)
 	require.Equal(t, 0, tickResult.PendingMergeBlocks)
@@ -391,7 +865,7 @@ func TestSeriesTickRecentlyRead(t *testing.T) {
 	series.blocks.AddBlock(b)
 	blockRetriever.EXPECT().IsBlockRetrievable(curr).Return(false)
 
-	tickResult, err = series.Tick()
+	tickResult, err = series.Tick(context.NewNoOpCanncellable())
 	require.NoError(t, err)
 	require.Equal(t, 0, tickResult.UnwiredBlocks)
 	require.Equal(t, 1, tickResult.PendingMergeBlocks)
@@ -427,7 +901,7 @@ func TestSeriesTickCacheLRU(t *testing.T) {
 
 	blockRetriever.EXPECT().IsBlockRetrievable(curr).Return(true)
 
-	tickResult, err := series.Tick()
+	tickResult, err := series.Tick(context.NewNoOpCanncellable())
 	require.NoError(t, err)
 	require.Equal(t, 1, tickResult.UnwiredBlocks)
 	require.Equal(t, 0, tickResult.PendingMergeBlocks)
@@ -442,7 +916,7 @@ func TestSeriesTickCacheLRU(t *testing.T) {
 
 	blockRetriever.EXPECT().IsBlockRetrievable(curr).Return(true)
 
-	tickResult, err = series.Tick()
+	tickResult, err = series.Tick(context.NewNoOpCanncellable())
 	require.NoError(t, err)
 	require.Equal(t, 0, tickResult.UnwiredBlocks)
 	require.Equal(t, 1, tickResult.PendingMergeBlocks)
@@ -464,7 +938,7 @@ func TestSeriesTickCacheLRU(t *testing.T) {
 	_, expiredBlockExists := series.blocks.BlockAt(curr.Add(-2 * retentionPeriod))
 	require.Equal(t, true, expiredBlockExists)
 
-	tickResult, err = series.Tick()
+	tickResult, err = series.Tick(context.NewNoOpCanncellable())
 	require.NoError(t, err)
 	require.Equal(t, 0, tickResult.UnwiredBlocks)
 	require.Equal(t, 1, tickResult.PendingMergeBlocks)
@@ -504,7 +978,7 @@ func TestSeriesTickCacheAllMetadata(t *testing.T) {
 
 	blockRetriever.EXPECT().IsBlockRetrievable(curr).Return(true)
 
-	tickResult, err := series.Tick()
+	tickResult, err := series.Tick(context.NewNoOpCanncellable())
 	require.NoError(t, err)
 	require.Equal(t, 0, tickResult.UnwiredBlocks)
 	require.Equal(t, 1, tickResult.PendingMergeBlocks)
@@ -523,7 +997,7 @@ func TestSeriesTickCacheAllMetadata(t *testing.T) {
 
 	blockRetriever.EXPECT().IsBlockRetrievable(curr).Return(true)
 
-	tickResult, err = series.Tick()
+	tickResult, err = series.Tick(context.NewNoOpCanncellable())
 	require.NoError(t, err)
 	require.Equal(t, 1, tickResult.UnwiredBlocks)
 	require.Equal(t, 0, tickResult.PendingMergeBlocks)
@@ -536,7 +1010,7 @@ func TestSeriesTickCacheAllMetadata(t *testing.T) {
 	series.blocks.AddBlock(b)
 	blockRetriever.EXPECT().IsBlockRetrievable(curr).Return(false)
 
-	tickResult, err = series.Tick()
+	tickResult, err = series.Tick(context.NewNoOpCanncellable())
 	require.NoError(t, err)
 	require.Equal(t, 0, tickResult.UnwiredBlocks)
 	require.Equal(t, 1, tickResult.PendingMergeBlocks)
@@ -568,7 +1042,7 @@ func TestSeriesTickCacheNone(t *testing.T) {
 	series.blocks.AddBlock(b)
 	blockRetriever.EXPECT().IsBlockRetrievable(curr).Return(true)
 
-	tickResult, err := series.Tick()
+	tickResult, err := series.Tick(context.NewNoOpCanncellable())
 	require.NoError(t, err)
 	require.Equal(t, 1, tickResult.UnwiredBlocks)
 	require.Equal(t, 0, tickResult.PendingMergeBlocks)
@@ -580,7 +1054,7 @@ func TestSeriesTickCacheNone(t *testing.T) {
 	series.blocks.AddBlock(b)
 	blockRetriever.EXPECT().IsBlockRetrievable(curr).Return(false)
 
-	tickResult, err = series.Tick()
+	tickResult, err = series.Tick(context.NewNoOpCanncellable())
 	require.NoError(t, err)
 	require.Equal(t, 0, tickResult.UnwiredBlocks)
 	require.Equal(t, 1, tickResult.PendingMergeBlocks)
@@ -804,7 +1278,8 @@ func TestSeriesOutOfOrderWritesAndRotate(t *testing.T) {
 		value := startValue
 
 		for i := 0; i < numPoints; i++ {
-			require.NoError(t, series.Write(ctx, start, value, xtime.Second, nil))
+			_, err := series.Write(ctx, start, value, xtime.Second, nil)
+			require.NoError(t, err)
 			expected = append(expected, ts.Datapoint{Timestamp: start, Value: value})
 			start = start.Add(10 * time.Second)
 			value = value + 1.0
@@ -814,7 +1289,8 @@ func TestSeriesOutOfOrderWritesAndRotate(t *testing.T) {
 		start = now
 		value = startValue
 		for i := 0; i < numPoints/2; i++ {
-			require.NoError(t, series.Write(ctx, start, value, xtime.Second, nil))
+			_, err := series.Write(ctx, start, value, xtime.Second, nil)
+			require.NoError(t, err)
 			start = start.Add(10 * time.Second)
 			value = value + 1.0
 		}
@@ -872,9 +1348,12 @@ func TestSeriesWriteReadFromTheSameBucket(t *testing.T) {
 	ctx := context.NewContext()
 	defer ctx.Close()
 
-	assert.NoError(t, series.Write(ctx, curr.Add(-3*time.Minute), 1, xtime.Second, nil))
-	assert.NoError(t, series.Write(ctx, curr.Add(-2*time.Minute), 2, xtime.Second, nil))
-	assert.NoError(t, series.Write(ctx, curr.Add(-1*time.Minute), 3, xtime.Second, nil))
+	_, err = series.Write(ctx, curr.Add(-3*time.Minute), 1, xtime.Second, nil)
+	assert.NoError(t, err)
+	_, err = series.Write(ctx, curr.Add(-2*time.Minute), 2, xtime.Second, nil)
+	assert.NoError(t, err)
+	_, err = series.Write(ctx, curr.Add(-1*time.Minute), 3, xtime.Second, nil)
+	assert.NoError(t, err)
 
 	results, err := series.ReadEncoded(ctx, curr.Add(-5*time.Minute), curr.Add(time.Minute))
 	require.NoError(t, err)
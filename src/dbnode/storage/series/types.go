@@ -28,6 +28,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/retention"
 	"github.com/m3db/m3/src/dbnode/storage/block"
+	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/xio"
 	"github.com/m3db/m3x/context"
 	"github.com/m3db/m3x/ident"
@@ -48,17 +49,31 @@ type DatabaseSeries interface {
 	// Tags return the tags of the series
 	Tags() ident.Tags
 
-	// Tick executes any updates to ensure buffer drains, blocks are flushed, etc
-	Tick() (TickResult, error)
+	// Tick executes any updates to ensure buffer drains, blocks are flushed, etc,
+	// any merges in progress are abandoned if the tick is cancelled
+	Tick(c context.Cancellable) (TickResult, error)
 
-	// Write writes a new value
+	// Write writes a new value and returns whether the write was accepted
+	// as a new datapoint or was a no-op
 	Write(
 		ctx context.Context,
 		timestamp time.Time,
 		value float64,
 		unit xtime.Unit,
 		annotation []byte,
-	) error
+	) (WriteResult, error)
+
+	// WriteBatch writes a batch of values taking the series lock once,
+	// annotations may be nil or otherwise must be the same length as the
+	// datapoints. Returns the result and error of each write by the index
+	// of its datapoint. If write timestamps are truncated the timestamps of
+	// the datapoints are truncated in place.
+	WriteBatch(
+		ctx context.Context,
+		datapoints []ts.Datapoint,
+		unit xtime.Unit,
+		annotations [][]byte,
+	) ([]WriteResult, []error)
 
 	// ReadEncoded reads encoded blocks
 	ReadEncoded(
@@ -85,6 +100,17 @@ type DatabaseSeries interface {
 	// NumActiveBlocks returns the number of active blocks the series currently holds
 	NumActiveBlocks() int
 
+	// UnflushedBytes returns an estimate of the bytes the series holds in
+	// its buffer that have not yet been drained
+	UnflushedBytes() int
+
+	// BucketsInfo returns a snapshot of the state of the buffer buckets
+	BucketsInfo() []BucketInfo
+
+	// WriteMetadata returns the last write time and number of datapoints
+	// buffered by the series without taking the series lock
+	WriteMetadata() WriteMetadata
+
 	// IsBootstrapped returns whether the series is bootstrapped or not
 	IsBootstrapped() bool
 
@@ -94,6 +120,14 @@ type DatabaseSeries interface {
 	// Flush flushes the data blocks of this series for a given start time
 	Flush(ctx context.Context, blockStart time.Time, persistFn persist.DataFn) (FlushOutcome, error)
 
+	// ForceFlush drains any buffered data that can no longer be written to and
+	// flushes the data block of this series for a given start time
+	ForceFlush(
+		ctx context.Context,
+		blockStart time.Time,
+		persistFn persist.DataFn,
+	) (FlushOutcome, error)
+
 	// Snapshot snapshots the buffer buckets of this series for any data that has
 	// not been rotated into a block yet
 	Snapshot(ctx context.Context, blockStart time.Time, persistFn persist.DataFn) error
@@ -112,6 +146,17 @@ type DatabaseSeries interface {
 	)
 }
 
+// WriteResult describes the outcome of a write.
+type WriteResult struct {
+	// WroteNewDatapoint is false when the write was a no-op because the
+	// datapoint matched the timestamp and value of an existing datapoint.
+	WroteNewDatapoint bool
+
+	// Upserted is true when the write replaced the value of an existing
+	// datapoint with the same timestamp.
+	Upserted bool
+}
+
 // FetchBlocksMetadataOptions encapsulates block fetch metadata options
 // and specifies a few series specific options too.
 type FetchBlocksMetadataOptions struct {
@@ -122,6 +167,31 @@ type FetchBlocksMetadataOptions struct {
 	IncludeCachedBlocks bool
 }
 
+// DownsampleAggregation is the aggregation used to reduce the datapoints
+// of each step when downsampling a read.
+type DownsampleAggregation int
+
+const (
+	// DownsampleLast keeps the last datapoint of each step.
+	DownsampleLast DownsampleAggregation = iota
+	// DownsampleMean averages the datapoints of each step.
+	DownsampleMean
+	// DownsampleMax keeps the largest datapoint of each step.
+	DownsampleMax
+)
+
+// ReadEncodedOptions are options for reading encoded series data.
+type ReadEncodedOptions struct {
+	// DownsampleStep when positive downsamples the data read to a single
+	// datapoint per step, with steps aligned to the start of each block
+	// and each datapoint timestamped at the start of its step.
+	DownsampleStep time.Duration
+
+	// DownsampleAggregation is the aggregation used to reduce the
+	// datapoints of each step.
+	DownsampleAggregation DownsampleAggregation
+}
+
 // QueryableBlockRetriever is a block retriever that can tell if a block
 // is retrievable or not for a given start time.
 type QueryableBlockRetriever interface {
@@ -144,6 +214,45 @@ type TickStatus struct {
 	UnwiredBlocks int
 	// PendingMergeBlocks is the number of blocks pending merges
 	PendingMergeBlocks int
+	// UnflushedBytes is the estimated number of bytes held in the buffer
+	UnflushedBytes int
+	// BufferEncoders is the number of encoders held in the buffer
+	BufferEncoders int
+	// BufferBootstrappedBlocks is the number of bootstrapped blocks held in
+	// the buffer waiting to be merged
+	BufferBootstrappedBlocks int
+}
+
+// BucketInfo is a snapshot of the state of a buffer bucket, used for debugging
+type BucketInfo struct {
+	// Start is the block start of the bucket
+	Start time.Time `json:"start"`
+	// Encoders describes each of the in order encoders of the bucket
+	Encoders []EncoderInfo `json:"encoders"`
+	// BootstrappedBlocks is the number of bootstrapped blocks held by the bucket
+	BootstrappedBlocks int `json:"bootstrappedBlocks"`
+	// Drained is whether the bucket has been drained
+	Drained bool `json:"drained"`
+	// LastRead is the last time the bucket was read, zero if never read
+	LastRead time.Time `json:"lastRead"`
+}
+
+// WriteMetadata is the write metadata of a series, used to estimate the
+// number of actively written series without the index
+type WriteMetadata struct {
+	// LastWrite is the time of the last accepted write, zero if never written
+	LastWrite time.Time
+	// NumEncoded is the number of datapoints encoded in the buffer, best
+	// effort between ticks
+	NumEncoded int
+}
+
+// EncoderInfo is a snapshot of the state of a buffer bucket encoder
+type EncoderInfo struct {
+	// Len is the length in bytes of the encoded stream
+	Len int `json:"len"`
+	// LastWriteAt is the timestamp of the last datapoint written
+	LastWriteAt time.Time `json:"lastWriteAt"`
 }
 
 // TickResult is a set of results from a tick
@@ -155,6 +264,11 @@ type TickResult struct {
 	MadeUnwiredBlocks int
 	// MergedOutOfOrderBlocks is count of blocks merged from out of order streams
 	MergedOutOfOrderBlocks int
+	// RecompressedBlocks is count of blocks whose segment was just replaced
+	// by a smaller re-encoding
+	RecompressedBlocks int
+	// RecompressedBytesSaved is count of bytes saved by recompressing blocks
+	RecompressedBytesSaved int
 }
 
 // DatabaseSeriesAllocate allocates a database series for a pool
@@ -244,6 +358,12 @@ type Options interface {
 	// MultiReaderIteratorPool returns the multiReaderIteratorPool
 	MultiReaderIteratorPool() encoding.MultiReaderIteratorPool
 
+	// SetBlockReadersPool sets the pool of the block reader slices returned by reads
+	SetBlockReadersPool(value xio.BlockReadersPool) Options
+
+	// BlockReadersPool returns the pool of the block reader slices returned by reads
+	BlockReadersPool() xio.BlockReadersPool
+
 	// SetFetchBlockMetadataResultsPool sets the fetchBlockMetadataResultsPool
 	SetFetchBlockMetadataResultsPool(value block.FetchBlockMetadataResultsPool) Options
 
@@ -261,18 +381,197 @@ type Options interface {
 
 	// Stats returns the configured Stats.
 	Stats() Stats
+
+	// SetMaxEncodersPerBucket sets the max number of encoders a buffer bucket
+	// may hold before the encoders are merged inline on write, zero means
+	// unlimited.
+	SetMaxEncodersPerBucket(value int) Options
+
+	// MaxEncodersPerBucket returns the max number of encoders a buffer bucket
+	// may hold before the encoders are merged inline on write, zero means
+	// unlimited.
+	MaxEncodersPerBucket() int
+
+	// SetMergeDatapointsPerTick sets the max number of datapoints a buffer
+	// bucket encodes while merging encoders during a single tick, any
+	// remaining merge work resumes on the next tick, zero means unlimited.
+	SetMergeDatapointsPerTick(value int) Options
+
+	// MergeDatapointsPerTick returns the max number of datapoints a buffer
+	// bucket encodes while merging encoders during a single tick, any
+	// remaining merge work resumes on the next tick, zero means unlimited.
+	MergeDatapointsPerTick() int
+
+	// SetMaxAnnotationSize sets the max size in bytes of an annotation
+	// accepted on write, zero means unlimited.
+	SetMaxAnnotationSize(value int) Options
+
+	// MaxAnnotationSize returns the max size in bytes of an annotation
+	// accepted on write, zero means unlimited.
+	MaxAnnotationSize() int
+
+	// SetMergeBootstrappedAtRead sets whether buffer buckets leave their
+	// bootstrapped blocks unmerged during ticks and inline merges, merging
+	// only encoders, so that the bootstrapped blocks are instead merged with
+	// the encoders at read time and when drained.
+	SetMergeBootstrappedAtRead(value bool) Options
+
+	// MergeBootstrappedAtRead returns whether buffer buckets leave their
+	// bootstrapped blocks unmerged during ticks and inline merges, merging
+	// only encoders, so that the bootstrapped blocks are instead merged with
+	// the encoders at read time and when drained.
+	MergeBootstrappedAtRead() bool
+
+	// SetWriteConflictResolution sets which value is kept when a series is
+	// written more than once at the same timestamp.
+	SetWriteConflictResolution(value WriteConflictResolution) Options
+
+	// WriteConflictResolution returns which value is kept when a series is
+	// written more than once at the same timestamp.
+	WriteConflictResolution() WriteConflictResolution
+
+	// SetBufferChecksumsInMetadata sets whether blocks metadata for data
+	// still in the buffer includes checksums when requested, this requires
+	// merging the buffered data into a temporary stream so is expensive.
+	SetBufferChecksumsInMetadata(value bool) Options
+
+	// BufferChecksumsInMetadata returns whether blocks metadata for data
+	// still in the buffer includes checksums when requested, this requires
+	// merging the buffered data into a temporary stream so is expensive.
+	BufferChecksumsInMetadata() bool
+
+	// SetWriteTimestampTruncateTo sets the precision that the timestamps of
+	// writes are truncated to before being buffered, datapoints that share a
+	// timestamp once truncated are resolved as any other repeated write,
+	// zero means no truncation.
+	SetWriteTimestampTruncateTo(value time.Duration) Options
+
+	// WriteTimestampTruncateTo returns the precision that the timestamps of
+	// writes are truncated to before being buffered, datapoints that share a
+	// timestamp once truncated are resolved as any other repeated write,
+	// zero means no truncation.
+	WriteTimestampTruncateTo() time.Duration
+
+	// SetValidateMetricTypes sets whether writes with an annotation carrying
+	// a metric type different to the one of earlier writes to the same
+	// buffer bucket are rejected.
+	SetValidateMetricTypes(value bool) Options
+
+	// ValidateMetricTypes returns whether writes with an annotation carrying
+	// a metric type different to the one of earlier writes to the same
+	// buffer bucket are rejected.
+	ValidateMetricTypes() bool
+
+	// SetBlockRecompressionAge sets the age past its end after which a block
+	// drained from the buffer is re-encoded with a higher effort encoding
+	// and swapped in place if smaller, zero disables recompression.
+	SetBlockRecompressionAge(value time.Duration) Options
+
+	// BlockRecompressionAge returns the age past its end after which a block
+	// drained from the buffer is re-encoded with a higher effort encoding
+	// and swapped in place if smaller, zero disables recompression.
+	BlockRecompressionAge() time.Duration
+
+	// SetSkipCorruptReadersOnMerge sets whether merges of buffer buckets skip
+	// the bootstrapped blocks and encoders that fail to decode and report
+	// them, rather than failing the merge.
+	SetSkipCorruptReadersOnMerge(value bool) Options
+
+	// SkipCorruptReadersOnMerge returns whether merges of buffer buckets skip
+	// the bootstrapped blocks and encoders that fail to decode and report
+	// them, rather than failing the merge.
+	SkipCorruptReadersOnMerge() bool
+
+	// SetVerifyMergeChecksums sets whether merges of buffer buckets decode
+	// the merged encoder and verify the count and checksum of the datapoints
+	// it holds against those that were encoded, failing the merge and leaving
+	// the bucket untouched if they diverge. This roughly doubles the cost of
+	// a merge so is disabled by default.
+	SetVerifyMergeChecksums(value bool) Options
+
+	// VerifyMergeChecksums returns whether merges of buffer buckets decode
+	// the merged encoder and verify the count and checksum of the datapoints
+	// it holds against those that were encoded, failing the merge and leaving
+	// the bucket untouched if they diverge. This roughly doubles the cost of
+	// a merge so is disabled by default.
+	VerifyMergeChecksums() bool
+
+	// SetAnnotationsEnabled sets whether writes may carry annotations, when
+	// disabled writes with a non-empty annotation are rejected.
+	SetAnnotationsEnabled(value bool) Options
+
+	// AnnotationsEnabled returns whether writes may carry annotations, when
+	// disabled writes with a non-empty annotation are rejected.
+	AnnotationsEnabled() bool
+}
+
+// ReadErrorSource is the source of a reader that failed to decode.
+type ReadErrorSource int
+
+const (
+	// ReadErrorSourceBootstrapped is a bootstrapped block of a buffer bucket.
+	ReadErrorSourceBootstrapped ReadErrorSource = iota
+	// ReadErrorSourceEncoder is an encoder of a buffer bucket.
+	ReadErrorSourceEncoder
+)
+
+// String returns the string representation of the read error source.
+func (s ReadErrorSource) String() string {
+	switch s {
+	case ReadErrorSourceBootstrapped:
+		return "bootstrapped"
+	case ReadErrorSourceEncoder:
+		return "encoder"
+	}
+	return "unknown"
+}
+
+// ReadError is a reader that failed to decode and was skipped by a merge.
+type ReadError struct {
+	BlockStart time.Time
+	Source     ReadErrorSource
+	Err        error
 }
 
 // Stats is passed down from namespace/shard to avoid allocations per series.
 type Stats struct {
-	encoderCreated tally.Counter
+	encoderCreated      tally.Counter
+	duplicateNoOpWrites tally.Counter
+	upsertWrites        tally.Counter
+	annotationSizes     tally.Histogram
+	mergeDuration       tally.Timer
+	encodersAtMerge     tally.Histogram
+	mergedDatapoints    tally.Histogram
+	metricTypeChanges   tally.Counter
+	abandonedMerges     tally.Counter
+	skippedReaders      tally.Counter
+	checksumMismatches  tally.Counter
+	encodedFirstTime    tally.Counter
+	reencodedInMerge    tally.Counter
+	writtenToDisk       tally.Counter
 }
 
 // NewStats returns a new Stats for the provided scope.
 func NewStats(scope tally.Scope) Stats {
 	subScope := scope.SubScope("series")
 	return Stats{
-		encoderCreated: subScope.Counter("encoder-created"),
+		encoderCreated:      subScope.Counter("encoder-created"),
+		duplicateNoOpWrites: subScope.Counter("duplicate-noop-writes"),
+		upsertWrites:        subScope.Counter("upsert-writes"),
+		annotationSizes: subScope.Histogram("annotation-size",
+			tally.MustMakeExponentialValueBuckets(16, 2, 16)),
+		mergeDuration: subScope.Timer("merge-duration"),
+		encodersAtMerge: subScope.Histogram("merge-encoders-per-bucket",
+			tally.MustMakeLinearValueBuckets(0, 1, 16)),
+		mergedDatapoints: subScope.Histogram("merge-datapoints",
+			tally.MustMakeExponentialValueBuckets(1, 2, 20)),
+		metricTypeChanges:  subScope.Counter("metric-type-changes"),
+		abandonedMerges:    subScope.Counter("merge-abandoned"),
+		skippedReaders:     subScope.Counter("merge-skipped-corrupt-readers"),
+		checksumMismatches: subScope.Counter("merge-checksum-mismatches"),
+		encodedFirstTime:   subScope.Counter("datapoints-encoded-first-time"),
+		reencodedInMerge:   subScope.Counter("datapoints-reencoded-in-merge"),
+		writtenToDisk:      subScope.Counter("datapoints-written-to-disk"),
 	}
 }
 
@@ -280,3 +579,74 @@ func NewStats(scope tally.Scope) Stats {
 func (s Stats) IncCreatedEncoders() {
 	s.encoderCreated.Inc(1)
 }
+
+// IncDuplicateNoOpWrites incs the DuplicateNoOpWrites stat.
+func (s Stats) IncDuplicateNoOpWrites() {
+	s.duplicateNoOpWrites.Inc(1)
+}
+
+// IncUpsertWrites incs the UpsertWrites stat.
+func (s Stats) IncUpsertWrites() {
+	s.upsertWrites.Inc(1)
+}
+
+// RecordAnnotationSize records the size of a written annotation.
+func (s Stats) RecordAnnotationSize(size int) {
+	s.annotationSizes.RecordValue(float64(size))
+}
+
+// RecordMergeDuration records the time taken to merge a buffer bucket.
+func (s Stats) RecordMergeDuration(value time.Duration) {
+	s.mergeDuration.Record(value)
+}
+
+// RecordEncodersPerBucketAtMerge records the number of encoders a buffer
+// bucket held when merged.
+func (s Stats) RecordEncodersPerBucketAtMerge(value int) {
+	s.encodersAtMerge.RecordValue(float64(value))
+}
+
+// RecordMergedDatapoints records the number of datapoints encoded by a
+// buffer bucket merge.
+func (s Stats) RecordMergedDatapoints(value int) {
+	s.mergedDatapoints.RecordValue(float64(value))
+}
+
+// IncMetricTypeChanges incs the MetricTypeChanges stat.
+func (s Stats) IncMetricTypeChanges() {
+	s.metricTypeChanges.Inc(1)
+}
+
+// IncAbandonedMerges incs the AbandonedMerges stat.
+func (s Stats) IncAbandonedMerges() {
+	s.abandonedMerges.Inc(1)
+}
+
+// IncSkippedCorruptReaders incs the SkippedCorruptReaders stat.
+func (s Stats) IncSkippedCorruptReaders(value int) {
+	s.skippedReaders.Inc(int64(value))
+}
+
+// IncMergeChecksumMismatches incs the MergeChecksumMismatches stat, the
+// number of merges failed as the merged encoder did not verify.
+func (s Stats) IncMergeChecksumMismatches() {
+	s.checksumMismatches.Inc(1)
+}
+
+// IncDatapointsEncodedFirstTime incs the DatapointsEncodedFirstTime stat,
+// the number of datapoints encoded when written to the buffer.
+func (s Stats) IncDatapointsEncodedFirstTime() {
+	s.encodedFirstTime.Inc(1)
+}
+
+// IncDatapointsReencodedInMerge incs the DatapointsReencodedInMerge stat,
+// the number of datapoints encoded again by buffer bucket merges.
+func (s Stats) IncDatapointsReencodedInMerge(value int) {
+	s.reencodedInMerge.Inc(int64(value))
+}
+
+// IncDatapointsWrittenToDisk incs the DatapointsWrittenToDisk stat, the
+// number of datapoints of flushed blocks whose datapoint stats are known.
+func (s Stats) IncDatapointsWrittenToDisk(value int) {
+	s.writtenToDisk.Inc(int64(value))
+}
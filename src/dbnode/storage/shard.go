@@ -27,6 +27,7 @@ import (
 	"io"
 	"math"
 	"sort"
+	"strconv"
 	"sync"
 	"time"
 
@@ -163,6 +164,9 @@ type dbShard struct {
 	tickWg                   *sync.WaitGroup
 	runtimeOptsListenClosers []xclose.SimpleCloser
 	currRuntimeOptions       dbShardRuntimeOptions
+	tickPacer                *dbShardTickPacer
+	readBreaker              *dbShardCircuitBreaker
+	writeBreaker             *dbShardCircuitBreaker
 	logger                   xlog.Logger
 	metrics                  dbShardMetrics
 	newSeriesBootstrapped    bool
@@ -191,10 +195,22 @@ type dbShardMetrics struct {
 	insertAsyncWriteErrors        tally.Counter
 	seriesBootstrapBlocksToBuffer tally.Counter
 	seriesBootstrapBlocksMerged   tally.Counter
+	seriesWrittenLastMinute       tally.Gauge
+	seriesWrittenLast5Minutes     tally.Gauge
+	seriesWrittenLastHour         tally.Gauge
+	bufferedDatapoints            tally.Gauge
 }
 
-func newDatabaseShardMetrics(scope tally.Scope) dbShardMetrics {
+func newDatabaseShardMetrics(
+	namespace ident.ID,
+	shard uint32,
+	scope tally.Scope,
+) dbShardMetrics {
 	seriesBootstrapScope := scope.SubScope("series-bootstrap")
+	writeRecencyScope := scope.SubScope("write-recency").Tagged(map[string]string{
+		"namespace": namespace.String(),
+		"shard":     strconv.Itoa(int(shard)),
+	})
 	return dbShardMetrics{
 		create:       scope.Counter("create"),
 		close:        scope.Counter("close"),
@@ -211,6 +227,44 @@ func newDatabaseShardMetrics(scope tally.Scope) dbShardMetrics {
 		}).Counter("insert-async.errors"),
 		seriesBootstrapBlocksToBuffer: seriesBootstrapScope.Counter("blocks-to-buffer"),
 		seriesBootstrapBlocksMerged:   seriesBootstrapScope.Counter("blocks-merged"),
+		seriesWrittenLastMinute: writeRecencyScope.Tagged(map[string]string{
+			"window": "1m",
+		}).Gauge("series"),
+		seriesWrittenLast5Minutes: writeRecencyScope.Tagged(map[string]string{
+			"window": "5m",
+		}).Gauge("series"),
+		seriesWrittenLastHour: writeRecencyScope.Tagged(map[string]string{
+			"window": "1h",
+		}).Gauge("series"),
+		bufferedDatapoints: writeRecencyScope.Gauge("buffered-datapoints"),
+	}
+}
+
+// seriesWriteRecency counts the series of a shard by how recently they were
+// last written, used to estimate the number of actively written series
+// without the inverted index.
+type seriesWriteRecency struct {
+	lastMinute         int
+	last5Minutes       int
+	lastHour           int
+	bufferedDatapoints int
+}
+
+func (r *seriesWriteRecency) add(now time.Time, meta series.WriteMetadata) {
+	r.bufferedDatapoints += meta.NumEncoded
+	if meta.LastWrite.IsZero() {
+		return
+	}
+	sinceWrite := now.Sub(meta.LastWrite)
+	if sinceWrite > time.Hour {
+		return
+	}
+	r.lastHour++
+	if sinceWrite <= 5*time.Minute {
+		r.last5Minutes++
+	}
+	if sinceWrite <= time.Minute {
+		r.lastMinute++
 	}
 }
 
@@ -274,11 +328,16 @@ func newDatabaseShard(
 		contextPool:        opts.ContextPool(),
 		flushState:         newShardFlushState(),
 		tickWg:             &sync.WaitGroup{},
+		tickPacer:          newDatabaseShardTickPacer(scope.SubScope("tick-pacing")),
 		logger:             opts.InstrumentOptions().Logger(),
-		metrics:            newDatabaseShardMetrics(scope),
+		metrics:            newDatabaseShardMetrics(namespaceMetadata.ID(), shard, scope),
 	}
 	s.insertQueue = newDatabaseShardInsertQueue(s.insertSeriesBatch,
-		s.nowFn, scope)
+		s.releaseDroppedInsert, s.nowFn, scope)
+	s.readBreaker = newDatabaseShardCircuitBreaker(dbShardCircuitBreakerReads,
+		namespaceMetadata.ID(), shard, s.nowFn, s.logger, scope)
+	s.writeBreaker = newDatabaseShardCircuitBreaker(dbShardCircuitBreakerWrites,
+		namespaceMetadata.ID(), shard, s.nowFn, s.logger, scope)
 
 	registerRuntimeOptionsListener := func(listener runtime.OptionsListener) {
 		elem := opts.RuntimeOptionsManager().RegisterListener(listener)
@@ -322,6 +381,10 @@ func (s *dbShard) SetRuntimeOptions(value runtime.Options) {
 		tickSleepPerSeries:       value.TickPerSeriesSleepDuration(),
 	}
 	s.Unlock()
+	s.tickPacer.SetPacing(value.TickAdaptivePacing(),
+		value.TickSeriesBatchSize(), value.TickPerSeriesSleepDuration())
+	s.readBreaker.SetOptions(value.ShardCircuitBreaker())
+	s.writeBreaker.SetOptions(value.ShardCircuitBreaker())
 }
 
 func (s *dbShard) ID() uint32 {
@@ -345,6 +408,18 @@ func (s *dbShard) Stream(
 	return s.DatabaseBlockRetriever.Stream(ctx, s.shard, id, blockStart, onRetrieve)
 }
 
+// StreamWithReadAhead implements series.QueryableBlockRetriever
+func (s *dbShard) StreamWithReadAhead(
+	ctx context.Context,
+	id ident.ID,
+	blockStart time.Time,
+	readAheadEnd time.Time,
+	onRetrieve block.OnRetrieveBlock,
+) (xio.BlockReader, error) {
+	return s.DatabaseBlockRetriever.StreamWithReadAhead(ctx, s.shard, id,
+		blockStart, readAheadEnd, onRetrieve)
+}
+
 // IsBlockRetrievable implements series.QueryableBlockRetriever
 func (s *dbShard) IsBlockRetrievable(blockStart time.Time) bool {
 	flushState := s.FlushState(blockStart)
@@ -576,7 +651,9 @@ func (s *dbShard) isClosingWithLock() bool {
 
 func (s *dbShard) Tick(c context.Cancellable, tickStart time.Time) (tickResult, error) {
 	s.removeAnyFlushStatesTooEarly(tickStart)
-	return s.tickAndExpire(c, tickPolicyRegular)
+	r, err := s.tickAndExpire(c, tickPolicyRegular)
+	r.newSeriesBacklog = s.insertQueue.BacklogLen()
+	return r, err
 }
 
 func (s *dbShard) tickAndExpire(
@@ -615,14 +692,20 @@ func (s *dbShard) tickAndExpire(
 	var (
 		r                             tickResult
 		terminatedTickingDueToClosing bool
-		i                             int
+		sinceSleep                    int
 		slept                         time.Duration
 		expired                       []*lookup.Entry
+		writeRecency                  seriesWriteRecency
+		now                           = s.nowFn()
 	)
 	s.RLock()
 	tickSleepBatch := s.currRuntimeOptions.tickSleepSeriesBatchSize
 	tickSleepPerSeries := s.currRuntimeOptions.tickSleepPerSeries
 	s.RUnlock()
+	adaptivePacing := s.tickPacer.Enabled()
+	if adaptivePacing {
+		tickSleepBatch, tickSleepPerSeries = s.tickPacer.Next()
+	}
 	s.forEachShardEntryBatch(func(currEntries []*lookup.Entry) bool {
 		// re-using `expired` to amortize allocs, still need to reset it
 		// to be safe for re-use.
@@ -631,7 +714,7 @@ func (s *dbShard) tickAndExpire(
 		}
 		expired = expired[:0]
 		for _, entry := range currEntries {
-			if i > 0 && i%tickSleepBatch == 0 {
+			if sinceSleep >= tickSleepBatch {
 				// NB(xichen): if the tick is cancelled, we bail out immediately.
 				// The cancellation check is performed on every batch of entries
 				// instead of every entry to reduce load.
@@ -645,10 +728,15 @@ func (s *dbShard) tickAndExpire(
 					terminatedTickingDueToClosing = true
 					return false
 				}
-				// Throttle the tick
-				sleepFor := time.Duration(tickSleepBatch) * tickSleepPerSeries
+				// Throttle the tick, when adaptively paced the pacing is first
+				// adjusted by the write latency observed during the batch.
+				if adaptivePacing {
+					tickSleepBatch, tickSleepPerSeries = s.tickPacer.Next()
+				}
+				sleepFor := time.Duration(sinceSleep) * tickSleepPerSeries
 				s.sleepFn(sleepFor)
 				slept += sleepFor
+				sinceSleep = 0
 			}
 
 			var (
@@ -657,7 +745,7 @@ func (s *dbShard) tickAndExpire(
 			)
 			switch policy {
 			case tickPolicyRegular:
-				result, err = entry.Series.Tick()
+				result, err = entry.Series.Tick(c)
 			case tickPolicyCloseShard:
 				err = series.ErrSeriesAllDatapointsExpired
 			}
@@ -669,16 +757,22 @@ func (s *dbShard) tickAndExpire(
 				if err != nil {
 					r.errors++
 				}
+				writeRecency.add(now, entry.Series.WriteMetadata())
 			}
 			r.activeBlocks += result.ActiveBlocks
 			r.openBlocks += result.OpenBlocks
 			r.wiredBlocks += result.WiredBlocks
 			r.unwiredBlocks += result.UnwiredBlocks
 			r.pendingMergeBlocks += result.PendingMergeBlocks
+			r.unflushedBytes += result.UnflushedBytes
+			r.bufferEncoders += result.BufferEncoders
+			r.bufferBootstrapped += result.BufferBootstrappedBlocks
 			r.madeExpiredBlocks += result.MadeExpiredBlocks
 			r.madeUnwiredBlocks += result.MadeUnwiredBlocks
 			r.mergedOutOfOrderBlocks += result.MergedOutOfOrderBlocks
-			i++
+			r.recompressedBlocks += result.RecompressedBlocks
+			r.recompressedBytesSaved += result.RecompressedBytesSaved
+			sinceSleep++
 		}
 
 		// Purge any series requiring purging.
@@ -697,9 +791,20 @@ func (s *dbShard) tickAndExpire(
 		return tickResult{}, errShardClosingTickTerminated
 	}
 
+	if policy == tickPolicyRegular && !c.IsCancelled() {
+		s.reportWriteRecency(writeRecency)
+	}
+
 	return r, nil
 }
 
+func (s *dbShard) reportWriteRecency(r seriesWriteRecency) {
+	s.metrics.seriesWrittenLastMinute.Update(float64(r.lastMinute))
+	s.metrics.seriesWrittenLast5Minutes.Update(float64(r.last5Minutes))
+	s.metrics.seriesWrittenLastHour.Update(float64(r.lastHour))
+	s.metrics.bufferedDatapoints.Update(float64(r.bufferedDatapoints))
+}
+
 // NB(prateek): purgeExpiredSeries requires that all entries passed to it have at least one reader/writer,
 // i.e. have a readWriteCount of at least 1.
 // Currently, this function is only called by the lambda inside `tickAndExpire`'s `forEachShardEntryBatch`
@@ -754,8 +859,145 @@ func (s *dbShard) WriteTagged(
 	unit xtime.Unit,
 	annotation []byte,
 ) error {
-	return s.writeAndIndex(ctx, id, tags, timestamp,
+	probe, err := s.writeBreaker.Allow()
+	if err != nil {
+		return err
+	}
+	err = s.writeAndIndex(ctx, id, tags, timestamp,
 		value, unit, annotation, true)
+	s.writeBreaker.Record(probe, err)
+	return err
+}
+
+func (s *dbShard) WriteTaggedBatch(
+	ctx context.Context,
+	id ident.ID,
+	tags ident.TagIterator,
+	datapoints []ts.Datapoint,
+	unit xtime.Unit,
+	annotations [][]byte,
+) ([]error, error) {
+	probe, err := s.writeBreaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	errs, err := s.writeTaggedBatch(ctx, id, tags, datapoints, unit, annotations)
+	if err == nil {
+		s.writeBreaker.Record(probe, firstShardCircuitBreakerFailure(errs))
+	} else {
+		s.writeBreaker.Record(probe, err)
+	}
+	return errs, err
+}
+
+func (s *dbShard) writeTaggedBatch(
+	ctx context.Context,
+	id ident.ID,
+	tags ident.TagIterator,
+	datapoints []ts.Datapoint,
+	unit xtime.Unit,
+	annotations [][]byte,
+) ([]error, error) {
+	errs := make([]error, len(datapoints))
+	if len(datapoints) == 0 {
+		return errs, nil
+	}
+
+	if truncateTo := s.seriesOpts.WriteTimestampTruncateTo(); truncateTo > 0 {
+		// NB: Index and commit log the timestamps as the series buffers them,
+		// truncating a copy as the datapoints belong to the caller.
+		truncated := make([]ts.Datapoint, len(datapoints))
+		for i, dp := range datapoints {
+			dp.Timestamp = dp.Timestamp.Truncate(truncateTo)
+			truncated[i] = dp
+		}
+		datapoints = truncated
+	}
+
+	entry, opts, err := s.tryRetrieveWritableSeries(id)
+	if err != nil {
+		return nil, err
+	}
+
+	first := 0
+	if entry == nil {
+		// Insert and index the series with the first write then batch the rest
+		// of the writes if the series was inserted synchronously
+		errs[0] = s.writeAndIndex(ctx, id, tags, datapoints[0].Timestamp,
+			datapoints[0].Value, unit, batchAnnotation(annotations, 0), true)
+		first = 1
+
+		entry, opts, err = s.tryRetrieveWritableSeries(id)
+		if err != nil {
+			return nil, err
+		}
+		if entry == nil {
+			// Series insert is still pending, write each datapoint individually
+			for i := first; i < len(datapoints); i++ {
+				errs[i] = s.writeAndIndex(ctx, id, tags, datapoints[i].Timestamp,
+					datapoints[i].Value, unit, batchAnnotation(annotations, i), true)
+			}
+			return errs, nil
+		}
+	}
+
+	var batchAnnotations [][]byte
+	if len(annotations) > 0 {
+		batchAnnotations = annotations[first:]
+	}
+	var (
+		sampleWrite = s.tickPacer.SampleWrite()
+		writeStart  time.Time
+	)
+	if sampleWrite {
+		writeStart = s.nowFn()
+	}
+	_, batchErrs := entry.Series.WriteBatch(ctx, datapoints[first:],
+		unit, batchAnnotations)
+	if sampleWrite {
+		s.tickPacer.RecordWriteLatency(s.nowFn().Sub(writeStart))
+	}
+	copy(errs[first:], batchErrs)
+
+	for i := first; i < len(datapoints); i++ {
+		if errs[i] != nil {
+			continue
+		}
+		blockStart := s.reverseIndex.BlockStartForWriteTime(datapoints[i].Timestamp)
+		if entry.NeedsIndexUpdate(blockStart) {
+			errs[i] = s.insertSeriesForIndexingAsyncBatched(entry,
+				datapoints[i].Timestamp, opts.writeNewSeriesAsync)
+		}
+	}
+
+	// Load series metadata before decrementing the writer count, see
+	// writeAndIndex for why taking a ref to the series ID here is safe
+	series := commitlog.Series{
+		UniqueIndex: entry.Index,
+		Namespace:   s.namespace.ID(),
+		ID:          entry.Series.ID(),
+		Tags:        entry.Series.Tags(),
+		Shard:       s.shard,
+	}
+	entry.DecrementReaderWriterCount()
+
+	// NB: no-op writes are still appended to the commit log, see writeAndIndex.
+	for i := first; i < len(datapoints); i++ {
+		if errs[i] != nil {
+			continue
+		}
+		errs[i] = s.commitLogWriter.Write(ctx, series, datapoints[i],
+			unit, batchAnnotation(annotations, i))
+	}
+
+	return errs, nil
+}
+
+func batchAnnotation(annotations [][]byte, i int) []byte {
+	if i < len(annotations) {
+		return annotations[i]
+	}
+	return nil
 }
 
 func (s *dbShard) Write(
@@ -766,8 +1008,14 @@ func (s *dbShard) Write(
 	unit xtime.Unit,
 	annotation []byte,
 ) error {
-	return s.writeAndIndex(ctx, id, ident.EmptyTagIterator, timestamp,
+	probe, err := s.writeBreaker.Allow()
+	if err != nil {
+		return err
+	}
+	err = s.writeAndIndex(ctx, id, ident.EmptyTagIterator, timestamp,
 		value, unit, annotation, false)
+	s.writeBreaker.Record(probe, err)
+	return err
 }
 
 func (s *dbShard) writeAndIndex(
@@ -780,6 +1028,12 @@ func (s *dbShard) writeAndIndex(
 	annotation []byte,
 	shouldReverseIndex bool,
 ) error {
+	if truncateTo := s.seriesOpts.WriteTimestampTruncateTo(); truncateTo > 0 {
+		// NB: Index and commit log the timestamp as the series buffers it so
+		// that bootstrapping from the commit log restores the same datapoints.
+		timestamp = timestamp.Truncate(truncateTo)
+	}
+
 	// Prepare write
 	entry, opts, err := s.tryRetrieveWritableSeries(id)
 	if err != nil {
@@ -823,7 +1077,20 @@ func (s *dbShard) writeAndIndex(
 	)
 	if writable {
 		// Perform write
-		err = entry.Series.Write(ctx, timestamp, value, unit, annotation)
+		var (
+			sampleWrite = s.tickPacer.SampleWrite()
+			writeStart  time.Time
+		)
+		if sampleWrite {
+			writeStart = s.nowFn()
+		}
+		// NB: the write is appended to the commit log even if it was a no-op
+		// for the buffer, the earlier identical write may never have made it
+		// into the commit log if its enqueue failed or it was dropped.
+		_, err = entry.Series.Write(ctx, timestamp, value, unit, annotation)
+		if sampleWrite {
+			s.tickPacer.RecordWriteLatency(s.nowFn().Sub(writeStart))
+		}
 		// Load series metadata before decrementing the writer count
 		// to ensure this metadata is snapshotted at a consistent state
 		// NB(r): We explicitly do not place the series ID back into a
@@ -895,6 +1162,20 @@ func (s *dbShard) ReadEncoded(
 	ctx context.Context,
 	id ident.ID,
 	start, end time.Time,
+) ([][]xio.BlockReader, error) {
+	probe, err := s.readBreaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.readEncoded(ctx, id, start, end)
+	s.readBreaker.Record(probe, err)
+	return result, err
+}
+
+func (s *dbShard) readEncoded(
+	ctx context.Context,
+	id ident.ID,
+	start, end time.Time,
 ) ([][]xio.BlockReader, error) {
 	s.RLock()
 	entry, _, err := s.lookupEntryWithLock(id)
@@ -1136,6 +1417,23 @@ const (
 	insertSyncIncReaderWriterCount
 )
 
+// releaseDroppedInsert releases the references held by an insert dropped from
+// the insert queue backlog.
+func (s *dbShard) releaseDroppedInsert(insert dbShardInsert) {
+	if insert.opts.hasPendingIndexing && insert.opts.entryRefCountIncremented {
+		indexBlockStart := s.reverseIndex.BlockStartForWriteTime(
+			insert.opts.pendingIndex.timestamp)
+		insert.entry.OnIndexFinalize(indexBlockStart)
+	}
+	if insert.opts.hasPendingRetrievedBlock {
+		// NB: the retrieved block will never be cached by the series, so the
+		// segment and the copy of the tags made for the insert are released.
+		block := insert.opts.pendingRetrievedBlock
+		block.segment.Finalize()
+		block.tags.Close()
+	}
+}
+
 func (s *dbShard) insertSeriesSync(
 	id ident.ID,
 	tagsArgOpts tagsArgOptions,
@@ -1293,7 +1591,7 @@ func (s *dbShard) insertSeriesBatch(inserts []dbShardInsert) error {
 
 		if inserts[i].opts.hasPendingWrite {
 			write := inserts[i].opts.pendingWrite
-			err := entry.Series.Write(ctx, write.timestamp, write.value,
+			_, err := entry.Series.Write(ctx, write.timestamp, write.value,
 				write.unit, write.annotation)
 			if err != nil {
 				s.metrics.insertAsyncWriteErrors.Inc(1)
@@ -1347,10 +1645,43 @@ func (s *dbShard) insertSeriesBatch(inserts []dbShardInsert) error {
 	return err
 }
 
+func (s *dbShard) BucketsInfo(id ident.ID) ([]series.BucketInfo, error) {
+	s.RLock()
+	entry, _, err := s.lookupEntryWithLock(id)
+	if entry != nil {
+		// NB: Ensure the series is not expired while being inspected.
+		entry.IncrementReaderWriterCount()
+		defer entry.DecrementReaderWriterCount()
+	}
+	s.RUnlock()
+
+	if err == errShardEntryNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return entry.Series.BucketsInfo(), nil
+}
+
 func (s *dbShard) FetchBlocks(
 	ctx context.Context,
 	id ident.ID,
 	starts []time.Time,
+) ([]block.FetchBlockResult, error) {
+	probe, err := s.readBreaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.fetchBlocks(ctx, id, starts)
+	s.readBreaker.Record(probe, err)
+	return result, err
+}
+
+func (s *dbShard) fetchBlocks(
+	ctx context.Context,
+	id ident.ID,
+	starts []time.Time,
 ) ([]block.FetchBlockResult, error) {
 	s.RLock()
 	entry, _, err := s.lookupEntryWithLock(id)
@@ -1416,6 +1747,13 @@ func (s *dbShard) fetchActiveBlocksMetadata(
 			return true
 		}
 
+		// Pace checksumming buffered blocks since it requires merging the
+		// buffered data, wait here rather than in the series so that no
+		// series lock is held while waiting.
+		if opts.IncludeBufferChecksums && opts.BufferChecksumsLimiter != nil {
+			opts.BufferChecksumsLimiter.Wait()
+		}
+
 		// Use a temporary context here so the stream readers can be returned to
 		// pool after we finish fetching the metadata for this series.
 		tmpCtx.Reset()
@@ -1821,11 +2159,60 @@ func (s *dbShard) Flush(
 	blockStart time.Time,
 	flush persist.DataFlush,
 ) error {
+	_, err := s.flush(blockStart, flush, func(
+		ctx context.Context,
+		curr series.DatabaseSeries,
+		persistFn persist.DataFn,
+	) (series.FlushOutcome, error) {
+		return curr.Flush(ctx, blockStart, persistFn)
+	})
+	return err
+}
+
+func (s *dbShard) ForceFlush(
+	blockStart time.Time,
+	force bool,
+	flush persist.DataFlush,
+) (ForceFlushResult, error) {
+	var (
+		now    = s.nowFn()
+		latest = retention.FlushTimeEnd(s.namespace.Options().RetentionOptions(), now)
+	)
+	if blockStart.After(latest) {
+		if !force {
+			return ForceFlushResult{}, nil
+		}
+		// NB: The block can still be written to so it is written out as a
+		// snapshot and its flush state is left alone, otherwise the next tick
+		// would skip flushing the data written to it after this point.
+		return s.snapshot(blockStart, now, flush)
+	}
+
+	return s.flush(blockStart, flush, func(
+		ctx context.Context,
+		curr series.DatabaseSeries,
+		persistFn persist.DataFn,
+	) (series.FlushOutcome, error) {
+		return curr.ForceFlush(ctx, blockStart, persistFn)
+	})
+}
+
+type seriesFlushFn func(
+	ctx context.Context,
+	curr series.DatabaseSeries,
+	persistFn persist.DataFn,
+) (series.FlushOutcome, error)
+
+func (s *dbShard) flush(
+	blockStart time.Time,
+	flush persist.DataFlush,
+	flushFn seriesFlushFn,
+) (ForceFlushResult, error) {
 	// We don't flush data when the shard is still bootstrapping
 	s.RLock()
 	if s.bootstrapState != Bootstrapped {
 		s.RUnlock()
-		return errShardNotBootstrappedToFlush
+		return ForceFlushResult{}, errShardNotBootstrappedToFlush
 	}
 	s.RUnlock()
 
@@ -1841,11 +2228,28 @@ func (s *dbShard) Flush(
 	}
 	prepared, err := flush.PrepareData(prepareOpts)
 	if err != nil {
-		return s.markFlushStateSuccessOrError(blockStart, err)
+		return ForceFlushResult{}, s.markFlushStateSuccessOrError(blockStart, err)
 	}
 
-	var multiErr xerrors.MultiError
-	tmpCtx := context.NewContext()
+	var (
+		multiErr xerrors.MultiError
+		tmpCtx   = context.NewContext()
+		result   ForceFlushResult
+	)
+	persistFn := func(
+		id ident.ID,
+		tags ident.Tags,
+		segment ts.Segment,
+		checksum uint32,
+		stats ts.DatapointStats,
+	) error {
+		if err := prepared.Persist(id, tags, segment, checksum, stats); err != nil {
+			return err
+		}
+		result.NumSeriesFlushed++
+		result.NumBytesWritten += int64(segment.Len())
+		return nil
+	}
 
 	flushResult := dbShardFlushResult{}
 	s.forEachShardEntry(func(entry *lookup.Entry) bool {
@@ -1853,7 +2257,7 @@ func (s *dbShard) Flush(
 		// Use a temporary context here so the stream readers can be returned to
 		// the pool after we finish fetching flushing the series.
 		tmpCtx.Reset()
-		flushOutcome, err := curr.Flush(tmpCtx, blockStart, prepared.Persist)
+		flushOutcome, err := flushFn(tmpCtx, curr, persistFn)
 		tmpCtx.BlockingClose()
 
 		if err != nil {
@@ -1874,7 +2278,7 @@ func (s *dbShard) Flush(
 		multiErr = multiErr.Add(err)
 	}
 
-	return s.markFlushStateSuccessOrError(blockStart, multiErr.FinalError())
+	return result, s.markFlushStateSuccessOrError(blockStart, multiErr.FinalError())
 }
 
 func (s *dbShard) Snapshot(
@@ -1882,15 +2286,27 @@ func (s *dbShard) Snapshot(
 	snapshotTime time.Time,
 	flush persist.DataFlush,
 ) error {
+	_, err := s.snapshot(blockStart, snapshotTime, flush)
+	return err
+}
+
+func (s *dbShard) snapshot(
+	blockStart time.Time,
+	snapshotTime time.Time,
+	flush persist.DataFlush,
+) (ForceFlushResult, error) {
 	// We don't snapshot data when the shard is still bootstrapping
 	s.RLock()
 	if s.bootstrapState != Bootstrapped {
 		s.RUnlock()
-		return errShardNotBootstrappedToSnapshot
+		return ForceFlushResult{}, errShardNotBootstrappedToSnapshot
 	}
 	s.RUnlock()
 
-	var multiErr xerrors.MultiError
+	var (
+		multiErr xerrors.MultiError
+		result   ForceFlushResult
+	)
 
 	s.markIsSnapshotting()
 	defer func() {
@@ -1915,7 +2331,22 @@ func (s *dbShard) Snapshot(
 	// Add the err so the defer will capture it
 	multiErr = multiErr.Add(err)
 	if err != nil {
-		return err
+		return ForceFlushResult{}, err
+	}
+
+	persistFn := func(
+		id ident.ID,
+		tags ident.Tags,
+		segment ts.Segment,
+		checksum uint32,
+		stats ts.DatapointStats,
+	) error {
+		if err := prepared.Persist(id, tags, segment, checksum, stats); err != nil {
+			return err
+		}
+		result.NumSeriesFlushed++
+		result.NumBytesWritten += int64(segment.Len())
+		return nil
 	}
 
 	tmpCtx := context.NewContext()
@@ -1924,7 +2355,7 @@ func (s *dbShard) Snapshot(
 		// Use a temporary context here so the stream readers can be returned to
 		// pool after we finish fetching flushing the series
 		tmpCtx.Reset()
-		err := series.Snapshot(tmpCtx, blockStart, prepared.Persist)
+		err := series.Snapshot(tmpCtx, blockStart, persistFn)
 		tmpCtx.BlockingClose()
 
 		if err != nil {
@@ -1941,7 +2372,7 @@ func (s *dbShard) Snapshot(
 		multiErr = multiErr.Add(err)
 	}
 
-	return multiErr.FinalError()
+	return result, multiErr.FinalError()
 }
 
 func (s *dbShard) FlushState(blockStart time.Time) fileOpState {
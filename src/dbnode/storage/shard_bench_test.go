@@ -0,0 +1,92 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
+	"github.com/m3db/m3/src/dbnode/runtime"
+	"github.com/m3db/m3/src/dbnode/storage/namespace"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3x/context"
+	"github.com/m3db/m3x/ident"
+	xtime "github.com/m3db/m3x/time"
+)
+
+// BenchmarkShardWriteRepeatedValues emulates a cache-like write pattern
+// where 90% of writes repeat the last written datapoint, these writes are
+// no-ops for the buffer but are still appended to the commit log.
+func BenchmarkShardWriteRepeatedValues(b *testing.B) {
+	var (
+		now                = time.Now().Truncate(time.Second)
+		numCommitLogWrites int64
+	)
+	opts := testDatabaseOptions()
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return now
+	}))
+
+	writer := commitLogWriter(commitLogWriterFn(func(
+		ctx context.Context,
+		series commitlog.Series,
+		datapoint ts.Datapoint,
+		unit xtime.Unit,
+		annotation ts.Annotation,
+	) error {
+		atomic.AddInt64(&numCommitLogWrites, 1)
+		return nil
+	}))
+
+	metadata, err := namespace.NewMetadata(defaultTestNs1ID, defaultTestNs1Opts)
+	if err != nil {
+		b.Fatal(err)
+	}
+	seriesOpts := NewSeriesOptionsFromOptions(opts, defaultTestNs1Opts.RetentionOptions())
+	shard := newDatabaseShard(metadata, 0, nil, nil,
+		&testIncreasingIndex{}, writer, nil, false, opts, seriesOpts).(*dbShard)
+	shard.SetRuntimeOptions(runtime.NewOptions().
+		SetWriteNewSeriesAsync(false))
+	defer shard.Close()
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	id := ident.StringID("foo")
+	value := 0.0
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if i%10 == 0 {
+			// Only every tenth write is a new datapoint
+			now = now.Add(time.Second)
+			value++
+		}
+		if err := shard.Write(ctx, id, now, value, xtime.Second, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+
+	b.Logf("commit log writes: %d, writes: %d", numCommitLogWrites, b.N)
+}
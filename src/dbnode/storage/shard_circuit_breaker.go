@@ -0,0 +1,315 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/clock"
+	"github.com/m3db/m3/src/dbnode/runtime"
+	m3dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
+	xerrors "github.com/m3db/m3x/errors"
+	"github.com/m3db/m3x/ident"
+	xlog "github.com/m3db/m3x/log"
+
+	"github.com/uber-go/tally"
+)
+
+const (
+	dbShardCircuitBreakerReads  = "read"
+	dbShardCircuitBreakerWrites = "write"
+)
+
+type dbShardCircuitBreakerState int32
+
+const (
+	dbShardCircuitBreakerClosed dbShardCircuitBreakerState = iota
+	dbShardCircuitBreakerOpen
+	dbShardCircuitBreakerHalfOpen
+)
+
+func (s dbShardCircuitBreakerState) String() string {
+	switch s {
+	case dbShardCircuitBreakerClosed:
+		return "closed"
+	case dbShardCircuitBreakerOpen:
+		return "open"
+	case dbShardCircuitBreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// dbShardCircuitBreaker fast fails the reads or the writes of a shard while
+// their recent error rate is high, such as while the disk holding the files
+// of the shard is failing, so that callers retry against the other replicas
+// of the shard rather than piling up behind the failing one. The breaker
+// opens once the error rate within a window reaches the threshold, after the
+// probe interval it half opens to let a single probe request through and
+// closes again if the probe succeeds or reopens if it fails.
+type dbShardCircuitBreaker struct {
+	sync.Mutex
+
+	enabled int32
+	state   int32
+
+	operation string
+	shard     uint32
+	nowFn     clock.NowFn
+	logger    xlog.Logger
+	openErr   error
+
+	breaker      runtime.ShardCircuitBreaker
+	windowStart  time.Time
+	requests     int
+	failures     int
+	openedAt     time.Time
+	probing      bool
+	probeStarted time.Time
+
+	metrics dbShardCircuitBreakerMetrics
+}
+
+type dbShardCircuitBreakerMetrics struct {
+	state       tally.Gauge
+	opened      tally.Counter
+	halfOpened  tally.Counter
+	closed      tally.Counter
+	rejected    tally.Counter
+	probeFailed tally.Counter
+}
+
+func newDatabaseShardCircuitBreakerMetrics(
+	scope tally.Scope,
+) dbShardCircuitBreakerMetrics {
+	transitions := func(state dbShardCircuitBreakerState) tally.Counter {
+		return scope.Tagged(map[string]string{
+			"state": state.String(),
+		}).Counter("transitions")
+	}
+	return dbShardCircuitBreakerMetrics{
+		state:       scope.Gauge("state"),
+		opened:      transitions(dbShardCircuitBreakerOpen),
+		halfOpened:  transitions(dbShardCircuitBreakerHalfOpen),
+		closed:      transitions(dbShardCircuitBreakerClosed),
+		rejected:    scope.Counter("rejected"),
+		probeFailed: scope.Counter("probe-failed"),
+	}
+}
+
+func newDatabaseShardCircuitBreaker(
+	operation string,
+	namespace ident.ID,
+	shard uint32,
+	nowFn clock.NowFn,
+	logger xlog.Logger,
+	scope tally.Scope,
+) *dbShardCircuitBreaker {
+	scope = scope.SubScope("circuit-breaker").Tagged(map[string]string{
+		"namespace": namespace.String(),
+		"shard":     strconv.Itoa(int(shard)),
+		"operation": operation,
+	})
+	return &dbShardCircuitBreaker{
+		operation: operation,
+		shard:     shard,
+		nowFn:     nowFn,
+		logger: logger.WithFields(
+			xlog.NewField("namespace", namespace.String()),
+			xlog.NewField("shard", shard),
+			xlog.NewField("operation", operation),
+		),
+		openErr: m3dberrors.NewShardUnavailableError(fmt.Errorf(
+			"shard %d %s circuit breaker is open", shard, operation)),
+		breaker: runtime.NewOptions().ShardCircuitBreaker(),
+		metrics: newDatabaseShardCircuitBreakerMetrics(scope),
+	}
+}
+
+// SetOptions sets the circuit breaker options, disabling the breaker closes
+// it so that it starts from a clean window once enabled again.
+func (b *dbShardCircuitBreaker) SetOptions(value runtime.ShardCircuitBreaker) {
+	b.Lock()
+	defer b.Unlock()
+
+	var enabled int32
+	if value.Enabled {
+		enabled = 1
+	}
+	atomic.StoreInt32(&b.enabled, enabled)
+	b.breaker = value
+	if !value.Enabled {
+		b.transitionWithLock(dbShardCircuitBreakerClosed, b.nowFn())
+	}
+}
+
+// Allow returns an error if the request should be fast failed, otherwise it
+// returns whether the request is the probe of a half open breaker which must
+// be passed along with the result of the request to Record.
+func (b *dbShardCircuitBreaker) Allow() (bool, error) {
+	if atomic.LoadInt32(&b.enabled) == 0 ||
+		dbShardCircuitBreakerState(atomic.LoadInt32(&b.state)) == dbShardCircuitBreakerClosed {
+		return false, nil
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	now := b.nowFn()
+	switch dbShardCircuitBreakerState(b.state) {
+	case dbShardCircuitBreakerClosed:
+		return false, nil
+	case dbShardCircuitBreakerOpen:
+		if now.Sub(b.openedAt) < b.breaker.ProbeInterval {
+			b.metrics.rejected.Inc(1)
+			return false, b.openErr
+		}
+		b.transitionWithLock(dbShardCircuitBreakerHalfOpen, now)
+	}
+
+	// NB: A probe that never records its result, such as one that panicked,
+	// is given up on after a probe interval so the breaker can't get stuck.
+	if b.probing && now.Sub(b.probeStarted) < b.breaker.ProbeInterval {
+		b.metrics.rejected.Inc(1)
+		return false, b.openErr
+	}
+	b.probing = true
+	b.probeStarted = now
+	return true, nil
+}
+
+// Record records the result of a request that was allowed.
+func (b *dbShardCircuitBreaker) Record(probe bool, err error) {
+	if atomic.LoadInt32(&b.enabled) == 0 {
+		return
+	}
+
+	failed := isShardCircuitBreakerFailure(err)
+
+	b.Lock()
+	defer b.Unlock()
+
+	now := b.nowFn()
+	state := dbShardCircuitBreakerState(b.state)
+	if probe {
+		if state != dbShardCircuitBreakerHalfOpen {
+			return
+		}
+		b.probing = false
+		if failed {
+			b.metrics.probeFailed.Inc(1)
+			b.transitionWithLock(dbShardCircuitBreakerOpen, now)
+			return
+		}
+		b.transitionWithLock(dbShardCircuitBreakerClosed, now)
+		return
+	}
+	if state != dbShardCircuitBreakerClosed {
+		// Requests allowed before the breaker opened don't count towards
+		// the window of the next time the breaker closes.
+		return
+	}
+
+	if now.Sub(b.windowStart) >= b.breaker.Window {
+		b.resetWindowWithLock(now)
+	}
+	b.requests++
+	if !failed {
+		return
+	}
+	b.failures++
+	if b.requests >= b.breaker.MinimumRequests &&
+		float64(b.failures)/float64(b.requests) >= b.breaker.ErrorRateThreshold {
+		b.logger.WithFields(
+			xlog.NewField("requests", b.requests),
+			xlog.NewField("failures", b.failures),
+			xlog.NewField("err", err.Error()),
+		).Warnf("shard circuit breaker error rate reached threshold %v",
+			b.breaker.ErrorRateThreshold)
+		b.transitionWithLock(dbShardCircuitBreakerOpen, now)
+	}
+}
+
+// State returns the state of the circuit breaker.
+func (b *dbShardCircuitBreaker) State() dbShardCircuitBreakerState {
+	return dbShardCircuitBreakerState(atomic.LoadInt32(&b.state))
+}
+
+func (b *dbShardCircuitBreaker) resetWindowWithLock(now time.Time) {
+	b.windowStart = now
+	b.requests = 0
+	b.failures = 0
+}
+
+func (b *dbShardCircuitBreaker) transitionWithLock(
+	state dbShardCircuitBreakerState,
+	now time.Time,
+) {
+	prev := dbShardCircuitBreakerState(b.state)
+	if prev == state {
+		return
+	}
+	atomic.StoreInt32(&b.state, int32(state))
+	b.metrics.state.Update(float64(state))
+
+	switch state {
+	case dbShardCircuitBreakerOpen:
+		b.openedAt = now
+		b.probing = false
+		b.metrics.opened.Inc(1)
+		b.logger.Warnf("shard circuit breaker opened from %s, fast failing requests "+
+			"for %s before probing", prev.String(), b.breaker.ProbeInterval.String())
+	case dbShardCircuitBreakerHalfOpen:
+		b.metrics.halfOpened.Inc(1)
+		b.logger.Infof("shard circuit breaker half opened, probing shard")
+	case dbShardCircuitBreakerClosed:
+		b.probing = false
+		b.resetWindowWithLock(now)
+		b.metrics.closed.Inc(1)
+		b.logger.Infof("shard circuit breaker closed from %s", prev.String())
+	}
+}
+
+// isShardCircuitBreakerFailure returns whether an error counts as a failure
+// of the shard, errors caused by the request itself or by the node shedding
+// load don't indicate that the shard is failing.
+func isShardCircuitBreakerFailure(err error) bool {
+	return err != nil &&
+		!xerrors.IsInvalidParams(err) &&
+		!m3dberrors.IsResourceExhausted(err) &&
+		!m3dberrors.IsDeadlineExceeded(err) &&
+		!m3dberrors.IsShardUnavailable(err)
+}
+
+// firstShardCircuitBreakerFailure returns the first error of a batch that
+// counts as a failure of the shard, if any.
+func firstShardCircuitBreakerFailure(errs []error) error {
+	for _, err := range errs {
+		if isShardCircuitBreakerFailure(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/runtime"
+	m3dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
+	xerrors "github.com/m3db/m3x/errors"
+	"github.com/m3db/m3x/ident"
+	xlog "github.com/m3db/m3x/log"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+var errTestShardDiskFailure = errors.New("disk failure")
+
+// testShardErrorSource is a fake source of the results of shard requests.
+type testShardErrorSource struct {
+	err      error
+	requests int
+}
+
+func (s *testShardErrorSource) request(b *dbShardCircuitBreaker) error {
+	probe, err := b.Allow()
+	if err != nil {
+		return err
+	}
+	s.requests++
+	b.Record(probe, s.err)
+	return s.err
+}
+
+func testShardCircuitBreakerOptions() runtime.ShardCircuitBreaker {
+	return runtime.ShardCircuitBreaker{
+		Enabled:            true,
+		ErrorRateThreshold: 0.5,
+		MinimumRequests:    10,
+		Window:             10 * time.Second,
+		ProbeInterval:      5 * time.Second,
+	}
+}
+
+func newTestShardCircuitBreaker(
+	scope tally.Scope,
+) (*dbShardCircuitBreaker, *time.Time) {
+	now := time.Now()
+	nowFn := func() time.Time { return now }
+	b := newDatabaseShardCircuitBreaker(dbShardCircuitBreakerWrites,
+		ident.StringID("testns"), 1, nowFn, xlog.NullLogger, scope)
+	b.SetOptions(testShardCircuitBreakerOptions())
+	return b, &now
+}
+
+func TestShardCircuitBreakerDisabled(t *testing.T) {
+	b, _ := newTestShardCircuitBreaker(tally.NoopScope)
+	breaker := testShardCircuitBreakerOptions()
+	breaker.Enabled = false
+	b.SetOptions(breaker)
+
+	source := &testShardErrorSource{err: errTestShardDiskFailure}
+	for i := 0; i < 100; i++ {
+		require.Equal(t, errTestShardDiskFailure, source.request(b))
+	}
+	assert.Equal(t, dbShardCircuitBreakerClosed, b.State())
+	assert.Equal(t, 100, source.requests)
+}
+
+func TestShardCircuitBreakerTransitions(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	b, now := newTestShardCircuitBreaker(scope)
+	source := &testShardErrorSource{}
+
+	// Below the minimum requests the breaker stays closed regardless of the
+	// error rate.
+	source.err = errTestShardDiskFailure
+	for i := 0; i < 9; i++ {
+		require.Equal(t, errTestShardDiskFailure, source.request(b))
+	}
+	require.Equal(t, dbShardCircuitBreakerClosed, b.State())
+
+	// Reaching the minimum requests at the error rate threshold opens it.
+	require.Equal(t, errTestShardDiskFailure, source.request(b))
+	require.Equal(t, dbShardCircuitBreakerOpen, b.State())
+
+	// Requests are fast failed while open, without reaching the shard.
+	source.err = nil
+	*now = now.Add(time.Second)
+	err := source.request(b)
+	require.Error(t, err)
+	assert.True(t, m3dberrors.IsShardUnavailable(err))
+	assert.Equal(t, 10, source.requests)
+
+	// After the probe interval a single probe is let through, it fails so
+	// the breaker opens again.
+	*now = now.Add(5 * time.Second)
+	probe, err := b.Allow()
+	require.NoError(t, err)
+	require.True(t, probe)
+	require.Equal(t, dbShardCircuitBreakerHalfOpen, b.State())
+
+	// Other requests are fast failed while the probe is in flight.
+	err = source.request(b)
+	assert.True(t, m3dberrors.IsShardUnavailable(err))
+
+	b.Record(probe, errTestShardDiskFailure)
+	require.Equal(t, dbShardCircuitBreakerOpen, b.State())
+	err = source.request(b)
+	assert.True(t, m3dberrors.IsShardUnavailable(err))
+
+	// The next probe succeeds and closes the breaker.
+	*now = now.Add(5 * time.Second)
+	require.NoError(t, source.request(b))
+	require.Equal(t, dbShardCircuitBreakerClosed, b.State())
+	for i := 0; i < 20; i++ {
+		require.NoError(t, source.request(b))
+	}
+	assert.Equal(t, 31, source.requests)
+
+	transitions := make(map[string]int64)
+	var rejected, probeFailed int64
+	for _, c := range scope.Snapshot().Counters() {
+		switch c.Name() {
+		case "circuit-breaker.transitions":
+			assert.Equal(t, "write", c.Tags()["operation"])
+			assert.Equal(t, "1", c.Tags()["shard"])
+			transitions[c.Tags()["state"]] += c.Value()
+		case "circuit-breaker.rejected":
+			rejected += c.Value()
+		case "circuit-breaker.probe-failed":
+			probeFailed += c.Value()
+		}
+	}
+	assert.Equal(t, map[string]int64{
+		"open":      2,
+		"half-open": 2,
+		"closed":    1,
+	}, transitions)
+	assert.Equal(t, int64(3), rejected)
+	assert.Equal(t, int64(1), probeFailed)
+}
+
+func TestShardCircuitBreakerErrorRateWindow(t *testing.T) {
+	b, now := newTestShardCircuitBreaker(tally.NoopScope)
+	source := &testShardErrorSource{}
+
+	// Failures below the error rate threshold keep the breaker closed.
+	for i := 0; i < 30; i++ {
+		source.err = nil
+		if i%3 == 0 {
+			source.err = errTestShardDiskFailure
+		}
+		source.request(b)
+	}
+	require.Equal(t, dbShardCircuitBreakerClosed, b.State())
+
+	// The failures of a previous window don't count towards the next one.
+	source.err = errTestShardDiskFailure
+	for i := 0; i < 5; i++ {
+		source.request(b)
+	}
+	*now = now.Add(10 * time.Second)
+	for i := 0; i < 9; i++ {
+		source.request(b)
+	}
+	require.Equal(t, dbShardCircuitBreakerClosed, b.State())
+	source.request(b)
+	require.Equal(t, dbShardCircuitBreakerOpen, b.State())
+}
+
+func TestShardCircuitBreakerIgnoresRequestErrors(t *testing.T) {
+	b, _ := newTestShardCircuitBreaker(tally.NoopScope)
+	for _, err := range []error{
+		xerrors.NewInvalidParamsError(errTestShardDiskFailure),
+		m3dberrors.NewResourceExhaustedError(errTestShardDiskFailure),
+		m3dberrors.NewDeadlineExceededError(errTestShardDiskFailure),
+	} {
+		source := &testShardErrorSource{err: err}
+		for i := 0; i < 20; i++ {
+			require.Equal(t, err, source.request(b))
+		}
+		require.Equal(t, dbShardCircuitBreakerClosed, b.State())
+	}
+}
+
+func TestShardCircuitBreakerStuckProbe(t *testing.T) {
+	b, now := newTestShardCircuitBreaker(tally.NoopScope)
+	source := &testShardErrorSource{err: errTestShardDiskFailure}
+	for i := 0; i < 10; i++ {
+		source.request(b)
+	}
+	require.Equal(t, dbShardCircuitBreakerOpen, b.State())
+
+	// A probe that never records its result is given up on after a probe
+	// interval so that another probe can be let through.
+	*now = now.Add(5 * time.Second)
+	probe, err := b.Allow()
+	require.NoError(t, err)
+	require.True(t, probe)
+	_, err = b.Allow()
+	require.Error(t, err)
+
+	*now = now.Add(5 * time.Second)
+	probe, err = b.Allow()
+	require.NoError(t, err)
+	require.True(t, probe)
+	b.Record(probe, nil)
+	require.Equal(t, dbShardCircuitBreakerClosed, b.State())
+}
+
+func TestShardCircuitBreakerDisablingCloses(t *testing.T) {
+	b, _ := newTestShardCircuitBreaker(tally.NoopScope)
+	source := &testShardErrorSource{err: errTestShardDiskFailure}
+	for i := 0; i < 10; i++ {
+		source.request(b)
+	}
+	require.Equal(t, dbShardCircuitBreakerOpen, b.State())
+
+	breaker := testShardCircuitBreakerOptions()
+	breaker.Enabled = false
+	b.SetOptions(breaker)
+	require.Equal(t, dbShardCircuitBreakerClosed, b.State())
+
+	// Once enabled again the breaker starts from a clean window.
+	b.SetOptions(testShardCircuitBreakerOptions())
+	for i := 0; i < 9; i++ {
+		source.request(b)
+	}
+	require.Equal(t, dbShardCircuitBreakerClosed, b.State())
+}
@@ -100,6 +100,64 @@ func TestShardFetchBlocksMetadata(t *testing.T) {
 	}
 }
 
+type countingLimiter struct {
+	waits int
+}
+
+func (l *countingLimiter) Wait() { l.waits++ }
+
+func TestShardFetchBlocksMetadataBufferChecksumsLimited(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := testDatabaseOptions()
+	ctx := opts.ContextPool().Get()
+	defer ctx.Close()
+
+	shard := testDatabaseShard(t, opts)
+	defer shard.Close()
+	start := time.Now()
+	end := start.Add(defaultTestRetentionOpts.BlockSize())
+
+	limiter := &countingLimiter{}
+	fetchOpts := block.FetchBlocksMetadataOptions{
+		IncludeChecksums:       true,
+		IncludeBufferChecksums: true,
+		BufferChecksumsLimiter: limiter,
+	}
+	seriesFetchOpts := series.FetchBlocksMetadataOptions{
+		FetchBlocksMetadataOptions: fetchOpts,
+		IncludeCachedBlocks:        true,
+	}
+	var mockSeries []*series.MockDatabaseSeries
+	for i := 0; i < 3; i++ {
+		id := ident.StringID(fmt.Sprintf("foo.%d", i))
+		mockSeries = append(mockSeries,
+			addMockSeries(ctrl, shard, id, ident.Tags{}, uint64(i)))
+	}
+	expectFetch := func(opts series.FetchBlocksMetadataOptions) {
+		for _, s := range mockSeries {
+			s.EXPECT().
+				FetchBlocksMetadata(gomock.Not(nil), start, end, opts).
+				Return(block.NewFetchBlocksMetadataResult(s.ID(), nil,
+					block.NewFetchBlockMetadataResults()), nil)
+		}
+	}
+
+	expectFetch(seriesFetchOpts)
+	_, _, err := shard.FetchBlocksMetadata(ctx, start, end, 10, 0, fetchOpts)
+	require.NoError(t, err)
+	require.Equal(t, len(mockSeries), limiter.waits)
+
+	// Without buffer checksums requested the limiter is not consulted
+	fetchOpts.IncludeBufferChecksums = false
+	seriesFetchOpts.FetchBlocksMetadataOptions = fetchOpts
+	expectFetch(seriesFetchOpts)
+	_, _, err = shard.FetchBlocksMetadata(ctx, start, end, 10, 0, fetchOpts)
+	require.NoError(t, err)
+	require.Equal(t, len(mockSeries), limiter.waits)
+}
+
 func TestShardFetchBlocksMetadataV2WithSeriesCachePolicyCacheAll(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
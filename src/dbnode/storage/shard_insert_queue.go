@@ -27,6 +27,7 @@ import (
 
 	"github.com/m3db/m3/src/dbnode/clock"
 	"github.com/m3db/m3/src/dbnode/runtime"
+	m3dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
 	"github.com/m3db/m3/src/dbnode/storage/series/lookup"
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3x/ident"
@@ -38,7 +39,7 @@ import (
 var (
 	errShardInsertQueueNotOpen             = errors.New("shard insert queue is not open")
 	errShardInsertQueueAlreadyOpenOrClosed = errors.New("shard insert queue already open or is closed")
-	errNewSeriesInsertRateLimitExceeded    = errors.New("shard insert of new series exceeds rate limit")
+	errNewSeriesInsertRateLimitExceeded    = m3dberrors.NewResourceExhaustedError(errors.New("shard insert of new series exceeds rate limit"))
 )
 
 type dbShardInsertQueueState int
@@ -58,12 +59,18 @@ type dbShardInsertQueue struct {
 	sleepFn            func(time.Duration)
 
 	// rate limits, protected by mutex
-	insertBatchBackoff   time.Duration
-	insertPerSecondLimit int
+	insertBatchBackoff     time.Duration
+	insertPerSecondLimit   int
+	insertPerSecondBacklog int
 
 	insertPerSecondLimitWindowNanos  int64
 	insertPerSecondLimitWindowValues int
 
+	// inserts exceeding the rate limit waiting to be admitted, protected by mutex
+	backlog      []dbShardInsertBacklogEntry
+	backlogTimer *time.Timer
+	dropInsertFn dbShardDropInsertFn
+
 	currBatch    *dbShardInsertBatch
 	notifyInsert chan struct{}
 	closeCh      chan struct{}
@@ -74,6 +81,8 @@ type dbShardInsertQueue struct {
 type dbShardInsertQueueMetrics struct {
 	insertsNoPendingWrite tally.Counter
 	insertsPendingWrite   tally.Counter
+	backlogQueued         tally.Counter
+	backlogDropped        tally.Counter
 }
 
 func newDatabaseShardInsertQueueMetrics(
@@ -81,6 +90,7 @@ func newDatabaseShardInsertQueueMetrics(
 ) dbShardInsertQueueMetrics {
 	insertName := "inserts"
 	insertPendingWriteTagName := "pending-write"
+	backlogScope := scope.SubScope("backlog")
 	return dbShardInsertQueueMetrics{
 		insertsNoPendingWrite: scope.Tagged(map[string]string{
 			insertPendingWriteTagName: "no",
@@ -88,12 +98,22 @@ func newDatabaseShardInsertQueueMetrics(
 		insertsPendingWrite: scope.Tagged(map[string]string{
 			insertPendingWriteTagName: "yes",
 		}).Counter(insertName),
+		backlogQueued:  backlogScope.Counter("queued"),
+		backlogDropped: backlogScope.Counter("dropped"),
 	}
 }
 
 type dbShardInsertBatch struct {
 	wg      *sync.WaitGroup
 	inserts []dbShardInsert
+	// backlogWgs are the wait groups of the backlogged inserts admitted
+	// into the batch, done once the batch is inserted.
+	backlogWgs []*sync.WaitGroup
+}
+
+type dbShardInsertBacklogEntry struct {
+	insert dbShardInsert
+	wg     *sync.WaitGroup
 }
 
 type dbShardInsertAsyncOptions struct {
@@ -147,10 +167,18 @@ func (b *dbShardInsertBatch) reset() {
 		b.inserts[i] = dbShardInsertZeroed
 	}
 	b.inserts = b.inserts[:0]
+	for i := range b.backlogWgs {
+		b.backlogWgs[i] = nil
+	}
+	b.backlogWgs = b.backlogWgs[:0]
 }
 
 type dbShardInsertEntryBatchFn func(inserts []dbShardInsert) error
 
+// dbShardDropInsertFn releases any resources held by an insert that was
+// dropped from the backlog without being inserted.
+type dbShardDropInsertFn func(insert dbShardInsert)
+
 // newDatabaseShardInsertQueue creates a new shard insert queue. The shard
 // insert queue is used to batch inserts into the shard series map without
 // sacrificing delays to insert the series.
@@ -166,8 +194,13 @@ type dbShardInsertEntryBatchFn func(inserts []dbShardInsert) error
 // The batching as it is without any sleep and just relying on a notification
 // trigger and hot looping when being flooded improved by a factor of roughly
 // 4x during floods of new series.
+//
+// When a backlog is configured inserts exceeding the rate limit are queued
+// rather than rejected and admitted at the limit rate, dropping the oldest
+// queued insert once the backlog is full.
 func newDatabaseShardInsertQueue(
 	insertEntryBatchFn dbShardInsertEntryBatchFn,
+	dropInsertFn dbShardDropInsertFn,
 	nowFn clock.NowFn,
 	scope tally.Scope,
 ) *dbShardInsertQueue {
@@ -177,6 +210,7 @@ func newDatabaseShardInsertQueue(
 	return &dbShardInsertQueue{
 		nowFn:              nowFn,
 		insertEntryBatchFn: insertEntryBatchFn,
+		dropInsertFn:       dropInsertFn,
 		sleepFn:            time.Sleep,
 		currBatch:          currBatch,
 		notifyInsert:       make(chan struct{}, 1),
@@ -189,7 +223,102 @@ func (q *dbShardInsertQueue) SetRuntimeOptions(value runtime.Options) {
 	q.Lock()
 	q.insertBatchBackoff = value.WriteNewSeriesBackoffDuration()
 	q.insertPerSecondLimit = value.WriteNewSeriesLimitPerShardPerSecond()
+	q.insertPerSecondBacklog = value.WriteNewSeriesBacklogPerShard()
+	for len(q.backlog) > q.insertPerSecondBacklog {
+		q.dropOldestBacklogWithLock()
+	}
 	q.Unlock()
+
+	// Notify insert loop in case the backlog can now be admitted
+	q.notifyInsertLoop()
+}
+
+func (q *dbShardInsertQueue) notifyInsertLoop() {
+	select {
+	case q.notifyInsert <- struct{}{}:
+	default:
+		// Loop busy, already ready to consume notification
+	}
+}
+
+// BacklogLen returns the number of inserts waiting in the backlog.
+func (q *dbShardInsertQueue) BacklogLen() int {
+	q.RLock()
+	n := len(q.backlog)
+	q.RUnlock()
+	return n
+}
+
+func (q *dbShardInsertQueue) rollLimitWindowWithLock(windowNanos int64) {
+	if q.insertPerSecondLimitWindowNanos != windowNanos {
+		// Rolled into to a new window
+		q.insertPerSecondLimitWindowNanos = windowNanos
+		q.insertPerSecondLimitWindowValues = 0
+	}
+}
+
+// admitBacklogWithLock moves as many backlogged inserts, oldest first, into
+// the current batch as the rate limit permits for the current window.
+func (q *dbShardInsertQueue) admitBacklogWithLock(windowNanos int64) {
+	n := len(q.backlog)
+	if n == 0 {
+		return
+	}
+	if limit := q.insertPerSecondLimit; limit > 0 {
+		q.rollLimitWindowWithLock(windowNanos)
+		if remaining := limit - q.insertPerSecondLimitWindowValues; remaining < n {
+			n = remaining
+		}
+		if n <= 0 {
+			return
+		}
+		q.insertPerSecondLimitWindowValues += n
+	}
+	for i := 0; i < n; i++ {
+		q.currBatch.inserts = append(q.currBatch.inserts, q.backlog[i].insert)
+		q.currBatch.backlogWgs = append(q.currBatch.backlogWgs, q.backlog[i].wg)
+		q.backlog[i] = dbShardInsertBacklogEntry{}
+	}
+	q.backlog = q.backlog[n:]
+}
+
+func (q *dbShardInsertQueue) enqueueBacklogWithLock(insert dbShardInsert) *sync.WaitGroup {
+	if len(q.backlog) >= q.insertPerSecondBacklog {
+		q.dropOldestBacklogWithLock()
+	}
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	q.backlog = append(q.backlog, dbShardInsertBacklogEntry{
+		insert: insert,
+		wg:     wg,
+	})
+	q.metrics.backlogQueued.Inc(1)
+	return wg
+}
+
+func (q *dbShardInsertQueue) dropOldestBacklogWithLock() {
+	dropped := q.backlog[0]
+	q.backlog[0] = dbShardInsertBacklogEntry{}
+	q.backlog = q.backlog[1:]
+	q.dropInsertFn(dropped.insert)
+	dropped.wg.Done()
+	q.metrics.backlogDropped.Inc(1)
+}
+
+// scheduleBacklogAdmissionWithLock wakes the insert loop at the start of the
+// next rate limit window so the backlog keeps draining without new inserts.
+func (q *dbShardInsertQueue) scheduleBacklogAdmissionWithLock() {
+	if len(q.backlog) == 0 || q.backlogTimer != nil {
+		return
+	}
+	now := q.nowFn()
+	untilNextWindow := now.Truncate(time.Second).Add(time.Second).Sub(now)
+	q.backlogTimer = time.AfterFunc(untilNextWindow, func() {
+		q.Lock()
+		q.backlogTimer = nil
+		q.Unlock()
+		q.notifyInsertLoop()
+	})
 }
 
 func (q *dbShardInsertQueue) insertLoop() {
@@ -217,6 +346,7 @@ func (q *dbShardInsertQueue) insertLoop() {
 			backoff = q.insertBatchBackoff - elapsedSinceLastInsert
 		} else {
 			// No backoff required, rotate and go
+			q.admitBacklogWithLock(q.nowFn().Truncate(time.Second).UnixNano())
 			batch = q.currBatch
 			q.currBatch = freeBatch
 		}
@@ -226,6 +356,7 @@ func (q *dbShardInsertQueue) insertLoop() {
 			q.sleepFn(backoff)
 			q.Lock()
 			// Rotate after backoff
+			q.admitBacklogWithLock(q.nowFn().Truncate(time.Second).UnixNano())
 			batch = q.currBatch
 			q.currBatch = freeBatch
 			q.Unlock()
@@ -234,6 +365,9 @@ func (q *dbShardInsertQueue) insertLoop() {
 		if len(batch.inserts) > 0 {
 			q.insertEntryBatchFn(batch.inserts)
 		}
+		for _, wg := range batch.backlogWgs {
+			wg.Done()
+		}
 		batch.wg.Done()
 
 		// Set the free batch
@@ -242,6 +376,12 @@ func (q *dbShardInsertQueue) insertLoop() {
 
 		lastInsert = q.nowFn()
 
+		if state == dbShardInsertQueueStateOpen {
+			q.Lock()
+			q.scheduleBacklogAdmissionWithLock()
+			q.Unlock()
+		}
+
 		if state != dbShardInsertQueueStateOpen {
 			return // Break if the queue closed
 		}
@@ -273,15 +413,22 @@ func (q *dbShardInsertQueue) Stop() error {
 	q.Unlock()
 
 	// Final flush
-	select {
-	case q.notifyInsert <- struct{}{}:
-	default:
-		// Loop busy, already ready to consume notification
-	}
+	q.notifyInsertLoop()
 
 	// wait till other go routine is done
 	<-q.closeCh
 
+	// Drop any inserts still waiting in the backlog
+	q.Lock()
+	if q.backlogTimer != nil {
+		q.backlogTimer.Stop()
+		q.backlogTimer = nil
+	}
+	for len(q.backlog) > 0 {
+		q.dropOldestBacklogWithLock()
+	}
+	q.Unlock()
+
 	return nil
 }
 
@@ -293,28 +440,30 @@ func (q *dbShardInsertQueue) Insert(insert dbShardInsert) (*sync.WaitGroup, erro
 		q.Unlock()
 		return nil, errShardInsertQueueNotOpen
 	}
+	var wg *sync.WaitGroup
 	if limit := q.insertPerSecondLimit; limit > 0 {
-		if q.insertPerSecondLimitWindowNanos != windowNanos {
-			// Rolled into to a new window
-			q.insertPerSecondLimitWindowNanos = windowNanos
-			q.insertPerSecondLimitWindowValues = 0
-		}
+		q.rollLimitWindowWithLock(windowNanos)
+		// Backlogged inserts are admitted ahead of new inserts
+		q.admitBacklogWithLock(windowNanos)
 		q.insertPerSecondLimitWindowValues++
 		if q.insertPerSecondLimitWindowValues > limit {
-			q.Unlock()
-			return nil, errNewSeriesInsertRateLimitExceeded
+			if q.insertPerSecondBacklog <= 0 {
+				q.Unlock()
+				return nil, errNewSeriesInsertRateLimitExceeded
+			}
+			// Counted against the window it is admitted in instead
+			q.insertPerSecondLimitWindowValues--
+			wg = q.enqueueBacklogWithLock(insert)
 		}
 	}
-	q.currBatch.inserts = append(q.currBatch.inserts, insert)
-	wg := q.currBatch.wg
+	if wg == nil {
+		q.currBatch.inserts = append(q.currBatch.inserts, insert)
+		wg = q.currBatch.wg
+	}
 	q.Unlock()
 
 	// Notify insert loop
-	select {
-	case q.notifyInsert <- struct{}{}:
-	default:
-		// Loop busy, already ready to consume notification
-	}
+	q.notifyInsertLoop()
 
 	if insert.opts.hasPendingWrite {
 		q.metrics.insertsPendingWrite.Inc(1)
@@ -0,0 +1,121 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fortytw2/leaktest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func TestShardInsertQueueRateLimitBacklog(t *testing.T) {
+	defer leaktest.CheckTimeout(t, 5*time.Second)()
+
+	var (
+		currTime = time.Now().Truncate(time.Second)
+		timeLock = sync.Mutex{}
+		addTime  = func(d time.Duration) {
+			timeLock.Lock()
+			defer timeLock.Unlock()
+			currTime = currTime.Add(d)
+		}
+		resultsLock sync.Mutex
+		inserted    []float64
+		dropped     []float64
+	)
+	newInsert := func(value float64) dbShardInsert {
+		return dbShardInsert{opts: dbShardInsertAsyncOptions{
+			hasPendingWrite: true,
+			pendingWrite:    dbShardPendingWrite{value: value},
+		}}
+	}
+	results := func() ([]float64, []float64) {
+		resultsLock.Lock()
+		defer resultsLock.Unlock()
+		return append([]float64(nil), inserted...), append([]float64(nil), dropped...)
+	}
+
+	q := newDatabaseShardInsertQueue(func(value []dbShardInsert) error {
+		resultsLock.Lock()
+		defer resultsLock.Unlock()
+		for _, insert := range value {
+			inserted = append(inserted, insert.opts.pendingWrite.value)
+		}
+		return nil
+	}, func(insert dbShardInsert) {
+		resultsLock.Lock()
+		defer resultsLock.Unlock()
+		dropped = append(dropped, insert.opts.pendingWrite.value)
+	}, func() time.Time {
+		timeLock.Lock()
+		defer timeLock.Unlock()
+		return currTime
+	}, tally.NoopScope)
+
+	q.insertPerSecondLimit = 2
+	q.insertPerSecondBacklog = 3
+
+	require.NoError(t, q.Start())
+
+	var wgs []*sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg, err := q.Insert(newInsert(float64(i)))
+		require.NoError(t, err)
+		wgs = append(wgs, wg)
+	}
+
+	// First two are admitted, the rest are backlogged with the oldest dropped
+	// once the backlog is full
+	require.Equal(t, 3, q.BacklogLen())
+	for _, wg := range wgs[:3] {
+		wg.Wait()
+	}
+	observedInserted, observedDropped := results()
+	assert.Equal(t, []float64{0, 1}, observedInserted)
+	assert.Equal(t, []float64{2}, observedDropped)
+
+	// Next window admits the oldest backlogged inserts ahead of new inserts
+	addTime(time.Second)
+	wg, err := q.Insert(newInsert(6))
+	require.NoError(t, err)
+	wgs = append(wgs, wg)
+	for _, wg := range wgs[3:5] {
+		wg.Wait()
+	}
+	require.Equal(t, 2, q.BacklogLen())
+	observedInserted, _ = results()
+	assert.Equal(t, []float64{0, 1, 3, 4}, observedInserted)
+
+	// Remaining backlogged inserts are dropped on close
+	require.NoError(t, q.Stop())
+	for _, wg := range wgs {
+		wg.Wait()
+	}
+	require.Equal(t, 0, q.BacklogLen())
+	observedInserted, observedDropped = results()
+	assert.Equal(t, []float64{0, 1, 3, 4}, observedInserted)
+	assert.Equal(t, []float64{2, 5, 6}, observedDropped)
+}
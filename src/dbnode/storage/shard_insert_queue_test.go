@@ -27,6 +27,8 @@ import (
 	"testing"
 	"time"
 
+	m3dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
+
 	"github.com/fortytw2/leaktest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -60,7 +62,7 @@ func TestShardInsertQueueBatchBackoff(t *testing.T) {
 		insertWgs[len(inserts)-1].Done()
 		insertProgressWgs[len(inserts)-1].Wait()
 		return nil
-	}, func() time.Time {
+	}, func(dbShardInsert) {}, func() time.Time {
 		timeLock.Lock()
 		defer timeLock.Unlock()
 		return currTime
@@ -138,7 +140,7 @@ func TestShardInsertQueueRateLimit(t *testing.T) {
 	)
 	q := newDatabaseShardInsertQueue(func(value []dbShardInsert) error {
 		return nil
-	}, func() time.Time {
+	}, func(dbShardInsert) {}, func() time.Time {
 		timeLock.Lock()
 		defer timeLock.Unlock()
 		return currTime
@@ -178,6 +180,7 @@ func TestShardInsertQueueRateLimit(t *testing.T) {
 	_, err = q.Insert(dbShardInsert{})
 	require.Error(t, err)
 	require.Equal(t, errNewSeriesInsertRateLimitExceeded, err)
+	require.True(t, m3dberrors.IsResourceExhausted(err))
 
 	// Start 3rd second
 	addTime(800 * time.Millisecond)
@@ -203,7 +206,7 @@ func TestShardInsertQueueFlushedOnClose(t *testing.T) {
 	q := newDatabaseShardInsertQueue(func(value []dbShardInsert) error {
 		atomic.AddInt64(&numInsertObserved, int64(len(value)))
 		return nil
-	}, func() time.Time { return currTime }, tally.NoopScope)
+	}, func(dbShardInsert) {}, func() time.Time { return currTime }, tally.NoopScope)
 
 	require.NoError(t, q.Start())
 
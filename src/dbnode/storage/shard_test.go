@@ -32,10 +32,13 @@ import (
 
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/persist/fs"
+	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
 	"github.com/m3db/m3/src/dbnode/retention"
 	"github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
+	m3dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
+	"github.com/m3db/m3/src/dbnode/storage/index"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3/src/dbnode/storage/series"
 	"github.com/m3db/m3/src/dbnode/storage/series/lookup"
@@ -203,7 +206,7 @@ func TestShardFlushSeriesFlushError(t *testing.T) {
 	var closed bool
 	flush := persist.NewMockDataFlush(ctrl)
 	prepared := persist.PreparedDataPersist{
-		Persist: func(ident.ID, ident.Tags, ts.Segment, uint32) error { return nil },
+		Persist: func(ident.ID, ident.Tags, ts.Segment, uint32, ts.DatapointStats) error { return nil },
 		Close:   func() error { closed = true; return nil },
 	}
 	prepareOpts := xtest.CmpMatcher(persist.DataPrepareOptions{
@@ -268,7 +271,7 @@ func TestShardFlushSeriesFlushSuccess(t *testing.T) {
 	var closed bool
 	flush := persist.NewMockDataFlush(ctrl)
 	prepared := persist.PreparedDataPersist{
-		Persist: func(ident.ID, ident.Tags, ts.Segment, uint32) error { return nil },
+		Persist: func(ident.ID, ident.Tags, ts.Segment, uint32, ts.DatapointStats) error { return nil },
 		Close:   func() error { closed = true; return nil },
 	}
 
@@ -312,6 +315,131 @@ func TestShardFlushSeriesFlushSuccess(t *testing.T) {
 	}, flushState)
 }
 
+func TestShardForceFlushSeries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	blockStart := time.Unix(21600, 0)
+
+	s := testDatabaseShard(t, testDatabaseOptions())
+	defer s.Close()
+	s.bootstrapState = Bootstrapped
+
+	var (
+		closed    bool
+		persisted []ident.ID
+	)
+	flush := persist.NewMockDataFlush(ctrl)
+	prepared := persist.PreparedDataPersist{
+		Persist: func(id ident.ID, _ ident.Tags, _ ts.Segment, _ uint32, _ ts.DatapointStats) error {
+			persisted = append(persisted, id)
+			return nil
+		},
+		Close: func() error { closed = true; return nil },
+	}
+
+	prepareOpts := xtest.CmpMatcher(persist.DataPrepareOptions{
+		NamespaceMetadata: s.namespace,
+		Shard:             s.shard,
+		BlockStart:        blockStart,
+	})
+	flush.EXPECT().PrepareData(prepareOpts).Return(prepared, nil)
+
+	for i, data := range []string{"bar", "bazqux"} {
+		id := ident.StringID("foo" + strconv.Itoa(i))
+		segment := ts.NewSegment(checked.NewBytes([]byte(data), nil), nil, ts.FinalizeNone)
+		curr := series.NewMockDatabaseSeries(ctrl)
+		curr.EXPECT().ID().Return(id).AnyTimes()
+		curr.EXPECT().IsEmpty().Return(false).AnyTimes()
+		curr.EXPECT().
+			ForceFlush(gomock.Any(), blockStart, gomock.Any()).
+			DoAndReturn(func(
+				_ context.Context,
+				_ time.Time,
+				persistFn persist.DataFn,
+			) (series.FlushOutcome, error) {
+				return series.FlushOutcomeFlushedToDisk, persistFn(id, ident.Tags{}, segment, 0, ts.DatapointStats{})
+			})
+		s.list.PushBack(lookup.NewEntry(curr, 0))
+	}
+
+	// A series without data for the block start is not counted.
+	curr := series.NewMockDatabaseSeries(ctrl)
+	curr.EXPECT().ID().Return(ident.StringID("foo2")).AnyTimes()
+	curr.EXPECT().IsEmpty().Return(false).AnyTimes()
+	curr.EXPECT().
+		ForceFlush(gomock.Any(), blockStart, gomock.Any()).
+		Return(series.FlushOutcomeBlockDoesNotExist, nil)
+	s.list.PushBack(lookup.NewEntry(curr, 0))
+
+	result, err := s.ForceFlush(blockStart, true, flush)
+	require.NoError(t, err)
+	require.True(t, closed)
+	require.Equal(t, ForceFlushResult{NumSeriesFlushed: 2, NumBytesWritten: 9}, result)
+	require.Equal(t, []ident.ID{ident.StringID("foo0"), ident.StringID("foo1")}, persisted)
+	require.Equal(t, fileOpSuccess, s.FlushState(blockStart).Status)
+}
+
+func TestShardForceFlushOpenBlock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		blockStart = time.Unix(21600, 0)
+		now        = blockStart.Add(time.Minute)
+	)
+
+	s := testDatabaseShard(t, testDatabaseOptions())
+	defer s.Close()
+	s.bootstrapState = Bootstrapped
+	s.nowFn = func() time.Time { return now }
+
+	// Without force a block that can still be written to is left alone.
+	flush := persist.NewMockDataFlush(ctrl)
+	result, err := s.ForceFlush(blockStart, false, flush)
+	require.NoError(t, err)
+	require.Equal(t, ForceFlushResult{}, result)
+
+	var closed bool
+	prepared := persist.PreparedDataPersist{
+		Persist: func(ident.ID, ident.Tags, ts.Segment, uint32, ts.DatapointStats) error { return nil },
+		Close:   func() error { closed = true; return nil },
+	}
+	prepareOpts := xtest.CmpMatcher(persist.DataPrepareOptions{
+		NamespaceMetadata: s.namespace,
+		Shard:             s.shard,
+		BlockStart:        blockStart,
+		FileSetType:       persist.FileSetSnapshotType,
+		Snapshot: persist.DataPrepareSnapshotOptions{
+			SnapshotTime: now,
+		},
+	})
+	flush.EXPECT().PrepareData(prepareOpts).Return(prepared, nil)
+
+	segment := ts.NewSegment(checked.NewBytes([]byte("bar"), nil), nil, ts.FinalizeNone)
+	curr := series.NewMockDatabaseSeries(ctrl)
+	curr.EXPECT().ID().Return(ident.StringID("foo")).AnyTimes()
+	curr.EXPECT().IsEmpty().Return(false).AnyTimes()
+	curr.EXPECT().
+		Snapshot(gomock.Any(), blockStart, gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ time.Time,
+			persistFn persist.DataFn,
+		) error {
+			return persistFn(ident.StringID("foo"), ident.Tags{}, segment, 0, ts.DatapointStats{})
+		})
+	s.list.PushBack(lookup.NewEntry(curr, 0))
+
+	// With force it is snapshotted and the flush state is left alone so that
+	// the next tick still flushes it.
+	result, err = s.ForceFlush(blockStart, true, flush)
+	require.NoError(t, err)
+	require.True(t, closed)
+	require.Equal(t, ForceFlushResult{NumSeriesFlushed: 1, NumBytesWritten: 3}, result)
+	require.Equal(t, fileOpNotStarted, s.FlushState(blockStart).Status)
+}
+
 func TestShardSnapshotShardNotBootstrapped(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -340,7 +468,7 @@ func TestShardSnapshotSeriesSnapshotSuccess(t *testing.T) {
 	var closed bool
 	flush := persist.NewMockDataFlush(ctrl)
 	prepared := persist.PreparedDataPersist{
-		Persist: func(ident.ID, ident.Tags, ts.Segment, uint32) error { return nil },
+		Persist: func(ident.ID, ident.Tags, ts.Segment, uint32, ts.DatapointStats) error { return nil },
 		Close:   func() error { closed = true; return nil },
 	}
 
@@ -463,6 +591,7 @@ func TestShardTick(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 3, r.activeSeries)
 	require.Equal(t, 0, r.expiredSeries)
+	require.True(t, r.unflushedBytes > 0)
 	require.Equal(t, 2*sleepPerSeries, slept) // Never sleeps on the first series
 
 	// Ensure flush states by time was expired correctly
@@ -471,6 +600,274 @@ func TestShardTick(t *testing.T) {
 	require.True(t, ok)
 }
 
+func TestShardTickAdaptivePacing(t *testing.T) {
+	opts := testDatabaseOptions()
+	shard := testDatabaseShard(t, opts)
+	defer shard.Close()
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	shard.Write(ctx, ident.StringID("foo"), time.Now(), 1.0, xtime.Second, nil)
+	shard.Write(ctx, ident.StringID("bar"), time.Now(), 2.0, xtime.Second, nil)
+	shard.Write(ctx, ident.StringID("baz"), time.Now(), 3.0, xtime.Second, nil)
+
+	shard.SetRuntimeOptions(runtime.NewOptions().
+		SetTickPerSeriesSleepDuration(time.Microsecond).
+		SetTickSeriesBatchSize(1).
+		SetTickAdaptivePacing(runtime.TickAdaptivePacing{
+			Enabled:                   true,
+			TargetWriteLatency:        time.Millisecond,
+			MinSeriesBatchSize:        1,
+			MaxSeriesBatchSize:        1,
+			MinPerSeriesSleepDuration: time.Microsecond,
+			MaxPerSeriesSleepDuration: time.Millisecond,
+		}))
+
+	var slept []time.Duration
+	shard.sleepFn = func(t time.Duration) {
+		slept = append(slept, t)
+	}
+
+	// A contended write backs off the tick when it starts, then with no
+	// further writes it speeds up again between each batch.
+	shard.tickPacer.RecordWriteLatency(time.Second)
+	r, err := shard.Tick(context.NewNoOpCanncellable(), time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 3, r.activeSeries)
+	require.Equal(t, []time.Duration{1750 * time.Nanosecond, 1532 * time.Nanosecond}, slept)
+}
+
+func TestShardTickWriteRecency(t *testing.T) {
+	var (
+		nowLock sync.RWMutex
+		now     = time.Now().Truncate(defaultTestRetentionOpts.BlockSize())
+		start   = now
+	)
+	nowFn := func() time.Time {
+		nowLock.RLock()
+		value := now
+		nowLock.RUnlock()
+		return value
+	}
+	setNow := func(t time.Time) {
+		nowLock.Lock()
+		now = t
+		nowLock.Unlock()
+	}
+
+	scope := tally.NewTestScope("", nil)
+	opts := testDatabaseOptions()
+	opts = opts.
+		SetClockOptions(opts.ClockOptions().SetNowFn(nowFn)).
+		SetInstrumentOptions(opts.InstrumentOptions().SetMetricsScope(scope))
+	shard := testDatabaseShard(t, opts)
+	defer shard.Close()
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	shard.Write(ctx, ident.StringID("foo"), nowFn(), 1.0, xtime.Second, nil)
+	setNow(start.Add(2 * time.Minute))
+	shard.Write(ctx, ident.StringID("bar"), nowFn(), 2.0, xtime.Second, nil)
+	shard.Write(ctx, ident.StringID("bar"), nowFn().Add(-time.Second), 3.0, xtime.Second, nil)
+
+	requireWriteRecency := func(lastMinute, last5Minutes, lastHour int) {
+		_, err := shard.Tick(context.NewNoOpCanncellable(), nowFn())
+		require.NoError(t, err)
+
+		gauges := scope.Snapshot().Gauges()
+		for window, expected := range map[string]int{
+			"1m": lastMinute,
+			"5m": last5Minutes,
+			"1h": lastHour,
+		} {
+			name := "dbshard.write-recency.series+namespace=testns1,shard=0,window=" + window
+			gauge, ok := gauges[name]
+			require.True(t, ok, name)
+			assert.Equal(t, float64(expected), gauge.Value(), window)
+		}
+		buffered, ok := gauges["dbshard.write-recency.buffered-datapoints+namespace=testns1,shard=0"]
+		require.True(t, ok)
+		assert.Equal(t, float64(3), buffered.Value())
+	}
+
+	requireWriteRecency(1, 2, 2)
+
+	setNow(start.Add(4 * time.Minute))
+	requireWriteRecency(0, 2, 2)
+
+	setNow(start.Add(30 * time.Minute))
+	requireWriteRecency(0, 0, 2)
+
+	setNow(start.Add(90 * time.Minute))
+	requireWriteRecency(0, 0, 0)
+
+	// A new write moves the series back into the most recent window
+	shard.Write(ctx, ident.StringID("foo"), nowFn(), 4.0, xtime.Second, nil)
+	setNow(start.Add(90*time.Minute + time.Second))
+	_, err := shard.Tick(context.NewNoOpCanncellable(), nowFn())
+	require.NoError(t, err)
+	gauges := scope.Snapshot().Gauges()
+	assert.Equal(t, float64(1),
+		gauges["dbshard.write-recency.series+namespace=testns1,shard=0,window=1m"].Value())
+	assert.Equal(t, float64(4),
+		gauges["dbshard.write-recency.buffered-datapoints+namespace=testns1,shard=0"].Value())
+}
+
+func TestShardWriteNoOpRetryWritesCommitLog(t *testing.T) {
+	opts := testDatabaseOptions()
+	testNs, closer := newTestNamespace(t)
+	defer closer()
+
+	numCommitLogWrites := int32(0)
+	writer := commitLogWriter(commitLogWriterFn(func(
+		ctx context.Context,
+		series commitlog.Series,
+		datapoint ts.Datapoint,
+		unit xtime.Unit,
+		annotation ts.Annotation,
+	) error {
+		if atomic.AddInt32(&numCommitLogWrites, 1) == 1 {
+			return commitlog.ErrCommitLogQueueFull
+		}
+		return nil
+	}))
+
+	seriesOpts := NewSeriesOptionsFromOptions(opts, testNs.Options().RetentionOptions())
+	shard := newDatabaseShard(testNs.metadata, 0, nil, nil,
+		&testIncreasingIndex{}, writer, nil, false, opts, seriesOpts).(*dbShard)
+	shard.SetRuntimeOptions(runtime.NewOptions().
+		SetWriteNewSeriesAsync(false))
+	defer shard.Close()
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	// The retry is a no-op for the buffer but must still reach the commit log
+	// as the first write never made it there.
+	now := opts.ClockOptions().NowFn()()
+	require.Equal(t, commitlog.ErrCommitLogQueueFull,
+		shard.Write(ctx, ident.StringID("foo"), now, 1.0, xtime.Second, nil))
+	require.NoError(t, shard.Write(ctx, ident.StringID("foo"), now, 1.0, xtime.Second, nil))
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&numCommitLogWrites))
+}
+
+func TestShardCircuitBreakerFastFailsWrites(t *testing.T) {
+	opts := testDatabaseOptions()
+	shard := testDatabaseShard(t, opts)
+	defer shard.Close()
+
+	breaker := runtime.NewOptions().ShardCircuitBreaker()
+	breaker.Enabled = true
+	breaker.MinimumRequests = 1
+	shard.SetRuntimeOptions(runtime.NewOptions().SetShardCircuitBreaker(breaker))
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	now := opts.ClockOptions().NowFn()()
+	require.NoError(t, shard.Write(ctx, ident.StringID("foo"), now, 1.0,
+		xtime.Second, nil))
+
+	// Open the write breaker as if the writes of the shard had been failing.
+	for i := 0; i < 2; i++ {
+		shard.writeBreaker.Record(false, errors.New("disk failure"))
+	}
+	require.Equal(t, dbShardCircuitBreakerOpen, shard.writeBreaker.State())
+
+	err := shard.Write(ctx, ident.StringID("foo"), now, 2.0, xtime.Second, nil)
+	require.Error(t, err)
+	assert.True(t, m3dberrors.IsShardUnavailable(err))
+
+	_, err = shard.WriteTaggedBatch(ctx, ident.StringID("foo"),
+		ident.EmptyTagIterator, []ts.Datapoint{{Timestamp: now, Value: 3.0}},
+		xtime.Second, nil)
+	require.Error(t, err)
+	assert.True(t, m3dberrors.IsShardUnavailable(err))
+
+	// Reads are tracked by a separate breaker and still served.
+	assert.Equal(t, dbShardCircuitBreakerClosed, shard.readBreaker.State())
+	_, err = shard.ReadEncoded(ctx, ident.StringID("foo"), now.Add(-time.Minute),
+		now.Add(time.Minute))
+	require.NoError(t, err)
+}
+
+func TestShardWriteTaggedBatchNoOpWritesCommitLog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := testDatabaseOptions()
+	testNs, closer := newTestNamespace(t)
+	defer closer()
+
+	now := opts.ClockOptions().NowFn()()
+	blockStart := xtime.ToUnixNano(now.Truncate(namespace.NewIndexOptions().BlockSize()))
+	numIndexWrites := int32(0)
+	idx := NewMocknamespaceIndex(ctrl)
+	idx.EXPECT().BlockStartForWriteTime(gomock.Any()).Return(blockStart).AnyTimes()
+	idx.EXPECT().WriteBatch(gomock.Any()).Do(
+		func(batch *index.WriteBatch) {
+			atomic.AddInt32(&numIndexWrites, int32(batch.Len()))
+			for _, e := range batch.PendingEntries() {
+				e.OnIndexSeries.OnIndexSuccess(blockStart)
+				e.OnIndexSeries.OnIndexFinalize(blockStart)
+			}
+		}).Return(nil).AnyTimes()
+
+	numCommitLogWrites := int32(0)
+	writer := commitLogWriter(commitLogWriterFn(func(
+		ctx context.Context,
+		series commitlog.Series,
+		datapoint ts.Datapoint,
+		unit xtime.Unit,
+		annotation ts.Annotation,
+	) error {
+		atomic.AddInt32(&numCommitLogWrites, 1)
+		return nil
+	}))
+
+	seriesOpts := NewSeriesOptionsFromOptions(opts, testNs.Options().RetentionOptions())
+	shard := newDatabaseShard(testNs.metadata, 0, nil, nil,
+		&testIncreasingIndex{}, writer, idx, false, opts, seriesOpts).(*dbShard)
+	shard.SetRuntimeOptions(runtime.NewOptions().
+		SetWriteNewSeriesAsync(false))
+	defer shard.Close()
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	datapoints := []ts.Datapoint{
+		{Timestamp: now, Value: 1.0},
+		{Timestamp: now, Value: 1.0},
+		{Timestamp: now.Add(time.Second), Value: 2.0},
+	}
+	errs, err := shard.WriteTaggedBatch(ctx, ident.StringID("foo"),
+		ident.EmptyTagIterator, datapoints, xtime.Second, nil)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(errs))
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&numCommitLogWrites))
+
+	// A second batch against the existing series appends every value
+	datapoints = []ts.Datapoint{
+		{Timestamp: now.Add(time.Second), Value: 2.0},
+		{Timestamp: now.Add(2 * time.Second), Value: 3.0},
+	}
+	errs, err = shard.WriteTaggedBatch(ctx, ident.StringID("foo"),
+		ident.EmptyTagIterator, datapoints, xtime.Second, nil)
+	require.NoError(t, err)
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(5), atomic.LoadInt32(&numCommitLogWrites))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&numIndexWrites))
+	assert.Equal(t, int64(1), shard.NumSeries())
+}
+
 func TestShardWriteAsync(t *testing.T) {
 	testReporter := xmetrics.NewTestStatsReporter(xmetrics.NewTestStatsReporterOptions())
 	scope, closer := tally.NewRootScope(tally.ScopeOptions{
@@ -615,10 +1012,11 @@ func TestShardReturnsErrorForConcurrentTicks(t *testing.T) {
 	closeWg.Add(2)
 
 	// wait to return the other tick has returned error
-	foo.EXPECT().Tick().Do(func() {
+	foo.EXPECT().Tick(gomock.Any()).Do(func(context.Cancellable) {
 		tick1Wg.Done()
 		tick2Wg.Wait()
 	}).Return(series.TickResult{}, nil)
+	foo.EXPECT().WriteMetadata().Return(series.WriteMetadata{})
 
 	go func() {
 		_, err := shard.Tick(context.NewNoOpCanncellable(), time.Now())
@@ -674,7 +1072,7 @@ func TestShardTicksStopWhenClosing(t *testing.T) {
 	orderWg.Add(1)
 	gomock.InOrder(
 		// loop until the shard is marked for Closing
-		foo.EXPECT().Tick().Do(func() {
+		foo.EXPECT().Tick(gomock.Any()).Do(func(context.Cancellable) {
 			orderWg.Done()
 			for {
 				if shard.isClosing() {
@@ -683,6 +1081,7 @@ func TestShardTicksStopWhenClosing(t *testing.T) {
 				time.Sleep(10 * time.Millisecond)
 			}
 		}).Return(series.TickResult{}, nil),
+		foo.EXPECT().WriteMetadata().Return(series.WriteMetadata{}),
 		// for the shard Close purging
 		foo.EXPECT().IsEmpty().Return(true),
 		foo.EXPECT().Close(),
@@ -746,9 +1145,10 @@ func TestPurgeExpiredSeriesWriteAfterTicking(t *testing.T) {
 	defer shard.Close()
 	id := ident.StringID("foo")
 	s := addMockSeries(ctrl, shard, id, ident.Tags{}, 0)
-	s.EXPECT().Tick().Do(func() {
+	s.EXPECT().Tick(gomock.Any()).Do(func(context.Cancellable) {
 		// Emulate a write taking place just after tick for this series
-		s.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		s.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(series.WriteResult{WroteNewDatapoint: true}, nil)
 
 		ctx := opts.ContextPool().Get()
 		nowFn := opts.ClockOptions().NowFn()
@@ -776,7 +1176,7 @@ func TestPurgeExpiredSeriesWriteAfterPurging(t *testing.T) {
 	defer shard.Close()
 	id := ident.StringID("foo")
 	s := addMockSeries(ctrl, shard, id, ident.Tags{}, 0)
-	s.EXPECT().Tick().Do(func() {
+	s.EXPECT().Tick(gomock.Any()).Do(func(context.Cancellable) {
 		// Emulate a write taking place and staying open just after tick for this series
 		var err error
 		entry, err = shard.writableSeries(id, ident.EmptyTagIterator)
@@ -1036,16 +1436,16 @@ func TestShardReadEncodedCachesSeriesWithRecentlyReadPolicy(t *testing.T) {
 	mid := start.Add(ropts.BlockSize())
 
 	retriever.EXPECT().
-		Stream(ctx, shard.shard, ident.NewIDMatcher("foo"),
-			start, shard.seriesOnRetrieveBlock).
-		Do(func(ctx context.Context, shard uint32, id ident.ID, at time.Time, onRetrieve block.OnRetrieveBlock) {
+		StreamWithReadAhead(ctx, shard.shard, ident.NewIDMatcher("foo"),
+			start, mid, shard.seriesOnRetrieveBlock).
+		Do(func(ctx context.Context, shard uint32, id ident.ID, at, readAheadEnd time.Time, onRetrieve block.OnRetrieveBlock) {
 			go onRetrieve.OnRetrieveBlock(id, ident.EmptyTagIterator, at, segments[0])
 		}).
 		Return(blockReaders[0], nil)
 	retriever.EXPECT().
-		Stream(ctx, shard.shard, ident.NewIDMatcher("foo"),
-			mid, shard.seriesOnRetrieveBlock).
-		Do(func(ctx context.Context, shard uint32, id ident.ID, at time.Time, onRetrieve block.OnRetrieveBlock) {
+		StreamWithReadAhead(ctx, shard.shard, ident.NewIDMatcher("foo"),
+			mid, mid, shard.seriesOnRetrieveBlock).
+		Do(func(ctx context.Context, shard uint32, id ident.ID, at, readAheadEnd time.Time, onRetrieve block.OnRetrieveBlock) {
 			go onRetrieve.OnRetrieveBlock(id, ident.EmptyTagIterator, at, segments[1])
 		}).
 		Return(blockReaders[1], nil)
@@ -1086,6 +1486,31 @@ func TestShardReadEncodedCachesSeriesWithRecentlyReadPolicy(t *testing.T) {
 	assert.Equal(t, 2, entry.Series.NumActiveBlocks())
 }
 
+func TestShardReleaseDroppedInsertFinalizesRetrievedBlock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	shard := testDatabaseShard(t, testDatabaseOptions())
+	defer shard.Close()
+
+	head := checked.NewBytes([]byte{1, 2, 3}, nil)
+	tags := ident.NewMockTagIterator(ctrl)
+	tags.EXPECT().Close()
+
+	shard.releaseDroppedInsert(dbShardInsert{
+		opts: dbShardInsertAsyncOptions{
+			hasPendingRetrievedBlock: true,
+			pendingRetrievedBlock: dbShardPendingRetrievedBlock{
+				id:      ident.StringID("foo"),
+				tags:    tags,
+				start:   time.Now(),
+				segment: ts.NewSegment(head, nil, ts.FinalizeNone),
+			},
+		},
+	})
+	require.Equal(t, 0, head.NumRef())
+}
+
 func TestShardNewInvalidShardEntry(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
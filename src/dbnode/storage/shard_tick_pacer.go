@@ -0,0 +1,212 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/runtime"
+
+	"github.com/uber-go/tally"
+)
+
+const (
+	// dbShardTickPacerSampleEvery is the number of writes per write latency
+	// sample, sampling avoids reading the clock twice for every write.
+	dbShardTickPacerSampleEvery = 16
+
+	// dbShardTickPacerMaxSamples is the maximum number of write latency samples
+	// kept between two adjustments, older samples are overwritten when full.
+	dbShardTickPacerMaxSamples = 1024
+
+	// dbShardTickPacerLatencyQuantile is the quantile of the sampled write
+	// latencies compared against the target write latency.
+	dbShardTickPacerLatencyQuantile = 0.99
+)
+
+// dbShardTickPacer paces the ticks of a shard by the write latency observed
+// while ticking, between each batch of series the tick backs off by halving
+// the batch size and doubling the per series sleep while the p99 write
+// latency exceeds the target and otherwise speeds up gradually, always
+// staying within the configured bounds.
+type dbShardTickPacer struct {
+	sync.Mutex
+
+	enabled int32
+	writes  uint64
+
+	pacing         runtime.TickAdaptivePacing
+	samples        []time.Duration
+	sampleCount    int
+	batchSize      int
+	perSeriesSleep time.Duration
+
+	metrics dbShardTickPacerMetrics
+}
+
+type dbShardTickPacerMetrics struct {
+	seriesBatchSize     tally.Gauge
+	perSeriesSleep      tally.Gauge
+	writeLatencyP99     tally.Gauge
+	backoffs            tally.Counter
+	speedups            tally.Counter
+	writeLatencySamples tally.Counter
+}
+
+func newDatabaseShardTickPacerMetrics(scope tally.Scope) dbShardTickPacerMetrics {
+	return dbShardTickPacerMetrics{
+		seriesBatchSize:     scope.Gauge("series-batch-size"),
+		perSeriesSleep:      scope.Gauge("per-series-sleep-seconds"),
+		writeLatencyP99:     scope.Gauge("write-latency-p99-seconds"),
+		backoffs:            scope.Counter("backoffs"),
+		speedups:            scope.Counter("speedups"),
+		writeLatencySamples: scope.Counter("write-latency-samples"),
+	}
+}
+
+func newDatabaseShardTickPacer(scope tally.Scope) *dbShardTickPacer {
+	return &dbShardTickPacer{
+		samples: make([]time.Duration, 0, dbShardTickPacerMaxSamples),
+		metrics: newDatabaseShardTickPacerMetrics(scope),
+	}
+}
+
+// SetPacing sets the adaptive pacing along with the fixed tick series batch
+// size and per series sleep that the pacing starts from, the adjusted values
+// are only reset when the adaptive pacing itself changes.
+func (p *dbShardTickPacer) SetPacing(
+	pacing runtime.TickAdaptivePacing,
+	seriesBatchSize int,
+	perSeriesSleep time.Duration,
+) {
+	p.Lock()
+	defer p.Unlock()
+
+	var enabled int32
+	if pacing.Enabled {
+		enabled = 1
+	}
+	atomic.StoreInt32(&p.enabled, enabled)
+
+	if pacing == p.pacing {
+		return
+	}
+	p.pacing = pacing
+	p.batchSize = clampInt(seriesBatchSize,
+		pacing.MinSeriesBatchSize, pacing.MaxSeriesBatchSize)
+	p.perSeriesSleep = clampDuration(perSeriesSleep,
+		pacing.MinPerSeriesSleepDuration, pacing.MaxPerSeriesSleepDuration)
+	p.samples = p.samples[:0]
+	p.sampleCount = 0
+}
+
+// Enabled returns whether the adaptive pacing is enabled.
+func (p *dbShardTickPacer) Enabled() bool {
+	return atomic.LoadInt32(&p.enabled) == 1
+}
+
+// SampleWrite returns whether the latency of the next write should be
+// recorded, only a fraction of writes are sampled.
+func (p *dbShardTickPacer) SampleWrite() bool {
+	return p.Enabled() &&
+		atomic.AddUint64(&p.writes, 1)%dbShardTickPacerSampleEvery == 0
+}
+
+// RecordWriteLatency records the latency of a sampled write.
+func (p *dbShardTickPacer) RecordWriteLatency(latency time.Duration) {
+	p.Lock()
+	if len(p.samples) < dbShardTickPacerMaxSamples {
+		p.samples = append(p.samples, latency)
+	} else {
+		p.samples[p.sampleCount%dbShardTickPacerMaxSamples] = latency
+	}
+	p.sampleCount++
+	p.Unlock()
+	p.metrics.writeLatencySamples.Inc(1)
+}
+
+// Next adjusts the pacing by the write latencies recorded since it was last
+// called and returns the tick series batch size and per series sleep to use
+// for the next batch of series.
+func (p *dbShardTickPacer) Next() (int, time.Duration) {
+	p.Lock()
+	defer p.Unlock()
+
+	latency := p.writeLatencyQuantileWithLock()
+	p.samples = p.samples[:0]
+	p.sampleCount = 0
+
+	if latency > p.pacing.TargetWriteLatency {
+		// Back off multiplicatively so that contended writes recover quickly.
+		p.batchSize = clampInt(p.batchSize/2,
+			p.pacing.MinSeriesBatchSize, p.pacing.MaxSeriesBatchSize)
+		p.perSeriesSleep = clampDuration(2*p.perSeriesSleep,
+			p.pacing.MinPerSeriesSleepDuration, p.pacing.MaxPerSeriesSleepDuration)
+		p.metrics.backoffs.Inc(1)
+	} else {
+		// Speed up gradually so that the tick only probes for more capacity.
+		p.batchSize = clampInt(p.batchSize+p.batchSize/8+1,
+			p.pacing.MinSeriesBatchSize, p.pacing.MaxSeriesBatchSize)
+		p.perSeriesSleep = clampDuration(p.perSeriesSleep-p.perSeriesSleep/8,
+			p.pacing.MinPerSeriesSleepDuration, p.pacing.MaxPerSeriesSleepDuration)
+		p.metrics.speedups.Inc(1)
+	}
+
+	p.metrics.seriesBatchSize.Update(float64(p.batchSize))
+	p.metrics.perSeriesSleep.Update(p.perSeriesSleep.Seconds())
+	p.metrics.writeLatencyP99.Update(latency.Seconds())
+	return p.batchSize, p.perSeriesSleep
+}
+
+func (p *dbShardTickPacer) writeLatencyQuantileWithLock() time.Duration {
+	if len(p.samples) == 0 {
+		// No writes were sampled so there was nothing to contend with.
+		return 0
+	}
+	sort.Slice(p.samples, func(i, j int) bool {
+		return p.samples[i] < p.samples[j]
+	})
+	idx := int(float64(len(p.samples)-1) * dbShardTickPacerLatencyQuantile)
+	return p.samples[idx]
+}
+
+func clampInt(value, min, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
+
+func clampDuration(value, min, max time.Duration) time.Duration {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/runtime"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func testTickAdaptivePacing() runtime.TickAdaptivePacing {
+	return runtime.TickAdaptivePacing{
+		Enabled:                   true,
+		TargetWriteLatency:        time.Millisecond,
+		MinSeriesBatchSize:        16,
+		MaxSeriesBatchSize:        4096,
+		MinPerSeriesSleepDuration: time.Microsecond,
+		MaxPerSeriesSleepDuration: time.Millisecond,
+	}
+}
+
+func TestShardTickPacerDisabled(t *testing.T) {
+	p := newDatabaseShardTickPacer(tally.NoopScope)
+	assert.False(t, p.Enabled())
+
+	pacing := testTickAdaptivePacing()
+	pacing.Enabled = false
+	p.SetPacing(pacing, 512, 100*time.Microsecond)
+	assert.False(t, p.Enabled())
+	for i := 0; i < 2*dbShardTickPacerSampleEvery; i++ {
+		assert.False(t, p.SampleWrite())
+	}
+}
+
+func TestShardTickPacerSamplesWrites(t *testing.T) {
+	p := newDatabaseShardTickPacer(tally.NoopScope)
+	p.SetPacing(testTickAdaptivePacing(), 512, 100*time.Microsecond)
+	require.True(t, p.Enabled())
+
+	sampled := 0
+	for i := 0; i < 10*dbShardTickPacerSampleEvery; i++ {
+		if p.SampleWrite() {
+			sampled++
+		}
+	}
+	assert.Equal(t, 10, sampled)
+}
+
+func TestShardTickPacerStartsWithinBounds(t *testing.T) {
+	p := newDatabaseShardTickPacer(tally.NoopScope)
+	pacing := testTickAdaptivePacing()
+	p.SetPacing(pacing, 1<<20, time.Nanosecond)
+
+	// Uncontended so speeds up from the clamped values and stays at bounds.
+	batchSize, perSeriesSleep := p.Next()
+	assert.Equal(t, pacing.MaxSeriesBatchSize, batchSize)
+	assert.Equal(t, pacing.MinPerSeriesSleepDuration, perSeriesSleep)
+
+	// Resetting the same pacing keeps the adjusted values.
+	p.RecordWriteLatency(time.Second)
+	p.SetPacing(pacing, 512, 100*time.Microsecond)
+	batchSize, perSeriesSleep = p.Next()
+	assert.Equal(t, pacing.MaxSeriesBatchSize/2, batchSize)
+	assert.Equal(t, 2*pacing.MinPerSeriesSleepDuration, perSeriesSleep)
+}
+
+// TestShardTickPacerConverges simulates a shard ticking batches of series
+// while writes arrive, with the write latency growing with the fraction of
+// time spent ticking rather than sleeping, and checks the pacer settles with
+// the p99 write latency around the target and recovers once writes are no
+// longer contended.
+func TestShardTickPacerConverges(t *testing.T) {
+	var (
+		scope  = tally.NewTestScope("", nil)
+		pacer  = newDatabaseShardTickPacer(scope)
+		pacing = testTickAdaptivePacing()
+		now    = time.Now()
+		nowFn  = func() time.Time { return now }
+	)
+	pacer.SetPacing(pacing, 512, 100*time.Microsecond)
+
+	const (
+		tickPerSeries      = 5 * time.Microsecond
+		writeInterval      = 20 * time.Microsecond
+		writeLatencyBase   = 200 * time.Microsecond
+		contendedRounds    = 200
+		uncontendedRounds  = 100
+		settledAfterRounds = 50
+	)
+	var (
+		contention     = 3 * time.Millisecond
+		batchSize      = 512
+		perSeriesSleep = 100 * time.Microsecond
+		settled        []time.Duration
+	)
+	for round := 0; round < contendedRounds+uncontendedRounds; round++ {
+		if round == contendedRounds {
+			contention = 0
+		}
+
+		// Writes contend with the tick proportionally to its duty cycle.
+		var (
+			ticking  = time.Duration(batchSize) * tickPerSeries
+			sleeping = time.Duration(batchSize) * perSeriesSleep
+			duty     = float64(ticking) / float64(ticking+sleeping)
+			writes   = int((ticking + sleeping) / writeInterval)
+		)
+		for i := 0; i < writes; i++ {
+			if !pacer.SampleWrite() {
+				continue
+			}
+			start := nowFn()
+			now = now.Add(writeLatencyBase + time.Duration(duty*float64(contention)))
+			pacer.RecordWriteLatency(nowFn().Sub(start))
+		}
+
+		batchSize, perSeriesSleep = pacer.Next()
+		require.True(t, batchSize >= pacing.MinSeriesBatchSize)
+		require.True(t, batchSize <= pacing.MaxSeriesBatchSize)
+		require.True(t, perSeriesSleep >= pacing.MinPerSeriesSleepDuration)
+		require.True(t, perSeriesSleep <= pacing.MaxPerSeriesSleepDuration)
+
+		if round >= settledAfterRounds && round < contendedRounds {
+			p99 := time.Duration(scope.Snapshot().
+				Gauges()["write-latency-p99-seconds+"].Value() * float64(time.Second))
+			settled = append(settled, p99)
+		}
+	}
+
+	// While contended the p99 write latency oscillates closely around the target.
+	var sum time.Duration
+	for _, p99 := range settled {
+		assert.True(t, p99 > pacing.TargetWriteLatency/2,
+			"p99 write latency %v too low", p99)
+		assert.True(t, p99 < 3*pacing.TargetWriteLatency/2,
+			"p99 write latency %v too high", p99)
+		sum += p99
+	}
+	mean := sum / time.Duration(len(settled))
+	assert.True(t, mean > 3*pacing.TargetWriteLatency/4, "mean %v", mean)
+	assert.True(t, mean <= pacing.TargetWriteLatency, "mean %v", mean)
+
+	// Once uncontended the tick speeds up to its bounds.
+	assert.Equal(t, pacing.MaxSeriesBatchSize, batchSize)
+	assert.Equal(t, pacing.MinPerSeriesSleepDuration, perSeriesSleep)
+
+	gauges := scope.Snapshot().Gauges()
+	assert.Equal(t, float64(pacing.MaxSeriesBatchSize),
+		gauges["series-batch-size+"].Value())
+	assert.Equal(t, pacing.MinPerSeriesSleepDuration.Seconds(),
+		gauges["per-series-sleep-seconds+"].Value())
+	assert.True(t, scope.Snapshot().Counters()["backoffs+"].Value() > 0)
+}
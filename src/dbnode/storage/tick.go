@@ -207,3 +207,7 @@ func (mgr *tickManager) Tick(forceType forceType, tickStart time.Time) error {
 
 	return multiErr.FinalError()
 }
+
+func (mgr *tickManager) Cancel() {
+	mgr.c.Cancel()
+}
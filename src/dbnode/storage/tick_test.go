@@ -87,6 +87,41 @@ func TestTickManagerTickCancelled(t *testing.T) {
 	wg.Wait()
 }
 
+func TestTickManagerCancel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var wg sync.WaitGroup
+	ch1 := make(chan struct{})
+	ch2 := make(chan struct{})
+	opts := testDatabaseOptions()
+
+	namespace := NewMockdatabaseNamespace(ctrl)
+	namespace.EXPECT().Tick(gomock.Any(), gomock.Any()).Do(func(c context.Cancellable, _ time.Time) {
+		ch1 <- struct{}{}
+		<-ch2
+		require.True(t, c.IsCancelled())
+	})
+	db := newMockdatabase(ctrl, namespace)
+
+	tm := newTickManager(db, opts).(*tickManager)
+	tm.sleepFn = func(time.Duration) {}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		require.Equal(t, errTickCancelled, tm.Tick(noForce, time.Now()))
+		require.Equal(t, 1, len(tm.tokenCh))
+	}()
+
+	// Wait for tick to start
+	<-ch1
+	tm.Cancel()
+	ch2 <- struct{}{}
+	wg.Wait()
+}
+
 func TestTickManagerTickErrorFlow(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -38,6 +38,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3/src/dbnode/storage/repair"
 	"github.com/m3db/m3/src/dbnode/storage/series"
+	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/xcounter"
 	"github.com/m3db/m3/src/dbnode/x/xio"
 	"github.com/m3db/m3x/context"
@@ -80,6 +81,12 @@ type Database interface {
 	// the GC to do so.
 	Terminate() error
 
+	// ShutdownSnapshot snapshots the unflushed in-memory data of every owned shard so
+	// that it can be bootstrapped quickly once restarted, stopping once the deadline has
+	// passed or the abort channel is closed. File operations remain disabled afterwards
+	// as the database is expected to be terminated.
+	ShutdownSnapshot(deadline time.Time, abortCh <-chan struct{}) error
+
 	// Write value to the database for an ID
 	Write(
 		ctx context.Context,
@@ -103,6 +110,20 @@ type Database interface {
 		annotation []byte,
 	) error
 
+	// WriteTaggedBatch writes a batch of values to the database for a single
+	// ID, annotations may be nil or otherwise must be the same length as the
+	// datapoints. Returns an error for each rejected datapoint by index, or an
+	// error if the batch could not be written at all.
+	WriteTaggedBatch(
+		ctx context.Context,
+		namespace ident.ID,
+		id ident.ID,
+		tags ident.TagIterator,
+		datapoints []ts.Datapoint,
+		unit xtime.Unit,
+		annotations [][]byte,
+	) ([]error, error)
+
 	// QueryIDs resolves the given query into known IDs.
 	QueryIDs(
 		ctx context.Context,
@@ -111,6 +132,24 @@ type Database interface {
 		opts index.QueryOptions,
 	) (index.QueryResults, error)
 
+	// AggregateTerms resolves the given query into the terms it matches along with
+	// their document frequencies, ordered by descending frequency. The query must be
+	// a regexp, prefix or wildcard query.
+	AggregateTerms(
+		ctx context.Context,
+		namespace ident.ID,
+		query index.Query,
+		opts index.QueryOptions,
+	) (index.AggregateQueryResults, error)
+
+	// AggregateTagValues returns a page of the distinct values of a tag along with
+	// their approximate document frequencies, in ascending order.
+	AggregateTagValues(
+		ctx context.Context,
+		namespace ident.ID,
+		opts index.AggregateTagValuesOptions,
+	) (index.AggregateTagValuesResults, error)
+
 	// ReadEncoded retrieves encoded segments for an ID
 	ReadEncoded(
 		ctx context.Context,
@@ -119,6 +158,13 @@ type Database interface {
 		start, end time.Time,
 	) ([][]xio.BlockReader, error)
 
+	// BucketsInfo returns a snapshot of the buffer buckets for an ID,
+	// returns nil if the series is not held in memory
+	BucketsInfo(
+		namespace ident.ID,
+		id ident.ID,
+	) ([]series.BucketInfo, error)
+
 	// FetchBlocks retrieves data blocks for a given id and a list of block start times.
 	FetchBlocks(
 		ctx context.Context,
@@ -169,10 +215,46 @@ type Database interface {
 	// Truncate truncates data for the given namespace
 	Truncate(namespace ident.ID) (int64, error)
 
+	// ForceFlush flushes the data of the given namespace in the time range of
+	// the options to persistent storage without waiting for a tick.
+	ForceFlush(namespace ident.ID, opts ForceFlushOptions) (ForceFlushResult, error)
+
 	// BootstrapState captures and returns a snapshot of the databases' bootstrap state.
 	BootstrapState() DatabaseBootstrapState
 }
 
+// ForceFlushOptions are the options for a force flush of a namespace.
+type ForceFlushOptions struct {
+	// Start is the inclusive start of the range of block starts to flush.
+	Start time.Time
+
+	// End is the exclusive end of the range of block starts to flush.
+	End time.Time
+
+	// Shards restricts the flush to the given shards, every owned shard
+	// is flushed if empty.
+	Shards []uint32
+
+	// Force allows writing out blocks that can still be written to, including
+	// the current block. These are written as snapshots rather than flushed so
+	// that the data written to them afterwards is still flushed by a tick.
+	Force bool
+}
+
+// ForceFlushResult is the result of a force flush.
+type ForceFlushResult struct {
+	// NumSeriesFlushed is the number of series blocks flushed.
+	NumSeriesFlushed int64
+
+	// NumBytesWritten is the number of bytes of series data flushed.
+	NumBytesWritten int64
+}
+
+func (r *ForceFlushResult) add(other ForceFlushResult) {
+	r.NumSeriesFlushed += other.NumSeriesFlushed
+	r.NumBytesWritten += other.NumBytesWritten
+}
+
 // database is the internal database interface
 type database interface {
 	Database
@@ -247,6 +329,16 @@ type databaseNamespace interface {
 		annotation []byte,
 	) error
 
+	// WriteTaggedBatch writes a batch of values to the namespace for an ID
+	WriteTaggedBatch(
+		ctx context.Context,
+		id ident.ID,
+		tags ident.TagIterator,
+		datapoints []ts.Datapoint,
+		unit xtime.Unit,
+		annotations [][]byte,
+	) ([]error, error)
+
 	// QueryIDs resolves the given query into known IDs.
 	QueryIDs(
 		ctx context.Context,
@@ -254,6 +346,21 @@ type databaseNamespace interface {
 		opts index.QueryOptions,
 	) (index.QueryResults, error)
 
+	// AggregateTerms resolves the given query into the terms it matches along with
+	// their document frequencies.
+	AggregateTerms(
+		ctx context.Context,
+		query index.Query,
+		opts index.QueryOptions,
+	) (index.AggregateQueryResults, error)
+
+	// AggregateTagValues returns a page of the distinct values of a tag along with
+	// their approximate document frequencies.
+	AggregateTagValues(
+		ctx context.Context,
+		opts index.AggregateTagValuesOptions,
+	) (index.AggregateTagValuesResults, error)
+
 	// ReadEncoded reads data for given id within [start, end)
 	ReadEncoded(
 		ctx context.Context,
@@ -261,6 +368,9 @@ type databaseNamespace interface {
 		start, end time.Time,
 	) ([][]xio.BlockReader, error)
 
+	// BucketsInfo returns a snapshot of the buffer buckets for an ID
+	BucketsInfo(id ident.ID) ([]series.BucketInfo, error)
+
 	// FetchBlocks retrieves data blocks for a given id and a list of block start times.
 	FetchBlocks(
 		ctx context.Context,
@@ -325,6 +435,14 @@ type databaseNamespace interface {
 	// Truncate truncates the in-memory data for this namespace
 	Truncate() (int64, error)
 
+	// ForceFlush flushes the data of the shards of the options for the given
+	// block start, skipping shards that have already flushed it.
+	ForceFlush(
+		blockStart time.Time,
+		opts ForceFlushOptions,
+		flush persist.DataFlush,
+	) (ForceFlushResult, error)
+
 	// Repair repairs the namespace data for a given time range
 	Repair(repairer databaseShardRepairer, tr xtime.Range) error
 
@@ -381,12 +499,25 @@ type databaseShard interface {
 		annotation []byte,
 	) error
 
+	// WriteTaggedBatch writes a batch of values to the shard for an ID
+	WriteTaggedBatch(
+		ctx context.Context,
+		id ident.ID,
+		tags ident.TagIterator,
+		datapoints []ts.Datapoint,
+		unit xtime.Unit,
+		annotations [][]byte,
+	) ([]error, error)
+
 	ReadEncoded(
 		ctx context.Context,
 		id ident.ID,
 		start, end time.Time,
 	) ([][]xio.BlockReader, error)
 
+	// BucketsInfo returns a snapshot of the buffer buckets for an ID
+	BucketsInfo(id ident.ID) ([]series.BucketInfo, error)
+
 	// FetchBlocks retrieves data blocks for a given id and a list of block start times.
 	FetchBlocks(
 		ctx context.Context,
@@ -423,6 +554,15 @@ type databaseShard interface {
 		flush persist.DataFlush,
 	) error
 
+	// ForceFlush drains and flushes the series' in this shard for the given
+	// block start, snapshotting the buffered data of a block that can still be
+	// written to if force is set.
+	ForceFlush(
+		blockStart time.Time,
+		force bool,
+		flush persist.DataFlush,
+	) (ForceFlushResult, error)
+
 	// Snapshot snapshot's the unflushed series' in this shard.
 	Snapshot(blockStart, snapshotStart time.Time, flush persist.DataFlush) error
 
@@ -466,6 +606,22 @@ type namespaceIndex interface {
 		opts index.QueryOptions,
 	) (index.QueryResults, error)
 
+	// AggregateTerms resolves the given query into the terms it matches along with
+	// their document frequencies.
+	AggregateTerms(
+		ctx context.Context,
+		query index.Query,
+		opts index.QueryOptions,
+	) (index.AggregateQueryResults, error)
+
+	// AggregateTagValues returns a page of the distinct values of a tag along with
+	// their approximate document frequencies, from the blocks overlapping the time
+	// range of the options.
+	AggregateTagValues(
+		ctx context.Context,
+		opts index.AggregateTagValuesOptions,
+	) (index.AggregateTagValuesResults, error)
+
 	// Bootstrap bootstraps the index the provided segments.
 	Bootstrap(
 		bootstrapResults result.IndexResults,
@@ -493,11 +649,13 @@ type namespaceIndex interface {
 // namespaceIndexTickResult are details about the work performed by the namespaceIndex
 // during a Tick().
 type namespaceIndexTickResult struct {
-	NumBlocks        int64
-	NumBlocksSealed  int64
-	NumBlocksEvicted int64
-	NumSegments      int64
-	NumTotalDocs     int64
+	NumBlocks          int64
+	NumBlocksSealed    int64
+	NumBlocksEvicted   int64
+	NumSegments        int64
+	NumTotalDocs       int64
+	NumSegmentsEvicted int64
+	NumDocsEvicted     int64
 }
 
 // namespaceIndexInsertQueue is a queue used in-front of the indexing component
@@ -533,6 +691,21 @@ type databaseFlushManager interface {
 	// Flush flushes in-memory data to persistent storage.
 	Flush(tickStart time.Time, dbBootstrapStateAtTickStart DatabaseBootstrapState) error
 
+	// ShutdownSnapshot snapshots the unflushed in-memory data of every owned shard
+	// regardless of the minimum snapshot interval, stopping before snapshotting the
+	// next shard once the deadline has passed or the abort channel is closed.
+	ShutdownSnapshot(snapshotTime time.Time, deadline time.Time, abortCh <-chan struct{}) error
+
+	// FlushNamespace flushes the in-memory data of a single namespace to persistent
+	// storage, snapshotting every shard's unflushed data regardless of the minimum
+	// snapshot interval.
+	FlushNamespace(ns databaseNamespace, tickStart time.Time) error
+
+	// ForceFlush flushes the in-memory data of a single namespace in the time range
+	// of the options to persistent storage, only flushing blocks that can still be
+	// written to if the force option is set.
+	ForceFlush(ns databaseNamespace, opts ForceFlushOptions, t time.Time) (ForceFlushResult, error)
+
 	// Report reports runtime information
 	Report()
 }
@@ -554,6 +727,21 @@ type databaseFileSystemManager interface {
 	// Flush flushes in-memory data to persistent storage.
 	Flush(t time.Time, dbBootstrapStateAtTickStart DatabaseBootstrapState) error
 
+	// ShutdownSnapshot snapshots the unflushed in-memory data of every owned shard
+	// regardless of the minimum snapshot interval, stopping before snapshotting the
+	// next shard once the deadline has passed or the abort channel is closed.
+	ShutdownSnapshot(snapshotTime time.Time, deadline time.Time, abortCh <-chan struct{}) error
+
+	// FlushNamespace flushes the in-memory data of a single namespace to persistent
+	// storage, returning errFileOpsUnavailable if file operations are disabled or
+	// already in progress.
+	FlushNamespace(ns databaseNamespace, t time.Time) error
+
+	// ForceFlush flushes the in-memory data of a single namespace in the time range
+	// of the options to persistent storage, returning errFileOpsUnavailable if file
+	// operations are disabled or already in progress.
+	ForceFlush(ns databaseNamespace, opts ForceFlushOptions, t time.Time) (ForceFlushResult, error)
+
 	// Disable disables the filesystem manager and prevents it from
 	// performing file operations, returns the current file operation status
 	Disable() fileOpStatus
@@ -612,6 +800,10 @@ type databaseTickManager interface {
 	// tick if force is true. It returns nil if a new tick has
 	// completed successfully, and an error otherwise.
 	Tick(forceType forceType, tickStart time.Time) error
+
+	// Cancel cancels the tick in progress if any, abandoning any
+	// merges being performed by the tick.
+	Cancel()
 }
 
 // databaseMediator mediates actions among various database managers
@@ -631,6 +823,21 @@ type databaseMediator interface {
 	// EnableFileOps enables file operations
 	EnableFileOps()
 
+	// ShutdownSnapshot disables file operations and then snapshots the unflushed in-memory
+	// data of every owned shard, stopping once the deadline has passed or the abort
+	// channel is closed.
+	ShutdownSnapshot(deadline time.Time, abortCh <-chan struct{}) error
+
+	// FlushNamespace flushes the in-memory data of a single namespace to persistent
+	// storage, returning errFileOpsUnavailable if file operations are disabled or
+	// already in progress.
+	FlushNamespace(ns databaseNamespace) error
+
+	// ForceFlush flushes the in-memory data of a single namespace in the time range
+	// of the options to persistent storage, returning errFileOpsUnavailable if file
+	// operations are disabled or already in progress.
+	ForceFlush(ns databaseNamespace, opts ForceFlushOptions) (ForceFlushResult, error)
+
 	// Tick performs a tick
 	Tick(runType runType, forceType forceType) error
 
@@ -730,6 +937,14 @@ type Options interface {
 	// RepairEnabled returns whether the repair is enabled.
 	RepairEnabled() bool
 
+	// SetNamespaceRemovalFlushEnabled sets whether the data of a namespace removed
+	// at runtime is flushed to disk before the namespace is released.
+	SetNamespaceRemovalFlushEnabled(value bool) Options
+
+	// NamespaceRemovalFlushEnabled returns whether the data of a namespace removed
+	// at runtime is flushed to disk before the namespace is released.
+	NamespaceRemovalFlushEnabled() bool
+
 	// SetRepairOptions sets the repair options.
 	SetRepairOptions(value repair.Options) Options
 
@@ -848,8 +1063,20 @@ type Options interface {
 
 	// QueryIDsWorkerPool returns the QueryIDs worker pool.
 	QueryIDsWorkerPool() xsync.WorkerPool
+
+	// SetNamespaceEncoderPoolFn sets the function used to create the encoder
+	// pool of each namespace, if nil namespaces share the encoder pool.
+	SetNamespaceEncoderPoolFn(value NewNamespaceEncoderPoolFn) Options
+
+	// NamespaceEncoderPoolFn returns the function used to create the encoder
+	// pool of each namespace, if nil namespaces share the encoder pool.
+	NamespaceEncoderPoolFn() NewNamespaceEncoderPoolFn
 }
 
+// NewNamespaceEncoderPoolFn returns a new encoder pool for the series of a
+// namespace, the pool reports its accounting with the instrument options.
+type NewNamespaceEncoderPoolFn func(iopts instrument.Options) encoding.EncoderPool
+
 // DatabaseBootstrapState stores a snapshot of the bootstrap state for all shards across all
 // namespaces at a given moment in time.
 type DatabaseBootstrapState struct {
@@ -0,0 +1,123 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"sync/atomic"
+
+	"github.com/m3db/m3/src/dbnode/runtime"
+	"github.com/m3db/m3x/ident"
+
+	"github.com/spaolacci/murmur3"
+	"github.com/uber-go/tally"
+)
+
+// writeShadowingHashSeed seeds the hash of the series IDs that selects the
+// series mirrored so that the selection is independent of the sharding of
+// the series, which hashes the IDs without a seed.
+const writeShadowingHashSeed = 0x9e3779b9
+
+// writeShadowingTarget is a namespace that writes are mirrored to.
+type writeShadowingTarget struct {
+	namespace ident.ID
+
+	// threshold is the fraction of series mirrored scaled to the range of
+	// the hash, series whose hash is below it are mirrored.
+	threshold uint64
+}
+
+// writeShadowingRules are the targets of each source namespace, sorted by
+// descending threshold so that the targets a series is mirrored to are a
+// prefix of them.
+type writeShadowingRules map[string][]writeShadowingTarget
+
+type writeShadowingMetrics struct {
+	writes tally.Counter
+	errors tally.Counter
+}
+
+func newWriteShadowingMetrics(scope tally.Scope) writeShadowingMetrics {
+	scope = scope.SubScope("write-shadowing")
+	return writeShadowingMetrics{
+		writes: scope.Counter("writes"),
+		errors: scope.Counter("errors"),
+	}
+}
+
+// writeShadower selects the writes mirrored by the write shadowing rules of
+// the runtime options, the rules are swapped atomically as they are updated
+// so that selecting the writes never contends.
+type writeShadower struct {
+	rules   atomic.Value
+	metrics writeShadowingMetrics
+}
+
+func newWriteShadower(scope tally.Scope) *writeShadower {
+	s := &writeShadower{metrics: newWriteShadowingMetrics(scope)}
+	s.rules.Store(writeShadowingRules(nil))
+	return s
+}
+
+func (s *writeShadower) SetRuntimeOptions(value runtime.Options) {
+	var rules writeShadowingRules
+	for _, rule := range value.WriteShadowingRules() {
+		if !rule.Enabled {
+			continue
+		}
+		if rules == nil {
+			rules = make(writeShadowingRules)
+		}
+		target := writeShadowingTarget{
+			namespace: ident.StringID(rule.TargetNamespace),
+			threshold: uint64(rule.Fraction * (1 << 32)),
+		}
+		targets := rules[rule.SourceNamespace]
+		i := len(targets)
+		for i > 0 && targets[i-1].threshold < target.threshold {
+			i--
+		}
+		targets = append(targets, writeShadowingTarget{})
+		copy(targets[i+1:], targets[i:])
+		targets[i] = target
+		rules[rule.SourceNamespace] = targets
+	}
+	s.rules.Store(rules)
+}
+
+// targets returns the namespaces that writes of the series to the namespace
+// are mirrored to, this is a single hash of the series ID and a compare per
+// target when the namespace has rules.
+func (s *writeShadower) targets(namespace, id ident.ID) []writeShadowingTarget {
+	rules := s.rules.Load().(writeShadowingRules)
+	if len(rules) == 0 {
+		return nil
+	}
+	targets := rules[string(namespace.Bytes())]
+	if len(targets) == 0 {
+		return nil
+	}
+	hash := uint64(murmur3.Sum32WithSeed(id.Bytes(), writeShadowingHashSeed))
+	n := 0
+	for n < len(targets) && hash < targets[n].threshold {
+		n++
+	}
+	return targets[:n]
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/m3db/m3/src/dbnode/runtime"
+	"github.com/m3db/m3x/ident"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func newTestWriteShadower(rules ...runtime.WriteShadowingRule) *writeShadower {
+	s := newWriteShadower(tally.NoopScope)
+	s.SetRuntimeOptions(runtime.NewOptions().SetWriteShadowingRules(rules))
+	return s
+}
+
+func TestWriteShadowerNoRules(t *testing.T) {
+	s := newTestWriteShadower()
+	assert.Nil(t, s.targets(ident.StringID("src"), ident.StringID("foo")))
+}
+
+func TestWriteShadowerConsistentTargets(t *testing.T) {
+	s := newTestWriteShadower(runtime.WriteShadowingRule{
+		SourceNamespace: "src",
+		TargetNamespace: "dst",
+		Fraction:        0.5,
+		Enabled:         true,
+	})
+
+	src := ident.StringID("src")
+	for i := 0; i < 1000; i++ {
+		id := ident.StringID(fmt.Sprintf("foo.%d", i))
+		mirrored := len(s.targets(src, id)) > 0
+		for j := 0; j < 10; j++ {
+			require.Equal(t, mirrored, len(s.targets(src, id)) > 0)
+		}
+	}
+}
+
+func TestWriteShadowerFraction(t *testing.T) {
+	tests := []struct {
+		fraction float64
+		minimum  int
+		maximum  int
+	}{
+		{fraction: 0, minimum: 0, maximum: 0},
+		{fraction: 0.05, minimum: 400, maximum: 600},
+		{fraction: 0.5, minimum: 4750, maximum: 5250},
+		{fraction: 1, minimum: 10000, maximum: 10000},
+	}
+
+	src := ident.StringID("src")
+	for _, test := range tests {
+		s := newTestWriteShadower(runtime.WriteShadowingRule{
+			SourceNamespace: "src",
+			TargetNamespace: "dst",
+			Fraction:        test.fraction,
+			Enabled:         true,
+		})
+
+		mirrored := 0
+		for i := 0; i < 10000; i++ {
+			targets := s.targets(src, ident.StringID(fmt.Sprintf("foo.%d", i)))
+			if len(targets) > 0 {
+				require.Equal(t, "dst", targets[0].namespace.String())
+				mirrored++
+			}
+		}
+		assert.True(t, mirrored >= test.minimum && mirrored <= test.maximum,
+			"fraction %v mirrored %d of 10000", test.fraction, mirrored)
+	}
+}
+
+func TestWriteShadowerTargetsSubsetOfLargerFractions(t *testing.T) {
+	s := newTestWriteShadower(
+		runtime.WriteShadowingRule{
+			SourceNamespace: "src",
+			TargetNamespace: "small",
+			Fraction:        0.1,
+			Enabled:         true,
+		},
+		runtime.WriteShadowingRule{
+			SourceNamespace: "src",
+			TargetNamespace: "large",
+			Fraction:        0.6,
+			Enabled:         true,
+		},
+	)
+
+	src := ident.StringID("src")
+	for i := 0; i < 1000; i++ {
+		targets := s.targets(src, ident.StringID(fmt.Sprintf("foo.%d", i)))
+		switch len(targets) {
+		case 0:
+		case 1:
+			require.Equal(t, "large", targets[0].namespace.String())
+		case 2:
+			require.Equal(t, "large", targets[0].namespace.String())
+			require.Equal(t, "small", targets[1].namespace.String())
+		default:
+			require.FailNow(t, "unexpected targets", "%d targets", len(targets))
+		}
+	}
+}
+
+func TestWriteShadowerIgnoresDisabledRulesAndOtherNamespaces(t *testing.T) {
+	s := newTestWriteShadower(
+		runtime.WriteShadowingRule{
+			SourceNamespace: "src",
+			TargetNamespace: "dst",
+			Fraction:        1,
+			Enabled:         false,
+		},
+		runtime.WriteShadowingRule{
+			SourceNamespace: "other",
+			TargetNamespace: "dst",
+			Fraction:        1,
+			Enabled:         true,
+		},
+	)
+
+	id := ident.StringID("foo")
+	assert.Empty(t, s.targets(ident.StringID("src"), id))
+	assert.Empty(t, s.targets(ident.StringID("unknown"), id))
+	assert.Len(t, s.targets(ident.StringID("other"), id), 1)
+
+	// Removing the rules stops the mirroring.
+	s.SetRuntimeOptions(runtime.NewOptions())
+	assert.Empty(t, s.targets(ident.StringID("other"), id))
+}
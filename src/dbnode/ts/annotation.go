@@ -0,0 +1,106 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ts
+
+import (
+	"fmt"
+)
+
+const (
+	// metricTypeAnnotationMarker is reserved in the high bits of the first
+	// byte of an annotation to mark that the low bits hold a MetricType.
+	metricTypeAnnotationMarker byte = 0xd0
+	metricTypeAnnotationMask   byte = 0xf0
+)
+
+// MetricType is the type of metric a series of datapoints represents.
+type MetricType byte
+
+const (
+	// MetricTypeUnknown is a metric of unknown type, it is also the type of
+	// datapoints whose annotation does not carry a metric type.
+	MetricTypeUnknown MetricType = iota
+	// MetricTypeGauge is a gauge metric.
+	MetricTypeGauge
+	// MetricTypeCounter is a counter metric.
+	MetricTypeCounter
+	// MetricTypeTimer is a timer metric.
+	MetricTypeTimer
+)
+
+var validMetricTypes = []MetricType{
+	MetricTypeUnknown,
+	MetricTypeGauge,
+	MetricTypeCounter,
+	MetricTypeTimer,
+}
+
+// IsValid returns whether the metric type is a known metric type.
+func (t MetricType) IsValid() bool {
+	for _, valid := range validMetricTypes {
+		if t == valid {
+			return true
+		}
+	}
+	return false
+}
+
+func (t MetricType) String() string {
+	switch t {
+	case MetricTypeUnknown:
+		return "unknown"
+	case MetricTypeGauge:
+		return "gauge"
+	case MetricTypeCounter:
+		return "counter"
+	case MetricTypeTimer:
+		return "timer"
+	}
+	return fmt.Sprintf("MetricType(%d)", byte(t))
+}
+
+// EncodeMetricTypeAnnotation returns an annotation that carries the metric
+// type in its first byte followed by the free form annotation rest.
+func EncodeMetricTypeAnnotation(t MetricType, rest []byte) (Annotation, error) {
+	if !t.IsValid() {
+		return nil, fmt.Errorf("invalid metric type: %d", byte(t))
+	}
+	annotation := make(Annotation, 1+len(rest))
+	annotation[0] = metricTypeAnnotationMarker | byte(t)
+	copy(annotation[1:], rest)
+	return annotation, nil
+}
+
+// DecodeMetricTypeAnnotation returns the metric type carried by an annotation
+// and the free form remainder of the annotation, if the annotation does not
+// carry a metric type it returns MetricTypeUnknown, the annotation as is and
+// false. The returned remainder references the annotation and is not a copy.
+func DecodeMetricTypeAnnotation(annotation Annotation) (MetricType, Annotation, bool) {
+	if len(annotation) == 0 ||
+		annotation[0]&metricTypeAnnotationMask != metricTypeAnnotationMarker {
+		return MetricTypeUnknown, annotation, false
+	}
+	t := MetricType(annotation[0] &^ metricTypeAnnotationMask)
+	if !t.IsValid() {
+		return MetricTypeUnknown, annotation, false
+	}
+	return t, annotation[1:], true
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricTypeAnnotationRoundTrip(t *testing.T) {
+	for _, metricType := range validMetricTypes {
+		annotation, err := EncodeMetricTypeAnnotation(metricType, []byte("rest"))
+		require.NoError(t, err)
+
+		decoded, rest, ok := DecodeMetricTypeAnnotation(annotation)
+		require.True(t, ok)
+		assert.Equal(t, metricType, decoded)
+		assert.Equal(t, Annotation("rest"), rest)
+	}
+
+	annotation, err := EncodeMetricTypeAnnotation(MetricTypeCounter, nil)
+	require.NoError(t, err)
+	decoded, rest, ok := DecodeMetricTypeAnnotation(annotation)
+	require.True(t, ok)
+	assert.Equal(t, MetricTypeCounter, decoded)
+	assert.Len(t, rest, 0)
+}
+
+func TestMetricTypeAnnotationInvalid(t *testing.T) {
+	_, err := EncodeMetricTypeAnnotation(MetricType(12), nil)
+	assert.Error(t, err)
+
+	for _, annotation := range []Annotation{
+		nil,
+		Annotation("free form"),
+		Annotation{0xdc, 'a'},
+	} {
+		decoded, rest, ok := DecodeMetricTypeAnnotation(annotation)
+		assert.False(t, ok)
+		assert.Equal(t, MetricTypeUnknown, decoded)
+		assert.Equal(t, annotation, rest)
+	}
+}
@@ -37,3 +37,17 @@ func (d Datapoint) Equal(x Datapoint) bool {
 
 // Annotation represents information used to annotate datapoints.
 type Annotation []byte
+
+// DatapointStats summarizes the datapoints of an encoded block so that they
+// can be inspected without decoding the block, the zero value denotes the
+// stats are unknown.
+type DatapointStats struct {
+	FirstTimestamp time.Time
+	LastTimestamp  time.Time
+	Count          int
+}
+
+// IsZero returns whether the stats are unknown.
+func (s DatapointStats) IsZero() bool {
+	return s.Count == 0
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xio
+
+import (
+	"sort"
+
+	"github.com/m3db/m3x/pool"
+)
+
+// BlockReaders is a pooled slice of block readers, finalizing it returns the
+// slice to the pool it was acquired from so it can be registered as a finalizer
+// with the context of a read without allocating.
+type BlockReaders struct {
+	Readers []BlockReader
+
+	pool BlockReadersPool
+}
+
+// Finalize returns the block readers to their pool.
+func (b *BlockReaders) Finalize() {
+	if b.pool == nil {
+		return
+	}
+	b.pool.Put(b)
+}
+
+type blockReadersPool struct {
+	sizesAsc          []pool.Bucket
+	buckets           []blockReadersPoolBucket
+	maxBucketCapacity int
+}
+
+type blockReadersPoolBucket struct {
+	capacity int
+	values   chan *BlockReaders
+}
+
+// NewBlockReadersPool creates a new pool
+func NewBlockReadersPool(sizes []pool.Bucket) BlockReadersPool {
+	sizesAsc := make([]pool.Bucket, len(sizes))
+	copy(sizesAsc, sizes)
+	sort.Sort(pool.BucketByCapacity(sizesAsc))
+	var maxBucketCapacity int
+	if len(sizesAsc) != 0 {
+		maxBucketCapacity = sizesAsc[len(sizesAsc)-1].Capacity
+	}
+	return &blockReadersPool{sizesAsc: sizesAsc, maxBucketCapacity: maxBucketCapacity}
+}
+
+func (p *blockReadersPool) alloc(capacity int) *BlockReaders {
+	return &BlockReaders{Readers: make([]BlockReader, 0, capacity), pool: p}
+}
+
+func (p *blockReadersPool) Init() {
+	buckets := make([]blockReadersPoolBucket, len(p.sizesAsc))
+	for i := range p.sizesAsc {
+		buckets[i].capacity = p.sizesAsc[i].Capacity
+		buckets[i].values = make(chan *BlockReaders, p.sizesAsc[i].Count)
+		for j := 0; j < p.sizesAsc[i].Count; j++ {
+			buckets[i].values <- p.alloc(p.sizesAsc[i].Capacity)
+		}
+	}
+	p.buckets = buckets
+}
+
+func (p *blockReadersPool) Get(capacity int) *BlockReaders {
+	if capacity > p.maxBucketCapacity {
+		return p.alloc(capacity)
+	}
+	for i := range p.buckets {
+		if p.buckets[i].capacity >= capacity {
+			select {
+			case b := <-p.buckets[i].values:
+				return b
+			default:
+				// NB: use the bucket's capacity so can potentially
+				// be returned to pool when it's finished with.
+				return p.alloc(p.buckets[i].capacity)
+			}
+		}
+	}
+	return p.alloc(capacity)
+}
+
+func (p *blockReadersPool) Put(readers *BlockReaders) {
+	capacity := cap(readers.Readers)
+	if capacity > p.maxBucketCapacity {
+		return
+	}
+
+	for i := range readers.Readers {
+		readers.Readers[i] = EmptyBlockReader
+	}
+	readers.Readers = readers.Readers[:0]
+	// NB: return to the largest bucket the readers can satisfy so that a Get
+	// from that bucket always receives at least the requested capacity.
+	for i := len(p.buckets) - 1; i >= 0; i-- {
+		if p.buckets[i].capacity <= capacity {
+			select {
+			case p.buckets[i].values <- readers:
+				return
+			default:
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package xio
+
+import (
+	"testing"
+
+	"github.com/m3db/m3x/pool"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBlockReadersPool() BlockReadersPool {
+	p := NewBlockReadersPool([]pool.Bucket{
+		{Capacity: 1, Count: 1},
+		{Capacity: 2, Count: 1},
+		{Capacity: 4, Count: 1},
+	})
+	p.Init()
+	return p
+}
+
+func TestBlockReadersPoolGetCapacity(t *testing.T) {
+	p := newTestBlockReadersPool()
+
+	for _, test := range []struct {
+		capacity    int
+		expectedCap int
+	}{
+		{capacity: 0, expectedCap: 1},
+		{capacity: 1, expectedCap: 1},
+		{capacity: 2, expectedCap: 2},
+		{capacity: 3, expectedCap: 4},
+		{capacity: 4, expectedCap: 4},
+		{capacity: 5, expectedCap: 5},
+	} {
+		readers := p.Get(test.capacity)
+		require.Equal(t, 0, len(readers.Readers))
+		require.Equal(t, test.expectedCap, cap(readers.Readers), "capacity %d", test.capacity)
+	}
+}
+
+func TestBlockReadersPoolFinalizeReuses(t *testing.T) {
+	p := newTestBlockReadersPool()
+
+	readers := p.Get(3)
+	readers.Readers = append(readers.Readers, BlockReader{Start: start, BlockSize: blockSize})
+	backing := readers.Readers[:1]
+	readers.Finalize()
+
+	// NB: the readers are cleared before being returned to the pool.
+	require.Equal(t, EmptyBlockReader, backing[0])
+	// NB: drain the bucket's preallocated readers so the returned ones are reused.
+	reused := false
+	for i := 0; i < 2; i++ {
+		if p.Get(4) == readers {
+			reused = true
+		}
+	}
+	require.True(t, reused)
+	require.Equal(t, 0, len(readers.Readers))
+}
+
+func TestBlockReadersPoolPutOversized(t *testing.T) {
+	p := newTestBlockReadersPool()
+
+	readers := p.Get(8)
+	readers.Finalize()
+	for i := 0; i < 2; i++ {
+		require.False(t, p.Get(4) == readers)
+	}
+}
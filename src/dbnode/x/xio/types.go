@@ -65,6 +65,18 @@ type SegmentReaderPool interface {
 	Put(sr SegmentReader)
 }
 
+// BlockReadersPool provides a pool for slices of block readers, bucketed by capacity
+type BlockReadersPool interface {
+	// Init initializes the pool
+	Init()
+
+	// Get provides block readers with a slice of at least the given capacity from the pool
+	Get(capacity int) *BlockReaders
+
+	// Put returns block readers to the pool
+	Put(readers *BlockReaders)
+}
+
 // ReaderSliceOfSlicesIterator is an iterator that iterates through an array of reader arrays
 type ReaderSliceOfSlicesIterator interface {
 	// Next moves to the next item
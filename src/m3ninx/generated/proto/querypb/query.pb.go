@@ -1,6 +1,7 @@
 // Code generated by protoc-gen-gogo. DO NOT EDIT.
 // source: github.com/m3db/m3/src/m3ninx/generated/proto/querypb/query.proto
 
+
 // Copyright (c) 2018 Uber Technologies, Inc.
 //
 // Permission is hereby granted, free of charge, to any person obtaining a copy
@@ -30,6 +31,13 @@
 	It has these top-level messages:
 		TermQuery
 		RegexpQuery
+		FuzzyQuery
+		PrefixQuery
+		WildcardQuery
+		RangeQuery
+		FieldQuery
+		MatchAllQuery
+		MatchNoneQuery
 		NegationQuery
 		ConjunctionQuery
 		DisjunctionQuery
@@ -55,8 +63,10 @@ var _ = math.Inf
 const _ = proto.GoGoProtoPackageIsVersion2 // please upgrade the proto package
 
 type TermQuery struct {
-	Field []byte `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
-	Term  []byte `protobuf:"bytes,2,opt,name=term,proto3" json:"term,omitempty"`
+	Field           []byte `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Term            []byte `protobuf:"bytes,2,opt,name=term,proto3" json:"term,omitempty"`
+	CaseInsensitive bool   `protobuf:"varint,3,opt,name=case_insensitive,json=caseInsensitive,proto3" json:"case_insensitive,omitempty"`
+	UnicodeFolding  bool   `protobuf:"varint,4,opt,name=unicode_folding,json=unicodeFolding,proto3" json:"unicode_folding,omitempty"`
 }
 
 func (m *TermQuery) Reset()                    { *m = TermQuery{} }
@@ -78,6 +88,20 @@ func (m *TermQuery) GetTerm() []byte {
 	return nil
 }
 
+func (m *TermQuery) GetCaseInsensitive() bool {
+	if m != nil {
+		return m.CaseInsensitive
+	}
+	return false
+}
+
+func (m *TermQuery) GetUnicodeFolding() bool {
+	if m != nil {
+		return m.UnicodeFolding
+	}
+	return false
+}
+
 type RegexpQuery struct {
 	Field  []byte `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
 	Regexp []byte `protobuf:"bytes,2,opt,name=regexp,proto3" json:"regexp,omitempty"`
@@ -102,6 +126,190 @@ func (m *RegexpQuery) GetRegexp() []byte {
 	return nil
 }
 
+type FuzzyQuery struct {
+	Field           []byte `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Term            []byte `protobuf:"bytes,2,opt,name=term,proto3" json:"term,omitempty"`
+	MaxEditDistance int32  `protobuf:"varint,3,opt,name=max_edit_distance,json=maxEditDistance,proto3" json:"max_edit_distance,omitempty"`
+}
+
+func (m *FuzzyQuery) Reset()                    { *m = FuzzyQuery{} }
+func (m *FuzzyQuery) String() string            { return proto.CompactTextString(m) }
+func (*FuzzyQuery) ProtoMessage()               {}
+func (*FuzzyQuery) Descriptor() ([]byte, []int) { return fileDescriptorQuery, []int{2} }
+
+func (m *FuzzyQuery) GetField() []byte {
+	if m != nil {
+		return m.Field
+	}
+	return nil
+}
+
+func (m *FuzzyQuery) GetTerm() []byte {
+	if m != nil {
+		return m.Term
+	}
+	return nil
+}
+
+func (m *FuzzyQuery) GetMaxEditDistance() int32 {
+	if m != nil {
+		return m.MaxEditDistance
+	}
+	return 0
+}
+
+type PrefixQuery struct {
+	Field           []byte `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Prefix          []byte `protobuf:"bytes,2,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	CaseInsensitive bool   `protobuf:"varint,3,opt,name=case_insensitive,json=caseInsensitive,proto3" json:"case_insensitive,omitempty"`
+	UnicodeFolding  bool   `protobuf:"varint,4,opt,name=unicode_folding,json=unicodeFolding,proto3" json:"unicode_folding,omitempty"`
+}
+
+func (m *PrefixQuery) Reset()                    { *m = PrefixQuery{} }
+func (m *PrefixQuery) String() string            { return proto.CompactTextString(m) }
+func (*PrefixQuery) ProtoMessage()               {}
+func (*PrefixQuery) Descriptor() ([]byte, []int) { return fileDescriptorQuery, []int{3} }
+
+func (m *PrefixQuery) GetField() []byte {
+	if m != nil {
+		return m.Field
+	}
+	return nil
+}
+
+func (m *PrefixQuery) GetPrefix() []byte {
+	if m != nil {
+		return m.Prefix
+	}
+	return nil
+}
+
+func (m *PrefixQuery) GetCaseInsensitive() bool {
+	if m != nil {
+		return m.CaseInsensitive
+	}
+	return false
+}
+
+func (m *PrefixQuery) GetUnicodeFolding() bool {
+	if m != nil {
+		return m.UnicodeFolding
+	}
+	return false
+}
+
+type WildcardQuery struct {
+	Field   []byte `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Pattern []byte `protobuf:"bytes,2,opt,name=pattern,proto3" json:"pattern,omitempty"`
+}
+
+func (m *WildcardQuery) Reset()                    { *m = WildcardQuery{} }
+func (m *WildcardQuery) String() string            { return proto.CompactTextString(m) }
+func (*WildcardQuery) ProtoMessage()               {}
+func (*WildcardQuery) Descriptor() ([]byte, []int) { return fileDescriptorQuery, []int{4} }
+
+func (m *WildcardQuery) GetField() []byte {
+	if m != nil {
+		return m.Field
+	}
+	return nil
+}
+
+func (m *WildcardQuery) GetPattern() []byte {
+	if m != nil {
+		return m.Pattern
+	}
+	return nil
+}
+
+type RangeQuery struct {
+	Field          []byte `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Lower          []byte `protobuf:"bytes,2,opt,name=lower,proto3" json:"lower,omitempty"`
+	Upper          []byte `protobuf:"bytes,3,opt,name=upper,proto3" json:"upper,omitempty"`
+	LowerInclusive bool   `protobuf:"varint,4,opt,name=lower_inclusive,json=lowerInclusive,proto3" json:"lower_inclusive,omitempty"`
+	UpperInclusive bool   `protobuf:"varint,5,opt,name=upper_inclusive,json=upperInclusive,proto3" json:"upper_inclusive,omitempty"`
+	Numeric        bool   `protobuf:"varint,6,opt,name=numeric,proto3" json:"numeric,omitempty"`
+}
+
+func (m *RangeQuery) Reset()                    { *m = RangeQuery{} }
+func (m *RangeQuery) String() string            { return proto.CompactTextString(m) }
+func (*RangeQuery) ProtoMessage()               {}
+func (*RangeQuery) Descriptor() ([]byte, []int) { return fileDescriptorQuery, []int{5} }
+
+func (m *RangeQuery) GetField() []byte {
+	if m != nil {
+		return m.Field
+	}
+	return nil
+}
+
+func (m *RangeQuery) GetLower() []byte {
+	if m != nil {
+		return m.Lower
+	}
+	return nil
+}
+
+func (m *RangeQuery) GetUpper() []byte {
+	if m != nil {
+		return m.Upper
+	}
+	return nil
+}
+
+func (m *RangeQuery) GetLowerInclusive() bool {
+	if m != nil {
+		return m.LowerInclusive
+	}
+	return false
+}
+
+func (m *RangeQuery) GetUpperInclusive() bool {
+	if m != nil {
+		return m.UpperInclusive
+	}
+	return false
+}
+
+func (m *RangeQuery) GetNumeric() bool {
+	if m != nil {
+		return m.Numeric
+	}
+	return false
+}
+
+type FieldQuery struct {
+	Field []byte `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+}
+
+func (m *FieldQuery) Reset()                    { *m = FieldQuery{} }
+func (m *FieldQuery) String() string            { return proto.CompactTextString(m) }
+func (*FieldQuery) ProtoMessage()               {}
+func (*FieldQuery) Descriptor() ([]byte, []int) { return fileDescriptorQuery, []int{6} }
+
+func (m *FieldQuery) GetField() []byte {
+	if m != nil {
+		return m.Field
+	}
+	return nil
+}
+
+type MatchAllQuery struct {
+}
+
+func (m *MatchAllQuery) Reset()                    { *m = MatchAllQuery{} }
+func (m *MatchAllQuery) String() string            { return proto.CompactTextString(m) }
+func (*MatchAllQuery) ProtoMessage()               {}
+func (*MatchAllQuery) Descriptor() ([]byte, []int) { return fileDescriptorQuery, []int{7} }
+
+type MatchNoneQuery struct {
+}
+
+func (m *MatchNoneQuery) Reset()                    { *m = MatchNoneQuery{} }
+func (m *MatchNoneQuery) String() string            { return proto.CompactTextString(m) }
+func (*MatchNoneQuery) ProtoMessage()               {}
+func (*MatchNoneQuery) Descriptor() ([]byte, []int) { return fileDescriptorQuery, []int{8} }
+
 type NegationQuery struct {
 	Query *Query `protobuf:"bytes,1,opt,name=query" json:"query,omitempty"`
 }
@@ -109,7 +317,7 @@ type NegationQuery struct {
 func (m *NegationQuery) Reset()                    { *m = NegationQuery{} }
 func (m *NegationQuery) String() string            { return proto.CompactTextString(m) }
 func (*NegationQuery) ProtoMessage()               {}
-func (*NegationQuery) Descriptor() ([]byte, []int) { return fileDescriptorQuery, []int{2} }
+func (*NegationQuery) Descriptor() ([]byte, []int) { return fileDescriptorQuery, []int{9} }
 
 func (m *NegationQuery) GetQuery() *Query {
 	if m != nil {
@@ -125,7 +333,7 @@ type ConjunctionQuery struct {
 func (m *ConjunctionQuery) Reset()                    { *m = ConjunctionQuery{} }
 func (m *ConjunctionQuery) String() string            { return proto.CompactTextString(m) }
 func (*ConjunctionQuery) ProtoMessage()               {}
-func (*ConjunctionQuery) Descriptor() ([]byte, []int) { return fileDescriptorQuery, []int{3} }
+func (*ConjunctionQuery) Descriptor() ([]byte, []int) { return fileDescriptorQuery, []int{10} }
 
 func (m *ConjunctionQuery) GetQueries() []*Query {
 	if m != nil {
@@ -141,7 +349,7 @@ type DisjunctionQuery struct {
 func (m *DisjunctionQuery) Reset()                    { *m = DisjunctionQuery{} }
 func (m *DisjunctionQuery) String() string            { return proto.CompactTextString(m) }
 func (*DisjunctionQuery) ProtoMessage()               {}
-func (*DisjunctionQuery) Descriptor() ([]byte, []int) { return fileDescriptorQuery, []int{4} }
+func (*DisjunctionQuery) Descriptor() ([]byte, []int) { return fileDescriptorQuery, []int{11} }
 
 func (m *DisjunctionQuery) GetQueries() []*Query {
 	if m != nil {
@@ -157,13 +365,20 @@ type Query struct {
 	//	*Query_Negation
 	//	*Query_Conjunction
 	//	*Query_Disjunction
+	//	*Query_Fuzzy
+	//	*Query_Prefix
+	//	*Query_Wildcard
+	//	*Query_Range
+	//	*Query_MatchAll
+	//	*Query_MatchNone
+	//	*Query_Field
 	Query isQuery_Query `protobuf_oneof:"query"`
 }
 
 func (m *Query) Reset()                    { *m = Query{} }
 func (m *Query) String() string            { return proto.CompactTextString(m) }
 func (*Query) ProtoMessage()               {}
-func (*Query) Descriptor() ([]byte, []int) { return fileDescriptorQuery, []int{5} }
+func (*Query) Descriptor() ([]byte, []int) { return fileDescriptorQuery, []int{12} }
 
 type isQuery_Query interface {
 	isQuery_Query()
@@ -186,12 +401,40 @@ type Query_Conjunction struct {
 type Query_Disjunction struct {
 	Disjunction *DisjunctionQuery `protobuf:"bytes,5,opt,name=disjunction,oneof"`
 }
+type Query_Fuzzy struct {
+	Fuzzy *FuzzyQuery `protobuf:"bytes,6,opt,name=fuzzy,oneof"`
+}
+type Query_Prefix struct {
+	Prefix *PrefixQuery `protobuf:"bytes,7,opt,name=prefix,oneof"`
+}
+type Query_Wildcard struct {
+	Wildcard *WildcardQuery `protobuf:"bytes,8,opt,name=wildcard,oneof"`
+}
+type Query_Range struct {
+	Range *RangeQuery `protobuf:"bytes,9,opt,name=range,oneof"`
+}
+type Query_MatchAll struct {
+	MatchAll *MatchAllQuery `protobuf:"bytes,10,opt,name=match_all,json=matchAll,oneof"`
+}
+type Query_MatchNone struct {
+	MatchNone *MatchNoneQuery `protobuf:"bytes,11,opt,name=match_none,json=matchNone,oneof"`
+}
+type Query_Field struct {
+	Field *FieldQuery `protobuf:"bytes,12,opt,name=field,oneof"`
+}
 
 func (*Query_Term) isQuery_Query()        {}
 func (*Query_Regexp) isQuery_Query()      {}
 func (*Query_Negation) isQuery_Query()    {}
 func (*Query_Conjunction) isQuery_Query() {}
 func (*Query_Disjunction) isQuery_Query() {}
+func (*Query_Fuzzy) isQuery_Query()       {}
+func (*Query_Prefix) isQuery_Query()      {}
+func (*Query_Wildcard) isQuery_Query()    {}
+func (*Query_Range) isQuery_Query()       {}
+func (*Query_MatchAll) isQuery_Query()    {}
+func (*Query_MatchNone) isQuery_Query()   {}
+func (*Query_Field) isQuery_Query()       {}
 
 func (m *Query) GetQuery() isQuery_Query {
 	if m != nil {
@@ -235,6 +478,55 @@ func (m *Query) GetDisjunction() *DisjunctionQuery {
 	return nil
 }
 
+func (m *Query) GetFuzzy() *FuzzyQuery {
+	if x, ok := m.GetQuery().(*Query_Fuzzy); ok {
+		return x.Fuzzy
+	}
+	return nil
+}
+
+func (m *Query) GetPrefix() *PrefixQuery {
+	if x, ok := m.GetQuery().(*Query_Prefix); ok {
+		return x.Prefix
+	}
+	return nil
+}
+
+func (m *Query) GetWildcard() *WildcardQuery {
+	if x, ok := m.GetQuery().(*Query_Wildcard); ok {
+		return x.Wildcard
+	}
+	return nil
+}
+
+func (m *Query) GetRange() *RangeQuery {
+	if x, ok := m.GetQuery().(*Query_Range); ok {
+		return x.Range
+	}
+	return nil
+}
+
+func (m *Query) GetMatchAll() *MatchAllQuery {
+	if x, ok := m.GetQuery().(*Query_MatchAll); ok {
+		return x.MatchAll
+	}
+	return nil
+}
+
+func (m *Query) GetMatchNone() *MatchNoneQuery {
+	if x, ok := m.GetQuery().(*Query_MatchNone); ok {
+		return x.MatchNone
+	}
+	return nil
+}
+
+func (m *Query) GetField() *FieldQuery {
+	if x, ok := m.GetQuery().(*Query_Field); ok {
+		return x.Field
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*Query) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _Query_OneofMarshaler, _Query_OneofUnmarshaler, _Query_OneofSizer, []interface{}{
@@ -243,6 +535,13 @@ func (*Query) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error,
 		(*Query_Negation)(nil),
 		(*Query_Conjunction)(nil),
 		(*Query_Disjunction)(nil),
+		(*Query_Fuzzy)(nil),
+		(*Query_Prefix)(nil),
+		(*Query_Wildcard)(nil),
+		(*Query_Range)(nil),
+		(*Query_MatchAll)(nil),
+		(*Query_MatchNone)(nil),
+		(*Query_Field)(nil),
 	}
 }
 
@@ -275,6 +574,41 @@ func _Query_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.Disjunction); err != nil {
 			return err
 		}
+	case *Query_Fuzzy:
+		_ = b.EncodeVarint(6<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Fuzzy); err != nil {
+			return err
+		}
+	case *Query_Prefix:
+		_ = b.EncodeVarint(7<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Prefix); err != nil {
+			return err
+		}
+	case *Query_Wildcard:
+		_ = b.EncodeVarint(8<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Wildcard); err != nil {
+			return err
+		}
+	case *Query_Range:
+		_ = b.EncodeVarint(9<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Range); err != nil {
+			return err
+		}
+	case *Query_MatchAll:
+		_ = b.EncodeVarint(10<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.MatchAll); err != nil {
+			return err
+		}
+	case *Query_MatchNone:
+		_ = b.EncodeVarint(11<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.MatchNone); err != nil {
+			return err
+		}
+	case *Query_Field:
+		_ = b.EncodeVarint(12<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Field); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("Query.Query has unexpected type %T", x)
@@ -325,6 +659,62 @@ func _Query_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer)
 		err := b.DecodeMessage(msg)
 		m.Query = &Query_Disjunction{msg}
 		return true, err
+	case 6: // query.fuzzy
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(FuzzyQuery)
+		err := b.DecodeMessage(msg)
+		m.Query = &Query_Fuzzy{msg}
+		return true, err
+	case 7: // query.prefix
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(PrefixQuery)
+		err := b.DecodeMessage(msg)
+		m.Query = &Query_Prefix{msg}
+		return true, err
+	case 8: // query.wildcard
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(WildcardQuery)
+		err := b.DecodeMessage(msg)
+		m.Query = &Query_Wildcard{msg}
+		return true, err
+	case 9: // query.range
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(RangeQuery)
+		err := b.DecodeMessage(msg)
+		m.Query = &Query_Range{msg}
+		return true, err
+	case 10: // query.match_all
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(MatchAllQuery)
+		err := b.DecodeMessage(msg)
+		m.Query = &Query_MatchAll{msg}
+		return true, err
+	case 11: // query.match_none
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(MatchNoneQuery)
+		err := b.DecodeMessage(msg)
+		m.Query = &Query_MatchNone{msg}
+		return true, err
+	case 12: // query.field
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(FieldQuery)
+		err := b.DecodeMessage(msg)
+		m.Query = &Query_Field{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -359,6 +749,41 @@ func _Query_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(5<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *Query_Fuzzy:
+		s := proto.Size(x.Fuzzy)
+		n += proto.SizeVarint(6<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Query_Prefix:
+		s := proto.Size(x.Prefix)
+		n += proto.SizeVarint(7<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Query_Wildcard:
+		s := proto.Size(x.Wildcard)
+		n += proto.SizeVarint(8<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Query_Range:
+		s := proto.Size(x.Range)
+		n += proto.SizeVarint(9<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Query_MatchAll:
+		s := proto.Size(x.MatchAll)
+		n += proto.SizeVarint(10<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Query_MatchNone:
+		s := proto.Size(x.MatchNone)
+		n += proto.SizeVarint(11<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Query_Field:
+		s := proto.Size(x.Field)
+		n += proto.SizeVarint(12<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -369,6 +794,13 @@ func _Query_OneofSizer(msg proto.Message) (n int) {
 func init() {
 	proto.RegisterType((*TermQuery)(nil), "query.TermQuery")
 	proto.RegisterType((*RegexpQuery)(nil), "query.RegexpQuery")
+	proto.RegisterType((*FuzzyQuery)(nil), "query.FuzzyQuery")
+	proto.RegisterType((*PrefixQuery)(nil), "query.PrefixQuery")
+	proto.RegisterType((*WildcardQuery)(nil), "query.WildcardQuery")
+	proto.RegisterType((*RangeQuery)(nil), "query.RangeQuery")
+	proto.RegisterType((*FieldQuery)(nil), "query.FieldQuery")
+	proto.RegisterType((*MatchAllQuery)(nil), "query.MatchAllQuery")
+	proto.RegisterType((*MatchNoneQuery)(nil), "query.MatchNoneQuery")
 	proto.RegisterType((*NegationQuery)(nil), "query.NegationQuery")
 	proto.RegisterType((*ConjunctionQuery)(nil), "query.ConjunctionQuery")
 	proto.RegisterType((*DisjunctionQuery)(nil), "query.DisjunctionQuery")
@@ -401,6 +833,26 @@ func (m *TermQuery) MarshalTo(dAtA []byte) (int, error) {
 		i = encodeVarintQuery(dAtA, i, uint64(len(m.Term)))
 		i += copy(dAtA[i:], m.Term)
 	}
+	if m.CaseInsensitive {
+		dAtA[i] = 0x18
+		i++
+		if m.CaseInsensitive {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.UnicodeFolding {
+		dAtA[i] = 0x20
+		i++
+		if m.UnicodeFolding {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
 	return i, nil
 }
 
@@ -434,7 +886,7 @@ func (m *RegexpQuery) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
-func (m *NegationQuery) Marshal() (dAtA []byte, err error) {
+func (m *FuzzyQuery) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalTo(dAtA)
@@ -444,25 +896,32 @@ func (m *NegationQuery) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *NegationQuery) MarshalTo(dAtA []byte) (int, error) {
+func (m *FuzzyQuery) MarshalTo(dAtA []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
 	_ = l
-	if m.Query != nil {
+	if len(m.Field) > 0 {
 		dAtA[i] = 0xa
 		i++
-		i = encodeVarintQuery(dAtA, i, uint64(m.Query.Size()))
-		n1, err := m.Query.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n1
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.Field)))
+		i += copy(dAtA[i:], m.Field)
+	}
+	if len(m.Term) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.Term)))
+		i += copy(dAtA[i:], m.Term)
+	}
+	if m.MaxEditDistance != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(m.MaxEditDistance))
 	}
 	return i, nil
 }
 
-func (m *ConjunctionQuery) Marshal() (dAtA []byte, err error) {
+func (m *PrefixQuery) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalTo(dAtA)
@@ -472,22 +931,256 @@ func (m *ConjunctionQuery) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *ConjunctionQuery) MarshalTo(dAtA []byte) (int, error) {
+func (m *PrefixQuery) MarshalTo(dAtA []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
 	_ = l
-	if len(m.Queries) > 0 {
-		for _, msg := range m.Queries {
-			dAtA[i] = 0xa
-			i++
-			i = encodeVarintQuery(dAtA, i, uint64(msg.Size()))
-			n, err := msg.MarshalTo(dAtA[i:])
-			if err != nil {
-				return 0, err
-			}
-			i += n
-		}
+	if len(m.Field) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.Field)))
+		i += copy(dAtA[i:], m.Field)
+	}
+	if len(m.Prefix) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.Prefix)))
+		i += copy(dAtA[i:], m.Prefix)
+	}
+	if m.CaseInsensitive {
+		dAtA[i] = 0x18
+		i++
+		if m.CaseInsensitive {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.UnicodeFolding {
+		dAtA[i] = 0x20
+		i++
+		if m.UnicodeFolding {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	return i, nil
+}
+
+func (m *WildcardQuery) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *WildcardQuery) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Field) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.Field)))
+		i += copy(dAtA[i:], m.Field)
+	}
+	if len(m.Pattern) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.Pattern)))
+		i += copy(dAtA[i:], m.Pattern)
+	}
+	return i, nil
+}
+
+func (m *RangeQuery) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RangeQuery) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Field) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.Field)))
+		i += copy(dAtA[i:], m.Field)
+	}
+	if len(m.Lower) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.Lower)))
+		i += copy(dAtA[i:], m.Lower)
+	}
+	if len(m.Upper) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.Upper)))
+		i += copy(dAtA[i:], m.Upper)
+	}
+	if m.LowerInclusive {
+		dAtA[i] = 0x20
+		i++
+		if m.LowerInclusive {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.UpperInclusive {
+		dAtA[i] = 0x28
+		i++
+		if m.UpperInclusive {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.Numeric {
+		dAtA[i] = 0x30
+		i++
+		if m.Numeric {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	return i, nil
+}
+
+func (m *FieldQuery) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *FieldQuery) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Field) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.Field)))
+		i += copy(dAtA[i:], m.Field)
+	}
+	return i, nil
+}
+
+func (m *MatchAllQuery) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *MatchAllQuery) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	return i, nil
+}
+
+func (m *MatchNoneQuery) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *MatchNoneQuery) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	return i, nil
+}
+
+func (m *NegationQuery) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *NegationQuery) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Query != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(m.Query.Size()))
+		n1, err := m.Query.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n1
+	}
+	return i, nil
+}
+
+func (m *ConjunctionQuery) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ConjunctionQuery) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Queries) > 0 {
+		for _, msg := range m.Queries {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintQuery(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
 	}
 	return i, nil
 }
@@ -617,6 +1310,104 @@ func (m *Query_Disjunction) MarshalTo(dAtA []byte) (int, error) {
 	}
 	return i, nil
 }
+func (m *Query_Fuzzy) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.Fuzzy != nil {
+		dAtA[i] = 0x32
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(m.Fuzzy.Size()))
+		n8, err := m.Fuzzy.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n8
+	}
+	return i, nil
+}
+func (m *Query_Prefix) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.Prefix != nil {
+		dAtA[i] = 0x3a
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(m.Prefix.Size()))
+		n9, err := m.Prefix.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n9
+	}
+	return i, nil
+}
+func (m *Query_Wildcard) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.Wildcard != nil {
+		dAtA[i] = 0x42
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(m.Wildcard.Size()))
+		n10, err := m.Wildcard.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n10
+	}
+	return i, nil
+}
+func (m *Query_Range) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.Range != nil {
+		dAtA[i] = 0x4a
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(m.Range.Size()))
+		n11, err := m.Range.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n11
+	}
+	return i, nil
+}
+func (m *Query_MatchAll) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.MatchAll != nil {
+		dAtA[i] = 0x52
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(m.MatchAll.Size()))
+		n12, err := m.MatchAll.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n12
+	}
+	return i, nil
+}
+func (m *Query_MatchNone) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.MatchNone != nil {
+		dAtA[i] = 0x5a
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(m.MatchNone.Size()))
+		n13, err := m.MatchNone.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n13
+	}
+	return i, nil
+}
+func (m *Query_Field) MarshalTo(dAtA []byte) (int, error) {
+	i := 0
+	if m.Field != nil {
+		dAtA[i] = 0x62
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(m.Field.Size()))
+		n14, err := m.Field.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n14
+	}
+	return i, nil
+}
 func encodeVarintQuery(dAtA []byte, offset int, v uint64) int {
 	for v >= 1<<7 {
 		dAtA[offset] = uint8(v&0x7f | 0x80)
@@ -637,6 +1428,12 @@ func (m *TermQuery) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovQuery(uint64(l))
 	}
+	if m.CaseInsensitive {
+		n += 2
+	}
+	if m.UnicodeFolding {
+		n += 2
+	}
 	return n
 }
 
@@ -654,11 +1451,111 @@ func (m *RegexpQuery) Size() (n int) {
 	return n
 }
 
-func (m *NegationQuery) Size() (n int) {
+func (m *FuzzyQuery) Size() (n int) {
 	var l int
 	_ = l
-	if m.Query != nil {
-		l = m.Query.Size()
+	l = len(m.Field)
+	if l > 0 {
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	l = len(m.Term)
+	if l > 0 {
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	if m.MaxEditDistance != 0 {
+		n += 1 + sovQuery(uint64(m.MaxEditDistance))
+	}
+	return n
+}
+
+func (m *PrefixQuery) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Field)
+	if l > 0 {
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	l = len(m.Prefix)
+	if l > 0 {
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	if m.CaseInsensitive {
+		n += 2
+	}
+	if m.UnicodeFolding {
+		n += 2
+	}
+	return n
+}
+
+func (m *WildcardQuery) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Field)
+	if l > 0 {
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	l = len(m.Pattern)
+	if l > 0 {
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	return n
+}
+
+func (m *RangeQuery) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Field)
+	if l > 0 {
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	l = len(m.Lower)
+	if l > 0 {
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	l = len(m.Upper)
+	if l > 0 {
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	if m.LowerInclusive {
+		n += 2
+	}
+	if m.UpperInclusive {
+		n += 2
+	}
+	if m.Numeric {
+		n += 2
+	}
+	return n
+}
+
+func (m *FieldQuery) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Field)
+	if l > 0 {
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	return n
+}
+
+func (m *MatchAllQuery) Size() (n int) {
+	var l int
+	_ = l
+	return n
+}
+
+func (m *MatchNoneQuery) Size() (n int) {
+	var l int
+	_ = l
+	return n
+}
+
+func (m *NegationQuery) Size() (n int) {
+	var l int
+	_ = l
+	if m.Query != nil {
+		l = m.Query.Size()
 		n += 1 + l + sovQuery(uint64(l))
 	}
 	return n
@@ -740,23 +1637,633 @@ func (m *Query_Disjunction) Size() (n int) {
 		l = m.Disjunction.Size()
 		n += 1 + l + sovQuery(uint64(l))
 	}
-	return n
-}
+	return n
+}
+func (m *Query_Fuzzy) Size() (n int) {
+	var l int
+	_ = l
+	if m.Fuzzy != nil {
+		l = m.Fuzzy.Size()
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	return n
+}
+func (m *Query_Prefix) Size() (n int) {
+	var l int
+	_ = l
+	if m.Prefix != nil {
+		l = m.Prefix.Size()
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	return n
+}
+func (m *Query_Wildcard) Size() (n int) {
+	var l int
+	_ = l
+	if m.Wildcard != nil {
+		l = m.Wildcard.Size()
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	return n
+}
+func (m *Query_Range) Size() (n int) {
+	var l int
+	_ = l
+	if m.Range != nil {
+		l = m.Range.Size()
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	return n
+}
+func (m *Query_MatchAll) Size() (n int) {
+	var l int
+	_ = l
+	if m.MatchAll != nil {
+		l = m.MatchAll.Size()
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	return n
+}
+func (m *Query_MatchNone) Size() (n int) {
+	var l int
+	_ = l
+	if m.MatchNone != nil {
+		l = m.MatchNone.Size()
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	return n
+}
+func (m *Query_Field) Size() (n int) {
+	var l int
+	_ = l
+	if m.Field != nil {
+		l = m.Field.Size()
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	return n
+}
+
+func sovQuery(x uint64) (n int) {
+	for {
+		n++
+		x >>= 7
+		if x == 0 {
+			break
+		}
+	}
+	return n
+}
+func sozQuery(x uint64) (n int) {
+	return sovQuery(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *TermQuery) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQuery
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TermQuery: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TermQuery: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Field", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Field = append(m.Field[:0], dAtA[iNdEx:postIndex]...)
+			if m.Field == nil {
+				m.Field = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Term", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Term = append(m.Term[:0], dAtA[iNdEx:postIndex]...)
+			if m.Term == nil {
+				m.Term = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CaseInsensitive", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.CaseInsensitive = bool(v != 0)
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field UnicodeFolding", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.UnicodeFolding = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQuery(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *RegexpQuery) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQuery
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RegexpQuery: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RegexpQuery: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Field", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Field = append(m.Field[:0], dAtA[iNdEx:postIndex]...)
+			if m.Field == nil {
+				m.Field = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Regexp", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Regexp = append(m.Regexp[:0], dAtA[iNdEx:postIndex]...)
+			if m.Regexp == nil {
+				m.Regexp = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQuery(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *FuzzyQuery) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQuery
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: FuzzyQuery: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: FuzzyQuery: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Field", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Field = append(m.Field[:0], dAtA[iNdEx:postIndex]...)
+			if m.Field == nil {
+				m.Field = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Term", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Term = append(m.Term[:0], dAtA[iNdEx:postIndex]...)
+			if m.Term == nil {
+				m.Term = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxEditDistance", wireType)
+			}
+			m.MaxEditDistance = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxEditDistance |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQuery(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *PrefixQuery) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQuery
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PrefixQuery: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PrefixQuery: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Field", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Field = append(m.Field[:0], dAtA[iNdEx:postIndex]...)
+			if m.Field == nil {
+				m.Field = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Prefix", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Prefix = append(m.Prefix[:0], dAtA[iNdEx:postIndex]...)
+			if m.Prefix == nil {
+				m.Prefix = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CaseInsensitive", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.CaseInsensitive = bool(v != 0)
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field UnicodeFolding", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.UnicodeFolding = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQuery(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
 
-func sovQuery(x uint64) (n int) {
-	for {
-		n++
-		x >>= 7
-		if x == 0 {
-			break
-		}
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
 	}
-	return n
-}
-func sozQuery(x uint64) (n int) {
-	return sovQuery(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+	return nil
 }
-func (m *TermQuery) Unmarshal(dAtA []byte) error {
+func (m *WildcardQuery) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -779,10 +2286,10 @@ func (m *TermQuery) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: TermQuery: wiretype end group for non-group")
+			return fmt.Errorf("proto: WildcardQuery: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: TermQuery: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: WildcardQuery: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
@@ -818,7 +2325,7 @@ func (m *TermQuery) Unmarshal(dAtA []byte) error {
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Term", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Pattern", wireType)
 			}
 			var byteLen int
 			for shift := uint(0); ; shift += 7 {
@@ -842,9 +2349,9 @@ func (m *TermQuery) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Term = append(m.Term[:0], dAtA[iNdEx:postIndex]...)
-			if m.Term == nil {
-				m.Term = []byte{}
+			m.Pattern = append(m.Pattern[:0], dAtA[iNdEx:postIndex]...)
+			if m.Pattern == nil {
+				m.Pattern = []byte{}
 			}
 			iNdEx = postIndex
 		default:
@@ -868,7 +2375,7 @@ func (m *TermQuery) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *RegexpQuery) Unmarshal(dAtA []byte) error {
+func (m *RangeQuery) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -891,10 +2398,10 @@ func (m *RegexpQuery) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: RegexpQuery: wiretype end group for non-group")
+			return fmt.Errorf("proto: RangeQuery: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: RegexpQuery: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: RangeQuery: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
@@ -930,7 +2437,179 @@ func (m *RegexpQuery) Unmarshal(dAtA []byte) error {
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Regexp", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Lower", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Lower = append(m.Lower[:0], dAtA[iNdEx:postIndex]...)
+			if m.Lower == nil {
+				m.Lower = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Upper", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Upper = append(m.Upper[:0], dAtA[iNdEx:postIndex]...)
+			if m.Upper == nil {
+				m.Upper = []byte{}
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LowerInclusive", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.LowerInclusive = bool(v != 0)
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field UpperInclusive", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.UpperInclusive = bool(v != 0)
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Numeric", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Numeric = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQuery(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *FieldQuery) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQuery
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: FieldQuery: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: FieldQuery: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Field", wireType)
 			}
 			var byteLen int
 			for shift := uint(0); ; shift += 7 {
@@ -947,18 +2626,118 @@ func (m *RegexpQuery) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
-			if byteLen < 0 {
+			if byteLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Field = append(m.Field[:0], dAtA[iNdEx:postIndex]...)
+			if m.Field == nil {
+				m.Field = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQuery(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *MatchAllQuery) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQuery
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: MatchAllQuery: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: MatchAllQuery: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQuery(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
 				return ErrInvalidLengthQuery
 			}
-			postIndex := iNdEx + byteLen
-			if postIndex > l {
+			if (iNdEx + skippy) > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Regexp = append(m.Regexp[:0], dAtA[iNdEx:postIndex]...)
-			if m.Regexp == nil {
-				m.Regexp = []byte{}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *MatchNoneQuery) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQuery
 			}
-			iNdEx = postIndex
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: MatchNoneQuery: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: MatchNoneQuery: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
 		default:
 			iNdEx = preIndex
 			skippy, err := skipQuery(dAtA[iNdEx:])
@@ -1414,6 +3193,230 @@ func (m *Query) Unmarshal(dAtA []byte) error {
 			}
 			m.Query = &Query_Disjunction{v}
 			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Fuzzy", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &FuzzyQuery{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Query = &Query_Fuzzy{v}
+			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Prefix", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &PrefixQuery{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Query = &Query_Prefix{v}
+			iNdEx = postIndex
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Wildcard", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &WildcardQuery{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Query = &Query_Wildcard{v}
+			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Range", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &RangeQuery{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Query = &Query_Range{v}
+			iNdEx = postIndex
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MatchAll", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &MatchAllQuery{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Query = &Query_MatchAll{v}
+			iNdEx = postIndex
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MatchNone", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &MatchNoneQuery{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Query = &Query_MatchNone{v}
+			iNdEx = postIndex
+		case 12:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Field", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &FieldQuery{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Query = &Query_Field{v}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipQuery(dAtA[iNdEx:])
@@ -1545,27 +3548,49 @@ func init() {
 }
 
 var fileDescriptorQuery = []byte{
-	// 343 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x92, 0xbd, 0x4e, 0xc3, 0x30,
-	0x14, 0x85, 0xe3, 0xb6, 0x69, 0xe1, 0xa6, 0x48, 0x95, 0x55, 0x81, 0x59, 0xa2, 0x2a, 0x03, 0x62,
-	0x40, 0xb5, 0xd4, 0x88, 0x85, 0x4e, 0x14, 0x86, 0x4c, 0x48, 0x44, 0x4c, 0x6c, 0x4d, 0x62, 0x82,
-	0x11, 0x76, 0x8a, 0x9b, 0x4a, 0xe5, 0x2d, 0x18, 0x78, 0x28, 0x46, 0x1e, 0x01, 0x95, 0x17, 0x41,
-	0xb1, 0xdd, 0x5f, 0x24, 0x06, 0xa6, 0xe4, 0xfa, 0x9e, 0x4f, 0x3a, 0xf9, 0x62, 0xb8, 0xcc, 0x79,
-	0xf9, 0x38, 0x4b, 0xfa, 0x69, 0x21, 0xa8, 0x08, 0xb3, 0x84, 0x8a, 0x90, 0x4e, 0x55, 0x4a, 0x45,
-	0x28, 0xb9, 0x9c, 0xd3, 0x9c, 0x49, 0xa6, 0xc6, 0x25, 0xcb, 0xe8, 0x44, 0x15, 0x65, 0x41, 0x5f,
-	0x66, 0x4c, 0xbd, 0x4e, 0x12, 0xf3, 0xec, 0xeb, 0x33, 0xec, 0xea, 0x21, 0x38, 0x87, 0xfd, 0x3b,
-	0xa6, 0xc4, 0x6d, 0x35, 0xe0, 0x2e, 0xb8, 0x0f, 0x9c, 0x3d, 0x67, 0x04, 0xf5, 0xd0, 0x69, 0x3b,
-	0x36, 0x03, 0xc6, 0xd0, 0x28, 0x99, 0x12, 0xa4, 0xa6, 0x0f, 0xf5, 0x7b, 0x30, 0x04, 0x2f, 0x66,
-	0x39, 0x9b, 0x4f, 0xfe, 0x02, 0x0f, 0xa1, 0xa9, 0x74, 0xc8, 0xa2, 0x76, 0x0a, 0x42, 0x38, 0xb8,
-	0x61, 0xf9, 0xb8, 0xe4, 0x85, 0x34, 0x78, 0x00, 0xa6, 0x8d, 0xc6, 0xbd, 0x41, 0xbb, 0x6f, 0x8a,
-	0xea, 0x65, 0x6c, 0x8b, 0x5e, 0x40, 0xe7, 0xaa, 0x90, 0x4f, 0x33, 0x99, 0xae, 0xb9, 0x13, 0x68,
-	0x55, 0x4b, 0xce, 0xa6, 0x04, 0xf5, 0xea, 0xbf, 0xc8, 0xe5, 0xb2, 0x62, 0xaf, 0xf9, 0xf4, 0x7f,
-	0xec, 0x7b, 0x0d, 0xdc, 0x25, 0x61, 0x3c, 0x98, 0x92, 0x1d, 0x1b, 0x5f, 0xd9, 0x8b, 0x1c, 0xe3,
-	0x06, 0x9f, 0x6d, 0x7d, 0xb6, 0x37, 0xc0, 0x36, 0xb9, 0x21, 0x2c, 0x72, 0x96, 0x32, 0xf0, 0x00,
-	0xf6, 0xa4, 0x95, 0x41, 0xea, 0x3a, 0xdf, 0xb5, 0xf9, 0x2d, 0x47, 0x91, 0x13, 0xaf, 0x72, 0x78,
-	0x08, 0x5e, 0xba, 0x76, 0x41, 0x1a, 0x1a, 0x3b, 0xb2, 0xd8, 0xae, 0xa5, 0xc8, 0x89, 0x37, 0xd3,
-	0x15, 0x9c, 0xad, 0x65, 0x10, 0x77, 0x0b, 0xde, 0xd5, 0x54, 0xc1, 0x1b, 0xe9, 0x51, 0xcb, 0xfe,
-	0xa9, 0xd1, 0xf1, 0xc7, 0xc2, 0x47, 0x9f, 0x0b, 0x1f, 0x7d, 0x2d, 0x7c, 0xf4, 0xf6, 0xed, 0x3b,
-	0xf7, 0x2d, 0x7b, 0xcb, 0x92, 0xa6, 0xbe, 0x60, 0xe1, 0x4f, 0x00, 0x00, 0x00, 0xff, 0xff, 0x87,
-	0xea, 0x95, 0x8a, 0xa5, 0x02, 0x00, 0x00,
+	// 698 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x55, 0xc1, 0x6e, 0xd3, 0x40,
+	0x10, 0xb5, 0x69, 0xdd, 0x24, 0xe3, 0xb4, 0x49, 0xad, 0x02, 0xe6, 0x12, 0x55, 0x3e, 0x40, 0x8b,
+	0x50, 0x2c, 0x39, 0x12, 0x07, 0x7a, 0x40, 0x2d, 0xa5, 0x4a, 0x0f, 0x54, 0x60, 0x21, 0x21, 0x71,
+	0x89, 0x1c, 0x7b, 0x93, 0x2e, 0xb2, 0xd7, 0x66, 0x6d, 0xd3, 0xb4, 0x7f, 0xc0, 0x09, 0xbe, 0x87,
+	0x2f, 0xe0, 0xc8, 0x27, 0xa0, 0xc2, 0x87, 0xa0, 0x9d, 0x5d, 0x27, 0x71, 0x91, 0x82, 0x40, 0xe2,
+	0x94, 0xcc, 0xec, 0x7b, 0xab, 0x37, 0xcf, 0xf3, 0x6c, 0x38, 0x9c, 0xd2, 0xe2, 0xbc, 0x1c, 0xf7,
+	0xc3, 0x34, 0x71, 0x93, 0x41, 0x34, 0x76, 0x93, 0x81, 0x9b, 0xf3, 0xd0, 0x4d, 0x06, 0x8c, 0xb2,
+	0x99, 0x3b, 0x25, 0x8c, 0xf0, 0xa0, 0x20, 0x91, 0x9b, 0xf1, 0xb4, 0x48, 0xdd, 0xf7, 0x25, 0xe1,
+	0x97, 0xd9, 0x58, 0xfe, 0xf6, 0xb1, 0x67, 0x19, 0x58, 0x38, 0x1f, 0x75, 0x68, 0xbd, 0x26, 0x3c,
+	0x79, 0x25, 0x2a, 0x6b, 0x07, 0x8c, 0x09, 0x25, 0x71, 0x64, 0xeb, 0xbb, 0xfa, 0x5e, 0xdb, 0x97,
+	0x85, 0x65, 0xc1, 0x7a, 0x41, 0x78, 0x62, 0xdf, 0xc2, 0x26, 0xfe, 0xb7, 0xf6, 0xa1, 0x1b, 0x06,
+	0x39, 0x19, 0x51, 0x96, 0x13, 0x96, 0xd3, 0x82, 0x7e, 0x20, 0xf6, 0xda, 0xae, 0xbe, 0xd7, 0xf4,
+	0x3b, 0xa2, 0x7f, 0xba, 0x68, 0x5b, 0x0f, 0xa0, 0x53, 0x32, 0x1a, 0xa6, 0x11, 0x19, 0x4d, 0xd2,
+	0x38, 0xa2, 0x6c, 0x6a, 0xaf, 0x23, 0x72, 0x4b, 0xb5, 0x4f, 0x64, 0xd7, 0x39, 0x00, 0xd3, 0x27,
+	0x53, 0x32, 0xcb, 0x56, 0x89, 0xb9, 0x03, 0x1b, 0x1c, 0x41, 0x4a, 0x8e, 0xaa, 0x9c, 0x31, 0xc0,
+	0x49, 0x79, 0x75, 0x75, 0xf9, 0xb7, 0x83, 0x3c, 0x84, 0xed, 0x24, 0x98, 0x8d, 0x48, 0x44, 0x8b,
+	0x51, 0x44, 0xf3, 0x22, 0x60, 0xa1, 0x9c, 0xc4, 0xf0, 0x3b, 0x49, 0x30, 0x7b, 0x1e, 0xd1, 0xe2,
+	0x58, 0xb5, 0x9d, 0x4f, 0x3a, 0x98, 0x2f, 0x39, 0x99, 0xd0, 0xd9, 0x1f, 0x14, 0x66, 0x08, 0xaa,
+	0x14, 0xca, 0xea, 0xbf, 0x58, 0xf6, 0x14, 0x36, 0xdf, 0xd0, 0x38, 0x0a, 0x03, 0x1e, 0xad, 0x92,
+	0x64, 0x43, 0x23, 0x0b, 0x8a, 0x82, 0x70, 0xa6, 0x34, 0x55, 0xa5, 0xf3, 0x45, 0x07, 0xf0, 0x03,
+	0x36, 0x25, 0xab, 0xe8, 0x3b, 0x60, 0xc4, 0xe9, 0x05, 0xe1, 0x8a, 0x2c, 0x0b, 0xd1, 0x2d, 0xb3,
+	0x8c, 0x70, 0x1c, 0xa2, 0xed, 0xcb, 0x42, 0x48, 0xc7, 0xe3, 0x11, 0x65, 0x61, 0x5c, 0xe6, 0x62,
+	0x48, 0x25, 0x1d, 0xdb, 0xa7, 0x55, 0x17, 0x67, 0xcc, 0xb2, 0x1a, 0xd0, 0x50, 0x33, 0x66, 0xd9,
+	0x32, 0xd0, 0x86, 0x06, 0x2b, 0x13, 0xc2, 0x69, 0x68, 0x6f, 0x20, 0xa0, 0x2a, 0x1d, 0x07, 0xe0,
+	0x44, 0x08, 0x5c, 0xa1, 0xdd, 0xe9, 0xc0, 0xe6, 0x8b, 0xa0, 0x08, 0xcf, 0x0f, 0xe3, 0x18, 0x61,
+	0x4e, 0x17, 0xb6, 0xb0, 0x71, 0x96, 0x32, 0x39, 0xb4, 0x33, 0x80, 0xcd, 0x33, 0x32, 0x0d, 0x0a,
+	0x9a, 0x32, 0x79, 0x93, 0x03, 0x32, 0x1d, 0x78, 0x93, 0xe9, 0xb5, 0xfb, 0x58, 0xf5, 0xf1, 0xd0,
+	0x57, 0xc1, 0x79, 0x02, 0xdd, 0x67, 0x29, 0x7b, 0x57, 0xb2, 0x70, 0xc1, 0xbb, 0x0f, 0x0d, 0x71,
+	0x48, 0x49, 0x6e, 0xeb, 0xbb, 0x6b, 0xbf, 0x31, 0xab, 0x43, 0xc1, 0x3d, 0xa6, 0xf9, 0xbf, 0x71,
+	0x7f, 0xae, 0x83, 0x51, 0x31, 0xe4, 0x36, 0x4b, 0x91, 0x5d, 0x05, 0x9f, 0x87, 0x79, 0xa8, 0xa9,
+	0x0d, 0x7f, 0x54, 0x4b, 0x8c, 0xe9, 0x59, 0x0a, 0xb9, 0x94, 0xb5, 0xa1, 0x56, 0xe5, 0xc8, 0xf2,
+	0xa0, 0xc9, 0x94, 0x19, 0xf8, 0x60, 0x4d, 0x6f, 0x47, 0xe1, 0x6b, 0x1e, 0x0d, 0x35, 0x7f, 0x8e,
+	0xb3, 0x0e, 0xc0, 0x0c, 0x17, 0x5e, 0xe0, 0xf3, 0x36, 0xbd, 0xbb, 0x8a, 0x76, 0xd3, 0xa5, 0xa1,
+	0xe6, 0x2f, 0xa3, 0x05, 0x39, 0x5a, 0x98, 0x61, 0x1b, 0x35, 0xf2, 0x4d, 0x9b, 0x04, 0x79, 0x09,
+	0x6d, 0xed, 0x83, 0x31, 0x11, 0xa9, 0xc7, 0xcd, 0x30, 0xbd, 0x6d, 0x45, 0x5b, 0xbc, 0x09, 0x86,
+	0x9a, 0x2f, 0x11, 0xc2, 0x06, 0x15, 0xcb, 0x46, 0xcd, 0x86, 0xa5, 0x40, 0x0b, 0x1b, 0x54, 0x58,
+	0x3d, 0x68, 0x5e, 0xa8, 0x60, 0xd9, 0xcd, 0x9a, 0x0d, 0xb5, 0xbc, 0x09, 0x1b, 0x2a, 0x9c, 0x10,
+	0xc3, 0x45, 0x94, 0xec, 0x56, 0x4d, 0xcc, 0x22, 0x5e, 0x42, 0x0c, 0x22, 0xac, 0x01, 0xb4, 0x12,
+	0xb1, 0x84, 0xa3, 0x20, 0x8e, 0x6d, 0xa8, 0xdd, 0x5f, 0xdb, 0x56, 0x71, 0x7f, 0xa2, 0x1a, 0xd6,
+	0x63, 0x00, 0x49, 0x62, 0x29, 0x23, 0xb6, 0x89, 0xac, 0xdb, 0xcb, 0xac, 0xf9, 0x4a, 0x0f, 0x35,
+	0xbf, 0x95, 0x54, 0x1d, 0x34, 0x09, 0x83, 0xd1, 0xae, 0x9b, 0x34, 0x8f, 0x0e, 0x9a, 0x24, 0xaa,
+	0xa3, 0x86, 0xda, 0xfc, 0xa3, 0x7b, 0x5f, 0xaf, 0x7b, 0xfa, 0xb7, 0xeb, 0x9e, 0xfe, 0xfd, 0xba,
+	0xa7, 0x7f, 0xfe, 0xd1, 0xd3, 0xde, 0x36, 0xd4, 0x57, 0x64, 0xbc, 0x81, 0x1f, 0x90, 0xc1, 0xaf,
+	0x01, 0x00, 0x07, 0x37, 0xa4, 0xaa, 0x85, 0x06, 0x00, 0x00,
 }
@@ -33,7 +33,7 @@ func TestMarshal(t *testing.T) {
 	}{
 		{
 			name:  "term query",
-			query: NewTermQuery([]byte("fruit"), []byte("apple")),
+			query: MustCreateTermQuery([]byte("fruit"), []byte("apple")),
 		},
 		{
 			name:  "regexp query",
@@ -41,29 +41,29 @@ func TestMarshal(t *testing.T) {
 		},
 		{
 			name:  "negation query",
-			query: NewNegationQuery(NewTermQuery([]byte("fruit"), []byte("apple"))),
+			query: NewNegationQuery(MustCreateTermQuery([]byte("fruit"), []byte("apple"))),
 		},
 		{
 			name: "disjunction query",
 			query: NewDisjunctionQuery(
-				NewTermQuery([]byte("fruit"), []byte("apple")),
+				MustCreateTermQuery([]byte("fruit"), []byte("apple")),
 				NewConjunctionQuery(
-					NewTermQuery([]byte("fruit"), []byte("banana")),
+					MustCreateTermQuery([]byte("fruit"), []byte("banana")),
 				),
 				NewDisjunctionQuery(
-					NewTermQuery([]byte("fruit"), []byte("orange")),
+					MustCreateTermQuery([]byte("fruit"), []byte("orange")),
 				),
 			),
 		},
 		{
 			name: "conjunction query",
 			query: NewConjunctionQuery(
-				NewTermQuery([]byte("fruit"), []byte("apple")),
+				MustCreateTermQuery([]byte("fruit"), []byte("apple")),
 				NewConjunctionQuery(
-					NewTermQuery([]byte("fruit"), []byte("banana")),
+					MustCreateTermQuery([]byte("fruit"), []byte("banana")),
 				),
 				NewDisjunctionQuery(
-					NewTermQuery([]byte("fruit"), []byte("orange")),
+					MustCreateTermQuery([]byte("fruit"), []byte("orange")),
 				),
 			),
 		},
@@ -0,0 +1,210 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package matchers translates Prometheus style label matchers into index
+// queries. A matcher is evaluated against the value of its label in a
+// series, where series without the label are treated as having the label with
+// an empty value, since empty values are never indexed:
+//
+//	EQ  ""  matches series without the label, a negated field query.
+//	NEQ ""  matches series with the label, a field query.
+//	EQ  v   matches series with the label equal to v, a term query.
+//	NEQ v   matches series without the label equal to v, including those
+//	        without the label, a negated term query.
+//	RE  r   matches series with the label matching r, and series without the
+//	        label if r matches the empty string.
+//	NRE r   matches series without the label matching r, including those
+//	        without the label unless r matches the empty string.
+//
+// Regular expressions are fully anchored, i.e. "foo" matches only the value
+// "foo" and not "foobar", the same as Prometheus.
+package matchers
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+
+	"github.com/m3db/m3/src/m3ninx/idx"
+)
+
+// Type is the type of a matcher.
+type Type int
+
+// List of matcher types.
+const (
+	EQ Type = iota
+	NEQ
+	RE
+	NRE
+)
+
+func (t Type) String() string {
+	switch t {
+	case EQ:
+		return "="
+	case NEQ:
+		return "!="
+	case RE:
+		return "=~"
+	case NRE:
+		return "!~"
+	}
+	return fmt.Sprintf("unknown(%d)", int(t))
+}
+
+var (
+	errNoMatchers = errors.New("no matchers")
+	errEmptyName  = errors.New("matcher has an empty name")
+)
+
+// Matcher matches series by the value of a label.
+type Matcher struct {
+	Type  Type
+	Name  []byte
+	Value []byte
+}
+
+func (m Matcher) String() string {
+	return fmt.Sprintf("%s%s%q", m.Name, m.Type, m.Value)
+}
+
+// NewQuery returns the query that matches the series matched by every one of
+// the given matchers.
+func NewQuery(matchers ...Matcher) (idx.Query, error) {
+	if len(matchers) == 0 {
+		return idx.Query{}, errNoMatchers
+	}
+
+	// NB: a conjunction must include at least one query that is not a
+	// negation to be searched, the match all query is dropped from the
+	// conjunction if there is such a query.
+	queries := make([]idx.Query, 0, len(matchers)+1)
+	queries = append(queries, idx.NewMatchAllQuery())
+	for _, m := range matchers {
+		q, err := m.Query()
+		if err != nil {
+			return idx.Query{}, err
+		}
+		queries = append(queries, q)
+	}
+	return idx.NewConjunctionQuery(queries...), nil
+}
+
+// Query returns the query that matches the series matched by the matcher.
+func (m Matcher) Query() (idx.Query, error) {
+	if len(m.Name) == 0 {
+		return idx.Query{}, errEmptyName
+	}
+
+	switch m.Type {
+	case EQ:
+		if len(m.Value) == 0 {
+			q, err := idx.NewFieldQuery(m.Name)
+			if err != nil {
+				return idx.Query{}, err
+			}
+			return idx.NewNegationQuery(q), nil
+		}
+		return idx.NewTermQuery(m.Name, m.Value)
+
+	case NEQ:
+		if len(m.Value) == 0 {
+			return idx.NewFieldQuery(m.Name)
+		}
+		q, err := idx.NewTermQuery(m.Name, m.Value)
+		if err != nil {
+			return idx.Query{}, err
+		}
+		return idx.NewNegationQuery(q), nil
+
+	case RE, NRE:
+		if len(m.Value) == 0 {
+			// NB: an empty regexp only matches the empty value.
+			return Matcher{Type: m.Type - RE, Name: m.Name}.Query()
+		}
+		q, err := m.regexpQuery()
+		if err != nil {
+			return idx.Query{}, err
+		}
+		if m.Type == NRE {
+			return idx.NewNegationQuery(q), nil
+		}
+		return q, nil
+	}
+
+	return idx.Query{}, fmt.Errorf("unknown matcher type: %v", m.Type)
+}
+
+// regexpQuery returns the query that matches the series matched by a regular
+// expression matcher, the query for a negated matcher is its negation.
+func (m Matcher) regexpQuery() (idx.Query, error) {
+	re, err := syntax.Parse(string(m.Value), syntax.Perl)
+	if err != nil {
+		return idx.Query{}, fmt.Errorf("invalid regexp %s for matcher %s: %v",
+			m.Value, m.Name, err)
+	}
+	re = re.Simplify()
+
+	// NB: avoid searching every term of the field for the common cases of
+	// matching any value or any non-empty value.
+	switch {
+	case matchesAny(re, syntax.OpStar):
+		return idx.NewMatchAllQuery(), nil
+	case matchesAny(re, syntax.OpPlus):
+		return idx.NewFieldQuery(m.Name)
+	}
+
+	q, err := idx.NewRegexpQuery(m.Name, m.Value)
+	if err != nil {
+		return idx.Query{}, fmt.Errorf("invalid regexp %s for matcher %s: %v",
+			m.Value, m.Name, err)
+	}
+
+	anchored, err := regexp.Compile("^(?:" + string(m.Value) + ")$")
+	if err != nil {
+		return idx.Query{}, fmt.Errorf("invalid regexp %s for matcher %s: %v",
+			m.Value, m.Name, err)
+	}
+	if anchored.MatchString("") {
+		// Series without the label match since the regexp matches the empty
+		// string, and the query for a negated matcher must then exclude them.
+		fq, err := idx.NewFieldQuery(m.Name)
+		if err != nil {
+			return idx.Query{}, err
+		}
+		return idx.NewDisjunctionQuery(q, idx.NewNegationQuery(fq)), nil
+	}
+	return q, nil
+}
+
+// matchesAny returns whether a regexp is a repetition of any character, where
+// op is the repetition operator.
+func matchesAny(re *syntax.Regexp, op syntax.Op) bool {
+	for re.Op == syntax.OpCapture {
+		re = re.Sub[0]
+	}
+	if re.Op != op || len(re.Sub) != 1 {
+		return false
+	}
+	sub := re.Sub[0]
+	return sub.Op == syntax.OpAnyChar || sub.Op == syntax.OpAnyCharNotNL
+}
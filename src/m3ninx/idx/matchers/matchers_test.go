@@ -0,0 +1,146 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package matchers
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/m3db/m3/src/m3ninx/doc"
+	"github.com/m3db/m3/src/m3ninx/index"
+	"github.com/m3db/m3/src/m3ninx/index/segment/mem"
+	"github.com/m3db/m3/src/m3ninx/search"
+	"github.com/m3db/m3/src/m3ninx/search/executor"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestReader(t *testing.T) index.Reader {
+	seg, err := mem.NewSegment(0, mem.NewOptions())
+	require.NoError(t, err)
+
+	for _, d := range []struct {
+		id     string
+		fields []string
+	}{
+		{id: "a", fields: []string{"__name__", "up", "job", "api", "env", "prod"}},
+		{id: "b", fields: []string{"__name__", "up", "job", "db", "env", "dev"}},
+		{id: "c", fields: []string{"__name__", "up", "job", "api"}},
+		{id: "d", fields: []string{"__name__", "down", "job", "cache", "env", "prod"}},
+	} {
+		var fields []doc.Field
+		for i := 0; i < len(d.fields); i += 2 {
+			fields = append(fields, doc.Field{
+				Name:  []byte(d.fields[i]),
+				Value: []byte(d.fields[i+1]),
+			})
+		}
+		_, err := seg.Insert(doc.Document{ID: []byte(d.id), Fields: fields})
+		require.NoError(t, err)
+	}
+
+	r, err := seg.Reader()
+	require.NoError(t, err)
+	return r
+}
+
+func matcher(t Type, name, value string) Matcher {
+	return Matcher{Type: t, Name: []byte(name), Value: []byte(value)}
+}
+
+// TestQueryConformance verifies that queries match the same series that
+// Prometheus matches with the same matchers.
+func TestQueryConformance(t *testing.T) {
+	tests := []struct {
+		matchers []Matcher
+		expected []string
+	}{
+		{matchers: []Matcher{matcher(EQ, "env", "")}, expected: []string{"c"}},
+		{matchers: []Matcher{matcher(NEQ, "env", "")}, expected: []string{"a", "b", "d"}},
+		{matchers: []Matcher{matcher(EQ, "env", "prod")}, expected: []string{"a", "d"}},
+		{matchers: []Matcher{matcher(NEQ, "env", "prod")}, expected: []string{"b", "c"}},
+		{matchers: []Matcher{matcher(EQ, "missing", "")}, expected: []string{"a", "b", "c", "d"}},
+		{matchers: []Matcher{matcher(EQ, "job", "")}, expected: nil},
+		{matchers: []Matcher{matcher(RE, "env", ".*")}, expected: []string{"a", "b", "c", "d"}},
+		{matchers: []Matcher{matcher(NRE, "env", ".*")}, expected: nil},
+		{matchers: []Matcher{matcher(RE, "env", ".+")}, expected: []string{"a", "b", "d"}},
+		{matchers: []Matcher{matcher(NRE, "env", ".+")}, expected: []string{"c"}},
+		{matchers: []Matcher{matcher(RE, "env", "")}, expected: []string{"c"}},
+		{matchers: []Matcher{matcher(NRE, "env", "")}, expected: []string{"a", "b", "d"}},
+		{matchers: []Matcher{matcher(RE, "env", "pro")}, expected: nil},
+		{matchers: []Matcher{matcher(RE, "env", "pro.*")}, expected: []string{"a", "d"}},
+		{matchers: []Matcher{matcher(NRE, "env", "prod")}, expected: []string{"b", "c"}},
+		{matchers: []Matcher{matcher(RE, "env", "prod|")}, expected: []string{"a", "c", "d"}},
+		{matchers: []Matcher{matcher(NRE, "env", "prod|")}, expected: []string{"b"}},
+		{matchers: []Matcher{matcher(RE, "env", "d.*|")}, expected: []string{"b", "c"}},
+		{
+			matchers: []Matcher{matcher(EQ, "__name__", "up"), matcher(NEQ, "env", "prod")},
+			expected: []string{"b", "c"},
+		},
+		{
+			matchers: []Matcher{matcher(RE, "job", "api|db"), matcher(EQ, "env", "")},
+			expected: []string{"c"},
+		},
+		{
+			matchers: []Matcher{matcher(EQ, "__name__", "up"), matcher(NRE, "job", "a.*")},
+			expected: []string{"b"},
+		},
+		{
+			matchers: []Matcher{matcher(NEQ, "__name__", "up"), matcher(NRE, "env", "dev")},
+			expected: []string{"d"},
+		},
+	}
+
+	r := newTestReader(t)
+	for _, test := range tests {
+		q, err := NewQuery(test.matchers...)
+		require.NoError(t, err, "%v", test.matchers)
+
+		e := executor.NewExecutor(index.Readers{r}, search.NewOptions())
+		iter, err := e.Execute(q.SearchQuery())
+		require.NoError(t, err, "%v", test.matchers)
+
+		var matched []string
+		for iter.Next() {
+			matched = append(matched, string(iter.Current().ID))
+		}
+		require.NoError(t, iter.Err())
+		require.NoError(t, iter.Close())
+		require.NoError(t, e.Close())
+
+		sort.Strings(matched)
+		require.Equal(t, test.expected, matched, "%v", test.matchers)
+	}
+}
+
+func TestQueryInvalid(t *testing.T) {
+	_, err := NewQuery()
+	require.Equal(t, errNoMatchers, err)
+
+	_, err = NewQuery(matcher(EQ, "", "up"))
+	require.Equal(t, errEmptyName, err)
+
+	_, err = NewQuery(matcher(RE, "job", "("))
+	require.Error(t, err)
+
+	_, err = NewQuery(Matcher{Type: Type(10), Name: []byte("job")})
+	require.Error(t, err)
+}
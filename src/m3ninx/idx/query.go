@@ -21,6 +21,7 @@
 package idx
 
 import (
+	"github.com/m3db/m3/src/m3ninx/index"
 	"github.com/m3db/m3/src/m3ninx/search"
 	"github.com/m3db/m3/src/m3ninx/search/query"
 )
@@ -34,11 +35,71 @@ func (q Query) String() string {
 	return q.query.String()
 }
 
+// NewMatchAllQuery returns a new query which matches every document.
+func NewMatchAllQuery() Query {
+	return Query{
+		query: query.NewMatchAllQuery(),
+	}
+}
+
+// NewMatchNoneQuery returns a new query which matches no documents.
+func NewMatchNoneQuery() Query {
+	return Query{
+		query: query.NewMatchNoneQuery(),
+	}
+}
+
 // NewTermQuery returns a new query for finding documents which match a term exactly.
-func NewTermQuery(field, term []byte) Query {
+func NewTermQuery(field, term []byte) (Query, error) {
+	q, err := query.NewTermQuery(field, term)
+	if err != nil {
+		return Query{}, err
+	}
 	return Query{
-		query: query.NewTermQuery(field, term),
+		query: q,
+	}, nil
+}
+
+// MustCreateTermQuery is like NewTermQuery but panics if the query cannot be created.
+func MustCreateTermQuery(field, term []byte) Query {
+	q, err := NewTermQuery(field, term)
+	if err != nil {
+		panic(err)
 	}
+	return q
+}
+
+// NewCaseInsensitiveTermQuery returns a new query for finding documents which match a
+// term under the given case folding.
+func NewCaseInsensitiveTermQuery(field, term []byte, folding index.CaseFolding) (Query, error) {
+	q, err := query.NewCaseInsensitiveTermQuery(field, term, folding)
+	if err != nil {
+		return Query{}, err
+	}
+	return Query{
+		query: q,
+	}, nil
+}
+
+// NewFieldQuery returns a new query for finding documents which have any term for the
+// given field.
+func NewFieldQuery(field []byte) (Query, error) {
+	q, err := query.NewFieldQuery(field)
+	if err != nil {
+		return Query{}, err
+	}
+	return Query{
+		query: q,
+	}, nil
+}
+
+// MustCreateFieldQuery is like NewFieldQuery but panics if the query cannot be created.
+func MustCreateFieldQuery(field []byte) Query {
+	q, err := NewFieldQuery(field)
+	if err != nil {
+		panic(err)
+	}
+	return q
 }
 
 // NewRegexpQuery returns a new query for finding documents which match a regular expression.
@@ -52,6 +113,18 @@ func NewRegexpQuery(field, regexp []byte) (Query, error) {
 	}, nil
 }
 
+// NewRegexpQueryForID returns a new query for finding documents whose ID matches a
+// regular expression.
+func NewRegexpQueryForID(regexp []byte) (Query, error) {
+	q, err := query.NewRegexpQueryForID(regexp)
+	if err != nil {
+		return Query{}, err
+	}
+	return Query{
+		query: q,
+	}, nil
+}
+
 // MustCreateRegexpQuery is like NewRegexpQuery but panics if the query cannot be created.
 func MustCreateRegexpQuery(field, regexp []byte) Query {
 	q, err := query.NewRegexpQuery(field, regexp)
@@ -63,6 +136,59 @@ func MustCreateRegexpQuery(field, regexp []byte) Query {
 	}
 }
 
+// NewWildcardQuery returns a new query for finding documents which match a wildcard
+// pattern, where '*' matches any sequence of characters and '?' matches any single character.
+func NewWildcardQuery(field, pattern []byte) (Query, error) {
+	q, err := query.NewWildcardQuery(field, pattern)
+	if err != nil {
+		return Query{}, err
+	}
+	return Query{
+		query: q,
+	}, nil
+}
+
+// MustCreateWildcardQuery is like NewWildcardQuery but panics if the query cannot be created.
+func MustCreateWildcardQuery(field, pattern []byte) Query {
+	q, err := query.NewWildcardQuery(field, pattern)
+	if err != nil {
+		panic(err)
+	}
+	return Query{
+		query: q,
+	}
+}
+
+// NewRangeQuery returns a new query for finding documents which have a term lexically
+// within the given bounds. A nil or empty bound leaves the range open-ended on that side.
+func NewRangeQuery(
+	field, lower, upper []byte,
+	lowerInclusive, upperInclusive bool,
+) (Query, error) {
+	q, err := query.NewRangeQuery(field, lower, upper, lowerInclusive, upperInclusive)
+	if err != nil {
+		return Query{}, err
+	}
+	return Query{
+		query: q,
+	}, nil
+}
+
+// NewNumericRangeQuery returns a new query for finding documents which have a term which,
+// parsed as an integer, is numerically within the given bounds.
+func NewNumericRangeQuery(
+	field, lower, upper []byte,
+	lowerInclusive, upperInclusive bool,
+) (Query, error) {
+	q, err := query.NewNumericRangeQuery(field, lower, upper, lowerInclusive, upperInclusive)
+	if err != nil {
+		return Query{}, err
+	}
+	return Query{
+		query: q,
+	}, nil
+}
+
 // NewNegationQuery returns a new query for finding documents which don't match a given query.
 func NewNegationQuery(q Query) Query {
 	return Query{
@@ -35,18 +35,18 @@ func TestQueryMatcherTermQuery(t *testing.T) {
 		expected bool
 	}{
 		{
-			left:     idx.NewTermQuery([]byte("abc"), []byte("def")),
-			right:    idx.NewTermQuery([]byte("abc"), []byte("def")),
+			left:     idx.MustCreateTermQuery([]byte("abc"), []byte("def")),
+			right:    idx.MustCreateTermQuery([]byte("abc"), []byte("def")),
 			expected: true,
 		},
 		{
-			left:     idx.NewTermQuery([]byte("abc"), []byte("def")),
-			right:    idx.NewTermQuery([]byte("abc1"), []byte("def")),
+			left:     idx.MustCreateTermQuery([]byte("abc"), []byte("def")),
+			right:    idx.MustCreateTermQuery([]byte("abc1"), []byte("def")),
 			expected: false,
 		},
 		{
-			left:     idx.NewTermQuery([]byte("abc"), []byte("def")),
-			right:    idx.NewTermQuery([]byte("abc"), []byte("def1")),
+			left:     idx.MustCreateTermQuery([]byte("abc"), []byte("def")),
+			right:    idx.MustCreateTermQuery([]byte("abc"), []byte("def1")),
 			expected: false,
 		},
 	} {
@@ -89,13 +89,13 @@ func TestQueryMatcherNegationQuery(t *testing.T) {
 		expected bool
 	}{
 		{
-			left:     idx.NewNegationQuery(idx.NewTermQuery([]byte("abc"), []byte("def"))),
-			right:    idx.NewNegationQuery(idx.NewTermQuery([]byte("abc"), []byte("def"))),
+			left:     idx.NewNegationQuery(idx.MustCreateTermQuery([]byte("abc"), []byte("def"))),
+			right:    idx.NewNegationQuery(idx.MustCreateTermQuery([]byte("abc"), []byte("def"))),
 			expected: true,
 		},
 		{
-			left:     idx.NewNegationQuery(idx.NewTermQuery([]byte("abc"), []byte("def"))),
-			right:    idx.NewNegationQuery(idx.NewTermQuery([]byte("abc"), []byte("efg"))),
+			left:     idx.NewNegationQuery(idx.MustCreateTermQuery([]byte("abc"), []byte("def"))),
+			right:    idx.NewNegationQuery(idx.MustCreateTermQuery([]byte("abc"), []byte("efg"))),
 			expected: false,
 		},
 	} {
@@ -105,15 +105,15 @@ func TestQueryMatcherNegationQuery(t *testing.T) {
 }
 
 func TestQueryMatcherTermRegexpMismatch(t *testing.T) {
-	q0 := idx.NewTermQuery([]byte("abc"), []byte("def"))
+	q0 := idx.MustCreateTermQuery([]byte("abc"), []byte("def"))
 	q1, err := idx.NewRegexpQuery([]byte("abc"), []byte("def"))
 	require.NoError(t, err)
 	require.False(t, idx.NewQueryMatcher(q0).Matches(q1))
 }
 
 func TestQueryMatcherConjunctionQuery(t *testing.T) {
-	tq0 := idx.NewTermQuery([]byte("abc0"), []byte("def"))
-	tq1 := idx.NewTermQuery([]byte("abc1"), []byte("def"))
+	tq0 := idx.MustCreateTermQuery([]byte("abc0"), []byte("def"))
+	tq1 := idx.MustCreateTermQuery([]byte("abc1"), []byte("def"))
 	rq, err := idx.NewRegexpQuery([]byte("abc2"), []byte("def"))
 	require.NoError(t, err)
 	q := idx.NewConjunctionQuery(tq0, tq1, rq)
@@ -121,8 +121,8 @@ func TestQueryMatcherConjunctionQuery(t *testing.T) {
 }
 
 func TestQueryMatcherTermConjMismatch(t *testing.T) {
-	q0 := idx.NewTermQuery([]byte("abc"), []byte("def"))
-	tq1 := idx.NewTermQuery([]byte("abc1"), []byte("def"))
+	q0 := idx.MustCreateTermQuery([]byte("abc"), []byte("def"))
+	tq1 := idx.MustCreateTermQuery([]byte("abc1"), []byte("def"))
 	rq, err := idx.NewRegexpQuery([]byte("abc2"), []byte("def"))
 	require.NoError(t, err)
 	q1 := idx.NewConjunctionQuery(q0, tq1, rq)
@@ -130,8 +130,8 @@ func TestQueryMatcherTermConjMismatch(t *testing.T) {
 }
 
 func TestQueryMatcherDisjunctionQuery(t *testing.T) {
-	tq0 := idx.NewTermQuery([]byte("abc0"), []byte("def"))
-	tq1 := idx.NewTermQuery([]byte("abc1"), []byte("def"))
+	tq0 := idx.MustCreateTermQuery([]byte("abc0"), []byte("def"))
+	tq1 := idx.MustCreateTermQuery([]byte("abc1"), []byte("def"))
 	rq, err := idx.NewRegexpQuery([]byte("abc2"), []byte("def"))
 	require.NoError(t, err)
 	q := idx.NewDisjunctionQuery(tq0, tq1, rq)
@@ -0,0 +1,195 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package index
+
+import (
+	"bytes"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CaseFolding is the case folding under which terms are compared when matching
+// case-insensitively.
+type CaseFolding uint8
+
+const (
+	// ASCIICaseFolding folds only the ASCII letters, all other bytes are compared exactly.
+	ASCIICaseFolding CaseFolding = iota
+
+	// UnicodeCaseFolding applies Unicode simple case folding to terms decoded as UTF-8.
+	// Bytes which aren't valid UTF-8 are compared exactly.
+	UnicodeCaseFolding
+)
+
+// maxFoldPrefixes is the maximum number of case variants of the leading characters of
+// a term which are enumerated as prefixes to scan.
+const maxFoldPrefixes = 16
+
+// CompiledFold is a term, or a prefix of terms, compiled for matching terms which are
+// equal to it, or begin with it, under case folding.
+type CompiledFold struct {
+	Term    []byte
+	Prefix  bool
+	Folding CaseFolding
+
+	// Prefixes are case variants of the leading characters of the term. Every matching
+	// term begins with exactly one of them, so only the terms beginning with each need
+	// be scanned. They are sorted and none is a prefix of another.
+	Prefixes [][]byte
+
+	folded []byte
+}
+
+// CompileFold compiles the provided term into an object that can be used to query the
+// various segment implementations for terms which are equal to the term under the given
+// case folding or, if prefix is true, which begin with it.
+func CompileFold(term []byte, prefix bool, folding CaseFolding) CompiledFold {
+	c := CompiledFold{
+		Term:    term,
+		Prefix:  prefix,
+		Folding: folding,
+	}
+
+	var (
+		prefixes = [][]byte{nil}
+		variants [][]byte
+	)
+	for remaining := term; len(remaining) > 0; {
+		var size int
+		variants, size = c.caseVariants(remaining)
+		if len(prefixes)*len(variants) > maxFoldPrefixes {
+			break
+		}
+
+		next := make([][]byte, 0, len(prefixes)*len(variants))
+		for _, p := range prefixes {
+			for _, v := range variants {
+				next = append(next, append(append([]byte(nil), p...), v...))
+			}
+		}
+		prefixes = next
+		remaining = remaining[size:]
+	}
+	sort.Slice(prefixes, func(i, j int) bool {
+		return bytes.Compare(prefixes[i], prefixes[j]) < 0
+	})
+	c.Prefixes = prefixes
+
+	if folding == UnicodeCaseFolding {
+		c.folded = make([]byte, 0, len(term))
+		for remaining := term; len(remaining) > 0; {
+			r, size := utf8.DecodeRune(remaining)
+			if r == utf8.RuneError && size == 1 {
+				c.folded = append(c.folded, remaining[0])
+			} else {
+				c.folded = appendRune(c.folded, foldRune(r))
+			}
+			remaining = remaining[size:]
+		}
+	}
+	return c
+}
+
+// caseVariants returns the encodings of every case of the leading character of b, and
+// the size of that character in b.
+func (c CompiledFold) caseVariants(b []byte) ([][]byte, int) {
+	if c.Folding != UnicodeCaseFolding {
+		lower := foldASCII(b[0])
+		if lower < 'a' || lower > 'z' {
+			return [][]byte{b[:1]}, 1
+		}
+		return [][]byte{{lower - 'a' + 'A'}, {lower}}, 1
+	}
+
+	r, size := utf8.DecodeRune(b)
+	if r == utf8.RuneError && size == 1 {
+		return [][]byte{b[:1]}, 1
+	}
+	variants := [][]byte{appendRune(nil, r)}
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		variants = append(variants, appendRune(nil, f))
+	}
+	return variants, size
+}
+
+// Match returns whether the provided term is equal to, or if compiled as a prefix begins
+// with, the compiled term under case folding.
+func (c CompiledFold) Match(term []byte) bool {
+	if c.Folding == UnicodeCaseFolding {
+		return c.matchUnicode(term)
+	}
+
+	if len(term) < len(c.Term) || (!c.Prefix && len(term) != len(c.Term)) {
+		return false
+	}
+	for i := range c.Term {
+		if foldASCII(term[i]) != foldASCII(c.Term[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c CompiledFold) matchUnicode(term []byte) bool {
+	folded := c.folded
+	for len(folded) > 0 {
+		if len(term) == 0 {
+			return false
+		}
+
+		fr, foldedSize := utf8.DecodeRune(folded)
+		r, size := utf8.DecodeRune(term)
+		if (fr == utf8.RuneError && foldedSize == 1) || (r == utf8.RuneError && size == 1) {
+			if foldedSize != 1 || size != 1 || folded[0] != term[0] {
+				return false
+			}
+		} else if foldRune(r) != fr {
+			return false
+		}
+		folded, term = folded[foldedSize:], term[size:]
+	}
+	return c.Prefix || len(term) == 0
+}
+
+func foldASCII(b byte) byte {
+	if b >= 'A' && b <= 'Z' {
+		return b + 'a' - 'A'
+	}
+	return b
+}
+
+// foldRune returns the smallest rune equivalent to r under simple case folding.
+func foldRune(r rune) rune {
+	folded := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f < folded {
+			folded = f
+		}
+	}
+	return folded
+}
+
+func appendRune(b []byte, r rune) []byte {
+	var buf [utf8.UTFMax]byte
+	n := utf8.EncodeRune(buf[:], r)
+	return append(b, buf[:n]...)
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompiledFoldMatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		term       string
+		prefix     bool
+		folding    CaseFolding
+		matches    []string
+		nonMatches []string
+	}{
+		{
+			name:       "ascii term",
+			term:       "FOO",
+			matches:    []string{"foo", "Foo", "FOO", "fOo"},
+			nonMatches: []string{"fo", "food", "fooo", "bar", ""},
+		},
+		{
+			name:       "ascii prefix",
+			term:       "Fo",
+			prefix:     true,
+			matches:    []string{"fo", "FOO", "food", "fOrest"},
+			nonMatches: []string{"f", "of", "bar"},
+		},
+		{
+			name:       "ascii term with non-letters",
+			term:       "a-1_B",
+			matches:    []string{"A-1_b", "a-1_b"},
+			nonMatches: []string{"a-1-b", "a_1_b"},
+		},
+		{
+			name:       "ascii folding leaves other letters as is",
+			term:       "ÉTÉ",
+			matches:    []string{"ÉtÉ", "ÉTÉ"},
+			nonMatches: []string{"été", "ÉTé"},
+		},
+		{
+			name:       "unicode term",
+			term:       "ÉTÉ",
+			folding:    UnicodeCaseFolding,
+			matches:    []string{"été", "Été", "ÉTÉ"},
+			nonMatches: []string{"ete", "étés"},
+		},
+		{
+			name:       "unicode folding of characters with different widths",
+			term:       "k",
+			folding:    UnicodeCaseFolding,
+			matches:    []string{"k", "K", "K"},
+			nonMatches: []string{"kk", ""},
+		},
+		{
+			name:       "unicode prefix",
+			term:       "straẞ",
+			prefix:     true,
+			folding:    UnicodeCaseFolding,
+			matches:    []string{"STRAßE", "straße"},
+			nonMatches: []string{"strasse", "stra"},
+		},
+		{
+			name:       "invalid utf-8 compared exactly",
+			term:       "\xffA",
+			folding:    UnicodeCaseFolding,
+			matches:    []string{"\xffa", "\xffA"},
+			nonMatches: []string{"\xfea", "�a"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := CompileFold([]byte(test.term), test.prefix, test.folding)
+			for _, term := range test.matches {
+				require.True(t, c.Match([]byte(term)), "expected %q to match", term)
+			}
+			for _, term := range test.nonMatches {
+				require.False(t, c.Match([]byte(term)), "expected %q not to match", term)
+			}
+		})
+	}
+}
+
+func TestCompileFoldPrefixes(t *testing.T) {
+	tests := []struct {
+		name     string
+		term     string
+		folding  CaseFolding
+		expected []string
+	}{
+		{
+			name:     "empty term",
+			expected: []string{""},
+		},
+		{
+			name:     "ascii letters",
+			term:     "a1b",
+			expected: []string{"A1B", "A1b", "a1B", "a1b"},
+		},
+		{
+			name:    "limited number of prefixes",
+			term:    "abcdef",
+			folding: ASCIICaseFolding,
+			expected: []string{
+				"ABCD", "ABCd", "ABcD", "ABcd", "AbCD", "AbCd", "AbcD", "Abcd",
+				"aBCD", "aBCd", "aBcD", "aBcd", "abCD", "abCd", "abcD", "abcd",
+			},
+		},
+		{
+			name:     "unicode case variants",
+			term:     "k",
+			folding:  UnicodeCaseFolding,
+			expected: []string{"K", "k", "K"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := CompileFold([]byte(test.term), false, test.folding)
+			prefixes := make([]string, 0, len(c.Prefixes))
+			for _, p := range c.Prefixes {
+				prefixes = append(prefixes, string(p))
+			}
+			require.Equal(t, test.expected, prefixes)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package index
+
+import (
+	"errors"
+
+	"github.com/couchbase/vellum/levenshtein"
+)
+
+// MaxFuzzyEditDistance is the maximum edit distance supported by fuzzy matching.
+const MaxFuzzyEditDistance = 2
+
+var errInvalidFuzzyEditDistance = errors.New("fuzzy edit distance must be 1 or 2")
+
+// CompiledFuzzy is a collection of the structs required to match terms within an edit
+// distance of a term, to allow amortisation of the automaton construction costs.
+type CompiledFuzzy struct {
+	Term            []byte
+	MaxEditDistance int
+	FST             *levenshtein.Levenshtein
+}
+
+// CompileFuzzy compiles the provided term and maximum edit distance into an object
+// that can be used to query the various segment implementations.
+func CompileFuzzy(term []byte, maxEditDistance int) (CompiledFuzzy, error) {
+	if maxEditDistance < 1 || maxEditDistance > MaxFuzzyEditDistance {
+		return CompiledFuzzy{}, errInvalidFuzzyEditDistance
+	}
+
+	fstFuzzy, err := levenshtein.New(string(term), maxEditDistance)
+	if err != nil {
+		return CompiledFuzzy{}, err
+	}
+
+	return CompiledFuzzy{
+		Term:            term,
+		MaxEditDistance: maxEditDistance,
+		FST:             fstFuzzy,
+	}, nil
+}
+
+// Match returns whether the provided term is within the maximum edit distance of the
+// compiled term. Like the FST automaton, the distance is computed over code-points
+// rather than raw bytes.
+func (c CompiledFuzzy) Match(term []byte) bool {
+	var (
+		source = []rune(string(c.Term))
+		target = []rune(string(term))
+	)
+	if absInt(len(source)-len(target)) > c.MaxEditDistance {
+		return false
+	}
+
+	// Compute the Levenshtein distance one row at a time, bailing out as soon as
+	// every entry of a row exceeds the maximum edit distance.
+	prev := make([]int, len(target)+1)
+	curr := make([]int, len(target)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(source); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if curr[j] < rowMin {
+				rowMin = curr[j]
+			}
+		}
+		if rowMin > c.MaxEditDistance {
+			return false
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(target)] <= c.MaxEditDistance
+}
+
+func absInt(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func minInt(values ...int) int {
+	result := values[0]
+	for _, v := range values[1:] {
+		if v < result {
+			result = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileFuzzyInvalidEditDistance(t *testing.T) {
+	for _, distance := range []int{-1, 0, 3} {
+		_, err := CompileFuzzy([]byte("apple"), distance)
+		require.Error(t, err)
+	}
+}
+
+func TestCompiledFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		term            string
+		maxEditDistance int
+		input           string
+		expected        bool
+	}{
+		{term: "apple", maxEditDistance: 1, input: "apple", expected: true},
+		{term: "apple", maxEditDistance: 1, input: "appel", expected: false},
+		{term: "apple", maxEditDistance: 2, input: "appel", expected: true},
+		{term: "apple", maxEditDistance: 1, input: "aple", expected: true},
+		{term: "apple", maxEditDistance: 1, input: "apples", expected: true},
+		{term: "apple", maxEditDistance: 1, input: "applesx", expected: false},
+		{term: "apple", maxEditDistance: 2, input: "", expected: false},
+		{term: "ab", maxEditDistance: 2, input: "", expected: true},
+		{term: "héllo", maxEditDistance: 1, input: "hello", expected: true},
+	}
+
+	for _, test := range tests {
+		c, err := CompileFuzzy([]byte(test.term), test.maxEditDistance)
+		require.NoError(t, err)
+		require.Equal(t, test.expected, c.Match([]byte(test.input)),
+			"%s within %d of %s", test.input, test.maxEditDistance, test.term)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package index
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// CompiledRange is a range of terms, optionally compared numerically, to allow
+// amortisation of the costs of parsing numeric bounds. A nil bound leaves the
+// range open-ended on that side.
+type CompiledRange struct {
+	Lower          []byte
+	Upper          []byte
+	LowerInclusive bool
+	UpperInclusive bool
+	Numeric        bool
+
+	lowerNum int64
+	upperNum int64
+}
+
+// CompileRange compiles the provided bounds into an object that can be used to query
+// the various segment implementations for terms lexically within the bounds. Empty
+// bounds are treated as nil, leaving the range open-ended on that side.
+func CompileRange(lower, upper []byte, lowerInclusive, upperInclusive bool) CompiledRange {
+	if len(lower) == 0 {
+		lower = nil
+	}
+	if len(upper) == 0 {
+		upper = nil
+	}
+	return CompiledRange{
+		Lower:          lower,
+		Upper:          upper,
+		LowerInclusive: lowerInclusive,
+		UpperInclusive: upperInclusive,
+	}
+}
+
+// CompileNumericRange compiles the provided bounds into an object that can be used to
+// query the various segment implementations for terms which, parsed as base 10
+// integers, are numerically within the bounds. Terms which can't be parsed as
+// integers never match. Empty bounds are treated as nil as with CompileRange.
+func CompileNumericRange(lower, upper []byte, lowerInclusive, upperInclusive bool) (CompiledRange, error) {
+	c := CompileRange(lower, upper, lowerInclusive, upperInclusive)
+	c.Numeric = true
+
+	var err error
+	if c.Lower != nil {
+		if c.lowerNum, err = parseRangeInt(c.Lower); err != nil {
+			return CompiledRange{}, fmt.Errorf("invalid numeric range lower bound: %v", err)
+		}
+	}
+	if c.Upper != nil {
+		if c.upperNum, err = parseRangeInt(c.Upper); err != nil {
+			return CompiledRange{}, fmt.Errorf("invalid numeric range upper bound: %v", err)
+		}
+	}
+	return c, nil
+}
+
+// Match returns whether the provided term is within the range.
+func (c CompiledRange) Match(term []byte) bool {
+	if c.Numeric {
+		return c.matchNumeric(term)
+	}
+
+	if c.Lower != nil {
+		cmp := bytes.Compare(term, c.Lower)
+		if cmp < 0 || (cmp == 0 && !c.LowerInclusive) {
+			return false
+		}
+	}
+	if c.Upper != nil {
+		cmp := bytes.Compare(term, c.Upper)
+		if cmp > 0 || (cmp == 0 && !c.UpperInclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c CompiledRange) matchNumeric(term []byte) bool {
+	v, err := parseRangeInt(term)
+	if err != nil {
+		return false
+	}
+
+	if c.Lower != nil {
+		if v < c.lowerNum || (v == c.lowerNum && !c.LowerInclusive) {
+			return false
+		}
+	}
+	if c.Upper != nil {
+		if v > c.upperNum || (v == c.upperNum && !c.UpperInclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseRangeInt(b []byte) (int64, error) {
+	return strconv.ParseInt(string(b), 10, 64)
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompiledRangeMatch(t *testing.T) {
+	tests := []struct {
+		name           string
+		lower, upper   string
+		lowerInclusive bool
+		upperInclusive bool
+		matches        []string
+		nonMatches     []string
+	}{
+		{
+			name:           "inclusive bounds",
+			lower:          "b",
+			upper:          "d",
+			lowerInclusive: true,
+			upperInclusive: true,
+			matches:        []string{"b", "ba", "c", "d"},
+			nonMatches:     []string{"a", "az", "da", "e"},
+		},
+		{
+			name:       "exclusive bounds",
+			lower:      "b",
+			upper:      "d",
+			matches:    []string{"b\x00", "ba", "c", "cz"},
+			nonMatches: []string{"a", "b", "d", "da"},
+		},
+		{
+			name:           "only lower bound",
+			lower:          "b",
+			lowerInclusive: true,
+			matches:        []string{"b", "c", "zzz"},
+			nonMatches:     []string{"", "a", "az"},
+		},
+		{
+			name:           "only upper bound",
+			upper:          "b",
+			upperInclusive: true,
+			matches:        []string{"", "a", "az", "b"},
+			nonMatches:     []string{"b\x00", "ba", "c"},
+		},
+		{
+			name:    "lexical comparison of numbers",
+			lower:   "10",
+			upper:   "20",
+			matches: []string{"100", "11", "199"},
+			// Lexically "9" sorts after "20".
+			nonMatches: []string{"9", "20", "5"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := CompileRange([]byte(test.lower), []byte(test.upper),
+				test.lowerInclusive, test.upperInclusive)
+			for _, term := range test.matches {
+				require.True(t, c.Match([]byte(term)), term)
+			}
+			for _, term := range test.nonMatches {
+				require.False(t, c.Match([]byte(term)), term)
+			}
+		})
+	}
+}
+
+func TestCompiledNumericRangeMatch(t *testing.T) {
+	tests := []struct {
+		name           string
+		lower, upper   string
+		lowerInclusive bool
+		upperInclusive bool
+		matches        []string
+		nonMatches     []string
+	}{
+		{
+			name:           "inclusive bounds",
+			lower:          "10",
+			upper:          "20",
+			lowerInclusive: true,
+			upperInclusive: true,
+			matches:        []string{"10", "15", "20", "+12", "011"},
+			nonMatches:     []string{"9", "21", "100", "abc", "", "15.0"},
+		},
+		{
+			name:       "exclusive bounds",
+			lower:      "10",
+			upper:      "20",
+			matches:    []string{"11", "19"},
+			nonMatches: []string{"10", "20"},
+		},
+		{
+			name:           "only lower bound",
+			lower:          "-5",
+			lowerInclusive: true,
+			matches:        []string{"-5", "0", "9223372036854775807"},
+			nonMatches:     []string{"-6", "port", "9223372036854775808"},
+		},
+		{
+			name:       "only upper bound",
+			upper:      "8080",
+			matches:    []string{"-1", "0", "80", "8079"},
+			nonMatches: []string{"8080", "9000", "http"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := CompileNumericRange([]byte(test.lower), []byte(test.upper),
+				test.lowerInclusive, test.upperInclusive)
+			require.NoError(t, err)
+			for _, term := range test.matches {
+				require.True(t, c.Match([]byte(term)), term)
+			}
+			for _, term := range test.nonMatches {
+				require.False(t, c.Match([]byte(term)), term)
+			}
+		})
+	}
+}
+
+func TestCompileNumericRangeInvalidBounds(t *testing.T) {
+	_, err := CompileNumericRange([]byte("ten"), nil, true, true)
+	require.Error(t, err)
+
+	_, err = CompileNumericRange(nil, []byte("20.5"), true, true)
+	require.Error(t, err)
+}
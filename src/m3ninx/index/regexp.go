@@ -21,6 +21,8 @@
 package index
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	re "regexp"
 	"regexp/syntax"
@@ -50,6 +52,12 @@ func CompileRegex(r []byte) (CompiledRegex, error) {
 		return CompiledRegex{}, fmt.Errorf("unable to create FST re: %v", err)
 	}
 
+	// Issue (c): Vellum rejects a number of constructs the stdlib accepts. Surface these up
+	// front with a clear error rather than relying on whichever segment is queried first.
+	if err := validateRegexp(vellumRe); err != nil {
+		return CompiledRegex{}, fmt.Errorf("unsupported regexp %q: %v", reString, err)
+	}
+
 	// Issue (b): Vellum treats every regular expression as anchored, where as the map-backed segment does not.
 	// To address this issue, we ensure that every incoming regular expression is modified to be anchored
 	// when querying the map-backed segment, and isn't anchored when querying Vellum's RE.
@@ -62,7 +70,10 @@ func CompileRegex(r []byte) (CompiledRegex, error) {
 	if err != nil {
 		return CompiledRegex{}, err
 	}
-	compiledRegex := CompiledRegex{Simple: simpleRE}
+	compiledRegex := CompiledRegex{
+		Simple:    simpleRE,
+		FSTSyntax: vellumRe,
+	}
 
 	fstRE, start, end, err := fstregexp.ParsedRegexp(vellumRe.String(), vellumRe)
 	if err != nil {
@@ -72,13 +83,99 @@ func CompileRegex(r []byte) (CompiledRegex, error) {
 	compiledRegex.PrefixBegin = start
 	compiledRegex.PrefixEnd = end
 
+	// Literals which are a prefix of the literal prefix are implied by it.
+	for _, literal := range requiredLiterals(vellumRe) {
+		if !bytes.HasPrefix(start, literal) {
+			compiledRegex.RequiredLiterals = append(compiledRegex.RequiredLiterals, literal)
+		}
+	}
+
 	return compiledRegex, nil
 }
 
+// Match returns whether the provided term matches the compiled regexp. Terms which don't
+// begin with the literal prefix of the regexp, or don't contain each of its required
+// literals, are ruled out without evaluating the regexp.
+func (c CompiledRegex) Match(term []byte) bool {
+	if !bytes.HasPrefix(term, c.PrefixBegin) {
+		return false
+	}
+	for _, literal := range c.RequiredLiterals {
+		if !bytes.Contains(term, literal) {
+			return false
+		}
+	}
+	return c.Simple.Match(term)
+}
+
 func parseRegexp(re string) (*syntax.Regexp, error) {
 	return syntax.Parse(re, syntax.Perl)
 }
 
+// requiredLiterals returns literals which every string matched by the parsed regexp
+// contains. Case-insensitive literals are ignored since they match more than their runes.
+func requiredLiterals(ast *syntax.Regexp) [][]byte {
+	isLiteral := func(r *syntax.Regexp) bool {
+		return r.Op == syntax.OpLiteral && r.Flags&syntax.FoldCase == 0
+	}
+
+	switch ast.Op {
+	case syntax.OpLiteral:
+		if isLiteral(ast) {
+			return [][]byte{[]byte(string(ast.Rune))}
+		}
+	case syntax.OpCapture, syntax.OpPlus:
+		return requiredLiterals(ast.Sub[0])
+	case syntax.OpRepeat:
+		if ast.Min >= 1 {
+			return requiredLiterals(ast.Sub[0])
+		}
+	case syntax.OpConcat:
+		// Adjacent literals must appear contiguously so are combined into a single literal.
+		var (
+			literals [][]byte
+			run      []rune
+		)
+		for _, sub := range ast.Sub {
+			if sub.Op == syntax.OpEmptyMatch {
+				continue
+			}
+			if isLiteral(sub) {
+				run = append(run, sub.Rune...)
+				continue
+			}
+			if len(run) > 0 {
+				literals = append(literals, []byte(string(run)))
+				run = nil
+			}
+			literals = append(literals, requiredLiterals(sub)...)
+		}
+		if len(run) > 0 {
+			literals = append(literals, []byte(string(run)))
+		}
+		return literals
+	}
+	return nil
+}
+
+// validateRegexp returns an error if the parsed syntax.Regexp contains constructs which
+// can't be matched against the FST segment. NB: assumes input regexp AST is un-anchored.
+func validateRegexp(ast *syntax.Regexp) error {
+	switch ast.Op {
+	case syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText:
+		return errors.New("'^' and '$' are only supported at the start and end of the regexp")
+	case syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return errors.New("word boundaries are not supported")
+	}
+
+	for _, sub := range ast.Sub {
+		if err := validateRegexp(sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ensureRegexpAnchored adds '^' and '$' characters to appropriate locations in the parsed syntax.Regexp,
 // to ensure every input regular expression is converted to it's equivalent anchored regular expression.
 // NB: assumes input regexp AST is un-anchored.
@@ -226,6 +226,87 @@ func TestEnsureRegexpUnachored(t *testing.T) {
 	}
 }
 
+func TestCompileRegexStoresFSTSyntax(t *testing.T) {
+	c, err := CompileRegex([]byte("^(foo|bar)baz$"))
+	require.NoError(t, err)
+	require.NotNil(t, c.FSTSyntax)
+	require.Equal(t, "(foo|bar)baz", c.FSTSyntax.String())
+	require.Equal(t, `\A(foo|bar)baz\z`, c.Simple.String())
+}
+
+func TestCompileRegexLiterals(t *testing.T) {
+	tests := []struct {
+		pattern          string
+		expectedPrefix   string
+		expectedLiterals []string
+	}{
+		{pattern: "abc.*", expectedPrefix: "abc"},
+		{pattern: "(abc).*", expectedPrefix: "abc"},
+		{pattern: "^abc.*", expectedPrefix: "abc"},
+		{pattern: "(?i)abc.*", expectedPrefix: ""},
+		{pattern: "api-(prod|staging).*", expectedPrefix: "api-"},
+		{pattern: ".*-prod-.*", expectedPrefix: "", expectedLiterals: []string{"-prod-"}},
+		{pattern: ".*foo.+bar", expectedPrefix: "", expectedLiterals: []string{"foo", "bar"}},
+		{pattern: "ab.*cd(ef)+", expectedPrefix: "ab", expectedLiterals: []string{"cd", "ef"}},
+		{pattern: ".*(foo|bar)", expectedPrefix: ""},
+		{pattern: ".*(?:foo)?", expectedPrefix: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern, func(t *testing.T) {
+			c, err := CompileRegex([]byte(test.pattern))
+			require.NoError(t, err)
+			require.Equal(t, test.expectedPrefix, string(c.PrefixBegin))
+
+			var literals []string
+			for _, literal := range c.RequiredLiterals {
+				literals = append(literals, string(literal))
+			}
+			require.Equal(t, test.expectedLiterals, literals)
+		})
+	}
+}
+
+func TestCompiledRegexMatch(t *testing.T) {
+	terms := []string{
+		"", "abc", "ABC", "abcdef", "xabc", "api-prod-1", "api-staging", "api-dev",
+		"web-prod-2", "foobar", "foo-bar", "barfoo", "abxcdef", "abcdefef", "prod",
+	}
+	patterns := []string{
+		"abc.*", "(?i)abc.*", "api-(prod|staging).*", ".*-prod-.*", ".*foo.+bar",
+		"ab.*cd(ef)+", ".*(foo|bar)", "foo|bar", "ab.*?ef", ".*?foo.+?bar", "(?U)abc.*",
+	}
+
+	for _, pattern := range patterns {
+		c, err := CompileRegex([]byte(pattern))
+		require.NoError(t, err)
+		for _, term := range terms {
+			require.Equal(t, c.Simple.MatchString(term), c.Match([]byte(term)),
+				"pattern %s, term %s", pattern, term)
+		}
+	}
+}
+
+func TestCompileRegexUnsupported(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+	}{
+		{name: "anchor in middle", pattern: "a^b"},
+		{name: "end anchor in middle", pattern: "a$b"},
+		{name: "word boundary", pattern: `\bfoo`},
+		{name: "no word boundary", pattern: `foo\B`},
+		{name: "backreference", pattern: `(a)\1`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := CompileRegex([]byte(test.pattern))
+			require.Error(t, err)
+		})
+	}
+}
+
 func TestEnsureRegexpAnchored(t *testing.T) {
 	testCases := []testCase{
 		testCase{
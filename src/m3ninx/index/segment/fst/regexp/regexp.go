@@ -56,23 +56,77 @@ func ParsedRegexp(pattern string, parsed *syntax.Regexp) (a *vregexp.Regexp, pre
 	return re, nil, nil, nil
 }
 
-// LiteralPrefix returns the literal prefix given the parse tree for a regexp
+// LiteralPrefix returns the literal prefix given the parse tree for a regexp, i.e. the
+// longest string every match of the regexp is guaranteed to begin with.
 func LiteralPrefix(s *syntax.Regexp) string {
-	// traverse the left-most branch in the parse tree as long as the
-	// node represents a concatenation
-	for s != nil && s.Op == syntax.OpConcat {
-		if len(s.Sub) < 1 {
-			return ""
+	prefix, _ := literalPrefix(s)
+	return string(prefix)
+}
+
+// literalPrefix returns the literal prefix of the parse tree, and whether the parse tree
+// matches exactly the prefix and nothing else.
+func literalPrefix(s *syntax.Regexp) (prefix []rune, complete bool) {
+	switch s.Op {
+	case syntax.OpLiteral:
+		// NB: case-insensitive literals match more than their runes so can't be used.
+		if s.Flags&syntax.FoldCase != 0 {
+			return nil, false
 		}
+		return s.Rune, true
 
-		s = s.Sub[0]
-	}
+	case syntax.OpEmptyMatch, syntax.OpBeginText, syntax.OpEndText, syntax.OpBeginLine,
+		syntax.OpEndLine:
+		// Zero-width assertions don't consume any input.
+		return nil, true
+
+	case syntax.OpCapture:
+		return literalPrefix(s.Sub[0])
+
+	case syntax.OpConcat:
+		for _, sub := range s.Sub {
+			subPrefix, subComplete := literalPrefix(sub)
+			prefix = append(prefix, subPrefix...)
+			if !subComplete {
+				return prefix, false
+			}
+		}
+		return prefix, true
+
+	case syntax.OpPlus:
+		// At least one repetition of the sub-expression must be matched.
+		prefix, _ = literalPrefix(s.Sub[0])
+		return prefix, false
 
-	if s.Op == syntax.OpLiteral {
-		return string(s.Rune)
+	case syntax.OpRepeat:
+		if s.Min < 1 {
+			return nil, false
+		}
+		prefix, _ = literalPrefix(s.Sub[0])
+		return prefix, false
+
+	case syntax.OpAlternate:
+		// Every match begins with the common prefix of the alternatives' prefixes, and
+		// only matches exactly that prefix if every alternative does.
+		if len(s.Sub) == 0 {
+			return nil, false
+		}
+		prefix, complete = literalPrefix(s.Sub[0])
+		for _, sub := range s.Sub[1:] {
+			subPrefix, subComplete := literalPrefix(sub)
+			complete = complete && subComplete && string(subPrefix) == string(prefix)
+			prefix = commonPrefix(prefix, subPrefix)
+		}
+		return prefix, complete
 	}
 
-	return "" // no literal prefix
+	return nil, false // no literal prefix
+}
+
+func commonPrefix(a, b []rune) []rune {
+	i := 0
+	for ; i < len(a) && i < len(b) && a[i] == b[i]; i++ {
+	}
+	return a[:i]
 }
 
 // IncrementBytes increments the provided bytes to the next word boundary.
@@ -42,10 +42,18 @@ func TestLiteralPrefix(t *testing.T) {
 		{"h.?", "h"},
 		{"h[a-z]", "h"},
 		{`h\s`, "h"},
-		{`(hello)world`, ""},
+		{`(hello)world`, "helloworld"},
+		{`(hello)+world`, "hello"},
+		{`(hello){2,}world`, "hello"},
+		{`(hello)?world`, ""},
+		{`hello(world|wide)`, "hellow"},
+		{`(hello|help)+`, "hel"},
+		{`(?i)hello`, ""},
+		{`he(?i)llo`, "he"},
 		{`日本語`, "日本語"},
 		{`日本語\w`, "日本語"},
-		{`^hello`, ""},
+		{`^hello`, "hello"},
+		{`^api-(prod|staging)-us-east$`, "api-"},
 		{`^`, ""},
 		{`$`, ""},
 	}
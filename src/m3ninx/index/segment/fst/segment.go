@@ -21,10 +21,12 @@
 package fst
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
 	"sync"
+	"sync/atomic"
 
 	"github.com/m3db/m3/src/m3ninx/doc"
 	"github.com/m3db/m3/src/m3ninx/generated/proto/fswriter"
@@ -32,6 +34,7 @@ import (
 	sgmt "github.com/m3db/m3/src/m3ninx/index/segment"
 	"github.com/m3db/m3/src/m3ninx/index/segment/fst/encoding"
 	"github.com/m3db/m3/src/m3ninx/index/segment/fst/encoding/docs"
+	fstregexp "github.com/m3db/m3/src/m3ninx/index/segment/fst/regexp"
 	"github.com/m3db/m3/src/m3ninx/postings"
 	"github.com/m3db/m3/src/m3ninx/postings/pilosa"
 	"github.com/m3db/m3/src/m3ninx/postings/roaring"
@@ -44,12 +47,19 @@ import (
 var (
 	errReaderClosed            = errors.New("segment is closed")
 	errReaderNilRegexp         = errors.New("nil regexp provided")
+	errReaderNilFuzzy          = errors.New("nil fuzzy automaton provided")
 	errUnsupportedMajorVersion = errors.New("unsupported major version")
 	errDocumentsDataUnset      = errors.New("documents data bytes are not set")
 	errDocumentsIdxUnset       = errors.New("documents index bytes are not set")
 	errPostingsDataUnset       = errors.New("postings data bytes are not set")
 	errFSTTermsDataUnset       = errors.New("fst terms data bytes are not set")
 	errFSTFieldsDataUnset      = errors.New("fst fields data bytes are not set")
+
+	// lastSegmentID is the ID of the most recently created segment, segment IDs are
+	// unique amongst the segments created by the process.
+	lastSegmentID uint64
+
+	alwaysMatch = &vellum.AlwaysMatch{}
 )
 
 // SegmentData represent the collection of required parameters to construct a Segment.
@@ -128,6 +138,7 @@ func NewSegment(data SegmentData, opts Options) (Segment, error) {
 	docsDataReader := docs.NewDataReader(data.DocsData)
 
 	return &fsSegment{
+		id:              atomic.AddUint64(&lastSegmentID, 1),
 		fieldsFST:       fieldsFST,
 		docsDataReader:  docsDataReader,
 		docsIndexReader: docsIndexReader,
@@ -142,7 +153,9 @@ func NewSegment(data SegmentData, opts Options) (Segment, error) {
 
 type fsSegment struct {
 	sync.RWMutex
+	id              uint64
 	closed          bool
+	closeFns        []func()
 	fieldsFST       *vellum.FST
 	docsDataReader  *docs.DataReader
 	docsIndexReader *docs.IndexReader
@@ -203,8 +216,8 @@ func (r *fsSegment) Reader() (index.Reader, error) {
 
 func (r *fsSegment) Close() error {
 	r.Lock()
-	defer r.Unlock()
 	if r.closed {
+		r.Unlock()
 		return errReaderClosed
 	}
 	r.closed = true
@@ -213,9 +226,29 @@ func (r *fsSegment) Close() error {
 	if r.data.Closer != nil {
 		multiErr = multiErr.Add(r.data.Closer.Close())
 	}
+	closeFns := r.closeFns
+	r.closeFns = nil
+	r.Unlock()
+
+	for _, fn := range closeFns {
+		fn()
+	}
 	return multiErr.FinalError()
 }
 
+// onClose registers a function to be called when the segment is closed, or calls it
+// immediately if the segment is already closed.
+func (r *fsSegment) onClose(fn func()) {
+	r.Lock()
+	if r.closed {
+		r.Unlock()
+		fn()
+		return
+	}
+	r.closeFns = append(r.closeFns, fn)
+	r.Unlock()
+}
+
 func (r *fsSegment) Fields() (sgmt.FieldsIterator, error) {
 	r.RLock()
 	defer r.RUnlock()
@@ -254,6 +287,10 @@ func (r *fsSegment) Terms(field []byte) (sgmt.TermsIterator, error) {
 }
 
 func (r *fsSegment) MatchTerm(field []byte, term []byte) (postings.List, error) {
+	return r.matchTerm(field, term, nil)
+}
+
+func (r *fsSegment) matchTerm(field, term []byte, termsScanned *int64) (postings.List, error) {
 	r.RLock()
 	defer r.RUnlock()
 	if r.closed {
@@ -274,6 +311,7 @@ func (r *fsSegment) MatchTerm(field []byte, term []byte) (postings.List, error)
 	defer fstCloser.Close()
 
 	postingsOffset, exists, err := termsFST.Get(term)
+	addTermsScanned(termsScanned, 1)
 	if err != nil {
 		return nil, err
 	}
@@ -296,6 +334,14 @@ func (r *fsSegment) MatchTerm(field []byte, term []byte) (postings.List, error)
 }
 
 func (r *fsSegment) MatchRegexp(field []byte, compiled index.CompiledRegex) (postings.List, error) {
+	return r.matchRegexp(field, compiled, nil)
+}
+
+func (r *fsSegment) matchRegexp(
+	field []byte,
+	compiled index.CompiledRegex,
+	termsScanned *int64,
+) (postings.List, error) {
 	r.RLock()
 	defer r.RUnlock()
 	if r.closed {
@@ -307,6 +353,430 @@ func (r *fsSegment) MatchRegexp(field []byte, compiled index.CompiledRegex) (pos
 		return nil, errReaderNilRegexp
 	}
 
+	return r.matchAutomatonWithRLock(field, re, compiled.PrefixBegin, compiled.PrefixEnd,
+		nil, compiled.MaxTermsMatched, termsScanned)
+}
+
+func (r *fsSegment) MatchFuzzy(field []byte, compiled index.CompiledFuzzy) (postings.List, error) {
+	return r.matchFuzzy(field, compiled, nil)
+}
+
+func (r *fsSegment) matchFuzzy(
+	field []byte,
+	compiled index.CompiledFuzzy,
+	termsScanned *int64,
+) (postings.List, error) {
+	r.RLock()
+	defer r.RUnlock()
+	if r.closed {
+		return nil, errReaderClosed
+	}
+
+	fuzzy := compiled.FST
+	if fuzzy == nil {
+		return nil, errReaderNilFuzzy
+	}
+
+	return r.matchAutomatonWithRLock(field, fuzzy, nil, nil, nil, 0, termsScanned)
+}
+
+func (r *fsSegment) MatchPrefix(field, prefix []byte) (postings.List, error) {
+	return r.matchPrefix(field, prefix, nil)
+}
+
+func (r *fsSegment) matchPrefix(field, prefix []byte, termsScanned *int64) (postings.List, error) {
+	r.RLock()
+	defer r.RUnlock()
+	if r.closed {
+		return nil, errReaderClosed
+	}
+
+	// NB: every term within the range beginning with the prefix and ending just before
+	// the prefix incremented begins with the prefix, so there's no need to run an
+	// automaton over the terms and the search can seek straight to the prefix.
+	var prefixBegin, prefixEnd []byte
+	if len(prefix) > 0 {
+		prefixBegin = prefix
+		prefixEnd = fstregexp.IncrementBytes(prefix)
+	}
+
+	return r.matchAutomatonWithRLock(field, alwaysMatch, prefixBegin, prefixEnd, nil, 0, termsScanned)
+}
+
+func (r *fsSegment) MatchField(field []byte) (postings.List, error) {
+	return r.matchField(field, nil)
+}
+
+func (r *fsSegment) matchField(field []byte, termsScanned *int64) (postings.List, error) {
+	r.RLock()
+	defer r.RUnlock()
+	if r.closed {
+		return nil, errReaderClosed
+	}
+
+	termsFST, exists, err := r.retrieveTermsFSTWithRLock(field)
+	if err != nil {
+		return nil, err
+	}
+
+	pl := r.opts.PostingsListPool().Get()
+	if !exists {
+		return pl, nil
+	}
+
+	// NB: every term of the field matches so rather than decoding each postings list and
+	// unioning them at the end, the postings are added directly to a single list.
+	var (
+		fstCloser     = x.NewSafeCloser(termsFST)
+		iter, iterErr = termsFST.Iterator(nil, nil)
+		iterCloser    = x.NewSafeCloser(iter)
+		scanned       int64
+	)
+	defer func() {
+		iterCloser.Close()
+		fstCloser.Close()
+		addTermsScanned(termsScanned, scanned)
+	}()
+
+	for iterErr == nil {
+		scanned++
+		_, postingsOffset := iter.Current()
+		postingsBytes, err := r.retrieveBytesWithRLock(r.data.PostingsData, postingsOffset)
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve postings data: %v", err)
+		}
+		if err := pilosa.UnmarshalInto(postingsBytes, pl); err != nil {
+			return nil, err
+		}
+		iterErr = iter.Next()
+	}
+
+	if iterErr != vellum.ErrIteratorDone {
+		return nil, iterErr
+	}
+
+	if err := iterCloser.Close(); err != nil {
+		return nil, err
+	}
+
+	if err := fstCloser.Close(); err != nil {
+		return nil, err
+	}
+
+	return pl, nil
+}
+
+func (r *fsSegment) MatchRange(field []byte, compiled index.CompiledRange) (postings.List, error) {
+	return r.matchRange(field, compiled, nil)
+}
+
+func (r *fsSegment) matchRange(
+	field []byte,
+	compiled index.CompiledRange,
+	termsScanned *int64,
+) (postings.List, error) {
+	r.RLock()
+	defer r.RUnlock()
+	if r.closed {
+		return nil, errReaderClosed
+	}
+
+	// NB: numeric ranges don't follow the lexical ordering of the terms so every term
+	// of the field has to be checked, whereas lexical ranges can seek to the lower bound
+	// and stop at the upper bound. The exclusivity of the bounds is left to the filter.
+	if compiled.Numeric {
+		return r.matchAutomatonWithRLock(field, alwaysMatch, nil, nil, compiled.Match, 0, termsScanned)
+	}
+
+	var (
+		startInclusive = compiled.Lower
+		endExclusive   []byte
+	)
+	if compiled.Upper != nil {
+		// The smallest term greater than the upper bound is the upper bound followed by a
+		// zero byte, which ensures the upper bound itself is visited.
+		endExclusive = append(append([]byte(nil), compiled.Upper...), 0)
+	}
+	if startInclusive != nil && endExclusive != nil && bytes.Compare(startInclusive, endExclusive) >= 0 {
+		return r.opts.PostingsListPool().Get(), nil
+	}
+
+	return r.matchAutomatonWithRLock(
+		field, alwaysMatch, startInclusive, endExclusive, compiled.Match, 0, termsScanned)
+}
+
+func (r *fsSegment) MatchFold(field []byte, compiled index.CompiledFold) (postings.List, error) {
+	return r.matchFold(field, compiled, nil)
+}
+
+func (r *fsSegment) matchFold(
+	field []byte,
+	compiled index.CompiledFold,
+	termsScanned *int64,
+) (postings.List, error) {
+	r.RLock()
+	defer r.RUnlock()
+	if r.closed {
+		return nil, errReaderClosed
+	}
+
+	// NB: rather than running an automaton over every term of the field, only the terms
+	// beginning with each case variant of the leading characters of the term are scanned,
+	// leaving the comparison of the rest of each term to the filter.
+	pls := make([]postings.List, 0, len(compiled.Prefixes))
+	for _, prefix := range compiled.Prefixes {
+		var prefixBegin, prefixEnd []byte
+		if len(prefix) > 0 {
+			prefixBegin = prefix
+			prefixEnd = fstregexp.IncrementBytes(prefix)
+		}
+
+		pl, err := r.matchAutomatonWithRLock(
+			field, alwaysMatch, prefixBegin, prefixEnd, compiled.Match, 0, termsScanned)
+		if err != nil {
+			return nil, err
+		}
+		pls = append(pls, pl)
+	}
+
+	return roaring.Union(pls)
+}
+
+func (r *fsSegment) MatchRegexpTerms(
+	field []byte,
+	compiled index.CompiledRegex,
+	limit int,
+) ([]index.TermFrequency, error) {
+	return r.matchRegexpTerms(field, compiled, limit, nil)
+}
+
+func (r *fsSegment) matchRegexpTerms(
+	field []byte,
+	compiled index.CompiledRegex,
+	limit int,
+	termsScanned *int64,
+) ([]index.TermFrequency, error) {
+	r.RLock()
+	defer r.RUnlock()
+	if r.closed {
+		return nil, errReaderClosed
+	}
+
+	re := compiled.FST
+	if re == nil {
+		return nil, errReaderNilRegexp
+	}
+
+	return r.matchAutomatonTermsWithRLock(field, re, compiled.PrefixBegin, compiled.PrefixEnd,
+		limit, termsScanned)
+}
+
+func (r *fsSegment) MatchPrefixTerms(field, prefix []byte, limit int) ([]index.TermFrequency, error) {
+	return r.matchPrefixTerms(field, prefix, limit, nil)
+}
+
+func (r *fsSegment) matchPrefixTerms(
+	field, prefix []byte,
+	limit int,
+	termsScanned *int64,
+) ([]index.TermFrequency, error) {
+	r.RLock()
+	defer r.RUnlock()
+	if r.closed {
+		return nil, errReaderClosed
+	}
+
+	var prefixBegin, prefixEnd []byte
+	if len(prefix) > 0 {
+		prefixBegin = prefix
+		prefixEnd = fstregexp.IncrementBytes(prefix)
+	}
+
+	return r.matchAutomatonTermsWithRLock(field, alwaysMatch, prefixBegin, prefixEnd, limit, termsScanned)
+}
+
+func (r *fsSegment) MatchPrefixTermsAfter(
+	field, prefix, after []byte,
+	limit int,
+) ([]index.TermFrequency, error) {
+	return r.matchPrefixTermsAfter(field, prefix, after, limit, nil)
+}
+
+func (r *fsSegment) matchPrefixTermsAfter(
+	field, prefix, after []byte,
+	limit int,
+	termsScanned *int64,
+) ([]index.TermFrequency, error) {
+	r.RLock()
+	defer r.RUnlock()
+	if r.closed {
+		return nil, errReaderClosed
+	}
+
+	var start, end []byte
+	if len(prefix) > 0 {
+		start = prefix
+		end = fstregexp.IncrementBytes(prefix)
+	}
+
+	// NB: the smallest term sorting after the given term is the term followed by a zero
+	// byte, starting the search there seeks past every term at or before it.
+	if after != nil {
+		next := make([]byte, 0, len(after)+1)
+		next = append(append(next, after...), 0)
+		if bytes.Compare(next, start) > 0 {
+			start = next
+		}
+	}
+
+	if end != nil && bytes.Compare(start, end) >= 0 {
+		return nil, nil
+	}
+
+	return r.matchOrderedTermsWithRLock(field, start, end, limit, termsScanned)
+}
+
+// matchAutomatonTermsWithRLock returns at most limit of the terms of the given field
+// accepted by the automaton, within the provided range of terms, along with their
+// document frequencies. Only the postings lists of the terms are decoded to count them,
+// they're never unioned together.
+func (r *fsSegment) matchAutomatonTermsWithRLock(
+	field []byte,
+	automaton vellum.Automaton,
+	startInclusive, endExclusive []byte,
+	limit int,
+	termsScanned *int64,
+) ([]index.TermFrequency, error) {
+	termsFST, exists, err := r.retrieveTermsFSTWithRLock(field)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, nil
+	}
+
+	var (
+		fstCloser     = x.NewSafeCloser(termsFST)
+		iter, iterErr = termsFST.Search(automaton, startInclusive, endExclusive)
+		iterCloser    = x.NewSafeCloser(iter)
+		top           = index.NewTopTerms(limit)
+		scanned       int64
+	)
+	defer func() {
+		iterCloser.Close()
+		fstCloser.Close()
+		addTermsScanned(termsScanned, scanned)
+	}()
+
+	for {
+		if iterErr == vellum.ErrIteratorDone {
+			break
+		}
+
+		if iterErr != nil {
+			return nil, iterErr
+		}
+
+		scanned++
+		term, postingsOffset := iter.Current()
+		pl, err := r.retrievePostingsListWithRLock(postingsOffset)
+		if err != nil {
+			return nil, err
+		}
+		top.Add(term, pl.Len())
+		iterErr = iter.Next()
+	}
+
+	if err := iterCloser.Close(); err != nil {
+		return nil, err
+	}
+
+	if err := fstCloser.Close(); err != nil {
+		return nil, err
+	}
+
+	return top.Terms(), nil
+}
+
+// matchOrderedTermsWithRLock returns at most limit of the terms of the given field
+// within the provided range of terms, along with their document frequencies, in
+// ascending order. Iteration stops as soon as the limit is reached.
+func (r *fsSegment) matchOrderedTermsWithRLock(
+	field []byte,
+	startInclusive, endExclusive []byte,
+	limit int,
+	termsScanned *int64,
+) ([]index.TermFrequency, error) {
+	termsFST, exists, err := r.retrieveTermsFSTWithRLock(field)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, nil
+	}
+
+	var (
+		fstCloser     = x.NewSafeCloser(termsFST)
+		iter, iterErr = termsFST.Search(alwaysMatch, startInclusive, endExclusive)
+		iterCloser    = x.NewSafeCloser(iter)
+		terms         []index.TermFrequency
+		scanned       int64
+	)
+	defer func() {
+		iterCloser.Close()
+		fstCloser.Close()
+		addTermsScanned(termsScanned, scanned)
+	}()
+
+	for limit <= 0 || len(terms) < limit {
+		if iterErr == vellum.ErrIteratorDone {
+			break
+		}
+
+		if iterErr != nil {
+			return nil, iterErr
+		}
+
+		scanned++
+		term, postingsOffset := iter.Current()
+		pl, err := r.retrievePostingsListWithRLock(postingsOffset)
+		if err != nil {
+			return nil, err
+		}
+		// NB: the term is only valid until the iterator is advanced so it's copied.
+		terms = append(terms, index.TermFrequency{
+			Term:  append([]byte(nil), term...),
+			Count: pl.Len(),
+		})
+		iterErr = iter.Next()
+	}
+
+	if err := iterCloser.Close(); err != nil {
+		return nil, err
+	}
+
+	if err := fstCloser.Close(); err != nil {
+		return nil, err
+	}
+
+	return terms, nil
+}
+
+// matchAutomatonWithRLock returns the union of the postings lists of the terms of the
+// given field accepted by the automaton, within the provided range of terms. If filter
+// is non-nil, only the terms it also accepts are included. If maxTermsMatched is positive
+// and more terms than it are matched, index.ErrTooManyTermsMatched is returned. The number
+// of terms visited is added to termsScanned, if non-nil.
+func (r *fsSegment) matchAutomatonWithRLock(
+	field []byte,
+	automaton vellum.Automaton,
+	startInclusive, endExclusive []byte,
+	filter func(term []byte) bool,
+	maxTermsMatched int,
+	termsScanned *int64,
+) (postings.List, error) {
 	termsFST, exists, err := r.retrieveTermsFSTWithRLock(field)
 	if err != nil {
 		return nil, err
@@ -319,14 +789,16 @@ func (r *fsSegment) MatchRegexp(field []byte, compiled index.CompiledRegex) (pos
 
 	var (
 		fstCloser     = x.NewSafeCloser(termsFST)
-		iter, iterErr = termsFST.Search(re, compiled.PrefixBegin, compiled.PrefixEnd)
+		iter, iterErr = termsFST.Search(automaton, startInclusive, endExclusive)
 		iterCloser    = x.NewSafeCloser(iter)
 		// NB(prateek): way quicker to union the PLs together at the end, rathen than one at a time.
-		pls []postings.List // TODO: pool this slice allocation
+		pls     []postings.List // TODO: pool this slice allocation
+		scanned int64
 	)
 	defer func() {
 		iterCloser.Close()
 		fstCloser.Close()
+		addTermsScanned(termsScanned, scanned)
 	}()
 
 	for {
@@ -338,7 +810,17 @@ func (r *fsSegment) MatchRegexp(field []byte, compiled index.CompiledRegex) (pos
 			return nil, iterErr
 		}
 
-		_, postingsOffset := iter.Current()
+		scanned++
+		term, postingsOffset := iter.Current()
+		if filter != nil && !filter(term) {
+			iterErr = iter.Next()
+			continue
+		}
+
+		if maxTermsMatched > 0 && len(pls) >= maxTermsMatched {
+			return nil, index.ErrTooManyTermsMatched
+		}
+
 		nextPl, err := r.retrievePostingsListWithRLock(postingsOffset)
 		if err != nil {
 			return nil, err
@@ -363,6 +845,13 @@ func (r *fsSegment) MatchRegexp(field []byte, compiled index.CompiledRegex) (pos
 	return pl, nil
 }
 
+// addTermsScanned adds n to the count of terms scanned, if it's being tracked.
+func addTermsScanned(termsScanned *int64, n int64) {
+	if termsScanned != nil {
+		atomic.AddInt64(termsScanned, n)
+	}
+}
+
 func (r *fsSegment) MatchAll() (postings.MutableList, error) {
 	r.RLock()
 	defer r.RUnlock()
@@ -496,10 +985,14 @@ type fsSegmentReader struct {
 	sync.RWMutex
 	closed bool
 
-	fsSegment *fsSegment
+	fsSegment    *fsSegment
+	termsScanned int64
 }
 
-var _ index.Reader = &fsSegmentReader{}
+var (
+	_ index.StatsReader     = &fsSegmentReader{}
+	_ index.ImmutableReader = &fsSegmentReader{}
+)
 
 func (sr *fsSegmentReader) MatchTerm(field []byte, term []byte) (postings.List, error) {
 	sr.RLock()
@@ -507,7 +1000,7 @@ func (sr *fsSegmentReader) MatchTerm(field []byte, term []byte) (postings.List,
 	if sr.closed {
 		return nil, errReaderClosed
 	}
-	return sr.fsSegment.MatchTerm(field, term)
+	return sr.fsSegment.matchTerm(field, term, &sr.termsScanned)
 }
 
 func (sr *fsSegmentReader) MatchRegexp(field []byte, compiled index.CompiledRegex) (postings.List, error) {
@@ -516,7 +1009,86 @@ func (sr *fsSegmentReader) MatchRegexp(field []byte, compiled index.CompiledRege
 	if sr.closed {
 		return nil, errReaderClosed
 	}
-	return sr.fsSegment.MatchRegexp(field, compiled)
+	return sr.fsSegment.matchRegexp(field, compiled, &sr.termsScanned)
+}
+
+func (sr *fsSegmentReader) MatchFuzzy(field []byte, compiled index.CompiledFuzzy) (postings.List, error) {
+	sr.RLock()
+	defer sr.RUnlock()
+	if sr.closed {
+		return nil, errReaderClosed
+	}
+	return sr.fsSegment.matchFuzzy(field, compiled, &sr.termsScanned)
+}
+
+func (sr *fsSegmentReader) MatchPrefix(field, prefix []byte) (postings.List, error) {
+	sr.RLock()
+	defer sr.RUnlock()
+	if sr.closed {
+		return nil, errReaderClosed
+	}
+	return sr.fsSegment.matchPrefix(field, prefix, &sr.termsScanned)
+}
+
+func (sr *fsSegmentReader) MatchField(field []byte) (postings.List, error) {
+	sr.RLock()
+	defer sr.RUnlock()
+	if sr.closed {
+		return nil, errReaderClosed
+	}
+	return sr.fsSegment.matchField(field, &sr.termsScanned)
+}
+
+func (sr *fsSegmentReader) MatchRange(field []byte, compiled index.CompiledRange) (postings.List, error) {
+	sr.RLock()
+	defer sr.RUnlock()
+	if sr.closed {
+		return nil, errReaderClosed
+	}
+	return sr.fsSegment.matchRange(field, compiled, &sr.termsScanned)
+}
+
+func (sr *fsSegmentReader) MatchFold(field []byte, compiled index.CompiledFold) (postings.List, error) {
+	sr.RLock()
+	defer sr.RUnlock()
+	if sr.closed {
+		return nil, errReaderClosed
+	}
+	return sr.fsSegment.matchFold(field, compiled, &sr.termsScanned)
+}
+
+func (sr *fsSegmentReader) MatchRegexpTerms(
+	field []byte,
+	compiled index.CompiledRegex,
+	limit int,
+) ([]index.TermFrequency, error) {
+	sr.RLock()
+	defer sr.RUnlock()
+	if sr.closed {
+		return nil, errReaderClosed
+	}
+	return sr.fsSegment.matchRegexpTerms(field, compiled, limit, &sr.termsScanned)
+}
+
+func (sr *fsSegmentReader) MatchPrefixTerms(field, prefix []byte, limit int) ([]index.TermFrequency, error) {
+	sr.RLock()
+	defer sr.RUnlock()
+	if sr.closed {
+		return nil, errReaderClosed
+	}
+	return sr.fsSegment.matchPrefixTerms(field, prefix, limit, &sr.termsScanned)
+}
+
+func (sr *fsSegmentReader) MatchPrefixTermsAfter(
+	field, prefix, after []byte,
+	limit int,
+) ([]index.TermFrequency, error) {
+	sr.RLock()
+	defer sr.RUnlock()
+	if sr.closed {
+		return nil, errReaderClosed
+	}
+	return sr.fsSegment.matchPrefixTermsAfter(field, prefix, after, limit, &sr.termsScanned)
 }
 
 func (sr *fsSegmentReader) MatchAll() (postings.MutableList, error) {
@@ -555,6 +1127,20 @@ func (sr *fsSegmentReader) AllDocs() (index.IDDocIterator, error) {
 	return sr.fsSegment.AllDocs()
 }
 
+func (sr *fsSegmentReader) SegmentID() uint64 {
+	return sr.fsSegment.id
+}
+
+func (sr *fsSegmentReader) OnSegmentClose(fn func()) {
+	sr.fsSegment.onClose(fn)
+}
+
+func (sr *fsSegmentReader) Stats() index.ReaderStats {
+	return index.ReaderStats{
+		TermsScanned: atomic.LoadInt64(&sr.termsScanned),
+	}
+}
+
 func (sr *fsSegmentReader) Close() error {
 	sr.Lock()
 	defer sr.Unlock()
@@ -0,0 +1,109 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fst
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/m3db/m3/src/m3ninx/doc"
+	"github.com/m3db/m3/src/m3ninx/index"
+	"github.com/m3db/m3/src/m3ninx/index/segment/mem"
+	"github.com/m3db/m3/src/m3ninx/postings"
+
+	"github.com/stretchr/testify/require"
+)
+
+const benchmarkNumTerms = 100000
+
+var (
+	benchmarkField  = []byte("service")
+	benchmarkPrefix = []byte("service-0420")
+)
+
+func BenchmarkSegmentMatchPrefix(b *testing.B) {
+	r := newBenchmarkSegmentReader(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pl, err := r.MatchPrefix(benchmarkField, benchmarkPrefix)
+		require.NoError(b, err)
+		require.Equal(b, 10, pl.Len())
+	}
+}
+
+func BenchmarkSegmentMatchRegexpPrefix(b *testing.B) {
+	r := newBenchmarkSegmentReader(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		compiled, err := index.CompileRegex(append(benchmarkPrefix, []byte(".*")...))
+		require.NoError(b, err)
+		pl, err := r.MatchRegexp(benchmarkField, compiled)
+		require.NoError(b, err)
+		require.Equal(b, 10, pl.Len())
+	}
+}
+
+func BenchmarkSegmentMatchField(b *testing.B) {
+	r := newBenchmarkSegmentReader(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pl, err := r.MatchField(benchmarkField)
+		require.NoError(b, err)
+		require.Equal(b, benchmarkNumTerms, pl.Len())
+	}
+}
+
+func BenchmarkSegmentMatchRegexpAll(b *testing.B) {
+	r := newBenchmarkSegmentReader(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		compiled, err := index.CompileRegex([]byte(".+"))
+		require.NoError(b, err)
+		pl, err := r.MatchRegexp(benchmarkField, compiled)
+		require.NoError(b, err)
+		require.Equal(b, benchmarkNumTerms, pl.Len())
+	}
+}
+
+func newBenchmarkSegmentReader(b *testing.B) index.Reader {
+	memSeg, err := mem.NewSegment(postings.ID(0), mem.NewOptions())
+	require.NoError(b, err)
+	for i := 0; i < benchmarkNumTerms; i++ {
+		_, err := memSeg.Insert(doc.Document{
+			ID: []byte(fmt.Sprintf("id-%05d", i)),
+			Fields: []doc.Field{
+				doc.Field{
+					Name:  benchmarkField,
+					Value: []byte(fmt.Sprintf("service-%05d", i)),
+				},
+			},
+		})
+		require.NoError(b, err)
+	}
+
+	r, err := newFSTSegment(b, memSeg, testOptions).Reader()
+	require.NoError(b, err)
+	return r
+}
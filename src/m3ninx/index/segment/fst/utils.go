@@ -34,7 +34,7 @@ func ToTestSegment(t *testing.T, s sgmt.MutableSegment, opts Options) sgmt.Segme
 	return newFSTSegment(t, s, opts)
 }
 
-func newFSTSegment(t *testing.T, s sgmt.MutableSegment, opts Options) sgmt.Segment {
+func newFSTSegment(t testing.TB, s sgmt.MutableSegment, opts Options) sgmt.Segment {
 	_, err := s.Seal()
 	require.NoError(t, err)
 
@@ -23,6 +23,7 @@ package fst
 import (
 	"bytes"
 	"fmt"
+	"regexp"
 	"sort"
 	"sync"
 	"testing"
@@ -299,6 +300,654 @@ func TestPostingsListRegexAll(t *testing.T) {
 	}
 }
 
+func TestPostingsListMatchField(t *testing.T) {
+	for _, test := range testDocuments {
+		t.Run(test.name, func(t *testing.T) {
+			memSeg, fstSeg := newTestSegments(t, test.docs)
+			memReader, err := memSeg.Reader()
+			require.NoError(t, err)
+			fstReader, err := fstSeg.Reader()
+			require.NoError(t, err)
+
+			fieldsIter, err := memSeg.Fields()
+			require.NoError(t, err)
+			fields := toSlice(t, fieldsIter)
+			fields = append(fields, []byte("no-such-field"))
+			for _, f := range fields {
+				memPl, err := memReader.MatchField(f)
+				require.NoError(t, err)
+				fstPl, err := fstReader.MatchField(f)
+				require.NoError(t, err)
+				require.True(t, memPl.Equal(fstPl))
+
+				c, err := index.CompileRegex([]byte(".*"))
+				require.NoError(t, err)
+				rePl, err := memReader.MatchRegexp(f, c)
+				require.NoError(t, err)
+				require.True(t, memPl.Equal(rePl))
+			}
+		})
+	}
+}
+
+func TestPostingsListMatchRegexpMaxTermsMatched(t *testing.T) {
+	for _, test := range testDocuments {
+		t.Run(test.name, func(t *testing.T) {
+			memSeg, fstSeg := newTestSegments(t, test.docs)
+			memReader, err := memSeg.Reader()
+			require.NoError(t, err)
+			fstReader, err := fstSeg.Reader()
+			require.NoError(t, err)
+			readers := []index.Reader{memReader, fstReader}
+
+			fieldsIter, err := memSeg.Fields()
+			require.NoError(t, err)
+			for _, f := range toSlice(t, fieldsIter) {
+				termsIter, err := memSeg.Terms(f)
+				require.NoError(t, err)
+				numTerms := len(toSlice(t, termsIter))
+
+				c, err := index.CompileRegex([]byte(".*"))
+				require.NoError(t, err)
+
+				// Matching exactly the maximum number of terms succeeds.
+				c.MaxTermsMatched = numTerms
+				for _, r := range readers {
+					_, err := r.MatchRegexp(f, c)
+					require.NoError(t, err)
+				}
+
+				if numTerms < 2 {
+					continue
+				}
+
+				c.MaxTermsMatched = numTerms - 1
+				for _, r := range readers {
+					_, err := r.MatchRegexp(f, c)
+					require.Equal(t, index.ErrTooManyTermsMatched, err)
+				}
+			}
+		})
+	}
+}
+
+func TestPostingsListMatchRegexpAnchoring(t *testing.T) {
+	// Each group lists patterns which must all match the same set of terms, as the
+	// regexp is always required to match the entire term.
+	patternGroups := [][]string{
+		{"apple", "^apple", "apple$", "^apple$", `\Aapple\z`},
+		{"app", "^app", "^app$"},
+		{"(banana|apple)", "^(banana|apple)$", "(^banana$|^apple$)"},
+		{".*ppl.*", "^.*ppl.*$"},
+		{"yel+ow", "^yel+ow$"},
+		{"node_.*", "^node_.*", "^(node_.*)$"},
+		{"[a-z]+", "^[a-z]+$"},
+	}
+
+	for _, test := range testDocuments {
+		t.Run(test.name, func(t *testing.T) {
+			memSeg, fstSeg := newTestSegments(t, test.docs)
+			memReader, err := memSeg.Reader()
+			require.NoError(t, err)
+			fstReader, err := fstSeg.Reader()
+			require.NoError(t, err)
+
+			fieldsIter, err := memSeg.Fields()
+			require.NoError(t, err)
+			fields := toSlice(t, fieldsIter)
+			for _, f := range fields {
+				for _, group := range patternGroups {
+					var expected postings.List
+					for _, pattern := range group {
+						c, err := index.CompileRegex([]byte(pattern))
+						require.NoError(t, err)
+
+						memPl, err := memReader.MatchRegexp(f, c)
+						require.NoError(t, err)
+						fstPl, err := fstReader.MatchRegexp(f, c)
+						require.NoError(t, err)
+						require.True(t, memPl.Equal(fstPl),
+							fmt.Sprintf("%s:%s - [%v] != [%v]", string(f), pattern,
+								pprintIter(memPl), pprintIter(fstPl)))
+
+						if expected == nil {
+							expected = memPl
+							continue
+						}
+						require.True(t, expected.Equal(memPl),
+							fmt.Sprintf("%s:%s - [%v] != [%v]", string(f), pattern,
+								pprintIter(expected), pprintIter(memPl)))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPostingsListMatchRegexpLiterals(t *testing.T) {
+	// Matching with the literal prefix and required literals must return the same
+	// terms as evaluating the regexp against every term of the field.
+	patterns := []string{
+		"apple", "app.*", "(apple|banana)", "^pine(apple)?$", ".*ppl.*",
+		".*-prod-.*", "node_(cpu|memory)_.*", "node_.*_seconds_total", ".*_bytes",
+	}
+
+	for _, test := range testDocuments {
+		t.Run(test.name, func(t *testing.T) {
+			memSeg, fstSeg := newTestSegments(t, test.docs)
+			memReader, err := memSeg.Reader()
+			require.NoError(t, err)
+			fstReader, err := fstSeg.Reader()
+			require.NoError(t, err)
+
+			fieldsIter, err := memSeg.Fields()
+			require.NoError(t, err)
+			fields := toSlice(t, fieldsIter)
+			for _, f := range fields {
+				for _, pattern := range patterns {
+					c, err := index.CompileRegex([]byte(pattern))
+					require.NoError(t, err)
+
+					expected, err := memReader.MatchRegexp(f, index.CompiledRegex{Simple: c.Simple})
+					require.NoError(t, err)
+
+					memPl, err := memReader.MatchRegexp(f, c)
+					require.NoError(t, err)
+					require.True(t, expected.Equal(memPl),
+						fmt.Sprintf("%s:%s - [%v] != [%v]", string(f), pattern,
+							pprintIter(expected), pprintIter(memPl)))
+
+					unbounded := c
+					unbounded.PrefixBegin, unbounded.PrefixEnd = nil, nil
+					fstUnboundedPl, err := fstReader.MatchRegexp(f, unbounded)
+					require.NoError(t, err)
+					fstPl, err := fstReader.MatchRegexp(f, c)
+					require.NoError(t, err)
+					require.True(t, expected.Equal(fstUnboundedPl),
+						fmt.Sprintf("%s:%s - [%v] != [%v]", string(f), pattern,
+							pprintIter(expected), pprintIter(fstUnboundedPl)))
+					require.True(t, expected.Equal(fstPl),
+						fmt.Sprintf("%s:%s - [%v] != [%v]", string(f), pattern,
+							pprintIter(expected), pprintIter(fstPl)))
+				}
+			}
+		})
+	}
+}
+
+func TestPostingsListMatchRange(t *testing.T) {
+	inclusivities := []struct{ lower, upper bool }{
+		{true, true}, {true, false}, {false, true}, {false, false},
+	}
+
+	for _, test := range testDocuments {
+		t.Run(test.name, func(t *testing.T) {
+			memSeg, fstSeg := newTestSegments(t, test.docs)
+			memReader, err := memSeg.Reader()
+			require.NoError(t, err)
+			fstReader, err := fstSeg.Reader()
+			require.NoError(t, err)
+
+			fieldsIter, err := memSeg.Fields()
+			require.NoError(t, err)
+			fields := toSlice(t, fieldsIter)
+			for _, f := range fields {
+				termsIter, err := memSeg.Terms(f)
+				require.NoError(t, err)
+				terms := toSlice(t, termsIter)
+
+				// Ranges bounded on either side, or both sides, by a sample of the terms as
+				// well as ranges between neighbouring terms.
+				var (
+					bounds [][2][]byte
+					step   = len(terms)/10 + 1
+				)
+				for i := 0; i < len(terms); i += step {
+					term := terms[i]
+					bounds = append(bounds,
+						[2][]byte{term, nil},
+						[2][]byte{nil, term},
+						[2][]byte{term, term},
+						[2][]byte{term, terms[(i+1)%len(terms)]},
+					)
+				}
+
+				for _, b := range bounds {
+					for _, incl := range inclusivities {
+						c := index.CompileRange(b[0], b[1], incl.lower, incl.upper)
+						memPl, err := memReader.MatchRange(f, c)
+						require.NoError(t, err)
+						fstPl, err := fstReader.MatchRange(f, c)
+						require.NoError(t, err)
+						require.True(t, memPl.Equal(fstPl),
+							fmt.Sprintf("%s:[%s, %s] - [%v] != [%v]", string(f), b[0], b[1],
+								pprintIter(memPl), pprintIter(fstPl)))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPostingsListMatchNumericRange(t *testing.T) {
+	var docs []doc.Document
+	for i, port := range []string{"80", "443", "8080", "9090", "010", "http", "-1"} {
+		docs = append(docs, doc.Document{
+			ID: []byte(fmt.Sprintf("%d", i)),
+			Fields: []doc.Field{
+				doc.Field{
+					Name:  []byte("port"),
+					Value: []byte(port),
+				},
+			},
+		})
+	}
+
+	tests := []struct {
+		name         string
+		lower, upper []byte
+		expected     []string
+	}{
+		{
+			name:     "both bounds",
+			lower:    []byte("10"),
+			upper:    []byte("443"),
+			expected: []string{"80", "443", "010"},
+		},
+		{
+			name:     "only lower bound",
+			lower:    []byte("443"),
+			expected: []string{"443", "8080", "9090"},
+		},
+		{
+			name:     "only upper bound",
+			upper:    []byte("10"),
+			expected: []string{"010", "-1"},
+		},
+	}
+
+	memSeg, fstSeg := newTestSegments(t, docs)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := index.CompileNumericRange(test.lower, test.upper, true, true)
+			require.NoError(t, err)
+
+			for _, seg := range []sgmt.Segment{memSeg, fstSeg} {
+				r, err := seg.Reader()
+				require.NoError(t, err)
+				pl, err := r.MatchRange([]byte("port"), c)
+				require.NoError(t, err)
+
+				iter, err := r.Docs(pl)
+				require.NoError(t, err)
+				var ports []string
+				for iter.Next() {
+					ports = append(ports, string(iter.Current().Fields[0].Value))
+				}
+				require.NoError(t, iter.Err())
+				require.NoError(t, iter.Close())
+				require.ElementsMatch(t, test.expected, ports)
+				require.NoError(t, r.Close())
+			}
+		})
+	}
+}
+
+func TestPostingsListMatchFold(t *testing.T) {
+	var docs []doc.Document
+	for i, name := range []string{"Foo", "foo", "FOOD", "fo", "bar", "Straße", "STRASSE", "ǅemal"} {
+		docs = append(docs, doc.Document{
+			ID: []byte(fmt.Sprintf("%d", i)),
+			Fields: []doc.Field{
+				doc.Field{
+					Name:  []byte("name"),
+					Value: []byte(name),
+				},
+			},
+		})
+	}
+
+	tests := []struct {
+		name     string
+		term     string
+		prefix   bool
+		folding  index.CaseFolding
+		expected []string
+	}{
+		{
+			name:     "term",
+			term:     "FOO",
+			expected: []string{"Foo", "foo"},
+		},
+		{
+			name:     "prefix",
+			term:     "fOo",
+			prefix:   true,
+			expected: []string{"Foo", "foo", "FOOD"},
+		},
+		{
+			name:     "empty prefix",
+			prefix:   true,
+			expected: []string{"Foo", "foo", "FOOD", "fo", "bar", "Straße", "STRASSE", "ǅemal"},
+		},
+		{
+			name:     "ascii folding leaves other letters as is",
+			term:     "STRAẞE",
+			expected: nil,
+		},
+		{
+			name:     "unicode term",
+			term:     "STRAẞE",
+			folding:  index.UnicodeCaseFolding,
+			expected: []string{"Straße"},
+		},
+		{
+			name:     "unicode prefix",
+			term:     "Ǆ",
+			prefix:   true,
+			folding:  index.UnicodeCaseFolding,
+			expected: []string{"ǅemal"},
+		},
+	}
+
+	memSeg, fstSeg := newTestSegments(t, docs)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := index.CompileFold([]byte(test.term), test.prefix, test.folding)
+
+			for _, seg := range []sgmt.Segment{memSeg, fstSeg} {
+				r, err := seg.Reader()
+				require.NoError(t, err)
+				pl, err := r.MatchFold([]byte("name"), c)
+				require.NoError(t, err)
+
+				iter, err := r.Docs(pl)
+				require.NoError(t, err)
+				var names []string
+				for iter.Next() {
+					names = append(names, string(iter.Current().Fields[0].Value))
+				}
+				require.NoError(t, iter.Err())
+				require.NoError(t, iter.Close())
+				require.ElementsMatch(t, test.expected, names)
+				require.NoError(t, r.Close())
+			}
+		})
+	}
+}
+
+func TestPostingsListMatchFuzzy(t *testing.T) {
+	tests := []struct {
+		name            string
+		term            string
+		maxEditDistance int
+		expected        int
+	}{
+		{name: "exact match", term: "apple", maxEditDistance: 1, expected: 1},
+		{name: "substitution within distance", term: "banane", maxEditDistance: 1, expected: 1},
+		{name: "deletion within distance", term: "pineaple", maxEditDistance: 1, expected: 1},
+		{name: "insertions beyond distance", term: "bananaxx", maxEditDistance: 1, expected: 0},
+		{name: "insertions within distance", term: "bananaxx", maxEditDistance: 2, expected: 1},
+		{name: "multiple terms within distance", term: "apples", maxEditDistance: 2, expected: 1},
+		{name: "no terms within distance", term: "cherry", maxEditDistance: 2, expected: 0},
+	}
+
+	memSeg, fstSeg := newTestSegments(t, fewTestDocuments)
+	memReader, err := memSeg.Reader()
+	require.NoError(t, err)
+	fstReader, err := fstSeg.Reader()
+	require.NoError(t, err)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := index.CompileFuzzy([]byte(test.term), test.maxEditDistance)
+			require.NoError(t, err)
+
+			memPl, err := memReader.MatchFuzzy([]byte("fruit"), c)
+			require.NoError(t, err)
+			fstPl, err := fstReader.MatchFuzzy([]byte("fruit"), c)
+			require.NoError(t, err)
+
+			require.Equal(t, test.expected, memPl.Len())
+			require.True(t, memPl.Equal(fstPl),
+				fmt.Sprintf("[%v] != [%v]", pprintIter(memPl), pprintIter(fstPl)))
+		})
+	}
+}
+
+func TestPostingsListMatchPrefix(t *testing.T) {
+	for _, test := range testDocuments {
+		t.Run(test.name, func(t *testing.T) {
+			memSeg, fstSeg := newTestSegments(t, test.docs)
+			memReader, err := memSeg.Reader()
+			require.NoError(t, err)
+			fstReader, err := fstSeg.Reader()
+			require.NoError(t, err)
+
+			fieldsIter, err := memSeg.Fields()
+			require.NoError(t, err)
+			fields := toSlice(t, fieldsIter)
+			for _, f := range fields {
+				termsIter, err := memSeg.Terms(f)
+				require.NoError(t, err)
+				terms := toSlice(t, termsIter)
+
+				// Use every prefix of each term, plus the empty prefix and a
+				// prefix no term begins with.
+				prefixes := [][]byte{nil, []byte("no-such-prefix")}
+				seen := make(map[string]struct{})
+				for _, term := range terms {
+					for i := 1; i <= len(term); i++ {
+						if _, ok := seen[string(term[:i])]; ok {
+							continue
+						}
+						seen[string(term[:i])] = struct{}{}
+						prefixes = append(prefixes, term[:i])
+					}
+				}
+
+				for _, prefix := range prefixes {
+					memPl, err := memReader.MatchPrefix(f, prefix)
+					require.NoError(t, err)
+					fstPl, err := fstReader.MatchPrefix(f, prefix)
+					require.NoError(t, err)
+					require.True(t, memPl.Equal(fstPl),
+						fmt.Sprintf("%s:%s - [%v] != [%v]", string(f), string(prefix),
+							pprintIter(memPl), pprintIter(fstPl)))
+
+					// Compiling a regexp per prefix is slow, so only cross check
+					// the short prefixes which match the most terms.
+					if len(prefix) > 3 {
+						continue
+					}
+					c, err := index.CompileRegex(append([]byte(regexp.QuoteMeta(string(prefix))), []byte(".*")...))
+					require.NoError(t, err)
+					rePl, err := memReader.MatchRegexp(f, c)
+					require.NoError(t, err)
+					require.True(t, memPl.Equal(rePl))
+				}
+			}
+		})
+	}
+}
+
+func TestMatchTerms(t *testing.T) {
+	all, err := index.CompileRegex([]byte(".*"))
+	require.NoError(t, err)
+
+	for _, test := range testDocuments {
+		t.Run(test.name, func(t *testing.T) {
+			memSeg, fstSeg := newTestSegments(t, test.docs)
+			memReader, err := memSeg.Reader()
+			require.NoError(t, err)
+			fstReader, err := fstSeg.Reader()
+			require.NoError(t, err)
+
+			fieldsIter, err := memSeg.Fields()
+			require.NoError(t, err)
+			for _, f := range toSlice(t, fieldsIter) {
+				for _, limit := range []int{0, 1, 3} {
+					for _, reader := range []index.Reader{memReader, fstReader} {
+						prefixTerms, err := reader.MatchPrefixTerms(f, nil, limit)
+						require.NoError(t, err)
+						regexpTerms, err := reader.MatchRegexpTerms(f, all, limit)
+						require.NoError(t, err)
+						require.Equal(t, prefixTerms, regexpTerms)
+
+						termsIter, err := memSeg.Terms(f)
+						require.NoError(t, err)
+						numTerms := len(toSlice(t, termsIter))
+						if limit > 0 && numTerms > limit {
+							require.Len(t, prefixTerms, limit)
+						} else {
+							require.Len(t, prefixTerms, numTerms)
+						}
+
+						for i, tf := range prefixTerms {
+							pl, err := reader.MatchTerm(f, tf.Term)
+							require.NoError(t, err)
+							require.Equal(t, pl.Len(), tf.Count)
+							if i > 0 {
+								require.True(t, prefixTerms[i-1].Count >= tf.Count)
+							}
+						}
+					}
+
+					memTerms, err := memReader.MatchPrefixTerms(f, nil, limit)
+					require.NoError(t, err)
+					fstTerms, err := fstReader.MatchPrefixTerms(f, nil, limit)
+					require.NoError(t, err)
+					require.Equal(t, memTerms, fstTerms)
+				}
+			}
+		})
+	}
+}
+
+func TestMatchTermsOrderedByFrequency(t *testing.T) {
+	_, fstSeg := newTestSegments(t, fewTestDocuments)
+	reader, err := fstSeg.Reader()
+	require.NoError(t, err)
+
+	terms, err := reader.MatchPrefixTerms([]byte("color"), nil, 0)
+	require.NoError(t, err)
+	require.Equal(t, []index.TermFrequency{
+		{Term: []byte("yellow"), Count: 2},
+		{Term: []byte("red"), Count: 1},
+	}, terms)
+
+	terms, err = reader.MatchPrefixTerms([]byte("color"), nil, 1)
+	require.NoError(t, err)
+	require.Equal(t, []index.TermFrequency{{Term: []byte("yellow"), Count: 2}}, terms)
+
+	re, err := index.CompileRegex([]byte(".*apple"))
+	require.NoError(t, err)
+	terms, err = reader.MatchRegexpTerms([]byte("fruit"), re, 0)
+	require.NoError(t, err)
+	require.Equal(t, []index.TermFrequency{
+		{Term: []byte("apple"), Count: 1},
+		{Term: []byte("pineapple"), Count: 1},
+	}, terms)
+
+	terms, err = reader.MatchPrefixTerms([]byte("no-such-field"), nil, 0)
+	require.NoError(t, err)
+	require.Empty(t, terms)
+}
+
+func TestMatchPrefixTermsAfter(t *testing.T) {
+	memSeg, fstSeg := newTestSegments(t, fewTestDocuments)
+	memReader, err := memSeg.Reader()
+	require.NoError(t, err)
+	fstReader, err := fstSeg.Reader()
+	require.NoError(t, err)
+
+	field := []byte("fruit")
+	tests := []struct {
+		prefix   []byte
+		after    []byte
+		limit    int
+		expected []index.TermFrequency
+	}{
+		{
+			expected: []index.TermFrequency{
+				{Term: []byte("apple"), Count: 1},
+				{Term: []byte("banana"), Count: 1},
+				{Term: []byte("pineapple"), Count: 1},
+			},
+		},
+		{
+			limit: 2,
+			expected: []index.TermFrequency{
+				{Term: []byte("apple"), Count: 1},
+				{Term: []byte("banana"), Count: 1},
+			},
+		},
+		{
+			after: []byte("apple"),
+			limit: 1,
+			expected: []index.TermFrequency{
+				{Term: []byte("banana"), Count: 1},
+			},
+		},
+		{
+			after: []byte("b"),
+			expected: []index.TermFrequency{
+				{Term: []byte("banana"), Count: 1},
+				{Term: []byte("pineapple"), Count: 1},
+			},
+		},
+		{
+			prefix: []byte("p"),
+			after:  []byte("apple"),
+			expected: []index.TermFrequency{
+				{Term: []byte("pineapple"), Count: 1},
+			},
+		},
+		{
+			prefix: []byte("a"),
+			after:  []byte("apple"),
+		},
+		{
+			after: []byte("pineapple"),
+		},
+	}
+
+	for _, test := range tests {
+		for _, reader := range []index.Reader{memReader, fstReader} {
+			terms, err := reader.MatchPrefixTermsAfter(field, test.prefix, test.after, test.limit)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, terms)
+		}
+	}
+}
+
+func TestReaderStatsTermsScanned(t *testing.T) {
+	_, fstSeg := newTestSegments(t, fewTestDocuments)
+	r, err := fstSeg.Reader()
+	require.NoError(t, err)
+	statsReader, ok := r.(index.StatsReader)
+	require.True(t, ok)
+
+	field := []byte("fruit")
+	_, err = r.MatchTerm(field, []byte("apple"))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), statsReader.Stats().TermsScanned)
+
+	// Every term of the field is visited.
+	_, err = r.MatchField(field)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), statsReader.Stats().TermsScanned)
+
+	// Only the terms beginning with the literal prefix of the regexp are visited.
+	c, err := index.CompileRegex([]byte("app.*"))
+	require.NoError(t, err)
+	_, err = r.MatchRegexp(field, c)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), statsReader.Stats().TermsScanned)
+
+	require.NoError(t, r.Close())
+}
+
 func TestSegmentDocs(t *testing.T) {
 	for _, test := range testDocuments {
 		t.Run(test.name, func(t *testing.T) {
@@ -378,6 +1027,32 @@ func TestPostingsListReaderLifecycle(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestSegmentReaderOnSegmentClose(t *testing.T) {
+	_, first := newTestSegments(t, fewTestDocuments)
+	_, second := newTestSegments(t, fewTestDocuments)
+
+	r1, err := first.Reader()
+	require.NoError(t, err)
+	r2, err := second.Reader()
+	require.NoError(t, err)
+
+	ir1 := r1.(index.ImmutableReader)
+	ir2 := r2.(index.ImmutableReader)
+	require.NotEqual(t, ir1.SegmentID(), ir2.SegmentID())
+
+	var closed int
+	ir1.OnSegmentClose(func() { closed++ })
+	require.NoError(t, r1.Close())
+	require.Equal(t, 0, closed)
+
+	require.NoError(t, first.Close())
+	require.Equal(t, 1, closed)
+
+	// Callbacks registered after the segment is closed run immediately.
+	ir1.OnSegmentClose(func() { closed++ })
+	require.Equal(t, 2, closed)
+}
+
 func newTestSegments(t *testing.T, docs []doc.Document) (memSeg sgmt.MutableSegment, fstSeg sgmt.Segment) {
 	s := newTestMemSegment(t)
 	for _, d := range docs {
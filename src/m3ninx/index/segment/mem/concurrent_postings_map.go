@@ -21,9 +21,12 @@
 package mem
 
 import (
+	"bytes"
 	"regexp"
+	"sort"
 	"sync"
 
+	"github.com/m3db/m3/src/m3ninx/index"
 	"github.com/m3db/m3/src/m3ninx/postings"
 )
 
@@ -88,6 +91,14 @@ func (m *concurrentPostingsMap) Keys() *bytesSliceIter {
 	return newBytesSliceIter(keys, m.opts)
 }
 
+// Len returns the number of keys in the map.
+func (m *concurrentPostingsMap) Len() int {
+	m.RLock()
+	n := m.postingsMap.Len()
+	m.RUnlock()
+	return n
+}
+
 // Get returns the postings.List backing `key`.
 func (m *concurrentPostingsMap) Get(key []byte) (postings.List, bool) {
 	m.RLock()
@@ -102,14 +113,124 @@ func (m *concurrentPostingsMap) Get(key []byte) (postings.List, bool) {
 // GetRegex returns the union of the postings lists whose keys match the
 // provided regexp.
 func (m *concurrentPostingsMap) GetRegex(re *regexp.Regexp) (postings.List, bool) {
-	var pl postings.MutableList
+	return m.getMatching(re.Match)
+}
+
+// GetCompiledRegex returns the union of the postings lists whose keys match the
+// compiled regexp. It returns index.ErrTooManyTermsMatched if more than the compiled
+// MaxTermsMatched keys match, if positive.
+func (m *concurrentPostingsMap) GetCompiledRegex(
+	compiled index.CompiledRegex,
+) (postings.List, bool, error) {
+	return m.getMatchingWithLimit(compiled.Match, compiled.MaxTermsMatched)
+}
+
+// GetFuzzy returns the union of the postings lists whose keys are within the
+// maximum edit distance of the compiled term.
+func (m *concurrentPostingsMap) GetFuzzy(compiled index.CompiledFuzzy) (postings.List, bool) {
+	return m.getMatching(compiled.Match)
+}
+
+// GetPrefix returns the union of the postings lists whose keys begin with the prefix.
+func (m *concurrentPostingsMap) GetPrefix(prefix []byte) (postings.List, bool) {
+	return m.getMatching(func(key []byte) bool {
+		return bytes.HasPrefix(key, prefix)
+	})
+}
+
+// GetRange returns the union of the postings lists whose keys are within the
+// compiled range.
+func (m *concurrentPostingsMap) GetRange(compiled index.CompiledRange) (postings.List, bool) {
+	return m.getMatching(compiled.Match)
+}
+
+// GetFold returns the union of the postings lists whose keys are equal to, or begin
+// with, the compiled term under case folding.
+func (m *concurrentPostingsMap) GetFold(compiled index.CompiledFold) (postings.List, bool) {
+	return m.getMatching(compiled.Match)
+}
+
+// GetAll returns the union of all the postings lists in the map.
+func (m *concurrentPostingsMap) GetAll() (postings.List, bool) {
+	return m.getMatching(func([]byte) bool {
+		return true
+	})
+}
+
+// GetMatchingTerms returns at most limit of the keys accepted by match along with the
+// lengths of their postings lists, ordered by descending length. A non-positive limit
+// returns every key accepted.
+func (m *concurrentPostingsMap) GetMatchingTerms(
+	match func(key []byte) bool,
+	limit int,
+) []index.TermFrequency {
+	top := index.NewTopTerms(limit)
+	m.RLock()
+	for _, mapEntry := range m.postingsMap.Iter() {
+		if match(mapEntry.Key()) {
+			top.Add(mapEntry.Key(), mapEntry.Value().Len())
+		}
+	}
+	m.RUnlock()
+	return top.Terms()
+}
+
+// GetOrderedTerms returns at most limit of the lowest keys accepted by match along with
+// the lengths of their postings lists, in ascending order. A non-positive limit returns
+// every key accepted.
+func (m *concurrentPostingsMap) GetOrderedTerms(
+	match func(key []byte) bool,
+	limit int,
+) []index.TermFrequency {
+	var terms []index.TermFrequency
+	m.RLock()
+	for _, mapEntry := range m.postingsMap.Iter() {
+		if match(mapEntry.Key()) {
+			terms = append(terms, index.TermFrequency{
+				Term:  append([]byte(nil), mapEntry.Key()...),
+				Count: mapEntry.Value().Len(),
+			})
+		}
+	}
+	m.RUnlock()
+
+	sort.Slice(terms, func(i, j int) bool {
+		return bytes.Compare(terms[i].Term, terms[j].Term) < 0
+	})
+	if limit > 0 && len(terms) > limit {
+		terms = terms[:limit]
+	}
+	return terms
+}
+
+func (m *concurrentPostingsMap) getMatching(
+	match func(key []byte) bool,
+) (postings.List, bool) {
+	// NB: matching can only fail when there is a limit on the number of keys matched.
+	pl, ok, _ := m.getMatchingWithLimit(match, 0)
+	return pl, ok
+}
+
+func (m *concurrentPostingsMap) getMatchingWithLimit(
+	match func(key []byte) bool,
+	maxMatched int,
+) (postings.List, bool, error) {
+	var (
+		pl      postings.MutableList
+		matched int
+	)
 
 	m.RLock()
 	for _, mapEntry := range m.postingsMap.Iter() {
 		// TODO: Evaluate lock contention caused by holding on to the read lock while
 		// evaluating this predicate.
 		// TODO: Evaluate if performing a prefix match would speed up the common case.
-		if re.Match(mapEntry.Key()) {
+		if match(mapEntry.Key()) {
+			matched++
+			if maxMatched > 0 && matched > maxMatched {
+				m.RUnlock()
+				return nil, false, index.ErrTooManyTermsMatched
+			}
 			if pl == nil {
 				pl = mapEntry.Value().Clone()
 			} else {
@@ -120,7 +241,7 @@ func (m *concurrentPostingsMap) GetRegex(re *regexp.Regexp) (postings.List, bool
 	m.RUnlock()
 
 	if pl == nil {
-		return nil, false
+		return nil, false, nil
 	}
-	return pl, true
+	return pl, true, nil
 }
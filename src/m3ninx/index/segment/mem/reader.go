@@ -23,6 +23,7 @@ package mem
 import (
 	"errors"
 	"sync"
+	"sync/atomic"
 
 	"github.com/m3db/m3/src/m3ninx/doc"
 	"github.com/m3db/m3/src/m3ninx/index"
@@ -41,9 +42,12 @@ type reader struct {
 	limits  readerDocRange
 	plPool  postings.Pool
 
-	closed bool
+	termsScanned int64
+	closed       bool
 }
 
+var _ index.StatsReader = &reader{}
+
 type readerDocRange struct {
 	startInclusive postings.ID
 	endExclusive   postings.ID
@@ -69,6 +73,7 @@ func (r *reader) MatchTerm(field, term []byte) (postings.List, error) {
 	// postings list through a call to Docs, IDs greater than or equal to the limit
 	// will be filtered out.
 	pl, err := r.segment.matchTerm(field, term)
+	atomic.AddInt64(&r.termsScanned, 1)
 	return pl, err
 }
 
@@ -83,12 +88,152 @@ func (r *reader) MatchRegexp(field []byte, compiled index.CompiledRegex) (postin
 	// permitted ID. The reader only guarantees that when fetching the documents associated
 	// with a postings list through a call to Docs will IDs greater than the maximum be
 	// filtered out.
-	compileRE := compiled.Simple
-	if compileRE == nil {
+	if compiled.Simple == nil {
 		return nil, errReaderNilRegex
 	}
 
-	return r.segment.matchRegexp(field, compileRE)
+	pl, err := r.segment.matchRegexp(field, compiled)
+	r.addFieldTermsScanned(field)
+	return pl, err
+}
+
+func (r *reader) MatchFuzzy(field []byte, compiled index.CompiledFuzzy) (postings.List, error) {
+	r.RLock()
+	defer r.RUnlock()
+	if r.closed {
+		return nil, errSegmentReaderClosed
+	}
+
+	// A reader can return IDs in the posting list which are greater than its maximum
+	// permitted ID. The reader only guarantees that when fetching the documents associated
+	// with a postings list through a call to Docs will IDs greater than the maximum be
+	// filtered out.
+	pl, err := r.segment.matchFuzzy(field, compiled)
+	r.addFieldTermsScanned(field)
+	return pl, err
+}
+
+func (r *reader) MatchPrefix(field, prefix []byte) (postings.List, error) {
+	r.RLock()
+	defer r.RUnlock()
+	if r.closed {
+		return nil, errSegmentReaderClosed
+	}
+
+	// A reader can return IDs in the posting list which are greater than its maximum
+	// permitted ID. The reader only guarantees that when fetching the documents associated
+	// with a postings list through a call to Docs will IDs greater than the maximum be
+	// filtered out.
+	pl, err := r.segment.matchPrefix(field, prefix)
+	r.addFieldTermsScanned(field)
+	return pl, err
+}
+
+func (r *reader) MatchField(field []byte) (postings.List, error) {
+	r.RLock()
+	defer r.RUnlock()
+	if r.closed {
+		return nil, errSegmentReaderClosed
+	}
+
+	// A reader can return IDs in the posting list which are greater than its maximum
+	// permitted ID. The reader only guarantees that when fetching the documents associated
+	// with a postings list through a call to Docs will IDs greater than the maximum be
+	// filtered out.
+	pl, err := r.segment.matchField(field)
+	r.addFieldTermsScanned(field)
+	return pl, err
+}
+
+func (r *reader) MatchRange(field []byte, compiled index.CompiledRange) (postings.List, error) {
+	r.RLock()
+	defer r.RUnlock()
+	if r.closed {
+		return nil, errSegmentReaderClosed
+	}
+
+	// A reader can return IDs in the posting list which are greater than its maximum
+	// permitted ID. The reader only guarantees that when fetching the documents associated
+	// with a postings list through a call to Docs will IDs greater than the maximum be
+	// filtered out.
+	pl, err := r.segment.matchRange(field, compiled)
+	r.addFieldTermsScanned(field)
+	return pl, err
+}
+
+func (r *reader) MatchFold(field []byte, compiled index.CompiledFold) (postings.List, error) {
+	r.RLock()
+	defer r.RUnlock()
+	if r.closed {
+		return nil, errSegmentReaderClosed
+	}
+
+	// A reader can return IDs in the posting list which are greater than its maximum
+	// permitted ID. The reader only guarantees that when fetching the documents associated
+	// with a postings list through a call to Docs will IDs greater than the maximum be
+	// filtered out.
+	pl, err := r.segment.matchFold(field, compiled)
+	r.addFieldTermsScanned(field)
+	return pl, err
+}
+
+func (r *reader) MatchRegexpTerms(
+	field []byte,
+	compiled index.CompiledRegex,
+	limit int,
+) ([]index.TermFrequency, error) {
+	r.RLock()
+	defer r.RUnlock()
+	if r.closed {
+		return nil, errSegmentReaderClosed
+	}
+
+	// NB: the document frequencies can include documents inserted after the reader was
+	// created, in the same way as the postings lists returned by the reader.
+	if compiled.Simple == nil {
+		return nil, errReaderNilRegex
+	}
+
+	terms, err := r.segment.matchRegexpTerms(field, compiled, limit)
+	r.addFieldTermsScanned(field)
+	return terms, err
+}
+
+func (r *reader) MatchPrefixTerms(field, prefix []byte, limit int) ([]index.TermFrequency, error) {
+	r.RLock()
+	defer r.RUnlock()
+	if r.closed {
+		return nil, errSegmentReaderClosed
+	}
+
+	// NB: the document frequencies can include documents inserted after the reader was
+	// created, in the same way as the postings lists returned by the reader.
+	terms, err := r.segment.matchPrefixTerms(field, prefix, limit)
+	r.addFieldTermsScanned(field)
+	return terms, err
+}
+
+func (r *reader) MatchPrefixTermsAfter(
+	field, prefix, after []byte,
+	limit int,
+) ([]index.TermFrequency, error) {
+	r.RLock()
+	defer r.RUnlock()
+	if r.closed {
+		return nil, errSegmentReaderClosed
+	}
+
+	// NB: the document frequencies can include documents inserted after the reader was
+	// created, in the same way as the postings lists returned by the reader.
+	terms, err := r.segment.matchPrefixTermsAfter(field, prefix, after, limit)
+	r.addFieldTermsScanned(field)
+	return terms, err
+}
+
+// addFieldTermsScanned records the scan of every term of the given field, which all
+// matches besides those of a single term require.
+func (r *reader) addFieldTermsScanned(field []byte) {
+	atomic.AddInt64(&r.termsScanned, int64(r.segment.termsCount(field)))
 }
 
 func (r *reader) MatchAll() (postings.MutableList, error) {
@@ -142,6 +287,12 @@ func (r *reader) getDocIterWithLock(iter postings.Iterator) index.IDDocIterator
 	return index.NewIDDocIterator(r, iter)
 }
 
+func (r *reader) Stats() index.ReaderStats {
+	return index.ReaderStats{
+		TermsScanned: atomic.LoadInt64(&r.termsScanned),
+	}
+}
+
 func (r *reader) Close() error {
 	r.Lock()
 	if r.closed {
@@ -55,6 +55,7 @@ func TestReaderMatchExact(t *testing.T) {
 	actual, err := reader.MatchTerm(name, value)
 	require.NoError(t, err)
 	require.True(t, postingsList.Equal(actual))
+	require.Equal(t, int64(1), reader.(index.StatsReader).Stats().TermsScanned)
 
 	require.NoError(t, reader.Close())
 }
@@ -74,13 +75,15 @@ func TestReaderMatchRegex(t *testing.T) {
 
 	segment := NewMockReadableSegment(mockCtrl)
 	gomock.InOrder(
-		segment.EXPECT().matchRegexp(name, compiled).Return(postingsList, nil),
+		segment.EXPECT().matchRegexp(name, index.CompiledRegex{Simple: compiled}).Return(postingsList, nil),
+		segment.EXPECT().termsCount(name).Return(7),
 	)
 
 	reader := newReader(segment, readerDocRange{0, maxID}, postings.NewPool(nil, roaring.NewPostingsList))
 	actual, err := reader.MatchRegexp(name, index.CompiledRegex{Simple: compiled})
 	require.NoError(t, err)
 	require.True(t, postingsList.Equal(actual))
+	require.Equal(t, int64(7), reader.(index.StatsReader).Stats().TermsScanned)
 
 	require.NoError(t, reader.Close())
 }
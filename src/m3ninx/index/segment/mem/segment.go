@@ -22,7 +22,6 @@ package mem
 
 import (
 	"errors"
-	re "regexp"
 	"sync"
 
 	"github.com/m3db/m3/src/m3ninx/doc"
@@ -323,14 +322,114 @@ func (s *segment) matchTerm(field, term []byte) (postings.List, error) {
 	return s.termsDict.MatchTerm(field, term), nil
 }
 
-func (s *segment) matchRegexp(field []byte, compiled *re.Regexp) (postings.List, error) {
+func (s *segment) matchRegexp(
+	field []byte,
+	compiled index.CompiledRegex,
+) (postings.List, error) {
 	s.state.RLock()
 	defer s.state.RUnlock()
 	if s.state.closed {
 		return nil, sgmt.ErrClosed
 	}
 
-	return s.termsDict.MatchRegexp(field, compiled), nil
+	return s.termsDict.MatchRegexp(field, compiled)
+}
+
+func (s *segment) matchFuzzy(field []byte, compiled index.CompiledFuzzy) (postings.List, error) {
+	s.state.RLock()
+	defer s.state.RUnlock()
+	if s.state.closed {
+		return nil, sgmt.ErrClosed
+	}
+
+	return s.termsDict.MatchFuzzy(field, compiled), nil
+}
+
+func (s *segment) matchPrefix(field, prefix []byte) (postings.List, error) {
+	s.state.RLock()
+	defer s.state.RUnlock()
+	if s.state.closed {
+		return nil, sgmt.ErrClosed
+	}
+
+	return s.termsDict.MatchPrefix(field, prefix), nil
+}
+
+func (s *segment) matchRegexpTerms(
+	field []byte,
+	compiled index.CompiledRegex,
+	limit int,
+) ([]index.TermFrequency, error) {
+	s.state.RLock()
+	defer s.state.RUnlock()
+	if s.state.closed {
+		return nil, sgmt.ErrClosed
+	}
+
+	return s.termsDict.MatchRegexpTerms(field, compiled, limit), nil
+}
+
+func (s *segment) matchPrefixTerms(field, prefix []byte, limit int) ([]index.TermFrequency, error) {
+	s.state.RLock()
+	defer s.state.RUnlock()
+	if s.state.closed {
+		return nil, sgmt.ErrClosed
+	}
+
+	return s.termsDict.MatchPrefixTerms(field, prefix, limit), nil
+}
+
+func (s *segment) matchPrefixTermsAfter(
+	field, prefix, after []byte,
+	limit int,
+) ([]index.TermFrequency, error) {
+	s.state.RLock()
+	defer s.state.RUnlock()
+	if s.state.closed {
+		return nil, sgmt.ErrClosed
+	}
+
+	return s.termsDict.MatchPrefixTermsAfter(field, prefix, after, limit), nil
+}
+
+func (s *segment) matchField(field []byte) (postings.List, error) {
+	s.state.RLock()
+	defer s.state.RUnlock()
+	if s.state.closed {
+		return nil, sgmt.ErrClosed
+	}
+
+	return s.termsDict.MatchField(field), nil
+}
+
+func (s *segment) matchRange(field []byte, compiled index.CompiledRange) (postings.List, error) {
+	s.state.RLock()
+	defer s.state.RUnlock()
+	if s.state.closed {
+		return nil, sgmt.ErrClosed
+	}
+
+	return s.termsDict.MatchRange(field, compiled), nil
+}
+
+func (s *segment) matchFold(field []byte, compiled index.CompiledFold) (postings.List, error) {
+	s.state.RLock()
+	defer s.state.RUnlock()
+	if s.state.closed {
+		return nil, sgmt.ErrClosed
+	}
+
+	return s.termsDict.MatchFold(field, compiled), nil
+}
+
+func (s *segment) termsCount(field []byte) int {
+	s.state.RLock()
+	defer s.state.RUnlock()
+	if s.state.closed {
+		return 0
+	}
+
+	return s.termsDict.TermsCount(field)
 }
 
 func (s *segment) getDoc(id postings.ID) (doc.Document, error) {
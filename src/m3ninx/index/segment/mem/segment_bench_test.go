@@ -21,17 +21,17 @@
 package mem
 
 import (
-	"regexp"
 	"testing"
 
 	"github.com/m3db/m3/src/m3ninx/doc"
+	"github.com/m3db/m3/src/m3ninx/index"
 	"github.com/m3db/m3/src/m3ninx/util"
 )
 
 var (
-	benchSegmentField    = []byte("__name__")
-	benchSegmentRegexp   = []byte("node_netstat_Tcp_.*")
-	benchSegmentCompiled = regexp.MustCompile(string(benchSegmentRegexp))
+	benchSegmentField       = []byte("__name__")
+	benchSegmentRegexp      = []byte("node_netstat_Tcp_.*")
+	benchSegmentCompiled, _ = index.CompileRegex(benchSegmentRegexp)
 )
 
 func BenchmarkSegment(b *testing.B) {
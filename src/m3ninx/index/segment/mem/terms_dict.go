@@ -21,10 +21,11 @@
 package mem
 
 import (
-	re "regexp"
+	"bytes"
 	"sync"
 
 	"github.com/m3db/m3/src/m3ninx/doc"
+	"github.com/m3db/m3/src/m3ninx/index"
 	sgmt "github.com/m3db/m3/src/m3ninx/index/segment"
 	"github.com/m3db/m3/src/m3ninx/postings"
 )
@@ -65,6 +66,16 @@ func (d *termsDict) MatchTerm(field, term []byte) postings.List {
 	return pl
 }
 
+func (d *termsDict) TermsCount(field []byte) int {
+	d.fields.RLock()
+	postingsMap, ok := d.fields.Get(field)
+	d.fields.RUnlock()
+	if !ok {
+		return 0
+	}
+	return postingsMap.Len()
+}
+
 func (d *termsDict) Fields() sgmt.FieldsIterator {
 	d.fields.RLock()
 	defer d.fields.RUnlock()
@@ -101,7 +112,61 @@ func (d *termsDict) matchTerm(field, term []byte) (postings.List, bool) {
 
 func (d *termsDict) MatchRegexp(
 	field []byte,
-	compiled *re.Regexp,
+	compiled index.CompiledRegex,
+) (postings.List, error) {
+	d.fields.RLock()
+	postingsMap, ok := d.fields.Get(field)
+	d.fields.RUnlock()
+	if !ok {
+		return d.opts.PostingsListPool().Get(), nil
+	}
+	pl, ok, err := postingsMap.GetCompiledRegex(compiled)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return d.opts.PostingsListPool().Get(), nil
+	}
+	return pl, nil
+}
+
+func (d *termsDict) MatchFuzzy(
+	field []byte,
+	compiled index.CompiledFuzzy,
+) postings.List {
+	d.fields.RLock()
+	postingsMap, ok := d.fields.Get(field)
+	d.fields.RUnlock()
+	if !ok {
+		return d.opts.PostingsListPool().Get()
+	}
+	pl, ok := postingsMap.GetFuzzy(compiled)
+	if !ok {
+		return d.opts.PostingsListPool().Get()
+	}
+	return pl
+}
+
+func (d *termsDict) MatchRange(
+	field []byte,
+	compiled index.CompiledRange,
+) postings.List {
+	d.fields.RLock()
+	postingsMap, ok := d.fields.Get(field)
+	d.fields.RUnlock()
+	if !ok {
+		return d.opts.PostingsListPool().Get()
+	}
+	pl, ok := postingsMap.GetRange(compiled)
+	if !ok {
+		return d.opts.PostingsListPool().Get()
+	}
+	return pl
+}
+
+func (d *termsDict) MatchFold(
+	field []byte,
+	compiled index.CompiledFold,
 ) postings.List {
 	d.fields.RLock()
 	postingsMap, ok := d.fields.Get(field)
@@ -109,13 +174,83 @@ func (d *termsDict) MatchRegexp(
 	if !ok {
 		return d.opts.PostingsListPool().Get()
 	}
-	pl, ok := postingsMap.GetRegex(compiled)
+	pl, ok := postingsMap.GetFold(compiled)
 	if !ok {
 		return d.opts.PostingsListPool().Get()
 	}
 	return pl
 }
 
+func (d *termsDict) MatchField(field []byte) postings.List {
+	d.fields.RLock()
+	postingsMap, ok := d.fields.Get(field)
+	d.fields.RUnlock()
+	if !ok {
+		return d.opts.PostingsListPool().Get()
+	}
+	pl, ok := postingsMap.GetAll()
+	if !ok {
+		return d.opts.PostingsListPool().Get()
+	}
+	return pl
+}
+
+func (d *termsDict) MatchPrefix(field, prefix []byte) postings.List {
+	d.fields.RLock()
+	postingsMap, ok := d.fields.Get(field)
+	d.fields.RUnlock()
+	if !ok {
+		return d.opts.PostingsListPool().Get()
+	}
+	pl, ok := postingsMap.GetPrefix(prefix)
+	if !ok {
+		return d.opts.PostingsListPool().Get()
+	}
+	return pl
+}
+
+func (d *termsDict) MatchRegexpTerms(
+	field []byte,
+	compiled index.CompiledRegex,
+	limit int,
+) []index.TermFrequency {
+	d.fields.RLock()
+	postingsMap, ok := d.fields.Get(field)
+	d.fields.RUnlock()
+	if !ok {
+		return nil
+	}
+	return postingsMap.GetMatchingTerms(compiled.Match, limit)
+}
+
+func (d *termsDict) MatchPrefixTerms(field, prefix []byte, limit int) []index.TermFrequency {
+	d.fields.RLock()
+	postingsMap, ok := d.fields.Get(field)
+	d.fields.RUnlock()
+	if !ok {
+		return nil
+	}
+	return postingsMap.GetMatchingTerms(func(term []byte) bool {
+		return bytes.HasPrefix(term, prefix)
+	}, limit)
+}
+
+func (d *termsDict) MatchPrefixTermsAfter(
+	field, prefix, after []byte,
+	limit int,
+) []index.TermFrequency {
+	d.fields.RLock()
+	postingsMap, ok := d.fields.Get(field)
+	d.fields.RUnlock()
+	if !ok {
+		return nil
+	}
+	return postingsMap.GetOrderedTerms(func(term []byte) bool {
+		return bytes.HasPrefix(term, prefix) &&
+			(after == nil || bytes.Compare(term, after) > 0)
+	}, limit)
+}
+
 func (d *termsDict) getOrAddName(name []byte) *concurrentPostingsMap {
 	// Cheap read lock to see if it already exists.
 	d.fields.RLock()
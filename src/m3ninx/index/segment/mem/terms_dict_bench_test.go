@@ -21,18 +21,18 @@
 package mem
 
 import (
-	"regexp"
 	"testing"
 
 	"github.com/m3db/m3/src/m3ninx/doc"
+	"github.com/m3db/m3/src/m3ninx/index"
 	"github.com/m3db/m3/src/m3ninx/postings"
 	"github.com/m3db/m3/src/m3ninx/util"
 )
 
 var (
-	benchTermsDictField    = []byte("__name__")
-	benchTermsDictRegexp   = []byte("node_netstat_Tcp_.*")
-	benchTermsDictCompiled = regexp.MustCompile(string(benchTermsDictRegexp))
+	benchTermsDictField       = []byte("__name__")
+	benchTermsDictRegexp      = []byte("node_netstat_Tcp_.*")
+	benchTermsDictCompiled, _ = index.CompileRegex(benchTermsDictRegexp)
 )
 
 func BenchmarkTermsDict(b *testing.B) {
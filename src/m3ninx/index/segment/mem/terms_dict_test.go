@@ -27,6 +27,7 @@ import (
 	"testing"
 
 	"github.com/m3db/m3/src/m3ninx/doc"
+	"github.com/m3db/m3/src/m3ninx/index"
 	"github.com/m3db/m3/src/m3ninx/postings"
 
 	"github.com/leanovate/gopter"
@@ -236,7 +237,10 @@ func (t *termsDictionaryTestSuite) TestMatchRegex() {
 
 				t.termsDict.Insert(f, id)
 
-				pl := t.termsDict.MatchRegexp(f.Name, compiled)
+				pl, err := t.termsDict.MatchRegexp(f.Name, index.CompiledRegex{Simple: compiled})
+				if err != nil {
+					return false, err
+				}
 				if pl == nil {
 					return false, fmt.Errorf("postings list of documents matching query should not be nil")
 				}
@@ -263,7 +267,10 @@ func (t *termsDictionaryTestSuite) TestMatchRegexNoResults() {
 					f        = input.field
 					compiled = input.compiled
 				)
-				pl := t.termsDict.MatchRegexp(f.Name, compiled)
+				pl, err := t.termsDict.MatchRegexp(f.Name, index.CompiledRegex{Simple: compiled})
+				if err != nil {
+					return false, err
+				}
 				if pl == nil {
 					return false, fmt.Errorf("postings list returned should not be nil")
 				}
@@ -280,6 +287,24 @@ func (t *termsDictionaryTestSuite) TestMatchRegexNoResults() {
 	props.TestingRun(t.T())
 }
 
+func (t *termsDictionaryTestSuite) TestMatchRegexMaxTermsMatched() {
+	field := []byte("fruit")
+	for i, term := range []string{"apple", "apricot", "banana"} {
+		t.termsDict.Insert(doc.Field{Name: field, Value: []byte(term)}, postings.ID(i))
+	}
+	compiled, err := index.CompileRegex([]byte("ap.*"))
+	t.Require().NoError(err)
+
+	compiled.MaxTermsMatched = 2
+	pl, err := t.termsDict.MatchRegexp(field, compiled)
+	t.Require().NoError(err)
+	t.Require().Equal(2, pl.Len())
+
+	compiled.MaxTermsMatched = 1
+	_, err = t.termsDict.MatchRegexp(field, compiled)
+	t.Require().Equal(index.ErrTooManyTermsMatched, err)
+}
+
 func TestTermsDictionary(t *testing.T) {
 	opts := NewOptions()
 	suite.Run(t, &termsDictionaryTestSuite{
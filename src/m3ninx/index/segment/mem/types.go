@@ -21,9 +21,8 @@
 package mem
 
 import (
-	re "regexp"
-
 	"github.com/m3db/m3/src/m3ninx/doc"
+	"github.com/m3db/m3/src/m3ninx/index"
 	sgmt "github.com/m3db/m3/src/m3ninx/index/segment"
 	"github.com/m3db/m3/src/m3ninx/postings"
 )
@@ -42,8 +41,45 @@ type termsDictionary interface {
 	MatchTerm(field, term []byte) postings.List
 
 	// MatchRegexp returns the postings list corresponding to documents which match the
-	// given egular expression.
-	MatchRegexp(field []byte, compiled *re.Regexp) postings.List
+	// given egular expression. It returns index.ErrTooManyTermsMatched if the regular
+	// expression matches more than the compiled MaxTermsMatched terms, if positive.
+	MatchRegexp(field []byte, compiled index.CompiledRegex) (postings.List, error)
+
+	// MatchFuzzy returns the postings list corresponding to documents which have a
+	// term within the compiled maximum edit distance of the compiled term.
+	MatchFuzzy(field []byte, compiled index.CompiledFuzzy) postings.List
+
+	// MatchPrefix returns the postings list corresponding to documents which have a
+	// term beginning with the given prefix.
+	MatchPrefix(field, prefix []byte) postings.List
+
+	// MatchField returns the postings list corresponding to documents which have any
+	// term for the given field.
+	MatchField(field []byte) postings.List
+
+	// MatchRange returns the postings list corresponding to documents which have a
+	// term within the compiled range.
+	MatchRange(field []byte, compiled index.CompiledRange) postings.List
+
+	// MatchFold returns the postings list corresponding to documents which have a
+	// term equal to, or beginning with, the compiled term under case folding.
+	MatchFold(field []byte, compiled index.CompiledFold) postings.List
+
+	// MatchRegexpTerms returns at most limit of the terms which match the given regular
+	// expression along with their document frequencies, ordered by descending frequency.
+	MatchRegexpTerms(field []byte, compiled index.CompiledRegex, limit int) []index.TermFrequency
+
+	// MatchPrefixTerms returns at most limit of the terms beginning with the given prefix
+	// along with their document frequencies, ordered by descending frequency.
+	MatchPrefixTerms(field, prefix []byte, limit int) []index.TermFrequency
+
+	// MatchPrefixTermsAfter returns at most limit of the terms beginning with the given
+	// prefix which sort after the given term along with their document frequencies, in
+	// ascending order.
+	MatchPrefixTermsAfter(field, prefix, after []byte, limit int) []index.TermFrequency
+
+	// TermsCount returns the number of known terms for the given field.
+	TermsCount(field []byte) int
 
 	// Fields returns the known fields.
 	Fields() sgmt.FieldsIterator
@@ -62,8 +98,40 @@ type ReadableSegment interface {
 	// matchTerm returns the postings list of documents which match the given term exactly.
 	matchTerm(field, term []byte) (postings.List, error)
 
-	// matchRegexp returns the postings list of documents which match the given regular expression.
-	matchRegexp(field []byte, compiled *re.Regexp) (postings.List, error)
+	// matchRegexp returns the postings list of documents which match the given regular
+	// expression, matching at most the compiled MaxTermsMatched terms if positive.
+	matchRegexp(field []byte, compiled index.CompiledRegex) (postings.List, error)
+
+	// matchFuzzy returns the postings list of documents which have a term within the
+	// compiled maximum edit distance of the compiled term.
+	matchFuzzy(field []byte, compiled index.CompiledFuzzy) (postings.List, error)
+
+	// matchPrefix returns the postings list of documents which have a term beginning
+	// with the given prefix.
+	matchPrefix(field, prefix []byte) (postings.List, error)
+
+	// matchField returns the postings list of documents which have any term for the
+	// given field.
+	matchField(field []byte) (postings.List, error)
+
+	// matchRange returns the postings list of documents which have a term within the
+	// compiled range.
+	matchRange(field []byte, compiled index.CompiledRange) (postings.List, error)
+
+	// matchFold returns the postings list of documents which have a term equal to, or
+	// beginning with, the compiled term under case folding.
+	matchFold(field []byte, compiled index.CompiledFold) (postings.List, error)
+
+	// matchRegexpTerms returns at most limit of the terms which match the given regular
+	// expression along with their document frequencies, ordered by descending frequency.
+	matchRegexpTerms(field []byte, compiled index.CompiledRegex, limit int) ([]index.TermFrequency, error)
+
+	// matchPrefixTerms returns at most limit of the terms beginning with the given prefix
+	// along with their document frequencies, ordered by descending frequency.
+	matchPrefixTerms(field, prefix []byte, limit int) ([]index.TermFrequency, error)
+
+	// termsCount returns the number of terms for the given field.
+	termsCount(field []byte) int
 
 	// getDoc returns the document associated with the given ID.
 	getDoc(id postings.ID) (doc.Document, error)
@@ -0,0 +1,108 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package index
+
+import (
+	"bytes"
+	"container/heap"
+	"sort"
+)
+
+// TermFrequency is a term along with the number of documents which contain it.
+type TermFrequency struct {
+	Term  []byte
+	Count int
+}
+
+// TopTerms collects the terms with the highest document frequencies from those added
+// to it, retaining only as many terms as its limit.
+type TopTerms struct {
+	limit int
+	terms termFrequencyHeap
+}
+
+// NewTopTerms returns a new TopTerms which retains at most limit terms. A non-positive
+// limit retains every term added.
+func NewTopTerms(limit int) *TopTerms {
+	return &TopTerms{limit: limit}
+}
+
+// Add adds a term with the given document frequency. The term is copied if it's
+// retained so callers are free to reuse it.
+func (t *TopTerms) Add(term []byte, count int) {
+	if t.limit <= 0 || len(t.terms) < t.limit {
+		heap.Push(&t.terms, TermFrequency{Term: append([]byte(nil), term...), Count: count})
+		return
+	}
+
+	// NB: the root of the heap is the lowest ranked term retained, so the term is only
+	// retained if it ranks higher than it.
+	root := &t.terms[0]
+	if !rankedBefore(TermFrequency{Term: term, Count: count}, *root) {
+		return
+	}
+	root.Term = append(root.Term[:0], term...)
+	root.Count = count
+	heap.Fix(&t.terms, 0)
+}
+
+// Len returns the number of terms retained.
+func (t *TopTerms) Len() int {
+	return len(t.terms)
+}
+
+// Terms returns the terms retained, ordered by descending document frequency and then
+// by term for terms with the same frequency.
+func (t *TopTerms) Terms() []TermFrequency {
+	terms := make([]TermFrequency, len(t.terms))
+	copy(terms, t.terms)
+	sort.Slice(terms, func(i, j int) bool {
+		return rankedBefore(terms[i], terms[j])
+	})
+	return terms
+}
+
+// rankedBefore reports whether a is ranked before b.
+func rankedBefore(a, b TermFrequency) bool {
+	if a.Count != b.Count {
+		return a.Count > b.Count
+	}
+	return bytes.Compare(a.Term, b.Term) < 0
+}
+
+// termFrequencyHeap is a heap with the lowest ranked term at its root.
+type termFrequencyHeap []TermFrequency
+
+func (h termFrequencyHeap) Len() int           { return len(h) }
+func (h termFrequencyHeap) Less(i, j int) bool { return rankedBefore(h[j], h[i]) }
+func (h termFrequencyHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *termFrequencyHeap) Push(x interface{}) {
+	*h = append(*h, x.(TermFrequency))
+}
+
+func (h *termFrequencyHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
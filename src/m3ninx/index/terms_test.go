@@ -0,0 +1,91 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopTerms(t *testing.T) {
+	input := []TermFrequency{
+		{Term: []byte("banana"), Count: 3},
+		{Term: []byte("apple"), Count: 5},
+		{Term: []byte("cherry"), Count: 1},
+		{Term: []byte("date"), Count: 5},
+		{Term: []byte("elderberry"), Count: 2},
+	}
+
+	tests := []struct {
+		name     string
+		limit    int
+		expected []string
+	}{
+		{
+			name:     "no limit",
+			limit:    0,
+			expected: []string{"apple", "date", "banana", "elderberry", "cherry"},
+		},
+		{
+			name:     "limit",
+			limit:    3,
+			expected: []string{"apple", "date", "banana"},
+		},
+		{
+			name:     "limit breaks ties by term",
+			limit:    1,
+			expected: []string{"apple"},
+		},
+		{
+			name:     "limit larger than terms",
+			limit:    10,
+			expected: []string{"apple", "date", "banana", "elderberry", "cherry"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			top := NewTopTerms(test.limit)
+			for _, tf := range input {
+				top.Add(tf.Term, tf.Count)
+			}
+			require.Equal(t, len(test.expected), top.Len())
+
+			var terms []string
+			for _, tf := range top.Terms() {
+				terms = append(terms, string(tf.Term))
+			}
+			require.Equal(t, test.expected, terms)
+		})
+	}
+}
+
+func TestTopTermsCopiesTerms(t *testing.T) {
+	top := NewTopTerms(1)
+	term := []byte("foo")
+	top.Add(term, 1)
+	copy(term, "bar")
+	top.Add(term, 2)
+	copy(term, "baz")
+
+	require.Equal(t, []TermFrequency{{Term: []byte("bar"), Count: 2}}, top.Terms())
+}
@@ -23,6 +23,7 @@ package index
 import (
 	"errors"
 	"regexp"
+	"regexp/syntax"
 
 	"github.com/m3db/m3/src/m3ninx/doc"
 	"github.com/m3db/m3/src/m3ninx/postings"
@@ -31,8 +32,14 @@ import (
 	vregex "github.com/couchbase/vellum/regexp"
 )
 
-// ErrDocNotFound is the error returned when there is no document for a given postings ID.
-var ErrDocNotFound = errors.New("no document with given postings ID")
+var (
+	// ErrDocNotFound is the error returned when there is no document for a given postings ID.
+	ErrDocNotFound = errors.New("no document with given postings ID")
+
+	// ErrTooManyTermsMatched is the error returned when a search matches more terms than
+	// permitted by its maximum number of terms matched.
+	ErrTooManyTermsMatched = errors.New("search matched too many terms")
+)
 
 // Index is a collection of searchable documents.
 type Index interface {
@@ -69,6 +76,42 @@ type Readable interface {
 	// regular expression.
 	MatchRegexp(field []byte, c CompiledRegex) (postings.List, error)
 
+	// MatchFuzzy returns a postings list over all documents which have a term for the
+	// given field within the compiled maximum edit distance of the compiled term.
+	MatchFuzzy(field []byte, c CompiledFuzzy) (postings.List, error)
+
+	// MatchPrefix returns a postings list over all documents which have a term for the
+	// given field beginning with the given prefix.
+	MatchPrefix(field, prefix []byte) (postings.List, error)
+
+	// MatchRange returns a postings list over all documents which have a term for the
+	// given field within the compiled range.
+	MatchRange(field []byte, c CompiledRange) (postings.List, error)
+
+	// MatchFold returns a postings list over all documents which have a term for the
+	// given field equal to, or beginning with, the compiled term under case folding.
+	MatchFold(field []byte, c CompiledFold) (postings.List, error)
+
+	// MatchField returns a postings list over all documents which have any term for the
+	// given field.
+	MatchField(field []byte) (postings.List, error)
+
+	// MatchRegexpTerms returns at most limit of the terms for the given field which
+	// match the given regular expression along with their document frequencies, ordered
+	// by descending frequency. A non-positive limit returns every matching term.
+	MatchRegexpTerms(field []byte, c CompiledRegex, limit int) ([]TermFrequency, error)
+
+	// MatchPrefixTerms returns at most limit of the terms for the given field beginning
+	// with the given prefix along with their document frequencies, ordered by descending
+	// frequency. A non-positive limit returns every matching term.
+	MatchPrefixTerms(field, prefix []byte, limit int) ([]TermFrequency, error)
+
+	// MatchPrefixTermsAfter returns at most limit of the terms for the given field
+	// beginning with the given prefix which sort after the given term, along with their
+	// document frequencies, in ascending order. A nil after term returns the terms from
+	// the start of the prefix and a non-positive limit returns every matching term.
+	MatchPrefixTermsAfter(field, prefix, after []byte, limit int) ([]TermFrequency, error)
+
 	// MatchAll returns a postings list for all documents known to the Reader.
 	MatchAll() (postings.MutableList, error)
 
@@ -85,8 +128,17 @@ type Readable interface {
 type CompiledRegex struct {
 	Simple      *regexp.Regexp
 	FST         *vregex.Regexp
+	FSTSyntax   *syntax.Regexp
 	PrefixBegin []byte
 	PrefixEnd   []byte
+
+	// RequiredLiterals are literals which every term matching the regexp contains, which
+	// allow terms to be ruled out without evaluating the regexp.
+	RequiredLiterals [][]byte
+
+	// MaxTermsMatched, if positive, is the maximum number of terms the regexp may match
+	// before matching is aborted with ErrTooManyTermsMatched.
+	MaxTermsMatched int
 }
 
 // DocRetriever returns the document associated with a postings ID. It returns
@@ -111,6 +163,34 @@ type Reader interface {
 	Close() error
 }
 
+// ReaderStats are statistics about the matches performed by a Reader.
+type ReaderStats struct {
+	// TermsScanned is the number of terms examined while matching.
+	TermsScanned int64
+}
+
+// StatsReader is a Reader which accumulates statistics about the matches it performs.
+type StatsReader interface {
+	Reader
+
+	// Stats returns the statistics accumulated by the Reader since it was created.
+	Stats() ReaderStats
+}
+
+// ImmutableReader is a Reader of an immutable segment. The matches of the segment never
+// change so they may be cached until the segment is closed.
+type ImmutableReader interface {
+	Reader
+
+	// SegmentID returns an ID of the segment, unique amongst the segments created by the
+	// process.
+	SegmentID() uint64
+
+	// OnSegmentClose registers a function to be called once the segment is closed, or
+	// calls it immediately if the segment is already closed.
+	OnSegmentClose(fn func())
+}
+
 // Readers is a slice of Reader.
 type Readers []Reader
 
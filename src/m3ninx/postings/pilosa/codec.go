@@ -91,3 +91,13 @@ func Unmarshal(data []byte, allocFn postings.PoolAllocateFn) (postings.List, err
 	iter := NewIterator(b.Iterator())
 	return pl, pl.AddIterator(iter)
 }
+
+// UnmarshalInto decodes the provided bytes and adds the IDs they contain to the given
+// postings list, avoiding the allocation of an intermediate postings list.
+func UnmarshalInto(data []byte, pl postings.MutableList) error {
+	b := roaring.NewBitmap()
+	if err := b.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	return pl.AddIterator(NewIterator(b.Iterator()))
+}
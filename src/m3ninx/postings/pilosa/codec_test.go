@@ -42,3 +42,20 @@ func TestEncodeDecode(t *testing.T) {
 
 	require.True(t, b.Equal(unmarshaled))
 }
+
+func TestUnmarshalInto(t *testing.T) {
+	b := roaring.NewPostingsList()
+	b.AddRange(postings.ID(1), postings.ID(1000))
+
+	e := NewEncoder()
+	bytes, err := e.Encode(b)
+	require.NoError(t, err)
+
+	pl := roaring.NewPostingsList()
+	pl.AddRange(postings.ID(500), postings.ID(2000))
+	require.NoError(t, UnmarshalInto(bytes, pl))
+
+	expected := roaring.NewPostingsList()
+	expected.AddRange(postings.ID(1), postings.ID(2000))
+	require.True(t, expected.Equal(pl))
+}
@@ -153,6 +153,10 @@ func (d *postingsList) Len() int {
 	return int(d.bitmap.GetCardinality())
 }
 
+func (d *postingsList) SizeInBytes() int {
+	return int(d.bitmap.GetSizeInBytes())
+}
+
 func (d *postingsList) Iterator() postings.Iterator {
 	return &roaringIterator{
 		iter: d.bitmap.Iterator(),
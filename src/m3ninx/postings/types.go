@@ -61,6 +61,9 @@ type List interface {
 	// Len returns the numbers of IDs in the postings list.
 	Len() int
 
+	// SizeInBytes returns an estimate of the memory used by the postings list.
+	SizeInBytes() int
+
 	// Iterator returns an iterator over the IDs in the postings list.
 	Iterator() Iterator
 
@@ -40,15 +40,17 @@ type executor struct {
 
 	newIteratorFn newIteratorFn
 	readers       index.Readers
+	opts          search.Options
 
 	closed bool
 }
 
-// NewExecutor returns a new Executor for executing queries.
-func NewExecutor(rs index.Readers) search.Executor {
+// NewExecutor returns a new Executor for executing queries with the given options.
+func NewExecutor(rs index.Readers, opts search.Options) search.Executor {
 	return &executor{
 		newIteratorFn: newIterator,
 		readers:       rs,
+		opts:          opts,
 	}
 }
 
@@ -59,10 +61,13 @@ func (e *executor) Execute(q search.Query) (doc.Iterator, error) {
 		return nil, errExecutorClosed
 	}
 
-	s, err := q.Searcher()
+	s, err := search.NewSearcher(q, e.opts)
 	if err != nil {
 		return nil, err
 	}
+	if cache := e.opts.PostingsCache(); cache != nil {
+		s = cache.Searcher(q, e.opts, s)
+	}
 
 	iter, err := e.newIteratorFn(s, e.readers)
 	if err != nil {
@@ -0,0 +1,102 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package executor
+
+import (
+	"testing"
+
+	"github.com/m3db/m3/src/m3ninx/index"
+	"github.com/m3db/m3/src/m3ninx/index/segment/mem"
+	"github.com/m3db/m3/src/m3ninx/search"
+	"github.com/m3db/m3/src/m3ninx/search/query"
+	"github.com/m3db/m3/src/m3ninx/util"
+)
+
+var benchTraceQuery = query.NewConjunctionQuery([]search.Query{
+	query.MustCreateTermQuery([]byte("__name__"), []byte("node_systemd_unit_state")),
+	query.MustCreateRegexpQuery([]byte("state"), []byte("(in)?active")),
+})
+
+func BenchmarkSearcherTrace(b *testing.B) {
+	benchmarks := []struct {
+		name        string
+		newSearcher func(q search.Query) (search.Searcher, error)
+	}{
+		{
+			name: "query searcher",
+			newSearcher: func(q search.Query) (search.Searcher, error) {
+				return q.Searcher(search.NewOptions())
+			},
+		},
+		{
+			name: "untraced",
+			newSearcher: func(q search.Query) (search.Searcher, error) {
+				return search.NewSearcher(q, search.NewOptions())
+			},
+		},
+		{
+			name: "traced",
+			newSearcher: func(q search.Query) (search.Searcher, error) {
+				opts := search.NewOptions().SetTrace(search.NewTrace("bench"))
+				return search.NewSearcher(q, opts)
+			},
+		},
+	}
+
+	r := newBenchReader(b)
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				s, err := bm.newSearcher(benchTraceQuery)
+				if err != nil {
+					b.Fatalf("unable to create searcher: %v", err)
+				}
+				if _, err := s.Search(r); err != nil {
+					b.Fatalf("unable to search: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func newBenchReader(b *testing.B) index.Reader {
+	docs, err := util.ReadDocs("../../util/testdata/node_exporter.json", 2000)
+	if err != nil {
+		b.Fatalf("unable to read documents for benchmarks: %v", err)
+	}
+
+	seg, err := mem.NewSegment(0, mem.NewOptions())
+	if err != nil {
+		b.Fatalf("unable to create segment: %v", err)
+	}
+	for _, d := range docs {
+		if _, err := seg.Insert(d); err != nil {
+			b.Fatalf("unable to insert document: %v", err)
+		}
+	}
+
+	r, err := seg.Reader()
+	if err != nil {
+		b.Fatalf("unable to create reader: %v", err)
+	}
+	return r
+}
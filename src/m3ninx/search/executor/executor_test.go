@@ -25,7 +25,9 @@ import (
 
 	"github.com/m3db/m3/src/m3ninx/doc"
 	"github.com/m3db/m3/src/m3ninx/index"
+	"github.com/m3db/m3/src/m3ninx/index/segment/mem"
 	"github.com/m3db/m3/src/m3ninx/search"
+	"github.com/m3db/m3/src/m3ninx/search/query"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
@@ -45,17 +47,18 @@ func TestExecutor(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	var (
-		q  = search.NewMockQuery(mockCtrl)
-		r  = index.NewMockReader(mockCtrl)
-		rs = index.Readers{r}
+		q    = search.NewMockQuery(mockCtrl)
+		r    = index.NewMockReader(mockCtrl)
+		rs   = index.Readers{r}
+		opts = search.NewOptions()
 	)
 	gomock.InOrder(
-		q.EXPECT().Searcher().Return(nil, nil),
+		q.EXPECT().Searcher(opts).Return(nil, nil),
 
 		r.EXPECT().Close().Return(nil),
 	)
 
-	e := NewExecutor(rs).(*executor)
+	e := NewExecutor(rs, opts).(*executor)
 
 	// Override newIteratorFn to return test iterator.
 	e.newIteratorFn = func(_ search.Searcher, _ index.Readers) (doc.Iterator, error) {
@@ -71,3 +74,57 @@ func TestExecutor(t *testing.T) {
 	err = e.Close()
 	require.NoError(t, err)
 }
+
+func TestExecutorTrace(t *testing.T) {
+	seg, err := mem.NewSegment(0, mem.NewOptions())
+	require.NoError(t, err)
+	for _, fields := range [][]string{
+		{"fruit", "apple", "color", "red"},
+		{"fruit", "banana", "color", "yellow"},
+		{"fruit", "pineapple", "color", "yellow"},
+	} {
+		_, err := seg.Insert(doc.Document{
+			Fields: []doc.Field{
+				{Name: []byte(fields[0]), Value: []byte(fields[1])},
+				{Name: []byte(fields[2]), Value: []byte(fields[3])},
+			},
+		})
+		require.NoError(t, err)
+	}
+	r, err := seg.Reader()
+	require.NoError(t, err)
+
+	var (
+		termQuery   = query.MustCreateTermQuery([]byte("color"), []byte("yellow"))
+		regexpQuery = query.MustCreateRegexpQuery([]byte("fruit"), []byte(".*apple"))
+		q           = query.NewConjunctionQuery([]search.Query{termQuery, regexpQuery})
+		trace       = search.NewTrace("segment")
+	)
+	e := NewExecutor(index.Readers{r}, search.NewOptions().SetTrace(trace))
+	it, err := e.Execute(q)
+	require.NoError(t, err)
+	var matched []string
+	for it.Next() {
+		matched = append(matched, string(it.Current().Fields[0].Value))
+	}
+	require.NoError(t, it.Err())
+	require.NoError(t, it.Close())
+	require.NoError(t, e.Close())
+	require.Equal(t, []string{"pineapple"}, matched)
+
+	require.Len(t, trace.Children, 1)
+	root := trace.Children[0]
+	require.Equal(t, q.String(), root.Name)
+	require.Equal(t, int64(1), root.DocsEmitted)
+	require.Equal(t, int64(4), root.PostingsDecoded)
+	require.Equal(t, int64(4), root.TermsScanned)
+
+	require.Len(t, root.Children, 2)
+	termTrace, regexpTrace := root.Children[0], root.Children[1]
+	require.Equal(t, termQuery.String(), termTrace.Name)
+	require.Equal(t, int64(2), termTrace.DocsEmitted)
+	require.Equal(t, int64(1), termTrace.TermsScanned)
+	require.Equal(t, regexpQuery.String(), regexpTrace.Name)
+	require.Equal(t, int64(2), regexpTrace.DocsEmitted)
+	require.Equal(t, int64(3), regexpTrace.TermsScanned)
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package search
+
+// Options is a collection of knobs for executing a search.
+type Options interface {
+	// SetMaxTermsMatched sets the maximum number of terms a regexp or wildcard search
+	// may match in a single segment before the search is aborted with
+	// index.ErrTooManyTermsMatched. A non-positive value means there is no limit.
+	SetMaxTermsMatched(value int) Options
+
+	// MaxTermsMatched returns the maximum number of terms a regexp or wildcard search
+	// may match in a single segment.
+	MaxTermsMatched() int
+
+	// SetTrace sets the trace in which the execution of a search is recorded. A nil
+	// trace means the search is not traced.
+	SetTrace(value *Trace) Options
+
+	// Trace returns the trace in which the execution of a search is recorded.
+	Trace() *Trace
+
+	// SetPostingsCache sets the cache of the postings lists matched by searches of
+	// immutable segments. A nil cache means matches are not cached.
+	SetPostingsCache(value *PostingsCache) Options
+
+	// PostingsCache returns the cache of the postings lists matched by searches of
+	// immutable segments.
+	PostingsCache() *PostingsCache
+}
+
+type opts struct {
+	maxTermsMatched int
+	trace           *Trace
+	postingsCache   *PostingsCache
+}
+
+// NewOptions returns new options.
+func NewOptions() Options {
+	return &opts{}
+}
+
+func (o *opts) SetMaxTermsMatched(value int) Options {
+	opts := *o
+	opts.maxTermsMatched = value
+	return &opts
+}
+
+func (o *opts) MaxTermsMatched() int {
+	return o.maxTermsMatched
+}
+
+func (o *opts) SetTrace(value *Trace) Options {
+	opts := *o
+	opts.trace = value
+	return &opts
+}
+
+func (o *opts) Trace() *Trace {
+	return o.trace
+}
+
+func (o *opts) SetPostingsCache(value *PostingsCache) Options {
+	opts := *o
+	opts.postingsCache = value
+	return &opts
+}
+
+func (o *opts) PostingsCache() *PostingsCache {
+	return o.postingsCache
+}
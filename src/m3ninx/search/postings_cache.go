@@ -0,0 +1,245 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package search
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/m3db/m3/src/m3ninx/index"
+	"github.com/m3db/m3/src/m3ninx/postings"
+
+	"github.com/uber-go/tally"
+)
+
+// postingsCacheEntryOverhead is an estimate of the memory used by a cache entry besides
+// its postings list.
+const postingsCacheEntryOverhead = 128
+
+// NB: the key includes the search options which change the outcome of a search, so a
+// query searched without a limit on the terms matched doesn't share its matches with
+// the same query searched with a limit it would exceed.
+type postingsCacheKey struct {
+	segmentID       uint64
+	queryHash       uint64
+	maxTermsMatched int
+}
+
+type postingsCacheEntry struct {
+	key      postingsCacheKey
+	query    Query
+	postings postings.List
+	size     int
+}
+
+type postingsCacheMetrics struct {
+	hits          tally.Counter
+	misses        tally.Counter
+	evictions     tally.Counter
+	invalidations tally.Counter
+	entries       tally.Gauge
+	bytes         tally.Gauge
+}
+
+func newPostingsCacheMetrics(scope tally.Scope) postingsCacheMetrics {
+	return postingsCacheMetrics{
+		hits:          scope.Counter("hits"),
+		misses:        scope.Counter("misses"),
+		evictions:     scope.Counter("evictions"),
+		invalidations: scope.Counter("invalidations"),
+		entries:       scope.Gauge("entries"),
+		bytes:         scope.Gauge("bytes"),
+	}
+}
+
+// PostingsCache is a bounded cache of the postings lists matched by queries against
+// immutable segments, keyed by segment, query hash and search options. The matches of a query against
+// an immutable segment never change so they're cached until the segment is closed, or
+// until they're evicted to keep the cache within its maximum size. Searches of mutable
+// segments bypass the cache.
+type PostingsCache struct {
+	sync.Mutex
+
+	maxBytes int
+	bytes    int
+	entries  map[postingsCacheKey]*list.Element
+	order    *list.List
+
+	// segments are the keys of the matches cached for each segment, for invalidating
+	// them once the segment is closed.
+	segments map[uint64]map[postingsCacheKey]struct{}
+
+	metrics postingsCacheMetrics
+}
+
+// NewPostingsCache returns a new cache of postings lists whose estimated size is at most
+// maxBytes, reporting its metrics to the given scope.
+func NewPostingsCache(maxBytes int, scope tally.Scope) *PostingsCache {
+	return &PostingsCache{
+		maxBytes: maxBytes,
+		entries:  make(map[postingsCacheKey]*list.Element),
+		order:    list.New(),
+		segments: make(map[uint64]map[postingsCacheKey]struct{}),
+		metrics:  newPostingsCacheMetrics(scope),
+	}
+}
+
+// Searcher returns a searcher which returns the cached matches of the query for immutable
+// segments, searching with the given searcher of the query constructed with the given
+// options and caching its matches if they're not already cached.
+func (c *PostingsCache) Searcher(q Query, opts Options, s Searcher) Searcher {
+	return &cachingSearcher{
+		cache:           c,
+		query:           q,
+		maxTermsMatched: opts.MaxTermsMatched(),
+		searcher:        s,
+	}
+}
+
+func (c *PostingsCache) get(key postingsCacheKey, q Query) (postings.List, bool) {
+	c.Lock()
+	elem, ok := c.entries[key]
+	// NB: queries are compared as well as their hashes in case of hash collisions.
+	if !ok || !elem.Value.(*postingsCacheEntry).query.Equal(q) {
+		c.Unlock()
+		c.metrics.misses.Inc(1)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	pl := elem.Value.(*postingsCacheEntry).postings
+	c.Unlock()
+
+	c.metrics.hits.Inc(1)
+	return pl, true
+}
+
+func (c *PostingsCache) put(
+	r index.ImmutableReader,
+	key postingsCacheKey,
+	q Query,
+	pl postings.List,
+) {
+	entry := &postingsCacheEntry{
+		key:      key,
+		query:    q,
+		postings: pl,
+		size:     postingsCacheEntryOverhead + pl.SizeInBytes(),
+	}
+	if entry.size > c.maxBytes {
+		return
+	}
+
+	c.Lock()
+	if elem, ok := c.entries[entry.key]; ok {
+		c.removeWithLock(elem)
+	}
+
+	keys, registered := c.segments[entry.key.segmentID]
+	if !registered {
+		keys = make(map[postingsCacheKey]struct{})
+		c.segments[entry.key.segmentID] = keys
+	}
+	keys[entry.key] = struct{}{}
+	c.entries[entry.key] = c.order.PushFront(entry)
+	c.bytes += entry.size
+
+	for c.bytes > c.maxBytes {
+		c.removeWithLock(c.order.Back())
+		c.metrics.evictions.Inc(1)
+	}
+	c.updateGaugesWithLock()
+	c.Unlock()
+
+	// NB: the callback is registered outside of the lock since it's called immediately
+	// if the segment has already been closed.
+	if !registered {
+		segmentID := entry.key.segmentID
+		r.OnSegmentClose(func() {
+			c.invalidate(segmentID)
+		})
+	}
+}
+
+// invalidate removes the entries of the segment with the given ID from the cache.
+func (c *PostingsCache) invalidate(segmentID uint64) {
+	c.Lock()
+	defer c.Unlock()
+
+	for key := range c.segments[segmentID] {
+		if elem, ok := c.entries[key]; ok {
+			c.removeWithLock(elem)
+		}
+	}
+	delete(c.segments, segmentID)
+	c.metrics.invalidations.Inc(1)
+	c.updateGaugesWithLock()
+}
+
+func (c *PostingsCache) removeWithLock(elem *list.Element) {
+	entry := c.order.Remove(elem).(*postingsCacheEntry)
+	delete(c.entries, entry.key)
+	c.bytes -= entry.size
+
+	// NB: the segment itself remains registered so its close callback isn't registered
+	// again when its queries are next cached.
+	if keys, ok := c.segments[entry.key.segmentID]; ok {
+		delete(keys, entry.key)
+	}
+}
+
+func (c *PostingsCache) updateGaugesWithLock() {
+	c.metrics.entries.Update(float64(len(c.entries)))
+	c.metrics.bytes.Update(float64(c.bytes))
+}
+
+type cachingSearcher struct {
+	cache           *PostingsCache
+	query           Query
+	maxTermsMatched int
+	searcher        Searcher
+}
+
+func (s *cachingSearcher) Search(r index.Reader) (postings.List, error) {
+	immutable, ok := r.(index.ImmutableReader)
+	if !ok {
+		return s.searcher.Search(r)
+	}
+
+	key := postingsCacheKey{
+		segmentID:       immutable.SegmentID(),
+		queryHash:       s.query.Hash(),
+		maxTermsMatched: s.maxTermsMatched,
+	}
+	if pl, ok := s.cache.get(key, s.query); ok {
+		return pl, nil
+	}
+
+	pl, err := s.searcher.Search(r)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.put(immutable, key, s.query, pl)
+	return pl, nil
+}
+
+func (s *cachingSearcher) Cost() int {
+	return s.searcher.Cost()
+}
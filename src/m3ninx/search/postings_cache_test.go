@@ -0,0 +1,393 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package search
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/m3db/m3/src/m3ninx/generated/proto/querypb"
+	"github.com/m3db/m3/src/m3ninx/index"
+	"github.com/m3db/m3/src/m3ninx/postings"
+	"github.com/m3db/m3/src/m3ninx/postings/roaring"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+// testImmutableReader is a reader of an immutable segment whose documents are
+// identified by the segment ID.
+type testImmutableReader struct {
+	index.Reader
+
+	sync.Mutex
+	id       uint64
+	closed   bool
+	closeFns []func()
+}
+
+func newTestImmutableReader(id uint64) *testImmutableReader {
+	return &testImmutableReader{id: id}
+}
+
+func (r *testImmutableReader) SegmentID() uint64 {
+	return r.id
+}
+
+func (r *testImmutableReader) OnSegmentClose(fn func()) {
+	r.Lock()
+	if r.closed {
+		r.Unlock()
+		fn()
+		return
+	}
+	r.closeFns = append(r.closeFns, fn)
+	r.Unlock()
+}
+
+func (r *testImmutableReader) closeSegment() {
+	r.Lock()
+	r.closed = true
+	closeFns := r.closeFns
+	r.closeFns = nil
+	r.Unlock()
+
+	for _, fn := range closeFns {
+		fn()
+	}
+}
+
+// testQuery is a query matching a single document whose ID is derived from the ID of
+// the segment searched and the query's value.
+type testQuery struct {
+	value    uint64
+	hash     uint64
+	searches *int64
+}
+
+func newTestQuery(value uint64) *testQuery {
+	return &testQuery{
+		value:    value,
+		hash:     value,
+		searches: new(int64),
+	}
+}
+
+func testQueryMatch(segmentID, value uint64) postings.ID {
+	return postings.ID(segmentID*1000 + value)
+}
+
+func (q *testQuery) Searcher(opts Options) (Searcher, error) {
+	return &testQuerySearcher{query: q}, nil
+}
+
+func (q *testQuery) Equal(o Query) bool {
+	inner, ok := o.(*testQuery)
+	return ok && q.value == inner.value
+}
+
+func (q *testQuery) Hash() uint64 {
+	return q.hash
+}
+
+func (q *testQuery) ToProto() *querypb.Query {
+	return nil
+}
+
+func (q *testQuery) String() string {
+	return fmt.Sprintf("test(%d)", q.value)
+}
+
+type testQuerySearcher struct {
+	query *testQuery
+}
+
+func (s *testQuerySearcher) Search(r index.Reader) (postings.List, error) {
+	atomic.AddInt64(s.query.searches, 1)
+	var segmentID uint64
+	if immutable, ok := r.(index.ImmutableReader); ok {
+		segmentID = immutable.SegmentID()
+	}
+	pl := roaring.NewPostingsList()
+	pl.Insert(testQueryMatch(segmentID, s.query.value))
+	return pl, nil
+}
+
+func (s *testQuerySearcher) Cost() int {
+	return 1
+}
+
+func newTestCachingSearcher(t *testing.T, cache *PostingsCache, q *testQuery) Searcher {
+	return newTestCachingSearcherWithOptions(t, cache, q, NewOptions())
+}
+
+func newTestCachingSearcherWithOptions(
+	t *testing.T,
+	cache *PostingsCache,
+	q *testQuery,
+	opts Options,
+) Searcher {
+	s, err := q.Searcher(opts)
+	require.NoError(t, err)
+	return cache.Searcher(q, opts, s)
+}
+
+func requireSearch(t *testing.T, s Searcher, r index.Reader, expected postings.ID) {
+	pl, err := s.Search(r)
+	require.NoError(t, err)
+	require.Equal(t, 1, pl.Len())
+	require.True(t, pl.Contains(expected))
+}
+
+func TestPostingsCacheHit(t *testing.T) {
+	var (
+		scope = tally.NewTestScope("", nil)
+		cache = NewPostingsCache(1<<20, scope)
+		q     = newTestQuery(1)
+		s     = newTestCachingSearcher(t, cache, q)
+		r     = newTestImmutableReader(7)
+	)
+
+	requireSearch(t, s, r, testQueryMatch(7, 1))
+	requireSearch(t, s, r, testQueryMatch(7, 1))
+	require.Equal(t, int64(1), atomic.LoadInt64(q.searches))
+
+	// An equivalent query shares the cached matches.
+	requireSearch(t, newTestCachingSearcher(t, cache, newTestQuery(1)), r, testQueryMatch(7, 1))
+	require.Equal(t, int64(1), atomic.LoadInt64(q.searches))
+
+	snapshot := scope.Snapshot()
+	require.Equal(t, int64(2), snapshot.Counters()["hits+"].Value())
+	require.Equal(t, int64(1), snapshot.Counters()["misses+"].Value())
+	require.Equal(t, float64(1), snapshot.Gauges()["entries+"].Value())
+	require.True(t, snapshot.Gauges()["bytes+"].Value() > 0)
+}
+
+func TestPostingsCacheKeyedBySearchOptions(t *testing.T) {
+	var (
+		cache   = NewPostingsCache(1<<20, tally.NoopScope)
+		q       = newTestQuery(1)
+		r       = newTestImmutableReader(7)
+		limited = NewOptions().SetMaxTermsMatched(1)
+	)
+
+	requireSearch(t, newTestCachingSearcher(t, cache, q), r, testQueryMatch(7, 1))
+	// Matches found without a limit on the terms matched aren't shared with a search
+	// which has a limit, since it may have exceeded it.
+	requireSearch(t, newTestCachingSearcherWithOptions(t, cache, q, limited), r, testQueryMatch(7, 1))
+	require.Equal(t, int64(2), atomic.LoadInt64(q.searches))
+
+	requireSearch(t, newTestCachingSearcherWithOptions(t, cache, q, limited), r, testQueryMatch(7, 1))
+	require.Equal(t, int64(2), atomic.LoadInt64(q.searches))
+
+	// Closing the segment invalidates the matches for every set of options.
+	r.closeSegment()
+	cache.Lock()
+	require.Empty(t, cache.entries)
+	cache.Unlock()
+}
+
+func TestPostingsCacheMutableSegmentBypassesCache(t *testing.T) {
+	var (
+		cache = NewPostingsCache(1<<20, tally.NoopScope)
+		q     = newTestQuery(1)
+		s     = newTestCachingSearcher(t, cache, q)
+		r     = &testStatsReader{}
+	)
+
+	requireSearch(t, s, r, testQueryMatch(0, 1))
+	requireSearch(t, s, r, testQueryMatch(0, 1))
+	require.Equal(t, int64(2), atomic.LoadInt64(q.searches))
+}
+
+func TestPostingsCacheHashCollision(t *testing.T) {
+	var (
+		cache = NewPostingsCache(1<<20, tally.NoopScope)
+		r     = newTestImmutableReader(7)
+		q1    = newTestQuery(1)
+		q2    = newTestQuery(2)
+	)
+	q2.hash = q1.hash
+
+	requireSearch(t, newTestCachingSearcher(t, cache, q1), r, testQueryMatch(7, 1))
+	requireSearch(t, newTestCachingSearcher(t, cache, q2), r, testQueryMatch(7, 2))
+	require.Equal(t, int64(1), atomic.LoadInt64(q2.searches))
+}
+
+func TestPostingsCacheInvalidatedOnSegmentClose(t *testing.T) {
+	var (
+		scope = tally.NewTestScope("", nil)
+		cache = NewPostingsCache(1<<20, scope)
+		q     = newTestQuery(1)
+		s     = newTestCachingSearcher(t, cache, q)
+		r1    = newTestImmutableReader(1)
+		r2    = newTestImmutableReader(2)
+	)
+
+	requireSearch(t, s, r1, testQueryMatch(1, 1))
+	requireSearch(t, s, r2, testQueryMatch(2, 1))
+	require.Len(t, r1.closeFns, 1)
+
+	r1.closeSegment()
+	cache.Lock()
+	require.Len(t, cache.entries, 1)
+	require.NotContains(t, cache.segments, uint64(1))
+	cache.Unlock()
+
+	// Matches cached once a segment has been closed are invalidated immediately.
+	requireSearch(t, s, r1, testQueryMatch(1, 1))
+	cache.Lock()
+	require.Len(t, cache.entries, 1)
+	cache.Unlock()
+	require.Equal(t, int64(2), scope.Snapshot().Counters()["invalidations+"].Value())
+}
+
+func TestPostingsCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	var (
+		entrySize = postingsCacheEntryOverhead + testQueryPostingsSize(t)
+		cache     = NewPostingsCache(2*entrySize, tally.NoopScope)
+		r         = newTestImmutableReader(7)
+		q1        = newTestQuery(1)
+		q2        = newTestQuery(2)
+		q3        = newTestQuery(3)
+	)
+
+	requireSearch(t, newTestCachingSearcher(t, cache, q1), r, testQueryMatch(7, 1))
+	requireSearch(t, newTestCachingSearcher(t, cache, q2), r, testQueryMatch(7, 2))
+	// Use the first query so the second is the least recently used.
+	requireSearch(t, newTestCachingSearcher(t, cache, q1), r, testQueryMatch(7, 1))
+	requireSearch(t, newTestCachingSearcher(t, cache, q3), r, testQueryMatch(7, 3))
+
+	cache.Lock()
+	require.Equal(t, 2*entrySize, cache.bytes)
+	require.Len(t, cache.entries, 2)
+	require.NotContains(t, cache.entries, postingsCacheKey{segmentID: 7, queryHash: q2.Hash()})
+	cache.Unlock()
+
+	requireSearch(t, newTestCachingSearcher(t, cache, q1), r, testQueryMatch(7, 1))
+	require.Equal(t, int64(1), atomic.LoadInt64(q1.searches))
+}
+
+func TestPostingsCacheSkipsListsLargerThanCache(t *testing.T) {
+	var (
+		cache = NewPostingsCache(postingsCacheEntryOverhead, tally.NoopScope)
+		q     = newTestQuery(1)
+		s     = newTestCachingSearcher(t, cache, q)
+		r     = newTestImmutableReader(7)
+	)
+
+	requireSearch(t, s, r, testQueryMatch(7, 1))
+	requireSearch(t, s, r, testQueryMatch(7, 1))
+	require.Equal(t, int64(2), atomic.LoadInt64(q.searches))
+	require.Empty(t, r.closeFns)
+}
+
+func TestPostingsCacheConcurrentSegmentRotation(t *testing.T) {
+	const (
+		numGoroutines = 16
+		numSearches   = 2000
+		numQueries    = 8
+		numSegments   = 4
+	)
+
+	var (
+		cache = NewPostingsCache(64*(postingsCacheEntryOverhead+testQueryPostingsSize(t)), tally.NoopScope)
+
+		readersLock sync.RWMutex
+		readers     = make([]*testImmutableReader, numSegments)
+		nextID      = uint64(numSegments)
+
+		queries = make([]*testQuery, numQueries)
+	)
+	for i := range readers {
+		readers[i] = newTestImmutableReader(uint64(i))
+	}
+	for i := range queries {
+		queries[i] = newTestQuery(uint64(i))
+	}
+
+	var (
+		done     = make(chan struct{})
+		rotateWg sync.WaitGroup
+	)
+	rotateWg.Add(1)
+	go func() {
+		defer rotateWg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			// Replace a segment with a new one and close the old one, as happens when
+			// segments are compacted or blocks are rotated out of retention.
+			readersLock.Lock()
+			idx := i % numSegments
+			old := readers[idx]
+			readers[idx] = newTestImmutableReader(atomic.AddUint64(&nextID, 1))
+			readersLock.Unlock()
+			old.closeSegment()
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for g := 0; g < numGoroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < numSearches; i++ {
+				readersLock.RLock()
+				r := readers[(g+i)%numSegments]
+				readersLock.RUnlock()
+
+				q := queries[(g*i)%numQueries]
+				pl, err := cache.Searcher(q, NewOptions(), &testQuerySearcher{query: q}).Search(r)
+				if err != nil || pl.Len() != 1 || !pl.Contains(testQueryMatch(r.id, q.value)) {
+					t.Errorf("unexpected matches of %s for segment %d: %v", q, r.id, err)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(done)
+	rotateWg.Wait()
+
+	// Close the remaining segments, after which nothing should remain cached.
+	for _, r := range readers {
+		r.closeSegment()
+	}
+	cache.Lock()
+	defer cache.Unlock()
+	require.Empty(t, cache.entries)
+	require.Empty(t, cache.segments)
+	require.Equal(t, 0, cache.bytes)
+	require.Equal(t, 0, cache.order.Len())
+}
+
+func testQueryPostingsSize(t *testing.T) int {
+	pl, err := (&testQuerySearcher{query: newTestQuery(1)}).Search(newTestImmutableReader(7))
+	require.NoError(t, err)
+	return pl.SizeInBytes()
+}
@@ -48,12 +48,12 @@ func TestConcurrentQueries(t *testing.T) {
 	simpleSeg := newTestMemSegment(t, lotsTestDocuments)
 	simpleReader, err := simpleSeg.Reader()
 	require.NoError(t, err)
-	simpleExec := executor.NewExecutor([]index.Reader{simpleReader})
+	simpleExec := executor.NewExecutor([]index.Reader{simpleReader}, search.NewOptions())
 
 	fstSeg := fst.ToTestSegment(t, simpleSeg, fstOptions)
 	fstReader, err := fstSeg.Reader()
 	require.NoError(t, err)
-	fstExec := executor.NewExecutor([]index.Reader{fstReader})
+	fstExec := executor.NewExecutor([]index.Reader{fstReader}, search.NewOptions())
 
 	properties.Property("Any concurrent queries segments does not affect fst segments", prop.ForAll(
 		func(q search.Query) (bool, error) {
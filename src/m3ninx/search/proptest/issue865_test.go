@@ -102,11 +102,11 @@ func TestAnyDistributionOfDocsDoesNotAffectQuery(t *testing.T) {
 			}
 
 			q := query.NewConjunctionQuery([]search.Query{
-				query.NewTermQuery([]byte("__name__"), []byte("node_memory_SwapTotal_bytes")),
-				query.NewTermQuery([]byte("instance"), []byte("m3db-node01:9100")),
+				query.MustCreateTermQuery([]byte("__name__"), []byte("node_memory_SwapTotal_bytes")),
+				query.MustCreateTermQuery([]byte("instance"), []byte("m3db-node01:9100")),
 			})
 
-			e := executor.NewExecutor(readers)
+			e := executor.NewExecutor(readers, search.NewOptions())
 			d, err := e.Execute(q)
 			if err != nil {
 				return false, err
@@ -51,7 +51,7 @@ func TestSegmentDistributionDoesNotAffectQuery(t *testing.T) {
 		func(i propTestInput, q search.Query) (bool, error) {
 			r, err := simpleSeg.Reader()
 			require.NoError(t, err)
-			eOrg := executor.NewExecutor([]index.Reader{r})
+			eOrg := executor.NewExecutor([]index.Reader{r}, search.NewOptions())
 			dOrg, err := eOrg.Execute(q)
 			if err != nil {
 				return false, err
@@ -71,7 +71,7 @@ func TestSegmentDistributionDoesNotAffectQuery(t *testing.T) {
 				readers = append(readers, r)
 			}
 
-			e := executor.NewExecutor(readers)
+			e := executor.NewExecutor(readers, search.NewOptions())
 			d, err := e.Execute(q)
 			if err != nil {
 				return false, err
@@ -108,7 +108,7 @@ func TestFSTSimpleSegmentsQueryTheSame(t *testing.T) {
 		func(q search.Query) (bool, error) {
 			r, err := simpleSeg.Reader()
 			require.NoError(t, err)
-			eOrg := executor.NewExecutor([]index.Reader{r})
+			eOrg := executor.NewExecutor([]index.Reader{r}, search.NewOptions())
 			dOrg, err := eOrg.Execute(q)
 			if err != nil {
 				return false, err
@@ -120,7 +120,7 @@ func TestFSTSimpleSegmentsQueryTheSame(t *testing.T) {
 
 			rFst, err := fstSeg.Reader()
 			require.NoError(t, err)
-			e := executor.NewExecutor([]index.Reader{rFst})
+			e := executor.NewExecutor([]index.Reader{rFst}, search.NewOptions())
 			d, err := e.Execute(q)
 			if err != nil {
 				return false, err
@@ -49,7 +49,7 @@ func genTermQuery(docs []doc.Document) gopter.Gen {
 		fieldID := fieldRes.(int)
 		field := doc.Fields[fieldID]
 
-		q := query.NewTermQuery(field.Name, field.Value)
+		q := query.MustCreateTermQuery(field.Name, field.Value)
 		return gopter.NewGenResult(q, gopter.NoShrinker)
 	}
 }
@@ -113,13 +113,21 @@ func genNegationQuery(docs []doc.Document) gopter.Gen {
 }
 
 func genConjuctionQuery(docs []doc.Document) gopter.Gen {
-	return gen.SliceOf(
+	// Conjunctions must contain at least one non-negation query so we always generate
+	// one before the remaining (possibly negated) queries.
+	return gopter.CombineGens(
 		gen.OneGenOf(
 			genTermQuery(docs),
-			genRegexpQuery(docs),
-			genNegationQuery(docs)),
-		reflect.TypeOf((*search.Query)(nil)).Elem()).
-		Map(func(qs []search.Query) search.Query {
+			genRegexpQuery(docs)),
+		gen.SliceOf(
+			gen.OneGenOf(
+				genTermQuery(docs),
+				genRegexpQuery(docs),
+				genNegationQuery(docs)),
+			reflect.TypeOf((*search.Query)(nil)).Elem())).
+		Map(func(values []interface{}) search.Query {
+			qs := []search.Query{values[0].(search.Query)}
+			qs = append(qs, values[1].([]search.Query)...)
 			return query.NewConjunctionQuery(qs)
 		})
 }
@@ -30,6 +30,17 @@ import (
 
 var errNilQuery = errors.New("query is nil")
 
+// UnknownQueryError is returned when unmarshalling a query of an unknown kind, for
+// example one encoded by a newer version which supports additional kinds of queries.
+type UnknownQueryError struct {
+	// Kind is the type of the unknown query.
+	Kind string
+}
+
+func (e UnknownQueryError) Error() string {
+	return fmt.Sprintf("unknown query: %s", e.Kind)
+}
+
 // Marshal encodes a query into a byte slice.
 func Marshal(q search.Query) ([]byte, error) {
 	if q == nil {
@@ -49,44 +60,127 @@ func Unmarshal(data []byte) (search.Query, error) {
 }
 
 func unmarshal(q *querypb.Query) (search.Query, error) {
+	if q == nil {
+		return nil, errNilQuery
+	}
+
 	switch q := q.Query.(type) {
 
 	case *querypb.Query_Term:
-		return NewTermQuery(q.Term.Field, q.Term.Term), nil
+		t := q.Term
+		field, err := unmarshalField(t.GetField())
+		if err != nil {
+			return nil, err
+		}
+		if t.GetCaseInsensitive() {
+			return NewCaseInsensitiveTermQuery(field, t.GetTerm(),
+				caseFolding(t.GetUnicodeFolding()))
+		}
+		return NewTermQuery(field, t.GetTerm())
 
 	case *querypb.Query_Regexp:
-		return NewRegexpQuery(q.Regexp.Field, q.Regexp.Regexp)
+		field, err := unmarshalField(q.Regexp.GetField())
+		if err != nil {
+			return nil, err
+		}
+		return NewRegexpQuery(field, q.Regexp.GetRegexp())
+
+	case *querypb.Query_Fuzzy:
+		field, err := unmarshalField(q.Fuzzy.GetField())
+		if err != nil {
+			return nil, err
+		}
+		return NewFuzzyQuery(field, q.Fuzzy.GetTerm(), int(q.Fuzzy.GetMaxEditDistance()))
+
+	case *querypb.Query_Prefix:
+		p := q.Prefix
+		field, err := unmarshalField(p.GetField())
+		if err != nil {
+			return nil, err
+		}
+		if p.GetCaseInsensitive() {
+			return NewCaseInsensitivePrefixQuery(field, p.GetPrefix(),
+				caseFolding(p.GetUnicodeFolding()))
+		}
+		return NewPrefixQuery(field, p.GetPrefix())
+
+	case *querypb.Query_Wildcard:
+		field, err := unmarshalField(q.Wildcard.GetField())
+		if err != nil {
+			return nil, err
+		}
+		return NewWildcardQuery(field, q.Wildcard.GetPattern())
+
+	case *querypb.Query_Range:
+		r := q.Range
+		field, err := unmarshalField(r.GetField())
+		if err != nil {
+			return nil, err
+		}
+		if r.GetNumeric() {
+			return NewNumericRangeQuery(field, r.GetLower(), r.GetUpper(),
+				r.GetLowerInclusive(), r.GetUpperInclusive())
+		}
+		return NewRangeQuery(field, r.GetLower(), r.GetUpper(),
+			r.GetLowerInclusive(), r.GetUpperInclusive())
+
+	case *querypb.Query_Field:
+		field, err := unmarshalField(q.Field.GetField())
+		if err != nil {
+			return nil, err
+		}
+		return NewFieldQuery(field)
+
+	case *querypb.Query_MatchAll:
+		return NewMatchAllQuery(), nil
+
+	case *querypb.Query_MatchNone:
+		return NewMatchNoneQuery(), nil
 
 	case *querypb.Query_Negation:
-		inner, err := unmarshal(q.Negation.Query)
+		inner, err := unmarshal(q.Negation.GetQuery())
 		if err != nil {
 			return nil, err
 		}
 		return NewNegationQuery(inner), nil
 
 	case *querypb.Query_Conjunction:
-		qs := make([]search.Query, 0, len(q.Conjunction.Queries))
-		for _, qry := range q.Conjunction.Queries {
-			sqry, err := unmarshal(qry)
-			if err != nil {
-				return nil, err
-			}
-			qs = append(qs, sqry)
+		qs, err := unmarshalQueries(q.Conjunction.GetQueries())
+		if err != nil {
+			return nil, err
 		}
 		return NewConjunctionQuery(qs), nil
 
 	case *querypb.Query_Disjunction:
-		qs := make([]search.Query, 0, len(q.Disjunction.Queries))
-		for _, qry := range q.Disjunction.Queries {
-			sqry, err := unmarshal(qry)
-			if err != nil {
-				return nil, err
-			}
-			qs = append(qs, sqry)
+		qs, err := unmarshalQueries(q.Disjunction.GetQueries())
+		if err != nil {
+			return nil, err
 		}
 		return NewDisjunctionQuery(qs), nil
 
 	}
 
-	return nil, fmt.Errorf("unknown query: %v", q)
+	return nil, UnknownQueryError{Kind: fmt.Sprintf("%T", q.Query)}
+}
+
+// unmarshalField returns the field of an encoded query. Queries are always encoded with
+// their resolved field and Protobuf doesn't distinguish a nil field from an empty one, so
+// a missing field is rejected rather than defaulted to the reserved ID field.
+func unmarshalField(field []byte) ([]byte, error) {
+	if len(field) == 0 {
+		return nil, errEmptyField
+	}
+	return field, nil
+}
+
+func unmarshalQueries(pbs []*querypb.Query) ([]search.Query, error) {
+	qs := make([]search.Query, 0, len(pbs))
+	for _, pb := range pbs {
+		q, err := unmarshal(pb)
+		if err != nil {
+			return nil, err
+		}
+		qs = append(qs, q)
+	}
+	return qs, nil
 }
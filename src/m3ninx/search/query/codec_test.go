@@ -21,8 +21,13 @@
 package query
 
 import (
+	"fmt"
+	"math/rand"
 	"testing"
 
+	"github.com/m3db/m3/src/m3ninx/generated/proto/querypb"
+	"github.com/m3db/m3/src/m3ninx/index"
+
 	"github.com/m3db/m3/src/m3ninx/search"
 
 	"github.com/stretchr/testify/require"
@@ -35,40 +40,102 @@ func TestMarshal(t *testing.T) {
 	}{
 		{
 			name:  "term query",
-			query: NewTermQuery([]byte("fruit"), []byte("apple")),
+			query: MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+		},
+		{
+			name:  "term query with default field",
+			query: MustCreateTermQuery(nil, []byte("apple")),
+		},
+		{
+			name:  "case-insensitive term query",
+			query: MustCreateCaseInsensitiveTermQuery([]byte("fruit"), []byte("Apple"), index.ASCIICaseFolding),
+		},
+		{
+			name:  "unicode case-insensitive term query",
+			query: MustCreateCaseInsensitiveTermQuery([]byte("fruit"), []byte("Äpfel"), index.UnicodeCaseFolding),
 		},
 		{
 			name:  "regexp query",
 			query: MustCreateRegexpQuery([]byte("fruit"), []byte(".*ple")),
 		},
+		{
+			name:  "fuzzy query",
+			query: MustCreateFuzzyQuery([]byte("fruit"), []byte("apple"), 1),
+		},
+		{
+			name:  "prefix query",
+			query: MustCreatePrefixQuery([]byte("fruit"), []byte("app")),
+		},
+		{
+			name:  "case-insensitive prefix query",
+			query: MustCreateCaseInsensitivePrefixQuery([]byte("fruit"), []byte("APP"), index.UnicodeCaseFolding),
+		},
+		{
+			name:  "field query",
+			query: MustCreateFieldQuery([]byte("fruit")),
+		},
+		{
+			name: "negated field query",
+			query: NewConjunctionQuery([]search.Query{
+				MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+				NewNegationQuery(MustCreateFieldQuery([]byte("color"))),
+			}),
+		},
+		{
+			name:  "match all query",
+			query: NewMatchAllQuery(),
+		},
+		{
+			name:  "match none query",
+			query: NewMatchNoneQuery(),
+		},
+		{
+			name: "conjunction query with match all query and negation",
+			query: NewConjunctionQuery([]search.Query{
+				NewMatchAllQuery(),
+				NewNegationQuery(MustCreateTermQuery([]byte("fruit"), []byte("apple"))),
+			}),
+		},
+		{
+			name:  "wildcard query",
+			query: MustCreateWildcardQuery([]byte("fruit"), []byte("a?p*")),
+		},
+		{
+			name:  "range query",
+			query: MustCreateRangeQuery([]byte("shard"), []byte("10"), nil, true, false),
+		},
+		{
+			name:  "numeric range query",
+			query: MustCreateNumericRangeQuery([]byte("shard"), []byte("5"), []byte("20"), false, true),
+		},
 		{
 			name:  "negation query",
-			query: NewNegationQuery(NewTermQuery([]byte("fruit"), []byte("apple"))),
+			query: NewNegationQuery(MustCreateTermQuery([]byte("fruit"), []byte("apple"))),
 		},
 		{
 			name: "disjunction query",
 			query: NewDisjunctionQuery([]search.Query{
-				NewTermQuery([]byte("fruit"), []byte("apple")),
+				MustCreateTermQuery([]byte("fruit"), []byte("apple")),
 				NewConjunctionQuery([]search.Query{
-					NewTermQuery([]byte("fruit"), []byte("banana")),
+					MustCreateTermQuery([]byte("fruit"), []byte("banana")),
 				}),
 				NewDisjunctionQuery([]search.Query{
-					NewTermQuery([]byte("fruit"), []byte("orange")),
+					MustCreateTermQuery([]byte("fruit"), []byte("orange")),
 				}),
-				NewNegationQuery(NewTermQuery([]byte("fruit"), []byte("pear"))),
+				NewNegationQuery(MustCreateTermQuery([]byte("fruit"), []byte("pear"))),
 			}),
 		},
 		{
 			name: "conjunction query",
 			query: NewConjunctionQuery([]search.Query{
-				NewTermQuery([]byte("fruit"), []byte("apple")),
+				MustCreateTermQuery([]byte("fruit"), []byte("apple")),
 				NewConjunctionQuery([]search.Query{
-					NewTermQuery([]byte("fruit"), []byte("banana")),
+					MustCreateTermQuery([]byte("fruit"), []byte("banana")),
 				}),
 				NewDisjunctionQuery([]search.Query{
-					NewTermQuery([]byte("fruit"), []byte("orange")),
+					MustCreateTermQuery([]byte("fruit"), []byte("orange")),
 				}),
-				NewNegationQuery(NewTermQuery([]byte("fruit"), []byte("pear"))),
+				NewNegationQuery(MustCreateTermQuery([]byte("fruit"), []byte("pear"))),
 			}),
 		},
 	}
@@ -104,3 +171,198 @@ func TestMarshalError(t *testing.T) {
 		})
 	}
 }
+
+func TestUnmarshalError(t *testing.T) {
+	tests := []struct {
+		name  string
+		query *querypb.Query
+	}{
+		{
+			name: "nil query",
+		},
+		{
+			name:  "query without a kind",
+			query: &querypb.Query{},
+		},
+		{
+			name: "negation of nil query",
+			query: &querypb.Query{
+				Query: &querypb.Query_Negation{Negation: &querypb.NegationQuery{}},
+			},
+		},
+		{
+			name: "conjunction containing nil query",
+			query: &querypb.Query{
+				Query: &querypb.Query_Conjunction{Conjunction: &querypb.ConjunctionQuery{
+					Queries: []*querypb.Query{
+						MustCreateTermQuery([]byte("fruit"), []byte("apple")).ToProto(),
+						nil,
+					},
+				}},
+			},
+		},
+		{
+			name: "invalid regexp",
+			query: &querypb.Query{
+				Query: &querypb.Query_Regexp{Regexp: &querypb.RegexpQuery{
+					Field:  []byte("fruit"),
+					Regexp: []byte("(app"),
+				}},
+			},
+		},
+		{
+			name: "nested invalid regexp",
+			query: NewDisjunctionQuery([]search.Query{
+				MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+				NewNegationQuery(&RegexpQuery{field: []byte("fruit"), regexp: []byte("a.*?")}),
+			}).ToProto(),
+		},
+		{
+			name: "term query without a field",
+			query: &querypb.Query{
+				Query: &querypb.Query_Term{Term: &querypb.TermQuery{
+					Term: []byte("apple"),
+				}},
+			},
+		},
+		{
+			name: "field query with an empty field",
+			query: &querypb.Query{
+				Query: &querypb.Query_Field{Field: &querypb.FieldQuery{
+					Field: []byte{},
+				}},
+			},
+		},
+		{
+			name:  "encoded query with an empty field",
+			query: (&PrefixQuery{field: []byte{}, prefix: []byte("app")}).ToProto(),
+		},
+		{
+			name: "invalid numeric range",
+			query: &querypb.Query{
+				Query: &querypb.Query_Range{Range: &querypb.RangeQuery{
+					Field:   []byte("shard"),
+					Lower:   []byte("ten"),
+					Numeric: true,
+				}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := unmarshal(test.query)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestUnmarshalUnknownQuery(t *testing.T) {
+	// A query with an unrecognised field number, as if encoded by a newer version which
+	// supports additional kinds of queries.
+	data := []byte{0x9a, 0x06, 0x00}
+
+	_, err := Unmarshal(data)
+	require.Error(t, err)
+	_, ok := err.(UnknownQueryError)
+	require.True(t, ok, "unexpected error: %v", err)
+}
+
+func TestUnmarshalRandomBytes(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	valid, err := Marshal(newRandomQuery(rng, 4))
+	require.NoError(t, err)
+
+	for i := 0; i < 10000; i++ {
+		data := make([]byte, len(valid))
+		copy(data, valid)
+		// Corrupt a few bytes of a valid query, the result must never panic.
+		for j := 0; j < 1+rng.Intn(3); j++ {
+			data[rng.Intn(len(data))] = byte(rng.Intn(256))
+		}
+		require.NotPanics(t, func() {
+			Unmarshal(data)
+		})
+	}
+}
+
+func TestMarshalRoundTripNested(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	for i := 0; i < 500; i++ {
+		q := newRandomQuery(rng, 6)
+
+		data, err := Marshal(q)
+		require.NoError(t, err)
+		cpy, err := Unmarshal(data)
+		require.NoError(t, err)
+		require.True(t, q.Equal(cpy), "%s != %s", q, cpy)
+	}
+}
+
+func TestMarshalRoundTripDeeplyNested(t *testing.T) {
+	q := MustCreateTermQuery([]byte("fruit"), []byte("apple"))
+	for i := 0; i < 100; i++ {
+		switch i % 3 {
+		case 0:
+			q = NewNegationQuery(q)
+		case 1:
+			q = NewDisjunctionQuery([]search.Query{q, MustCreateTermQuery([]byte("depth"), []byte(fmt.Sprint(i)))})
+		case 2:
+			q = NewConjunctionQuery([]search.Query{MustCreateTermQuery([]byte("depth"), []byte(fmt.Sprint(i))), q})
+		}
+	}
+
+	data, err := Marshal(q)
+	require.NoError(t, err)
+	cpy, err := Unmarshal(data)
+	require.NoError(t, err)
+	require.True(t, q.Equal(cpy))
+}
+
+// newRandomQuery returns a random query, nesting composite queries up to the given depth.
+func newRandomQuery(rng *rand.Rand, depth int) search.Query {
+	var (
+		field = []byte(fmt.Sprintf("field%d", rng.Intn(3)))
+		value = []byte(fmt.Sprintf("value%d", rng.Intn(10)))
+	)
+
+	kinds := 9
+	if depth > 0 {
+		kinds = 12
+	}
+	switch rng.Intn(kinds) {
+	case 0:
+		return MustCreateTermQuery(field, value)
+	case 1:
+		return MustCreateRegexpQuery(field, append(value, []byte(".*")...))
+	case 2:
+		return MustCreateFuzzyQuery(field, value, 1+rng.Intn(2))
+	case 3:
+		return MustCreatePrefixQuery(field, value)
+	case 4:
+		return MustCreateWildcardQuery(field, append(value, '*'))
+	case 5:
+		return MustCreateRangeQuery(field, value, nil, rng.Intn(2) == 0, rng.Intn(2) == 0)
+	case 6:
+		lower := []byte(fmt.Sprint(rng.Intn(100)))
+		return MustCreateNumericRangeQuery(field, lower, nil, rng.Intn(2) == 0, false)
+	case 7:
+		if rng.Intn(2) == 0 {
+			return MustCreateFieldQuery(field)
+		}
+		return NewMatchAllQuery()
+	case 8:
+		return NewMatchNoneQuery()
+	case 9:
+		return NewNegationQuery(newRandomQuery(rng, depth-1))
+	}
+
+	qs := make([]search.Query, 0, 4)
+	for i := 0; i < 1+rng.Intn(4); i++ {
+		qs = append(qs, newRandomQuery(rng, depth-1))
+	}
+	if rng.Intn(2) == 0 {
+		return NewConjunctionQuery(qs)
+	}
+	return NewDisjunctionQuery(qs)
+}
@@ -21,6 +21,7 @@
 package query
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/m3db/m3/src/m3ninx/generated/proto/querypb"
@@ -28,6 +29,8 @@ import (
 	"github.com/m3db/m3/src/m3ninx/search/searcher"
 )
 
+var errNegationsOnly = errors.New("conjunction query must contain at least one non-negation query")
+
 // ConjuctionQuery finds documents which match at least one of the given queries.
 type ConjuctionQuery struct {
 	queries   []search.Query
@@ -35,7 +38,8 @@ type ConjuctionQuery struct {
 }
 
 // NewConjunctionQuery constructs a new query which matches documents which match all
-// of the given queries.
+// of the given queries. Equivalent queries are only included once, and the conjunction
+// is simplified in the presence of MatchAllQuery and MatchNoneQuery queries.
 func NewConjunctionQuery(queries []search.Query) search.Query {
 	qs := make([]search.Query, 0, len(queries))
 	ns := make([]search.Query, 0, len(queries))
@@ -44,6 +48,7 @@ func NewConjunctionQuery(queries []search.Query) search.Query {
 		case *ConjuctionQuery:
 			// Merge conjunction queries into slice of top-level queries.
 			qs = append(qs, query.queries...)
+			ns = append(ns, query.negations...)
 			continue
 		case *NegationQuery:
 			ns = append(ns, query.query)
@@ -52,32 +57,66 @@ func NewConjunctionQuery(queries []search.Query) search.Query {
 		}
 	}
 
-	if len(qs) == 0 && len(ns) > 0 {
-		// We need to ensure we have at least one non-negation query from which we can calculate
-		// the set difference with the other negation queries.
-		qs = append(qs, queries[0])
-		ns = ns[1:]
+	// Documents must match every query, so a query which matches no documents means the
+	// conjunction can't match anything whereas a query which matches every document has
+	// no effect on the result and can be dropped.
+	var matchAll bool
+	filtered := qs[:0]
+	for _, query := range qs {
+		switch query.(type) {
+		case *MatchNoneQuery:
+			return NewMatchNoneQuery()
+		case *MatchAllQuery:
+			matchAll = true
+			continue
+		}
+		filtered = append(filtered, query)
+	}
+	qs = filtered
+
+	filtered = ns[:0]
+	for _, query := range ns {
+		switch query.(type) {
+		case *MatchAllQuery:
+			return NewMatchNoneQuery()
+		case *MatchNoneQuery:
+			matchAll = true
+			continue
+		}
+		filtered = append(filtered, query)
+	}
+	ns = filtered
+
+	if len(qs) == 0 && matchAll {
+		// Only queries matching every document were provided, so retain one of them to
+		// ensure the conjunction still matches every document (less any negations).
+		qs = append(qs, NewMatchAllQuery())
 	}
 
 	return &ConjuctionQuery{
-		queries:   qs,
-		negations: ns,
+		queries:   dedupe(qs),
+		negations: dedupe(ns),
 	}
 }
 
 // Searcher returns a searcher over the provided readers.
-func (q *ConjuctionQuery) Searcher() (search.Searcher, error) {
+func (q *ConjuctionQuery) Searcher(opts search.Options) (search.Searcher, error) {
 	switch {
+	case len(q.queries) == 0 && len(q.negations) > 0:
+		// A conjunction of only negations requires a full scan of every reader so we
+		// require at least one non-negation query to calculate the set difference from.
+		return nil, errNegationsOnly
+
 	case len(q.queries) == 0:
 		return searcher.NewEmptySearcher(), nil
 
 	case len(q.queries) == 1 && len(q.negations) == 0:
-		return q.queries[0].Searcher()
+		return q.queries[0].Searcher(opts)
 	}
 
 	qsrs := make(search.Searchers, 0, len(q.queries))
 	for _, q := range q.queries {
-		sr, err := q.Searcher()
+		sr, err := search.NewSearcher(q, opts)
 		if err != nil {
 			return nil, err
 		}
@@ -86,7 +125,7 @@ func (q *ConjuctionQuery) Searcher() (search.Searcher, error) {
 
 	nsrs := make(search.Searchers, 0, len(q.negations))
 	for _, q := range q.negations {
-		sr, err := q.Searcher()
+		sr, err := search.NewSearcher(q, opts)
 		if err != nil {
 			return nil, err
 		}
@@ -96,10 +135,13 @@ func (q *ConjuctionQuery) Searcher() (search.Searcher, error) {
 	return searcher.NewConjunctionSearcher(qsrs, nsrs)
 }
 
-// Equal reports whether q is equivalent to o.
+// Equal reports whether q is equivalent to o. The order of the queries is not significant.
 func (q *ConjuctionQuery) Equal(o search.Query) bool {
-	if len(q.queries) == 1 && len(q.negations) == 0 {
-		return q.queries[0].Equal(o)
+	if s, ok := singular(q); ok {
+		return s.Equal(o)
+	}
+	if s, ok := singular(o); ok {
+		o = s
 	}
 
 	inner, ok := o.(*ConjuctionQuery)
@@ -107,28 +149,17 @@ func (q *ConjuctionQuery) Equal(o search.Query) bool {
 		return false
 	}
 
-	if len(q.queries) != len(inner.queries) {
-		return false
-	}
-
-	if len(q.negations) != len(inner.negations) {
-		return false
-	}
-
-	// TODO: Should order matter?
-	for i := range q.queries {
-		if !q.queries[i].Equal(inner.queries[i]) {
-			return false
-		}
-	}
+	return equalUnordered(q.queries, inner.queries) &&
+		equalUnordered(q.negations, inner.negations)
+}
 
-	for i := range q.negations {
-		if !q.negations[i].Equal(inner.negations[i]) {
-			return false
-		}
+// Hash returns a stable hash of the query.
+func (q *ConjuctionQuery) Hash() uint64 {
+	if s, ok := singular(q); ok {
+		return s.Hash()
 	}
 
-	return true
+	return hashQuery(conjunctionQueryKind, hashUnordered(q.queries), hashUnordered(q.negations))
 }
 
 // ToProto returns the Protobuf query struct corresponding to the conjunction query.
@@ -151,5 +182,10 @@ func (q *ConjuctionQuery) ToProto() *querypb.Query {
 }
 
 func (q *ConjuctionQuery) String() string {
-	return fmt.Sprintf("conjunction(%s)", join(q.queries))
+	qs := make([]search.Query, 0, len(q.queries)+len(q.negations))
+	qs = append(qs, q.queries...)
+	for _, qry := range q.negations {
+		qs = append(qs, NewNegationQuery(qry))
+	}
+	return fmt.Sprintf("conjunction(%s)", join(qs))
 }
@@ -39,28 +39,39 @@ func TestConjunctionQuery(t *testing.T) {
 		{
 			name: "a single query provided",
 			queries: []search.Query{
-				NewTermQuery([]byte("fruit"), []byte("apple")),
+				MustCreateTermQuery([]byte("fruit"), []byte("apple")),
 			},
 		},
 		{
 			name: "multiple queries provided",
 			queries: []search.Query{
-				NewTermQuery([]byte("fruit"), []byte("apple")),
-				NewTermQuery([]byte("vegetable"), []byte("carrot")),
+				MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+				MustCreateTermQuery([]byte("vegetable"), []byte("carrot")),
 			},
 		},
 		{
 			name: "multiple queries including negations",
 			queries: []search.Query{
-				NewTermQuery([]byte("fruit"), []byte("apple")),
-				NewTermQuery([]byte("vegetable"), []byte("carrot")),
-				NewNegationQuery(NewTermQuery([]byte("fruit"), []byte("banana"))),
+				MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+				MustCreateTermQuery([]byte("vegetable"), []byte("carrot")),
+				NewNegationQuery(MustCreateTermQuery([]byte("fruit"), []byte("banana"))),
 			},
 		},
 		{
-			name: "single negation query",
+			name: "negation of a regexp query",
+			queries: []search.Query{
+				MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+				NewNegationQuery(MustCreateRegexpQuery([]byte("color"), []byte("gr.*"))),
+			},
+		},
+		{
+			name: "negation of a disjunction query",
 			queries: []search.Query{
-				NewNegationQuery(NewTermQuery([]byte("fruit"), []byte("banana"))),
+				MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+				NewNegationQuery(NewDisjunctionQuery([]search.Query{
+					MustCreateTermQuery([]byte("color"), []byte("green")),
+					MustCreateRegexpQuery([]byte("color"), []byte("r.*")),
+				})),
 			},
 		},
 	}
@@ -68,12 +79,58 @@ func TestConjunctionQuery(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			q := NewConjunctionQuery(test.queries)
-			_, err := q.Searcher()
+			_, err := q.Searcher(search.NewOptions())
 			require.NoError(t, err)
 		})
 	}
 }
 
+func TestConjunctionQueryNegationsOnly(t *testing.T) {
+	tests := []struct {
+		name    string
+		queries []search.Query
+	}{
+		{
+			name: "single negation query",
+			queries: []search.Query{
+				NewNegationQuery(MustCreateTermQuery([]byte("fruit"), []byte("banana"))),
+			},
+		},
+		{
+			name: "multiple negation queries",
+			queries: []search.Query{
+				NewNegationQuery(MustCreateTermQuery([]byte("fruit"), []byte("banana"))),
+				NewNegationQuery(MustCreateRegexpQuery([]byte("color"), []byte("r.*"))),
+			},
+		},
+		{
+			name: "nested conjunction of negation queries",
+			queries: []search.Query{
+				NewNegationQuery(MustCreateTermQuery([]byte("fruit"), []byte("banana"))),
+				NewConjunctionQuery([]search.Query{
+					NewNegationQuery(MustCreateTermQuery([]byte("fruit"), []byte("apple"))),
+				}),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q := NewConjunctionQuery(test.queries)
+			_, err := q.Searcher(search.NewOptions())
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestConjunctionQueryString(t *testing.T) {
+	q := NewConjunctionQuery([]search.Query{
+		MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+		NewNegationQuery(MustCreateTermQuery([]byte("fruit"), []byte("banana"))),
+	})
+	require.Equal(t, "conjunction(term(fruit, apple), negation(term(fruit, banana)))", q.String())
+}
+
 func TestConjunctionQueryEqual(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -89,34 +146,51 @@ func TestConjunctionQueryEqual(t *testing.T) {
 		{
 			name: "equal queries",
 			left: NewConjunctionQuery([]search.Query{
-				NewTermQuery([]byte("fruit"), []byte("apple")),
-				NewNegationQuery(NewTermQuery([]byte("fruit"), []byte("banana"))),
+				MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+				NewNegationQuery(MustCreateTermQuery([]byte("fruit"), []byte("banana"))),
 			}),
 			right: NewConjunctionQuery([]search.Query{
-				NewTermQuery([]byte("fruit"), []byte("apple")),
-				NewNegationQuery(NewTermQuery([]byte("fruit"), []byte("banana"))),
+				MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+				NewNegationQuery(MustCreateTermQuery([]byte("fruit"), []byte("banana"))),
 			}),
 			expected: true,
 		},
 		{
 			name: "single query",
 			left: NewConjunctionQuery([]search.Query{
-				NewTermQuery([]byte("fruit"), []byte("apple")),
+				MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+			}),
+			right:    MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+			expected: true,
+		},
+		{
+			name: "single negation query",
+			left: NewConjunctionQuery([]search.Query{
+				NewNegationQuery(MustCreateTermQuery([]byte("fruit"), []byte("apple"))),
 			}),
-			right:    NewTermQuery([]byte("fruit"), []byte("apple")),
+			right:    NewNegationQuery(MustCreateTermQuery([]byte("fruit"), []byte("apple"))),
 			expected: true,
 		},
+		{
+			name: "single query with negation",
+			left: NewConjunctionQuery([]search.Query{
+				MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+				NewNegationQuery(MustCreateTermQuery([]byte("fruit"), []byte("banana"))),
+			}),
+			right:    MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+			expected: false,
+		},
 		{
 			name: "different order",
 			left: NewConjunctionQuery([]search.Query{
-				NewTermQuery([]byte("fruit"), []byte("apple")),
-				NewTermQuery([]byte("fruit"), []byte("banana")),
+				MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+				MustCreateTermQuery([]byte("fruit"), []byte("banana")),
 			}),
 			right: NewConjunctionQuery([]search.Query{
-				NewTermQuery([]byte("fruit"), []byte("banana")),
-				NewTermQuery([]byte("fruit"), []byte("apple")),
+				MustCreateTermQuery([]byte("fruit"), []byte("banana")),
+				MustCreateTermQuery([]byte("fruit"), []byte("apple")),
 			}),
-			expected: false,
+			expected: true,
 		},
 	}
 
@@ -126,3 +200,86 @@ func TestConjunctionQueryEqual(t *testing.T) {
 		})
 	}
 }
+
+func TestConjunctionQueryDedupesChildren(t *testing.T) {
+	q := NewConjunctionQuery([]search.Query{
+		MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+		MustCreateRegexpQuery([]byte("fruit"), []byte("ban.*")),
+		MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+		NewConjunctionQuery([]search.Query{
+			MustCreateRegexpQuery([]byte("fruit"), []byte("ban.*")),
+			MustCreateTermQuery([]byte("fruit"), []byte("cherry")),
+		}),
+	})
+
+	inner, ok := q.(*ConjuctionQuery)
+	require.True(t, ok)
+	require.Len(t, inner.queries, 3)
+}
+
+func TestConjunctionQueryMatchAllAndNone(t *testing.T) {
+	var (
+		apple  = MustCreateTermQuery([]byte("fruit"), []byte("apple"))
+		banana = MustCreateTermQuery([]byte("fruit"), []byte("banana"))
+	)
+
+	tests := []struct {
+		name     string
+		queries  []search.Query
+		expected search.Query
+	}{
+		{
+			name:     "match none query",
+			queries:  []search.Query{apple, NewMatchNoneQuery(), banana},
+			expected: NewMatchNoneQuery(),
+		},
+		{
+			name:     "negated match all query",
+			queries:  []search.Query{apple, NewNegationQuery(NewMatchAllQuery())},
+			expected: NewMatchNoneQuery(),
+		},
+		{
+			name: "nested match none query",
+			queries: []search.Query{
+				apple,
+				NewConjunctionQuery([]search.Query{banana, NewMatchNoneQuery()}),
+			},
+			expected: NewMatchNoneQuery(),
+		},
+		{
+			name:     "match all query is dropped",
+			queries:  []search.Query{apple, NewMatchAllQuery(), banana},
+			expected: NewConjunctionQuery([]search.Query{apple, banana}),
+		},
+		{
+			name:     "negated match none query is dropped",
+			queries:  []search.Query{apple, NewNegationQuery(NewMatchNoneQuery())},
+			expected: apple,
+		},
+		{
+			name:     "only match all queries",
+			queries:  []search.Query{NewMatchAllQuery(), NewMatchAllQuery()},
+			expected: NewMatchAllQuery(),
+		},
+		{
+			name: "nested only match all queries",
+			queries: []search.Query{
+				NewConjunctionQuery([]search.Query{NewMatchAllQuery()}),
+				NewNegationQuery(apple),
+			},
+			expected: NewConjunctionQuery([]search.Query{
+				NewMatchAllQuery(),
+				NewNegationQuery(apple),
+			}),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q := NewConjunctionQuery(test.queries)
+			require.True(t, q.Equal(test.expected), "%s != %s", q, test.expected)
+			_, err := q.Searcher(search.NewOptions())
+			require.NoError(t, err)
+		})
+	}
+}
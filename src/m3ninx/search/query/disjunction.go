@@ -34,7 +34,7 @@ type DisjuctionQuery struct {
 }
 
 // NewDisjunctionQuery constructs a new query which matches documents that match any
-// of the given queries.
+// of the given queries. Equivalent queries are only included once.
 func NewDisjunctionQuery(queries []search.Query) search.Query {
 	qs := make([]search.Query, 0, len(queries))
 	for _, query := range queries {
@@ -48,23 +48,23 @@ func NewDisjunctionQuery(queries []search.Query) search.Query {
 		qs = append(qs, query)
 	}
 	return &DisjuctionQuery{
-		queries: qs,
+		queries: dedupe(qs),
 	}
 }
 
 // Searcher returns a searcher over the provided readers.
-func (q *DisjuctionQuery) Searcher() (search.Searcher, error) {
+func (q *DisjuctionQuery) Searcher(opts search.Options) (search.Searcher, error) {
 	switch len(q.queries) {
 	case 0:
 		return searcher.NewEmptySearcher(), nil
 
 	case 1:
-		return q.queries[0].Searcher()
+		return q.queries[0].Searcher(opts)
 	}
 
 	srs := make(search.Searchers, 0, len(q.queries))
 	for _, q := range q.queries {
-		sr, err := q.Searcher()
+		sr, err := search.NewSearcher(q, opts)
 		if err != nil {
 			return nil, err
 		}
@@ -74,10 +74,13 @@ func (q *DisjuctionQuery) Searcher() (search.Searcher, error) {
 	return searcher.NewDisjunctionSearcher(srs)
 }
 
-// Equal reports whether q is equivalent to o.
+// Equal reports whether q is equivalent to o. The order of the queries is not significant.
 func (q *DisjuctionQuery) Equal(o search.Query) bool {
-	if len(q.queries) == 1 {
-		return q.queries[0].Equal(o)
+	if s, ok := singular(q); ok {
+		return s.Equal(o)
+	}
+	if s, ok := singular(o); ok {
+		o = s
 	}
 
 	inner, ok := o.(*DisjuctionQuery)
@@ -85,17 +88,16 @@ func (q *DisjuctionQuery) Equal(o search.Query) bool {
 		return false
 	}
 
-	if len(q.queries) != len(inner.queries) {
-		return false
-	}
+	return equalUnordered(q.queries, inner.queries)
+}
 
-	// TODO: Should order matter?
-	for i := range q.queries {
-		if !q.queries[i].Equal(inner.queries[i]) {
-			return false
-		}
+// Hash returns a stable hash of the query.
+func (q *DisjuctionQuery) Hash() uint64 {
+	if s, ok := singular(q); ok {
+		return s.Hash()
 	}
-	return true
+
+	return hashQuery(disjunctionQueryKind, hashUnordered(q.queries))
 }
 
 // ToProto returns the Protobuf query struct corresponding to the disjunction query.
@@ -39,14 +39,14 @@ func TestDisjunctionQuery(t *testing.T) {
 		{
 			name: "a single query provided",
 			queries: []search.Query{
-				NewTermQuery([]byte("fruit"), []byte("apple")),
+				MustCreateTermQuery([]byte("fruit"), []byte("apple")),
 			},
 		},
 		{
 			name: "multiple queries provided",
 			queries: []search.Query{
-				NewTermQuery([]byte("fruit"), []byte("apple")),
-				NewTermQuery([]byte("vegetable"), []byte("carrot")),
+				MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+				MustCreateTermQuery([]byte("vegetable"), []byte("carrot")),
 			},
 		},
 	}
@@ -54,7 +54,7 @@ func TestDisjunctionQuery(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			q := NewDisjunctionQuery(test.queries)
-			_, err := q.Searcher()
+			_, err := q.Searcher(search.NewOptions())
 			require.NoError(t, err)
 		})
 	}
@@ -75,34 +75,34 @@ func TestDisjunctionQueryEqual(t *testing.T) {
 		{
 			name: "equal queries",
 			left: NewDisjunctionQuery([]search.Query{
-				NewTermQuery([]byte("fruit"), []byte("apple")),
-				NewTermQuery([]byte("fruit"), []byte("banana")),
+				MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+				MustCreateTermQuery([]byte("fruit"), []byte("banana")),
 			}),
 			right: NewDisjunctionQuery([]search.Query{
-				NewTermQuery([]byte("fruit"), []byte("apple")),
-				NewTermQuery([]byte("fruit"), []byte("banana")),
+				MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+				MustCreateTermQuery([]byte("fruit"), []byte("banana")),
 			}),
 			expected: true,
 		},
 		{
 			name: "single query",
 			left: NewDisjunctionQuery([]search.Query{
-				NewTermQuery([]byte("fruit"), []byte("apple")),
+				MustCreateTermQuery([]byte("fruit"), []byte("apple")),
 			}),
-			right:    NewTermQuery([]byte("fruit"), []byte("apple")),
+			right:    MustCreateTermQuery([]byte("fruit"), []byte("apple")),
 			expected: true,
 		},
 		{
 			name: "different order",
 			left: NewDisjunctionQuery([]search.Query{
-				NewTermQuery([]byte("fruit"), []byte("apple")),
-				NewTermQuery([]byte("fruit"), []byte("banana")),
+				MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+				MustCreateTermQuery([]byte("fruit"), []byte("banana")),
 			}),
 			right: NewDisjunctionQuery([]search.Query{
-				NewTermQuery([]byte("fruit"), []byte("banana")),
-				NewTermQuery([]byte("fruit"), []byte("apple")),
+				MustCreateTermQuery([]byte("fruit"), []byte("banana")),
+				MustCreateTermQuery([]byte("fruit"), []byte("apple")),
 			}),
-			expected: false,
+			expected: true,
 		},
 	}
 
@@ -112,3 +112,19 @@ func TestDisjunctionQueryEqual(t *testing.T) {
 		})
 	}
 }
+
+func TestDisjunctionQueryDedupesChildren(t *testing.T) {
+	q := NewDisjunctionQuery([]search.Query{
+		MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+		MustCreateRegexpQuery([]byte("fruit"), []byte("ban.*")),
+		MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+		NewDisjunctionQuery([]search.Query{
+			MustCreateRegexpQuery([]byte("fruit"), []byte("ban.*")),
+			MustCreateTermQuery([]byte("fruit"), []byte("cherry")),
+		}),
+	})
+
+	inner, ok := q.(*DisjuctionQuery)
+	require.True(t, ok)
+	require.Len(t, inner.queries, 3)
+}
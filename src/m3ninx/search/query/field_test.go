@@ -0,0 +1,90 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package query
+
+import (
+	"testing"
+
+	"github.com/m3db/m3/src/m3ninx/search"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldQuery(t *testing.T) {
+	q := MustCreateFieldQuery([]byte("fruit"))
+	_, err := q.Searcher(search.NewOptions())
+	require.NoError(t, err)
+}
+
+func TestFieldQueryEqual(t *testing.T) {
+	tests := []struct {
+		name        string
+		left, right search.Query
+		expected    bool
+	}{
+		{
+			name:     "same field",
+			left:     MustCreateFieldQuery([]byte("fruit")),
+			right:    MustCreateFieldQuery([]byte("fruit")),
+			expected: true,
+		},
+		{
+			name: "singular disjunction query",
+			left: MustCreateFieldQuery([]byte("fruit")),
+			right: NewDisjunctionQuery([]search.Query{
+				MustCreateFieldQuery([]byte("fruit")),
+			}),
+			expected: true,
+		},
+		{
+			name:     "different field",
+			left:     MustCreateFieldQuery([]byte("fruit")),
+			right:    MustCreateFieldQuery([]byte("food")),
+			expected: false,
+		},
+		{
+			name:     "term query with same field",
+			left:     MustCreateFieldQuery([]byte("fruit")),
+			right:    MustCreateTermQuery([]byte("fruit"), []byte("app")),
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, test.left.Equal(test.right))
+		})
+	}
+}
+
+func TestFieldQueryNegation(t *testing.T) {
+	q := NewConjunctionQuery([]search.Query{
+		MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+		NewNegationQuery(MustCreateFieldQuery([]byte("color"))),
+	})
+	_, err := q.Searcher(search.NewOptions())
+	require.NoError(t, err)
+}
+
+func TestFieldQueryString(t *testing.T) {
+	q := MustCreateFieldQuery([]byte("fruit"))
+	require.Equal(t, "field(fruit)", q.String())
+}
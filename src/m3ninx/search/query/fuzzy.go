@@ -0,0 +1,114 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package query
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/m3db/m3/src/m3ninx/generated/proto/querypb"
+	"github.com/m3db/m3/src/m3ninx/index"
+	"github.com/m3db/m3/src/m3ninx/search"
+	"github.com/m3db/m3/src/m3ninx/search/searcher"
+)
+
+// FuzzyQuery finds documents which have a term within a maximum edit distance of
+// the given term.
+type FuzzyQuery struct {
+	field           []byte
+	term            []byte
+	maxEditDistance int
+	compiled        index.CompiledFuzzy
+}
+
+// NewFuzzyQuery constructs a new query for terms within the given maximum edit distance,
+// which must be 1 or 2, of the given term. A nil field searches the reserved ID field,
+// whereas an empty field is returned as an error.
+func NewFuzzyQuery(field, term []byte, maxEditDistance int) (search.Query, error) {
+	field, err := resolveField(field)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled, err := index.CompileFuzzy(term, maxEditDistance)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FuzzyQuery{
+		field:           field,
+		term:            term,
+		maxEditDistance: maxEditDistance,
+		compiled:        compiled,
+	}, nil
+}
+
+// MustCreateFuzzyQuery is like NewFuzzyQuery but panics if the query cannot be created.
+func MustCreateFuzzyQuery(field, term []byte, maxEditDistance int) search.Query {
+	q, err := NewFuzzyQuery(field, term, maxEditDistance)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// Searcher returns a searcher over the provided readers.
+func (q *FuzzyQuery) Searcher(opts search.Options) (search.Searcher, error) {
+	return searcher.NewFuzzySearcher(q.field, q.compiled), nil
+}
+
+// Equal reports whether q is equivalent to o.
+func (q *FuzzyQuery) Equal(o search.Query) bool {
+	o, ok := singular(o)
+	if !ok {
+		return false
+	}
+
+	inner, ok := o.(*FuzzyQuery)
+	if !ok {
+		return false
+	}
+
+	return bytes.Equal(q.field, inner.field) && bytes.Equal(q.term, inner.term) &&
+		q.maxEditDistance == inner.maxEditDistance
+}
+
+// Hash returns a stable hash of the query.
+func (q *FuzzyQuery) Hash() uint64 {
+	return hashQuery(fuzzyQueryKind, hashBytes(q.field), hashBytes(q.term), uint64(q.maxEditDistance))
+}
+
+// ToProto returns the Protobuf query struct corresponding to the fuzzy query.
+func (q *FuzzyQuery) ToProto() *querypb.Query {
+	fuzzy := querypb.FuzzyQuery{
+		Field:           q.field,
+		Term:            q.term,
+		MaxEditDistance: int32(q.maxEditDistance),
+	}
+
+	return &querypb.Query{
+		Query: &querypb.Query_Fuzzy{Fuzzy: &fuzzy},
+	}
+}
+
+func (q *FuzzyQuery) String() string {
+	return fmt.Sprintf("fuzzy(%s, %s, %d)", q.field, q.term, q.maxEditDistance)
+}
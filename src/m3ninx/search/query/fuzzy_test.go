@@ -0,0 +1,121 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package query
+
+import (
+	"testing"
+
+	"github.com/m3db/m3/src/m3ninx/search"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFuzzyQuery(t *testing.T) {
+	tests := []struct {
+		name            string
+		field, term     []byte
+		maxEditDistance int
+		expectErr       bool
+	}{
+		{
+			name:            "valid edit distance should not return an error",
+			field:           []byte("fruit"),
+			term:            []byte("aple"),
+			maxEditDistance: 2,
+			expectErr:       false,
+		},
+		{
+			name:            "zero edit distance should return an error",
+			field:           []byte("fruit"),
+			term:            []byte("aple"),
+			maxEditDistance: 0,
+			expectErr:       true,
+		},
+		{
+			name:            "edit distance above two should return an error",
+			field:           []byte("fruit"),
+			term:            []byte("aple"),
+			maxEditDistance: 3,
+			expectErr:       true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q, err := NewFuzzyQuery(test.field, test.term, test.maxEditDistance)
+
+			if test.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			_, err = q.Searcher(search.NewOptions())
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestFuzzyQueryEqual(t *testing.T) {
+	tests := []struct {
+		name        string
+		left, right search.Query
+		expected    bool
+	}{
+		{
+			name:     "same field, term and edit distance",
+			left:     MustCreateFuzzyQuery([]byte("fruit"), []byte("aple"), 1),
+			right:    MustCreateFuzzyQuery([]byte("fruit"), []byte("aple"), 1),
+			expected: true,
+		},
+		{
+			name: "singular conjunction query",
+			left: MustCreateFuzzyQuery([]byte("fruit"), []byte("aple"), 1),
+			right: NewConjunctionQuery([]search.Query{
+				MustCreateFuzzyQuery([]byte("fruit"), []byte("aple"), 1),
+			}),
+			expected: true,
+		},
+		{
+			name:     "different term",
+			left:     MustCreateFuzzyQuery([]byte("fruit"), []byte("aple"), 1),
+			right:    MustCreateFuzzyQuery([]byte("fruit"), []byte("banan"), 1),
+			expected: false,
+		},
+		{
+			name:     "different edit distance",
+			left:     MustCreateFuzzyQuery([]byte("fruit"), []byte("aple"), 1),
+			right:    MustCreateFuzzyQuery([]byte("fruit"), []byte("aple"), 2),
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, test.left.Equal(test.right))
+		})
+	}
+}
+
+func TestFuzzyQueryString(t *testing.T) {
+	q := MustCreateFuzzyQuery([]byte("fruit"), []byte("aple"), 2)
+	require.Equal(t, "fuzzy(fruit, aple, 2)", q.String())
+}
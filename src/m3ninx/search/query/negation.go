@@ -41,8 +41,8 @@ func NewNegationQuery(q search.Query) search.Query {
 }
 
 // Searcher returns a searcher over the provided readers.
-func (q *NegationQuery) Searcher() (search.Searcher, error) {
-	s, err := q.query.Searcher()
+func (q *NegationQuery) Searcher(opts search.Options) (search.Searcher, error) {
+	s, err := search.NewSearcher(q.query, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -64,6 +64,11 @@ func (q *NegationQuery) Equal(o search.Query) bool {
 	return q.query.Equal(inner.query)
 }
 
+// Hash returns a stable hash of the query.
+func (q *NegationQuery) Hash() uint64 {
+	return hashQuery(negationQueryKind, q.query.Hash())
+}
+
 // ToProto returns the Protobuf query struct corresponding to the term query.
 func (q *NegationQuery) ToProto() *querypb.Query {
 	inner := q.query.ToProto()
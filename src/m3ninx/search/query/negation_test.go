@@ -35,14 +35,25 @@ func TestNegationQuery(t *testing.T) {
 	}{
 		{
 			name:  "valid query",
-			query: NewTermQuery([]byte("fruit"), []byte("apple")),
+			query: MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+		},
+		{
+			name:  "regexp query",
+			query: MustCreateRegexpQuery([]byte("fruit"), []byte("app.*")),
+		},
+		{
+			name: "disjunction query",
+			query: NewDisjunctionQuery([]search.Query{
+				MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+				MustCreateRegexpQuery([]byte("fruit"), []byte("ban.*")),
+			}),
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			q := NewNegationQuery(test.query)
-			_, err := q.Searcher()
+			_, err := q.Searcher(search.NewOptions())
 			require.NoError(t, err)
 		})
 	}
@@ -56,30 +67,30 @@ func TestNegationQueryEqual(t *testing.T) {
 	}{
 		{
 			name:     "same inner queries",
-			left:     NewNegationQuery(NewTermQuery([]byte("fruit"), []byte("apple"))),
-			right:    NewNegationQuery(NewTermQuery([]byte("fruit"), []byte("apple"))),
+			left:     NewNegationQuery(MustCreateTermQuery([]byte("fruit"), []byte("apple"))),
+			right:    NewNegationQuery(MustCreateTermQuery([]byte("fruit"), []byte("apple"))),
 			expected: true,
 		},
 		{
 			name: "singular conjunction query",
-			left: NewNegationQuery(NewTermQuery([]byte("fruit"), []byte("apple"))),
+			left: NewNegationQuery(MustCreateTermQuery([]byte("fruit"), []byte("apple"))),
 			right: NewConjunctionQuery([]search.Query{
-				NewNegationQuery(NewTermQuery([]byte("fruit"), []byte("apple"))),
+				NewNegationQuery(MustCreateTermQuery([]byte("fruit"), []byte("apple"))),
 			}),
 			expected: true,
 		},
 		{
 			name: "singular disjunction query",
-			left: NewNegationQuery(NewTermQuery([]byte("fruit"), []byte("apple"))),
+			left: NewNegationQuery(MustCreateTermQuery([]byte("fruit"), []byte("apple"))),
 			right: NewDisjunctionQuery([]search.Query{
-				NewNegationQuery(NewTermQuery([]byte("fruit"), []byte("apple"))),
+				NewNegationQuery(MustCreateTermQuery([]byte("fruit"), []byte("apple"))),
 			}),
 			expected: true,
 		},
 		{
 			name:     "different inner queries",
-			left:     NewNegationQuery(NewTermQuery([]byte("fruit"), []byte("apple"))),
-			right:    NewNegationQuery(NewTermQuery([]byte("fruit"), []byte("banana"))),
+			left:     NewNegationQuery(MustCreateTermQuery([]byte("fruit"), []byte("apple"))),
+			right:    NewNegationQuery(MustCreateTermQuery([]byte("fruit"), []byte("banana"))),
 			expected: false,
 		},
 	}
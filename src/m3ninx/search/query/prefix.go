@@ -0,0 +1,148 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package query
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/m3db/m3/src/m3ninx/generated/proto/querypb"
+	"github.com/m3db/m3/src/m3ninx/index"
+	"github.com/m3db/m3/src/m3ninx/search"
+	"github.com/m3db/m3/src/m3ninx/search/searcher"
+)
+
+// PrefixQuery finds documents which have a term beginning with the given prefix, or
+// beginning with it under case folding if the query is case-insensitive.
+type PrefixQuery struct {
+	field           []byte
+	prefix          []byte
+	caseInsensitive bool
+	compiled        index.CompiledFold
+}
+
+// NewPrefixQuery constructs a new PrefixQuery for the given field and prefix. A nil
+// field searches the reserved ID field, whereas an empty field is returned as an error.
+func NewPrefixQuery(field, prefix []byte) (search.Query, error) {
+	field, err := resolveField(field)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PrefixQuery{
+		field:  field,
+		prefix: prefix,
+	}, nil
+}
+
+// MustCreatePrefixQuery is like NewPrefixQuery but panics if the query cannot be created.
+func MustCreatePrefixQuery(field, prefix []byte) search.Query {
+	q, err := NewPrefixQuery(field, prefix)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// NewCaseInsensitivePrefixQuery constructs a new PrefixQuery for the given field and
+// prefix which matches terms beginning with the prefix under the given case folding.
+// The field is treated as with NewPrefixQuery.
+func NewCaseInsensitivePrefixQuery(
+	field, prefix []byte,
+	folding index.CaseFolding,
+) (search.Query, error) {
+	field, err := resolveField(field)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PrefixQuery{
+		field:           field,
+		prefix:          prefix,
+		caseInsensitive: true,
+		compiled:        index.CompileFold(prefix, true, folding),
+	}, nil
+}
+
+// MustCreateCaseInsensitivePrefixQuery is like NewCaseInsensitivePrefixQuery but panics
+// if the query cannot be created.
+func MustCreateCaseInsensitivePrefixQuery(
+	field, prefix []byte,
+	folding index.CaseFolding,
+) search.Query {
+	q, err := NewCaseInsensitivePrefixQuery(field, prefix, folding)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// Searcher returns a searcher over the provided readers.
+func (q *PrefixQuery) Searcher(opts search.Options) (search.Searcher, error) {
+	if q.caseInsensitive {
+		return searcher.NewFoldSearcher(q.field, q.compiled), nil
+	}
+	return searcher.NewPrefixSearcher(q.field, q.prefix), nil
+}
+
+// Equal reports whether q is equivalent to o.
+func (q *PrefixQuery) Equal(o search.Query) bool {
+	o, ok := singular(o)
+	if !ok {
+		return false
+	}
+
+	inner, ok := o.(*PrefixQuery)
+	if !ok {
+		return false
+	}
+
+	return bytes.Equal(q.field, inner.field) && bytes.Equal(q.prefix, inner.prefix) &&
+		equalCaseFolding(q.caseInsensitive, q.compiled.Folding, inner.caseInsensitive, inner.compiled.Folding)
+}
+
+// Hash returns a stable hash of the query.
+func (q *PrefixQuery) Hash() uint64 {
+	if q.caseInsensitive {
+		return hashQuery(prefixQueryKind, hashBytes(q.field), hashBytes(q.prefix),
+			hashBool(true), uint64(q.compiled.Folding))
+	}
+	return hashQuery(prefixQueryKind, hashBytes(q.field), hashBytes(q.prefix))
+}
+
+// ToProto returns the Protobuf query struct corresponding to the prefix query.
+func (q *PrefixQuery) ToProto() *querypb.Query {
+	prefix := querypb.PrefixQuery{
+		Field:           q.field,
+		Prefix:          q.prefix,
+		CaseInsensitive: q.caseInsensitive,
+		UnicodeFolding:  q.caseInsensitive && q.compiled.Folding == index.UnicodeCaseFolding,
+	}
+
+	return &querypb.Query{
+		Query: &querypb.Query_Prefix{Prefix: &prefix},
+	}
+}
+
+func (q *PrefixQuery) String() string {
+	name := caseSensitivityName("prefix", q.caseInsensitive, q.compiled.Folding)
+	return fmt.Sprintf("%s(%s, %s)", name, q.field, q.prefix)
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package query
+
+import (
+	"testing"
+
+	"github.com/m3db/m3/src/m3ninx/index"
+	"github.com/m3db/m3/src/m3ninx/postings/roaring"
+	"github.com/m3db/m3/src/m3ninx/search"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefixQuery(t *testing.T) {
+	q := MustCreatePrefixQuery([]byte("fruit"), []byte("app"))
+	_, err := q.Searcher(search.NewOptions())
+	require.NoError(t, err)
+}
+
+func TestPrefixQueryCaseInsensitive(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	field := []byte("fruit")
+	q := MustCreateCaseInsensitivePrefixQuery(field, []byte("APP"), index.ASCIICaseFolding)
+
+	r := index.NewMockReader(mockCtrl)
+	r.EXPECT().MatchFold(field, gomock.Any()).Do(func(_ []byte, c index.CompiledFold) {
+		require.True(t, c.Prefix)
+		require.True(t, c.Match([]byte("apple")))
+		require.True(t, c.Match([]byte("App")))
+		require.False(t, c.Match([]byte("pineapple")))
+	}).Return(roaring.NewPostingsList(), nil)
+
+	s, err := q.Searcher(search.NewOptions())
+	require.NoError(t, err)
+	_, err = s.Search(r)
+	require.NoError(t, err)
+}
+
+func TestPrefixQueryEqual(t *testing.T) {
+	tests := []struct {
+		name        string
+		left, right search.Query
+		expected    bool
+	}{
+		{
+			name:     "same field and prefix",
+			left:     MustCreatePrefixQuery([]byte("fruit"), []byte("app")),
+			right:    MustCreatePrefixQuery([]byte("fruit"), []byte("app")),
+			expected: true,
+		},
+		{
+			name: "singular disjunction query",
+			left: MustCreatePrefixQuery([]byte("fruit"), []byte("app")),
+			right: NewDisjunctionQuery([]search.Query{
+				MustCreatePrefixQuery([]byte("fruit"), []byte("app")),
+			}),
+			expected: true,
+		},
+		{
+			name:     "different field",
+			left:     MustCreatePrefixQuery([]byte("fruit"), []byte("app")),
+			right:    MustCreatePrefixQuery([]byte("food"), []byte("app")),
+			expected: false,
+		},
+		{
+			name:     "different prefix",
+			left:     MustCreatePrefixQuery([]byte("fruit"), []byte("app")),
+			right:    MustCreatePrefixQuery([]byte("fruit"), []byte("ban")),
+			expected: false,
+		},
+		{
+			name:     "case-sensitive and case-insensitive prefix",
+			left:     MustCreatePrefixQuery([]byte("fruit"), []byte("app")),
+			right:    MustCreateCaseInsensitivePrefixQuery([]byte("fruit"), []byte("app"), index.ASCIICaseFolding),
+			expected: false,
+		},
+		{
+			name:     "case-insensitive term query with same term",
+			left:     MustCreateCaseInsensitivePrefixQuery([]byte("fruit"), []byte("app"), index.ASCIICaseFolding),
+			right:    MustCreateCaseInsensitiveTermQuery([]byte("fruit"), []byte("app"), index.ASCIICaseFolding),
+			expected: false,
+		},
+		{
+			name:     "term query with same term",
+			left:     MustCreatePrefixQuery([]byte("fruit"), []byte("app")),
+			right:    MustCreateTermQuery([]byte("fruit"), []byte("app")),
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, test.left.Equal(test.right))
+		})
+	}
+}
+
+func TestPrefixQueryString(t *testing.T) {
+	q := MustCreatePrefixQuery([]byte("fruit"), []byte("app"))
+	require.Equal(t, "prefix(fruit, app)", q.String())
+
+	q = MustCreateCaseInsensitivePrefixQuery([]byte("fruit"), []byte("app"), index.ASCIICaseFolding)
+	require.Equal(t, "case_insensitive_prefix(fruit, app)", q.String())
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package query
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/m3db/m3/src/m3ninx/generated/proto/querypb"
+	"github.com/m3db/m3/src/m3ninx/index"
+	"github.com/m3db/m3/src/m3ninx/search"
+	"github.com/m3db/m3/src/m3ninx/search/searcher"
+)
+
+// RangeQuery finds documents which have a term within the given bounds. Terms are
+// compared lexically unless the query is numeric, in which case they are parsed as
+// integers and terms which can't be parsed are skipped.
+type RangeQuery struct {
+	field          []byte
+	lower          []byte
+	upper          []byte
+	lowerInclusive bool
+	upperInclusive bool
+	numeric        bool
+	compiled       index.CompiledRange
+}
+
+// NewRangeQuery constructs a new query for terms lexically within the given bounds. A
+// nil or empty bound leaves the range open-ended on that side. A nil field searches the
+// reserved ID field, whereas an empty field is returned as an error.
+func NewRangeQuery(
+	field, lower, upper []byte,
+	lowerInclusive, upperInclusive bool,
+) (search.Query, error) {
+	field, err := resolveField(field)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RangeQuery{
+		field:          field,
+		lower:          lower,
+		upper:          upper,
+		lowerInclusive: lowerInclusive,
+		upperInclusive: upperInclusive,
+		compiled:       index.CompileRange(lower, upper, lowerInclusive, upperInclusive),
+	}, nil
+}
+
+// MustCreateRangeQuery is like NewRangeQuery but panics if the query cannot be created.
+func MustCreateRangeQuery(
+	field, lower, upper []byte,
+	lowerInclusive, upperInclusive bool,
+) search.Query {
+	q, err := NewRangeQuery(field, lower, upper, lowerInclusive, upperInclusive)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// NewNumericRangeQuery constructs a new query for terms which, parsed as integers, are
+// numerically within the given bounds. A nil or empty bound leaves the range open-ended
+// on that side, any other bound must be a valid integer. The field is treated as with
+// NewRangeQuery.
+func NewNumericRangeQuery(
+	field, lower, upper []byte,
+	lowerInclusive, upperInclusive bool,
+) (search.Query, error) {
+	field, err := resolveField(field)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled, err := index.CompileNumericRange(lower, upper, lowerInclusive, upperInclusive)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RangeQuery{
+		field:          field,
+		lower:          lower,
+		upper:          upper,
+		lowerInclusive: lowerInclusive,
+		upperInclusive: upperInclusive,
+		numeric:        true,
+		compiled:       compiled,
+	}, nil
+}
+
+// MustCreateNumericRangeQuery is like NewNumericRangeQuery but panics if the query
+// cannot be created.
+func MustCreateNumericRangeQuery(
+	field, lower, upper []byte,
+	lowerInclusive, upperInclusive bool,
+) search.Query {
+	q, err := NewNumericRangeQuery(field, lower, upper, lowerInclusive, upperInclusive)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// Searcher returns a searcher over the provided readers.
+func (q *RangeQuery) Searcher(opts search.Options) (search.Searcher, error) {
+	return searcher.NewRangeSearcher(q.field, q.compiled), nil
+}
+
+// Equal reports whether q is equivalent to o.
+func (q *RangeQuery) Equal(o search.Query) bool {
+	o, ok := singular(o)
+	if !ok {
+		return false
+	}
+
+	inner, ok := o.(*RangeQuery)
+	if !ok {
+		return false
+	}
+
+	return bytes.Equal(q.field, inner.field) &&
+		bytes.Equal(q.lower, inner.lower) &&
+		bytes.Equal(q.upper, inner.upper) &&
+		q.lowerInclusive == inner.lowerInclusive &&
+		q.upperInclusive == inner.upperInclusive &&
+		q.numeric == inner.numeric
+}
+
+// Hash returns a stable hash of the query.
+func (q *RangeQuery) Hash() uint64 {
+	return hashQuery(rangeQueryKind,
+		hashBytes(q.field),
+		hashBytes(q.lower),
+		hashBytes(q.upper),
+		hashBool(q.lowerInclusive),
+		hashBool(q.upperInclusive),
+		hashBool(q.numeric),
+	)
+}
+
+// ToProto returns the Protobuf query struct corresponding to the range query.
+func (q *RangeQuery) ToProto() *querypb.Query {
+	rng := querypb.RangeQuery{
+		Field:          q.field,
+		Lower:          q.lower,
+		Upper:          q.upper,
+		LowerInclusive: q.lowerInclusive,
+		UpperInclusive: q.upperInclusive,
+		Numeric:        q.numeric,
+	}
+
+	return &querypb.Query{
+		Query: &querypb.Query_Range{Range: &rng},
+	}
+}
+
+func (q *RangeQuery) String() string {
+	name := "range"
+	if q.numeric {
+		name = "numeric_range"
+	}
+
+	var (
+		openBracket, closeBracket = "(", ")"
+		lower, upper              = "*", "*"
+	)
+	if q.lowerInclusive {
+		openBracket = "["
+	}
+	if q.upperInclusive {
+		closeBracket = "]"
+	}
+	if len(q.lower) > 0 {
+		lower = string(q.lower)
+	}
+	if len(q.upper) > 0 {
+		upper = string(q.upper)
+	}
+	return fmt.Sprintf("%s(%s, %s%s, %s%s)", name, q.field, openBracket, lower, upper, closeBracket)
+}
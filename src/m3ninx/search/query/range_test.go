@@ -0,0 +1,148 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package query
+
+import (
+	"testing"
+
+	"github.com/m3db/m3/src/m3ninx/search"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRangeQuery(t *testing.T) {
+	tests := []struct {
+		name         string
+		lower, upper []byte
+	}{
+		{
+			name:  "both bounds",
+			lower: []byte("10"),
+			upper: []byte("20"),
+		},
+		{
+			name:  "only lower bound",
+			lower: []byte("10"),
+		},
+		{
+			name:  "only upper bound",
+			upper: []byte("20"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q := MustCreateRangeQuery([]byte("shard"), test.lower, test.upper, true, false)
+			_, err := q.Searcher(search.NewOptions())
+			require.NoError(t, err)
+
+			q, err = NewNumericRangeQuery([]byte("shard"), test.lower, test.upper, true, false)
+			require.NoError(t, err)
+			_, err = q.Searcher(search.NewOptions())
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNumericRangeQueryInvalidBounds(t *testing.T) {
+	_, err := NewNumericRangeQuery([]byte("shard"), []byte("ten"), nil, true, true)
+	require.Error(t, err)
+
+	_, err = NewNumericRangeQuery([]byte("shard"), nil, []byte("1.5"), true, true)
+	require.Error(t, err)
+}
+
+func TestRangeQueryInConjunction(t *testing.T) {
+	q := NewConjunctionQuery([]search.Query{
+		MustCreateTermQuery([]byte("service"), []byte("m3db")),
+		MustCreateNumericRangeQuery([]byte("shard"), []byte("10"), []byte("20"), true, true),
+		NewNegationQuery(MustCreateRangeQuery([]byte("port"), nil, []byte("1024"), true, false)),
+	})
+	_, err := q.Searcher(search.NewOptions())
+	require.NoError(t, err)
+}
+
+func TestRangeQueryEqual(t *testing.T) {
+	tests := []struct {
+		name        string
+		left, right search.Query
+		expected    bool
+	}{
+		{
+			name:     "same bounds",
+			left:     MustCreateRangeQuery([]byte("shard"), []byte("10"), []byte("20"), true, false),
+			right:    MustCreateRangeQuery([]byte("shard"), []byte("10"), []byte("20"), true, false),
+			expected: true,
+		},
+		{
+			name: "singular conjunction query",
+			left: MustCreateRangeQuery([]byte("shard"), []byte("10"), []byte("20"), true, false),
+			right: NewConjunctionQuery([]search.Query{
+				MustCreateRangeQuery([]byte("shard"), []byte("10"), []byte("20"), true, false),
+			}),
+			expected: true,
+		},
+		{
+			name:     "nil and empty bounds",
+			left:     MustCreateRangeQuery([]byte("shard"), nil, []byte("20"), true, false),
+			right:    MustCreateRangeQuery([]byte("shard"), []byte{}, []byte("20"), true, false),
+			expected: true,
+		},
+		{
+			name:     "different field",
+			left:     MustCreateRangeQuery([]byte("shard"), []byte("10"), []byte("20"), true, false),
+			right:    MustCreateRangeQuery([]byte("port"), []byte("10"), []byte("20"), true, false),
+			expected: false,
+		},
+		{
+			name:     "different bounds",
+			left:     MustCreateRangeQuery([]byte("shard"), []byte("10"), []byte("20"), true, false),
+			right:    MustCreateRangeQuery([]byte("shard"), []byte("10"), []byte("30"), true, false),
+			expected: false,
+		},
+		{
+			name:     "different inclusivity",
+			left:     MustCreateRangeQuery([]byte("shard"), []byte("10"), []byte("20"), true, false),
+			right:    MustCreateRangeQuery([]byte("shard"), []byte("10"), []byte("20"), true, true),
+			expected: false,
+		},
+		{
+			name:     "numeric and lexical",
+			left:     MustCreateRangeQuery([]byte("shard"), []byte("10"), []byte("20"), true, false),
+			right:    MustCreateNumericRangeQuery([]byte("shard"), []byte("10"), []byte("20"), true, false),
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, test.left.Equal(test.right))
+		})
+	}
+}
+
+func TestRangeQueryString(t *testing.T) {
+	q := MustCreateRangeQuery([]byte("shard"), []byte("10"), []byte("20"), true, false)
+	require.Equal(t, "range(shard, [10, 20))", q.String())
+
+	q = MustCreateNumericRangeQuery([]byte("shard"), nil, []byte("20"), false, true)
+	require.Equal(t, "numeric_range(shard, (*, 20])", q.String())
+}
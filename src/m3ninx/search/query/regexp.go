@@ -24,6 +24,7 @@ import (
 	"bytes"
 	"fmt"
 
+	"github.com/m3db/m3/src/m3ninx/doc"
 	"github.com/m3db/m3/src/m3ninx/generated/proto/querypb"
 	"github.com/m3db/m3/src/m3ninx/index"
 	"github.com/m3db/m3/src/m3ninx/search"
@@ -37,9 +38,16 @@ type RegexpQuery struct {
 	compiled index.CompiledRegex
 }
 
-// NewRegexpQuery constructs a new query for the given regular expression.
+// NewRegexpQuery constructs a new query for the given regular expression. Compiled
+// regular expressions are shared between queries, see SetRegexpCacheSize. A nil field
+// searches the reserved ID field, whereas an empty field is returned as an error.
 func NewRegexpQuery(field, regexp []byte) (search.Query, error) {
-	compiled, err := index.CompileRegex(regexp)
+	field, err := resolveField(field)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled, err := compileRegexp(regexp)
 	if err != nil {
 		return nil, err
 	}
@@ -60,9 +68,15 @@ func MustCreateRegexpQuery(field, regexp []byte) search.Query {
 	return q
 }
 
+// NewRegexpQueryForID constructs a new query for documents whose ID matches the given
+// regular expression.
+func NewRegexpQueryForID(regexp []byte) (search.Query, error) {
+	return NewRegexpQuery(doc.IDReservedFieldName, regexp)
+}
+
 // Searcher returns a searcher over the provided readers.
-func (q *RegexpQuery) Searcher() (search.Searcher, error) {
-	return searcher.NewRegexpSearcher(q.field, q.compiled), nil
+func (q *RegexpQuery) Searcher(opts search.Options) (search.Searcher, error) {
+	return searcher.NewRegexpSearcher(q.field, q.compiled, opts), nil
 }
 
 // Equal reports whether q is equivalent to o.
@@ -80,6 +94,11 @@ func (q *RegexpQuery) Equal(o search.Query) bool {
 	return bytes.Equal(q.field, inner.field) && bytes.Equal(q.regexp, inner.regexp)
 }
 
+// Hash returns a stable hash of the query.
+func (q *RegexpQuery) Hash() uint64 {
+	return hashQuery(regexpQueryKind, hashBytes(q.field), hashBytes(q.regexp))
+}
+
 // ToProto returns the Protobuf query struct corresponding to the regexp query.
 func (q *RegexpQuery) ToProto() *querypb.Query {
 	regexp := querypb.RegexpQuery{
@@ -0,0 +1,158 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package query
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+
+	"github.com/m3db/m3/src/m3ninx/index"
+)
+
+const defaultRegexpCacheSize = 256
+
+// regexpCache is shared by all regexp queries in the process so that repeatedly issued
+// patterns are only compiled once.
+var regexpCache = newRegexpLRU(defaultRegexpCacheSize)
+
+// SetRegexpCacheSize sets the maximum number of compiled regexps shared between regexp
+// queries, evicting the least recently used regexps beyond that size. A size of zero
+// disables caching.
+func SetRegexpCacheSize(size int) {
+	regexpCache.setSize(size)
+}
+
+// RegexpCacheStats are the cumulative hits and misses of the compiled regexp cache.
+type RegexpCacheStats struct {
+	Hits   int64
+	Misses int64
+}
+
+// CurrentRegexpCacheStats returns the cumulative hits and misses of the compiled regexp
+// cache.
+func CurrentRegexpCacheStats() RegexpCacheStats {
+	return RegexpCacheStats{
+		Hits:   atomic.LoadInt64(&regexpCache.hits),
+		Misses: atomic.LoadInt64(&regexpCache.misses),
+	}
+}
+
+// compileRegexp returns the compiled regexp for the pattern from the cache, compiling
+// and caching it if it isn't present. Patterns which fail to compile are not cached.
+func compileRegexp(pattern []byte) (index.CompiledRegex, error) {
+	if compiled, ok := regexpCache.get(pattern); ok {
+		return compiled, nil
+	}
+
+	compiled, err := index.CompileRegex(pattern)
+	if err != nil {
+		return index.CompiledRegex{}, err
+	}
+	regexpCache.put(pattern, compiled)
+	return compiled, nil
+}
+
+type regexpLRUEntry struct {
+	pattern  string
+	compiled index.CompiledRegex
+}
+
+// regexpLRU is a bounded, least recently used cache of compiled regexps. The compiled
+// regexps themselves are immutable and safe to share between concurrent queries.
+type regexpLRU struct {
+	sync.Mutex
+
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+
+	hits   int64
+	misses int64
+}
+
+func newRegexpLRU(size int) *regexpLRU {
+	return &regexpLRU{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func (c *regexpLRU) get(pattern []byte) (index.CompiledRegex, bool) {
+	c.Lock()
+	elem, ok := c.entries[string(pattern)]
+	if !ok {
+		c.Unlock()
+		atomic.AddInt64(&c.misses, 1)
+		return index.CompiledRegex{}, false
+	}
+	c.order.MoveToFront(elem)
+	compiled := elem.Value.(*regexpLRUEntry).compiled
+	c.Unlock()
+
+	atomic.AddInt64(&c.hits, 1)
+	return compiled, true
+}
+
+func (c *regexpLRU) put(pattern []byte, compiled index.CompiledRegex) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.size <= 0 {
+		return
+	}
+
+	// Another query may have compiled the same pattern concurrently.
+	if elem, ok := c.entries[string(pattern)]; ok {
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	entry := &regexpLRUEntry{
+		pattern:  string(pattern),
+		compiled: compiled,
+	}
+	c.entries[entry.pattern] = c.order.PushFront(entry)
+	c.evictWithLock()
+}
+
+func (c *regexpLRU) setSize(size int) {
+	c.Lock()
+	c.size = size
+	c.evictWithLock()
+	c.Unlock()
+}
+
+func (c *regexpLRU) numEntries() int {
+	c.Lock()
+	n := c.order.Len()
+	c.Unlock()
+	return n
+}
+
+func (c *regexpLRU) evictWithLock() {
+	for c.order.Len() > 0 && c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*regexpLRUEntry).pattern)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package query
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegexpCacheHitsAndMisses(t *testing.T) {
+	defer SetRegexpCacheSize(defaultRegexpCacheSize)
+	resetRegexpCache(t, 2)
+
+	before := CurrentRegexpCacheStats()
+	q1, err := NewRegexpQuery([]byte("fruit"), []byte("app.*"))
+	require.NoError(t, err)
+	q2, err := NewRegexpQuery([]byte("color"), []byte("app.*"))
+	require.NoError(t, err)
+
+	after := CurrentRegexpCacheStats()
+	require.Equal(t, int64(1), after.Hits-before.Hits)
+	require.Equal(t, int64(1), after.Misses-before.Misses)
+
+	// Both queries share the same compiled regexp.
+	require.True(t, q1.(*RegexpQuery).compiled.Simple == q2.(*RegexpQuery).compiled.Simple)
+}
+
+func TestRegexpCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	defer SetRegexpCacheSize(defaultRegexpCacheSize)
+	resetRegexpCache(t, 2)
+
+	mustCompile := func(pattern string) {
+		_, err := compileRegexp([]byte(pattern))
+		require.NoError(t, err)
+	}
+	mustCompile("a.*")
+	mustCompile("b.*")
+	// Use "a.*" so that "b.*" is the least recently used.
+	mustCompile("a.*")
+	mustCompile("c.*")
+
+	require.Equal(t, 2, regexpCache.numEntries())
+	_, ok := regexpCache.get([]byte("a.*"))
+	require.True(t, ok)
+	_, ok = regexpCache.get([]byte("b.*"))
+	require.False(t, ok)
+	_, ok = regexpCache.get([]byte("c.*"))
+	require.True(t, ok)
+
+	// Shrinking the cache evicts entries immediately.
+	SetRegexpCacheSize(1)
+	require.Equal(t, 1, regexpCache.numEntries())
+}
+
+func TestRegexpCacheDisabled(t *testing.T) {
+	defer SetRegexpCacheSize(defaultRegexpCacheSize)
+	resetRegexpCache(t, 0)
+
+	before := CurrentRegexpCacheStats()
+	for i := 0; i < 3; i++ {
+		_, err := NewRegexpQuery([]byte("fruit"), []byte("app.*"))
+		require.NoError(t, err)
+	}
+
+	after := CurrentRegexpCacheStats()
+	require.Equal(t, int64(0), after.Hits-before.Hits)
+	require.Equal(t, int64(3), after.Misses-before.Misses)
+	require.Equal(t, 0, regexpCache.numEntries())
+}
+
+func TestRegexpCacheDoesNotCacheErrors(t *testing.T) {
+	defer SetRegexpCacheSize(defaultRegexpCacheSize)
+	resetRegexpCache(t, 2)
+
+	for i := 0; i < 2; i++ {
+		_, err := NewRegexpQuery([]byte("fruit"), []byte("(app"))
+		require.Error(t, err)
+	}
+	require.Equal(t, 0, regexpCache.numEntries())
+}
+
+func TestRegexpCacheConcurrent(t *testing.T) {
+	defer SetRegexpCacheSize(defaultRegexpCacheSize)
+	resetRegexpCache(t, 4)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				pattern := []byte(fmt.Sprintf("%d.*", (i+j)%8))
+				q, err := NewRegexpQuery([]byte("fruit"), pattern)
+				require.NoError(t, err)
+				require.True(t, q.(*RegexpQuery).compiled.Simple.Match(pattern[:1]))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	require.True(t, regexpCache.numEntries() <= 4)
+}
+
+func BenchmarkNewRegexpQuery(b *testing.B) {
+	defer SetRegexpCacheSize(defaultRegexpCacheSize)
+
+	var (
+		field   = []byte("__name__")
+		pattern = []byte("node_(cpu|memory|disk)_.*_(total|bytes)")
+	)
+	for _, size := range []int{0, defaultRegexpCacheSize} {
+		b.Run(fmt.Sprintf("cache size %d", size), func(b *testing.B) {
+			SetRegexpCacheSize(size)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := NewRegexpQuery(field, pattern); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func resetRegexpCache(t *testing.T, size int) {
+	SetRegexpCacheSize(0)
+	require.Equal(t, 0, regexpCache.numEntries())
+	SetRegexpCacheSize(size)
+}
@@ -58,7 +58,7 @@ func TestRegexpQuery(t *testing.T) {
 			}
 			require.NoError(t, err)
 
-			_, err = q.Searcher()
+			_, err = q.Searcher(search.NewOptions())
 			require.NoError(t, err)
 		})
 	}
@@ -112,3 +112,15 @@ func TestRegexpQueryEqual(t *testing.T) {
 		})
 	}
 }
+
+func TestRegexpQueryEqualCompiledSeparately(t *testing.T) {
+	defer SetRegexpCacheSize(defaultRegexpCacheSize)
+	SetRegexpCacheSize(0)
+
+	left := MustCreateRegexpQuery([]byte("fruit"), []byte("app.*"))
+	right := MustCreateRegexpQuery([]byte("fruit"), []byte("app.*"))
+	require.False(t, left.(*RegexpQuery).compiled.Simple == right.(*RegexpQuery).compiled.Simple)
+
+	require.True(t, left.Equal(right))
+	require.Equal(t, left.Hash(), right.Hash())
+}
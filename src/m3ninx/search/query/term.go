@@ -25,26 +25,81 @@ import (
 	"fmt"
 
 	"github.com/m3db/m3/src/m3ninx/generated/proto/querypb"
+	"github.com/m3db/m3/src/m3ninx/index"
 	"github.com/m3db/m3/src/m3ninx/search"
 	"github.com/m3db/m3/src/m3ninx/search/searcher"
 )
 
-// TermQuery finds document which match the given term exactly.
+// TermQuery finds document which match the given term exactly, or under case folding
+// if the query is case-insensitive.
 type TermQuery struct {
-	field []byte
-	term  []byte
+	field           []byte
+	term            []byte
+	caseInsensitive bool
+	compiled        index.CompiledFold
 }
 
-// NewTermQuery constructs a new TermQuery for the given field and term.
-func NewTermQuery(field, term []byte) search.Query {
+// NewTermQuery constructs a new TermQuery for the given field and term. A nil field
+// searches the reserved ID field, whereas an empty field is returned as an error.
+func NewTermQuery(field, term []byte) (search.Query, error) {
+	field, err := resolveField(field)
+	if err != nil {
+		return nil, err
+	}
+
 	return &TermQuery{
 		field: field,
 		term:  term,
+	}, nil
+}
+
+// MustCreateTermQuery is like NewTermQuery but panics if the query cannot be created.
+func MustCreateTermQuery(field, term []byte) search.Query {
+	q, err := NewTermQuery(field, term)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// NewCaseInsensitiveTermQuery constructs a new TermQuery for the given field and term
+// which matches terms equal to the term under the given case folding. The field is
+// treated as with NewTermQuery.
+func NewCaseInsensitiveTermQuery(
+	field, term []byte,
+	folding index.CaseFolding,
+) (search.Query, error) {
+	field, err := resolveField(field)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TermQuery{
+		field:           field,
+		term:            term,
+		caseInsensitive: true,
+		compiled:        index.CompileFold(term, false, folding),
+	}, nil
+}
+
+// MustCreateCaseInsensitiveTermQuery is like NewCaseInsensitiveTermQuery but panics if
+// the query cannot be created.
+func MustCreateCaseInsensitiveTermQuery(
+	field, term []byte,
+	folding index.CaseFolding,
+) search.Query {
+	q, err := NewCaseInsensitiveTermQuery(field, term, folding)
+	if err != nil {
+		panic(err)
 	}
+	return q
 }
 
 // Searcher returns a searcher over the provided readers.
-func (q *TermQuery) Searcher() (search.Searcher, error) {
+func (q *TermQuery) Searcher(opts search.Options) (search.Searcher, error) {
+	if q.caseInsensitive {
+		return searcher.NewFoldSearcher(q.field, q.compiled), nil
+	}
 	return searcher.NewTermSearcher(q.field, q.term), nil
 }
 
@@ -60,14 +115,26 @@ func (q *TermQuery) Equal(o search.Query) bool {
 		return false
 	}
 
-	return bytes.Equal(q.field, inner.field) && bytes.Equal(q.term, inner.term)
+	return bytes.Equal(q.field, inner.field) && bytes.Equal(q.term, inner.term) &&
+		equalCaseFolding(q.caseInsensitive, q.compiled.Folding, inner.caseInsensitive, inner.compiled.Folding)
+}
+
+// Hash returns a stable hash of the query.
+func (q *TermQuery) Hash() uint64 {
+	if q.caseInsensitive {
+		return hashQuery(termQueryKind, hashBytes(q.field), hashBytes(q.term),
+			hashBool(true), uint64(q.compiled.Folding))
+	}
+	return hashQuery(termQueryKind, hashBytes(q.field), hashBytes(q.term))
 }
 
 // ToProto returns the Protobuf query struct corresponding to the term query.
 func (q *TermQuery) ToProto() *querypb.Query {
 	term := querypb.TermQuery{
-		Field: q.field,
-		Term:  q.term,
+		Field:           q.field,
+		Term:            q.term,
+		CaseInsensitive: q.caseInsensitive,
+		UnicodeFolding:  q.caseInsensitive && q.compiled.Folding == index.UnicodeCaseFolding,
 	}
 
 	return &querypb.Query{
@@ -76,5 +143,6 @@ func (q *TermQuery) ToProto() *querypb.Query {
 }
 
 func (q *TermQuery) String() string {
-	return fmt.Sprintf("term(%s, %s)", q.field, q.term)
+	name := caseSensitivityName("term", q.caseInsensitive, q.compiled.Folding)
+	return fmt.Sprintf("%s(%s, %s)", name, q.field, q.term)
 }
@@ -23,8 +23,11 @@ package query
 import (
 	"testing"
 
+	"github.com/m3db/m3/src/m3ninx/index"
+	"github.com/m3db/m3/src/m3ninx/postings/roaring"
 	"github.com/m3db/m3/src/m3ninx/search"
 
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -42,13 +45,34 @@ func TestTermQuery(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			q := NewTermQuery(test.field, test.term)
-			_, err := q.Searcher()
+			q := MustCreateTermQuery(test.field, test.term)
+			_, err := q.Searcher(search.NewOptions())
 			require.NoError(t, err)
 		})
 	}
 }
 
+func TestTermQueryCaseInsensitive(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	field := []byte("fruit")
+	q := MustCreateCaseInsensitiveTermQuery(field, []byte("APPLE"), index.ASCIICaseFolding)
+
+	r := index.NewMockReader(mockCtrl)
+	r.EXPECT().MatchFold(field, gomock.Any()).Do(func(_ []byte, c index.CompiledFold) {
+		require.False(t, c.Prefix)
+		require.True(t, c.Match([]byte("apple")))
+		require.True(t, c.Match([]byte("Apple")))
+		require.False(t, c.Match([]byte("apples")))
+	}).Return(roaring.NewPostingsList(), nil)
+
+	s, err := q.Searcher(search.NewOptions())
+	require.NoError(t, err)
+	_, err = s.Search(r)
+	require.NoError(t, err)
+}
+
 func TestTermQueryEqual(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -57,36 +81,54 @@ func TestTermQueryEqual(t *testing.T) {
 	}{
 		{
 			name:     "same field and term",
-			left:     NewTermQuery([]byte("fruit"), []byte("apple")),
-			right:    NewTermQuery([]byte("fruit"), []byte("apple")),
+			left:     MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+			right:    MustCreateTermQuery([]byte("fruit"), []byte("apple")),
 			expected: true,
 		},
 		{
 			name: "singular conjunction query",
-			left: NewTermQuery([]byte("fruit"), []byte("apple")),
+			left: MustCreateTermQuery([]byte("fruit"), []byte("apple")),
 			right: NewConjunctionQuery([]search.Query{
-				NewTermQuery([]byte("fruit"), []byte("apple")),
+				MustCreateTermQuery([]byte("fruit"), []byte("apple")),
 			}),
 			expected: true,
 		},
 		{
 			name: "singular disjunction query",
-			left: NewTermQuery([]byte("fruit"), []byte("apple")),
+			left: MustCreateTermQuery([]byte("fruit"), []byte("apple")),
 			right: NewDisjunctionQuery([]search.Query{
-				NewTermQuery([]byte("fruit"), []byte("apple")),
+				MustCreateTermQuery([]byte("fruit"), []byte("apple")),
 			}),
 			expected: true,
 		},
 		{
 			name:     "different field",
-			left:     NewTermQuery([]byte("fruit"), []byte("apple")),
-			right:    NewTermQuery([]byte("food"), []byte("apple")),
+			left:     MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+			right:    MustCreateTermQuery([]byte("food"), []byte("apple")),
 			expected: false,
 		},
 		{
 			name:     "different term",
-			left:     NewTermQuery([]byte("fruit"), []byte("apple")),
-			right:    NewTermQuery([]byte("fruit"), []byte("banana")),
+			left:     MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+			right:    MustCreateTermQuery([]byte("fruit"), []byte("banana")),
+			expected: false,
+		},
+		{
+			name:     "same case-insensitive term",
+			left:     MustCreateCaseInsensitiveTermQuery([]byte("fruit"), []byte("apple"), index.ASCIICaseFolding),
+			right:    MustCreateCaseInsensitiveTermQuery([]byte("fruit"), []byte("apple"), index.ASCIICaseFolding),
+			expected: true,
+		},
+		{
+			name:     "case-sensitive and case-insensitive term",
+			left:     MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+			right:    MustCreateCaseInsensitiveTermQuery([]byte("fruit"), []byte("apple"), index.ASCIICaseFolding),
+			expected: false,
+		},
+		{
+			name:     "different case folding",
+			left:     MustCreateCaseInsensitiveTermQuery([]byte("fruit"), []byte("apple"), index.ASCIICaseFolding),
+			right:    MustCreateCaseInsensitiveTermQuery([]byte("fruit"), []byte("apple"), index.UnicodeCaseFolding),
 			expected: false,
 		},
 	}
@@ -97,3 +139,29 @@ func TestTermQueryEqual(t *testing.T) {
 		})
 	}
 }
+
+func TestTermQueryString(t *testing.T) {
+	tests := []struct {
+		query    search.Query
+		expected string
+	}{
+		{
+			query:    MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+			expected: "term(fruit, apple)",
+		},
+		{
+			query:    MustCreateCaseInsensitiveTermQuery([]byte("fruit"), []byte("apple"), index.ASCIICaseFolding),
+			expected: "case_insensitive_term(fruit, apple)",
+		},
+		{
+			query:    MustCreateCaseInsensitiveTermQuery([]byte("fruit"), []byte("apple"), index.UnicodeCaseFolding),
+			expected: "unicode_case_insensitive_term(fruit, apple)",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expected, func(t *testing.T) {
+			require.Equal(t, test.expected, test.query.String())
+		})
+	}
+}
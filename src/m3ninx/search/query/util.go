@@ -22,8 +22,46 @@ package query
 
 import (
 	"bytes"
+	"errors"
 
+	"github.com/m3db/m3/src/m3ninx/doc"
+	"github.com/m3db/m3/src/m3ninx/index"
 	"github.com/m3db/m3/src/m3ninx/search"
+
+	"github.com/cespare/xxhash"
+)
+
+var errEmptyField = errors.New("query field must not be empty")
+
+// resolveField returns the field a query constructed with the given field searches, which
+// is the reserved ID field if the field is nil. It returns an error if the field is empty
+// but non-nil.
+func resolveField(field []byte) ([]byte, error) {
+	if field == nil {
+		return doc.IDReservedFieldName, nil
+	}
+	if len(field) == 0 {
+		return nil, errEmptyField
+	}
+	return field, nil
+}
+
+type queryKind uint64
+
+// NB: the kinds are part of each query's hash so must not be reordered.
+const (
+	termQueryKind queryKind = iota + 1
+	regexpQueryKind
+	negationQueryKind
+	conjunctionQueryKind
+	disjunctionQueryKind
+	fuzzyQueryKind
+	prefixQueryKind
+	wildcardQueryKind
+	rangeQueryKind
+	matchAllQueryKind
+	matchNoneQueryKind
+	fieldQueryKind
 )
 
 // singular returns a bool indicating whether a given query is composed of a single
@@ -31,9 +69,12 @@ import (
 func singular(q search.Query) (search.Query, bool) {
 	switch q := q.(type) {
 	case *ConjuctionQuery:
-		if len(q.queries) == 1 {
+		if len(q.queries) == 1 && len(q.negations) == 0 {
 			return q.queries[0], true
 		}
+		if len(q.queries) == 0 && len(q.negations) == 1 {
+			return NewNegationQuery(q.negations[0]), true
+		}
 		return nil, false
 	case *DisjuctionQuery:
 		if len(q.queries) == 1 {
@@ -44,6 +85,38 @@ func singular(q search.Query) (search.Query, bool) {
 	return q, true
 }
 
+// caseFolding returns the case folding encoded by a Protobuf query.
+func caseFolding(unicodeFolding bool) index.CaseFolding {
+	if unicodeFolding {
+		return index.UnicodeCaseFolding
+	}
+	return index.ASCIICaseFolding
+}
+
+// equalCaseFolding reports whether two queries match terms with the same case sensitivity,
+// ignoring the folding of case-sensitive queries.
+func equalCaseFolding(
+	aCaseInsensitive bool, aFolding index.CaseFolding,
+	bCaseInsensitive bool, bFolding index.CaseFolding,
+) bool {
+	if aCaseInsensitive != bCaseInsensitive {
+		return false
+	}
+	return !aCaseInsensitive || aFolding == bFolding
+}
+
+// caseSensitivityName returns the name of a query of the given kind with the given case
+// sensitivity for use in its string representation.
+func caseSensitivityName(name string, caseInsensitive bool, folding index.CaseFolding) string {
+	if !caseInsensitive {
+		return name
+	}
+	if folding == index.UnicodeCaseFolding {
+		return "unicode_case_insensitive_" + name
+	}
+	return "case_insensitive_" + name
+}
+
 // join concatenates a slice of queries.
 func join(qs []search.Query) string {
 	switch len(qs) {
@@ -62,3 +135,90 @@ func join(qs []search.Query) string {
 
 	return b.String()
 }
+
+// hashQuery combines the hashes of the components of a query of the given kind, so that
+// different kinds of queries with the same components hash differently.
+func hashQuery(kind queryKind, components ...uint64) uint64 {
+	hash := uint64(kind)
+	for _, c := range components {
+		hash = 31*hash + c
+	}
+	return hash
+}
+
+func hashBytes(b []byte) uint64 {
+	return xxhash.Sum64(b)
+}
+
+func hashBool(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// hashUnordered combines the hashes of the queries such that the result is independent
+// of their order.
+func hashUnordered(qs []search.Query) uint64 {
+	var hash uint64
+	for _, q := range qs {
+		hash += q.Hash()
+	}
+	return hash
+}
+
+// equalUnordered reports whether the two slices contain equivalent queries, irrespective
+// of their order.
+func equalUnordered(a, b []search.Query) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	hashes := make([]uint64, 0, len(b))
+	for _, q := range b {
+		hashes = append(hashes, q.Hash())
+	}
+
+	matched := make([]bool, len(b))
+	for _, qa := range a {
+		hash := qa.Hash()
+		found := false
+		for i, qb := range b {
+			if matched[i] || hashes[i] != hash || !qa.Equal(qb) {
+				continue
+			}
+			matched[i] = true
+			found = true
+			break
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// dedupe removes any queries equivalent to an earlier query in the slice, modifying the
+// slice in place.
+func dedupe(qs []search.Query) []search.Query {
+	var (
+		seen   = make(map[uint64][]search.Query, len(qs))
+		result = qs[:0]
+	)
+	for _, q := range qs {
+		hash := q.Hash()
+		duplicate := false
+		for _, s := range seen[hash] {
+			if s.Equal(q) {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+		seen[hash] = append(seen[hash], q)
+		result = append(result, q)
+	}
+	return result
+}
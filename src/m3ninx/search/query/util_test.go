@@ -23,6 +23,8 @@ package query
 import (
 	"testing"
 
+	"github.com/m3db/m3/src/m3ninx/doc"
+	"github.com/m3db/m3/src/m3ninx/index"
 	"github.com/m3db/m3/src/m3ninx/search"
 
 	"github.com/golang/mock/gomock"
@@ -64,3 +66,232 @@ func TestJoin(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryFieldDefault(t *testing.T) {
+	tests := []struct {
+		name   string
+		create func(field []byte) (search.Query, error)
+	}{
+		{
+			name: "term query",
+			create: func(field []byte) (search.Query, error) {
+				return NewTermQuery(field, []byte("apple"))
+			},
+		},
+		{
+			name: "case-insensitive term query",
+			create: func(field []byte) (search.Query, error) {
+				return NewCaseInsensitiveTermQuery(field, []byte("apple"), index.ASCIICaseFolding)
+			},
+		},
+		{
+			name: "prefix query",
+			create: func(field []byte) (search.Query, error) {
+				return NewPrefixQuery(field, []byte("app"))
+			},
+		},
+		{
+			name: "case-insensitive prefix query",
+			create: func(field []byte) (search.Query, error) {
+				return NewCaseInsensitivePrefixQuery(field, []byte("app"), index.ASCIICaseFolding)
+			},
+		},
+		{
+			name: "field query",
+			create: func(field []byte) (search.Query, error) {
+				return NewFieldQuery(field)
+			},
+		},
+		{
+			name: "range query",
+			create: func(field []byte) (search.Query, error) {
+				return NewRangeQuery(field, []byte("a"), []byte("b"), true, false)
+			},
+		},
+		{
+			name: "numeric range query",
+			create: func(field []byte) (search.Query, error) {
+				return NewNumericRangeQuery(field, []byte("1"), []byte("2"), true, false)
+			},
+		},
+		{
+			name: "regexp query",
+			create: func(field []byte) (search.Query, error) {
+				return NewRegexpQuery(field, []byte("a.*"))
+			},
+		},
+		{
+			name: "fuzzy query",
+			create: func(field []byte) (search.Query, error) {
+				return NewFuzzyQuery(field, []byte("apple"), 1)
+			},
+		},
+		{
+			name: "wildcard query",
+			create: func(field []byte) (search.Query, error) {
+				return NewWildcardQuery(field, []byte("a*"))
+			},
+		},
+		{
+			name: "wildcard query without wildcards",
+			create: func(field []byte) (search.Query, error) {
+				return NewWildcardQuery(field, []byte("apple"))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// A nil field searches the default field.
+			q, err := test.create(nil)
+			require.NoError(t, err)
+			expected, err := test.create(doc.IDReservedFieldName)
+			require.NoError(t, err)
+			require.True(t, q.Equal(expected))
+
+			// An empty but non-nil field is invalid.
+			_, err = test.create([]byte{})
+			require.Equal(t, errEmptyField, err)
+		})
+	}
+}
+
+func TestNewRegexpQueryForID(t *testing.T) {
+	q, err := NewRegexpQueryForID([]byte("foo.*"))
+	require.NoError(t, err)
+	require.True(t, q.Equal(MustCreateRegexpQuery(doc.IDReservedFieldName, []byte("foo.*"))))
+}
+
+func TestQueryHashEquivalentQueries(t *testing.T) {
+	defer SetRegexpCacheSize(defaultRegexpCacheSize)
+	SetRegexpCacheSize(0)
+
+	var (
+		apple     = MustCreateTermQuery([]byte("fruit"), []byte("apple"))
+		banana    = MustCreateTermQuery([]byte("fruit"), []byte("banana"))
+		notRed    = NewNegationQuery(MustCreateTermQuery([]byte("color"), []byte("red")))
+		appRegexp = MustCreateRegexpQuery([]byte("fruit"), []byte("app.*"))
+	)
+
+	tests := []struct {
+		name        string
+		left, right search.Query
+	}{
+		{
+			name:  "regexps compiled separately",
+			left:  MustCreateRegexpQuery([]byte("fruit"), []byte("app.*")),
+			right: MustCreateRegexpQuery([]byte("fruit"), []byte("app.*")),
+		},
+		{
+			name:  "conjunction in a different order",
+			left:  NewConjunctionQuery([]search.Query{apple, notRed, appRegexp}),
+			right: NewConjunctionQuery([]search.Query{notRed, appRegexp, apple}),
+		},
+		{
+			name:  "disjunction in a different order",
+			left:  NewDisjunctionQuery([]search.Query{apple, banana, appRegexp}),
+			right: NewDisjunctionQuery([]search.Query{appRegexp, apple, banana}),
+		},
+		{
+			name: "nested queries in a different order",
+			left: NewDisjunctionQuery([]search.Query{
+				NewConjunctionQuery([]search.Query{apple, notRed}),
+				NewNegationQuery(NewDisjunctionQuery([]search.Query{banana, appRegexp})),
+			}),
+			right: NewDisjunctionQuery([]search.Query{
+				NewNegationQuery(NewDisjunctionQuery([]search.Query{appRegexp, banana})),
+				NewConjunctionQuery([]search.Query{notRed, apple}),
+			}),
+		},
+		{
+			name:  "singular conjunction",
+			left:  NewConjunctionQuery([]search.Query{apple}),
+			right: apple,
+		},
+		{
+			name:  "singular disjunction",
+			left:  NewDisjunctionQuery([]search.Query{appRegexp}),
+			right: appRegexp,
+		},
+		{
+			name:  "conjunction of a single negation",
+			left:  NewConjunctionQuery([]search.Query{notRed}),
+			right: notRed,
+		},
+		{
+			name: "conjunction within singular disjunction",
+			left: NewDisjunctionQuery([]search.Query{
+				NewConjunctionQuery([]search.Query{apple, notRed}),
+			}),
+			right: NewConjunctionQuery([]search.Query{apple, notRed}),
+		},
+		{
+			name:  "duplicate children",
+			left:  NewDisjunctionQuery([]search.Query{apple, banana, apple}),
+			right: NewDisjunctionQuery([]search.Query{banana, apple}),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.True(t, test.left.Equal(test.right))
+			require.True(t, test.right.Equal(test.left))
+			require.Equal(t, test.left.Hash(), test.right.Hash())
+		})
+	}
+}
+
+func TestQueryHashDifferentQueries(t *testing.T) {
+	var (
+		field = []byte("fruit")
+		value = []byte("apple")
+	)
+
+	// Queries of different kinds over the same components must hash differently.
+	queries := []search.Query{
+		MustCreateTermQuery(field, value),
+		MustCreateRegexpQuery(field, value),
+		MustCreateFuzzyQuery(field, value, 1),
+		MustCreatePrefixQuery(field, value),
+		MustCreateWildcardQuery(field, []byte("apple*")),
+		MustCreateRangeQuery(field, value, value, true, true),
+		MustCreateRangeQuery(field, value, value, true, false),
+		MustCreateNumericRangeQuery(field, []byte("1"), []byte("1"), true, true),
+		MustCreateFieldQuery(field),
+		NewMatchAllQuery(),
+		NewMatchNoneQuery(),
+		NewNegationQuery(MustCreateTermQuery(field, value)),
+		NewConjunctionQuery([]search.Query{
+			MustCreateTermQuery(field, value),
+			MustCreateTermQuery(field, []byte("banana")),
+		}),
+		NewDisjunctionQuery([]search.Query{
+			MustCreateTermQuery(field, value),
+			MustCreateTermQuery(field, []byte("banana")),
+		}),
+	}
+
+	seen := make(map[uint64]search.Query, len(queries))
+	for _, q := range queries {
+		other, ok := seen[q.Hash()]
+		require.False(t, ok, "%s has the same hash as %s", q, other)
+		seen[q.Hash()] = q
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	var (
+		apple  = MustCreateTermQuery([]byte("fruit"), []byte("apple"))
+		banana = MustCreateTermQuery([]byte("fruit"), []byte("banana"))
+	)
+
+	qs := dedupe([]search.Query{
+		apple,
+		banana,
+		MustCreateTermQuery([]byte("fruit"), []byte("apple")),
+		NewDisjunctionQuery([]search.Query{banana}),
+	})
+	require.Len(t, qs, 2)
+	require.True(t, qs[0].Equal(apple))
+	require.True(t, qs[1].Equal(banana))
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package query
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+
+	"github.com/m3db/m3/src/m3ninx/generated/proto/querypb"
+	"github.com/m3db/m3/src/m3ninx/index"
+	"github.com/m3db/m3/src/m3ninx/search"
+	"github.com/m3db/m3/src/m3ninx/search/searcher"
+)
+
+const wildcardChars = "*?"
+
+// WildcardQuery finds documents which have a term matching the given glob-style pattern,
+// where '*' matches any sequence of characters and '?' matches any single character.
+type WildcardQuery struct {
+	field    []byte
+	pattern  []byte
+	prefix   []byte
+	compiled index.CompiledRegex
+}
+
+// NewWildcardQuery constructs a new query for the given wildcard pattern. Patterns which
+// do not contain any wildcards are returned as the equivalent TermQuery. A nil field
+// searches the reserved ID field, whereas an empty field is returned as an error.
+func NewWildcardQuery(field, pattern []byte) (search.Query, error) {
+	field, err := resolveField(field)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := bytes.IndexAny(pattern, wildcardChars)
+	if idx < 0 {
+		return NewTermQuery(field, pattern)
+	}
+
+	compiled, err := index.CompileRegex(wildcardToRegexp(pattern))
+	if err != nil {
+		return nil, err
+	}
+
+	return &WildcardQuery{
+		field:    field,
+		pattern:  pattern,
+		prefix:   pattern[:idx],
+		compiled: compiled,
+	}, nil
+}
+
+// MustCreateWildcardQuery is like NewWildcardQuery but panics if the query cannot be created.
+func MustCreateWildcardQuery(field, pattern []byte) search.Query {
+	q, err := NewWildcardQuery(field, pattern)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// Prefix returns the literal prefix of the pattern before its first wildcard.
+func (q *WildcardQuery) Prefix() []byte {
+	return q.prefix
+}
+
+// Searcher returns a searcher over the provided readers.
+func (q *WildcardQuery) Searcher(opts search.Options) (search.Searcher, error) {
+	// Patterns which are a literal prefix followed only by '*' don't need a regexp at all.
+	// Otherwise the compiled regexp carries the literal prefix so FST-backed segments
+	// seek directly to it rather than scanning every term. Prefix searches can't limit
+	// the number of terms they match so they are only used when there is no such limit.
+	if opts.MaxTermsMatched() <= 0 && len(bytes.TrimRight(q.pattern[len(q.prefix):], "*")) == 0 {
+		return searcher.NewPrefixSearcher(q.field, q.prefix), nil
+	}
+	return searcher.NewRegexpSearcher(q.field, q.compiled, opts), nil
+}
+
+// Equal reports whether q is equivalent to o.
+func (q *WildcardQuery) Equal(o search.Query) bool {
+	o, ok := singular(o)
+	if !ok {
+		return false
+	}
+
+	inner, ok := o.(*WildcardQuery)
+	if !ok {
+		return false
+	}
+
+	return bytes.Equal(q.field, inner.field) && bytes.Equal(q.pattern, inner.pattern)
+}
+
+// Hash returns a stable hash of the query.
+func (q *WildcardQuery) Hash() uint64 {
+	return hashQuery(wildcardQueryKind, hashBytes(q.field), hashBytes(q.pattern))
+}
+
+// ToProto returns the Protobuf query struct corresponding to the wildcard query.
+func (q *WildcardQuery) ToProto() *querypb.Query {
+	wildcard := querypb.WildcardQuery{
+		Field:   q.field,
+		Pattern: q.pattern,
+	}
+
+	return &querypb.Query{
+		Query: &querypb.Query_Wildcard{Wildcard: &wildcard},
+	}
+}
+
+func (q *WildcardQuery) String() string {
+	return fmt.Sprintf("wildcard(%s, %s)", q.field, q.pattern)
+}
+
+// wildcardToRegexp converts a wildcard pattern into the equivalent regexp. Every other
+// character is matched literally, and '.' is allowed to match newlines so that wildcards
+// match any character.
+func wildcardToRegexp(pattern []byte) []byte {
+	var b bytes.Buffer
+	b.WriteString("(?s)")
+	for len(pattern) > 0 {
+		idx := bytes.IndexAny(pattern, wildcardChars)
+		if idx < 0 {
+			b.WriteString(regexp.QuoteMeta(string(pattern)))
+			break
+		}
+
+		b.WriteString(regexp.QuoteMeta(string(pattern[:idx])))
+		switch pattern[idx] {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		}
+		pattern = pattern[idx+1:]
+	}
+	return b.Bytes()
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package query
+
+import (
+	"testing"
+
+	"github.com/m3db/m3/src/m3ninx/index"
+	"github.com/m3db/m3/src/m3ninx/postings/roaring"
+	"github.com/m3db/m3/src/m3ninx/search"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWildcardQuery(t *testing.T) {
+	tests := []struct {
+		name           string
+		pattern        string
+		expectedPrefix string
+	}{
+		{
+			name:           "trailing star",
+			pattern:        "app*",
+			expectedPrefix: "app",
+		},
+		{
+			name:           "repeated trailing star",
+			pattern:        "app**",
+			expectedPrefix: "app",
+		},
+		{
+			name:           "wildcards in the middle",
+			pattern:        "a?p*e",
+			expectedPrefix: "a",
+		},
+		{
+			name:           "leading wildcard",
+			pattern:        "*ple",
+			expectedPrefix: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q, err := NewWildcardQuery([]byte("fruit"), []byte(test.pattern))
+			require.NoError(t, err)
+
+			wq, ok := q.(*WildcardQuery)
+			require.True(t, ok)
+			require.Equal(t, test.expectedPrefix, string(wq.Prefix()))
+			// The compiled regexp must carry the same prefix so FST segments can seek to it.
+			require.Equal(t, test.expectedPrefix, string(wq.compiled.PrefixBegin))
+
+			_, err = q.Searcher(search.NewOptions())
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestWildcardQueryWithoutWildcardsIsTermQuery(t *testing.T) {
+	q, err := NewWildcardQuery([]byte("fruit"), []byte("apple.pie"))
+	require.NoError(t, err)
+	require.True(t, q.Equal(MustCreateTermQuery([]byte("fruit"), []byte("apple.pie"))))
+}
+
+func TestWildcardQueryMatches(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		term     string
+		expected bool
+	}{
+		{pattern: "app*", term: "apple", expected: true},
+		{pattern: "app*", term: "app", expected: true},
+		{pattern: "app*", term: "pineapple", expected: false},
+		{pattern: "*apple", term: "pineapple", expected: true},
+		{pattern: "*apple", term: "apples", expected: false},
+		{pattern: "a?ple", term: "apple", expected: true},
+		{pattern: "a?ple", term: "aple", expected: false},
+		{pattern: "a?ple", term: "a\nple", expected: true},
+		{pattern: "?", term: "é", expected: true},
+		{pattern: "a.b*", term: "a.bc", expected: true},
+		{pattern: "a.b*", term: "axbc", expected: false},
+		{pattern: "(a|b)*", term: "(a|b)c", expected: true},
+		{pattern: "(a|b)*", term: "ac", expected: false},
+		{pattern: "*", term: "", expected: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern+"/"+test.term, func(t *testing.T) {
+			q, err := NewWildcardQuery([]byte("fruit"), []byte(test.pattern))
+			require.NoError(t, err)
+
+			compiled := q.(*WildcardQuery).compiled
+			require.Equal(t, test.expected, compiled.Simple.MatchString(test.term))
+
+			fstMatched := true
+			state := compiled.FST.Start()
+			for _, b := range []byte(test.term) {
+				state = compiled.FST.Accept(state, b)
+				if !compiled.FST.CanMatch(state) {
+					fstMatched = false
+					break
+				}
+			}
+			fstMatched = fstMatched && compiled.FST.IsMatch(state)
+			require.Equal(t, test.expected, fstMatched)
+		})
+	}
+}
+
+func TestWildcardQueryMaxTermsMatched(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	field := []byte("fruit")
+	q := MustCreateWildcardQuery(field, []byte("app*"))
+
+	// Without a limit the pattern is searched by prefix.
+	r := index.NewMockReader(mockCtrl)
+	r.EXPECT().MatchPrefix(field, []byte("app")).Return(roaring.NewPostingsList(), nil)
+	s, err := q.Searcher(search.NewOptions())
+	require.NoError(t, err)
+	_, err = s.Search(r)
+	require.NoError(t, err)
+
+	// With a limit the pattern is searched by its regexp, which enforces the limit.
+	r.EXPECT().MatchRegexp(field, gomock.Any()).Do(func(_ []byte, c index.CompiledRegex) {
+		require.Equal(t, 5, c.MaxTermsMatched)
+	}).Return(nil, index.ErrTooManyTermsMatched)
+	s, err = q.Searcher(search.NewOptions().SetMaxTermsMatched(5))
+	require.NoError(t, err)
+	_, err = s.Search(r)
+	require.Equal(t, index.ErrTooManyTermsMatched, err)
+}
+
+func TestWildcardQueryEqual(t *testing.T) {
+	tests := []struct {
+		name        string
+		left, right search.Query
+		expected    bool
+	}{
+		{
+			name:     "same field and pattern",
+			left:     MustCreateWildcardQuery([]byte("fruit"), []byte("app*")),
+			right:    MustCreateWildcardQuery([]byte("fruit"), []byte("app*")),
+			expected: true,
+		},
+		{
+			name: "singular conjunction query",
+			left: MustCreateWildcardQuery([]byte("fruit"), []byte("app*")),
+			right: NewConjunctionQuery([]search.Query{
+				MustCreateWildcardQuery([]byte("fruit"), []byte("app*")),
+			}),
+			expected: true,
+		},
+		{
+			name:     "different field",
+			left:     MustCreateWildcardQuery([]byte("fruit"), []byte("app*")),
+			right:    MustCreateWildcardQuery([]byte("food"), []byte("app*")),
+			expected: false,
+		},
+		{
+			name:     "different pattern",
+			left:     MustCreateWildcardQuery([]byte("fruit"), []byte("app*")),
+			right:    MustCreateWildcardQuery([]byte("fruit"), []byte("app?")),
+			expected: false,
+		},
+		{
+			name:     "equivalent prefix query",
+			left:     MustCreateWildcardQuery([]byte("fruit"), []byte("app*")),
+			right:    MustCreatePrefixQuery([]byte("fruit"), []byte("app")),
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, test.left.Equal(test.right))
+		})
+	}
+}
+
+func TestWildcardQueryString(t *testing.T) {
+	q := MustCreateWildcardQuery([]byte("fruit"), []byte("a?p*"))
+	require.Equal(t, "wildcard(fruit, a?p*)", q.String())
+}
@@ -21,6 +21,8 @@
 package searcher
 
 import (
+	"sort"
+
 	"github.com/m3db/m3/src/m3ninx/index"
 	"github.com/m3db/m3/src/m3ninx/postings"
 	"github.com/m3db/m3/src/m3ninx/search"
@@ -32,32 +34,50 @@ type conjunctionSearcher struct {
 }
 
 // NewConjunctionSearcher returns a new Searcher which matches documents which match each
-// of the given searchers and none of the negations.
+// of the given searchers and none of the negations. The searchers are evaluated in order
+// of increasing cost.
 func NewConjunctionSearcher(searchers, negations search.Searchers) (search.Searcher, error) {
 	if len(searchers) == 0 {
 		return nil, errEmptySearchers
 	}
 
+	sorted := make(search.Searchers, len(searchers))
+	copy(sorted, searchers)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Cost() < sorted[j].Cost()
+	})
+
 	return &conjunctionSearcher{
-		searchers: searchers,
+		searchers: sorted,
 		negations: negations,
 	}, nil
 }
 
 func (s *conjunctionSearcher) Search(r index.Reader) (postings.List, error) {
-	var pl postings.MutableList
+	// Searching the cheapest searchers first means the more expensive ones can be skipped
+	// entirely if any of them don't match any documents.
+	pls := make([]postings.List, 0, len(s.searchers))
 	for _, sr := range s.searchers {
 		curr, err := sr.Search(r)
 		if err != nil {
 			return nil, err
 		}
 
-		// TODO: Sort the iterators so that we take the intersection in order of increasing size.
-		if pl == nil {
-			pl = curr.Clone()
-		} else {
-			pl.Intersect(curr)
+		if curr.IsEmpty() {
+			return curr.Clone(), nil
 		}
+		pls = append(pls, curr)
+	}
+
+	// The estimated costs can be inaccurate for any given reader, so take the intersection
+	// in order of increasing size to ensure the list that is cloned, and every intermediate
+	// intersection, is as small as possible.
+	sort.SliceStable(pls, func(i, j int) bool {
+		return pls[i].Len() < pls[j].Len()
+	})
+	pl := pls[0].Clone()
+	for _, curr := range pls[1:] {
+		pl.Intersect(curr)
 
 		// We can break early if the interescted postings list is ever empty.
 		if pl.IsEmpty() {
@@ -65,6 +85,12 @@ func (s *conjunctionSearcher) Search(r index.Reader) (postings.List, error) {
 		}
 	}
 
+	// Negations only ever remove documents so there's no need to search them if the
+	// intersection is already empty.
+	if pl.IsEmpty() {
+		return pl, nil
+	}
+
 	for _, sr := range s.negations {
 		curr, err := sr.Search(r)
 		if err != nil {
@@ -82,3 +108,9 @@ func (s *conjunctionSearcher) Search(r index.Reader) (postings.List, error) {
 
 	return pl, nil
 }
+
+func (s *conjunctionSearcher) Cost() int {
+	// A conjunction matches at most as many documents as its most selective searcher,
+	// which is the first since they're sorted by cost.
+	return s.searchers[0].Cost()
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package searcher
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/m3db/m3/src/m3ninx/doc"
+	"github.com/m3db/m3/src/m3ninx/index"
+	"github.com/m3db/m3/src/m3ninx/index/segment/mem"
+	"github.com/m3db/m3/src/m3ninx/postings"
+	"github.com/m3db/m3/src/m3ninx/search"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	benchmarkSkewedNumDocs   = 100000
+	benchmarkSkewedRareEvery = 10000
+)
+
+var (
+	benchmarkSkewedCommonField = []byte("service")
+	benchmarkSkewedCommonTerm  = []byte("common")
+	benchmarkSkewedRareField   = []byte("host")
+	benchmarkSkewedRareTerm    = []byte("rare")
+)
+
+func BenchmarkConjunctionSearcherSkewed(b *testing.B) {
+	r := newBenchmarkSkewedReader(b)
+	var (
+		common = NewTermSearcher(benchmarkSkewedCommonField, benchmarkSkewedCommonTerm)
+		rare   = NewTermSearcher(benchmarkSkewedRareField, benchmarkSkewedRareTerm)
+	)
+
+	benchmarks := []struct {
+		name      string
+		searchers search.Searchers
+	}{
+		{
+			name:      "common first",
+			searchers: search.Searchers{common, rare},
+		},
+		{
+			name:      "rare first",
+			searchers: search.Searchers{rare, common},
+		},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			s, err := NewConjunctionSearcher(bm.searchers, nil)
+			require.NoError(b, err)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				pl, err := s.Search(r)
+				require.NoError(b, err)
+				require.Equal(b, benchmarkSkewedNumDocs/benchmarkSkewedRareEvery, pl.Len())
+			}
+		})
+	}
+}
+
+func newBenchmarkSkewedReader(b *testing.B) index.Reader {
+	seg, err := mem.NewSegment(postings.ID(0), mem.NewOptions())
+	require.NoError(b, err)
+	for i := 0; i < benchmarkSkewedNumDocs; i++ {
+		host := []byte(fmt.Sprintf("host-%d", i%100))
+		if i%benchmarkSkewedRareEvery == 0 {
+			host = benchmarkSkewedRareTerm
+		}
+		_, err := seg.Insert(doc.Document{
+			ID: []byte(fmt.Sprintf("id-%d", i)),
+			Fields: []doc.Field{
+				{Name: benchmarkSkewedCommonField, Value: benchmarkSkewedCommonTerm},
+				{Name: benchmarkSkewedRareField, Value: host},
+			},
+		})
+		require.NoError(b, err)
+	}
+
+	r, err := seg.Reader()
+	require.NoError(b, err)
+	return r
+}
@@ -65,6 +65,9 @@ func TestConjunctionSearcher(t *testing.T) {
 	thirdPL2.Insert(postings.ID(89))
 	thirdSearcher := search.NewMockSearcher(mockCtrl)
 
+	firstSearcher.EXPECT().Cost().Return(termCost).AnyTimes()
+	secondSearcher.EXPECT().Cost().Return(termCost).AnyTimes()
+
 	gomock.InOrder(
 		// Get the postings lists for the first Reader.
 		firstSearcher.EXPECT().Search(firstReader).Return(firstPL1, nil),
@@ -102,6 +105,81 @@ func TestConjunctionSearcher(t *testing.T) {
 	require.True(t, pl.Equal(expected))
 }
 
+func TestConjunctionSearcherEmptyIntersectionSkipsNegations(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	reader := index.NewMockReader(mockCtrl)
+
+	firstPL := roaring.NewPostingsList()
+	firstPL.Insert(postings.ID(42))
+	firstSearcher := search.NewMockSearcher(mockCtrl)
+
+	secondPL := roaring.NewPostingsList()
+	secondPL.Insert(postings.ID(50))
+	secondSearcher := search.NewMockSearcher(mockCtrl)
+
+	// The negation searcher should never be called since the intersection of the first
+	// two searchers is empty.
+	negationSearcher := search.NewMockSearcher(mockCtrl)
+
+	firstSearcher.EXPECT().Cost().Return(termCost).AnyTimes()
+	secondSearcher.EXPECT().Cost().Return(termCost).AnyTimes()
+
+	gomock.InOrder(
+		firstSearcher.EXPECT().Search(reader).Return(firstPL, nil),
+		secondSearcher.EXPECT().Search(reader).Return(secondPL, nil),
+	)
+
+	s, err := NewConjunctionSearcher(
+		search.Searchers{firstSearcher, secondSearcher},
+		search.Searchers{negationSearcher},
+	)
+	require.NoError(t, err)
+
+	pl, err := s.Search(reader)
+	require.NoError(t, err)
+	require.True(t, pl.IsEmpty())
+}
+
+func TestConjunctionSearcherOrdersByCost(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	reader := index.NewMockReader(mockCtrl)
+
+	expensivePL := roaring.NewPostingsList()
+	expensivePL.Insert(postings.ID(42))
+	expensivePL.Insert(postings.ID(50))
+	expensiveSearcher := search.NewMockSearcher(mockCtrl)
+	expensiveSearcher.EXPECT().Cost().Return(defaultCost).AnyTimes()
+
+	cheapPL := roaring.NewPostingsList()
+	cheapPL.Insert(postings.ID(50))
+	cheapSearcher := search.NewMockSearcher(mockCtrl)
+	cheapSearcher.EXPECT().Cost().Return(termCost).AnyTimes()
+
+	gomock.InOrder(
+		cheapSearcher.EXPECT().Search(reader).Return(cheapPL, nil),
+		expensiveSearcher.EXPECT().Search(reader).Return(expensivePL, nil),
+
+		// The expensive searcher isn't searched if the cheap one matches nothing.
+		cheapSearcher.EXPECT().Search(reader).Return(roaring.NewPostingsList(), nil),
+	)
+
+	s, err := NewConjunctionSearcher(search.Searchers{expensiveSearcher, cheapSearcher}, nil)
+	require.NoError(t, err)
+	require.Equal(t, termCost, s.Cost())
+
+	pl, err := s.Search(reader)
+	require.NoError(t, err)
+	require.True(t, pl.Equal(cheapPL))
+
+	pl, err = s.Search(reader)
+	require.NoError(t, err)
+	require.True(t, pl.IsEmpty())
+}
+
 func TestConjunctionSearcherError(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -0,0 +1,52 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package searcher
+
+import "math"
+
+// The estimated costs of the searchers. Since a searcher doesn't know which readers it
+// will be executed against, the costs are fixed estimates of how many documents each kind
+// of searcher matches rather than exact counts.
+const (
+	emptyCost  = 0
+	termCost   = 1
+	fuzzyCost  = 10
+	foldCost   = 10
+	prefixCost = 100
+	rangeCost  = 100
+	fieldCost  = 10000
+	maxCost    = math.MaxInt32
+
+	// defaultCost is the cost of searchers whose number of matches can't be estimated.
+	defaultCost = 1000
+)
+
+// addCosts returns the sum of the given costs, saturating at maxCost.
+func addCosts(costs ...int) int {
+	var sum int
+	for _, c := range costs {
+		if c >= maxCost-sum {
+			return maxCost
+		}
+		sum += c
+	}
+	return sum
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package searcher
+
+import (
+	"testing"
+
+	"github.com/m3db/m3/src/m3ninx/search"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddCosts(t *testing.T) {
+	require.Equal(t, 0, addCosts())
+	require.Equal(t, termCost+prefixCost, addCosts(termCost, prefixCost))
+	require.Equal(t, maxCost, addCosts(maxCost-1, termCost))
+	require.Equal(t, maxCost, addCosts(maxCost, maxCost))
+}
+
+func TestSearcherCosts(t *testing.T) {
+	var (
+		term   = NewTermSearcher([]byte("fruit"), []byte("apple"))
+		prefix = NewPrefixSearcher([]byte("fruit"), []byte("app"))
+		field  = NewFieldSearcher([]byte("fruit"))
+	)
+	negation, err := NewNegationSearcher(term)
+	require.NoError(t, err)
+	disjunction, err := NewDisjunctionSearcher(search.Searchers{term, prefix})
+	require.NoError(t, err)
+	conjunction, err := NewConjunctionSearcher(search.Searchers{field, prefix, term}, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		searcher search.Searcher
+		expected int
+	}{
+		{name: "empty", searcher: NewEmptySearcher(), expected: emptyCost},
+		{name: "all", searcher: NewAllSearcher(), expected: maxCost},
+		{name: "term", searcher: term, expected: termCost},
+		{name: "field", searcher: field, expected: fieldCost},
+		{name: "negation", searcher: negation, expected: maxCost - termCost},
+		{name: "disjunction", searcher: disjunction, expected: termCost + prefixCost},
+		{name: "conjunction", searcher: conjunction, expected: termCost},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, test.searcher.Cost())
+		})
+	}
+}
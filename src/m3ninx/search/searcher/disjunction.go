@@ -59,3 +59,11 @@ func (s *disjunctionSearcher) Search(r index.Reader) (postings.List, error) {
 	}
 	return pl, nil
 }
+
+func (s *disjunctionSearcher) Cost() int {
+	costs := make([]int, 0, len(s.searchers))
+	for _, sr := range s.searchers {
+		costs = append(costs, sr.Cost())
+	}
+	return addCosts(costs...)
+}
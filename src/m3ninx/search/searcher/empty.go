@@ -41,3 +41,7 @@ func NewEmptySearcher() search.Searcher {
 func (s *emptySearcher) Search(r index.Reader) (postings.List, error) {
 	return s.postings, nil
 }
+
+func (s *emptySearcher) Cost() int {
+	return emptyCost
+}
@@ -52,3 +52,8 @@ func (s *negationSearcher) Search(r index.Reader) (postings.List, error) {
 	pl.Difference(sPl)
 	return pl, nil
 }
+
+func (s *negationSearcher) Cost() int {
+	// A negation matches every document which the searcher it negates doesn't match.
+	return maxCost - s.searcher.Cost()
+}
@@ -32,8 +32,15 @@ type regexpSearcher struct {
 }
 
 // NewRegexpSearcher returns a new searcher for finding documents which match the given regular
-// expression.
-func NewRegexpSearcher(field []byte, compiled index.CompiledRegex) search.Searcher {
+// expression. The search fails with index.ErrTooManyTermsMatched if the regular expression
+// matches more terms in a segment than permitted by the options. The searcher is also a
+// search.TermsSearcher, whose terms aren't limited by the options.
+func NewRegexpSearcher(
+	field []byte,
+	compiled index.CompiledRegex,
+	opts search.Options,
+) search.Searcher {
+	compiled.MaxTermsMatched = opts.MaxTermsMatched()
 	return &regexpSearcher{
 		field:    field,
 		compiled: compiled,
@@ -43,3 +50,11 @@ func NewRegexpSearcher(field []byte, compiled index.CompiledRegex) search.Search
 func (s *regexpSearcher) Search(r index.Reader) (postings.List, error) {
 	return r.MatchRegexp(s.field, s.compiled)
 }
+
+func (s *regexpSearcher) Cost() int {
+	return defaultCost
+}
+
+func (s *regexpSearcher) MatchTerms(r index.Reader, limit int) ([]index.TermFrequency, error) {
+	return r.MatchRegexpTerms(s.field, s.compiled, limit)
+}
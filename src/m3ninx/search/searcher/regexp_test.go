@@ -27,6 +27,7 @@ import (
 	"github.com/m3db/m3/src/m3ninx/index"
 	"github.com/m3db/m3/src/m3ninx/postings"
 	"github.com/m3db/m3/src/m3ninx/postings/roaring"
+	"github.com/m3db/m3/src/m3ninx/search"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
@@ -60,7 +61,7 @@ func TestRegexpSearcher(t *testing.T) {
 		secondReader.EXPECT().MatchRegexp(field, compiled).Return(secondPL, nil),
 	)
 
-	s := NewRegexpSearcher(field, compiled)
+	s := NewRegexpSearcher(field, compiled, search.NewOptions())
 
 	// Test the postings list from the first Reader.
 	pl, err := s.Search(firstReader)
@@ -72,3 +73,46 @@ func TestRegexpSearcher(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, pl.Equal(secondPL))
 }
+
+func TestRegexpSearcherMaxTermsMatched(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	field, regexp := []byte("fruit"), []byte(".*pple")
+	compiled := index.CompiledRegex{
+		Simple: re.MustCompile(string(regexp)),
+	}
+	limited := compiled
+	limited.MaxTermsMatched = 10
+
+	r := index.NewMockReader(mockCtrl)
+	r.EXPECT().MatchRegexp(field, limited).Return(nil, index.ErrTooManyTermsMatched)
+
+	s := NewRegexpSearcher(field, compiled, search.NewOptions().SetMaxTermsMatched(10))
+	_, err := s.Search(r)
+	require.Equal(t, index.ErrTooManyTermsMatched, err)
+}
+
+func TestRegexpSearcherMatchTerms(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	field := []byte("fruit")
+	compiled, err := index.CompileRegex([]byte(".*pple"))
+	require.NoError(t, err)
+	terms := []index.TermFrequency{
+		{Term: []byte("apple"), Count: 3},
+		{Term: []byte("pineapple"), Count: 1},
+	}
+
+	// The terms matched aren't limited by the maximum number of terms matched.
+	reader := index.NewMockReader(mockCtrl)
+	reader.EXPECT().MatchRegexpTerms(field, gomock.Any(), 5).Return(terms, nil)
+
+	s, ok := NewRegexpSearcher(field, compiled, search.NewOptions().SetMaxTermsMatched(1)).(search.TermsSearcher)
+	require.True(t, ok)
+
+	result, err := s.MatchTerms(reader, 5)
+	require.NoError(t, err)
+	require.Equal(t, terms, result)
+}
@@ -41,3 +41,7 @@ func NewTermSearcher(field, term []byte) search.Searcher {
 func (s *termSearcher) Search(r index.Reader) (postings.List, error) {
 	return r.MatchTerm(s.field, s.term)
 }
+
+func (s *termSearcher) Cost() int {
+	return termCost
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package search
+
+import (
+	"time"
+
+	"github.com/m3db/m3/src/m3ninx/index"
+	"github.com/m3db/m3/src/m3ninx/postings"
+)
+
+// Trace is a record of the execution of a searcher and of the searchers it's composed
+// of. The statistics of a trace are accumulated over every segment searched and include
+// those of its children.
+type Trace struct {
+	// Name describes what was searched, e.g. the query the searcher was created for.
+	Name string
+
+	// TermsScanned is the number of terms examined, for segments which track it.
+	TermsScanned int64
+
+	// PostingsDecoded is the number of postings read by the leaf searchers.
+	PostingsDecoded int64
+
+	// DocsEmitted is the number of documents matched by the searcher.
+	DocsEmitted int64
+
+	// Duration is the wall time spent searching.
+	Duration time.Duration
+
+	// Children are the traces of the searchers the searcher is composed of.
+	Children []*Trace
+}
+
+// NewTrace returns a new trace with the given name.
+func NewTrace(name string) *Trace {
+	return &Trace{Name: name}
+}
+
+// NewChild adds a new child trace with the given name to the trace and returns it.
+func (t *Trace) NewChild(name string) *Trace {
+	child := NewTrace(name)
+	t.Children = append(t.Children, child)
+	return child
+}
+
+// Finish completes a trace which groups the traces of its children, e.g. those of a
+// query searched over several segments, recording the wall time since start and the
+// documents emitted, and summing the terms scanned and postings decoded of its children.
+func (t *Trace) Finish(start time.Time, docsEmitted int64) {
+	t.Duration = time.Since(start)
+	t.DocsEmitted = docsEmitted
+	t.TermsScanned, t.PostingsDecoded = 0, 0
+	for _, child := range t.Children {
+		t.TermsScanned += child.TermsScanned
+		t.PostingsDecoded += child.PostingsDecoded
+	}
+}
+
+// NewSearcher returns a Searcher for the query. If the options have a trace then the
+// execution of the searcher is recorded in a new child of it, otherwise the searcher
+// is returned as is so untraced searches pay nothing.
+func NewSearcher(q Query, opts Options) (Searcher, error) {
+	trace := opts.Trace()
+	if trace == nil {
+		return q.Searcher(opts)
+	}
+
+	child := trace.NewChild(q.String())
+	s, err := q.Searcher(opts.SetTrace(child))
+	if err != nil {
+		return nil, err
+	}
+	return &tracingSearcher{
+		searcher: s,
+		trace:    child,
+	}, nil
+}
+
+type tracingSearcher struct {
+	searcher Searcher
+	trace    *Trace
+}
+
+func (s *tracingSearcher) Search(r index.Reader) (postings.List, error) {
+	var (
+		start                 = time.Now()
+		statsReader, hasStats = r.(index.StatsReader)
+		termsScanned          int64
+	)
+	if hasStats {
+		termsScanned = statsReader.Stats().TermsScanned
+	}
+
+	pl, err := s.searcher.Search(r)
+	s.trace.Duration += time.Since(start)
+	if err != nil {
+		return nil, err
+	}
+
+	if hasStats {
+		s.trace.TermsScanned += statsReader.Stats().TermsScanned - termsScanned
+	}
+
+	n := int64(pl.Len())
+	s.trace.DocsEmitted += n
+	if len(s.trace.Children) == 0 {
+		s.trace.PostingsDecoded += n
+	} else {
+		// NB: the children have already been searched so their totals are up to date.
+		s.trace.PostingsDecoded = 0
+		for _, child := range s.trace.Children {
+			s.trace.PostingsDecoded += child.PostingsDecoded
+		}
+	}
+
+	return pl, nil
+}
+
+func (s *tracingSearcher) Cost() int {
+	return s.searcher.Cost()
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package search
+
+import (
+	"testing"
+
+	"github.com/m3db/m3/src/m3ninx/index"
+	"github.com/m3db/m3/src/m3ninx/postings"
+	"github.com/m3db/m3/src/m3ninx/postings/roaring"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type testStatsReader struct {
+	index.Reader
+
+	termsScanned int64
+}
+
+func (r *testStatsReader) Stats() index.ReaderStats {
+	return index.ReaderStats{TermsScanned: r.termsScanned}
+}
+
+func TestNewSearcherUntraced(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	var (
+		q    = NewMockQuery(mockCtrl)
+		s    = NewMockSearcher(mockCtrl)
+		opts = NewOptions()
+	)
+	q.EXPECT().Searcher(opts).Return(s, nil)
+
+	actual, err := NewSearcher(q, opts)
+	require.NoError(t, err)
+	require.Equal(t, s, actual)
+}
+
+func TestNewSearcherTraced(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	var (
+		q     = NewMockQuery(mockCtrl)
+		s     = NewMockSearcher(mockCtrl)
+		r     = &testStatsReader{}
+		trace = NewTrace("root")
+		opts  = NewOptions().SetTrace(trace)
+	)
+	pl := roaring.NewPostingsList()
+	pl.Insert(postings.ID(3))
+	pl.Insert(postings.ID(7))
+
+	q.EXPECT().String().Return("term(fruit, apple)")
+	q.EXPECT().Searcher(gomock.Any()).Do(func(opts Options) {
+		// The query's searcher is traced in a child of the trace.
+		require.Equal(t, trace.Children[0], opts.Trace())
+	}).Return(s, nil)
+	s.EXPECT().Search(r).Do(func(index.Reader) {
+		r.termsScanned += 5
+	}).Return(pl, nil).Times(2)
+	s.EXPECT().Cost().Return(42)
+
+	ts, err := NewSearcher(q, opts)
+	require.NoError(t, err)
+	require.Equal(t, 42, ts.Cost())
+
+	// The statistics are accumulated over every segment searched.
+	r.termsScanned = 10
+	for i := 0; i < 2; i++ {
+		actual, err := ts.Search(r)
+		require.NoError(t, err)
+		require.True(t, pl.Equal(actual))
+	}
+
+	require.Len(t, trace.Children, 1)
+	child := trace.Children[0]
+	require.Equal(t, "term(fruit, apple)", child.Name)
+	require.Equal(t, int64(10), child.TermsScanned)
+	require.Equal(t, int64(4), child.PostingsDecoded)
+	require.Equal(t, int64(4), child.DocsEmitted)
+	require.Empty(t, child.Children)
+}
+
+func TestTracePostingsDecodedSumsChildren(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	var (
+		q     = NewMockQuery(mockCtrl)
+		s     = NewMockSearcher(mockCtrl)
+		r     = index.NewMockReader(mockCtrl)
+		trace = NewTrace("root")
+	)
+	pl := roaring.NewPostingsList()
+	pl.Insert(postings.ID(3))
+
+	q.EXPECT().String().Return("conjunction(...)")
+	q.EXPECT().Searcher(gomock.Any()).Do(func(opts Options) {
+		opts.Trace().NewChild("a").PostingsDecoded = 10
+		opts.Trace().NewChild("b").PostingsDecoded = 20
+	}).Return(s, nil)
+	s.EXPECT().Search(r).Return(pl, nil)
+
+	ts, err := NewSearcher(q, NewOptions().SetTrace(trace))
+	require.NoError(t, err)
+	_, err = ts.Search(r)
+	require.NoError(t, err)
+
+	child := trace.Children[0]
+	require.Equal(t, int64(30), child.PostingsDecoded)
+	require.Equal(t, int64(1), child.DocsEmitted)
+	require.Equal(t, int64(0), child.TermsScanned)
+}
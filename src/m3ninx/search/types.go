@@ -42,12 +42,15 @@ type Executor interface {
 type Query interface {
 	fmt.Stringer
 
-	// Searcher returns a Searcher for executing the query.
-	Searcher() (Searcher, error)
+	// Searcher returns a Searcher for executing the query with the given options.
+	Searcher(opts Options) (Searcher, error)
 
 	// Equal reports whether two queries are equivalent.
 	Equal(q Query) bool
 
+	// Hash returns a stable hash of the query. Equivalent queries have the same hash.
+	Hash() uint64
+
 	// ToProto returns the Protobuf query struct corresponding to this query.
 	ToProto() *querypb.Query
 }
@@ -57,6 +60,23 @@ type Query interface {
 type Searcher interface {
 	// Search executes a configured query against the given Reader.
 	Search(index.Reader) (postings.List, error)
+
+	// Cost returns an estimate of the number of documents the searcher matches relative
+	// to other searchers. Composite searchers use it to evaluate the most selective
+	// searchers first.
+	Cost() int
+}
+
+// TermsSearcher is a Searcher which matches documents by the terms of a single field, and
+// so can also rank the terms it matches by the number of documents containing them.
+type TermsSearcher interface {
+	Searcher
+
+	// MatchTerms returns at most limit of the terms matched in the given Reader along
+	// with their document frequencies, ordered by descending frequency, without computing
+	// the postings list of the documents they match. A non-positive limit returns every
+	// matched term.
+	MatchTerms(r index.Reader, limit int) ([]index.TermFrequency, error)
 }
 
 // Searchers is a slice of Searcher.
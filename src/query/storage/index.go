@@ -24,11 +24,18 @@ import (
 	"fmt"
 
 	"github.com/m3db/m3/src/dbnode/storage/index"
-	"github.com/m3db/m3/src/m3ninx/idx"
+	idxmatchers "github.com/m3db/m3/src/m3ninx/idx/matchers"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3x/ident"
 )
 
+var matcherTypes = map[models.MatchType]idxmatchers.Type{
+	models.MatchEqual:     idxmatchers.EQ,
+	models.MatchNotEqual:  idxmatchers.NEQ,
+	models.MatchRegexp:    idxmatchers.RE,
+	models.MatchNotRegexp: idxmatchers.NRE,
+}
+
 // FromM3IdentToMetric converts an M3 ident metric to a coordinator metric
 func FromM3IdentToMetric(identID ident.ID, iterTags ident.TagIterator) (*models.Metric, error) {
 	id := identID.String()
@@ -85,48 +92,22 @@ func FetchOptionsToM3Options(fetchOptions *FetchOptions, fetchQuery *FetchQuery)
 // FetchQueryToM3Query converts an m3coordinator fetch query to an M3 query
 func FetchQueryToM3Query(fetchQuery *FetchQuery) (index.Query, error) {
 	matchers := fetchQuery.TagMatchers
-	idxQueries := make([]idx.Query, len(matchers))
-	var err error
+	idxMatchers := make([]idxmatchers.Matcher, len(matchers))
 	for i, matcher := range matchers {
-		idxQueries[i], err = matcherToQuery(matcher)
-		if err != nil {
-			return index.Query{}, err
-		}
-	}
-
-	q := idx.NewConjunctionQuery(idxQueries...)
-	return index.Query{Query: q}, nil
-}
-
-func matcherToQuery(matcher *models.Matcher) (idx.Query, error) {
-	negate := false
-	switch matcher.Type {
-	// Support for Regexp types
-	case models.MatchNotRegexp:
-		negate = true
-		fallthrough
-	case models.MatchRegexp:
-		query, err := idx.NewRegexpQuery([]byte(matcher.Name), []byte(matcher.Value))
-		if err != nil {
-			return idx.Query{}, err
-		}
-		if negate {
-			query = idx.NewNegationQuery(query)
+		matcherType, ok := matcherTypes[matcher.Type]
+		if !ok {
+			return index.Query{}, fmt.Errorf("unsupported query type: %v", matcher)
 		}
-		return query, nil
-
-		// Support exact matches
-	case models.MatchNotEqual:
-		negate = true
-		fallthrough
-	case models.MatchEqual:
-		query := idx.NewTermQuery([]byte(matcher.Name), []byte(matcher.Value))
-		if negate {
-			query = idx.NewNegationQuery(query)
+		idxMatchers[i] = idxmatchers.Matcher{
+			Type:  matcherType,
+			Name:  []byte(matcher.Name),
+			Value: []byte(matcher.Value),
 		}
-		return query, nil
+	}
 
-	default:
-		return idx.Query{}, fmt.Errorf("unsupported query type: %v", matcher)
+	q, err := idxmatchers.NewQuery(idxMatchers...)
+	if err != nil {
+		return index.Query{}, err
 	}
+	return index.Query{Query: q}, nil
 }
@@ -88,7 +88,7 @@ func TestFetchQueryToM3Query(t *testing.T) {
 		},
 		{
 			name:     "exact match negated",
-			expected: "conjunction(negation(term(t1, v1)))",
+			expected: "conjunction(all(), negation(term(t1, v1)))",
 			matchers: models.Matchers{
 				{
 					Type:  models.MatchNotEqual,
@@ -110,7 +110,7 @@ func TestFetchQueryToM3Query(t *testing.T) {
 		},
 		{
 			name:     "regexp match negated",
-			expected: "conjunction(negation(regexp(t1, v1)))",
+			expected: "conjunction(all(), negation(regexp(t1, v1)))",
 			matchers: models.Matchers{
 				{
 					Type:  models.MatchNotRegexp,
@@ -119,6 +119,39 @@ func TestFetchQueryToM3Query(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:     "exact match empty",
+			expected: "conjunction(all(), negation(field(t1)))",
+			matchers: models.Matchers{
+				{
+					Type:  models.MatchEqual,
+					Name:  "t1",
+					Value: "",
+				},
+			},
+		},
+		{
+			name:     "exact match empty negated",
+			expected: "conjunction(field(t1))",
+			matchers: models.Matchers{
+				{
+					Type:  models.MatchNotEqual,
+					Name:  "t1",
+					Value: "",
+				},
+			},
+		},
+		{
+			name:     "regexp match all",
+			expected: "conjunction(all())",
+			matchers: models.Matchers{
+				{
+					Type:  models.MatchRegexp,
+					Name:  "t1",
+					Value: ".*",
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
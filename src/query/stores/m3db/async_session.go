@@ -114,6 +114,17 @@ func (s *AsyncSession) WriteTagged(namespace, id ident.ID, tags ident.TagIterato
 	return s.session.WriteTagged(namespace, id, tags, t, value, unit, annotation)
 }
 
+// WriteTaggedBatch writes a batch of tagged values to the database
+func (s *AsyncSession) WriteTaggedBatch(namespace ident.ID, writes []client.TaggedWrite) error {
+	s.RLock()
+	defer s.RUnlock()
+	if s.err != nil {
+		return s.err
+	}
+
+	return s.session.WriteTaggedBatch(namespace, writes)
+}
+
 // Fetch fetches values from the database for an ID
 func (s *AsyncSession) Fetch(namespace, id ident.ID, startInclusive, endExclusive time.Time) (encoding.SeriesIterator, error) {
 	s.RLock()
@@ -158,6 +169,29 @@ func (s *AsyncSession) FetchTaggedIDs(namespace ident.ID, q index.Query, opts in
 	return s.session.FetchTaggedIDs(namespace, q, opts)
 }
 
+// FetchTaggedPages resolves the provided query to known IDs, and returns an iterator
+// which fetches the data for them a page at a time.
+func (s *AsyncSession) FetchTaggedPages(namespace ident.ID, q index.Query, opts index.QueryOptions) (client.FetchTaggedPagesIterator, error) {
+	s.RLock()
+	defer s.RUnlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return s.session.FetchTaggedPages(namespace, q, opts)
+}
+
+// AggregateTagValues returns a page of the values of a tag across the cluster.
+func (s *AsyncSession) AggregateTagValues(namespace ident.ID, opts index.AggregateTagValuesOptions) (index.AggregateTagValuesResults, error) {
+	s.RLock()
+	defer s.RUnlock()
+	if s.err != nil {
+		return index.AggregateTagValuesResults{}, s.err
+	}
+
+	return s.session.AggregateTagValues(namespace, opts)
+}
+
 // ShardID returns the given shard for an ID for callers
 // to easily discern what shard is failing when operations
 // for given IDs begin failing
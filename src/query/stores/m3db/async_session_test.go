@@ -75,6 +75,9 @@ func TestAsyncSessionError(t *testing.T) {
 	err = asyncSession.WriteTagged(nil, nil, nil, time.Now(), 0, xtime.Second, nil)
 	assert.EqualError(t, err, expectedErrStr)
 
+	err = asyncSession.WriteTaggedBatch(nil, nil)
+	assert.EqualError(t, err, expectedErrStr)
+
 	seriesIterator, err := asyncSession.Fetch(nil, nil, time.Now(), time.Now())
 	assert.Nil(t, seriesIterator)
 	assert.EqualError(t, err, expectedErrStr)
@@ -138,6 +141,10 @@ func TestAsyncSessionInitialized(t *testing.T) {
 	err = asyncSession.WriteTagged(nil, nil, nil, time.Now(), 0, xtime.Second, nil)
 	assert.NoError(t, err)
 
+	mockSession.EXPECT().WriteTaggedBatch(gomock.Any(), gomock.Any()).Return(nil)
+	err = asyncSession.WriteTaggedBatch(nil, nil)
+	assert.NoError(t, err)
+
 	mockSession.EXPECT().Fetch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
 	_, err = asyncSession.Fetch(nil, nil, time.Now(), time.Now())
 	assert.NoError(t, err)